@@ -0,0 +1,418 @@
+// scheduler-sim 在虚拟时钟上重放一段 usage_log 流量，复用线上调度策略离线评估
+// 账号优先级、并发、负载因子、倍率与分组调度策略的调整效果，输出各候选配置下的
+// 账号负载、排队等待、粘性命中率、429 次数与成本。
+//
+// 数据来源二选一：
+//   - -input：JSON 夹具（groups / accounts / usage_logs，可同时携带 upstream 与 configs）；
+//   - -dsn：只读连接数据库（生产库的恢复副本），按 -start/-end 窗口读取 usage_logs。
+//
+// 用法：
+//
+//	go run ./cmd/scheduler-sim -input fixture.json [-configs configs.json] [-json]
+//	go run ./cmd/scheduler-sim -dsn "postgres://..." -start 2026-10-01T00:00:00Z -end 2026-10-01T06:00:00Z [-groups 1,2] [-configs configs.json]
+//
+// 未提供候选配置时，对比当前配置与每种已注册调度策略。
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	_ "github.com/lib/pq"
+)
+
+type inputGroup struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Platform           string `json:"platform"`
+	SchedulingStrategy string `json:"scheduling_strategy"`
+}
+
+type inputAccount struct {
+	ID             int64             `json:"id"`
+	Name           string            `json:"name"`
+	Platform       string            `json:"platform"`
+	Type           string            `json:"type"`
+	Status         string            `json:"status"`
+	Schedulable    *bool             `json:"schedulable"`
+	Priority       int               `json:"priority"`
+	Concurrency    int               `json:"concurrency"`
+	LoadFactor     *int              `json:"load_factor"`
+	RateMultiplier *float64          `json:"rate_multiplier"`
+	ModelMapping   map[string]string `json:"model_mapping"`
+	GroupIDs       []int64           `json:"group_ids"`
+}
+
+type inputUsageLog struct {
+	CreatedAt    time.Time `json:"created_at"`
+	GroupID      int64     `json:"group_id"`
+	Model        string    `json:"model"`
+	SessionID    string    `json:"session_id"`
+	DurationMs   int       `json:"duration_ms"`
+	FirstTokenMs *int      `json:"first_token_ms"`
+	TotalCost    float64   `json:"total_cost"`
+}
+
+type inputDoc struct {
+	Groups    []inputGroup    `json:"groups"`
+	Accounts  []inputAccount  `json:"accounts"`
+	UsageLogs []inputUsageLog `json:"usage_logs"`
+	// Upstream 按账号 ID 配置模拟上游行为（延迟倍数、RPM 上限、随机 429）。
+	Upstream map[string]service.SchedulerSimulationUpstream `json:"upstream"`
+	Configs  []service.SchedulerSimulationConfig            `json:"configs"`
+}
+
+func main() {
+	inputPath := flag.String("input", "", "JSON 夹具路径（与 -dsn 二选一）")
+	dsn := flag.String("dsn", "", "只读 PostgreSQL DSN（与 -input 二选一）")
+	startStr := flag.String("start", "", "重放窗口开始（RFC3339，-dsn 模式必填）")
+	endStr := flag.String("end", "", "重放窗口结束（RFC3339，-dsn 模式必填）")
+	groupsStr := flag.String("groups", "", "仅重放指定分组，逗号分隔的分组 ID")
+	configsPath := flag.String("configs", "", "候选配置 JSON（upstream / configs），覆盖夹具中的同名字段")
+	preferSoonestReset := flag.Bool("prefer-soonest-reset", false, "模拟 gateway.scheduling.prefer_soonest_reset")
+	seed := flag.Int64("seed", 1, "随机 429 的种子")
+	jsonOut := flag.Bool("json", false, "以 JSON 输出完整报告（默认输出可读表格）")
+	flag.Parse()
+	if (*inputPath == "") == (*dsn == "") {
+		fmt.Fprintln(os.Stderr, "usage: scheduler-sim (-input fixture.json | -dsn DSN -start T -end T [-groups 1,2]) [-configs configs.json] [-json]")
+		os.Exit(2)
+	}
+
+	var doc inputDoc
+	if *inputPath != "" {
+		raw, err := os.ReadFile(*inputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read input: %v\n", err)
+			os.Exit(1)
+		}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			fmt.Fprintf(os.Stderr, "parse input: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		groupIDs, err := parseGroupIDs(*groupsStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse -groups: %v\n", err)
+			os.Exit(2)
+		}
+		start, err := time.Parse(time.RFC3339, *startStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse -start: %v\n", err)
+			os.Exit(2)
+		}
+		end, err := time.Parse(time.RFC3339, *endStr)
+		if err != nil || !end.After(start) {
+			fmt.Fprintln(os.Stderr, "parse -end: must be RFC3339 and after -start")
+			os.Exit(2)
+		}
+		if err := loadFromDatabase(context.Background(), *dsn, start, end, groupIDs, &doc); err != nil {
+			fmt.Fprintf(os.Stderr, "load database: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *configsPath != "" {
+		raw, err := os.ReadFile(*configsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read configs: %v\n", err)
+			os.Exit(1)
+		}
+		var overlay inputDoc
+		if err := json.Unmarshal(raw, &overlay); err != nil {
+			fmt.Fprintf(os.Stderr, "parse configs: %v\n", err)
+			os.Exit(1)
+		}
+		if overlay.Upstream != nil {
+			doc.Upstream = overlay.Upstream
+		}
+		if overlay.Configs != nil {
+			doc.Configs = overlay.Configs
+		}
+	}
+
+	input, err := buildSimulationInput(&doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "build input: %v\n", err)
+		os.Exit(1)
+	}
+	input.Scheduling = config.GatewaySchedulingConfig{PreferSoonestReset: *preferSoonestReset}
+	input.Seed = *seed
+
+	reports, err := service.SimulateScheduling(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"requests": len(input.Requests), "reports": reports}); err != nil {
+			fmt.Fprintf(os.Stderr, "write output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printReports(input, reports)
+}
+
+func printReports(input service.SchedulerSimulationInput, reports []service.SchedulerSimulationReport) {
+	first, last := input.Requests[0].At, input.Requests[0].At
+	for _, req := range input.Requests {
+		if req.At.Before(first) {
+			first = req.At
+		}
+		if req.At.After(last) {
+			last = req.At
+		}
+	}
+	fmt.Printf("调度模拟：%d 条请求 %s ~ %s，%d 个账号\n",
+		len(input.Requests), first.Format(time.RFC3339), last.Format(time.RFC3339), len(input.Accounts))
+	for _, report := range reports {
+		fmt.Printf("\n== 配置 %s（策略=%s）==\n", report.Config, report.Strategy)
+		fmt.Printf("  请求=%d 成功=%d 拒绝=%d 排队超时=%d 换号失败=%d 换号=%d 429=%d\n",
+			report.Requests, report.Served, report.Rejected, report.QueueTimeouts, report.Failed, report.Failovers, report.RateLimitHits)
+		fmt.Printf("  粘性：带会话=%d 有绑定=%d 命中=%d 命中率=%.1f%%\n",
+			report.SessionRequests, report.StickyEligible, report.StickyHits, report.StickyHitRate*100)
+		fmt.Printf("  排队：%d 次 P50=%dms P95=%dms 最大=%dms | 成本=%.4f\n",
+			report.QueuedRequests, report.QueueWaitP50Ms, report.QueueWaitP95Ms, report.QueueWaitMaxMs, report.TotalCost)
+		for _, a := range report.Accounts {
+			fmt.Printf("  账号 %-4d %-24s 优先级=%-3d 并发=%-3d 请求=%-6d 峰值=%-3d 平均=%-6.2f 利用率=%5.1f%% 排队=%-5d 最长等待=%-8s 429=%-4d 成本=%.4f\n",
+				a.AccountID, a.Name, a.Priority, a.Concurrency, a.Requests, a.PeakConcurrency, a.AvgConcurrency,
+				a.Utilization*100, a.QueuedRequests, fmt.Sprintf("%dms", a.MaxQueueWaitMs), a.RateLimitHits, a.Cost)
+		}
+	}
+}
+
+func parseGroupIDs(raw string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid group id %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func buildSimulationInput(doc *inputDoc) (service.SchedulerSimulationInput, error) {
+	if len(doc.Accounts) == 0 {
+		return service.SchedulerSimulationInput{}, fmt.Errorf("input contains no accounts")
+	}
+	if len(doc.UsageLogs) == 0 {
+		return service.SchedulerSimulationInput{}, fmt.Errorf("input contains no usage_logs in the selected window")
+	}
+
+	input := service.SchedulerSimulationInput{}
+	for _, g := range doc.Groups {
+		input.Groups = append(input.Groups, &service.Group{
+			ID:                 g.ID,
+			Name:               g.Name,
+			Platform:           g.Platform,
+			Status:             service.StatusActive,
+			Hydrated:           true,
+			SchedulingStrategy: g.SchedulingStrategy,
+		})
+	}
+	for i, a := range doc.Accounts {
+		if a.ID <= 0 {
+			return service.SchedulerSimulationInput{}, fmt.Errorf("invalid account at index %d: id is required", i)
+		}
+		status := a.Status
+		if status == "" {
+			status = service.StatusActive
+		}
+		schedulable := true
+		if a.Schedulable != nil {
+			schedulable = *a.Schedulable
+		}
+		account := &service.Account{
+			ID:             a.ID,
+			Name:           a.Name,
+			Platform:       a.Platform,
+			Type:           a.Type,
+			Status:         status,
+			Schedulable:    schedulable,
+			Priority:       a.Priority,
+			Concurrency:    a.Concurrency,
+			LoadFactor:     a.LoadFactor,
+			RateMultiplier: a.RateMultiplier,
+		}
+		if len(a.ModelMapping) > 0 {
+			mapping := make(map[string]any, len(a.ModelMapping))
+			for k, v := range a.ModelMapping {
+				mapping[k] = v
+			}
+			account.Credentials = map[string]any{"model_mapping": mapping}
+		}
+		input.Accounts = append(input.Accounts, service.SchedulerSimulationAccount{
+			Account:  account,
+			GroupIDs: a.GroupIDs,
+			Upstream: doc.Upstream[strconv.FormatInt(a.ID, 10)],
+		})
+	}
+	for _, row := range doc.UsageLogs {
+		input.Requests = append(input.Requests, service.SchedulerSimulationRequest{
+			At:           row.CreatedAt,
+			GroupID:      row.GroupID,
+			Model:        row.Model,
+			SessionID:    row.SessionID,
+			Duration:     time.Duration(row.DurationMs) * time.Millisecond,
+			FirstTokenMs: row.FirstTokenMs,
+			TotalCost:    row.TotalCost,
+		})
+	}
+
+	input.Configs = doc.Configs
+	if len(input.Configs) == 0 {
+		input.Configs = []service.SchedulerSimulationConfig{{Name: "current"}}
+		for _, strategy := range service.AccountSchedulingStrategies() {
+			input.Configs = append(input.Configs, service.SchedulerSimulationConfig{Name: strategy, Strategy: strategy})
+		}
+	}
+	return input, nil
+}
+
+// loadFromDatabase 只读加载分组、账号及其分组关系与窗口内的 usage_logs。
+func loadFromDatabase(ctx context.Context, dsn string, start, end time.Time, groupIDs []int64, doc *inputDoc) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	wanted := make(map[int64]struct{}, len(groupIDs))
+	for _, id := range groupIDs {
+		wanted[id] = struct{}{}
+	}
+	include := func(groupID int64) bool {
+		if len(wanted) == 0 {
+			return true
+		}
+		_, ok := wanted[groupID]
+		return ok
+	}
+
+	groupRows, err := tx.QueryContext(ctx, `SELECT id, name, platform, scheduling_strategy FROM groups WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("query groups: %w", err)
+	}
+	for groupRows.Next() {
+		var g inputGroup
+		if err := groupRows.Scan(&g.ID, &g.Name, &g.Platform, &g.SchedulingStrategy); err != nil {
+			_ = groupRows.Close()
+			return fmt.Errorf("scan group: %w", err)
+		}
+		if include(g.ID) {
+			doc.Groups = append(doc.Groups, g)
+		}
+	}
+	if err := groupRows.Close(); err != nil {
+		return err
+	}
+
+	accountGroups := make(map[int64][]int64)
+	agRows, err := tx.QueryContext(ctx, `SELECT account_id, group_id FROM account_groups`)
+	if err != nil {
+		return fmt.Errorf("query account_groups: %w", err)
+	}
+	for agRows.Next() {
+		var accountID, groupID int64
+		if err := agRows.Scan(&accountID, &groupID); err != nil {
+			_ = agRows.Close()
+			return fmt.Errorf("scan account_group: %w", err)
+		}
+		if include(groupID) {
+			accountGroups[accountID] = append(accountGroups[accountID], groupID)
+		}
+	}
+	if err := agRows.Close(); err != nil {
+		return err
+	}
+
+	accountRows, err := tx.QueryContext(ctx, `
+		SELECT id, name, platform, type, status, schedulable, priority, concurrency, load_factor, rate_multiplier,
+		       COALESCE(credentials->'model_mapping', 'null'::jsonb)
+		FROM accounts
+		WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("query accounts: %w", err)
+	}
+	for accountRows.Next() {
+		var a inputAccount
+		var schedulable bool
+		var loadFactor sql.NullInt64
+		var rateMultiplier sql.NullFloat64
+		var mappingRaw []byte
+		if err := accountRows.Scan(&a.ID, &a.Name, &a.Platform, &a.Type, &a.Status, &schedulable, &a.Priority, &a.Concurrency, &loadFactor, &rateMultiplier, &mappingRaw); err != nil {
+			_ = accountRows.Close()
+			return fmt.Errorf("scan account: %w", err)
+		}
+		groups, ok := accountGroups[a.ID]
+		if !ok {
+			continue
+		}
+		a.GroupIDs = groups
+		a.Schedulable = &schedulable
+		if loadFactor.Valid {
+			v := int(loadFactor.Int64)
+			a.LoadFactor = &v
+		}
+		if rateMultiplier.Valid {
+			v := rateMultiplier.Float64
+			a.RateMultiplier = &v
+		}
+		// model_mapping 值可能不是字符串（历史数据），解析失败时视为无映射
+		_ = json.Unmarshal(mappingRaw, &a.ModelMapping)
+		doc.Accounts = append(doc.Accounts, a)
+	}
+	if err := accountRows.Close(); err != nil {
+		return err
+	}
+
+	logRows, err := tx.QueryContext(ctx, `
+		SELECT created_at, COALESCE(group_id, 0), COALESCE(NULLIF(requested_model, ''), model), COALESCE(session_id, ''),
+		       COALESCE(duration_ms, 0), first_token_ms, total_cost
+		FROM usage_logs
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at, id`, start, end)
+	if err != nil {
+		return fmt.Errorf("query usage_logs: %w", err)
+	}
+	defer func() { _ = logRows.Close() }()
+	for logRows.Next() {
+		var row inputUsageLog
+		var firstTokenMs sql.NullInt64
+		if err := logRows.Scan(&row.CreatedAt, &row.GroupID, &row.Model, &row.SessionID, &row.DurationMs, &firstTokenMs, &row.TotalCost); err != nil {
+			return fmt.Errorf("scan usage_log: %w", err)
+		}
+		if row.GroupID <= 0 || !include(row.GroupID) {
+			continue
+		}
+		if firstTokenMs.Valid {
+			v := int(firstTokenMs.Int64)
+			row.FirstTokenMs = &v
+		}
+		doc.UsageLogs = append(doc.UsageLogs, row)
+	}
+	return logRows.Err()
+}
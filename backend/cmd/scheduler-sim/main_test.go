@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureJSON = `{
+	"groups": [{"id": 7, "name": "claude", "platform": "anthropic", "scheduling_strategy": "cost_aware"}],
+	"accounts": [
+		{"id": 1, "name": "a", "platform": "anthropic", "type": "apikey", "priority": 1, "concurrency": 2, "rate_multiplier": 0.5, "group_ids": [7]},
+		{"id": 2, "name": "b", "platform": "anthropic", "type": "apikey", "schedulable": false, "concurrency": 1, "group_ids": [7],
+		 "model_mapping": {"claude-sonnet-4": "claude-sonnet-4"}}
+	],
+	"usage_logs": [
+		{"created_at": "2026-10-01T00:00:01Z", "group_id": 7, "model": "claude-sonnet-4", "session_id": "s1", "duration_ms": 1500, "first_token_ms": 300, "total_cost": 0.2},
+		{"created_at": "2026-10-01T00:00:00Z", "group_id": 7, "model": "claude-sonnet-4", "duration_ms": 800, "total_cost": 0.1}
+	],
+	"upstream": {"1": {"rpm_limit": 60, "latency_scale": 1.2}}
+}`
+
+func TestBuildSimulationInputFromFixture(t *testing.T) {
+	var doc inputDoc
+	require.NoError(t, json.Unmarshal([]byte(fixtureJSON), &doc))
+
+	input, err := buildSimulationInput(&doc)
+	require.NoError(t, err)
+	require.Len(t, input.Groups, 1)
+	require.Equal(t, service.AccountSchedulingStrategyCostAware, input.Groups[0].SchedulingStrategy)
+
+	require.Len(t, input.Accounts, 2)
+	require.Equal(t, service.StatusActive, input.Accounts[0].Account.Status)
+	require.True(t, input.Accounts[0].Account.Schedulable)
+	require.False(t, input.Accounts[1].Account.Schedulable)
+	require.Equal(t, 60, input.Accounts[0].Upstream.RPMLimit)
+	require.Zero(t, input.Accounts[1].Upstream.RPMLimit)
+	require.True(t, input.Accounts[1].Account.IsModelSupported("claude-sonnet-4"))
+	require.False(t, input.Accounts[1].Account.IsModelSupported("claude-opus-4"))
+
+	require.Len(t, input.Requests, 2)
+	require.Equal(t, 1500*time.Millisecond, input.Requests[0].Duration)
+	require.Equal(t, 300, *input.Requests[0].FirstTokenMs)
+
+	// 未提供候选配置：当前配置 + 每种已注册策略
+	require.Len(t, input.Configs, 1+len(service.AccountSchedulingStrategies()))
+	require.Equal(t, "current", input.Configs[0].Name)
+	require.Empty(t, input.Configs[0].Strategy)
+
+	reports, err := service.SimulateScheduling(input)
+	require.NoError(t, err)
+	require.Len(t, reports, len(input.Configs))
+	for _, report := range reports {
+		require.Equal(t, 2, report.Served, report.Config)
+		require.InDelta(t, 0.15, report.TotalCost, 1e-9, report.Config)
+	}
+}
+
+func TestBuildSimulationInputRequiresUsageLogs(t *testing.T) {
+	_, err := buildSimulationInput(&inputDoc{Accounts: []inputAccount{{ID: 1}}})
+	require.ErrorContains(t, err, "no usage_logs")
+
+	_, err = buildSimulationInput(&inputDoc{})
+	require.ErrorContains(t, err, "no accounts")
+}
+
+func TestParseGroupIDs(t *testing.T) {
+	ids, err := parseGroupIDs(" 1, 2,,3 ")
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3}, ids)
+
+	_, err = parseGroupIDs("1,x")
+	require.Error(t, err)
+}
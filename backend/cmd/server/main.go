@@ -19,7 +19,9 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/Wei-Shaw/sub2api/internal/setup"
 	"github.com/Wei-Shaw/sub2api/internal/web"
 
@@ -139,6 +141,18 @@ func runMainServer() {
 	if err := logger.Init(logger.OptionsFromConfig(cfg.Log)); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	shutdownTracing, err := tracing.Init(tracingOptionsFromConfig(cfg))
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	// 最后执行：应用清理期间产生的 span 也需要被导出
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Tracing shutdown: %v", err)
+		}
+	}()
 	if cfg.RunMode == config.RunModeSimple {
 		log.Println("⚠️  WARNING: Running in SIMPLE mode - billing and quota checks are DISABLED")
 	}
@@ -172,6 +186,17 @@ func runMainServer() {
 
 	log.Printf("Server started on %s", app.Server.Addr)
 
+	// SIGHUP 重新加载配置文件，不中断现有连接
+	if app.ConfigReload != nil {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				_, _ = app.ConfigReload.Reload(context.Background(), service.ConfigReloadSourceSignal)
+			}
+		}()
+	}
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -188,3 +213,30 @@ func runMainServer() {
 
 	log.Println("Server exited")
 }
+
+// tracingOptionsFromConfig maps the tracing section onto tracing.InitOptions.
+// It lives here rather than in pkg/tracing so that the tracing package (which
+// servertiming and the repositories depend on) stays free of the config tree.
+func tracingOptionsFromConfig(cfg *config.Config) tracing.InitOptions {
+	tc := cfg.Tracing
+	serviceName := tc.ServiceName
+	if serviceName == "" {
+		serviceName = cfg.Log.ServiceName
+	}
+	return tracing.InitOptions{
+		Enabled:          tc.Enabled,
+		ServiceName:      serviceName,
+		ServiceVersion:   Version,
+		Environment:      cfg.Log.Environment,
+		Exporter:         tc.Exporter,
+		Endpoint:         tc.Endpoint,
+		Headers:          tc.Headers,
+		FilePath:         tc.FilePath,
+		SampleRatio:      tc.SampleRatio,
+		ExportTimeout:    time.Duration(tc.ExportTimeoutSeconds) * time.Second,
+		BatchSize:        tc.BatchSize,
+		QueueSize:        tc.QueueSize,
+		FlushInterval:    time.Duration(tc.FlushIntervalSeconds) * time.Second,
+		MaxSpansPerTrace: tc.MaxSpansPerTrace,
+	}
+}
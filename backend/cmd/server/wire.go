@@ -14,6 +14,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/secrets"
 	"github.com/Wei-Shaw/sub2api/internal/repository"
 	"github.com/Wei-Shaw/sub2api/internal/securityaudit"
 	"github.com/Wei-Shaw/sub2api/internal/server"
@@ -25,9 +26,10 @@ import (
 )
 
 type Application struct {
-	Server      *http.Server
-	PromptAudit *securityaudit.PromptService
-	Cleanup     func()
+	Server       *http.Server
+	PromptAudit  *securityaudit.PromptService
+	ConfigReload *service.ConfigReloadService
+	Cleanup      func()
 }
 
 func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
@@ -56,7 +58,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		provideCleanup,
 
 		// Application struct
-		wire.Struct(new(Application), "Server", "PromptAudit", "Cleanup"),
+		wire.Struct(new(Application), "Server", "PromptAudit", "ConfigReload", "Cleanup"),
 	)
 	return nil, nil
 }
@@ -85,6 +87,8 @@ func provideCleanup(
 	opsIngressReject *service.OpsIngressRejectAggregator,
 	apiKeyService *service.APIKeyService,
 	authCacheInvalidationWorker *service.AuthCacheInvalidationWorker,
+	userWebhookDispatcher *service.UserWebhookDispatcher,
+	openAIBatchWorker *service.OpenAIBatchWorker,
 	schedulerSnapshot *service.SchedulerSnapshotService,
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
@@ -110,6 +114,13 @@ func provideCleanup(
 	scheduledTestRunner *service.ScheduledTestRunnerService,
 	backupSvc *service.BackupService,
 	paymentOrderExpiry *service.PaymentOrderExpiryService,
+	balanceLedger *service.BalanceLedgerService,
+	invoice *service.InvoiceService,
+	usageExport *service.UsageExportService,
+	configReload *service.ConfigReloadService,
+	credentialEncryption *service.CredentialEncryptionService,
+	usageLogMoneyBackfill *service.UsageLogMoneyBackfillService,
+	secretResolver *secrets.Resolver,
 	channelMonitorRunner *service.ChannelMonitorRunner,
 	channelMonitorV2Aggregator *service.ChannelMonitorV2Aggregator,
 	quotaFlusher *service.UserPlatformQuotaUsageFlusher,
@@ -141,6 +152,14 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"UserWebhookDispatcher", func() error {
+				userWebhookDispatcher.Stop()
+				return nil
+			}},
+			{"OpenAIBatchWorker", func() error {
+				openAIBatchWorker.Stop()
+				return nil
+			}},
 			{"AuthCacheInvalidationSubscriber", func() error {
 				if apiKeyService != nil {
 					apiKeyService.StopAuthCacheInvalidationSubscriber()
@@ -327,6 +346,46 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"BalanceLedgerService", func() error {
+				if balanceLedger != nil {
+					balanceLedger.Stop()
+				}
+				return nil
+			}},
+			{"InvoiceService", func() error {
+				if invoice != nil {
+					invoice.Stop()
+				}
+				return nil
+			}},
+			{"UsageExportService", func() error {
+				if usageExport != nil {
+					usageExport.Stop()
+				}
+				return nil
+			}},
+			{"ConfigReloadService", func() error {
+				if configReload != nil {
+					configReload.Stop()
+				}
+				return nil
+			}},
+			{"CredentialEncryptionService", func() error {
+				if credentialEncryption != nil {
+					credentialEncryption.Stop()
+				}
+				return nil
+			}},
+			{"UsageLogMoneyBackfillService", func() error {
+				if usageLogMoneyBackfill != nil {
+					usageLogMoneyBackfill.Stop()
+				}
+				return nil
+			}},
+			{"SecretResolver", func() error {
+				secretResolver.Stop()
+				return nil
+			}},
 			{"ChannelMonitorV2Aggregator", func() error {
 			if channelMonitorV2Aggregator != nil {
 				channelMonitorV2Aggregator.Stop()
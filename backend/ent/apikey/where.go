@@ -160,6 +160,16 @@ func Window7dStart(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
 }
 
+// ResponseCacheEnabled applies equality check predicate on the "response_cache_enabled" field. It's identical to ResponseCacheEnabledEQ.
+func ResponseCacheEnabled(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// OrganizationID applies equality check predicate on the "organization_id" field. It's identical to OrganizationIDEQ.
+func OrganizationID(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldOrganizationID, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldCreatedAt, v))
@@ -1125,6 +1135,96 @@ func Window7dStartNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
 }
 
+// ResponseCacheEnabledEQ applies the EQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheEnabledNEQ applies the NEQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldResponseCacheEnabled, v))
+}
+
+// ModelAllowlistIsNil applies the IsNil predicate on the "model_allowlist" field.
+func ModelAllowlistIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldModelAllowlist))
+}
+
+// ModelAllowlistNotNil applies the NotNil predicate on the "model_allowlist" field.
+func ModelAllowlistNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldModelAllowlist))
+}
+
+// ModelDenylistIsNil applies the IsNil predicate on the "model_denylist" field.
+func ModelDenylistIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldModelDenylist))
+}
+
+// ModelDenylistNotNil applies the NotNil predicate on the "model_denylist" field.
+func ModelDenylistNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldModelDenylist))
+}
+
+// ModelAliasesIsNil applies the IsNil predicate on the "model_aliases" field.
+func ModelAliasesIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldModelAliases))
+}
+
+// ModelAliasesNotNil applies the NotNil predicate on the "model_aliases" field.
+func ModelAliasesNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldModelAliases))
+}
+
+// OrganizationIDEQ applies the EQ predicate on the "organization_id" field.
+func OrganizationIDEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldOrganizationID, v))
+}
+
+// OrganizationIDNEQ applies the NEQ predicate on the "organization_id" field.
+func OrganizationIDNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldOrganizationID, v))
+}
+
+// OrganizationIDIn applies the In predicate on the "organization_id" field.
+func OrganizationIDIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldOrganizationID, vs...))
+}
+
+// OrganizationIDNotIn applies the NotIn predicate on the "organization_id" field.
+func OrganizationIDNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldOrganizationID, vs...))
+}
+
+// OrganizationIDGT applies the GT predicate on the "organization_id" field.
+func OrganizationIDGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldOrganizationID, v))
+}
+
+// OrganizationIDGTE applies the GTE predicate on the "organization_id" field.
+func OrganizationIDGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldOrganizationID, v))
+}
+
+// OrganizationIDLT applies the LT predicate on the "organization_id" field.
+func OrganizationIDLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldOrganizationID, v))
+}
+
+// OrganizationIDLTE applies the LTE predicate on the "organization_id" field.
+func OrganizationIDLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldOrganizationID, v))
+}
+
+// OrganizationIDIsNil applies the IsNil predicate on the "organization_id" field.
+func OrganizationIDIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldOrganizationID))
+}
+
+// OrganizationIDNotNil applies the NotNil predicate on the "organization_id" field.
+func OrganizationIDNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldOrganizationID))
+}
+
 // HasUser applies the HasEdge predicate on the "user" edge.
 func HasUser() predicate.APIKey {
 	return predicate.APIKey(func(s *sql.Selector) {
@@ -307,6 +307,52 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_c *APIKeyCreate) SetResponseCacheEnabled(v bool) *APIKeyCreate {
+	_c.mutation.SetResponseCacheEnabled(v)
+	return _c
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableResponseCacheEnabled(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetResponseCacheEnabled(*v)
+	}
+	return _c
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (_c *APIKeyCreate) SetModelAllowlist(v []string) *APIKeyCreate {
+	_c.mutation.SetModelAllowlist(v)
+	return _c
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (_c *APIKeyCreate) SetModelDenylist(v []string) *APIKeyCreate {
+	_c.mutation.SetModelDenylist(v)
+	return _c
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (_c *APIKeyCreate) SetModelAliases(v map[string]string) *APIKeyCreate {
+	_c.mutation.SetModelAliases(v)
+	return _c
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (_c *APIKeyCreate) SetOrganizationID(v int64) *APIKeyCreate {
+	_c.mutation.SetOrganizationID(v)
+	return _c
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableOrganizationID(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetOrganizationID(*v)
+	}
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -419,6 +465,10 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUsage7d
 		_c.mutation.SetUsage7d(v)
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		v := apikey.DefaultResponseCacheEnabled
+		_c.mutation.SetResponseCacheEnabled(v)
+	}
 	return nil
 }
 
@@ -481,6 +531,9 @@ func (_c *APIKeyCreate) check() error {
 	if _, ok := _c.mutation.Usage7d(); !ok {
 		return &ValidationError{Name: "usage_7d", err: errors.New(`ent: missing required field "APIKey.usage_7d"`)}
 	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		return &ValidationError{Name: "response_cache_enabled", err: errors.New(`ent: missing required field "APIKey.response_cache_enabled"`)}
+	}
 	if len(_c.mutation.UserIDs()) == 0 {
 		return &ValidationError{Name: "user", err: errors.New(`ent: missing required edge "APIKey.user"`)}
 	}
@@ -595,6 +648,26 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(apikey.FieldResponseCacheEnabled, field.TypeBool, value)
+		_node.ResponseCacheEnabled = value
+	}
+	if value, ok := _c.mutation.ModelAllowlist(); ok {
+		_spec.SetField(apikey.FieldModelAllowlist, field.TypeJSON, value)
+		_node.ModelAllowlist = value
+	}
+	if value, ok := _c.mutation.ModelDenylist(); ok {
+		_spec.SetField(apikey.FieldModelDenylist, field.TypeJSON, value)
+		_node.ModelDenylist = value
+	}
+	if value, ok := _c.mutation.ModelAliases(); ok {
+		_spec.SetField(apikey.FieldModelAliases, field.TypeJSON, value)
+		_node.ModelAliases = value
+	}
+	if value, ok := _c.mutation.OrganizationID(); ok {
+		_spec.SetField(apikey.FieldOrganizationID, field.TypeInt64, value)
+		_node.OrganizationID = &value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1063,6 +1136,72 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *APIKeyUpsert) SetResponseCacheEnabled(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldResponseCacheEnabled, v)
+	return u
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateResponseCacheEnabled() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldResponseCacheEnabled)
+	return u
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (u *APIKeyUpsert) SetModelAllowlist(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldModelAllowlist, v)
+	return u
+}
+
+// UpdateModelAllowlist sets the "model_allowlist" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateModelAllowlist() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldModelAllowlist)
+	return u
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (u *APIKeyUpsert) ClearModelAllowlist() *APIKeyUpsert {
+	u.SetNull(apikey.FieldModelAllowlist)
+	return u
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (u *APIKeyUpsert) SetModelDenylist(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldModelDenylist, v)
+	return u
+}
+
+// UpdateModelDenylist sets the "model_denylist" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateModelDenylist() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldModelDenylist)
+	return u
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (u *APIKeyUpsert) ClearModelDenylist() *APIKeyUpsert {
+	u.SetNull(apikey.FieldModelDenylist)
+	return u
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (u *APIKeyUpsert) SetModelAliases(v map[string]string) *APIKeyUpsert {
+	u.Set(apikey.FieldModelAliases, v)
+	return u
+}
+
+// UpdateModelAliases sets the "model_aliases" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateModelAliases() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldModelAliases)
+	return u
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (u *APIKeyUpsert) ClearModelAliases() *APIKeyUpsert {
+	u.SetNull(apikey.FieldModelAliases)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1077,6 +1216,9 @@ func (u *APIKeyUpsertOne) UpdateNewValues() *APIKeyUpsertOne {
 		if _, exists := u.create.mutation.CreatedAt(); exists {
 			s.SetIgnore(apikey.FieldCreatedAt)
 		}
+		if _, exists := u.create.mutation.OrganizationID(); exists {
+			s.SetIgnore(apikey.FieldOrganizationID)
+		}
 	}))
 	return u
 }
@@ -1535,6 +1677,83 @@ func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
 	})
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *APIKeyUpsertOne) SetResponseCacheEnabled(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateResponseCacheEnabled() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (u *APIKeyUpsertOne) SetModelAllowlist(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModelAllowlist(v)
+	})
+}
+
+// UpdateModelAllowlist sets the "model_allowlist" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateModelAllowlist() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModelAllowlist()
+	})
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (u *APIKeyUpsertOne) ClearModelAllowlist() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearModelAllowlist()
+	})
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (u *APIKeyUpsertOne) SetModelDenylist(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModelDenylist(v)
+	})
+}
+
+// UpdateModelDenylist sets the "model_denylist" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateModelDenylist() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModelDenylist()
+	})
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (u *APIKeyUpsertOne) ClearModelDenylist() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearModelDenylist()
+	})
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (u *APIKeyUpsertOne) SetModelAliases(v map[string]string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModelAliases(v)
+	})
+}
+
+// UpdateModelAliases sets the "model_aliases" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateModelAliases() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModelAliases()
+	})
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (u *APIKeyUpsertOne) ClearModelAliases() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearModelAliases()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1714,6 +1933,9 @@ func (u *APIKeyUpsertBulk) UpdateNewValues() *APIKeyUpsertBulk {
 			if _, exists := b.mutation.CreatedAt(); exists {
 				s.SetIgnore(apikey.FieldCreatedAt)
 			}
+			if _, exists := b.mutation.OrganizationID(); exists {
+				s.SetIgnore(apikey.FieldOrganizationID)
+			}
 		}
 	}))
 	return u
@@ -2173,6 +2395,83 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *APIKeyUpsertBulk) SetResponseCacheEnabled(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateResponseCacheEnabled() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (u *APIKeyUpsertBulk) SetModelAllowlist(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModelAllowlist(v)
+	})
+}
+
+// UpdateModelAllowlist sets the "model_allowlist" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateModelAllowlist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModelAllowlist()
+	})
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (u *APIKeyUpsertBulk) ClearModelAllowlist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearModelAllowlist()
+	})
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (u *APIKeyUpsertBulk) SetModelDenylist(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModelDenylist(v)
+	})
+}
+
+// UpdateModelDenylist sets the "model_denylist" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateModelDenylist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModelDenylist()
+	})
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (u *APIKeyUpsertBulk) ClearModelDenylist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearModelDenylist()
+	})
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (u *APIKeyUpsertBulk) SetModelAliases(v map[string]string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModelAliases(v)
+	})
+}
+
+// UpdateModelAliases sets the "model_aliases" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateModelAliases() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModelAliases()
+	})
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (u *APIKeyUpsertBulk) ClearModelAliases() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearModelAliases()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
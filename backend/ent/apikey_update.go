@@ -438,6 +438,68 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *APIKeyUpdate) SetResponseCacheEnabled(v bool) *APIKeyUpdate {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableResponseCacheEnabled(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (_u *APIKeyUpdate) SetModelAllowlist(v []string) *APIKeyUpdate {
+	_u.mutation.SetModelAllowlist(v)
+	return _u
+}
+
+// AppendModelAllowlist appends value to the "model_allowlist" field.
+func (_u *APIKeyUpdate) AppendModelAllowlist(v []string) *APIKeyUpdate {
+	_u.mutation.AppendModelAllowlist(v)
+	return _u
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (_u *APIKeyUpdate) ClearModelAllowlist() *APIKeyUpdate {
+	_u.mutation.ClearModelAllowlist()
+	return _u
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (_u *APIKeyUpdate) SetModelDenylist(v []string) *APIKeyUpdate {
+	_u.mutation.SetModelDenylist(v)
+	return _u
+}
+
+// AppendModelDenylist appends value to the "model_denylist" field.
+func (_u *APIKeyUpdate) AppendModelDenylist(v []string) *APIKeyUpdate {
+	_u.mutation.AppendModelDenylist(v)
+	return _u
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (_u *APIKeyUpdate) ClearModelDenylist() *APIKeyUpdate {
+	_u.mutation.ClearModelDenylist()
+	return _u
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (_u *APIKeyUpdate) SetModelAliases(v map[string]string) *APIKeyUpdate {
+	_u.mutation.SetModelAliases(v)
+	return _u
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (_u *APIKeyUpdate) ClearModelAliases() *APIKeyUpdate {
+	_u.mutation.ClearModelAliases()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -696,6 +758,40 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(apikey.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ModelAllowlist(); ok {
+		_spec.SetField(apikey.FieldModelAllowlist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelAllowlist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelAllowlist, value)
+		})
+	}
+	if _u.mutation.ModelAllowlistCleared() {
+		_spec.ClearField(apikey.FieldModelAllowlist, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ModelDenylist(); ok {
+		_spec.SetField(apikey.FieldModelDenylist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelDenylist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelDenylist, value)
+		})
+	}
+	if _u.mutation.ModelDenylistCleared() {
+		_spec.ClearField(apikey.FieldModelDenylist, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ModelAliases(); ok {
+		_spec.SetField(apikey.FieldModelAliases, field.TypeJSON, value)
+	}
+	if _u.mutation.ModelAliasesCleared() {
+		_spec.ClearField(apikey.FieldModelAliases, field.TypeJSON)
+	}
+	if _u.mutation.OrganizationIDCleared() {
+		_spec.ClearField(apikey.FieldOrganizationID, field.TypeInt64)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -1225,6 +1321,68 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *APIKeyUpdateOne) SetResponseCacheEnabled(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableResponseCacheEnabled(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (_u *APIKeyUpdateOne) SetModelAllowlist(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetModelAllowlist(v)
+	return _u
+}
+
+// AppendModelAllowlist appends value to the "model_allowlist" field.
+func (_u *APIKeyUpdateOne) AppendModelAllowlist(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendModelAllowlist(v)
+	return _u
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (_u *APIKeyUpdateOne) ClearModelAllowlist() *APIKeyUpdateOne {
+	_u.mutation.ClearModelAllowlist()
+	return _u
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (_u *APIKeyUpdateOne) SetModelDenylist(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetModelDenylist(v)
+	return _u
+}
+
+// AppendModelDenylist appends value to the "model_denylist" field.
+func (_u *APIKeyUpdateOne) AppendModelDenylist(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendModelDenylist(v)
+	return _u
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (_u *APIKeyUpdateOne) ClearModelDenylist() *APIKeyUpdateOne {
+	_u.mutation.ClearModelDenylist()
+	return _u
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (_u *APIKeyUpdateOne) SetModelAliases(v map[string]string) *APIKeyUpdateOne {
+	_u.mutation.SetModelAliases(v)
+	return _u
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (_u *APIKeyUpdateOne) ClearModelAliases() *APIKeyUpdateOne {
+	_u.mutation.ClearModelAliases()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1513,6 +1671,40 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(apikey.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ModelAllowlist(); ok {
+		_spec.SetField(apikey.FieldModelAllowlist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelAllowlist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelAllowlist, value)
+		})
+	}
+	if _u.mutation.ModelAllowlistCleared() {
+		_spec.ClearField(apikey.FieldModelAllowlist, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ModelDenylist(); ok {
+		_spec.SetField(apikey.FieldModelDenylist, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelDenylist(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldModelDenylist, value)
+		})
+	}
+	if _u.mutation.ModelDenylistCleared() {
+		_spec.ClearField(apikey.FieldModelDenylist, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ModelAliases(); ok {
+		_spec.SetField(apikey.FieldModelAliases, field.TypeJSON, value)
+	}
+	if _u.mutation.ModelAliasesCleared() {
+		_spec.ClearField(apikey.FieldModelAliases, field.TypeJSON)
+	}
+	if _u.mutation.OrganizationIDCleared() {
+		_spec.ClearField(apikey.FieldOrganizationID, field.TypeInt64)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
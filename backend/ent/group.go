@@ -4,6 +4,7 @@ package ent
 
 import (
 	"encoding/json"
+	"encoding/json/jsontext"
 	"fmt"
 	"strings"
 	"time"
@@ -75,6 +76,20 @@ type Group struct {
 	BatchImageDiscountMultiplier float64 `json:"batch_image_discount_multiplier,omitempty"`
 	// 批量图片生成冻结价格比例，按普通生图原价乘以该比例冻结，结算后释放差额
 	BatchImageHoldMultiplier float64 `json:"batch_image_hold_multiplier,omitempty"`
+	// 是否允许该分组使用 OpenAI 兼容 Batch API（/v1/batches、/v1/files）
+	AllowBatchAPI bool `json:"allow_batch_api,omitempty"`
+	// Batch API 折扣倍率，在分组有效倍率之上再乘以该值；0 表示免费
+	BatchAPIDiscountMultiplier float64 `json:"batch_api_discount_multiplier,omitempty"`
+	// Batch API 冻结价格比例，按原价预估（含 max_tokens 上限）乘以该比例冻结，结算后释放差额
+	BatchAPIHoldMultiplier float64 `json:"batch_api_hold_multiplier,omitempty"`
+	// 是否对该分组启用精确匹配响应缓存（/v1/messages、/v1/chat/completions、/v1/responses）
+	ResponseCacheEnabled bool `json:"response_cache_enabled,omitempty"`
+	// 响应缓存 TTL（秒），0 表示使用全局默认值
+	ResponseCacheTTLSeconds int `json:"response_cache_ttl_seconds,omitempty"`
+	// 缓存命中计费倍率，在分组有效倍率之上再乘以该值；0 表示命中免费
+	ResponseCachePriceMultiplier float64 `json:"response_cache_price_multiplier,omitempty"`
+	// 账号调度策略：空或 default 沿用平台默认调度，另可选 least_latency、cost_aware、weighted_round_robin
+	SchedulingStrategy string `json:"scheduling_strategy,omitempty"`
 	// 视频生成是否使用独立倍率；false 表示共享分组有效倍率
 	VideoRateIndependent bool `json:"video_rate_independent,omitempty"`
 	// 视频生成独立倍率，仅 video_rate_independent=true 时生效
@@ -89,6 +104,8 @@ type Group struct {
 	VideoModelPrices map[string]map[string]float64 `json:"video_model_prices,omitempty"`
 	// Codex alpha/search 网页搜索单次价格（USD/次）；nil 表示使用默认价 0.01（官方 $10/1000 次）
 	WebSearchPricePerCall *float64 `json:"web_search_price_per_call,omitempty"`
+	// 网关模拟 web_fetch 工具的单次抓取价格（USD/次）；nil 表示使用默认价 0.01
+	WebFetchPricePerCall *float64 `json:"web_fetch_price_per_call,omitempty"`
 	// 搜索工具价格 per 1000 calls（web_search 等）
 	SearchPricePer1k *float64 `json:"search_price_per_1k,omitempty"`
 	// Voice realtime 每分钟价格（USD）
@@ -100,7 +117,7 @@ type Group struct {
 	// 是否按上下文长度应用模型阶梯价格；默认开启以保持官方/渠道长上下文价
 	LongContextPricingEnabled bool `json:"long_context_pricing_enabled,omitempty"`
 	// 分组逐模型定价；优先级高于渠道和内置定价
-	ModelPricing json.RawMessage `json:"model_pricing,omitempty"`
+	ModelPricing jsontext.Value `json:"model_pricing,omitempty"`
 	// 是否仅允许 Claude Code 客户端
 	ClaudeCodeOnly bool `json:"claude_code_only,omitempty"`
 	// 非 Claude Code 请求降级使用的分组 ID
@@ -251,13 +268,13 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case group.FieldVideoModelPrices, group.FieldModelPricing, group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldModelsListConfig, group.FieldReasoningEffortMappings:
 			values[i] = new([]byte)
-		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldVideoRateIndependent, group.FieldLongContextPricingEnabled, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldAllowLive, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldProfitControlEnabled:
+		case group.FieldPeakRateEnabled, group.FieldIsExclusive, group.FieldAllowImageGeneration, group.FieldAllowBatchImageGeneration, group.FieldImageRateIndependent, group.FieldAllowBatchAPI, group.FieldResponseCacheEnabled, group.FieldVideoRateIndependent, group.FieldLongContextPricingEnabled, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldAllowLive, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldProfitControlEnabled:
 			values[i] = new(sql.NullBool)
-		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldWebSearchPricePerCall, group.FieldSearchPricePer1k, group.FieldAudioRealtimePricePerMin, group.FieldAudioTtsPricePerMillionChars, group.FieldAudioSttPricePerHour, group.FieldProfitMinMargin, group.FieldProfitSafetyBuffer:
+		case group.FieldRateMultiplier, group.FieldPeakRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImageRateMultiplier, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldBatchImageDiscountMultiplier, group.FieldBatchImageHoldMultiplier, group.FieldBatchAPIDiscountMultiplier, group.FieldBatchAPIHoldMultiplier, group.FieldResponseCachePriceMultiplier, group.FieldVideoRateMultiplier, group.FieldVideoPrice480p, group.FieldVideoPrice720p, group.FieldVideoPrice1080p, group.FieldWebSearchPricePerCall, group.FieldWebFetchPricePerCall, group.FieldSearchPricePer1k, group.FieldAudioRealtimePricePerMin, group.FieldAudioTtsPricePerMillionChars, group.FieldAudioSttPricePerHour, group.FieldProfitMinMargin, group.FieldProfitSafetyBuffer:
 			values[i] = new(sql.NullFloat64)
-		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
+		case group.FieldID, group.FieldDefaultValidityDays, group.FieldResponseCacheTTLSeconds, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldDuplicateOperationID, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldMaxReasoningEffort:
+		case group.FieldName, group.FieldDescription, group.FieldPeakStart, group.FieldPeakEnd, group.FieldStatus, group.FieldDuplicateOperationID, group.FieldPlatform, group.FieldSubscriptionType, group.FieldSchedulingStrategy, group.FieldDefaultMappedModel, group.FieldMaxReasoningEffort:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -459,6 +476,48 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.BatchImageHoldMultiplier = value.Float64
 			}
+		case group.FieldAllowBatchAPI:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field allow_batch_api", values[i])
+			} else if value.Valid {
+				_m.AllowBatchAPI = value.Bool
+			}
+		case group.FieldBatchAPIDiscountMultiplier:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field batch_api_discount_multiplier", values[i])
+			} else if value.Valid {
+				_m.BatchAPIDiscountMultiplier = value.Float64
+			}
+		case group.FieldBatchAPIHoldMultiplier:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field batch_api_hold_multiplier", values[i])
+			} else if value.Valid {
+				_m.BatchAPIHoldMultiplier = value.Float64
+			}
+		case group.FieldResponseCacheEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_enabled", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheEnabled = value.Bool
+			}
+		case group.FieldResponseCacheTTLSeconds:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_ttl_seconds", values[i])
+			} else if value.Valid {
+				_m.ResponseCacheTTLSeconds = int(value.Int64)
+			}
+		case group.FieldResponseCachePriceMultiplier:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache_price_multiplier", values[i])
+			} else if value.Valid {
+				_m.ResponseCachePriceMultiplier = value.Float64
+			}
+		case group.FieldSchedulingStrategy:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field scheduling_strategy", values[i])
+			} else if value.Valid {
+				_m.SchedulingStrategy = value.String
+			}
 		case group.FieldVideoRateIndependent:
 			if value, ok := values[i].(*sql.NullBool); !ok {
 				return fmt.Errorf("unexpected type %T for field video_rate_independent", values[i])
@@ -507,6 +566,13 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 				_m.WebSearchPricePerCall = new(float64)
 				*_m.WebSearchPricePerCall = value.Float64
 			}
+		case group.FieldWebFetchPricePerCall:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field web_fetch_price_per_call", values[i])
+			} else if value.Valid {
+				_m.WebFetchPricePerCall = new(float64)
+				*_m.WebFetchPricePerCall = value.Float64
+			}
 		case group.FieldSearchPricePer1k:
 			if value, ok := values[i].(*sql.NullFloat64); !ok {
 				return fmt.Errorf("unexpected type %T for field search_price_per_1k", values[i])
@@ -865,6 +931,27 @@ func (_m *Group) String() string {
 	builder.WriteString("batch_image_hold_multiplier=")
 	builder.WriteString(fmt.Sprintf("%v", _m.BatchImageHoldMultiplier))
 	builder.WriteString(", ")
+	builder.WriteString("allow_batch_api=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowBatchAPI))
+	builder.WriteString(", ")
+	builder.WriteString("batch_api_discount_multiplier=")
+	builder.WriteString(fmt.Sprintf("%v", _m.BatchAPIDiscountMultiplier))
+	builder.WriteString(", ")
+	builder.WriteString("batch_api_hold_multiplier=")
+	builder.WriteString(fmt.Sprintf("%v", _m.BatchAPIHoldMultiplier))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_ttl_seconds=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCacheTTLSeconds))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache_price_multiplier=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCachePriceMultiplier))
+	builder.WriteString(", ")
+	builder.WriteString("scheduling_strategy=")
+	builder.WriteString(_m.SchedulingStrategy)
+	builder.WriteString(", ")
 	builder.WriteString("video_rate_independent=")
 	builder.WriteString(fmt.Sprintf("%v", _m.VideoRateIndependent))
 	builder.WriteString(", ")
@@ -894,6 +981,11 @@ func (_m *Group) String() string {
 		builder.WriteString(fmt.Sprintf("%v", *v))
 	}
 	builder.WriteString(", ")
+	if v := _m.WebFetchPricePerCall; v != nil {
+		builder.WriteString("web_fetch_price_per_call=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
 	if v := _m.SearchPricePer1k; v != nil {
 		builder.WriteString("search_price_per_1k=")
 		builder.WriteString(fmt.Sprintf("%v", *v))
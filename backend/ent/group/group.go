@@ -72,6 +72,20 @@ const (
 	FieldBatchImageDiscountMultiplier = "batch_image_discount_multiplier"
 	// FieldBatchImageHoldMultiplier holds the string denoting the batch_image_hold_multiplier field in the database.
 	FieldBatchImageHoldMultiplier = "batch_image_hold_multiplier"
+	// FieldAllowBatchAPI holds the string denoting the allow_batch_api field in the database.
+	FieldAllowBatchAPI = "allow_batch_api"
+	// FieldBatchAPIDiscountMultiplier holds the string denoting the batch_api_discount_multiplier field in the database.
+	FieldBatchAPIDiscountMultiplier = "batch_api_discount_multiplier"
+	// FieldBatchAPIHoldMultiplier holds the string denoting the batch_api_hold_multiplier field in the database.
+	FieldBatchAPIHoldMultiplier = "batch_api_hold_multiplier"
+	// FieldResponseCacheEnabled holds the string denoting the response_cache_enabled field in the database.
+	FieldResponseCacheEnabled = "response_cache_enabled"
+	// FieldResponseCacheTTLSeconds holds the string denoting the response_cache_ttl_seconds field in the database.
+	FieldResponseCacheTTLSeconds = "response_cache_ttl_seconds"
+	// FieldResponseCachePriceMultiplier holds the string denoting the response_cache_price_multiplier field in the database.
+	FieldResponseCachePriceMultiplier = "response_cache_price_multiplier"
+	// FieldSchedulingStrategy holds the string denoting the scheduling_strategy field in the database.
+	FieldSchedulingStrategy = "scheduling_strategy"
 	// FieldVideoRateIndependent holds the string denoting the video_rate_independent field in the database.
 	FieldVideoRateIndependent = "video_rate_independent"
 	// FieldVideoRateMultiplier holds the string denoting the video_rate_multiplier field in the database.
@@ -86,6 +100,8 @@ const (
 	FieldVideoModelPrices = "video_model_prices"
 	// FieldWebSearchPricePerCall holds the string denoting the web_search_price_per_call field in the database.
 	FieldWebSearchPricePerCall = "web_search_price_per_call"
+	// FieldWebFetchPricePerCall holds the string denoting the web_fetch_price_per_call field in the database.
+	FieldWebFetchPricePerCall = "web_fetch_price_per_call"
 	// FieldSearchPricePer1k holds the string denoting the search_price_per_1k field in the database.
 	FieldSearchPricePer1k = "search_price_per_1k"
 	// FieldAudioRealtimePricePerMin holds the string denoting the audio_realtime_price_per_min field in the database.
@@ -243,6 +259,13 @@ var Columns = []string{
 	FieldImagePrice4k,
 	FieldBatchImageDiscountMultiplier,
 	FieldBatchImageHoldMultiplier,
+	FieldAllowBatchAPI,
+	FieldBatchAPIDiscountMultiplier,
+	FieldBatchAPIHoldMultiplier,
+	FieldResponseCacheEnabled,
+	FieldResponseCacheTTLSeconds,
+	FieldResponseCachePriceMultiplier,
+	FieldSchedulingStrategy,
 	FieldVideoRateIndependent,
 	FieldVideoRateMultiplier,
 	FieldVideoPrice480p,
@@ -250,6 +273,7 @@ var Columns = []string{
 	FieldVideoPrice1080p,
 	FieldVideoModelPrices,
 	FieldWebSearchPricePerCall,
+	FieldWebFetchPricePerCall,
 	FieldSearchPricePer1k,
 	FieldAudioRealtimePricePerMin,
 	FieldAudioTtsPricePerMillionChars,
@@ -358,6 +382,22 @@ var (
 	DefaultBatchImageDiscountMultiplier float64
 	// DefaultBatchImageHoldMultiplier holds the default value on creation for the "batch_image_hold_multiplier" field.
 	DefaultBatchImageHoldMultiplier float64
+	// DefaultAllowBatchAPI holds the default value on creation for the "allow_batch_api" field.
+	DefaultAllowBatchAPI bool
+	// DefaultBatchAPIDiscountMultiplier holds the default value on creation for the "batch_api_discount_multiplier" field.
+	DefaultBatchAPIDiscountMultiplier float64
+	// DefaultBatchAPIHoldMultiplier holds the default value on creation for the "batch_api_hold_multiplier" field.
+	DefaultBatchAPIHoldMultiplier float64
+	// DefaultResponseCacheEnabled holds the default value on creation for the "response_cache_enabled" field.
+	DefaultResponseCacheEnabled bool
+	// DefaultResponseCacheTTLSeconds holds the default value on creation for the "response_cache_ttl_seconds" field.
+	DefaultResponseCacheTTLSeconds int
+	// DefaultResponseCachePriceMultiplier holds the default value on creation for the "response_cache_price_multiplier" field.
+	DefaultResponseCachePriceMultiplier float64
+	// DefaultSchedulingStrategy holds the default value on creation for the "scheduling_strategy" field.
+	DefaultSchedulingStrategy string
+	// SchedulingStrategyValidator is a validator for the "scheduling_strategy" field. It is called by the builders before save.
+	SchedulingStrategyValidator func(string) error
 	// DefaultVideoRateIndependent holds the default value on creation for the "video_rate_independent" field.
 	DefaultVideoRateIndependent bool
 	// DefaultVideoRateMultiplier holds the default value on creation for the "video_rate_multiplier" field.
@@ -562,6 +602,41 @@ func ByBatchImageHoldMultiplier(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldBatchImageHoldMultiplier, opts...).ToFunc()
 }
 
+// ByAllowBatchAPI orders the results by the allow_batch_api field.
+func ByAllowBatchAPI(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAllowBatchAPI, opts...).ToFunc()
+}
+
+// ByBatchAPIDiscountMultiplier orders the results by the batch_api_discount_multiplier field.
+func ByBatchAPIDiscountMultiplier(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBatchAPIDiscountMultiplier, opts...).ToFunc()
+}
+
+// ByBatchAPIHoldMultiplier orders the results by the batch_api_hold_multiplier field.
+func ByBatchAPIHoldMultiplier(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBatchAPIHoldMultiplier, opts...).ToFunc()
+}
+
+// ByResponseCacheEnabled orders the results by the response_cache_enabled field.
+func ByResponseCacheEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheEnabled, opts...).ToFunc()
+}
+
+// ByResponseCacheTTLSeconds orders the results by the response_cache_ttl_seconds field.
+func ByResponseCacheTTLSeconds(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCacheTTLSeconds, opts...).ToFunc()
+}
+
+// ByResponseCachePriceMultiplier orders the results by the response_cache_price_multiplier field.
+func ByResponseCachePriceMultiplier(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldResponseCachePriceMultiplier, opts...).ToFunc()
+}
+
+// BySchedulingStrategy orders the results by the scheduling_strategy field.
+func BySchedulingStrategy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSchedulingStrategy, opts...).ToFunc()
+}
+
 // ByVideoRateIndependent orders the results by the video_rate_independent field.
 func ByVideoRateIndependent(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldVideoRateIndependent, opts...).ToFunc()
@@ -592,6 +667,11 @@ func ByWebSearchPricePerCall(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldWebSearchPricePerCall, opts...).ToFunc()
 }
 
+// ByWebFetchPricePerCall orders the results by the web_fetch_price_per_call field.
+func ByWebFetchPricePerCall(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldWebFetchPricePerCall, opts...).ToFunc()
+}
+
 // BySearchPricePer1k orders the results by the search_price_per_1k field.
 func BySearchPricePer1k(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldSearchPricePer1k, opts...).ToFunc()
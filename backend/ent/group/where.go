@@ -195,6 +195,41 @@ func BatchImageHoldMultiplier(v float64) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldBatchImageHoldMultiplier, v))
 }
 
+// AllowBatchAPI applies equality check predicate on the "allow_batch_api" field. It's identical to AllowBatchAPIEQ.
+func AllowBatchAPI(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowBatchAPI, v))
+}
+
+// BatchAPIDiscountMultiplier applies equality check predicate on the "batch_api_discount_multiplier" field. It's identical to BatchAPIDiscountMultiplierEQ.
+func BatchAPIDiscountMultiplier(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIHoldMultiplier applies equality check predicate on the "batch_api_hold_multiplier" field. It's identical to BatchAPIHoldMultiplierEQ.
+func BatchAPIHoldMultiplier(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldBatchAPIHoldMultiplier, v))
+}
+
+// ResponseCacheEnabled applies equality check predicate on the "response_cache_enabled" field. It's identical to ResponseCacheEnabledEQ.
+func ResponseCacheEnabled(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheTTLSeconds applies equality check predicate on the "response_cache_ttl_seconds" field. It's identical to ResponseCacheTTLSecondsEQ.
+func ResponseCacheTTLSeconds(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCachePriceMultiplier applies equality check predicate on the "response_cache_price_multiplier" field. It's identical to ResponseCachePriceMultiplierEQ.
+func ResponseCachePriceMultiplier(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCachePriceMultiplier, v))
+}
+
+// SchedulingStrategy applies equality check predicate on the "scheduling_strategy" field. It's identical to SchedulingStrategyEQ.
+func SchedulingStrategy(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSchedulingStrategy, v))
+}
+
 // VideoRateIndependent applies equality check predicate on the "video_rate_independent" field. It's identical to VideoRateIndependentEQ.
 func VideoRateIndependent(v bool) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldVideoRateIndependent, v))
@@ -225,6 +260,11 @@ func WebSearchPricePerCall(v float64) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldWebSearchPricePerCall, v))
 }
 
+// WebFetchPricePerCall applies equality check predicate on the "web_fetch_price_per_call" field. It's identical to WebFetchPricePerCallEQ.
+func WebFetchPricePerCall(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldWebFetchPricePerCall, v))
+}
+
 // SearchPricePer1k applies equality check predicate on the "search_price_per_1k" field. It's identical to SearchPricePer1kEQ.
 func SearchPricePer1k(v float64) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldSearchPricePer1k, v))
@@ -1590,6 +1630,251 @@ func BatchImageHoldMultiplierLTE(v float64) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldBatchImageHoldMultiplier, v))
 }
 
+// AllowBatchAPIEQ applies the EQ predicate on the "allow_batch_api" field.
+func AllowBatchAPIEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAllowBatchAPI, v))
+}
+
+// AllowBatchAPINEQ applies the NEQ predicate on the "allow_batch_api" field.
+func AllowBatchAPINEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAllowBatchAPI, v))
+}
+
+// BatchAPIDiscountMultiplierEQ applies the EQ predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIDiscountMultiplierNEQ applies the NEQ predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIDiscountMultiplierIn applies the In predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldBatchAPIDiscountMultiplier, vs...))
+}
+
+// BatchAPIDiscountMultiplierNotIn applies the NotIn predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldBatchAPIDiscountMultiplier, vs...))
+}
+
+// BatchAPIDiscountMultiplierGT applies the GT predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIDiscountMultiplierGTE applies the GTE predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIDiscountMultiplierLT applies the LT predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIDiscountMultiplierLTE applies the LTE predicate on the "batch_api_discount_multiplier" field.
+func BatchAPIDiscountMultiplierLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldBatchAPIDiscountMultiplier, v))
+}
+
+// BatchAPIHoldMultiplierEQ applies the EQ predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldBatchAPIHoldMultiplier, v))
+}
+
+// BatchAPIHoldMultiplierNEQ applies the NEQ predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldBatchAPIHoldMultiplier, v))
+}
+
+// BatchAPIHoldMultiplierIn applies the In predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldBatchAPIHoldMultiplier, vs...))
+}
+
+// BatchAPIHoldMultiplierNotIn applies the NotIn predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldBatchAPIHoldMultiplier, vs...))
+}
+
+// BatchAPIHoldMultiplierGT applies the GT predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldBatchAPIHoldMultiplier, v))
+}
+
+// BatchAPIHoldMultiplierGTE applies the GTE predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldBatchAPIHoldMultiplier, v))
+}
+
+// BatchAPIHoldMultiplierLT applies the LT predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldBatchAPIHoldMultiplier, v))
+}
+
+// BatchAPIHoldMultiplierLTE applies the LTE predicate on the "batch_api_hold_multiplier" field.
+func BatchAPIHoldMultiplierLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldBatchAPIHoldMultiplier, v))
+}
+
+// ResponseCacheEnabledEQ applies the EQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheEnabledNEQ applies the NEQ predicate on the "response_cache_enabled" field.
+func ResponseCacheEnabledNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheEnabled, v))
+}
+
+// ResponseCacheTTLSecondsEQ applies the EQ predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsNEQ applies the NEQ predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsIn applies the In predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseCacheTTLSeconds, vs...))
+}
+
+// ResponseCacheTTLSecondsNotIn applies the NotIn predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseCacheTTLSeconds, vs...))
+}
+
+// ResponseCacheTTLSecondsGT applies the GT predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsGTE applies the GTE predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsLT applies the LT predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCacheTTLSecondsLTE applies the LTE predicate on the "response_cache_ttl_seconds" field.
+func ResponseCacheTTLSecondsLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseCacheTTLSeconds, v))
+}
+
+// ResponseCachePriceMultiplierEQ applies the EQ predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldResponseCachePriceMultiplier, v))
+}
+
+// ResponseCachePriceMultiplierNEQ applies the NEQ predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldResponseCachePriceMultiplier, v))
+}
+
+// ResponseCachePriceMultiplierIn applies the In predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldResponseCachePriceMultiplier, vs...))
+}
+
+// ResponseCachePriceMultiplierNotIn applies the NotIn predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldResponseCachePriceMultiplier, vs...))
+}
+
+// ResponseCachePriceMultiplierGT applies the GT predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldResponseCachePriceMultiplier, v))
+}
+
+// ResponseCachePriceMultiplierGTE applies the GTE predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldResponseCachePriceMultiplier, v))
+}
+
+// ResponseCachePriceMultiplierLT applies the LT predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldResponseCachePriceMultiplier, v))
+}
+
+// ResponseCachePriceMultiplierLTE applies the LTE predicate on the "response_cache_price_multiplier" field.
+func ResponseCachePriceMultiplierLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldResponseCachePriceMultiplier, v))
+}
+
+// SchedulingStrategyEQ applies the EQ predicate on the "scheduling_strategy" field.
+func SchedulingStrategyEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyNEQ applies the NEQ predicate on the "scheduling_strategy" field.
+func SchedulingStrategyNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyIn applies the In predicate on the "scheduling_strategy" field.
+func SchedulingStrategyIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSchedulingStrategy, vs...))
+}
+
+// SchedulingStrategyNotIn applies the NotIn predicate on the "scheduling_strategy" field.
+func SchedulingStrategyNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSchedulingStrategy, vs...))
+}
+
+// SchedulingStrategyGT applies the GT predicate on the "scheduling_strategy" field.
+func SchedulingStrategyGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyGTE applies the GTE predicate on the "scheduling_strategy" field.
+func SchedulingStrategyGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyLT applies the LT predicate on the "scheduling_strategy" field.
+func SchedulingStrategyLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyLTE applies the LTE predicate on the "scheduling_strategy" field.
+func SchedulingStrategyLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyContains applies the Contains predicate on the "scheduling_strategy" field.
+func SchedulingStrategyContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyHasPrefix applies the HasPrefix predicate on the "scheduling_strategy" field.
+func SchedulingStrategyHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyHasSuffix applies the HasSuffix predicate on the "scheduling_strategy" field.
+func SchedulingStrategyHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyEqualFold applies the EqualFold predicate on the "scheduling_strategy" field.
+func SchedulingStrategyEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyContainsFold applies the ContainsFold predicate on the "scheduling_strategy" field.
+func SchedulingStrategyContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldSchedulingStrategy, v))
+}
+
 // VideoRateIndependentEQ applies the EQ predicate on the "video_rate_independent" field.
 func VideoRateIndependentEQ(v bool) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldVideoRateIndependent, v))
@@ -1850,6 +2135,56 @@ func WebSearchPricePerCallNotNil() predicate.Group {
 	return predicate.Group(sql.FieldNotNull(FieldWebSearchPricePerCall))
 }
 
+// WebFetchPricePerCallEQ applies the EQ predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldWebFetchPricePerCall, v))
+}
+
+// WebFetchPricePerCallNEQ applies the NEQ predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallNEQ(v float64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldWebFetchPricePerCall, v))
+}
+
+// WebFetchPricePerCallIn applies the In predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldWebFetchPricePerCall, vs...))
+}
+
+// WebFetchPricePerCallNotIn applies the NotIn predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallNotIn(vs ...float64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldWebFetchPricePerCall, vs...))
+}
+
+// WebFetchPricePerCallGT applies the GT predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallGT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldWebFetchPricePerCall, v))
+}
+
+// WebFetchPricePerCallGTE applies the GTE predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallGTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldWebFetchPricePerCall, v))
+}
+
+// WebFetchPricePerCallLT applies the LT predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallLT(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldWebFetchPricePerCall, v))
+}
+
+// WebFetchPricePerCallLTE applies the LTE predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallLTE(v float64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldWebFetchPricePerCall, v))
+}
+
+// WebFetchPricePerCallIsNil applies the IsNil predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldWebFetchPricePerCall))
+}
+
+// WebFetchPricePerCallNotNil applies the NotNil predicate on the "web_fetch_price_per_call" field.
+func WebFetchPricePerCallNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldWebFetchPricePerCall))
+}
+
 // SearchPricePer1kEQ applies the EQ predicate on the "search_price_per_1k" field.
 func SearchPricePer1kEQ(v float64) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldSearchPricePer1k, v))
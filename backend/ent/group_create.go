@@ -4,7 +4,7 @@ package ent
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/json/jsontext"
 	"errors"
 	"fmt"
 	"time"
@@ -414,6 +414,104 @@ func (_c *GroupCreate) SetNillableBatchImageHoldMultiplier(v *float64) *GroupCre
 	return _c
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (_c *GroupCreate) SetAllowBatchAPI(v bool) *GroupCreate {
+	_c.mutation.SetAllowBatchAPI(v)
+	return _c
+}
+
+// SetNillableAllowBatchAPI sets the "allow_batch_api" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAllowBatchAPI(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetAllowBatchAPI(*v)
+	}
+	return _c
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (_c *GroupCreate) SetBatchAPIDiscountMultiplier(v float64) *GroupCreate {
+	_c.mutation.SetBatchAPIDiscountMultiplier(v)
+	return _c
+}
+
+// SetNillableBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableBatchAPIDiscountMultiplier(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetBatchAPIDiscountMultiplier(*v)
+	}
+	return _c
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (_c *GroupCreate) SetBatchAPIHoldMultiplier(v float64) *GroupCreate {
+	_c.mutation.SetBatchAPIHoldMultiplier(v)
+	return _c
+}
+
+// SetNillableBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableBatchAPIHoldMultiplier(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetBatchAPIHoldMultiplier(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_c *GroupCreate) SetResponseCacheEnabled(v bool) *GroupCreate {
+	_c.mutation.SetResponseCacheEnabled(v)
+	return _c
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheEnabled(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheEnabled(*v)
+	}
+	return _c
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_c *GroupCreate) SetResponseCacheTTLSeconds(v int) *GroupCreate {
+	_c.mutation.SetResponseCacheTTLSeconds(v)
+	return _c
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCacheTTLSeconds(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCacheTTLSeconds(*v)
+	}
+	return _c
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (_c *GroupCreate) SetResponseCachePriceMultiplier(v float64) *GroupCreate {
+	_c.mutation.SetResponseCachePriceMultiplier(v)
+	return _c
+}
+
+// SetNillableResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseCachePriceMultiplier(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetResponseCachePriceMultiplier(*v)
+	}
+	return _c
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_c *GroupCreate) SetSchedulingStrategy(v string) *GroupCreate {
+	_c.mutation.SetSchedulingStrategy(v)
+	return _c
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSchedulingStrategy(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSchedulingStrategy(*v)
+	}
+	return _c
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (_c *GroupCreate) SetVideoRateIndependent(v bool) *GroupCreate {
 	_c.mutation.SetVideoRateIndependent(v)
@@ -504,6 +602,20 @@ func (_c *GroupCreate) SetNillableWebSearchPricePerCall(v *float64) *GroupCreate
 	return _c
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (_c *GroupCreate) SetWebFetchPricePerCall(v float64) *GroupCreate {
+	_c.mutation.SetWebFetchPricePerCall(v)
+	return _c
+}
+
+// SetNillableWebFetchPricePerCall sets the "web_fetch_price_per_call" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableWebFetchPricePerCall(v *float64) *GroupCreate {
+	if v != nil {
+		_c.SetWebFetchPricePerCall(*v)
+	}
+	return _c
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (_c *GroupCreate) SetSearchPricePer1k(v float64) *GroupCreate {
 	_c.mutation.SetSearchPricePer1k(v)
@@ -575,7 +687,7 @@ func (_c *GroupCreate) SetNillableLongContextPricingEnabled(v *bool) *GroupCreat
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (_c *GroupCreate) SetModelPricing(v json.RawMessage) *GroupCreate {
+func (_c *GroupCreate) SetModelPricing(v jsontext.Value) *GroupCreate {
 	_c.mutation.SetModelPricing(v)
 	return _c
 }
@@ -1055,6 +1167,34 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultBatchImageHoldMultiplier
 		_c.mutation.SetBatchImageHoldMultiplier(v)
 	}
+	if _, ok := _c.mutation.AllowBatchAPI(); !ok {
+		v := group.DefaultAllowBatchAPI
+		_c.mutation.SetAllowBatchAPI(v)
+	}
+	if _, ok := _c.mutation.BatchAPIDiscountMultiplier(); !ok {
+		v := group.DefaultBatchAPIDiscountMultiplier
+		_c.mutation.SetBatchAPIDiscountMultiplier(v)
+	}
+	if _, ok := _c.mutation.BatchAPIHoldMultiplier(); !ok {
+		v := group.DefaultBatchAPIHoldMultiplier
+		_c.mutation.SetBatchAPIHoldMultiplier(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		v := group.DefaultResponseCacheEnabled
+		_c.mutation.SetResponseCacheEnabled(v)
+	}
+	if _, ok := _c.mutation.ResponseCacheTTLSeconds(); !ok {
+		v := group.DefaultResponseCacheTTLSeconds
+		_c.mutation.SetResponseCacheTTLSeconds(v)
+	}
+	if _, ok := _c.mutation.ResponseCachePriceMultiplier(); !ok {
+		v := group.DefaultResponseCachePriceMultiplier
+		_c.mutation.SetResponseCachePriceMultiplier(v)
+	}
+	if _, ok := _c.mutation.SchedulingStrategy(); !ok {
+		v := group.DefaultSchedulingStrategy
+		_c.mutation.SetSchedulingStrategy(v)
+	}
 	if _, ok := _c.mutation.VideoRateIndependent(); !ok {
 		v := group.DefaultVideoRateIndependent
 		_c.mutation.SetVideoRateIndependent(v)
@@ -1236,6 +1376,32 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.BatchImageHoldMultiplier(); !ok {
 		return &ValidationError{Name: "batch_image_hold_multiplier", err: errors.New(`ent: missing required field "Group.batch_image_hold_multiplier"`)}
 	}
+	if _, ok := _c.mutation.AllowBatchAPI(); !ok {
+		return &ValidationError{Name: "allow_batch_api", err: errors.New(`ent: missing required field "Group.allow_batch_api"`)}
+	}
+	if _, ok := _c.mutation.BatchAPIDiscountMultiplier(); !ok {
+		return &ValidationError{Name: "batch_api_discount_multiplier", err: errors.New(`ent: missing required field "Group.batch_api_discount_multiplier"`)}
+	}
+	if _, ok := _c.mutation.BatchAPIHoldMultiplier(); !ok {
+		return &ValidationError{Name: "batch_api_hold_multiplier", err: errors.New(`ent: missing required field "Group.batch_api_hold_multiplier"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheEnabled(); !ok {
+		return &ValidationError{Name: "response_cache_enabled", err: errors.New(`ent: missing required field "Group.response_cache_enabled"`)}
+	}
+	if _, ok := _c.mutation.ResponseCacheTTLSeconds(); !ok {
+		return &ValidationError{Name: "response_cache_ttl_seconds", err: errors.New(`ent: missing required field "Group.response_cache_ttl_seconds"`)}
+	}
+	if _, ok := _c.mutation.ResponseCachePriceMultiplier(); !ok {
+		return &ValidationError{Name: "response_cache_price_multiplier", err: errors.New(`ent: missing required field "Group.response_cache_price_multiplier"`)}
+	}
+	if _, ok := _c.mutation.SchedulingStrategy(); !ok {
+		return &ValidationError{Name: "scheduling_strategy", err: errors.New(`ent: missing required field "Group.scheduling_strategy"`)}
+	}
+	if v, ok := _c.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	if _, ok := _c.mutation.VideoRateIndependent(); !ok {
 		return &ValidationError{Name: "video_rate_independent", err: errors.New(`ent: missing required field "Group.video_rate_independent"`)}
 	}
@@ -1468,6 +1634,34 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldBatchImageHoldMultiplier, field.TypeFloat64, value)
 		_node.BatchImageHoldMultiplier = value
 	}
+	if value, ok := _c.mutation.AllowBatchAPI(); ok {
+		_spec.SetField(group.FieldAllowBatchAPI, field.TypeBool, value)
+		_node.AllowBatchAPI = value
+	}
+	if value, ok := _c.mutation.BatchAPIDiscountMultiplier(); ok {
+		_spec.SetField(group.FieldBatchAPIDiscountMultiplier, field.TypeFloat64, value)
+		_node.BatchAPIDiscountMultiplier = value
+	}
+	if value, ok := _c.mutation.BatchAPIHoldMultiplier(); ok {
+		_spec.SetField(group.FieldBatchAPIHoldMultiplier, field.TypeFloat64, value)
+		_node.BatchAPIHoldMultiplier = value
+	}
+	if value, ok := _c.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+		_node.ResponseCacheEnabled = value
+	}
+	if value, ok := _c.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+		_node.ResponseCacheTTLSeconds = value
+	}
+	if value, ok := _c.mutation.ResponseCachePriceMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCachePriceMultiplier, field.TypeFloat64, value)
+		_node.ResponseCachePriceMultiplier = value
+	}
+	if value, ok := _c.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+		_node.SchedulingStrategy = value
+	}
 	if value, ok := _c.mutation.VideoRateIndependent(); ok {
 		_spec.SetField(group.FieldVideoRateIndependent, field.TypeBool, value)
 		_node.VideoRateIndependent = value
@@ -1496,6 +1690,10 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldWebSearchPricePerCall, field.TypeFloat64, value)
 		_node.WebSearchPricePerCall = &value
 	}
+	if value, ok := _c.mutation.WebFetchPricePerCall(); ok {
+		_spec.SetField(group.FieldWebFetchPricePerCall, field.TypeFloat64, value)
+		_node.WebFetchPricePerCall = &value
+	}
 	if value, ok := _c.mutation.SearchPricePer1k(); ok {
 		_spec.SetField(group.FieldSearchPricePer1k, field.TypeFloat64, value)
 		_node.SearchPricePer1k = &value
@@ -2192,6 +2390,114 @@ func (u *GroupUpsert) AddBatchImageHoldMultiplier(v float64) *GroupUpsert {
 	return u
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (u *GroupUpsert) SetAllowBatchAPI(v bool) *GroupUpsert {
+	u.Set(group.FieldAllowBatchAPI, v)
+	return u
+}
+
+// UpdateAllowBatchAPI sets the "allow_batch_api" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAllowBatchAPI() *GroupUpsert {
+	u.SetExcluded(group.FieldAllowBatchAPI)
+	return u
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (u *GroupUpsert) SetBatchAPIDiscountMultiplier(v float64) *GroupUpsert {
+	u.Set(group.FieldBatchAPIDiscountMultiplier, v)
+	return u
+}
+
+// UpdateBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateBatchAPIDiscountMultiplier() *GroupUpsert {
+	u.SetExcluded(group.FieldBatchAPIDiscountMultiplier)
+	return u
+}
+
+// AddBatchAPIDiscountMultiplier adds v to the "batch_api_discount_multiplier" field.
+func (u *GroupUpsert) AddBatchAPIDiscountMultiplier(v float64) *GroupUpsert {
+	u.Add(group.FieldBatchAPIDiscountMultiplier, v)
+	return u
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (u *GroupUpsert) SetBatchAPIHoldMultiplier(v float64) *GroupUpsert {
+	u.Set(group.FieldBatchAPIHoldMultiplier, v)
+	return u
+}
+
+// UpdateBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateBatchAPIHoldMultiplier() *GroupUpsert {
+	u.SetExcluded(group.FieldBatchAPIHoldMultiplier)
+	return u
+}
+
+// AddBatchAPIHoldMultiplier adds v to the "batch_api_hold_multiplier" field.
+func (u *GroupUpsert) AddBatchAPIHoldMultiplier(v float64) *GroupUpsert {
+	u.Add(group.FieldBatchAPIHoldMultiplier, v)
+	return u
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsert) SetResponseCacheEnabled(v bool) *GroupUpsert {
+	u.Set(group.FieldResponseCacheEnabled, v)
+	return u
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheEnabled() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheEnabled)
+	return u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsert) SetResponseCacheTTLSeconds(v int) *GroupUpsert {
+	u.Set(group.FieldResponseCacheTTLSeconds, v)
+	return u
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCacheTTLSeconds() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCacheTTLSeconds)
+	return u
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsert) AddResponseCacheTTLSeconds(v int) *GroupUpsert {
+	u.Add(group.FieldResponseCacheTTLSeconds, v)
+	return u
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (u *GroupUpsert) SetResponseCachePriceMultiplier(v float64) *GroupUpsert {
+	u.Set(group.FieldResponseCachePriceMultiplier, v)
+	return u
+}
+
+// UpdateResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCachePriceMultiplier() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCachePriceMultiplier)
+	return u
+}
+
+// AddResponseCachePriceMultiplier adds v to the "response_cache_price_multiplier" field.
+func (u *GroupUpsert) AddResponseCachePriceMultiplier(v float64) *GroupUpsert {
+	u.Add(group.FieldResponseCachePriceMultiplier, v)
+	return u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsert) SetSchedulingStrategy(v string) *GroupUpsert {
+	u.Set(group.FieldSchedulingStrategy, v)
+	return u
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSchedulingStrategy() *GroupUpsert {
+	u.SetExcluded(group.FieldSchedulingStrategy)
+	return u
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (u *GroupUpsert) SetVideoRateIndependent(v bool) *GroupUpsert {
 	u.Set(group.FieldVideoRateIndependent, v)
@@ -2336,6 +2642,30 @@ func (u *GroupUpsert) ClearWebSearchPricePerCall() *GroupUpsert {
 	return u
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (u *GroupUpsert) SetWebFetchPricePerCall(v float64) *GroupUpsert {
+	u.Set(group.FieldWebFetchPricePerCall, v)
+	return u
+}
+
+// UpdateWebFetchPricePerCall sets the "web_fetch_price_per_call" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateWebFetchPricePerCall() *GroupUpsert {
+	u.SetExcluded(group.FieldWebFetchPricePerCall)
+	return u
+}
+
+// AddWebFetchPricePerCall adds v to the "web_fetch_price_per_call" field.
+func (u *GroupUpsert) AddWebFetchPricePerCall(v float64) *GroupUpsert {
+	u.Add(group.FieldWebFetchPricePerCall, v)
+	return u
+}
+
+// ClearWebFetchPricePerCall clears the value of the "web_fetch_price_per_call" field.
+func (u *GroupUpsert) ClearWebFetchPricePerCall() *GroupUpsert {
+	u.SetNull(group.FieldWebFetchPricePerCall)
+	return u
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (u *GroupUpsert) SetSearchPricePer1k(v float64) *GroupUpsert {
 	u.Set(group.FieldSearchPricePer1k, v)
@@ -2445,7 +2775,7 @@ func (u *GroupUpsert) UpdateLongContextPricingEnabled() *GroupUpsert {
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (u *GroupUpsert) SetModelPricing(v json.RawMessage) *GroupUpsert {
+func (u *GroupUpsert) SetModelPricing(v jsontext.Value) *GroupUpsert {
 	u.Set(group.FieldModelPricing, v)
 	return u
 }
@@ -3320,6 +3650,132 @@ func (u *GroupUpsertOne) UpdateBatchImageHoldMultiplier() *GroupUpsertOne {
 	})
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (u *GroupUpsertOne) SetAllowBatchAPI(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowBatchAPI(v)
+	})
+}
+
+// UpdateAllowBatchAPI sets the "allow_batch_api" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAllowBatchAPI() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowBatchAPI()
+	})
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (u *GroupUpsertOne) SetBatchAPIDiscountMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetBatchAPIDiscountMultiplier(v)
+	})
+}
+
+// AddBatchAPIDiscountMultiplier adds v to the "batch_api_discount_multiplier" field.
+func (u *GroupUpsertOne) AddBatchAPIDiscountMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddBatchAPIDiscountMultiplier(v)
+	})
+}
+
+// UpdateBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateBatchAPIDiscountMultiplier() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateBatchAPIDiscountMultiplier()
+	})
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (u *GroupUpsertOne) SetBatchAPIHoldMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetBatchAPIHoldMultiplier(v)
+	})
+}
+
+// AddBatchAPIHoldMultiplier adds v to the "batch_api_hold_multiplier" field.
+func (u *GroupUpsertOne) AddBatchAPIHoldMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddBatchAPIHoldMultiplier(v)
+	})
+}
+
+// UpdateBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateBatchAPIHoldMultiplier() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateBatchAPIHoldMultiplier()
+	})
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsertOne) SetResponseCacheEnabled(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheEnabled() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertOne) SetResponseCacheTTLSeconds(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheTTLSeconds(v)
+	})
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertOne) AddResponseCacheTTLSeconds(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheTTLSeconds(v)
+	})
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCacheTTLSeconds() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheTTLSeconds()
+	})
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (u *GroupUpsertOne) SetResponseCachePriceMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCachePriceMultiplier(v)
+	})
+}
+
+// AddResponseCachePriceMultiplier adds v to the "response_cache_price_multiplier" field.
+func (u *GroupUpsertOne) AddResponseCachePriceMultiplier(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCachePriceMultiplier(v)
+	})
+}
+
+// UpdateResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCachePriceMultiplier() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCachePriceMultiplier()
+	})
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsertOne) SetSchedulingStrategy(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSchedulingStrategy(v)
+	})
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSchedulingStrategy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSchedulingStrategy()
+	})
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (u *GroupUpsertOne) SetVideoRateIndependent(v bool) *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
@@ -3488,6 +3944,34 @@ func (u *GroupUpsertOne) ClearWebSearchPricePerCall() *GroupUpsertOne {
 	})
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (u *GroupUpsertOne) SetWebFetchPricePerCall(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetWebFetchPricePerCall(v)
+	})
+}
+
+// AddWebFetchPricePerCall adds v to the "web_fetch_price_per_call" field.
+func (u *GroupUpsertOne) AddWebFetchPricePerCall(v float64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddWebFetchPricePerCall(v)
+	})
+}
+
+// UpdateWebFetchPricePerCall sets the "web_fetch_price_per_call" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateWebFetchPricePerCall() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateWebFetchPricePerCall()
+	})
+}
+
+// ClearWebFetchPricePerCall clears the value of the "web_fetch_price_per_call" field.
+func (u *GroupUpsertOne) ClearWebFetchPricePerCall() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearWebFetchPricePerCall()
+	})
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (u *GroupUpsertOne) SetSearchPricePer1k(v float64) *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
@@ -3615,7 +4099,7 @@ func (u *GroupUpsertOne) UpdateLongContextPricingEnabled() *GroupUpsertOne {
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (u *GroupUpsertOne) SetModelPricing(v json.RawMessage) *GroupUpsertOne {
+func (u *GroupUpsertOne) SetModelPricing(v jsontext.Value) *GroupUpsertOne {
 	return u.Update(func(s *GroupUpsert) {
 		s.SetModelPricing(v)
 	})
@@ -4710,6 +5194,132 @@ func (u *GroupUpsertBulk) UpdateBatchImageHoldMultiplier() *GroupUpsertBulk {
 	})
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (u *GroupUpsertBulk) SetAllowBatchAPI(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAllowBatchAPI(v)
+	})
+}
+
+// UpdateAllowBatchAPI sets the "allow_batch_api" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAllowBatchAPI() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAllowBatchAPI()
+	})
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (u *GroupUpsertBulk) SetBatchAPIDiscountMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetBatchAPIDiscountMultiplier(v)
+	})
+}
+
+// AddBatchAPIDiscountMultiplier adds v to the "batch_api_discount_multiplier" field.
+func (u *GroupUpsertBulk) AddBatchAPIDiscountMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddBatchAPIDiscountMultiplier(v)
+	})
+}
+
+// UpdateBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateBatchAPIDiscountMultiplier() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateBatchAPIDiscountMultiplier()
+	})
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (u *GroupUpsertBulk) SetBatchAPIHoldMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetBatchAPIHoldMultiplier(v)
+	})
+}
+
+// AddBatchAPIHoldMultiplier adds v to the "batch_api_hold_multiplier" field.
+func (u *GroupUpsertBulk) AddBatchAPIHoldMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddBatchAPIHoldMultiplier(v)
+	})
+}
+
+// UpdateBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateBatchAPIHoldMultiplier() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateBatchAPIHoldMultiplier()
+	})
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (u *GroupUpsertBulk) SetResponseCacheEnabled(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheEnabled(v)
+	})
+}
+
+// UpdateResponseCacheEnabled sets the "response_cache_enabled" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheEnabled() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheEnabled()
+	})
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertBulk) SetResponseCacheTTLSeconds(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCacheTTLSeconds(v)
+	})
+}
+
+// AddResponseCacheTTLSeconds adds v to the "response_cache_ttl_seconds" field.
+func (u *GroupUpsertBulk) AddResponseCacheTTLSeconds(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCacheTTLSeconds(v)
+	})
+}
+
+// UpdateResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCacheTTLSeconds() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCacheTTLSeconds()
+	})
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (u *GroupUpsertBulk) SetResponseCachePriceMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCachePriceMultiplier(v)
+	})
+}
+
+// AddResponseCachePriceMultiplier adds v to the "response_cache_price_multiplier" field.
+func (u *GroupUpsertBulk) AddResponseCachePriceMultiplier(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddResponseCachePriceMultiplier(v)
+	})
+}
+
+// UpdateResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCachePriceMultiplier() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCachePriceMultiplier()
+	})
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsertBulk) SetSchedulingStrategy(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSchedulingStrategy(v)
+	})
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSchedulingStrategy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSchedulingStrategy()
+	})
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (u *GroupUpsertBulk) SetVideoRateIndependent(v bool) *GroupUpsertBulk {
 	return u.Update(func(s *GroupUpsert) {
@@ -4878,6 +5488,34 @@ func (u *GroupUpsertBulk) ClearWebSearchPricePerCall() *GroupUpsertBulk {
 	})
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (u *GroupUpsertBulk) SetWebFetchPricePerCall(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetWebFetchPricePerCall(v)
+	})
+}
+
+// AddWebFetchPricePerCall adds v to the "web_fetch_price_per_call" field.
+func (u *GroupUpsertBulk) AddWebFetchPricePerCall(v float64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddWebFetchPricePerCall(v)
+	})
+}
+
+// UpdateWebFetchPricePerCall sets the "web_fetch_price_per_call" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateWebFetchPricePerCall() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateWebFetchPricePerCall()
+	})
+}
+
+// ClearWebFetchPricePerCall clears the value of the "web_fetch_price_per_call" field.
+func (u *GroupUpsertBulk) ClearWebFetchPricePerCall() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearWebFetchPricePerCall()
+	})
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (u *GroupUpsertBulk) SetSearchPricePer1k(v float64) *GroupUpsertBulk {
 	return u.Update(func(s *GroupUpsert) {
@@ -5005,7 +5643,7 @@ func (u *GroupUpsertBulk) UpdateLongContextPricingEnabled() *GroupUpsertBulk {
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (u *GroupUpsertBulk) SetModelPricing(v json.RawMessage) *GroupUpsertBulk {
+func (u *GroupUpsertBulk) SetModelPricing(v jsontext.Value) *GroupUpsertBulk {
 	return u.Update(func(s *GroupUpsert) {
 		s.SetModelPricing(v)
 	})
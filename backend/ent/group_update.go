@@ -4,7 +4,7 @@ package ent
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/json/jsontext"
 	"errors"
 	"fmt"
 	"time"
@@ -525,6 +525,132 @@ func (_u *GroupUpdate) AddBatchImageHoldMultiplier(v float64) *GroupUpdate {
 	return _u
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (_u *GroupUpdate) SetAllowBatchAPI(v bool) *GroupUpdate {
+	_u.mutation.SetAllowBatchAPI(v)
+	return _u
+}
+
+// SetNillableAllowBatchAPI sets the "allow_batch_api" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAllowBatchAPI(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetAllowBatchAPI(*v)
+	}
+	return _u
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (_u *GroupUpdate) SetBatchAPIDiscountMultiplier(v float64) *GroupUpdate {
+	_u.mutation.ResetBatchAPIDiscountMultiplier()
+	_u.mutation.SetBatchAPIDiscountMultiplier(v)
+	return _u
+}
+
+// SetNillableBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableBatchAPIDiscountMultiplier(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetBatchAPIDiscountMultiplier(*v)
+	}
+	return _u
+}
+
+// AddBatchAPIDiscountMultiplier adds value to the "batch_api_discount_multiplier" field.
+func (_u *GroupUpdate) AddBatchAPIDiscountMultiplier(v float64) *GroupUpdate {
+	_u.mutation.AddBatchAPIDiscountMultiplier(v)
+	return _u
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (_u *GroupUpdate) SetBatchAPIHoldMultiplier(v float64) *GroupUpdate {
+	_u.mutation.ResetBatchAPIHoldMultiplier()
+	_u.mutation.SetBatchAPIHoldMultiplier(v)
+	return _u
+}
+
+// SetNillableBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableBatchAPIHoldMultiplier(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetBatchAPIHoldMultiplier(*v)
+	}
+	return _u
+}
+
+// AddBatchAPIHoldMultiplier adds value to the "batch_api_hold_multiplier" field.
+func (_u *GroupUpdate) AddBatchAPIHoldMultiplier(v float64) *GroupUpdate {
+	_u.mutation.AddBatchAPIHoldMultiplier(v)
+	return _u
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *GroupUpdate) SetResponseCacheEnabled(v bool) *GroupUpdate {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheEnabled(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdate) SetResponseCacheTTLSeconds(v int) *GroupUpdate {
+	_u.mutation.ResetResponseCacheTTLSeconds()
+	_u.mutation.SetResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCacheTTLSeconds(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCacheTTLSeconds(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheTTLSeconds adds value to the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdate) AddResponseCacheTTLSeconds(v int) *GroupUpdate {
+	_u.mutation.AddResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (_u *GroupUpdate) SetResponseCachePriceMultiplier(v float64) *GroupUpdate {
+	_u.mutation.ResetResponseCachePriceMultiplier()
+	_u.mutation.SetResponseCachePriceMultiplier(v)
+	return _u
+}
+
+// SetNillableResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseCachePriceMultiplier(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseCachePriceMultiplier(*v)
+	}
+	return _u
+}
+
+// AddResponseCachePriceMultiplier adds value to the "response_cache_price_multiplier" field.
+func (_u *GroupUpdate) AddResponseCachePriceMultiplier(v float64) *GroupUpdate {
+	_u.mutation.AddResponseCachePriceMultiplier(v)
+	return _u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_u *GroupUpdate) SetSchedulingStrategy(v string) *GroupUpdate {
+	_u.mutation.SetSchedulingStrategy(v)
+	return _u
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSchedulingStrategy(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSchedulingStrategy(*v)
+	}
+	return _u
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (_u *GroupUpdate) SetVideoRateIndependent(v bool) *GroupUpdate {
 	_u.mutation.SetVideoRateIndependent(v)
@@ -680,6 +806,33 @@ func (_u *GroupUpdate) ClearWebSearchPricePerCall() *GroupUpdate {
 	return _u
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (_u *GroupUpdate) SetWebFetchPricePerCall(v float64) *GroupUpdate {
+	_u.mutation.ResetWebFetchPricePerCall()
+	_u.mutation.SetWebFetchPricePerCall(v)
+	return _u
+}
+
+// SetNillableWebFetchPricePerCall sets the "web_fetch_price_per_call" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableWebFetchPricePerCall(v *float64) *GroupUpdate {
+	if v != nil {
+		_u.SetWebFetchPricePerCall(*v)
+	}
+	return _u
+}
+
+// AddWebFetchPricePerCall adds value to the "web_fetch_price_per_call" field.
+func (_u *GroupUpdate) AddWebFetchPricePerCall(v float64) *GroupUpdate {
+	_u.mutation.AddWebFetchPricePerCall(v)
+	return _u
+}
+
+// ClearWebFetchPricePerCall clears the value of the "web_fetch_price_per_call" field.
+func (_u *GroupUpdate) ClearWebFetchPricePerCall() *GroupUpdate {
+	_u.mutation.ClearWebFetchPricePerCall()
+	return _u
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (_u *GroupUpdate) SetSearchPricePer1k(v float64) *GroupUpdate {
 	_u.mutation.ResetSearchPricePer1k()
@@ -803,13 +956,13 @@ func (_u *GroupUpdate) SetNillableLongContextPricingEnabled(v *bool) *GroupUpdat
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (_u *GroupUpdate) SetModelPricing(v json.RawMessage) *GroupUpdate {
+func (_u *GroupUpdate) SetModelPricing(v jsontext.Value) *GroupUpdate {
 	_u.mutation.SetModelPricing(v)
 	return _u
 }
 
 // AppendModelPricing appends value to the "model_pricing" field.
-func (_u *GroupUpdate) AppendModelPricing(v json.RawMessage) *GroupUpdate {
+func (_u *GroupUpdate) AppendModelPricing(v jsontext.Value) *GroupUpdate {
 	_u.mutation.AppendModelPricing(v)
 	return _u
 }
@@ -1457,6 +1610,11 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "subscription_type", err: fmt.Errorf(`ent: validator failed for field "Group.subscription_type": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.SearchPricePer1k(); ok {
 		if err := group.SearchPricePer1kValidator(v); err != nil {
 			return &ValidationError{Name: "search_price_per_1k", err: fmt.Errorf(`ent: validator failed for field "Group.search_price_per_1k": %w`, err)}
@@ -1643,6 +1801,39 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedBatchImageHoldMultiplier(); ok {
 		_spec.AddField(group.FieldBatchImageHoldMultiplier, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.AllowBatchAPI(); ok {
+		_spec.SetField(group.FieldAllowBatchAPI, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.BatchAPIDiscountMultiplier(); ok {
+		_spec.SetField(group.FieldBatchAPIDiscountMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBatchAPIDiscountMultiplier(); ok {
+		_spec.AddField(group.FieldBatchAPIDiscountMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BatchAPIHoldMultiplier(); ok {
+		_spec.SetField(group.FieldBatchAPIHoldMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBatchAPIHoldMultiplier(); ok {
+		_spec.AddField(group.FieldBatchAPIHoldMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheTTLSeconds(); ok {
+		_spec.AddField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ResponseCachePriceMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCachePriceMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCachePriceMultiplier(); ok {
+		_spec.AddField(group.FieldResponseCachePriceMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+	}
 	if value, ok := _u.mutation.VideoRateIndependent(); ok {
 		_spec.SetField(group.FieldVideoRateIndependent, field.TypeBool, value)
 	}
@@ -1694,6 +1885,15 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.WebSearchPricePerCallCleared() {
 		_spec.ClearField(group.FieldWebSearchPricePerCall, field.TypeFloat64)
 	}
+	if value, ok := _u.mutation.WebFetchPricePerCall(); ok {
+		_spec.SetField(group.FieldWebFetchPricePerCall, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedWebFetchPricePerCall(); ok {
+		_spec.AddField(group.FieldWebFetchPricePerCall, field.TypeFloat64, value)
+	}
+	if _u.mutation.WebFetchPricePerCallCleared() {
+		_spec.ClearField(group.FieldWebFetchPricePerCall, field.TypeFloat64)
+	}
 	if value, ok := _u.mutation.SearchPricePer1k(); ok {
 		_spec.SetField(group.FieldSearchPricePer1k, field.TypeFloat64, value)
 	}
@@ -2646,6 +2846,132 @@ func (_u *GroupUpdateOne) AddBatchImageHoldMultiplier(v float64) *GroupUpdateOne
 	return _u
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (_u *GroupUpdateOne) SetAllowBatchAPI(v bool) *GroupUpdateOne {
+	_u.mutation.SetAllowBatchAPI(v)
+	return _u
+}
+
+// SetNillableAllowBatchAPI sets the "allow_batch_api" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAllowBatchAPI(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAllowBatchAPI(*v)
+	}
+	return _u
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (_u *GroupUpdateOne) SetBatchAPIDiscountMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.ResetBatchAPIDiscountMultiplier()
+	_u.mutation.SetBatchAPIDiscountMultiplier(v)
+	return _u
+}
+
+// SetNillableBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableBatchAPIDiscountMultiplier(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetBatchAPIDiscountMultiplier(*v)
+	}
+	return _u
+}
+
+// AddBatchAPIDiscountMultiplier adds value to the "batch_api_discount_multiplier" field.
+func (_u *GroupUpdateOne) AddBatchAPIDiscountMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.AddBatchAPIDiscountMultiplier(v)
+	return _u
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (_u *GroupUpdateOne) SetBatchAPIHoldMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.ResetBatchAPIHoldMultiplier()
+	_u.mutation.SetBatchAPIHoldMultiplier(v)
+	return _u
+}
+
+// SetNillableBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableBatchAPIHoldMultiplier(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetBatchAPIHoldMultiplier(*v)
+	}
+	return _u
+}
+
+// AddBatchAPIHoldMultiplier adds value to the "batch_api_hold_multiplier" field.
+func (_u *GroupUpdateOne) AddBatchAPIHoldMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.AddBatchAPIHoldMultiplier(v)
+	return _u
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (_u *GroupUpdateOne) SetResponseCacheEnabled(v bool) *GroupUpdateOne {
+	_u.mutation.SetResponseCacheEnabled(v)
+	return _u
+}
+
+// SetNillableResponseCacheEnabled sets the "response_cache_enabled" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheEnabled(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdateOne) SetResponseCacheTTLSeconds(v int) *GroupUpdateOne {
+	_u.mutation.ResetResponseCacheTTLSeconds()
+	_u.mutation.SetResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetNillableResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCacheTTLSeconds(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCacheTTLSeconds(*v)
+	}
+	return _u
+}
+
+// AddResponseCacheTTLSeconds adds value to the "response_cache_ttl_seconds" field.
+func (_u *GroupUpdateOne) AddResponseCacheTTLSeconds(v int) *GroupUpdateOne {
+	_u.mutation.AddResponseCacheTTLSeconds(v)
+	return _u
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (_u *GroupUpdateOne) SetResponseCachePriceMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.ResetResponseCachePriceMultiplier()
+	_u.mutation.SetResponseCachePriceMultiplier(v)
+	return _u
+}
+
+// SetNillableResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseCachePriceMultiplier(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseCachePriceMultiplier(*v)
+	}
+	return _u
+}
+
+// AddResponseCachePriceMultiplier adds value to the "response_cache_price_multiplier" field.
+func (_u *GroupUpdateOne) AddResponseCachePriceMultiplier(v float64) *GroupUpdateOne {
+	_u.mutation.AddResponseCachePriceMultiplier(v)
+	return _u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_u *GroupUpdateOne) SetSchedulingStrategy(v string) *GroupUpdateOne {
+	_u.mutation.SetSchedulingStrategy(v)
+	return _u
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSchedulingStrategy(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSchedulingStrategy(*v)
+	}
+	return _u
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (_u *GroupUpdateOne) SetVideoRateIndependent(v bool) *GroupUpdateOne {
 	_u.mutation.SetVideoRateIndependent(v)
@@ -2801,6 +3127,33 @@ func (_u *GroupUpdateOne) ClearWebSearchPricePerCall() *GroupUpdateOne {
 	return _u
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (_u *GroupUpdateOne) SetWebFetchPricePerCall(v float64) *GroupUpdateOne {
+	_u.mutation.ResetWebFetchPricePerCall()
+	_u.mutation.SetWebFetchPricePerCall(v)
+	return _u
+}
+
+// SetNillableWebFetchPricePerCall sets the "web_fetch_price_per_call" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableWebFetchPricePerCall(v *float64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetWebFetchPricePerCall(*v)
+	}
+	return _u
+}
+
+// AddWebFetchPricePerCall adds value to the "web_fetch_price_per_call" field.
+func (_u *GroupUpdateOne) AddWebFetchPricePerCall(v float64) *GroupUpdateOne {
+	_u.mutation.AddWebFetchPricePerCall(v)
+	return _u
+}
+
+// ClearWebFetchPricePerCall clears the value of the "web_fetch_price_per_call" field.
+func (_u *GroupUpdateOne) ClearWebFetchPricePerCall() *GroupUpdateOne {
+	_u.mutation.ClearWebFetchPricePerCall()
+	return _u
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (_u *GroupUpdateOne) SetSearchPricePer1k(v float64) *GroupUpdateOne {
 	_u.mutation.ResetSearchPricePer1k()
@@ -2924,13 +3277,13 @@ func (_u *GroupUpdateOne) SetNillableLongContextPricingEnabled(v *bool) *GroupUp
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (_u *GroupUpdateOne) SetModelPricing(v json.RawMessage) *GroupUpdateOne {
+func (_u *GroupUpdateOne) SetModelPricing(v jsontext.Value) *GroupUpdateOne {
 	_u.mutation.SetModelPricing(v)
 	return _u
 }
 
 // AppendModelPricing appends value to the "model_pricing" field.
-func (_u *GroupUpdateOne) AppendModelPricing(v json.RawMessage) *GroupUpdateOne {
+func (_u *GroupUpdateOne) AppendModelPricing(v jsontext.Value) *GroupUpdateOne {
 	_u.mutation.AppendModelPricing(v)
 	return _u
 }
@@ -3591,6 +3944,11 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "subscription_type", err: fmt.Errorf(`ent: validator failed for field "Group.subscription_type": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.SearchPricePer1k(); ok {
 		if err := group.SearchPricePer1kValidator(v); err != nil {
 			return &ValidationError{Name: "search_price_per_1k", err: fmt.Errorf(`ent: validator failed for field "Group.search_price_per_1k": %w`, err)}
@@ -3794,6 +4152,39 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedBatchImageHoldMultiplier(); ok {
 		_spec.AddField(group.FieldBatchImageHoldMultiplier, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.AllowBatchAPI(); ok {
+		_spec.SetField(group.FieldAllowBatchAPI, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.BatchAPIDiscountMultiplier(); ok {
+		_spec.SetField(group.FieldBatchAPIDiscountMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBatchAPIDiscountMultiplier(); ok {
+		_spec.AddField(group.FieldBatchAPIDiscountMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BatchAPIHoldMultiplier(); ok {
+		_spec.SetField(group.FieldBatchAPIHoldMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBatchAPIHoldMultiplier(); ok {
+		_spec.AddField(group.FieldBatchAPIHoldMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheEnabled(); ok {
+		_spec.SetField(group.FieldResponseCacheEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseCacheTTLSeconds(); ok {
+		_spec.SetField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCacheTTLSeconds(); ok {
+		_spec.AddField(group.FieldResponseCacheTTLSeconds, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ResponseCachePriceMultiplier(); ok {
+		_spec.SetField(group.FieldResponseCachePriceMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedResponseCachePriceMultiplier(); ok {
+		_spec.AddField(group.FieldResponseCachePriceMultiplier, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+	}
 	if value, ok := _u.mutation.VideoRateIndependent(); ok {
 		_spec.SetField(group.FieldVideoRateIndependent, field.TypeBool, value)
 	}
@@ -3845,6 +4236,15 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if _u.mutation.WebSearchPricePerCallCleared() {
 		_spec.ClearField(group.FieldWebSearchPricePerCall, field.TypeFloat64)
 	}
+	if value, ok := _u.mutation.WebFetchPricePerCall(); ok {
+		_spec.SetField(group.FieldWebFetchPricePerCall, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedWebFetchPricePerCall(); ok {
+		_spec.AddField(group.FieldWebFetchPricePerCall, field.TypeFloat64, value)
+	}
+	if _u.mutation.WebFetchPricePerCallCleared() {
+		_spec.ClearField(group.FieldWebFetchPricePerCall, field.TypeFloat64)
+	}
 	if value, ok := _u.mutation.SearchPricePer1k(); ok {
 		_spec.SetField(group.FieldSearchPricePer1k, field.TypeFloat64, value)
 	}
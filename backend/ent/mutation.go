@@ -4,7 +4,7 @@ package ent
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/json/jsontext"
 	"errors"
 	"fmt"
 	"sync"
@@ -108,51 +108,59 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                     Op
+	typ                    string
+	id                     *int64
+	created_at             *time.Time
+	updated_at             *time.Time
+	deleted_at             *time.Time
+	key                    *string
+	name                   *string
+	status                 *string
+	last_used_at           *time.Time
+	ip_whitelist           *[]string
+	appendip_whitelist     []string
+	ip_blacklist           *[]string
+	appendip_blacklist     []string
+	quota                  *float64
+	addquota               *float64
+	quota_used             *float64
+	addquota_used          *float64
+	expires_at             *time.Time
+	rate_limit_5h          *float64
+	addrate_limit_5h       *float64
+	rate_limit_1d          *float64
+	addrate_limit_1d       *float64
+	rate_limit_7d          *float64
+	addrate_limit_7d       *float64
+	usage_5h               *float64
+	addusage_5h            *float64
+	usage_1d               *float64
+	addusage_1d            *float64
+	usage_7d               *float64
+	addusage_7d            *float64
+	window_5h_start        *time.Time
+	window_1d_start        *time.Time
+	window_7d_start        *time.Time
+	response_cache_enabled *bool
+	model_allowlist        *[]string
+	appendmodel_allowlist  []string
+	model_denylist         *[]string
+	appendmodel_denylist   []string
+	model_aliases          *map[string]string
+	organization_id        *int64
+	addorganization_id     *int64
+	clearedFields          map[string]struct{}
+	user                   *int64
+	cleareduser            bool
+	group                  *int64
+	clearedgroup           bool
+	usage_logs             map[int64]struct{}
+	removedusage_logs      map[int64]struct{}
+	clearedusage_logs      bool
+	done                   bool
+	oldValue               func(context.Context) (*APIKey, error)
+	predicates             []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -1390,6 +1398,291 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (m *APIKeyMutation) SetResponseCacheEnabled(b bool) {
+	m.response_cache_enabled = &b
+}
+
+// ResponseCacheEnabled returns the value of the "response_cache_enabled" field in the mutation.
+func (m *APIKeyMutation) ResponseCacheEnabled() (r bool, exists bool) {
+	v := m.response_cache_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheEnabled returns the old "response_cache_enabled" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldResponseCacheEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheEnabled: %w", err)
+	}
+	return oldValue.ResponseCacheEnabled, nil
+}
+
+// ResetResponseCacheEnabled resets all changes to the "response_cache_enabled" field.
+func (m *APIKeyMutation) ResetResponseCacheEnabled() {
+	m.response_cache_enabled = nil
+}
+
+// SetModelAllowlist sets the "model_allowlist" field.
+func (m *APIKeyMutation) SetModelAllowlist(s []string) {
+	m.model_allowlist = &s
+	m.appendmodel_allowlist = nil
+}
+
+// ModelAllowlist returns the value of the "model_allowlist" field in the mutation.
+func (m *APIKeyMutation) ModelAllowlist() (r []string, exists bool) {
+	v := m.model_allowlist
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelAllowlist returns the old "model_allowlist" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldModelAllowlist(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelAllowlist is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelAllowlist requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelAllowlist: %w", err)
+	}
+	return oldValue.ModelAllowlist, nil
+}
+
+// AppendModelAllowlist adds s to the "model_allowlist" field.
+func (m *APIKeyMutation) AppendModelAllowlist(s []string) {
+	m.appendmodel_allowlist = append(m.appendmodel_allowlist, s...)
+}
+
+// AppendedModelAllowlist returns the list of values that were appended to the "model_allowlist" field in this mutation.
+func (m *APIKeyMutation) AppendedModelAllowlist() ([]string, bool) {
+	if len(m.appendmodel_allowlist) == 0 {
+		return nil, false
+	}
+	return m.appendmodel_allowlist, true
+}
+
+// ClearModelAllowlist clears the value of the "model_allowlist" field.
+func (m *APIKeyMutation) ClearModelAllowlist() {
+	m.model_allowlist = nil
+	m.appendmodel_allowlist = nil
+	m.clearedFields[apikey.FieldModelAllowlist] = struct{}{}
+}
+
+// ModelAllowlistCleared returns if the "model_allowlist" field was cleared in this mutation.
+func (m *APIKeyMutation) ModelAllowlistCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldModelAllowlist]
+	return ok
+}
+
+// ResetModelAllowlist resets all changes to the "model_allowlist" field.
+func (m *APIKeyMutation) ResetModelAllowlist() {
+	m.model_allowlist = nil
+	m.appendmodel_allowlist = nil
+	delete(m.clearedFields, apikey.FieldModelAllowlist)
+}
+
+// SetModelDenylist sets the "model_denylist" field.
+func (m *APIKeyMutation) SetModelDenylist(s []string) {
+	m.model_denylist = &s
+	m.appendmodel_denylist = nil
+}
+
+// ModelDenylist returns the value of the "model_denylist" field in the mutation.
+func (m *APIKeyMutation) ModelDenylist() (r []string, exists bool) {
+	v := m.model_denylist
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelDenylist returns the old "model_denylist" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldModelDenylist(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelDenylist is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelDenylist requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelDenylist: %w", err)
+	}
+	return oldValue.ModelDenylist, nil
+}
+
+// AppendModelDenylist adds s to the "model_denylist" field.
+func (m *APIKeyMutation) AppendModelDenylist(s []string) {
+	m.appendmodel_denylist = append(m.appendmodel_denylist, s...)
+}
+
+// AppendedModelDenylist returns the list of values that were appended to the "model_denylist" field in this mutation.
+func (m *APIKeyMutation) AppendedModelDenylist() ([]string, bool) {
+	if len(m.appendmodel_denylist) == 0 {
+		return nil, false
+	}
+	return m.appendmodel_denylist, true
+}
+
+// ClearModelDenylist clears the value of the "model_denylist" field.
+func (m *APIKeyMutation) ClearModelDenylist() {
+	m.model_denylist = nil
+	m.appendmodel_denylist = nil
+	m.clearedFields[apikey.FieldModelDenylist] = struct{}{}
+}
+
+// ModelDenylistCleared returns if the "model_denylist" field was cleared in this mutation.
+func (m *APIKeyMutation) ModelDenylistCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldModelDenylist]
+	return ok
+}
+
+// ResetModelDenylist resets all changes to the "model_denylist" field.
+func (m *APIKeyMutation) ResetModelDenylist() {
+	m.model_denylist = nil
+	m.appendmodel_denylist = nil
+	delete(m.clearedFields, apikey.FieldModelDenylist)
+}
+
+// SetModelAliases sets the "model_aliases" field.
+func (m *APIKeyMutation) SetModelAliases(value map[string]string) {
+	m.model_aliases = &value
+}
+
+// ModelAliases returns the value of the "model_aliases" field in the mutation.
+func (m *APIKeyMutation) ModelAliases() (r map[string]string, exists bool) {
+	v := m.model_aliases
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelAliases returns the old "model_aliases" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldModelAliases(ctx context.Context) (v map[string]string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelAliases is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelAliases requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelAliases: %w", err)
+	}
+	return oldValue.ModelAliases, nil
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (m *APIKeyMutation) ClearModelAliases() {
+	m.model_aliases = nil
+	m.clearedFields[apikey.FieldModelAliases] = struct{}{}
+}
+
+// ModelAliasesCleared returns if the "model_aliases" field was cleared in this mutation.
+func (m *APIKeyMutation) ModelAliasesCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldModelAliases]
+	return ok
+}
+
+// ResetModelAliases resets all changes to the "model_aliases" field.
+func (m *APIKeyMutation) ResetModelAliases() {
+	m.model_aliases = nil
+	delete(m.clearedFields, apikey.FieldModelAliases)
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (m *APIKeyMutation) SetOrganizationID(i int64) {
+	m.organization_id = &i
+	m.addorganization_id = nil
+}
+
+// OrganizationID returns the value of the "organization_id" field in the mutation.
+func (m *APIKeyMutation) OrganizationID() (r int64, exists bool) {
+	v := m.organization_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOrganizationID returns the old "organization_id" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldOrganizationID(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOrganizationID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOrganizationID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOrganizationID: %w", err)
+	}
+	return oldValue.OrganizationID, nil
+}
+
+// AddOrganizationID adds i to the "organization_id" field.
+func (m *APIKeyMutation) AddOrganizationID(i int64) {
+	if m.addorganization_id != nil {
+		*m.addorganization_id += i
+	} else {
+		m.addorganization_id = &i
+	}
+}
+
+// AddedOrganizationID returns the value that was added to the "organization_id" field in this mutation.
+func (m *APIKeyMutation) AddedOrganizationID() (r int64, exists bool) {
+	v := m.addorganization_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (m *APIKeyMutation) ClearOrganizationID() {
+	m.organization_id = nil
+	m.addorganization_id = nil
+	m.clearedFields[apikey.FieldOrganizationID] = struct{}{}
+}
+
+// OrganizationIDCleared returns if the "organization_id" field was cleared in this mutation.
+func (m *APIKeyMutation) OrganizationIDCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldOrganizationID]
+	return ok
+}
+
+// ResetOrganizationID resets all changes to the "organization_id" field.
+func (m *APIKeyMutation) ResetOrganizationID() {
+	m.organization_id = nil
+	m.addorganization_id = nil
+	delete(m.clearedFields, apikey.FieldOrganizationID)
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1532,7 +1825,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 28)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1602,6 +1895,21 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.response_cache_enabled != nil {
+		fields = append(fields, apikey.FieldResponseCacheEnabled)
+	}
+	if m.model_allowlist != nil {
+		fields = append(fields, apikey.FieldModelAllowlist)
+	}
+	if m.model_denylist != nil {
+		fields = append(fields, apikey.FieldModelDenylist)
+	}
+	if m.model_aliases != nil {
+		fields = append(fields, apikey.FieldModelAliases)
+	}
+	if m.organization_id != nil {
+		fields = append(fields, apikey.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -1656,6 +1964,16 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldResponseCacheEnabled:
+		return m.ResponseCacheEnabled()
+	case apikey.FieldModelAllowlist:
+		return m.ModelAllowlist()
+	case apikey.FieldModelDenylist:
+		return m.ModelDenylist()
+	case apikey.FieldModelAliases:
+		return m.ModelAliases()
+	case apikey.FieldOrganizationID:
+		return m.OrganizationID()
 	}
 	return nil, false
 }
@@ -1711,6 +2029,16 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldResponseCacheEnabled:
+		return m.OldResponseCacheEnabled(ctx)
+	case apikey.FieldModelAllowlist:
+		return m.OldModelAllowlist(ctx)
+	case apikey.FieldModelDenylist:
+		return m.OldModelDenylist(ctx)
+	case apikey.FieldModelAliases:
+		return m.OldModelAliases(ctx)
+	case apikey.FieldOrganizationID:
+		return m.OldOrganizationID(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1881,6 +2209,41 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldResponseCacheEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheEnabled(v)
+		return nil
+	case apikey.FieldModelAllowlist:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelAllowlist(v)
+		return nil
+	case apikey.FieldModelDenylist:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelDenylist(v)
+		return nil
+	case apikey.FieldModelAliases:
+		v, ok := value.(map[string]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelAliases(v)
+		return nil
+	case apikey.FieldOrganizationID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOrganizationID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1913,6 +2276,9 @@ func (m *APIKeyMutation) AddedFields() []string {
 	if m.addusage_7d != nil {
 		fields = append(fields, apikey.FieldUsage7d)
 	}
+	if m.addorganization_id != nil {
+		fields = append(fields, apikey.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -1937,6 +2303,8 @@ func (m *APIKeyMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedUsage1d()
 	case apikey.FieldUsage7d:
 		return m.AddedUsage7d()
+	case apikey.FieldOrganizationID:
+		return m.AddedOrganizationID()
 	}
 	return nil, false
 }
@@ -2002,6 +2370,13 @@ func (m *APIKeyMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddUsage7d(v)
 		return nil
+	case apikey.FieldOrganizationID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddOrganizationID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey numeric field %s", name)
 }
@@ -2037,6 +2412,18 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldWindow7dStart) {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.FieldCleared(apikey.FieldModelAllowlist) {
+		fields = append(fields, apikey.FieldModelAllowlist)
+	}
+	if m.FieldCleared(apikey.FieldModelDenylist) {
+		fields = append(fields, apikey.FieldModelDenylist)
+	}
+	if m.FieldCleared(apikey.FieldModelAliases) {
+		fields = append(fields, apikey.FieldModelAliases)
+	}
+	if m.FieldCleared(apikey.FieldOrganizationID) {
+		fields = append(fields, apikey.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -2078,6 +2465,18 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ClearWindow7dStart()
 		return nil
+	case apikey.FieldModelAllowlist:
+		m.ClearModelAllowlist()
+		return nil
+	case apikey.FieldModelDenylist:
+		m.ClearModelDenylist()
+		return nil
+	case apikey.FieldModelAliases:
+		m.ClearModelAliases()
+		return nil
+	case apikey.FieldOrganizationID:
+		m.ClearOrganizationID()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey nullable field %s", name)
 }
@@ -2155,6 +2554,21 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldResponseCacheEnabled:
+		m.ResetResponseCacheEnabled()
+		return nil
+	case apikey.FieldModelAllowlist:
+		m.ResetModelAllowlist()
+		return nil
+	case apikey.FieldModelDenylist:
+		m.ResetModelDenylist()
+		return nil
+	case apikey.FieldModelAliases:
+		m.ResetModelAliases()
+		return nil
+	case apikey.FieldOrganizationID:
+		m.ResetOrganizationID()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -22121,6 +22535,17 @@ type GroupMutation struct {
 	addbatch_image_discount_multiplier      *float64
 	batch_image_hold_multiplier             *float64
 	addbatch_image_hold_multiplier          *float64
+	allow_batch_api                         *bool
+	batch_api_discount_multiplier           *float64
+	addbatch_api_discount_multiplier        *float64
+	batch_api_hold_multiplier               *float64
+	addbatch_api_hold_multiplier            *float64
+	response_cache_enabled                  *bool
+	response_cache_ttl_seconds              *int
+	addresponse_cache_ttl_seconds           *int
+	response_cache_price_multiplier         *float64
+	addresponse_cache_price_multiplier      *float64
+	scheduling_strategy                     *string
 	video_rate_independent                  *bool
 	video_rate_multiplier                   *float64
 	addvideo_rate_multiplier                *float64
@@ -22133,6 +22558,8 @@ type GroupMutation struct {
 	video_model_prices                      *map[string]map[string]float64
 	web_search_price_per_call               *float64
 	addweb_search_price_per_call            *float64
+	web_fetch_price_per_call                *float64
+	addweb_fetch_price_per_call             *float64
 	search_price_per_1k                     *float64
 	addsearch_price_per_1k                  *float64
 	audio_realtime_price_per_min            *float64
@@ -22142,8 +22569,8 @@ type GroupMutation struct {
 	audio_stt_price_per_hour                *float64
 	addaudio_stt_price_per_hour             *float64
 	long_context_pricing_enabled            *bool
-	model_pricing                           *json.RawMessage
-	appendmodel_pricing                     json.RawMessage
+	model_pricing                           *jsontext.Value
+	appendmodel_pricing                     jsontext.Value
 	claude_code_only                        *bool
 	fallback_group_id                       *int64
 	addfallback_group_id                    *int64
@@ -23666,6 +24093,338 @@ func (m *GroupMutation) ResetBatchImageHoldMultiplier() {
 	m.addbatch_image_hold_multiplier = nil
 }
 
+// SetAllowBatchAPI sets the "allow_batch_api" field.
+func (m *GroupMutation) SetAllowBatchAPI(b bool) {
+	m.allow_batch_api = &b
+}
+
+// AllowBatchAPI returns the value of the "allow_batch_api" field in the mutation.
+func (m *GroupMutation) AllowBatchAPI() (r bool, exists bool) {
+	v := m.allow_batch_api
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowBatchAPI returns the old "allow_batch_api" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAllowBatchAPI(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowBatchAPI is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowBatchAPI requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowBatchAPI: %w", err)
+	}
+	return oldValue.AllowBatchAPI, nil
+}
+
+// ResetAllowBatchAPI resets all changes to the "allow_batch_api" field.
+func (m *GroupMutation) ResetAllowBatchAPI() {
+	m.allow_batch_api = nil
+}
+
+// SetBatchAPIDiscountMultiplier sets the "batch_api_discount_multiplier" field.
+func (m *GroupMutation) SetBatchAPIDiscountMultiplier(f float64) {
+	m.batch_api_discount_multiplier = &f
+	m.addbatch_api_discount_multiplier = nil
+}
+
+// BatchAPIDiscountMultiplier returns the value of the "batch_api_discount_multiplier" field in the mutation.
+func (m *GroupMutation) BatchAPIDiscountMultiplier() (r float64, exists bool) {
+	v := m.batch_api_discount_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBatchAPIDiscountMultiplier returns the old "batch_api_discount_multiplier" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldBatchAPIDiscountMultiplier(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBatchAPIDiscountMultiplier is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBatchAPIDiscountMultiplier requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBatchAPIDiscountMultiplier: %w", err)
+	}
+	return oldValue.BatchAPIDiscountMultiplier, nil
+}
+
+// AddBatchAPIDiscountMultiplier adds f to the "batch_api_discount_multiplier" field.
+func (m *GroupMutation) AddBatchAPIDiscountMultiplier(f float64) {
+	if m.addbatch_api_discount_multiplier != nil {
+		*m.addbatch_api_discount_multiplier += f
+	} else {
+		m.addbatch_api_discount_multiplier = &f
+	}
+}
+
+// AddedBatchAPIDiscountMultiplier returns the value that was added to the "batch_api_discount_multiplier" field in this mutation.
+func (m *GroupMutation) AddedBatchAPIDiscountMultiplier() (r float64, exists bool) {
+	v := m.addbatch_api_discount_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetBatchAPIDiscountMultiplier resets all changes to the "batch_api_discount_multiplier" field.
+func (m *GroupMutation) ResetBatchAPIDiscountMultiplier() {
+	m.batch_api_discount_multiplier = nil
+	m.addbatch_api_discount_multiplier = nil
+}
+
+// SetBatchAPIHoldMultiplier sets the "batch_api_hold_multiplier" field.
+func (m *GroupMutation) SetBatchAPIHoldMultiplier(f float64) {
+	m.batch_api_hold_multiplier = &f
+	m.addbatch_api_hold_multiplier = nil
+}
+
+// BatchAPIHoldMultiplier returns the value of the "batch_api_hold_multiplier" field in the mutation.
+func (m *GroupMutation) BatchAPIHoldMultiplier() (r float64, exists bool) {
+	v := m.batch_api_hold_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldBatchAPIHoldMultiplier returns the old "batch_api_hold_multiplier" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldBatchAPIHoldMultiplier(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldBatchAPIHoldMultiplier is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldBatchAPIHoldMultiplier requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldBatchAPIHoldMultiplier: %w", err)
+	}
+	return oldValue.BatchAPIHoldMultiplier, nil
+}
+
+// AddBatchAPIHoldMultiplier adds f to the "batch_api_hold_multiplier" field.
+func (m *GroupMutation) AddBatchAPIHoldMultiplier(f float64) {
+	if m.addbatch_api_hold_multiplier != nil {
+		*m.addbatch_api_hold_multiplier += f
+	} else {
+		m.addbatch_api_hold_multiplier = &f
+	}
+}
+
+// AddedBatchAPIHoldMultiplier returns the value that was added to the "batch_api_hold_multiplier" field in this mutation.
+func (m *GroupMutation) AddedBatchAPIHoldMultiplier() (r float64, exists bool) {
+	v := m.addbatch_api_hold_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetBatchAPIHoldMultiplier resets all changes to the "batch_api_hold_multiplier" field.
+func (m *GroupMutation) ResetBatchAPIHoldMultiplier() {
+	m.batch_api_hold_multiplier = nil
+	m.addbatch_api_hold_multiplier = nil
+}
+
+// SetResponseCacheEnabled sets the "response_cache_enabled" field.
+func (m *GroupMutation) SetResponseCacheEnabled(b bool) {
+	m.response_cache_enabled = &b
+}
+
+// ResponseCacheEnabled returns the value of the "response_cache_enabled" field in the mutation.
+func (m *GroupMutation) ResponseCacheEnabled() (r bool, exists bool) {
+	v := m.response_cache_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheEnabled returns the old "response_cache_enabled" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheEnabled: %w", err)
+	}
+	return oldValue.ResponseCacheEnabled, nil
+}
+
+// ResetResponseCacheEnabled resets all changes to the "response_cache_enabled" field.
+func (m *GroupMutation) ResetResponseCacheEnabled() {
+	m.response_cache_enabled = nil
+}
+
+// SetResponseCacheTTLSeconds sets the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) SetResponseCacheTTLSeconds(i int) {
+	m.response_cache_ttl_seconds = &i
+	m.addresponse_cache_ttl_seconds = nil
+}
+
+// ResponseCacheTTLSeconds returns the value of the "response_cache_ttl_seconds" field in the mutation.
+func (m *GroupMutation) ResponseCacheTTLSeconds() (r int, exists bool) {
+	v := m.response_cache_ttl_seconds
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCacheTTLSeconds returns the old "response_cache_ttl_seconds" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCacheTTLSeconds(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCacheTTLSeconds is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCacheTTLSeconds requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCacheTTLSeconds: %w", err)
+	}
+	return oldValue.ResponseCacheTTLSeconds, nil
+}
+
+// AddResponseCacheTTLSeconds adds i to the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) AddResponseCacheTTLSeconds(i int) {
+	if m.addresponse_cache_ttl_seconds != nil {
+		*m.addresponse_cache_ttl_seconds += i
+	} else {
+		m.addresponse_cache_ttl_seconds = &i
+	}
+}
+
+// AddedResponseCacheTTLSeconds returns the value that was added to the "response_cache_ttl_seconds" field in this mutation.
+func (m *GroupMutation) AddedResponseCacheTTLSeconds() (r int, exists bool) {
+	v := m.addresponse_cache_ttl_seconds
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseCacheTTLSeconds resets all changes to the "response_cache_ttl_seconds" field.
+func (m *GroupMutation) ResetResponseCacheTTLSeconds() {
+	m.response_cache_ttl_seconds = nil
+	m.addresponse_cache_ttl_seconds = nil
+}
+
+// SetResponseCachePriceMultiplier sets the "response_cache_price_multiplier" field.
+func (m *GroupMutation) SetResponseCachePriceMultiplier(f float64) {
+	m.response_cache_price_multiplier = &f
+	m.addresponse_cache_price_multiplier = nil
+}
+
+// ResponseCachePriceMultiplier returns the value of the "response_cache_price_multiplier" field in the mutation.
+func (m *GroupMutation) ResponseCachePriceMultiplier() (r float64, exists bool) {
+	v := m.response_cache_price_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCachePriceMultiplier returns the old "response_cache_price_multiplier" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCachePriceMultiplier(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCachePriceMultiplier is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCachePriceMultiplier requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCachePriceMultiplier: %w", err)
+	}
+	return oldValue.ResponseCachePriceMultiplier, nil
+}
+
+// AddResponseCachePriceMultiplier adds f to the "response_cache_price_multiplier" field.
+func (m *GroupMutation) AddResponseCachePriceMultiplier(f float64) {
+	if m.addresponse_cache_price_multiplier != nil {
+		*m.addresponse_cache_price_multiplier += f
+	} else {
+		m.addresponse_cache_price_multiplier = &f
+	}
+}
+
+// AddedResponseCachePriceMultiplier returns the value that was added to the "response_cache_price_multiplier" field in this mutation.
+func (m *GroupMutation) AddedResponseCachePriceMultiplier() (r float64, exists bool) {
+	v := m.addresponse_cache_price_multiplier
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetResponseCachePriceMultiplier resets all changes to the "response_cache_price_multiplier" field.
+func (m *GroupMutation) ResetResponseCachePriceMultiplier() {
+	m.response_cache_price_multiplier = nil
+	m.addresponse_cache_price_multiplier = nil
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (m *GroupMutation) SetSchedulingStrategy(s string) {
+	m.scheduling_strategy = &s
+}
+
+// SchedulingStrategy returns the value of the "scheduling_strategy" field in the mutation.
+func (m *GroupMutation) SchedulingStrategy() (r string, exists bool) {
+	v := m.scheduling_strategy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSchedulingStrategy returns the old "scheduling_strategy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSchedulingStrategy(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSchedulingStrategy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSchedulingStrategy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSchedulingStrategy: %w", err)
+	}
+	return oldValue.SchedulingStrategy, nil
+}
+
+// ResetSchedulingStrategy resets all changes to the "scheduling_strategy" field.
+func (m *GroupMutation) ResetSchedulingStrategy() {
+	m.scheduling_strategy = nil
+}
+
 // SetVideoRateIndependent sets the "video_rate_independent" field.
 func (m *GroupMutation) SetVideoRateIndependent(b bool) {
 	m.video_rate_independent = &b
@@ -24087,6 +24846,76 @@ func (m *GroupMutation) ResetWebSearchPricePerCall() {
 	delete(m.clearedFields, group.FieldWebSearchPricePerCall)
 }
 
+// SetWebFetchPricePerCall sets the "web_fetch_price_per_call" field.
+func (m *GroupMutation) SetWebFetchPricePerCall(f float64) {
+	m.web_fetch_price_per_call = &f
+	m.addweb_fetch_price_per_call = nil
+}
+
+// WebFetchPricePerCall returns the value of the "web_fetch_price_per_call" field in the mutation.
+func (m *GroupMutation) WebFetchPricePerCall() (r float64, exists bool) {
+	v := m.web_fetch_price_per_call
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldWebFetchPricePerCall returns the old "web_fetch_price_per_call" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldWebFetchPricePerCall(ctx context.Context) (v *float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldWebFetchPricePerCall is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldWebFetchPricePerCall requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldWebFetchPricePerCall: %w", err)
+	}
+	return oldValue.WebFetchPricePerCall, nil
+}
+
+// AddWebFetchPricePerCall adds f to the "web_fetch_price_per_call" field.
+func (m *GroupMutation) AddWebFetchPricePerCall(f float64) {
+	if m.addweb_fetch_price_per_call != nil {
+		*m.addweb_fetch_price_per_call += f
+	} else {
+		m.addweb_fetch_price_per_call = &f
+	}
+}
+
+// AddedWebFetchPricePerCall returns the value that was added to the "web_fetch_price_per_call" field in this mutation.
+func (m *GroupMutation) AddedWebFetchPricePerCall() (r float64, exists bool) {
+	v := m.addweb_fetch_price_per_call
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearWebFetchPricePerCall clears the value of the "web_fetch_price_per_call" field.
+func (m *GroupMutation) ClearWebFetchPricePerCall() {
+	m.web_fetch_price_per_call = nil
+	m.addweb_fetch_price_per_call = nil
+	m.clearedFields[group.FieldWebFetchPricePerCall] = struct{}{}
+}
+
+// WebFetchPricePerCallCleared returns if the "web_fetch_price_per_call" field was cleared in this mutation.
+func (m *GroupMutation) WebFetchPricePerCallCleared() bool {
+	_, ok := m.clearedFields[group.FieldWebFetchPricePerCall]
+	return ok
+}
+
+// ResetWebFetchPricePerCall resets all changes to the "web_fetch_price_per_call" field.
+func (m *GroupMutation) ResetWebFetchPricePerCall() {
+	m.web_fetch_price_per_call = nil
+	m.addweb_fetch_price_per_call = nil
+	delete(m.clearedFields, group.FieldWebFetchPricePerCall)
+}
+
 // SetSearchPricePer1k sets the "search_price_per_1k" field.
 func (m *GroupMutation) SetSearchPricePer1k(f float64) {
 	m.search_price_per_1k = &f
@@ -24404,13 +25233,13 @@ func (m *GroupMutation) ResetLongContextPricingEnabled() {
 }
 
 // SetModelPricing sets the "model_pricing" field.
-func (m *GroupMutation) SetModelPricing(jm json.RawMessage) {
-	m.model_pricing = &jm
+func (m *GroupMutation) SetModelPricing(j jsontext.Value) {
+	m.model_pricing = &j
 	m.appendmodel_pricing = nil
 }
 
 // ModelPricing returns the value of the "model_pricing" field in the mutation.
-func (m *GroupMutation) ModelPricing() (r json.RawMessage, exists bool) {
+func (m *GroupMutation) ModelPricing() (r jsontext.Value, exists bool) {
 	v := m.model_pricing
 	if v == nil {
 		return
@@ -24421,7 +25250,7 @@ func (m *GroupMutation) ModelPricing() (r json.RawMessage, exists bool) {
 // OldModelPricing returns the old "model_pricing" field's value of the Group entity.
 // If the Group object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *GroupMutation) OldModelPricing(ctx context.Context) (v json.RawMessage, err error) {
+func (m *GroupMutation) OldModelPricing(ctx context.Context) (v jsontext.Value, err error) {
 	if !m.op.Is(OpUpdateOne) {
 		return v, errors.New("OldModelPricing is only allowed on UpdateOne operations")
 	}
@@ -24435,13 +25264,13 @@ func (m *GroupMutation) OldModelPricing(ctx context.Context) (v json.RawMessage,
 	return oldValue.ModelPricing, nil
 }
 
-// AppendModelPricing adds jm to the "model_pricing" field.
-func (m *GroupMutation) AppendModelPricing(jm json.RawMessage) {
-	m.appendmodel_pricing = append(m.appendmodel_pricing, jm...)
+// AppendModelPricing adds j to the "model_pricing" field.
+func (m *GroupMutation) AppendModelPricing(j jsontext.Value) {
+	m.appendmodel_pricing = append(m.appendmodel_pricing, j...)
 }
 
 // AppendedModelPricing returns the list of values that were appended to the "model_pricing" field in this mutation.
-func (m *GroupMutation) AppendedModelPricing() (json.RawMessage, bool) {
+func (m *GroupMutation) AppendedModelPricing() (jsontext.Value, bool) {
 	if len(m.appendmodel_pricing) == 0 {
 		return nil, false
 	}
@@ -25773,7 +26602,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 62)
+	fields := make([]string, 0, 70)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -25858,6 +26687,27 @@ func (m *GroupMutation) Fields() []string {
 	if m.batch_image_hold_multiplier != nil {
 		fields = append(fields, group.FieldBatchImageHoldMultiplier)
 	}
+	if m.allow_batch_api != nil {
+		fields = append(fields, group.FieldAllowBatchAPI)
+	}
+	if m.batch_api_discount_multiplier != nil {
+		fields = append(fields, group.FieldBatchAPIDiscountMultiplier)
+	}
+	if m.batch_api_hold_multiplier != nil {
+		fields = append(fields, group.FieldBatchAPIHoldMultiplier)
+	}
+	if m.response_cache_enabled != nil {
+		fields = append(fields, group.FieldResponseCacheEnabled)
+	}
+	if m.response_cache_ttl_seconds != nil {
+		fields = append(fields, group.FieldResponseCacheTTLSeconds)
+	}
+	if m.response_cache_price_multiplier != nil {
+		fields = append(fields, group.FieldResponseCachePriceMultiplier)
+	}
+	if m.scheduling_strategy != nil {
+		fields = append(fields, group.FieldSchedulingStrategy)
+	}
 	if m.video_rate_independent != nil {
 		fields = append(fields, group.FieldVideoRateIndependent)
 	}
@@ -25879,6 +26729,9 @@ func (m *GroupMutation) Fields() []string {
 	if m.web_search_price_per_call != nil {
 		fields = append(fields, group.FieldWebSearchPricePerCall)
 	}
+	if m.web_fetch_price_per_call != nil {
+		fields = append(fields, group.FieldWebFetchPricePerCall)
+	}
 	if m.search_price_per_1k != nil {
 		fields = append(fields, group.FieldSearchPricePer1k)
 	}
@@ -26024,6 +26877,20 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.BatchImageDiscountMultiplier()
 	case group.FieldBatchImageHoldMultiplier:
 		return m.BatchImageHoldMultiplier()
+	case group.FieldAllowBatchAPI:
+		return m.AllowBatchAPI()
+	case group.FieldBatchAPIDiscountMultiplier:
+		return m.BatchAPIDiscountMultiplier()
+	case group.FieldBatchAPIHoldMultiplier:
+		return m.BatchAPIHoldMultiplier()
+	case group.FieldResponseCacheEnabled:
+		return m.ResponseCacheEnabled()
+	case group.FieldResponseCacheTTLSeconds:
+		return m.ResponseCacheTTLSeconds()
+	case group.FieldResponseCachePriceMultiplier:
+		return m.ResponseCachePriceMultiplier()
+	case group.FieldSchedulingStrategy:
+		return m.SchedulingStrategy()
 	case group.FieldVideoRateIndependent:
 		return m.VideoRateIndependent()
 	case group.FieldVideoRateMultiplier:
@@ -26038,6 +26905,8 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.VideoModelPrices()
 	case group.FieldWebSearchPricePerCall:
 		return m.WebSearchPricePerCall()
+	case group.FieldWebFetchPricePerCall:
+		return m.WebFetchPricePerCall()
 	case group.FieldSearchPricePer1k:
 		return m.SearchPricePer1k()
 	case group.FieldAudioRealtimePricePerMin:
@@ -26157,6 +27026,20 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldBatchImageDiscountMultiplier(ctx)
 	case group.FieldBatchImageHoldMultiplier:
 		return m.OldBatchImageHoldMultiplier(ctx)
+	case group.FieldAllowBatchAPI:
+		return m.OldAllowBatchAPI(ctx)
+	case group.FieldBatchAPIDiscountMultiplier:
+		return m.OldBatchAPIDiscountMultiplier(ctx)
+	case group.FieldBatchAPIHoldMultiplier:
+		return m.OldBatchAPIHoldMultiplier(ctx)
+	case group.FieldResponseCacheEnabled:
+		return m.OldResponseCacheEnabled(ctx)
+	case group.FieldResponseCacheTTLSeconds:
+		return m.OldResponseCacheTTLSeconds(ctx)
+	case group.FieldResponseCachePriceMultiplier:
+		return m.OldResponseCachePriceMultiplier(ctx)
+	case group.FieldSchedulingStrategy:
+		return m.OldSchedulingStrategy(ctx)
 	case group.FieldVideoRateIndependent:
 		return m.OldVideoRateIndependent(ctx)
 	case group.FieldVideoRateMultiplier:
@@ -26171,6 +27054,8 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldVideoModelPrices(ctx)
 	case group.FieldWebSearchPricePerCall:
 		return m.OldWebSearchPricePerCall(ctx)
+	case group.FieldWebFetchPricePerCall:
+		return m.OldWebFetchPricePerCall(ctx)
 	case group.FieldSearchPricePer1k:
 		return m.OldSearchPricePer1k(ctx)
 	case group.FieldAudioRealtimePricePerMin:
@@ -26430,6 +27315,55 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetBatchImageHoldMultiplier(v)
 		return nil
+	case group.FieldAllowBatchAPI:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowBatchAPI(v)
+		return nil
+	case group.FieldBatchAPIDiscountMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBatchAPIDiscountMultiplier(v)
+		return nil
+	case group.FieldBatchAPIHoldMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetBatchAPIHoldMultiplier(v)
+		return nil
+	case group.FieldResponseCacheEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheEnabled(v)
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCacheTTLSeconds(v)
+		return nil
+	case group.FieldResponseCachePriceMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCachePriceMultiplier(v)
+		return nil
+	case group.FieldSchedulingStrategy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSchedulingStrategy(v)
+		return nil
 	case group.FieldVideoRateIndependent:
 		v, ok := value.(bool)
 		if !ok {
@@ -26479,6 +27413,13 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWebSearchPricePerCall(v)
 		return nil
+	case group.FieldWebFetchPricePerCall:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetWebFetchPricePerCall(v)
+		return nil
 	case group.FieldSearchPricePer1k:
 		v, ok := value.(float64)
 		if !ok {
@@ -26515,7 +27456,7 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		m.SetLongContextPricingEnabled(v)
 		return nil
 	case group.FieldModelPricing:
-		v, ok := value.(json.RawMessage)
+		v, ok := value.(jsontext.Value)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
@@ -26712,6 +27653,18 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addbatch_image_hold_multiplier != nil {
 		fields = append(fields, group.FieldBatchImageHoldMultiplier)
 	}
+	if m.addbatch_api_discount_multiplier != nil {
+		fields = append(fields, group.FieldBatchAPIDiscountMultiplier)
+	}
+	if m.addbatch_api_hold_multiplier != nil {
+		fields = append(fields, group.FieldBatchAPIHoldMultiplier)
+	}
+	if m.addresponse_cache_ttl_seconds != nil {
+		fields = append(fields, group.FieldResponseCacheTTLSeconds)
+	}
+	if m.addresponse_cache_price_multiplier != nil {
+		fields = append(fields, group.FieldResponseCachePriceMultiplier)
+	}
 	if m.addvideo_rate_multiplier != nil {
 		fields = append(fields, group.FieldVideoRateMultiplier)
 	}
@@ -26727,6 +27680,9 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addweb_search_price_per_call != nil {
 		fields = append(fields, group.FieldWebSearchPricePerCall)
 	}
+	if m.addweb_fetch_price_per_call != nil {
+		fields = append(fields, group.FieldWebFetchPricePerCall)
+	}
 	if m.addsearch_price_per_1k != nil {
 		fields = append(fields, group.FieldSearchPricePer1k)
 	}
@@ -26789,6 +27745,14 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedBatchImageDiscountMultiplier()
 	case group.FieldBatchImageHoldMultiplier:
 		return m.AddedBatchImageHoldMultiplier()
+	case group.FieldBatchAPIDiscountMultiplier:
+		return m.AddedBatchAPIDiscountMultiplier()
+	case group.FieldBatchAPIHoldMultiplier:
+		return m.AddedBatchAPIHoldMultiplier()
+	case group.FieldResponseCacheTTLSeconds:
+		return m.AddedResponseCacheTTLSeconds()
+	case group.FieldResponseCachePriceMultiplier:
+		return m.AddedResponseCachePriceMultiplier()
 	case group.FieldVideoRateMultiplier:
 		return m.AddedVideoRateMultiplier()
 	case group.FieldVideoPrice480p:
@@ -26799,6 +27763,8 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedVideoPrice1080p()
 	case group.FieldWebSearchPricePerCall:
 		return m.AddedWebSearchPricePerCall()
+	case group.FieldWebFetchPricePerCall:
+		return m.AddedWebFetchPricePerCall()
 	case group.FieldSearchPricePer1k:
 		return m.AddedSearchPricePer1k()
 	case group.FieldAudioRealtimePricePerMin:
@@ -26912,6 +27878,34 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddBatchImageHoldMultiplier(v)
 		return nil
+	case group.FieldBatchAPIDiscountMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddBatchAPIDiscountMultiplier(v)
+		return nil
+	case group.FieldBatchAPIHoldMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddBatchAPIHoldMultiplier(v)
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseCacheTTLSeconds(v)
+		return nil
+	case group.FieldResponseCachePriceMultiplier:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddResponseCachePriceMultiplier(v)
+		return nil
 	case group.FieldVideoRateMultiplier:
 		v, ok := value.(float64)
 		if !ok {
@@ -26947,6 +27941,13 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddWebSearchPricePerCall(v)
 		return nil
+	case group.FieldWebFetchPricePerCall:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddWebFetchPricePerCall(v)
+		return nil
 	case group.FieldSearchPricePer1k:
 		v, ok := value.(float64)
 		if !ok {
@@ -27067,6 +28068,9 @@ func (m *GroupMutation) ClearedFields() []string {
 	if m.FieldCleared(group.FieldWebSearchPricePerCall) {
 		fields = append(fields, group.FieldWebSearchPricePerCall)
 	}
+	if m.FieldCleared(group.FieldWebFetchPricePerCall) {
+		fields = append(fields, group.FieldWebFetchPricePerCall)
+	}
 	if m.FieldCleared(group.FieldSearchPricePer1k) {
 		fields = append(fields, group.FieldSearchPricePer1k)
 	}
@@ -27147,6 +28151,9 @@ func (m *GroupMutation) ClearField(name string) error {
 	case group.FieldWebSearchPricePerCall:
 		m.ClearWebSearchPricePerCall()
 		return nil
+	case group.FieldWebFetchPricePerCall:
+		m.ClearWebFetchPricePerCall()
+		return nil
 	case group.FieldSearchPricePer1k:
 		m.ClearSearchPricePer1k()
 		return nil
@@ -27263,6 +28270,27 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldBatchImageHoldMultiplier:
 		m.ResetBatchImageHoldMultiplier()
 		return nil
+	case group.FieldAllowBatchAPI:
+		m.ResetAllowBatchAPI()
+		return nil
+	case group.FieldBatchAPIDiscountMultiplier:
+		m.ResetBatchAPIDiscountMultiplier()
+		return nil
+	case group.FieldBatchAPIHoldMultiplier:
+		m.ResetBatchAPIHoldMultiplier()
+		return nil
+	case group.FieldResponseCacheEnabled:
+		m.ResetResponseCacheEnabled()
+		return nil
+	case group.FieldResponseCacheTTLSeconds:
+		m.ResetResponseCacheTTLSeconds()
+		return nil
+	case group.FieldResponseCachePriceMultiplier:
+		m.ResetResponseCachePriceMultiplier()
+		return nil
+	case group.FieldSchedulingStrategy:
+		m.ResetSchedulingStrategy()
+		return nil
 	case group.FieldVideoRateIndependent:
 		m.ResetVideoRateIndependent()
 		return nil
@@ -27284,6 +28312,9 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldWebSearchPricePerCall:
 		m.ResetWebSearchPricePerCall()
 		return nil
+	case group.FieldWebFetchPricePerCall:
+		m.ResetWebFetchPricePerCall()
+		return nil
 	case group.FieldSearchPricePer1k:
 		m.ResetSearchPricePer1k()
 		return nil
@@ -43151,8 +44182,8 @@ type UsageCleanupTaskMutation struct {
 	created_at      *time.Time
 	updated_at      *time.Time
 	status          *string
-	filters         *json.RawMessage
-	appendfilters   json.RawMessage
+	filters         *jsontext.Value
+	appendfilters   jsontext.Value
 	created_by      *int64
 	addcreated_by   *int64
 	deleted_rows    *int64
@@ -43376,13 +44407,13 @@ func (m *UsageCleanupTaskMutation) ResetStatus() {
 }
 
 // SetFilters sets the "filters" field.
-func (m *UsageCleanupTaskMutation) SetFilters(jm json.RawMessage) {
-	m.filters = &jm
+func (m *UsageCleanupTaskMutation) SetFilters(j jsontext.Value) {
+	m.filters = &j
 	m.appendfilters = nil
 }
 
 // Filters returns the value of the "filters" field in the mutation.
-func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
+func (m *UsageCleanupTaskMutation) Filters() (r jsontext.Value, exists bool) {
 	v := m.filters
 	if v == nil {
 		return
@@ -43393,7 +44424,7 @@ func (m *UsageCleanupTaskMutation) Filters() (r json.RawMessage, exists bool) {
 // OldFilters returns the old "filters" field's value of the UsageCleanupTask entity.
 // If the UsageCleanupTask object wasn't provided to the builder, the object is fetched from the database.
 // An error is returned if the mutation operation is not UpdateOne, or the database query fails.
-func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMessage, err error) {
+func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v jsontext.Value, err error) {
 	if !m.op.Is(OpUpdateOne) {
 		return v, errors.New("OldFilters is only allowed on UpdateOne operations")
 	}
@@ -43407,13 +44438,13 @@ func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMe
 	return oldValue.Filters, nil
 }
 
-// AppendFilters adds jm to the "filters" field.
-func (m *UsageCleanupTaskMutation) AppendFilters(jm json.RawMessage) {
-	m.appendfilters = append(m.appendfilters, jm...)
+// AppendFilters adds j to the "filters" field.
+func (m *UsageCleanupTaskMutation) AppendFilters(j jsontext.Value) {
+	m.appendfilters = append(m.appendfilters, j...)
 }
 
 // AppendedFilters returns the list of values that were appended to the "filters" field in this mutation.
-func (m *UsageCleanupTaskMutation) AppendedFilters() (json.RawMessage, bool) {
+func (m *UsageCleanupTaskMutation) AppendedFilters() (jsontext.Value, bool) {
 	if len(m.appendfilters) == 0 {
 		return nil, false
 	}
@@ -43964,7 +44995,7 @@ func (m *UsageCleanupTaskMutation) SetField(name string, value ent.Value) error
 		m.SetStatus(v)
 		return nil
 	case usagecleanuptask.FieldFilters:
-		v, ok := value.(json.RawMessage)
+		v, ok := value.(jsontext.Value)
 		if !ok {
 			return fmt.Errorf("unexpected type %T for field %s", value, name)
 		}
@@ -115,6 +115,29 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		// ========== Response cache ==========
+		field.Bool("response_cache_enabled").
+			Default(false).
+			Comment("Opt this key into the exact-match response cache even if its group has not enabled it"),
+
+		// ========== Model policy ==========
+		field.JSON("model_allowlist", []string{}).
+			Optional().
+			Comment("Allowed model patterns (trailing * wildcard); empty = all models of the group"),
+		field.JSON("model_denylist", []string{}).
+			Optional().
+			Comment("Denied model patterns (trailing * wildcard); checked before the allowlist"),
+		field.JSON("model_aliases", map[string]string{}).
+			Optional().
+			Comment("Per-key model aliases, e.g. {\"team-default\": \"claude-sonnet-4-5\"}"),
+
+		// ========== Organization ==========
+		field.Int64("organization_id").
+			Optional().
+			Nillable().
+			Immutable().
+			Comment("Organization whose shared wallet pays for this key; NULL for personal keys"),
 	}
 }
 
@@ -144,5 +167,6 @@ func (APIKey) Indexes() []ent.Index {
 		// Index for quota queries
 		index.Fields("quota", "quota_used"),
 		index.Fields("expires_at"),
+		index.Fields("organization_id"),
 	}
 }
@@ -131,6 +131,37 @@ func (Group) Fields() []ent.Field {
 			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
 			Default(0.6).
 			Comment("批量图片生成冻结价格比例，按普通生图原价乘以该比例冻结，结算后释放差额"),
+
+		// 文本 Batch API（/v1/batches）配置
+		field.Bool("allow_batch_api").
+			Default(false).
+			Comment("是否允许该分组使用 OpenAI 兼容 Batch API（/v1/batches、/v1/files）"),
+		field.Float("batch_api_discount_multiplier").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
+			Default(0.5).
+			Comment("Batch API 折扣倍率，在分组有效倍率之上再乘以该值；0 表示免费"),
+		field.Float("batch_api_hold_multiplier").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
+			Default(0.6).
+			Comment("Batch API 冻结价格比例，按原价预估（含 max_tokens 上限）乘以该比例冻结，结算后释放差额"),
+
+		// 精确匹配响应缓存配置
+		field.Bool("response_cache_enabled").
+			Default(false).
+			Comment("是否对该分组启用精确匹配响应缓存（/v1/messages、/v1/chat/completions、/v1/responses）"),
+		field.Int("response_cache_ttl_seconds").
+			Default(0).
+			Comment("响应缓存 TTL（秒），0 表示使用全局默认值"),
+		field.Float("response_cache_price_multiplier").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(10,4)"}).
+			Default(0).
+			Comment("缓存命中计费倍率，在分组有效倍率之上再乘以该值；0 表示命中免费"),
+
+		// 账号调度策略
+		field.String("scheduling_strategy").
+			MaxLen(32).
+			Default("").
+			Comment("账号调度策略：空或 default 沿用平台默认调度，另可选 least_latency、cost_aware、weighted_round_robin"),
 		field.Bool("video_rate_independent").
 			Default(false).
 			Comment("视频生成是否使用独立倍率；false 表示共享分组有效倍率"),
@@ -159,6 +190,11 @@ func (Group) Fields() []ent.Field {
 			Nillable().
 			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
 			Comment("Codex alpha/search 网页搜索单次价格（USD/次）；nil 表示使用默认价 0.01（官方 $10/1000 次）"),
+		field.Float("web_fetch_price_per_call").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Comment("网关模拟 web_fetch 工具的单次抓取价格（USD/次）；nil 表示使用默认价 0.01"),
 
 		// 搜索/工具调用显式定价（per 1k calls），用于 Grok web_search 等。
 		field.Float("search_price_per_1k").
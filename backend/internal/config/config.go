@@ -10,6 +10,7 @@ import (
 	"net/textproto"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -71,6 +72,8 @@ type Config struct {
 	Database                DatabaseConfig                `mapstructure:"database"`
 	Redis                   RedisConfig                   `mapstructure:"redis"`
 	Ops                     OpsConfig                     `mapstructure:"ops"`
+	Metrics                 MetricsConfig                 `mapstructure:"metrics"`
+	Tracing                 TracingConfig                 `mapstructure:"tracing"`
 	JWT                     JWTConfig                     `mapstructure:"jwt"`
 	Totp                    TotpConfig                    `mapstructure:"totp"`
 	WebAuthn                WebAuthnConfig                `mapstructure:"webauthn"`
@@ -78,6 +81,7 @@ type Config struct {
 	WeChat                  WeChatConnectConfig           `mapstructure:"wechat_connect"`
 	OIDC                    OIDCConnectConfig             `mapstructure:"oidc_connect"`
 	DingTalk                DingTalkConnectConfig         `mapstructure:"dingtalk_connect"`
+	SAML                    SAMLConfig                    `mapstructure:"saml"`
 	GitHubOAuth             EmailOAuthProviderConfig      `mapstructure:"github_oauth"`
 	GoogleOAuth             EmailOAuthProviderConfig      `mapstructure:"google_oauth"`
 	Default                 DefaultConfig                 `mapstructure:"default"`
@@ -99,6 +103,20 @@ type Config struct {
 	Idempotency             IdempotencyConfig             `mapstructure:"idempotency"`
 	BatchImage              BatchImageConfig              `mapstructure:"batch_image"`
 	ImageStorage            ImageStorageConfig            `mapstructure:"image_storage"`
+	UserWebhook             UserWebhookConfig             `mapstructure:"user_webhook"`
+	BatchAPI                BatchAPIConfig                `mapstructure:"batch_api"`
+	ResponseCache           ResponseCacheConfig           `mapstructure:"response_cache"`
+	Organization            OrganizationConfig            `mapstructure:"organization"`
+	Budget                  BudgetConfig                  `mapstructure:"budget"`
+	BalanceLedger           BalanceLedgerConfig           `mapstructure:"balance_ledger"`
+	Invoice                 InvoiceConfig                 `mapstructure:"invoice"`
+	UsageExport             UsageExportConfig             `mapstructure:"usage_export"`
+	Secrets                 SecretsConfig                 `mapstructure:"secrets"`
+
+	// secretRefs 启动时由密钥引用解析得到的配置项（路径 -> 引用），见 resolveConfigSecrets
+	secretRefs map[string]string
+	// live 热重载发布的最新配置快照，由 load 创建并在副本间共享，见 Live 与 Reload
+	live *atomic.Pointer[Config]
 }
 
 type LogConfig struct {
@@ -343,6 +361,49 @@ type OIDCConnectConfig struct {
 	UserInfoUsernamePath string `mapstructure:"userinfo_username_path"`
 }
 
+// SAMLConfig SAML 2.0 单点登录（SP 发起 + 可选 IdP 发起）。
+// IdP 元数据优先使用管理后台导入的 XML，其次为 idp_metadata_xml，最后为 idp_metadata_url。
+type SAMLConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	ProviderName string `mapstructure:"provider_name"` // 登录按钮显示名
+	// SP 标识；为空时使用 SP 元数据地址
+	EntityID string `mapstructure:"entity_id"`
+	// 后端 ACS 地址（需在 IdP 登记），例如 https://your-domain.com/api/v1/auth/oauth/saml/acs
+	ACSURL string `mapstructure:"acs_url"`
+	// 前端接收登录结果的路由（默认：/auth/saml/callback）
+	FrontendRedirectURL string `mapstructure:"frontend_redirect_url"`
+
+	// 可选：SP 签名证书与私钥（PEM 内容或文件路径），用于签名 AuthnRequest 和解密加密断言
+	SPCertificate        string `mapstructure:"sp_certificate"`
+	SPPrivateKey         string `mapstructure:"sp_private_key"`
+	SignAuthnRequests    bool   `mapstructure:"sign_authn_requests"`
+	IdPMetadataURL       string `mapstructure:"idp_metadata_url"`
+	IdPMetadataXML       string `mapstructure:"idp_metadata_xml"`
+	AllowIdPInitiated    bool   `mapstructure:"allow_idp_initiated"`
+	ClockSkewSeconds     int    `mapstructure:"clock_skew_seconds"`
+	MetadataCacheSeconds int    `mapstructure:"metadata_cache_seconds"`
+
+	// 属性映射：为空时按常见属性名（mail / email / emailaddress claim 等）回退
+	EmailAttribute       string `mapstructure:"email_attribute"`
+	UsernameAttribute    string `mapstructure:"username_attribute"`
+	DisplayNameAttribute string `mapstructure:"display_name_attribute"`
+	GroupsAttribute      string `mapstructure:"groups_attribute"`
+	// IdP 已校验邮箱时置 true：断言中的邮箱可用于匹配已有账号（仍需走绑定确认）
+	TrustEmail bool `mapstructure:"trust_email"`
+
+	// 角色映射：用户的 IdP 分组命中任一 admin_groups 时授予管理员；
+	// 为空时不改动角色。配置后每次 SAML 登录都会按分组同步（包括降级）。
+	AdminGroups []string `mapstructure:"admin_groups"`
+	// 分组映射：IdP 分组 → 本地分组 ID（增量加入 allowed_groups，不会移除）
+	GroupMappings []SAMLGroupMapping `mapstructure:"group_mappings"`
+}
+
+// SAMLGroupMapping 把一个 IdP 分组映射到若干本地分组。
+type SAMLGroupMapping struct {
+	IdPGroup string  `mapstructure:"idp_group"`
+	GroupIDs []int64 `mapstructure:"group_ids"`
+}
+
 type DingTalkConnectConfig struct {
 	Enabled             bool   `mapstructure:"enabled"`
 	ClientID            string `mapstructure:"client_id"`
@@ -710,6 +771,8 @@ type SecurityConfig struct {
 	CSP             CSPConfig            `mapstructure:"csp"`
 	ProxyFallback   ProxyFallbackConfig  `mapstructure:"proxy_fallback"`
 	ProxyProbe      ProxyProbeConfig     `mapstructure:"proxy_probe"`
+	// CredentialEncryption 账号凭证（accounts.credentials 敏感子键）静态加密
+	CredentialEncryption CredentialEncryptionConfig `mapstructure:"credential_encryption"`
 	// TrustForwardedIPForAPIKeyACL enables legacy raw forwarded-header takeover.
 	// When disabled, server.trusted_proxies is authoritative for all client-IP consumers.
 	TrustForwardedIPForAPIKeyACL  bool                                       `mapstructure:"trust_forwarded_ip_for_api_key_acl"`
@@ -842,6 +905,55 @@ type ProbeURLConfig struct {
 	Parser string `mapstructure:"parser"` // "ip-api" / "ipify" / "chatgpt-trace"
 }
 
+// CredentialEncryptionConfig 账号凭证静态加密配置（信封加密）。
+//
+// 敏感子键（见 service.SensitiveCredentialKeys）由数据密钥（DEK）加密，DEK 存于
+// credential_encryption_keys 表并由这里配置的主密钥（KEK）包裹。轮换主密钥只需新增
+// 一个 master_keys 条目并切换 active_master_key_id，启动时自动重新包裹 DEK；
+// 旧主密钥需保留到所有实例重启完成。
+type CredentialEncryptionConfig struct {
+	// Enabled 为 false 时新写入保持明文，重加密任务会把已加密的值解密回明文
+	// （前提是仍配置了对应的主密钥）。
+	Enabled bool `mapstructure:"enabled"`
+	// ActiveMasterKeyID 用于包裹新 DEK 的主密钥 ID
+	ActiveMasterKeyID string `mapstructure:"active_master_key_id"`
+	// MasterKeys 主密钥 ID -> 32 字节 hex 编码密钥。ID 不区分大小写（按小写处理）。
+	MasterKeys map[string]string `mapstructure:"master_keys"`
+	// ReencryptBatchSize 重加密任务每批处理的账号数
+	ReencryptBatchSize int `mapstructure:"reencrypt_batch_size"`
+}
+
+var credentialEncryptionKeyIDPattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+func validateCredentialEncryptionConfig(cfg *CredentialEncryptionConfig) error {
+	normalized := make(map[string]string, len(cfg.MasterKeys))
+	for id, key := range cfg.MasterKeys {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if !credentialEncryptionKeyIDPattern.MatchString(id) {
+			return fmt.Errorf("master_keys: invalid key id %q (allowed: a-z, 0-9, _ and -)", id)
+		}
+		raw, err := hex.DecodeString(strings.TrimSpace(key))
+		if err != nil || len(raw) != 32 {
+			return fmt.Errorf("master_keys.%s must be 32 bytes (64 hex chars)", id)
+		}
+		normalized[id] = strings.TrimSpace(key)
+	}
+	cfg.MasterKeys = normalized
+	cfg.ActiveMasterKeyID = strings.ToLower(strings.TrimSpace(cfg.ActiveMasterKeyID))
+	if cfg.ActiveMasterKeyID != "" {
+		if _, ok := cfg.MasterKeys[cfg.ActiveMasterKeyID]; !ok {
+			return fmt.Errorf("active_master_key_id %q is not defined in master_keys", cfg.ActiveMasterKeyID)
+		}
+	}
+	if cfg.Enabled && cfg.ActiveMasterKeyID == "" {
+		return fmt.Errorf("active_master_key_id is required when enabled")
+	}
+	if cfg.ReencryptBatchSize <= 0 {
+		return fmt.Errorf("reencrypt_batch_size must be positive")
+	}
+	return nil
+}
+
 func normalizeProxyProbeURLs(targets []ProbeURLConfig) ([]ProbeURLConfig, error) {
 	if len(targets) == 0 {
 		return nil, nil
@@ -1589,6 +1701,189 @@ type OpsConfig struct {
 	Aggregation OpsAggregationConfig `mapstructure:"aggregation"`
 }
 
+// MetricsConfig controls the Prometheus scrape endpoint.
+type MetricsConfig struct {
+	// Enabled exposes GET /metrics and starts recording gateway metrics.
+	Enabled bool `mapstructure:"enabled"`
+	// BearerToken, when set, must be sent as "Authorization: Bearer <token>".
+	BearerToken string `mapstructure:"bearer_token"`
+
+	// Cardinality controls: the first N distinct values of each label are
+	// exported and later values collapse into "other". 0 drops the label.
+	MaxAccountSeries int `mapstructure:"max_account_series"`
+	MaxGroupSeries   int `mapstructure:"max_group_series"`
+	MaxModelSeries   int `mapstructure:"max_model_series"`
+
+	// ConcurrencyRefreshSeconds caches the per-account slot usage gathered on
+	// scrape so that frequent scrapes do not hammer Redis and the database.
+	ConcurrencyRefreshSeconds int `mapstructure:"concurrency_refresh_seconds"`
+}
+
+// TracingConfig controls OpenTelemetry span export for the gateway forward path.
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ServiceName defaults to log.service_name when empty.
+	ServiceName string `mapstructure:"service_name"`
+	// Exporter: otlp_http (OTLP/protobuf over HTTP), otlp_grpc, stdout or file.
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint is the collector URL, e.g. http://otel-collector:4318 for otlp_http (/v1/traces is appended
+	// when no path is given) or http://otel-collector:4317 for otlp_grpc. An http scheme disables TLS.
+	Endpoint string            `mapstructure:"endpoint"`
+	Headers  map[string]string `mapstructure:"headers"`
+	// FilePath receives one JSON span per line when Exporter is file.
+	FilePath string `mapstructure:"file_path"`
+	// SampleRatio applies to new traces; requests carrying a traceparent follow the caller's sampled flag.
+	SampleRatio          float64 `mapstructure:"sample_ratio"`
+	ExportTimeoutSeconds int     `mapstructure:"export_timeout_seconds"`
+	BatchSize            int     `mapstructure:"batch_size"`
+	QueueSize            int     `mapstructure:"queue_size"`
+	FlushIntervalSeconds int     `mapstructure:"flush_interval_seconds"`
+	// MaxSpansPerTrace caps child spans per request (Redis/DB phases can be numerous).
+	MaxSpansPerTrace int `mapstructure:"max_spans_per_trace"`
+}
+
+// UserWebhookConfig 用户自助配置的出站 Webhook（余额不足、额度耗尽、订阅到期、支付完成等事件）。
+// 目标 URL 的 http/私网放行策略沿用 security.url_allowlist.allow_insecure_http / allow_private_hosts。
+type UserWebhookConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxEndpointsPerUser 每个用户最多可配置的 endpoint 数量
+	MaxEndpointsPerUser int `mapstructure:"max_endpoints_per_user"`
+	// MaxAttempts 单次投递的最大尝试次数（含首次），用尽后标记为 failed
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RequestTimeoutSeconds 单次 HTTP 投递超时（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// WorkerConcurrency dispatcher 并发投递数
+	WorkerConcurrency int `mapstructure:"worker_concurrency"`
+	// DeliveryRetentionDays 投递日志保留天数（0 = 不清理）
+	DeliveryRetentionDays int `mapstructure:"delivery_retention_days"`
+}
+
+// BatchAPIConfig OpenAI 兼容 Batch API（/v1/files + /v1/batches）。
+// 批次内的每一行由网关 worker 在账号空闲时按普通网关请求执行，按分组 batch_api_discount_multiplier 折扣计费。
+type BatchAPIConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxFileBytes 单个上传文件字节上限
+	MaxFileBytes int64 `mapstructure:"max_file_bytes"`
+	// MaxRequestsPerBatch 单个批次最多包含的请求行数
+	MaxRequestsPerBatch int `mapstructure:"max_requests_per_batch"`
+	// WorkerConcurrency 单实例同时执行的批次请求数
+	WorkerConcurrency int `mapstructure:"worker_concurrency"`
+	// PerBatchConcurrency 单个批次同时执行的请求数
+	PerBatchConcurrency int `mapstructure:"per_batch_concurrency"`
+	// IdleLoadThreshold 分组可调度账号的平均负载率（0-100）低于该值时才派发批次请求
+	IdleLoadThreshold int `mapstructure:"idle_load_threshold"`
+	// RequestTimeoutSeconds 单个批次请求的执行超时（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// DefaultMaxOutputTokens 请求未指定输出上限时，用于估算冻结额的输出 token 数
+	DefaultMaxOutputTokens int `mapstructure:"default_max_output_tokens"`
+	// FileRetentionDays 上传文件与结果文件的保留天数
+	FileRetentionDays int `mapstructure:"file_retention_days"`
+}
+
+// ResponseCacheConfig 精确匹配响应缓存（/v1/messages、/v1/chat/completions、/v1/responses）。
+// 是否启用由分组 response_cache_enabled 或 API Key response_cache_enabled 决定，这里只是全局开关与默认值。
+type ResponseCacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DefaultTTLSeconds 分组未配置 TTL 时的缓存有效期（秒）
+	DefaultTTLSeconds int `mapstructure:"default_ttl_seconds"`
+	// MaxEntryBytes 单条缓存响应体的字节上限，超出则不缓存
+	MaxEntryBytes int `mapstructure:"max_entry_bytes"`
+	// KeyPrefix Redis 键前缀
+	KeyPrefix string `mapstructure:"key_prefix"`
+}
+
+// OrganizationConfig 组织（团队）：共享钱包、成员角色与组织 API Key。
+type OrganizationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxOwnedPerUser 每个用户最多可创建（拥有）的组织数量
+	MaxOwnedPerUser int `mapstructure:"max_owned_per_user"`
+	// InvitationTTLHours 邀请链接有效期（小时）
+	InvitationTTLHours int `mapstructure:"invitation_ttl_hours"`
+	// BillingStateCacheSeconds 网关侧组织余额 / 成员月度消费的本地缓存时间（秒），0 表示每次查库
+	BillingStateCacheSeconds int `mapstructure:"billing_state_cache_seconds"`
+}
+
+// BudgetConfig 用户 / API Key / 分组的自然周期预算（硬上限拒绝请求，软上限仅通知）。
+type BudgetConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxPerScope 每个用户 / API Key / 分组最多可挂载的预算条数
+	MaxPerScope int `mapstructure:"max_per_scope"`
+	// StateCacheSeconds 网关侧预算及本周期消费的本地缓存时间（秒），0 表示每次查库
+	StateCacheSeconds int `mapstructure:"state_cache_seconds"`
+}
+
+// BalanceLedgerConfig 余额流水对账任务与用户账单导出。
+type BalanceLedgerConfig struct {
+	// ReconcileIntervalMinutes 对账任务执行间隔（分钟），0 表示关闭定时对账（仍可在后台手动触发）
+	ReconcileIntervalMinutes int `mapstructure:"reconcile_interval_minutes"`
+	// ReconcileWindowHours 每次对账回看的时间窗口（小时）
+	ReconcileWindowHours int `mapstructure:"reconcile_window_hours"`
+	// ReconcileGraceMinutes 窗口末尾留出的宽限时间（分钟），等待异步写入的用量日志落库
+	ReconcileGraceMinutes int `mapstructure:"reconcile_grace_minutes"`
+	// MaxFindingsPerKind 每次对账每类差异最多保存的明细条数
+	MaxFindingsPerKind int `mapstructure:"max_findings_per_kind"`
+	// StatementMaxRows 单次账单导出的最大流水条数
+	StatementMaxRows int `mapstructure:"statement_max_rows"`
+}
+
+// InvoiceConfig 收据与月度用量对账单。开票方信息印在每张票据上。
+type InvoiceConfig struct {
+	// NumberPrefix 票据编号前缀，编号格式为 <prefix>-<年份>-<6 位序号>，按自然年连续递增
+	NumberPrefix string `mapstructure:"number_prefix"`
+	// AutoIssueIntervalMinutes 自动开票任务执行间隔（分钟）：为已支付订单开收据、
+	// 为上一自然月有扣费用量的用户 / 组织开对账单；0 表示关闭（仍可按需开具）
+	AutoIssueIntervalMinutes int `mapstructure:"auto_issue_interval_minutes"`
+	// AutoIssueBatchSize 每轮自动开票每类票据最多处理的数量
+	AutoIssueBatchSize int `mapstructure:"auto_issue_batch_size"`
+	// IssuerName / IssuerTaxID / IssuerAddress / IssuerEmail 开票方信息
+	IssuerName    string `mapstructure:"issuer_name"`
+	IssuerTaxID   string `mapstructure:"issuer_tax_id"`
+	IssuerAddress string `mapstructure:"issuer_address"`
+	IssuerEmail   string `mapstructure:"issuer_email"`
+}
+
+// UsageExportConfig usage_logs 原始明细定时导出到 S3 兼容存储（CSV / Parquet），供数仓增量加载。
+type UsageExportConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Cron 五段式 cron 表达式（系统时区），默认每天 01:30
+	Cron string `mapstructure:"cron"`
+	// Format 导出格式：csv（gzip 压缩）或 parquet（zstd 压缩）
+	Format string `mapstructure:"format"`
+	// BatchSize 每个导出批次最多包含的行数；每批按日期拆分为若干文件
+	BatchSize int `mapstructure:"batch_size"`
+	// LagMinutes 只导出早于 now-lag 的记录，等待异步写入的用量日志落库，避免水位越过未提交的行
+	LagMinutes int `mapstructure:"lag_minutes"`
+	// TimeoutMinutes 单次导出任务的最长执行时间（分钟），超时后下次调度从水位继续
+	TimeoutMinutes int `mapstructure:"timeout_minutes"`
+	// S3 目标存储（兼容 AWS S3 / MinIO / R2 / OSS）
+	S3 UsageExportS3Config `mapstructure:"s3"`
+}
+
+// UsageExportS3Config 导出目标存储配置。
+type UsageExportS3Config struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	// Prefix 对象 key 前缀，如 "exports/"
+	Prefix         string `mapstructure:"prefix"`
+	ForcePathStyle bool   `mapstructure:"force_path_style"`
+}
+
+// isValidInvoiceNumberPrefix 编号前缀限 1-16 个 ASCII 字母、数字或连字符。
+func isValidInvoiceNumberPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 16 {
+		return false
+	}
+	for _, r := range prefix {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
 type OpsCleanupConfig struct {
 	Enabled  bool   `mapstructure:"enabled"`
 	Schedule string `mapstructure:"schedule"`
@@ -1802,6 +2097,9 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 		cfg.Security.ForwardedClientIPHeaders = normalizeStringSlice(strings.Split(forwardedClientIPHeadersEnv, ","))
 	}
 	cfg.Server.TrustedProxiesConfigured = trustedProxiesConfigured
+	if err := resolveConfigSecrets(&cfg); err != nil {
+		return nil, fmt.Errorf("resolve secret references: %w", err)
+	}
 	if cfg.Gateway.OpenAIScheduler.StickyEscapeTTFTMs == 0 {
 		cfg.Gateway.OpenAIScheduler.StickyEscapeTTFTMs = 15000
 	}
@@ -1855,6 +2153,17 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.OIDC.UserInfoUsernamePath = strings.TrimSpace(cfg.OIDC.UserInfoUsernamePath)
 	cfg.OIDC.UsePKCEExplicit = hasExplicitConfigOrEnv("oidc_connect.use_pkce", "OIDC_CONNECT_USE_PKCE")
 	cfg.OIDC.ValidateIDTokenExplicit = hasExplicitConfigOrEnv("oidc_connect.validate_id_token", "OIDC_CONNECT_VALIDATE_ID_TOKEN")
+	cfg.SAML.ProviderName = strings.TrimSpace(cfg.SAML.ProviderName)
+	cfg.SAML.EntityID = strings.TrimSpace(cfg.SAML.EntityID)
+	cfg.SAML.ACSURL = strings.TrimSpace(cfg.SAML.ACSURL)
+	cfg.SAML.FrontendRedirectURL = strings.TrimSpace(cfg.SAML.FrontendRedirectURL)
+	cfg.SAML.IdPMetadataURL = strings.TrimSpace(cfg.SAML.IdPMetadataURL)
+	cfg.SAML.IdPMetadataXML = strings.TrimSpace(cfg.SAML.IdPMetadataXML)
+	cfg.SAML.EmailAttribute = strings.TrimSpace(cfg.SAML.EmailAttribute)
+	cfg.SAML.UsernameAttribute = strings.TrimSpace(cfg.SAML.UsernameAttribute)
+	cfg.SAML.DisplayNameAttribute = strings.TrimSpace(cfg.SAML.DisplayNameAttribute)
+	cfg.SAML.GroupsAttribute = strings.TrimSpace(cfg.SAML.GroupsAttribute)
+	cfg.SAML.AdminGroups = normalizeStringSlice(cfg.SAML.AdminGroups)
 	cfg.Dashboard.KeyPrefix = strings.TrimSpace(cfg.Dashboard.KeyPrefix)
 	cfg.CORS.AllowedOrigins = normalizeStringSlice(cfg.CORS.AllowedOrigins)
 	cfg.Security.ResponseHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Security.ResponseHeaders.AdditionalAllowed)
@@ -1947,6 +2256,7 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 		)
 	}
 
+	cfg.live = new(atomic.Pointer[Config])
 	return &cfg, nil
 }
 
@@ -2043,6 +2353,9 @@ func setDefaults() {
 	viper.SetDefault("security.csp.enabled", true)
 	viper.SetDefault("security.csp.policy", DefaultCSPPolicy)
 	viper.SetDefault("security.proxy_probe.insecure_skip_verify", false)
+	viper.SetDefault("security.credential_encryption.enabled", false)
+	viper.SetDefault("security.credential_encryption.active_master_key_id", "")
+	viper.SetDefault("security.credential_encryption.reencrypt_batch_size", 200)
 	viper.SetDefault("security.trust_forwarded_ip_for_api_key_acl", true)
 
 	// Security - disable direct fallback on proxy error
@@ -2118,6 +2431,27 @@ func setDefaults() {
 	viper.SetDefault("oidc_connect.userinfo_id_path", "")
 	viper.SetDefault("oidc_connect.userinfo_username_path", "")
 
+	// SAML 2.0 SSO
+	viper.SetDefault("saml.enabled", false)
+	viper.SetDefault("saml.provider_name", "SSO")
+	viper.SetDefault("saml.entity_id", "")
+	viper.SetDefault("saml.acs_url", "")
+	viper.SetDefault("saml.frontend_redirect_url", "/auth/saml/callback")
+	viper.SetDefault("saml.sp_certificate", "")
+	viper.SetDefault("saml.sp_private_key", "")
+	viper.SetDefault("saml.sign_authn_requests", false)
+	viper.SetDefault("saml.idp_metadata_url", "")
+	viper.SetDefault("saml.idp_metadata_xml", "")
+	viper.SetDefault("saml.allow_idp_initiated", false)
+	viper.SetDefault("saml.clock_skew_seconds", 120)
+	viper.SetDefault("saml.metadata_cache_seconds", 3600)
+	viper.SetDefault("saml.email_attribute", "")
+	viper.SetDefault("saml.username_attribute", "")
+	viper.SetDefault("saml.display_name_attribute", "")
+	viper.SetDefault("saml.groups_attribute", "")
+	viper.SetDefault("saml.trust_email", false)
+	viper.SetDefault("saml.admin_groups", []string{})
+
 	// DingTalk Connect OAuth 登录
 	viper.SetDefault("dingtalk_connect.enabled", false)
 	viper.SetDefault("dingtalk_connect.authorize_url", "https://login.dingtalk.com/oauth2/auth")
@@ -2237,6 +2571,27 @@ func setDefaults() {
 	// TTL should be slightly larger than collection interval (1m) to maximize cross-replica cache hits.
 	viper.SetDefault("ops.metrics_collector_cache.ttl", 65*time.Second)
 
+	// Metrics (Prometheus scrape endpoint)
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.bearer_token", "")
+	viper.SetDefault("metrics.max_account_series", 500)
+	viper.SetDefault("metrics.max_group_series", 200)
+	viper.SetDefault("metrics.max_model_series", 100)
+	viper.SetDefault("metrics.concurrency_refresh_seconds", 15)
+
+	// Tracing (OpenTelemetry OTLP export)
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.service_name", "")
+	viper.SetDefault("tracing.exporter", "otlp_http")
+	viper.SetDefault("tracing.endpoint", "http://localhost:4318")
+	viper.SetDefault("tracing.file_path", "")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.export_timeout_seconds", 10)
+	viper.SetDefault("tracing.batch_size", 256)
+	viper.SetDefault("tracing.queue_size", 4096)
+	viper.SetDefault("tracing.flush_interval_seconds", 5)
+	viper.SetDefault("tracing.max_spans_per_trace", 512)
+
 	// JWT
 	viper.SetDefault("jwt.secret", "")
 	viper.SetDefault("jwt.expire_hour", 24)
@@ -2327,6 +2682,88 @@ func setDefaults() {
 	viper.SetDefault("idempotency.cleanup_interval_seconds", 60)
 	viper.SetDefault("idempotency.cleanup_batch_size", 500)
 
+	// User webhooks
+	viper.SetDefault("user_webhook.enabled", true)
+	viper.SetDefault("user_webhook.max_endpoints_per_user", 10)
+	viper.SetDefault("user_webhook.max_attempts", 8)
+	viper.SetDefault("user_webhook.request_timeout_seconds", 10)
+	viper.SetDefault("user_webhook.worker_concurrency", 8)
+	viper.SetDefault("user_webhook.delivery_retention_days", 30)
+
+	// Batch API
+	viper.SetDefault("batch_api.enabled", true)
+	viper.SetDefault("batch_api.max_file_bytes", 100*1024*1024)
+	viper.SetDefault("batch_api.max_requests_per_batch", 10000)
+	viper.SetDefault("batch_api.worker_concurrency", 4)
+	viper.SetDefault("batch_api.per_batch_concurrency", 2)
+	viper.SetDefault("batch_api.idle_load_threshold", 60)
+	viper.SetDefault("batch_api.request_timeout_seconds", 600)
+	viper.SetDefault("batch_api.default_max_output_tokens", 4096)
+	viper.SetDefault("batch_api.file_retention_days", 30)
+
+	// Response cache
+	viper.SetDefault("response_cache.enabled", true)
+	viper.SetDefault("response_cache.default_ttl_seconds", 3600)
+	viper.SetDefault("response_cache.max_entry_bytes", 1024*1024)
+	viper.SetDefault("response_cache.key_prefix", "response_cache:")
+
+	// Organization
+	viper.SetDefault("organization.enabled", true)
+	viper.SetDefault("organization.max_owned_per_user", 5)
+	viper.SetDefault("organization.invitation_ttl_hours", 168)
+	viper.SetDefault("organization.billing_state_cache_seconds", 10)
+
+	// Budget
+	viper.SetDefault("budget.enabled", true)
+	viper.SetDefault("budget.max_per_scope", 10)
+	viper.SetDefault("budget.state_cache_seconds", 10)
+
+	// Balance ledger
+	viper.SetDefault("balance_ledger.reconcile_interval_minutes", 60)
+	viper.SetDefault("balance_ledger.reconcile_window_hours", 24)
+	viper.SetDefault("balance_ledger.reconcile_grace_minutes", 10)
+	viper.SetDefault("balance_ledger.max_findings_per_kind", 100)
+	viper.SetDefault("balance_ledger.statement_max_rows", 10000)
+
+	// Invoice
+	viper.SetDefault("invoice.number_prefix", "INV")
+	viper.SetDefault("invoice.auto_issue_interval_minutes", 60)
+	viper.SetDefault("invoice.auto_issue_batch_size", 500)
+	viper.SetDefault("invoice.issuer_name", "")
+	viper.SetDefault("invoice.issuer_tax_id", "")
+	viper.SetDefault("invoice.issuer_address", "")
+	viper.SetDefault("invoice.issuer_email", "")
+
+	// Usage export
+	viper.SetDefault("usage_export.enabled", false)
+	viper.SetDefault("usage_export.cron", "30 1 * * *")
+	viper.SetDefault("usage_export.format", "parquet")
+	viper.SetDefault("usage_export.batch_size", 100000)
+	viper.SetDefault("usage_export.lag_minutes", 10)
+	viper.SetDefault("usage_export.timeout_minutes", 60)
+	viper.SetDefault("usage_export.s3.endpoint", "")
+	viper.SetDefault("usage_export.s3.region", "")
+	viper.SetDefault("usage_export.s3.bucket", "")
+	viper.SetDefault("usage_export.s3.access_key_id", "")
+	viper.SetDefault("usage_export.s3.secret_access_key", "")
+	viper.SetDefault("usage_export.s3.prefix", "")
+	viper.SetDefault("usage_export.s3.force_path_style", false)
+
+	// Secrets backend
+	viper.SetDefault("secrets.cache_ttl_seconds", 300)
+	viper.SetDefault("secrets.refresh_interval_seconds", 300)
+	viper.SetDefault("secrets.timeout_seconds", 10)
+	viper.SetDefault("secrets.vault.address", "")
+	viper.SetDefault("secrets.vault.token", "")
+	viper.SetDefault("secrets.vault.token_file", "")
+	viper.SetDefault("secrets.vault.namespace", "")
+	viper.SetDefault("secrets.vault.kv_version", 2)
+	viper.SetDefault("secrets.vault.transit_mount", "transit")
+	viper.SetDefault("secrets.kms.endpoint", "")
+	viper.SetDefault("secrets.kms.key_id", "")
+	viper.SetDefault("secrets.kms.token", "")
+	viper.SetDefault("secrets.kms.token_file", "")
+
 	// Gateway
 	viper.SetDefault("gateway.response_header_timeout", 600) // 600秒(10分钟)等待上游响应头，LLM高负载时可能排队较久
 	viper.SetDefault("gateway.openai_response_header_timeout", 0)
@@ -2625,6 +3062,12 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("security.proxy_probe.urls: %w", err)
 	}
 	c.Security.ProxyProbe.URLs = proxyProbeURLs
+	if err := validateCredentialEncryptionConfig(&c.Security.CredentialEncryption); err != nil {
+		return fmt.Errorf("security.credential_encryption.%w", err)
+	}
+	if err := c.Secrets.validate(); err != nil {
+		return fmt.Errorf("secrets.%w", err)
+	}
 	if c.Server.ReadHeaderTimeout < 1 || c.Server.ReadHeaderTimeout > 60 {
 		return fmt.Errorf("server.read_header_timeout must be between 1 and 60 seconds")
 	}
@@ -2991,6 +3434,46 @@ func (c *Config) Validate() error {
 		warnIfInsecureURL("oidc_connect.redirect_url", c.OIDC.RedirectURL)
 		warnIfInsecureURL("oidc_connect.frontend_redirect_url", c.OIDC.FrontendRedirectURL)
 	}
+	if c.SAML.Enabled {
+		if strings.TrimSpace(c.SAML.ACSURL) == "" {
+			return fmt.Errorf("saml.acs_url is required when saml.enabled=true")
+		}
+		if err := ValidateAbsoluteHTTPURL(c.SAML.ACSURL); err != nil {
+			return fmt.Errorf("saml.acs_url invalid: %w", err)
+		}
+		if v := strings.TrimSpace(c.SAML.IdPMetadataURL); v != "" {
+			if err := ValidateAbsoluteHTTPURL(v); err != nil {
+				return fmt.Errorf("saml.idp_metadata_url invalid: %w", err)
+			}
+		}
+		if err := ValidateFrontendRedirectURL(c.SAML.FrontendRedirectURL); err != nil {
+			return fmt.Errorf("saml.frontend_redirect_url invalid: %w", err)
+		}
+		if (strings.TrimSpace(c.SAML.SPCertificate) == "") != (strings.TrimSpace(c.SAML.SPPrivateKey) == "") {
+			return fmt.Errorf("saml.sp_certificate and saml.sp_private_key must be set together")
+		}
+		if c.SAML.SignAuthnRequests && strings.TrimSpace(c.SAML.SPPrivateKey) == "" {
+			return fmt.Errorf("saml.sp_private_key is required when saml.sign_authn_requests=true")
+		}
+		if c.SAML.ClockSkewSeconds < 0 || c.SAML.ClockSkewSeconds > 600 {
+			return fmt.Errorf("saml.clock_skew_seconds must be between 0 and 600")
+		}
+		if c.SAML.MetadataCacheSeconds < 0 {
+			return fmt.Errorf("saml.metadata_cache_seconds must be non-negative")
+		}
+		for i, mapping := range c.SAML.GroupMappings {
+			if strings.TrimSpace(mapping.IdPGroup) == "" {
+				return fmt.Errorf("saml.group_mappings[%d].idp_group is required", i)
+			}
+			for _, id := range mapping.GroupIDs {
+				if id <= 0 {
+					return fmt.Errorf("saml.group_mappings[%d].group_ids must be positive", i)
+				}
+			}
+		}
+		warnIfInsecureURL("saml.acs_url", c.SAML.ACSURL)
+		warnIfInsecureURL("saml.idp_metadata_url", c.SAML.IdPMetadataURL)
+	}
 	if c.Billing.CircuitBreaker.Enabled {
 		if c.Billing.CircuitBreaker.FailureThreshold <= 0 {
 			return fmt.Errorf("billing.circuit_breaker.failure_threshold must be positive")
@@ -3227,6 +3710,120 @@ func (c *Config) Validate() error {
 	if c.Idempotency.CleanupBatchSize <= 0 {
 		return fmt.Errorf("idempotency.cleanup_batch_size must be positive")
 	}
+	if c.UserWebhook.Enabled {
+		if c.UserWebhook.MaxEndpointsPerUser <= 0 {
+			return fmt.Errorf("user_webhook.max_endpoints_per_user must be positive")
+		}
+		if c.UserWebhook.MaxAttempts <= 0 {
+			return fmt.Errorf("user_webhook.max_attempts must be positive")
+		}
+		if c.UserWebhook.RequestTimeoutSeconds <= 0 {
+			return fmt.Errorf("user_webhook.request_timeout_seconds must be positive")
+		}
+		if c.UserWebhook.WorkerConcurrency <= 0 {
+			return fmt.Errorf("user_webhook.worker_concurrency must be positive")
+		}
+	}
+	if c.UserWebhook.DeliveryRetentionDays < 0 {
+		return fmt.Errorf("user_webhook.delivery_retention_days must be non-negative")
+	}
+	if c.BatchAPI.Enabled {
+		if c.BatchAPI.MaxFileBytes <= 0 {
+			return fmt.Errorf("batch_api.max_file_bytes must be positive")
+		}
+		if c.BatchAPI.MaxRequestsPerBatch <= 0 {
+			return fmt.Errorf("batch_api.max_requests_per_batch must be positive")
+		}
+		if c.BatchAPI.WorkerConcurrency <= 0 {
+			return fmt.Errorf("batch_api.worker_concurrency must be positive")
+		}
+		if c.BatchAPI.PerBatchConcurrency <= 0 {
+			return fmt.Errorf("batch_api.per_batch_concurrency must be positive")
+		}
+		if c.BatchAPI.IdleLoadThreshold <= 0 || c.BatchAPI.IdleLoadThreshold > 100 {
+			return fmt.Errorf("batch_api.idle_load_threshold must be between 1 and 100")
+		}
+		if c.BatchAPI.RequestTimeoutSeconds <= 0 {
+			return fmt.Errorf("batch_api.request_timeout_seconds must be positive")
+		}
+		if c.BatchAPI.DefaultMaxOutputTokens <= 0 {
+			return fmt.Errorf("batch_api.default_max_output_tokens must be positive")
+		}
+		if c.BatchAPI.FileRetentionDays <= 0 {
+			return fmt.Errorf("batch_api.file_retention_days must be positive")
+		}
+	}
+	if c.ResponseCache.Enabled {
+		if c.ResponseCache.DefaultTTLSeconds <= 0 {
+			return fmt.Errorf("response_cache.default_ttl_seconds must be positive")
+		}
+		if c.ResponseCache.MaxEntryBytes <= 0 {
+			return fmt.Errorf("response_cache.max_entry_bytes must be positive")
+		}
+	}
+	if c.Organization.Enabled {
+		if c.Organization.MaxOwnedPerUser <= 0 {
+			return fmt.Errorf("organization.max_owned_per_user must be positive")
+		}
+		if c.Organization.InvitationTTLHours <= 0 {
+			return fmt.Errorf("organization.invitation_ttl_hours must be positive")
+		}
+	}
+	if c.Organization.BillingStateCacheSeconds < 0 {
+		return fmt.Errorf("organization.billing_state_cache_seconds must be non-negative")
+	}
+	if c.Budget.Enabled && c.Budget.MaxPerScope <= 0 {
+		return fmt.Errorf("budget.max_per_scope must be positive")
+	}
+	if c.Budget.StateCacheSeconds < 0 {
+		return fmt.Errorf("budget.state_cache_seconds must be non-negative")
+	}
+	if c.BalanceLedger.ReconcileIntervalMinutes < 0 {
+		return fmt.Errorf("balance_ledger.reconcile_interval_minutes must be non-negative")
+	}
+	if c.BalanceLedger.ReconcileWindowHours <= 0 {
+		return fmt.Errorf("balance_ledger.reconcile_window_hours must be positive")
+	}
+	if c.BalanceLedger.ReconcileGraceMinutes < 0 {
+		return fmt.Errorf("balance_ledger.reconcile_grace_minutes must be non-negative")
+	}
+	if c.BalanceLedger.MaxFindingsPerKind <= 0 {
+		return fmt.Errorf("balance_ledger.max_findings_per_kind must be positive")
+	}
+	if c.BalanceLedger.StatementMaxRows <= 0 {
+		return fmt.Errorf("balance_ledger.statement_max_rows must be positive")
+	}
+	if !isValidInvoiceNumberPrefix(c.Invoice.NumberPrefix) {
+		return fmt.Errorf("invoice.number_prefix must be 1-16 letters, digits or dashes")
+	}
+	if c.Invoice.AutoIssueIntervalMinutes < 0 {
+		return fmt.Errorf("invoice.auto_issue_interval_minutes must be non-negative")
+	}
+	if c.Invoice.AutoIssueBatchSize <= 0 {
+		return fmt.Errorf("invoice.auto_issue_batch_size must be positive")
+	}
+	switch c.UsageExport.Format {
+	case "csv", "parquet":
+	default:
+		return fmt.Errorf("usage_export.format must be one of: csv, parquet")
+	}
+	if c.UsageExport.BatchSize <= 0 {
+		return fmt.Errorf("usage_export.batch_size must be positive")
+	}
+	if c.UsageExport.LagMinutes < 0 {
+		return fmt.Errorf("usage_export.lag_minutes must be non-negative")
+	}
+	if c.UsageExport.TimeoutMinutes <= 0 {
+		return fmt.Errorf("usage_export.timeout_minutes must be positive")
+	}
+	if c.UsageExport.Enabled {
+		if strings.TrimSpace(c.UsageExport.Cron) == "" {
+			return fmt.Errorf("usage_export.cron is required when usage_export.enabled=true")
+		}
+		if strings.TrimSpace(c.UsageExport.S3.Bucket) == "" {
+			return fmt.Errorf("usage_export.s3.bucket is required when usage_export.enabled=true")
+		}
+	}
 	if c.Gateway.MaxBodySize <= 0 {
 		return fmt.Errorf("gateway.max_body_size must be positive")
 	}
@@ -3640,6 +4237,34 @@ func (c *Config) Validate() error {
 	if c.Ops.Cleanup.Enabled && strings.TrimSpace(c.Ops.Cleanup.Schedule) == "" {
 		return fmt.Errorf("ops.cleanup.schedule is required when ops.cleanup.enabled=true")
 	}
+	if c.Metrics.MaxAccountSeries < 0 || c.Metrics.MaxGroupSeries < 0 || c.Metrics.MaxModelSeries < 0 {
+		return fmt.Errorf("metrics.max_*_series must be non-negative")
+	}
+	if c.Metrics.ConcurrencyRefreshSeconds < 0 {
+		return fmt.Errorf("metrics.concurrency_refresh_seconds must be non-negative")
+	}
+	if c.Tracing.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.Tracing.Exporter)) {
+		case "otlp_http", "otlp_grpc":
+			if strings.TrimSpace(c.Tracing.Endpoint) == "" {
+				return fmt.Errorf("tracing.endpoint is required when tracing.exporter=%s", c.Tracing.Exporter)
+			}
+		case "stdout":
+		case "file":
+			if strings.TrimSpace(c.Tracing.FilePath) == "" {
+				return fmt.Errorf("tracing.file_path is required when tracing.exporter=file")
+			}
+		default:
+			return fmt.Errorf("tracing.exporter must be one of: otlp_http/otlp_grpc/stdout/file")
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
+	if c.Tracing.ExportTimeoutSeconds < 0 || c.Tracing.BatchSize < 0 || c.Tracing.QueueSize < 0 ||
+		c.Tracing.FlushIntervalSeconds < 0 || c.Tracing.MaxSpansPerTrace < 0 {
+		return fmt.Errorf("tracing timeouts and sizes must be non-negative")
+	}
 	if c.Concurrency.PingInterval < 5 || c.Concurrency.PingInterval > 30 {
 		return fmt.Errorf("concurrency.ping_interval must be between 5-30 seconds")
 	}
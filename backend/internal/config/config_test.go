@@ -680,6 +680,25 @@ func TestLoadDefaultIdempotencyConfig(t *testing.T) {
 	}
 }
 
+func TestLoadDefaultUserWebhookConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.UserWebhook.Enabled)
+	require.Equal(t, 10, cfg.UserWebhook.MaxEndpointsPerUser)
+	require.Equal(t, 8, cfg.UserWebhook.MaxAttempts)
+	require.Equal(t, 30, cfg.UserWebhook.DeliveryRetentionDays)
+}
+
+func TestValidateUserWebhookConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	t.Setenv("USER_WEBHOOK_MAX_ATTEMPTS", "0")
+
+	_, err := Load()
+	require.ErrorContains(t, err, "user_webhook.max_attempts must be positive")
+}
+
 func TestLoadDefaultBatchImageQueueDisabled(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -2589,3 +2608,75 @@ func TestLoad_DefaultGatewayImageStreamConfig(t *testing.T) {
 		t.Fatalf("image stream timeout = %d, want greater than ordinary stream timeout %d", cfg.Gateway.ImageStreamDataIntervalTimeout, cfg.Gateway.StreamDataIntervalTimeout)
 	}
 }
+
+func TestValidateTracingConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Tracing.Enabled || cfg.Tracing.Exporter != "otlp_http" || cfg.Tracing.SampleRatio != 1 {
+		t.Fatalf("unexpected tracing defaults: %+v", cfg.Tracing)
+	}
+
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Exporter = "file"
+	cfg.Tracing.FilePath = ""
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "tracing.file_path") {
+		t.Fatalf("Validate() expected tracing.file_path error, got: %v", err)
+	}
+
+	cfg.Tracing.Exporter = "jaeger"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "tracing.exporter") {
+		t.Fatalf("Validate() expected tracing.exporter error, got: %v", err)
+	}
+
+	cfg.Tracing.Exporter = "stdout"
+	cfg.Tracing.SampleRatio = 1.5
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "tracing.sample_ratio") {
+		t.Fatalf("Validate() expected tracing.sample_ratio error, got: %v", err)
+	}
+
+	cfg.Tracing.SampleRatio = 0.1
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestValidateSAMLRequiresACSURLAndPairedKeyMaterial(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.SAML.ProviderName != "SSO" || cfg.SAML.ClockSkewSeconds != 120 || cfg.SAML.FrontendRedirectURL != "/auth/saml/callback" {
+		t.Fatalf("unexpected saml defaults: %+v", cfg.SAML)
+	}
+
+	cfg.SAML.Enabled = true
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "saml.acs_url") {
+		t.Fatalf("Validate() expected saml.acs_url error, got: %v", err)
+	}
+
+	cfg.SAML.ACSURL = "https://example.com/api/v1/auth/oauth/saml/acs"
+	cfg.SAML.SPCertificate = "/etc/sub2api/saml-sp.crt"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "saml.sp_certificate") {
+		t.Fatalf("Validate() expected paired key material error, got: %v", err)
+	}
+
+	cfg.SAML.SPCertificate = ""
+	cfg.SAML.GroupMappings = []SAMLGroupMapping{{IdPGroup: "engineering", GroupIDs: []int64{0}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "saml.group_mappings[0]") {
+		t.Fatalf("Validate() expected group mapping error, got: %v", err)
+	}
+
+	cfg.SAML.GroupMappings = []SAMLGroupMapping{{IdPGroup: "engineering", GroupIDs: []int64{1, 2}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() expected valid saml config, got: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+//go:build unit
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCredentialEncryptionConfig(t *testing.T) {
+	t.Parallel()
+
+	validKey := strings.Repeat("ab", 32)
+	cfg := CredentialEncryptionConfig{
+		Enabled:            true,
+		ActiveMasterKeyID:  " KEK-2026 ",
+		MasterKeys:         map[string]string{"kek-2026": validKey, "kek_old": " " + validKey + " "},
+		ReencryptBatchSize: 100,
+	}
+	require.NoError(t, validateCredentialEncryptionConfig(&cfg))
+	require.Equal(t, "kek-2026", cfg.ActiveMasterKeyID)
+	require.Equal(t, validKey, cfg.MasterKeys["kek_old"])
+
+	tests := []struct {
+		name    string
+		cfg     CredentialEncryptionConfig
+		wantErr string
+	}{
+		{
+			name:    "enabled without active key",
+			cfg:     CredentialEncryptionConfig{Enabled: true, MasterKeys: map[string]string{"k1": validKey}, ReencryptBatchSize: 1},
+			wantErr: "active_master_key_id is required",
+		},
+		{
+			name:    "active key not defined",
+			cfg:     CredentialEncryptionConfig{ActiveMasterKeyID: "k2", MasterKeys: map[string]string{"k1": validKey}, ReencryptBatchSize: 1},
+			wantErr: "not defined in master_keys",
+		},
+		{
+			name:    "short key",
+			cfg:     CredentialEncryptionConfig{MasterKeys: map[string]string{"k1": "abcd"}, ReencryptBatchSize: 1},
+			wantErr: "must be 32 bytes",
+		},
+		{
+			name:    "invalid key id",
+			cfg:     CredentialEncryptionConfig{MasterKeys: map[string]string{"k:1": validKey}, ReencryptBatchSize: 1},
+			wantErr: "invalid key id",
+		},
+		{
+			name:    "non-positive batch size",
+			cfg:     CredentialEncryptionConfig{},
+			wantErr: "reencrypt_batch_size must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			err := validateCredentialEncryptionConfig(&cfg)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
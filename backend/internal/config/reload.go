@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// hotReloadablePaths 列出可在运行期生效的配置项（mapstructure 路径）。
+//
+// 只收录读取方在每次请求/每个周期都通过 Config.Live 读取的标量字段：重载会发布一份
+// 新的配置快照，读取方下一次访问即可看到新值。启动时被拷贝进客户端、连接池、限流器或
+// Redis TTL 的字段（如 response_header_timeout、concurrency.ping_interval、
+// scheduling.*_wait_timeout）不在此列，变更后会被报告为需要重启。
+//
+// 新增条目时必须同时把该字段的读取方改为 cfg.Live().xxx，否则新值不会生效。
+var hotReloadablePaths = map[string]bool{
+	// 请求体限制（路由中间件与全局限制按请求读取）
+	"server.max_request_body_size": true,
+	"gateway.max_body_size":        true,
+	"gateway.text_max_body_size":   true,
+
+	// 超时与流式读取
+	"gateway.stream_data_interval_timeout":                    true,
+	"gateway.stream_keepalive_interval":                       true,
+	"gateway.max_line_size":                                   true,
+	"gateway.openai_first_output_timeout_seconds":             true,
+	"gateway.openai_high_effort_first_output_timeout_seconds": true,
+	"gateway.upstream_response_read_max_bytes":                true,
+	"gateway.failover_on_400":                                 true,
+	"gateway.log_upstream_error_body":                         true,
+	"gateway.log_upstream_error_body_max_bytes":               true,
+
+	// 调度
+	"gateway.scheduling.sticky_session_max_waiting":     true,
+	"gateway.scheduling.fallback_max_waiting":           true,
+	"gateway.scheduling.prefer_soonest_reset":           true,
+	"gateway.scheduling.load_batch_enabled":             true,
+	"gateway.scheduling.db_fallback_enabled":            true,
+	"gateway.scheduling.db_fallback_timeout_seconds":    true,
+	"gateway.scheduling.outbox_lag_warn_seconds":        true,
+	"gateway.scheduling.outbox_lag_rebuild_seconds":     true,
+	"gateway.scheduling.outbox_backlog_rebuild_rows":    true,
+	"gateway.scheduling.outbox_lag_rebuild_failures":    true,
+	"gateway.openai_scheduler.sticky_escape_enabled":    true,
+	"gateway.openai_scheduler.sticky_escape_ttft_ms":    true,
+	"gateway.openai_scheduler.sticky_escape_error_rate": true,
+
+	// 用户消息串行队列（服务持有 &cfg.Gateway.UserMessageQueue）
+	"gateway.user_message_queue.lock_ttl_ms":     true,
+	"gateway.user_message_queue.wait_timeout_ms": true,
+	"gateway.user_message_queue.min_delay_ms":    true,
+	"gateway.user_message_queue.max_delay_ms":    true,
+
+	// 运维监控
+	"ops.use_preaggregated_tables": true,
+	"ops.aggregation.enabled":      true,
+}
+
+// ReloadReport 描述一次配置重载的结果。只包含配置路径，不包含取值，避免密钥进入日志。
+type ReloadReport struct {
+	// Applied 已在运行期生效的配置项
+	Applied []string `json:"applied"`
+	// RestartRequired 已变更但需要重启进程才能生效的配置项
+	RestartRequired []string `json:"restart_required"`
+}
+
+// Changed 报告新配置与当前运行配置是否存在差异。
+func (r *ReloadReport) Changed() bool {
+	return r != nil && (len(r.Applied) > 0 || len(r.RestartRequired) > 0)
+}
+
+var reloadMu sync.Mutex
+
+// Live 返回当前生效的配置快照。
+//
+// 热重载从不修改运行中的 *Config：Reload 基于最新快照复制出一份新配置，写入可热更新的
+// 字段后原子发布，读取可热更新字段的调用方通过 Live 取得快照，不会与重载产生数据竞争。
+// 尚未发生过重载、或不是由 Load 系列函数加载的配置（例如测试中直接构造的）返回自身。
+func (c *Config) Live() *Config {
+	if c == nil || c.live == nil {
+		return c
+	}
+	if snapshot := c.live.Load(); snapshot != nil {
+		return snapshot
+	}
+	return c
+}
+
+// Reload 重新读取并校验配置文件，把可热更新的字段写入新的配置快照并发布，返回变更报告。
+//
+// 校验失败时不发布新快照，current.Live() 保持不变。
+func Reload(current *Config) (*ReloadReport, error) {
+	if current == nil {
+		return nil, fmt.Errorf("reload config: current config is nil")
+	}
+	if current.live == nil {
+		return nil, fmt.Errorf("reload config: current config was not loaded from a config file")
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	next, err := load(true)
+	if err != nil {
+		return nil, err
+	}
+	base := current.Live()
+	carryOverRuntimeFields(base, next)
+	// 浅拷贝即可：只有标量字段会被改写，切片、map 等仍与旧快照共享且不会被修改
+	snapshot := *base
+	report := applyReload(&snapshot, next)
+	if len(report.Applied) > 0 {
+		current.live.Store(&snapshot)
+	}
+	return report, nil
+}
+
+// WatchConfigFile 监听当前使用的配置文件，文件变更时调用 onChange。
+// 未找到配置文件（纯默认值/环境变量启动）时返回 false。
+func WatchConfigFile(onChange func()) bool {
+	if viper.ConfigFileUsed() == "" {
+		return false
+	}
+	viper.OnConfigChange(func(fsnotify.Event) { onChange() })
+	viper.WatchConfig()
+	return true
+}
+
+// carryOverRuntimeFields 保留运行期才确定的字段，避免它们被误判为变更。
+func carryOverRuntimeFields(current, next *Config) {
+	// 未显式配置时 TOTP 密钥每次加载都会随机生成
+	if !next.Totp.EncryptionKeyConfigured {
+		next.Totp.EncryptionKey = current.Totp.EncryptionKey
+		next.Totp.EncryptionKeyConfigured = current.Totp.EncryptionKeyConfigured
+	}
+	// jwt.secret 可留空，由数据库初始化流程在启动时补齐
+	if next.JWT.Secret == "" {
+		next.JWT.Secret = current.JWT.Secret
+	}
+}
+
+// applyReload 把 next 中可热更新的字段写入 current（尚未发布的快照），其余差异记为需要重启。
+func applyReload(current, next *Config) *ReloadReport {
+	report := &ReloadReport{Applied: []string{}, RestartRequired: []string{}}
+	diffConfigValues(reflect.ValueOf(current).Elem(), reflect.ValueOf(next).Elem(), "", func(path string, cur, nxt reflect.Value) {
+		if hotReloadablePaths[path] && isHotReloadableKind(cur.Kind()) {
+			cur.Set(nxt)
+			report.Applied = append(report.Applied, path)
+			return
+		}
+		report.RestartRequired = append(report.RestartRequired, path)
+	})
+	sort.Strings(report.Applied)
+	sort.Strings(report.RestartRequired)
+	return report
+}
+
+func isHotReloadableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// diffConfigValues 按 mapstructure 路径递归比较两个配置结构体，对每个不同的叶子调用 onDiff。
+// 非结构体字段（切片、map、指针）整体比较。
+func diffConfigValues(cur, next reflect.Value, prefix string, onDiff func(path string, cur, next reflect.Value)) {
+	t := cur.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, squash := configFieldName(field)
+		if name == "-" {
+			continue
+		}
+		path := prefix
+		if !squash {
+			path = joinConfigPath(prefix, name)
+		}
+		cf, nf := cur.Field(i), next.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			diffConfigValues(cf, nf, path, onDiff)
+			continue
+		}
+		if !reflect.DeepEqual(cf.Interface(), nf.Interface()) {
+			onDiff(path, cf, nf)
+		}
+	}
+}
+
+func configFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+	squash := strings.Contains(opts, "squash")
+	if name == "" && !squash {
+		name = strings.ToLower(field.Name)
+	}
+	return name, squash
+}
+
+func joinConfigPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadAppliesHotFieldsFromConfigFile(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("gateway:\n  max_body_size: 67108864\n  response_header_timeout: 600\n"), 0o600))
+	t.Setenv("CONFIG_FILE", configFile)
+
+	cfg, err := LoadForBootstrap()
+	require.NoError(t, err)
+	totpKey := cfg.Totp.EncryptionKey
+
+	require.NoError(t, os.WriteFile(configFile, []byte("gateway:\n  max_body_size: 134217728\n  response_header_timeout: 900\n  scheduling:\n    fallback_max_waiting: 7\n"), 0o600))
+	report, err := Reload(cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"gateway.max_body_size", "gateway.scheduling.fallback_max_waiting"}, report.Applied)
+	require.Equal(t, []string{"gateway.response_header_timeout"}, report.RestartRequired)
+
+	live := cfg.Live()
+	require.NotSame(t, cfg, live)
+	require.Equal(t, int64(134217728), live.Gateway.MaxBodySize)
+	require.Equal(t, 7, live.Gateway.Scheduling.FallbackMaxWaiting)
+	require.Equal(t, 600, live.Gateway.ResponseHeaderTimeout, "restart-only fields keep the running value")
+	require.Equal(t, totpKey, live.Totp.EncryptionKey, "auto-generated TOTP key must survive reloads")
+	require.Equal(t, int64(67108864), cfg.Gateway.MaxBodySize, "the bootstrap config is never written in place")
+
+	report, err = Reload(cfg)
+	require.NoError(t, err)
+	require.Empty(t, report.Applied)
+	require.Equal(t, []string{"gateway.response_header_timeout"}, report.RestartRequired)
+	require.Same(t, live, cfg.Live(), "a reload without applied changes keeps the published snapshot")
+}
+
+func TestReloadPublishesSnapshotWithoutRacingReaders(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("gateway:\n  max_line_size: 1048576\n"), 0o600))
+	t.Setenv("CONFIG_FILE", configFile)
+
+	cfg, err := LoadForBootstrap()
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = cfg.Live().Gateway.MaxLineSize
+			}
+		}
+	}()
+
+	for _, size := range []int{2097152, 4194304} {
+		require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf("gateway:\n  max_line_size: %d\n", size)), 0o600))
+		_, err := Reload(cfg)
+		require.NoError(t, err)
+		require.Equal(t, size, cfg.Live().Gateway.MaxLineSize)
+	}
+	close(stop)
+	<-done
+	require.Equal(t, 1048576, cfg.Gateway.MaxLineSize)
+}
+
+func TestReloadRejectsConfigNotLoadedFromFile(t *testing.T) {
+	_, err := Reload(&Config{})
+	require.Error(t, err)
+}
+
+func TestReloadKeepsCurrentConfigWhenInvalid(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("gateway:\n  max_body_size: 67108864\n"), 0o600))
+	t.Setenv("CONFIG_FILE", configFile)
+
+	cfg, err := LoadForBootstrap()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(configFile, []byte("gateway:\n  max_body_size: 134217728\n  scheduling:\n    sticky_session_max_waiting: -1\n"), 0o600))
+	_, err = Reload(cfg)
+	require.Error(t, err)
+	require.Same(t, cfg, cfg.Live())
+	require.Equal(t, int64(67108864), cfg.Gateway.MaxBodySize)
+}
+
+func TestApplyReloadDoesNotApplyNonScalarFields(t *testing.T) {
+	current := &Config{}
+	current.JWT.Secret = "runtime-secret"
+	next := &Config{}
+	next.Gateway.Scheduling.StickySessionWaitTimeout = time.Minute
+	next.CORS.AllowedOrigins = []string{"https://example.com"}
+	next.Gateway.StreamKeepaliveInterval = 15
+	carryOverRuntimeFields(current, next)
+
+	report := applyReload(current, next)
+	require.Equal(t, []string{"gateway.stream_keepalive_interval"}, report.Applied)
+	require.Equal(t, []string{"cors.allowed_origins", "gateway.scheduling.sticky_session_wait_timeout"}, report.RestartRequired)
+	require.Equal(t, 15, current.Gateway.StreamKeepaliveInterval)
+	require.Zero(t, current.Gateway.Scheduling.StickySessionWaitTimeout)
+	require.Nil(t, current.CORS.AllowedOrigins)
+	require.Equal(t, "runtime-secret", current.JWT.Secret)
+}
+
+func TestHotReloadablePathsResolveToScalarFields(t *testing.T) {
+	kinds := map[string]reflect.Kind{}
+	var walk func(t reflect.Type, prefix string)
+	walk = func(typ reflect.Type, prefix string) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, squash := configFieldName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			path := prefix
+			if !squash {
+				path = joinConfigPath(prefix, name)
+			}
+			if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+				walk(field.Type, path)
+				continue
+			}
+			kinds[path] = field.Type.Kind()
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+
+	for path := range hotReloadablePaths {
+		kind, ok := kinds[path]
+		require.True(t, ok, "unknown config path %s", path)
+		require.True(t, isHotReloadableKind(kind), "%s is not a scalar field", path)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/secrets"
+)
+
+// SecretsConfig 外部密钥后端配置。
+//
+// 下列敏感配置项（见 secretConfigFields）以及支付服务商配置中的值都可以写成密钥引用，
+// 如 "vault://secret/sub2api#totp_key"、"file:///run/secrets/jwt"、"kms://<密文>"，
+// 启动（及配置重载）时解析为真实值，config.yaml 与环境变量中不再出现明文密钥。
+type SecretsConfig struct {
+	// CacheTTLSeconds 运行期解析结果（支付服务商密钥）的缓存时间
+	CacheTTLSeconds int `mapstructure:"cache_ttl_seconds"`
+	// RefreshIntervalSeconds 后台刷新已解析引用的周期，0 表示不刷新
+	RefreshIntervalSeconds int `mapstructure:"refresh_interval_seconds"`
+	// TimeoutSeconds 单次请求 Vault / KMS 的超时
+	TimeoutSeconds int                `mapstructure:"timeout_seconds"`
+	Vault          SecretsVaultConfig `mapstructure:"vault"`
+	KMS            SecretsKMSConfig   `mapstructure:"kms"`
+}
+
+// SecretsVaultConfig HashiCorp Vault（KV v1/v2 与 transit）兼容 HTTP API
+type SecretsVaultConfig struct {
+	Address string `mapstructure:"address"`
+	// Token 与 TokenFile 二选一；TokenFile 每次请求重新读取，适配 Vault Agent 续期
+	Token        string `mapstructure:"token"`
+	TokenFile    string `mapstructure:"token_file"`
+	Namespace    string `mapstructure:"namespace"`
+	KVVersion    int    `mapstructure:"kv_version"`
+	TransitMount string `mapstructure:"transit_mount"`
+}
+
+// SecretsKMSConfig KMS 风格的解密 HTTP API（AWS KMS Decrypt 的 JSON 形状，Bearer 鉴权）
+type SecretsKMSConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	KeyID     string `mapstructure:"key_id"`
+	Token     string `mapstructure:"token"`
+	TokenFile string `mapstructure:"token_file"`
+}
+
+func (c *SecretsConfig) validate() error {
+	if c.CacheTTLSeconds < 0 {
+		return fmt.Errorf("cache_ttl_seconds must be non-negative")
+	}
+	if c.RefreshIntervalSeconds < 0 {
+		return fmt.Errorf("refresh_interval_seconds must be non-negative")
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must be non-negative")
+	}
+	if c.Vault.KVVersion != 0 && c.Vault.KVVersion != 1 && c.Vault.KVVersion != 2 {
+		return fmt.Errorf("vault.kv_version must be 1 or 2")
+	}
+	return nil
+}
+
+// ResolverOptions 转换为 secrets.Resolver 的参数
+func (c SecretsConfig) ResolverOptions() secrets.Options {
+	return secrets.Options{
+		Vault: secrets.VaultOptions{
+			Address:      c.Vault.Address,
+			Token:        c.Vault.Token,
+			TokenFile:    c.Vault.TokenFile,
+			Namespace:    c.Vault.Namespace,
+			KVVersion:    c.Vault.KVVersion,
+			TransitMount: c.Vault.TransitMount,
+		},
+		KMS: secrets.KMSOptions{
+			Endpoint:  c.KMS.Endpoint,
+			KeyID:     c.KMS.KeyID,
+			Token:     c.KMS.Token,
+			TokenFile: c.KMS.TokenFile,
+		},
+		CacheTTL:        time.Duration(c.CacheTTLSeconds) * time.Second,
+		RefreshInterval: time.Duration(c.RefreshIntervalSeconds) * time.Second,
+		Timeout:         time.Duration(c.TimeoutSeconds) * time.Second,
+	}
+}
+
+// secretConfigFields 允许写成密钥引用的标量配置项（mapstructure 路径 -> 字段）。
+// security.credential_encryption.master_keys 的每个值同样支持引用，在 resolveConfigSecrets 中逐项处理。
+// 密钥后端自身的凭证（secrets.vault.token 等）不在此列，避免循环依赖。
+func secretConfigFields(cfg *Config) map[string]*string {
+	return map[string]*string{
+		"totp.encryption_key":               &cfg.Totp.EncryptionKey,
+		"jwt.secret":                        &cfg.JWT.Secret,
+		"database.password":                 &cfg.Database.Password,
+		"redis.password":                    &cfg.Redis.Password,
+		"metrics.bearer_token":              &cfg.Metrics.BearerToken,
+		"usage_export.s3.secret_access_key": &cfg.UsageExport.S3.SecretAccessKey,
+		"gemini.oauth.client_secret":        &cfg.Gemini.OAuth.ClientSecret,
+		"linuxdo_connect.client_secret":     &cfg.LinuxDo.ClientSecret,
+		"oidc_connect.client_secret":        &cfg.OIDC.ClientSecret,
+		"dingtalk_connect.client_secret":    &cfg.DingTalk.ClientSecret,
+		"github_oauth.client_secret":        &cfg.GitHubOAuth.ClientSecret,
+		"google_oauth.client_secret":        &cfg.GoogleOAuth.ClientSecret,
+		"wechat_connect.app_secret":         &cfg.WeChat.AppSecret,
+		"wechat_connect.open_app_secret":    &cfg.WeChat.OpenAppSecret,
+		"wechat_connect.mp_app_secret":      &cfg.WeChat.MPAppSecret,
+		"wechat_connect.mobile_app_secret":  &cfg.WeChat.MobileAppSecret,
+	}
+}
+
+// resolveConfigSecrets 把敏感配置项中的密钥引用替换为真实值，并记录引用以便运行期监测变更。
+// 任一引用无法解析时返回错误：缺失主密钥时启动失败，好过以随机密钥运行。
+func resolveConfigSecrets(cfg *Config) error {
+	resolver := secrets.NewResolver(cfg.Secrets.ResolverOptions())
+	ctx := context.Background()
+	refs := map[string]string{}
+
+	resolve := func(path string, field *string) error {
+		if !secrets.IsReference(*field) {
+			return nil
+		}
+		ref := *field
+		value, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		*field = value
+		refs[path] = ref
+		return nil
+	}
+
+	for path, field := range secretConfigFields(cfg) {
+		if err := resolve(path, field); err != nil {
+			return err
+		}
+	}
+	for id, key := range cfg.Security.CredentialEncryption.MasterKeys {
+		if err := resolve("security.credential_encryption.master_keys."+id, &key); err != nil {
+			return err
+		}
+		cfg.Security.CredentialEncryption.MasterKeys[id] = key
+	}
+	cfg.secretRefs = refs
+	return nil
+}
+
+// SecretReferences 返回启动时由密钥引用解析得到的配置项（路径 -> 引用），不含解析结果。
+func (c *Config) SecretReferences() map[string]string {
+	out := make(map[string]string, len(c.secretRefs))
+	for path, ref := range c.secretRefs {
+		out[path] = ref
+	}
+	return out
+}
+
+// ProvideSecretResolver 创建运行期密钥解析器（支付服务商配置中的引用），并启动后台刷新。
+//
+// 启动时解析的配置项同样纳入刷新：它们已被拷贝进客户端与加密器，值变更后只记录告警，
+// 提示需要重启（或在配置重载报告的 restart_required 中看到）。
+func ProvideSecretResolver(cfg *Config) *secrets.Resolver {
+	resolver := secrets.NewResolver(cfg.Secrets.ResolverOptions())
+	paths := map[string][]string{}
+	for path, ref := range cfg.SecretReferences() {
+		paths[ref] = append(paths[ref], path)
+		if _, err := resolver.Resolve(context.Background(), ref); err != nil {
+			slog.Warn("secrets: track startup reference failed", "path", path, "error", err)
+		}
+	}
+	resolver.OnChange(func(ref string) {
+		if len(paths[ref]) > 0 {
+			slog.Warn("secrets: referenced secret changed; restart required to apply", "paths", paths[ref])
+		}
+	})
+	resolver.Start()
+	return resolver
+}
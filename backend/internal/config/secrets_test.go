@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadResolvesSecretReferences(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	totpKey := strings.Repeat("ab", 32)
+	keyFile := filepath.Join(t.TempDir(), "totp")
+	require.NoError(t, os.WriteFile(keyFile, []byte(totpKey+"\n"), 0o600))
+	t.Setenv("TOTP_ENCRYPTION_KEY", "file://"+keyFile)
+	t.Setenv("SUB2API_TEST_JWT", strings.Repeat("j", 32))
+	t.Setenv("JWT_SECRET", "env://SUB2API_TEST_JWT")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, totpKey, cfg.Totp.EncryptionKey)
+	require.True(t, cfg.Totp.EncryptionKeyConfigured)
+	require.Equal(t, strings.Repeat("j", 32), cfg.JWT.Secret)
+	require.Equal(t, map[string]string{
+		"totp.encryption_key": "file://" + keyFile,
+		"jwt.secret":          "env://SUB2API_TEST_JWT",
+	}, cfg.SecretReferences())
+}
+
+func TestLoadFailsOnUnresolvableSecretReference(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	t.Setenv("TOTP_ENCRYPTION_KEY", "vault://secret/sub2api#totp_key")
+
+	_, err := Load()
+	require.ErrorContains(t, err, "totp.encryption_key")
+}
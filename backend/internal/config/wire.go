@@ -5,6 +5,7 @@ import "github.com/google/wire"
 // ProviderSet 提供配置层的依赖
 var ProviderSet = wire.NewSet(
 	ProvideConfig,
+	ProvideSecretResolver,
 )
 
 // ProvideConfig 提供应用配置
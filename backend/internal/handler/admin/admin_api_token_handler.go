@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAPITokenHandler manages long-lived admin API tokens used by automation.
+type AdminAPITokenHandler struct {
+	tokenService *service.AdminAPITokenService
+}
+
+// NewAdminAPITokenHandler creates a new admin API token handler.
+func NewAdminAPITokenHandler(tokenService *service.AdminAPITokenService) *AdminAPITokenHandler {
+	return &AdminAPITokenHandler{tokenService: tokenService}
+}
+
+// CreateAdminAPITokenRequest represents the create token payload.
+// ExpiresAt takes precedence over ExpiresInDays; both empty = never expires.
+type CreateAdminAPITokenRequest struct {
+	Name          string     `json:"name" binding:"required"`
+	Scopes        []string   `json:"scopes" binding:"required"`
+	StepUpScopes  []string   `json:"step_up_scopes"`
+	AllowedIPs    []string   `json:"allowed_ips"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	ExpiresInDays int        `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+// List returns all admin API tokens (without secrets).
+// GET /api/v1/admin/api-tokens
+func (h *AdminAPITokenHandler) List(c *gin.Context) {
+	tokens, err := h.tokenService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// Create issues a new token. The plaintext token is only returned in this response.
+// POST /api/v1/admin/api-tokens
+func (h *AdminAPITokenHandler) Create(c *gin.Context) {
+	var req CreateAdminAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	expiresAt := req.ExpiresAt
+	if expiresAt == nil && req.ExpiresInDays > 0 {
+		at := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &at
+	}
+	token, plaintext, err := h.tokenService.Create(c.Request.Context(), service.CreateAdminAPITokenInput{
+		Name:         req.Name,
+		Scopes:       req.Scopes,
+		StepUpScopes: req.StepUpScopes,
+		AllowedIPs:   req.AllowedIPs,
+		ExpiresAt:    expiresAt,
+	}, getAdminIDFromContext(c))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"token": plaintext, "api_token": token})
+}
+
+// Revoke revokes a token immediately. Revoking an already revoked token is a no-op.
+// DELETE /api/v1/admin/api-tokens/:id
+func (h *AdminAPITokenHandler) Revoke(c *gin.Context) {
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.tokenService.Revoke(c.Request.Context(), id, getAdminIDFromContext(c)); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Token revoked successfully"})
+}
@@ -756,6 +756,25 @@ func (s *stubAdminService) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return nil, service.ErrAPIKeyNotFound
 }
 
+func (s *stubAdminService) AdminUpdateAPIKeyModelPolicy(ctx context.Context, keyID int64, update service.APIKeyModelPolicyUpdate) (*service.APIKey, error) {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == keyID {
+			if update.ModelAllowlist != nil {
+				s.apiKeys[i].ModelAllowlist = *update.ModelAllowlist
+			}
+			if update.ModelDenylist != nil {
+				s.apiKeys[i].ModelDenylist = *update.ModelDenylist
+			}
+			if update.ModelAliases != nil {
+				s.apiKeys[i].ModelAliases = *update.ModelAliases
+			}
+			k := s.apiKeys[i]
+			return &k, nil
+		}
+	}
+	return nil, service.ErrAPIKeyNotFound
+}
+
 func (s *stubAdminService) ResetAccountQuota(ctx context.Context, id int64) error {
 	return nil
 }
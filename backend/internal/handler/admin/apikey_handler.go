@@ -26,6 +26,10 @@ func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandle
 type AdminUpdateAPIKeyGroupRequest struct {
 	GroupID             *int64 `json:"group_id"`               // nil=不修改, 0=解绑, >0=绑定到目标分组
 	ResetRateLimitUsage *bool  `json:"reset_rate_limit_usage"` // true=重置 5h/1d/7d 限速用量
+
+	ModelAllowlist *[]string          `json:"model_allowlist"` // nil=不修改, 空数组=清空
+	ModelDenylist  *[]string          `json:"model_denylist"`  // nil=不修改, 空数组=清空
+	ModelAliases   *map[string]string `json:"model_aliases"`   // nil=不修改, 空对象=清空
 }
 
 // UpdateGroup handles updating an API key's admin-managed fields.
@@ -44,6 +48,18 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	}
 
 	var resetKey *service.APIKey
+	policyUpdate := service.APIKeyModelPolicyUpdate{
+		ModelAllowlist: req.ModelAllowlist,
+		ModelDenylist:  req.ModelDenylist,
+		ModelAliases:   req.ModelAliases,
+	}
+	if !policyUpdate.IsEmpty() {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyModelPolicy(c.Request.Context(), keyID, policyUpdate)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
 	if req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage {
 		resetKey, err = h.adminService.AdminResetAPIKeyRateLimitUsage(c.Request.Context(), keyID)
 		if err != nil {
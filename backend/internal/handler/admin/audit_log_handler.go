@@ -41,6 +41,7 @@ func (h *AuditLogHandler) List(c *gin.Context) {
 		PageSize:   pageSize,
 		ActorEmail: strings.TrimSpace(c.Query("actor_email")),
 		AuthMethod: strings.TrimSpace(c.Query("auth_method")),
+		AdminRole:  strings.TrimSpace(c.Query("admin_role")),
 		Action:     strings.TrimSpace(c.Query("action")),
 		Method:     strings.TrimSpace(c.Query("method")),
 		ClientIP:   strings.TrimSpace(c.Query("client_ip")),
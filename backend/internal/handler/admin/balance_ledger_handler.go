@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceLedgerHandler handles admin access to user balance ledgers and
+// ledger reconciliation runs.
+type BalanceLedgerHandler struct {
+	ledgerService *service.BalanceLedgerService
+}
+
+// NewBalanceLedgerHandler creates a new admin balance ledger handler.
+func NewBalanceLedgerHandler(ledgerService *service.BalanceLedgerService) *BalanceLedgerHandler {
+	return &BalanceLedgerHandler{ledgerService: ledgerService}
+}
+
+// ListUserLedger returns a user's balance ledger entries, newest first.
+// GET /api/v1/admin/users/:id/balance-ledger?reason=&start_date=&end_date=&timezone=
+func (h *BalanceLedgerHandler) ListUserLedger(c *gin.Context) {
+	userID, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	filter := service.BalanceLedgerFilter{Reason: c.Query("reason")}
+	userTZ := c.Query("timezone")
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		filter.StartTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		t = t.AddDate(0, 0, 1)
+		filter.EndTime = &t
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	entries, result, err := h.ledgerService.ListForUser(c.Request.Context(), userID, filter, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.AdminBalanceLedgerEntry, 0, len(entries))
+	for i := range entries {
+		out = append(out, *dto.AdminBalanceLedgerEntryFromService(&entries[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// ListReconciliations returns past reconciliation runs, newest first.
+// GET /api/v1/admin/balance-ledger/reconciliations
+func (h *BalanceLedgerHandler) ListReconciliations(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	runs, result, err := h.ledgerService.ListReconciliationRuns(c.Request.Context(), params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.BalanceReconciliationRun, 0, len(runs))
+	for i := range runs {
+		out = append(out, *dto.BalanceReconciliationRunFromService(&runs[i], false))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// GetReconciliation returns one reconciliation run including its findings.
+// GET /api/v1/admin/balance-ledger/reconciliations/:id
+func (h *BalanceLedgerHandler) GetReconciliation(c *gin.Context) {
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	run, err := h.ledgerService.GetReconciliationRun(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BalanceReconciliationRunFromService(run, true))
+}
+
+// RunReconciliation runs a reconciliation immediately and returns the result.
+// POST /api/v1/admin/balance-ledger/reconciliations/run
+func (h *BalanceLedgerHandler) RunReconciliation(c *gin.Context) {
+	run, err := h.ledgerService.Reconcile(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BalanceReconciliationRunFromService(run, true))
+}
@@ -0,0 +1,169 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles admin management of spending budgets on users,
+// API keys and groups.
+type BudgetHandler struct {
+	budgetService *service.BudgetService
+}
+
+// NewBudgetHandler creates a new admin budget handler.
+func NewBudgetHandler(budgetService *service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{budgetService: budgetService}
+}
+
+// CreateBudgetRequest represents the admin create budget payload.
+type CreateBudgetRequest struct {
+	ScopeType   string  `json:"scope_type" binding:"required,oneof=user api_key group"`
+	ScopeID     int64   `json:"scope_id" binding:"required"`
+	Model       string  `json:"model"`
+	Period      string  `json:"period" binding:"required"`
+	LimitUSD    float64 `json:"limit_usd" binding:"required"`
+	Enforcement string  `json:"enforcement"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// UpdateBudgetRequest represents the admin update budget payload (nil = no change).
+type UpdateBudgetRequest struct {
+	Model       *string  `json:"model"`
+	Period      *string  `json:"period"`
+	LimitUSD    *float64 `json:"limit_usd"`
+	Enforcement *string  `json:"enforcement"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+func parseBudgetID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid budget ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// List returns paginated budgets, optionally filtered by scope_type / scope_id.
+// GET /api/v1/admin/budgets
+func (h *BudgetHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := service.BudgetListFilter{ScopeType: c.Query("scope_type")}
+	if v := c.Query("scope_id"); v != "" {
+		scopeID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || scopeID <= 0 {
+			response.BadRequest(c, "Invalid scope_id")
+			return
+		}
+		filter.ScopeID = scopeID
+	}
+
+	budgets, result, err := h.budgetService.AdminList(c.Request.Context(), pagination.PaginationParams{Page: page, PageSize: pageSize}, filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.Budget, 0, len(budgets))
+	for i := range budgets {
+		out = append(out, *dto.BudgetFromService(&budgets[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Get returns a budget with its current period spend.
+// GET /api/v1/admin/budgets/:id
+func (h *BudgetHandler) Get(c *gin.Context) {
+	budgetID, ok := parseBudgetID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.budgetService.GetStatus(c.Request.Context(), budgetID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BudgetStatusFromService(status))
+}
+
+// Create creates a budget on a user, API key or group.
+// POST /api/v1/admin/budgets
+func (h *BudgetHandler) Create(c *gin.Context) {
+	var req CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	budget, err := h.budgetService.AdminCreate(c.Request.Context(), subject.UserID, service.CreateBudgetInput{
+		ScopeType:   req.ScopeType,
+		ScopeID:     req.ScopeID,
+		Model:       req.Model,
+		Period:      req.Period,
+		LimitUSD:    req.LimitUSD,
+		Enforcement: req.Enforcement,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, dto.BudgetFromService(budget))
+}
+
+// Update updates any budget, including budgets created by users.
+// PUT /api/v1/admin/budgets/:id
+func (h *BudgetHandler) Update(c *gin.Context) {
+	budgetID, ok := parseBudgetID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	budget, err := h.budgetService.AdminUpdate(c.Request.Context(), budgetID, service.UpdateBudgetInput{
+		Model:       req.Model,
+		Period:      req.Period,
+		LimitUSD:    req.LimitUSD,
+		Enforcement: req.Enforcement,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BudgetFromService(budget))
+}
+
+// Delete deletes a budget.
+// DELETE /api/v1/admin/budgets/:id
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	budgetID, ok := parseBudgetID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.budgetService.AdminDelete(c.Request.Context(), budgetID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Budget deleted successfully"})
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CredentialEncryptionHandler exposes at-rest encryption of account credentials:
+// data key status, data key rotation and a manual re-encryption pass.
+type CredentialEncryptionHandler struct {
+	credentialEncryptionService *service.CredentialEncryptionService
+}
+
+// NewCredentialEncryptionHandler creates a new admin credential encryption handler.
+func NewCredentialEncryptionHandler(credentialEncryptionService *service.CredentialEncryptionService) *CredentialEncryptionHandler {
+	return &CredentialEncryptionHandler{credentialEncryptionService: credentialEncryptionService}
+}
+
+// GetStatus returns the data keys, the number of accounts not yet on the active key and the last pass.
+// GET /api/v1/admin/system/credential-encryption
+func (h *CredentialEncryptionHandler) GetStatus(c *gin.Context) {
+	status, err := h.credentialEncryptionService.Status(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// RotateDataKey activates a new data key; existing credentials are re-encrypted in the background.
+// POST /api/v1/admin/system/credential-encryption/rotate
+func (h *CredentialEncryptionHandler) RotateDataKey(c *gin.Context) {
+	keyID, err := h.credentialEncryptionService.RotateDataKey(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"active_key_id": keyID})
+}
+
+// Reencrypt starts a re-encryption pass in the background.
+// POST /api/v1/admin/system/credential-encryption/reencrypt
+func (h *CredentialEncryptionHandler) Reencrypt(c *gin.Context) {
+	if err := h.credentialEncryptionService.TriggerRun(); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Accepted(c, gin.H{"started": true})
+}
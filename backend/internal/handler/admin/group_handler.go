@@ -114,6 +114,13 @@ type CreateGroupRequest struct {
 	ImageRateMultiplier             *float64                      `json:"image_rate_multiplier"`
 	BatchImageDiscountMultiplier    *float64                      `json:"batch_image_discount_multiplier"`
 	BatchImageHoldMultiplier        *float64                      `json:"batch_image_hold_multiplier"`
+	AllowBatchAPI                   bool                          `json:"allow_batch_api"`
+	BatchAPIDiscountMultiplier      *float64                      `json:"batch_api_discount_multiplier"`
+	BatchAPIHoldMultiplier          *float64                      `json:"batch_api_hold_multiplier"`
+	ResponseCacheEnabled            bool                          `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds         *int                          `json:"response_cache_ttl_seconds"`
+	ResponseCachePriceMultiplier    *float64                      `json:"response_cache_price_multiplier"`
+	SchedulingStrategy              string                        `json:"scheduling_strategy"`
 	VideoRateIndependent            bool                          `json:"video_rate_independent"`
 	VideoRateMultiplier             *float64                      `json:"video_rate_multiplier"`
 	PeakRateEnabled                 bool                          `json:"peak_rate_enabled"`
@@ -131,6 +138,7 @@ type CreateGroupRequest struct {
 	VideoPrice1080P                 *float64                      `json:"video_price_1080p"`
 	VideoModelPrices                map[string]map[string]float64 `json:"video_model_prices,omitempty"`
 	WebSearchPricePerCall           *float64                      `json:"web_search_price_per_call"`
+	WebFetchPricePerCall            *float64                      `json:"web_fetch_price_per_call"`
 	SearchPricePer1k                *float64                      `json:"search_price_per_1k"`
 	AudioRealtimePricePerMin        *float64                      `json:"audio_realtime_price_per_min"`
 	AudioTtsPricePerMillionChars    *float64                      `json:"audio_tts_price_per_million_chars"`
@@ -183,6 +191,13 @@ type UpdateGroupRequest struct {
 	ImageRateMultiplier             *float64                      `json:"image_rate_multiplier"`
 	BatchImageDiscountMultiplier    *float64                      `json:"batch_image_discount_multiplier"`
 	BatchImageHoldMultiplier        *float64                      `json:"batch_image_hold_multiplier"`
+	AllowBatchAPI                   *bool                         `json:"allow_batch_api"`
+	BatchAPIDiscountMultiplier      *float64                      `json:"batch_api_discount_multiplier"`
+	BatchAPIHoldMultiplier          *float64                      `json:"batch_api_hold_multiplier"`
+	ResponseCacheEnabled            *bool                         `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds         *int                          `json:"response_cache_ttl_seconds"`
+	ResponseCachePriceMultiplier    *float64                      `json:"response_cache_price_multiplier"`
+	SchedulingStrategy              *string                       `json:"scheduling_strategy"`
 	VideoRateIndependent            *bool                         `json:"video_rate_independent"`
 	VideoRateMultiplier             *float64                      `json:"video_rate_multiplier"`
 	PeakRateEnabled                 *bool                         `json:"peak_rate_enabled"`
@@ -200,6 +215,7 @@ type UpdateGroupRequest struct {
 	VideoPrice1080P                 *float64                      `json:"video_price_1080p"`
 	VideoModelPrices                map[string]map[string]float64 `json:"video_model_prices,omitempty"`
 	WebSearchPricePerCall           *float64                      `json:"web_search_price_per_call"`
+	WebFetchPricePerCall            *float64                      `json:"web_fetch_price_per_call"`
 	SearchPricePer1k                *float64                      `json:"search_price_per_1k"`
 	AudioRealtimePricePerMin        *float64                      `json:"audio_realtime_price_per_min"`
 	AudioTtsPricePerMillionChars    *float64                      `json:"audio_tts_price_per_million_chars"`
@@ -481,6 +497,15 @@ func (h *GroupHandler) GetModelsListCandidates(c *gin.Context) {
 	response.Success(c, gin.H{"models": models})
 }
 
+// ListSchedulingStrategies handles listing the account scheduling strategies a group can use.
+// GET /api/v1/admin/groups/scheduling-strategies
+func (h *GroupHandler) ListSchedulingStrategies(c *gin.Context) {
+	response.Success(c, gin.H{
+		"strategies": service.AccountSchedulingStrategies(),
+		"default":    service.AccountSchedulingStrategyDefault,
+	})
+}
+
 // Create handles creating a new group
 // POST /api/v1/admin/groups
 func (h *GroupHandler) Create(c *gin.Context) {
@@ -520,6 +545,13 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		ImageRateMultiplier:             req.ImageRateMultiplier,
 		BatchImageDiscountMultiplier:    req.BatchImageDiscountMultiplier,
 		BatchImageHoldMultiplier:        req.BatchImageHoldMultiplier,
+		AllowBatchAPI:                   req.AllowBatchAPI,
+		BatchAPIDiscountMultiplier:      req.BatchAPIDiscountMultiplier,
+		BatchAPIHoldMultiplier:          req.BatchAPIHoldMultiplier,
+		ResponseCacheEnabled:            req.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         req.ResponseCacheTTLSeconds,
+		ResponseCachePriceMultiplier:    req.ResponseCachePriceMultiplier,
+		SchedulingStrategy:              req.SchedulingStrategy,
 		VideoRateIndependent:            req.VideoRateIndependent,
 		VideoRateMultiplier:             req.VideoRateMultiplier,
 		PeakRateEnabled:                 req.PeakRateEnabled,
@@ -537,6 +569,7 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		VideoPrice1080P:                 req.VideoPrice1080P,
 		VideoModelPrices:                req.VideoModelPrices,
 		WebSearchPricePerCall:           req.WebSearchPricePerCall,
+		WebFetchPricePerCall:            req.WebFetchPricePerCall,
 		SearchPricePer1k:                req.SearchPricePer1k,
 		AudioRealtimePricePerMin:        req.AudioRealtimePricePerMin,
 		AudioTTSPricePerMillionChars:    req.AudioTtsPricePerMillionChars,
@@ -649,6 +682,13 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		ImageRateMultiplier:             req.ImageRateMultiplier,
 		BatchImageDiscountMultiplier:    req.BatchImageDiscountMultiplier,
 		BatchImageHoldMultiplier:        req.BatchImageHoldMultiplier,
+		AllowBatchAPI:                   req.AllowBatchAPI,
+		BatchAPIDiscountMultiplier:      req.BatchAPIDiscountMultiplier,
+		BatchAPIHoldMultiplier:          req.BatchAPIHoldMultiplier,
+		ResponseCacheEnabled:            req.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         req.ResponseCacheTTLSeconds,
+		ResponseCachePriceMultiplier:    req.ResponseCachePriceMultiplier,
+		SchedulingStrategy:              req.SchedulingStrategy,
 		VideoRateIndependent:            req.VideoRateIndependent,
 		VideoRateMultiplier:             req.VideoRateMultiplier,
 		PeakRateEnabled:                 req.PeakRateEnabled,
@@ -666,6 +706,7 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		VideoPrice1080P:                 req.VideoPrice1080P,
 		VideoModelPrices:                req.VideoModelPrices,
 		WebSearchPricePerCall:           req.WebSearchPricePerCall,
+		WebFetchPricePerCall:            req.WebFetchPricePerCall,
 		SearchPricePer1k:                req.SearchPricePer1k,
 		AudioRealtimePricePerMin:        req.AudioRealtimePricePerMin,
 		AudioTTSPricePerMillionChars:    req.AudioTtsPricePerMillionChars,
@@ -0,0 +1,271 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvoiceHandler handles admin access to receipts and usage statements:
+// listing, downloading, issuing on demand, voiding and reissuing, plus
+// editing any user's or organization's billing profile.
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+// NewInvoiceHandler creates a new admin invoice handler.
+func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService}
+}
+
+// IssueInvoiceReceiptRequest represents the admin issue receipt payload.
+type IssueInvoiceReceiptRequest struct {
+	OrderID int64 `json:"order_id" binding:"required"`
+}
+
+// IssueInvoiceStatementRequest represents the admin issue usage statement payload (month = YYYY-MM).
+type IssueInvoiceStatementRequest struct {
+	OwnerType string `json:"owner_type" binding:"required,oneof=user organization"`
+	OwnerID   int64  `json:"owner_id" binding:"required"`
+	Month     string `json:"month" binding:"required"`
+}
+
+// VoidInvoiceRequest represents the void / reissue payload.
+type VoidInvoiceRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// UpdateBillingProfileRequest represents the billing profile payload (replaces all fields).
+type UpdateBillingProfileRequest struct {
+	CompanyName  string `json:"company_name"`
+	TaxID        string `json:"tax_id"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2"`
+	City         string `json:"city"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+	Email        string `json:"email"`
+	Notes        string `json:"notes"`
+}
+
+// List returns invoices, newest first.
+// GET /api/v1/admin/invoices?owner_type=&owner_id=&kind=&status=&number=
+func (h *InvoiceHandler) List(c *gin.Context) {
+	filter := service.InvoiceFilter{
+		OwnerType: c.Query("owner_type"),
+		Kind:      c.Query("kind"),
+		Status:    c.Query("status"),
+		Number:    c.Query("number"),
+	}
+	if raw := c.Query("owner_id"); raw != "" {
+		ownerID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || ownerID <= 0 {
+			response.BadRequest(c, "Invalid owner_id")
+			return
+		}
+		filter.OwnerID = ownerID
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	invoices, result, err := h.invoiceService.AdminList(c.Request.Context(), filter, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.AdminInvoice, 0, len(invoices))
+	for i := range invoices {
+		out = append(out, *dto.AdminInvoiceFromService(&invoices[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Get returns a single invoice.
+// GET /api/v1/admin/invoices/:id
+func (h *InvoiceHandler) Get(c *gin.Context) {
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	inv, err := h.invoiceService.AdminGet(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminInvoiceFromService(inv))
+}
+
+// Download returns the rendered invoice document.
+// GET /api/v1/admin/invoices/:id/download?format=html|pdf
+func (h *InvoiceHandler) Download(c *gin.Context) {
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	inv, err := h.invoiceService.AdminGet(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	doc, err := h.invoiceService.Render(inv, c.DefaultQuery("format", service.InvoiceFormatHTML))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+doc.Filename)
+	c.Data(200, doc.ContentType, doc.Data)
+}
+
+// IssueReceipt issues (or returns the existing) receipt for a paid order.
+// POST /api/v1/admin/invoices/receipts
+func (h *InvoiceHandler) IssueReceipt(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	var req IssueInvoiceReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	inv, err := h.invoiceService.AdminIssueReceipt(c.Request.Context(), subject.UserID, req.OrderID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminInvoiceFromService(inv))
+}
+
+// IssueStatement issues (or returns the existing) usage statement for a user or organization.
+// POST /api/v1/admin/invoices/statements
+func (h *InvoiceHandler) IssueStatement(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	var req IssueInvoiceStatementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	owner := service.InvoiceOwnerRef{OwnerType: req.OwnerType, OwnerID: req.OwnerID}
+	inv, err := h.invoiceService.AdminIssueStatement(c.Request.Context(), subject.UserID, owner, req.Month)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminInvoiceFromService(inv))
+}
+
+// Void voids an issued invoice; the number is kept.
+// POST /api/v1/admin/invoices/:id/void
+func (h *InvoiceHandler) Void(c *gin.Context) {
+	h.voidOrReissue(c, false)
+}
+
+// Reissue voids the invoice (if still issued) and issues a replacement with a new number,
+// rebuilt from current order / usage data and the current billing profile.
+// POST /api/v1/admin/invoices/:id/reissue
+func (h *InvoiceHandler) Reissue(c *gin.Context) {
+	h.voidOrReissue(c, true)
+}
+
+func (h *InvoiceHandler) voidOrReissue(c *gin.Context, reissue bool) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req VoidInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	var (
+		inv *service.Invoice
+		err error
+	)
+	if reissue {
+		inv, err = h.invoiceService.Reissue(c.Request.Context(), subject.UserID, id, req.Reason)
+	} else {
+		inv, err = h.invoiceService.Void(c.Request.Context(), subject.UserID, id, req.Reason)
+	}
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.AdminInvoiceFromService(inv))
+}
+
+// GetBillingProfile returns a user's or organization's billing profile.
+// GET /api/v1/admin/billing-profiles/:owner_type/:owner_id
+func (h *InvoiceHandler) GetBillingProfile(c *gin.Context) {
+	owner, ok := parseInvoiceOwnerParams(c)
+	if !ok {
+		return
+	}
+	profile, err := h.invoiceService.GetBillingProfile(c.Request.Context(), owner)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BillingProfileFromService(profile))
+}
+
+// UpdateBillingProfile replaces a user's or organization's billing profile.
+// PUT /api/v1/admin/billing-profiles/:owner_type/:owner_id
+func (h *InvoiceHandler) UpdateBillingProfile(c *gin.Context) {
+	owner, ok := parseInvoiceOwnerParams(c)
+	if !ok {
+		return
+	}
+	var req UpdateBillingProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	profile, err := h.invoiceService.UpdateBillingProfile(c.Request.Context(), &service.BillingProfile{
+		OwnerType:    owner.OwnerType,
+		OwnerID:      owner.OwnerID,
+		CompanyName:  req.CompanyName,
+		TaxID:        req.TaxID,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+		Email:        req.Email,
+		Notes:        req.Notes,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BillingProfileFromService(profile))
+}
+
+func parseInvoiceOwnerParams(c *gin.Context) (service.InvoiceOwnerRef, bool) {
+	ownerType := c.Param("owner_type")
+	if ownerType != service.InvoiceOwnerUser && ownerType != service.InvoiceOwnerOrganization {
+		response.ErrorFrom(c, service.ErrInvoiceInvalidOwner)
+		return service.InvoiceOwnerRef{}, false
+	}
+	ownerID, ok := parsePositiveIDParam(c, "owner_id")
+	if !ok {
+		return service.InvoiceOwnerRef{}, false
+	}
+	return service.InvoiceOwnerRef{OwnerType: ownerType, OwnerID: ownerID}, true
+}
@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles admin organization management:
+// listing, inspecting members, wallet adjustments and enabling/disabling.
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new admin organization handler.
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+// AdjustOrganizationBalanceRequest represents a wallet adjustment (positive credits, negative debits).
+type AdjustOrganizationBalanceRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+	Note   string  `json:"note"`
+}
+
+// UpdateOrganizationStatusRequest represents the status update payload.
+type UpdateOrganizationStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active disabled"`
+}
+
+// List returns paginated organizations.
+// GET /api/v1/admin/organizations
+func (h *OrganizationHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	search := strings.TrimSpace(c.Query("search"))
+	if len(search) > 100 {
+		search = search[:100]
+	}
+
+	orgs, result, err := h.organizationService.AdminList(c.Request.Context(), pagination.PaginationParams{Page: page, PageSize: pageSize}, search)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.Organization, 0, len(orgs))
+	for i := range orgs {
+		out = append(out, *dto.OrganizationFromService(&orgs[i], ""))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Get returns an organization with its members.
+// GET /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		response.BadRequest(c, "Invalid organization ID")
+		return
+	}
+
+	org, members, err := h.organizationService.AdminGet(c.Request.Context(), orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"organization": dto.OrganizationFromService(org, ""),
+		"members":      dto.OrganizationMembersFromService(members),
+	})
+}
+
+// AdjustBalance credits or debits the organization wallet.
+// POST /api/v1/admin/organizations/:id/balance
+func (h *OrganizationHandler) AdjustBalance(c *gin.Context) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		response.BadRequest(c, "Invalid organization ID")
+		return
+	}
+
+	var req AdjustOrganizationBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not found in context")
+		return
+	}
+
+	balance, err := h.organizationService.AdminAdjustBalance(c.Request.Context(), subject.UserID, orgID, req.Amount, req.Note)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"balance": balance})
+}
+
+// UpdateStatus enables or disables an organization.
+// PUT /api/v1/admin/organizations/:id/status
+func (h *OrganizationHandler) UpdateStatus(c *gin.Context) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		response.BadRequest(c, "Invalid organization ID")
+		return
+	}
+
+	var req UpdateOrganizationStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.AdminSetStatus(c.Request.Context(), orgID, req.Status)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationFromService(org, ""))
+}
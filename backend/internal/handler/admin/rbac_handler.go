@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RBACHandler manages staff roles (permission scopes over the admin route groups)
+// and which users hold them.
+type RBACHandler struct {
+	rbacService *service.AdminRBACService
+}
+
+// NewRBACHandler creates a new admin RBAC handler.
+func NewRBACHandler(rbacService *service.AdminRBACService) *RBACHandler {
+	return &RBACHandler{rbacService: rbacService}
+}
+
+// AdminRoleRequest represents the create/update staff role payload (nil = no change on update).
+type AdminRoleRequest struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// AssignAdminRoleRequest binds a user to a staff role.
+type AssignAdminRoleRequest struct {
+	RoleID int64 `json:"role_id" binding:"required"`
+}
+
+func (r *AdminRoleRequest) toInput() service.AdminRoleInput {
+	return service.AdminRoleInput{Name: r.Name, Description: r.Description, Permissions: r.Permissions}
+}
+
+// Me returns the effective admin permissions of the current request.
+// GET /api/v1/admin/rbac/me
+func (h *RBACHandler) Me(c *gin.Context) {
+	perms, _ := middleware2.GetAdminPermissionsFromContext(c)
+	response.Success(c, gin.H{
+		"super_admin": perms.IsSuper(),
+		"role":        c.GetString(middleware2.ContextKeyAdminRole),
+		"permissions": perms.Scopes(),
+	})
+}
+
+// ListPermissions returns the catalogue of grantable resources.
+// GET /api/v1/admin/rbac/permissions
+func (h *RBACHandler) ListPermissions(c *gin.Context) {
+	response.Success(c, gin.H{
+		"resources": service.AdminResources,
+		"actions":   []string{service.AdminActionRead, service.AdminActionWrite},
+	})
+}
+
+// ListRoles returns all staff roles.
+// GET /api/v1/admin/rbac/roles
+func (h *RBACHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, roles)
+}
+
+// CreateRole creates a staff role.
+// POST /api/v1/admin/rbac/roles
+func (h *RBACHandler) CreateRole(c *gin.Context) {
+	var req AdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	role, err := h.rbacService.CreateRole(c.Request.Context(), req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, role)
+}
+
+// UpdateRole updates a staff role. Built-in roles keep their name.
+// PUT /api/v1/admin/rbac/roles/:id
+func (h *RBACHandler) UpdateRole(c *gin.Context) {
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	role, err := h.rbacService.UpdateRole(c.Request.Context(), id, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, role)
+}
+
+// DeleteRole deletes an unassigned custom staff role.
+// DELETE /api/v1/admin/rbac/roles/:id
+func (h *RBACHandler) DeleteRole(c *gin.Context) {
+	id, ok := parsePositiveIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.rbacService.DeleteRole(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Role deleted successfully"})
+}
+
+// ListAssignments returns all users holding a staff role.
+// GET /api/v1/admin/rbac/assignments
+func (h *RBACHandler) ListAssignments(c *gin.Context) {
+	assignments, err := h.rbacService.ListAssignments(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, assignments)
+}
+
+// AssignRole binds (or rebinds) a user to a staff role.
+// PUT /api/v1/admin/rbac/assignments/:user_id
+func (h *RBACHandler) AssignRole(c *gin.Context) {
+	userID, ok := parsePositiveIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+	var req AssignAdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if err := h.rbacService.AssignRole(c.Request.Context(), userID, req.RoleID, getAdminIDFromContext(c)); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"user_id": userID, "role_id": req.RoleID})
+}
+
+// RemoveAssignment revokes a user's staff role.
+// DELETE /api/v1/admin/rbac/assignments/:user_id
+func (h *RBACHandler) RemoveAssignment(c *gin.Context) {
+	userID, ok := parsePositiveIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+	if err := h.rbacService.RemoveAssignment(c.Request.Context(), userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Role assignment removed"})
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SAMLHandler handles admin inspection of the SAML SP configuration and
+// import of IdP metadata.
+type SAMLHandler struct {
+	samlService *service.SAMLService
+}
+
+// NewSAMLHandler creates a new admin SAML handler.
+func NewSAMLHandler(samlService *service.SAMLService) *SAMLHandler {
+	return &SAMLHandler{samlService: samlService}
+}
+
+// ImportSAMLMetadataRequest carries IdP metadata either inline or as a URL to fetch once.
+type ImportSAMLMetadataRequest struct {
+	MetadataXML string `json:"metadata_xml"`
+	MetadataURL string `json:"metadata_url"`
+}
+
+// GetStatus returns the SP configuration and the active IdP metadata summary.
+// GET /api/v1/admin/saml
+func (h *SAMLHandler) GetStatus(c *gin.Context) {
+	status, err := h.samlService.Status(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// ImportMetadata validates and stores IdP metadata, overriding the config file.
+// POST /api/v1/admin/saml/metadata
+func (h *SAMLHandler) ImportMetadata(c *gin.Context) {
+	var req ImportSAMLMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	summary, err := h.samlService.ImportIdPMetadata(c.Request.Context(), req.MetadataXML, req.MetadataURL)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, summary)
+}
+
+// DeleteMetadata removes imported IdP metadata and falls back to the config file.
+// DELETE /api/v1/admin/saml/metadata
+func (h *SAMLHandler) DeleteMetadata(c *gin.Context) {
+	if err := h.samlService.DeleteImportedIdPMetadata(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "SAML metadata deleted"})
+}
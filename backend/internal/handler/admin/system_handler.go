@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/sysutil"
 	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
@@ -18,8 +19,9 @@ import (
 
 // SystemHandler handles system-related operations
 type SystemHandler struct {
-	updateSvc systemUpdateService
-	lockSvc   *service.SystemOperationLockService
+	updateSvc    systemUpdateService
+	lockSvc      *service.SystemOperationLockService
+	configReload systemConfigReloader
 }
 
 // systemUpdateTimeout bounds a full in-place update or rollback: the release
@@ -51,6 +53,11 @@ type systemUpdateService interface {
 	RollbackToVersion(ctx context.Context, version string) error
 }
 
+type systemConfigReloader interface {
+	Reload(ctx context.Context, source string) (*config.ReloadReport, error)
+	LastStatus() *service.ConfigReloadStatus
+}
+
 // NewSystemHandler creates a new SystemHandler
 func NewSystemHandler(updateSvc systemUpdateService, lockSvc *service.SystemOperationLockService) *SystemHandler {
 	return &SystemHandler{
@@ -59,6 +66,12 @@ func NewSystemHandler(updateSvc systemUpdateService, lockSvc *service.SystemOper
 	}
 }
 
+// WithConfigReload enables the config hot-reload endpoints.
+func (h *SystemHandler) WithConfigReload(reloader systemConfigReloader) *SystemHandler {
+	h.configReload = reloader
+	return h
+}
+
 // GetVersion returns the current version
 // GET /api/v1/admin/system/version
 func (h *SystemHandler) GetVersion(c *gin.Context) {
@@ -228,6 +241,32 @@ func (h *SystemHandler) RestartService(c *gin.Context) {
 	})
 }
 
+// ReloadConfig re-reads config.yaml on this instance, applies the settings that
+// can change at runtime and asks the other instances to do the same.
+// POST /api/v1/admin/system/config/reload
+func (h *SystemHandler) ReloadConfig(c *gin.Context) {
+	if h.configReload == nil {
+		response.Error(c, http.StatusServiceUnavailable, "config reload is not available")
+		return
+	}
+	report, err := h.configReload.Reload(c.Request.Context(), service.ConfigReloadSourceAdmin)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Success(c, report)
+}
+
+// GetConfigReloadStatus returns the result of the last config reload on this instance
+// GET /api/v1/admin/system/config/reload
+func (h *SystemHandler) GetConfigReloadStatus(c *gin.Context) {
+	if h.configReload == nil {
+		response.Error(c, http.StatusServiceUnavailable, "config reload is not available")
+		return
+	}
+	response.Success(c, h.configReload.LastStatus())
+}
+
 func (h *SystemHandler) acquireSystemLock(
 	ctx context.Context,
 	operationID string,
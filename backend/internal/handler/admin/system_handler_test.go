@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -322,3 +323,54 @@ func TestSystemHandlerGetRollbackVersionsError(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, rec.Code)
 }
+
+type systemConfigReloaderStub struct {
+	report  *config.ReloadReport
+	err     error
+	sources []string
+}
+
+func (s *systemConfigReloaderStub) Reload(_ context.Context, source string) (*config.ReloadReport, error) {
+	s.sources = append(s.sources, source)
+	return s.report, s.err
+}
+
+func (s *systemConfigReloaderStub) LastStatus() *service.ConfigReloadStatus {
+	return nil
+}
+
+func TestSystemHandlerReloadConfigReturnsReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reloader := &systemConfigReloaderStub{report: &config.ReloadReport{
+		Applied:         []string{"gateway.max_body_size"},
+		RestartRequired: []string{"server.port"},
+	}}
+	handler := NewSystemHandler(&systemHandlerUpdateServiceStub{}, nil).WithConfigReload(reloader)
+	router := gin.New()
+	router.POST("/api/v1/admin/system/config/reload", handler.ReloadConfig)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/system/config/reload", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{service.ConfigReloadSourceAdmin}, reloader.sources)
+	var body struct {
+		Data config.ReloadReport `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, []string{"gateway.max_body_size"}, body.Data.Applied)
+	require.Equal(t, []string{"server.port"}, body.Data.RestartRequired)
+}
+
+func TestSystemHandlerReloadConfigRejectsInvalidConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reloader := &systemConfigReloaderStub{err: errors.New("validate config error: bad value")}
+	handler := NewSystemHandler(&systemHandlerUpdateServiceStub{}, nil).WithConfigReload(reloader)
+	router := gin.New()
+	router.POST("/api/v1/admin/system/config/reload", handler.ReloadConfig)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/system/config/reload", nil))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageExportHandler exposes the scheduled usage_logs export status and a manual trigger.
+type UsageExportHandler struct {
+	usageExportService *service.UsageExportService
+}
+
+// NewUsageExportHandler creates a new admin usage export handler.
+func NewUsageExportHandler(usageExportService *service.UsageExportService) *UsageExportHandler {
+	return &UsageExportHandler{usageExportService: usageExportService}
+}
+
+// GetStatus returns the export configuration, watermark and recently uploaded files.
+// GET /api/v1/admin/usage-export
+func (h *UsageExportHandler) GetStatus(c *gin.Context) {
+	status, err := h.usageExportService.Status(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// Run starts an export in the background; it resumes from the watermark or the pending range.
+// POST /api/v1/admin/usage-export/run
+func (h *UsageExportHandler) Run(c *gin.Context) {
+	if err := h.usageExportService.TriggerRun(); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Accepted(c, gin.H{"started": true})
+}
@@ -242,6 +242,9 @@ func (h *UserHandler) BindAuthIdentity(c *gin.Context) {
 		response.BadRequest(c, "Invalid request: "+err.Error())
 		return
 	}
+	if h.rejectStaffOnAdminTarget(c, userID, false) {
+		return
+	}
 
 	input := service.AdminBindAuthIdentityInput{
 		ProviderType:    req.ProviderType,
@@ -276,6 +279,10 @@ func (h *UserHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if h.rejectStaffOnAdminTarget(c, 0, req.Role == service.RoleAdmin) {
+		return
+	}
+
 	// 创建管理员账号属权限敏感操作：需最近完成 step-up 2FA 验证。
 	if req.Role == service.RoleAdmin {
 		if !middleware.EnforceStepUp(c, h.totpService, h.userService, h.settingService) {
@@ -318,6 +325,10 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if h.rejectStaffOnAdminTarget(c, userID, req.Role == service.RoleAdmin) {
+		return
+	}
+
 	// 防锁死保护：管理员不能把自己降级为普通用户(单管理员场景下会失去后台访问权)。
 	// 与既有"不能禁用/删除 admin"保护一致。降级其他管理员仍然允许。
 	if req.Role == service.RoleUser && userID == getAdminIDFromContext(c) {
@@ -363,6 +374,29 @@ func (h *UserHandler) Update(c *gin.Context) {
 	response.Success(c, dto.UserFromServiceAdmin(user))
 }
 
+// rejectStaffOnAdminTarget 员工角色不能授予管理员角色，也不能修改完整管理员账号
+// （改密、改邮箱、绑定登录身份都可能接管管理员）。已写入错误响应时返回 true。
+func (h *UserHandler) rejectStaffOnAdminTarget(c *gin.Context, userID int64, grantsAdmin bool) bool {
+	if !middleware.IsStaffAdminRequest(c) {
+		return false
+	}
+	if !grantsAdmin {
+		if userID <= 0 {
+			return false
+		}
+		target, err := h.adminService.GetUser(c.Request.Context(), userID)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return true
+		}
+		if target.Role != service.RoleAdmin {
+			return false
+		}
+	}
+	response.ErrorFrom(c, service.ErrSuperAdminRequired)
+	return true
+}
+
 // Delete handles deleting a user
 // DELETE /api/v1/admin/users/:id
 func (h *UserHandler) Delete(c *gin.Context) {
@@ -403,7 +437,15 @@ func (h *UserHandler) UpdateBalance(c *gin.Context) {
 		UserID: userID,
 		Body:   req,
 	}
+	adminID := getAdminIDFromContext(c)
 	executeAdminIdempotentJSON(c, "admin.users.balance.update", idempotencyPayload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		ctx = service.WithBalanceLedgerSource(ctx, service.BalanceLedgerSource{
+			Reason:     service.BalanceLedgerReasonAdminAdjust,
+			SourceType: service.BalanceLedgerSourceAdmin,
+			SourceID:   strconv.FormatInt(adminID, 10),
+			ActorID:    &adminID,
+			Note:       req.Notes,
+		})
 		user, execErr := h.adminService.UpdateUserBalance(ctx, userID, req.Balance, req.Operation, req.Notes)
 		if execErr != nil {
 			return nil, execErr
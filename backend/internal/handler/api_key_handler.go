@@ -44,6 +44,14 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h *float64 `json:"rate_limit_5h"`
 	RateLimit1d *float64 `json:"rate_limit_1d"`
 	RateLimit7d *float64 `json:"rate_limit_7d"`
+
+	ResponseCacheEnabled *bool `json:"response_cache_enabled"` // 启用精确匹配响应缓存
+
+	OrganizationID *int64 `json:"organization_id"` // 组织共享 Key，创建后不可变更
+
+	ModelAllowlist []string          `json:"model_allowlist"` // 允许的模型模式（支持末尾 *）
+	ModelDenylist  []string          `json:"model_denylist"`  // 禁止的模型模式（优先于允许列表）
+	ModelAliases   map[string]string `json:"model_aliases"`   // 模型别名 -> 目标模型
 }
 
 // UpdateAPIKeyRequest represents the update API key request payload
@@ -62,6 +70,12 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // 重置限速用量
+
+	ResponseCacheEnabled *bool `json:"response_cache_enabled"` // 启用精确匹配响应缓存（nil 不修改）
+
+	ModelAllowlist *[]string          `json:"model_allowlist"` // 允许的模型模式（nil 不修改，空数组清空）
+	ModelDenylist  *[]string          `json:"model_denylist"`  // 禁止的模型模式（nil 不修改，空数组清空）
+	ModelAliases   *map[string]string `json:"model_aliases"`   // 模型别名（nil 不修改，空对象清空）
 }
 
 func validAPIKeyLimit(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) && v >= 0 }
@@ -197,12 +211,16 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	}
 
 	svcReq := service.CreateAPIKeyRequest{
-		Name:          req.Name,
-		GroupID:       req.GroupID,
-		CustomKey:     req.CustomKey,
-		IPWhitelist:   req.IPWhitelist,
-		IPBlacklist:   req.IPBlacklist,
-		ExpiresInDays: req.ExpiresInDays,
+		Name:           req.Name,
+		GroupID:        req.GroupID,
+		CustomKey:      req.CustomKey,
+		IPWhitelist:    req.IPWhitelist,
+		IPBlacklist:    req.IPBlacklist,
+		ExpiresInDays:  req.ExpiresInDays,
+		OrganizationID: req.OrganizationID,
+		ModelAllowlist: req.ModelAllowlist,
+		ModelDenylist:  req.ModelDenylist,
+		ModelAliases:   req.ModelAliases,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
@@ -216,6 +234,9 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	if req.RateLimit7d != nil {
 		svcReq.RateLimit7d = *req.RateLimit7d
 	}
+	if req.ResponseCacheEnabled != nil {
+		svcReq.ResponseCacheEnabled = *req.ResponseCacheEnabled
+	}
 
 	executeUserIdempotentJSON(c, "user.api_keys.create", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
 		key, err := h.apiKeyService.Create(ctx, subject.UserID, svcReq)
@@ -260,6 +281,13 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 		RateLimit1d:         req.RateLimit1d,
 		RateLimit7d:         req.RateLimit7d,
 		ResetRateLimitUsage: req.ResetRateLimitUsage,
+
+		ResponseCacheEnabled: req.ResponseCacheEnabled,
+		APIKeyModelPolicyUpdate: service.APIKeyModelPolicyUpdate{
+			ModelAllowlist: req.ModelAllowlist,
+			ModelDenylist:  req.ModelDenylist,
+			ModelAliases:   req.ModelAliases,
+		},
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
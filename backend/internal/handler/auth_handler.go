@@ -27,6 +27,7 @@ type AuthHandler struct {
 	redeemService        *service.RedeemService
 	totpService          *service.TotpService
 	userAttributeService *service.UserAttributeService
+	samlService          *service.SAMLService
 
 	dingTalkClientInstance *DingTalkClient
 	dingTalkClientMu       sync.Mutex
@@ -51,6 +51,8 @@ type oauthPendingSessionPayload struct {
 	BrowserSessionKey      string
 	UpstreamIdentityClaims map[string]any
 	CompletionResponse     map[string]any
+	// PromoCode 为空时回退读取 oauth_promo_code cookie
+	PromoCode string
 }
 
 type oauthAdoptionDecisionRequest struct {
@@ -240,7 +242,11 @@ func (h *AuthHandler) createOAuthPendingSession(c *gin.Context, payload oauthPen
 	localFlowState := map[string]any{
 		oauthCompletionResponseKey: payload.CompletionResponse,
 	}
-	if promoCode := readOAuthPromoCode(c); promoCode != "" {
+	promoCode := strings.TrimSpace(payload.PromoCode)
+	if promoCode == "" {
+		promoCode = readOAuthPromoCode(c)
+	}
+	if promoCode != "" {
 		localFlowState[oauthPromoCodeStateKey] = promoCode
 	}
 
@@ -1263,6 +1269,12 @@ func applyPendingOAuthBindingTx(
 		}
 	}
 
+	if authService != nil && strings.EqualFold(strings.TrimSpace(session.ProviderType), "saml") {
+		if err := authService.ApplySAMLDirectoryMapping(ctx, targetUserID, session.UpstreamIdentityClaims); err != nil {
+			return err
+		}
+	}
+
 	if shouldAdoptAvatar && userService != nil {
 		if _, err := userService.SetAvatar(ctx, targetUserID, adoptedAvatarURL); err != nil {
 			return err
@@ -526,7 +526,8 @@ func (h *AuthHandler) findOIDCCompatEmailUser(ctx context.Context, email string)
 		strings.HasSuffix(email, service.LinuxDoConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(email, service.OIDCConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(email, service.WeChatConnectSyntheticEmailDomain) ||
-		strings.HasSuffix(email, service.DingTalkConnectSyntheticEmailDomain) {
+		strings.HasSuffix(email, service.DingTalkConnectSyntheticEmailDomain) ||
+		strings.HasSuffix(email, service.SAMLSyntheticEmailDomain) {
 		return nil, nil
 	}
 
@@ -552,6 +553,30 @@ func (h *AuthHandler) createOIDCOAuthChoicePendingSession(
 	compatEmailUser *dbent.User,
 	forceEmailOnSignup bool,
 ) error {
+	return h.createOAuthPendingSession(c, buildOAuthChoicePendingSessionPayload(
+		identity,
+		suggestedEmail,
+		resolvedEmail,
+		redirectTo,
+		browserSessionKey,
+		upstreamClaims,
+		compatEmail,
+		compatEmailUser,
+		forceEmailOnSignup,
+	))
+}
+
+func buildOAuthChoicePendingSessionPayload(
+	identity service.PendingAuthIdentityKey,
+	suggestedEmail string,
+	resolvedEmail string,
+	redirectTo string,
+	browserSessionKey string,
+	upstreamClaims map[string]any,
+	compatEmail string,
+	compatEmailUser *dbent.User,
+	forceEmailOnSignup bool,
+) oauthPendingSessionPayload {
 	suggestionEmail := strings.TrimSpace(suggestedEmail)
 	canonicalEmail := strings.TrimSpace(resolvedEmail)
 	if suggestionEmail == "" {
@@ -592,7 +617,7 @@ func (h *AuthHandler) createOIDCOAuthChoicePendingSession(
 		targetUserID = &compatEmailUser.ID
 	}
 
-	return h.createOAuthPendingSession(c, oauthPendingSessionPayload{
+	return oauthPendingSessionPayload{
 		Intent:                 oauthIntentLogin,
 		Identity:               identity,
 		TargetUserID:           targetUserID,
@@ -601,7 +626,7 @@ func (h *AuthHandler) createOIDCOAuthChoicePendingSession(
 		BrowserSessionKey:      browserSessionKey,
 		UpstreamIdentityClaims: upstreamClaims,
 		CompletionResponse:     completionResponse,
-	})
+	}
 }
 
 type completeOIDCOAuthRequest struct {
@@ -615,6 +640,12 @@ type completeOIDCOAuthRequest struct {
 // the invitation code and creating the user account.
 // POST /api/v1/auth/oauth/oidc/complete-registration
 func (h *AuthHandler) CompleteOIDCOAuthRegistration(c *gin.Context) {
+	h.completeInvitationOAuthRegistration(c, "oidc")
+}
+
+// completeInvitationOAuthRegistration is shared by providers whose pending
+// sessions carry a synthetic email (OIDC, SAML) and only need an invitation code.
+func (h *AuthHandler) completeInvitationOAuthRegistration(c *gin.Context, signupSource string) {
 	var req completeOIDCOAuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": err.Error()})
@@ -652,6 +683,10 @@ func (h *AuthHandler) CompleteOIDCOAuthRegistration(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
+	if !strings.EqualFold(strings.TrimSpace(session.ProviderType), signupSource) {
+		response.BadRequest(c, "Pending oauth session provider mismatch")
+		return
+	}
 	if updatedSession, handled, err := h.legacyCompleteRegistrationSessionStatus(c, session); err != nil {
 		response.ErrorFrom(c, err)
 		return
@@ -697,7 +732,7 @@ func (h *AuthHandler) CompleteOIDCOAuthRegistration(c *gin.Context) {
 		req.InvitationCode,
 		req.AffCode,
 		pendingOAuthPromoCode(session),
-		"oidc",
+		signupSource,
 	)
 	if err != nil {
 		response.ErrorFrom(c, err)
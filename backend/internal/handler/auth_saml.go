@@ -0,0 +1,405 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/oauth"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/crewjam/saml"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	samlOAuthCookiePath            = "/api/v1/auth/oauth/saml"
+	samlOAuthRequestIDCookieName   = "saml_oauth_request_id"
+	samlOAuthRelayStateCookieName  = "saml_oauth_relay_state"
+	samlOAuthRedirectCookieName    = "saml_oauth_redirect"
+	samlOAuthIntentCookieName      = "saml_oauth_intent"
+	samlOAuthBindUserCookieName    = "saml_oauth_bind_user"
+	samlOAuthBrowserCookieName     = "saml_oauth_browser_session"
+	samlOAuthPromoCodeCookieName   = "saml_oauth_promo_code"
+	samlOAuthCookieMaxAgeSec       = 10 * 60 // 10 minutes
+	samlOAuthDefaultRedirectTo     = "/dashboard"
+	samlOAuthDefaultFrontendCB     = "/auth/saml/callback"
+	samlOAuthMetadataContentType   = "application/samlmetadata+xml"
+	samlOAuthResponseFormFieldName = "SAMLResponse"
+)
+
+// SetSAMLService injects the optional SAML service.
+func (h *AuthHandler) SetSAMLService(samlService *service.SAMLService) {
+	h.samlService = samlService
+}
+
+// SAMLOAuthStart 发起 SP-initiated SAML 登录（HTTP-Redirect 绑定的 AuthnRequest）。
+// GET /api/v1/auth/oauth/saml/start?redirect=/dashboard
+func (h *AuthHandler) SAMLOAuthStart(c *gin.Context) {
+	if !h.requireActionCaptchaForOAuthLoginStart(c) {
+		return
+	}
+	if h.samlService == nil {
+		response.ErrorFrom(c, service.ErrSAMLDisabled)
+		return
+	}
+	sp, err := h.samlService.ServiceProvider(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	relayState, err := oauth.GenerateState()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to generate oauth state").WithCause(err))
+		return
+	}
+	browserSessionKey, err := generateOAuthPendingBrowserSession()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_BROWSER_SESSION_GEN_FAILED", "failed to generate oauth browser session").WithCause(err))
+		return
+	}
+
+	authnRequest, err := sp.MakeAuthenticationRequest(
+		sp.GetSSOBindingLocation(saml.HTTPRedirectBinding),
+		saml.HTTPRedirectBinding,
+		saml.HTTPPostBinding,
+	)
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_BUILD_URL_FAILED", "failed to build saml authn request").WithCause(err))
+		return
+	}
+	authURL, err := authnRequest.Redirect(relayState, sp)
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_BUILD_URL_FAILED", "failed to build saml authn request").WithCause(err))
+		return
+	}
+
+	redirectTo := sanitizeFrontendRedirectPath(c.Query("redirect"))
+	if redirectTo == "" {
+		redirectTo = samlOAuthDefaultRedirectTo
+	}
+
+	secureCookie := isRequestHTTPS(c)
+	samlSetCookie(c, samlOAuthRequestIDCookieName, encodeCookieValue(authnRequest.ID), secureCookie)
+	samlSetCookie(c, samlOAuthRelayStateCookieName, encodeCookieValue(relayState), secureCookie)
+	samlSetCookie(c, samlOAuthRedirectCookieName, encodeCookieValue(redirectTo), secureCookie)
+	samlSetCookie(c, samlOAuthBrowserCookieName, encodeCookieValue(browserSessionKey), secureCookie)
+	intent := normalizeOAuthIntent(c.Query("intent"))
+	samlSetCookie(c, samlOAuthIntentCookieName, encodeCookieValue(intent), secureCookie)
+	if promoCode := strings.TrimSpace(c.Query("promo_code")); promoCode != "" {
+		samlSetCookie(c, samlOAuthPromoCodeCookieName, encodeCookieValue(promoCode), secureCookie)
+	} else {
+		samlClearCookie(c, samlOAuthPromoCodeCookieName, secureCookie)
+	}
+	setOAuthPendingBrowserCookie(c, browserSessionKey, secureCookie)
+	clearOAuthPendingSessionCookie(c, secureCookie)
+	if intent == oauthIntentBindCurrentUser {
+		bindCookieValue, err := h.buildOAuthBindUserCookieFromContext(c)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+		samlSetCookie(c, samlOAuthBindUserCookieName, encodeCookieValue(bindCookieValue), secureCookie)
+	} else {
+		samlClearCookie(c, samlOAuthBindUserCookieName, secureCookie)
+	}
+
+	respondOAuthStart(c, authURL.String())
+}
+
+// SAMLOAuthACS 是 Assertion Consumer Service：校验 IdP POST 回来的断言，
+// 然后与 OIDC 回调一样进入 pending session 绑定流程。
+// POST /api/v1/auth/oauth/saml/acs
+func (h *AuthHandler) SAMLOAuthACS(c *gin.Context) {
+	if h.samlService == nil {
+		response.ErrorFrom(c, service.ErrSAMLDisabled)
+		return
+	}
+	cfg, err := h.samlService.Config()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	frontendCallback := strings.TrimSpace(cfg.FrontendRedirectURL)
+	if frontendCallback == "" {
+		frontendCallback = samlOAuthDefaultFrontendCB
+	}
+
+	secureCookie := isRequestHTTPS(c)
+	defer func() {
+		samlClearCookie(c, samlOAuthRequestIDCookieName, secureCookie)
+		samlClearCookie(c, samlOAuthRelayStateCookieName, secureCookie)
+		samlClearCookie(c, samlOAuthRedirectCookieName, secureCookie)
+		samlClearCookie(c, samlOAuthIntentCookieName, secureCookie)
+		samlClearCookie(c, samlOAuthBindUserCookieName, secureCookie)
+		samlClearCookie(c, samlOAuthBrowserCookieName, secureCookie)
+		samlClearCookie(c, samlOAuthPromoCodeCookieName, secureCookie)
+	}()
+
+	if strings.TrimSpace(c.PostForm(samlOAuthResponseFormFieldName)) == "" {
+		redirectOAuthError(c, frontendCallback, "missing_params", "missing SAMLResponse", "")
+		return
+	}
+
+	sp, err := h.samlService.ServiceProvider(c.Request.Context())
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "config_error", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+
+	relayState := strings.TrimSpace(c.PostForm("RelayState"))
+	requestID, _ := readCookieDecoded(c, samlOAuthRequestIDCookieName)
+	requestID = strings.TrimSpace(requestID)
+
+	var (
+		possibleRequestIDs []string
+		redirectTo         string
+		intent             = oauthIntentLogin
+		browserSessionKey  string
+		promoCode          string
+	)
+	if requestID != "" {
+		// SP-initiated：RelayState 必须与发起时写入的随机值一致，且只接受对应 AuthnRequest 的响应
+		expectedRelayState, _ := readCookieDecoded(c, samlOAuthRelayStateCookieName)
+		if expectedRelayState == "" || relayState != expectedRelayState {
+			redirectOAuthError(c, frontendCallback, "invalid_state", "invalid saml relay state", "")
+			return
+		}
+		possibleRequestIDs = []string{requestID}
+		sp.AllowIDPInitiated = false
+		redirectTo, _ = readCookieDecoded(c, samlOAuthRedirectCookieName)
+		intentValue, _ := readCookieDecoded(c, samlOAuthIntentCookieName)
+		intent = normalizeOAuthIntent(intentValue)
+		browserSessionKey, _ = readCookieDecoded(c, samlOAuthBrowserCookieName)
+		promoCode, _ = readCookieDecoded(c, samlOAuthPromoCodeCookieName)
+	} else {
+		if !cfg.AllowIdPInitiated {
+			redirectOAuthError(c, frontendCallback, "invalid_state", "missing saml request state", "")
+			return
+		}
+		// IdP-initiated：没有发起记录，RelayState 仅作为登录后跳转路径使用
+		redirectTo = relayState
+		browserSessionKey, err = generateOAuthPendingBrowserSession()
+		if err != nil {
+			redirectOAuthError(c, frontendCallback, "session_error", "failed to generate oauth browser session", "")
+			return
+		}
+	}
+	redirectTo = sanitizeFrontendRedirectPath(redirectTo)
+	if redirectTo == "" {
+		redirectTo = samlOAuthDefaultRedirectTo
+	}
+	if strings.TrimSpace(browserSessionKey) == "" {
+		redirectOAuthError(c, frontendCallback, "missing_browser_session", "missing oauth browser session", "")
+		return
+	}
+
+	assertion, err := sp.ParseResponse(c.Request, possibleRequestIDs)
+	if err != nil {
+		var invalidErr *saml.InvalidResponseError
+		if errors.As(err, &invalidErr) && invalidErr != nil {
+			log.Printf("[SAML] assertion validation failed: %v", invalidErr.PrivateErr)
+		} else {
+			log.Printf("[SAML] assertion validation failed: %v", err)
+		}
+		redirectOAuthError(c, frontendCallback, "invalid_assertion", "failed to validate saml assertion", "")
+		return
+	}
+	if err := h.samlService.ConsumeAssertion(c.Request.Context(), assertion); err != nil {
+		if errors.Is(err, service.ErrSAMLAssertionReplayed) {
+			log.Printf("[SAML] rejected replayed assertion: id=%s", assertion.ID)
+		}
+		redirectOAuthError(c, frontendCallback, "invalid_assertion", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+	identity, err := h.samlService.ResolveIdentity(sp, assertion)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "invalid_assertion", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+
+	// ACS 是跨站 POST，Lax 的通用浏览器会话 cookie 不会随请求带上，这里用 SAML 流程里保存的副本重新写回
+	setOAuthPendingBrowserCookie(c, browserSessionKey, secureCookie)
+
+	identityRef := service.PendingAuthIdentityKey{
+		ProviderType:    "saml",
+		ProviderKey:     identity.Issuer,
+		ProviderSubject: identity.Subject,
+	}
+	email := samlSyntheticEmail(identity.Issuer, identity.Subject)
+	username := firstNonEmpty(identity.Username, identity.DisplayName, samlFallbackUsername(identity.Subject))
+	compatEmail := ""
+	if cfg.TrustEmail {
+		compatEmail = identity.Email
+	}
+	upstreamClaims := map[string]any{
+		"email":                  email,
+		"username":               username,
+		"subject":                identity.Subject,
+		"issuer":                 identity.Issuer,
+		"email_verified":         cfg.TrustEmail && identity.Email != "",
+		"provider_fallback":      strings.TrimSpace(cfg.ProviderName),
+		"suggested_display_name": firstNonEmpty(identity.DisplayName, username),
+	}
+	for key, value := range identity.DirectoryClaims() {
+		upstreamClaims[key] = value
+	}
+	if compatEmail != "" && !strings.EqualFold(compatEmail, email) {
+		upstreamClaims["compat_email"] = compatEmail
+	}
+
+	if intent == oauthIntentBindCurrentUser {
+		targetUserID, err := h.readOAuthBindUserIDFromCookie(c, samlOAuthBindUserCookieName)
+		if err != nil {
+			redirectOAuthError(c, frontendCallback, "invalid_state", "invalid oauth bind target", "")
+			return
+		}
+		if err := h.createOAuthPendingSession(c, oauthPendingSessionPayload{
+			Intent:                 oauthIntentBindCurrentUser,
+			Identity:               identityRef,
+			TargetUserID:           &targetUserID,
+			ResolvedEmail:          email,
+			RedirectTo:             redirectTo,
+			BrowserSessionKey:      browserSessionKey,
+			UpstreamIdentityClaims: upstreamClaims,
+			PromoCode:              promoCode,
+			CompletionResponse: map[string]any{
+				"redirect": redirectTo,
+			},
+		}); err != nil {
+			redirectOAuthError(c, frontendCallback, "session_error", "failed to continue oauth bind", "")
+			return
+		}
+		redirectToFrontendCallback(c, frontendCallback)
+		return
+	}
+
+	existingIdentityUser, err := h.findOAuthIdentityUser(c.Request.Context(), identityRef)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "session_error", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+	if existingIdentityUser != nil {
+		if err := h.createOAuthPendingSession(c, oauthPendingSessionPayload{
+			Intent:                 oauthIntentLogin,
+			Identity:               identityRef,
+			TargetUserID:           &existingIdentityUser.ID,
+			ResolvedEmail:          existingIdentityUser.Email,
+			RedirectTo:             redirectTo,
+			BrowserSessionKey:      browserSessionKey,
+			UpstreamIdentityClaims: upstreamClaims,
+			PromoCode:              promoCode,
+			CompletionResponse: map[string]any{
+				"redirect": redirectTo,
+			},
+		}); err != nil {
+			redirectOAuthError(c, frontendCallback, "session_error", "failed to continue oauth login", "")
+			return
+		}
+		redirectToFrontendCallback(c, frontendCallback)
+		return
+	}
+
+	compatEmailUser, err := h.findOIDCCompatEmailUser(c.Request.Context(), compatEmail)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "session_error", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+
+	// SAML 不走已验证邮箱快捷路径：首次登录统一落到 choice 页，保证首绑/邮箱接管规则与目录映射一起生效
+	choicePayload := buildOAuthChoicePendingSessionPayload(
+		identityRef,
+		email,
+		email,
+		redirectTo,
+		browserSessionKey,
+		upstreamClaims,
+		compatEmail,
+		compatEmailUser,
+		h.isForceEmailOnThirdPartySignup(c.Request.Context()),
+	)
+	choicePayload.PromoCode = promoCode
+	if err := h.createOAuthPendingSession(c, choicePayload); err != nil {
+		redirectOAuthError(c, frontendCallback, "session_error", "failed to continue oauth login", "")
+		return
+	}
+	redirectToFrontendCallback(c, frontendCallback)
+}
+
+// SAMLMetadata 输出本 SP 的元数据，供 IdP 侧导入。
+// GET /api/v1/auth/oauth/saml/metadata
+func (h *AuthHandler) SAMLMetadata(c *gin.Context) {
+	if h.samlService == nil {
+		response.ErrorFrom(c, service.ErrSAMLDisabled)
+		return
+	}
+	metadata, err := h.samlService.SPMetadata()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Data(http.StatusOK, samlOAuthMetadataContentType, metadata)
+}
+
+// CompleteSAMLOAuthRegistration completes a pending SAML registration with an invitation code.
+// POST /api/v1/auth/oauth/saml/complete-registration
+func (h *AuthHandler) CompleteSAMLOAuthRegistration(c *gin.Context) {
+	h.completeInvitationOAuthRegistration(c, "saml")
+}
+
+func (h *AuthHandler) BindSAMLOAuthLogin(c *gin.Context) { h.bindPendingOAuthLogin(c, "saml") }
+
+func (h *AuthHandler) CreateSAMLOAuthAccount(c *gin.Context) { h.createPendingOAuthAccount(c, "saml") }
+
+func samlSyntheticEmail(issuer, subject string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(issuer)) + "\x1f" + strings.TrimSpace(subject)))
+	return "saml-" + hex.EncodeToString(sum[:16]) + service.SAMLSyntheticEmailDomain
+}
+
+func samlFallbackUsername(subject string) string {
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return "saml_user"
+	}
+	sum := sha256.Sum256([]byte(subject))
+	return "saml_" + hex.EncodeToString(sum[:])[:12]
+}
+
+// samlSetCookie 写入 SAML 流程 cookie。IdP 以跨站 POST 回调 ACS，HTTPS 下必须用
+// SameSite=None 才能带回；纯 HTTP 部署浏览器不接受 None，只能退回 Lax（仅同站 IdP 可用）。
+func samlSetCookie(c *gin.Context, name, value string, secure bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     samlOAuthCookiePath,
+		MaxAge:   samlOAuthCookieMaxAgeSec,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: samlCookieSameSite(secure),
+	})
+}
+
+func samlClearCookie(c *gin.Context, name string, secure bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     samlOAuthCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: samlCookieSameSite(secure),
+	})
+}
+
+func samlCookieSameSite(secure bool) http.SameSite {
+	if secure {
+		return http.SameSiteNoneMode
+	}
+	return http.SameSiteLaxMode
+}
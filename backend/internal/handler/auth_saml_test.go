@@ -0,0 +1,479 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/authidentity"
+	"github.com/Wei-Shaw/sub2api/ent/pendingauthsession"
+	"github.com/Wei-Shaw/sub2api/ent/userallowedgroup"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/crewjam/saml"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	samlTestACSURL   = "https://sub2api.example.test/api/v1/auth/oauth/saml/acs"
+	samlTestEntityID = "https://sub2api.example.test/api/v1/auth/oauth/saml/metadata"
+)
+
+// newSAMLTestIdP 生成一个本地自签名证书的 IdP，用于签发测试断言。
+func newSAMLTestIdP(t *testing.T, entityHost string) (*saml.IdentityProvider, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: entityHost},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	metadataURL, err := url.Parse("https://" + entityHost + "/metadata")
+	require.NoError(t, err)
+	ssoURL, err := url.Parse("https://" + entityHost + "/sso")
+	require.NoError(t, err)
+	idp := &saml.IdentityProvider{
+		Key:         key,
+		Certificate: cert,
+		MetadataURL: *metadataURL,
+		SSOURL:      *ssoURL,
+	}
+	metadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	return idp, string(metadata)
+}
+
+func newSAMLOAuthHandlerAndClient(t *testing.T, samlCfg config.SAMLConfig) (*AuthHandler, *dbent.Client) {
+	t.Helper()
+
+	handler, client := newOIDCOAuthHandlerAndClient(t, false, config.OIDCConnectConfig{})
+	t.Cleanup(func() { _ = client.Close() })
+	samlCfg.Enabled = true
+	samlCfg.ACSURL = samlTestACSURL
+	if samlCfg.EntityID == "" {
+		samlCfg.EntityID = samlTestEntityID
+	}
+	if samlCfg.FrontendRedirectURL == "" {
+		samlCfg.FrontendRedirectURL = "/auth/saml/callback"
+	}
+	handler.cfg.SAML = samlCfg
+	handler.SetSAMLService(service.NewSAMLService(handler.cfg, nil, nil))
+	return handler, client
+}
+
+// signSAMLTestResponse 让测试 IdP 针对本 SP 签发一份 POST 绑定的 SAMLResponse。
+func signSAMLTestResponse(t *testing.T, handler *AuthHandler, idp *saml.IdentityProvider, requestID string, session *saml.Session) string {
+	t.Helper()
+
+	sp, err := handler.samlService.ServiceProvider(context.Background())
+	require.NoError(t, err)
+	spMetadata := sp.Metadata()
+	idpReq := &saml.IdpAuthnRequest{
+		IDP:                     idp,
+		HTTPRequest:             httptest.NewRequest(http.MethodGet, idp.SSOURL.String(), nil),
+		Request:                 saml.AuthnRequest{ID: requestID, Issuer: &saml.Issuer{Value: sp.EntityID}},
+		ServiceProviderMetadata: spMetadata,
+		SPSSODescriptor:         &spMetadata.SPSSODescriptors[0],
+		ACSEndpoint:             &saml.IndexedEndpoint{Binding: saml.HTTPPostBinding, Location: samlTestACSURL},
+		Now:                     saml.TimeNow(),
+	}
+	require.NoError(t, saml.DefaultAssertionMaker{}.MakeAssertion(idpReq, session))
+	form, err := idpReq.PostBinding()
+	require.NoError(t, err)
+	return form.SAMLResponse
+}
+
+func newSAMLACSRequest(samlResponse, relayState string) *http.Request {
+	form := url.Values{}
+	form.Set("SAMLResponse", samlResponse)
+	if relayState != "" {
+		form.Set("RelayState", relayState)
+	}
+	req := httptest.NewRequest(http.MethodPost, samlTestACSURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func samlTestSession(nameID string, groups ...string) *saml.Session {
+	return &saml.Session{
+		ID:             "idp-session-" + nameID,
+		CreateTime:     saml.TimeNow(),
+		ExpireTime:     saml.TimeNow().Add(time.Hour),
+		Index:          "idx-" + nameID,
+		NameID:         nameID,
+		UserName:       "saml_" + nameID,
+		UserEmail:      nameID + "@corp.example.com",
+		UserCommonName: "SAML " + nameID,
+		Groups:         groups,
+	}
+}
+
+func TestSAMLOAuthStartRedirectsToIdPAndSetsCrossSiteCookies(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-start.example.test")
+	handler, _ := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/saml/start?redirect=/keys", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	c.Request = req
+
+	handler.SAMLOAuthStart(c)
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, idp.SSOURL.Host, location.Host)
+	require.NotEmpty(t, location.Query().Get("SAMLRequest"))
+
+	cookies := recorder.Result().Cookies()
+	requestIDCookie := findCookie(cookies, samlOAuthRequestIDCookieName)
+	require.NotNil(t, requestIDCookie)
+	require.Equal(t, http.SameSiteNoneMode, requestIDCookie.SameSite)
+	require.True(t, requestIDCookie.Secure)
+	relayCookie := findCookie(cookies, samlOAuthRelayStateCookieName)
+	require.NotNil(t, relayCookie)
+	require.Equal(t, location.Query().Get("RelayState"), decodeCookieValueForTest(t, relayCookie.Value))
+	redirectCookie := findCookie(cookies, samlOAuthRedirectCookieName)
+	require.NotNil(t, redirectCookie)
+	require.Equal(t, "/keys", decodeCookieValueForTest(t, redirectCookie.Value))
+	require.NotNil(t, findCookie(cookies, samlOAuthBrowserCookieName))
+	require.NotNil(t, findCookie(cookies, oauthPendingBrowserCookieName))
+}
+
+func TestSAMLOAuthACSCreatesChoicePendingSessionWithDirectoryClaims(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-choice.example.test")
+	handler, client := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{
+		IdPMetadataXML:  metadata,
+		GroupsAttribute: "eduPersonAffiliation",
+		AdminGroups:     []string{"sub2api-admins"},
+		GroupMappings: []config.SAMLGroupMapping{
+			{IdPGroup: "engineering", GroupIDs: []int64{7, 3}},
+		},
+	})
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "id-req-choice", samlTestSession("alice", "engineering", "sub2api-admins"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	req := newSAMLACSRequest(samlResponse, "relay-choice")
+	req.AddCookie(encodedCookie(samlOAuthRequestIDCookieName, "id-req-choice"))
+	req.AddCookie(encodedCookie(samlOAuthRelayStateCookieName, "relay-choice"))
+	req.AddCookie(encodedCookie(samlOAuthRedirectCookieName, "/keys"))
+	req.AddCookie(encodedCookie(samlOAuthIntentCookieName, oauthIntentLogin))
+	req.AddCookie(encodedCookie(samlOAuthBrowserCookieName, "browser-saml-choice"))
+	req.AddCookie(encodedCookie(samlOAuthPromoCodeCookieName, "PROMO-SAML"))
+	c.Request = req
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Equal(t, "/auth/saml/callback", recorder.Header().Get("Location"))
+
+	cookies := recorder.Result().Cookies()
+	browserCookie := findCookie(cookies, oauthPendingBrowserCookieName)
+	require.NotNil(t, browserCookie)
+	require.Equal(t, "browser-saml-choice", decodeCookieValueForTest(t, browserCookie.Value))
+	sessionCookie := findCookie(cookies, oauthPendingSessionCookieName)
+	require.NotNil(t, sessionCookie)
+
+	session, err := client.PendingAuthSession.Query().
+		Where(pendingauthsession.SessionTokenEQ(decodeCookieValueForTest(t, sessionCookie.Value))).
+		Only(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "saml", session.ProviderType)
+	require.Equal(t, idp.MetadataURL.String(), session.ProviderKey)
+	require.Equal(t, "alice", session.ProviderSubject)
+	require.Nil(t, session.TargetUserID)
+	require.True(t, strings.HasSuffix(session.ResolvedEmail, service.SAMLSyntheticEmailDomain))
+	require.Equal(t, "saml_alice", session.UpstreamIdentityClaims["username"])
+	require.Equal(t, service.RoleAdmin, session.UpstreamIdentityClaims[service.SAMLClaimRole])
+	role, groupIDs := service.SAMLDirectoryMappingFromClaims(session.UpstreamIdentityClaims)
+	require.Equal(t, service.RoleAdmin, role)
+	require.Equal(t, []int64{3, 7}, groupIDs)
+	// 未开启 trust_email 时不使用断言邮箱匹配已有账号
+	require.Nil(t, session.UpstreamIdentityClaims["compat_email"])
+	require.Equal(t, "PROMO-SAML", session.LocalFlowState[oauthPromoCodeStateKey])
+
+	completion, ok := session.LocalFlowState[oauthCompletionResponseKey].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, oauthPendingChoiceStep, completion["step"])
+	require.Equal(t, "/keys", completion["redirect"])
+}
+
+func TestSAMLOAuthACSTrustEmailOffersExistingAccountBind(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-compat.example.test")
+	handler, client := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata, TrustEmail: true})
+
+	ctx := context.Background()
+	existing, err := client.User.Create().
+		SetEmail("bob@corp.example.com").
+		SetUsername("bob").
+		SetPasswordHash("hash").
+		SetRole(service.RoleUser).
+		SetStatus(service.StatusActive).
+		Save(ctx)
+	require.NoError(t, err)
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "id-req-compat", samlTestSession("bob"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	req := newSAMLACSRequest(samlResponse, "relay-compat")
+	req.AddCookie(encodedCookie(samlOAuthRequestIDCookieName, "id-req-compat"))
+	req.AddCookie(encodedCookie(samlOAuthRelayStateCookieName, "relay-compat"))
+	req.AddCookie(encodedCookie(samlOAuthBrowserCookieName, "browser-saml-compat"))
+	c.Request = req
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	sessionCookie := findCookie(recorder.Result().Cookies(), oauthPendingSessionCookieName)
+	require.NotNil(t, sessionCookie)
+	session, err := client.PendingAuthSession.Query().
+		Where(pendingauthsession.SessionTokenEQ(decodeCookieValueForTest(t, sessionCookie.Value))).
+		Only(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, session.TargetUserID)
+	require.Equal(t, existing.ID, *session.TargetUserID)
+
+	completion, ok := session.LocalFlowState[oauthCompletionResponseKey].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "compat_email_match", completion["choice_reason"])
+	require.Equal(t, true, completion["existing_account_bindable"])
+}
+
+func TestSAMLOAuthACSRejectsRelayStateMismatch(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-relay.example.test")
+	handler, client := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata})
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "id-req-relay", samlTestSession("carol"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	req := newSAMLACSRequest(samlResponse, "forged-relay")
+	req.AddCookie(encodedCookie(samlOAuthRequestIDCookieName, "id-req-relay"))
+	req.AddCookie(encodedCookie(samlOAuthRelayStateCookieName, "relay-expected"))
+	req.AddCookie(encodedCookie(samlOAuthBrowserCookieName, "browser-saml-relay"))
+	c.Request = req
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Location"), "error=invalid_state")
+	count, err := client.PendingAuthSession.Query().Count(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestSAMLOAuthACSRejectsResponseForDifferentRequest(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-inresponseto.example.test")
+	handler, _ := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata, AllowIdPInitiated: true})
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "id-req-other", samlTestSession("dave"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	req := newSAMLACSRequest(samlResponse, "relay-irt")
+	req.AddCookie(encodedCookie(samlOAuthRequestIDCookieName, "id-req-mine"))
+	req.AddCookie(encodedCookie(samlOAuthRelayStateCookieName, "relay-irt"))
+	req.AddCookie(encodedCookie(samlOAuthBrowserCookieName, "browser-saml-irt"))
+	c.Request = req
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Location"), "error=invalid_assertion")
+}
+
+func TestSAMLOAuthACSRejectsUntrustedSigningCertificate(t *testing.T) {
+	_, metadata := newSAMLTestIdP(t, "idp-trusted.example.test")
+	rogueIdP, _ := newSAMLTestIdP(t, "idp-trusted.example.test")
+	handler, _ := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata})
+
+	samlResponse := signSAMLTestResponse(t, handler, rogueIdP, "id-req-rogue", samlTestSession("mallory"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	req := newSAMLACSRequest(samlResponse, "relay-rogue")
+	req.AddCookie(encodedCookie(samlOAuthRequestIDCookieName, "id-req-rogue"))
+	req.AddCookie(encodedCookie(samlOAuthRelayStateCookieName, "relay-rogue"))
+	req.AddCookie(encodedCookie(samlOAuthBrowserCookieName, "browser-saml-rogue"))
+	c.Request = req
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Location"), "error=invalid_assertion")
+}
+
+func TestSAMLOAuthACSRejectsIdPInitiatedWhenDisabled(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-unsolicited.example.test")
+	handler, _ := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata})
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "", samlTestSession("erin"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = newSAMLACSRequest(samlResponse, "/dashboard")
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Location"), "error=invalid_state")
+}
+
+func TestSAMLOAuthACSRejectsReplayedAssertion(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-replay.example.test")
+	handler, _ := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{
+		IdPMetadataXML:    metadata,
+		AllowIdPInitiated: true,
+	})
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "", samlTestSession("grace"))
+	post := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = newSAMLACSRequest(samlResponse, "/dashboard")
+		handler.SAMLOAuthACS(c)
+		c.Writer.WriteHeaderNow()
+		return recorder
+	}
+
+	first := post()
+	require.Equal(t, http.StatusFound, first.Code)
+	require.Equal(t, "/auth/saml/callback", first.Header().Get("Location"))
+	require.NotNil(t, findCookie(first.Result().Cookies(), oauthPendingSessionCookieName))
+
+	replayed := post()
+	require.Equal(t, http.StatusFound, replayed.Code)
+	require.Contains(t, replayed.Header().Get("Location"), "error=invalid_assertion")
+	require.Contains(t, replayed.Header().Get("Location"), "SAML_ASSERTION_REPLAYED")
+	require.Nil(t, findCookie(replayed.Result().Cookies(), oauthPendingSessionCookieName))
+}
+
+func TestSAMLOAuthIdPInitiatedLoginAppliesDirectoryMappingOnExchange(t *testing.T) {
+	idp, metadata := newSAMLTestIdP(t, "idp-initiated.example.test")
+	ctx := context.Background()
+	handler, client := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{
+		IdPMetadataXML:    metadata,
+		AllowIdPInitiated: true,
+		GroupsAttribute:   "eduPersonAffiliation",
+		AdminGroups:       []string{"sub2api-admins"},
+		GroupMappings: []config.SAMLGroupMapping{
+			{IdPGroup: "engineering", GroupIDs: []int64{9999}},
+		},
+	})
+
+	group, err := client.Group.Create().SetName("saml-engineering").Save(ctx)
+	require.NoError(t, err)
+	handler.cfg.SAML.GroupMappings[0].GroupIDs = append(handler.cfg.SAML.GroupMappings[0].GroupIDs, group.ID)
+
+	existing, err := client.User.Create().
+		SetEmail("frank@corp.example.com").
+		SetUsername("frank").
+		SetPasswordHash("hash").
+		SetRole(service.RoleUser).
+		SetStatus(service.StatusActive).
+		Save(ctx)
+	require.NoError(t, err)
+	_, err = client.AuthIdentity.Create().
+		SetUserID(existing.ID).
+		SetProviderType("saml").
+		SetProviderKey(idp.MetadataURL.String()).
+		SetProviderSubject("frank").
+		SetMetadata(map[string]any{"username": "frank"}).
+		Save(ctx)
+	require.NoError(t, err)
+
+	samlResponse := signSAMLTestResponse(t, handler, idp, "", samlTestSession("frank", "engineering", "sub2api-admins"))
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = newSAMLACSRequest(samlResponse, "/admin/users")
+
+	handler.SAMLOAuthACS(c)
+	c.Writer.WriteHeaderNow()
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	require.Equal(t, "/auth/saml/callback", recorder.Header().Get("Location"))
+	cookies := recorder.Result().Cookies()
+	sessionCookie := findCookie(cookies, oauthPendingSessionCookieName)
+	require.NotNil(t, sessionCookie)
+	browserCookie := findCookie(cookies, oauthPendingBrowserCookieName)
+	require.NotNil(t, browserCookie)
+
+	session, err := client.PendingAuthSession.Query().
+		Where(pendingauthsession.SessionTokenEQ(decodeCookieValueForTest(t, sessionCookie.Value))).
+		Only(ctx)
+	require.NoError(t, err)
+	require.Equal(t, oauthIntentLogin, session.Intent)
+	require.NotNil(t, session.TargetUserID)
+	require.Equal(t, existing.ID, *session.TargetUserID)
+	require.Equal(t, "/admin/users", session.RedirectTo)
+
+	exchangeRecorder := httptest.NewRecorder()
+	exchangeCtx, _ := gin.CreateTestContext(exchangeRecorder)
+	exchangeReq := httptest.NewRequest(http.MethodPost, "/api/v1/auth/oauth/pending/exchange", nil)
+	exchangeReq.AddCookie(&http.Cookie{Name: oauthPendingSessionCookieName, Value: sessionCookie.Value})
+	exchangeReq.AddCookie(&http.Cookie{Name: oauthPendingBrowserCookieName, Value: browserCookie.Value})
+	exchangeCtx.Request = exchangeReq
+
+	handler.ExchangePendingOAuthCompletion(exchangeCtx)
+
+	require.Equal(t, http.StatusOK, exchangeRecorder.Code)
+	updated, err := client.User.Get(ctx, existing.ID)
+	require.NoError(t, err)
+	require.Equal(t, service.RoleAdmin, updated.Role)
+	allowed, err := client.UserAllowedGroup.Query().
+		Where(userallowedgroup.UserIDEQ(existing.ID)).
+		All(ctx)
+	require.NoError(t, err)
+	require.Len(t, allowed, 1)
+	require.Equal(t, group.ID, allowed[0].GroupID)
+
+	identityCount, err := client.AuthIdentity.Query().
+		Where(authidentity.ProviderTypeEQ("saml"), authidentity.UserIDEQ(existing.ID)).
+		Count(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, identityCount)
+}
+
+func TestSAMLMetadataServesSPDescriptor(t *testing.T) {
+	_, metadata := newSAMLTestIdP(t, "idp-metadata.example.test")
+	handler, _ := newSAMLOAuthHandlerAndClient(t, config.SAMLConfig{IdPMetadataXML: metadata})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/saml/metadata", nil)
+
+	handler.SAMLMetadata(c)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, samlOAuthMetadataContentType, recorder.Header().Get("Content-Type"))
+	var descriptor saml.EntityDescriptor
+	require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &descriptor))
+	require.Equal(t, samlTestEntityID, descriptor.EntityID)
+	require.Len(t, descriptor.SPSSODescriptors, 1)
+	require.Equal(t, samlTestACSURL, descriptor.SPSSODescriptors[0].AssertionConsumerServices[0].Location)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceLedgerHandler handles the current user's balance ledger and statements
+type BalanceLedgerHandler struct {
+	ledgerService *service.BalanceLedgerService
+}
+
+// NewBalanceLedgerHandler creates a new BalanceLedgerHandler
+func NewBalanceLedgerHandler(ledgerService *service.BalanceLedgerService) *BalanceLedgerHandler {
+	return &BalanceLedgerHandler{ledgerService: ledgerService}
+}
+
+// List handles listing the current user's balance ledger entries
+// GET /api/v1/user/balance-ledger?reason=&start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=
+func (h *BalanceLedgerHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	startTime, endTime, ok := parseBalanceLedgerDateRange(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	filter := service.BalanceLedgerFilter{Reason: c.Query("reason"), StartTime: startTime, EndTime: endTime}
+	entries, result, err := h.ledgerService.ListForUser(c.Request.Context(), subject.UserID, filter, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.BalanceLedgerEntry, 0, len(entries))
+	for i := range entries {
+		out = append(out, *dto.BalanceLedgerEntryFromService(&entries[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Statement handles the current user's balance statement for a date range
+// GET /api/v1/user/balance-ledger/statement?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=&format=csv|json
+func (h *BalanceLedgerHandler) Statement(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	startTime, endTime, ok := parseBalanceLedgerDateRange(c)
+	if !ok {
+		return
+	}
+	if startTime == nil || endTime == nil {
+		response.BadRequest(c, "start_date and end_date are required")
+		return
+	}
+
+	statement, err := h.ledgerService.Statement(c.Request.Context(), subject.UserID, *startTime, *endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if c.DefaultQuery("format", "csv") == "json" {
+		response.Success(c, dto.BalanceStatementFromService(statement))
+		return
+	}
+
+	data, err := writeBalanceStatementCSV(statement)
+	if err != nil {
+		response.InternalError(c, "Export failed: "+err.Error())
+		return
+	}
+	filename := fmt.Sprintf("balance_statement_%s_%s.csv",
+		c.Query("start_date"), c.Query("end_date"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(200, "text/csv", data)
+}
+
+// parseBalanceLedgerDateRange 解析 start_date / end_date（按 timezone 参数的本地日期），
+// end_date 包含当天，返回的结束时间为次日零点。
+func parseBalanceLedgerDateRange(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var startTime, endTime *time.Time
+	userTZ := c.Query("timezone")
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return nil, nil, false
+		}
+		startTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return nil, nil, false
+		}
+		t = t.AddDate(0, 0, 1)
+		endTime = &t
+	}
+	return startTime, endTime, true
+}
+
+func writeBalanceStatementCSV(statement *service.BalanceStatement) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	formatAmount := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 8, 64)
+	}
+
+	if err := writer.Write([]string{"time", "reason", "source_type", "source_id", "amount", "balance_before", "balance_after", "frozen_delta", "note"}); err != nil {
+		return nil, err
+	}
+	if err := writer.Write([]string{statement.Start.UTC().Format(time.RFC3339), "opening_balance", "", "", "", "", formatAmount(statement.OpeningBalance), "", ""}); err != nil {
+		return nil, err
+	}
+	for i := range statement.Entries {
+		e := &statement.Entries[i]
+		if err := writer.Write([]string{
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			e.Reason,
+			e.SourceType,
+			e.SourceID,
+			formatAmount(e.Amount),
+			formatAmount(e.BalanceBefore),
+			formatAmount(e.BalanceAfter),
+			formatAmount(e.FrozenDelta),
+			e.Note,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Write([]string{statement.End.UTC().Format(time.RFC3339), "closing_balance", "", "", formatAmount(statement.TotalCredits - statement.TotalDebits), "", formatAmount(statement.ClosingBalance), "", ""}); err != nil {
+		return nil, err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -281,9 +281,10 @@ func batchImageOwnerFromContext(c *gin.Context) (service.BatchImageOwner, bool)
 		return service.BatchImageOwner{}, false
 	}
 	return service.BatchImageOwner{
-		UserID:   apiKey.UserID,
-		APIKeyID: apiKey.ID,
-		GroupID:  apiKey.GroupID,
+		UserID:         apiKey.UserID,
+		APIKeyID:       apiKey.ID,
+		GroupID:        apiKey.GroupID,
+		OrganizationID: apiKey.OrganizationID,
 	}, true
 }
 
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles the current user's spending budgets on
+// their account and their API keys.
+type BudgetHandler struct {
+	budgetService *service.BudgetService
+}
+
+// NewBudgetHandler creates a new BudgetHandler
+func NewBudgetHandler(budgetService *service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{budgetService: budgetService}
+}
+
+// CreateBudgetRequest represents the create budget payload.
+// scope_type is user (scope_id may be omitted) or api_key.
+type CreateBudgetRequest struct {
+	ScopeType   string  `json:"scope_type" binding:"required,oneof=user api_key"`
+	ScopeID     int64   `json:"scope_id"`
+	Model       string  `json:"model"`
+	Period      string  `json:"period" binding:"required"`
+	LimitUSD    float64 `json:"limit_usd" binding:"required"`
+	Enforcement string  `json:"enforcement"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// UpdateBudgetRequest represents the update budget payload (nil = no change)
+type UpdateBudgetRequest struct {
+	Model       *string  `json:"model"`
+	Period      *string  `json:"period"`
+	LimitUSD    *float64 `json:"limit_usd"`
+	Enforcement *string  `json:"enforcement"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+func (r *UpdateBudgetRequest) toInput() service.UpdateBudgetInput {
+	return service.UpdateBudgetInput{
+		Model:       r.Model,
+		Period:      r.Period,
+		LimitUSD:    r.LimitUSD,
+		Enforcement: r.Enforcement,
+		Enabled:     r.Enabled,
+	}
+}
+
+// List handles listing the budgets on the current user and their API keys with current period spend
+// GET /api/v1/budgets
+func (h *BudgetHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	statuses, err := h.budgetService.ListForUser(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.BudgetStatus, 0, len(statuses))
+	for i := range statuses {
+		out = append(out, *dto.BudgetStatusFromService(&statuses[i]))
+	}
+	response.Success(c, out)
+}
+
+// Create handles creating a budget on the current user or one of their API keys
+// POST /api/v1/budgets
+func (h *BudgetHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	budget, err := h.budgetService.CreateForUser(c.Request.Context(), subject.UserID, service.CreateBudgetInput{
+		ScopeType:   req.ScopeType,
+		ScopeID:     req.ScopeID,
+		Model:       req.Model,
+		Period:      req.Period,
+		LimitUSD:    req.LimitUSD,
+		Enforcement: req.Enforcement,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, dto.BudgetFromService(budget))
+}
+
+// Update handles updating a budget created by the current user
+// PUT /api/v1/budgets/:id
+func (h *BudgetHandler) Update(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	budgetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || budgetID <= 0 {
+		response.BadRequest(c, "Invalid budget ID")
+		return
+	}
+
+	var req UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	budget, err := h.budgetService.UpdateForUser(c.Request.Context(), subject.UserID, budgetID, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BudgetFromService(budget))
+}
+
+// Delete handles deleting a budget created by the current user
+// DELETE /api/v1/budgets/:id
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	budgetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || budgetID <= 0 {
+		response.BadRequest(c, "Invalid budget ID")
+		return
+	}
+
+	if err := h.budgetService.DeleteForUser(c.Request.Context(), subject.UserID, budgetID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Budget deleted successfully"})
+}
@@ -22,7 +22,11 @@ func NewChannelMonitorV2Handler(svc *service.ChannelMonitorV2Service) *ChannelMo
 
 // channelMonitorV2IsAdmin is true when the request already passed admin auth
 // (shared Dimensions/Errors handlers serve both user and admin route groups).
+// Staff roles carry admin permissions in context but keep users.role = user.
 func channelMonitorV2IsAdmin(c *gin.Context) bool {
+	if _, ok := middleware.GetAdminPermissionsFromContext(c); ok {
+		return true
+	}
 	role, ok := middleware.GetUserRoleFromContext(c)
 	return ok && role == service.RoleAdmin
 }
@@ -0,0 +1,124 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type BalanceLedgerEntry struct {
+	ID            int64     `json:"id"`
+	Reason        string    `json:"reason"`
+	SourceType    string    `json:"source_type"`
+	SourceID      string    `json:"source_id"`
+	Amount        float64   `json:"amount"`
+	BalanceBefore float64   `json:"balance_before"`
+	BalanceAfter  float64   `json:"balance_after"`
+	FrozenDelta   float64   `json:"frozen_delta"`
+	FrozenAfter   float64   `json:"frozen_after"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// AdminBalanceLedgerEntry 额外暴露用户 ID 与操作管理员 ID
+type AdminBalanceLedgerEntry struct {
+	BalanceLedgerEntry
+	UserID  int64  `json:"user_id"`
+	ActorID *int64 `json:"actor_id,omitempty"`
+}
+
+type BalanceStatement struct {
+	Start          time.Time            `json:"start"`
+	End            time.Time            `json:"end"`
+	OpeningBalance float64              `json:"opening_balance"`
+	ClosingBalance float64              `json:"closing_balance"`
+	TotalCredits   float64              `json:"total_credits"`
+	TotalDebits    float64              `json:"total_debits"`
+	Entries        []BalanceLedgerEntry `json:"entries"`
+}
+
+type BalanceReconciliationRun struct {
+	ID                int64                                  `json:"id"`
+	WindowStart       time.Time                              `json:"window_start"`
+	WindowEnd         time.Time                              `json:"window_end"`
+	BalanceDriftCount int                                    `json:"balance_drift_count"`
+	LedgerGapCount    int                                    `json:"ledger_gap_count"`
+	UsageDriftCount   int                                    `json:"usage_drift_count"`
+	PaymentDriftCount int                                    `json:"payment_drift_count"`
+	HasDrift          bool                                   `json:"has_drift"`
+	Findings          []service.BalanceReconciliationFinding `json:"findings,omitempty"`
+	StartedAt         time.Time                              `json:"started_at"`
+	FinishedAt        time.Time                              `json:"finished_at"`
+}
+
+func BalanceLedgerEntryFromService(e *service.BalanceLedgerEntry) *BalanceLedgerEntry {
+	if e == nil {
+		return nil
+	}
+	return &BalanceLedgerEntry{
+		ID:            e.ID,
+		Reason:        e.Reason,
+		SourceType:    e.SourceType,
+		SourceID:      e.SourceID,
+		Amount:        e.Amount,
+		BalanceBefore: e.BalanceBefore,
+		BalanceAfter:  e.BalanceAfter,
+		FrozenDelta:   e.FrozenDelta,
+		FrozenAfter:   e.FrozenAfter,
+		Note:          e.Note,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func AdminBalanceLedgerEntryFromService(e *service.BalanceLedgerEntry) *AdminBalanceLedgerEntry {
+	if e == nil {
+		return nil
+	}
+	return &AdminBalanceLedgerEntry{
+		BalanceLedgerEntry: *BalanceLedgerEntryFromService(e),
+		UserID:             e.UserID,
+		ActorID:            e.ActorID,
+	}
+}
+
+func BalanceStatementFromService(s *service.BalanceStatement) *BalanceStatement {
+	if s == nil {
+		return nil
+	}
+	out := &BalanceStatement{
+		Start:          s.Start,
+		End:            s.End,
+		OpeningBalance: s.OpeningBalance,
+		ClosingBalance: s.ClosingBalance,
+		TotalCredits:   s.TotalCredits,
+		TotalDebits:    s.TotalDebits,
+		Entries:        make([]BalanceLedgerEntry, 0, len(s.Entries)),
+	}
+	for i := range s.Entries {
+		out.Entries = append(out.Entries, *BalanceLedgerEntryFromService(&s.Entries[i]))
+	}
+	return out
+}
+
+// BalanceReconciliationRunFromService 转换对账结果；列表视图不带明细（withFindings=false）。
+func BalanceReconciliationRunFromService(r *service.BalanceReconciliationRun, withFindings bool) *BalanceReconciliationRun {
+	if r == nil {
+		return nil
+	}
+	out := &BalanceReconciliationRun{
+		ID:                r.ID,
+		WindowStart:       r.WindowStart,
+		WindowEnd:         r.WindowEnd,
+		BalanceDriftCount: r.BalanceDriftCount,
+		LedgerGapCount:    r.LedgerGapCount,
+		UsageDriftCount:   r.UsageDriftCount,
+		PaymentDriftCount: r.PaymentDriftCount,
+		HasDrift:          r.HasDrift(),
+		StartedAt:         r.StartedAt,
+		FinishedAt:        r.FinishedAt,
+	}
+	if withFindings {
+		out.Findings = r.Findings
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// Budget 自然周期预算。Model 为空表示全部模型。
+type Budget struct {
+	ID          int64     `json:"id"`
+	ScopeType   string    `json:"scope_type"`
+	ScopeID     int64     `json:"scope_id"`
+	Model       string    `json:"model"`
+	Period      string    `json:"period"`
+	LimitUSD    float64   `json:"limit_usd"`
+	Enforcement string    `json:"enforcement"`
+	Enabled     bool      `json:"enabled"`
+	Source      string    `json:"source"`
+	CreatedBy   *int64    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BudgetStatus 预算及其当前周期消费。
+type BudgetStatus struct {
+	Budget
+	PeriodStart  time.Time `json:"period_start"`
+	PeriodEnd    time.Time `json:"period_end"`
+	SpentUSD     float64   `json:"spent_usd"`
+	RemainingUSD float64   `json:"remaining_usd"`
+}
+
+func BudgetFromService(b *service.Budget) *Budget {
+	if b == nil {
+		return nil
+	}
+	return &Budget{
+		ID:          b.ID,
+		ScopeType:   b.ScopeType,
+		ScopeID:     b.ScopeID,
+		Model:       b.Model,
+		Period:      b.Period,
+		LimitUSD:    b.LimitUSD,
+		Enforcement: b.Enforcement,
+		Enabled:     b.Enabled,
+		Source:      b.Source,
+		CreatedBy:   b.CreatedBy,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+func BudgetStatusFromService(s *service.BudgetStatus) *BudgetStatus {
+	if s == nil {
+		return nil
+	}
+	remaining := s.Budget.LimitUSD - s.SpentUSD
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &BudgetStatus{
+		Budget:       *BudgetFromService(&s.Budget),
+		PeriodStart:  s.PeriodStart,
+		PeriodEnd:    s.PeriodEnd,
+		SpentUSD:     s.SpentUSD,
+		RemainingUSD: remaining,
+	}
+}
@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type BillingProfile struct {
+	OwnerType    string    `json:"owner_type"`
+	OwnerID      int64     `json:"owner_id"`
+	CompanyName  string    `json:"company_name"`
+	TaxID        string    `json:"tax_id"`
+	AddressLine1 string    `json:"address_line1"`
+	AddressLine2 string    `json:"address_line2"`
+	City         string    `json:"city"`
+	PostalCode   string    `json:"postal_code"`
+	Country      string    `json:"country"`
+	Email        string    `json:"email"`
+	Notes        string    `json:"notes"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type Invoice struct {
+	ID                int64                     `json:"id"`
+	Number            string                    `json:"number"`
+	Kind              string                    `json:"kind"`
+	Status            string                    `json:"status"`
+	OwnerType         string                    `json:"owner_type"`
+	OwnerID           int64                     `json:"owner_id"`
+	PaymentOrderID    *int64                    `json:"payment_order_id,omitempty"`
+	PeriodStart       *time.Time                `json:"period_start,omitempty"`
+	PeriodEnd         *time.Time                `json:"period_end,omitempty"`
+	Currency          string                    `json:"currency"`
+	Subtotal          float64                   `json:"subtotal"`
+	Total             float64                   `json:"total"`
+	BillTo            BillingProfile            `json:"bill_to"`
+	LineItems         []service.InvoiceLineItem `json:"line_items"`
+	ReplacesInvoiceID *int64                    `json:"replaces_invoice_id,omitempty"`
+	ReplacesNumber    string                    `json:"replaces_number,omitempty"`
+	VoidReason        string                    `json:"void_reason,omitempty"`
+	VoidedAt          *time.Time                `json:"voided_at,omitempty"`
+	IssuedAt          time.Time                 `json:"issued_at"`
+}
+
+// AdminInvoice 额外暴露签发 / 作废的管理员 ID
+type AdminInvoice struct {
+	Invoice
+	IssuedBy *int64 `json:"issued_by,omitempty"`
+	VoidedBy *int64 `json:"voided_by,omitempty"`
+}
+
+func BillingProfileFromService(p *service.BillingProfile) *BillingProfile {
+	if p == nil {
+		return nil
+	}
+	return &BillingProfile{
+		OwnerType:    p.OwnerType,
+		OwnerID:      p.OwnerID,
+		CompanyName:  p.CompanyName,
+		TaxID:        p.TaxID,
+		AddressLine1: p.AddressLine1,
+		AddressLine2: p.AddressLine2,
+		City:         p.City,
+		PostalCode:   p.PostalCode,
+		Country:      p.Country,
+		Email:        p.Email,
+		Notes:        p.Notes,
+		UpdatedAt:    p.UpdatedAt,
+	}
+}
+
+func InvoiceFromService(inv *service.Invoice) *Invoice {
+	if inv == nil {
+		return nil
+	}
+	lines := inv.LineItems
+	if lines == nil {
+		lines = []service.InvoiceLineItem{}
+	}
+	return &Invoice{
+		ID:                inv.ID,
+		Number:            inv.Number,
+		Kind:              inv.Kind,
+		Status:            inv.Status,
+		OwnerType:         inv.OwnerType,
+		OwnerID:           inv.OwnerID,
+		PaymentOrderID:    inv.PaymentOrderID,
+		PeriodStart:       inv.PeriodStart,
+		PeriodEnd:         inv.PeriodEnd,
+		Currency:          inv.Currency,
+		Subtotal:          money.RoundLedger(inv.Subtotal),
+		Total:             money.RoundLedger(inv.Total),
+		BillTo:            *BillingProfileFromService(&inv.BillTo),
+		LineItems:         lines,
+		ReplacesInvoiceID: inv.ReplacesInvoiceID,
+		ReplacesNumber:    inv.ReplacesNumber,
+		VoidReason:        inv.VoidReason,
+		VoidedAt:          inv.VoidedAt,
+		IssuedAt:          inv.IssuedAt,
+	}
+}
+
+func AdminInvoiceFromService(inv *service.Invoice) *AdminInvoice {
+	if inv == nil {
+		return nil
+	}
+	return &AdminInvoice{
+		Invoice:  *InvoiceFromService(inv),
+		IssuedBy: inv.IssuedBy,
+		VoidedBy: inv.VoidedBy,
+	}
+}
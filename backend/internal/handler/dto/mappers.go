@@ -5,9 +5,13 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 )
 
+// 金额字段统一按 money 包的舍入策略输出：余额、配额、用量与 actual_cost 取记账精度，
+// usage 明细取明细精度，JSON 中的数值与数据库 NUMERIC 列逐位一致，不带浮点尾差。
+
 func UserFromServiceShallow(u *service.User) *User {
 	if u == nil {
 		return nil
@@ -17,8 +21,8 @@ func UserFromServiceShallow(u *service.User) *User {
 		Email:                      u.Email,
 		Username:                   u.Username,
 		Role:                       u.Role,
-		Balance:                    u.Balance,
-		FrozenBalance:              u.FrozenBalance,
+		Balance:                    money.RoundLedger(u.Balance),
+		FrozenBalance:              money.RoundLedger(u.FrozenBalance),
 		Concurrency:                u.Concurrency,
 		Status:                     u.Status,
 		AllowedGroups:              u.AllowedGroups,
@@ -27,9 +31,9 @@ func UserFromServiceShallow(u *service.User) *User {
 		UpdatedAt:                  u.UpdatedAt,
 		BalanceNotifyEnabled:       u.BalanceNotifyEnabled,
 		BalanceNotifyThresholdType: u.BalanceNotifyThresholdType,
-		BalanceNotifyThreshold:     u.BalanceNotifyThreshold,
+		BalanceNotifyThreshold:     money.RoundLedgerPtr(u.BalanceNotifyThreshold),
 		BalanceNotifyExtraEmails:   NotifyEmailEntriesFromService(u.BalanceNotifyExtraEmails),
-		TotalRecharged:             u.TotalRecharged,
+		TotalRecharged:             money.RoundLedger(u.TotalRecharged),
 		RPMLimit:                   u.RPMLimit,
 		DeletedAt:                  u.DeletedAt,
 	}
@@ -80,33 +84,38 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		return nil
 	}
 	out := &APIKey{
-		ID:                 k.ID,
-		UserID:             k.UserID,
-		Key:                k.Key,
-		Name:               k.Name,
-		GroupID:            k.GroupID,
-		Status:             k.Status,
-		IPWhitelist:        k.IPWhitelist,
-		IPBlacklist:        k.IPBlacklist,
-		LastUsedAt:         k.LastUsedAt,
-		LastUsedIP:         k.LastUsedIP,
-		Quota:              k.Quota,
-		QuotaUsed:          k.QuotaUsed,
-		ExpiresAt:          k.ExpiresAt,
-		CreatedAt:          k.CreatedAt,
-		UpdatedAt:          k.UpdatedAt,
-		CurrentConcurrency: k.CurrentConcurrency,
-		RateLimit5h:        k.RateLimit5h,
-		RateLimit1d:        k.RateLimit1d,
-		RateLimit7d:        k.RateLimit7d,
-		Usage5h:            k.EffectiveUsage5h(),
-		Usage1d:            k.EffectiveUsage1d(),
-		Usage7d:            k.EffectiveUsage7d(),
-		Window5hStart:      k.Window5hStart,
-		Window1dStart:      k.Window1dStart,
-		Window7dStart:      k.Window7dStart,
-		User:               UserFromServiceShallow(k.User),
-		Group:              GroupFromServiceShallow(k.Group),
+		ID:                   k.ID,
+		UserID:               k.UserID,
+		Key:                  k.Key,
+		Name:                 k.Name,
+		GroupID:              k.GroupID,
+		Status:               k.Status,
+		IPWhitelist:          k.IPWhitelist,
+		IPBlacklist:          k.IPBlacklist,
+		LastUsedAt:           k.LastUsedAt,
+		LastUsedIP:           k.LastUsedIP,
+		Quota:                k.Quota,
+		QuotaUsed:            money.RoundLedger(k.QuotaUsed),
+		ExpiresAt:            k.ExpiresAt,
+		CreatedAt:            k.CreatedAt,
+		UpdatedAt:            k.UpdatedAt,
+		CurrentConcurrency:   k.CurrentConcurrency,
+		RateLimit5h:          k.RateLimit5h,
+		RateLimit1d:          k.RateLimit1d,
+		RateLimit7d:          k.RateLimit7d,
+		Usage5h:              money.RoundLedger(k.EffectiveUsage5h()),
+		Usage1d:              money.RoundLedger(k.EffectiveUsage1d()),
+		Usage7d:              money.RoundLedger(k.EffectiveUsage7d()),
+		Window5hStart:        k.Window5hStart,
+		Window1dStart:        k.Window1dStart,
+		Window7dStart:        k.Window7dStart,
+		ResponseCacheEnabled: k.ResponseCacheEnabled,
+		OrganizationID:       k.OrganizationID,
+		ModelAllowlist:       k.ModelAllowlist,
+		ModelDenylist:        k.ModelDenylist,
+		ModelAliases:         k.ModelAliases,
+		User:                 UserFromServiceShallow(k.User),
+		Group:                GroupFromServiceShallow(k.Group),
 	}
 	if k.Window5hStart != nil && !service.IsWindowExpired(k.Window5hStart, service.RateLimitWindow5h) {
 		t := k.Window5hStart.Add(service.RateLimitWindow5h)
@@ -192,6 +201,13 @@ func groupFromServiceBase(g *service.Group) Group {
 		ImageRateMultiplier:             g.ImageRateMultiplier,
 		BatchImageDiscountMultiplier:    g.BatchImageDiscountMultiplier,
 		BatchImageHoldMultiplier:        g.BatchImageHoldMultiplier,
+		AllowBatchAPI:                   g.AllowBatchAPI,
+		BatchAPIDiscountMultiplier:      g.BatchAPIDiscountMultiplier,
+		BatchAPIHoldMultiplier:          g.BatchAPIHoldMultiplier,
+		ResponseCacheEnabled:            g.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         g.ResponseCacheTTLSeconds,
+		ResponseCachePriceMultiplier:    g.ResponseCachePriceMultiplier,
+		SchedulingStrategy:              g.SchedulingStrategy,
 		VideoRateIndependent:            g.VideoRateIndependent,
 		VideoRateMultiplier:             g.VideoRateMultiplier,
 		PeakRateEnabled:                 g.PeakRateEnabled,
@@ -206,6 +222,7 @@ func groupFromServiceBase(g *service.Group) Group {
 		VideoPrice1080P:                 g.VideoPrice1080P,
 		VideoModelPrices:                g.VideoModelPrices,
 		WebSearchPricePerCall:           g.WebSearchPricePerCall,
+		WebFetchPricePerCall:            g.WebFetchPricePerCall,
 		SearchPricePer1k:                g.SearchPricePer1k,
 		AudioRealtimePricePerMin:        g.AudioRealtimePricePerMin,
 		AudioTtsPricePerMillionChars:    g.AudioTTSPricePerMillionChars,
@@ -653,12 +670,12 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		CacheReadTokens:           l.CacheReadTokens,
 		CacheCreation5mTokens:     l.CacheCreation5mTokens,
 		CacheCreation1hTokens:     l.CacheCreation1hTokens,
-		InputCost:                 l.InputCost,
-		OutputCost:                l.OutputCost,
-		CacheCreationCost:         l.CacheCreationCost,
-		CacheReadCost:             l.CacheReadCost,
-		TotalCost:                 l.TotalCost,
-		ActualCost:                l.ActualCost,
+		InputCost:                 money.RoundLineItem(l.InputCost),
+		OutputCost:                money.RoundLineItem(l.OutputCost),
+		CacheCreationCost:         money.RoundLineItem(l.CacheCreationCost),
+		CacheReadCost:             money.RoundLineItem(l.CacheReadCost),
+		TotalCost:                 money.RoundLineItem(l.TotalCost),
+		ActualCost:                money.RoundLedger(l.ActualCost),
 		RateMultiplier:            l.RateMultiplier,
 		LongContextBillingApplied: l.LongContextBillingApplied,
 		BillingType:               l.BillingType,
@@ -672,9 +689,9 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		ImageInputSize:            l.ImageInputSize,
 		ImageOutputSize:           l.ImageOutputSize,
 		ImageInputTokens:          l.ImageInputTokens,
-		ImageInputCost:            l.ImageInputCost,
+		ImageInputCost:            money.RoundLineItem(l.ImageInputCost),
 		ImageOutputTokens:         l.ImageOutputTokens,
-		ImageOutputCost:           l.ImageOutputCost,
+		ImageOutputCost:           money.RoundLineItem(l.ImageOutputCost),
 		ImageSizeSource:           l.ImageSizeSource,
 		ImageSizeBreakdown:        l.ImageSizeBreakdown,
 		MediaType:                 l.MediaType,
@@ -809,9 +826,9 @@ func userSubscriptionFromServiceBase(sub *service.UserSubscription) UserSubscrip
 		DailyWindowStart:   sub.DailyWindowStart,
 		WeeklyWindowStart:  sub.WeeklyWindowStart,
 		MonthlyWindowStart: sub.MonthlyWindowStart,
-		DailyUsageUSD:      sub.DailyUsageUSD,
-		WeeklyUsageUSD:     sub.WeeklyUsageUSD,
-		MonthlyUsageUSD:    sub.MonthlyUsageUSD,
+		DailyUsageUSD:      money.RoundLedger(sub.DailyUsageUSD),
+		WeeklyUsageUSD:     money.RoundLedger(sub.WeeklyUsageUSD),
+		MonthlyUsageUSD:    money.RoundLedger(sub.MonthlyUsageUSD),
 		CreatedAt:          sub.CreatedAt,
 		UpdatedAt:          sub.UpdatedAt,
 		RevokedAt:          sub.DeletedAt,
@@ -0,0 +1,210 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// Organization 组织（团队）。Role 为当前用户在组织中的角色，管理后台视图为空。
+type Organization struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	OwnerUserID int64     `json:"owner_user_id"`
+	Balance     float64   `json:"balance"`
+	Status      string    `json:"status"`
+	MemberCount int       `json:"member_count"`
+	Role        string    `json:"role,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	UserID               int64     `json:"user_id"`
+	Email                string    `json:"email"`
+	Username             string    `json:"username"`
+	Role                 string    `json:"role"`
+	MonthlySpendLimitUSD float64   `json:"monthly_spend_limit_usd"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type OrganizationInvitation struct {
+	ID         int64      `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  int64      `json:"invited_by"`
+	Status     string     `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedBy *int64     `json:"accepted_by,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type OrganizationWalletTransaction struct {
+	ID           int64     `json:"id"`
+	ActorUserID  *int64    `json:"actor_user_id,omitempty"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	BalanceAfter float64   `json:"balance_after"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type OrganizationMemberUsage struct {
+	UserID               int64   `json:"user_id"`
+	Email                string  `json:"email"`
+	Username             string  `json:"username"`
+	Role                 string  `json:"role"`
+	MonthlySpendLimitUSD float64 `json:"monthly_spend_limit_usd"`
+	Requests             int64   `json:"requests"`
+	InputTokens          int64   `json:"input_tokens"`
+	OutputTokens         int64   `json:"output_tokens"`
+	CacheTokens          int64   `json:"cache_tokens"`
+	TotalCost            float64 `json:"total_cost"`
+	ActualCost           float64 `json:"actual_cost"`
+}
+
+type OrganizationUsageSummary struct {
+	OrganizationID int64                     `json:"organization_id"`
+	StartTime      time.Time                 `json:"start_time"`
+	EndTime        time.Time                 `json:"end_time"`
+	Requests       int64                     `json:"requests"`
+	InputTokens    int64                     `json:"input_tokens"`
+	OutputTokens   int64                     `json:"output_tokens"`
+	CacheTokens    int64                     `json:"cache_tokens"`
+	TotalCost      float64                   `json:"total_cost"`
+	ActualCost     float64                   `json:"actual_cost"`
+	Members        []OrganizationMemberUsage `json:"members"`
+}
+
+type OrganizationAPIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	GroupID    *int64     `json:"group_id,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func OrganizationFromService(o *service.Organization, role string) *Organization {
+	if o == nil {
+		return nil
+	}
+	return &Organization{
+		ID:          o.ID,
+		Name:        o.Name,
+		OwnerUserID: o.OwnerUserID,
+		Balance:     o.Balance,
+		Status:      o.Status,
+		MemberCount: o.MemberCount,
+		Role:        role,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+	}
+}
+
+func OrganizationMemberFromService(m *service.OrganizationMember) *OrganizationMember {
+	if m == nil {
+		return nil
+	}
+	return &OrganizationMember{
+		UserID:               m.UserID,
+		Email:                m.Email,
+		Username:             m.Username,
+		Role:                 m.Role,
+		MonthlySpendLimitUSD: m.MonthlySpendLimitUSD,
+		CreatedAt:            m.CreatedAt,
+		UpdatedAt:            m.UpdatedAt,
+	}
+}
+
+func OrganizationMembersFromService(members []service.OrganizationMember) []OrganizationMember {
+	out := make([]OrganizationMember, 0, len(members))
+	for i := range members {
+		out = append(out, *OrganizationMemberFromService(&members[i]))
+	}
+	return out
+}
+
+func OrganizationInvitationFromService(inv *service.OrganizationInvitation) *OrganizationInvitation {
+	if inv == nil {
+		return nil
+	}
+	return &OrganizationInvitation{
+		ID:         inv.ID,
+		Email:      inv.Email,
+		Role:       inv.Role,
+		InvitedBy:  inv.InvitedBy,
+		Status:     inv.Status,
+		ExpiresAt:  inv.ExpiresAt,
+		AcceptedBy: inv.AcceptedBy,
+		AcceptedAt: inv.AcceptedAt,
+		CreatedAt:  inv.CreatedAt,
+	}
+}
+
+func OrganizationWalletTransactionFromService(t *service.OrganizationWalletTransaction) *OrganizationWalletTransaction {
+	if t == nil {
+		return nil
+	}
+	return &OrganizationWalletTransaction{
+		ID:           t.ID,
+		ActorUserID:  t.ActorUserID,
+		Type:         t.Type,
+		Amount:       t.Amount,
+		BalanceAfter: t.BalanceAfter,
+		Note:         t.Note,
+		CreatedAt:    t.CreatedAt,
+	}
+}
+
+func OrganizationUsageSummaryFromService(s *service.OrganizationUsageSummary) *OrganizationUsageSummary {
+	if s == nil {
+		return nil
+	}
+	members := make([]OrganizationMemberUsage, 0, len(s.Members))
+	for _, m := range s.Members {
+		members = append(members, OrganizationMemberUsage{
+			UserID:               m.UserID,
+			Email:                m.Email,
+			Username:             m.Username,
+			Role:                 m.Role,
+			MonthlySpendLimitUSD: m.MonthlySpendLimitUSD,
+			Requests:             m.Requests,
+			InputTokens:          m.InputTokens,
+			OutputTokens:         m.OutputTokens,
+			CacheTokens:          m.CacheTokens,
+			TotalCost:            m.TotalCost,
+			ActualCost:           m.ActualCost,
+		})
+	}
+	return &OrganizationUsageSummary{
+		OrganizationID: s.OrganizationID,
+		StartTime:      s.StartTime,
+		EndTime:        s.EndTime,
+		Requests:       s.Requests,
+		InputTokens:    s.InputTokens,
+		OutputTokens:   s.OutputTokens,
+		CacheTokens:    s.CacheTokens,
+		TotalCost:      s.TotalCost,
+		ActualCost:     s.ActualCost,
+		Members:        members,
+	}
+}
+
+func OrganizationAPIKeyFromService(k *service.OrganizationAPIKey) *OrganizationAPIKey {
+	if k == nil {
+		return nil
+	}
+	return &OrganizationAPIKey{
+		ID:         k.ID,
+		UserID:     k.UserID,
+		Name:       k.Name,
+		Status:     k.Status,
+		GroupID:    k.GroupID,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -83,6 +83,15 @@ type APIKey struct {
 	Reset1dAt     *time.Time `json:"reset_1d_at,omitempty"`
 	Reset7dAt     *time.Time `json:"reset_7d_at,omitempty"`
 
+	ResponseCacheEnabled bool `json:"response_cache_enabled"`
+
+	OrganizationID *int64 `json:"organization_id,omitempty"`
+
+	// Model policy: allowlist/denylist patterns (trailing * wildcard) and alias -> target map
+	ModelAllowlist []string          `json:"model_allowlist"`
+	ModelDenylist  []string          `json:"model_denylist"`
+	ModelAliases   map[string]string `json:"model_aliases"`
+
 	User  *User  `json:"user,omitempty"`
 	Group *Group `json:"group,omitempty"`
 }
@@ -109,6 +118,13 @@ type Group struct {
 	ImageRateMultiplier          float64 `json:"image_rate_multiplier"`
 	BatchImageDiscountMultiplier float64 `json:"batch_image_discount_multiplier"`
 	BatchImageHoldMultiplier     float64 `json:"batch_image_hold_multiplier"`
+	AllowBatchAPI                bool    `json:"allow_batch_api"`
+	BatchAPIDiscountMultiplier   float64 `json:"batch_api_discount_multiplier"`
+	BatchAPIHoldMultiplier       float64 `json:"batch_api_hold_multiplier"`
+	ResponseCacheEnabled         bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds      int     `json:"response_cache_ttl_seconds"`
+	ResponseCachePriceMultiplier float64 `json:"response_cache_price_multiplier"`
+	SchedulingStrategy           string  `json:"scheduling_strategy"`
 	VideoRateIndependent         bool    `json:"video_rate_independent"`
 	VideoRateMultiplier          float64 `json:"video_rate_multiplier"`
 	// 高峰时段倍率配置
@@ -126,6 +142,7 @@ type Group struct {
 	VideoModelPrices map[string]map[string]float64 `json:"video_model_prices,omitempty"`
 	// Codex alpha/search 网页搜索单次价格（USD/次）；null 表示使用默认价 0.01
 	WebSearchPricePerCall        *float64 `json:"web_search_price_per_call"`
+	WebFetchPricePerCall         *float64 `json:"web_fetch_price_per_call"`
 	SearchPricePer1k             *float64 `json:"search_price_per_1k"`
 	AudioRealtimePricePerMin     *float64 `json:"audio_realtime_price_per_min"`
 	AudioTtsPricePerMillionChars *float64 `json:"audio_tts_price_per_million_chars"`
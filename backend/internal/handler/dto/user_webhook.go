@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// UserWebhookEndpoint 用户 Webhook endpoint。签名 secret 不在此返回，
+// 仅在创建 / 轮换接口的响应中以 Secret 字段出现一次。
+type UserWebhookEndpoint struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserWebhookEndpointWithSecret 创建 / 轮换 secret 的响应。
+type UserWebhookEndpointWithSecret struct {
+	UserWebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// UserWebhookDelivery 投递日志条目。
+type UserWebhookDelivery struct {
+	ID                 int64           `json:"id"`
+	EndpointID         int64           `json:"endpoint_id"`
+	EventID            string          `json:"event_id"`
+	EventType          string          `json:"event_type"`
+	Payload            json.RawMessage `json:"payload,omitempty"`
+	Status             string          `json:"status"`
+	Attempts           int             `json:"attempts"`
+	NextAttemptAt      *time.Time      `json:"next_attempt_at,omitempty"`
+	LastResponseStatus *int            `json:"last_response_status,omitempty"`
+	LastResponseBody   string          `json:"last_response_body,omitempty"`
+	LastError          string          `json:"last_error,omitempty"`
+	LastDurationMs     *int            `json:"last_duration_ms,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	DeliveredAt        *time.Time      `json:"delivered_at,omitempty"`
+}
+
+func UserWebhookEndpointFromService(e *service.UserWebhookEndpoint) *UserWebhookEndpoint {
+	if e == nil {
+		return nil
+	}
+	events := e.Events
+	if events == nil {
+		events = []string{}
+	}
+	return &UserWebhookEndpoint{
+		ID:        e.ID,
+		Name:      e.Name,
+		URL:       e.URL,
+		Events:    events,
+		Enabled:   e.Enabled,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// UserWebhookDeliveryFromService 转换投递记录；列表视图不带 payload 以控制响应体积。
+func UserWebhookDeliveryFromService(d *service.UserWebhookDelivery, withPayload bool) *UserWebhookDelivery {
+	if d == nil {
+		return nil
+	}
+	out := &UserWebhookDelivery{
+		ID:                 d.ID,
+		EndpointID:         d.EndpointID,
+		EventID:            d.EventID,
+		EventType:          d.EventType,
+		Status:             d.Status,
+		Attempts:           d.Attempts,
+		LastResponseStatus: d.LastResponseStatus,
+		LastResponseBody:   d.LastResponseBody,
+		LastError:          d.LastError,
+		LastDurationMs:     d.LastDurationMs,
+		CreatedAt:          d.CreatedAt,
+		UpdatedAt:          d.UpdatedAt,
+		DeliveredAt:        d.DeliveredAt,
+	}
+	if d.Status == service.UserWebhookDeliveryStatusPending {
+		next := d.NextAttemptAt
+		out.NextAttemptAt = &next
+	}
+	if withPayload {
+		out.Payload = d.Payload
+	}
+	return out
+}
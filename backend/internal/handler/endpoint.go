@@ -18,6 +18,8 @@ const (
 	EndpointMessages             = "/v1/messages"
 	EndpointChatCompletions      = "/v1/chat/completions"
 	EndpointEmbeddings           = "/v1/embeddings"
+	EndpointRerank               = "/v1/rerank"
+	EndpointModerations          = "/v1/moderations"
 	EndpointAlphaSearch          = "/v1/alpha/search"
 	EndpointResponses            = "/v1/responses"
 	EndpointResponsesCompact     = "/v1/responses/compact"
@@ -85,6 +87,10 @@ func NormalizeInboundEndpoint(path string) string {
 		return EndpointResponsesInputTokens
 	case strings.Contains(path, EndpointEmbeddings):
 		return EndpointEmbeddings
+	case strings.Contains(path, EndpointRerank) || isBareOrSubpathOf(strings.TrimRight(path, "/"), "/rerank"):
+		return EndpointRerank
+	case strings.Contains(path, EndpointModerations) || isBareOrSubpathOf(strings.TrimRight(path, "/"), "/moderations"):
+		return EndpointModerations
 	case strings.Contains(path, EndpointAlphaSearch) || isBareOrSubpathOf(strings.TrimRight(path, "/"), "/alpha/search") || isBareOrSubpathOf(strings.TrimRight(path, "/"), "/backend-api/codex/alpha/search"):
 		return EndpointAlphaSearch
 	case strings.Contains(path, EndpointChatCompletions):
@@ -184,8 +190,8 @@ func isBareOrSubpathOf(path, root string) bool {
 // Platform-specific rules:
 //   - OpenAI and Grok text compatibility routes forward to /v1/responses
 //     (with optional subpath such as /v1/responses/compact preserved from
-//     the raw URL); native endpoints such as embeddings and alpha search
-//     retain their paths. Grok raw Chat requests override this through the
+//     the raw URL); native endpoints such as embeddings, rerank, moderations
+//     and alpha search retain their paths. Grok raw Chat requests override this through the
 //     forwarding result consumed by resolveOpenAIUpstreamEndpoint.
 //   - Anthropic  → /v1/messages
 //   - Gemini     → /v1beta/models
@@ -197,7 +203,7 @@ func DeriveUpstreamEndpoint(inbound, rawRequestPath, platform string) string {
 
 	switch platform {
 	case service.PlatformOpenAI, service.PlatformGrok:
-		if inbound == EndpointEmbeddings || inbound == EndpointAlphaSearch || inbound == EndpointResponsesInputTokens || inbound == EndpointImagesGenerations || inbound == EndpointImagesEdits || inbound == EndpointVideosGenerations || inbound == EndpointVideosEdits || inbound == EndpointVideosExtensions || inbound == EndpointVideos {
+		if inbound == EndpointEmbeddings || inbound == EndpointRerank || inbound == EndpointModerations || inbound == EndpointAlphaSearch || inbound == EndpointResponsesInputTokens || inbound == EndpointImagesGenerations || inbound == EndpointImagesEdits || inbound == EndpointVideosGenerations || inbound == EndpointVideosEdits || inbound == EndpointVideosExtensions || inbound == EndpointVideos {
 			return inbound
 		}
 		// OpenAI forwards everything to the Responses API.
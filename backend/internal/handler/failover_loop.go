@@ -9,6 +9,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -200,11 +201,14 @@ func (s *FailoverState) HandleFailoverError(
 ) FailoverAction {
 	// 客户端已断开：failover 只会用已取消的 context 重新选号并必然失败，
 	// 不应再被当成账号耗尽处理（误报 502）。
+	attempt := gatewayAttemptTraceFromContext(ctx)
 	if ctx != nil && ctx.Err() != nil {
+		attempt.finishFailover(attemptOutcomeCanceled, failoverErr, ctx.Err())
 		return FailoverCanceled
 	}
 	s.LastFailoverErr = failoverErr
 	if failoverErr == nil || !failoverErr.ShouldRetryNextAccount() {
+		attempt.finishFailover(attemptOutcomeExhausted, failoverErr, nil)
 		return FailoverExhausted
 	}
 
@@ -227,6 +231,7 @@ func (s *FailoverState) HandleFailoverError(
 			zap.Int("same_account_retry_max", retryLimit),
 			zap.Duration("retry_delay", retryDelay),
 		)
+		attempt.finishFailover(attemptOutcomeSameAccountRetry, failoverErr, nil)
 		if !sleepWithContext(ctx, retryDelay) {
 			return FailoverCanceled
 		}
@@ -243,6 +248,7 @@ func (s *FailoverState) HandleFailoverError(
 
 	// 检查是否耗尽
 	if s.SwitchCount >= s.MaxSwitches {
+		attempt.finishFailover(attemptOutcomeExhausted, failoverErr, nil)
 		return FailoverExhausted
 	}
 
@@ -254,6 +260,7 @@ func (s *FailoverState) HandleFailoverError(
 		zap.Int("switch_count", s.SwitchCount),
 		zap.Int("max_switches", s.MaxSwitches),
 	)
+	attempt.finishFailover(attemptOutcomeSwitchAccount, failoverErr, nil)
 
 	// Antigravity 平台换号线性递增延时
 	if platform == service.PlatformAntigravity {
@@ -295,6 +302,9 @@ func (s *FailoverState) HandleSelectionExhausted(ctx context.Context) FailoverAc
 			return FailoverExhausted
 		}
 
+		gatewayAttemptTraceFromContext(ctx).addEvent("gateway.failover.single_account_backoff",
+			attribute.Int("switch_count", s.SwitchCount),
+		)
 		logger.FromContext(ctx).Warn("gateway.failover_single_account_backoff",
 			zap.Duration("backoff_delay", singleAccountBackoffDelay),
 			zap.Int("switch_count", s.SwitchCount),
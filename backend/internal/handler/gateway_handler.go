@@ -478,6 +478,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if accountReleaseFunc != nil {
 				accountReleaseFunc()
 			}
+			h.reportAccountScheduleResult(account, result, err)
 			if err != nil {
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
@@ -785,7 +786,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				baseRPM := account.GetBaseRPM()
 				release, qErr := h.userMsgQueueHelper.AcquireWithWait(
 					c, account.ID, baseRPM, reqStream, &streamStarted,
-					h.cfg.Gateway.UserMessageQueue.WaitTimeout(),
+					h.cfg.Live().Gateway.UserMessageQueue.WaitTimeout(),
 					reqLog,
 				)
 				if qErr != nil {
@@ -803,7 +804,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				baseRPM := account.GetBaseRPM()
 				if tErr := h.userMsgQueueHelper.ThrottleWithPing(
 					c, account.ID, baseRPM, reqStream, &streamStarted,
-					h.cfg.Gateway.UserMessageQueue.WaitTimeout(),
+					h.cfg.Live().Gateway.UserMessageQueue.WaitTimeout(),
 					reqLog,
 				); tErr != nil {
 					reqLog.Warn("gateway.umq_throttle_failed",
@@ -870,6 +871,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if accountReleaseFunc != nil {
 				accountReleaseFunc()
 			}
+			h.reportAccountScheduleResult(account, result, err)
 
 			// 提交 usage 记录。成功路径与"流中断但 Forward 已观测到 usage 的部分结果"
 			// 错误路径共用：后者若不入账，上游已计量的请求会完全漏记漏计费（#5148）。
@@ -1070,6 +1072,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 // GET /v1/models
 // Returns models based on account configurations (model_mapping whitelist)
 // Falls back to default models if no whitelist is configured
+// The list is narrowed by the API key's model allowlist/denylist and extended with its aliases
 func (h *GatewayHandler) Models(c *gin.Context) {
 	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
 
@@ -1088,14 +1091,14 @@ func (h *GatewayHandler) Models(c *gin.Context) {
 		availableModels := h.compositeAvailableModels(c.Request.Context(), groupID)
 		if apiKey != nil && apiKey.Group != nil && apiKey.Group.CustomModelsListEnabled() {
 			availableModels = filterModelsByCustomList(availableModels, defaultModelIDsForPlatform(service.PlatformComposite), apiKey.Group.ModelsListConfig.Models)
-			writeCustomModelsList(c, service.PlatformComposite, availableModels)
+			writeCustomModelsList(c, service.PlatformComposite, apiKey.FilterModelIDs(availableModels))
 			return
 		}
 		if len(availableModels) > 0 {
-			writeModelsList(c, service.PlatformComposite, availableModels)
+			writeModelsList(c, service.PlatformComposite, apiKey.FilterModelIDs(availableModels))
 			return
 		}
-		writeModelsList(c, service.PlatformComposite, defaultModelIDsForPlatform(service.PlatformComposite))
+		writeModelsList(c, service.PlatformComposite, apiKey.FilterModelIDs(defaultModelIDsForPlatform(service.PlatformComposite)))
 		return
 	}
 
@@ -1104,12 +1107,18 @@ func (h *GatewayHandler) Models(c *gin.Context) {
 	if apiKey != nil && apiKey.Group != nil && apiKey.Group.CustomModelsListEnabled() {
 		fallbackModels := defaultModelIDsForPlatform(platform)
 		availableModels = filterModelsByCustomList(customModelsListSource(platform, availableModels, fallbackModels), fallbackModels, apiKey.Group.ModelsListConfig.Models)
-		writeCustomModelsList(c, platform, availableModels)
+		writeCustomModelsList(c, platform, apiKey.FilterModelIDs(availableModels))
 		return
 	}
 
 	if len(availableModels) > 0 {
-		writeModelsList(c, platform, availableModels)
+		writeModelsList(c, platform, apiKey.FilterModelIDs(availableModels))
+		return
+	}
+
+	// Keys with a model policy only see the allowed defaults plus their aliases.
+	if apiKey.HasModelPolicy() {
+		writeCustomModelsList(c, platform, apiKey.FilterModelIDs(defaultModelIDsForPlatform(platform)))
 		return
 	}
 
@@ -1866,6 +1875,16 @@ func (h *GatewayHandler) handleStreamingAwareError(c *gin.Context, status int, e
 	h.errorResponse(c, status, errType, message)
 }
 
+// reportAccountScheduleResult 向分组调度策略上报一次 Forward 结果；客户端主动断开不计为账号失败。
+func (h *GatewayHandler) reportAccountScheduleResult(account *service.Account, result *service.ForwardResult, err error) {
+	switch {
+	case err == nil && result != nil:
+		h.gatewayService.ReportAccountScheduleResult(account, true, result.FirstTokenMs)
+	case err != nil && !errors.Is(err, context.Canceled):
+		h.gatewayService.ReportAccountScheduleResult(account, false, nil)
+	}
+}
+
 // ensureForwardErrorResponse 在 Forward 返回错误但尚未写响应时补写统一错误响应。
 // Writer 已被写过时（ping 已 flush）走 streamStarted 分支，
 // 让 handleStreamingAwareError 通过 SSE 发协议合规的终止事件，
@@ -2348,7 +2367,8 @@ func billingErrorDetails(err error) (status int, code, message string, retryAfte
 	}
 	if errors.Is(err, service.ErrUserPlatformDailyQuotaExhausted) ||
 		errors.Is(err, service.ErrUserPlatformWeeklyQuotaExhausted) ||
-		errors.Is(err, service.ErrUserPlatformMonthlyQuotaExhausted) {
+		errors.Is(err, service.ErrUserPlatformMonthlyQuotaExhausted) ||
+		errors.Is(err, service.ErrBudgetExceeded) {
 		// 与 RPM 超限一致映射 429 + Retry-After，让 SDK 自动退避（而非 403 直接失败）。
 		// 错误码用 rate_limit_exceeded 与 OpenAI 兼容客户端一致；细分类型由 ErrCode + window_resets_at metadata 区分。
 		msg := pkgerrors.Message(err)
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// observeGatewayMetrics exports one finished gateway request to the Prometheus
+// registry. It reads the same request-scoped ops keys as the error logger, so
+// it must run after c.Next() and before the capture writer is released.
+func observeGatewayMetrics(c *gin.Context, startedAt time.Time) {
+	gw := metrics.Default()
+	if gw == nil || c == nil || c.Request == nil {
+		return
+	}
+
+	apiKey := getOpsAPIKey(c)
+	fallbackPlatform := ""
+	if c.Request.URL != nil {
+		fallbackPlatform = guessPlatformFromPath(c.Request.URL.Path)
+	}
+	platform := resolveOpsPlatform(c.Request.Context(), apiKey, fallbackPlatform)
+
+	sample := metrics.GatewayRequest{
+		Platform:   platform,
+		StatusCode: c.Writer.Status(),
+		Duration:   time.Since(startedAt),
+	}
+	if apiKey != nil && apiKey.GroupID != nil {
+		sample.GroupID = *apiKey.GroupID
+	}
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		if id, ok := v.(int64); ok {
+			sample.AccountID = id
+		}
+	}
+	if v, ok := c.Get(opsModelKey); ok {
+		if model, ok := v.(string); ok {
+			sample.Model = model
+		}
+	}
+	if v, ok := c.Get(opsStreamKey); ok {
+		if stream, ok := v.(bool); ok {
+			sample.Stream = stream
+		}
+	}
+	if ttft := getContextLatencyMs(c, service.OpsTimeToFirstTokenMsKey); ttft != nil && *ttft > 0 {
+		sample.FirstToken = time.Duration(*ttft) * time.Millisecond
+	}
+	gw.ObserveRequest(sample)
+
+	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
+		if events, ok := v.([]*service.OpsUpstreamErrorEvent); ok {
+			for _, ev := range events {
+				if ev == nil {
+					continue
+				}
+				eventPlatform := ev.Platform
+				if eventPlatform == "" {
+					eventPlatform = platform
+				}
+				gw.ObserveUpstreamError(metrics.UpstreamError{
+					Platform:   eventPlatform,
+					AccountID:  ev.AccountID,
+					StatusCode: ev.UpstreamStatusCode,
+					Kind:       ev.Kind,
+				})
+			}
+		}
+	}
+}
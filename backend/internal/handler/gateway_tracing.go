@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Failover decisions recorded on attempt spans.
+const (
+	attemptOutcomeSameAccountRetry = "same_account_retry"
+	attemptOutcomeSwitchAccount    = "switch_account"
+	attemptOutcomeExhausted        = "exhausted"
+	attemptOutcomeCanceled         = "canceled"
+)
+
+type gatewayAttemptTraceKey struct{}
+
+// gatewayAttemptTrace turns each pass through the failover loop into a
+// "gateway.attempt" span under the request's server span. It lives in the
+// request context rather than the gin context because FailoverState only
+// receives a context.Context, and some handlers snapshot the request context
+// before the first account is selected.
+type gatewayAttemptTrace struct {
+	root trace.Span
+
+	mu      sync.Mutex
+	current trace.Span
+	count   int
+}
+
+// withGatewayAttemptTrace installs the attempt tracker when the request is
+// being traced. It must run before handlers capture c.Request.Context().
+func withGatewayAttemptTrace(c *gin.Context) {
+	if c == nil || c.Request == nil {
+		return
+	}
+	root := trace.SpanFromContext(c.Request.Context())
+	if !root.IsRecording() {
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), gatewayAttemptTraceKey{}, &gatewayAttemptTrace{root: root})
+	c.Request = c.Request.WithContext(ctx)
+}
+
+func gatewayAttemptTraceFromContext(ctx context.Context) *gatewayAttemptTrace {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(gatewayAttemptTraceKey{}).(*gatewayAttemptTrace)
+	return t
+}
+
+// begin closes any attempt still open and starts the next one. The returned
+// context carries the attempt span so upstream dependency spans nest under it.
+func (t *gatewayAttemptTrace) begin(ctx context.Context, accountID int64, platform string) context.Context {
+	if t == nil {
+		return ctx
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != nil {
+		t.current.End()
+	}
+	t.count++
+	attrs := []attribute.KeyValue{
+		attribute.Int("gateway.attempt", t.count),
+		attribute.Int64("account.id", accountID),
+	}
+	if platform != "" {
+		attrs = append(attrs, attribute.String("account.platform", platform))
+	}
+	ctx, t.current = tracing.Start(trace.ContextWithSpan(ctx, t.root), "gateway.attempt", trace.WithAttributes(attrs...))
+	return ctx
+}
+
+// finishFailover ends the current attempt with the failover decision taken
+// for it.
+func (t *gatewayAttemptTrace) finishFailover(outcome string, failoverErr *service.UpstreamFailoverError, cause error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := t.current
+	if span == nil {
+		return
+	}
+	t.current = nil
+	span.SetAttributes(attribute.String("gateway.failover.action", outcome))
+	if failoverErr != nil {
+		span.SetAttributes(attribute.Int("upstream.status_code", failoverErr.StatusCode))
+		if failoverErr.Reason != "" {
+			span.SetAttributes(attribute.String("gateway.failure.reason", string(failoverErr.Reason)))
+		}
+		span.SetStatus(codes.Error, "upstream "+strconv.Itoa(failoverErr.StatusCode))
+	}
+	if cause != nil {
+		span.RecordError(cause)
+		span.SetStatus(codes.Error, cause.Error())
+	}
+	span.End()
+}
+
+// finishRequest ends the last attempt once the handler chain has returned.
+func (t *gatewayAttemptTrace) finishRequest(status int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := t.current
+	if span == nil {
+		return
+	}
+	t.current = nil
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, strconv.Itoa(status))
+	}
+	span.End()
+}
+
+// addEvent annotates the request span, e.g. with selection back-off decisions
+// that happen between attempts.
+func (t *gatewayAttemptTrace) addEvent(name string, attrs ...attribute.KeyValue) {
+	if t == nil {
+		return
+	}
+	t.root.AddEvent(name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key string) any {
+	for _, a := range span.Attributes() {
+		if string(a.Key) == key {
+			return a.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func TestGatewayAttemptTrace_FailoverAttemptsBecomeSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	ctx, root := provider.Tracer(tracing.ScopeName).Start(c.Request.Context(), "POST /v1/messages", trace.WithSpanKind(trace.SpanKindServer))
+	c.Request = c.Request.WithContext(ctx)
+	withGatewayAttemptTrace(c)
+
+	fs := NewFailoverState(3, false)
+	setOpsSelectedAccount(c, 11, service.PlatformAnthropic)
+	action := fs.HandleFailoverError(c.Request.Context(), &mockTempUnscheduler{}, 11, service.PlatformAnthropic, 0, &service.UpstreamFailoverError{StatusCode: 529})
+	require.Equal(t, FailoverContinue, action)
+
+	setOpsSelectedAccount(c, 12, service.PlatformAnthropic)
+	gatewayAttemptTraceFromContext(c.Request.Context()).finishRequest(http.StatusOK)
+	root.End()
+
+	var attempts []sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "gateway.attempt" {
+			attempts = append(attempts, s)
+		}
+	}
+	require.Len(t, attempts, 2)
+	for _, a := range attempts {
+		require.Equal(t, root.SpanContext().SpanID(), a.Parent().SpanID(), "attempts are siblings under the request span")
+		require.Equal(t, root.SpanContext().TraceID(), a.SpanContext().TraceID())
+	}
+
+	first, second := attempts[0], attempts[1]
+	if spanAttr(first, "gateway.attempt") != int64(1) {
+		first, second = second, first
+	}
+	require.Equal(t, int64(11), spanAttr(first, "account.id"))
+	require.Equal(t, attemptOutcomeSwitchAccount, spanAttr(first, "gateway.failover.action"))
+	require.Equal(t, int64(529), spanAttr(first, "upstream.status_code"))
+	require.Equal(t, codes.Error, first.Status().Code)
+
+	require.Equal(t, int64(12), spanAttr(second, "account.id"))
+	require.Equal(t, int64(http.StatusOK), spanAttr(second, "http.response.status_code"))
+	require.Equal(t, codes.Unset, second.Status().Code)
+}
+
+func TestGatewayAttemptTrace_NoopWithoutActiveSpan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+
+	withGatewayAttemptTrace(c)
+	require.Nil(t, gatewayAttemptTraceFromContext(c.Request.Context()))
+
+	setOpsSelectedAccount(c, 11, service.PlatformAnthropic)
+	fs := NewFailoverState(1, false)
+	action := fs.HandleFailoverError(c.Request.Context(), &mockTempUnscheduler{}, 11, service.PlatformAnthropic, 0, &service.UpstreamFailoverError{StatusCode: 500})
+	require.Equal(t, FailoverContinue, action)
+}
@@ -45,10 +45,15 @@ func (h *OpenAIGatewayHandler) GrokRealtime(c *gin.Context) {
 	}
 
 	reqLog := requestLogger(c, "handler.openai_gateway.grok_realtime")
-	model := c.Query("model")
-	if strings.TrimSpace(model) == "" {
+	model := strings.TrimSpace(c.Query("model"))
+	if model == "" {
 		model = "grok-voice-latest"
 	}
+	model, policyDenied := resolveAPIKeyModelPolicy(c, apiKey, model)
+	if policyDenied != "" {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", policyDenied)
+		return
+	}
 	// Keep the HTTP response uncommitted while selecting and probing an account.
 	// Realtime is not an HTTP streaming response; using reqStream=true here would
 	// let the wait queue flush an SSE ping before the WebSocket handshake succeeds.
@@ -42,6 +42,15 @@ type AdminHandlers struct {
 	Affiliate              *admin.AffiliateHandler
 	Compliance             *admin.ComplianceHandler
 	AuditLog               *admin.AuditLogHandler
+	Organization           *admin.OrganizationHandler
+	Budget                 *admin.BudgetHandler
+	SAML                   *admin.SAMLHandler
+	BalanceLedger          *admin.BalanceLedgerHandler
+	Invoice                *admin.InvoiceHandler
+	UsageExport            *admin.UsageExportHandler
+	CredentialEncryption   *admin.CredentialEncryptionHandler
+	RBAC                   *admin.RBACHandler
+	AdminAPIToken          *admin.AdminAPITokenHandler
 }
 
 // Handlers contains all HTTP handlers
@@ -67,6 +76,13 @@ type Handlers struct {
 	ModelPlaza       *ModelPlazaHandler
 	AsyncImage       *AsyncImageHandler
 	BatchImage       *BatchImageHandler
+	UserWebhook      *UserWebhookHandler
+	Organization     *OrganizationHandler
+	Budget           *BudgetHandler
+	OpenAIBatch      *OpenAIBatchHandler
+	ResponseCache    *ResponseCacheHandler
+	BalanceLedger    *BalanceLedgerHandler
+	Invoice          *InvoiceHandler
 }
 
 // BuildInfo contains build-time information
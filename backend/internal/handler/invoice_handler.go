@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvoiceHandler handles billing profiles, receipts and monthly usage
+// statements for the current user and the organizations they manage.
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+// NewInvoiceHandler creates a new InvoiceHandler
+func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService}
+}
+
+// UpdateBillingProfileRequest represents the billing profile payload (replaces all fields)
+type UpdateBillingProfileRequest struct {
+	CompanyName  string `json:"company_name"`
+	TaxID        string `json:"tax_id"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2"`
+	City         string `json:"city"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+	Email        string `json:"email"`
+	Notes        string `json:"notes"`
+}
+
+func (r *UpdateBillingProfileRequest) toService(owner service.InvoiceOwnerRef) *service.BillingProfile {
+	return &service.BillingProfile{
+		OwnerType:    owner.OwnerType,
+		OwnerID:      owner.OwnerID,
+		CompanyName:  r.CompanyName,
+		TaxID:        r.TaxID,
+		AddressLine1: r.AddressLine1,
+		AddressLine2: r.AddressLine2,
+		City:         r.City,
+		PostalCode:   r.PostalCode,
+		Country:      r.Country,
+		Email:        r.Email,
+		Notes:        r.Notes,
+	}
+}
+
+// IssueReceiptRequest represents the issue receipt payload
+type IssueReceiptRequest struct {
+	OrderID int64 `json:"order_id" binding:"required"`
+}
+
+// IssueStatementRequest represents the issue usage statement payload (month = YYYY-MM)
+type IssueStatementRequest struct {
+	Month string `json:"month" binding:"required"`
+}
+
+// invoiceOwner 解析请求对应的票据归属方：组织路由（/organizations/:id/...）为该组织，其余为当前用户。
+func (h *InvoiceHandler) invoiceOwner(c *gin.Context) (int64, service.InvoiceOwnerRef, bool) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return 0, service.InvoiceOwnerRef{}, false
+	}
+	if c.Param("id") == "" {
+		return subject.UserID, service.InvoiceOwnerRef{OwnerType: service.InvoiceOwnerUser, OwnerID: subject.UserID}, true
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return 0, service.InvoiceOwnerRef{}, false
+	}
+	owner := service.InvoiceOwnerRef{OwnerType: service.InvoiceOwnerOrganization, OwnerID: orgID}
+	if err := h.invoiceService.AuthorizeOwner(c.Request.Context(), subject.UserID, owner); err != nil {
+		response.ErrorFrom(c, err)
+		return 0, service.InvoiceOwnerRef{}, false
+	}
+	return subject.UserID, owner, true
+}
+
+// GetBillingProfile handles getting the billing profile
+// GET /api/v1/user/billing-profile
+// GET /api/v1/organizations/:id/billing-profile
+func (h *InvoiceHandler) GetBillingProfile(c *gin.Context) {
+	_, owner, ok := h.invoiceOwner(c)
+	if !ok {
+		return
+	}
+	profile, err := h.invoiceService.GetBillingProfile(c.Request.Context(), owner)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BillingProfileFromService(profile))
+}
+
+// UpdateBillingProfile handles replacing the billing profile
+// PUT /api/v1/user/billing-profile
+// PUT /api/v1/organizations/:id/billing-profile
+func (h *InvoiceHandler) UpdateBillingProfile(c *gin.Context) {
+	_, owner, ok := h.invoiceOwner(c)
+	if !ok {
+		return
+	}
+	var req UpdateBillingProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	profile, err := h.invoiceService.UpdateBillingProfile(c.Request.Context(), req.toService(owner))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.BillingProfileFromService(profile))
+}
+
+// List handles listing invoices, newest first
+// GET /api/v1/user/invoices?kind=&status=
+// GET /api/v1/organizations/:id/invoices?kind=&status=
+func (h *InvoiceHandler) List(c *gin.Context) {
+	_, owner, ok := h.invoiceOwner(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	filter := service.InvoiceFilter{Kind: c.Query("kind"), Status: c.Query("status")}
+	invoices, result, err := h.invoiceService.ListForOwner(c.Request.Context(), owner, filter, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.Invoice, 0, len(invoices))
+	for i := range invoices {
+		out = append(out, *dto.InvoiceFromService(&invoices[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// IssueStatement handles issuing (or fetching the existing) usage statement for a closed month
+// POST /api/v1/user/invoices/statements
+// POST /api/v1/organizations/:id/invoices/statements
+func (h *InvoiceHandler) IssueStatement(c *gin.Context) {
+	userID, owner, ok := h.invoiceOwner(c)
+	if !ok {
+		return
+	}
+	var req IssueStatementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	inv, err := h.invoiceService.IssueStatementForUser(c.Request.Context(), userID, owner, req.Month)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.InvoiceFromService(inv))
+}
+
+// IssueReceipt handles issuing (or fetching the existing) receipt for one of the user's paid orders
+// POST /api/v1/user/invoices/receipts
+func (h *InvoiceHandler) IssueReceipt(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	var req IssueReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	inv, err := h.invoiceService.IssueReceiptForUser(c.Request.Context(), subject.UserID, req.OrderID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.InvoiceFromService(inv))
+}
+
+// Get handles getting an invoice owned by the user or an organization they manage
+// GET /api/v1/user/invoices/:invoice_id
+func (h *InvoiceHandler) Get(c *gin.Context) {
+	inv, ok := h.loadInvoice(c)
+	if !ok {
+		return
+	}
+	response.Success(c, dto.InvoiceFromService(inv))
+}
+
+// Download handles downloading the rendered invoice document
+// GET /api/v1/user/invoices/:invoice_id/download?format=html|pdf
+func (h *InvoiceHandler) Download(c *gin.Context) {
+	inv, ok := h.loadInvoice(c)
+	if !ok {
+		return
+	}
+	doc, err := h.invoiceService.Render(inv, c.DefaultQuery("format", service.InvoiceFormatHTML))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+doc.Filename)
+	c.Data(200, doc.ContentType, doc.Data)
+}
+
+func (h *InvoiceHandler) loadInvoice(c *gin.Context) (*service.Invoice, bool) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return nil, false
+	}
+	id, ok := parseOrganizationID(c, "invoice_id", "Invalid invoice ID")
+	if !ok {
+		return nil, false
+	}
+	inv, err := h.invoiceService.GetForUser(c.Request.Context(), subject.UserID, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return nil, false
+	}
+	return inv, true
+}
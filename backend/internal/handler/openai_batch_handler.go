@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAIBatchHandler 提供 OpenAI 兼容的 /v1/files 与 /v1/batches 接口。
+type OpenAIBatchHandler struct {
+	service *service.OpenAIBatchService
+	cfg     *config.Config
+}
+
+func NewOpenAIBatchHandler(service *service.OpenAIBatchService, cfg *config.Config) *OpenAIBatchHandler {
+	return &OpenAIBatchHandler{service: service, cfg: cfg}
+}
+
+func (h *OpenAIBatchHandler) UploadFile(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	maxBytes := h.service.MaxFileBytes()
+	// multipart 头部与表单字段额外预留 1MB。
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			openAIBatchError(c, service.ErrOpenAIFileTooLarge)
+			return
+		}
+		openAIBatchError(c, infraerrors.BadRequest("OPENAI_FILE_REQUIRED", "multipart field 'file' is required"))
+		return
+	}
+	if header.Size > maxBytes {
+		openAIBatchError(c, service.ErrOpenAIFileTooLarge)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		openAIBatchError(c, infraerrors.BadRequest("OPENAI_FILE_REQUIRED", "failed to read uploaded file").WithCause(err))
+		return
+	}
+	defer func() { _ = file.Close() }()
+	content, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		openAIBatchError(c, infraerrors.BadRequest("OPENAI_FILE_REQUIRED", "failed to read uploaded file").WithCause(err))
+		return
+	}
+	got, err := h.service.UploadFile(c.Request.Context(), owner, strings.TrimSpace(c.PostForm("purpose")), header.Filename, content)
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) GetFile(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	got, err := h.service.GetFile(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) GetFileContent(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	content, filename, err := h.service.GetFileContent(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	if filename != "" {
+		c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	}
+	c.Data(http.StatusOK, "application/jsonl", content)
+}
+
+func (h *OpenAIBatchHandler) ListFiles(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	got, err := h.service.ListFiles(c.Request.Context(), owner, c.Query("purpose"), c.Query("after"), limit)
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) DeleteFile(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	got, err := h.service.DeleteFile(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) CreateBatch(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	var req service.CreateOpenAIBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		openAIBatchError(c, infraerrors.BadRequest("OPENAI_BATCH_INVALID_REQUEST", "invalid request body").WithCause(err))
+		return
+	}
+	// 与 API Key 鉴权中间件的 IP 限制使用同一解析方式，批次执行时据此重新校验。
+	owner.ClientIP = ip.GetSecurityClientIP(c, h.cfg != nil && h.cfg.TrustForwardedIPForAPIKeyACL())
+	got, err := h.service.CreateBatch(c.Request.Context(), owner, req)
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) GetBatch(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	got, err := h.service.GetBatch(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) ListBatches(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	got, err := h.service.ListBatches(c.Request.Context(), owner, c.Query("after"), limit)
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func (h *OpenAIBatchHandler) CancelBatch(c *gin.Context) {
+	owner, ok := openAIBatchOwnerFromContext(c)
+	if !ok {
+		openAIBatchError(c, infraerrors.New(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required"))
+		return
+	}
+	got, err := h.service.CancelBatch(c.Request.Context(), owner, c.Param("id"))
+	if err != nil {
+		openAIBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, got)
+}
+
+func openAIBatchOwnerFromContext(c *gin.Context) (service.OpenAIBatchOwner, bool) {
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil || apiKey.ID <= 0 || apiKey.UserID <= 0 {
+		return service.OpenAIBatchOwner{}, false
+	}
+	return service.OpenAIBatchOwner{
+		UserID:         apiKey.UserID,
+		APIKeyID:       apiKey.ID,
+		GroupID:        apiKey.GroupID,
+		OrganizationID: apiKey.OrganizationID,
+	}, true
+}
+
+// openAIBatchError 以 OpenAI 错误结构返回，便于官方 SDK 直接解析。
+func openAIBatchError(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	code := infraerrors.Reason(err)
+	message := infraerrors.Message(err)
+	if err == nil || status == 0 || (status == http.StatusInternalServerError && strings.TrimSpace(code) == "") {
+		status = http.StatusInternalServerError
+		code = "INTERNAL_ERROR"
+		message = "internal error"
+	}
+	errType := "invalid_request_error"
+	switch {
+	case status >= http.StatusInternalServerError:
+		errType = "api_error"
+	case status == http.StatusUnauthorized:
+		errType = "authentication_error"
+	case status == http.StatusForbidden:
+		errType = "permission_error"
+	case status == http.StatusNotFound:
+		errType = "not_found_error"
+	}
+	body := gin.H{
+		"type":    errType,
+		"code":    strings.ToLower(code),
+		"message": message,
+	}
+	if metadata := infraerrors.FromError(err); metadata != nil {
+		if line, ok := metadata.Metadata["line"]; ok {
+			body["line"], _ = strconv.Atoi(line)
+		}
+	}
+	c.JSON(status, gin.H{"error": body})
+}
@@ -15,12 +15,43 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 	"go.uber.org/zap"
 )
 
+// openAINativeJSONRoute describes an OpenAI-compatible endpoint whose JSON body
+// is forwarded as-is to API key accounts (embeddings, rerank, moderations).
+type openAINativeJSONRoute struct {
+	// name is used for log components/events ("embeddings" → openai_embeddings.*).
+	name string
+	// auditProtocol is the Prompt Audit protocol; empty skips the audit
+	// (moderations classifies content on purpose and must not be blocked by it).
+	auditProtocol string
+	// defaultModel fills in a missing "model" field; empty means model is required.
+	defaultModel string
+	capability   service.OpenAIEndpointCapability
+	// forward is a method expression such as (*service.OpenAIGatewayService).ForwardEmbeddings.
+	forward func(*service.OpenAIGatewayService, context.Context, *gin.Context, *service.Account, []byte, string) (*service.OpenAIForwardResult, error)
+}
+
+var openAIEmbeddingsRoute = openAINativeJSONRoute{
+	name:          "embeddings",
+	auditProtocol: "openai_embeddings",
+	capability:    service.OpenAIEndpointCapabilityEmbeddings,
+	forward:       (*service.OpenAIGatewayService).ForwardEmbeddings,
+}
+
 // Embeddings handles the OpenAI-compatible Embeddings API.
 // POST /v1/embeddings
 func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
+	h.handleOpenAINativeJSON(c, openAIEmbeddingsRoute)
+}
+
+// handleOpenAINativeJSON runs the shared auth → audit → billing check →
+// account selection/failover → forward → usage record flow for native JSON
+// endpoints.
+func (h *OpenAIGatewayHandler) handleOpenAINativeJSON(c *gin.Context, route openAINativeJSONRoute) {
+	component := "openai_" + route.name
 	streamStarted := false
 	requestStart := time.Now()
 
@@ -37,7 +68,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 	}
 	reqLog := requestLogger(
 		c,
-		"handler.openai_gateway.embeddings",
+		"handler.openai_gateway."+route.name,
 		zap.Int64("user_id", subject.UserID),
 		zap.Int64("api_key_id", apiKey.ID),
 		zap.Any("group_id", apiKey.GroupID),
@@ -66,6 +97,12 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 	}
 
 	modelResult := gjson.GetBytes(body, "model")
+	if !modelResult.Exists() && route.defaultModel != "" {
+		if patched, err := sjson.SetBytes(body, "model", route.defaultModel); err == nil {
+			body = patched
+			modelResult = gjson.GetBytes(body, "model")
+		}
+	}
 	if !modelResult.Exists() || modelResult.Type != gjson.String || strings.TrimSpace(modelResult.String()) == "" {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
 		return
@@ -79,9 +116,11 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 	reqLog = reqLog.With(zap.String("model", reqModel))
 	setOpsRequestContext(c, reqModel, false)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeSync))
-	if decision := h.checkSecurityAudit(c, reqLog, apiKey, subject, "openai_embeddings", reqModel, body); decision != nil && !decision.AllowNextStage {
-		h.openAISecurityAuditError(c, decision)
-		return
+	if route.auditProtocol != "" {
+		if decision := h.checkSecurityAudit(c, reqLog, apiKey, subject, route.auditProtocol, reqModel, body); decision != nil && !decision.AllowNextStage {
+			h.openAISecurityAuditError(c, decision)
+			return
+		}
 	}
 
 	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
@@ -98,7 +137,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 	}
 
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription, service.QuotaPlatform(c.Request.Context(), apiKey)); err != nil {
-		reqLog.Info("openai_embeddings.billing_check_failed", zap.Error(err))
+		reqLog.Info(component+".billing_check_failed", zap.Error(err))
 		status, code, message, retryAfter := billingErrorDetails(err)
 		if retryAfter > 0 {
 			c.Header("Retry-After", strconv.Itoa(retryAfter))
@@ -117,7 +156,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 	}
 	routingStart := time.Now()
 
-	// 分组利润控制：embeddings 文本入口请求级装门并固定 pricingAt。
+	// 分组利润控制：embeddings / rerank / moderations 文本入口请求级装门并固定 pricingAt。
 	embPricingCtx, pricingAt := h.gatewayService.WithOpenAIRequestPricingContext(c.Request.Context(), apiKey.GroupID)
 	c.Request = c.Request.WithContext(embPricingCtx)
 
@@ -130,17 +169,17 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 			reqModel,
 			failedAccountIDs,
 			service.OpenAIUpstreamTransportHTTPSSE,
-			service.OpenAIEndpointCapabilityEmbeddings,
+			route.capability,
 			false,
 			false,
 			true,
 		)
 		if err != nil {
 			if failoverClientGone(c) {
-				reqLog.Info("openai_embeddings.account_select_aborted_client_disconnected", zap.Error(err))
+				reqLog.Info(component+".account_select_aborted_client_disconnected", zap.Error(err))
 				return
 			}
-			reqLog.Warn("openai_embeddings.account_select_failed",
+			reqLog.Warn(component+".account_select_failed",
 				zap.Error(err),
 				zap.Int("excluded_account_count", len(failedAccountIDs)),
 			)
@@ -197,7 +236,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 					accountReleaseFunc()
 				}
 			}()
-			return h.gatewayService.ForwardEmbeddings(c.Request.Context(), c, account, forwardBody, "")
+			return route.forward(h.gatewayService, c.Request.Context(), c, account, forwardBody, "")
 		}()
 
 		forwardDurationMs := time.Since(forwardStart).Milliseconds()
@@ -217,7 +256,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 				}
 				h.gatewayService.ReportOpenAIAccountScheduleResult(account, openAIAccountScheduleModel(c, account, reqModel, false, result), false, nil, err)
 				if failoverClientGone(c) {
-					reqLog.Info("openai_embeddings.failover_aborted_client_disconnected",
+					reqLog.Info(component+".failover_aborted_client_disconnected",
 						zap.Int64("account_id", account.ID),
 						zap.Int("upstream_status", failoverErr.StatusCode),
 					)
@@ -231,7 +270,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 					return
 				}
 				switchCount++
-				reqLog.Warn("openai_embeddings.upstream_failover_switching",
+				reqLog.Warn(component+".upstream_failover_switching",
 					zap.Int64("account_id", account.ID),
 					zap.Int("upstream_status", failoverErr.StatusCode),
 					zap.Int("switch_count", switchCount),
@@ -243,7 +282,7 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 			if c.Writer.Size() == writerSizeBeforeForward {
 				h.errorResponse(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
 			}
-			reqLog.Warn("openai_embeddings.forward_failed",
+			reqLog.Warn(component+".forward_failed",
 				zap.Int64("account_id", account.ID),
 				zap.Error(err),
 			)
@@ -276,16 +315,16 @@ func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
 				PricingAt:          pricingAt,
 			}); err != nil {
 				logger.L().With(
-					zap.String("component", "handler.openai_gateway.embeddings"),
+					zap.String("component", "handler.openai_gateway."+route.name),
 					zap.Int64("user_id", subject.UserID),
 					zap.Int64("api_key_id", apiKey.ID),
 					zap.Any("group_id", apiKey.GroupID),
 					zap.String("model", reqModel),
 					zap.Int64("account_id", account.ID),
-				).Error("openai_embeddings.record_usage_failed", zap.Error(err))
+				).Error(component+".record_usage_failed", zap.Error(err))
 			}
 		})
-		reqLog.Debug("openai_embeddings.request_completed",
+		reqLog.Debug(component+".request_completed",
 			zap.Int64("account_id", account.ID),
 			zap.Int("switch_count", switchCount),
 		)
@@ -25,6 +25,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 	"go.uber.org/zap"
 )
 
@@ -174,6 +175,8 @@ func usageRecordContext(parent context.Context, base context.Context) context.Co
 	if requestID, _ := parent.Value(ctxkey.RequestID).(string); strings.TrimSpace(requestID) != "" {
 		base = context.WithValue(base, ctxkey.RequestID, strings.TrimSpace(requestID))
 	}
+	// 批次执行标记决定折扣计费与费用回写，必须随异步计费任务传递。
+	base = service.WithOpenAIBatchExecution(base, service.OpenAIBatchExecutionFromContext(parent))
 	return base
 }
 
@@ -1874,6 +1877,21 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, "model is required in first response.create payload")
 		return
 	}
+	// Key 级模型策略：后续帧在 MapRequestModel 中逐帧复核。
+	policyModel, policyDenied := resolveAPIKeyModelPolicy(c, apiKey, reqModel)
+	if policyDenied != "" {
+		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, policyDenied)
+		return
+	}
+	if policyModel != reqModel {
+		rewritten, setErr := sjson.SetBytes(firstMessage, "model", policyModel)
+		if setErr != nil {
+			closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, "invalid JSON payload")
+			return
+		}
+		firstMessage = rewritten
+		reqModel = policyModel
+	}
 	ensureCompositeTargetPlatform(c, apiKey, reqModel)
 	ctx = c.Request.Context()
 	if apiKey.Group != nil && apiKey.Group.Platform == service.PlatformComposite {
@@ -2294,6 +2312,12 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 				if model == "" {
 					model = reqModel
 				}
+				// 每一帧 response.create（含 session.update 后回落的会话模型）都要过 Key 级模型策略，
+				// 否则首帧之后换模型即可绕过允许/禁止列表。
+				model, policyErr := enforceOpenAIWSModelPolicy(c, apiKey, model)
+				if policyErr != nil {
+					return "", policyErr
+				}
 				setOpsRequestContext(c, model, true)
 				mapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(ctx, apiKey.GroupID, model)
 				mappedModelUnchanged := false
@@ -2714,7 +2738,8 @@ func (h *OpenAIGatewayHandler) submitUsageRecordTask(parent context.Context, tas
 func (h *OpenAIGatewayHandler) submitOpenAIUsageRecordTask(parent context.Context, result *service.OpenAIForwardResult, task service.UsageRecordTask) {
 	// Money-critical bills never drop on pool overflow: media, search surcharge, voice.
 	if result != nil && (result.ImageCount > 0 || result.VideoCount > 0 ||
-		result.SearchCount > 0 || result.WebSearchCalls > 0 || result.AudioUsage != nil) {
+		result.SearchCount > 0 || result.WebSearchCalls > 0 || result.AudioUsage != nil) ||
+		service.OpenAIBatchExecutionFromContext(parent) != nil {
 		h.submitMandatoryUsageRecordTask(parent, task)
 		return
 	}
@@ -3133,10 +3158,10 @@ func (h *OpenAIGatewayHandler) errorResponse(c *gin.Context, status int, errType
 // openAICompactKeepaliveInterval 复用流式 keepalive 配置作为 compact 下游
 // 心跳间隔；0 表示禁用（与流式路径语义一致）。
 func (h *OpenAIGatewayHandler) openAICompactKeepaliveInterval() time.Duration {
-	if h.cfg == nil || h.cfg.Gateway.StreamKeepaliveInterval <= 0 {
+	if h.cfg == nil || h.cfg.Live().Gateway.StreamKeepaliveInterval <= 0 {
 		return 0
 	}
-	return time.Duration(h.cfg.Gateway.StreamKeepaliveInterval) * time.Second
+	return time.Duration(h.cfg.Live().Gateway.StreamKeepaliveInterval) * time.Second
 }
 
 func setOpenAIClientTransportHTTP(c *gin.Context) {
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var openAIRerankRoute = openAINativeJSONRoute{
+	name:          "rerank",
+	auditProtocol: "openai_rerank",
+	capability:    service.OpenAIEndpointCapabilityRerank,
+	forward:       (*service.OpenAIGatewayService).ForwardRerank,
+}
+
+var openAIModerationsRoute = openAINativeJSONRoute{
+	name:         "moderations",
+	defaultModel: service.OpenAIModerationsDefaultModel,
+	capability:   service.OpenAIEndpointCapabilityModerations,
+	forward:      (*service.OpenAIGatewayService).ForwardModerations,
+}
+
+// Rerank handles the Cohere/Jina-style Rerank API on OpenAI-compatible API key accounts.
+// POST /v1/rerank
+func (h *OpenAIGatewayHandler) Rerank(c *gin.Context) {
+	h.handleOpenAINativeJSON(c, openAIRerankRoute)
+}
+
+// Moderations handles the OpenAI-compatible Moderations API.
+// POST /v1/moderations
+func (h *OpenAIGatewayHandler) Moderations(c *gin.Context) {
+	h.handleOpenAINativeJSON(c, openAIModerationsRoute)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+)
+
+// resolveAPIKeyModelPolicy 解析 Key 的模型别名并校验允许/禁止列表，返回目标模型。
+// denied 非空表示模型不被允许，此时已按 HTTP 入口的口径记录运维与入口拒绝标记。
+//
+// HTTP 入口由 API Key 中间件在读取请求体时完成同样的校验；WebSocket 升级请求没有
+// 请求体，模型只出现在查询参数或每一帧 response.create 中，必须由 handler 自行校验。
+func resolveAPIKeyModelPolicy(c *gin.Context, apiKey *service.APIKey, model string) (target string, denied string) {
+	model = strings.TrimSpace(model)
+	if model == "" || !apiKey.HasModelPolicy() {
+		return model, ""
+	}
+	target = apiKey.ResolveModelAlias(model)
+	if apiKey.IsModelAllowed(target) {
+		return target, ""
+	}
+	service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalPolicyDenied)
+	middleware2.MarkIngressRejected(c, middleware2.IngressRejectModelNotAllowed)
+	return target, "Model " + strconv.Quote(target) + " is not allowed for this API key"
+}
+
+// enforceOpenAIWSModelPolicy 对单帧 response.create 的模型应用 Key 级模型策略；
+// 不允许时返回关闭连接的错误，由 WS 转发层以 policy violation 关闭客户端连接。
+func enforceOpenAIWSModelPolicy(c *gin.Context, apiKey *service.APIKey, model string) (string, error) {
+	target, denied := resolveAPIKeyModelPolicy(c, apiKey, model)
+	if denied != "" {
+		return target, service.NewOpenAIWSClientCloseError(coderws.StatusPolicyViolation, denied, nil)
+	}
+	return target, nil
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpenAIWSModelPolicyTestContext() *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/openai/v1/responses", nil)
+	return c
+}
+
+func TestEnforceOpenAIWSModelPolicy(t *testing.T) {
+	apiKey := &service.APIKey{
+		ModelAllowlist: []string{"gpt-5*"},
+		ModelDenylist:  []string{"gpt-5-pro"},
+		ModelAliases:   map[string]string{"fast": "gpt-5-mini"},
+	}
+
+	t.Run("alias resolves to allowed target", func(t *testing.T) {
+		model, err := enforceOpenAIWSModelPolicy(newOpenAIWSModelPolicyTestContext(), apiKey, " fast ")
+		require.NoError(t, err)
+		require.Equal(t, "gpt-5-mini", model)
+	})
+
+	t.Run("allowed model passes through", func(t *testing.T) {
+		model, err := enforceOpenAIWSModelPolicy(newOpenAIWSModelPolicyTestContext(), apiKey, "gpt-5")
+		require.NoError(t, err)
+		require.Equal(t, "gpt-5", model)
+	})
+
+	t.Run("denylisted model closes with policy violation", func(t *testing.T) {
+		_, err := enforceOpenAIWSModelPolicy(newOpenAIWSModelPolicyTestContext(), apiKey, "gpt-5-pro")
+		var closeErr *service.OpenAIWSClientCloseError
+		require.True(t, errors.As(err, &closeErr))
+		require.Equal(t, coderws.StatusPolicyViolation, closeErr.StatusCode())
+		require.Contains(t, closeErr.Reason(), `"gpt-5-pro"`)
+	})
+
+	t.Run("model outside allowlist closes with policy violation", func(t *testing.T) {
+		_, err := enforceOpenAIWSModelPolicy(newOpenAIWSModelPolicyTestContext(), apiKey, "o3")
+		var closeErr *service.OpenAIWSClientCloseError
+		require.True(t, errors.As(err, &closeErr))
+		require.Equal(t, coderws.StatusPolicyViolation, closeErr.StatusCode())
+	})
+
+	t.Run("key without policy is unrestricted", func(t *testing.T) {
+		model, err := enforceOpenAIWSModelPolicy(newOpenAIWSModelPolicyTestContext(), &service.APIKey{}, "o3")
+		require.NoError(t, err)
+		require.Equal(t, "o3", model)
+	})
+}
+
+func TestOpenAIResponsesWebSocket_FirstFrameModelDeniedByAPIKeyPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newOpenAIHandlerForPreviousResponseIDValidation(t, nil)
+	h.cfg = &config.Config{}
+
+	groupID := int64(2)
+	apiKey := &service.APIKey{
+		ID:            102,
+		GroupID:       &groupID,
+		User:          &service.User{ID: 1},
+		ModelDenylist: []string{"gpt-5-pro"},
+	}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+		c.Set(string(middleware.ContextKeyUser), middleware.AuthSubject{UserID: 1, Concurrency: 1})
+		c.Next()
+	})
+	router.GET("/openai/v1/responses", h.ResponsesWebSocket)
+	wsServer := httptest.NewServer(router)
+	defer wsServer.Close()
+
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), 3*time.Second)
+	clientConn, _, err := coderws.Dial(dialCtx, "ws"+strings.TrimPrefix(wsServer.URL, "http")+"/openai/v1/responses", nil)
+	cancelDial()
+	require.NoError(t, err)
+	defer func() { _ = clientConn.CloseNow() }()
+
+	writeCtx, cancelWrite := context.WithTimeout(context.Background(), 3*time.Second)
+	err = clientConn.Write(writeCtx, coderws.MessageText, []byte(`{"type":"response.create","model":"gpt-5-pro","input":[]}`))
+	cancelWrite()
+	require.NoError(t, err)
+
+	readCtx, cancelRead := context.WithTimeout(context.Background(), 3*time.Second)
+	_, _, err = clientConn.Read(readCtx)
+	cancelRead()
+	var closeErr coderws.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, coderws.StatusPolicyViolation, closeErr.Code)
+	require.Contains(t, closeErr.Reason, "not allowed for this API key")
+}
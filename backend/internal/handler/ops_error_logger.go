@@ -472,12 +472,16 @@ func setOpsSelectedAccount(c *gin.Context, accountID int64, platform ...string)
 	c.Set(opsAccountIDKey, accountID)
 	if c.Request != nil {
 		ctx := context.WithValue(c.Request.Context(), ctxkey.AccountID, accountID)
+		attemptPlatform := ""
 		if len(platform) > 0 {
 			p := strings.TrimSpace(platform[0])
 			if p != "" {
 				ctx = context.WithValue(ctx, ctxkey.Platform, p)
+				attemptPlatform = p
 			}
 		}
+		// 每次选号即一次上游尝试：为 failover 循环的每一轮开启独立的 attempt span。
+		ctx = gatewayAttemptTraceFromContext(ctx).begin(ctx, accountID, attemptPlatform)
 		c.Request = c.Request.WithContext(ctx)
 	}
 }
@@ -1088,8 +1092,12 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			releaseOpsCaptureWriter(w)
 		}()
 		c.Writer = w
+		withGatewayAttemptTrace(c)
+		startedAt := time.Now()
 		c.Next()
 		w.finalizeCapture()
+		gatewayAttemptTraceFromContext(c.Request.Context()).finishRequest(c.Writer.Status())
+		observeGatewayMetrics(c, startedAt)
 
 		if _, rejected := middleware2.GetIngressRejectReason(c); rejected {
 			return
@@ -0,0 +1,452 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles organizations (teams): members, invitations,
+// the shared wallet, usage and pooled API keys.
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+// CreateOrganizationRequest represents the create / rename organization payload
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateOrganizationMemberRequest represents the update member payload (nil = no change)
+type UpdateOrganizationMemberRequest struct {
+	Role                 *string  `json:"role"`
+	MonthlySpendLimitUSD *float64 `json:"monthly_spend_limit_usd"`
+}
+
+// InviteOrganizationMemberRequest represents the invite payload
+type InviteOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role"`
+}
+
+// AcceptOrganizationInvitationRequest represents the accept invitation payload
+type AcceptOrganizationInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// FundOrganizationRequest represents a transfer from the member's balance to the wallet
+type FundOrganizationRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+	Note   string  `json:"note"`
+}
+
+// List handles listing the organizations the current user belongs to
+// GET /api/v1/organizations
+func (h *OrganizationHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	memberships, err := h.organizationService.ListMine(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.Organization, 0, len(memberships))
+	for i := range memberships {
+		out = append(out, *dto.OrganizationFromService(&memberships[i].Organization, memberships[i].Role))
+	}
+	response.Success(c, out)
+}
+
+// Create handles creating an organization owned by the current user
+// POST /api/v1/organizations
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.Create(c.Request.Context(), subject.UserID, req.Name)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, dto.OrganizationFromService(org, service.OrganizationRoleOwner))
+}
+
+// Get handles getting an organization the current user belongs to
+// GET /api/v1/organizations/:id
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	org, member, err := h.organizationService.Get(c.Request.Context(), subject.UserID, orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationFromService(org, member.Role))
+}
+
+// Update handles renaming an organization (owner/admin)
+// PUT /api/v1/organizations/:id
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	org, err := h.organizationService.Rename(c.Request.Context(), subject.UserID, orgID, req.Name)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationFromService(org, ""))
+}
+
+// ListMembers handles listing organization members
+// GET /api/v1/organizations/:id/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), subject.UserID, orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationMembersFromService(members))
+}
+
+// UpdateMember handles changing a member's role or monthly spending limit
+// PUT /api/v1/organizations/:id/members/:user_id
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+	userID, ok := parseOrganizationID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req UpdateOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	member, err := h.organizationService.UpdateMember(c.Request.Context(), subject.UserID, orgID, userID, service.UpdateOrganizationMemberInput{
+		Role:                 req.Role,
+		MonthlySpendLimitUSD: req.MonthlySpendLimitUSD,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationMemberFromService(member))
+}
+
+// RemoveMember handles removing a member, or leaving when the target is the caller
+// DELETE /api/v1/organizations/:id/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+	userID, ok := parseOrganizationID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), subject.UserID, orgID, userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Member removed successfully"})
+}
+
+// ListInvitations handles listing invitations (owner/admin)
+// GET /api/v1/organizations/:id/invitations
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.organizationService.ListInvitations(c.Request.Context(), subject.UserID, orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.OrganizationInvitation, 0, len(invitations))
+	for i := range invitations {
+		out = append(out, *dto.OrganizationInvitationFromService(&invitations[i]))
+	}
+	response.Success(c, out)
+}
+
+// Invite handles emailing an invitation (owner/admin)
+// POST /api/v1/organizations/:id/invitations
+func (h *OrganizationHandler) Invite(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req InviteOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	inv, err := h.organizationService.Invite(c.Request.Context(), subject.UserID, orgID, req.Email, req.Role)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, dto.OrganizationInvitationFromService(inv))
+}
+
+// RevokeInvitation handles revoking a pending invitation (owner/admin)
+// DELETE /api/v1/organizations/:id/invitations/:invitation_id
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+	invitationID, ok := parseOrganizationID(c, "invitation_id", "Invalid invitation ID")
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RevokeInvitation(c.Request.Context(), subject.UserID, orgID, invitationID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation handles accepting an emailed invitation
+// POST /api/v1/organizations/invitations/accept
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req AcceptOrganizationInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	inv, err := h.organizationService.AcceptInvitation(c.Request.Context(), subject.UserID, req.Token)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"organization_id": inv.OrganizationID, "role": inv.Role})
+}
+
+// Fund handles transferring the member's personal balance into the shared wallet
+// POST /api/v1/organizations/:id/wallet/fund
+func (h *OrganizationHandler) Fund(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var req FundOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	orgBalance, userBalance, err := h.organizationService.Fund(c.Request.Context(), subject.UserID, orgID, req.Amount, req.Note)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"organization_balance": orgBalance, "user_balance": userBalance})
+}
+
+// ListWalletTransactions handles listing wallet top-ups (owner/admin)
+// GET /api/v1/organizations/:id/wallet/transactions
+func (h *OrganizationHandler) ListWalletTransactions(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	txs, result, err := h.organizationService.ListWalletTransactions(c.Request.Context(), subject.UserID, orgID, pagination.PaginationParams{Page: page, PageSize: pageSize})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.OrganizationWalletTransaction, 0, len(txs))
+	for i := range txs {
+		out = append(out, *dto.OrganizationWalletTransactionFromService(&txs[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Usage handles the per-member usage view built from usage_logs
+// GET /api/v1/organizations/:id/usage?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=
+func (h *OrganizationHandler) Usage(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	var startTime, endTime *time.Time
+	userTZ := c.Query("timezone")
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		startTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		t = t.AddDate(0, 0, 1)
+		endTime = &t
+	}
+
+	summary, err := h.organizationService.GetUsage(c.Request.Context(), subject.UserID, orgID, startTime, endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationUsageSummaryFromService(summary))
+}
+
+// ListAPIKeys handles listing pooled organization API keys
+// GET /api/v1/organizations/:id/api-keys
+func (h *OrganizationHandler) ListAPIKeys(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	orgID, ok := parseOrganizationID(c, "id", "Invalid organization ID")
+	if !ok {
+		return
+	}
+
+	keys, err := h.organizationService.ListAPIKeys(c.Request.Context(), subject.UserID, orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.OrganizationAPIKey, 0, len(keys))
+	for i := range keys {
+		out = append(out, *dto.OrganizationAPIKeyFromService(&keys[i]))
+	}
+	response.Success(c, out)
+}
+
+func parseOrganizationID(c *gin.Context, param, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, message)
+		return 0, false
+	}
+	return id, true
+}
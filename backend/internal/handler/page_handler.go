@@ -274,10 +274,11 @@ func RegisterPageRoutes(v1 *gin.RouterGroup, dataDir string, jwtAuth gin.Handler
 		pageImages.GET("/:slug/images/*filename", h.ServePageImage)
 	}
 
-	// Admin-only: list all available pages
+	// Admin-only: list all available pages (custom menu pages are part of settings)
 	adminPages := v1.Group("/pages")
 	adminPages.Use(adminAuth)
 	adminPages.Use(middleware2.AdminComplianceGuard(settingService))
+	adminPages.Use(middleware2.RequireAdminPermission(service.AdminResourceSettings))
 	{
 		adminPages.GET("", h.ListPages)
 	}
@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCleanPageImageRelativePath(t *testing.T) {
@@ -110,3 +117,24 @@ func mustEvalSymlinks(t *testing.T, path string) string {
 	}
 	return realPath
 }
+
+func TestListPagesRequiresSettingsPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(perms *service.AdminPermissions) int {
+		router := gin.New()
+		adminAuth := func(c *gin.Context) {
+			c.Set(middleware2.ContextKeyAdminPermissions, perms)
+			c.Next()
+		}
+		noop := func(c *gin.Context) { c.Next() }
+		RegisterPageRoutes(router.Group("/api/v1"), t.TempDir(), noop, adminAuth, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pages", nil))
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, serve(service.NewAdminPermissions("support", []string{"users:read"})))
+	require.Equal(t, http.StatusOK, serve(service.NewAdminPermissions("ops", []string{"settings:read"})))
+	require.Equal(t, http.StatusOK, serve(service.SuperAdminPermissions()))
+}
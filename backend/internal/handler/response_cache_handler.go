@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/securityaudit"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	responseCacheHeader       = "X-Response-Cache"
+	responseCacheStoreTimeout = 3 * time.Second
+)
+
+// ResponseCacheHandler 精确匹配响应缓存中间件：挂在 /v1/messages、/v1/chat/completions、
+// /v1/responses 路由上，命中时直接回放缓存响应并按命中价计费，未命中时透传给原 handler
+// 并在成功后写入缓存。
+type ResponseCacheHandler struct {
+	service                  *service.ResponseCacheService
+	billingCacheService      *service.BillingCacheService
+	apiKeyService            *service.APIKeyService
+	contentModerationService *service.ContentModerationService
+	securityAuditCoordinator *securityaudit.Coordinator
+	cfg                      *config.Config
+}
+
+func NewResponseCacheHandler(
+	responseCacheService *service.ResponseCacheService,
+	billingCacheService *service.BillingCacheService,
+	apiKeyService *service.APIKeyService,
+	contentModerationService *service.ContentModerationService,
+	coordinator *securityaudit.Coordinator,
+	cfg *config.Config,
+) *ResponseCacheHandler {
+	return &ResponseCacheHandler{
+		service:                  responseCacheService,
+		billingCacheService:      billingCacheService,
+		apiKeyService:            apiKeyService,
+		contentModerationService: contentModerationService,
+		securityAuditCoordinator: coordinator,
+		cfg:                      cfg,
+	}
+}
+
+// Middleware 返回路由级中间件。分组与 Key 均未启用缓存时零开销透传。
+func (h *ResponseCacheHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil || h.service == nil {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+		policy, ok := h.service.PolicyFor(apiKey)
+		if !ok {
+			c.Next()
+			return
+		}
+		noStore, noCache := parseResponseCacheControl(c.GetHeader("Cache-Control"))
+		if noStore {
+			c.Next()
+			return
+		}
+
+		startedAt := time.Now()
+		body, ok := h.peekRequestBody(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		groupID := int64(0)
+		if apiKey.GroupID != nil {
+			groupID = *apiKey.GroupID
+		}
+		req, ok := h.service.BuildRequest(GetInboundEndpoint(c), groupID, body)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if noCache {
+			c.Header(responseCacheHeader, "bypass")
+		} else {
+			entry, err := h.service.Lookup(c.Request.Context(), req.Key)
+			if err == nil && entry != nil && entry.Stream == req.Stream && h.serveHit(c, apiKey, req, policy, entry, body, startedAt) {
+				return
+			}
+			c.Header(responseCacheHeader, "miss")
+		}
+
+		h.captureAndStore(c, req, policy)
+	}
+}
+
+// peekRequestBody 读出请求体用于计算缓存键，并原样放回供后续 handler 再读。
+// 读取失败（如超出 body 限制）时把已读部分与原错误一起放回，由 handler 按原逻辑报错。
+func (h *ResponseCacheHandler) peekRequestBody(c *gin.Context) ([]byte, bool) {
+	if c.Request == nil || c.Request.Body == nil {
+		return nil, false
+	}
+	raw, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), responseCacheErrReader{err: err}))
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	probe := &http.Request{Header: c.Request.Header, Body: io.NopCloser(bytes.NewReader(raw)), ContentLength: int64(len(raw))}
+	body, err := readLenientJSONRequestBodyWithPrealloc(probe, h.cfg)
+	if err != nil || len(body) == 0 {
+		return nil, false
+	}
+	return body, true
+}
+
+// serveHit 回放缓存响应。审计未通过、余额/订阅不满足或命中无法定价时返回 false，
+// 交给原 handler 按各协议的错误格式处理。
+func (h *ResponseCacheHandler) serveHit(c *gin.Context, apiKey *service.APIKey, req service.ResponseCacheRequest, policy service.ResponseCachePolicy, entry *service.ResponseCacheEntry, body []byte, startedAt time.Time) bool {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok || apiKey.User == nil {
+		return false
+	}
+	reqLog := requestLogger(c, "handler.response_cache",
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+	// 命中同样不能绕过内容审计：缓存按分组共享，原请求可能来自另一个用户。
+	protocol := responseCacheAuditProtocol(GetInboundEndpoint(c))
+	if decision := runSecurityAudit(c, reqLog, h.securityAuditCoordinator, h.contentModerationService, apiKey, subject, protocol, req.Model, body, "http"); decision != nil && !decision.AllowNextStage {
+		return false
+	}
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+	quotaPlatform := service.QuotaPlatform(c.Request.Context(), apiKey)
+	if policy.PriceMultiplier > 0 && h.billingCacheService != nil {
+		if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription, quotaPlatform); err != nil {
+			return false
+		}
+	}
+
+	// 回放前先定价：查不到价格时按未命中转发上游，不能把缓存响应当作免费命中。
+	price, err := h.service.PriceHit(c.Request.Context(), apiKey, apiKey.User, entry, policy)
+	if err != nil {
+		reqLog.Warn("response_cache.price_hit_failed", zap.String("model", req.Model), zap.Error(err))
+		return false
+	}
+
+	c.Header(responseCacheHeader, "hit")
+	writeResponseCacheEntry(c, entry)
+	c.Abort()
+
+	recordErr := h.service.RecordHit(context.WithoutCancel(c.Request.Context()), &service.ResponseCacheHitInput{
+		APIKey:          apiKey,
+		User:            apiKey.User,
+		Subscription:    subscription,
+		Entry:           entry,
+		Policy:          policy,
+		Price:           price,
+		InboundEndpoint: GetInboundEndpoint(c),
+		UserAgent:       c.GetHeader("User-Agent"),
+		IPAddress:       ip.GetClientIP(c),
+		APIKeyService:   h.apiKeyService,
+		QuotaPlatform:   quotaPlatform,
+		Duration:        time.Since(startedAt),
+	})
+	if recordErr != nil {
+		reqLog.Warn("response_cache.record_hit_failed", zap.String("model", req.Model), zap.Error(recordErr))
+	}
+	return true
+}
+
+// writeResponseCacheEntry 写出缓存响应；流式响应按 SSE 事件边界逐条写出并 flush，
+// 保证客户端看到与上游一致的事件分帧。
+func writeResponseCacheEntry(c *gin.Context, entry *service.ResponseCacheEntry) {
+	contentType := strings.TrimSpace(entry.ContentType)
+	if !entry.Stream {
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		c.Data(entry.StatusCode, contentType, entry.Body)
+		return
+	}
+	if contentType == "" {
+		contentType = "text/event-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(entry.StatusCode)
+	for _, event := range service.SplitResponseCacheSSEEvents(entry.Body) {
+		if _, err := c.Writer.Write(event); err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// captureAndStore 透传给原 handler，同时复制响应体；请求成功结束后写入缓存。
+func (h *ResponseCacheHandler) captureAndStore(c *gin.Context, req service.ResponseCacheRequest, policy service.ResponseCachePolicy) {
+	originalWriter := c.Writer
+	w := &responseCacheCaptureWriter{ResponseWriter: originalWriter, limit: h.service.MaxEntryBytes()}
+	c.Writer = w
+	c.Next()
+	if c.Writer == w {
+		c.Writer = originalWriter
+	}
+
+	captured, ok := w.captured()
+	if !ok || w.Status() != http.StatusOK {
+		return
+	}
+	accountID, _ := c.Get(opsAccountIDKey)
+	id, _ := accountID.(int64)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), responseCacheStoreTimeout)
+	defer cancel()
+	h.service.Store(ctx, req, policy, w.Status(), w.Header().Get("Content-Type"), captured, id)
+}
+
+// parseResponseCacheControl 解析客户端 Cache-Control：no-store 既不读也不写，
+// no-cache 跳过读取但仍写入最新结果。
+func parseResponseCacheControl(value string) (noStore bool, noCache bool) {
+	for _, directive := range strings.Split(strings.ToLower(value), ",") {
+		switch strings.TrimSpace(directive) {
+		case "no-store":
+			noStore = true
+		case "no-cache":
+			noCache = true
+		}
+	}
+	return noStore, noCache
+}
+
+func responseCacheAuditProtocol(inboundEndpoint string) string {
+	switch inboundEndpoint {
+	case EndpointChatCompletions:
+		return service.ContentModerationProtocolOpenAIChat
+	case EndpointResponses:
+		return service.ContentModerationProtocolOpenAIResponses
+	default:
+		return service.ContentModerationProtocolAnthropicMessages
+	}
+}
+
+type responseCacheErrReader struct{ err error }
+
+func (r responseCacheErrReader) Read([]byte) (int, error) { return 0, r.err }
+
+// responseCacheCaptureWriter 在写给客户端的同时复制响应体；超过上限即放弃缓存，
+// 不影响下游写出。SSE keepalive 可能从其它 goroutine 写入，因此加锁。
+type responseCacheCaptureWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *responseCacheCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCacheCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseCacheCaptureWriter) capture(data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+len(data) > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	_, _ = w.buf.Write(data)
+}
+
+func (w *responseCacheCaptureWriter) captured() ([]byte, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.overflow || w.buf.Len() == 0 {
+		return nil, false
+	}
+	return bytes.Clone(w.buf.Bytes()), true
+}
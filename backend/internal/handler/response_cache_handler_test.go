@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type responseCacheHandlerStoreStub struct {
+	entries map[string]*service.ResponseCacheEntry
+}
+
+func (s *responseCacheHandlerStoreStub) GetResponseCacheEntry(_ context.Context, key string) (*service.ResponseCacheEntry, error) {
+	if entry, ok := s.entries[key]; ok {
+		return entry, nil
+	}
+	return nil, service.ErrResponseCacheMiss
+}
+
+func (s *responseCacheHandlerStoreStub) SetResponseCacheEntry(_ context.Context, key string, entry *service.ResponseCacheEntry, _ time.Duration) error {
+	s.entries[key] = entry
+	return nil
+}
+
+func newResponseCacheTestRouter(t *testing.T, store *responseCacheHandlerStoreStub, upstreamCalls *int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.ResponseCache.Enabled = true
+	cfg.ResponseCache.DefaultTTLSeconds = 60
+	cfg.ResponseCache.MaxEntryBytes = 1 << 20
+	h := NewResponseCacheHandler(service.NewResponseCacheService(store, nil, cfg), nil, nil, nil, nil, cfg)
+
+	groupID := int64(3)
+	apiKey := &service.APIKey{ID: 10, UserID: 20, GroupID: &groupID, User: &service.User{ID: 20}, Group: &service.Group{ID: groupID, ResponseCacheEnabled: true}}
+
+	r := gin.New()
+	r.POST("/v1/messages", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), apiKey)
+		c.Set(string(middleware2.ContextKeyUser), middleware2.AuthSubject{UserID: 20})
+		c.Set(ctxKeyInboundEndpoint, EndpointMessages)
+	}, h.Middleware(), func(c *gin.Context) {
+		*upstreamCalls++
+		setOpsSelectedAccount(c, 99)
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":5,\"output_tokens\":1}}}\n\n")
+		_, _ = c.Writer.WriteString("event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}\n\n")
+		_, _ = c.Writer.WriteString("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	})
+	return r
+}
+
+func doResponseCacheRequest(r *gin.Engine, body string, cacheControl string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if cacheControl != "" {
+		req.Header.Set("Cache-Control", cacheControl)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCacheMiddleware_StoresThenReplaysStream(t *testing.T) {
+	store := &responseCacheHandlerStoreStub{entries: map[string]*service.ResponseCacheEntry{}}
+	calls := 0
+	r := newResponseCacheTestRouter(t, store, &calls)
+	body := `{"model":"claude-sonnet-4","stream":true,"max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`
+
+	first := doResponseCacheRequest(r, body, "")
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Equal(t, "miss", first.Header().Get(responseCacheHeader))
+	require.Equal(t, 1, calls)
+	require.Len(t, store.entries, 1)
+	for _, entry := range store.entries {
+		require.Equal(t, int64(99), entry.AccountID)
+		require.Equal(t, 7, entry.OutputTokens)
+	}
+
+	second := doResponseCacheRequest(r, `{"stream":true,"model":"claude-sonnet-4","max_tokens":16,"messages":[{"content":"hi","role":"user"}],"metadata":{"user_id":"x"}}`, "")
+	require.Equal(t, http.StatusOK, second.Code)
+	require.Equal(t, "hit", second.Header().Get(responseCacheHeader))
+	require.Equal(t, 1, calls, "命中时不调用上游")
+	require.Equal(t, "text/event-stream", second.Header().Get("Content-Type"))
+	require.Equal(t, first.Body.String(), second.Body.String(), "回放保持原 SSE 分帧")
+
+	third := doResponseCacheRequest(r, body, "no-cache")
+	require.Equal(t, "bypass", third.Header().Get(responseCacheHeader))
+	require.Equal(t, 2, calls)
+
+	fourth := doResponseCacheRequest(r, body, "no-store")
+	require.Empty(t, fourth.Header().Get(responseCacheHeader))
+	require.Equal(t, 3, calls)
+}
+
+func TestParseResponseCacheControl(t *testing.T) {
+	noStore, noCache := parseResponseCacheControl("max-age=0, No-Cache")
+	require.False(t, noStore)
+	require.True(t, noCache)
+	noStore, noCache = parseResponseCacheControl("no-store")
+	require.True(t, noStore)
+	require.False(t, noCache)
+}
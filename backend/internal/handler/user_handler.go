@@ -99,6 +99,7 @@ type userProfileResponse struct {
 	OIDCBound         bool                                   `json:"oidc_bound"`
 	WeChatBound       bool                                   `json:"wechat_bound"`
 	DingTalkBound     bool                                   `json:"dingtalk_bound"`
+	SAMLBound         bool                                   `json:"saml_bound"`
 }
 
 type userProfileSourceContext struct {
@@ -561,6 +562,7 @@ func userProfileResponseFromService(user *service.User, identities service.UserI
 		OIDCBound:         identities.OIDC.Bound,
 		WeChatBound:       identities.WeChat.Bound,
 		DingTalkBound:     identities.DingTalk.Bound,
+		SAMLBound:         identities.SAML.Bound,
 	}
 }
 
@@ -571,6 +573,7 @@ func userProfileBindingMap(identities service.UserIdentitySummarySet) map[string
 		"oidc":     identities.OIDC,
 		"wechat":   identities.WeChat,
 		"dingtalk": identities.DingTalk,
+		"saml":     identities.SAML,
 	}
 }
 
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserWebhookHandler handles user-managed outbound webhooks and their delivery log.
+type UserWebhookHandler struct {
+	webhookService *service.UserWebhookService
+}
+
+// NewUserWebhookHandler creates a new UserWebhookHandler
+func NewUserWebhookHandler(webhookService *service.UserWebhookService) *UserWebhookHandler {
+	return &UserWebhookHandler{webhookService: webhookService}
+}
+
+// CreateUserWebhookRequest represents the create webhook endpoint payload
+type CreateUserWebhookRequest struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url" binding:"required"`
+	Events  []string `json:"events" binding:"required"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// UpdateUserWebhookRequest represents the update webhook endpoint payload (nil = no change)
+type UpdateUserWebhookRequest struct {
+	Name    *string  `json:"name"`
+	URL     *string  `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// ListEventTypes returns the subscribable event types
+// GET /api/v1/webhooks/events
+func (h *UserWebhookHandler) ListEventTypes(c *gin.Context) {
+	response.Success(c, gin.H{"events": service.UserWebhookEventTypes})
+}
+
+// List handles listing the current user's webhook endpoints
+// GET /api/v1/webhooks
+func (h *UserWebhookHandler) List(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	endpoints, err := h.webhookService.ListEndpoints(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.UserWebhookEndpoint, 0, len(endpoints))
+	for i := range endpoints {
+		out = append(out, *dto.UserWebhookEndpointFromService(&endpoints[i]))
+	}
+	response.Success(c, out)
+}
+
+// Create handles creating a webhook endpoint; the signing secret is returned once
+// POST /api/v1/webhooks
+func (h *UserWebhookHandler) Create(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreateUserWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	endpoint, secret, err := h.webhookService.CreateEndpoint(c.Request.Context(), subject.UserID, service.CreateUserWebhookEndpointInput{
+		Name:    req.Name,
+		URL:     req.URL,
+		Events:  req.Events,
+		Enabled: req.Enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Created(c, dto.UserWebhookEndpointWithSecret{
+		UserWebhookEndpoint: *dto.UserWebhookEndpointFromService(endpoint),
+		Secret:              secret,
+	})
+}
+
+// Update handles updating a webhook endpoint
+// PUT /api/v1/webhooks/:id
+func (h *UserWebhookHandler) Update(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parseUserWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	var req UpdateUserWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(c.Request.Context(), subject.UserID, id, service.UpdateUserWebhookEndpointInput{
+		Name:    req.Name,
+		URL:     req.URL,
+		Events:  req.Events,
+		Enabled: req.Enabled,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserWebhookEndpointFromService(endpoint))
+}
+
+// Delete handles deleting a webhook endpoint
+// DELETE /api/v1/webhooks/:id
+func (h *UserWebhookHandler) Delete(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parseUserWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteEndpoint(c.Request.Context(), subject.UserID, id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// RotateSecret handles regenerating the signing secret of an endpoint
+// POST /api/v1/webhooks/:id/rotate-secret
+func (h *UserWebhookHandler) RotateSecret(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parseUserWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	endpoint, secret, err := h.webhookService.RotateSecret(c.Request.Context(), subject.UserID, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserWebhookEndpointWithSecret{
+		UserWebhookEndpoint: *dto.UserWebhookEndpointFromService(endpoint),
+		Secret:              secret,
+	})
+}
+
+// SendTest handles queueing a webhook.ping delivery to an endpoint
+// POST /api/v1/webhooks/:id/test
+func (h *UserWebhookHandler) SendTest(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parseUserWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.SendTest(c.Request.Context(), subject.UserID, id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Accepted(c, gin.H{"message": "Test event queued"})
+}
+
+// ListDeliveries handles listing the delivery log with pagination
+// GET /api/v1/webhooks/deliveries
+func (h *UserWebhookHandler) ListDeliveries(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	filter := service.UserWebhookDeliveryFilter{
+		EventType: strings.TrimSpace(c.Query("event_type")),
+		Status:    strings.TrimSpace(c.Query("status")),
+	}
+	if raw := c.Query("endpoint_id"); raw != "" {
+		endpointID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || endpointID <= 0 {
+			response.BadRequest(c, "Invalid endpoint_id")
+			return
+		}
+		filter.EndpointID = endpointID
+	}
+
+	deliveries, result, err := h.webhookService.ListDeliveries(c.Request.Context(), subject.UserID, filter, pagination.PaginationParams{Page: page, PageSize: pageSize})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.UserWebhookDelivery, 0, len(deliveries))
+	for i := range deliveries {
+		out = append(out, *dto.UserWebhookDeliveryFromService(&deliveries[i], false))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// GetDelivery handles getting a single delivery including its payload
+// GET /api/v1/webhooks/deliveries/:id
+func (h *UserWebhookHandler) GetDelivery(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parseUserWebhookID(c, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(c.Request.Context(), subject.UserID, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserWebhookDeliveryFromService(delivery, true))
+}
+
+// Redeliver handles manually re-queueing a delivery
+// POST /api/v1/webhooks/deliveries/:id/redeliver
+func (h *UserWebhookHandler) Redeliver(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	id, ok := parseUserWebhookID(c, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Redeliver(c.Request.Context(), subject.UserID, id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Accepted(c, gin.H{"message": "Delivery queued"})
+}
+
+func parseUserWebhookID(c *gin.Context, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, message)
+		return 0, false
+	}
+	return id, true
+}
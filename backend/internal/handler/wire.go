@@ -46,6 +46,15 @@ func ProvideAdminHandlers(
 	affiliateHandler *admin.AffiliateHandler,
 	complianceHandler *admin.ComplianceHandler,
 	auditLogHandler *admin.AuditLogHandler,
+	organizationHandler *admin.OrganizationHandler,
+	budgetHandler *admin.BudgetHandler,
+	samlHandler *admin.SAMLHandler,
+	balanceLedgerHandler *admin.BalanceLedgerHandler,
+	invoiceHandler *admin.InvoiceHandler,
+	usageExportHandler *admin.UsageExportHandler,
+	credentialEncryptionHandler *admin.CredentialEncryptionHandler,
+	rbacHandler *admin.RBACHandler,
+	adminAPITokenHandler *admin.AdminAPITokenHandler,
 	upstreamBillingProbe *service.UpstreamBillingProbeService,
 	ollamaCloudUsage *service.OllamaCloudUsageService,
 ) *AdminHandlers {
@@ -87,6 +96,15 @@ func ProvideAdminHandlers(
 		Affiliate:              affiliateHandler,
 		Compliance:             complianceHandler,
 		AuditLog:               auditLogHandler,
+		Organization:           organizationHandler,
+		Budget:                 budgetHandler,
+		SAML:                   samlHandler,
+		BalanceLedger:          balanceLedgerHandler,
+		Invoice:                invoiceHandler,
+		UsageExport:            usageExportHandler,
+		CredentialEncryption:   credentialEncryptionHandler,
+		RBAC:                   rbacHandler,
+		AdminAPIToken:          adminAPITokenHandler,
 	}
 }
 
@@ -146,9 +164,16 @@ func ProvideBatchImageHandler(
 	return h
 }
 
-// ProvideSystemHandler creates admin.SystemHandler with UpdateService
-func ProvideSystemHandler(updateService *service.UpdateService, lockService *service.SystemOperationLockService) *admin.SystemHandler {
-	return admin.NewSystemHandler(updateService, lockService)
+// ProvideSystemHandler creates admin.SystemHandler with UpdateService and ConfigReloadService
+func ProvideSystemHandler(updateService *service.UpdateService, lockService *service.SystemOperationLockService, configReload *service.ConfigReloadService) *admin.SystemHandler {
+	return admin.NewSystemHandler(updateService, lockService).WithConfigReload(configReload)
+}
+
+// ProvideAuthHandler creates AuthHandler with the optional SAML service
+func ProvideAuthHandler(cfg *config.Config, authService *service.AuthService, userService *service.UserService, settingService *service.SettingService, promoService *service.PromoService, redeemService *service.RedeemService, totpService *service.TotpService, userAttributeService *service.UserAttributeService, samlService *service.SAMLService) *AuthHandler {
+	h := NewAuthHandler(cfg, authService, userService, settingService, promoService, redeemService, totpService, userAttributeService)
+	h.SetSAMLService(samlService)
+	return h
 }
 
 // ProvideSettingHandler creates SettingHandler with version from BuildInfo
@@ -190,6 +215,13 @@ func ProvideHandlers(
 	modelPlazaHandler *ModelPlazaHandler,
 	asyncImageHandler *AsyncImageHandler,
 	batchImageHandler *BatchImageHandler,
+	userWebhookHandler *UserWebhookHandler,
+	organizationHandler *OrganizationHandler,
+	budgetHandler *BudgetHandler,
+	openAIBatchHandler *OpenAIBatchHandler,
+	responseCacheHandler *ResponseCacheHandler,
+	balanceLedgerHandler *BalanceLedgerHandler,
+	invoiceHandler *InvoiceHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
@@ -215,13 +247,20 @@ func ProvideHandlers(
 		ModelPlaza:       modelPlazaHandler,
 		AsyncImage:       asyncImageHandler,
 		BatchImage:       batchImageHandler,
+		UserWebhook:      userWebhookHandler,
+		Organization:     organizationHandler,
+		Budget:           budgetHandler,
+		OpenAIBatch:      openAIBatchHandler,
+		ResponseCache:    responseCacheHandler,
+		BalanceLedger:    balanceLedgerHandler,
+		Invoice:          invoiceHandler,
 	}
 }
 
 // ProviderSet is the Wire provider set for all handlers
 var ProviderSet = wire.NewSet(
 	// Top-level handlers
-	NewAuthHandler,
+	ProvideAuthHandler,
 	NewUserHandler,
 	NewAPIKeyHandler,
 	NewUsageHandler,
@@ -241,6 +280,13 @@ var ProviderSet = wire.NewSet(
 	NewModelPlazaHandler,
 	NewAsyncImageHandler,
 	ProvideBatchImageHandler,
+	NewUserWebhookHandler,
+	NewOrganizationHandler,
+	NewBudgetHandler,
+	NewOpenAIBatchHandler,
+	NewResponseCacheHandler,
+	NewBalanceLedgerHandler,
+	NewInvoiceHandler,
 
 	// Admin handlers
 	admin.NewDashboardHandler,
@@ -277,6 +323,15 @@ var ProviderSet = wire.NewSet(
 	admin.NewAffiliateHandler,
 	admin.NewComplianceHandler,
 	admin.NewAuditLogHandler,
+	admin.NewOrganizationHandler,
+	admin.NewBudgetHandler,
+	admin.NewSAMLHandler,
+	admin.NewBalanceLedgerHandler,
+	admin.NewInvoiceHandler,
+	admin.NewUsageExportHandler,
+	admin.NewCredentialEncryptionHandler,
+	admin.NewRBACHandler,
+	admin.NewAdminAPITokenHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
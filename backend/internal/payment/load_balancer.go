@@ -12,6 +12,7 @@ import (
 	dbent "github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/ent/paymentorder"
 	"github.com/Wei-Shaw/sub2api/ent/paymentproviderinstance"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/secrets"
 )
 
 // Strategy represents a load balancing strategy for provider instance selection.
@@ -43,6 +44,9 @@ type DefaultLoadBalancer struct {
 	db            *dbent.Client
 	encryptionKey []byte
 	counter       atomic.Uint64
+	// secrets resolves secret references (e.g. "vault://payment/stripe#secret_key")
+	// stored as provider config values; nil leaves values unchanged.
+	secrets *secrets.Resolver
 }
 
 type contextKey string
@@ -54,6 +58,11 @@ func NewDefaultLoadBalancer(db *dbent.Client, encryptionKey []byte) *DefaultLoad
 	return &DefaultLoadBalancer{db: db, encryptionKey: encryptionKey}
 }
 
+// SetSecretResolver enables secret references in provider config values.
+func (lb *DefaultLoadBalancer) SetSecretResolver(resolver *secrets.Resolver) {
+	lb.secrets = resolver
+}
+
 func WithWxpayJSAPIAppID(ctx context.Context, appID string) context.Context {
 	appID = strings.TrimSpace(appID)
 	if appID == "" {
@@ -111,7 +120,7 @@ func (lb *DefaultLoadBalancer) SelectInstance(
 
 	// Step 4: pick by strategy.
 	selected := lb.pickByStrategy(available, strategy)
-	return lb.buildSelection(selected.inst)
+	return lb.buildSelection(ctx, selected.inst)
 }
 
 // queryEnabledInstances returns enabled instances that support paymentType.
@@ -292,11 +301,14 @@ func pickLeastAmount(candidates []instanceCandidate) instanceCandidate {
 	return best
 }
 
-func (lb *DefaultLoadBalancer) buildSelection(selected *dbent.PaymentProviderInstance) (*InstanceSelection, error) {
+func (lb *DefaultLoadBalancer) buildSelection(ctx context.Context, selected *dbent.PaymentProviderInstance) (*InstanceSelection, error) {
 	config, err := lb.decryptConfig(selected.Config)
 	if err != nil {
 		return nil, fmt.Errorf("decrypt instance %d config: %w", selected.ID, err)
 	}
+	if config, err = lb.secrets.ResolveMap(ctx, config); err != nil {
+		return nil, fmt.Errorf("resolve instance %d config secrets: %w", selected.ID, err)
+	}
 	if config == nil {
 		config = map[string]string{}
 	}
@@ -425,5 +437,9 @@ func (lb *DefaultLoadBalancer) GetInstanceConfig(ctx context.Context, instanceID
 	if err != nil {
 		return nil, fmt.Errorf("get instance %d: %w", instanceID, err)
 	}
-	return lb.decryptConfig(inst.Config)
+	config, err := lb.decryptConfig(inst.Config)
+	if err != nil {
+		return nil, err
+	}
+	return lb.secrets.ResolveMap(ctx, config)
 }
@@ -8,6 +8,7 @@ import (
 
 	dbent "github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/secrets"
 	"github.com/google/wire"
 )
 
@@ -52,8 +53,11 @@ func ProvideRegistry() *Registry {
 }
 
 // ProvideDefaultLoadBalancer creates a DefaultLoadBalancer backed by the ent client.
-func ProvideDefaultLoadBalancer(client *dbent.Client, key EncryptionKey) *DefaultLoadBalancer {
-	return NewDefaultLoadBalancer(client, []byte(key))
+// Provider config values may be secret references resolved through resolver.
+func ProvideDefaultLoadBalancer(client *dbent.Client, key EncryptionKey, resolver *secrets.Resolver) *DefaultLoadBalancer {
+	lb := NewDefaultLoadBalancer(client, []byte(key))
+	lb.SetSecretResolver(resolver)
+	return lb
 }
 
 // ProviderSet is the Wire provider set for the payment package.
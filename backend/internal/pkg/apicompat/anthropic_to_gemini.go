@@ -0,0 +1,529 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// geminiDummyThoughtSignature is accepted by Gemini in place of a real
+// thoughtSignature. Gemini 3 rejects functionCall parts without one, and
+// tool_use blocks produced by other providers never carry a Gemini signature.
+const geminiDummyThoughtSignature = "skip_thought_signature_validator"
+
+// ---------------------------------------------------------------------------
+// Request: Anthropic Messages → Gemini generateContent
+// ---------------------------------------------------------------------------
+
+// AnthropicToGemini converts an Anthropic Messages request into a Gemini
+// generateContent request. The model and the stream flag are not part of the
+// Gemini body; callers put them into the upstream URL.
+func AnthropicToGemini(req *AnthropicRequest) (*GeminiRequest, error) {
+	out := &GeminiRequest{}
+
+	if len(req.System) > 0 {
+		sysParts, err := parseAnthropicSystemContentParts(req.System)
+		if err != nil {
+			return nil, fmt.Errorf("parse system: %w", err)
+		}
+		if len(sysParts) > 0 {
+			content := &GeminiContent{}
+			for _, p := range sysParts {
+				content.Parts = append(content.Parts, GeminiPart{Text: p.Text})
+			}
+			out.SystemInstruction = content
+		}
+	}
+
+	// tool_result 只携带 tool_use_id，Gemini 的 functionResponse 需要函数名，
+	// 先扫描全部 assistant 消息建立 id → name 映射。
+	toolNames := make(map[string]string)
+	for _, m := range req.Messages {
+		if m.Role != "assistant" {
+			continue
+		}
+		var blocks []AnthropicContentBlock
+		if json.Unmarshal(m.Content, &blocks) != nil {
+			continue
+		}
+		for _, b := range blocks {
+			if b.Type == "tool_use" && b.ID != "" {
+				toolNames[b.ID] = b.Name
+			}
+		}
+	}
+
+	for i, m := range req.Messages {
+		parts, err := anthropicContentToGeminiParts(m.Content, toolNames)
+		if err != nil {
+			return nil, fmt.Errorf("convert message %d: %w", i, err)
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		role := "user"
+		if m.Role == "assistant" {
+			role = "model"
+		}
+		out.Contents = append(out.Contents, GeminiContent{Role: role, Parts: parts})
+	}
+
+	out.Tools = convertAnthropicToolsToGemini(req.Tools)
+
+	if len(req.ToolChoice) > 0 {
+		tc, err := convertAnthropicToolChoiceToGemini(req.ToolChoice)
+		if err != nil {
+			return nil, fmt.Errorf("convert tool_choice: %w", err)
+		}
+		out.ToolConfig = tc
+	}
+
+	cfg := &GeminiGenerationConfig{
+		Temperature:   req.Temperature,
+		TopP:          req.TopP,
+		StopSequences: req.StopSeqs,
+	}
+	if req.MaxTokens > 0 {
+		cfg.MaxOutputTokens = req.MaxTokens
+	}
+	if req.Thinking != nil {
+		switch req.Thinking.Type {
+		case "enabled":
+			budget := req.Thinking.BudgetTokens
+			cfg.ThinkingConfig = &GeminiThinkingConfig{IncludeThoughts: true, ThinkingBudget: &budget}
+		case "adaptive":
+			dynamic := -1
+			cfg.ThinkingConfig = &GeminiThinkingConfig{IncludeThoughts: true, ThinkingBudget: &dynamic}
+		}
+	}
+	if cfg.MaxOutputTokens > 0 || cfg.Temperature != nil || cfg.TopP != nil || len(cfg.StopSequences) > 0 || cfg.ThinkingConfig != nil {
+		out.GenerationConfig = cfg
+	}
+
+	return out, nil
+}
+
+// anthropicContentToGeminiParts converts one Anthropic message content (string
+// or block array) into Gemini parts.
+func anthropicContentToGeminiParts(raw json.RawMessage, toolNames map[string]string) ([]GeminiPart, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if s == "" {
+			return nil, nil
+		}
+		return []GeminiPart{{Text: s}}, nil
+	}
+	var blocks []AnthropicContentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, err
+	}
+
+	// 多个 block 时过滤纯空白文本，与 Gemini 兼容链路保持一致。
+	singleBlock := len(blocks) == 1
+	var parts []GeminiPart
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			if b.Text != "" && (singleBlock || strings.TrimSpace(b.Text) != "") {
+				parts = append(parts, GeminiPart{Text: b.Text})
+			}
+		case "thinking":
+			// 没有签名的思考内容无法回传给 Gemini，直接丢弃。
+			if b.Signature != "" {
+				parts = append(parts, GeminiPart{Text: b.Thinking, Thought: true, ThoughtSignature: b.Signature})
+			}
+		case "image", "document":
+			if part, ok := anthropicSourceToGeminiPart(b.Source); ok {
+				parts = append(parts, part)
+			}
+		case "tool_use":
+			signature := strings.TrimSpace(b.Signature)
+			if signature == "" {
+				signature = geminiDummyThoughtSignature
+			}
+			parts = append(parts, GeminiPart{
+				ThoughtSignature: signature,
+				FunctionCall: &GeminiFunctionCall{
+					Name: b.Name,
+					Args: normalizeGeminiArgs(b.Input),
+				},
+			})
+		case "tool_result":
+			name := toolNames[b.ToolUseID]
+			if name == "" {
+				name = "tool"
+			}
+			text, images := convertToolResultOutput(b)
+			key := "content"
+			if b.IsError {
+				key = "error"
+			}
+			response, err := json.Marshal(map[string]string{key: text})
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, GeminiPart{FunctionResponse: &GeminiFunctionResponse{Name: name, Response: response}})
+			for _, img := range images {
+				if part, ok := dataURIToGeminiPart(img.ImageURL); ok {
+					parts = append(parts, part)
+				}
+			}
+		}
+	}
+	return parts, nil
+}
+
+// anthropicSourceToGeminiPart converts an image/document source into an
+// inlineData part. Plain-text document sources become text parts.
+func anthropicSourceToGeminiPart(src *AnthropicImageSource) (GeminiPart, bool) {
+	if src == nil || src.Data == "" {
+		return GeminiPart{}, false
+	}
+	if src.Type == "text" {
+		return GeminiPart{Text: src.Data}, true
+	}
+	mediaType := src.MediaType
+	if mediaType == "" {
+		mediaType = "image/png"
+	}
+	return GeminiPart{InlineData: &GeminiBlob{MimeType: mediaType, Data: src.Data}}, true
+}
+
+// dataURIToGeminiPart converts a base64 data URI into an inlineData part.
+func dataURIToGeminiPart(uri string) (GeminiPart, bool) {
+	rest, ok := strings.CutPrefix(uri, "data:")
+	if !ok {
+		return GeminiPart{}, false
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	mediaType, isBase64 := strings.CutSuffix(meta, ";base64")
+	if !ok || !isBase64 || data == "" {
+		return GeminiPart{}, false
+	}
+	return GeminiPart{InlineData: &GeminiBlob{MimeType: mediaType, Data: data}}, true
+}
+
+// normalizeGeminiArgs returns a JSON object for functionCall.args; Gemini
+// rejects null or missing args.
+func normalizeGeminiArgs(raw json.RawMessage) json.RawMessage {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" || !json.Valid(raw) {
+		return json.RawMessage("{}")
+	}
+	return raw
+}
+
+// convertAnthropicToolsToGemini maps custom tools to functionDeclarations and
+// Anthropic web search server tools to Gemini's googleSearch. Only explicitly
+// typed search tools are promoted; a client function named web_search stays a
+// function.
+func convertAnthropicToolsToGemini(tools []AnthropicTool) []GeminiTool {
+	var decls []GeminiFunctionDeclaration
+	googleSearch := false
+	for _, t := range tools {
+		if strings.HasPrefix(t.Type, "web_search") || t.Type == "google_search" {
+			googleSearch = true
+			continue
+		}
+		if t.Type != "" && t.Type != "custom" {
+			// 其他 Anthropic server tool（bash、text_editor 等）在 Gemini 中没有对应物。
+			continue
+		}
+		if strings.TrimSpace(t.Name) == "" {
+			continue
+		}
+		description, schema := t.Description, t.InputSchema
+		if t.Custom != nil {
+			description, schema = t.Custom.Description, t.Custom.InputSchema
+		} else if t.Type == "custom" && len(schema) == 0 {
+			// MCP custom 工具缺少 custom 描述且没有顶层 schema，视为无效定义。
+			continue
+		}
+		decls = append(decls, GeminiFunctionDeclaration{
+			Name:        t.Name,
+			Description: description,
+			Parameters:  cleanGeminiSchema(schema),
+		})
+	}
+
+	var out []GeminiTool
+	if len(decls) > 0 {
+		out = append(out, GeminiTool{FunctionDeclarations: decls})
+	}
+	if googleSearch {
+		out = append(out, GeminiTool{GoogleSearch: json.RawMessage("{}")})
+	}
+	return out
+}
+
+// convertAnthropicToolChoiceToGemini maps Anthropic tool_choice to Gemini's
+// functionCallingConfig.
+//
+//	{"type":"auto"}                   → AUTO
+//	{"type":"any"}                    → ANY
+//	{"type":"tool","name":"X"}        → ANY + allowedFunctionNames ["X"]
+//	{"type":"none"}                   → NONE
+func convertAnthropicToolChoiceToGemini(raw json.RawMessage) (*GeminiToolConfig, error) {
+	var tc struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &tc); err != nil {
+		return nil, err
+	}
+
+	cfg := &GeminiFunctionCallingConfig{}
+	switch tc.Type {
+	case "auto":
+		cfg.Mode = "AUTO"
+	case "any":
+		cfg.Mode = "ANY"
+	case "tool":
+		cfg.Mode = "ANY"
+		cfg.AllowedFunctionNames = []string{tc.Name}
+	case "none":
+		cfg.Mode = "NONE"
+	default:
+		return nil, nil
+	}
+	return &GeminiToolConfig{FunctionCallingConfig: cfg}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Response: Anthropic Messages → Gemini generateContent (non-streaming)
+// ---------------------------------------------------------------------------
+
+// AnthropicToGeminiResponse converts a non-streaming Anthropic response into
+// a Gemini generateContent response.
+func AnthropicToGeminiResponse(resp *AnthropicResponse) *GeminiResponse {
+	var parts []GeminiPart
+	for _, b := range resp.Content {
+		switch b.Type {
+		case "text":
+			if b.Text != "" {
+				parts = append(parts, GeminiPart{Text: b.Text})
+			}
+		case "thinking":
+			if b.Thinking != "" || b.Signature != "" {
+				parts = append(parts, GeminiPart{Text: b.Thinking, Thought: true, ThoughtSignature: b.Signature})
+			}
+		case "tool_use":
+			parts = append(parts, GeminiPart{FunctionCall: &GeminiFunctionCall{
+				ID:   b.ID,
+				Name: b.Name,
+				Args: normalizeGeminiArgs(b.Input),
+			}})
+		}
+	}
+	if parts == nil {
+		parts = []GeminiPart{}
+	}
+
+	return &GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content:      &GeminiContent{Role: "model", Parts: parts},
+			FinishReason: anthropicStopReasonToGeminiFinishReason(AnthropicStopReasonString(resp.StopReason)),
+		}},
+		UsageMetadata: anthropicUsageToGeminiUsage(resp.Usage),
+		ModelVersion:  resp.Model,
+		ResponseID:    resp.ID,
+	}
+}
+
+// anthropicStopReasonToGeminiFinishReason maps an Anthropic stop_reason to a
+// Gemini finishReason. Gemini reports function calls with STOP.
+func anthropicStopReasonToGeminiFinishReason(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return "MAX_TOKENS"
+	case "refusal":
+		return "SAFETY"
+	default:
+		return "STOP"
+	}
+}
+
+// anthropicUsageToGeminiUsage converts Anthropic usage (input excludes cached
+// tokens) into Gemini usageMetadata (promptTokenCount includes them).
+func anthropicUsageToGeminiUsage(u AnthropicUsage) *GeminiUsageMetadata {
+	prompt := u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+	return &GeminiUsageMetadata{
+		PromptTokenCount:        prompt,
+		CandidatesTokenCount:    u.OutputTokens,
+		CachedContentTokenCount: u.CacheReadInputTokens,
+		TotalTokenCount:         prompt + u.OutputTokens,
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: AnthropicStreamEvent → []GeminiResponse (stateful converter)
+// ---------------------------------------------------------------------------
+
+// AnthropicEventToGeminiState tracks state for converting a sequence of
+// Anthropic SSE events into Gemini streamGenerateContent chunks.
+type AnthropicEventToGeminiState struct {
+	ResponseID string
+	Model      string
+
+	// FinishSent tracks whether the terminal chunk has been emitted.
+	FinishSent bool
+
+	// Current content block. tool_use arguments are buffered until
+	// content_block_stop because Gemini sends each functionCall whole.
+	CurrentBlockType string
+	CurrentToolID    string
+	CurrentToolName  string
+	CurrentToolArgs  strings.Builder
+
+	StopReason string
+	Usage      AnthropicUsage
+}
+
+// NewAnthropicEventToGeminiState returns an initialised stream state.
+func NewAnthropicEventToGeminiState() *AnthropicEventToGeminiState {
+	return &AnthropicEventToGeminiState{}
+}
+
+// AnthropicEventToGeminiChunks converts a single Anthropic SSE event into zero
+// or more Gemini stream chunks, updating state as it goes.
+func AnthropicEventToGeminiChunks(evt *AnthropicStreamEvent, state *AnthropicEventToGeminiState) []GeminiResponse {
+	switch evt.Type {
+	case "message_start":
+		if evt.Message != nil {
+			state.ResponseID = evt.Message.ID
+			if state.Model == "" {
+				state.Model = evt.Message.Model
+			}
+			state.Usage = evt.Message.Usage
+		}
+		return nil
+	case "content_block_start":
+		if evt.ContentBlock == nil {
+			return nil
+		}
+		state.CurrentBlockType = evt.ContentBlock.Type
+		if evt.ContentBlock.Type != "tool_use" {
+			if evt.ContentBlock.Type == "text" && evt.ContentBlock.Text != "" {
+				return []GeminiResponse{anthToGeminiChunk(state, GeminiPart{Text: evt.ContentBlock.Text})}
+			}
+			return nil
+		}
+		state.CurrentToolID = evt.ContentBlock.ID
+		state.CurrentToolName = evt.ContentBlock.Name
+		state.CurrentToolArgs.Reset()
+		if input := strings.TrimSpace(string(evt.ContentBlock.Input)); input != "" && input != "{}" {
+			state.CurrentToolArgs.WriteString(input)
+		}
+		return nil
+	case "content_block_delta":
+		return anthToGeminiHandleDelta(evt, state)
+	case "content_block_stop":
+		blockType := state.CurrentBlockType
+		state.CurrentBlockType = ""
+		if blockType != "tool_use" {
+			return nil
+		}
+		call := &GeminiFunctionCall{
+			ID:   state.CurrentToolID,
+			Name: state.CurrentToolName,
+			Args: normalizeGeminiArgs(json.RawMessage(state.CurrentToolArgs.String())),
+		}
+		state.CurrentToolArgs.Reset()
+		return []GeminiResponse{anthToGeminiChunk(state, GeminiPart{FunctionCall: call})}
+	case "message_delta":
+		if evt.Delta != nil && evt.Delta.StopReason != "" {
+			state.StopReason = evt.Delta.StopReason
+		}
+		if evt.Usage != nil {
+			mergeAnthropicStreamUsage(&state.Usage, evt.Usage)
+		}
+		return nil
+	case "message_stop":
+		return FinalizeAnthropicGeminiStream(state)
+	default:
+		return nil
+	}
+}
+
+// FinalizeAnthropicGeminiStream emits the terminal chunk carrying
+// finishReason and usageMetadata if it has not been sent yet.
+func FinalizeAnthropicGeminiStream(state *AnthropicEventToGeminiState) []GeminiResponse {
+	if state.FinishSent {
+		return nil
+	}
+	state.FinishSent = true
+	return []GeminiResponse{{
+		Candidates: []GeminiCandidate{{
+			FinishReason: anthropicStopReasonToGeminiFinishReason(state.StopReason),
+		}},
+		UsageMetadata: anthropicUsageToGeminiUsage(state.Usage),
+		ModelVersion:  state.Model,
+		ResponseID:    state.ResponseID,
+	}}
+}
+
+// GeminiChunkToSSE formats a Gemini stream chunk as an SSE data line
+// (streamGenerateContent?alt=sse has no event names).
+func GeminiChunkToSSE(chunk GeminiResponse) (string, error) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data: %s\n\n", data), nil
+}
+
+func anthToGeminiHandleDelta(evt *AnthropicStreamEvent, state *AnthropicEventToGeminiState) []GeminiResponse {
+	if evt.Delta == nil {
+		return nil
+	}
+	switch evt.Delta.Type {
+	case "text_delta":
+		if evt.Delta.Text == "" {
+			return nil
+		}
+		return []GeminiResponse{anthToGeminiChunk(state, GeminiPart{Text: evt.Delta.Text})}
+	case "thinking_delta":
+		if evt.Delta.Thinking == "" {
+			return nil
+		}
+		return []GeminiResponse{anthToGeminiChunk(state, GeminiPart{Text: evt.Delta.Thinking, Thought: true})}
+	case "signature_delta":
+		if evt.Delta.Signature == "" {
+			return nil
+		}
+		return []GeminiResponse{anthToGeminiChunk(state, GeminiPart{Thought: true, ThoughtSignature: evt.Delta.Signature})}
+	case "input_json_delta":
+		state.CurrentToolArgs.WriteString(evt.Delta.PartialJSON)
+		return nil
+	default:
+		return nil
+	}
+}
+
+func anthToGeminiChunk(state *AnthropicEventToGeminiState, part GeminiPart) GeminiResponse {
+	return GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content: &GeminiContent{Role: "model", Parts: []GeminiPart{part}},
+		}},
+		ModelVersion: state.Model,
+		ResponseID:   state.ResponseID,
+	}
+}
+
+// mergeAnthropicStreamUsage folds message_delta usage into the running total.
+// message_delta reports cumulative output tokens; input counts are only
+// overwritten when the upstream actually reports them.
+func mergeAnthropicStreamUsage(dst *AnthropicUsage, src *AnthropicUsage) {
+	if src.InputTokens > 0 {
+		dst.InputTokens = src.InputTokens
+	}
+	if src.OutputTokens > 0 {
+		dst.OutputTokens = src.OutputTokens
+	}
+	if src.CacheReadInputTokens > 0 {
+		dst.CacheReadInputTokens = src.CacheReadInputTokens
+	}
+	if src.CacheCreationInputTokens > 0 {
+		dst.CacheCreationInputTokens = src.CacheCreationInputTokens
+	}
+}
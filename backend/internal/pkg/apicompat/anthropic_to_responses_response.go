@@ -63,6 +63,13 @@ func AnthropicToResponsesResponse(resp *AnthropicResponse) *ResponsesResponse {
 				Arguments: args,
 				Status:    "completed",
 			})
+		case "server_tool_use":
+			outputs = append(outputs, ResponsesOutput{
+				Type:   "web_search_call",
+				ID:     generateItemID(),
+				Status: "completed",
+				Action: webSearchActionFromServerToolUse(block.Name, block.Input),
+			})
 		}
 	}
 
@@ -116,6 +123,22 @@ func AnthropicToResponsesResponse(resp *AnthropicResponse) *ResponsesResponse {
 	return out
 }
 
+// webSearchActionFromServerToolUse maps an Anthropic web_search / web_fetch
+// server tool call to the action of a Responses web_search_call item. The tool
+// results themselves have no Responses equivalent and are dropped; the model's
+// text output carries what it learned from them.
+func webSearchActionFromServerToolUse(name string, input json.RawMessage) *WebSearchAction {
+	var args struct {
+		Query string `json:"query"`
+		URL   string `json:"url"`
+	}
+	_ = json.Unmarshal(input, &args)
+	if name == "web_fetch" {
+		return &WebSearchAction{Type: "open_page", URL: args.URL}
+	}
+	return &WebSearchAction{Type: "search", Query: args.Query}
+}
+
 // anthropicStopReasonToResponsesStatus maps Anthropic stop_reason to Responses status.
 func anthropicStopReasonToResponsesStatus(stopReason string, blocks []AnthropicContentBlock) string {
 	switch stopReason {
@@ -148,7 +171,7 @@ type AnthropicEventToResponsesState struct {
 	// Current output tracking
 	OutputIndex     int
 	CurrentItemID   string
-	CurrentItemType string // "message" | "function_call" | "reasoning"
+	CurrentItemType string // "message" | "function_call" | "reasoning" | "web_search_call"
 
 	// For message output: accumulate text parts
 	ContentIndex int
@@ -164,6 +187,7 @@ type AnthropicEventToResponsesState struct {
 	CurrentContent []ResponsesContentPart // message
 	CurrentArgs    string                 // function_call
 	CurrentSummary string                 // reasoning
+	CurrentAction  *WebSearchAction       // web_search_call
 
 	// Outputs accumulates every closed output item so that response.completed
 	// can carry the full output list. The OpenAI SDK's get_final_response()
@@ -342,6 +366,23 @@ func anthToResHandleContentBlockStart(evt *AnthropicStreamEvent, state *Anthropi
 				Status: "in_progress",
 			},
 		}))
+
+	case "server_tool_use":
+		events = append(events, closeCurrentResponsesItem(state)...)
+
+		state.CurrentItemID = generateItemID()
+		state.CurrentItemType = "web_search_call"
+		state.CurrentAction = webSearchActionFromServerToolUse(evt.ContentBlock.Name, evt.ContentBlock.Input)
+
+		events = append(events, makeResponsesEvent(state, "response.output_item.added", &ResponsesStreamEvent{
+			OutputIndex: state.OutputIndex,
+			Item: &ResponsesOutput{
+				Type:   "web_search_call",
+				ID:     state.CurrentItemID,
+				Status: "in_progress",
+				Action: state.CurrentAction,
+			},
+		}))
 	}
 
 	return events
@@ -425,6 +466,9 @@ func anthToResHandleContentBlockStop(evt *AnthropicStreamEvent, state *Anthropic
 		events = append(events, closeCurrentResponsesItem(state)...)
 		return events
 
+	case "web_search_call":
+		return closeCurrentResponsesItem(state)
+
 	case "message":
 		// Text block is done: emit output_text.done then content_part.done (the
 		// order OpenAI uses), both carrying the part's full text. The message
@@ -523,6 +567,8 @@ func closeCurrentResponsesItem(state *AnthropicEventToResponsesState) []Response
 		if state.CurrentSummary != "" {
 			item.Summary = []ResponsesSummary{{Type: "summary_text", Text: state.CurrentSummary}}
 		}
+	case "web_search_call":
+		item.Action = state.CurrentAction
 	}
 	state.Outputs = append(state.Outputs, item)
 
@@ -534,6 +580,7 @@ func closeCurrentResponsesItem(state *AnthropicEventToResponsesState) []Response
 	state.CurrentContent = nil
 	state.CurrentArgs = ""
 	state.CurrentSummary = ""
+	state.CurrentAction = nil
 	state.TextAccum = ""
 	state.OutputIndex++
 	state.ContentIndex = 0
@@ -185,3 +185,52 @@ func TestAnthropicEventToResponses_ToolCallCompletedCarriesArguments(t *testing.
 		t.Errorf("name = %q, want get_weather", fc.Name)
 	}
 }
+
+// TestAnthropicEventToResponses_ServerToolUseBecomesWebSearchCall pins that
+// web_search / web_fetch server tool calls surface as web_search_call items
+// (search / open_page actions) while their result blocks are dropped.
+func TestAnthropicEventToResponses_ServerToolUseBecomesWebSearchCall(t *testing.T) {
+	state := NewAnthropicEventToResponsesState()
+	state.Model = "claude-sonnet-4-5"
+
+	var events []ResponsesStreamEvent
+	feed := func(evt *AnthropicStreamEvent) {
+		events = append(events, AnthropicEventToResponsesEvents(evt, state)...)
+	}
+
+	idx0, idx1, idx2 := 0, 1, 2
+	feed(&AnthropicStreamEvent{Type: "message_start", Message: &AnthropicResponse{ID: "msg_1"}})
+	feed(&AnthropicStreamEvent{Type: "content_block_start", Index: &idx0, ContentBlock: &AnthropicContentBlock{
+		Type: "server_tool_use", ID: "srvtoolu_1", Name: "web_fetch", Input: []byte(`{"url":"https://example.com/a"}`),
+	}})
+	feed(&AnthropicStreamEvent{Type: "content_block_stop", Index: &idx0})
+	feed(&AnthropicStreamEvent{Type: "content_block_start", Index: &idx1, ContentBlock: &AnthropicContentBlock{
+		Type: "web_fetch_tool_result", ToolUseID: "srvtoolu_1", Content: []byte(`{"type":"web_fetch_result"}`),
+	}})
+	feed(&AnthropicStreamEvent{Type: "content_block_stop", Index: &idx1})
+	feed(&AnthropicStreamEvent{Type: "content_block_start", Index: &idx2, ContentBlock: &AnthropicContentBlock{Type: "text"}})
+	feed(&AnthropicStreamEvent{Type: "content_block_delta", Index: &idx2, Delta: &AnthropicDelta{Type: "text_delta", Text: "page text"}})
+	feed(&AnthropicStreamEvent{Type: "content_block_stop", Index: &idx2})
+	feed(&AnthropicStreamEvent{Type: "message_stop"})
+
+	var completed *ResponsesStreamEvent
+	for i := range events {
+		if events[i].Type == "response.completed" {
+			completed = &events[i]
+		}
+	}
+	if completed == nil || completed.Response == nil || len(completed.Response.Output) != 2 {
+		t.Fatalf("response.completed output = %+v, want web_search_call + message", completed)
+	}
+	call := completed.Response.Output[0]
+	if call.Type != "web_search_call" || call.Status != "completed" || call.Action == nil {
+		t.Fatalf("output[0] = %+v, want a completed web_search_call", call)
+	}
+	if call.Action.Type != "open_page" || call.Action.URL != "https://example.com/a" {
+		t.Errorf("action = %+v, want open_page https://example.com/a", call.Action)
+	}
+	msg := completed.Response.Output[1]
+	if msg.Type != "message" || len(msg.Content) != 1 || msg.Content[0].Text != "page text" {
+		t.Errorf("output[1] = %+v, want the text message", msg)
+	}
+}
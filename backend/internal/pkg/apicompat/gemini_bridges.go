@@ -0,0 +1,236 @@
+package apicompat
+
+// Gemini ↔ Responses / Chat Completions conversions are composed through the
+// Anthropic Messages converters: Gemini is translated to and from Anthropic
+// directly, and the existing Anthropic ↔ Responses ↔ Chat Completions chain
+// covers the rest. Keeping a single hub avoids a second copy of the tool,
+// thinking and image mapping rules for every format pair.
+
+// ---------------------------------------------------------------------------
+// Requests
+// ---------------------------------------------------------------------------
+
+// ResponsesToGemini converts a Responses API request into a Gemini
+// generateContent request.
+func ResponsesToGemini(req *ResponsesRequest) (*GeminiRequest, error) {
+	anthropicReq, err := ResponsesToAnthropicRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	out, err := AnthropicToGemini(anthropicReq)
+	if err != nil {
+		return nil, err
+	}
+	// ResponsesToAnthropicRequest 为满足 Anthropic 必填项补了默认 max_tokens，
+	// 客户端未指定时不应把这个默认值传给 Gemini。
+	if (req.MaxOutputTokens == nil || *req.MaxOutputTokens <= 0) && out.GenerationConfig != nil {
+		out.GenerationConfig.MaxOutputTokens = 0
+	}
+	return out, nil
+}
+
+// ChatCompletionsToGemini converts a Chat Completions request into a Gemini
+// generateContent request.
+func ChatCompletionsToGemini(req *ChatCompletionsRequest) (*GeminiRequest, error) {
+	responsesReq, err := ChatCompletionsToResponses(req)
+	if err != nil {
+		return nil, err
+	}
+	return ResponsesToGemini(responsesReq)
+}
+
+// GeminiToResponsesRequest converts a Gemini generateContent request into a
+// Responses API request.
+func GeminiToResponsesRequest(req *GeminiRequest, model string, stream bool) (*ResponsesRequest, error) {
+	anthropicReq, err := GeminiToAnthropicRequest(req, model, stream)
+	if err != nil {
+		return nil, err
+	}
+	return AnthropicToResponses(anthropicReq)
+}
+
+// GeminiToChatCompletionsRequest converts a Gemini generateContent request
+// into a Chat Completions request.
+func GeminiToChatCompletionsRequest(req *GeminiRequest, model string, stream bool) (*ChatCompletionsRequest, error) {
+	anthropicReq, err := GeminiToAnthropicRequest(req, model, stream)
+	if err != nil {
+		return nil, err
+	}
+	return AnthropicToChatCompletionsRequest(anthropicReq)
+}
+
+// ---------------------------------------------------------------------------
+// Non-streaming responses
+// ---------------------------------------------------------------------------
+
+// GeminiResponseToResponses converts a Gemini response into a Responses API
+// response.
+func GeminiResponseToResponses(resp *GeminiResponse, model string) *ResponsesResponse {
+	return AnthropicToResponsesResponse(GeminiResponseToAnthropic(resp, model))
+}
+
+// GeminiResponseToChatCompletions converts a Gemini response into a Chat
+// Completions response.
+func GeminiResponseToChatCompletions(resp *GeminiResponse, model string) *ChatCompletionsResponse {
+	return ResponsesToChatCompletions(GeminiResponseToResponses(resp, model), model)
+}
+
+// ResponsesToGeminiResponse converts a Responses API response into a Gemini
+// response.
+func ResponsesToGeminiResponse(resp *ResponsesResponse, model string) *GeminiResponse {
+	return AnthropicToGeminiResponse(ResponsesToAnthropic(resp, model))
+}
+
+// ChatCompletionsResponseToGemini converts a Chat Completions response into a
+// Gemini response.
+func ChatCompletionsResponseToGemini(resp *ChatCompletionsResponse, model string) *GeminiResponse {
+	return AnthropicToGeminiResponse(ChatCompletionsResponseToAnthropic(resp, model))
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: Gemini chunks → Responses events / Chat chunks
+// ---------------------------------------------------------------------------
+
+// GeminiToResponsesStreamState chains Gemini → Anthropic → Responses.
+type GeminiToResponsesStreamState struct {
+	Anthropic *GeminiToAnthropicStreamState
+	Responses *AnthropicEventToResponsesState
+}
+
+// NewGeminiToResponsesStreamState returns an initialised stream state.
+func NewGeminiToResponsesStreamState(model string) *GeminiToResponsesStreamState {
+	responses := NewAnthropicEventToResponsesState()
+	responses.Model = model
+	return &GeminiToResponsesStreamState{
+		Anthropic: NewGeminiToAnthropicStreamState(model),
+		Responses: responses,
+	}
+}
+
+// GeminiChunkToResponsesEvents converts one Gemini stream chunk into zero or
+// more Responses SSE events.
+func GeminiChunkToResponsesEvents(chunk *GeminiResponse, state *GeminiToResponsesStreamState) []ResponsesStreamEvent {
+	return anthropicEventsToResponses(GeminiChunkToAnthropicEvents(chunk, state.Anthropic), state.Responses)
+}
+
+// FinalizeGeminiResponsesStream emits the terminal Responses events when the
+// Gemini stream ends.
+func FinalizeGeminiResponsesStream(state *GeminiToResponsesStreamState) []ResponsesStreamEvent {
+	events := anthropicEventsToResponses(FinalizeGeminiAnthropicStream(state.Anthropic), state.Responses)
+	return append(events, FinalizeAnthropicResponsesStream(state.Responses)...)
+}
+
+// GeminiToChatStreamState chains Gemini → Anthropic → Responses → Chat.
+type GeminiToChatStreamState struct {
+	Responses *GeminiToResponsesStreamState
+	Chat      *ResponsesEventToChatState
+}
+
+// NewGeminiToChatStreamState returns an initialised stream state.
+func NewGeminiToChatStreamState(model string) *GeminiToChatStreamState {
+	chat := NewResponsesEventToChatState()
+	chat.Model = model
+	return &GeminiToChatStreamState{
+		Responses: NewGeminiToResponsesStreamState(model),
+		Chat:      chat,
+	}
+}
+
+// GeminiChunkToChatChunks converts one Gemini stream chunk into zero or more
+// Chat Completions chunks.
+func GeminiChunkToChatChunks(chunk *GeminiResponse, state *GeminiToChatStreamState) []ChatCompletionsChunk {
+	return responsesEventsToChat(GeminiChunkToResponsesEvents(chunk, state.Responses), state.Chat)
+}
+
+// FinalizeGeminiChatStream emits the terminal Chat Completions chunk when the
+// Gemini stream ends.
+func FinalizeGeminiChatStream(state *GeminiToChatStreamState) []ChatCompletionsChunk {
+	chunks := responsesEventsToChat(FinalizeGeminiResponsesStream(state.Responses), state.Chat)
+	return append(chunks, FinalizeResponsesChatStream(state.Chat)...)
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: Responses events / Chat chunks → Gemini chunks
+// ---------------------------------------------------------------------------
+
+// ResponsesToGeminiStreamState chains Responses → Anthropic → Gemini.
+type ResponsesToGeminiStreamState struct {
+	Anthropic *ResponsesEventToAnthropicState
+	Gemini    *AnthropicEventToGeminiState
+}
+
+// NewResponsesToGeminiStreamState returns an initialised stream state.
+func NewResponsesToGeminiStreamState(model string) *ResponsesToGeminiStreamState {
+	anthropic := NewResponsesEventToAnthropicState()
+	anthropic.Model = model
+	gemini := NewAnthropicEventToGeminiState()
+	gemini.Model = model
+	return &ResponsesToGeminiStreamState{Anthropic: anthropic, Gemini: gemini}
+}
+
+// ResponsesEventToGeminiChunks converts one Responses SSE event into zero or
+// more Gemini stream chunks.
+func ResponsesEventToGeminiChunks(evt *ResponsesStreamEvent, state *ResponsesToGeminiStreamState) []GeminiResponse {
+	return anthropicEventsToGemini(ResponsesEventToAnthropicEvents(evt, state.Anthropic), state.Gemini)
+}
+
+// FinalizeResponsesGeminiStream emits the terminal Gemini chunk when the
+// Responses stream ends.
+func FinalizeResponsesGeminiStream(state *ResponsesToGeminiStreamState) []GeminiResponse {
+	chunks := anthropicEventsToGemini(FinalizeResponsesAnthropicStream(state.Anthropic), state.Gemini)
+	return append(chunks, FinalizeAnthropicGeminiStream(state.Gemini)...)
+}
+
+// ChatCompletionsToGeminiStreamState chains Chat Completions → Anthropic →
+// Gemini.
+type ChatCompletionsToGeminiStreamState struct {
+	Anthropic *ChatCompletionsToAnthropicStreamState
+	Gemini    *AnthropicEventToGeminiState
+}
+
+// NewChatCompletionsToGeminiStreamState returns an initialised stream state.
+func NewChatCompletionsToGeminiStreamState(model string) *ChatCompletionsToGeminiStreamState {
+	gemini := NewAnthropicEventToGeminiState()
+	gemini.Model = model
+	return &ChatCompletionsToGeminiStreamState{
+		Anthropic: NewChatCompletionsToAnthropicStreamState(model),
+		Gemini:    gemini,
+	}
+}
+
+// ChatCompletionsChunkToGeminiChunks converts one Chat Completions stream
+// chunk into zero or more Gemini stream chunks.
+func ChatCompletionsChunkToGeminiChunks(chunk *ChatCompletionsChunk, state *ChatCompletionsToGeminiStreamState) []GeminiResponse {
+	return anthropicEventsToGemini(ChatCompletionsChunkToAnthropicEvents(chunk, state.Anthropic), state.Gemini)
+}
+
+// FinalizeChatCompletionsGeminiStream emits the terminal Gemini chunk when
+// the Chat Completions stream ends.
+func FinalizeChatCompletionsGeminiStream(state *ChatCompletionsToGeminiStreamState) []GeminiResponse {
+	chunks := anthropicEventsToGemini(FinalizeChatCompletionsAnthropicStream(state.Anthropic), state.Gemini)
+	return append(chunks, FinalizeAnthropicGeminiStream(state.Gemini)...)
+}
+
+func anthropicEventsToResponses(events []AnthropicStreamEvent, state *AnthropicEventToResponsesState) []ResponsesStreamEvent {
+	var out []ResponsesStreamEvent
+	for i := range events {
+		out = append(out, AnthropicEventToResponsesEvents(&events[i], state)...)
+	}
+	return out
+}
+
+func responsesEventsToChat(events []ResponsesStreamEvent, state *ResponsesEventToChatState) []ChatCompletionsChunk {
+	var out []ChatCompletionsChunk
+	for i := range events {
+		out = append(out, ResponsesEventToChatChunks(&events[i], state)...)
+	}
+	return out
+}
+
+func anthropicEventsToGemini(events []AnthropicStreamEvent, state *AnthropicEventToGeminiState) []GeminiResponse {
+	var out []GeminiResponse
+	for i := range events {
+		out = append(out, AnthropicEventToGeminiChunks(&events[i], state)...)
+	}
+	return out
+}
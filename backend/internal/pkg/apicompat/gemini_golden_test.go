@@ -0,0 +1,210 @@
+package apicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/gemini golden files")
+
+// geminiGoldenConverters maps each testdata/gemini/<kind> directory to the
+// conversion under test. Every <case>.in.json is converted and compared with
+// <case>.golden.json; run `go test -run TestGeminiGolden -update` to refresh.
+var geminiGoldenConverters = map[string]func(t *testing.T, in []byte) any{
+	"anthropic_to_gemini_request": func(t *testing.T, in []byte) any {
+		var req AnthropicRequest
+		require.NoError(t, json.Unmarshal(in, &req))
+		out, err := AnthropicToGemini(&req)
+		require.NoError(t, err)
+		return out
+	},
+	"gemini_to_anthropic_request": func(t *testing.T, in []byte) any {
+		var req GeminiRequest
+		require.NoError(t, json.Unmarshal(in, &req))
+		out, err := GeminiToAnthropicRequest(&req, "claude-sonnet-4-5", true)
+		require.NoError(t, err)
+		return out
+	},
+	"chat_to_gemini_request": func(t *testing.T, in []byte) any {
+		var req ChatCompletionsRequest
+		require.NoError(t, json.Unmarshal(in, &req))
+		out, err := ChatCompletionsToGemini(&req)
+		require.NoError(t, err)
+		return out
+	},
+	"gemini_to_responses_request": func(t *testing.T, in []byte) any {
+		var req GeminiRequest
+		require.NoError(t, json.Unmarshal(in, &req))
+		out, err := GeminiToResponsesRequest(&req, "gpt-5.2", true)
+		require.NoError(t, err)
+		return out
+	},
+	"gemini_to_anthropic_response": func(t *testing.T, in []byte) any {
+		resp, err := ParseGeminiResponse(in)
+		require.NoError(t, err)
+		return GeminiResponseToAnthropic(resp, "gemini-2.5-pro")
+	},
+	"anthropic_to_gemini_response": func(t *testing.T, in []byte) any {
+		var resp AnthropicResponse
+		require.NoError(t, json.Unmarshal(in, &resp))
+		return AnthropicToGeminiResponse(&resp)
+	},
+	"gemini_to_chat_response": func(t *testing.T, in []byte) any {
+		resp, err := ParseGeminiResponse(in)
+		require.NoError(t, err)
+		return GeminiResponseToChatCompletions(resp, "gemini-2.5-pro")
+	},
+	"gemini_to_anthropic_stream": func(t *testing.T, in []byte) any {
+		var chunks []json.RawMessage
+		require.NoError(t, json.Unmarshal(in, &chunks))
+		state := NewGeminiToAnthropicStreamState("gemini-2.5-pro")
+		var events []AnthropicStreamEvent
+		for _, raw := range chunks {
+			chunk, err := ParseGeminiResponse(raw)
+			require.NoError(t, err)
+			events = append(events, GeminiChunkToAnthropicEvents(chunk, state)...)
+		}
+		return append(events, FinalizeGeminiAnthropicStream(state)...)
+	},
+	"anthropic_to_gemini_stream": func(t *testing.T, in []byte) any {
+		var events []AnthropicStreamEvent
+		require.NoError(t, json.Unmarshal(in, &events))
+		state := NewAnthropicEventToGeminiState()
+		var chunks []GeminiResponse
+		for i := range events {
+			chunks = append(chunks, AnthropicEventToGeminiChunks(&events[i], state)...)
+		}
+		return append(chunks, FinalizeAnthropicGeminiStream(state)...)
+	},
+	"gemini_to_chat_stream": func(t *testing.T, in []byte) any {
+		var chunks []GeminiResponse
+		require.NoError(t, json.Unmarshal(in, &chunks))
+		state := NewGeminiToChatStreamState("gemini-2.5-pro")
+		var out []ChatCompletionsChunk
+		for i := range chunks {
+			out = append(out, GeminiChunkToChatChunks(&chunks[i], state)...)
+		}
+		return append(out, FinalizeGeminiChatStream(state)...)
+	},
+}
+
+var (
+	// geminiGoldenRandomID matches generated IDs. Each distinct ID is replaced
+	// by an ordinal placeholder so goldens still show which IDs pair up.
+	geminiGoldenRandomID = regexp.MustCompile(`(msg_|toolu_|resp_|item_|fc_|chatcmpl-)[0-9a-f]{24}`)
+	geminiGoldenCreated  = regexp.MustCompile(`"(created|created_at)": [0-9]+`)
+)
+
+func scrubGeminiGolden(s string) string {
+	ids := map[string]string{}
+	s = geminiGoldenRandomID.ReplaceAllStringFunc(s, func(id string) string {
+		if _, ok := ids[id]; !ok {
+			ids[id] = fmt.Sprintf("<id%d>", len(ids)+1)
+		}
+		return geminiGoldenRandomID.FindStringSubmatch(id)[1] + ids[id]
+	})
+	return geminiGoldenCreated.ReplaceAllString(s, `"${1}": 0`)
+}
+
+func TestGeminiGolden(t *testing.T) {
+	kinds := make([]string, 0, len(geminiGoldenConverters))
+	for kind := range geminiGoldenConverters {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		convert := geminiGoldenConverters[kind]
+		inputs, err := filepath.Glob(filepath.Join("testdata", "gemini", kind, "*.in.json"))
+		require.NoError(t, err)
+		require.NotEmpty(t, inputs, "no golden inputs for %s", kind)
+
+		for _, inPath := range inputs {
+			name := strings.TrimSuffix(filepath.Base(inPath), ".in.json")
+			t.Run(kind+"/"+name, func(t *testing.T) {
+				in, err := os.ReadFile(inPath)
+				require.NoError(t, err)
+
+				raw, err := json.Marshal(convert(t, in))
+				require.NoError(t, err)
+				var pretty bytes.Buffer
+				require.NoError(t, json.Indent(&pretty, raw, "", "  "))
+				got := scrubGeminiGolden(pretty.String()) + "\n"
+
+				goldenPath := strings.TrimSuffix(inPath, ".in.json") + ".golden.json"
+				if *updateGolden {
+					require.NoError(t, os.WriteFile(goldenPath, []byte(got), 0o644))
+					return
+				}
+				want, err := os.ReadFile(goldenPath)
+				require.NoError(t, err, "missing golden file; run with -update")
+				require.Equal(t, string(want), got)
+			})
+		}
+	}
+}
+
+func TestCleanGeminiSchema(t *testing.T) {
+	in := json.RawMessage(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"default": {"type": ["string", "null"], "default": "x"},
+			"count": {"type": "integer", "exclusiveMinimum": 0},
+			"mode": {"const": "fast"},
+			"item": {"$ref": "#/$defs/Item", "description": "the item"}
+		},
+		"$defs": {"Item": {"type": "object", "properties": {"id": {"type": "string", "minLength": 1}}}}
+	}`)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(cleanGeminiSchema(in), &got))
+	require.Equal(t, map[string]any{
+		"type": "OBJECT",
+		"properties": map[string]any{
+			"default": map[string]any{"type": "STRING", "nullable": true},
+			"count":   map[string]any{"type": "INTEGER", "minimum": float64(1)},
+			"mode":    map[string]any{"enum": []any{"fast"}},
+			"item": map[string]any{
+				"type":        "OBJECT",
+				"description": "the item",
+				"properties":  map[string]any{"id": map[string]any{"type": "STRING"}},
+			},
+		},
+	}, got)
+
+	require.JSONEq(t, `{"type":"OBJECT","properties":{}}`, string(cleanGeminiSchema(nil)))
+}
+
+func TestGeminiRequestAcceptsSnakeCase(t *testing.T) {
+	var req GeminiRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"contents": [{"role": "user", "parts": [{"inline_data": {"mime_type": "image/png", "data": "AAAA"}}]}],
+		"system_instruction": {"parts": [{"text": "be brief"}]},
+		"generation_config": {"max_output_tokens": 256, "thinking_config": {"thinking_budget": 0}},
+		"tools": [{"function_declarations": [{"name": "f", "parameters": {"type": "OBJECT", "properties": {"snake_key": {"type": "STRING"}}}}]}]
+	}`), &req))
+	require.Equal(t, "be brief", req.SystemInstruction.Parts[0].Text)
+	require.Equal(t, "image/png", req.Contents[0].Parts[0].InlineData.MimeType)
+	require.Equal(t, 256, req.GenerationConfig.MaxOutputTokens)
+	require.Equal(t, 0, *req.GenerationConfig.ThinkingConfig.ThinkingBudget)
+	// 自由格式的 schema 保留原始键名
+	require.Contains(t, string(req.Tools[0].FunctionDeclarations[0].Parameters), "snake_key")
+}
+
+func TestParseGeminiResponseUnwrapsV1Internal(t *testing.T) {
+	resp, err := ParseGeminiResponse([]byte(`{"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}],"responseId":"abc"},"traceId":"t"}`))
+	require.NoError(t, err)
+	require.Equal(t, "abc", resp.ResponseID)
+	require.Equal(t, "hi", resp.Candidates[0].Content.Parts[0].Text)
+}
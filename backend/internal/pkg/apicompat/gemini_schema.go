@@ -0,0 +1,192 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// geminiUnsupportedSchemaKeys are JSON Schema keywords rejected by Gemini's
+// OpenAPI schema subset in functionDeclarations[].parameters.
+var geminiUnsupportedSchemaKeys = map[string]bool{
+	"$schema": true, "$id": true, "$ref": true, "$defs": true, "definitions": true,
+	"additionalProperties": true, "patternProperties": true,
+	"minLength": true, "maxLength": true, "minItems": true, "maxItems": true,
+	"exclusiveMinimum": true, "exclusiveMaximum": true,
+	"const": true, "examples": true, "default": true, "strict": true,
+}
+
+// cleanGeminiSchema converts a JSON Schema tool definition into the schema
+// subset accepted by Gemini function declarations: local $ref pointers are
+// inlined, unsupported keywords dropped, type names upper-cased and
+// ["T", "null"] unions collapsed into a nullable T.
+func cleanGeminiSchema(raw json.RawMessage) json.RawMessage {
+	var schema any
+	if len(raw) == 0 || json.Unmarshal(raw, &schema) != nil {
+		schema = nil
+	}
+	root, ok := schema.(map[string]any)
+	if !ok {
+		root = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	defs := map[string]any{}
+	for _, key := range []string{"$defs", "definitions"} {
+		if d, ok := root[key].(map[string]any); ok {
+			for name, def := range d {
+				defs[name] = def
+			}
+		}
+	}
+	cleaned := cleanGeminiSchemaNode(root, defs, 0)
+	out, err := json.Marshal(cleaned)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// geminiSchemaMaxRefDepth stops recursive $ref expansion (self-referencing
+// schemas); deeper references degrade to an untyped object.
+const geminiSchemaMaxRefDepth = 8
+
+func cleanGeminiSchemaNode(node any, defs map[string]any, refDepth int) any {
+	switch v := node.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			def, found := defs[ref[strings.LastIndex(ref, "/")+1:]]
+			if !found || refDepth >= geminiSchemaMaxRefDepth {
+				return map[string]any{"type": "OBJECT"}
+			}
+			merged := map[string]any{}
+			if defMap, ok := def.(map[string]any); ok {
+				for key, value := range defMap {
+					merged[key] = value
+				}
+			}
+			// 引用处的兄弟字段（如 description）优先于定义本身。
+			for key, value := range v {
+				if key != "$ref" {
+					merged[key] = value
+				}
+			}
+			return cleanGeminiSchemaNode(merged, defs, refDepth+1)
+		}
+
+		cleaned := make(map[string]any, len(v))
+		for key, value := range v {
+			if geminiUnsupportedSchemaKeys[key] || key == "properties" {
+				continue
+			}
+			cleaned[key] = cleanGeminiSchemaNode(value, defs, refDepth)
+		}
+		if constValue, ok := v["const"]; ok {
+			if _, hasEnum := cleaned["enum"]; !hasEnum {
+				cleaned["enum"] = []any{constValue}
+			}
+		}
+		switch typeValue := cleaned["type"].(type) {
+		case string:
+			cleaned["type"] = strings.ToUpper(typeValue)
+		case []any:
+			delete(cleaned, "type")
+			for _, item := range typeValue {
+				name, ok := item.(string)
+				if !ok {
+					continue
+				}
+				if strings.EqualFold(name, "null") {
+					cleaned["nullable"] = true
+				} else if _, set := cleaned["type"]; !set {
+					cleaned["type"] = strings.ToUpper(name)
+				}
+			}
+		}
+		if cleaned["type"] == "INTEGER" {
+			if minimum, ok := incrementIntegralBound(v["exclusiveMinimum"]); ok {
+				if existing, exists := cleaned["minimum"].(float64); !exists || existing < minimum {
+					cleaned["minimum"] = minimum
+				}
+			}
+		}
+		// properties 的键是属性名而非关键字，单独处理，避免名为 default/const 的属性被当作关键字过滤。
+		if props, ok := v["properties"].(map[string]any); ok {
+			cleanedProps := make(map[string]any, len(props))
+			for name, prop := range props {
+				cleanedProps[name] = cleanGeminiSchemaNode(prop, defs, refDepth)
+			}
+			cleaned["properties"] = cleanedProps
+		}
+		return cleaned
+	case []any:
+		cleaned := make([]any, len(v))
+		for i, item := range v {
+			cleaned[i] = cleanGeminiSchemaNode(item, defs, refDepth)
+		}
+		return cleaned
+	default:
+		return v
+	}
+}
+
+func incrementIntegralBound(value any) (float64, bool) {
+	v, ok := value.(float64)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) || v+1 <= v {
+		return 0, false
+	}
+	return v + 1, true
+}
+
+// geminiSchemaToJSONSchema converts a Gemini OpenAPI-subset schema back into
+// JSON Schema: type names are lower-cased and nullable becomes a type union.
+// parametersJsonSchema is already JSON Schema and is returned unchanged.
+func geminiSchemaToJSONSchema(decl GeminiFunctionDeclaration) json.RawMessage {
+	if len(decl.ParametersJSONSchema) > 0 {
+		return decl.ParametersJSONSchema
+	}
+	var schema any
+	if len(decl.Parameters) == 0 || json.Unmarshal(decl.Parameters, &schema) != nil {
+		return json.RawMessage(`{"type":"object","properties":{}}`)
+	}
+	out, err := json.Marshal(geminiSchemaNodeToJSONSchema(schema))
+	if err != nil {
+		return decl.Parameters
+	}
+	return out
+}
+
+func geminiSchemaNodeToJSONSchema(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if key == "nullable" || key == "properties" {
+				continue
+			}
+			out[key] = geminiSchemaNodeToJSONSchema(value)
+		}
+		if props, ok := v["properties"].(map[string]any); ok {
+			converted := make(map[string]any, len(props))
+			for name, prop := range props {
+				converted[name] = geminiSchemaNodeToJSONSchema(prop)
+			}
+			out["properties"] = converted
+		}
+		if typeName, ok := v["type"].(string); ok {
+			typeName = strings.ToLower(typeName)
+			if nullable, _ := v["nullable"].(bool); nullable {
+				out["type"] = []any{typeName, "null"}
+			} else {
+				out["type"] = typeName
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = geminiSchemaNodeToJSONSchema(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
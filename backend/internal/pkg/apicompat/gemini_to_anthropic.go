@@ -0,0 +1,639 @@
+package apicompat
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Request: Gemini generateContent → Anthropic Messages
+// ---------------------------------------------------------------------------
+
+// GeminiToAnthropicRequest converts a Gemini generateContent request into an
+// Anthropic Messages request. Gemini carries the model and the stream flag in
+// the URL, so the caller passes them explicitly.
+func GeminiToAnthropicRequest(req *GeminiRequest, model string, stream bool) (*AnthropicRequest, error) {
+	out := &AnthropicRequest{
+		Model:  model,
+		Stream: stream,
+	}
+
+	if req.SystemInstruction != nil {
+		var texts []string
+		for _, p := range req.SystemInstruction.Parts {
+			if p.Text != "" {
+				texts = append(texts, p.Text)
+			}
+		}
+		if len(texts) > 0 {
+			system, err := json.Marshal(strings.Join(texts, "\n\n"))
+			if err != nil {
+				return nil, err
+			}
+			out.System = system
+		}
+	}
+
+	messages, err := convertGeminiContentsToAnthropic(req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	out.Messages = messages
+
+	out.Tools = convertGeminiToolsToAnthropic(req.Tools)
+	if req.ToolConfig != nil && req.ToolConfig.FunctionCallingConfig != nil {
+		out.ToolChoice = convertGeminiToolConfigToAnthropic(req.ToolConfig.FunctionCallingConfig)
+	}
+
+	cfg := req.GenerationConfig
+	if cfg == nil {
+		cfg = &GeminiGenerationConfig{}
+	}
+	out.MaxTokens = cfg.MaxOutputTokens
+	if out.MaxTokens <= 0 {
+		// Anthropic requires max_tokens; default to a sensible value.
+		out.MaxTokens = 8192
+	}
+	out.Temperature = cfg.Temperature
+	out.TopP = cfg.TopP
+	out.StopSeqs = cfg.StopSequences
+
+	if budget := geminiThinkingBudget(cfg.ThinkingConfig); budget > 0 {
+		// max_tokens 包含思考预算，与 Gemini maxOutputTokens 的口径一致；
+		// 预算超出上限时收缩预算，低于 Anthropic 最小预算则不开启思考。
+		if budget >= out.MaxTokens {
+			budget = out.MaxTokens - 1
+		}
+		if budget >= anthropicMinThinkingBudget {
+			out.Thinking = &AnthropicThinking{Type: "enabled", BudgetTokens: budget}
+			// Anthropic 开启思考时不接受自定义 temperature/top_p。
+			out.Temperature = nil
+			out.TopP = nil
+		}
+	}
+
+	return out, nil
+}
+
+// anthropicMinThinkingBudget is the smallest budget_tokens Anthropic accepts.
+const anthropicMinThinkingBudget = 1024
+
+// geminiThinkingBudget resolves a Gemini thinkingConfig into an Anthropic
+// thinking budget; 0 means thinking stays disabled.
+func geminiThinkingBudget(tc *GeminiThinkingConfig) int {
+	if tc == nil {
+		return 0
+	}
+	if tc.ThinkingBudget != nil {
+		switch budget := *tc.ThinkingBudget; {
+		case budget > 0:
+			return max(budget, anthropicMinThinkingBudget)
+		case budget == 0:
+			return 0
+		}
+		// -1: dynamic budget, fall through to the level-based default.
+	} else if tc.ThinkingLevel == "" && !tc.IncludeThoughts {
+		return 0
+	}
+	switch strings.ToLower(tc.ThinkingLevel) {
+	case "minimal", "low":
+		return defaultThinkingBudget("low")
+	case "medium":
+		return defaultThinkingBudget("medium")
+	default:
+		return defaultThinkingBudget("high")
+	}
+}
+
+// convertGeminiContentsToAnthropic converts Gemini contents into Anthropic
+// messages. Consecutive turns with the same role are merged because Anthropic
+// requires alternating roles. Gemini function calls usually carry no id, so
+// tool_use ids are generated and paired with functionResponses by name in
+// call order.
+func convertGeminiContentsToAnthropic(contents []GeminiContent) ([]AnthropicMessage, error) {
+	type pendingMessage struct {
+		role   string
+		blocks []AnthropicContentBlock
+	}
+	var pending []pendingMessage
+	callIDs := make(map[string][]string)
+
+	for _, c := range contents {
+		role := "user"
+		if c.Role == "model" {
+			role = "assistant"
+		}
+		var results, blocks []AnthropicContentBlock
+		for _, p := range c.Parts {
+			switch {
+			case p.FunctionCall != nil:
+				id := p.FunctionCall.ID
+				if id == "" {
+					id = generateToolUseID()
+				}
+				callIDs[p.FunctionCall.Name] = append(callIDs[p.FunctionCall.Name], id)
+				blocks = append(blocks, AnthropicContentBlock{
+					Type:  "tool_use",
+					ID:    id,
+					Name:  p.FunctionCall.Name,
+					Input: normalizeGeminiArgs(p.FunctionCall.Args),
+				})
+			case p.FunctionResponse != nil:
+				id := p.FunctionResponse.ID
+				if queue := callIDs[p.FunctionResponse.Name]; len(queue) > 0 {
+					if id == "" {
+						id = queue[0]
+					}
+					callIDs[p.FunctionResponse.Name] = removeFirstString(queue, id)
+				}
+				if id == "" {
+					id = generateToolUseID()
+				}
+				text, isError := geminiFunctionResponseText(p.FunctionResponse.Response)
+				content, err := json.Marshal(text)
+				if err != nil {
+					return nil, err
+				}
+				// Anthropic 要求 tool_result 位于 user 消息最前面。
+				results = append(results, AnthropicContentBlock{
+					Type:      "tool_result",
+					ToolUseID: id,
+					Content:   content,
+					IsError:   isError,
+				})
+			case p.Thought:
+				// Anthropic 只接受 assistant 轮中带签名的 thinking block。
+				if role == "assistant" && p.ThoughtSignature != "" {
+					blocks = append(blocks, AnthropicContentBlock{
+						Type:      "thinking",
+						Thinking:  p.Text,
+						Signature: p.ThoughtSignature,
+					})
+				}
+			case p.InlineData != nil:
+				if block, ok := geminiBlobToAnthropicBlock(p.InlineData); ok {
+					blocks = append(blocks, block)
+				}
+			case p.FileData != nil:
+				blocks = append(blocks, AnthropicContentBlock{
+					Type: "text",
+					Text: fmt.Sprintf("[file: %s]", p.FileData.FileURI),
+				})
+			case p.Text != "":
+				blocks = append(blocks, AnthropicContentBlock{Type: "text", Text: p.Text})
+			}
+		}
+		blocks = append(results, blocks...)
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(pending); n > 0 && pending[n-1].role == role {
+			pending[n-1].blocks = append(pending[n-1].blocks, blocks...)
+			continue
+		}
+		pending = append(pending, pendingMessage{role: role, blocks: blocks})
+	}
+
+	messages := make([]AnthropicMessage, 0, len(pending))
+	for _, m := range pending {
+		content, err := json.Marshal(m.blocks)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, AnthropicMessage{Role: m.role, Content: content})
+	}
+	return messages, nil
+}
+
+// geminiFunctionResponseText flattens a functionResponse.response object into
+// tool_result text. The conventional {"content"|"output"|"result": "..."}
+// wrappers are unwrapped; a lone {"error": ...} marks the result as an error.
+func geminiFunctionResponseText(raw json.RawMessage) (string, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err == nil && len(obj) == 1 {
+		for key, value := range obj {
+			var s string
+			if json.Unmarshal(value, &s) != nil {
+				s = string(value)
+			}
+			switch key {
+			case "content", "output", "result":
+				return s, false
+			case "error":
+				return s, true
+			}
+		}
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw), false
+	}
+	return compact.String(), false
+}
+
+// geminiBlobToAnthropicBlock converts inline media into an image or PDF
+// document block; other media types have no Anthropic equivalent.
+func geminiBlobToAnthropicBlock(blob *GeminiBlob) (AnthropicContentBlock, bool) {
+	if blob.Data == "" {
+		return AnthropicContentBlock{}, false
+	}
+	src := &AnthropicImageSource{Type: "base64", MediaType: blob.MimeType, Data: blob.Data}
+	switch {
+	case strings.HasPrefix(blob.MimeType, "image/"):
+		return AnthropicContentBlock{Type: "image", Source: src}, true
+	case blob.MimeType == "application/pdf":
+		return AnthropicContentBlock{Type: "document", Source: src}, true
+	default:
+		return AnthropicContentBlock{}, false
+	}
+}
+
+// convertGeminiToolsToAnthropic maps functionDeclarations to custom tools and
+// googleSearch to the Anthropic web search server tool.
+func convertGeminiToolsToAnthropic(tools []GeminiTool) []AnthropicTool {
+	var out []AnthropicTool
+	googleSearch := false
+	for _, t := range tools {
+		for _, decl := range t.FunctionDeclarations {
+			out = append(out, AnthropicTool{
+				Name:        decl.Name,
+				Description: decl.Description,
+				InputSchema: geminiSchemaToJSONSchema(decl),
+			})
+		}
+		if len(t.GoogleSearch) > 0 {
+			googleSearch = true
+		}
+	}
+	if googleSearch {
+		out = append(out, AnthropicTool{Type: "web_search_20250305", Name: "web_search"})
+	}
+	return out
+}
+
+// convertGeminiToolConfigToAnthropic is the reverse of
+// convertAnthropicToolChoiceToGemini. ANY with exactly one allowed function
+// becomes a forced tool choice; VALIDATED behaves like AUTO.
+func convertGeminiToolConfigToAnthropic(cfg *GeminiFunctionCallingConfig) json.RawMessage {
+	switch strings.ToUpper(cfg.Mode) {
+	case "ANY":
+		if len(cfg.AllowedFunctionNames) == 1 {
+			out, _ := json.Marshal(map[string]string{"type": "tool", "name": cfg.AllowedFunctionNames[0]})
+			return out
+		}
+		return json.RawMessage(`{"type":"any"}`)
+	case "NONE":
+		return json.RawMessage(`{"type":"none"}`)
+	case "AUTO", "VALIDATED":
+		return json.RawMessage(`{"type":"auto"}`)
+	default:
+		return nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Response: Gemini generateContent → Anthropic Messages (non-streaming)
+// ---------------------------------------------------------------------------
+
+// GeminiResponseToAnthropic converts a non-streaming Gemini response into an
+// Anthropic Messages response. Only the first candidate is converted.
+func GeminiResponseToAnthropic(resp *GeminiResponse, model string) *AnthropicResponse {
+	if model == "" {
+		model = resp.ModelVersion
+	}
+	out := &AnthropicResponse{
+		ID:      geminiAnthropicMessageID(resp.ResponseID),
+		Type:    "message",
+		Role:    "assistant",
+		Content: []AnthropicContentBlock{},
+		Model:   model,
+		Usage:   geminiUsageToAnthropicUsage(resp.UsageMetadata),
+	}
+
+	var finishReason string
+	if len(resp.Candidates) > 0 {
+		cand := resp.Candidates[0]
+		finishReason = cand.FinishReason
+		if cand.Content != nil {
+			out.Content = geminiPartsToAnthropicBlocks(cand.Content.Parts)
+		}
+	} else if len(resp.PromptFeedback) > 0 {
+		// 无候选且带 promptFeedback：提示词被安全策略拦截。
+		finishReason = "SAFETY"
+	}
+
+	hasToolUse := false
+	for _, b := range out.Content {
+		if b.Type == "tool_use" {
+			hasToolUse = true
+			break
+		}
+	}
+	out.StopReason = AnthropicStopReasonPtr(geminiFinishReasonToAnthropic(finishReason, hasToolUse))
+	return out
+}
+
+// geminiPartsToAnthropicBlocks converts response parts into content blocks,
+// merging adjacent text and thought parts into single blocks.
+func geminiPartsToAnthropicBlocks(parts []GeminiPart) []AnthropicContentBlock {
+	blocks := []AnthropicContentBlock{}
+	appendText := func(text string) {
+		if n := len(blocks); n > 0 && blocks[n-1].Type == "text" {
+			blocks[n-1].Text += text
+			return
+		}
+		blocks = append(blocks, AnthropicContentBlock{Type: "text", Text: text})
+	}
+	for _, p := range parts {
+		switch {
+		case p.FunctionCall != nil:
+			id := p.FunctionCall.ID
+			if id == "" {
+				id = generateToolUseID()
+			}
+			blocks = append(blocks, AnthropicContentBlock{
+				Type:      "tool_use",
+				ID:        id,
+				Name:      p.FunctionCall.Name,
+				Input:     normalizeGeminiArgs(p.FunctionCall.Args),
+				Signature: p.ThoughtSignature,
+			})
+		case p.Thought:
+			if n := len(blocks); n > 0 && blocks[n-1].Type == "thinking" {
+				blocks[n-1].Thinking += p.Text
+				if p.ThoughtSignature != "" {
+					blocks[n-1].Signature = p.ThoughtSignature
+				}
+				continue
+			}
+			blocks = append(blocks, AnthropicContentBlock{Type: "thinking", Thinking: p.Text, Signature: p.ThoughtSignature})
+		case p.InlineData != nil:
+			appendText(geminiInlineImageMarkdown(p.InlineData))
+		case p.Text != "":
+			appendText(p.Text)
+		}
+	}
+	return blocks
+}
+
+// geminiInlineImageMarkdown renders a generated image as a markdown data URI
+// so text-only clients can still display it.
+func geminiInlineImageMarkdown(blob *GeminiBlob) string {
+	return fmt.Sprintf("![image](data:%s;base64,%s)", blob.MimeType, blob.Data)
+}
+
+// geminiFinishReasonToAnthropic maps a Gemini finishReason to an Anthropic
+// stop_reason. A function call always wins because Gemini reports it as STOP.
+func geminiFinishReasonToAnthropic(finishReason string, hasToolUse bool) string {
+	if hasToolUse {
+		return "tool_use"
+	}
+	switch finishReason {
+	case "MAX_TOKENS":
+		return "max_tokens"
+	case "SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST", "SPII", "RECITATION", "IMAGE_SAFETY":
+		return "refusal"
+	default:
+		return "end_turn"
+	}
+}
+
+// geminiUsageToAnthropicUsage converts usageMetadata into Anthropic usage.
+// Cached tokens are moved out of input_tokens and thinking tokens are billed
+// as output.
+func geminiUsageToAnthropicUsage(u *GeminiUsageMetadata) AnthropicUsage {
+	if u == nil {
+		return AnthropicUsage{}
+	}
+	return AnthropicUsage{
+		InputTokens:          max(u.PromptTokenCount-u.CachedContentTokenCount, 0),
+		OutputTokens:         u.CandidatesTokenCount + u.ThoughtsTokenCount,
+		CacheReadInputTokens: u.CachedContentTokenCount,
+	}
+}
+
+func geminiAnthropicMessageID(responseID string) string {
+	if responseID != "" {
+		return "msg_" + responseID
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "msg_" + hex.EncodeToString(b)
+}
+
+func generateToolUseID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "toolu_" + hex.EncodeToString(b)
+}
+
+func removeFirstString(values []string, target string) []string {
+	for i, v := range values {
+		if v == target {
+			return append(values[:i:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: Gemini stream chunk → []AnthropicStreamEvent (stateful converter)
+// ---------------------------------------------------------------------------
+
+// GeminiToAnthropicStreamState tracks state for converting Gemini
+// streamGenerateContent chunks into Anthropic SSE events.
+type GeminiToAnthropicStreamState struct {
+	ResponseID string
+	Model      string
+
+	MessageStartSent bool
+	MessageStopSent  bool
+
+	ContentBlockIndex int
+	ContentBlockOpen  bool
+	CurrentBlockType  string // "thinking" | "text" | "tool_use"
+
+	HasToolUse   bool
+	FinishReason string
+	Usage        AnthropicUsage
+}
+
+// NewGeminiToAnthropicStreamState returns an initialised stream state.
+func NewGeminiToAnthropicStreamState(model string) *GeminiToAnthropicStreamState {
+	return &GeminiToAnthropicStreamState{Model: model}
+}
+
+// GeminiChunkToAnthropicEvents converts one Gemini stream chunk into zero or
+// more Anthropic stream events, updating state as it goes. Gemini sends each
+// functionCall complete in a single part, so tool_use blocks are opened and
+// closed within the same chunk.
+func GeminiChunkToAnthropicEvents(chunk *GeminiResponse, state *GeminiToAnthropicStreamState) []AnthropicStreamEvent {
+	if chunk == nil || state == nil || state.MessageStopSent {
+		return nil
+	}
+	if !state.MessageStartSent {
+		state.ResponseID = geminiAnthropicMessageID(chunk.ResponseID)
+		if state.Model == "" {
+			state.Model = chunk.ModelVersion
+		}
+	}
+	// usageMetadata 在每个分片中都是累计值，取最新一次即可。
+	if chunk.UsageMetadata != nil {
+		state.Usage = geminiUsageToAnthropicUsage(chunk.UsageMetadata)
+	}
+
+	events := ensureGeminiAnthropicMessageStart(state)
+	if len(chunk.Candidates) == 0 {
+		return events
+	}
+	cand := chunk.Candidates[0]
+	if cand.FinishReason != "" {
+		state.FinishReason = cand.FinishReason
+	}
+	if cand.Content == nil {
+		return events
+	}
+
+	for _, p := range cand.Content.Parts {
+		switch {
+		case p.FunctionCall != nil:
+			events = append(events, closeGeminiAnthropicBlock(state)...)
+			id := p.FunctionCall.ID
+			if id == "" {
+				id = generateToolUseID()
+			}
+			idx := state.ContentBlockIndex
+			state.ContentBlockOpen = true
+			state.CurrentBlockType = "tool_use"
+			state.HasToolUse = true
+			events = append(events,
+				AnthropicStreamEvent{
+					Type:  "content_block_start",
+					Index: &idx,
+					ContentBlock: &AnthropicContentBlock{
+						Type:      "tool_use",
+						ID:        id,
+						Name:      p.FunctionCall.Name,
+						Input:     json.RawMessage("{}"),
+						Signature: p.ThoughtSignature,
+					},
+				},
+				AnthropicStreamEvent{
+					Type:  "content_block_delta",
+					Index: &idx,
+					Delta: &AnthropicDelta{
+						Type:        "input_json_delta",
+						PartialJSON: string(normalizeGeminiArgs(p.FunctionCall.Args)),
+					},
+				},
+			)
+			events = append(events, closeGeminiAnthropicBlock(state)...)
+		case p.Thought:
+			events = append(events, ensureGeminiAnthropicBlock(state, "thinking")...)
+			if p.Text != "" {
+				events = append(events, geminiAnthropicDelta(state, &AnthropicDelta{Type: "thinking_delta", Thinking: p.Text}))
+			}
+			if p.ThoughtSignature != "" {
+				events = append(events, geminiAnthropicDelta(state, &AnthropicDelta{Type: "signature_delta", Signature: p.ThoughtSignature}))
+			}
+		default:
+			// Gemini 3 可能把思考签名挂在思考结束后的首个文本分片上。
+			if p.ThoughtSignature != "" && state.ContentBlockOpen && state.CurrentBlockType == "thinking" {
+				events = append(events, geminiAnthropicDelta(state, &AnthropicDelta{Type: "signature_delta", Signature: p.ThoughtSignature}))
+			}
+			text := p.Text
+			if p.InlineData != nil {
+				text = geminiInlineImageMarkdown(p.InlineData)
+			}
+			if text == "" {
+				continue
+			}
+			events = append(events, ensureGeminiAnthropicBlock(state, "text")...)
+			events = append(events, geminiAnthropicDelta(state, &AnthropicDelta{Type: "text_delta", Text: text}))
+		}
+	}
+	return events
+}
+
+// FinalizeGeminiAnthropicStream emits terminal Anthropic events (close open
+// block + message_delta + message_stop) when the Gemini stream ends.
+func FinalizeGeminiAnthropicStream(state *GeminiToAnthropicStreamState) []AnthropicStreamEvent {
+	if state == nil || state.MessageStopSent {
+		return nil
+	}
+	if !state.MessageStartSent {
+		state.ResponseID = geminiAnthropicMessageID("")
+	}
+	events := ensureGeminiAnthropicMessageStart(state)
+	events = append(events, closeGeminiAnthropicBlock(state)...)
+
+	usage := state.Usage
+	events = append(events,
+		AnthropicStreamEvent{
+			Type: "message_delta",
+			Delta: &AnthropicDelta{
+				StopReason: geminiFinishReasonToAnthropic(state.FinishReason, state.HasToolUse),
+			},
+			Usage: &usage,
+		},
+		AnthropicStreamEvent{Type: "message_stop"},
+	)
+	state.MessageStopSent = true
+	return events
+}
+
+func ensureGeminiAnthropicMessageStart(state *GeminiToAnthropicStreamState) []AnthropicStreamEvent {
+	if state.MessageStartSent {
+		return nil
+	}
+	state.MessageStartSent = true
+	return []AnthropicStreamEvent{{
+		Type: "message_start",
+		Message: &AnthropicResponse{
+			ID:         state.ResponseID,
+			Type:       "message",
+			Role:       "assistant",
+			Content:    []AnthropicContentBlock{},
+			Model:      state.Model,
+			StopReason: nil, // JSON null; never ""
+			Usage:      AnthropicUsage{},
+		},
+	}}
+}
+
+// ensureGeminiAnthropicBlock opens a thinking or text block, closing any
+// block of another type first.
+func ensureGeminiAnthropicBlock(state *GeminiToAnthropicStreamState, blockType string) []AnthropicStreamEvent {
+	if state.ContentBlockOpen && state.CurrentBlockType == blockType {
+		return nil
+	}
+	events := closeGeminiAnthropicBlock(state)
+	idx := state.ContentBlockIndex
+	state.ContentBlockOpen = true
+	state.CurrentBlockType = blockType
+	return append(events, AnthropicStreamEvent{
+		Type:         "content_block_start",
+		Index:        &idx,
+		ContentBlock: &AnthropicContentBlock{Type: blockType},
+	})
+}
+
+func closeGeminiAnthropicBlock(state *GeminiToAnthropicStreamState) []AnthropicStreamEvent {
+	if !state.ContentBlockOpen {
+		return nil
+	}
+	idx := state.ContentBlockIndex
+	state.ContentBlockOpen = false
+	state.ContentBlockIndex++
+	state.CurrentBlockType = ""
+	return []AnthropicStreamEvent{{Type: "content_block_stop", Index: &idx}}
+}
+
+func geminiAnthropicDelta(state *GeminiToAnthropicStreamState, delta *AnthropicDelta) AnthropicStreamEvent {
+	idx := state.ContentBlockIndex
+	return AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: delta}
+}
@@ -620,6 +620,11 @@ func convertResponsesToAnthropicTools(tools []ResponsesTool) []AnthropicTool {
 				Type: "web_search_20250305",
 				Name: "web_search",
 			})
+		case "web_fetch", "web_fetch_20250910":
+			out = append(out, AnthropicTool{
+				Type: "web_fetch_20250910",
+				Name: "web_fetch",
+			})
 		case "function":
 			out = append(out, AnthropicTool{
 				Name:        t.Name,
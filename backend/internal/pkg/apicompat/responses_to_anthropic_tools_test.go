@@ -127,6 +127,15 @@ func TestResponsesToAnthropic_MixedToolsProduceValidAnthropicTools(t *testing.T)
 	assert.NotContains(t, string(serverToolWire), `"input_schema"`)
 }
 
+func TestResponsesToAnthropic_WebFetchToolBecomesServerTool(t *testing.T) {
+	tools := convertResponsesToAnthropicTools([]ResponsesTool{{Type: "web_fetch"}})
+
+	require.Len(t, tools, 1)
+	assert.Equal(t, "web_fetch_20250910", tools[0].Type)
+	assert.Equal(t, "web_fetch", tools[0].Name)
+	assert.Empty(t, tools[0].InputSchema)
+}
+
 func TestResponsesToAnthropic_DefaultToolNormalizesInputSchema(t *testing.T) {
 	tools := convertResponsesToAnthropicTools([]ResponsesTool{{
 		Type: "local_shell",
@@ -1,5 +1,6 @@
 // Package apicompat provides type definitions and conversion utilities for
-// translating between Anthropic Messages and OpenAI Responses API formats.
+// translating between Anthropic Messages, OpenAI Responses, OpenAI Chat
+// Completions and Gemini generateContent API formats.
 // It enables multi-protocol support so that clients using different API
 // formats can be served through a unified gateway.
 package apicompat
@@ -7,6 +8,7 @@ package apicompat
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 )
 
 // ---------------------------------------------------------------------------
@@ -117,6 +119,15 @@ type AnthropicTool struct {
 	Description  string                 `json:"description,omitempty"`
 	InputSchema  json.RawMessage        `json:"input_schema,omitempty"` // JSON Schema object
 	CacheControl *AnthropicCacheControl `json:"cache_control,omitempty"`
+	// Custom carries description/input_schema for MCP-style {"type":"custom"}
+	// tools that nest them under "custom" instead of the top level.
+	Custom *AnthropicCustomToolSpec `json:"custom,omitempty"`
+}
+
+// AnthropicCustomToolSpec is the nested spec of an MCP-style custom tool.
+type AnthropicCustomToolSpec struct {
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema,omitempty"`
 }
 
 // AnthropicCacheControl 对应 Anthropic API 的 cache_control 字段。
@@ -471,8 +482,9 @@ func (o *ResponsesOutput) UnmarshalJSON(data []byte) error {
 
 // WebSearchAction describes the search action in a web_search_call output item.
 type WebSearchAction struct {
-	Type  string `json:"type,omitempty"`  // "search"
+	Type  string `json:"type,omitempty"`  // "search" | "open_page"
 	Query string `json:"query,omitempty"` // primary search query
+	URL   string `json:"url,omitempty"`   // page opened by an open_page action
 }
 
 // ResponsesSummary is a summary text block inside a reasoning output.
@@ -821,6 +833,255 @@ func (d ChatDelta) reasoningText() *string {
 	return d.Reasoning
 }
 
+// ---------------------------------------------------------------------------
+// Gemini generateContent API types
+// ---------------------------------------------------------------------------
+
+// GeminiRequest is the request body for models/{model}:generateContent and
+// :streamGenerateContent. The model and the stream flag live in the URL.
+//
+// Gemini REST accepts both camelCase and snake_case field names; the types
+// below decode either form and always encode camelCase.
+type GeminiRequest struct {
+	Contents          []GeminiContent         `json:"contents"`
+	SystemInstruction *GeminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []GeminiTool            `json:"tools,omitempty"`
+	ToolConfig        *GeminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    json.RawMessage         `json:"safetySettings,omitempty"`
+	CachedContent     string                  `json:"cachedContent,omitempty"`
+}
+
+// GeminiContent is one turn of the conversation (or the system instruction).
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" | "model"
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiPart is a single part of a Gemini content. Exactly one of the data
+// fields is set; Thought marks the text as model reasoning.
+type GeminiPart struct {
+	Text    string `json:"text,omitempty"`
+	Thought bool   `json:"thought,omitempty"`
+	// ThoughtSignature is the opaque reasoning signature Gemini 3 requires to be
+	// echoed back on functionCall parts in later turns.
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *GeminiBlob             `json:"inlineData,omitempty"`
+	FileData         *GeminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// GeminiBlob is base64 inline media (images, PDFs, audio).
+type GeminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// GeminiFileData references media by URI.
+type GeminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// GeminiFunctionCall is a tool call emitted by the model.
+type GeminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse carries a tool result back to the model.
+type GeminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"` // JSON object
+}
+
+// GeminiTool declares functions or a built-in tool.
+type GeminiTool struct {
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations,omitempty"`
+	GoogleSearch         json.RawMessage             `json:"googleSearch,omitempty"`
+}
+
+// GeminiFunctionDeclaration describes a callable function. Parameters uses
+// Gemini's OpenAPI schema subset; ParametersJSONSchema is plain JSON Schema.
+type GeminiFunctionDeclaration struct {
+	Name                 string          `json:"name"`
+	Description          string          `json:"description,omitempty"`
+	Parameters           json.RawMessage `json:"parameters,omitempty"`
+	ParametersJSONSchema json.RawMessage `json:"parametersJsonSchema,omitempty"`
+}
+
+// GeminiToolConfig controls function calling.
+type GeminiToolConfig struct {
+	FunctionCallingConfig *GeminiFunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// GeminiFunctionCallingConfig selects the function calling mode.
+type GeminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode,omitempty"` // "AUTO" | "ANY" | "NONE" | "VALIDATED"
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GeminiGenerationConfig holds sampling and output options.
+type GeminiGenerationConfig struct {
+	MaxOutputTokens  int                   `json:"maxOutputTokens,omitempty"`
+	Temperature      *float64              `json:"temperature,omitempty"`
+	TopP             *float64              `json:"topP,omitempty"`
+	TopK             *int                  `json:"topK,omitempty"`
+	StopSequences    []string              `json:"stopSequences,omitempty"`
+	ResponseMimeType string                `json:"responseMimeType,omitempty"`
+	ResponseSchema   json.RawMessage       `json:"responseSchema,omitempty"`
+	ThinkingConfig   *GeminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// GeminiThinkingConfig configures model reasoning. ThinkingBudget -1 asks for
+// a dynamic budget and 0 disables thinking where the model allows it.
+type GeminiThinkingConfig struct {
+	IncludeThoughts bool   `json:"includeThoughts,omitempty"`
+	ThinkingBudget  *int   `json:"thinkingBudget,omitempty"`
+	ThinkingLevel   string `json:"thinkingLevel,omitempty"` // "low" | "medium" | "high"
+}
+
+// GeminiResponse is the generateContent response and also the payload of each
+// streamGenerateContent SSE chunk.
+type GeminiResponse struct {
+	Candidates     []GeminiCandidate    `json:"candidates,omitempty"`
+	PromptFeedback json.RawMessage      `json:"promptFeedback,omitempty"`
+	UsageMetadata  *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion   string               `json:"modelVersion,omitempty"`
+	ResponseID     string               `json:"responseId,omitempty"`
+}
+
+// GeminiCandidate is one generated candidate; only index 0 is converted.
+type GeminiCandidate struct {
+	Content      *GeminiContent `json:"content,omitempty"`
+	FinishReason string         `json:"finishReason,omitempty"` // "STOP" | "MAX_TOKENS" | "SAFETY" | ...
+	Index        int            `json:"index"`
+}
+
+// GeminiUsageMetadata holds token counts. PromptTokenCount includes
+// CachedContentTokenCount; ThoughtsTokenCount is billed as output.
+type GeminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+}
+
+// ParseGeminiResponse decodes a Gemini response body or SSE chunk. Code Assist
+// and Antigravity (v1internal) wrap the payload as {"response": {...}}; both
+// shapes are accepted.
+func ParseGeminiResponse(data []byte) (*GeminiResponse, error) {
+	var wrapper struct {
+		Response json.RawMessage `json:"response"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err == nil && len(wrapper.Response) > 0 && wrapper.Response[0] == '{' {
+		data = wrapper.Response
+	}
+	var resp GeminiResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *GeminiRequest) UnmarshalJSON(data []byte) error {
+	type alias GeminiRequest
+	return unmarshalGeminiCamel(data, (*alias)(r))
+}
+
+func (p *GeminiPart) UnmarshalJSON(data []byte) error {
+	type alias GeminiPart
+	return unmarshalGeminiCamel(data, (*alias)(p))
+}
+
+func (b *GeminiBlob) UnmarshalJSON(data []byte) error {
+	type alias GeminiBlob
+	return unmarshalGeminiCamel(data, (*alias)(b))
+}
+
+func (f *GeminiFileData) UnmarshalJSON(data []byte) error {
+	type alias GeminiFileData
+	return unmarshalGeminiCamel(data, (*alias)(f))
+}
+
+func (t *GeminiTool) UnmarshalJSON(data []byte) error {
+	type alias GeminiTool
+	return unmarshalGeminiCamel(data, (*alias)(t))
+}
+
+func (d *GeminiFunctionDeclaration) UnmarshalJSON(data []byte) error {
+	type alias GeminiFunctionDeclaration
+	return unmarshalGeminiCamel(data, (*alias)(d))
+}
+
+func (c *GeminiToolConfig) UnmarshalJSON(data []byte) error {
+	type alias GeminiToolConfig
+	return unmarshalGeminiCamel(data, (*alias)(c))
+}
+
+func (c *GeminiFunctionCallingConfig) UnmarshalJSON(data []byte) error {
+	type alias GeminiFunctionCallingConfig
+	return unmarshalGeminiCamel(data, (*alias)(c))
+}
+
+func (c *GeminiGenerationConfig) UnmarshalJSON(data []byte) error {
+	type alias GeminiGenerationConfig
+	return unmarshalGeminiCamel(data, (*alias)(c))
+}
+
+func (c *GeminiThinkingConfig) UnmarshalJSON(data []byte) error {
+	type alias GeminiThinkingConfig
+	return unmarshalGeminiCamel(data, (*alias)(c))
+}
+
+// unmarshalGeminiCamel rewrites the snake_case keys of one object level to
+// camelCase before decoding. Nested objects are handled by their own
+// UnmarshalJSON, so free-form payloads (args, response, schemas) keep their keys.
+func unmarshalGeminiCamel(data []byte, dst any) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return json.Unmarshal(data, dst)
+	}
+	changed := false
+	for key, value := range obj {
+		if !strings.Contains(key, "_") {
+			continue
+		}
+		camel := geminiSnakeToCamel(key)
+		if _, exists := obj[camel]; !exists {
+			obj[camel] = value
+		}
+		delete(obj, key)
+		changed = true
+	}
+	if changed {
+		normalized, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		data = normalized
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func geminiSnakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
 // ---------------------------------------------------------------------------
 // Shared constants
 // ---------------------------------------------------------------------------
@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Label names shared by the gateway families. Capped labels are listed in
+// Options so operators can tune cardinality per dimension.
+const (
+	LabelPlatform    = "platform"
+	LabelGroup       = "group_id"
+	LabelAccount     = "account_id"
+	LabelModel       = "model"
+	LabelStatusClass = "status_class"
+	LabelStream      = "stream"
+	LabelErrorClass  = "class"
+	LabelMode        = "mode"
+)
+
+// Upstream error classes exported on sub2api_gateway_upstream_errors_total.
+const (
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassAuth        = "auth"
+	ErrorClassOverloaded  = "overloaded"
+	ErrorClassServer      = "server_error"
+	ErrorClassClient      = "client_error"
+	ErrorClassNetwork     = "network"
+	ErrorClassOther       = "other"
+)
+
+// DefaultLatencyBuckets covers sub-second cache hits up to long reasoning turns.
+var DefaultLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300, 600}
+
+// DefaultFirstTokenBuckets is tuned for time-to-first-token, which is usually
+// dominated by upstream queueing rather than generation.
+var DefaultFirstTokenBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 3, 5, 10, 20, 30, 60}
+
+// Options controls the gateway metric families.
+type Options struct {
+	// MaxAccountSeries caps distinct account_id values; 0 drops the label.
+	MaxAccountSeries int
+	// MaxGroupSeries caps distinct group_id values; 0 drops the label.
+	MaxGroupSeries int
+	// MaxModelSeries caps distinct model values; 0 drops the label.
+	MaxModelSeries int
+
+	LatencyBuckets    []float64
+	FirstTokenBuckets []float64
+}
+
+// GatewayRequest describes one finished gateway request.
+type GatewayRequest struct {
+	Platform   string
+	GroupID    int64
+	AccountID  int64
+	Model      string
+	StatusCode int
+	Stream     bool
+	Duration   time.Duration
+	// FirstToken is zero when the request produced no token (errors, non-LLM endpoints).
+	FirstToken time.Duration
+}
+
+// UpstreamError describes one failed upstream attempt, including attempts that
+// were later recovered by failover.
+type UpstreamError struct {
+	Platform   string
+	AccountID  int64
+	StatusCode int
+	// Kind mirrors OpsUpstreamErrorEvent.Kind (http_error, request_error, ...).
+	Kind string
+}
+
+// Gateway bundles the registry and the families recorded on the request path.
+type Gateway struct {
+	Registry *Registry
+	limiter  *LabelLimiter
+
+	requests         *CounterVec
+	accountRequests  *CounterVec
+	modelRequests    *CounterVec
+	duration         *HistogramVec
+	firstToken       *HistogramVec
+	upstreamErrors   *CounterVec
+	schedulerBuckets *GaugeVec
+}
+
+// NewGateway builds a registry populated with the gateway families.
+func NewGateway(opts Options) *Gateway {
+	latency := opts.LatencyBuckets
+	if len(latency) == 0 {
+		latency = DefaultLatencyBuckets
+	}
+	firstToken := opts.FirstTokenBuckets
+	if len(firstToken) == 0 {
+		firstToken = DefaultFirstTokenBuckets
+	}
+	limiter := NewLabelLimiter(map[string]int{
+		LabelAccount: opts.MaxAccountSeries,
+		LabelGroup:   opts.MaxGroupSeries,
+		LabelModel:   opts.MaxModelSeries,
+	})
+	reg := NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	g := &Gateway{Registry: reg, limiter: limiter}
+
+	g.requests = reg.NewCounterVec("sub2api_gateway_requests_total",
+		"Gateway requests by platform, group, status class and stream mode.",
+		limiter, LabelPlatform, LabelGroup, LabelStatusClass, LabelStream)
+	g.accountRequests = reg.NewCounterVec("sub2api_gateway_account_requests_total",
+		"Gateway requests by the upstream account that served the final attempt.",
+		limiter, LabelPlatform, LabelAccount, LabelStatusClass)
+	g.modelRequests = reg.NewCounterVec("sub2api_gateway_model_requests_total",
+		"Gateway requests by requested model.",
+		limiter, LabelPlatform, LabelModel, LabelStatusClass)
+	g.duration = reg.NewHistogramVec("sub2api_gateway_request_duration_seconds",
+		"End-to-end gateway request latency.",
+		latency, limiter, LabelPlatform, LabelGroup, LabelStream)
+	g.firstToken = reg.NewHistogramVec("sub2api_gateway_first_token_seconds",
+		"Time to first token for requests that produced output.",
+		firstToken, limiter, LabelPlatform, LabelGroup)
+	g.upstreamErrors = reg.NewCounterVec("sub2api_gateway_upstream_errors_total",
+		"Failed upstream attempts by error class, including attempts recovered by failover.",
+		limiter, LabelPlatform, LabelAccount, LabelErrorClass)
+	g.schedulerBuckets = reg.NewGaugeVec("sub2api_scheduler_snapshot_accounts",
+		"Accounts in the most recently written scheduler snapshot bucket.",
+		limiter, LabelPlatform, LabelGroup, LabelMode)
+	return g
+}
+
+// ObserveRequest records a finished gateway request.
+func (g *Gateway) ObserveRequest(r GatewayRequest) {
+	if g == nil {
+		return
+	}
+	class := StatusClass(r.StatusCode)
+	stream := strconv.FormatBool(r.Stream)
+	group := idLabel(r.GroupID)
+
+	g.requests.Inc(r.Platform, group, class, stream)
+	if r.AccountID > 0 {
+		g.accountRequests.Inc(r.Platform, idLabel(r.AccountID), class)
+	}
+	if r.Model != "" {
+		g.modelRequests.Inc(r.Platform, r.Model, class)
+	}
+	if r.Duration > 0 {
+		g.duration.Observe(r.Duration.Seconds(), r.Platform, group, stream)
+	}
+	if r.FirstToken > 0 {
+		g.firstToken.Observe(r.FirstToken.Seconds(), r.Platform, group)
+	}
+}
+
+// ObserveUpstreamError records one failed upstream attempt.
+func (g *Gateway) ObserveUpstreamError(e UpstreamError) {
+	if g == nil {
+		return
+	}
+	g.upstreamErrors.Inc(e.Platform, idLabel(e.AccountID), ClassifyUpstreamError(e.StatusCode, e.Kind))
+}
+
+// SetSchedulerBucketSize records the size of a freshly written snapshot bucket.
+func (g *Gateway) SetSchedulerBucketSize(platform string, groupID int64, mode string, size int) {
+	if g == nil {
+		return
+	}
+	g.schedulerBuckets.Set(float64(size), platform, idLabel(groupID), mode)
+}
+
+// Limiter exposes the shared label limiter so scrape-time collectors apply
+// the same cardinality caps as request-path families.
+func (g *Gateway) Limiter() *LabelLimiter {
+	if g == nil {
+		return nil
+	}
+	return g.limiter
+}
+
+// StatusClass maps an HTTP status code to 1xx..5xx; unknown codes map to "unknown".
+func StatusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// ClassifyUpstreamError maps an upstream status code and attempt kind to a
+// bounded error class.
+func ClassifyUpstreamError(statusCode int, kind string) string {
+	switch {
+	case statusCode == 429:
+		return ErrorClassRateLimited
+	case statusCode == 401 || statusCode == 403:
+		return ErrorClassAuth
+	case statusCode == 503 || statusCode == 529:
+		return ErrorClassOverloaded
+	case statusCode >= 500 && statusCode <= 599:
+		return ErrorClassServer
+	case statusCode >= 400 && statusCode <= 499:
+		return ErrorClassClient
+	case statusCode == 0 && kind == "request_error":
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
+
+func idLabel(id int64) string {
+	if id <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+var defaultGateway atomic.Pointer[Gateway]
+
+// SetDefault installs the process-wide gateway metrics. Passing nil disables
+// recording; every package-level helper is then a no-op.
+func SetDefault(g *Gateway) {
+	defaultGateway.Store(g)
+}
+
+// Default returns the process-wide gateway metrics, or nil when disabled.
+func Default() *Gateway {
+	return defaultGateway.Load()
+}
+
+// ObserveRequest records a request on the default gateway metrics.
+func ObserveRequest(r GatewayRequest) {
+	Default().ObserveRequest(r)
+}
+
+// ObserveUpstreamError records an upstream failure on the default gateway metrics.
+func ObserveUpstreamError(e UpstreamError) {
+	Default().ObserveUpstreamError(e)
+}
+
+// SetSchedulerBucketSize records a snapshot bucket size on the default gateway metrics.
+func SetSchedulerBucketSize(platform string, groupID int64, mode string, size int) {
+	Default().SetSchedulerBucketSize(platform, groupID, mode, size)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGatewayObserveRequestRecordsAllFamilies(t *testing.T) {
+	g := NewGateway(Options{MaxAccountSeries: 10, MaxGroupSeries: 10, MaxModelSeries: 10})
+	g.ObserveRequest(GatewayRequest{
+		Platform:   "anthropic",
+		GroupID:    7,
+		AccountID:  42,
+		Model:      "claude-sonnet-4-5",
+		StatusCode: 200,
+		Stream:     true,
+		Duration:   1500 * time.Millisecond,
+		FirstToken: 300 * time.Millisecond,
+	})
+	g.ObserveUpstreamError(UpstreamError{Platform: "anthropic", AccountID: 42, StatusCode: 429})
+	g.SetSchedulerBucketSize("anthropic", 7, "single", 12)
+
+	got := renderText(t, g.Registry)
+	for _, line := range []string{
+		`sub2api_gateway_requests_total{group_id="7",platform="anthropic",status_class="2xx",stream="true"} 1`,
+		`sub2api_gateway_account_requests_total{account_id="42",platform="anthropic",status_class="2xx"} 1`,
+		`sub2api_gateway_model_requests_total{model="claude-sonnet-4-5",platform="anthropic",status_class="2xx"} 1`,
+		`sub2api_gateway_request_duration_seconds_count{group_id="7",platform="anthropic",stream="true"} 1`,
+		`sub2api_gateway_first_token_seconds_bucket{group_id="7",platform="anthropic",le="0.5"} 1`,
+		`sub2api_gateway_upstream_errors_total{account_id="42",class="rate_limited",platform="anthropic"} 1`,
+		`sub2api_scheduler_snapshot_accounts{group_id="7",mode="single",platform="anthropic"} 12`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Fatalf("missing %q in\n%s", line, got)
+		}
+	}
+}
+
+func TestGatewayAccountLabelCanBeDisabled(t *testing.T) {
+	g := NewGateway(Options{MaxGroupSeries: 10, MaxModelSeries: 10})
+	g.ObserveRequest(GatewayRequest{Platform: "openai", AccountID: 1, StatusCode: 502})
+
+	got := renderText(t, g.Registry)
+	if !strings.Contains(got, `sub2api_gateway_account_requests_total{account_id="",platform="openai",status_class="5xx"} 1`) {
+		t.Fatalf("account label should be dropped:\n%s", got)
+	}
+}
+
+func TestClassifyUpstreamError(t *testing.T) {
+	cases := []struct {
+		status int
+		kind   string
+		want   string
+	}{
+		{429, "http_error", ErrorClassRateLimited},
+		{401, "http_error", ErrorClassAuth},
+		{529, "http_error", ErrorClassOverloaded},
+		{500, "http_error", ErrorClassServer},
+		{400, "http_error", ErrorClassClient},
+		{0, "request_error", ErrorClassNetwork},
+		{0, "failover", ErrorClassOther},
+	}
+	for _, tc := range cases {
+		if got := ClassifyUpstreamError(tc.status, tc.kind); got != tc.want {
+			t.Fatalf("ClassifyUpstreamError(%d, %q) = %q, want %q", tc.status, tc.kind, got, tc.want)
+		}
+	}
+}
+
+func TestPackageHelpersAreNoOpsWithoutDefault(t *testing.T) {
+	SetDefault(nil)
+	ObserveRequest(GatewayRequest{Platform: "openai", StatusCode: 200})
+	ObserveUpstreamError(UpstreamError{Platform: "openai", StatusCode: 500})
+	SetSchedulerBucketSize("openai", 1, "single", 3)
+}
+
+func TestHandlerRequiresBearerToken(t *testing.T) {
+	g := NewGateway(Options{})
+	g.ObserveRequest(GatewayRequest{Platform: "gemini", StatusCode: 200})
+	h := Handler(g.Registry, "secret")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status with token = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `sub2api_gateway_requests_total{group_id="",platform="gemini",status_class="2xx",stream="false"} 1`) {
+		t.Fatalf("unexpected body:\n%s", rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "go_goroutines ") {
+		t.Fatalf("Go runtime collector missing:\n%s", rec.Body.String())
+	}
+}
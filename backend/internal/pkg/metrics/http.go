@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the registry via promhttp, which negotiates the exposition
+// format with the scraper. When bearerToken is non-empty the request must
+// carry a matching "Authorization: Bearer" header.
+func Handler(reg *Registry, bearerToken string) http.Handler {
+	bearerToken = strings.TrimSpace(bearerToken)
+	scrape := promhttp.HandlerFor(reg.Prometheus(), promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if bearerToken != "" && !bearerMatches(r.Header.Get("Authorization"), bearerToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodHead {
+			return
+		}
+		scrape.ServeHTTP(w, r)
+	})
+}
+
+func bearerMatches(header, token string) bool {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return false
+	}
+	got := strings.TrimSpace(header[len(prefix):])
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
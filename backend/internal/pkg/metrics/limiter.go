@@ -0,0 +1,73 @@
+package metrics
+
+import "sync"
+
+// OverflowLabelValue replaces label values once a dimension reaches its cap.
+const OverflowLabelValue = "other"
+
+// LabelLimiter caps the number of distinct values per label name.
+//
+// The first N distinct values of a capped label are admitted as-is and every
+// later value collapses into OverflowLabelValue. A label may also be dropped
+// entirely, in which case its value is always empty. Labels that are neither
+// capped nor dropped (status classes, platforms) pass through unchanged, so
+// only high-cardinality dimensions need to be configured.
+type LabelLimiter struct {
+	mu      sync.Mutex
+	limits  map[string]int
+	dropped map[string]bool
+	seen    map[string]map[string]struct{}
+}
+
+// NewLabelLimiter creates a limiter. limits maps label names to their maximum
+// number of distinct values; a non-positive limit drops the label.
+func NewLabelLimiter(limits map[string]int) *LabelLimiter {
+	l := &LabelLimiter{
+		limits:  make(map[string]int, len(limits)),
+		dropped: make(map[string]bool),
+		seen:    make(map[string]map[string]struct{}),
+	}
+	for name, limit := range limits {
+		if limit <= 0 {
+			l.dropped[name] = true
+			continue
+		}
+		l.limits[name] = limit
+	}
+	return l
+}
+
+// Admit returns the value to record for label name.
+func (l *LabelLimiter) Admit(name, value string) string {
+	if l == nil {
+		return value
+	}
+	if l.dropped[name] {
+		return ""
+	}
+	limit, capped := l.limits[name]
+	if !capped || value == "" {
+		return value
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	values := l.seen[name]
+	if values == nil {
+		values = make(map[string]struct{}, limit)
+		l.seen[name] = values
+	}
+	if _, ok := values[value]; ok {
+		return value
+	}
+	if len(values) >= limit {
+		return OverflowLabelValue
+	}
+	values[value] = struct{}{}
+	return value
+}
+
+// Dropped reports whether label name is configured to be dropped.
+func (l *LabelLimiter) Dropped(name string) bool {
+	return l != nil && l.dropped[name]
+}
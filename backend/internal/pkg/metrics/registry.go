@@ -0,0 +1,254 @@
+// Package metrics exposes the gateway scrape endpoint on top of
+// prometheus/client_golang.
+//
+// The wrappers here add what the gateway needs on top of client_golang:
+// every family is bounded by a LabelLimiter so that a misbehaving client
+// cannot explode series cardinality, and scrape-time values can be emitted as
+// plain Samples instead of hand-written prometheus.Collector types.
+package metrics
+
+import (
+	"io"
+	"math"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const typeCounter = "counter"
+
+// Sample is a single scrape-time value emitted by a Collector.
+type Sample struct {
+	Name   string
+	Help   string
+	Type   string // "counter" or "gauge" (default)
+	Labels []Label
+	Value  float64
+}
+
+// Label is one name/value pair attached to a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Collector produces samples on every scrape. Collectors run sequentially and
+// must return promptly; expensive sources should cache their own results.
+type Collector func(emit func(Sample))
+
+// Registry owns every metric family exposed on the scrape endpoint.
+type Registry struct {
+	prom *prometheus.Registry
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{prom: prometheus.NewRegistry()}
+}
+
+// Prometheus returns the underlying client_golang registry.
+func (r *Registry) Prometheus() *prometheus.Registry {
+	if r == nil {
+		return nil
+	}
+	return r.prom
+}
+
+// MustRegister registers additional client_golang collectors, e.g. the Go
+// runtime and process collectors.
+func (r *Registry) MustRegister(cs ...prometheus.Collector) {
+	r.prom.MustRegister(cs...)
+}
+
+// RegisterCollector adds a scrape-time collector.
+func (r *Registry) RegisterCollector(c Collector) {
+	if r == nil || c == nil {
+		return
+	}
+	r.prom.MustRegister(sampleCollector{collect: c})
+}
+
+// WriteText renders all families in the Prometheus text exposition format.
+func (r *Registry) WriteText(out io.Writer) error {
+	if r == nil {
+		return nil
+	}
+	families, err := r.prom.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(out, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, mf := range families {
+		if err := enc.Encode(mf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleCollector adapts a Collector to prometheus.Collector. It describes
+// nothing, which makes it an unchecked collector: the emitted families may
+// vary from scrape to scrape.
+type sampleCollector struct {
+	collect Collector
+}
+
+func (sampleCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (s sampleCollector) Collect(ch chan<- prometheus.Metric) {
+	type family struct {
+		help    string
+		typ     prometheus.ValueType
+		samples []Sample
+	}
+	families := make(map[string]*family)
+	var order []string
+	s.collect(func(sample Sample) {
+		if sample.Name == "" || math.IsNaN(sample.Value) {
+			return
+		}
+		f := families[sample.Name]
+		if f == nil {
+			f = &family{typ: prometheus.GaugeValue}
+			if sample.Type == typeCounter {
+				f.typ = prometheus.CounterValue
+			}
+			families[sample.Name] = f
+			order = append(order, sample.Name)
+		}
+		// client_golang rejects a family whose samples disagree on help, so
+		// the first non-empty help wins.
+		if f.help == "" {
+			f.help = sample.Help
+		}
+		f.samples = append(f.samples, sample)
+	})
+
+	for _, name := range order {
+		f := families[name]
+		for _, sample := range f.samples {
+			names := make([]string, len(sample.Labels))
+			values := make([]string, len(sample.Labels))
+			for i, l := range sample.Labels {
+				names[i], values[i] = l.Name, l.Value
+			}
+			desc := prometheus.NewDesc(name, f.help, names, nil)
+			m, err := prometheus.NewConstMetric(desc, f.typ, sample.Value, values...)
+			if err != nil {
+				ch <- prometheus.NewInvalidMetric(desc, err)
+				continue
+			}
+			ch <- m
+		}
+	}
+}
+
+// vec is the shared label bookkeeping for all vector types.
+type vec struct {
+	labelNames []string
+	limiter    *LabelLimiter
+}
+
+func (v *vec) admit(values []string) []string {
+	normalized := make([]string, len(v.labelNames))
+	for i := range v.labelNames {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		normalized[i] = v.limiter.Admit(v.labelNames[i], value)
+	}
+	return normalized
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels.
+type CounterVec struct {
+	vec
+	prom *prometheus.CounterVec
+}
+
+// NewCounterVec registers a counter family.
+func (r *Registry) NewCounterVec(name, help string, limiter *LabelLimiter, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		vec:  vec{labelNames: labelNames, limiter: limiter},
+		prom: prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames),
+	}
+	r.prom.MustRegister(c.prom)
+	return c
+}
+
+// Add increments the series identified by values. Negative deltas are ignored.
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if c == nil || delta < 0 || math.IsNaN(delta) {
+		return
+	}
+	c.prom.WithLabelValues(c.admit(values)...).Add(delta)
+}
+
+// Inc increments the series identified by values by one.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// GaugeVec is a settable value partitioned by labels.
+type GaugeVec struct {
+	vec
+	prom *prometheus.GaugeVec
+}
+
+// NewGaugeVec registers a gauge family.
+func (r *Registry) NewGaugeVec(name, help string, limiter *LabelLimiter, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{
+		vec:  vec{labelNames: labelNames, limiter: limiter},
+		prom: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labelNames),
+	}
+	r.prom.MustRegister(g.prom)
+	return g
+}
+
+// Set replaces the value of the series identified by values.
+func (g *GaugeVec) Set(value float64, values ...string) {
+	if g == nil || math.IsNaN(value) {
+		return
+	}
+	g.prom.WithLabelValues(g.admit(values)...).Set(value)
+}
+
+// Delete drops the series identified by values, e.g. when a bucket disappears.
+func (g *GaugeVec) Delete(values ...string) {
+	if g == nil {
+		return
+	}
+	g.prom.DeleteLabelValues(g.admit(values)...)
+}
+
+// HistogramVec is a cumulative bucketed histogram partitioned by labels.
+type HistogramVec struct {
+	vec
+	prom *prometheus.HistogramVec
+}
+
+// NewHistogramVec registers a histogram family. The +Inf bucket is implicit.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, limiter *LabelLimiter, labelNames ...string) *HistogramVec {
+	h := &HistogramVec{
+		vec:  vec{labelNames: labelNames, limiter: limiter},
+		prom: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: sortedBuckets(buckets)}, labelNames),
+	}
+	r.prom.MustRegister(h.prom)
+	return h
+}
+
+// Observe records one observation for the series identified by values.
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	if h == nil || math.IsNaN(value) {
+		return
+	}
+	h.prom.WithLabelValues(h.admit(values)...).Observe(value)
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	out := append([]float64(nil), buckets...)
+	sort.Float64s(out)
+	return out
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func renderText(t *testing.T, reg *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	return b.String()
+}
+
+func TestRegistryWritesSortedCounterAndGauge(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounterVec("b_requests_total", "Requests.", nil, "platform")
+	inflight := reg.NewGaugeVec("a_inflight", "In flight.", nil, "platform")
+
+	requests.Inc("openai")
+	requests.Add(2, "anthropic")
+	requests.Add(-1, "anthropic")
+	inflight.Set(3, "gemini")
+
+	want := strings.Join([]string{
+		"# HELP a_inflight In flight.",
+		"# TYPE a_inflight gauge",
+		`a_inflight{platform="gemini"} 3`,
+		"# HELP b_requests_total Requests.",
+		"# TYPE b_requests_total counter",
+		`b_requests_total{platform="anthropic"} 2`,
+		`b_requests_total{platform="openai"} 1`,
+		"",
+	}, "\n")
+	if got := renderText(t, reg); got != want {
+		t.Fatalf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramIsCumulative(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogramVec("latency_seconds", "", []float64{1, 0.5}, nil, "platform")
+	h.Observe(0.2, "openai")
+	h.Observe(0.7, "openai")
+	h.Observe(4, "openai")
+
+	got := renderText(t, reg)
+	for _, line := range []string{
+		`latency_seconds_bucket{platform="openai",le="0.5"} 1`,
+		`latency_seconds_bucket{platform="openai",le="1"} 2`,
+		`latency_seconds_bucket{platform="openai",le="+Inf"} 3`,
+		`latency_seconds_sum{platform="openai"} 4.9`,
+		`latency_seconds_count{platform="openai"} 3`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Fatalf("missing %q in\n%s", line, got)
+		}
+	}
+}
+
+func TestLabelValuesAreEscaped(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounterVec("x_total", "", nil, "model").Inc("a\"b\\c\nd")
+	if got := renderText(t, reg); !strings.Contains(got, `x_total{model="a\"b\\c\nd"} 1`) {
+		t.Fatalf("label not escaped:\n%s", got)
+	}
+}
+
+func TestCollectorSamplesAreMergedIntoOutput(t *testing.T) {
+	reg := NewRegistry()
+	reg.RegisterCollector(func(emit func(Sample)) {
+		emit(Sample{Name: "queue_depth", Help: "Depth.", Labels: []Label{{Name: "queue", Value: "usage"}}, Value: 7})
+		emit(Sample{Name: "queue_depth", Labels: []Label{{Name: "queue", Value: "billing"}}, Value: 1})
+	})
+
+	want := strings.Join([]string{
+		"# HELP queue_depth Depth.",
+		"# TYPE queue_depth gauge",
+		`queue_depth{queue="billing"} 1`,
+		`queue_depth{queue="usage"} 7`,
+		"",
+	}, "\n")
+	if got := renderText(t, reg); got != want {
+		t.Fatalf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLabelLimiterCollapsesOverflowAndDropsDisabledLabels(t *testing.T) {
+	limiter := NewLabelLimiter(map[string]int{"account_id": 2, "model": 0})
+
+	if got := limiter.Admit("account_id", "1"); got != "1" {
+		t.Fatalf("first value = %q", got)
+	}
+	if got := limiter.Admit("account_id", "2"); got != "2" {
+		t.Fatalf("second value = %q", got)
+	}
+	if got := limiter.Admit("account_id", "3"); got != OverflowLabelValue {
+		t.Fatalf("overflow value = %q, want %q", got, OverflowLabelValue)
+	}
+	if got := limiter.Admit("account_id", "1"); got != "1" {
+		t.Fatalf("admitted value must stay stable, got %q", got)
+	}
+	if got := limiter.Admit("model", "claude"); got != "" {
+		t.Fatalf("dropped label = %q, want empty", got)
+	}
+	if got := limiter.Admit("platform", "openai"); got != "openai" {
+		t.Fatalf("uncapped label = %q", got)
+	}
+}
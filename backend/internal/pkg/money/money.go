@@ -0,0 +1,113 @@
+// Package money 定义计费金额的定点精度与舍入策略。
+//
+// 计费命令（service.UsageBillingCommand / BatchImageBalanceHoldCommand）从构造到
+// 落库全程以 decimal 承载金额，仓储层直接绑定为 NUMERIC 参数；展示用的领域模型、
+// ent 实体与 DTO 仍以 float64 读出，只在进入计费命令时经 Decimal 转换一次。
+// 所有会累加、落库或对账的金额都必须经过本包定格到固定小数位：
+//
+//   - 明细金额（usage_logs 的各分项费用与 total_cost）保留 LineItemScale 位小数，
+//     与 DECIMAL(20,10) 列一致；
+//   - 记账金额（actual_cost、余额、API Key 配额、订阅 / 平台用量、限速窗口用量）
+//     保留 LedgerScale 位小数，与 DECIMAL(20,8) 列一致；
+//   - 舍入一律 half-away-from-zero，与 PostgreSQL NUMERIC 的 ROUND 一致；
+//   - 每个金额只在产生时舍入一次，之后的加减乘在 decimal 上精确完成，
+//     因此请求级金额之和与按月汇总的金额逐分相等。
+//
+// float64 → decimal 统一取 float64 的最短十进制表示（decimal.NewFromFloat），
+// 这也是 PostgreSQL 把 float8 参数转成 numeric 时使用的表示。
+//
+// 范围约定：decimal 贯穿计价结果的运算与计费命令；service.CostBreakdown、User.Balance、
+// UsageLog.ActualCost 等领域模型字段保留 float64，只作为定格金额的载体。float64 可无损往返
+// 15 位有效数字，因此绝对值不超过 MaxExactLedgerAmount 的记账金额（8 位小数）与不超过
+// MaxExactLineItemAmount 的明细金额（10 位小数）经 Float / Decimal 往返后逐位相等。
+// 载体上的加减比较同样必须走本包（Sum / Mul），不能直接用浮点运算。
+package money
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// LineItemScale 明细金额的小数位数。
+	LineItemScale int32 = 10
+	// LedgerScale 记账金额的小数位数；Redis 缓存的 Lua 脚本以 10^-LedgerScale USD 为整数单位做加减。
+	LedgerScale int32 = 8
+)
+
+const (
+	// MaxExactLedgerAmount 8 位小数金额以 float64 承载时可无损往返的最大绝对值（15 位有效数字）。
+	MaxExactLedgerAmount = 9_999_999.99999999
+	// MaxExactLineItemAmount 10 位小数金额以 float64 承载时可无损往返的最大绝对值（15 位有效数字）。
+	MaxExactLineItemAmount = 99_999.9999999999
+)
+
+var ledgerUnit = decimal.New(1, LedgerScale)
+
+// Decimal 把 float64 金额转换为 decimal；NaN / ±Inf 视为 0，避免脏数据进入账目。
+func Decimal(v float64) decimal.Decimal {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat(v)
+}
+
+// Float 把 decimal 金额转换回 float64。定格后的金额不超过 17 位有效数字，
+// 转换结果再经 Decimal 读回时与原值相等。
+func Float(d decimal.Decimal) float64 {
+	return d.InexactFloat64()
+}
+
+// Round 把金额舍入到 scale 位小数（half-away-from-zero）。
+func Round(v float64, scale int32) float64 {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	return Float(Decimal(v).Round(scale))
+}
+
+// RoundLedger 把金额舍入到 LedgerScale。
+func RoundLedger(v float64) float64 {
+	return Round(v, LedgerScale)
+}
+
+// RoundLineItem 把金额舍入到 LineItemScale。
+func RoundLineItem(v float64) float64 {
+	return Round(v, LineItemScale)
+}
+
+// RoundLedgerPtr 是 RoundLedger 的可空版本。
+func RoundLedgerPtr(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	rounded := RoundLedger(*v)
+	return &rounded
+}
+
+// Sum 在 decimal 上精确求和，不做额外舍入。
+func Sum(values ...float64) float64 {
+	total := decimal.Zero
+	for _, v := range values {
+		total = total.Add(Decimal(v))
+	}
+	return Float(total)
+}
+
+// Mul 在 decimal 上精确计算 a × b，并舍入到 scale 位小数。
+func Mul(a, b float64, scale int32) float64 {
+	return Float(Decimal(a).Mul(Decimal(b)).Round(scale))
+}
+
+// ToUnits 把金额舍入到 LedgerScale 后换算为整数记账单位（1 单位 = 10^-8 USD）。
+// Redis 计费脚本把增量以整数单位传入 Lua，在整数上累加，避免浮点累计误差；
+// int64 可表示约 ±9.2e10 USD，远超单个余额或配额的实际取值。
+func ToUnits(v float64) int64 {
+	return Decimal(v).Round(LedgerScale).Mul(ledgerUnit).IntPart()
+}
+
+// FromUnits 把整数记账单位换算回金额。
+func FromUnits(units int64) float64 {
+	return Float(decimal.New(units, -LedgerScale))
+}
@@ -0,0 +1,92 @@
+package money
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundHalfAwayFromZero(t *testing.T) {
+	require.Equal(t, 0.00000002, RoundLedger(0.000000015))
+	require.Equal(t, -0.00000002, RoundLedger(-0.000000015))
+	require.Equal(t, 0.00000001, RoundLedger(0.0000000149))
+	require.Equal(t, 0.0000000001, RoundLineItem(0.00000000005))
+	require.Equal(t, 1.23456789, RoundLedger(1.234567885))
+
+	require.Zero(t, RoundLedger(0))
+	require.True(t, math.IsNaN(RoundLedger(math.NaN())))
+	require.True(t, Decimal(math.Inf(1)).IsZero())
+}
+
+func TestRoundIsIdempotent(t *testing.T) {
+	for _, v := range []float64{0.1, 0.30000000000000004, 123.456789012345, 1e-9, 98765.43210987} {
+		once := RoundLedger(v)
+		require.Equal(t, once, RoundLedger(once), "%v", v)
+		require.True(t, Decimal(once).Equal(Decimal(once).Round(LedgerScale)), "%v", v)
+	}
+}
+
+func TestSumReconcilesWhereFloatDrifts(t *testing.T) {
+	values := make([]float64, 0, 100000)
+	var floatSum float64
+	for i := 0; i < 100000; i++ {
+		v := RoundLedger(0.01 + float64(i%7)*0.00000013)
+		values = append(values, v)
+		floatSum += v
+	}
+	expected := decimal.Zero
+	for _, v := range values {
+		expected = expected.Add(Decimal(v))
+	}
+
+	require.True(t, Decimal(Sum(values...)).Equal(expected))
+	// float64 逐笔累加会偏离精确和，这正是月末对账漂移的来源
+	require.False(t, Decimal(floatSum).Equal(expected))
+}
+
+func TestMul(t *testing.T) {
+	require.Equal(t, 0.0045, Mul(1500, 0.000003, LineItemScale))
+	require.Equal(t, 0.3, Mul(0.1, 3, LedgerScale))
+	require.Equal(t, 0.00000001, Mul(0.000000015, 0.5, LedgerScale))
+}
+
+func TestUnitsRoundTrip(t *testing.T) {
+	require.Equal(t, int64(12345678901), ToUnits(123.45678901))
+	require.Equal(t, int64(-150000000), ToUnits(-1.5))
+	require.Equal(t, int64(2), ToUnits(0.000000015))
+	require.Equal(t, 123.45678901, FromUnits(12345678901))
+	require.Equal(t, 0.1, FromUnits(ToUnits(0.1)))
+
+	var units int64
+	for i := 0; i < 1000; i++ {
+		units += ToUnits(0.1)
+	}
+	require.Equal(t, 100.0, FromUnits(units))
+}
+
+func TestRoundLedgerPtr(t *testing.T) {
+	require.Nil(t, RoundLedgerPtr(nil))
+	v := 0.123456789
+	require.Equal(t, 0.12345679, *RoundLedgerPtr(&v))
+}
+
+// float64 只作为定格金额的载体：范围内的任意定格值经 Float / Decimal 往返后必须逐位相等。
+func TestFloatCarrierRoundTripsQuantizedAmounts(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	check := func(limit float64, scale int32) {
+		max := decimal.NewFromFloat(limit)
+		samples := []decimal.Decimal{max, max.Neg(), decimal.New(1, -scale)}
+		for i := 0; i < 200000; i++ {
+			units := rng.Int63n(max.Shift(scale).IntPart() + 1)
+			samples = append(samples, decimal.New(units, -scale))
+		}
+		for _, d := range samples {
+			require.True(t, Decimal(Float(d)).Equal(d), "%s", d)
+		}
+	}
+	check(MaxExactLedgerAmount, LedgerScale)
+	check(MaxExactLineItemAmount, LineItemScale)
+}
@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// KMSOptions configures a KMS-style decrypt endpoint.
+//
+// The request and response follow the AWS KMS Decrypt JSON shape:
+//
+//	POST <endpoint>  {"CiphertextBlob": "<base64>", "KeyId": "<key id>"}
+//	200              {"Plaintext": "<base64>"}
+//
+// Authentication is a bearer token, which fits a local signing proxy or a cloud KMS
+// gateway in front of the provider's native API.
+type KMSOptions struct {
+	Endpoint  string
+	KeyID     string
+	Token     string
+	TokenFile string
+}
+
+// KMSProvider resolves kms://<base64 ciphertext> references.
+type KMSProvider struct {
+	opts       KMSOptions
+	httpClient *http.Client
+}
+
+// NewKMSProvider creates a KMS-style decrypt provider.
+func NewKMSProvider(opts KMSOptions, httpClient *http.Client) *KMSProvider {
+	return &KMSProvider{opts: opts, httpClient: httpClient}
+}
+
+func (p *KMSProvider) Scheme() string { return SchemeKMS }
+
+func (p *KMSProvider) Fetch(ctx context.Context, locator string) (string, error) {
+	endpoint := strings.TrimSpace(p.opts.Endpoint)
+	if endpoint == "" {
+		return "", fmt.Errorf("%w: kms endpoint", ErrNotConfigured)
+	}
+	token, err := readToken(p.opts.Token, p.opts.TokenFile)
+	if err != nil {
+		return "", err
+	}
+	body := map[string]string{"CiphertextBlob": locator}
+	if keyID := strings.TrimSpace(p.opts.KeyID); keyID != "" {
+		body["KeyId"] = keyID
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("secrets: build kms request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	var resp struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := doJSON(p.httpClient, req, "kms", &resp); err != nil {
+		return "", err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("secrets: decode kms plaintext: %w", err)
+	}
+	return string(plaintext), nil
+}
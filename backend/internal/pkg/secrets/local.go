@@ -0,0 +1,35 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvProvider resolves env://NAME references.
+type EnvProvider struct{}
+
+func (EnvProvider) Scheme() string { return SchemeEnv }
+
+func (EnvProvider) Fetch(_ context.Context, locator string) (string, error) {
+	value, ok := os.LookupEnv(locator)
+	if !ok {
+		return "", fmt.Errorf("secrets: environment variable %s is not set", locator)
+	}
+	return value, nil
+}
+
+// FileProvider resolves file://PATH references, e.g. Docker/Kubernetes mounted secrets.
+// The file is re-read on every refresh so rotated mounts are picked up.
+type FileProvider struct{}
+
+func (FileProvider) Scheme() string { return SchemeFile }
+
+func (FileProvider) Fetch(_ context.Context, locator string) (string, error) {
+	data, err := os.ReadFile(locator)
+	if err != nil {
+		return "", fmt.Errorf("secrets: read file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
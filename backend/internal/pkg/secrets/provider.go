@@ -0,0 +1,61 @@
+// Package secrets resolves secret references such as "vault://secret/sub2api#totp_key"
+// to their values, so that keys and provider secrets never have to be written into
+// config.yaml, the environment or the database in plain form.
+//
+// A reference is "<scheme>://<locator>". Supported schemes:
+//
+//	env://NAME                          environment variable NAME
+//	file:///run/secrets/name            file contents (surrounding whitespace trimmed)
+//	vault://<mount>/<path>#<field>      HashiCorp Vault KV (v1 or v2) secret field
+//	vault-transit://<key>/<ciphertext>  Vault transit decrypt of "vault:v1:..." ciphertext
+//	kms://<base64 ciphertext>           KMS-style HTTP decrypt API
+//
+// Any other value is a literal and is returned unchanged.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Provider schemes accepted in references.
+const (
+	SchemeEnv          = "env"
+	SchemeFile         = "file"
+	SchemeVault        = "vault"
+	SchemeVaultTransit = "vault-transit"
+	SchemeKMS          = "kms"
+)
+
+// ErrNotConfigured is returned when a reference names a backend that has no configuration.
+var ErrNotConfigured = errors.New("secrets: backend not configured")
+
+// Provider fetches the value behind a reference locator (the part after "<scheme>://").
+type Provider interface {
+	// Scheme returns the reference scheme handled by this provider.
+	Scheme() string
+	// Fetch resolves a locator to its current value.
+	Fetch(ctx context.Context, locator string) (string, error)
+}
+
+// ParseReference splits a reference into scheme and locator.
+// ok is false for literal values, including unknown schemes.
+func ParseReference(value string) (scheme, locator string, ok bool) {
+	scheme, locator, found := strings.Cut(strings.TrimSpace(value), "://")
+	if !found || locator == "" {
+		return "", "", false
+	}
+	switch scheme {
+	case SchemeEnv, SchemeFile, SchemeVault, SchemeVaultTransit, SchemeKMS:
+		return scheme, locator, true
+	default:
+		return "", "", false
+	}
+}
+
+// IsReference reports whether value is a secret reference rather than a literal.
+func IsReference(value string) bool {
+	_, _, ok := ParseReference(value)
+	return ok
+}
@@ -0,0 +1,251 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL       = 5 * time.Minute
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// Options configures a Resolver.
+type Options struct {
+	Vault VaultOptions
+	KMS   KMSOptions
+	// CacheTTL is how long a resolved value is served without asking the backend again.
+	CacheTTL time.Duration
+	// RefreshInterval re-fetches every cached reference in the background; 0 disables it.
+	RefreshInterval time.Duration
+	// Timeout bounds each backend request.
+	Timeout time.Duration
+	// HTTPClient overrides the client used for Vault and KMS (tests, custom TLS).
+	HTTPClient *http.Client
+}
+
+type cacheEntry struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// Resolver resolves secret references with a TTL cache.
+//
+// When a backend is unreachable and a previously fetched value exists, the stale value
+// is served and a warning logged: a Vault outage must not take down payments that were
+// working a minute ago. A reference that has never been resolved fails hard.
+type Resolver struct {
+	providers       map[string]Provider
+	cacheTTL        time.Duration
+	refreshInterval time.Duration
+	timeout         time.Duration
+
+	mu       sync.RWMutex
+	cache    map[string]cacheEntry
+	onChange []func(ref string)
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewResolver creates a Resolver with the env, file, Vault, Vault transit and KMS providers.
+func NewResolver(opts Options) *Resolver {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	cacheTTL := opts.CacheTTL
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	r := &Resolver{
+		providers:       map[string]Provider{},
+		cacheTTL:        cacheTTL,
+		refreshInterval: opts.RefreshInterval,
+		timeout:         timeout,
+		cache:           map[string]cacheEntry{},
+		stopCh:          make(chan struct{}),
+	}
+	for _, p := range []Provider{
+		EnvProvider{},
+		FileProvider{},
+		NewVaultProvider(opts.Vault, client),
+		NewVaultTransitProvider(opts.Vault, client),
+		NewKMSProvider(opts.KMS, client),
+	} {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds or replaces the provider for p.Scheme().
+func (r *Resolver) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Scheme()] = p
+}
+
+// OnChange registers fn to be called with the reference whenever a background refresh
+// observes a new value. Values are never passed to callbacks, so they can log freely.
+func (r *Resolver) OnChange(fn func(ref string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = append(r.onChange, fn)
+}
+
+// Resolve returns the value behind a reference, or value itself when it is a literal.
+// A nil Resolver returns every value unchanged.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if r == nil || !IsReference(value) {
+		return value, nil
+	}
+	r.mu.RLock()
+	entry, cached := r.cache[value]
+	r.mu.RUnlock()
+	if cached && time.Since(entry.fetchedAt) < r.cacheTTL {
+		return entry.value, nil
+	}
+
+	fetched, err := r.fetch(ctx, value)
+	if err != nil {
+		if cached {
+			slog.Warn("secrets: refresh failed, serving cached value", "ref", redactReference(value), "error", err)
+			return entry.value, nil
+		}
+		return "", err
+	}
+	r.store(value, fetched)
+	return fetched, nil
+}
+
+// ResolveMap returns a copy of values with every reference resolved.
+func (r *Resolver) ResolveMap(ctx context.Context, values map[string]string) (map[string]string, error) {
+	if r == nil || values == nil {
+		return values, nil
+	}
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		resolved, err := r.Resolve(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		out[key] = resolved
+	}
+	return out, nil
+}
+
+// Refresh re-fetches every cached reference and notifies OnChange callbacks of changed values.
+func (r *Resolver) Refresh(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	refs := make([]string, 0, len(r.cache))
+	for ref := range r.cache {
+		refs = append(refs, ref)
+	}
+	r.mu.RUnlock()
+
+	for _, ref := range refs {
+		fetched, err := r.fetch(ctx, ref)
+		if err != nil {
+			slog.Warn("secrets: background refresh failed", "ref", redactReference(ref), "error", err)
+			continue
+		}
+		if r.store(ref, fetched) {
+			r.notify(ref)
+		}
+	}
+}
+
+// Start launches the background refresh loop when RefreshInterval is set.
+func (r *Resolver) Start() {
+	if r == nil || r.refreshInterval <= 0 {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), r.refreshInterval)
+				r.Refresh(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop ends the background refresh loop.
+func (r *Resolver) Stop() {
+	if r == nil {
+		return
+	}
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		r.wg.Wait()
+	})
+}
+
+func (r *Resolver) fetch(ctx context.Context, ref string) (string, error) {
+	scheme, locator, _ := ParseReference(ref)
+	r.mu.RLock()
+	p := r.providers[scheme]
+	r.mu.RUnlock()
+	if p == nil {
+		return "", fmt.Errorf("%w: %s", ErrNotConfigured, scheme)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	value, err := p.Fetch(ctx, locator)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", redactReference(ref), err)
+	}
+	return value, nil
+}
+
+// store caches value and reports whether it replaced a different cached value.
+func (r *Resolver) store(ref, value string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, existed := r.cache[ref]
+	r.cache[ref] = cacheEntry{value: value, fetchedAt: time.Now()}
+	return existed && prev.value != value
+}
+
+func (r *Resolver) notify(ref string) {
+	r.mu.RLock()
+	callbacks := append([]func(string){}, r.onChange...)
+	r.mu.RUnlock()
+	for _, fn := range callbacks {
+		fn(ref)
+	}
+}
+
+// redactReference keeps the scheme and a short prefix of the locator. Transit and KMS
+// locators are ciphertext: harmless, but long and noisy in logs.
+func redactReference(ref string) string {
+	scheme, locator, ok := ParseReference(ref)
+	if !ok {
+		return "<literal>"
+	}
+	const keep = 32
+	if len(locator) > keep {
+		locator = locator[:keep] + "..."
+	}
+	return scheme + "://" + locator
+}
@@ -0,0 +1,217 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value   string
+		scheme  string
+		locator string
+		ok      bool
+	}{
+		{"env://TOTP_KEY", SchemeEnv, "TOTP_KEY", true},
+		{"file:///run/secrets/jwt", SchemeFile, "/run/secrets/jwt", true},
+		{"vault://secret/sub2api#totp", SchemeVault, "secret/sub2api#totp", true},
+		{"vault-transit://payments/vault:v1:abc/def", SchemeVaultTransit, "payments/vault:v1:abc/def", true},
+		{"kms://AQIDBA==", SchemeKMS, "AQIDBA==", true},
+		{"https://example.com", "", "", false},
+		{"0123456789abcdef", "", "", false},
+		{"env://", "", "", false},
+	}
+	for _, tt := range tests {
+		scheme, locator, ok := ParseReference(tt.value)
+		require.Equal(t, tt.ok, ok, tt.value)
+		require.Equal(t, tt.scheme, scheme, tt.value)
+		require.Equal(t, tt.locator, locator, tt.value)
+	}
+}
+
+func TestResolver_LiteralsPassThrough(t *testing.T) {
+	r := NewResolver(Options{})
+	got, err := r.Resolve(context.Background(), "plain-secret")
+	require.NoError(t, err)
+	require.Equal(t, "plain-secret", got)
+
+	var nilResolver *Resolver
+	got, err = nilResolver.Resolve(context.Background(), "env://ANYTHING")
+	require.NoError(t, err)
+	require.Equal(t, "env://ANYTHING", got)
+}
+
+func TestResolver_EnvAndFile(t *testing.T) {
+	t.Setenv("SECRETS_TEST_VALUE", "from-env")
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	r := NewResolver(Options{})
+	got, err := r.ResolveMap(context.Background(), map[string]string{
+		"a": "env://SECRETS_TEST_VALUE",
+		"b": "file://" + path,
+		"c": "literal",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "from-env", "b": "from-file", "c": "literal"}, got)
+
+	_, err = r.Resolve(context.Background(), "env://SECRETS_TEST_MISSING")
+	require.Error(t, err)
+}
+
+func newVaultStub(t *testing.T, kv map[string]any, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if hits != nil {
+			hits.Add(1)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/data/sub2api":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": kv}})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/kv/sub2api":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": kv})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/transit/decrypt/payments":
+			var body struct {
+				Ciphertext string `json:"ciphertext"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "vault:v1:c2VhbGVk", body.Ciphertext)
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"plaintext": base64.StdEncoding.EncodeToString([]byte("transit-plain")),
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestResolver_VaultKVAndTransit(t *testing.T) {
+	srv := newVaultStub(t, map[string]any{"totp_key": "kv-value", "value": "default-field", "n": 1}, nil)
+	defer srv.Close()
+
+	r := NewResolver(Options{Vault: VaultOptions{Address: srv.URL, Token: "root"}})
+	ctx := context.Background()
+
+	got, err := r.Resolve(ctx, "vault://secret/sub2api#totp_key")
+	require.NoError(t, err)
+	require.Equal(t, "kv-value", got)
+
+	got, err = r.Resolve(ctx, "vault://secret/sub2api")
+	require.NoError(t, err)
+	require.Equal(t, "default-field", got)
+
+	got, err = r.Resolve(ctx, "vault-transit://payments/vault:v1:c2VhbGVk")
+	require.NoError(t, err)
+	require.Equal(t, "transit-plain", got)
+
+	_, err = r.Resolve(ctx, "vault://secret/sub2api#missing")
+	require.ErrorContains(t, err, "no field")
+	_, err = r.Resolve(ctx, "vault://secret/sub2api#n")
+	require.ErrorContains(t, err, "not a string")
+	_, err = r.Resolve(ctx, "vault://secret/other#x")
+	require.ErrorContains(t, err, "status 404")
+
+	v1 := NewResolver(Options{Vault: VaultOptions{Address: srv.URL, Token: "root", KVVersion: 1}})
+	got, err = v1.Resolve(ctx, "vault://kv/sub2api#totp_key")
+	require.NoError(t, err)
+	require.Equal(t, "kv-value", got)
+}
+
+func TestResolver_VaultTokenFile(t *testing.T) {
+	srv := newVaultStub(t, map[string]any{"value": "ok"}, nil)
+	defer srv.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("root\n"), 0o600))
+
+	r := NewResolver(Options{Vault: VaultOptions{Address: srv.URL, Token: "ignored", TokenFile: tokenFile}})
+	got, err := r.Resolve(context.Background(), "vault://secret/sub2api")
+	require.NoError(t, err)
+	require.Equal(t, "ok", got)
+}
+
+func TestResolver_VaultNotConfigured(t *testing.T) {
+	r := NewResolver(Options{})
+	_, err := r.Resolve(context.Background(), "vault://secret/sub2api#x")
+	require.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestResolver_KMS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer kms-token", r.Header.Get("Authorization"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "Y2lwaGVy", body["CiphertextBlob"])
+		require.Equal(t, "alias/sub2api", body["KeyId"])
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"Plaintext": base64.StdEncoding.EncodeToString([]byte("kms-plain")),
+		})
+	}))
+	defer srv.Close()
+
+	r := NewResolver(Options{KMS: KMSOptions{Endpoint: srv.URL, KeyID: "alias/sub2api", Token: "kms-token"}})
+	got, err := r.Resolve(context.Background(), "kms://Y2lwaGVy")
+	require.NoError(t, err)
+	require.Equal(t, "kms-plain", got)
+}
+
+func TestResolver_CachesAndServesStaleOnFailure(t *testing.T) {
+	var hits atomic.Int32
+	srv := newVaultStub(t, map[string]any{"value": "v1"}, &hits)
+
+	r := NewResolver(Options{Vault: VaultOptions{Address: srv.URL, Token: "root"}, CacheTTL: time.Hour})
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		got, err := r.Resolve(ctx, "vault://secret/sub2api")
+		require.NoError(t, err)
+		require.Equal(t, "v1", got)
+	}
+	require.Equal(t, int32(1), hits.Load())
+
+	// Expire the entry, then take the backend down: the last known value is served.
+	r.mu.Lock()
+	entry := r.cache["vault://secret/sub2api"]
+	entry.fetchedAt = time.Now().Add(-2 * time.Hour)
+	r.cache["vault://secret/sub2api"] = entry
+	r.mu.Unlock()
+	srv.Close()
+
+	got, err := r.Resolve(ctx, "vault://secret/sub2api")
+	require.NoError(t, err)
+	require.Equal(t, "v1", got)
+}
+
+func TestResolver_RefreshNotifiesChanges(t *testing.T) {
+	t.Setenv("SECRETS_TEST_ROTATING", "old")
+	r := NewResolver(Options{CacheTTL: time.Hour})
+	var changed []string
+	r.OnChange(func(ref string) { changed = append(changed, ref) })
+
+	got, err := r.Resolve(context.Background(), "env://SECRETS_TEST_ROTATING")
+	require.NoError(t, err)
+	require.Equal(t, "old", got)
+
+	r.Refresh(context.Background())
+	require.Empty(t, changed)
+
+	t.Setenv("SECRETS_TEST_ROTATING", "new")
+	r.Refresh(context.Background())
+	require.Equal(t, []string{"env://SECRETS_TEST_ROTATING"}, changed)
+
+	got, err = r.Resolve(context.Background(), "env://SECRETS_TEST_ROTATING")
+	require.NoError(t, err)
+	require.Equal(t, "new", got)
+}
@@ -0,0 +1,192 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	defaultVaultField        = "value"
+	defaultVaultTransitMount = "transit"
+	maxSecretResponseBytes   = 1 << 20
+)
+
+// VaultOptions configures access to a HashiCorp Vault compatible HTTP API.
+type VaultOptions struct {
+	Address string
+	// Token is sent as X-Vault-Token. TokenFile takes precedence and is re-read on
+	// every request, so a token renewed by Vault Agent is picked up without restart.
+	Token     string
+	TokenFile string
+	Namespace string
+	// KVVersion is 1 or 2 (default 2).
+	KVVersion int
+	// TransitMount is the mount path of the transit engine (default "transit").
+	TransitMount string
+}
+
+// VaultProvider resolves vault://<mount>/<path>#<field> references against the KV engine.
+// The field defaults to "value".
+type VaultProvider struct {
+	opts       VaultOptions
+	httpClient *http.Client
+}
+
+// NewVaultProvider creates a Vault KV provider.
+func NewVaultProvider(opts VaultOptions, httpClient *http.Client) *VaultProvider {
+	return &VaultProvider{opts: opts, httpClient: httpClient}
+}
+
+func (p *VaultProvider) Scheme() string { return SchemeVault }
+
+func (p *VaultProvider) Fetch(ctx context.Context, locator string) (string, error) {
+	secretPath, field, _ := strings.Cut(locator, "#")
+	if field == "" {
+		field = defaultVaultField
+	}
+	mount, path, ok := strings.Cut(strings.Trim(secretPath, "/"), "/")
+	if !ok || mount == "" || path == "" {
+		return "", fmt.Errorf("secrets: vault reference must be vault://<mount>/<path>[#field]")
+	}
+
+	apiPath := "/v1/" + mount + "/data/" + path
+	if p.opts.KVVersion == 1 {
+		apiPath = "/v1/" + mount + "/" + path
+	}
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := vaultRequest(ctx, p.httpClient, p.opts, http.MethodGet, apiPath, nil, &resp); err != nil {
+		return "", err
+	}
+	data := resp.Data
+	if p.opts.KVVersion != 1 {
+		inner, _ := resp.Data["data"].(map[string]any)
+		data = inner
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secrets: vault secret %s has no field %q", secretPath, field)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secrets: vault secret %s field %q is not a string", secretPath, field)
+	}
+	return s, nil
+}
+
+// VaultTransitProvider resolves vault-transit://<key>/<ciphertext> references by calling
+// the transit decrypt endpoint. The ciphertext ("vault:v1:...") can be stored in config
+// or the database; only Vault can turn it back into the key.
+type VaultTransitProvider struct {
+	opts       VaultOptions
+	httpClient *http.Client
+}
+
+// NewVaultTransitProvider creates a Vault transit decrypt provider.
+func NewVaultTransitProvider(opts VaultOptions, httpClient *http.Client) *VaultTransitProvider {
+	return &VaultTransitProvider{opts: opts, httpClient: httpClient}
+}
+
+func (p *VaultTransitProvider) Scheme() string { return SchemeVaultTransit }
+
+func (p *VaultTransitProvider) Fetch(ctx context.Context, locator string) (string, error) {
+	key, ciphertext, ok := strings.Cut(locator, "/")
+	if !ok || key == "" || ciphertext == "" {
+		return "", fmt.Errorf("secrets: vault-transit reference must be vault-transit://<key>/<ciphertext>")
+	}
+	mount := strings.Trim(p.opts.TransitMount, "/")
+	if mount == "" {
+		mount = defaultVaultTransitMount
+	}
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": ciphertext}
+	if err := vaultRequest(ctx, p.httpClient, p.opts, http.MethodPost, "/v1/"+mount+"/decrypt/"+url.PathEscape(key), body, &resp); err != nil {
+		return "", err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("secrets: decode vault transit plaintext: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func vaultRequest(ctx context.Context, client *http.Client, opts VaultOptions, method, apiPath string, body any, out any) error {
+	address := strings.TrimRight(strings.TrimSpace(opts.Address), "/")
+	if address == "" {
+		return fmt.Errorf("%w: vault address", ErrNotConfigured)
+	}
+	token, err := readToken(opts.Token, opts.TokenFile)
+	if err != nil {
+		return err
+	}
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, address+apiPath, reader)
+	if err != nil {
+		return fmt.Errorf("secrets: build vault request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if ns := strings.TrimSpace(opts.Namespace); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	return doJSON(client, req, "vault", out)
+}
+
+func readToken(token, tokenFile string) (string, error) {
+	if tokenFile = strings.TrimSpace(tokenFile); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("secrets: read token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// doJSON executes req and decodes a JSON response. Error responses are reported by status
+// only: backends may echo request data, which must not end up in logs.
+func doJSON(client *http.Client, req *http.Request, backend string, out any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("secrets: %s request: %w", backend, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretResponseBytes))
+	if err != nil {
+		return fmt.Errorf("secrets: read %s response: %w", backend, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("secrets: %s returned status %d", backend, resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("secrets: decode %s response: %w", backend, err)
+	}
+	return nil
+}
@@ -8,6 +8,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -75,14 +78,16 @@ func FromContext(ctx context.Context) (*Collector, bool) {
 	return collector, ok && collector != nil
 }
 
-// Active reports whether timing collection is enabled for this request.
+// Active reports whether timing collection is enabled for this request, either
+// for the Server-Timing header or because the request is being traced.
 func Active(ctx context.Context) bool {
 	_, ok := FromContext(ctx)
-	return ok
+	return ok || tracing.Recording(ctx)
 }
 
 // Record adds a completed interval and operation count to a metric.
 func Record(ctx context.Context, name string, startedAt, endedAt time.Time, count int) {
+	traceInterval(ctx, name, startedAt, endedAt)
 	collector, ok := FromContext(ctx)
 	if !ok {
 		return
@@ -93,6 +98,7 @@ func Record(ctx context.Context, name string, startedAt, endedAt time.Time, coun
 // RecordInterval adds timing without incrementing the operation count. It is
 // useful when one logical operation has multiple blocking driver calls.
 func RecordInterval(ctx context.Context, name string, startedAt, endedAt time.Time) {
+	traceInterval(ctx, name, startedAt, endedAt)
 	collector, ok := FromContext(ctx)
 	if !ok {
 		return
@@ -100,6 +106,19 @@ func RecordInterval(ctx context.Context, name string, startedAt, endedAt time.Ti
 	collector.record(name, startedAt, endedAt, 0)
 }
 
+// traceInterval mirrors a timing phase as a child span of the active trace, so
+// db/redis/dep_* phases show up in OTLP exports without separate instrumentation.
+func traceInterval(ctx context.Context, name string, startedAt, endedAt time.Time) {
+	if !tracing.Recording(ctx) {
+		return
+	}
+	name = normalizeMetricName(name)
+	if name == "" {
+		return
+	}
+	tracing.RecordSpan(ctx, name, startedAt, endedAt, attribute.String("servertiming.metric", name))
+}
+
 // Record adds a completed interval directly to the collector.
 func (c *Collector) Record(name string, startedAt, endedAt time.Time, count int) {
 	if count <= 0 {
@@ -133,15 +152,22 @@ func (c *Collector) record(name string, startedAt, endedAt time.Time, count int)
 // Observe starts a metric span and returns an idempotent completion function.
 func Observe(ctx context.Context, name string) func() {
 	collector, ok := FromContext(ctx)
+	traced := tracing.Recording(ctx)
 	name = normalizeMetricName(name)
-	if !ok || name == "" {
+	if (!ok && !traced) || name == "" {
 		return func() {}
 	}
 	startedAt := time.Now()
 	var once sync.Once
 	return func() {
 		once.Do(func() {
-			collector.Record(name, startedAt, time.Now(), 1)
+			endedAt := time.Now()
+			if traced {
+				traceInterval(ctx, name, startedAt, endedAt)
+			}
+			if ok {
+				collector.Record(name, startedAt, endedAt, 1)
+			}
 		})
 	}
 }
@@ -0,0 +1,205 @@
+// Package textpdf 生成只含文字与直线的简单 PDF（A4 纵向），用于票据等结构固定的文档。
+//
+// 不嵌入字体：纯 ASCII 文本使用 PDF 标准字体 Helvetica / Helvetica-Bold，
+// 含非 ASCII 字符的文本整串使用预定义 CJK 字体 STSong-Light（UniGB-UCS2-H 编码），
+// 由阅读器提供字形。BMP 之外的字符以 '?' 代替。坐标单位为 pt，原点在页面左下角。
+package textpdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 页面尺寸（pt）。
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+	fontCJK     = "F3"
+)
+
+// Document 按页累积内容流，Bytes 时一次性输出完整 PDF。
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+// New 创建只含一张空白页的文档。
+func New() *Document {
+	d := &Document{}
+	d.AddPage()
+	return d
+}
+
+// AddPage 追加一页，之后的绘制都落在新页上。
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount 返回当前页数。
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// Text 在 (x, y) 处以 size 号字左对齐绘制一行文字，y 为基线。
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	if s == "" {
+		return
+	}
+	font, encoded := encodeText(s, bold)
+	fmt.Fprintf(d.current(), "BT /%s %s Tf %s %s Td %s Tj ET\n", font, num(size), num(x), num(y), encoded)
+}
+
+// TextRight 以 right 为右边界右对齐绘制文字，宽度按 TextWidth 估算。
+func (d *Document) TextRight(right, y, size float64, bold bool, s string) {
+	d.Text(right-TextWidth(s, size), y, size, bold, s)
+}
+
+// Line 以 width 线宽绘制一条直线。
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.current(), "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// TextWidth 估算文字宽度（pt）：ASCII 按 Helvetica 字宽，其余字符按全角计。
+// 只用于右对齐金额等短文本，不追求精确排版。
+func TextWidth(s string, size float64) float64 {
+	cjk := !isASCII(s)
+	var units float64
+	for _, r := range s {
+		switch {
+		case cjk && r < 0x80:
+			units += 500
+		case cjk:
+			units += 1000
+		default:
+			units += helveticaWidth(r)
+		}
+	}
+	return units * size / 1000
+}
+
+// Bytes 输出完整的 PDF 文件内容。
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	offsets := make([]int, 0, 6+2*len(d.pages))
+	writeObj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 对象编号：1 Catalog，2 Pages，3-6 字体，之后每页依次为 Page 与内容流。
+	const firstPageObj = 7
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageObj+2*i))
+	}
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [6 0 R] >>")
+	writeObj("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /DW 1000 /W [1 95 500] >>")
+
+	for i, page := range d.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), fontRegular, fontBold, fontCJK, firstPageObj+2*i+1))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// encodeText 选择字体并编码文字：ASCII 为转义后的字面量字符串，其余为 UTF-16BE 十六进制串。
+func encodeText(s string, bold bool) (string, string) {
+	if isASCII(s) {
+		font := fontRegular
+		if bold {
+			font = fontBold
+		}
+		var b strings.Builder
+		b.WriteByte('(')
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			switch {
+			case c == '(' || c == ')' || c == '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case c < 0x20 || c == 0x7f:
+				b.WriteByte(' ')
+			default:
+				b.WriteByte(c)
+			}
+		}
+		b.WriteByte(')')
+		return font, b.String()
+	}
+
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		if r > 0xffff || utf16.IsSurrogate(r) {
+			r = '?'
+		}
+		if r < 0x20 {
+			r = ' '
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	b.WriteByte('>')
+	return fontCJK, b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// helveticaWidth 返回常用 ASCII 字符在 Helvetica 下的字宽（1/1000 em），未列出的按 556 计。
+func helveticaWidth(r rune) float64 {
+	switch {
+	case r >= '0' && r <= '9':
+		return 556
+	case r == ' ' || r == '.' || r == ',' || r == ':' || r == ';' || r == '/' || r == 'f' || r == 't' || r == 'I':
+		return 278
+	case r == 'i' || r == 'j' || r == 'l':
+		return 222
+	case r == '-' || r == '(' || r == ')' || r == 'r':
+		return 333
+	case r == 'm' || r == 'M':
+		return 833
+	case r == 'w' || r == 'W':
+		return 722
+	case r >= 'A' && r <= 'Z':
+		return 667
+	default:
+		return 556
+	}
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
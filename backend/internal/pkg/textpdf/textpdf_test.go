@@ -0,0 +1,59 @@
+package textpdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentBytesHasValidXref(t *testing.T) {
+	d := New()
+	d.Text(50, 800, 12, true, "Invoice INV-2026-000001")
+	d.Line(50, 790, 545, 790, 0.5)
+	d.AddPage()
+	d.TextRight(545, 700, 10, false, "1,234.56")
+	out := d.Bytes()
+
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	require.Contains(t, string(out), "/Count 2")
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	// 每个 xref 条目必须指向对应编号的对象。
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	require.Len(t, entries, 6+2*2)
+	for i, e := range entries {
+		off, err := strconv.Atoi(string(e[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(out[off:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	font, s := encodeText(`a(b)\c`, false)
+	require.Equal(t, fontRegular, font)
+	require.Equal(t, `(a\(b\)\\c)`, s)
+
+	font, _ = encodeText("Total", true)
+	require.Equal(t, fontBold, font)
+
+	font, s = encodeText("发票 A", false)
+	require.Equal(t, fontCJK, font)
+	require.Equal(t, "<53D1796800200041>", s)
+
+	_, s = encodeText("x😀", false)
+	require.Equal(t, "<0078003F>", s)
+}
+
+func TestTextWidth(t *testing.T) {
+	require.InDelta(t, 5.56*3+2.78, TextWidth("1.00", 10), 1e-9)
+	require.InDelta(t, 20+5, TextWidth("发票A", 10), 1e-9)
+}
@@ -0,0 +1,201 @@
+// Package tracing records request spans for the gateway forward path on top of
+// the OpenTelemetry SDK. Spans are exported over OTLP (HTTP or gRPC) or written
+// to stdout/a file, and traces started by clients that send a W3C traceparent
+// header are continued.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter kinds accepted by InitOptions.Exporter.
+const (
+	ExporterOTLPHTTP = "otlp_http"
+	ExporterOTLPGRPC = "otlp_grpc"
+	ExporterStdout   = "stdout"
+	ExporterFile     = "file"
+)
+
+const (
+	defaultOTLPHTTPPath     = "/v1/traces"
+	defaultMaxSpansPerTrace = 512
+)
+
+// InitOptions describes the process-wide tracer provider.
+type InitOptions struct {
+	Enabled          bool
+	ServiceName      string
+	ServiceVersion   string
+	Environment      string
+	Exporter         string
+	Endpoint         string
+	Headers          map[string]string
+	FilePath         string
+	SampleRatio      float64
+	ExportTimeout    time.Duration
+	BatchSize        int
+	QueueSize        int
+	FlushInterval    time.Duration
+	MaxSpansPerTrace int
+}
+
+// Init builds the configured exporter, installs an SDK tracer provider and the
+// W3C trace-context propagator as the process-wide defaults. The returned
+// shutdown function flushes pending spans; it is safe to call when tracing is
+// disabled.
+func Init(opts InitOptions) (func(context.Context) error, error) {
+	if !opts.Enabled {
+		install(nil, 0)
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := newExporter(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := newResource(opts)
+	if err != nil {
+		return nil, err
+	}
+	provider := NewTracerProvider(exporter, opts, sdktrace.WithResource(res))
+	install(provider, opts.MaxSpansPerTrace)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return func(ctx context.Context) error {
+		uninstall(provider)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// NewTracerProvider wires exporter behind a batch span processor and a
+// parent-based ratio sampler: new traces are sampled at opts.SampleRatio and
+// traces continued from an incoming traceparent follow the caller's flag.
+// Zero batch settings fall back to the SDK defaults.
+func NewTracerProvider(exporter sdktrace.SpanExporter, opts InitOptions, extra ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	var batchOpts []sdktrace.BatchSpanProcessorOption
+	if opts.BatchSize > 0 {
+		batchOpts = append(batchOpts, sdktrace.WithMaxExportBatchSize(opts.BatchSize))
+	}
+	if opts.QueueSize > 0 {
+		batchOpts = append(batchOpts, sdktrace.WithMaxQueueSize(max(opts.QueueSize, opts.BatchSize)))
+	}
+	if opts.FlushInterval > 0 {
+		batchOpts = append(batchOpts, sdktrace.WithBatchTimeout(opts.FlushInterval))
+	}
+	if opts.ExportTimeout > 0 {
+		batchOpts = append(batchOpts, sdktrace.WithExportTimeout(opts.ExportTimeout))
+	}
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithBatcher(exporter, batchOpts...),
+	}
+	return sdktrace.NewTracerProvider(append(providerOpts, extra...)...)
+}
+
+func newResource(opts InitOptions) (*resource.Resource, error) {
+	serviceName := strings.TrimSpace(opts.ServiceName)
+	if serviceName == "" {
+		serviceName = "sub2api"
+	}
+	attrs := []attribute.KeyValue{attribute.String("service.name", serviceName)}
+	if v := strings.TrimSpace(opts.ServiceVersion); v != "" {
+		attrs = append(attrs, attribute.String("service.version", v))
+	}
+	if env := strings.TrimSpace(opts.Environment); env != "" {
+		attrs = append(attrs, attribute.String("deployment.environment", env))
+	}
+	return resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+}
+
+func newExporter(ctx context.Context, opts InitOptions) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(strings.TrimSpace(opts.Exporter)) {
+	case ExporterOTLPHTTP, "":
+		endpoint, err := normalizeOTLPEndpoint(opts.Endpoint, defaultOTLPHTTPPath)
+		if err != nil {
+			return nil, err
+		}
+		httpOpts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint), otlptracehttp.WithHeaders(opts.Headers)}
+		if opts.ExportTimeout > 0 {
+			httpOpts = append(httpOpts, otlptracehttp.WithTimeout(opts.ExportTimeout))
+		}
+		return otlptracehttp.New(ctx, httpOpts...)
+	case ExporterOTLPGRPC:
+		endpoint, err := normalizeOTLPEndpoint(opts.Endpoint, "")
+		if err != nil {
+			return nil, err
+		}
+		grpcOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpointURL(endpoint), otlptracegrpc.WithHeaders(opts.Headers)}
+		if opts.ExportTimeout > 0 {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithTimeout(opts.ExportTimeout))
+		}
+		return otlptracegrpc.New(ctx, grpcOpts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		return newFileExporter(opts.FilePath)
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", opts.Exporter)
+	}
+}
+
+// normalizeOTLPEndpoint validates a collector URL and, for OTLP/HTTP, appends
+// the default signal path when none is given. The scheme decides TLS.
+func normalizeOTLPEndpoint(endpoint, defaultPath string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("tracing: endpoint must be an http(s) URL, got %q", endpoint)
+	}
+	if defaultPath != "" && (u.Path == "" || u.Path == "/") {
+		u.Path = defaultPath
+	}
+	return u.String(), nil
+}
+
+// fileExporter writes one JSON span per line and closes the file on shutdown.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	closer io.Closer
+}
+
+func newFileExporter(path string) (*fileExporter, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("tracing: file exporter requires a file path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("tracing: create trace directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: open trace file: %w", err)
+	}
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &fileExporter{SpanExporter: exp, closer: f}, nil
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if closeErr := e.closer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of every span created here.
+const ScopeName = "github.com/Wei-Shaw/sub2api"
+
+// DroppedSpansAttribute is set on the root span when MaxSpansPerTrace cut
+// child spans from the trace.
+const DroppedSpansAttribute = "sub2api.dropped_spans"
+
+// propagator only understands W3C traceparent/tracestate. Incoming baggage and
+// vendor headers are deliberately ignored.
+var propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+type state struct {
+	provider         *sdktrace.TracerProvider
+	tracer           trace.Tracer
+	maxSpansPerTrace int
+}
+
+var current atomic.Pointer[state]
+
+func install(provider *sdktrace.TracerProvider, maxSpansPerTrace int) {
+	if provider == nil {
+		current.Store(nil)
+		return
+	}
+	if maxSpansPerTrace <= 0 {
+		maxSpansPerTrace = defaultMaxSpansPerTrace
+	}
+	current.Store(&state{
+		provider:         provider,
+		tracer:           provider.Tracer(ScopeName),
+		maxSpansPerTrace: maxSpansPerTrace,
+	})
+}
+
+func uninstall(provider *sdktrace.TracerProvider) {
+	if s := current.Load(); s != nil && s.provider == provider {
+		current.CompareAndSwap(s, nil)
+	}
+}
+
+// Enabled reports whether a process-wide tracer provider is installed.
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Extract returns ctx carrying the remote parent described by the W3C
+// traceparent header in h, if any, so the next Start continues that trace.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return propagator.Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// Start creates a child of the recording span in ctx using that span's
+// provider, or a new root (or child of a remote parent) using the process-wide
+// provider. The returned span is non-recording when tracing is disabled, the
+// trace is not sampled or the per-trace span budget is used up; ctx is then
+// returned unchanged apart from the sampling decision.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if name == "" {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	if parent := trace.SpanFromContext(ctx); parent.IsRecording() {
+		if !budgetFromContext(ctx).take() {
+			return ctx, trace.SpanFromContext(context.Background())
+		}
+		return parent.TracerProvider().Tracer(ScopeName).Start(ctx, name, opts...)
+	}
+	s := current.Load()
+	if s == nil {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	ctx, span := s.tracer.Start(ctx, name, opts...)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	return context.WithValue(ctx, traceBudgetKey{}, &traceBudget{root: span, remaining: s.maxSpansPerTrace - 1}), span
+}
+
+// Recording reports whether ctx carries a span that is still being recorded.
+// Instrumentation uses it as a cheap guard before measuring anything.
+func Recording(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	return trace.SpanFromContext(ctx).IsRecording()
+}
+
+// RecordSpan records an already finished child span of the span in ctx. It is
+// used to convert measurements taken elsewhere (servertiming phases) into spans
+// and does nothing when ctx carries no recording span.
+func RecordSpan(ctx context.Context, name string, startedAt, endedAt time.Time, attrs ...attribute.KeyValue) {
+	if !Recording(ctx) || startedAt.IsZero() || endedAt.Before(startedAt) {
+		return
+	}
+	_, span := Start(ctx, name, trace.WithTimestamp(startedAt), trace.WithAttributes(attrs...))
+	span.End(trace.WithTimestamp(endedAt))
+}
+
+type traceBudgetKey struct{}
+
+// traceBudget bounds the number of spans a single local trace may produce, so
+// that a request issuing thousands of Redis commands cannot flood the exporter.
+type traceBudget struct {
+	root trace.Span
+
+	mu        sync.Mutex
+	remaining int
+	dropped   int
+}
+
+func budgetFromContext(ctx context.Context) *traceBudget {
+	b, _ := ctx.Value(traceBudgetKey{}).(*traceBudget)
+	return b
+}
+
+func (b *traceBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	if b.remaining > 0 {
+		b.remaining--
+		b.mu.Unlock()
+		return true
+	}
+	b.dropped++
+	dropped := b.dropped
+	b.mu.Unlock()
+	b.root.SetAttributes(attribute.Int(DroppedSpansAttribute, dropped))
+	return false
+}
@@ -0,0 +1,251 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// installTestProvider installs an in-memory provider as the process-wide one.
+func installTestProvider(t *testing.T, opts InitOptions) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(exp, opts)
+	install(provider, opts.MaxSpansPerTrace)
+	t.Cleanup(func() {
+		uninstall(provider)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exp
+}
+
+func spansByName(provider *sdktrace.TracerProvider, exp *tracetest.InMemoryExporter, name string) tracetest.SpanStubs {
+	_ = provider.ForceFlush(context.Background())
+	var out tracetest.SpanStubs
+	for _, s := range exp.GetSpans() {
+		if s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func attrValue(attrs []attribute.KeyValue, key string) any {
+	for _, a := range attrs {
+		if string(a.Key) == key {
+			return a.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func headerWithTraceparent(value string) http.Header {
+	h := http.Header{}
+	h.Set("traceparent", value)
+	return h
+}
+
+func TestExtract_W3CTraceparent(t *testing.T) {
+	sc := trace.SpanContextFromContext(Extract(context.Background(), headerWithTraceparent(testTraceparent)))
+	require.True(t, sc.IsValid())
+	require.True(t, sc.IsRemote())
+	require.True(t, sc.IsSampled())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", sc.SpanID().String())
+
+	for _, invalid := range []string{
+		"",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+	} {
+		sc := trace.SpanContextFromContext(Extract(context.Background(), headerWithTraceparent(invalid)))
+		require.False(t, sc.IsValid(), invalid)
+	}
+}
+
+func TestStart_ParentChildAndRemoteParent(t *testing.T) {
+	exp := installTestProvider(t, InitOptions{SampleRatio: 1})
+	provider := current.Load().provider
+
+	ctx := Extract(context.Background(), headerWithTraceparent(testTraceparent))
+	remote := trace.SpanContextFromContext(ctx)
+
+	ctx, root := Start(ctx, "root", trace.WithSpanKind(trace.SpanKindServer))
+	require.True(t, root.IsRecording())
+	_, child := Start(ctx, "child", trace.WithAttributes(attribute.Int64("account.id", 7)))
+	require.True(t, child.IsRecording())
+	child.RecordError(io.ErrUnexpectedEOF)
+	child.SetStatus(codes.Error, io.ErrUnexpectedEOF.Error())
+	child.End()
+	RecordSpan(ctx, "redis", time.Now().Add(-time.Millisecond), time.Now())
+	root.End()
+
+	roots := spansByName(provider, exp, "root")
+	require.Len(t, roots, 1)
+	require.Equal(t, remote.TraceID(), roots[0].SpanContext.TraceID())
+	require.Equal(t, remote.SpanID(), roots[0].Parent.SpanID())
+	require.Equal(t, trace.SpanKindServer, roots[0].SpanKind)
+
+	children := spansByName(provider, exp, "child")
+	require.Len(t, children, 1)
+	require.Equal(t, roots[0].SpanContext.SpanID(), children[0].Parent.SpanID())
+	require.Equal(t, codes.Error, children[0].Status.Code)
+	require.Equal(t, int64(7), attrValue(children[0].Attributes, "account.id"))
+	require.Len(t, spansByName(provider, exp, "redis"), 1)
+}
+
+func TestStart_UnsampledRemoteParentIsNotRecorded(t *testing.T) {
+	installTestProvider(t, InitOptions{SampleRatio: 1})
+
+	ctx := Extract(context.Background(), headerWithTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	ctx, span := Start(ctx, "root")
+	require.False(t, span.IsRecording())
+	require.False(t, Recording(ctx))
+	span.End()
+}
+
+func TestStart_SampleRatioZeroDropsNewTraces(t *testing.T) {
+	installTestProvider(t, InitOptions{SampleRatio: 0})
+	_, span := Start(context.Background(), "root")
+	require.False(t, span.IsRecording())
+}
+
+func TestStart_DisabledIsNoop(t *testing.T) {
+	shutdown, err := Init(InitOptions{})
+	require.NoError(t, err)
+	require.False(t, Enabled())
+	ctx, span := Start(context.Background(), "root")
+	require.False(t, span.IsRecording())
+	require.False(t, Recording(ctx))
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestStart_MaxSpansPerTrace(t *testing.T) {
+	exp := installTestProvider(t, InitOptions{SampleRatio: 1, MaxSpansPerTrace: 3})
+	provider := current.Load().provider
+
+	ctx, root := Start(context.Background(), "root")
+	for i := 0; i < 5; i++ {
+		RecordSpan(ctx, "db", time.Now(), time.Now())
+	}
+	root.End()
+
+	require.Len(t, spansByName(provider, exp, "db"), 2)
+	require.Equal(t, int64(3), attrValue(spansByName(provider, exp, "root")[0].Attributes, DroppedSpansAttribute))
+}
+
+func TestInit_FileExporterWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "spans.jsonl")
+	shutdown, err := Init(InitOptions{
+		Enabled:     true,
+		ServiceName: "sub2api-test",
+		Exporter:    ExporterFile,
+		FilePath:    path,
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+	require.True(t, Enabled())
+
+	_, span := Start(context.Background(), "gateway.attempt", trace.WithAttributes(attribute.String("account.platform", "openai"), attribute.Bool("stream", true)))
+	require.True(t, span.IsRecording())
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	require.False(t, Enabled())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan())
+
+	var doc struct {
+		Name        string
+		SpanContext struct {
+			TraceID string
+			SpanID  string
+		}
+		Resource []struct {
+			Key   string
+			Value struct {
+				Value any
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &doc))
+	require.Equal(t, "gateway.attempt", doc.Name)
+	require.Len(t, doc.SpanContext.TraceID, 32)
+	require.Len(t, doc.SpanContext.SpanID, 16)
+	var serviceName any
+	for _, kv := range doc.Resource {
+		if kv.Key == "service.name" {
+			serviceName = kv.Value.Value
+		}
+	}
+	require.Equal(t, "sub2api-test", serviceName)
+}
+
+func TestInit_OTLPHTTPPostsToTracesPath(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		gotPath string
+		gotAuth string
+		gotType string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	shutdown, err := Init(InitOptions{
+		Enabled:       true,
+		Exporter:      ExporterOTLPHTTP,
+		Endpoint:      srv.URL,
+		Headers:       map[string]string{"Authorization": "Bearer t"},
+		SampleRatio:   1,
+		ExportTimeout: time.Second,
+	})
+	require.NoError(t, err)
+	_, span := Start(context.Background(), "a")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "/v1/traces", gotPath)
+	require.Equal(t, "Bearer t", gotAuth)
+	require.Equal(t, "application/x-protobuf", gotType)
+}
+
+func TestInit_RejectsInvalidEndpoint(t *testing.T) {
+	_, err := Init(InitOptions{Enabled: true, Exporter: ExporterOTLPHTTP, Endpoint: "otel:4318"})
+	require.Error(t, err)
+	_, err = Init(InitOptions{Enabled: true, Exporter: ExporterOTLPGRPC, Endpoint: ""})
+	require.Error(t, err)
+	_, err = Init(InitOptions{Enabled: true, Exporter: "zipkin"})
+	require.Error(t, err)
+	require.False(t, Enabled())
+}
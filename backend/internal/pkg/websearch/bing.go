@@ -0,0 +1,86 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	bingSearchEndpoint = "https://api.bing.microsoft.com/v7.0/search"
+	bingMaxCount       = 50
+	bingProviderName   = ProviderTypeBing
+)
+
+// BingProvider implements web search via the Bing Web Search API v7.
+type BingProvider struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewBingProvider creates a Bing Web Search provider.
+// endpoint overrides the default API URL (e.g. an Azure regional endpoint); empty uses the default.
+func NewBingProvider(apiKey, endpoint string, httpClient *http.Client) *BingProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &BingProvider{apiKey: apiKey, endpoint: resolveEndpoint(endpoint, bingSearchEndpoint), httpClient: httpClient}
+}
+
+func (b *BingProvider) Name() string { return bingProviderName }
+
+func (b *BingProvider) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	u, err := url.Parse(b.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("bing: invalid endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("q", req.Query)
+	q.Set("count", strconv.Itoa(clampCount(req.MaxResults, bingMaxCount)))
+	q.Set("responseFilter", "Webpages")
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("bing: build request: %w", err)
+	}
+	httpReq.Header.Set("Ocp-Apim-Subscription-Key", b.apiKey)
+	httpReq.Header.Set("Accept", "application/json")
+
+	body, err := doProviderRequest(b.httpClient, httpReq, bingProviderName)
+	if err != nil {
+		return nil, err
+	}
+	var raw bingResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("bing: decode response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(raw.WebPages.Value))
+	for _, r := range raw.WebPages.Value {
+		results = append(results, SearchResult{
+			URL:     r.URL,
+			Title:   r.Name,
+			Snippet: r.Snippet,
+			PageAge: r.DatePublished,
+		})
+	}
+	return &SearchResponse{Results: results, Query: req.Query}, nil
+}
+
+type bingResponse struct {
+	WebPages struct {
+		Value []bingResult `json:"value"`
+	} `json:"webPages"`
+}
+
+type bingResult struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Snippet       string `json:"snippet"`
+	DatePublished string `json:"datePublished"`
+}
@@ -0,0 +1,33 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBingProvider_Search_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "bing-key", r.Header.Get("Ocp-Apim-Subscription-Key"))
+		require.Equal(t, "golang", r.URL.Query().Get("q"))
+		require.Equal(t, "50", r.URL.Query().Get("count"))
+		_, _ = w.Write([]byte(`{"webPages":{"value":[
+			{"name":"Go","url":"https://go.dev","snippet":"Go lang","datePublished":"2024-01-02"}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	p := NewBingProvider("bing-key", srv.URL, srv.Client())
+	require.Equal(t, "bing", p.Name())
+	resp, err := p.Search(context.Background(), SearchRequest{Query: "golang", MaxResults: 80})
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{{URL: "https://go.dev", Title: "Go", Snippet: "Go lang", PageAge: "2024-01-02"}}, resp.Results)
+}
+
+func TestBingProvider_DefaultEndpoint(t *testing.T) {
+	p := NewBingProvider("k", "", nil)
+	require.Equal(t, bingSearchEndpoint, p.endpoint)
+}
@@ -0,0 +1,71 @@
+package websearch
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that never change the page content.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"msclkid": true,
+	"ref":     true,
+}
+
+// DedupeResults removes results pointing at the same page, keeping the first occurrence.
+// Aggregating providers (SearXNG, Jina) often return the same page from several engines
+// or with different tracking parameters. A later duplicate fills in a missing snippet or
+// page age on the kept result.
+func DedupeResults(results []SearchResult) []SearchResult {
+	if len(results) <= 1 {
+		return results
+	}
+	out := make([]SearchResult, 0, len(results))
+	index := make(map[string]int, len(results))
+	for _, r := range results {
+		key := normalizeResultURL(r.URL)
+		if key == "" {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			if out[i].Snippet == "" {
+				out[i].Snippet = r.Snippet
+			}
+			if out[i].PageAge == "" {
+				out[i].PageAge = r.PageAge
+			}
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, r)
+	}
+	return out
+}
+
+// normalizeResultURL returns a comparison key for a result URL: scheme-insensitive,
+// lowercase host without "www.", no fragment, no tracking parameters, no trailing slash.
+func normalizeResultURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	host = strings.TrimSuffix(strings.TrimSuffix(host, ":443"), ":80")
+
+	q := u.Query()
+	for key := range q {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			q.Del(key)
+		}
+	}
+	key := host + strings.TrimRight(u.EscapedPath(), "/")
+	if encoded := q.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
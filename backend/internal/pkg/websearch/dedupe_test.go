@@ -0,0 +1,28 @@
+package websearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeResults(t *testing.T) {
+	results := []SearchResult{
+		{URL: "https://go.dev/doc/", Title: "Docs"},
+		{URL: "http://www.go.dev/doc?utm_source=x#intro", Title: "Docs again", Snippet: "filled", PageAge: "1 day"},
+		{URL: "https://go.dev/doc?page=2", Title: "Page 2"},
+		{URL: "", Title: "no url"},
+		{URL: "https://GO.dev/blog", Title: "Blog"},
+		{URL: "https://go.dev/blog?fbclid=abc", Title: "Blog dup"},
+	}
+	got := DedupeResults(results)
+	require.Len(t, got, 3)
+	require.Equal(t, SearchResult{URL: "https://go.dev/doc/", Title: "Docs", Snippet: "filled", PageAge: "1 day"}, got[0])
+	require.Equal(t, "Page 2", got[1].Title)
+	require.Equal(t, "Blog", got[2].Title)
+}
+
+func TestNormalizeResultURL_KeepsMeaningfulQuery(t *testing.T) {
+	require.Equal(t, "example.com/search?q=go", normalizeResultURL("https://example.com/search/?utm_medium=a&q=go"))
+	require.NotEqual(t, normalizeResultURL("https://example.com/a"), normalizeResultURL("https://example.com/b"))
+}
@@ -0,0 +1,94 @@
+package websearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	exaSearchEndpoint    = "https://api.exa.ai/search"
+	exaMaxCount          = 25
+	exaProviderName      = ProviderTypeExa
+	exaHighlightSentence = 3
+)
+
+// ExaProvider implements web search via the Exa search API.
+type ExaProvider struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewExaProvider creates an Exa provider; endpoint overrides the default API URL.
+func NewExaProvider(apiKey, endpoint string, httpClient *http.Client) *ExaProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &ExaProvider{apiKey: apiKey, endpoint: resolveEndpoint(endpoint, exaSearchEndpoint), httpClient: httpClient}
+}
+
+func (e *ExaProvider) Name() string { return exaProviderName }
+
+func (e *ExaProvider) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	payload := exaRequest{
+		Query:      req.Query,
+		NumResults: clampCount(req.MaxResults, exaMaxCount),
+	}
+	payload.Contents.Highlights.NumSentences = exaHighlightSentence
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("exa: encode request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("exa: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", e.apiKey)
+
+	body, err := doProviderRequest(e.httpClient, httpReq, exaProviderName)
+	if err != nil {
+		return nil, err
+	}
+	var raw exaResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("exa: decode response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(raw.Results))
+	for _, r := range raw.Results {
+		results = append(results, SearchResult{
+			URL:     r.URL,
+			Title:   r.Title,
+			Snippet: strings.Join(r.Highlights, " "),
+			PageAge: r.PublishedDate,
+		})
+	}
+	return &SearchResponse{Results: results, Query: req.Query}, nil
+}
+
+type exaRequest struct {
+	Query      string `json:"query"`
+	NumResults int    `json:"numResults"`
+	Contents   struct {
+		Highlights struct {
+			NumSentences int `json:"numSentences"`
+		} `json:"highlights"`
+	} `json:"contents"`
+}
+
+type exaResponse struct {
+	Results []exaResult `json:"results"`
+}
+
+type exaResult struct {
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	PublishedDate string   `json:"publishedDate"`
+	Highlights    []string `json:"highlights"`
+}
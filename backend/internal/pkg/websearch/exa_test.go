@@ -0,0 +1,35 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExaProvider_Search_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "exa-key", r.Header.Get("x-api-key"))
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "golang", body["query"])
+		require.Equal(t, float64(3), body["numResults"])
+		require.Equal(t, map[string]any{"highlights": map[string]any{"numSentences": float64(3)}}, body["contents"])
+		_, _ = w.Write([]byte(`{"results":[
+			{"url":"https://go.dev","title":"Go","publishedDate":"2024-01-02","highlights":["Go is fast.","Go is simple."]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	p := NewExaProvider("exa-key", srv.URL, srv.Client())
+	require.Equal(t, "exa", p.Name())
+	resp, err := p.Search(context.Background(), SearchRequest{Query: "golang", MaxResults: 3})
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{{
+		URL: "https://go.dev", Title: "Go", Snippet: "Go is fast. Go is simple.", PageAge: "2024-01-02",
+	}}, resp.Results)
+}
@@ -0,0 +1,378 @@
+package websearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyutil"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"golang.org/x/net/html"
+)
+
+const (
+	defaultFetchMaxChars  = 20000
+	maxFetchBodySize      = 5 << 20 // 5 MB
+	maxFetchRedirects     = 5
+	pageFetchTimeout      = 15 * time.Second
+	pageFetchUserAgent    = "Mozilla/5.0 (compatible; sub2api-fetch/1.0)"
+	pageFetchAcceptHeader = "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5"
+)
+
+// ErrFetchBlocked indicates the fetch target is not a public http(s) URL.
+var ErrFetchBlocked = errors.New("websearch: fetch target not allowed")
+
+// ErrUnsupportedContentType indicates the page is neither HTML nor text.
+var ErrUnsupportedContentType = errors.New("websearch: unsupported content type")
+
+// ErrFetchProxyUnavailable indicates no page-fetch client could be built for the proxy URL.
+var ErrFetchProxyUnavailable = errors.New("websearch: fetch proxy unavailable")
+
+// FetchRequest describes a single page to fetch.
+type FetchRequest struct {
+	URL      string
+	ProxyURL string // optional HTTP proxy URL
+	MaxChars int    // defaults to defaultFetchMaxChars if <= 0
+}
+
+// PageContent is the extracted text of a fetched page.
+type PageContent struct {
+	URL         string `json:"url"` // final URL after redirects
+	Title       string `json:"title,omitempty"`
+	Text        string `json:"text"`
+	ContentType string `json:"content_type"`
+	Truncated   bool   `json:"truncated,omitempty"`
+}
+
+// PageFetchOptions controls the optional page-fetch step after a search.
+type PageFetchOptions struct {
+	MaxPages int // fetch the top N results; 0 disables the step
+	MaxChars int // per-page text limit; defaults to defaultFetchMaxChars if <= 0
+}
+
+// validateFetchTarget rejects non-http(s) URLs and private/loopback hosts.
+// Resolved IPs are only checked for direct connections: through a proxy the
+// proxy does the resolution. Replaced in tests to reach httptest servers.
+var validateFetchTarget = func(raw string, viaProxy bool) error {
+	if _, err := urlvalidator.ValidateHTTPURL(raw, true, urlvalidator.ValidationOptions{}); err != nil {
+		return fmt.Errorf("%w: %s", ErrFetchBlocked, err.Error())
+	}
+	if viaProxy {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrFetchBlocked, err.Error())
+	}
+	if err := urlvalidator.ValidateResolvedIP(u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %s", ErrFetchBlocked, err.Error())
+	}
+	return nil
+}
+
+// fetchDialIPAllowed reports whether the page-fetch dialer may connect to ip.
+// Replaced in tests to reach httptest servers.
+var fetchDialIPAllowed = func(ip net.IP) bool {
+	return !urlvalidator.IsDisallowedIP(ip)
+}
+
+// pageFetchDialer dials the already-checked target IP, or the proxy.
+var pageFetchDialer = &net.Dialer{Timeout: proxyDialTimeout, KeepAlive: 30 * time.Second}
+
+// directPageFetchClient is used by FetchPage when the caller passes no client.
+var directPageFetchClient, _ = newPageFetchHTTPClient("")
+
+// dialPageFetchTarget resolves the target host and connects only to allowed IPs.
+// validateFetchTarget checks the resolved IPs before the request, but the name can
+// re-resolve to an internal address by the time the transport dials (DNS rebinding);
+// checking the IP actually being dialed closes that window.
+func dialPageFetchTarget(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("%w: no addresses for %s", ErrFetchBlocked, host)
+	}
+	var lastErr error
+	for _, ip := range ips {
+		if !fetchDialIPAllowed(ip) {
+			lastErr = fmt.Errorf("%w: resolved ip %s is not allowed", ErrFetchBlocked, ip)
+			continue
+		}
+		conn, err := pageFetchDialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// newPageFetchHTTPClient creates the client used to download result pages.
+// Direct connections go through dialPageFetchTarget; through a proxy the proxy
+// resolves the target, so only the URL-level check applies and the proxy itself
+// is dialed normally. Kept apart from newHTTPClient: search providers such as a
+// self-hosted SearXNG legitimately live on private addresses.
+// Returns error if proxyURL is invalid — never falls back to direct connection.
+func newPageFetchHTTPClient(proxyURL string) (*http.Client, error) {
+	transport := &http.Transport{
+		TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+		DialContext:           dialPageFetchTarget,
+		TLSHandshakeTimeout:   proxyTLSTimeout,
+		ResponseHeaderTimeout: pageFetchTimeout,
+	}
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", proxyURL, err)
+		}
+		transport.DialContext = pageFetchDialer.DialContext
+		if err := proxyutil.ConfigureTransportProxy(transport, parsed); err != nil {
+			return nil, fmt.Errorf("configure proxy: %w", err)
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: pageFetchTimeout}, nil
+}
+
+// FetchPage downloads req.URL and returns its readable text.
+// HTML is reduced to the main content (article/main when present, without scripts,
+// navigation and footers); plain text, JSON and XML are returned as-is.
+func FetchPage(ctx context.Context, client *http.Client, req FetchRequest) (*PageContent, error) {
+	viaProxy := req.ProxyURL != ""
+	if err := validateFetchTarget(req.URL, viaProxy); err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = directPageFetchClient
+	}
+	// Copy so the redirect policy does not leak into the shared search client.
+	fetchClient := *client
+	fetchClient.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if len(via) >= maxFetchRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+		}
+		return validateFetchTarget(r.URL.String(), viaProxy)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: build request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", pageFetchUserAgent)
+	httpReq.Header.Set("Accept", pageFetchAcceptHeader)
+
+	resp, err := fetchClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("fetch: read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch: status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+	page := &PageContent{URL: resp.Request.URL.String(), ContentType: mediaType}
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		page.Title, page.Text = extractHTMLText(body)
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		page.Text = strings.TrimSpace(strings.ToValidUTF8(string(body), ""))
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedContentType, mediaType)
+	}
+
+	maxChars := req.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultFetchMaxChars
+	}
+	page.Text, page.Truncated = truncateRunes(page.Text, maxChars)
+	return page, nil
+}
+
+// FetchPage fetches a page through the Manager's page-fetch client cache.
+func (m *Manager) FetchPage(ctx context.Context, req FetchRequest) (*PageContent, error) {
+	client, err := m.getOrCreatePageFetchClient(req.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFetchProxyUnavailable, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, pageFetchTimeout)
+	defer cancel()
+	return FetchPage(ctx, client, req)
+}
+
+// SetPageFetch enables (MaxPages > 0) or disables the page-fetch step of SearchWithBestProvider.
+func (m *Manager) SetPageFetch(opts PageFetchOptions) {
+	m.pageFetch = opts
+}
+
+// fillPageContent fetches the top results concurrently and stores their text in Content.
+// A page that fails to fetch keeps only its snippet.
+func (m *Manager) fillPageContent(ctx context.Context, resp *SearchResponse, proxyURL string) {
+	n := m.pageFetch.MaxPages
+	if n <= 0 || resp == nil {
+		return
+	}
+	if n > len(resp.Results) {
+		n = len(resp.Results)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(r *SearchResult) {
+			defer wg.Done()
+			page, err := m.FetchPage(ctx, FetchRequest{URL: r.URL, ProxyURL: proxyURL, MaxChars: m.pageFetch.MaxChars})
+			if err != nil {
+				slog.Debug("websearch: page fetch failed, keeping snippet", "url", r.URL, "error", err)
+				return
+			}
+			r.Content = page.Text
+		}(&resp.Results[i])
+	}
+	wg.Wait()
+}
+
+// skippedElements never contribute readable text.
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+	"iframe": true, "nav": true, "footer": true, "header": true, "aside": true, "form": true,
+	"button": true, "select": true,
+}
+
+// blockElements end a line of text.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "section": true, "article": true,
+	"main": true, "pre": true, "blockquote": true, "table": true, "ul": true, "ol": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "dt": true, "dd": true,
+}
+
+// extractHTMLText returns the document title and the readable text of the main content.
+func extractHTMLText(body []byte) (string, string) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", strings.TrimSpace(strings.ToValidUTF8(string(body), ""))
+	}
+	title := ""
+	if n := findElement(doc, "title"); n != nil {
+		title = collapseSpaces(nodeText(n))
+	}
+	root := findElement(doc, "article")
+	if root == nil {
+		root = findElement(doc, "main")
+	}
+	if root == nil {
+		if root = findElement(doc, "body"); root == nil {
+			root = doc
+		}
+	}
+
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if text := collapseSpaces(n.Data); text != "" {
+				if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+					sb.WriteByte(' ')
+				}
+				sb.WriteString(text)
+			}
+			return
+		case html.ElementNode:
+			if skippedElements[n.Data] {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] {
+			sb.WriteByte('\n')
+		}
+	}
+	walk(root)
+	return title, collapseBlankLines(sb.String())
+}
+
+func findElement(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return sb.String()
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func collapseBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// truncateRunes cuts s to at most maxChars runes.
+func truncateRunes(s string, maxChars int) (string, bool) {
+	if utf8.RuneCountInString(s) <= maxChars {
+		return s, false
+	}
+	runes := []rune(s)
+	return string(runes[:maxChars]), true
+}
@@ -0,0 +1,151 @@
+package websearch
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// allowLocalFetch lets FetchPage reach httptest servers for the duration of a test.
+func allowLocalFetch(t *testing.T) {
+	t.Helper()
+	origValidate, origDial := validateFetchTarget, fetchDialIPAllowed
+	validateFetchTarget = func(string, bool) error { return nil }
+	fetchDialIPAllowed = func(net.IP) bool { return true }
+	t.Cleanup(func() {
+		validateFetchTarget = origValidate
+		fetchDialIPAllowed = origDial
+	})
+}
+
+const testArticleHTML = `<!doctype html><html><head><title> Go  Release </title>
+<script>var tracking = 1;</script><style>body{}</style></head>
+<body><nav>Home | Blog</nav>
+<article><h1>Go 1.23</h1><p>Iterators are   here.</p><ul><li>range over func</li></ul></article>
+<footer>Copyright</footer></body></html>`
+
+func TestFetchPage_ExtractsArticleText(t *testing.T) {
+	allowLocalFetch(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NotEmpty(t, r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(testArticleHTML))
+	}))
+	defer srv.Close()
+
+	page, err := FetchPage(context.Background(), srv.Client(), FetchRequest{URL: srv.URL})
+	require.NoError(t, err)
+	require.Equal(t, "Go Release", page.Title)
+	require.Equal(t, "Go 1.23\nIterators are here.\nrange over func", page.Text)
+	require.Equal(t, "text/html", page.ContentType)
+	require.False(t, page.Truncated)
+}
+
+func TestFetchPage_PlainTextTruncated(t *testing.T) {
+	allowLocalFetch(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("语", 50)))
+	}))
+	defer srv.Close()
+
+	page, err := FetchPage(context.Background(), srv.Client(), FetchRequest{URL: srv.URL, MaxChars: 10})
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("语", 10), page.Text)
+	require.True(t, page.Truncated)
+}
+
+func TestFetchPage_RejectsBinaryAndErrors(t *testing.T) {
+	allowLocalFetch(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4"))
+	}))
+	defer srv.Close()
+
+	_, err := FetchPage(context.Background(), srv.Client(), FetchRequest{URL: srv.URL + "/doc.pdf"})
+	require.ErrorIs(t, err, ErrUnsupportedContentType)
+	_, err = FetchPage(context.Background(), srv.Client(), FetchRequest{URL: srv.URL + "/missing"})
+	require.ErrorContains(t, err, "status 404")
+}
+
+func TestFetchPage_BlocksPrivateTargets(t *testing.T) {
+	for _, target := range []string{
+		"http://127.0.0.1:8080/admin",
+		"http://localhost/",
+		"http://169.254.169.254/latest/meta-data",
+		"file:///etc/passwd",
+	} {
+		_, err := FetchPage(context.Background(), nil, FetchRequest{URL: target})
+		require.ErrorIs(t, err, ErrFetchBlocked, target)
+	}
+}
+
+func TestManager_SearchWithBestProvider_FetchesPageContent(t *testing.T) {
+	allowLocalFetch(t)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(testArticleHTML))
+	}))
+	defer page.Close()
+	search := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[
+			{"url":"` + page.URL + `/a","title":"A","content":"snippet a"},
+			{"url":"` + page.URL + `/a#dup","title":"A dup","content":"snippet a"},
+			{"url":"` + page.URL + `/b","title":"B","content":"snippet b"}
+		]}`))
+	}))
+	defer search.Close()
+
+	m := NewManager([]ProviderConfig{{Type: ProviderTypeSearXNG, BaseURL: search.URL}}, nil)
+	m.SetPageFetch(PageFetchOptions{MaxPages: 1, MaxChars: 7})
+	resp, providerName, err := m.SearchWithBestProvider(context.Background(), SearchRequest{Query: "go"})
+	require.NoError(t, err)
+	require.Equal(t, "searxng", providerName)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, "Go 1.23", resp.Results[0].Content)
+	require.Empty(t, resp.Results[1].Content)
+}
+
+func TestFetchPage_DialerBlocksRebindToPrivateIP(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer page.Close()
+
+	// The URL-level check passes (as if DNS answered with a public IP first),
+	// but the address actually dialed is loopback.
+	orig := validateFetchTarget
+	validateFetchTarget = func(string, bool) error { return nil }
+	t.Cleanup(func() { validateFetchTarget = orig })
+
+	m := NewManager(nil, nil)
+	_, err := m.FetchPage(context.Background(), FetchRequest{URL: page.URL})
+	require.ErrorIs(t, err, ErrFetchBlocked)
+	_, err = FetchPage(context.Background(), nil, FetchRequest{URL: page.URL})
+	require.ErrorIs(t, err, ErrFetchBlocked)
+}
+
+func TestManager_PageFetchClientIsSeparateFromSearchClient(t *testing.T) {
+	m := NewManager(nil, nil)
+	search, err := m.getOrCreateHTTPClient("")
+	require.NoError(t, err)
+	fetch, err := m.getOrCreatePageFetchClient("")
+	require.NoError(t, err)
+	require.NotSame(t, search, fetch)
+
+	_, err = m.getOrCreatePageFetchClient("://bad-url")
+	require.Error(t, err)
+	_, err = m.FetchPage(context.Background(), FetchRequest{URL: "https://example.com", ProxyURL: "://bad-url"})
+	require.ErrorIs(t, err, ErrFetchProxyUnavailable)
+}
@@ -0,0 +1,88 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	googleCSEEndpoint     = "https://www.googleapis.com/customsearch/v1"
+	googleCSEMaxCount     = 10
+	googleCSEProviderName = ProviderTypeGoogleCSE
+)
+
+// GoogleCSEProvider implements web search via the Google Custom Search JSON API.
+type GoogleCSEProvider struct {
+	apiKey     string
+	engineID   string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewGoogleCSEProvider creates a Google Programmable Search provider.
+// engineID is the search engine ID ("cx"); endpoint overrides the default API URL.
+func NewGoogleCSEProvider(apiKey, engineID, endpoint string, httpClient *http.Client) *GoogleCSEProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &GoogleCSEProvider{
+		apiKey:     apiKey,
+		engineID:   engineID,
+		endpoint:   resolveEndpoint(endpoint, googleCSEEndpoint),
+		httpClient: httpClient,
+	}
+}
+
+func (g *GoogleCSEProvider) Name() string { return googleCSEProviderName }
+
+func (g *GoogleCSEProvider) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	u, err := url.Parse(g.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("google_cse: invalid endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("key", g.apiKey)
+	q.Set("cx", g.engineID)
+	q.Set("q", req.Query)
+	q.Set("num", strconv.Itoa(clampCount(req.MaxResults, googleCSEMaxCount)))
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("google_cse: build request: %w", redactURLError(err))
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	body, err := doProviderRequest(g.httpClient, httpReq, googleCSEProviderName)
+	if err != nil {
+		return nil, err
+	}
+	var raw googleCSEResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("google_cse: decode response: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(raw.Items))
+	for _, r := range raw.Items {
+		results = append(results, SearchResult{
+			URL:     r.Link,
+			Title:   r.Title,
+			Snippet: r.Snippet,
+		})
+	}
+	return &SearchResponse{Results: results, Query: req.Query}, nil
+}
+
+type googleCSEResponse struct {
+	Items []googleCSEItem `json:"items"`
+}
+
+type googleCSEItem struct {
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	Snippet string `json:"snippet"`
+}
@@ -0,0 +1,39 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleCSEProvider_Search_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		require.Equal(t, "g-key", q.Get("key"))
+		require.Equal(t, "engine-1", q.Get("cx"))
+		require.Equal(t, "golang", q.Get("q"))
+		require.Equal(t, "10", q.Get("num"))
+		_, _ = w.Write([]byte(`{"items":[{"title":"Go","link":"https://go.dev","snippet":"Go lang"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewGoogleCSEProvider("g-key", "engine-1", srv.URL, srv.Client())
+	require.Equal(t, "google_cse", p.Name())
+	resp, err := p.Search(context.Background(), SearchRequest{Query: "golang", MaxResults: 20})
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{{URL: "https://go.dev", Title: "Go", Snippet: "Go lang"}}, resp.Results)
+}
+
+func TestGoogleCSEProvider_Search_RequestErrorHidesKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	endpoint := srv.URL
+	srv.Close()
+
+	p := NewGoogleCSEProvider("secret-key", "engine-1", endpoint, nil)
+	_, err := p.Search(context.Background(), SearchRequest{Query: "golang"})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret-key")
+}
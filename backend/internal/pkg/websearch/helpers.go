@@ -1,5 +1,13 @@
 package websearch
 
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
 const (
 	maxResponseSize   = 1 << 20 // 1 MB
 	errorBodyTruncLen = 200
@@ -12,3 +20,56 @@ func truncateBody(body []byte) string {
 	}
 	return string(body[:errorBodyTruncLen]) + "...(truncated)"
 }
+
+// doProviderRequest sends req and returns the body of a 200 response.
+// Errors are prefixed with the provider name, matching the Brave/Tavily format.
+func doProviderRequest(client *http.Client, req *http.Request, name string) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", name, redactURLError(err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%s: read body: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d: %s", name, resp.StatusCode, truncateBody(body))
+	}
+	return body, nil
+}
+
+// redactURLError strips the query string from *url.Error so API keys passed as
+// query parameters (Google CSE) never end up in logs.
+func redactURLError(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	if i := strings.IndexByte(ue.URL, '?'); i >= 0 {
+		redacted := *ue
+		redacted.URL = ue.URL[:i]
+		return &redacted
+	}
+	return err
+}
+
+// resolveEndpoint returns override when set, otherwise the provider's default endpoint.
+func resolveEndpoint(override, fallback string) string {
+	if s := strings.TrimSpace(override); s != "" {
+		return s
+	}
+	return fallback
+}
+
+// clampCount applies the default and the provider's upper bound to a requested result count.
+func clampCount(n, upper int) int {
+	if n <= 0 {
+		n = defaultMaxResults
+	}
+	if upper > 0 && n > upper {
+		n = upper
+	}
+	return n
+}
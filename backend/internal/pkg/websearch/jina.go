@@ -0,0 +1,85 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	jinaSearchEndpoint = "https://s.jina.ai/"
+	jinaProviderName   = ProviderTypeJina
+)
+
+// JinaProvider implements web search via the Jina Search API (s.jina.ai).
+type JinaProvider struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewJinaProvider creates a Jina Search provider; endpoint overrides the default API URL.
+func NewJinaProvider(apiKey, endpoint string, httpClient *http.Client) *JinaProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &JinaProvider{apiKey: apiKey, endpoint: resolveEndpoint(endpoint, jinaSearchEndpoint), httpClient: httpClient}
+}
+
+func (j *JinaProvider) Name() string { return jinaProviderName }
+
+func (j *JinaProvider) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	u, err := url.Parse(j.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("jina: invalid endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("q", req.Query)
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("jina: build request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+j.apiKey)
+	// Snippets only: full page text is billed per token and comes from the page-fetch step instead.
+	httpReq.Header.Set("X-Respond-With", "no-content")
+
+	body, err := doProviderRequest(j.httpClient, httpReq, jinaProviderName)
+	if err != nil {
+		return nil, err
+	}
+	var raw jinaResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("jina: decode response: %w", err)
+	}
+
+	count := clampCount(req.MaxResults, 0)
+	results := make([]SearchResult, 0, count)
+	for _, r := range raw.Data {
+		if len(results) >= count {
+			break
+		}
+		results = append(results, SearchResult{
+			URL:     r.URL,
+			Title:   r.Title,
+			Snippet: r.Description,
+			PageAge: r.Date,
+		})
+	}
+	return &SearchResponse{Results: results, Query: req.Query}, nil
+}
+
+type jinaResponse struct {
+	Data []jinaResult `json:"data"`
+}
+
+type jinaResult struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Date        string `json:"date"`
+}
@@ -0,0 +1,29 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJinaProvider_Search_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer jina-key", r.Header.Get("Authorization"))
+		require.Equal(t, "no-content", r.Header.Get("X-Respond-With"))
+		require.Equal(t, "golang", r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`{"data":[
+			{"url":"https://go.dev","title":"Go","description":"Go lang"},
+			{"url":"https://pkg.go.dev","title":"Pkg","description":"Packages"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	p := NewJinaProvider("jina-key", srv.URL, srv.Client())
+	require.Equal(t, "jina", p.Name())
+	resp, err := p.Search(context.Background(), SearchRequest{Query: "golang", MaxResults: 1})
+	require.NoError(t, err)
+	require.Equal(t, []SearchResult{{URL: "https://go.dev", Title: "Go", Snippet: "Go lang"}}, resp.Results)
+}
@@ -21,8 +21,11 @@ import (
 
 // ProviderConfig holds the configuration for a single search provider.
 type ProviderConfig struct {
-	Type         string `json:"type"`                    // ProviderTypeBrave | ProviderTypeTavily
-	APIKey       string `json:"api_key"`                 // secret
+	Type         string `json:"type"`                    // one of the ProviderType* constants
+	APIKey       string `json:"api_key"`                 // secret; optional for SearXNG
+	BaseURL      string `json:"base_url,omitempty"`      // SearXNG instance URL (required); endpoint override for Bing/Google CSE/Exa/Jina
+	EngineID     string `json:"engine_id,omitempty"`     // Google CSE search engine ID ("cx")
+	Priority     int    `json:"priority,omitempty"`      // failover tier: lower tiers are tried first
 	QuotaLimit   int64  `json:"quota_limit"`             // 0 = unlimited
 	SubscribedAt *int64 `json:"subscribed_at,omitempty"` // subscription start (unix seconds); quota resets monthly from this date
 	ProxyURL     string `json:"-"`                       // resolved proxy URL (not persisted)
@@ -30,13 +33,29 @@ type ProviderConfig struct {
 	ExpiresAt    *int64 `json:"expires_at,omitempty"`    // optional expiration (unix seconds)
 }
 
+// HasCredentials reports whether the config carries everything its provider type needs:
+// SearXNG needs an instance URL, Google CSE an API key and engine ID, the rest an API key.
+func (c ProviderConfig) HasCredentials() bool {
+	switch c.Type {
+	case ProviderTypeSearXNG:
+		return strings.TrimSpace(c.BaseURL) != ""
+	case ProviderTypeGoogleCSE:
+		return c.APIKey != "" && c.EngineID != ""
+	default:
+		return c.APIKey != ""
+	}
+}
+
 // Manager selects providers by quota-weighted load balancing and tracks quota via Redis.
 type Manager struct {
 	configs []ProviderConfig
 	redis   *redis.Client
 
-	clientMu    sync.Mutex
-	clientCache map[string]*http.Client
+	pageFetch PageFetchOptions
+
+	clientMu         sync.Mutex
+	clientCache      map[string]*http.Client // search provider clients
+	fetchClientCache map[string]*http.Client // page-fetch clients (checked dialer)
 }
 
 // Timeout constants for proxy and search operations.
@@ -78,15 +97,17 @@ func NewManager(configs []ProviderConfig, redisClient *redis.Client) *Manager {
 	copied := make([]ProviderConfig, len(configs))
 	copy(copied, configs)
 	return &Manager{
-		configs:     copied,
-		redis:       redisClient,
-		clientCache: make(map[string]*http.Client),
+		configs:          copied,
+		redis:            redisClient,
+		clientCache:      make(map[string]*http.Client),
+		fetchClientCache: make(map[string]*http.Client),
 	}
 }
 
-// SearchWithBestProvider selects a provider using quota-weighted load balancing,
-// reserves quota, executes the search, and rolls back quota on failure.
+// SearchWithBestProvider selects a provider by priority tier and, within a tier, quota-weighted
+// load balancing; it reserves quota, executes the search, and rolls back quota on failure.
 // If the search fails due to a proxy error, the proxy is marked unavailable for 5 minutes.
+// Results are deduplicated and, when page fetch is enabled, the top pages' text is attached.
 func (m *Manager) SearchWithBestProvider(ctx context.Context, req SearchRequest) (*SearchResponse, string, error) {
 	if strings.TrimSpace(req.Query) == "" {
 		return nil, "", fmt.Errorf("websearch: empty search query")
@@ -97,7 +118,7 @@ func (m *Manager) SearchWithBestProvider(ctx context.Context, req SearchRequest)
 		return nil, "", fmt.Errorf("websearch: no available provider (all exhausted, expired, or proxy unavailable)")
 	}
 
-	selected := m.selectByQuotaWeight(ctx, candidates)
+	selected := m.orderByPriority(ctx, candidates)
 
 	for _, cfg := range selected {
 		allowed, incremented := m.tryReserveQuota(ctx, cfg)
@@ -128,12 +149,13 @@ func (m *Manager) SearchWithBestProvider(ctx context.Context, req SearchRequest)
 				"provider", cfg.Type, "error", err)
 			continue
 		}
+		m.fillPageContent(ctx, resp, effectiveProxyURL(cfg, req.ProxyURL))
 		return resp, cfg.Type, nil
 	}
 	return nil, "", fmt.Errorf("websearch: no available provider (all exhausted or failed)")
 }
 
-// filterAvailableProviders returns providers that have API keys, are not expired,
+// filterAvailableProviders returns providers that have credentials, are not expired,
 // and whose proxies are not marked unavailable.
 func (m *Manager) filterAvailableProviders(ctx context.Context, accountProxyURL string) []ProviderConfig {
 	var out []ProviderConfig
@@ -152,6 +174,26 @@ func (m *Manager) filterAvailableProviders(ctx context.Context, accountProxyURL
 	return out
 }
 
+// orderByPriority groups candidates into priority tiers (ascending) and orders each tier
+// with selectByQuotaWeight, so a lower tier is exhausted before failing over to the next.
+// With every priority at 0 this is plain quota-weighted ordering.
+func (m *Manager) orderByPriority(ctx context.Context, candidates []ProviderConfig) []ProviderConfig {
+	tiers := make(map[int][]ProviderConfig)
+	var priorities []int
+	for _, cfg := range candidates {
+		if _, ok := tiers[cfg.Priority]; !ok {
+			priorities = append(priorities, cfg.Priority)
+		}
+		tiers[cfg.Priority] = append(tiers[cfg.Priority], cfg)
+	}
+	sort.Ints(priorities)
+	out := make([]ProviderConfig, 0, len(candidates))
+	for _, p := range priorities {
+		out = append(out, m.selectByQuotaWeight(ctx, tiers[p])...)
+	}
+	return out
+}
+
 // weighted is a provider candidate with computed quota weight.
 type weighted struct {
 	cfg    ProviderConfig
@@ -228,7 +270,7 @@ func mergeWeightedResults(withQuota, withoutQuota []weighted, capacity int) []Pr
 }
 
 func (m *Manager) isProviderAvailable(cfg ProviderConfig) bool {
-	if cfg.APIKey == "" {
+	if !cfg.HasCredentials() {
 		return false
 	}
 	if cfg.ExpiresAt != nil && time.Now().Unix() > *cfg.ExpiresAt {
@@ -369,35 +411,55 @@ func (m *Manager) TestSearch(ctx context.Context, req SearchRequest) (*SearchRes
 }
 
 func (m *Manager) executeSearch(ctx context.Context, cfg ProviderConfig, req SearchRequest) (*SearchResponse, error) {
-	proxyURL := cfg.ProxyURL
-	if req.ProxyURL != "" {
-		proxyURL = req.ProxyURL
-	}
-	client, err := m.getOrCreateHTTPClient(proxyURL)
+	client, err := m.getOrCreateHTTPClient(effectiveProxyURL(cfg, req.ProxyURL))
 	if err != nil {
 		return nil, fmt.Errorf("websearch: %w", err)
 	}
 	provider := m.buildProvider(cfg, client)
-	return provider.Search(ctx, req)
+	resp, err := provider.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Results = DedupeResults(resp.Results)
+	if limit := clampCount(req.MaxResults, 0); len(resp.Results) > limit {
+		resp.Results = resp.Results[:limit]
+	}
+	return resp, nil
+}
+
+// effectiveProxyURL returns the account proxy when set, otherwise the provider's own proxy.
+func effectiveProxyURL(cfg ProviderConfig, accountProxyURL string) string {
+	if accountProxyURL != "" {
+		return accountProxyURL
+	}
+	return cfg.ProxyURL
 }
 
 // --- HTTP client cache ---
 
 func (m *Manager) getOrCreateHTTPClient(proxyURL string) (*http.Client, error) {
+	return m.cachedHTTPClient(&m.clientCache, proxyURL, newHTTPClient)
+}
+
+func (m *Manager) getOrCreatePageFetchClient(proxyURL string) (*http.Client, error) {
+	return m.cachedHTTPClient(&m.fetchClientCache, proxyURL, newPageFetchHTTPClient)
+}
+
+func (m *Manager) cachedHTTPClient(cache *map[string]*http.Client, proxyURL string, create func(string) (*http.Client, error)) (*http.Client, error) {
 	m.clientMu.Lock()
 	defer m.clientMu.Unlock()
 
-	if c, ok := m.clientCache[proxyURL]; ok {
+	if c, ok := (*cache)[proxyURL]; ok {
 		return c, nil
 	}
-	if len(m.clientCache) >= maxCachedClients {
-		m.clientCache = make(map[string]*http.Client)
+	if len(*cache) >= maxCachedClients {
+		*cache = make(map[string]*http.Client)
 	}
-	c, err := newHTTPClient(proxyURL)
+	c, err := create(proxyURL)
 	if err != nil {
 		return nil, err
 	}
-	m.clientCache[proxyURL] = c
+	(*cache)[proxyURL] = c
 	return c, nil
 }
 
@@ -464,6 +526,16 @@ func (m *Manager) buildProvider(cfg ProviderConfig, client *http.Client) Provide
 		return NewBraveProvider(cfg.APIKey, client)
 	case tavilyProviderName:
 		return NewTavilyProvider(cfg.APIKey, client)
+	case searxngProviderName:
+		return NewSearXNGProvider(cfg.BaseURL, cfg.APIKey, client)
+	case bingProviderName:
+		return NewBingProvider(cfg.APIKey, cfg.BaseURL, client)
+	case googleCSEProviderName:
+		return NewGoogleCSEProvider(cfg.APIKey, cfg.EngineID, cfg.BaseURL, client)
+	case exaProviderName:
+		return NewExaProvider(cfg.APIKey, cfg.BaseURL, client)
+	case jinaProviderName:
+		return NewJinaProvider(cfg.APIKey, cfg.BaseURL, client)
 	default:
 		slog.Warn("websearch: unknown provider type, falling back to brave",
 			"type", cfg.Type)
@@ -321,3 +321,51 @@ func TestManager_ResetUsage_NilRedis(t *testing.T) {
 	err := m.ResetUsage(context.Background(), "brave")
 	require.NoError(t, err)
 }
+
+// --- priority tiers ---
+
+func TestOrderByPriority_LowerTierFirst(t *testing.T) {
+	m := NewManager(nil, nil)
+	candidates := []ProviderConfig{
+		{Type: "brave", APIKey: "k1", QuotaLimit: 100, Priority: 1},
+		{Type: "searxng", BaseURL: "http://searx", Priority: 0},
+		{Type: "tavily", APIKey: "k2", Priority: 2},
+		{Type: "exa", APIKey: "k3", QuotaLimit: 100, Priority: 1},
+	}
+	result := m.orderByPriority(context.Background(), candidates)
+	require.Len(t, result, 4)
+	require.Equal(t, "searxng", result[0].Type)
+	require.ElementsMatch(t, []string{"brave", "exa"}, []string{result[1].Type, result[2].Type})
+	require.Equal(t, "tavily", result[3].Type)
+}
+
+func TestManager_SearchWithBestProvider_FailsOverToNextTier(t *testing.T) {
+	var primaryHits int
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		primaryHits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"url":"https://go.dev","title":"Go","description":"from jina"}]}`))
+	}))
+	defer secondary.Close()
+
+	m := NewManager([]ProviderConfig{
+		{Type: ProviderTypeJina, APIKey: "k", BaseURL: secondary.URL, Priority: 5},
+		{Type: ProviderTypeSearXNG, BaseURL: primary.URL, Priority: 1},
+	}, nil)
+	resp, providerName, err := m.SearchWithBestProvider(context.Background(), SearchRequest{Query: "go"})
+	require.NoError(t, err)
+	require.Equal(t, 1, primaryHits)
+	require.Equal(t, "jina", providerName)
+	require.Equal(t, "from jina", resp.Results[0].Snippet)
+}
+
+func TestProviderConfig_HasCredentials(t *testing.T) {
+	require.True(t, ProviderConfig{Type: ProviderTypeSearXNG, BaseURL: "http://searx"}.HasCredentials())
+	require.False(t, ProviderConfig{Type: ProviderTypeSearXNG, APIKey: "k"}.HasCredentials())
+	require.False(t, ProviderConfig{Type: ProviderTypeGoogleCSE, APIKey: "k"}.HasCredentials())
+	require.True(t, ProviderConfig{Type: ProviderTypeGoogleCSE, APIKey: "k", EngineID: "cx"}.HasCredentials())
+	require.False(t, ProviderConfig{Type: ProviderTypeBing}.HasCredentials())
+}
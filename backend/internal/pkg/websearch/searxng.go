@@ -0,0 +1,105 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const searxngProviderName = ProviderTypeSearXNG
+
+// SearXNGProvider implements web search via a self-hosted SearXNG instance.
+// The instance must have the "json" output format enabled (search.formats in settings.yml).
+type SearXNGProvider struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewSearXNGProvider creates a SearXNG provider for the instance at baseURL.
+// apiKey is optional; when set it is sent as a bearer token for instances behind an
+// authenticating reverse proxy.
+func NewSearXNGProvider(baseURL, apiKey string, httpClient *http.Client) *SearXNGProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &SearXNGProvider{baseURL: baseURL, apiKey: apiKey, httpClient: httpClient}
+}
+
+func (s *SearXNGProvider) Name() string { return searxngProviderName }
+
+func (s *SearXNGProvider) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	endpoint, err := searxngSearchURL(s.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	q := endpoint.Query()
+	q.Set("q", req.Query)
+	q.Set("format", "json")
+	q.Set("pageno", "1")
+	endpoint.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("searxng: build request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if s.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	body, err := doProviderRequest(s.httpClient, httpReq, searxngProviderName)
+	if err != nil {
+		return nil, err
+	}
+	var raw searxngResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("searxng: decode response: %w", err)
+	}
+
+	// SearXNG has no result count parameter; it aggregates engines and returns a full page.
+	count := clampCount(req.MaxResults, 0)
+	results := make([]SearchResult, 0, count)
+	for _, r := range raw.Results {
+		if len(results) >= count {
+			break
+		}
+		results = append(results, SearchResult{
+			URL:     r.URL,
+			Title:   r.Title,
+			Snippet: r.Content,
+			PageAge: r.PublishedDate,
+		})
+	}
+	return &SearchResponse{Results: results, Query: req.Query}, nil
+}
+
+// searxngSearchURL accepts either the instance root or its /search endpoint.
+func searxngSearchURL(baseURL string) (*url.URL, error) {
+	raw := strings.TrimSpace(baseURL)
+	if raw == "" {
+		return nil, fmt.Errorf("searxng: base URL not configured")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("searxng: invalid base URL %q", raw)
+	}
+	if !strings.HasSuffix(u.Path, "/search") {
+		u.Path = strings.TrimRight(u.Path, "/") + "/search"
+	}
+	return u, nil
+}
+
+type searxngResponse struct {
+	Results []searxngResult `json:"results"`
+}
+
+type searxngResult struct {
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	PublishedDate string `json:"publishedDate"`
+}
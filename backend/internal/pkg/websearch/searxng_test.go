@@ -0,0 +1,69 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearXNGProvider_Name(t *testing.T) {
+	p := NewSearXNGProvider("http://searx.local", "", nil)
+	require.Equal(t, "searxng", p.Name())
+}
+
+func TestSearXNGProvider_Search_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/searx/search", r.URL.Path)
+		require.Equal(t, "golang", r.URL.Query().Get("q"))
+		require.Equal(t, "json", r.URL.Query().Get("format"))
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"results":[
+			{"url":"https://go.dev","title":"Go","content":"Go lang","publishedDate":"2024-01-02T00:00:00"},
+			{"url":"https://pkg.go.dev","title":"Pkg","content":"Packages","publishedDate":null},
+			{"url":"https://tour.go.dev","title":"Tour","content":"Tour"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	p := NewSearXNGProvider(srv.URL+"/searx/", "secret", srv.Client())
+	resp, err := p.Search(context.Background(), SearchRequest{Query: "golang", MaxResults: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, "Go lang", resp.Results[0].Snippet)
+	require.Equal(t, "2024-01-02T00:00:00", resp.Results[0].PageAge)
+	require.Empty(t, resp.Results[1].PageAge)
+}
+
+func TestSearXNGProvider_Search_NoAuthHeaderWithoutKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/search", r.URL.Path)
+		require.Empty(t, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer srv.Close()
+
+	p := NewSearXNGProvider(srv.URL+"/search", "", srv.Client())
+	resp, err := p.Search(context.Background(), SearchRequest{Query: "x"})
+	require.NoError(t, err)
+	require.Empty(t, resp.Results)
+}
+
+func TestSearXNGProvider_Search_JSONFormatDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	p := NewSearXNGProvider(srv.URL, "", srv.Client())
+	_, err := p.Search(context.Background(), SearchRequest{Query: "x"})
+	require.ErrorContains(t, err, "searxng: status 403")
+}
+
+func TestSearXNGProvider_Search_MissingBaseURL(t *testing.T) {
+	p := NewSearXNGProvider("", "", nil)
+	_, err := p.Search(context.Background(), SearchRequest{Query: "x"})
+	require.ErrorContains(t, err, "base URL not configured")
+}
@@ -6,6 +6,7 @@ type SearchResult struct {
 	Title   string `json:"title"`
 	Snippet string `json:"snippet"`
 	PageAge string `json:"page_age,omitempty"`
+	Content string `json:"content,omitempty"` // extracted page text, filled only when page fetch is enabled
 }
 
 // SearchRequest describes a web search to perform.
@@ -25,6 +26,11 @@ const defaultMaxResults = 5
 
 // Provider type identifiers.
 const (
-	ProviderTypeBrave  = "brave"
-	ProviderTypeTavily = "tavily"
+	ProviderTypeBrave     = "brave"
+	ProviderTypeTavily    = "tavily"
+	ProviderTypeSearXNG   = "searxng"
+	ProviderTypeBing      = "bing"
+	ProviderTypeGoogleCSE = "google_cse"
+	ProviderTypeExa       = "exa"
+	ProviderTypeJina      = "jina"
 )
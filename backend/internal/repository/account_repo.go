@@ -15,6 +15,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -41,6 +42,7 @@ import (
 //   - client: Ent 客户端，用于类型安全的 ORM 操作
 //   - sql: 原生 SQL 执行器，用于复杂查询和批量操作
 //   - schedulerCache: 调度器缓存，用于在账号状态变更时同步快照
+//   - credCipher: 凭证静态加密，写入前加密、读出后解密 credentials 敏感子键
 type accountRepository struct {
 	client *dbent.Client // Ent ORM 客户端
 	sql    sqlExecutor   // 原生 SQL 执行接口
@@ -49,6 +51,8 @@ type accountRepository struct {
 	// Used to proactively sync account snapshot to cache when status changes,
 	// ensuring sticky sessions can promptly detect unavailable accounts.
 	schedulerCache service.SchedulerCache
+	// credCipher 为 nil 时凭证按明文读写
+	credCipher *CredentialCipher
 }
 
 var schedulerNeutralExtraKeyPrefixes = []string{
@@ -106,14 +110,18 @@ func stripCodexFingerprintSeedFromExtraUpdate(extra map[string]any) map[string]a
 
 // NewAccountRepository 创建账户仓储实例。
 // 这是对外暴露的构造函数，返回接口类型以便于依赖注入。
-func NewAccountRepository(client *dbent.Client, sqlDB *sql.DB, schedulerCache service.SchedulerCache) service.AccountRepository {
-	return newAccountRepositoryWithSQL(client, sqlDB, schedulerCache)
+func NewAccountRepository(client *dbent.Client, sqlDB *sql.DB, schedulerCache service.SchedulerCache, credCipher *CredentialCipher) service.AccountRepository {
+	repo := newAccountRepositoryWithSQL(client, sqlDB, schedulerCache)
+	repo.credCipher = credCipher
+	return repo
 }
 
 // NewAdminAccountRepository exposes the account repository's atomic duplication capability
 // as an explicit dependency of the admin service.
-func NewAdminAccountRepository(client *dbent.Client, sqlDB *sql.DB, schedulerCache service.SchedulerCache) service.AdminAccountRepository {
-	return newAccountRepositoryWithSQL(client, sqlDB, schedulerCache)
+func NewAdminAccountRepository(client *dbent.Client, sqlDB *sql.DB, schedulerCache service.SchedulerCache, credCipher *CredentialCipher) service.AdminAccountRepository {
+	repo := newAccountRepositoryWithSQL(client, sqlDB, schedulerCache)
+	repo.credCipher = credCipher
+	return repo
 }
 
 // newAccountRepositoryWithSQL 是内部构造函数，支持依赖注入 SQL 执行器。
@@ -123,7 +131,7 @@ func newAccountRepositoryWithSQL(client *dbent.Client, sqlq sqlExecutor, schedul
 }
 
 func (r *accountRepository) Create(ctx context.Context, account *service.Account) error {
-	if err := createAccountRecord(ctx, r.client, account); err != nil {
+	if err := r.createAccountRecord(ctx, r.client, account); err != nil {
 		return err
 	}
 	if err := enqueueSchedulerOutbox(ctx, r.sql, service.SchedulerOutboxEventAccountChanged, &account.ID, nil, buildSchedulerGroupPayload(account.GroupIDs)); err != nil {
@@ -132,17 +140,21 @@ func (r *accountRepository) Create(ctx context.Context, account *service.Account
 	return nil
 }
 
-func createAccountRecord(ctx context.Context, client *dbent.Client, account *service.Account) error {
+func (r *accountRepository) createAccountRecord(ctx context.Context, client *dbent.Client, account *service.Account) error {
 	if account == nil {
 		return service.ErrAccountNilInput
 	}
+	credentials, err := r.credCipher.EncryptCredentials(normalizeJSONMap(account.Credentials))
+	if err != nil {
+		return err
+	}
 
 	builder := client.Account.Create().
 		SetName(account.Name).
 		SetNillableNotes(account.Notes).
 		SetPlatform(account.Platform).
 		SetType(account.Type).
-		SetCredentials(normalizeJSONMap(account.Credentials)).
+		SetCredentials(credentials).
 		SetExtra(normalizeJSONMap(account.Extra)).
 		SetConcurrency(account.Concurrency).
 		SetPriority(account.Priority).
@@ -222,7 +234,7 @@ func (r *accountRepository) CreateWithAccountGroups(ctx context.Context, account
 		txClient = r.client
 	}
 
-	if err := createAccountRecord(ctx, txClient, account); err != nil {
+	if err := r.createAccountRecord(ctx, txClient, account); err != nil {
 		return err
 	}
 	groupIDs := make([]int64, 0, len(groups))
@@ -319,7 +331,7 @@ func (r *accountRepository) GetByIDs(ctx context.Context, ids []int64) ([]*servi
 
 	outByID := make(map[int64]*service.Account, len(entAccounts))
 	for _, entAcc := range entAccounts {
-		out := accountEntityToService(entAcc)
+		out := r.accountToService(ctx, entAcc)
 		if out == nil {
 			continue
 		}
@@ -515,11 +527,15 @@ func (r *accountRepository) updateLockedAccount(
 	explicitRateSyncEnabled *bool,
 	explicitRateMultiplier *float64,
 ) (*dbent.Account, error) {
-	extra, err := lockAndMergeAccountProbeExtra(ctx, client, account, explicitProbeEnabled, explicitRateSyncEnabled)
+	extra, err := lockAndMergeAccountProbeExtra(ctx, client, r.credCipher, account, explicitProbeEnabled, explicitRateSyncEnabled)
 	if err != nil {
 		return nil, err
 	}
 	account.Extra = extra
+	credentials, err := r.credCipher.EncryptCredentials(normalizeJSONMap(account.Credentials))
+	if err != nil {
+		return nil, err
+	}
 
 	schedulable := account.Schedulable
 	if account.Status == service.StatusError {
@@ -531,7 +547,7 @@ func (r *accountRepository) updateLockedAccount(
 		SetNillableNotes(account.Notes).
 		SetPlatform(account.Platform).
 		SetType(account.Type).
-		SetCredentials(normalizeJSONMap(account.Credentials)).
+		SetCredentials(credentials).
 		SetExtra(extra).
 		SetConcurrency(account.Concurrency).
 		SetPriority(account.Priority).
@@ -607,35 +623,38 @@ func (r *accountRepository) updateLockedAccount(
 func lockAndMergeAccountProbeExtra(
 	ctx context.Context,
 	client *dbent.Client,
+	credCipher *CredentialCipher,
 	account *service.Account,
 	explicitProbeEnabled *bool,
 	explicitRateSyncEnabled *bool,
 ) (map[string]any, error) {
-	credentials, err := json.Marshal(normalizeJSONMap(account.Credentials))
-	if err != nil {
-		return nil, err
-	}
+	credentials := normalizeJSONMap(account.Credentials)
 	var proxyID any
 	if account.ProxyID != nil {
 		proxyID = *account.ProxyID
 	}
+	var baseURL any
+	if value, ok := credentials["base_url"].(string); ok {
+		baseURL = value
+	}
+	// 凭证可能以明文或旧数据密钥的密文存储，不能在 SQL 里与重新加密的值比较：
+	// 这里只锁行并取出原样的 credentials，凭证是否一致解密后在 Go 里判断。
 	rows, err := client.QueryContext(ctx, `
 		SELECT
 			platform = $2
 			AND type = $3
-			AND credentials = $4::jsonb
-			AND proxy_id IS NOT DISTINCT FROM $5,
+			AND proxy_id IS NOT DISTINCT FROM $4,
 			COALESCE(
 				platform IN ('openai', 'anthropic')
 				AND $2 IN ('openai', 'anthropic')
 				AND type = 'apikey'
 				AND $3 = 'apikey'
-				AND credentials -> 'api_key' IS NOT DISTINCT FROM $4::jsonb -> 'api_key'
 				AND `+ollamaCloudBaseURLMatchesSQL("credentials ->> 'base_url'")+`
-				AND `+ollamaCloudBaseURLMatchesSQL("$4::jsonb ->> 'base_url'")+`,
+				AND `+ollamaCloudBaseURLMatchesSQL("$5::text")+`,
 				false
 			),
-			proxy_id IS NOT DISTINCT FROM $5,
+			proxy_id IS NOT DISTINCT FROM $4,
+			credentials,
 			extra -> 'upstream_billing_probe_enabled',
 			extra -> 'upstream_billing_rate_sync_enabled',
 			extra -> 'upstream_billing_probe',
@@ -645,7 +664,7 @@ func lockAndMergeAccountProbeExtra(
 		FROM accounts
 		WHERE id = $1 AND deleted_at IS NULL
 		FOR NO KEY UPDATE
-	`, account.ID, account.Platform, account.Type, string(credentials), proxyID)
+	`, account.ID, account.Platform, account.Type, proxyID, baseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -661,6 +680,7 @@ func lockAndMergeAccountProbeExtra(
 		identityUnchanged            bool
 		ollamaGroupIdentityUnchanged bool
 		ollamaProxyIdentityUnchanged bool
+		storedCredentials            []byte
 		currentEnabled               []byte
 		currentRateSyncEnabled       []byte
 		currentSnapshot              []byte
@@ -672,6 +692,7 @@ func lockAndMergeAccountProbeExtra(
 		&identityUnchanged,
 		&ollamaGroupIdentityUnchanged,
 		&ollamaProxyIdentityUnchanged,
+		&storedCredentials,
 		&currentEnabled,
 		&currentRateSyncEnabled,
 		&currentSnapshot,
@@ -684,6 +705,14 @@ func lockAndMergeAccountProbeExtra(
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	// 无法解密的存储值按"身份已变更"处理，宁可丢弃缓存的探测结果也不沿用到新凭证上。
+	if current, err := credCipher.decryptStoredCredentials(ctx, storedCredentials); err != nil {
+		identityUnchanged = false
+		ollamaGroupIdentityUnchanged = false
+	} else {
+		identityUnchanged = identityUnchanged && credentialJSONValuesEqual(current, credentials)
+		ollamaGroupIdentityUnchanged = ollamaGroupIdentityUnchanged && credentialJSONValuesEqual(current["api_key"], credentials["api_key"])
+	}
 
 	extra := copyJSONMap(normalizeJSONMap(account.Extra))
 	for _, key := range []string{
@@ -785,7 +814,7 @@ func decodeAccountExtraJSON(raw []byte) (any, bool, error) {
 }
 
 func (r *accountRepository) UpdateCredentials(ctx context.Context, id int64, credentials map[string]any) error {
-	payload, err := json.Marshal(normalizeJSONMap(credentials))
+	payload, err := r.credCipher.credentialsJSON(normalizeJSONMap(credentials))
 	if err != nil {
 		return err
 	}
@@ -807,6 +836,18 @@ func (r *accountRepository) UpdateCredentials(ctx context.Context, id int64, cre
 			client = tx.Client()
 		}
 	}
+	// 变化判断用与库中存储形态对齐的 $3：明文行或旧数据密钥加密的行在凭证未变化时
+	// 不能因为密文不同被当作身份变化，误清探测快照与 Ollama 会话。
+	comparable := payload
+	if r.credCipher != nil {
+		stored, err := lockStoredCredentials(ctx, client, id)
+		if err != nil {
+			return err
+		}
+		if comparable, err = r.credCipher.credentialsComparableJSON(ctx, stored, normalizeJSONMap(credentials)); err != nil {
+			return err
+		}
+	}
 	result, err := client.ExecContext(ctx, `
 		UPDATE accounts
 		SET
@@ -816,9 +857,9 @@ func (r *accountRepository) UpdateCredentials(ctx context.Context, id int64, cre
 				-- 非 Ollama 账号的无变化持久化误清探测快照或重写 NULL extra。
 				WHEN platform IN ('openai', 'anthropic')
 					AND type = 'apikey'
-					AND credentials IS DISTINCT FROM $1::jsonb
+					AND credentials IS DISTINCT FROM $3::jsonb
 					AND (
-						credentials -> 'api_key' IS DISTINCT FROM $1::jsonb -> 'api_key'
+						credentials -> 'api_key' IS DISTINCT FROM $3::jsonb -> 'api_key'
 						OR NOT (
 							`+ollamaCloudBaseURLMatchesSQL("credentials ->> 'base_url'")+`
 							AND `+ollamaCloudBaseURLMatchesSQL("$1::jsonb ->> 'base_url'")+`
@@ -832,13 +873,13 @@ func (r *accountRepository) UpdateCredentials(ctx context.Context, id int64, cre
 				-- 上游倍率探测已放宽到全部 API-key 平台：凭证变化即视为探测
 				-- 身份变化，丢弃 stale 快照。
 				WHEN type = 'apikey'
-					AND credentials IS DISTINCT FROM $1::jsonb
+					AND credentials IS DISTINCT FROM $3::jsonb
 				THEN COALESCE(extra, '{}'::jsonb) - 'upstream_billing_probe'
 				ELSE extra
 			END,
 			updated_at = NOW()
 		WHERE id = $2 AND deleted_at IS NULL
-	`, string(payload), id)
+	`, payload, id, comparable)
 	if err != nil {
 		return err
 	}
@@ -1373,48 +1414,129 @@ func (r *accountRepository) SetError(ctx context.Context, id int64, errorMsg str
 	return nil
 }
 
+// credentialsCAS 以行内 credentials 当前的存储形态作为期望值执行 CAS 更新 exec。
+//
+// 期望值若按活动数据密钥重新加密，会与明文行、退役数据密钥加密的行以及其他实例用缓存旧密钥
+// 写入的行都不相等，CAS 被误判为"已变更"。这里先读出行内 credentials 解密后与期望明文比较，
+// 一致时把库中原样的 jsonb 交给 exec，由 SQL 里的 "credentials = $n::jsonb" 保证原子性；
+// exec 未命中而行内明文仍一致（期间被重新加密改写）时重新读取并重试一次。
+// 未配置凭证加密时库中只有明文，直接比较。
+func (r *accountRepository) credentialsCAS(
+	ctx context.Context,
+	q sqlExecutor,
+	id int64,
+	expected map[string]any,
+	exec func(expectedJSON string) (bool, error),
+) (bool, error) {
+	if r.credCipher == nil {
+		expectedJSON, err := r.credCipher.credentialsJSON(normalizeJSONMap(expected))
+		if err != nil {
+			return false, err
+		}
+		return exec(expectedJSON)
+	}
+	previous := ""
+	for attempt := 0; attempt < 2; attempt++ {
+		stored, err := loadStoredCredentials(ctx, q, id)
+		if err != nil || stored == nil || string(stored) == previous {
+			return false, err
+		}
+		if !r.credCipher.storedCredentialsMatch(ctx, stored, expected) {
+			return false, nil
+		}
+		applied, err := exec(string(stored))
+		if err != nil || applied {
+			return applied, err
+		}
+		previous = string(stored)
+	}
+	return false, nil
+}
+
+// loadStoredCredentials 读取账号在库中原样存储的 credentials；账号不存在时返回 nil。
+func loadStoredCredentials(ctx context.Context, q sqlExecutor, id int64) ([]byte, error) {
+	return queryStoredCredentials(ctx, q, `SELECT credentials FROM accounts WHERE id = $1 AND deleted_at IS NULL`, id)
+}
+
+// lockStoredCredentials 同 loadStoredCredentials，并在当前事务内锁住该行直到写入完成。
+func lockStoredCredentials(ctx context.Context, q sqlExecutor, id int64) ([]byte, error) {
+	return queryStoredCredentials(ctx, q, `SELECT credentials FROM accounts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id)
+}
+
+func queryStoredCredentials(ctx context.Context, q sqlExecutor, query string, id int64) ([]byte, error) {
+	rows, err := q.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	var stored []byte
+	if err := rows.Scan(&stored); err != nil {
+		return nil, err
+	}
+	return stored, rows.Err()
+}
+
+// decodeGrokCredentialsSnapshot 解码 service 层序列化的 Grok 凭证快照。
+func decodeGrokCredentialsSnapshot(raw string) (map[string]any, error) {
+	var credentials map[string]any
+	if err := json.Unmarshal([]byte(raw), &credentials); err != nil {
+		return nil, fmt.Errorf("decode credentials snapshot: %w", err)
+	}
+	return credentials, nil
+}
+
 func (r *accountRepository) SetGrokCredentialErrorIfMatch(
 	ctx context.Context,
 	id int64,
 	snapshot service.GrokCredentialMutationSnapshot,
 	errorMsg string,
 ) (bool, error) {
-	result, err := r.sql.ExecContext(ctx, `
-		WITH updated AS (
-		UPDATE accounts AS a
-		SET status = $1,
-			error_message = $2,
-			schedulable = false,
-			updated_at = NOW()
-		WHERE a.id = $3
-			AND a.deleted_at IS NULL
-			AND a.status = $4
-			AND a.platform = $5
-			AND a.type = $6
-			AND a.schedulable IS TRUE
-			AND (a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until <= NOW())
-			AND (a.rate_limit_reset_at IS NULL OR a.rate_limit_reset_at <= NOW())
-			AND (a.overload_until IS NULL OR a.overload_until <= NOW())
-			AND (a.auto_pause_on_expired IS NOT TRUE OR a.expires_at IS NULL OR a.expires_at > NOW())
-			AND a.credentials = $7::jsonb
-			AND a.proxy_id IS NOT DISTINCT FROM $8
-			AND ($2 <> $9 OR (
-				a.proxy_id IS NOT NULL AND NOT EXISTS (
-					SELECT 1 FROM proxies p WHERE p.id = a.proxy_id AND p.deleted_at IS NULL
-				)
-			))
-		RETURNING a.id
-		)
-		INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
-		SELECT $10, updated.id, NULL, NULL FROM updated
-	`, service.StatusError, errorMsg, id, service.StatusActive, service.PlatformGrok, service.AccountTypeOAuth,
-		snapshot.CredentialsJSON, snapshot.ProxyID, string(service.GrokCredentialReasonProxyInvalid),
-		service.SchedulerOutboxEventAccountChanged)
+	expected, err := decodeGrokCredentialsSnapshot(snapshot.CredentialsJSON)
 	if err != nil {
 		return false, err
 	}
-	affected, err := result.RowsAffected()
-	if err != nil || affected == 0 {
+	applied, err := r.credentialsCAS(ctx, r.sql, id, expected, func(expectedJSON string) (bool, error) {
+		result, err := r.sql.ExecContext(ctx, `
+			WITH updated AS (
+			UPDATE accounts AS a
+			SET status = $1,
+				error_message = $2,
+				schedulable = false,
+				updated_at = NOW()
+			WHERE a.id = $3
+				AND a.deleted_at IS NULL
+				AND a.status = $4
+				AND a.platform = $5
+				AND a.type = $6
+				AND a.schedulable IS TRUE
+				AND (a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until <= NOW())
+				AND (a.rate_limit_reset_at IS NULL OR a.rate_limit_reset_at <= NOW())
+				AND (a.overload_until IS NULL OR a.overload_until <= NOW())
+				AND (a.auto_pause_on_expired IS NOT TRUE OR a.expires_at IS NULL OR a.expires_at > NOW())
+				AND a.credentials = $7::jsonb
+				AND a.proxy_id IS NOT DISTINCT FROM $8
+				AND ($2 <> $9 OR (
+					a.proxy_id IS NOT NULL AND NOT EXISTS (
+						SELECT 1 FROM proxies p WHERE p.id = a.proxy_id AND p.deleted_at IS NULL
+					)
+				))
+			RETURNING a.id
+			)
+			INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
+			SELECT $10, updated.id, NULL, NULL FROM updated
+		`, service.StatusError, errorMsg, id, service.StatusActive, service.PlatformGrok, service.AccountTypeOAuth,
+			expectedJSON, snapshot.ProxyID, string(service.GrokCredentialReasonProxyInvalid),
+			service.SchedulerOutboxEventAccountChanged)
+		if err != nil {
+			return false, err
+		}
+		affected, err := result.RowsAffected()
+		return err == nil && affected > 0, err
+	})
+	if err != nil || !applied {
 		return false, err
 	}
 	r.syncSchedulerAccountSnapshotDetached(ctx, id)
@@ -1435,48 +1557,44 @@ func (r *accountRepository) SetGrokOAuthErrorIfCredentialsUnchanged(
 	if r == nil || r.sql == nil {
 		return false, errors.New("account repository SQL executor is not configured")
 	}
-	expectedJSON, err := json.Marshal(normalizeJSONMap(expectedCredentials))
-	if err != nil {
-		return false, err
-	}
-	result, err := r.sql.ExecContext(ctx, `
-		WITH updated AS (
-		UPDATE accounts AS a
-		SET status = $1,
-			error_message = $2,
-			schedulable = FALSE,
-			updated_at = NOW()
-		WHERE a.id = $3
-			AND a.deleted_at IS NULL
-			AND a.platform = $4
-			AND a.type = $5
-			AND a.status = $6
-			AND a.credentials = $7::jsonb
-			AND NULLIF(BTRIM(a.credentials->>'refresh_token'), '') IS NULL
-		RETURNING a.id
+	applied, err := r.credentialsCAS(ctx, r.sql, id, expectedCredentials, func(expectedJSON string) (bool, error) {
+		result, err := r.sql.ExecContext(ctx, `
+			WITH updated AS (
+			UPDATE accounts AS a
+			SET status = $1,
+				error_message = $2,
+				schedulable = FALSE,
+				updated_at = NOW()
+			WHERE a.id = $3
+				AND a.deleted_at IS NULL
+				AND a.platform = $4
+				AND a.type = $5
+				AND a.status = $6
+				AND a.credentials = $7::jsonb
+				AND NULLIF(BTRIM(a.credentials->>'refresh_token'), '') IS NULL
+			RETURNING a.id
+			)
+			INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
+			SELECT $8, updated.id, NULL, NULL FROM updated
+		`,
+			service.StatusError,
+			errorMsg,
+			id,
+			service.PlatformGrok,
+			service.AccountTypeOAuth,
+			service.StatusActive,
+			expectedJSON,
+			service.SchedulerOutboxEventAccountChanged,
 		)
-		INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
-		SELECT $8, updated.id, NULL, NULL FROM updated
-	`,
-		service.StatusError,
-		errorMsg,
-		id,
-		service.PlatformGrok,
-		service.AccountTypeOAuth,
-		service.StatusActive,
-		string(expectedJSON),
-		service.SchedulerOutboxEventAccountChanged,
-	)
-	if err != nil {
-		return false, err
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+		if err != nil {
+			return false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		return err == nil && rowsAffected > 0, err
+	})
+	if err != nil || !applied {
 		return false, err
 	}
-	if rowsAffected == 0 {
-		return false, nil
-	}
 	r.syncSchedulerAccountSnapshotDetached(ctx, id)
 	return true, nil
 }
@@ -1496,48 +1614,44 @@ func (r *accountRepository) UpdateGrokOAuthCredentialsIfUnchanged(
 	if r == nil || r.sql == nil {
 		return false, errors.New("account repository SQL executor is not configured")
 	}
-	expectedJSON, err := json.Marshal(normalizeJSONMap(expectedCredentials))
-	if err != nil {
-		return false, err
-	}
-	credentialsJSON, err := json.Marshal(normalizeJSONMap(credentials))
+	credentialsJSON, err := r.credCipher.credentialsJSON(normalizeJSONMap(credentials))
 	if err != nil {
 		return false, err
 	}
-	result, err := r.sql.ExecContext(ctx, `
-		WITH updated AS (
-		UPDATE accounts AS a
-		SET credentials = $1::jsonb,
-			updated_at = NOW()
-		WHERE a.id = $2
-			AND a.deleted_at IS NULL
-			AND a.platform = $3
-			AND a.type = $4
-			AND a.credentials = $5::jsonb
-			AND a.proxy_id IS NOT DISTINCT FROM $6
-		RETURNING a.id
+	applied, err := r.credentialsCAS(ctx, r.sql, id, expectedCredentials, func(expectedJSON string) (bool, error) {
+		result, err := r.sql.ExecContext(ctx, `
+			WITH updated AS (
+			UPDATE accounts AS a
+			SET credentials = $1::jsonb,
+				updated_at = NOW()
+			WHERE a.id = $2
+				AND a.deleted_at IS NULL
+				AND a.platform = $3
+				AND a.type = $4
+				AND a.credentials = $5::jsonb
+				AND a.proxy_id IS NOT DISTINCT FROM $6
+			RETURNING a.id
+			)
+			INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
+			SELECT $7, updated.id, NULL, NULL FROM updated
+		`,
+			credentialsJSON,
+			id,
+			service.PlatformGrok,
+			service.AccountTypeOAuth,
+			expectedJSON,
+			expectedProxyID,
+			service.SchedulerOutboxEventAccountChanged,
 		)
-		INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
-		SELECT $7, updated.id, NULL, NULL FROM updated
-	`,
-		string(credentialsJSON),
-		id,
-		service.PlatformGrok,
-		service.AccountTypeOAuth,
-		string(expectedJSON),
-		expectedProxyID,
-		service.SchedulerOutboxEventAccountChanged,
-	)
-	if err != nil {
-		return false, err
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+		if err != nil {
+			return false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		return err == nil && rowsAffected > 0, err
+	})
+	if err != nil || !applied {
 		return false, err
 	}
-	if rowsAffected == 0 {
-		return false, nil
-	}
 	r.syncSchedulerAccountSnapshotDetached(ctx, id)
 	return true, nil
 }
@@ -1556,49 +1670,45 @@ func (r *accountRepository) SetGrokOAuthRefreshErrorIfCredentialsUnchanged(
 	if r == nil || r.sql == nil {
 		return false, errors.New("account repository SQL executor is not configured")
 	}
-	expectedJSON, err := json.Marshal(normalizeJSONMap(expectedCredentials))
-	if err != nil {
-		return false, err
-	}
-	result, err := r.sql.ExecContext(ctx, `
-		WITH updated AS (
-		UPDATE accounts AS a
-		SET status = $1,
-			error_message = $2,
-			schedulable = FALSE,
-			updated_at = NOW()
-		WHERE a.id = $3
-			AND a.deleted_at IS NULL
-			AND a.platform = $4
-			AND a.type = $5
-			AND a.status = $6
-			AND a.credentials = $7::jsonb
-			AND a.proxy_id IS NOT DISTINCT FROM $8
-		RETURNING a.id
+	applied, err := r.credentialsCAS(ctx, r.sql, id, expectedCredentials, func(expectedJSON string) (bool, error) {
+		result, err := r.sql.ExecContext(ctx, `
+			WITH updated AS (
+			UPDATE accounts AS a
+			SET status = $1,
+				error_message = $2,
+				schedulable = FALSE,
+				updated_at = NOW()
+			WHERE a.id = $3
+				AND a.deleted_at IS NULL
+				AND a.platform = $4
+				AND a.type = $5
+				AND a.status = $6
+				AND a.credentials = $7::jsonb
+				AND a.proxy_id IS NOT DISTINCT FROM $8
+			RETURNING a.id
+			)
+			INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
+			SELECT $9, updated.id, NULL, NULL FROM updated
+		`,
+			service.StatusError,
+			errorMsg,
+			id,
+			service.PlatformGrok,
+			service.AccountTypeOAuth,
+			service.StatusActive,
+			expectedJSON,
+			expectedProxyID,
+			service.SchedulerOutboxEventAccountChanged,
 		)
-		INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
-		SELECT $9, updated.id, NULL, NULL FROM updated
-	`,
-		service.StatusError,
-		errorMsg,
-		id,
-		service.PlatformGrok,
-		service.AccountTypeOAuth,
-		service.StatusActive,
-		string(expectedJSON),
-		expectedProxyID,
-		service.SchedulerOutboxEventAccountChanged,
-	)
-	if err != nil {
-		return false, err
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+		if err != nil {
+			return false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		return err == nil && rowsAffected > 0, err
+	})
+	if err != nil || !applied {
 		return false, err
 	}
-	if rowsAffected == 0 {
-		return false, nil
-	}
 	r.syncSchedulerAccountSnapshotDetached(ctx, id)
 	return true, nil
 }
@@ -1617,49 +1727,45 @@ func (r *accountRepository) SetGrokOAuthRefreshTempUnschedulableIfCredentialsUnc
 	if r == nil || r.sql == nil {
 		return false, errors.New("account repository SQL executor is not configured")
 	}
-	expectedJSON, err := json.Marshal(normalizeJSONMap(expectedCredentials))
-	if err != nil {
-		return false, err
-	}
-	result, err := r.sql.ExecContext(ctx, `
-		WITH updated AS (
-		UPDATE accounts AS a
-		SET temp_unschedulable_until = $1,
-			temp_unschedulable_reason = $2,
-			updated_at = NOW()
-		WHERE a.id = $3
-			AND a.deleted_at IS NULL
-			AND a.platform = $4
-			AND a.type = $5
-			AND a.status = $6
-			AND a.credentials = $7::jsonb
-			AND a.proxy_id IS NOT DISTINCT FROM $8
-			AND (a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until < $1)
-		RETURNING a.id
+	applied, err := r.credentialsCAS(ctx, r.sql, id, expectedCredentials, func(expectedJSON string) (bool, error) {
+		result, err := r.sql.ExecContext(ctx, `
+			WITH updated AS (
+			UPDATE accounts AS a
+			SET temp_unschedulable_until = $1,
+				temp_unschedulable_reason = $2,
+				updated_at = NOW()
+			WHERE a.id = $3
+				AND a.deleted_at IS NULL
+				AND a.platform = $4
+				AND a.type = $5
+				AND a.status = $6
+				AND a.credentials = $7::jsonb
+				AND a.proxy_id IS NOT DISTINCT FROM $8
+				AND (a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until < $1)
+			RETURNING a.id
+			)
+			INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
+			SELECT $9, updated.id, NULL, NULL FROM updated
+		`,
+			until,
+			reason,
+			id,
+			service.PlatformGrok,
+			service.AccountTypeOAuth,
+			service.StatusActive,
+			expectedJSON,
+			expectedProxyID,
+			service.SchedulerOutboxEventAccountChanged,
 		)
-		INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
-		SELECT $9, updated.id, NULL, NULL FROM updated
-	`,
-		until,
-		reason,
-		id,
-		service.PlatformGrok,
-		service.AccountTypeOAuth,
-		service.StatusActive,
-		string(expectedJSON),
-		expectedProxyID,
-		service.SchedulerOutboxEventAccountChanged,
-	)
-	if err != nil {
-		return false, err
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+		if err != nil {
+			return false, err
+		}
+		rowsAffected, err := result.RowsAffected()
+		return err == nil && rowsAffected > 0, err
+	})
+	if err != nil || !applied {
 		return false, err
 	}
-	if rowsAffected == 0 {
-		return false, nil
-	}
 	r.syncSchedulerAccountSnapshotDetached(ctx, id)
 	return true, nil
 }
@@ -2334,38 +2440,45 @@ func (r *accountRepository) SetGrokCredentialTempUnschedulableIfMatch(
 	until time.Time,
 	reason string,
 ) (bool, error) {
-	result, err := r.sql.ExecContext(ctx, `
-		WITH updated AS (
-		UPDATE accounts AS a
-		SET temp_unschedulable_until = CASE
-				WHEN a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until < $1 THEN $1
-				ELSE a.temp_unschedulable_until
-			END,
-			temp_unschedulable_reason = $2,
-			updated_at = NOW()
-		WHERE a.id = $3
-			AND a.deleted_at IS NULL
-			AND a.status = $4
-			AND a.platform = $5
-			AND a.type = $6
-			AND a.schedulable IS TRUE
-			AND (a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until <= NOW())
-			AND (a.rate_limit_reset_at IS NULL OR a.rate_limit_reset_at <= NOW())
-			AND (a.overload_until IS NULL OR a.overload_until <= NOW())
-			AND (a.auto_pause_on_expired IS NOT TRUE OR a.expires_at IS NULL OR a.expires_at > NOW())
-			AND a.credentials = $7::jsonb
-			AND a.proxy_id IS NOT DISTINCT FROM $8
-		RETURNING a.id
-		)
-		INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
-		SELECT $9, updated.id, NULL, NULL FROM updated
-	`, until, reason, id, service.StatusActive, service.PlatformGrok, service.AccountTypeOAuth,
-		snapshot.CredentialsJSON, snapshot.ProxyID, service.SchedulerOutboxEventAccountChanged)
+	expected, err := decodeGrokCredentialsSnapshot(snapshot.CredentialsJSON)
 	if err != nil {
 		return false, err
 	}
-	affected, err := result.RowsAffected()
-	if err != nil || affected == 0 {
+	applied, err := r.credentialsCAS(ctx, r.sql, id, expected, func(expectedJSON string) (bool, error) {
+		result, err := r.sql.ExecContext(ctx, `
+			WITH updated AS (
+			UPDATE accounts AS a
+			SET temp_unschedulable_until = CASE
+					WHEN a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until < $1 THEN $1
+					ELSE a.temp_unschedulable_until
+				END,
+				temp_unschedulable_reason = $2,
+				updated_at = NOW()
+			WHERE a.id = $3
+				AND a.deleted_at IS NULL
+				AND a.status = $4
+				AND a.platform = $5
+				AND a.type = $6
+				AND a.schedulable IS TRUE
+				AND (a.temp_unschedulable_until IS NULL OR a.temp_unschedulable_until <= NOW())
+				AND (a.rate_limit_reset_at IS NULL OR a.rate_limit_reset_at <= NOW())
+				AND (a.overload_until IS NULL OR a.overload_until <= NOW())
+				AND (a.auto_pause_on_expired IS NOT TRUE OR a.expires_at IS NULL OR a.expires_at > NOW())
+				AND a.credentials = $7::jsonb
+				AND a.proxy_id IS NOT DISTINCT FROM $8
+			RETURNING a.id
+			)
+			INSERT INTO scheduler_outbox (event_type, account_id, group_id, payload)
+			SELECT $9, updated.id, NULL, NULL FROM updated
+		`, until, reason, id, service.StatusActive, service.PlatformGrok, service.AccountTypeOAuth,
+			expectedJSON, snapshot.ProxyID, service.SchedulerOutboxEventAccountChanged)
+		if err != nil {
+			return false, err
+		}
+		affected, err := result.RowsAffected()
+		return err == nil && affected > 0, err
+	})
+	if err != nil || !applied {
 		return false, err
 	}
 	r.syncSchedulerAccountSnapshotDetached(ctx, id)
@@ -2677,10 +2790,6 @@ func (r *accountRepository) updateUpstreamBillingProbeSnapshotInTx(
 	if err != nil {
 		return err
 	}
-	credentials, err := json.Marshal(account.Credentials)
-	if err != nil {
-		return err
-	}
 	var expectedSnapshot any
 	if account.Extra != nil {
 		expectedSnapshot = account.Extra[service.UpstreamBillingProbeExtraKey]
@@ -2717,36 +2826,39 @@ func (r *accountRepository) updateUpstreamBillingProbeSnapshotInTx(
 	if account.ProxyID != nil {
 		proxyID = *account.ProxyID
 	}
-	result, err := client.ExecContext(ctx, `
-		UPDATE accounts
-		SET
-			extra = COALESCE(extra, '{}'::jsonb) || $1::jsonb,
-			rate_multiplier = CASE
-				WHEN $10::numeric IS NOT NULL
-					AND extra @> '{"upstream_billing_probe_enabled": true}'::jsonb
-					AND extra @> '{"upstream_billing_rate_sync_enabled": true}'::jsonb
-				THEN $10::numeric
-				ELSE rate_multiplier
-			END,
-			updated_at = NOW()
-		WHERE id = $2
-			AND platform = $3
-			AND type = $4
-			AND credentials = $5::jsonb
-			AND proxy_id IS NOT DISTINCT FROM $6
-			AND COALESCE(extra -> 'upstream_billing_probe', 'null'::jsonb) = $7::jsonb
-			AND COALESCE(extra -> 'upstream_billing_probe_enabled', 'null'::jsonb) = $8::jsonb
-			AND COALESCE(extra -> 'upstream_billing_rate_sync_enabled', 'null'::jsonb) = $9::jsonb
-			AND deleted_at IS NULL
-	`, string(payload), account.ID, account.Platform, account.Type, string(credentials), proxyID, string(expectedSnapshotJSON), string(expectedEnabledJSON), string(expectedRateSyncEnabledJSON), rateMultiplier)
-	if err != nil {
-		return err
-	}
-	affected, err := result.RowsAffected()
+	applied, err := r.credentialsCAS(ctx, client, account.ID, account.Credentials, func(expectedCredentials string) (bool, error) {
+		result, err := client.ExecContext(ctx, `
+			UPDATE accounts
+			SET
+				extra = COALESCE(extra, '{}'::jsonb) || $1::jsonb,
+				rate_multiplier = CASE
+					WHEN $10::numeric IS NOT NULL
+						AND extra @> '{"upstream_billing_probe_enabled": true}'::jsonb
+						AND extra @> '{"upstream_billing_rate_sync_enabled": true}'::jsonb
+					THEN $10::numeric
+					ELSE rate_multiplier
+				END,
+				updated_at = NOW()
+			WHERE id = $2
+				AND platform = $3
+				AND type = $4
+				AND credentials = $5::jsonb
+				AND proxy_id IS NOT DISTINCT FROM $6
+				AND COALESCE(extra -> 'upstream_billing_probe', 'null'::jsonb) = $7::jsonb
+				AND COALESCE(extra -> 'upstream_billing_probe_enabled', 'null'::jsonb) = $8::jsonb
+				AND COALESCE(extra -> 'upstream_billing_rate_sync_enabled', 'null'::jsonb) = $9::jsonb
+				AND deleted_at IS NULL
+		`, string(payload), account.ID, account.Platform, account.Type, expectedCredentials, proxyID, string(expectedSnapshotJSON), string(expectedEnabledJSON), string(expectedRateSyncEnabledJSON), rateMultiplier)
+		if err != nil {
+			return false, err
+		}
+		affected, err := result.RowsAffected()
+		return err == nil && affected > 0, err
+	})
 	if err != nil {
 		return err
 	}
-	if affected == 0 {
+	if !applied {
 		return service.ErrUpstreamBillingProbeIdentityChanged
 	}
 	return enqueueSchedulerOutbox(ctx, client, service.SchedulerOutboxEventAccountChanged, &account.ID, nil, nil)
@@ -2898,19 +3010,30 @@ func (r *accountRepository) BulkUpdate(ctx context.Context, ids []int64, updates
 	// JSONB 需要合并而非覆盖，使用 raw SQL 保持旧行为。
 	credentialPlaceholder := ""
 	if len(updates.Credentials) > 0 {
-		payload, err := json.Marshal(updates.Credentials)
+		payload, err := r.credCipher.credentialsJSON(updates.Credentials)
 		if err != nil {
 			return 0, err
 		}
 		credentialPlaceholder = "$" + itoa(idx)
 		setClauses = append(setClauses, "credentials = COALESCE(credentials, '{}'::jsonb) || "+credentialPlaceholder+"::jsonb")
-		args = append(args, payload)
+		args = append(args, []byte(payload))
 		idx++
 	}
 
 	ollamaGroupIdentityChanges := make([]string, 0, 2)
-	if _, ok := updates.Credentials["api_key"]; ok {
-		ollamaGroupIdentityChanges = append(ollamaGroupIdentityChanges, "credentials -> 'api_key' IS DISTINCT FROM "+credentialPlaceholder+"::jsonb -> 'api_key'")
+	if apiKey, ok := updates.Credentials["api_key"]; ok {
+		// 字符串 api_key 按全部存储形态比较：明文行与旧数据密钥加密的行同样算"未变化"。
+		if s, isString := apiKey.(string); isString && strings.TrimSpace(s) != "" {
+			storedAPIKeys, err := r.credCipher.credentialValueCandidates("api_key", s)
+			if err != nil {
+				return 0, err
+			}
+			ollamaGroupIdentityChanges = append(ollamaGroupIdentityChanges, "NOT COALESCE(credentials ->> 'api_key' = ANY($"+itoa(idx)+"), FALSE)")
+			args = append(args, pq.Array(storedAPIKeys))
+			idx++
+		} else {
+			ollamaGroupIdentityChanges = append(ollamaGroupIdentityChanges, "credentials -> 'api_key' IS DISTINCT FROM "+credentialPlaceholder+"::jsonb -> 'api_key'")
+		}
 	}
 	if _, ok := updates.Credentials["base_url"]; ok {
 		ollamaGroupIdentityChanges = append(ollamaGroupIdentityChanges,
@@ -3144,7 +3267,7 @@ func (r *accountRepository) accountsToService(ctx context.Context, accounts []*d
 
 	outAccounts := make([]service.Account, 0, len(accounts))
 	for _, acc := range accounts {
-		out := accountEntityToService(acc)
+		out := r.accountToService(ctx, acc)
 		if out == nil {
 			continue
 		}
@@ -3361,6 +3484,15 @@ func buildSchedulerGroupPayload(groupIDs []int64) any {
 	return map[string]any{"group_ids": groupIDs}
 }
 
+// accountToService 转换实体并解密凭证敏感子键。
+func (r *accountRepository) accountToService(ctx context.Context, m *dbent.Account) *service.Account {
+	out := accountEntityToService(m)
+	if out != nil {
+		out.Credentials = r.credCipher.DecryptCredentials(ctx, out.ID, out.Credentials)
+	}
+	return out
+}
+
 func accountEntityToService(m *dbent.Account) *service.Account {
 	if m == nil {
 		return nil
@@ -3816,7 +3948,7 @@ func (r *accountRepository) ListShadowsByParent(ctx context.Context, parentID in
 	}
 	out := make([]*service.Account, 0, len(rows))
 	for _, m := range rows {
-		out = append(out, accountEntityToService(m))
+		out = append(out, r.accountToService(ctx, m))
 	}
 	return out, nil
 }
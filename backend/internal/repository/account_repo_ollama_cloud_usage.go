@@ -48,7 +48,12 @@ func (r *accountRepository) ListOllamaCloudUsageGroupAccounts(ctx context.Contex
 			continue
 		}
 		seen[apiKey] = struct{}{}
-		keys = append(keys, apiKey)
+		// 库中的 api_key 可能是明文或任一数据密钥的确定性密文，按全部存储形态匹配
+		storedKeys, err := r.credCipher.credentialValueCandidates("api_key", apiKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, storedKeys...)
 	}
 	if len(keys) == 0 {
 		return []service.Account{}, nil
@@ -182,6 +187,10 @@ func (r *accountRepository) updateOllamaCloudUsageGroup(
 	if !ok || apiKey == "" {
 		return service.ErrOllamaCloudUsageAccountInvalid
 	}
+	storedAPIKeys, err := r.credCipher.credentialValueCandidates("api_key", apiKey)
+	if err != nil {
+		return err
+	}
 	apply := func(txCtx context.Context, client *dbent.Client) error {
 		matchesProxy, err := lockAndMatchProbeProxyIdentity(txCtx, client, account)
 		if err != nil {
@@ -190,7 +199,7 @@ func (r *accountRepository) updateOllamaCloudUsageGroup(
 		if !matchesProxy {
 			return service.ErrOllamaCloudUsageIdentityChanged
 		}
-		members, err := lockOllamaCloudUsageGroup(txCtx, client, account, apiKey)
+		members, err := lockOllamaCloudUsageGroup(txCtx, client, r.credCipher, account, storedAPIKeys)
 		if err != nil {
 			return err
 		}
@@ -244,9 +253,9 @@ func (r *accountRepository) updateOllamaCloudUsageGroup(
 				updated_at = NOW()
 			WHERE deleted_at IS NULL
 				AND `+ollamaCloudUsageEligibleSQL+`
-				AND credentials ->> 'api_key' = $2
+				AND credentials ->> 'api_key' = ANY($2)
 				AND id = ANY($3)
-		`, string(encoded), apiKey, pq.Array(memberIDs))
+		`, string(encoded), pq.Array(storedAPIKeys), pq.Array(memberIDs))
 		if err != nil {
 			return err
 		}
@@ -280,13 +289,10 @@ func (r *accountRepository) updateOllamaCloudUsageGroup(
 func lockOllamaCloudUsageGroup(
 	ctx context.Context,
 	client *dbent.Client,
+	credCipher *CredentialCipher,
 	account *service.Account,
-	apiKey string,
+	apiKeys []string,
 ) ([]lockedOllamaCloudUsageMember, error) {
-	credentials, err := json.Marshal(normalizeJSONMap(account.Credentials))
-	if err != nil {
-		return nil, err
-	}
 	var proxyID any
 	if account.ProxyID != nil {
 		proxyID = *account.ProxyID
@@ -297,28 +303,33 @@ func lockOllamaCloudUsageGroup(
 			id = $2
 				AND platform = $3
 				AND type = $4
-				AND credentials = $5::jsonb
-				AND proxy_id IS NOT DISTINCT FROM $6,
+				AND proxy_id IS NOT DISTINCT FROM $5,
+			credentials,
 			COALESCE((extra -> 'ollama_cloud_usage_session')::text, 'null'),
 			COALESCE((extra -> 'ollama_cloud_usage_auto_refresh')::text, 'null'),
 			COALESCE((extra -> 'ollama_cloud_usage_snapshot')::text, 'null')
 		FROM accounts
 		WHERE deleted_at IS NULL
 			AND `+ollamaCloudUsageEligibleSQL+`
-			AND credentials ->> 'api_key' = $1
+			AND credentials ->> 'api_key' = ANY($1)
 		ORDER BY id
 		FOR NO KEY UPDATE
-	`, apiKey, account.ID, account.Platform, account.Type, string(credentials), proxyID)
+	`, pq.Array(apiKeys), account.ID, account.Platform, account.Type, proxyID)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 	members := make([]lockedOllamaCloudUsageMember, 0, 1)
 	for rows.Next() {
-		var member lockedOllamaCloudUsageMember
-		if err := rows.Scan(&member.id, &member.anchorMatches, &member.sessionJSON, &member.autoJSON, &member.snapshotJSON); err != nil {
+		var (
+			member            lockedOllamaCloudUsageMember
+			storedCredentials []byte
+		)
+		if err := rows.Scan(&member.id, &member.anchorMatches, &storedCredentials, &member.sessionJSON, &member.autoJSON, &member.snapshotJSON); err != nil {
 			return nil, err
 		}
+		// 锚点账号的凭证须与调用方看到的一致；存储形态随数据密钥变化，解密后比较
+		member.anchorMatches = member.anchorMatches && credCipher.storedCredentialsMatch(ctx, storedCredentials, account.Credentials)
 		members = append(members, member)
 	}
 	if err := rows.Err(); err != nil {
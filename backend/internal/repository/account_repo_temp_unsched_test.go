@@ -53,7 +53,7 @@ func TestAccountRepository_GrokCredentialConditionalMutationsAreEligibleAndAtomi
 		require.Contains(t, normalized, "NOT EXISTS ( SELECT 1 FROM proxies p")
 		require.Contains(t, normalized, "INSERT INTO scheduler_outbox")
 		require.Len(t, exec.execArgs[0], 10)
-		require.Equal(t, snapshot.CredentialsJSON, exec.execArgs[0][6])
+		require.JSONEq(t, snapshot.CredentialsJSON, exec.execArgs[0][6].(string))
 		require.Equal(t, &proxyID, exec.execArgs[0][7])
 		require.Equal(t, string(service.GrokCredentialReasonProxyInvalid), exec.execArgs[0][8])
 		require.Equal(t, service.SchedulerOutboxEventAccountChanged, exec.execArgs[0][9])
@@ -80,7 +80,7 @@ func TestAccountRepository_GrokCredentialConditionalMutationsAreEligibleAndAtomi
 		require.Contains(t, normalized, "a.proxy_id IS NOT DISTINCT FROM $8")
 		require.Contains(t, normalized, "INSERT INTO scheduler_outbox")
 		require.Len(t, exec.execArgs[0], 9)
-		require.Equal(t, snapshot.CredentialsJSON, exec.execArgs[0][6])
+		require.JSONEq(t, snapshot.CredentialsJSON, exec.execArgs[0][6].(string))
 		require.Equal(t, &proxyID, exec.execArgs[0][7])
 		require.Equal(t, service.SchedulerOutboxEventAccountChanged, exec.execArgs[0][8])
 	})
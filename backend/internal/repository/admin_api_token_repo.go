@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type adminAPITokenRepository struct {
+	db *sql.DB
+}
+
+func NewAdminAPITokenRepository(db *sql.DB) service.AdminAPITokenRepository {
+	return &adminAPITokenRepository{db: db}
+}
+
+const adminAPITokenSelectColumns = `id, name, token_prefix, scopes::text, step_up_scopes::text, allowed_ips::text,
+	expires_at, last_used_at, last_used_ip, created_by, created_at, revoked_at, revoked_by`
+
+func scanAdminAPIToken(scan func(dest ...any) error) (*service.AdminAPIToken, error) {
+	var (
+		token                            service.AdminAPIToken
+		scopes, stepUpScopes, allowedIPs string
+		expiresAt, lastUsedAt, revokedAt sql.NullTime
+		revokedBy                        sql.NullInt64
+	)
+	if err := scan(&token.ID, &token.Name, &token.TokenPrefix, &scopes, &stepUpScopes, &allowedIPs,
+		&expiresAt, &lastUsedAt, &token.LastUsedIP, &token.CreatedBy, &token.CreatedAt, &revokedAt, &revokedBy); err != nil {
+		return nil, err
+	}
+	token.Scopes, token.StepUpScopes, token.AllowedIPs = []string{}, []string{}, []string{}
+	if err := json.Unmarshal([]byte(scopes), &token.Scopes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(stepUpScopes), &token.StepUpScopes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(allowedIPs), &token.AllowedIPs); err != nil {
+		return nil, err
+	}
+	token.ExpiresAt = timePtrFromNull(expiresAt)
+	token.LastUsedAt = timePtrFromNull(lastUsedAt)
+	token.RevokedAt = timePtrFromNull(revokedAt)
+	token.RevokedBy = int64PtrFromNull(revokedBy)
+	return &token, nil
+}
+
+func (r *adminAPITokenRepository) Create(ctx context.Context, token *service.AdminAPIToken, tokenHash string) error {
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return err
+	}
+	stepUpScopes, err := json.Marshal(token.StepUpScopes)
+	if err != nil {
+		return err
+	}
+	allowedIPs, err := json.Marshal(token.AllowedIPs)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO admin_api_tokens (name, token_prefix, token_hash, scopes, step_up_scopes, allowed_ips, expires_at, created_by)
+		VALUES ($1, $2, $3, $4::jsonb, $5::jsonb, $6::jsonb, $7, $8)
+		RETURNING id, created_at`,
+		token.Name, token.TokenPrefix, tokenHash, string(scopes), string(stepUpScopes), string(allowedIPs),
+		token.ExpiresAt, token.CreatedBy,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *adminAPITokenRepository) List(ctx context.Context) ([]service.AdminAPIToken, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+adminAPITokenSelectColumns+` FROM admin_api_tokens ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	tokens := make([]service.AdminAPIToken, 0)
+	for rows.Next() {
+		token, err := scanAdminAPIToken(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *adminAPITokenRepository) GetByHash(ctx context.Context, tokenHash string) (*service.AdminAPIToken, error) {
+	token, err := scanAdminAPIToken(r.db.QueryRowContext(ctx,
+		`SELECT `+adminAPITokenSelectColumns+` FROM admin_api_tokens WHERE token_hash = $1`, tokenHash).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrAdminAPITokenNotFound
+	}
+	return token, err
+}
+
+func (r *adminAPITokenRepository) Revoke(ctx context.Context, id, revokedBy int64, at time.Time) error {
+	// 已吊销的 Token 不覆盖首次吊销信息；只有 id 不存在时才返回 not found。
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		WITH updated AS (
+			UPDATE admin_api_tokens SET revoked_at = $3, revoked_by = $2
+			WHERE id = $1 AND revoked_at IS NULL
+		)
+		SELECT EXISTS (SELECT 1 FROM admin_api_tokens WHERE id = $1)`,
+		id, revokedBy, at,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return service.ErrAdminAPITokenNotFound
+	}
+	return nil
+}
+
+func (r *adminAPITokenRepository) TouchLastUsed(ctx context.Context, id int64, clientIP string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE admin_api_tokens SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`, id, at, clientIP)
+	return err
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const adminRBACPubSubKey = "admin_rbac_updated"
+
+type adminRBACNotifier struct {
+	rdb *redis.Client
+}
+
+// NewAdminRBACNotifier 创建员工角色变更广播器
+func NewAdminRBACNotifier(rdb *redis.Client) service.AdminRBACNotifier {
+	return &adminRBACNotifier{rdb: rdb}
+}
+
+// NotifyUpdate 通知其他实例清空本地权限缓存
+func (c *adminRBACNotifier) NotifyUpdate(ctx context.Context) error {
+	return c.rdb.Publish(ctx, adminRBACPubSubKey, "refresh").Err()
+}
+
+// SubscribeUpdates 订阅员工角色变更通知
+func (c *adminRBACNotifier) SubscribeUpdates(ctx context.Context, handler func()) {
+	go func() {
+		sub := c.rdb.Subscribe(ctx, adminRBACPubSubKey)
+		defer func() { _ = sub.Close() }()
+
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-ch:
+				if msg == nil {
+					return
+				}
+				handler()
+			}
+		}
+	}()
+}
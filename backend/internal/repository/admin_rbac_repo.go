@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type adminRBACRepository struct {
+	db *sql.DB
+}
+
+func NewAdminRBACRepository(db *sql.DB) service.AdminRBACRepository {
+	return &adminRBACRepository{db: db}
+}
+
+const adminRoleSelectColumns = `r.id, r.name, r.description, r.permissions::text, r.builtin, r.created_at, r.updated_at`
+
+func scanAdminRole(scan func(dest ...any) error) (*service.AdminRole, error) {
+	var (
+		role        service.AdminRole
+		permissions string
+	)
+	if err := scan(&role.ID, &role.Name, &role.Description, &permissions, &role.Builtin, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		return nil, err
+	}
+	role.Permissions = []string{}
+	if err := json.Unmarshal([]byte(permissions), &role.Permissions); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *adminRBACRepository) ListRoles(ctx context.Context) ([]service.AdminRole, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+adminRoleSelectColumns+` FROM admin_roles r ORDER BY r.builtin DESC, r.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	roles := make([]service.AdminRole, 0)
+	for rows.Next() {
+		role, err := scanAdminRole(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *adminRBACRepository) GetRole(ctx context.Context, id int64) (*service.AdminRole, error) {
+	role, err := scanAdminRole(r.db.QueryRowContext(ctx,
+		`SELECT `+adminRoleSelectColumns+` FROM admin_roles r WHERE r.id = $1`, id).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrAdminRoleNotFound
+	}
+	return role, err
+}
+
+func (r *adminRBACRepository) CreateRole(ctx context.Context, role *service.AdminRole) error {
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO admin_roles (name, description, permissions)
+		VALUES ($1, $2, $3::jsonb)
+		RETURNING id, created_at, updated_at`,
+		role.Name, role.Description, string(permissions),
+	).Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if isUniqueConstraintViolation(err) {
+		return service.ErrAdminRoleNameExists
+	}
+	return err
+}
+
+func (r *adminRBACRepository) UpdateRole(ctx context.Context, role *service.AdminRole) error {
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE admin_roles SET name = $2, description = $3, permissions = $4::jsonb, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		role.ID, role.Name, role.Description, string(permissions),
+	).Scan(&role.UpdatedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return service.ErrAdminRoleNotFound
+	case isUniqueConstraintViolation(err):
+		return service.ErrAdminRoleNameExists
+	}
+	return err
+}
+
+func (r *adminRBACRepository) DeleteRole(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM admin_roles WHERE id = $1`, id)
+	if err != nil {
+		// 23503 foreign_key_violation：仍有用户绑定该角色（ON DELETE RESTRICT）。
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return service.ErrAdminRoleInUse
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return service.ErrAdminRoleNotFound
+	}
+	return nil
+}
+
+func (r *adminRBACRepository) ListAssignments(ctx context.Context) ([]service.AdminRoleAssignment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT a.user_id, COALESCE(u.email, ''), a.role_id, r.name, a.assigned_by, a.created_at
+		FROM admin_role_assignments a
+		JOIN admin_roles r ON r.id = a.role_id
+		LEFT JOIN users u ON u.id = a.user_id
+		ORDER BY a.created_at DESC, a.user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.AdminRoleAssignment, 0)
+	for rows.Next() {
+		var (
+			a          service.AdminRoleAssignment
+			assignedBy sql.NullInt64
+		)
+		if err := rows.Scan(&a.UserID, &a.UserEmail, &a.RoleID, &a.RoleName, &assignedBy, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.AssignedBy = int64PtrFromNull(assignedBy)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *adminRBACRepository) GetUserRole(ctx context.Context, userID int64) (*service.AdminRole, error) {
+	role, err := scanAdminRole(r.db.QueryRowContext(ctx, `
+		SELECT `+adminRoleSelectColumns+`
+		FROM admin_role_assignments a
+		JOIN admin_roles r ON r.id = a.role_id
+		WHERE a.user_id = $1`, userID).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return role, err
+}
+
+func (r *adminRBACRepository) AssignRole(ctx context.Context, userID, roleID int64, assignedBy *int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO admin_role_assignments (user_id, role_id, assigned_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			role_id = EXCLUDED.role_id, assigned_by = EXCLUDED.assigned_by, created_at = NOW()`,
+		userID, roleID, nullInt64Ptr(assignedBy))
+	return err
+}
+
+func (r *adminRBACRepository) RemoveAssignment(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM admin_role_assignments WHERE user_id = $1`, userID)
+	return err
+}
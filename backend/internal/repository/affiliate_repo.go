@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -286,6 +287,13 @@ FROM cleared`, userID)
 			return service.ErrAffiliateQuotaEmpty
 		}
 
+		if err := bindBalanceLedgerSource(txCtx, txClient, service.BalanceLedgerSource{
+			Reason:     service.BalanceLedgerReasonAffiliateTransfer,
+			SourceType: service.BalanceLedgerSourceAffiliate,
+			SourceID:   strconv.FormatInt(userID, 10),
+		}); err != nil {
+			return err
+		}
 		affected, err := txClient.User.Update().
 			Where(user.IDEQ(userID)).
 			AddBalance(transferred).
@@ -55,7 +55,9 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 		SetNillableExpiresAt(key.ExpiresAt).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
-		SetRateLimit7d(key.RateLimit7d)
+		SetRateLimit7d(key.RateLimit7d).
+		SetResponseCacheEnabled(key.ResponseCacheEnabled).
+		SetNillableOrganizationID(key.OrganizationID)
 
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -63,6 +65,15 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if len(key.ModelAllowlist) > 0 {
+		builder.SetModelAllowlist(key.ModelAllowlist)
+	}
+	if len(key.ModelDenylist) > 0 {
+		builder.SetModelDenylist(key.ModelDenylist)
+	}
+	if len(key.ModelAliases) > 0 {
+		builder.SetModelAliases(key.ModelAliases)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -144,6 +155,11 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldRateLimit5h,
 			apikey.FieldRateLimit1d,
 			apikey.FieldRateLimit7d,
+			apikey.FieldResponseCacheEnabled,
+			apikey.FieldOrganizationID,
+			apikey.FieldModelAllowlist,
+			apikey.FieldModelDenylist,
+			apikey.FieldModelAliases,
 		).
 		WithUser(func(q *dbent.UserQuery) {
 			q.Select(
@@ -194,6 +210,7 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldVideoPrice1080p,
 				group.FieldVideoModelPrices,
 				group.FieldWebSearchPricePerCall,
+				group.FieldWebFetchPricePerCall,
 				group.FieldSearchPricePer1k,
 				group.FieldAudioRealtimePricePerMin,
 				group.FieldAudioTtsPricePerMillionChars,
@@ -225,6 +242,11 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldProfitControlEnabled,
 				group.FieldProfitMinMargin,
 				group.FieldProfitSafetyBuffer,
+				// 响应缓存由网关中间件直接读取认证快照中的分组配置。
+				group.FieldResponseCacheEnabled,
+				group.FieldResponseCacheTTLSeconds,
+				group.FieldResponseCachePriceMultiplier,
+				group.FieldSchedulingStrategy,
 			)
 		}).
 		Only(ctx)
@@ -294,6 +316,9 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey, fiel
 			builder.ClearWindow7dStart()
 		}
 	}
+	if fields.ResponseCache {
+		builder.SetResponseCacheEnabled(key.ResponseCacheEnabled)
+	}
 	if fields.GroupID {
 		if key.GroupID != nil {
 			builder.SetGroupID(*key.GroupID)
@@ -325,6 +350,25 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey, fiel
 		}
 	}
 
+	// 模型策略字段
+	if fields.ModelPolicy {
+		if len(key.ModelAllowlist) > 0 {
+			builder.SetModelAllowlist(key.ModelAllowlist)
+		} else {
+			builder.ClearModelAllowlist()
+		}
+		if len(key.ModelDenylist) > 0 {
+			builder.SetModelDenylist(key.ModelDenylist)
+		} else {
+			builder.ClearModelDenylist()
+		}
+		if len(key.ModelAliases) > 0 {
+			builder.SetModelAliases(key.ModelAliases)
+		} else {
+			builder.ClearModelAliases()
+		}
+	}
+
 	affected, err := builder.Save(ctx)
 	if err != nil {
 		return err
@@ -891,6 +935,13 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 		Window5hStart: m.Window5hStart,
 		Window1dStart: m.Window1dStart,
 		Window7dStart: m.Window7dStart,
+
+		ResponseCacheEnabled: m.ResponseCacheEnabled,
+		OrganizationID:       m.OrganizationID,
+
+		ModelAllowlist: m.ModelAllowlist,
+		ModelDenylist:  m.ModelDenylist,
+		ModelAliases:   m.ModelAliases,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -981,6 +1032,13 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		ImagePrice4K:                    g.ImagePrice4k,
 		BatchImageDiscountMultiplier:    g.BatchImageDiscountMultiplier,
 		BatchImageHoldMultiplier:        g.BatchImageHoldMultiplier,
+		AllowBatchAPI:                   g.AllowBatchAPI,
+		BatchAPIDiscountMultiplier:      g.BatchAPIDiscountMultiplier,
+		BatchAPIHoldMultiplier:          g.BatchAPIHoldMultiplier,
+		ResponseCacheEnabled:            g.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         g.ResponseCacheTTLSeconds,
+		ResponseCachePriceMultiplier:    g.ResponseCachePriceMultiplier,
+		SchedulingStrategy:              g.SchedulingStrategy,
 		VideoRateIndependent:            g.VideoRateIndependent,
 		VideoRateMultiplier:             g.VideoRateMultiplier,
 		VideoPrice480P:                  g.VideoPrice480p,
@@ -988,6 +1046,7 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		VideoPrice1080P:                 g.VideoPrice1080p,
 		VideoModelPrices:                service.NormalizeVideoModelPrices(g.VideoModelPrices),
 		WebSearchPricePerCall:           g.WebSearchPricePerCall,
+		WebFetchPricePerCall:            g.WebFetchPricePerCall,
 		SearchPricePer1k:                g.SearchPricePer1k,
 		AudioRealtimePricePerMin:        g.AudioRealtimePricePerMin,
 		AudioTTSPricePerMillionChars:    g.AudioTtsPricePerMillionChars,
@@ -25,7 +25,7 @@ func NewAuditLogRepository(db *sql.DB) service.AuditLogRepository {
 
 const auditLogInsertColumns = `created_at, actor_user_id, actor_email, actor_role, auth_method,
 credential_masked, action, method, path, request_id, client_ip, user_agent,
-request_body, status_code, latency_ms, extra`
+request_body, status_code, latency_ms, extra, admin_role`
 
 func auditLogInsertValues(log *service.AuditLog) []any {
 	createdAt := log.CreatedAt
@@ -55,6 +55,7 @@ func auditLogInsertValues(log *service.AuditLog) []any {
 		log.StatusCode,
 		log.LatencyMs,
 		extraJSON,
+		truncateString(log.AdminRole, 64),
 	}
 }
 
@@ -74,7 +75,7 @@ func (r *auditLogRepository) BatchInsert(ctx context.Context, logs []*service.Au
 		"audit_logs",
 		"created_at", "actor_user_id", "actor_email", "actor_role", "auth_method",
 		"credential_masked", "action", "method", "path", "request_id", "client_ip", "user_agent",
-		"request_body", "status_code", "latency_ms", "extra",
+		"request_body", "status_code", "latency_ms", "extra", "admin_role",
 	))
 	if err != nil {
 		_ = tx.Rollback()
@@ -117,7 +118,7 @@ func (r *auditLogRepository) Insert(ctx context.Context, log *service.AuditLog)
 		return fmt.Errorf("nil audit log")
 	}
 	query := `INSERT INTO audit_logs (` + auditLogInsertColumns + `)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)`
 	_, err := r.db.ExecContext(ctx, query, auditLogInsertValues(log)...)
 	return err
 }
@@ -147,6 +148,10 @@ func buildAuditLogsWhere(filter *service.AuditLogFilter) (string, []any) {
 		args = append(args, v)
 		clauses = append(clauses, "l.auth_method = $"+itoa(len(args)))
 	}
+	if v := strings.TrimSpace(filter.AdminRole); v != "" {
+		args = append(args, v)
+		clauses = append(clauses, "l.admin_role = $"+itoa(len(args)))
+	}
 	if v := strings.TrimSpace(filter.Action); v != "" {
 		args = append(args, "%"+escapeLikePattern(v)+"%")
 		clauses = append(clauses, "l.action ILIKE $"+itoa(len(args)))
@@ -192,7 +197,8 @@ const auditLogSelectColumns = `
   COALESCE(l.request_body, ''),
   l.status_code,
   l.latency_ms,
-  COALESCE(l.extra::text, '{}')`
+  COALESCE(l.extra::text, '{}'),
+  COALESCE(l.admin_role, '')`
 
 func scanAuditLogRow(scan func(dest ...any) error) (*service.AuditLog, error) {
 	item := &service.AuditLog{}
@@ -216,6 +222,7 @@ func scanAuditLogRow(scan func(dest ...any) error) (*service.AuditLog, error) {
 		&item.StatusCode,
 		&item.LatencyMs,
 		&extraRaw,
+		&item.AdminRole,
 	); err != nil {
 		return nil, err
 	}
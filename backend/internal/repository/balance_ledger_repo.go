@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// balanceLedgerSourceSetting 是触发器 record_balance_ledger_entry 读取的事务级 GUC。
+const balanceLedgerSourceSetting = "sub2api.balance_ledger_source"
+
+// balanceLedgerTolerance 对账时忽略的金额误差（流水与余额均为 decimal(20,8)）。
+const balanceLedgerTolerance = 0.000001
+
+// bindBalanceLedgerSource 把余额流水来源写入当前事务（set_config 的 is_local = true），
+// 必须与随后修改余额的 UPDATE 处于同一事务内才会生效。
+func bindBalanceLedgerSource(ctx context.Context, exec sqlExecutor, source service.BalanceLedgerSource) error {
+	payload := map[string]any{
+		"reason":      source.Reason,
+		"source_type": source.SourceType,
+		"source_id":   source.SourceID,
+		"note":        source.Note,
+	}
+	if source.ActorID != nil {
+		payload["actor_id"] = strconv.FormatInt(*source.ActorID, 10)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := exec.ExecContext(ctx, `SELECT set_config($1, $2, true)`, balanceLedgerSourceSetting, string(raw)); err != nil {
+		return fmt.Errorf("bind balance ledger source: %w", err)
+	}
+	return nil
+}
+
+// withBalanceLedgerSource 在 context 携带余额流水来源时，保证 fn 内的余额变更与来源处于同一事务：
+// 已在事务中则直接绑定；否则开启事务执行 fn 后提交。未携带来源时直接执行 fn。
+func withBalanceLedgerSource(ctx context.Context, client *dbent.Client, fn func(ctx context.Context) error) error {
+	source, ok := service.BalanceLedgerSourceFromContext(ctx)
+	if !ok {
+		return fn(ctx)
+	}
+	if tx := dbent.TxFromContext(ctx); tx != nil {
+		if err := bindBalanceLedgerSource(ctx, tx.Client(), source); err != nil {
+			return err
+		}
+		return fn(ctx)
+	}
+
+	tx, err := client.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	txCtx := dbent.NewTxContext(ctx, tx)
+	if err := bindBalanceLedgerSource(txCtx, tx.Client(), source); err != nil {
+		return err
+	}
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type balanceLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewBalanceLedgerRepository(db *sql.DB) service.BalanceLedgerRepository {
+	return &balanceLedgerRepository{db: db}
+}
+
+const balanceLedgerColumns = `id, user_id, reason, source_type, source_id, actor_id, amount,
+	balance_before, balance_after, frozen_delta, frozen_after, note, created_at`
+
+func scanBalanceLedgerEntry(row rowScanner) (*service.BalanceLedgerEntry, error) {
+	var (
+		e       service.BalanceLedgerEntry
+		actorID sql.NullInt64
+	)
+	if err := row.Scan(&e.ID, &e.UserID, &e.Reason, &e.SourceType, &e.SourceID, &actorID, &e.Amount,
+		&e.BalanceBefore, &e.BalanceAfter, &e.FrozenDelta, &e.FrozenAfter, &e.Note, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	if actorID.Valid {
+		v := actorID.Int64
+		e.ActorID = &v
+	}
+	return &e, nil
+}
+
+func (r *balanceLedgerRepository) queryEntries(ctx context.Context, query string, args ...any) ([]service.BalanceLedgerEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.BalanceLedgerEntry, 0)
+	for rows.Next() {
+		e, err := scanBalanceLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *balanceLedgerRepository) ListByUser(ctx context.Context, userID int64, filter service.BalanceLedgerFilter, params pagination.PaginationParams) ([]service.BalanceLedgerEntry, *pagination.PaginationResult, error) {
+	where := []string{"user_id = $1"}
+	args := []any{userID}
+	if reason := strings.TrimSpace(filter.Reason); reason != "" {
+		args = append(args, reason)
+		where = append(where, fmt.Sprintf("reason = $%d", len(args)))
+	}
+	if filter.StartTime != nil {
+		args = append(args, *filter.StartTime)
+		where = append(where, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.EndTime != nil {
+		args = append(args, *filter.EndTime)
+		where = append(where, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	whereSQL := strings.Join(where, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM balance_ledger WHERE `+whereSQL, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count balance ledger: %w", err)
+	}
+
+	args = append(args, params.Limit(), params.Offset())
+	entries, err := r.queryEntries(ctx, fmt.Sprintf(`
+		SELECT %s FROM balance_ledger
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d
+	`, balanceLedgerColumns, whereSQL, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list balance ledger: %w", err)
+	}
+	return entries, paginationResultFromTotal(total, params), nil
+}
+
+func (r *balanceLedgerRepository) ListRange(ctx context.Context, userID int64, start, end time.Time, limit int) ([]service.BalanceLedgerEntry, error) {
+	return r.queryEntries(ctx, `
+		SELECT `+balanceLedgerColumns+` FROM balance_ledger
+		WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY id ASC
+		LIMIT $4
+	`, userID, start, end, limit)
+}
+
+func (r *balanceLedgerRepository) BalanceBefore(ctx context.Context, userID int64, at time.Time) (float64, error) {
+	var balance float64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT balance_after FROM balance_ledger
+		WHERE user_id = $1 AND created_at < $2
+		ORDER BY id DESC
+		LIMIT 1
+	`, userID, at).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// queryFindings 执行返回 (user_id, source_type, source_id, ledger_id, expected, actual, total) 的对账查询，
+// total 为窗口函数 COUNT(*) OVER ()，即截断前的差异总数。
+func (r *balanceLedgerRepository) queryFindings(ctx context.Context, kind, query string, args ...any) ([]service.BalanceReconciliationFinding, int, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.BalanceReconciliationFinding, 0)
+	total := 0
+	for rows.Next() {
+		f := service.BalanceReconciliationFinding{Kind: kind}
+		var ledgerID sql.NullInt64
+		if err := rows.Scan(&f.UserID, &f.SourceType, &f.SourceID, &ledgerID, &f.Expected, &f.Actual, &total); err != nil {
+			return nil, 0, err
+		}
+		f.LedgerID = ledgerID.Int64
+		out = append(out, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+func (r *balanceLedgerRepository) FindBalanceDrift(ctx context.Context, limit int) ([]service.BalanceReconciliationFinding, int, error) {
+	return r.queryFindings(ctx, service.BalanceDriftKindBalance, `
+		SELECT u.id, '', '', last.id, u.balance::double precision,
+			COALESCE(last.balance_after, 0)::double precision, COUNT(*) OVER ()
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT bl.id, bl.balance_after
+			FROM balance_ledger bl
+			WHERE bl.user_id = u.id
+			ORDER BY bl.id DESC
+			LIMIT 1
+		) last ON TRUE
+		WHERE u.deleted_at IS NULL
+			AND ABS(u.balance - COALESCE(last.balance_after, 0)) > $1
+		ORDER BY u.id
+		LIMIT $2
+	`, balanceLedgerTolerance, limit)
+}
+
+func (r *balanceLedgerRepository) FindLedgerGaps(ctx context.Context, start, end time.Time, limit int) ([]service.BalanceReconciliationFinding, int, error) {
+	// 取窗口前最后一条作为衔接起点，窗口内第一条也能被检查到。
+	return r.queryFindings(ctx, service.BalanceDriftKindLedgerGap, `
+		WITH chained AS (
+			SELECT bl.id, bl.user_id, bl.reason, bl.created_at, bl.balance_before,
+				LAG(bl.balance_after) OVER (PARTITION BY bl.user_id ORDER BY bl.id) AS prev_after
+			FROM balance_ledger bl
+			WHERE bl.created_at < $2
+				AND (bl.created_at >= $1 OR bl.id IN (
+					SELECT MAX(p.id) FROM balance_ledger p
+					WHERE p.created_at < $1
+						AND p.user_id IN (SELECT DISTINCT w.user_id FROM balance_ledger w WHERE w.created_at >= $1 AND w.created_at < $2)
+					GROUP BY p.user_id
+				))
+		)
+		SELECT user_id, '', '', id, prev_after::double precision, balance_before::double precision, COUNT(*) OVER ()
+		FROM chained
+		WHERE created_at >= $1
+			AND prev_after IS NOT NULL
+			AND ABS(prev_after - balance_before) > $3
+		ORDER BY id
+		LIMIT $4
+	`, start, end, balanceLedgerTolerance, limit)
+}
+
+func (r *balanceLedgerRepository) FindUsageDrift(ctx context.Context, start, end time.Time, limit int) ([]service.BalanceReconciliationFinding, int, error) {
+	// 只核对直接扣个人余额的请求：组织 Key 扣组织钱包，批量 API 的行费用走冻结结算。
+	return r.queryFindings(ctx, service.BalanceDriftKindUsage, `
+		WITH charged AS (
+			SELECT ul.id, ul.user_id, ul.request_id, ul.actual_cost
+			FROM usage_logs ul
+			JOIN api_keys ak ON ak.id = ul.api_key_id
+			WHERE ul.created_at >= $1 AND ul.created_at < $2
+				AND ul.billing_type = $3
+				AND ul.actual_cost > 0
+				AND ul.request_id IS NOT NULL
+				AND ak.organization_id IS NULL
+				AND NOT EXISTS (SELECT 1 FROM openai_batch_items obi WHERE obi.request_id = ul.request_id)
+		), matched AS (
+			SELECT c.id, c.user_id, c.request_id, c.actual_cost,
+				MAX(bl.id) AS ledger_id,
+				COALESCE(-SUM(bl.amount), 0) AS ledger_amount
+			FROM charged c
+			LEFT JOIN balance_ledger bl
+				ON bl.user_id = c.user_id
+				AND bl.reason = $4
+				AND ((bl.source_type = $5 AND bl.source_id = c.request_id)
+					OR (bl.source_type = $6 AND bl.source_id = c.id::text))
+			GROUP BY c.id, c.user_id, c.request_id, c.actual_cost
+		)
+		SELECT user_id, $5, request_id, ledger_id, actual_cost::double precision, ledger_amount::double precision, COUNT(*) OVER ()
+		FROM matched
+		WHERE ABS(actual_cost - ledger_amount) > $7
+		ORDER BY id
+		LIMIT $8
+	`, start, end, service.BillingTypeBalance, service.BalanceLedgerReasonUsage,
+		service.BalanceLedgerSourceUsageRequest, service.BalanceLedgerSourceUsageLog, balanceLedgerTolerance, limit)
+}
+
+func (r *balanceLedgerRepository) FindPaymentDrift(ctx context.Context, start, end time.Time, limit int) ([]service.BalanceReconciliationFinding, int, error) {
+	// 早于流水上线的订单没有入账流水，从最早的 opening 流水之后开始核对。
+	return r.queryFindings(ctx, service.BalanceDriftKindPayment, `
+		WITH matched AS (
+			SELECT po.id, po.user_id, po.amount,
+				MAX(bl.id) AS ledger_id,
+				COALESCE(SUM(bl.amount), 0) AS ledger_amount
+			FROM payment_orders po
+			LEFT JOIN balance_ledger bl
+				ON bl.user_id = po.user_id
+				AND bl.reason = $3
+				AND bl.source_type = $4
+				AND bl.source_id = po.id::text
+			WHERE po.order_type = $5
+				AND po.completed_at >= $1 AND po.completed_at < $2
+				AND po.completed_at > COALESCE((SELECT MIN(created_at) FROM balance_ledger), NOW())
+			GROUP BY po.id, po.user_id, po.amount
+		)
+		SELECT user_id, $4, id::text, ledger_id, amount::double precision, ledger_amount::double precision, COUNT(*) OVER ()
+		FROM matched
+		WHERE ABS(amount - ledger_amount) > $6
+		ORDER BY id
+		LIMIT $7
+	`, start, end, service.BalanceLedgerReasonPaymentRecharge, service.BalanceLedgerSourcePaymentOrder,
+		payment.OrderTypeBalance, balanceLedgerTolerance, limit)
+}
+
+func (r *balanceLedgerRepository) CreateReconciliationRun(ctx context.Context, run *service.BalanceReconciliationRun) error {
+	findings := run.Findings
+	if findings == nil {
+		findings = []service.BalanceReconciliationFinding{}
+	}
+	raw, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO balance_reconciliation_runs (
+			window_start, window_end, balance_drift_count, ledger_gap_count,
+			usage_drift_count, payment_drift_count, findings, started_at, finished_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, run.WindowStart, run.WindowEnd, run.BalanceDriftCount, run.LedgerGapCount,
+		run.UsageDriftCount, run.PaymentDriftCount, raw, run.StartedAt, run.FinishedAt).Scan(&run.ID)
+}
+
+const balanceReconciliationRunColumns = `id, window_start, window_end, balance_drift_count, ledger_gap_count,
+	usage_drift_count, payment_drift_count, findings, started_at, finished_at`
+
+func scanBalanceReconciliationRun(row rowScanner) (*service.BalanceReconciliationRun, error) {
+	var (
+		run service.BalanceReconciliationRun
+		raw []byte
+	)
+	if err := row.Scan(&run.ID, &run.WindowStart, &run.WindowEnd, &run.BalanceDriftCount, &run.LedgerGapCount,
+		&run.UsageDriftCount, &run.PaymentDriftCount, &raw, &run.StartedAt, &run.FinishedAt); err != nil {
+		return nil, err
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &run.Findings); err != nil {
+			return nil, fmt.Errorf("decode reconciliation findings: %w", err)
+		}
+	}
+	return &run, nil
+}
+
+func (r *balanceLedgerRepository) ListReconciliationRuns(ctx context.Context, params pagination.PaginationParams) ([]service.BalanceReconciliationRun, *pagination.PaginationResult, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM balance_reconciliation_runs`).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count reconciliation runs: %w", err)
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+balanceReconciliationRunColumns+`
+		FROM balance_reconciliation_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, nil, fmt.Errorf("list reconciliation runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.BalanceReconciliationRun, 0, params.Limit())
+	for rows.Next() {
+		run, err := scanBalanceReconciliationRun(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *balanceLedgerRepository) GetReconciliationRun(ctx context.Context, id int64) (*service.BalanceReconciliationRun, error) {
+	run, err := scanBalanceReconciliationRun(r.db.QueryRowContext(ctx, `
+		SELECT `+balanceReconciliationRunColumns+`
+		FROM balance_reconciliation_runs
+		WHERE id = $1
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrBalanceReconcileRunNotFound
+	}
+	return run, err
+}
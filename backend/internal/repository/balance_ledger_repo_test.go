@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRedeemBalanceAdjustment_BindsLedgerSourceInTransaction(t *testing.T) {
+	repo, mock := newRedeemAdjustmentRepoMock(t)
+	actorID := int64(3)
+	ctx := service.WithBalanceLedgerSource(context.Background(), service.BalanceLedgerSource{
+		Reason:     service.BalanceLedgerReasonAdminAdjust,
+		SourceType: service.BalanceLedgerSourceAdmin,
+		SourceID:   "3",
+		ActorID:    &actorID,
+		Note:       "manual fix",
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).
+		WithArgs(balanceLedgerSourceSetting, `{"actor_id":"3","note":"manual fix","reason":"admin_adjust","source_id":"3","source_type":"admin"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE users SET balance = GREATEST\(balance \+ \$1, 0\), updated_at = NOW\(\) WHERE id = \$2 AND deleted_at IS NULL`).
+		WithArgs(5.0, int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.ApplyRedeemBalanceAdjustment(ctx, 42, 5))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyRedeemBalanceAdjustment_LedgerSourceRollsBackOnFailure(t *testing.T) {
+	repo, mock := newRedeemAdjustmentRepoMock(t)
+	ctx := service.WithBalanceLedgerSource(context.Background(), service.BalanceLedgerSource{
+		Reason:     service.BalanceLedgerReasonRedeem,
+		SourceType: service.BalanceLedgerSourceRedeemCode,
+		SourceID:   "CODE",
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).
+		WithArgs(balanceLedgerSourceSetting, `{"note":"","reason":"redeem","source_id":"CODE","source_type":"redeem_code"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE users SET balance`).
+		WithArgs(5.0, int64(404)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.ApplyRedeemBalanceAdjustment(ctx, 404, 5)
+	require.ErrorIs(t, err, service.ErrUserNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBindBalanceLedgerSource_WrapsError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	mock.ExpectExec(`SELECT set_config`).WillReturnError(errors.New("boom"))
+	err = bindBalanceLedgerSource(context.Background(), db, service.BalanceLedgerSource{Reason: service.BalanceLedgerReasonUsage})
+	require.ErrorContains(t, err, "bind balance ledger source")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
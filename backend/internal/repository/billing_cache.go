@@ -10,10 +10,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
 )
 
+// 余额、订阅用量、限速窗口用量与 user × platform 用量在 Redis 中一律存为定格到
+// money.LedgerScale 位小数的十进制字符串（如 "12.34567891"），Lua 里先换算为整数记账单位
+// （1 单位 = 1e-8 USD）做加减再写回，避免 tonumber / HINCRBYFLOAT 的浮点累计误差。
+//
+// key 与字段沿用旧版命名，存储格式也仍是旧版能直接 ParseFloat / tonumber 的十进制串：
+// 滚动升级期间新旧副本读写同一组 key，旧副本 HINCRBYFLOAT 写入的浮点串在新副本下一次
+// 写入时按 half-away-from-zero 定格回 8 位小数，两边不会各自维护一份分叉的缓存。
 const (
 	billingBalanceKeyPrefix   = "billing:balance:"
 	billingSubKeyPrefix       = "billing:sub:"
@@ -72,27 +81,81 @@ const (
 	rateLimitFieldWindow7d = "window_7d"
 )
 
+// ledgerLuaHelpers 是各计费脚本共用的 Lua 前导：
+//
+//	ledger_units(v)  把十进制金额串换算为整数记账单位；超过 8 位的小数按 half-away-from-zero 舍入，
+//	                 旧版 Lua tostring 写出的科学计数法（如 "1e-05"）退回 tonumber 处理，缺失 / 损坏按 0。
+//	ledger_string(u) 把整数记账单位格式化为 8 位小数的十进制串。
+//
+// Redis Lua 的数字是 double，2^53 个单位（约 9e7 USD）以内的整数运算是精确的。
+const ledgerLuaHelpers = `
+local function ledger_units(v)
+	if not v or v == '' then
+		return 0
+	end
+	local sign, ip, fp = string.match(v, '^(-?)(%d*)%.?(%d*)$')
+	if not sign or (ip == '' and fp == '') then
+		local n = tonumber(v)
+		if not n or n ~= n then
+			return 0
+		end
+		if n < 0 then
+			return -math.floor(-n * 100000000 + 0.5)
+		end
+		return math.floor(n * 100000000 + 0.5)
+	end
+	if ip == '' then
+		ip = '0'
+	end
+	local carry = 0
+	if #fp > 8 then
+		if tonumber(string.sub(fp, 9, 9)) >= 5 then
+			carry = 1
+		end
+		fp = string.sub(fp, 1, 8)
+	end
+	fp = fp .. string.rep('0', 8 - #fp)
+	local u = tonumber(ip) * 100000000 + tonumber(fp) + carry
+	if sign == '-' then
+		return -u
+	end
+	return u
+end
+
+local function ledger_string(u)
+	local sign = ''
+	if u < 0 then
+		sign = '-'
+		u = -u
+	end
+	local ip = math.floor(u / 100000000)
+	return sign .. string.format('%.0f', ip) .. '.' .. string.format('%08.0f', u - ip * 100000000)
+end
+`
+
 var (
-	deductBalanceScript = redis.NewScript(`
+	// ARGV: [1]=amount_units, [2]=ttl_seconds
+	deductBalanceScript = redis.NewScript(ledgerLuaHelpers + `
 		local current = redis.call('GET', KEYS[1])
 		if current == false then
 			return 0
 		end
-		local newVal = tonumber(current) - tonumber(ARGV[1])
-		redis.call('SET', KEYS[1], newVal)
+		redis.call('SET', KEYS[1], ledger_string(ledger_units(current) - tonumber(ARGV[1])))
 		redis.call('EXPIRE', KEYS[1], ARGV[2])
 		return 1
 	`)
 
-	updateSubUsageScript = redis.NewScript(`
+	// ARGV: [1]=cost_units, [2]=ttl_seconds
+	updateSubUsageScript = redis.NewScript(ledgerLuaHelpers + `
 		local exists = redis.call('EXISTS', KEYS[1])
 		if exists == 0 then
 			return 0
 		end
 		local cost = tonumber(ARGV[1])
-		redis.call('HINCRBYFLOAT', KEYS[1], 'daily_usage', cost)
-		redis.call('HINCRBYFLOAT', KEYS[1], 'weekly_usage', cost)
-		redis.call('HINCRBYFLOAT', KEYS[1], 'monthly_usage', cost)
+		for _, field in ipairs({'daily_usage', 'weekly_usage', 'monthly_usage'}) do
+			local current = redis.call('HGET', KEYS[1], field)
+			redis.call('HSET', KEYS[1], field, ledger_string(ledger_units(current) + cost))
+		end
 		redis.call('EXPIRE', KEYS[1], ARGV[2])
 		return 1
 	`)
@@ -102,8 +165,8 @@ var (
 	// (instead of accumulated) and the window timestamp is updated, matching the DB-side
 	// IncrementRateLimitUsage semantics.
 	//
-	// ARGV: [1]=cost, [2]=ttl_seconds, [3]=now_unix, [4]=window_5h_seconds, [5]=window_1d_seconds, [6]=window_7d_seconds
-	updateRateLimitUsageScript = redis.NewScript(`
+	// ARGV: [1]=cost_units, [2]=ttl_seconds, [3]=now_unix, [4]=window_5h_seconds, [5]=window_1d_seconds, [6]=window_7d_seconds
+	updateRateLimitUsageScript = redis.NewScript(ledgerLuaHelpers + `
 		local exists = redis.call('EXISTS', KEYS[1])
 		if exists == 0 then
 			return 0
@@ -120,11 +183,12 @@ var (
 			local w = tonumber(redis.call('HGET', KEYS[1], window_field) or 0)
 			if w == 0 or (now - w) >= window_duration then
 				-- Window expired or never started: reset usage to cost, start new window
-				redis.call('HSET', KEYS[1], usage_field, tostring(cost))
+				redis.call('HSET', KEYS[1], usage_field, ledger_string(cost))
 				redis.call('HSET', KEYS[1], window_field, tostring(now))
 			else
 				-- Window still valid: accumulate
-				redis.call('HINCRBYFLOAT', KEYS[1], usage_field, cost)
+				local current = redis.call('HGET', KEYS[1], usage_field)
+				redis.call('HSET', KEYS[1], usage_field, ledger_string(ledger_units(current) + cost))
 			end
 		end
 
@@ -136,6 +200,34 @@ var (
 	`)
 )
 
+// formatLedgerAmount 把金额定格到 money.LedgerScale 位小数并格式化为十进制串写入 Redis。
+func formatLedgerAmount(v float64) string {
+	return money.Decimal(v).StringFixed(money.LedgerScale)
+}
+
+// parseLedgerAmount 把 Redis 中的十进制金额串解析为金额并定格到 money.LedgerScale；
+// 旧版副本写入的浮点串同样可以解析。
+func parseLedgerAmount(raw string) (float64, error) {
+	d, err := decimal.NewFromString(raw)
+	if err != nil {
+		return 0, err
+	}
+	return money.Float(d.Round(money.LedgerScale)), nil
+}
+
+// parseLedgerField 解析哈希中的金额字段；缺失或损坏的字段按 0 处理。
+func parseLedgerField(raw string) float64 {
+	if raw == "" {
+		return 0
+	}
+	v, err := parseLedgerAmount(raw)
+	if err != nil {
+		log.Printf("billing_cache: corrupt ledger amount %q (using 0): %v", raw, err)
+		return 0
+	}
+	return v
+}
+
 type billingCache struct {
 	rdb *redis.Client
 }
@@ -150,17 +242,17 @@ func (c *billingCache) GetUserBalance(ctx context.Context, userID int64) (float6
 	if err != nil {
 		return 0, err
 	}
-	return strconv.ParseFloat(val, 64)
+	return parseLedgerAmount(val)
 }
 
 func (c *billingCache) SetUserBalance(ctx context.Context, userID int64, balance float64) error {
 	key := billingBalanceKey(userID)
-	return c.rdb.Set(ctx, key, balance, jitteredTTL()).Err()
+	return c.rdb.Set(ctx, key, formatLedgerAmount(balance), jitteredTTL()).Err()
 }
 
 func (c *billingCache) DeductUserBalance(ctx context.Context, userID int64, amount float64) error {
 	key := billingBalanceKey(userID)
-	_, err := deductBalanceScript.Run(ctx, c.rdb, []string{key}, amount, int(jitteredTTL().Seconds())).Result()
+	_, err := deductBalanceScript.Run(ctx, c.rdb, []string{key}, money.ToUnits(amount), int(jitteredTTL().Seconds())).Result()
 	if err != nil && !errors.Is(err, redis.Nil) {
 		log.Printf("Warning: deduct balance cache failed for user %d: %v", userID, err)
 		return err
@@ -200,17 +292,9 @@ func (c *billingCache) parseSubscriptionCache(data map[string]string) (*service.
 		}
 	}
 
-	if dailyStr, ok := data[subFieldDailyUsage]; ok {
-		result.DailyUsage, _ = strconv.ParseFloat(dailyStr, 64)
-	}
-
-	if weeklyStr, ok := data[subFieldWeeklyUsage]; ok {
-		result.WeeklyUsage, _ = strconv.ParseFloat(weeklyStr, 64)
-	}
-
-	if monthlyStr, ok := data[subFieldMonthlyUsage]; ok {
-		result.MonthlyUsage, _ = strconv.ParseFloat(monthlyStr, 64)
-	}
+	result.DailyUsage = parseLedgerField(data[subFieldDailyUsage])
+	result.WeeklyUsage = parseLedgerField(data[subFieldWeeklyUsage])
+	result.MonthlyUsage = parseLedgerField(data[subFieldMonthlyUsage])
 
 	if versionStr, ok := data[subFieldVersion]; ok {
 		result.Version, _ = strconv.ParseInt(versionStr, 10, 64)
@@ -229,9 +313,9 @@ func (c *billingCache) SetSubscriptionCache(ctx context.Context, userID, groupID
 	fields := map[string]any{
 		subFieldStatus:       data.Status,
 		subFieldExpiresAt:    data.ExpiresAt.Unix(),
-		subFieldDailyUsage:   data.DailyUsage,
-		subFieldWeeklyUsage:  data.WeeklyUsage,
-		subFieldMonthlyUsage: data.MonthlyUsage,
+		subFieldDailyUsage:   formatLedgerAmount(data.DailyUsage),
+		subFieldWeeklyUsage:  formatLedgerAmount(data.WeeklyUsage),
+		subFieldMonthlyUsage: formatLedgerAmount(data.MonthlyUsage),
 		subFieldVersion:      data.Version,
 	}
 
@@ -244,7 +328,7 @@ func (c *billingCache) SetSubscriptionCache(ctx context.Context, userID, groupID
 
 func (c *billingCache) UpdateSubscriptionUsage(ctx context.Context, userID, groupID int64, cost float64) error {
 	key := billingSubKey(userID, groupID)
-	_, err := updateSubUsageScript.Run(ctx, c.rdb, []string{key}, cost, int(jitteredTTL().Seconds())).Result()
+	_, err := updateSubUsageScript.Run(ctx, c.rdb, []string{key}, money.ToUnits(cost), int(jitteredTTL().Seconds())).Result()
 	if err != nil && !errors.Is(err, redis.Nil) {
 		log.Printf("Warning: update subscription usage cache failed for user %d group %d: %v", userID, groupID, err)
 		return err
@@ -306,15 +390,9 @@ func (c *billingCache) GetAPIKeyRateLimit(ctx context.Context, keyID int64) (*se
 		return nil, redis.Nil
 	}
 	data := &service.APIKeyRateLimitCacheData{}
-	if v, ok := result[rateLimitFieldUsage5h]; ok {
-		data.Usage5h, _ = strconv.ParseFloat(v, 64)
-	}
-	if v, ok := result[rateLimitFieldUsage1d]; ok {
-		data.Usage1d, _ = strconv.ParseFloat(v, 64)
-	}
-	if v, ok := result[rateLimitFieldUsage7d]; ok {
-		data.Usage7d, _ = strconv.ParseFloat(v, 64)
-	}
+	data.Usage5h = parseLedgerField(result[rateLimitFieldUsage5h])
+	data.Usage1d = parseLedgerField(result[rateLimitFieldUsage1d])
+	data.Usage7d = parseLedgerField(result[rateLimitFieldUsage7d])
 	if v, ok := result[rateLimitFieldWindow5h]; ok {
 		data.Window5h, _ = strconv.ParseInt(v, 10, 64)
 	}
@@ -333,9 +411,9 @@ func (c *billingCache) SetAPIKeyRateLimit(ctx context.Context, keyID int64, data
 	}
 	key := billingRateLimitKey(keyID)
 	fields := map[string]any{
-		rateLimitFieldUsage5h:  data.Usage5h,
-		rateLimitFieldUsage1d:  data.Usage1d,
-		rateLimitFieldUsage7d:  data.Usage7d,
+		rateLimitFieldUsage5h:  formatLedgerAmount(data.Usage5h),
+		rateLimitFieldUsage1d:  formatLedgerAmount(data.Usage1d),
+		rateLimitFieldUsage7d:  formatLedgerAmount(data.Usage7d),
 		rateLimitFieldWindow5h: data.Window5h,
 		rateLimitFieldWindow1d: data.Window1d,
 		rateLimitFieldWindow7d: data.Window7d,
@@ -351,7 +429,7 @@ func (c *billingCache) UpdateAPIKeyRateLimitUsage(ctx context.Context, keyID int
 	key := billingRateLimitKey(keyID)
 	now := time.Now().Unix()
 	_, err := updateRateLimitUsageScript.Run(ctx, c.rdb, []string{key},
-		cost,
+		money.ToUnits(cost),
 		int(rateLimitCacheTTL.Seconds()),
 		now,
 		int(rateLimitWindow5h.Seconds()),
@@ -386,17 +464,6 @@ func parseUserPlatformQuotaHash(m map[string]string) *service.UserPlatformQuotaC
 	if len(m) == 0 {
 		return nil
 	}
-	parseFloat := func(s string) float64 {
-		if s == "" {
-			return 0
-		}
-		f, err := strconv.ParseFloat(s, 64)
-		if err != nil {
-			log.Printf("billing_cache: corrupt quota usage field %q (using 0): %v", s, err)
-			return 0
-		}
-		return f
-	}
 	parseFloatPtr := func(s string) *float64 {
 		if s == "" {
 			return nil
@@ -423,9 +490,9 @@ func parseUserPlatformQuotaHash(m map[string]string) *service.UserPlatformQuotaC
 		return n
 	}
 	return &service.UserPlatformQuotaCacheEntry{
-		DailyUsageUSD:      parseFloat(m["daily_usage"]),
-		WeeklyUsageUSD:     parseFloat(m["weekly_usage"]),
-		MonthlyUsageUSD:    parseFloat(m["monthly_usage"]),
+		DailyUsageUSD:      parseLedgerField(m["daily_usage"]),
+		WeeklyUsageUSD:     parseLedgerField(m["weekly_usage"]),
+		MonthlyUsageUSD:    parseLedgerField(m["monthly_usage"]),
 		Version:            parseInt64(m["version"]),
 		SchemaVersion:      parseInt64(m["schema_version"]),
 		DailyLimitUSD:      parseFloatPtr(m["daily_limit"]),
@@ -474,9 +541,9 @@ func (c *billingCache) SetUserPlatformQuotaCache(ctx context.Context, userID int
 	}
 
 	pipe.HSet(ctx, key,
-		"daily_usage", entry.DailyUsageUSD,
-		"weekly_usage", entry.WeeklyUsageUSD,
-		"monthly_usage", entry.MonthlyUsageUSD,
+		"daily_usage", formatLedgerAmount(entry.DailyUsageUSD),
+		"weekly_usage", formatLedgerAmount(entry.WeeklyUsageUSD),
+		"monthly_usage", formatLedgerAmount(entry.MonthlyUsageUSD),
 		"version", entry.Version,
 		"schema_version", entry.SchemaVersion,
 		"daily_limit", fmtFloatPtr(entry.DailyLimitUSD),
@@ -501,12 +568,12 @@ func (c *billingCache) DeleteUserPlatformQuotaCache(ctx context.Context, userID
 // key 不存在同样跳过（由下次 SetCache 重建）。
 // KEYS[1] = hash key
 // KEYS[2] = 脏集 key（dirty set）
-// ARGV[1] = cost (string float)
+// ARGV[1] = cost（整数记账单位）
 // ARGV[2] = ttl seconds
 // ARGV[3] = expected schema_version (Go 侧 UserPlatformQuotaCacheSchemaV1)
 // ARGV[4] = dirty set member（空串则不 SADD）
 // ARGV[5] = 脏集兜底 TTL 秒
-const updateUserPlatformQuotaUsageScript = `
+const updateUserPlatformQuotaUsageScript = ledgerLuaHelpers + `
 if redis.call("EXISTS", KEYS[1]) == 0 then
     return 0
 end
@@ -514,9 +581,10 @@ local ver = redis.call("HGET", KEYS[1], "schema_version")
 if ver == false or tonumber(ver) ~= tonumber(ARGV[3]) then
     return 0
 end
-redis.call("HINCRBYFLOAT", KEYS[1], "daily_usage", ARGV[1])
-redis.call("HINCRBYFLOAT", KEYS[1], "weekly_usage", ARGV[1])
-redis.call("HINCRBYFLOAT", KEYS[1], "monthly_usage", ARGV[1])
+local cost = tonumber(ARGV[1])
+for _, field in ipairs({"daily_usage", "weekly_usage", "monthly_usage"}) do
+    redis.call("HSET", KEYS[1], field, ledger_string(ledger_units(redis.call("HGET", KEYS[1], field)) + cost))
+end
 redis.call("HINCRBY", KEYS[1], "version", 1)
 redis.call("EXPIRE", KEYS[1], ARGV[2])
 if ARGV[4] ~= "" then
@@ -546,7 +614,7 @@ func (c *billingCache) IncrUserPlatformQuotaUsageCache(ctx context.Context, user
 	}
 	_, err := c.rdb.Eval(ctx, updateUserPlatformQuotaUsageScript,
 		[]string{userPlatformQuotaCacheKey(userID, platform), userPlatformQuotaDirtySetKey()},
-		strconv.FormatFloat(cost, 'f', -1, 64),
+		money.ToUnits(cost),
 		int(ttl.Seconds()),
 		service.UserPlatformQuotaCacheSchemaV1,
 		member,
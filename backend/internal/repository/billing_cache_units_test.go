@@ -0,0 +1,138 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBillingCache_BalanceStoredAsLedgerDecimal(t *testing.T) {
+	c, mr := newMiniRedisCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.SetUserBalance(ctx, 1, 10.5))
+	raw, err := mr.Get(billingBalanceKey(1))
+	require.NoError(t, err)
+	require.Equal(t, "10.50000000", raw)
+
+	require.NoError(t, c.DeductUserBalance(ctx, 1, 0.000000015))
+	got, err := c.GetUserBalance(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 10.49999998, got)
+}
+
+func TestBillingCache_BalanceDeductionsReconcileExactly(t *testing.T) {
+	c, _ := newMiniRedisCache(t)
+	ctx := context.Background()
+	require.NoError(t, c.SetUserBalance(ctx, 1, 100))
+
+	remaining := money.Decimal(100)
+	for i := 0; i < 2000; i++ {
+		cost := money.RoundLedger(0.00012345 + float64(i%13)*0.00000107)
+		require.NoError(t, c.DeductUserBalance(ctx, 1, cost))
+		remaining = remaining.Sub(money.Decimal(cost))
+	}
+
+	got, err := c.GetUserBalance(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, money.Decimal(got).Equal(remaining), "cache=%v expected=%s", got, remaining)
+}
+
+func TestBillingCache_SubscriptionUsageAccumulatesUnits(t *testing.T) {
+	c, _ := newMiniRedisCache(t)
+	ctx := context.Background()
+	require.NoError(t, c.SetSubscriptionCache(ctx, 1, 2, &service.SubscriptionCacheData{
+		Status:       "active",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		DailyUsage:   1,
+		WeeklyUsage:  2,
+		MonthlyUsage: 3,
+		Version:      1,
+	}))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.UpdateSubscriptionUsage(ctx, 1, 2, 0.1))
+	}
+
+	got, err := c.GetSubscriptionCache(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, got.DailyUsage)
+	require.Equal(t, 3.0, got.WeeklyUsage)
+	require.Equal(t, 4.0, got.MonthlyUsage)
+}
+
+func TestBillingCache_RateLimitUsageResetsAndAccumulatesUnits(t *testing.T) {
+	c, mr := newMiniRedisCache(t)
+	ctx := context.Background()
+	now := time.Now().Unix()
+	require.NoError(t, c.SetAPIKeyRateLimit(ctx, 9, &service.APIKeyRateLimitCacheData{
+		Usage5h:  0.3,
+		Usage1d:  0.3,
+		Usage7d:  0.3,
+		Window5h: now - int64(rateLimitWindow5h.Seconds()) - 1, // 已过期
+		Window1d: now,
+		Window7d: now,
+	}))
+
+	require.NoError(t, c.UpdateAPIKeyRateLimitUsage(ctx, 9, 0.1))
+	require.NoError(t, c.UpdateAPIKeyRateLimitUsage(ctx, 9, 0.2))
+
+	raw := mr.HGet(billingRateLimitKey(9), rateLimitFieldUsage1d)
+	require.Equal(t, "0.60000000", raw)
+
+	got, err := c.GetAPIKeyRateLimit(ctx, 9)
+	require.NoError(t, err)
+	require.Equal(t, 0.3, got.Usage5h, "过期窗口从本次费用重新累计")
+	require.Equal(t, 0.6, got.Usage1d)
+	require.True(t, decimal.NewFromFloat(got.Usage7d).Equal(decimal.RequireFromString("0.6")))
+}
+
+// 滚动升级期间旧副本仍按浮点串写同一组 key（SET tostring / HINCRBYFLOAT），
+// 新副本必须能在其上继续精确累加，并把结果定格回 8 位小数。
+func TestBillingCache_InteroperatesWithLegacyFloatValues(t *testing.T) {
+	c, mr := newMiniRedisCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, mr.Set(billingBalanceKey(1), "9.9999999999999982"))
+	require.NoError(t, c.DeductUserBalance(ctx, 1, 0.5))
+	raw, err := mr.Get(billingBalanceKey(1))
+	require.NoError(t, err)
+	require.Equal(t, "9.50000000", raw)
+
+	require.NoError(t, mr.Set(billingBalanceKey(2), "-0.3000000000000000444"))
+	require.NoError(t, c.DeductUserBalance(ctx, 2, 0.00002))
+	got, err := c.GetUserBalance(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, -0.30002, got)
+
+	subKey := billingSubKey(1, 2)
+	mr.HSet(subKey, subFieldStatus, "active")
+	mr.HSet(subKey, subFieldDailyUsage, "0.30000000000000004")
+	mr.HSet(subKey, subFieldWeeklyUsage, "0.123456785")
+	mr.HSet(subKey, subFieldMonthlyUsage, "2")
+	require.NoError(t, c.UpdateSubscriptionUsage(ctx, 1, 2, 0.1))
+	require.Equal(t, "0.40000000", mr.HGet(subKey, subFieldDailyUsage))
+	require.Equal(t, "0.22345679", mr.HGet(subKey, subFieldWeeklyUsage), "第 9 位 half 边界远离零舍入")
+	require.Equal(t, "2.10000000", mr.HGet(subKey, subFieldMonthlyUsage))
+
+	// 旧副本对新格式的值继续 HINCRBYFLOAT 也能正常工作。
+	_, err = mr.HIncrByFloat(subKey, subFieldDailyUsage, 0.1)
+	require.NoError(t, err)
+	sub, err := c.GetSubscriptionCache(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, sub.DailyUsage)
+}
+
+func TestParseLedgerFieldToleratesCorruptValues(t *testing.T) {
+	require.Zero(t, parseLedgerField(""))
+	require.Zero(t, parseLedgerField("not-a-number"))
+	require.Equal(t, 1.5, parseLedgerField("1.5"))
+	require.Equal(t, 0.12345679, parseLedgerField("0.123456785"))
+}
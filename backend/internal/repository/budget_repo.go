@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type budgetRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetRepository(db *sql.DB) service.BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+const budgetColumns = `id, scope_type, scope_id, model, period, limit_usd, enforcement, enabled, source,
+	created_by, alert_period_start, alert_percent, created_at, updated_at`
+
+func scanBudget(row rowScanner) (*service.Budget, error) {
+	var (
+		b                service.Budget
+		createdBy        sql.NullInt64
+		alertPeriodStart sql.NullTime
+	)
+	if err := row.Scan(&b.ID, &b.ScopeType, &b.ScopeID, &b.Model, &b.Period, &b.LimitUSD, &b.Enforcement, &b.Enabled, &b.Source,
+		&createdBy, &alertPeriodStart, &b.AlertPercent, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		v := createdBy.Int64
+		b.CreatedBy = &v
+	}
+	if alertPeriodStart.Valid {
+		v := alertPeriodStart.Time
+		b.AlertPeriodStart = &v
+	}
+	return &b, nil
+}
+
+func (r *budgetRepository) queryBudgets(ctx context.Context, query string, args ...any) ([]service.Budget, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.Budget, 0)
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *budgetRepository) Create(ctx context.Context, budget *service.Budget) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO budgets (scope_type, scope_id, model, period, limit_usd, enforcement, enabled, source, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`, budget.ScopeType, budget.ScopeID, budget.Model, budget.Period, budget.LimitUSD, budget.Enforcement,
+		budget.Enabled, budget.Source, budget.CreatedBy).Scan(&budget.ID, &budget.CreatedAt, &budget.UpdatedAt)
+	if isUniqueViolation(err) {
+		return service.ErrBudgetExists
+	}
+	if err != nil {
+		return fmt.Errorf("create budget: %w", err)
+	}
+	return nil
+}
+
+func (r *budgetRepository) GetByID(ctx context.Context, id int64) (*service.Budget, error) {
+	b, err := scanBudget(r.db.QueryRowContext(ctx, `SELECT `+budgetColumns+` FROM budgets WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrBudgetNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get budget: %w", err)
+	}
+	return b, nil
+}
+
+// Update 修改预算口径后清空本周期的通知进度，让新上限重新按 50/80/100% 通知。
+func (r *budgetRepository) Update(ctx context.Context, budget *service.Budget) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE budgets
+		SET model = $2, period = $3, limit_usd = $4, enforcement = $5, enabled = $6,
+			alert_period_start = NULL, alert_percent = 0, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, budget.ID, budget.Model, budget.Period, budget.LimitUSD, budget.Enforcement, budget.Enabled).Scan(&budget.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrBudgetNotFound
+	}
+	if isUniqueViolation(err) {
+		return service.ErrBudgetExists
+	}
+	if err != nil {
+		return fmt.Errorf("update budget: %w", err)
+	}
+	budget.AlertPeriodStart = nil
+	budget.AlertPercent = 0
+	return nil
+}
+
+func (r *budgetRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM budgets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete budget: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return service.ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (r *budgetRepository) List(ctx context.Context, params pagination.PaginationParams, filter service.BudgetListFilter) ([]service.Budget, *pagination.PaginationResult, error) {
+	where := "TRUE"
+	args := []any{}
+	if filter.ScopeType != "" {
+		args = append(args, filter.ScopeType)
+		where += fmt.Sprintf(" AND scope_type = $%d", len(args))
+	}
+	if filter.ScopeID > 0 {
+		args = append(args, filter.ScopeID)
+		where += fmt.Sprintf(" AND scope_id = $%d", len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM budgets WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count budgets: %w", err)
+	}
+
+	args = append(args, params.Limit(), params.Offset())
+	out, err := r.queryBudgets(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM budgets
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d
+	`, budgetColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list budgets: %w", err)
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *budgetRepository) ListByScope(ctx context.Context, scope service.BudgetScopeRef, enabledOnly bool) ([]service.Budget, error) {
+	out, err := r.queryBudgets(ctx, `
+		SELECT `+budgetColumns+`
+		FROM budgets
+		WHERE scope_type = $1 AND scope_id = $2 AND ($3 = FALSE OR enabled)
+		ORDER BY id ASC
+	`, scope.Type, scope.ID, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list budgets by scope: %w", err)
+	}
+	return out, nil
+}
+
+func (r *budgetRepository) ListForUser(ctx context.Context, userID int64) ([]service.Budget, error) {
+	out, err := r.queryBudgets(ctx, `
+		SELECT `+budgetColumns+`
+		FROM budgets
+		WHERE (scope_type = 'user' AND scope_id = $1)
+			OR (scope_type = 'api_key' AND scope_id IN (
+				SELECT id FROM api_keys WHERE user_id = $1 AND deleted_at IS NULL
+			))
+		ORDER BY scope_type DESC, scope_id ASC, id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list budgets for user: %w", err)
+	}
+	return out, nil
+}
+
+func (r *budgetRepository) CountByScope(ctx context.Context, scope service.BudgetScopeRef) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM budgets WHERE scope_type = $1 AND scope_id = $2
+	`, scope.Type, scope.ID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count budgets by scope: %w", err)
+	}
+	return n, nil
+}
+
+// GetSpend 只统计余额计费（billing_type = 0）的用量；按模型的预算以客户端请求的模型名归集。
+func (r *budgetRepository) GetSpend(ctx context.Context, budget *service.Budget, start, end time.Time) (float64, error) {
+	var column string
+	switch budget.ScopeType {
+	case service.BudgetScopeUser:
+		column = "user_id"
+	case service.BudgetScopeAPIKey:
+		column = "api_key_id"
+	case service.BudgetScopeGroup:
+		column = "group_id"
+	default:
+		return 0, service.ErrBudgetInvalidScope
+	}
+
+	query := `
+		SELECT COALESCE(SUM(actual_cost), 0)
+		FROM usage_logs
+		WHERE ` + column + ` = $1 AND billing_type = $2 AND created_at >= $3 AND created_at < $4`
+	args := []any{budget.ScopeID, service.BillingTypeBalance, start, end}
+	if budget.Model != "" {
+		args = append(args, budget.Model)
+		query += ` AND COALESCE(NULLIF(requested_model, ''), model) = $5`
+	}
+
+	var spent float64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&spent); err != nil {
+		return 0, fmt.Errorf("get budget spend: %w", err)
+	}
+	return spent, nil
+}
+
+func (r *budgetRepository) ClaimAlert(ctx context.Context, id int64, periodStart time.Time, percent int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE budgets
+		SET alert_period_start = $2, alert_percent = $3
+		WHERE id = $1 AND (alert_period_start IS DISTINCT FROM $2 OR alert_percent < $3)
+	`, id, periodStart, percent)
+	if err != nil {
+		return false, fmt.Errorf("claim budget alert: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
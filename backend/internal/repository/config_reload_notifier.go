@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const configReloadPubSubKey = "config_reload_requested"
+
+type configReloadNotifier struct {
+	rdb *redis.Client
+}
+
+// NewConfigReloadNotifier 创建配置重载广播器
+func NewConfigReloadNotifier(rdb *redis.Client) service.ConfigReloadNotifier {
+	return &configReloadNotifier{rdb: rdb}
+}
+
+// NotifyReload 通知其他实例重新加载配置文件
+func (c *configReloadNotifier) NotifyReload(ctx context.Context, origin string) error {
+	return c.rdb.Publish(ctx, configReloadPubSubKey, origin).Err()
+}
+
+// SubscribeReloads 订阅配置重载通知
+func (c *configReloadNotifier) SubscribeReloads(ctx context.Context, handler func(origin string)) {
+	go func() {
+		sub := c.rdb.Subscribe(ctx, configReloadPubSubKey)
+		defer func() { _ = sub.Close() }()
+
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-ch:
+				if msg == nil {
+					return
+				}
+				handler(msg.Payload)
+			}
+		}
+	}()
+}
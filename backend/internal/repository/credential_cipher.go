@@ -0,0 +1,617 @@
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const (
+	// credentialCiphertextPrefix 加密值格式："enc:v1:<数据密钥 ID>:<base64(nonce||密文)>"
+	credentialCiphertextPrefix = "enc:v1:"
+	credentialDataKeyIDBytes   = 8
+	// credentialKeyMissRefreshInterval 遇到未知数据密钥时按需刷新的最小间隔，防止坏数据打爆数据库
+	credentialKeyMissRefreshInterval = 5 * time.Second
+)
+
+var errCredentialDataKeyUnavailable = errors.New("credential data key unavailable")
+
+// CredentialCipher 对 accounts.credentials 中的敏感子键做信封加密。
+//
+// 每个敏感值序列化为 JSON 后用数据密钥（DEK）以 AES-256-GCM 加密，密文携带 DEK ID；
+// DEK 存于 credential_encryption_keys 表，由配置中的主密钥包裹。nonce 由 HMAC(字段名, 明文)
+// 派生（确定性加密）：同一 DEK 下相同明文得到相同密文，代价是数据库里能看出两个账号是否共享
+// 同一个值。确定性只在同一 DEK 内成立，库里同时存在明文行（刚开启加密）与旧 DEK 的密文（轮换后
+// 尚未改写），因此原生 SQL 的等值比较不能拿按活动 DEK 重新加密的期望值直接比：整份 credentials
+// 的 CAS 先解密行内值在 Go 里比较（见 accountRepository.credentialsCAS），api_key 分组按全部
+// 存储形态匹配（见 credentialValueCandidates）。字段名同时作为 AAD，密文不能挪到其他子键下解密。
+//
+// nil（未配置主密钥）时写入为明文透传，读到的密文原样保留。
+type CredentialCipher struct {
+	db                *sql.DB
+	enabled           bool
+	masterKeys        map[string][]byte
+	activeMasterKeyID string
+
+	mu       sync.RWMutex
+	keys     map[string]*credentialDataKey
+	activeID string
+
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+type credentialDataKey struct {
+	meta   service.CredentialEncryptionKey
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// NewCredentialCipher 加载数据密钥；启用加密且没有活动数据密钥时生成一个。
+func NewCredentialCipher(cfg *config.Config, db *sql.DB) (*CredentialCipher, error) {
+	ec := cfg.Security.CredentialEncryption
+	if len(ec.MasterKeys) == 0 {
+		warnOrphanCredentialDataKeys(db)
+		return nil, nil
+	}
+	c := &CredentialCipher{
+		db:                db,
+		enabled:           ec.Enabled,
+		masterKeys:        make(map[string][]byte, len(ec.MasterKeys)),
+		activeMasterKeyID: ec.ActiveMasterKeyID,
+		keys:              map[string]*credentialDataKey{},
+	}
+	for id, hexKey := range ec.MasterKeys {
+		key, err := hex.DecodeString(hexKey)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("credential encryption master key %s must be 32 bytes (64 hex chars)", id)
+		}
+		c.masterKeys[id] = key
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.RefreshKeys(ctx); err != nil {
+		return nil, fmt.Errorf("load credential data keys: %w", err)
+	}
+	if c.enabled && c.activeKey() == nil {
+		if err := c.insertActiveDataKey(ctx, c.db); err != nil {
+			return nil, fmt.Errorf("create credential data key: %w", err)
+		}
+		if err := c.RefreshKeys(ctx); err != nil {
+			return nil, fmt.Errorf("load credential data keys: %w", err)
+		}
+		if c.activeKey() == nil {
+			return nil, fmt.Errorf("active credential data key is wrapped by a master key that is not configured")
+		}
+	}
+	return c, nil
+}
+
+// warnOrphanCredentialDataKeys 未配置主密钥但库里已有数据密钥时提醒：已加密的凭证将无法解密。
+func warnOrphanCredentialDataKeys(db *sql.DB) {
+	if db == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var count int64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM credential_encryption_keys`).Scan(&count); err != nil || count == 0 {
+		return
+	}
+	logger.LegacyPrintf("repository.credential_cipher", "[CredentialEncryption] Warning: %d data keys exist but security.credential_encryption.master_keys is empty; encrypted account credentials cannot be decrypted", count)
+}
+
+// Enabled 报告新写入的凭证是否加密
+func (c *CredentialCipher) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// RefreshKeys 从数据库重新加载全部数据密钥；由非活动主密钥包裹的密钥顺带改用活动主密钥重新包裹。
+func (c *CredentialCipher) RefreshKeys(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id, wrapped_key, master_key_id, active, created_at, rotated_at
+		FROM credential_encryption_keys
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	keys := map[string]*credentialDataKey{}
+	activeID := ""
+	var rewrap []*credentialDataKey
+	rawKeys := map[string][]byte{}
+	for rows.Next() {
+		var (
+			wrapped   string
+			rotatedAt sql.NullTime
+			dk        credentialDataKey
+		)
+		if err := rows.Scan(&dk.meta.ID, &wrapped, &dk.meta.MasterKeyID, &dk.meta.Active, &dk.meta.CreatedAt, &rotatedAt); err != nil {
+			return err
+		}
+		if rotatedAt.Valid {
+			t := rotatedAt.Time
+			dk.meta.RotatedAt = &t
+		}
+		if raw, err := c.unwrapDataKey(dk.meta.ID, dk.meta.MasterKeyID, wrapped); err != nil {
+			logger.LegacyPrintf("repository.credential_cipher", "[CredentialEncryption] Warning: data key %s unavailable: %v", dk.meta.ID, err)
+		} else if err := dk.init(raw); err != nil {
+			return err
+		} else {
+			dk.meta.Available = true
+			rawKeys[dk.meta.ID] = raw
+			if c.activeMasterKeyID != "" && dk.meta.MasterKeyID != c.activeMasterKeyID {
+				rewrap = append(rewrap, &dk)
+			}
+		}
+		keys[dk.meta.ID] = &dk
+		if dk.meta.Active {
+			activeID = dk.meta.ID
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, dk := range rewrap {
+		if err := c.rewrapDataKey(ctx, dk, rawKeys[dk.meta.ID]); err != nil {
+			logger.LegacyPrintf("repository.credential_cipher", "[CredentialEncryption] Rewrap data key %s failed: %v", dk.meta.ID, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.activeID = activeID
+	c.mu.Unlock()
+	c.refreshMu.Lock()
+	c.lastRefresh = time.Now()
+	c.refreshMu.Unlock()
+	return nil
+}
+
+// RotateDataKey 停用当前数据密钥并生成新的活动数据密钥。旧密钥保留用于解密尚未改写的值。
+func (c *CredentialCipher) RotateDataKey(ctx context.Context) (string, error) {
+	if !c.Enabled() {
+		return "", service.ErrCredentialEncryptionDisabled
+	}
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE credential_encryption_keys SET active = FALSE, rotated_at = NOW() WHERE active
+	`); err != nil {
+		return "", err
+	}
+	if err := c.insertActiveDataKey(ctx, tx); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	if err := c.RefreshKeys(ctx); err != nil {
+		return "", err
+	}
+	active := c.activeKey()
+	if active == nil {
+		return "", errCredentialDataKeyUnavailable
+	}
+	return active.meta.ID, nil
+}
+
+// insertActiveDataKey 生成并写入新的活动数据密钥。并发启动的实例由 active 部分唯一索引去重。
+func (c *CredentialCipher) insertActiveDataKey(ctx context.Context, exec sqlExecutor) error {
+	masterKey, ok := c.masterKeys[c.activeMasterKeyID]
+	if !ok {
+		return fmt.Errorf("active master key %q is not configured", c.activeMasterKeyID)
+	}
+	idBytes := make([]byte, credentialDataKeyIDBytes)
+	if _, err := io.ReadFull(rand.Reader, idBytes); err != nil {
+		return err
+	}
+	raw := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return err
+	}
+	id := hex.EncodeToString(idBytes)
+	wrapped, err := wrapCredentialDataKey(masterKey, id, raw)
+	if err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO credential_encryption_keys (id, wrapped_key, master_key_id, active)
+		VALUES ($1, $2, $3, TRUE)
+		ON CONFLICT DO NOTHING
+	`, id, wrapped, c.activeMasterKeyID)
+	return err
+}
+
+func (c *CredentialCipher) rewrapDataKey(ctx context.Context, dk *credentialDataKey, raw []byte) error {
+	wrapped, err := wrapCredentialDataKey(c.masterKeys[c.activeMasterKeyID], dk.meta.ID, raw)
+	if err != nil {
+		return err
+	}
+	if _, err := c.db.ExecContext(ctx, `
+		UPDATE credential_encryption_keys SET wrapped_key = $2, master_key_id = $3
+		WHERE id = $1 AND master_key_id = $4
+	`, dk.meta.ID, wrapped, c.activeMasterKeyID, dk.meta.MasterKeyID); err != nil {
+		return err
+	}
+	dk.meta.MasterKeyID = c.activeMasterKeyID
+	return nil
+}
+
+func (c *CredentialCipher) unwrapDataKey(id, masterKeyID, wrapped string) ([]byte, error) {
+	masterKey, ok := c.masterKeys[masterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("master key %q is not configured", masterKeyID)
+	}
+	data, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("decode wrapped key: %w", err)
+	}
+	aead, err := newAESGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	raw, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap: %w", err)
+	}
+	return raw, nil
+}
+
+// wrapCredentialDataKey 用主密钥包裹数据密钥，DEK ID 作为 AAD 防止包裹结果被挪用到其他 ID。
+func wrapCredentialDataKey(masterKey []byte, id string, raw []byte) (string, error) {
+	aead, err := newAESGCM(masterKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, raw, []byte(id))), nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// init 从数据密钥派生加密子密钥与 nonce 派生子密钥。
+func (dk *credentialDataKey) init(raw []byte) error {
+	encKey := hmacSHA256(raw, []byte("credential-encryption/v1/enc"))
+	aead, err := newAESGCM(encKey)
+	if err != nil {
+		return err
+	}
+	dk.aead = aead
+	dk.macKey = hmacSHA256(raw, []byte("credential-encryption/v1/nonce"))
+	return nil
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func (c *CredentialCipher) activeKey() *credentialDataKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if dk := c.keys[c.activeID]; dk != nil && dk.aead != nil {
+		return dk
+	}
+	return nil
+}
+
+func (c *CredentialCipher) activeKeyID() string {
+	if c == nil {
+		return ""
+	}
+	if dk := c.activeKey(); dk != nil {
+		return dk.meta.ID
+	}
+	return ""
+}
+
+// lookupKey 查找数据密钥；未知 ID 多半是其他实例刚轮换，按需刷新一次。
+func (c *CredentialCipher) lookupKey(ctx context.Context, id string) *credentialDataKey {
+	c.mu.RLock()
+	dk := c.keys[id]
+	c.mu.RUnlock()
+	if dk != nil {
+		return dk
+	}
+	c.refreshMu.Lock()
+	stale := time.Since(c.lastRefresh) >= credentialKeyMissRefreshInterval
+	c.refreshMu.Unlock()
+	if !stale {
+		return nil
+	}
+	if err := c.RefreshKeys(ctx); err != nil {
+		logger.LegacyPrintf("repository.credential_cipher", "[CredentialEncryption] Refresh data keys failed: %v", err)
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys[id]
+}
+
+// ListKeys 列出全部数据密钥（不含密钥本身）
+func (c *CredentialCipher) ListKeys(ctx context.Context) ([]service.CredentialEncryptionKey, error) {
+	if c == nil {
+		return []service.CredentialEncryptionKey{}, nil
+	}
+	if err := c.RefreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]service.CredentialEncryptionKey, 0, len(c.keys))
+	for _, dk := range c.keys {
+		out = append(out, dk.meta)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// EncryptCredentials 返回敏感子键已加密的副本，不修改入参。未启用加密时原样返回。
+func (c *CredentialCipher) EncryptCredentials(credentials map[string]any) (map[string]any, error) {
+	if !c.Enabled() || len(credentials) == 0 {
+		return credentials, nil
+	}
+	dk := c.activeKey()
+	if dk == nil {
+		return nil, errCredentialDataKeyUnavailable
+	}
+	out := make(map[string]any, len(credentials))
+	for key, value := range credentials {
+		if service.IsSensitiveCredentialKey(key) {
+			encrypted, err := dk.encryptValue(key, value)
+			if err != nil {
+				return nil, fmt.Errorf("encrypt credential %s: %w", key, err)
+			}
+			value = encrypted
+		}
+		out[key] = value
+	}
+	return out, nil
+}
+
+// DecryptCredentials 返回敏感子键已解密的副本。无法解密的值保留密文并记录告警，
+// 账号随后会以上游鉴权失败的形式暴露问题，而不是在读取时整体报错。
+func (c *CredentialCipher) DecryptCredentials(ctx context.Context, accountID int64, credentials map[string]any) map[string]any {
+	out, err := c.decryptCredentials(ctx, credentials)
+	if err != nil {
+		logger.LegacyPrintf("repository.credential_cipher", "[CredentialEncryption] Warning: decrypt credentials for account %d failed: %v", accountID, err)
+	}
+	return out
+}
+
+// decryptCredentials 解密敏感子键；出错时仍返回尽量解密后的副本。
+func (c *CredentialCipher) decryptCredentials(ctx context.Context, credentials map[string]any) (map[string]any, error) {
+	if !hasCredentialCiphertext(credentials) {
+		return credentials, nil
+	}
+	if c == nil {
+		return credentials, errCredentialDataKeyUnavailable
+	}
+	var firstErr error
+	out := make(map[string]any, len(credentials))
+	for key, value := range credentials {
+		if s, ok := value.(string); ok && strings.HasPrefix(s, credentialCiphertextPrefix) {
+			decrypted, err := c.decryptValue(ctx, key, s)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", key, err)
+				}
+			} else {
+				value = decrypted
+			}
+		}
+		out[key] = value
+	}
+	return out, firstErr
+}
+
+func hasCredentialCiphertext(credentials map[string]any) bool {
+	for key, value := range credentials {
+		if !service.IsSensitiveCredentialKey(key) {
+			continue
+		}
+		if s, ok := value.(string); ok && strings.HasPrefix(s, credentialCiphertextPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// encryptValue 加密单个敏感值。nil、空白字符串与已是密文的值原样返回：
+// 空值保持可被 SQL 判空，无法解密而保留的密文也不会被二次加密。
+func (dk *credentialDataKey) encryptValue(field string, value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return value, nil
+	case string:
+		if strings.TrimSpace(v) == "" || strings.HasPrefix(v, credentialCiphertextPrefix) {
+			return value, nil
+		}
+	}
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	nonceInput := make([]byte, 0, len(field)+1+len(plaintext))
+	nonceInput = append(nonceInput, field...)
+	nonceInput = append(nonceInput, 0)
+	nonceInput = append(nonceInput, plaintext...)
+	nonce := hmacSHA256(dk.macKey, nonceInput)[:dk.aead.NonceSize()]
+	sealed := dk.aead.Seal(append([]byte(nil), nonce...), nonce, plaintext, []byte(field))
+	return credentialCiphertextPrefix + dk.meta.ID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (c *CredentialCipher) decryptValue(ctx context.Context, field, value string) (any, error) {
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, credentialCiphertextPrefix), ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed ciphertext")
+	}
+	dk := c.lookupKey(ctx, keyID)
+	if dk == nil || dk.aead == nil {
+		return nil, fmt.Errorf("%w: %s", errCredentialDataKeyUnavailable, keyID)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode ciphertext: %w", err)
+	}
+	nonceSize := dk.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	plaintext, err := dk.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(field))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(plaintext, &decoded); err != nil {
+		return nil, fmt.Errorf("decode plaintext: %w", err)
+	}
+	return decoded, nil
+}
+
+// credentialsJSON 把凭证加密后序列化为 jsonb 参数，供原生 SQL 写入使用。
+func (c *CredentialCipher) credentialsJSON(credentials map[string]any) (string, error) {
+	encrypted, err := c.EncryptCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// credentialValueCandidates 返回单个敏感子键的字符串值在库中可能的全部存储形态：明文本身，
+// 以及用每个可用数据密钥加密的结果，供 "credentials ->> 'api_key' = ANY($1)" 这类比较使用。
+// 确定性加密只在同一数据密钥下成立，尚未改写的明文行、退役密钥加密的行都要能命中。
+func (c *CredentialCipher) credentialValueCandidates(field, value string) ([]string, error) {
+	candidates := []string{value}
+	if c == nil {
+		return candidates, nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, dk := range c.keys {
+		if dk.aead == nil {
+			continue
+		}
+		encrypted, err := dk.encryptValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := encrypted.(string); ok && s != value {
+			candidates = append(candidates, s)
+		}
+	}
+	return candidates, nil
+}
+
+// decryptStoredCredentials 解码并解密库中存储的 credentials jsonb；任一敏感子键无法解密时返回错误。
+func (c *CredentialCipher) decryptStoredCredentials(ctx context.Context, stored []byte) (map[string]any, error) {
+	var credentials map[string]any
+	if err := json.Unmarshal(stored, &credentials); err != nil {
+		return nil, fmt.Errorf("decode stored credentials: %w", err)
+	}
+	return c.decryptCredentials(ctx, normalizeJSONMap(credentials))
+}
+
+// storedCredentialsMatch 报告库中存储的 credentials 解密后是否与期望明文一致（按 JSON 语义比较）。
+// 存储形态可能是明文、退役数据密钥或其他实例缓存的旧密钥加密的密文，不能与重新加密的期望值直接比较。
+// 无法解密时视为不一致。
+func (c *CredentialCipher) storedCredentialsMatch(ctx context.Context, stored []byte, expected map[string]any) bool {
+	current, err := c.decryptStoredCredentials(ctx, stored)
+	if err != nil {
+		return false
+	}
+	return credentialJSONValuesEqual(current, normalizeJSONMap(expected))
+}
+
+// credentialJSONValuesEqual 按 JSON 语义比较两个值（数值类型、键序不影响结果）。
+func credentialJSONValuesEqual(a, b any) bool {
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	if errLeft != nil || errRight != nil {
+		return false
+	}
+	return credentialsJSONEqual(left, right)
+}
+
+// credentialsComparableJSON 把待写入的凭证序列化为与库中存储形态对齐的 jsonb，供原生 SQL 判断
+// "凭证是否变化"：解密后与存储值一致的子键沿用库里的原始形态（明文或任意数据密钥的密文），
+// 其余子键按活动数据密钥加密。stored 为空或未配置主密钥时等同于 credentialsJSON。
+func (c *CredentialCipher) credentialsComparableJSON(ctx context.Context, stored []byte, credentials map[string]any) (string, error) {
+	if c == nil || len(stored) == 0 {
+		return c.credentialsJSON(credentials)
+	}
+	encrypted, err := c.EncryptCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	var current map[string]any
+	if err := json.Unmarshal(stored, &current); err != nil {
+		return "", fmt.Errorf("decode stored credentials: %w", err)
+	}
+	current = normalizeJSONMap(current)
+	out := make(map[string]any, len(encrypted))
+	for key, value := range encrypted {
+		out[key] = value
+		raw, ok := current[key]
+		if !ok {
+			continue
+		}
+		decrypted, err := c.decryptCredentials(ctx, map[string]any{key: raw})
+		if err != nil {
+			continue
+		}
+		if credentialJSONValuesEqual(decrypted[key], credentials[key]) {
+			out[key] = raw
+		}
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
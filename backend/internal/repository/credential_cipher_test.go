@@ -0,0 +1,301 @@
+//go:build unit
+
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCredentialCipher 构造不依赖数据库的加密器，keyIDs[0] 为活动数据密钥；密钥内容由 ID 决定。
+func newTestCredentialCipher(t *testing.T, enabled bool, keyIDs ...string) *CredentialCipher {
+	t.Helper()
+	c := &CredentialCipher{enabled: enabled, keys: map[string]*credentialDataKey{}, lastRefresh: time.Now()}
+	for i, id := range keyIDs {
+		dk := &credentialDataKey{meta: service.CredentialEncryptionKey{ID: id, Active: i == 0, Available: true}}
+		material := sha256.Sum256([]byte(id))
+		require.NoError(t, dk.init(material[:]))
+		c.keys[id] = dk
+		if i == 0 {
+			c.activeID = id
+		}
+	}
+	return c
+}
+
+func TestCredentialCipher_EncryptsOnlySensitiveKeysAndRoundTrips(t *testing.T) {
+	c := newTestCredentialCipher(t, true, "k1")
+	input := map[string]any{
+		"api_key":         "sk-live-123",
+		"refresh_token":   "rt-456",
+		"access_token":    "",
+		"base_url":        "https://api.example.com",
+		"model_mapping":   map[string]any{"a": "b"},
+		"service_account": map[string]any{"client_email": "svc@example.com"},
+	}
+
+	encrypted, err := c.EncryptCredentials(input)
+	require.NoError(t, err)
+	require.Equal(t, "sk-live-123", input["api_key"], "input map must not be modified")
+	for _, key := range []string{"api_key", "refresh_token", "service_account"} {
+		value, ok := encrypted[key].(string)
+		require.True(t, ok, key)
+		require.True(t, strings.HasPrefix(value, "enc:v1:k1:"), key)
+	}
+	require.Equal(t, "", encrypted["access_token"], "blank values stay blank so SQL emptiness checks keep working")
+	require.Equal(t, input["base_url"], encrypted["base_url"])
+	require.Equal(t, input["model_mapping"], encrypted["model_mapping"])
+
+	decrypted, err := c.decryptCredentials(context.Background(), encrypted)
+	require.NoError(t, err)
+	require.Equal(t, input, decrypted)
+}
+
+func TestCredentialCipher_DeterministicPerField(t *testing.T) {
+	c := newTestCredentialCipher(t, true, "k1")
+
+	first, err := c.credentialsJSON(map[string]any{"api_key": "same", "session_key": "same"})
+	require.NoError(t, err)
+	second, err := c.credentialsJSON(map[string]any{"api_key": "same", "session_key": "same"})
+	require.NoError(t, err)
+	require.Equal(t, first, second, "api_key grouping relies on stable ciphertext per data key")
+
+	var doc map[string]string
+	require.NoError(t, json.Unmarshal([]byte(first), &doc))
+	require.NotEqual(t, doc["api_key"], doc["session_key"])
+
+	candidates, err := c.credentialValueCandidates("api_key", "same")
+	require.NoError(t, err)
+	require.Equal(t, []string{"same", doc["api_key"]}, candidates)
+
+	// 密文绑定字段名，挪到其他子键下无法解密
+	_, err = c.decryptCredentials(context.Background(), map[string]any{"cookie": doc["api_key"]})
+	require.Error(t, err)
+}
+
+func TestCredentialCipher_ReadsRetiredKeysAndDoesNotDoubleEncrypt(t *testing.T) {
+	old := newTestCredentialCipher(t, true, "old")
+	stored, err := old.EncryptCredentials(map[string]any{"api_key": "sk-1"})
+	require.NoError(t, err)
+
+	rotated := newTestCredentialCipher(t, true, "new", "old")
+	plain, err := rotated.decryptCredentials(context.Background(), stored)
+	require.NoError(t, err)
+	require.Equal(t, "sk-1", plain["api_key"])
+
+	reencrypted, err := rotated.EncryptCredentials(plain)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(reencrypted["api_key"].(string), "enc:v1:new:"))
+
+	// 缺少数据密钥：保留密文，再次写入时不会二次加密
+	missing := newTestCredentialCipher(t, true, "new")
+	kept, err := missing.decryptCredentials(context.Background(), stored)
+	require.ErrorIs(t, err, errCredentialDataKeyUnavailable)
+	require.Equal(t, stored["api_key"], kept["api_key"])
+	again, err := missing.EncryptCredentials(kept)
+	require.NoError(t, err)
+	require.Equal(t, stored["api_key"], again["api_key"])
+}
+
+func TestCredentialCipher_DisabledAndNil(t *testing.T) {
+	enabled := newTestCredentialCipher(t, true, "k1")
+	stored, err := enabled.EncryptCredentials(map[string]any{"api_key": "sk-1"})
+	require.NoError(t, err)
+
+	disabled := newTestCredentialCipher(t, false, "k1")
+	out, err := disabled.EncryptCredentials(map[string]any{"api_key": "sk-1"})
+	require.NoError(t, err)
+	require.Equal(t, "sk-1", out["api_key"])
+	plain, err := disabled.decryptCredentials(context.Background(), stored)
+	require.NoError(t, err)
+	require.Equal(t, "sk-1", plain["api_key"])
+
+	var none *CredentialCipher
+	out, err = none.EncryptCredentials(map[string]any{"api_key": "sk-1"})
+	require.NoError(t, err)
+	require.Equal(t, "sk-1", out["api_key"])
+	require.Equal(t, map[string]any{"api_key": "sk-1"}, none.DecryptCredentials(context.Background(), 1, map[string]any{"api_key": "sk-1"}))
+	_, err = none.decryptCredentials(context.Background(), stored)
+	require.Error(t, err)
+}
+
+func TestCredentialCipher_WrapDataKeyBindsID(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{9}, 32)
+	c := &CredentialCipher{masterKeys: map[string][]byte{"m1": masterKey}}
+	raw := bytes.Repeat([]byte{7}, 32)
+
+	wrapped, err := wrapCredentialDataKey(masterKey, "dek1", raw)
+	require.NoError(t, err)
+	unwrapped, err := c.unwrapDataKey("dek1", "m1", wrapped)
+	require.NoError(t, err)
+	require.Equal(t, raw, unwrapped)
+
+	_, err = c.unwrapDataKey("dek2", "m1", wrapped)
+	require.Error(t, err)
+	_, err = c.unwrapDataKey("dek1", "m2", wrapped)
+	require.Error(t, err)
+}
+
+func TestCredentialEncryptionRepository_ReencryptBatchRewritesPlaintextRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	c := newTestCredentialCipher(t, true, "k1")
+	current, err := c.credentialsJSON(map[string]any{"api_key": "sk-2"})
+	require.NoError(t, err)
+	target, err := c.credentialsJSON(map[string]any{"api_key": "sk-1", "base_url": "https://x"})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, credentials")).
+		WithArgs(int64(0), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "credentials"}).
+			AddRow(int64(1), []byte(`{"api_key": "sk-1", "base_url": "https://x"}`)).
+			AddRow(int64(2), []byte(current)).
+			AddRow(int64(3), []byte(`{"model_mapping": {}}`)))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE accounts SET credentials = $2::jsonb")).
+		WithArgs(int64(1), target, `{"api_key": "sk-1", "base_url": "https://x"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewCredentialEncryptionRepository(db, c)
+	batch, err := repo.ReencryptBatch(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, &service.CredentialReencryptBatch{LastID: 3, Scanned: 3, Rewritten: 1}, batch)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountRepository_GrokCredentialsCASAfterDataKeyRotation(t *testing.T) {
+	expected := map[string]any{"access_token": "at-1", "refresh_token": "rt-1"}
+	refreshed := map[string]any{"access_token": "at-2", "refresh_token": "rt-2"}
+
+	old := newTestCredentialCipher(t, true, "old")
+	storedUnderOldKey, err := old.credentialsJSON(expected)
+	require.NoError(t, err)
+	changedUnderOldKey, err := old.credentialsJSON(map[string]any{"access_token": "at-1", "refresh_token": "rt-other"})
+	require.NoError(t, err)
+
+	rotated := newTestCredentialCipher(t, true, "new", "old")
+	storedUnderNewKey, err := rotated.credentialsJSON(expected)
+	require.NoError(t, err)
+	refreshedJSON, err := rotated.credentialsJSON(refreshed)
+	require.NoError(t, err)
+	require.NotEqual(t, storedUnderOldKey, storedUnderNewKey)
+
+	const selectStored = "SELECT credentials FROM accounts WHERE id = $1"
+	const updateCAS = "SET credentials = $1::jsonb"
+
+	run := func(t *testing.T, setup func(mock sqlmock.Sqlmock)) bool {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+		setup(mock)
+		repo := newAccountRepositoryWithSQL(nil, db, nil)
+		repo.credCipher = rotated
+		applied, err := repo.UpdateGrokOAuthCredentialsIfUnchanged(context.Background(), 42, expected, nil, refreshed)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+		return applied
+	}
+
+	t.Run("row under retired key", func(t *testing.T) {
+		require.True(t, run(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(selectStored)).WithArgs(int64(42)).
+				WillReturnRows(sqlmock.NewRows([]string{"credentials"}).AddRow([]byte(storedUnderOldKey)))
+			mock.ExpectExec(regexp.QuoteMeta(updateCAS)).
+				WithArgs(refreshedJSON, int64(42), service.PlatformGrok, service.AccountTypeOAuth, storedUnderOldKey, nil, service.SchedulerOutboxEventAccountChanged).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}))
+	})
+
+	t.Run("plaintext row written before encryption was enabled", func(t *testing.T) {
+		plaintext := `{"access_token": "at-1", "refresh_token": "rt-1"}`
+		require.True(t, run(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(selectStored)).WithArgs(int64(42)).
+				WillReturnRows(sqlmock.NewRows([]string{"credentials"}).AddRow([]byte(plaintext)))
+			mock.ExpectExec(regexp.QuoteMeta(updateCAS)).
+				WithArgs(refreshedJSON, int64(42), service.PlatformGrok, service.AccountTypeOAuth, plaintext, nil, service.SchedulerOutboxEventAccountChanged).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}))
+	})
+
+	t.Run("re-encrypted between read and update is retried", func(t *testing.T) {
+		require.True(t, run(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(selectStored)).WithArgs(int64(42)).
+				WillReturnRows(sqlmock.NewRows([]string{"credentials"}).AddRow([]byte(storedUnderOldKey)))
+			mock.ExpectExec(regexp.QuoteMeta(updateCAS)).
+				WithArgs(refreshedJSON, int64(42), service.PlatformGrok, service.AccountTypeOAuth, storedUnderOldKey, nil, service.SchedulerOutboxEventAccountChanged).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta(selectStored)).WithArgs(int64(42)).
+				WillReturnRows(sqlmock.NewRows([]string{"credentials"}).AddRow([]byte(storedUnderNewKey)))
+			mock.ExpectExec(regexp.QuoteMeta(updateCAS)).
+				WithArgs(refreshedJSON, int64(42), service.PlatformGrok, service.AccountTypeOAuth, storedUnderNewKey, nil, service.SchedulerOutboxEventAccountChanged).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}))
+	})
+
+	t.Run("changed credentials are rejected without writing", func(t *testing.T) {
+		require.False(t, run(t, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(regexp.QuoteMeta(selectStored)).WithArgs(int64(42)).
+				WillReturnRows(sqlmock.NewRows([]string{"credentials"}).AddRow([]byte(changedUnderOldKey)))
+		}))
+	})
+}
+
+func TestCredentialCipher_ValueCandidatesCoverEveryStoredForm(t *testing.T) {
+	old := newTestCredentialCipher(t, true, "old")
+	rotated := newTestCredentialCipher(t, true, "new", "old")
+
+	underOld, err := old.EncryptCredentials(map[string]any{"api_key": "sk-1"})
+	require.NoError(t, err)
+	underNew, err := rotated.EncryptCredentials(map[string]any{"api_key": "sk-1"})
+	require.NoError(t, err)
+
+	candidates, err := rotated.credentialValueCandidates("api_key", "sk-1")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"sk-1", underOld["api_key"].(string), underNew["api_key"].(string)}, candidates)
+
+	var none *CredentialCipher
+	candidates, err = none.credentialValueCandidates("api_key", "sk-1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"sk-1"}, candidates)
+}
+
+// 轮换后未变化的子键必须沿用库里旧密钥的密文，UpdateCredentials 才不会把它当作身份变化。
+func TestCredentialCipher_ComparableJSONKeepsStoredFormForUnchangedKeys(t *testing.T) {
+	old := newTestCredentialCipher(t, true, "old")
+	rotated := newTestCredentialCipher(t, true, "new", "old")
+
+	stored, err := old.credentialsJSON(map[string]any{"api_key": "sk-1", "base_url": "https://ollama.com"})
+	require.NoError(t, err)
+	var storedDoc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stored), &storedDoc))
+
+	comparable, err := rotated.credentialsComparableJSON(context.Background(), []byte(stored), map[string]any{
+		"api_key": "sk-1", "base_url": "https://ollama.com", "org_id": "org-2",
+	})
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(comparable), &doc))
+	require.Equal(t, storedDoc["api_key"], doc["api_key"])
+	require.Equal(t, "https://ollama.com", doc["base_url"])
+	require.Equal(t, "org-2", doc["org_id"])
+
+	comparable, err = rotated.credentialsComparableJSON(context.Background(), []byte(stored), map[string]any{"api_key": "sk-2"})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(comparable), &doc))
+	require.NotEqual(t, storedDoc["api_key"], doc["api_key"])
+	plain, err := rotated.decryptCredentials(context.Background(), doc)
+	require.NoError(t, err)
+	require.Equal(t, "sk-2", plain["api_key"])
+}
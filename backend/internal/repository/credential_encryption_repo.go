@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// credentialEncryptionRepository 实现 service.CredentialEncryptionRepository。
+// cipher 为 nil（未配置主密钥）时只能查看状态，重加密会把带密文的账号计为失败。
+type credentialEncryptionRepository struct {
+	db     *sql.DB
+	cipher *CredentialCipher
+}
+
+// NewCredentialEncryptionRepository 创建凭证加密密钥管理与重加密仓储
+func NewCredentialEncryptionRepository(db *sql.DB, cipher *CredentialCipher) service.CredentialEncryptionRepository {
+	return &credentialEncryptionRepository{db: db, cipher: cipher}
+}
+
+func (r *credentialEncryptionRepository) Enabled() bool {
+	return r.cipher.Enabled()
+}
+
+func (r *credentialEncryptionRepository) RefreshKeys(ctx context.Context) error {
+	return r.cipher.RefreshKeys(ctx)
+}
+
+func (r *credentialEncryptionRepository) RotateDataKey(ctx context.Context) (string, error) {
+	return r.cipher.RotateDataKey(ctx)
+}
+
+func (r *credentialEncryptionRepository) ListKeys(ctx context.Context) ([]service.CredentialEncryptionKey, error) {
+	if r.cipher != nil {
+		return r.cipher.ListKeys(ctx)
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, master_key_id, active, created_at, rotated_at
+		FROM credential_encryption_keys
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	out := []service.CredentialEncryptionKey{}
+	for rows.Next() {
+		var (
+			key       service.CredentialEncryptionKey
+			rotatedAt sql.NullTime
+		)
+		if err := rows.Scan(&key.ID, &key.MasterKeyID, &key.Active, &key.CreatedAt, &rotatedAt); err != nil {
+			return nil, err
+		}
+		if rotatedAt.Valid {
+			t := rotatedAt.Time
+			key.RotatedAt = &t
+		}
+		out = append(out, key)
+	}
+	return out, rows.Err()
+}
+
+// CountPending 启用时统计仍有敏感值未由活动数据密钥加密的账号，关闭时统计仍有密文的账号。
+func (r *credentialEncryptionRepository) CountPending(ctx context.Context) (int64, error) {
+	condition := `jsonb_typeof(e.value) = 'string' AND e.value #>> '{}' LIKE 'enc:v1:%'`
+	args := []any{pq.Array(service.SensitiveCredentialKeys)}
+	if active := r.cipher.activeKeyID(); r.cipher.Enabled() && active != "" {
+		condition = `jsonb_typeof(e.value) <> 'null'
+			AND NOT (jsonb_typeof(e.value) = 'string'
+				AND (btrim(e.value #>> '{}') = '' OR e.value #>> '{}' LIKE $2))`
+		args = append(args, credentialCiphertextPrefix+active+":%")
+	}
+	var count int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM accounts a
+		WHERE jsonb_typeof(a.credentials) = 'object'
+			AND EXISTS (
+				SELECT 1 FROM jsonb_each(a.credentials) e
+				WHERE e.key = ANY($1) AND `+condition+`
+			)
+	`, args...).Scan(&count)
+	return count, err
+}
+
+// ReencryptBatch 逐行解密后按当前目标形态重新加密，仅在结果不同时以 CAS 写回。
+//
+// CAS 条件是读取时的原始 credentials：期间被业务写入覆盖的行本批跳过，业务写入本身已采用
+// 目标形态，或留给下一轮处理。写回不修改 updated_at 也不发调度事件，凭证的明文内容并未改变。
+func (r *credentialEncryptionRepository) ReencryptBatch(ctx context.Context, afterID int64, limit int) (*service.CredentialReencryptBatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, credentials
+		FROM accounts
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	type storedCredentials struct {
+		id  int64
+		raw []byte
+	}
+	var batch []storedCredentials
+	for rows.Next() {
+		var item storedCredentials
+		if err := rows.Scan(&item.id, &item.raw); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		batch = append(batch, item)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &service.CredentialReencryptBatch{Scanned: len(batch)}
+	for _, item := range batch {
+		result.LastID = item.id
+		var stored map[string]any
+		if err := json.Unmarshal(item.raw, &stored); err != nil || len(stored) == 0 {
+			continue
+		}
+		plain, err := r.cipher.decryptCredentials(ctx, stored)
+		if err != nil {
+			result.Failed++
+			logger.LegacyPrintf("repository.credential_encryption", "[CredentialEncryption] Skip account %d: %v", item.id, err)
+			continue
+		}
+		target, err := r.cipher.credentialsJSON(plain)
+		if err != nil {
+			return result, err
+		}
+		if credentialsJSONEqual(item.raw, []byte(target)) {
+			continue
+		}
+		res, err := r.db.ExecContext(ctx, `
+			UPDATE accounts SET credentials = $2::jsonb
+			WHERE id = $1 AND credentials = $3::jsonb
+		`, item.id, target, string(item.raw))
+		if err != nil {
+			return result, err
+		}
+		if affected, _ := res.RowsAffected(); affected > 0 {
+			result.Rewritten++
+		}
+	}
+	return result, nil
+}
+
+// credentialsJSONEqual 按语义比较两个 JSON 文档（jsonb 输出的空白与键序与 encoding/json 不同）。
+func credentialsJSONEqual(a, b []byte) bool {
+	var left, right any
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return bytes.Equal(a, b)
+	}
+	leftJSON, _ := json.Marshal(left)
+	rightJSON, _ := json.Marshal(right)
+	return bytes.Equal(leftJSON, rightJSON)
+}
@@ -82,6 +82,13 @@ func createGroupRecord(ctx context.Context, client *dbent.Client, groupIn *servi
 		SetNillableImagePrice4k(groupIn.ImagePrice4K).
 		SetBatchImageDiscountMultiplier(groupIn.BatchImageDiscountMultiplier).
 		SetBatchImageHoldMultiplier(groupIn.BatchImageHoldMultiplier).
+		SetAllowBatchAPI(groupIn.AllowBatchAPI).
+		SetBatchAPIDiscountMultiplier(groupIn.BatchAPIDiscountMultiplier).
+		SetBatchAPIHoldMultiplier(groupIn.BatchAPIHoldMultiplier).
+		SetResponseCacheEnabled(groupIn.ResponseCacheEnabled).
+		SetResponseCacheTTLSeconds(groupIn.ResponseCacheTTLSeconds).
+		SetResponseCachePriceMultiplier(groupIn.ResponseCachePriceMultiplier).
+		SetSchedulingStrategy(groupIn.SchedulingStrategy).
 		SetVideoRateIndependent(groupIn.VideoRateIndependent).
 		SetVideoRateMultiplier(groupIn.VideoRateMultiplier).
 		SetNillableVideoPrice480p(groupIn.VideoPrice480P).
@@ -89,6 +96,7 @@ func createGroupRecord(ctx context.Context, client *dbent.Client, groupIn *servi
 		SetNillableVideoPrice1080p(groupIn.VideoPrice1080P).
 		SetVideoModelPrices(service.NormalizeVideoModelPrices(groupIn.VideoModelPrices)).
 		SetNillableWebSearchPricePerCall(groupIn.WebSearchPricePerCall).
+		SetNillableWebFetchPricePerCall(groupIn.WebFetchPricePerCall).
 		SetNillableSearchPricePer1k(groupIn.SearchPricePer1k).
 		SetNillableAudioRealtimePricePerMin(groupIn.AudioRealtimePricePerMin).
 		SetNillableAudioTtsPricePerMillionChars(groupIn.AudioTTSPricePerMillionChars).
@@ -265,6 +273,13 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetNillableImagePrice4k(groupIn.ImagePrice4K).
 		SetBatchImageDiscountMultiplier(groupIn.BatchImageDiscountMultiplier).
 		SetBatchImageHoldMultiplier(groupIn.BatchImageHoldMultiplier).
+		SetAllowBatchAPI(groupIn.AllowBatchAPI).
+		SetBatchAPIDiscountMultiplier(groupIn.BatchAPIDiscountMultiplier).
+		SetBatchAPIHoldMultiplier(groupIn.BatchAPIHoldMultiplier).
+		SetResponseCacheEnabled(groupIn.ResponseCacheEnabled).
+		SetResponseCacheTTLSeconds(groupIn.ResponseCacheTTLSeconds).
+		SetResponseCachePriceMultiplier(groupIn.ResponseCachePriceMultiplier).
+		SetSchedulingStrategy(groupIn.SchedulingStrategy).
 		SetVideoRateIndependent(groupIn.VideoRateIndependent).
 		SetVideoRateMultiplier(groupIn.VideoRateMultiplier).
 		SetNillableVideoPrice480p(groupIn.VideoPrice480P).
@@ -346,6 +361,11 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 	} else {
 		builder = builder.ClearWebSearchPricePerCall()
 	}
+	if groupIn.WebFetchPricePerCall != nil {
+		builder = builder.SetWebFetchPricePerCall(*groupIn.WebFetchPricePerCall)
+	} else {
+		builder = builder.ClearWebFetchPricePerCall()
+	}
 	if groupIn.SearchPricePer1k != nil {
 		builder = builder.SetSearchPricePer1k(*groupIn.SearchPricePer1k)
 	} else {
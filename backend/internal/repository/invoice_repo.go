@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) service.InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+const billingProfileColumns = `owner_type, owner_id, company_name, tax_id, address_line1, address_line2,
+	city, postal_code, country, email, notes, updated_at`
+
+func (r *invoiceRepository) GetBillingProfile(ctx context.Context, ownerType string, ownerID int64) (*service.BillingProfile, error) {
+	var p service.BillingProfile
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+billingProfileColumns+`
+		FROM billing_profiles
+		WHERE owner_type = $1 AND owner_id = $2
+	`, ownerType, ownerID).Scan(&p.OwnerType, &p.OwnerID, &p.CompanyName, &p.TaxID, &p.AddressLine1, &p.AddressLine2,
+		&p.City, &p.PostalCode, &p.Country, &p.Email, &p.Notes, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *invoiceRepository) UpsertBillingProfile(ctx context.Context, p *service.BillingProfile) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO billing_profiles (owner_type, owner_id, company_name, tax_id, address_line1, address_line2,
+			city, postal_code, country, email, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (owner_type, owner_id) DO UPDATE SET
+			company_name = EXCLUDED.company_name,
+			tax_id = EXCLUDED.tax_id,
+			address_line1 = EXCLUDED.address_line1,
+			address_line2 = EXCLUDED.address_line2,
+			city = EXCLUDED.city,
+			postal_code = EXCLUDED.postal_code,
+			country = EXCLUDED.country,
+			email = EXCLUDED.email,
+			notes = EXCLUDED.notes,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, p.OwnerType, p.OwnerID, p.CompanyName, p.TaxID, p.AddressLine1, p.AddressLine2,
+		p.City, p.PostalCode, p.Country, p.Email, p.Notes).Scan(&p.UpdatedAt)
+}
+
+const invoiceColumns = `i.id, i.number, i.kind, i.status, i.owner_type, i.owner_id, i.payment_order_id,
+	i.period_start, i.period_end, i.currency, i.subtotal, i.total, i.bill_to, i.line_items,
+	i.replaces_invoice_id, COALESCE(ri.number, ''), i.issued_by, i.void_reason, i.voided_by, i.voided_at,
+	i.issued_at, i.created_at`
+
+const invoiceFrom = `invoices i LEFT JOIN invoices ri ON ri.id = i.replaces_invoice_id`
+
+func scanInvoice(row rowScanner) (*service.Invoice, error) {
+	var (
+		inv                            service.Invoice
+		orderID, replacesID            sql.NullInt64
+		issuedBy, voidedBy             sql.NullInt64
+		periodStart, periodEnd, voidAt sql.NullTime
+		billTo, lineItems              []byte
+	)
+	if err := row.Scan(&inv.ID, &inv.Number, &inv.Kind, &inv.Status, &inv.OwnerType, &inv.OwnerID, &orderID,
+		&periodStart, &periodEnd, &inv.Currency, &inv.Subtotal, &inv.Total, &billTo, &lineItems,
+		&replacesID, &inv.ReplacesNumber, &issuedBy, &inv.VoidReason, &voidedBy, &voidAt,
+		&inv.IssuedAt, &inv.CreatedAt); err != nil {
+		return nil, err
+	}
+	inv.PaymentOrderID = int64PtrFromNull(orderID)
+	inv.ReplacesInvoiceID = int64PtrFromNull(replacesID)
+	inv.IssuedBy = int64PtrFromNull(issuedBy)
+	inv.VoidedBy = int64PtrFromNull(voidedBy)
+	inv.PeriodStart = timePtrFromNull(periodStart)
+	inv.PeriodEnd = timePtrFromNull(periodEnd)
+	inv.VoidedAt = timePtrFromNull(voidAt)
+	if err := json.Unmarshal(billTo, &inv.BillTo); err != nil {
+		return nil, fmt.Errorf("decode invoice bill_to: %w", err)
+	}
+	if err := json.Unmarshal(lineItems, &inv.LineItems); err != nil {
+		return nil, fmt.Errorf("decode invoice line_items: %w", err)
+	}
+	return &inv, nil
+}
+
+func int64PtrFromNull(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	out := v.Int64
+	return &out
+}
+
+func timePtrFromNull(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	out := v.Time
+	return &out
+}
+
+func (r *invoiceRepository) getOne(ctx context.Context, where string, args ...any) (*service.Invoice, error) {
+	inv, err := scanInvoice(r.db.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM `+invoiceFrom+` WHERE `+where, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return inv, err
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id int64) (*service.Invoice, error) {
+	inv, err := r.getOne(ctx, `i.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, service.ErrInvoiceNotFound
+	}
+	return inv, nil
+}
+
+func (r *invoiceRepository) GetLiveReceipt(ctx context.Context, orderID int64) (*service.Invoice, error) {
+	return r.getOne(ctx, `i.payment_order_id = $1 AND i.kind = $2 AND i.status = $3`,
+		orderID, service.InvoiceKindReceipt, service.InvoiceStatusIssued)
+}
+
+func (r *invoiceRepository) GetLiveStatement(ctx context.Context, ownerType string, ownerID int64, periodStart time.Time) (*service.Invoice, error) {
+	return r.getOne(ctx, `i.owner_type = $1 AND i.owner_id = $2 AND i.period_start = $3 AND i.kind = $4 AND i.status = $5`,
+		ownerType, ownerID, periodStart, service.InvoiceKindUsageStatement, service.InvoiceStatusIssued)
+}
+
+func (r *invoiceRepository) List(ctx context.Context, filter service.InvoiceFilter, params pagination.PaginationParams) ([]service.Invoice, *pagination.PaginationResult, error) {
+	where := []string{"TRUE"}
+	args := []any{}
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.OwnerType != "" {
+		add("i.owner_type = $%d", filter.OwnerType)
+	}
+	if filter.OwnerID > 0 {
+		add("i.owner_id = $%d", filter.OwnerID)
+	}
+	if filter.Kind != "" {
+		add("i.kind = $%d", filter.Kind)
+	}
+	if filter.Status != "" {
+		add("i.status = $%d", filter.Status)
+	}
+	if number := strings.TrimSpace(filter.Number); number != "" {
+		add(`i.number ILIKE $%d ESCAPE '\'`, "%"+escapeLike(number)+"%")
+	}
+	whereSQL := strings.Join(where, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM invoices i WHERE `+whereSQL, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count invoices: %w", err)
+	}
+
+	args = append(args, params.Limit(), params.Offset())
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE %s
+		ORDER BY i.issued_at DESC, i.id DESC
+		LIMIT $%d OFFSET $%d
+	`, invoiceColumns, invoiceFrom, whereSQL, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list invoices: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.Invoice, 0, params.Limit())
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, *inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, inv *service.Invoice, numberPrefix string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin create invoice: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertInvoice(ctx, tx, inv, numberPrefix); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *invoiceRepository) Replace(ctx context.Context, oldID, actorID int64, reason string, at time.Time, inv *service.Invoice, numberPrefix string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin reissue invoice: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE invoices
+		SET status = $2, void_reason = $3, voided_by = $4, voided_at = $5
+		WHERE id = $1 AND status = $6
+	`, oldID, service.InvoiceStatusVoid, reason, actorID, at, service.InvoiceStatusIssued); err != nil {
+		return fmt.Errorf("void replaced invoice: %w", err)
+	}
+	if err := insertInvoice(ctx, tx, inv, numberPrefix); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT number FROM invoices WHERE id = $1`, oldID).Scan(&inv.ReplacesNumber); err != nil {
+		return fmt.Errorf("load replaced invoice number: %w", err)
+	}
+	return tx.Commit()
+}
+
+// insertInvoice 分配编号并写入票据。编号计数行在事务提交前一直被锁住，
+// 并发签发按提交顺序串行取号，回滚的事务不会留下空号。
+func insertInvoice(ctx context.Context, tx *sql.Tx, inv *service.Invoice, numberPrefix string) error {
+	billTo, err := json.Marshal(inv.BillTo)
+	if err != nil {
+		return err
+	}
+	lineItems := inv.LineItems
+	if lineItems == nil {
+		lineItems = []service.InvoiceLineItem{}
+	}
+	lines, err := json.Marshal(lineItems)
+	if err != nil {
+		return err
+	}
+
+	year := inv.IssuedAt.Year()
+	var seq int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO invoice_number_sequences (year, last_value) VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_value = invoice_number_sequences.last_value + 1
+		RETURNING last_value
+	`, year).Scan(&seq); err != nil {
+		return fmt.Errorf("allocate invoice number: %w", err)
+	}
+	inv.Number = service.FormatInvoiceNumber(numberPrefix, year, seq)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO invoices (number, kind, status, owner_type, owner_id, payment_order_id, period_start, period_end,
+			currency, subtotal, total, bill_to, line_items, replaces_invoice_id, issued_by, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at
+	`, inv.Number, inv.Kind, inv.Status, inv.OwnerType, inv.OwnerID, inv.PaymentOrderID, inv.PeriodStart, inv.PeriodEnd,
+		inv.Currency, inv.Subtotal, inv.Total, billTo, lines, inv.ReplacesInvoiceID, inv.IssuedBy, inv.IssuedAt).
+		Scan(&inv.ID, &inv.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" &&
+		(pqErr.Constraint == "uq_invoices_live_receipt" || pqErr.Constraint == "uq_invoices_live_statement") {
+		return service.ErrInvoiceAlreadyIssued
+	}
+	if err != nil {
+		return fmt.Errorf("create invoice: %w", err)
+	}
+	return nil
+}
+
+func (r *invoiceRepository) Void(ctx context.Context, id, actorID int64, reason string, at time.Time) (*service.Invoice, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE invoices
+		SET status = $2, void_reason = $3, voided_by = $4, voided_at = $5
+		WHERE id = $1 AND status = $6
+	`, id, service.InvoiceStatusVoid, reason, actorID, at, service.InvoiceStatusIssued)
+	if err != nil {
+		return nil, fmt.Errorf("void invoice: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, service.ErrInvoiceAlreadyVoid
+	}
+	return r.GetByID(ctx, id)
+}
+
+// invoiceUsageScope 返回归属方的 usage_logs 过滤条件（$1 为归属方 ID）。
+// 个人对账单只含个人 Key，组织 Key 的用量记在组织对账单上。
+func invoiceUsageScope(ownerType string) string {
+	if ownerType == service.InvoiceOwnerOrganization {
+		return "ak.organization_id = $1"
+	}
+	return "ul.user_id = $1 AND ak.organization_id IS NULL"
+}
+
+func (r *invoiceRepository) AggregateUsage(ctx context.Context, owner service.InvoiceOwnerRef, start, end time.Time) ([]service.InvoiceUsageLine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			COALESCE(NULLIF(ul.requested_model, ''), ul.model) AS model,
+			COUNT(*),
+			COALESCE(SUM(ul.input_tokens), 0),
+			COALESCE(SUM(ul.output_tokens), 0),
+			COALESCE(SUM(ul.cache_creation_tokens + ul.cache_read_tokens), 0),
+			COALESCE(SUM(ul.actual_cost), 0)::double precision
+		FROM usage_logs ul
+		JOIN api_keys ak ON ak.id = ul.api_key_id
+		WHERE `+invoiceUsageScope(owner.OwnerType)+`
+			AND ul.created_at >= $2 AND ul.created_at < $3
+			AND ul.billing_type = $4
+		GROUP BY 1
+		ORDER BY SUM(ul.actual_cost) DESC, 1 ASC
+	`, owner.OwnerID, start, end, service.BillingTypeBalance)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.InvoiceUsageLine, 0)
+	for rows.Next() {
+		var u service.InvoiceUsageLine
+		if err := rows.Scan(&u.Model, &u.Requests, &u.InputTokens, &u.OutputTokens, &u.CacheTokens, &u.ActualCost); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func (r *invoiceRepository) ListOwnersWithUsage(ctx context.Context, start, end time.Time, limit int) ([]service.InvoiceOwnerRef, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH owners AS (
+			SELECT DISTINCT
+				CASE WHEN ak.organization_id IS NULL THEN $3 ELSE $4 END AS owner_type,
+				COALESCE(ak.organization_id, ul.user_id) AS owner_id
+			FROM usage_logs ul
+			JOIN api_keys ak ON ak.id = ul.api_key_id
+			WHERE ul.created_at >= $1 AND ul.created_at < $2
+				AND ul.billing_type = $5
+				AND ul.actual_cost > 0
+		)
+		SELECT o.owner_type, o.owner_id
+		FROM owners o
+		WHERE NOT EXISTS (
+			SELECT 1 FROM invoices i
+			WHERE i.owner_type = o.owner_type AND i.owner_id = o.owner_id
+				AND i.kind = $6 AND i.status = $7 AND i.period_start = $1
+		)
+		ORDER BY 1, 2
+		LIMIT $8
+	`, start, end, service.InvoiceOwnerUser, service.InvoiceOwnerOrganization, service.BillingTypeBalance,
+		service.InvoiceKindUsageStatement, service.InvoiceStatusIssued, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.InvoiceOwnerRef, 0)
+	for rows.Next() {
+		var o service.InvoiceOwnerRef
+		if err := rows.Scan(&o.OwnerType, &o.OwnerID); err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (r *invoiceRepository) ListOrdersWithoutReceipt(ctx context.Context, start, end time.Time, limit int) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT po.id
+		FROM payment_orders po
+		WHERE po.paid_at >= $1 AND po.paid_at < $2
+			AND NOT EXISTS (
+				SELECT 1 FROM invoices i
+				WHERE i.payment_order_id = po.id AND i.kind = $3 AND i.status = $4
+			)
+		ORDER BY po.id
+		LIMIT $5
+	`, start, end, service.InvoiceKindReceipt, service.InvoiceStatusIssued, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
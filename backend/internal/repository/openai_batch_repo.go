@@ -0,0 +1,569 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type openAIBatchRepository struct {
+	db *sql.DB
+}
+
+func NewOpenAIBatchRepository(db *sql.DB) service.OpenAIBatchRepository {
+	return &openAIBatchRepository{db: db}
+}
+
+const openAIFileColumns = `id, user_id, api_key_id, purpose, filename, bytes, created_at, expires_at`
+
+func scanOpenAIFile(row rowScanner) (*service.OpenAIFile, error) {
+	var (
+		f         service.OpenAIFile
+		expiresAt sql.NullTime
+	)
+	if err := row.Scan(&f.ID, &f.UserID, &f.APIKeyID, &f.Purpose, &f.Filename, &f.Bytes, &f.CreatedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		f.ExpiresAt = &expiresAt.Time
+	}
+	return &f, nil
+}
+
+func openAIBatchColumnsFor(alias string) string {
+	return fmt.Sprintf(`%[1]s.id, %[1]s.user_id, %[1]s.api_key_id, %[1]s.group_id, %[1]s.endpoint, %[1]s.input_file_id,
+		%[1]s.output_file_id, %[1]s.error_file_id, %[1]s.status, %[1]s.completion_window, %[1]s.metadata,
+		%[1]s.client_ip, %[1]s.request_total, %[1]s.request_completed, %[1]s.request_failed,
+		%[1]s.hold_amount, %[1]s.actual_cost, %[1]s.settle_amount, %[1]s.discount_multiplier, %[1]s.hold_multiplier,
+		COALESCE(%[1]s.last_error, ''), %[1]s.created_at, %[1]s.in_progress_at, %[1]s.expires_at, %[1]s.finalizing_at,
+		%[1]s.completed_at, %[1]s.failed_at, %[1]s.expired_at, %[1]s.cancelling_at, %[1]s.cancelled_at`, alias)
+}
+
+var openAIBatchColumns = openAIBatchColumnsFor("b")
+
+func scanOpenAIBatch(row rowScanner) (*service.OpenAIBatch, error) {
+	var (
+		b                                service.OpenAIBatch
+		outputFileID, errorFileID        sql.NullString
+		metadata                         []byte
+		settleAmount                     sql.NullFloat64
+		inProgressAt, finalizingAt       sql.NullTime
+		completedAt, failedAt, expiredAt sql.NullTime
+		cancellingAt, cancelledAt        sql.NullTime
+	)
+	if err := row.Scan(
+		&b.ID, &b.UserID, &b.APIKeyID, &b.GroupID, &b.Endpoint, &b.InputFileID,
+		&outputFileID, &errorFileID, &b.Status, &b.CompletionWindow, &metadata,
+		&b.ClientIP, &b.RequestTotal, &b.RequestCompleted, &b.RequestFailed,
+		&b.HoldAmount, &b.ActualCost, &settleAmount, &b.DiscountMultiplier, &b.HoldMultiplier,
+		&b.LastError, &b.CreatedAt, &inProgressAt, &b.ExpiresAt, &finalizingAt,
+		&completedAt, &failedAt, &expiredAt, &cancellingAt, &cancelledAt,
+	); err != nil {
+		return nil, err
+	}
+	if outputFileID.Valid {
+		b.OutputFileID = &outputFileID.String
+	}
+	if errorFileID.Valid {
+		b.ErrorFileID = &errorFileID.String
+	}
+	if len(metadata) > 0 {
+		_ = json.Unmarshal(metadata, &b.Metadata)
+	}
+	if settleAmount.Valid {
+		b.SettleAmount = &settleAmount.Float64
+	}
+	for _, pair := range []struct {
+		src sql.NullTime
+		dst **time.Time
+	}{
+		{inProgressAt, &b.InProgressAt}, {finalizingAt, &b.FinalizingAt}, {completedAt, &b.CompletedAt},
+		{failedAt, &b.FailedAt}, {expiredAt, &b.ExpiredAt}, {cancellingAt, &b.CancellingAt}, {cancelledAt, &b.CancelledAt},
+	} {
+		if pair.src.Valid {
+			t := pair.src.Time
+			*pair.dst = &t
+		}
+	}
+	return &b, nil
+}
+
+const openAIBatchItemColumns = `id, batch_id, line_index, custom_id, method, url, body, status, attempts,
+	COALESCE(response_status, 0), response_body, COALESCE(request_id, ''), COALESCE(error_code, ''),
+	COALESCE(error_message, ''), cost, billed_at`
+
+func scanOpenAIBatchItem(row rowScanner) (*service.OpenAIBatchItem, error) {
+	var (
+		item     service.OpenAIBatchItem
+		billedAt sql.NullTime
+	)
+	if err := row.Scan(&item.ID, &item.BatchID, &item.LineIndex, &item.CustomID, &item.Method, &item.URL, &item.Body,
+		&item.Status, &item.Attempts, &item.ResponseStatus, &item.ResponseBody, &item.RequestID, &item.ErrorCode,
+		&item.ErrorMessage, &item.Cost, &billedAt); err != nil {
+		return nil, err
+	}
+	if billedAt.Valid {
+		item.BilledAt = &billedAt.Time
+	}
+	return &item, nil
+}
+
+func (r *openAIBatchRepository) CreateFile(ctx context.Context, file *service.OpenAIFile) error {
+	return insertOpenAIFile(ctx, r.db, file)
+}
+
+type openAIBatchExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertOpenAIFile(ctx context.Context, db openAIBatchExecer, file *service.OpenAIFile) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO openai_files (id, user_id, api_key_id, purpose, filename, bytes, content, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, file.ID, file.UserID, file.APIKeyID, file.Purpose, file.Filename, file.Bytes, file.Content, file.CreatedAt, file.ExpiresAt)
+	return err
+}
+
+func (r *openAIBatchRepository) GetFile(ctx context.Context, userID int64, fileID string) (*service.OpenAIFile, error) {
+	file, err := scanOpenAIFile(r.db.QueryRowContext(ctx, `
+		SELECT `+openAIFileColumns+`
+		FROM openai_files
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, fileID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOpenAIFileNotFound
+	}
+	return file, err
+}
+
+func (r *openAIBatchRepository) GetFileContent(ctx context.Context, userID int64, fileID string) ([]byte, error) {
+	var content []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT content FROM openai_files
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, fileID, userID).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOpenAIFileNotFound
+	}
+	return content, err
+}
+
+func (r *openAIBatchRepository) ListFiles(ctx context.Context, userID int64, purpose, after string, limit int) ([]service.OpenAIFile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+openAIFileColumns+`
+		FROM openai_files
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND ($2 = '' OR purpose = $2)
+		  AND ($3 = '' OR (created_at, id) < (SELECT created_at, id FROM openai_files WHERE id = $3 AND user_id = $1))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, userID, purpose, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	files := make([]service.OpenAIFile, 0, limit)
+	for rows.Next() {
+		f, err := scanOpenAIFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *f)
+	}
+	return files, rows.Err()
+}
+
+func (r *openAIBatchRepository) DeleteFile(ctx context.Context, userID int64, fileID string) error {
+	var inUse bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM openai_batches
+			WHERE input_file_id = $1 AND user_id = $2
+			  AND status IN ('validating', 'in_progress', 'finalizing', 'cancelling')
+		)
+	`, fileID, userID).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return service.ErrOpenAIFileInUse
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE openai_files SET deleted_at = NOW(), content = ''::bytea
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, fileID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return service.ErrOpenAIFileNotFound
+	}
+	return nil
+}
+
+func (r *openAIBatchRepository) DeleteExpiredFiles(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE openai_files SET deleted_at = NOW(), content = ''::bytea
+		WHERE id IN (
+			SELECT f.id FROM openai_files AS f
+			WHERE f.deleted_at IS NULL AND f.expires_at IS NOT NULL AND f.expires_at < $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM openai_batches AS b
+				WHERE b.input_file_id = f.id AND b.status IN ('validating', 'in_progress', 'finalizing', 'cancelling')
+			  )
+			LIMIT $2
+		)
+	`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *openAIBatchRepository) CreateBatch(ctx context.Context, batch *service.OpenAIBatch, items []service.OpenAIBatchItem) (err error) {
+	metadata, err := json.Marshal(batch.Metadata)
+	if err != nil || batch.Metadata == nil {
+		metadata = []byte("{}")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO openai_batches (
+			id, user_id, api_key_id, group_id, endpoint, input_file_id, status, completion_window, metadata,
+			client_ip, request_total, hold_amount, discount_multiplier, hold_multiplier, created_at, in_progress_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`, batch.ID, batch.UserID, batch.APIKeyID, batch.GroupID, batch.Endpoint, batch.InputFileID, batch.Status,
+		batch.CompletionWindow, metadata, batch.ClientIP, batch.RequestTotal, batch.HoldAmount, batch.DiscountMultiplier,
+		batch.HoldMultiplier, batch.CreatedAt, batch.InProgressAt, batch.ExpiresAt); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO openai_batch_items (batch_id, line_index, custom_id, method, url, body, status)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending')
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for i := range items {
+		if _, err = stmt.ExecContext(ctx, batch.ID, items[i].LineIndex, items[i].CustomID, items[i].Method, items[i].URL, items[i].Body); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *openAIBatchRepository) GetBatch(ctx context.Context, userID int64, batchID string) (*service.OpenAIBatch, error) {
+	batch, err := scanOpenAIBatch(r.db.QueryRowContext(ctx, `
+		SELECT `+openAIBatchColumns+`
+		FROM openai_batches AS b
+		WHERE b.id = $1 AND b.user_id = $2
+	`, batchID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOpenAIBatchNotFound
+	}
+	return batch, err
+}
+
+func (r *openAIBatchRepository) ListBatches(ctx context.Context, userID int64, after string, limit int) ([]service.OpenAIBatch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+openAIBatchColumns+`
+		FROM openai_batches AS b
+		WHERE b.user_id = $1
+		  AND ($2 = '' OR (b.created_at, b.id) < (SELECT created_at, id FROM openai_batches WHERE id = $2 AND user_id = $1))
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $3
+	`, userID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanOpenAIBatches(rows, limit)
+}
+
+func scanOpenAIBatches(rows *sql.Rows, capacity int) ([]service.OpenAIBatch, error) {
+	batches := make([]service.OpenAIBatch, 0, capacity)
+	for rows.Next() {
+		b, err := scanOpenAIBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, *b)
+	}
+	return batches, rows.Err()
+}
+
+func (r *openAIBatchRepository) MarkBatchCancelling(ctx context.Context, userID int64, batchID string) (*service.OpenAIBatch, error) {
+	batch, err := scanOpenAIBatch(r.db.QueryRowContext(ctx, `
+		UPDATE openai_batches AS b
+		SET status = 'cancelling', cancelling_at = NOW(), updated_at = NOW()
+		WHERE b.id = $1 AND b.user_id = $2 AND b.status IN ('validating', 'in_progress')
+		RETURNING `+openAIBatchColumns, batchID, userID))
+	if err == nil {
+		return batch, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	current, getErr := r.GetBatch(ctx, userID, batchID)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if current.Status == service.OpenAIBatchStatusCancelling {
+		return current, nil
+	}
+	return nil, service.ErrOpenAIBatchNotCancellable
+}
+
+func (r *openAIBatchRepository) ClaimBatches(ctx context.Context, workerID string, limit int, lease time.Duration) ([]service.OpenAIBatch, error) {
+	leaseSeconds := int64(lease / time.Second)
+	if leaseSeconds < 1 {
+		leaseSeconds = 60
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		WITH candidates AS (
+			SELECT id
+			FROM openai_batches
+			WHERE status IN ('in_progress', 'finalizing', 'cancelling')
+			  AND (claimed_until IS NULL OR claimed_until < NOW())
+			ORDER BY created_at ASC, id ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE openai_batches AS b
+		SET claimed_by = $1, claimed_until = NOW() + ($3 * INTERVAL '1 second')
+		FROM candidates AS c
+		WHERE b.id = c.id
+		RETURNING `+openAIBatchColumns, workerID, limit, leaseSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanOpenAIBatches(rows, limit)
+}
+
+func (r *openAIBatchRepository) ReleaseBatchClaim(ctx context.Context, batchID, workerID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE openai_batches AS b
+		SET claimed_by = NULL, claimed_until = NULL, updated_at = NOW(),
+			request_completed = counts.succeeded, request_failed = counts.failed
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE status = 'succeeded') AS succeeded,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM openai_batch_items WHERE batch_id = $1
+		) AS counts
+		WHERE b.id = $1 AND b.claimed_by = $2
+	`, batchID, workerID)
+	return err
+}
+
+func (r *openAIBatchRepository) ClaimPendingItems(ctx context.Context, batchID string, limit int, staleBefore time.Time) ([]service.OpenAIBatchItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH candidates AS (
+			SELECT id FROM openai_batch_items
+			WHERE batch_id = $1
+			  AND (status = 'pending' OR (status = 'running' AND started_at < $3))
+			ORDER BY line_index ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE openai_batch_items AS i
+		SET status = 'running', started_at = NOW(), attempts = i.attempts + 1
+		FROM candidates AS c
+		WHERE i.id = c.id
+		RETURNING i.id, i.batch_id, i.line_index, i.custom_id, i.method, i.url, i.body, i.status, i.attempts,
+			COALESCE(i.response_status, 0), i.response_body, COALESCE(i.request_id, ''), COALESCE(i.error_code, ''),
+			COALESCE(i.error_message, ''), i.cost, i.billed_at
+	`, batchID, limit, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanOpenAIBatchItems(rows, limit)
+}
+
+func scanOpenAIBatchItems(rows *sql.Rows, capacity int) ([]service.OpenAIBatchItem, error) {
+	items := make([]service.OpenAIBatchItem, 0, capacity)
+	for rows.Next() {
+		item, err := scanOpenAIBatchItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+func (r *openAIBatchRepository) CompleteItem(ctx context.Context, item *service.OpenAIBatchItem) error {
+	var responseStatus sql.NullInt64
+	if item.ResponseStatus > 0 {
+		responseStatus = sql.NullInt64{Int64: int64(item.ResponseStatus), Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE openai_batch_items
+		SET status = $2, response_status = $3, response_body = $4, request_id = NULLIF($5, ''),
+			error_code = NULLIF($6, ''), error_message = NULLIF($7, ''), finished_at = NOW()
+		WHERE id = $1 AND status = 'running'
+	`, item.ID, item.Status, responseStatus, item.ResponseBody, item.RequestID, item.ErrorCode, item.ErrorMessage)
+	return err
+}
+
+func (r *openAIBatchRepository) RequeueItem(ctx context.Context, itemID int64, errorMessage string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE openai_batch_items
+		SET status = 'pending', started_at = NULL, error_message = NULLIF($2, '')
+		WHERE id = $1 AND status = 'running'
+	`, itemID, errorMessage)
+	return err
+}
+
+func (r *openAIBatchRepository) FailUnfinishedItems(ctx context.Context, batchID, code, message string) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE openai_batch_items
+		SET status = 'failed', error_code = $2, error_message = $3, response_status = NULL,
+			response_body = NULL, finished_at = NOW()
+		WHERE batch_id = $1 AND status IN ('pending', 'running')
+	`, batchID, code, message)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *openAIBatchRepository) RecordItemCost(ctx context.Context, itemID int64, requestID string, cost float64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE openai_batch_items
+		SET cost = $2, billed_at = NOW(), request_id = COALESCE(request_id, NULLIF($3, ''))
+		WHERE id = $1
+	`, itemID, cost, requestID)
+	return err
+}
+
+func (r *openAIBatchRepository) GetBatchProgress(ctx context.Context, batchID string) (*service.OpenAIBatchProgress, error) {
+	var p service.OpenAIBatchProgress
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'running'),
+			COUNT(*) FILTER (WHERE status = 'succeeded'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'failed' AND error_code = $2),
+			COUNT(*) FILTER (WHERE status = 'succeeded' AND billed_at IS NULL),
+			COALESCE(SUM(cost), 0)
+		FROM openai_batch_items
+		WHERE batch_id = $1
+	`, batchID, service.OpenAIBatchItemErrorExpired).Scan(&p.Pending, &p.Running, &p.Succeeded, &p.Failed, &p.Expired, &p.Unbilled, &p.Cost)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *openAIBatchRepository) ListBatchItems(ctx context.Context, batchID string) ([]service.OpenAIBatchItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+openAIBatchItemColumns+`
+		FROM openai_batch_items
+		WHERE batch_id = $1
+		ORDER BY line_index ASC
+	`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanOpenAIBatchItems(rows, 0)
+}
+
+func (r *openAIBatchRepository) MarkBatchFinalizing(ctx context.Context, batchID string) (*service.OpenAIBatch, error) {
+	batch, err := scanOpenAIBatch(r.db.QueryRowContext(ctx, `
+		UPDATE openai_batches AS b
+		SET status = CASE WHEN b.status = 'in_progress' THEN 'finalizing' ELSE b.status END,
+			finalizing_at = COALESCE(b.finalizing_at, NOW()), updated_at = NOW()
+		WHERE b.id = $1
+		RETURNING `+openAIBatchColumns, batchID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOpenAIBatchNotFound
+	}
+	return batch, err
+}
+
+func (r *openAIBatchRepository) LockSettleAmount(ctx context.Context, batchID string, amount float64) (float64, error) {
+	var locked float64
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE openai_batches
+		SET settle_amount = COALESCE(settle_amount, $2), updated_at = NOW()
+		WHERE id = $1
+		RETURNING settle_amount
+	`, batchID, amount).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, service.ErrOpenAIBatchNotFound
+	}
+	return locked, err
+}
+
+func (r *openAIBatchRepository) SetBatchError(ctx context.Context, batchID, message string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE openai_batches SET last_error = NULLIF($2, ''), updated_at = NOW() WHERE id = $1
+	`, batchID, message)
+	return err
+}
+
+func (r *openAIBatchRepository) FinishBatch(ctx context.Context, batchID string, finish *service.OpenAIBatchFinish) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	var outputFileID, errorFileID *string
+	if finish.OutputFile != nil {
+		if err = insertOpenAIFile(ctx, tx, finish.OutputFile); err != nil {
+			return err
+		}
+		outputFileID = &finish.OutputFile.ID
+	}
+	if finish.ErrorFile != nil {
+		if err = insertOpenAIFile(ctx, tx, finish.ErrorFile); err != nil {
+			return err
+		}
+		errorFileID = &finish.ErrorFile.ID
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE openai_batches AS b
+		SET status = $2, actual_cost = $3, output_file_id = $4, error_file_id = $5,
+			last_error = NULLIF($6, ''), updated_at = NOW(),
+			completed_at = CASE WHEN $2 = 'completed' THEN NOW() ELSE b.completed_at END,
+			expired_at = CASE WHEN $2 = 'expired' THEN NOW() ELSE b.expired_at END,
+			cancelled_at = CASE WHEN $2 = 'cancelled' THEN NOW() ELSE b.cancelled_at END,
+			failed_at = CASE WHEN $2 = 'failed' THEN NOW() ELSE b.failed_at END,
+			request_completed = counts.succeeded, request_failed = counts.failed
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE status = 'succeeded') AS succeeded,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM openai_batch_items WHERE batch_id = $1
+		) AS counts
+		WHERE b.id = $1 AND b.status IN ('in_progress', 'finalizing', 'cancelling')
+	`, batchID, finish.Status, finish.ActualCost, outputFileID, errorFileID, finish.LastError)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = service.ErrOpenAIBatchNotFound
+		return err
+	}
+	return tx.Commit()
+}
@@ -30,6 +30,7 @@ SELECT
   sustained_minutes,
   cooldown_minutes,
   COALESCE(notify_email, true),
+  COALESCE(notify_channels, '[]'::jsonb),
   filters,
   last_triggered_at,
   created_at,
@@ -46,7 +47,7 @@ ORDER BY id DESC`
 	out := []*service.OpsAlertRule{}
 	for rows.Next() {
 		var rule service.OpsAlertRule
-		var filtersRaw []byte
+		var filtersRaw, notifyChannelsRaw []byte
 		var lastTriggeredAt sql.NullTime
 		if err := rows.Scan(
 			&rule.ID,
@@ -61,6 +62,7 @@ ORDER BY id DESC`
 			&rule.SustainedMinutes,
 			&rule.CooldownMinutes,
 			&rule.NotifyEmail,
+			&notifyChannelsRaw,
 			&filtersRaw,
 			&lastTriggeredAt,
 			&rule.CreatedAt,
@@ -78,6 +80,7 @@ ORDER BY id DESC`
 				rule.Filters = decoded
 			}
 		}
+		rule.NotifyChannels = opsDecodeNotifyChannels(notifyChannelsRaw)
 		out = append(out, &rule)
 	}
 	if err := rows.Err(); err != nil {
@@ -98,6 +101,10 @@ func (r *opsRepository) CreateAlertRule(ctx context.Context, input *service.OpsA
 	if err != nil {
 		return nil, err
 	}
+	notifyChannelsArg, err := opsNotifyChannelsJSON(input.NotifyChannels)
+	if err != nil {
+		return nil, err
+	}
 
 	q := `
 INSERT INTO ops_alert_rules (
@@ -112,11 +119,12 @@ INSERT INTO ops_alert_rules (
   sustained_minutes,
   cooldown_minutes,
   notify_email,
+  notify_channels,
   filters,
   created_at,
   updated_at
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,NOW(),NOW()
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NOW(),NOW()
 )
 RETURNING
   id,
@@ -131,13 +139,14 @@ RETURNING
   sustained_minutes,
   cooldown_minutes,
   COALESCE(notify_email, true),
+  COALESCE(notify_channels, '[]'::jsonb),
   filters,
   last_triggered_at,
   created_at,
   updated_at`
 
 	var out service.OpsAlertRule
-	var filtersRaw []byte
+	var filtersRaw, notifyChannelsRaw []byte
 	var lastTriggeredAt sql.NullTime
 
 	if err := r.db.QueryRowContext(
@@ -154,6 +163,7 @@ RETURNING
 		input.SustainedMinutes,
 		input.CooldownMinutes,
 		input.NotifyEmail,
+		notifyChannelsArg,
 		filtersArg,
 	).Scan(
 		&out.ID,
@@ -168,6 +178,7 @@ RETURNING
 		&out.SustainedMinutes,
 		&out.CooldownMinutes,
 		&out.NotifyEmail,
+		&notifyChannelsRaw,
 		&filtersRaw,
 		&lastTriggeredAt,
 		&out.CreatedAt,
@@ -185,6 +196,7 @@ RETURNING
 			out.Filters = decoded
 		}
 	}
+	out.NotifyChannels = opsDecodeNotifyChannels(notifyChannelsRaw)
 
 	return &out, nil
 }
@@ -204,6 +216,10 @@ func (r *opsRepository) UpdateAlertRule(ctx context.Context, input *service.OpsA
 	if err != nil {
 		return nil, err
 	}
+	notifyChannelsArg, err := opsNotifyChannelsJSON(input.NotifyChannels)
+	if err != nil {
+		return nil, err
+	}
 
 	q := `
 UPDATE ops_alert_rules
@@ -219,7 +235,8 @@ SET
   sustained_minutes = $10,
   cooldown_minutes = $11,
   notify_email = $12,
-  filters = $13,
+  notify_channels = $13,
+  filters = $14,
   updated_at = NOW()
 WHERE id = $1
 RETURNING
@@ -235,13 +252,14 @@ RETURNING
   sustained_minutes,
   cooldown_minutes,
   COALESCE(notify_email, true),
+  COALESCE(notify_channels, '[]'::jsonb),
   filters,
   last_triggered_at,
   created_at,
   updated_at`
 
 	var out service.OpsAlertRule
-	var filtersRaw []byte
+	var filtersRaw, notifyChannelsRaw []byte
 	var lastTriggeredAt sql.NullTime
 
 	if err := r.db.QueryRowContext(
@@ -259,6 +277,7 @@ RETURNING
 		input.SustainedMinutes,
 		input.CooldownMinutes,
 		input.NotifyEmail,
+		notifyChannelsArg,
 		filtersArg,
 	).Scan(
 		&out.ID,
@@ -273,6 +292,7 @@ RETURNING
 		&out.SustainedMinutes,
 		&out.CooldownMinutes,
 		&out.NotifyEmail,
+		&notifyChannelsRaw,
 		&filtersRaw,
 		&lastTriggeredAt,
 		&out.CreatedAt,
@@ -291,6 +311,7 @@ RETURNING
 			out.Filters = decoded
 		}
 	}
+	out.NotifyChannels = opsDecodeNotifyChannels(notifyChannelsRaw)
 
 	return &out, nil
 }
@@ -851,3 +872,25 @@ func opsNullJSONMap(v map[string]any) (any, error) {
 	}
 	return sql.NullString{String: string(b), Valid: true}, nil
 }
+
+func opsNotifyChannelsJSON(channels []service.OpsAlertNotifyChannel) (string, error) {
+	if len(channels) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(channels)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func opsDecodeNotifyChannels(raw []byte) []service.OpsAlertNotifyChannel {
+	out := []service.OpsAlertNotifyChannel{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return out
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return []service.OpsAlertNotifyChannel{}
+	}
+	return out
+}
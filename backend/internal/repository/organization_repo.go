@@ -0,0 +1,625 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type organizationRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) service.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+const organizationColumns = `o.id, o.name, o.owner_user_id, o.balance, o.status,
+	(SELECT COUNT(*) FROM organization_members cm WHERE cm.organization_id = o.id),
+	o.created_at, o.updated_at`
+
+const organizationMemberColumns = `m.id, m.organization_id, m.user_id, m.role, m.monthly_spend_limit_usd,
+	COALESCE(u.email, ''), COALESCE(u.username, ''), m.created_at, m.updated_at`
+
+const organizationInvitationColumns = `id, organization_id, email, role, invited_by, status, expires_at, accepted_by, accepted_at, created_at`
+
+func scanOrganization(row rowScanner, extra ...any) (*service.Organization, error) {
+	var o service.Organization
+	dest := []any{&o.ID, &o.Name, &o.OwnerUserID, &o.Balance, &o.Status, &o.MemberCount, &o.CreatedAt, &o.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func scanOrganizationMember(row rowScanner) (*service.OrganizationMember, error) {
+	var m service.OrganizationMember
+	if err := row.Scan(&m.ID, &m.OrganizationID, &m.UserID, &m.Role, &m.MonthlySpendLimitUSD, &m.Email, &m.Username, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func scanOrganizationInvitation(row rowScanner) (*service.OrganizationInvitation, error) {
+	var (
+		inv        service.OrganizationInvitation
+		acceptedBy sql.NullInt64
+		acceptedAt sql.NullTime
+	)
+	if err := row.Scan(&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.Status, &inv.ExpiresAt, &acceptedBy, &acceptedAt, &inv.CreatedAt); err != nil {
+		return nil, err
+	}
+	if acceptedBy.Valid {
+		v := acceptedBy.Int64
+		inv.AcceptedBy = &v
+	}
+	if acceptedAt.Valid {
+		v := acceptedAt.Time
+		inv.AcceptedAt = &v
+	}
+	return &inv, nil
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *service.Organization) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin create organization: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO organizations (name, owner_user_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, balance, created_at, updated_at
+	`, org.Name, org.OwnerUserID, org.Status).Scan(&org.ID, &org.Balance, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		return fmt.Errorf("create organization: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+	`, org.ID, org.OwnerUserID, service.OrganizationRoleOwner); err != nil {
+		return fmt.Errorf("create organization owner: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit create organization: %w", err)
+	}
+	org.MemberCount = 1
+	return nil
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id int64) (*service.Organization, error) {
+	o, err := scanOrganization(r.db.QueryRowContext(ctx, `
+		SELECT `+organizationColumns+`
+		FROM organizations o
+		WHERE o.id = $1 AND o.deleted_at IS NULL
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+	return o, nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, org *service.Organization) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE organizations
+		SET name = $2, status = $3, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`, org.ID, org.Name, org.Status).Scan(&org.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update organization: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepository) List(ctx context.Context, params pagination.PaginationParams, search string) ([]service.Organization, *pagination.PaginationResult, error) {
+	where := "o.deleted_at IS NULL"
+	args := []any{}
+	if search = strings.TrimSpace(search); search != "" {
+		args = append(args, "%"+search+"%")
+		where += fmt.Sprintf(" AND o.name ILIKE $%d", len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM organizations o WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count organizations: %w", err)
+	}
+
+	args = append(args, params.Limit(), params.Offset())
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM organizations o
+		WHERE %s
+		ORDER BY o.id DESC
+		LIMIT $%d OFFSET $%d
+	`, organizationColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list organizations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.Organization, 0, params.Limit())
+	for rows.Next() {
+		o, err := scanOrganization(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, *o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *organizationRepository) ListForUser(ctx context.Context, userID int64) ([]service.OrganizationMembership, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+organizationColumns+`, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1 AND o.deleted_at IS NULL
+		ORDER BY o.id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user organizations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.OrganizationMembership, 0)
+	for rows.Next() {
+		var role string
+		o, err := scanOrganization(rows, &role)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, service.OrganizationMembership{Organization: *o, Role: role})
+	}
+	return out, rows.Err()
+}
+
+func (r *organizationRepository) CountOwnedBy(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM organizations
+		WHERE owner_user_id = $1 AND deleted_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count owned organizations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *organizationRepository) GetMember(ctx context.Context, orgID, userID int64) (*service.OrganizationMember, error) {
+	m, err := scanOrganizationMember(r.db.QueryRowContext(ctx, `
+		SELECT `+organizationMemberColumns+`
+		FROM organization_members m
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1 AND m.user_id = $2
+	`, orgID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOrganizationMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization member: %w", err)
+	}
+	return m, nil
+}
+
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID int64) ([]service.OrganizationMember, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+organizationMemberColumns+`
+		FROM organization_members m
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, m.id ASC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list organization members: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.OrganizationMember, 0)
+	for rows.Next() {
+		m, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *m)
+	}
+	return out, rows.Err()
+}
+
+func (r *organizationRepository) UpdateMember(ctx context.Context, member *service.OrganizationMember) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE organization_members
+		SET role = $3, monthly_spend_limit_usd = $4, updated_at = NOW()
+		WHERE organization_id = $1 AND user_id = $2
+		RETURNING updated_at
+	`, member.OrganizationID, member.UserID, member.Role, member.MonthlySpendLimitUSD).Scan(&member.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrOrganizationMemberNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update organization member: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2 AND role <> $3
+	`, orgID, userID, service.OrganizationRoleOwner)
+	if err != nil {
+		return fmt.Errorf("remove organization member: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return service.ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+func (r *organizationRepository) CreateInvitation(ctx context.Context, inv *service.OrganizationInvitation, tokenHash string) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO organization_invitations (organization_id, email, role, token_hash, invited_by, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, inv.OrganizationID, inv.Email, inv.Role, tokenHash, inv.InvitedBy, inv.Status, inv.ExpiresAt).Scan(&inv.ID, &inv.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create organization invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepository) ListInvitations(ctx context.Context, orgID int64) ([]service.OrganizationInvitation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+organizationInvitationColumns+`
+		FROM organization_invitations
+		WHERE organization_id = $1
+		ORDER BY id DESC
+		LIMIT 200
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list organization invitations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.OrganizationInvitation, 0)
+	for rows.Next() {
+		inv, err := scanOrganizationInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *inv)
+	}
+	return out, rows.Err()
+}
+
+func (r *organizationRepository) RevokeInvitation(ctx context.Context, orgID, id int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE organization_invitations
+		SET status = $3
+		WHERE id = $1 AND organization_id = $2 AND status = $4
+	`, id, orgID, service.OrganizationInvitationStatusRevoked, service.OrganizationInvitationStatusPending)
+	if err != nil {
+		return fmt.Errorf("revoke organization invitation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return service.ErrOrganizationInvitationNotFound
+	}
+	return nil
+}
+
+func (r *organizationRepository) AcceptInvitation(ctx context.Context, tokenHash string, userID int64, email string, now time.Time) (*service.OrganizationInvitation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin accept organization invitation: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	inv, err := scanOrganizationInvitation(tx.QueryRowContext(ctx, `
+		SELECT `+organizationInvitationColumns+`
+		FROM organization_invitations
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOrganizationInvitationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization invitation: %w", err)
+	}
+	if inv.Status != service.OrganizationInvitationStatusPending || !now.Before(inv.ExpiresAt) {
+		return nil, service.ErrOrganizationInvitationInvalid
+	}
+	if !strings.EqualFold(strings.TrimSpace(inv.Email), strings.TrimSpace(email)) {
+		return nil, service.ErrOrganizationInvitationMismatch
+	}
+
+	var status string
+	err = tx.QueryRowContext(ctx, `
+		SELECT status FROM organizations WHERE id = $1 AND deleted_at IS NULL
+	`, inv.OrganizationID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get invitation organization: %w", err)
+	}
+	if status != service.OrganizationStatusActive {
+		return nil, service.ErrOrganizationDisabled
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, user_id) DO NOTHING
+	`, inv.OrganizationID, userID, inv.Role)
+	if err != nil {
+		return nil, fmt.Errorf("insert organization member: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, service.ErrOrganizationMemberExists
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE organization_invitations
+		SET status = $2, accepted_by = $3, accepted_at = $4
+		WHERE id = $1
+	`, inv.ID, service.OrganizationInvitationStatusAccepted, userID, now); err != nil {
+		return nil, fmt.Errorf("mark organization invitation accepted: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit accept organization invitation: %w", err)
+	}
+	inv.Status = service.OrganizationInvitationStatusAccepted
+	inv.AcceptedBy = &userID
+	inv.AcceptedAt = &now
+	return inv, nil
+}
+
+func (r *organizationRepository) TransferFromUser(ctx context.Context, orgID, userID int64, amount float64, note string) (float64, float64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin organization transfer: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var userBalance float64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users
+		SET balance = balance - $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL AND balance >= $1
+		RETURNING balance
+	`, amount, userID).Scan(&userBalance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, service.ErrInsufficientBalance
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("deduct user balance for organization transfer: %w", err)
+	}
+
+	orgBalance, err := creditOrganizationWallet(ctx, tx, orgID, userID, service.OrganizationWalletTxMemberTransfer, amount, note)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit organization transfer: %w", err)
+	}
+	return orgBalance, userBalance, nil
+}
+
+func (r *organizationRepository) AdjustBalance(ctx context.Context, orgID int64, actorUserID int64, amount float64, note string) (float64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin organization balance adjust: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	orgBalance, err := creditOrganizationWallet(ctx, tx, orgID, actorUserID, service.OrganizationWalletTxAdminAdjust, amount, note)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit organization balance adjust: %w", err)
+	}
+	return orgBalance, nil
+}
+
+// creditOrganizationWallet 在事务内调整组织钱包并写入流水；amount 可为负（管理员扣减）。
+func creditOrganizationWallet(ctx context.Context, tx *sql.Tx, orgID, actorUserID int64, txType string, amount float64, note string) (float64, error) {
+	var balance float64
+	err := tx.QueryRowContext(ctx, `
+		UPDATE organizations
+		SET balance = balance + $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING balance
+	`, amount, orgID).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, service.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("update organization balance: %w", err)
+	}
+
+	var actor any
+	if actorUserID > 0 {
+		actor = actorUserID
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO organization_wallet_transactions (organization_id, actor_user_id, type, amount, balance_after, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, orgID, actor, txType, amount, balance, note); err != nil {
+		return 0, fmt.Errorf("insert organization wallet transaction: %w", err)
+	}
+	return balance, nil
+}
+
+func (r *organizationRepository) ListWalletTransactions(ctx context.Context, orgID int64, params pagination.PaginationParams) ([]service.OrganizationWalletTransaction, *pagination.PaginationResult, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM organization_wallet_transactions WHERE organization_id = $1
+	`, orgID).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count organization wallet transactions: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, organization_id, actor_user_id, type, amount, balance_after, note, created_at
+		FROM organization_wallet_transactions
+		WHERE organization_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, orgID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, nil, fmt.Errorf("list organization wallet transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.OrganizationWalletTransaction, 0, params.Limit())
+	for rows.Next() {
+		var (
+			t     service.OrganizationWalletTransaction
+			actor sql.NullInt64
+		)
+		if err := rows.Scan(&t.ID, &t.OrganizationID, &actor, &t.Type, &t.Amount, &t.BalanceAfter, &t.Note, &t.CreatedAt); err != nil {
+			return nil, nil, err
+		}
+		if actor.Valid {
+			v := actor.Int64
+			t.ActorUserID = &v
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *organizationRepository) GetBillingState(ctx context.Context, orgID, userID int64, monthStart time.Time) (*service.OrganizationBillingState, error) {
+	var state service.OrganizationBillingState
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			o.status,
+			o.balance,
+			m.id IS NOT NULL,
+			COALESCE(m.monthly_spend_limit_usd, 0),
+			CASE WHEN m.id IS NULL THEN 0 ELSE COALESCE((
+				SELECT SUM(ul.actual_cost)
+				FROM usage_logs ul
+				JOIN api_keys k ON k.id = ul.api_key_id
+				WHERE k.organization_id = o.id
+					AND ul.user_id = $2
+					AND ul.created_at >= $3
+			), 0) END
+		FROM organizations o
+		LEFT JOIN organization_members m ON m.organization_id = o.id AND m.user_id = $2
+		WHERE o.id = $1 AND o.deleted_at IS NULL
+	`, orgID, userID, monthStart).Scan(&state.Status, &state.Balance, &state.IsMember, &state.MonthlySpendLimitUSD, &state.MonthSpendUSD)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get organization billing state: %w", err)
+	}
+	return &state, nil
+}
+
+// GetMemberUsage 按成员聚合组织 Key 的 usage_logs；已离开组织的成员仍保留其历史用量（角色为空）。
+func (r *organizationRepository) GetMemberUsage(ctx context.Context, orgID int64, startTime, endTime time.Time) ([]service.OrganizationMemberUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH usage AS (
+			SELECT
+				ul.user_id,
+				COUNT(*) AS requests,
+				COALESCE(SUM(ul.input_tokens), 0) AS input_tokens,
+				COALESCE(SUM(ul.output_tokens), 0) AS output_tokens,
+				COALESCE(SUM(ul.cache_creation_tokens + ul.cache_read_tokens), 0) AS cache_tokens,
+				COALESCE(SUM(ul.total_cost), 0) AS total_cost,
+				COALESCE(SUM(ul.actual_cost), 0) AS actual_cost
+			FROM usage_logs ul
+			JOIN api_keys k ON k.id = ul.api_key_id
+			WHERE k.organization_id = $1
+				AND ul.created_at >= $2
+				AND ul.created_at < $3
+			GROUP BY ul.user_id
+		)
+		SELECT
+			COALESCE(m.user_id, usage.user_id),
+			COALESCE(u.email, ''),
+			COALESCE(u.username, ''),
+			COALESCE(m.role, ''),
+			COALESCE(m.monthly_spend_limit_usd, 0),
+			COALESCE(usage.requests, 0),
+			COALESCE(usage.input_tokens, 0),
+			COALESCE(usage.output_tokens, 0),
+			COALESCE(usage.cache_tokens, 0),
+			COALESCE(usage.total_cost, 0),
+			COALESCE(usage.actual_cost, 0)
+		FROM (SELECT * FROM organization_members WHERE organization_id = $1) m
+		FULL OUTER JOIN usage ON usage.user_id = m.user_id
+		LEFT JOIN users u ON u.id = COALESCE(m.user_id, usage.user_id)
+		ORDER BY COALESCE(usage.actual_cost, 0) DESC, 1 ASC
+	`, orgID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("get organization member usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.OrganizationMemberUsage, 0)
+	for rows.Next() {
+		var u service.OrganizationMemberUsage
+		if err := rows.Scan(&u.UserID, &u.Email, &u.Username, &u.Role, &u.MonthlySpendLimitUSD,
+			&u.Requests, &u.InputTokens, &u.OutputTokens, &u.CacheTokens, &u.TotalCost, &u.ActualCost); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func (r *organizationRepository) ListAPIKeys(ctx context.Context, orgID int64) ([]service.OrganizationAPIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, status, group_id, last_used_at, created_at
+		FROM api_keys
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		ORDER BY id DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list organization api keys: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.OrganizationAPIKey, 0)
+	for rows.Next() {
+		var (
+			k          service.OrganizationAPIKey
+			groupID    sql.NullInt64
+			lastUsedAt sql.NullTime
+		)
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.Status, &groupID, &lastUsedAt, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		if groupID.Valid {
+			v := groupID.Int64
+			k.GroupID = &v
+		}
+		if lastUsedAt.Valid {
+			v := lastUsedAt.Time
+			k.LastUsedAt = &v
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
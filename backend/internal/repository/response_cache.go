@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+type responseCacheStore struct {
+	rdb       *redis.Client
+	keyPrefix string
+}
+
+func NewResponseCacheStore(rdb *redis.Client, cfg *config.Config) service.ResponseCacheStore {
+	prefix := "response_cache:"
+	if cfg != nil && strings.TrimSpace(cfg.ResponseCache.KeyPrefix) != "" {
+		prefix = strings.TrimSpace(cfg.ResponseCache.KeyPrefix)
+	}
+	if !strings.HasSuffix(prefix, ":") {
+		prefix += ":"
+	}
+	return &responseCacheStore{rdb: rdb, keyPrefix: prefix}
+}
+
+func (c *responseCacheStore) GetResponseCacheEntry(ctx context.Context, key string) (*service.ResponseCacheEntry, error) {
+	raw, err := c.rdb.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, service.ErrResponseCacheMiss
+		}
+		return nil, err
+	}
+	var entry service.ResponseCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		// 旧格式或损坏的条目按未命中处理，由本次请求重新写入覆盖。
+		return nil, service.ErrResponseCacheMiss
+	}
+	return &entry, nil
+}
+
+func (c *responseCacheStore) SetResponseCacheEntry(ctx context.Context, key string, entry *service.ResponseCacheEntry, ttl time.Duration) error {
+	if entry == nil || ttl <= 0 {
+		return nil
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, c.keyPrefix+key, raw, ttl).Err()
+}
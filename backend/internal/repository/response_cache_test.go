@@ -0,0 +1,46 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCacheStore_RoundTripAndExpiry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cfg := &config.Config{}
+	cfg.ResponseCache.KeyPrefix = "rc"
+	store := NewResponseCacheStore(rdb, cfg)
+	ctx := context.Background()
+
+	_, err := store.GetResponseCacheEntry(ctx, "k1")
+	require.ErrorIs(t, err, service.ErrResponseCacheMiss)
+
+	entry := &service.ResponseCacheEntry{StatusCode: 200, Stream: true, Body: []byte("data: {}\n\n"), Model: "m", AccountID: 9, OutputTokens: 3}
+	require.NoError(t, store.SetResponseCacheEntry(ctx, "k1", entry, time.Minute))
+	require.True(t, mr.Exists("rc:k1"))
+
+	got, err := store.GetResponseCacheEntry(ctx, "k1")
+	require.NoError(t, err)
+	require.Equal(t, entry.Body, got.Body)
+	require.Equal(t, int64(9), got.AccountID)
+	require.True(t, got.Stream)
+
+	mr.FastForward(2 * time.Minute)
+	_, err = store.GetResponseCacheEntry(ctx, "k1")
+	require.ErrorIs(t, err, service.ErrResponseCacheMiss)
+
+	require.NoError(t, mr.Set("rc:bad", "{not json"))
+	_, err = store.GetResponseCacheEntry(ctx, "bad")
+	require.ErrorIs(t, err, service.ErrResponseCacheMiss)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const samlAssertionReplayKeyPrefix = "saml:assertion:"
+
+type samlAssertionReplayCache struct {
+	rdb *redis.Client
+}
+
+// NewSAMLAssertionReplayCache 创建基于 Redis 的 SAML 断言重放登记表，多实例共享。
+func NewSAMLAssertionReplayCache(rdb *redis.Client) service.SAMLAssertionReplayCache {
+	return &samlAssertionReplayCache{rdb: rdb}
+}
+
+func (c *samlAssertionReplayCache) MarkSAMLAssertionUsed(ctx context.Context, assertionID string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, samlAssertionReplayKeyPrefix+assertionID, 1, ttl).Result()
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -43,9 +44,9 @@ func TestUsageBillingRepositoryApply_DeduplicatesBalanceBilling(t *testing.T) {
 		UserID:              user.ID,
 		AccountID:           account.ID,
 		AccountType:         service.AccountTypeAPIKey,
-		BalanceCost:         1.25,
-		APIKeyQuotaCost:     1.25,
-		APIKeyRateLimitCost: 1.25,
+		BalanceCost:         decimal.NewFromFloat(1.25),
+		APIKeyQuotaCost:     decimal.NewFromFloat(1.25),
+		APIKeyRateLimitCost: decimal.NewFromFloat(1.25),
 	}
 
 	result1, err := repo.Apply(ctx, cmd)
@@ -112,7 +113,7 @@ func TestUsageBillingRepositoryApply_DeduplicatesSubscriptionBilling(t *testing.
 		UserID:           user.ID,
 		AccountID:        0,
 		SubscriptionID:   &subscription.ID,
-		SubscriptionCost: 2.5,
+		SubscriptionCost: decimal.NewFromFloat(2.5),
 	}
 
 	result1, err := repo.Apply(ctx, cmd)
@@ -149,7 +150,7 @@ func TestUsageBillingRepositoryApply_RequestFingerprintConflict(t *testing.T) {
 		RequestID:   requestID,
 		APIKeyID:    apiKey.ID,
 		UserID:      user.ID,
-		BalanceCost: 1.25,
+		BalanceCost: decimal.NewFromFloat(1.25),
 	})
 	require.NoError(t, err)
 
@@ -157,7 +158,7 @@ func TestUsageBillingRepositoryApply_RequestFingerprintConflict(t *testing.T) {
 		RequestID:   requestID,
 		APIKeyID:    apiKey.ID,
 		UserID:      user.ID,
-		BalanceCost: 2.50,
+		BalanceCost: decimal.NewFromFloat(2.50),
 	})
 	require.ErrorIs(t, err, service.ErrUsageBillingRequestConflict)
 }
@@ -190,7 +191,7 @@ func TestUsageBillingRepositoryApply_UpdatesAccountQuota(t *testing.T) {
 		UserID:           user.ID,
 		AccountID:        account.ID,
 		AccountType:      service.AccountTypeAPIKey,
-		AccountQuotaCost: 3.5,
+		AccountQuotaCost: decimal.NewFromFloat(3.5),
 	})
 	require.NoError(t, err)
 
@@ -243,7 +244,7 @@ func TestUsageBillingRepositoryApply_EnqueuesSchedulerOutboxOnQuotaCrossing(t *t
 			APIKeyID:         apiKeyID,
 			AccountID:        accountID,
 			AccountType:      service.AccountTypeAPIKey,
-			AccountQuotaCost: 4,
+			AccountQuotaCost: decimal.NewFromFloat(4),
 		})
 		require.NoError(t, err)
 		require.Equal(t, 0, outboxCountFor(t, accountID), "below limit should not enqueue")
@@ -254,7 +255,7 @@ func TestUsageBillingRepositoryApply_EnqueuesSchedulerOutboxOnQuotaCrossing(t *t
 			APIKeyID:         apiKeyID,
 			AccountID:        accountID,
 			AccountType:      service.AccountTypeAPIKey,
-			AccountQuotaCost: 8,
+			AccountQuotaCost: decimal.NewFromFloat(8),
 		})
 		require.NoError(t, err)
 		require.Equal(t, 1, outboxCountFor(t, accountID), "crossing daily limit should enqueue once")
@@ -265,7 +266,7 @@ func TestUsageBillingRepositoryApply_EnqueuesSchedulerOutboxOnQuotaCrossing(t *t
 			APIKeyID:         apiKeyID,
 			AccountID:        accountID,
 			AccountType:      service.AccountTypeAPIKey,
-			AccountQuotaCost: 2,
+			AccountQuotaCost: decimal.NewFromFloat(2),
 		})
 		require.NoError(t, err)
 		require.Equal(t, 1, outboxCountFor(t, accountID), "subsequent increments beyond limit should not re-enqueue")
@@ -280,7 +281,7 @@ func TestUsageBillingRepositoryApply_EnqueuesSchedulerOutboxOnQuotaCrossing(t *t
 			APIKeyID:         apiKeyID,
 			AccountID:        accountID,
 			AccountType:      service.AccountTypeAPIKey,
-			AccountQuotaCost: 15, // 单次即跨越
+			AccountQuotaCost: decimal.NewFromFloat(15), // 单次即跨越
 		})
 		require.NoError(t, err)
 		require.Equal(t, 1, outboxCountFor(t, accountID), "single-shot crossing weekly limit should enqueue once")
@@ -342,7 +343,7 @@ func TestUsageBillingRepositoryApply_DeduplicatesAgainstArchivedKey(t *testing.T
 		RequestID:   requestID,
 		APIKeyID:    apiKey.ID,
 		UserID:      user.ID,
-		BalanceCost: 1.25,
+		BalanceCost: decimal.NewFromFloat(1.25),
 	}
 
 	result1, err := repo.Apply(ctx, cmd)
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type usageExportRepository struct {
+	db *sql.DB
+}
+
+func NewUsageExportRepository(db *sql.DB) service.UsageExportRepository {
+	return &usageExportRepository{db: db}
+}
+
+func (r *usageExportRepository) GetState(ctx context.Context, stream string) (*service.UsageExportState, error) {
+	// 迁移已写入默认行；这里兜底插入，保证新增的导出流无需额外迁移。
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO usage_export_state (stream) VALUES ($1) ON CONFLICT (stream) DO NOTHING`, stream); err != nil {
+		return nil, err
+	}
+	var (
+		state         service.UsageExportState
+		pendingFrom   sql.NullInt64
+		pendingTo     sql.NullInt64
+		lastRunAt     sql.NullTime
+		lastSuccessAt sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT stream, watermark_id, pending_from_id, pending_to_id, last_run_at, last_success_at, last_error, updated_at
+		FROM usage_export_state WHERE stream = $1`, stream,
+	).Scan(&state.Stream, &state.WatermarkID, &pendingFrom, &pendingTo, &lastRunAt, &lastSuccessAt, &state.LastError, &state.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	state.PendingFromID = int64PtrFromNull(pendingFrom)
+	state.PendingToID = int64PtrFromNull(pendingTo)
+	state.LastRunAt = timePtrFromNull(lastRunAt)
+	state.LastSuccessAt = timePtrFromNull(lastSuccessAt)
+	return &state, nil
+}
+
+func (r *usageExportRepository) SetPending(ctx context.Context, stream string, fromID, toID int64, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE usage_export_state
+		SET pending_from_id = $2, pending_to_id = $3, last_run_at = $4, updated_at = $4
+		WHERE stream = $1 AND watermark_id = $2 AND pending_to_id IS NULL`,
+		stream, fromID, toID, at)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("usage export state for %s changed concurrently", stream)
+	}
+	return nil
+}
+
+func (r *usageExportRepository) Commit(ctx context.Context, stream string, toID int64, files []service.UsageExportFile, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, f := range files {
+		// 重做同一区间时 key 相同，覆盖清单中的旧记录。
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO usage_export_files (stream, object_key, format, partition_date, from_id, to_id, row_count, size_bytes, created_at)
+			VALUES ($1, $2, $3, $4::date, $5, $6, $7, $8, $9)
+			ON CONFLICT (object_key) DO UPDATE SET
+				row_count = EXCLUDED.row_count, size_bytes = EXCLUDED.size_bytes, created_at = EXCLUDED.created_at`,
+			f.Stream, f.ObjectKey, f.Format, f.PartitionDate, f.FromID, f.ToID, f.RowCount, f.SizeBytes, at,
+		); err != nil {
+			return fmt.Errorf("insert usage export file: %w", err)
+		}
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE usage_export_state
+		SET watermark_id = $2, pending_from_id = NULL, pending_to_id = NULL,
+			last_run_at = $3, last_success_at = $3, last_error = '', updated_at = $3
+		WHERE stream = $1 AND watermark_id <= $2`,
+		stream, toID, at)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("usage export state for %s changed concurrently", stream)
+	}
+	return tx.Commit()
+}
+
+func (r *usageExportRepository) RecordError(ctx context.Context, stream string, message string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE usage_export_state SET last_error = $2, last_run_at = $3, updated_at = $3 WHERE stream = $1`,
+		stream, message, at)
+	return err
+}
+
+func (r *usageExportRepository) ListFiles(ctx context.Context, stream string, limit int) ([]service.UsageExportFile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, stream, object_key, format, to_char(partition_date, 'YYYY-MM-DD'), from_id, to_id, row_count, size_bytes, created_at
+		FROM usage_export_files
+		WHERE stream = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`, stream, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	files := make([]service.UsageExportFile, 0)
+	for rows.Next() {
+		var f service.UsageExportFile
+		if err := rows.Scan(&f.ID, &f.Stream, &f.ObjectKey, &f.Format, &f.PartitionDate, &f.FromID, &f.ToID, &f.RowCount, &f.SizeBytes, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+func (r *usageExportRepository) UpperBoundID(ctx context.Context, cutoff time.Time) (int64, error) {
+	// 走 created_at 索引取截止时间前最新的一条，避免在大表上 MAX(id) WHERE created_at < cutoff 全量扫描。
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM usage_logs WHERE created_at < $1 ORDER BY created_at DESC, id DESC LIMIT 1`, cutoff,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (r *usageExportRepository) ListRows(ctx context.Context, fromID, toID int64, limit int) ([]service.UsageExportRow, error) {
+	query := `
+		SELECT ul.id, ul.created_at, ul.user_id, ul.api_key_id, COALESCE(ak.name, ''), ul.account_id,
+			ul.group_id, COALESCE(g.name, ''), COALESCE(ul.request_id, ''),
+			ul.model, COALESCE(ul.requested_model, ''), COALESCE(ul.upstream_model, ''),
+			COALESCE(ul.inbound_endpoint, ''), COALESCE(ul.upstream_endpoint, ''),
+			ul.billing_type, COALESCE(ul.billing_mode, ''), ul.stream,
+			ul.input_tokens, ul.output_tokens, ul.cache_creation_tokens, ul.cache_read_tokens,
+			ul.input_cost::text, ul.output_cost::text, ul.cache_creation_cost::text, ul.cache_read_cost::text,
+			ul.total_cost::text, ul.actual_cost::text, ul.rate_multiplier::text,
+			ul.duration_ms, ul.first_token_ms
+		FROM usage_logs ul
+		LEFT JOIN api_keys ak ON ak.id = ul.api_key_id
+		LEFT JOIN groups g ON g.id = ul.group_id
+		WHERE ul.id > $1 AND ul.id <= $2
+		ORDER BY ul.id`
+	args := []any{fromID, toID}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.UsageExportRow, 0)
+	for rows.Next() {
+		var (
+			row          service.UsageExportRow
+			groupID      sql.NullInt64
+			durationMs   sql.NullInt64
+			firstTokenMs sql.NullInt64
+		)
+		if err := rows.Scan(
+			&row.ID, &row.CreatedAt, &row.UserID, &row.APIKeyID, &row.APIKeyName, &row.AccountID,
+			&groupID, &row.GroupName, &row.RequestID,
+			&row.Model, &row.RequestedModel, &row.UpstreamModel,
+			&row.InboundEndpoint, &row.UpstreamEndpoint,
+			&row.BillingType, &row.BillingMode, &row.Stream,
+			&row.InputTokens, &row.OutputTokens, &row.CacheCreationTokens, &row.CacheReadTokens,
+			&row.InputCost, &row.OutputCost, &row.CacheCreationCost, &row.CacheReadCost,
+			&row.TotalCost, &row.ActualCost, &row.RateMultiplier,
+			&durationMs, &firstTokenMs,
+		); err != nil {
+			return nil, err
+		}
+		row.GroupID = int64PtrFromNull(groupID)
+		row.DurationMs = int64PtrFromNull(durationMs)
+		row.FirstTokenMs = int64PtrFromNull(firstTokenMs)
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// usageLogMoneyBackfillRepository 实现 service.UsageLogMoneyBackfillRepository。
+type usageLogMoneyBackfillRepository struct {
+	db *sql.DB
+}
+
+// NewUsageLogMoneyBackfillRepository 创建 usage_logs 金额回填仓储
+func NewUsageLogMoneyBackfillRepository(db *sql.DB) service.UsageLogMoneyBackfillRepository {
+	return &usageLogMoneyBackfillRepository{db: db}
+}
+
+// RoundActualCostBatch 按主键范围取一批记录，仅改写 actual_cost 尚未处于 8 位精度的行。
+// 每批一条自动提交的语句，锁只覆盖本批行；已舍入的行不会被重复写入，也不触发分组日汇总失效。
+func (r *usageLogMoneyBackfillRepository) RoundActualCostBatch(ctx context.Context, afterID int64, limit int) (*service.UsageLogMoneyBackfillBatch, error) {
+	var (
+		lastID  int64
+		scanned int
+		updated int
+	)
+	err := r.db.QueryRowContext(ctx, `
+		WITH batch AS (
+			SELECT id
+			FROM usage_logs
+			WHERE id > $1
+			ORDER BY id
+			LIMIT $2
+		), rounded AS (
+			UPDATE usage_logs u
+			SET actual_cost = ROUND(u.actual_cost, 8)
+			FROM batch
+			WHERE u.id = batch.id
+				AND u.actual_cost <> ROUND(u.actual_cost, 8)
+			RETURNING u.id
+		)
+		SELECT
+			COALESCE((SELECT MAX(id) FROM batch), 0),
+			(SELECT COUNT(*) FROM batch),
+			(SELECT COUNT(*) FROM rounded)
+	`, afterID, limit).Scan(&lastID, &scanned, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &service.UsageLogMoneyBackfillBatch{LastID: lastID, Scanned: scanned, Updated: updated}, nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageLogMoneyBackfillRepository_RoundActualCostBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	mock.ExpectQuery(`(?s)WITH batch AS .*WHERE id > \$1.*LIMIT \$2.*SET actual_cost = ROUND\(u\.actual_cost, 8\).*u\.actual_cost <> ROUND\(u\.actual_cost, 8\)`).
+		WithArgs(int64(100), 500).
+		WillReturnRows(sqlmock.NewRows([]string{"last_id", "scanned", "updated"}).AddRow(int64(600), 500, 37))
+
+	repo := NewUsageLogMoneyBackfillRepository(db)
+	batch, err := repo.RoundActualCostBatch(context.Background(), 100, 500)
+
+	require.NoError(t, err)
+	require.Equal(t, &service.UsageLogMoneyBackfillBatch{LastID: 600, Scanned: 500, Updated: 37}, batch)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
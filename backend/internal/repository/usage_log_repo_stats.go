@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -980,9 +981,9 @@ func (r *usageLogRepository) GetAccountUsageStats(ctx context.Context, accountID
 
 	for i := range history {
 		h := &history[i]
-		totalAccountCost += h.ActualCost
-		totalUserCost += h.UserCost
-		totalStandardCost += h.Cost
+		totalAccountCost = money.Sum(totalAccountCost, h.ActualCost)
+		totalUserCost = money.Sum(totalUserCost, h.UserCost)
+		totalStandardCost = money.Sum(totalStandardCost, h.Cost)
 		totalRequests += h.Requests
 		totalTokens += h.Tokens
 
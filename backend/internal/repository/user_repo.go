@@ -442,7 +442,8 @@ func normalizeEmailAuthIdentitySubject(email string) string {
 	if strings.HasSuffix(normalized, service.LinuxDoConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(normalized, service.OIDCConnectSyntheticEmailDomain) ||
 		strings.HasSuffix(normalized, service.WeChatConnectSyntheticEmailDomain) ||
-		strings.HasSuffix(normalized, service.DingTalkConnectSyntheticEmailDomain) {
+		strings.HasSuffix(normalized, service.DingTalkConnectSyntheticEmailDomain) ||
+		strings.HasSuffix(normalized, service.SAMLSyntheticEmailDomain) {
 		return ""
 	}
 	return normalized
@@ -823,21 +824,26 @@ func (r *userRepository) filterUsersByAttributes(ctx context.Context, attrs map[
 	return result, nil
 }
 
+// 以下余额变更方法在 context 携带余额流水来源（service.WithBalanceLedgerSource）时，
+// 会在同一事务内先绑定来源，由触发器写入带原因的流水。
+
 func (r *userRepository) UpdateBalance(ctx context.Context, id int64, amount float64) error {
-	client := clientFromContext(ctx, r.client)
-	update := client.User.Update().Where(dbuser.IDEQ(id)).AddBalance(amount)
-	// Track cumulative recharge amount for percentage-based notifications
-	if amount > 0 {
-		update = update.AddTotalRecharged(amount)
-	}
-	n, err := update.Save(ctx)
-	if err != nil {
-		return translatePersistenceError(err, service.ErrUserNotFound, nil)
-	}
-	if n == 0 {
-		return service.ErrUserNotFound
-	}
-	return nil
+	return withBalanceLedgerSource(ctx, r.client, func(ctx context.Context) error {
+		client := clientFromContext(ctx, r.client)
+		update := client.User.Update().Where(dbuser.IDEQ(id)).AddBalance(amount)
+		// Track cumulative recharge amount for percentage-based notifications
+		if amount > 0 {
+			update = update.AddTotalRecharged(amount)
+		}
+		n, err := update.Save(ctx)
+		if err != nil {
+			return translatePersistenceError(err, service.ErrUserNotFound, nil)
+		}
+		if n == 0 {
+			return service.ErrUserNotFound
+		}
+		return nil
+	})
 }
 
 func (r *userRepository) ApplyRedeemBalanceAdjustment(ctx context.Context, id int64, delta float64) error {
@@ -846,48 +852,52 @@ func (r *userRepository) ApplyRedeemBalanceAdjustment(ctx context.Context, id in
 		SET balance = GREATEST(balance + $1, 0), updated_at = NOW()
 		WHERE id = $2 AND deleted_at IS NULL
 	`
-	client := clientFromContext(ctx, r.client)
-	result, err := client.ExecContext(ctx, updateSQL, delta, id)
-	if err != nil {
-		return err
-	}
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if affected == 0 {
-		return service.ErrUserNotFound
-	}
-	return nil
+	return withBalanceLedgerSource(ctx, r.client, func(ctx context.Context) error {
+		client := clientFromContext(ctx, r.client)
+		result, err := client.ExecContext(ctx, updateSQL, delta, id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return service.ErrUserNotFound
+		}
+		return nil
+	})
 }
 
 // DeductBalance 扣除用户余额
 // 透支策略：允许余额变为负数，确保当前请求能够完成
 // 中间件会阻止余额 <= 0 的用户发起后续请求
 func (r *userRepository) DeductBalance(ctx context.Context, id int64, amount float64) error {
-	client := clientFromContext(ctx, r.client)
-	n, err := client.User.Update().
-		Where(dbuser.IDEQ(id), dbuser.BalanceGTE(amount)).
-		AddBalance(-amount).
-		Save(ctx)
-	if err != nil {
-		return err
-	}
-	if n > 0 {
-		return nil
-	}
+	return withBalanceLedgerSource(ctx, r.client, func(ctx context.Context) error {
+		client := clientFromContext(ctx, r.client)
+		n, err := client.User.Update().
+			Where(dbuser.IDEQ(id), dbuser.BalanceGTE(amount)).
+			AddBalance(-amount).
+			Save(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
 
-	n, err = client.User.Update().
-		Where(dbuser.IDEQ(id)).
-		AddBalance(-amount).
-		Save(ctx)
-	if err != nil {
-		return err
-	}
-	if n == 0 {
-		return service.ErrUserNotFound
-	}
-	return nil
+		n, err = client.User.Update().
+			Where(dbuser.IDEQ(id)).
+			AddBalance(-amount).
+			Save(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return service.ErrUserNotFound
+		}
+		return nil
+	})
 }
 
 // DeductAvailableBalance atomically deducts min(amount, max(balance, 0)).
@@ -912,25 +922,31 @@ func (r *userRepository) DeductAvailableBalance(ctx context.Context, id int64, a
 		)
 		SELECT deducted FROM updated
 	`
-	rows, err := clientFromContext(ctx, r.client).QueryContext(ctx, updateSQL, amount, id)
-	if err != nil {
-		return 0, err
-	}
-	defer func() {
-		if closeErr := rows.Close(); closeErr != nil && err == nil {
-			err = closeErr
+	err = withBalanceLedgerSource(ctx, r.client, func(ctx context.Context) (err error) {
+		rows, err := clientFromContext(ctx, r.client).QueryContext(ctx, updateSQL, amount, id)
+		if err != nil {
+			return err
 		}
-	}()
-	if !rows.Next() {
-		if rowsErr := rows.Err(); rowsErr != nil {
-			return 0, rowsErr
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		if !rows.Next() {
+			if rowsErr := rows.Err(); rowsErr != nil {
+				return rowsErr
+			}
+			return service.ErrUserNotFound
 		}
-		return 0, service.ErrUserNotFound
-	}
-	if err := rows.Scan(&deducted); err != nil {
+		if err := rows.Scan(&deducted); err != nil {
+			return err
+		}
+		return rows.Err()
+	})
+	if err != nil {
 		return 0, err
 	}
-	return deducted, rows.Err()
+	return deducted, nil
 }
 
 // AdjustBalance 原子地把 delta 累加到余额上，结果为负时整条语句不生效。
@@ -943,7 +959,14 @@ func (r *userRepository) AdjustBalance(ctx context.Context, id int64, delta floa
 		WHERE id = $2 AND deleted_at IS NULL AND balance + $1 >= 0
 		RETURNING balance - $1, balance
 	`
-	change, ok, err := scanBalanceChange(ctx, clientFromContext(ctx, r.client), updateSQL, delta, id)
+	var (
+		change service.BalanceChange
+		ok     bool
+	)
+	err := withBalanceLedgerSource(ctx, r.client, func(ctx context.Context) (err error) {
+		change, ok, err = scanBalanceChange(ctx, clientFromContext(ctx, r.client), updateSQL, delta, id)
+		return err
+	})
 	if err != nil {
 		return service.BalanceChange{}, err
 	}
@@ -976,7 +999,14 @@ func (r *userRepository) SetBalance(ctx context.Context, id int64, value float64
 		WHERE u.id = prev.id AND u.deleted_at IS NULL
 		RETURNING prev.balance, u.balance
 	`
-	change, ok, err := scanBalanceChange(ctx, clientFromContext(ctx, r.client), updateSQL, value, id)
+	var (
+		change service.BalanceChange
+		ok     bool
+	)
+	err := withBalanceLedgerSource(ctx, r.client, func(ctx context.Context) (err error) {
+		change, ok, err = scanBalanceChange(ctx, clientFromContext(ctx, r.client), updateSQL, value, id)
+		return err
+	})
 	if err != nil {
 		return service.BalanceChange{}, err
 	}
@@ -1472,7 +1502,7 @@ func userSignupSourceOrDefault(signupSource string) string {
 	switch strings.TrimSpace(strings.ToLower(signupSource)) {
 	case "", "email":
 		return "email"
-	case "linuxdo", "wechat", "oidc", "dingtalk":
+	case "linuxdo", "wechat", "oidc", "dingtalk", "saml":
 		return strings.TrimSpace(strings.ToLower(signupSource))
 	default:
 		return "email"
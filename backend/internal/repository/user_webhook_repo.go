@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type userWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewUserWebhookRepository(db *sql.DB) service.UserWebhookRepository {
+	return &userWebhookRepository{db: db}
+}
+
+const userWebhookEndpointColumns = `id, user_id, name, url, secret_encrypted, events, enabled, created_at, updated_at`
+
+var userWebhookDeliveryColumns = userWebhookDeliveryColumnsFor("d")
+
+func scanUserWebhookEndpoint(row rowScanner) (*service.UserWebhookEndpoint, error) {
+	var e service.UserWebhookEndpoint
+	if err := row.Scan(&e.ID, &e.UserID, &e.Name, &e.URL, &e.SecretEncrypted, pq.Array(&e.Events), &e.Enabled, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if e.Events == nil {
+		e.Events = []string{}
+	}
+	return &e, nil
+}
+
+func scanUserWebhookDelivery(row rowScanner, extra ...any) (*service.UserWebhookDelivery, error) {
+	var (
+		d              service.UserWebhookDelivery
+		payload        []byte
+		responseStatus sql.NullInt64
+		durationMs     sql.NullInt64
+		deliveredAt    sql.NullTime
+	)
+	dest := []any{
+		&d.ID, &d.EndpointID, &d.UserID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &responseStatus, &d.LastResponseBody, &d.LastError,
+		&durationMs, &d.CreatedAt, &d.UpdatedAt, &deliveredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if responseStatus.Valid {
+		v := int(responseStatus.Int64)
+		d.LastResponseStatus = &v
+	}
+	if durationMs.Valid {
+		v := int(durationMs.Int64)
+		d.LastDurationMs = &v
+	}
+	if deliveredAt.Valid {
+		v := deliveredAt.Time
+		d.DeliveredAt = &v
+	}
+	return &d, nil
+}
+
+func (r *userWebhookRepository) ListEndpoints(ctx context.Context, userID int64) ([]service.UserWebhookEndpoint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userWebhookEndpointColumns+`
+		FROM user_webhook_endpoints
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user webhook endpoints: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.UserWebhookEndpoint, 0)
+	for rows.Next() {
+		e, err := scanUserWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+func (r *userWebhookRepository) GetEndpoint(ctx context.Context, userID, id int64) (*service.UserWebhookEndpoint, error) {
+	e, err := scanUserWebhookEndpoint(r.db.QueryRowContext(ctx, `
+		SELECT `+userWebhookEndpointColumns+`
+		FROM user_webhook_endpoints
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrUserWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user webhook endpoint: %w", err)
+	}
+	return e, nil
+}
+
+func (r *userWebhookRepository) CountEndpoints(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM user_webhook_endpoints
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count user webhook endpoints: %w", err)
+	}
+	return count, nil
+}
+
+func (r *userWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *service.UserWebhookEndpoint) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO user_webhook_endpoints (user_id, name, url, secret_encrypted, events, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, endpoint.UserID, endpoint.Name, endpoint.URL, endpoint.SecretEncrypted, pq.Array(endpoint.Events), endpoint.Enabled,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create user webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *userWebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *service.UserWebhookEndpoint) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE user_webhook_endpoints
+		SET name = $3, url = $4, secret_encrypted = $5, events = $6, enabled = $7, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at
+	`, endpoint.ID, endpoint.UserID, endpoint.Name, endpoint.URL, endpoint.SecretEncrypted, pq.Array(endpoint.Events), endpoint.Enabled,
+	).Scan(&endpoint.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrUserWebhookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update user webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteEndpoint 软删除 endpoint，并把其未完成的投递标记为失败，避免继续发送到已删除的目标。
+func (r *userWebhookRepository) DeleteEndpoint(ctx context.Context, userID, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_webhook_endpoints
+		SET deleted_at = NOW(), enabled = FALSE, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete user webhook endpoint: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrUserWebhookNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_webhook_deliveries
+		SET status = 'failed', last_error = 'endpoint deleted', claimed_at = NULL, claimed_by = NULL, updated_at = NOW()
+		WHERE endpoint_id = $1 AND status = 'pending'
+	`, id); err != nil {
+		return fmt.Errorf("cancel pending user webhook deliveries: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Enqueue 写入 outbox。ctx 携带 dbent.Tx 时加入该事务，与调用方的业务变更一起提交或回滚。
+func (r *userWebhookRepository) Enqueue(ctx context.Context, event service.UserWebhookEvent, payload []byte, endpointID int64) (int64, error) {
+	var exec sqlExecutor = r.db
+	if tx := dbent.TxFromContext(ctx); tx != nil {
+		exec = tx.Client()
+	}
+	return enqueueUserWebhookEvent(ctx, exec, event, payload, endpointID)
+}
+
+// enqueueUserWebhookOutbox 在调用方事务内写入预先序列化好的事件（计费等直接使用 *sql.Tx 的路径）。
+func enqueueUserWebhookOutbox(ctx context.Context, exec sqlExecutor, events []service.UserWebhookOutboxEvent) error {
+	for _, e := range events {
+		if _, err := enqueueUserWebhookEvent(ctx, exec, e.Event, e.Payload, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func enqueueUserWebhookEvent(ctx context.Context, exec sqlExecutor, event service.UserWebhookEvent, payload []byte, endpointID int64) (int64, error) {
+	var (
+		result sql.Result
+		err    error
+	)
+	if endpointID > 0 {
+		result, err = exec.ExecContext(ctx, `
+			INSERT INTO user_webhook_deliveries (endpoint_id, user_id, event_id, event_type, payload)
+			SELECT e.id, e.user_id, $3, $4, $5::jsonb
+			FROM user_webhook_endpoints e
+			WHERE e.id = $1 AND e.user_id = $2 AND e.deleted_at IS NULL
+			ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		`, endpointID, event.UserID, event.ID, event.Type, string(payload))
+	} else {
+		result, err = exec.ExecContext(ctx, `
+			INSERT INTO user_webhook_deliveries (endpoint_id, user_id, event_id, event_type, payload)
+			SELECT e.id, e.user_id, $2, $3, $4::jsonb
+			FROM user_webhook_endpoints e
+			WHERE e.user_id = $1 AND e.enabled AND e.deleted_at IS NULL AND $3 = ANY(e.events)
+			ON CONFLICT (endpoint_id, event_id) DO NOTHING
+		`, event.UserID, event.ID, event.Type, string(payload))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("enqueue user webhook event: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (r *userWebhookRepository) ListDeliveries(ctx context.Context, userID int64, filter service.UserWebhookDeliveryFilter, params pagination.PaginationParams) ([]service.UserWebhookDelivery, *pagination.PaginationResult, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+	if filter.EndpointID > 0 {
+		args = append(args, filter.EndpointID)
+		conditions = append(conditions, fmt.Sprintf("endpoint_id = $%d", len(args)))
+	}
+	if v := strings.TrimSpace(filter.EventType); v != "" {
+		args = append(args, v)
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if v := strings.TrimSpace(filter.Status); v != "" {
+		args = append(args, v)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_webhook_deliveries WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("count user webhook deliveries: %w", err)
+	}
+
+	args = append(args, params.Limit(), params.Offset())
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM user_webhook_deliveries AS d
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, userWebhookDeliveryColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list user webhook deliveries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.UserWebhookDelivery, 0, params.Limit())
+	for rows.Next() {
+		d, err := scanUserWebhookDelivery(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *userWebhookRepository) GetDelivery(ctx context.Context, userID, id int64) (*service.UserWebhookDelivery, error) {
+	d, err := scanUserWebhookDelivery(r.db.QueryRowContext(ctx, `
+		SELECT `+userWebhookDeliveryColumns+`
+		FROM user_webhook_deliveries AS d
+		WHERE d.id = $1 AND d.user_id = $2
+	`, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrUserWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+// RequeueDelivery 重置尝试次数后重新排队；仍在 pending 的投递本就在队列中，保持原样。
+func (r *userWebhookRepository) RequeueDelivery(ctx context.Context, userID, id int64) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		WITH target AS (
+			SELECT d.id, d.status
+			FROM user_webhook_deliveries AS d
+			JOIN user_webhook_endpoints AS e ON e.id = d.endpoint_id
+			WHERE d.id = $1 AND d.user_id = $2 AND e.deleted_at IS NULL
+		), requeued AS (
+			UPDATE user_webhook_deliveries AS d
+			SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL,
+				claimed_at = NULL, claimed_by = NULL, updated_at = NOW()
+			FROM target
+			WHERE d.id = target.id AND target.status <> 'pending'
+		)
+		SELECT EXISTS (SELECT 1 FROM target)
+	`, id, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("requeue user webhook delivery: %w", err)
+	}
+	if !exists {
+		return service.ErrUserWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *userWebhookRepository) ClaimDeliveries(ctx context.Context, workerID string, limit int, lease time.Duration) ([]service.UserWebhookDeliveryTask, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("nil user webhook database")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	leaseSeconds := int64(lease / time.Second)
+	if leaseSeconds < 1 {
+		leaseSeconds = 60
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		WITH candidates AS (
+			SELECT id
+			FROM user_webhook_deliveries
+			WHERE status = 'pending'
+			  AND next_attempt_at <= NOW()
+			  AND (claimed_at IS NULL OR claimed_at < NOW() - ($3 * INTERVAL '1 second'))
+			ORDER BY next_attempt_at ASC, id ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE user_webhook_deliveries AS d
+			SET claimed_at = NOW(), claimed_by = $1
+			FROM candidates AS c
+			WHERE d.id = c.id
+			RETURNING d.*
+		)
+		SELECT `+userWebhookDeliveryColumnsFor("claimed")+`, e.url, e.secret_encrypted
+		FROM claimed
+		JOIN user_webhook_endpoints AS e ON e.id = claimed.endpoint_id
+	`, workerID, limit, leaseSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	tasks := make([]service.UserWebhookDeliveryTask, 0, limit)
+	for rows.Next() {
+		var task service.UserWebhookDeliveryTask
+		d, err := scanUserWebhookDelivery(rows, &task.URL, &task.SecretEncrypted)
+		if err != nil {
+			return nil, err
+		}
+		task.UserWebhookDelivery = *d
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func userWebhookDeliveryColumnsFor(alias string) string {
+	return fmt.Sprintf(`%[1]s.id, %[1]s.endpoint_id, %[1]s.user_id, %[1]s.event_id, %[1]s.event_type, %[1]s.payload,
+		%[1]s.status, %[1]s.attempts, %[1]s.next_attempt_at, %[1]s.last_response_status,
+		COALESCE(%[1]s.last_response_body, ''), COALESCE(%[1]s.last_error, ''), %[1]s.last_duration_ms,
+		%[1]s.created_at, %[1]s.updated_at, %[1]s.delivered_at`, alias)
+}
+
+func (r *userWebhookRepository) CompleteDelivery(ctx context.Context, id int64, workerID string, result service.UserWebhookAttemptResult) error {
+	return r.finishAttempt(ctx, id, workerID, `status = 'succeeded', delivered_at = NOW()`, nil, result)
+}
+
+func (r *userWebhookRepository) RetryDelivery(ctx context.Context, id int64, workerID string, nextAttemptAt time.Time, result service.UserWebhookAttemptResult) error {
+	return r.finishAttempt(ctx, id, workerID, `next_attempt_at = $7`, []any{nextAttemptAt}, result)
+}
+
+func (r *userWebhookRepository) FailDelivery(ctx context.Context, id int64, workerID string, result service.UserWebhookAttemptResult) error {
+	return r.finishAttempt(ctx, id, workerID, `status = 'failed'`, nil, result)
+}
+
+func (r *userWebhookRepository) finishAttempt(ctx context.Context, id int64, workerID, set string, extra []any, result service.UserWebhookAttemptResult) error {
+	var responseStatus any
+	if result.ResponseStatus != nil {
+		responseStatus = *result.ResponseStatus
+	}
+	args := append([]any{id, workerID, responseStatus, nullIfEmpty(result.ResponseBody), nullIfEmpty(result.Error), result.DurationMs}, extra...)
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE user_webhook_deliveries
+		SET `+set+`,
+			attempts = attempts + 1,
+			last_response_status = $3,
+			last_response_body = $4,
+			last_error = $5,
+			last_duration_ms = $6,
+			claimed_at = NULL,
+			claimed_by = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND claimed_by = $2 AND status = 'pending'
+	`, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return fmt.Errorf("user webhook delivery %d is no longer owned by %s", id, workerID)
+	}
+	return nil
+}
+
+func (r *userWebhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	if limit <= 0 {
+		limit = 5000
+	}
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_webhook_deliveries
+		WHERE id IN (
+			SELECT id FROM user_webhook_deliveries
+			WHERE created_at < $1 AND status <> 'pending'
+			ORDER BY created_at ASC
+			LIMIT $2
+		)
+	`, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete user webhook deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
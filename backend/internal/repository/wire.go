@@ -70,6 +70,9 @@ var ProviderSet = wire.NewSet(
 	NewGroupRepository,
 	NewAdminGroupRepository,
 	NewCompositeModelRouteRepository,
+	NewCredentialCipher, // 账号凭证静态加密
+	NewCredentialEncryptionRepository,
+	NewUsageLogMoneyBackfillRepository, // 历史 usage_logs 金额回填
 	NewAccountRepository,
 	NewAdminAccountRepository,
 	NewScheduledTestPlanRepository,   // 定时测试计划仓储
@@ -124,6 +127,7 @@ var ProviderSet = wire.NewSet(
 	NewRedeemCache,
 	NewUpdateCache,
 	NewGeminiTokenCache,
+	NewSAMLAssertionReplayCache,
 	NewImageTaskStore,
 	NewBatchImageQueue,
 	NewBatchImageDownloadLimiter,
@@ -131,12 +135,24 @@ var ProviderSet = wire.NewSet(
 	ProvideSchedulerCache,
 	NewSchedulerOutboxRepository,
 	NewAuthCacheInvalidationOutboxRepository,
+	NewUserWebhookRepository,
+	NewOrganizationRepository,
+	NewBudgetRepository,
+	NewBalanceLedgerRepository,
+	NewInvoiceRepository,
+	NewUsageExportRepository,
+	NewConfigReloadNotifier,
+	NewAdminRBACRepository,
+	NewAdminRBACNotifier,
+	NewAdminAPITokenRepository,
+	NewOpenAIBatchRepository,
 	NewProxyLatencyCache,
 	NewTotpCache,
 	NewRefreshTokenCache,
 	NewErrorPassthroughCache,
 	NewTLSFingerprintProfileCache,
 	NewContentModerationHashCache,
+	NewResponseCacheStore,
 
 	// Encryptors
 	NewAESEncryptor,
@@ -70,6 +70,7 @@ func TestAPIContracts(t *testing.T) {
 					"oidc_bound": false,
 					"wechat_bound": false,
 					"dingtalk_bound": false,
+					"saml_bound": false,
 					"identities": {
 						"email": {
 							"provider": "email",
@@ -114,6 +115,14 @@ func TestAPIContracts(t *testing.T) {
 							"can_bind": true,
 							"can_unbind": false,
 							"bind_start_path": "/api/v1/auth/oauth/dingtalk/bind/start?intent=bind_current_user&redirect=%2Fsettings%2Fprofile"
+						},
+						"saml": {
+							"provider": "saml",
+							"bound": false,
+							"bound_count": 0,
+							"can_bind": true,
+							"can_unbind": false,
+							"bind_start_path": "/api/v1/auth/oauth/saml/bind/start?intent=bind_current_user&redirect=%2Fsettings%2Fprofile"
 						}
 					},
 					"identity_bindings": {
@@ -160,6 +169,14 @@ func TestAPIContracts(t *testing.T) {
 							"can_bind": true,
 							"can_unbind": false,
 							"bind_start_path": "/api/v1/auth/oauth/dingtalk/bind/start?intent=bind_current_user&redirect=%2Fsettings%2Fprofile"
+						},
+						"saml": {
+							"provider": "saml",
+							"bound": false,
+							"bound_count": 0,
+							"can_bind": true,
+							"can_unbind": false,
+							"bind_start_path": "/api/v1/auth/oauth/saml/bind/start?intent=bind_current_user&redirect=%2Fsettings%2Fprofile"
 						}
 					},
 					"auth_bindings": {
@@ -206,6 +223,14 @@ func TestAPIContracts(t *testing.T) {
 							"can_bind": true,
 							"can_unbind": false,
 							"bind_start_path": "/api/v1/auth/oauth/dingtalk/bind/start?intent=bind_current_user&redirect=%2Fsettings%2Fprofile"
+						},
+						"saml": {
+							"provider": "saml",
+							"bound": false,
+							"bound_count": 0,
+							"can_bind": true,
+							"can_unbind": false,
+							"bind_start_path": "/api/v1/auth/oauth/saml/bind/start?intent=bind_current_user&redirect=%2Fsettings%2Fprofile"
 						}
 					},
 					"run_mode": "standard"
@@ -233,6 +258,9 @@ func TestAPIContracts(t *testing.T) {
 					"status": "active",
 					"ip_whitelist": null,
 					"ip_blacklist": null,
+					"model_allowlist": null,
+					"model_denylist": null,
+					"model_aliases": null,
 					"last_used_at": null,
 					"last_used_ip": null,
 					"current_concurrency": 0,
@@ -241,6 +269,7 @@ func TestAPIContracts(t *testing.T) {
 					"rate_limit_5h": 0,
 					"rate_limit_1d": 0,
 					"rate_limit_7d": 0,
+					"response_cache_enabled": false,
 					"usage_5h": 0,
 					"usage_1d": 0,
 					"usage_7d": 0,
@@ -284,6 +313,9 @@ func TestAPIContracts(t *testing.T) {
 							"status": "active",
 							"ip_whitelist": null,
 							"ip_blacklist": null,
+							"model_allowlist": null,
+							"model_denylist": null,
+							"model_aliases": null,
 							"last_used_at": null,
 							"last_used_ip": null,
 							"current_concurrency": 0,
@@ -292,6 +324,7 @@ func TestAPIContracts(t *testing.T) {
 							"rate_limit_5h": 0,
 							"rate_limit_1d": 0,
 							"rate_limit_7d": 0,
+							"response_cache_enabled": false,
 							"usage_5h": 0,
 							"usage_1d": 0,
 							"usage_7d": 0,
@@ -372,12 +405,16 @@ func TestAPIContracts(t *testing.T) {
 						"video_price_720p": null,
 						"video_price_1080p": null,
 						"web_search_price_per_call": null,
+						"web_fetch_price_per_call": null,
 						"search_price_per_1k": null,
 						"audio_tts_price_per_million_chars": null,
 						"audio_stt_price_per_hour": null,
 						"audio_realtime_price_per_min": null,
 						"allow_image_generation": false,
 						"allow_batch_image_generation": false,
+						"allow_batch_api": false,
+						"batch_api_discount_multiplier": 0,
+						"batch_api_hold_multiplier": 0,
 						"batch_image_discount_multiplier": 0,
 						"batch_image_hold_multiplier": 0,
 						"image_rate_independent": false,
@@ -391,6 +428,10 @@ func TestAPIContracts(t *testing.T) {
 						"fallback_group_id_on_invalid_request": null,
 						"require_oauth_only": false,
 						"require_privacy_set": false,
+						"response_cache_enabled": false,
+						"response_cache_price_multiplier": 0,
+						"scheduling_strategy": "",
+						"response_cache_ttl_seconds": 0,
 						"max_reasoning_effort": "",
 						"reasoning_effort_mappings": null,
 						"rpm_limit": 0,
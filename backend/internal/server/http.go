@@ -12,6 +12,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/websearch"
 	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/server/routes"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -42,6 +43,8 @@ func ProvideRouter(
 	settingService *service.SettingService,
 	compositeResolver *service.CompositeRouteResolver,
 	redisClient *redis.Client,
+	metricsService *service.PrometheusMetricsService,
+	openAIBatchWorker *service.OpenAIBatchWorker,
 ) *gin.Engine {
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -59,15 +62,18 @@ func ProvideRouter(
 		}
 		configs := make([]websearch.ProviderConfig, 0, len(cfg.Providers))
 		for _, p := range cfg.Providers {
-			if p.APIKey == "" {
-				continue
-			}
 			pc := websearch.ProviderConfig{
 				Type:       p.Type,
 				APIKey:     p.APIKey,
+				BaseURL:    p.BaseURL,
+				EngineID:   p.EngineID,
+				Priority:   p.Priority,
 				QuotaLimit: derefInt64(p.QuotaLimit),
 				ExpiresAt:  p.ExpiresAt,
 			}
+			if !pc.HasCredentials() {
+				continue
+			}
 			if p.SubscribedAt != nil {
 				pc.SubscribedAt = p.SubscribedAt
 			}
@@ -84,10 +90,16 @@ func ProvideRouter(
 			}
 			configs = append(configs, pc)
 		}
-		service.SetWebSearchManager(websearch.NewManager(configs, redisClient))
+		mgr := websearch.NewManager(configs, redisClient)
+		mgr.SetPageFetch(cfg.PageFetchOptions())
+		service.SetWebSearchManager(mgr)
 	})
 
-	return SetupRouter(r, handlers, jwtAuth, optionalJWTAuth, adminAuth, apiKeyAuth, auditLog, stepUpAuth, apiKeyService, subscriptionService, opsService, settingService, compositeResolver, cfg, redisClient)
+	engine := SetupRouter(r, handlers, jwtAuth, optionalJWTAuth, adminAuth, apiKeyAuth, auditLog, stepUpAuth, apiKeyService, subscriptionService, opsService, settingService, compositeResolver, cfg, redisClient)
+	routes.RegisterMetricsRoutes(engine, metricsService.Handler())
+	// Batch API worker 通过进程内回放网关路由执行每一行请求。
+	openAIBatchWorker.SetHandler(engine)
+	return engine
 }
 
 func configureTrustedProxies(r *gin.Engine, cfg config.ServerConfig) {
@@ -124,14 +136,10 @@ func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
 		// 不设置 ReadTimeout，因为大请求体可能需要较长时间读取
 	}
 
-	globalMaxSize := cfg.Server.MaxRequestBodySize
-	if globalMaxSize <= 0 {
-		globalMaxSize = cfg.Gateway.MaxBodySize
-	}
-	if globalMaxSize > 0 {
-		httpHandler = http.MaxBytesHandler(httpHandler, globalMaxSize)
+	if globalMaxSize := globalMaxRequestBodySize(cfg); globalMaxSize > 0 {
 		log.Printf("Global max request body size: %d bytes (%.2f MB)", globalMaxSize, float64(globalMaxSize)/(1<<20))
 	}
+	httpHandler = globalBodyLimitHandler(httpHandler, cfg)
 
 	// 根据配置决定是否启用 H2C
 	if cfg.Server.H2C.Enabled {
@@ -163,6 +171,27 @@ func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
 	return server
 }
 
+// globalMaxRequestBodySize 返回全局请求体上限：优先 server.max_request_body_size，
+// 未配置时回退 gateway.max_body_size。
+func globalMaxRequestBodySize(cfg *config.Config) int64 {
+	if cfg.Live().Server.MaxRequestBodySize > 0 {
+		return cfg.Live().Server.MaxRequestBodySize
+	}
+	return cfg.Live().Gateway.MaxBodySize
+}
+
+// globalBodyLimitHandler 按请求读取全局上限后套用 http.MaxBytesHandler，
+// 使配置热重载后的新上限无需重启即可生效。
+func globalBodyLimitHandler(next http.Handler, cfg *config.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxSize := globalMaxRequestBodySize(cfg); maxSize > 0 {
+			http.MaxBytesHandler(next, maxSize).ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func derefInt64(p *int64) int64 {
 	if p == nil {
 		return 0
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -17,19 +20,29 @@ func NewAdminAuthMiddleware(
 	userService *service.UserService,
 	settingService *service.SettingService,
 	auditService *service.AuditLogService,
+	rbacService *service.AdminRBACService,
+	tokenService *service.AdminAPITokenService,
+	cfg *config.Config,
 ) AdminAuthMiddleware {
-	return AdminAuthMiddleware(adminAuth(authService, userService, settingService, auditService))
+	return AdminAuthMiddleware(adminAuth(authService, userService, settingService, auditService, rbacService, tokenService, cfg))
 }
 
 // adminAuth 管理员认证中间件实现
-// 支持两种认证方式（通过不同的 header 区分）：
+// 支持三种认证方式（通过不同的 header 区分）：
 // 1. Admin API Key: x-api-key: <admin-api-key>
-// 2. JWT Token: Authorization: Bearer <jwt-token> (需要管理员角色)
+// 2. Admin API Token: X-Admin-Token: admtok_... 或 Authorization: Bearer admtok_...
+// 3. JWT Token: Authorization: Bearer <jwt-token> (需要管理员角色或员工角色)
+//
+// 认证通过后写入管理面权限：管理员与 Admin API Key 拥有全部权限，
+// 员工与 Admin API Token 仅拥有所绑定角色 / Token 的 scope，由各路由分组上的 RequireAdminPermission 校验。
 func adminAuth(
 	authService *service.AuthService,
 	userService *service.UserService,
 	settingService *service.SettingService,
 	auditService *service.AuditLogService,
+	rbacService *service.AdminRBACService,
+	tokenService *service.AdminAPITokenService,
+	cfg *config.Config,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// WebSocket upgrade requests cannot set Authorization headers in browsers.
@@ -38,7 +51,7 @@ func adminAuth(
 		//   Sec-WebSocket-Protocol: sub2api-admin, jwt.<token>
 		if isWebSocketUpgradeRequest(c) {
 			if token := extractJWTFromWebSocketSubprotocol(c); token != "" {
-				if !validateJWTForAdmin(c, token, authService, userService, settingService, auditService) {
+				if !validateJWTForAdmin(c, token, authService, userService, settingService, auditService, rbacService) {
 					return
 				}
 				c.Next()
@@ -56,7 +69,16 @@ func adminAuth(
 			return
 		}
 
-		// 检查 Authorization header（JWT 认证）
+		// 检查 X-Admin-Token header（Admin API Token 认证）
+		if token := strings.TrimSpace(c.GetHeader(AdminAPITokenHeader)); token != "" {
+			if !validateAdminAPIToken(c, token, tokenService, cfg) {
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// 检查 Authorization header（JWT 或 Admin API Token 认证）
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
 			parts := strings.SplitN(authHeader, " ", 2)
@@ -66,7 +88,14 @@ func adminAuth(
 					AbortWithError(c, 401, "UNAUTHORIZED", "Authorization required")
 					return
 				}
-				if !validateJWTForAdmin(c, token, authService, userService, settingService, auditService) {
+				if strings.HasPrefix(token, service.AdminAPITokenPrefix) {
+					if !validateAdminAPIToken(c, token, tokenService, cfg) {
+						return
+					}
+					c.Next()
+					return
+				}
+				if !validateJWTForAdmin(c, token, authService, userService, settingService, auditService, rbacService) {
 					return
 				}
 				c.Next()
@@ -150,10 +179,42 @@ func validateAdminAPIKey(
 	c.Set(string(ContextKeyUserRole), admin.Role)
 	c.Set(ContextKeyAuthEmail, admin.Email)
 	c.Set("auth_method", "admin_api_key")
+	setAdminPermissions(c, service.SuperAdminPermissions())
 	return true
 }
 
-// validateJWTForAdmin 验证 JWT 并检查管理员权限
+// validateAdminAPIToken 验证管理面 API Token，以 Token 创建者身份执行，权限仅限 Token 的 scope。
+// Token 的 IP 白名单与 API Key ACL 相同，按安全口径解析客户端 IP，不信任伪造的转发头。
+func validateAdminAPIToken(c *gin.Context, plaintext string, tokenService *service.AdminAPITokenService, cfg *config.Config) bool {
+	if tokenService == nil {
+		AbortWithError(c, 401, "INVALID_ADMIN_TOKEN", "Invalid admin API token")
+		return false
+	}
+	auth, err := tokenService.Authenticate(c.Request.Context(), plaintext, ip.GetSecurityClientIP(c, cfg.ForwardedClientIPTrustEnabled()))
+	if err != nil {
+		status := infraerrors.Code(err)
+		if status >= 500 {
+			AbortWithError(c, 500, "INTERNAL_ERROR", "Internal server error")
+			return false
+		}
+		AbortWithError(c, status, infraerrors.Reason(err), infraerrors.Message(err))
+		return false
+	}
+
+	c.Set(string(ContextKeyUser), AuthSubject{
+		UserID:      auth.Owner.ID,
+		Concurrency: auth.Owner.Concurrency,
+	})
+	c.Set(string(ContextKeyUserRole), auth.Owner.Role)
+	c.Set(ContextKeyAuthEmail, auth.Owner.Email)
+	c.Set("auth_method", service.AuditAuthMethodAdminAPIToken)
+	c.Set(contextKeyAdminTokenStepUp, auth.StepUp)
+	setAdminPermissions(c, auth.Permissions)
+	SetAuditExtra(c, map[string]any{"admin_token_id": auth.Token.ID, "admin_token_name": auth.Token.Name})
+	return true
+}
+
+// validateJWTForAdmin 验证 JWT 并检查管理员（或员工角色）权限
 func validateJWTForAdmin(
 	c *gin.Context,
 	token string,
@@ -161,6 +222,7 @@ func validateJWTForAdmin(
 	userService *service.UserService,
 	settingService *service.SettingService,
 	auditService *service.AuditLogService,
+	rbacService *service.AdminRBACService,
 ) bool {
 	// 验证 JWT token
 	claims, err := authService.ValidateToken(token)
@@ -197,8 +259,13 @@ func validateJWTForAdmin(
 		return false
 	}
 
-	// 检查管理员权限
-	if !user.IsAdmin() {
+	// 检查管理员权限：完整管理员或绑定了员工角色的用户
+	perms, err := rbacService.ResolvePermissions(c.Request.Context(), user)
+	if err != nil {
+		AbortWithError(c, 500, "INTERNAL_ERROR", "Internal server error")
+		return false
+	}
+	if perms == nil {
 		AbortWithError(c, 403, "FORBIDDEN", "Admin access required")
 		return false
 	}
@@ -211,6 +278,7 @@ func validateJWTForAdmin(
 	c.Set(ContextKeyAuthEmail, user.Email)
 	c.Set(ContextKeySessionID, claims.SessionID)
 	c.Set("auth_method", "jwt")
+	setAdminPermissions(c, perms)
 
 	return true
 }
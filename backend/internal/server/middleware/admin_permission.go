@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 管理面权限相关 gin context 键（由管理员认证中间件写入）。
+const (
+	// ContextKeyAdminPermissions 当前请求的有效管理面权限（*service.AdminPermissions）。
+	ContextKeyAdminPermissions = "admin_permissions"
+	// ContextKeyAdminRole 员工角色名；完整管理员为空（审计用）。
+	ContextKeyAdminRole = "admin_role"
+	// AdminAPITokenHeader 管理面 API Token 请求头。
+	AdminAPITokenHeader = "X-Admin-Token"
+
+	// contextKeyAdminTokenStepUp Admin API Token 允许绕过 step-up 的权限（*service.AdminPermissions）。
+	contextKeyAdminTokenStepUp = "admin_token_step_up"
+	// contextKeyAdminRequiredScope RequireAdminPermission 校验通过的 resource 与读写类型（adminRequiredScope）。
+	contextKeyAdminRequiredScope = "admin_required_scope"
+)
+
+type adminRequiredScope struct {
+	resource string
+	write    bool
+}
+
+// setAdminPermissions 写入管理面权限与员工角色名。
+func setAdminPermissions(c *gin.Context, perms *service.AdminPermissions) {
+	c.Set(ContextKeyAdminPermissions, perms)
+	c.Set(ContextKeyAdminRole, perms.Role)
+}
+
+// GetAdminPermissionsFromContext 读取管理员认证中间件写入的权限。
+func GetAdminPermissionsFromContext(c *gin.Context) (*service.AdminPermissions, bool) {
+	value, exists := c.Get(ContextKeyAdminPermissions)
+	if !exists {
+		return nil, false
+	}
+	perms, ok := value.(*service.AdminPermissions)
+	return perms, ok && perms != nil
+}
+
+// IsSuperAdminRequest 当前请求是否由完整管理员发起（员工角色返回 false）。
+func IsSuperAdminRequest(c *gin.Context) bool {
+	perms, ok := GetAdminPermissionsFromContext(c)
+	return ok && perms.IsSuper()
+}
+
+// IsStaffAdminRequest 当前请求是否由员工角色（非完整管理员）发起。
+func IsStaffAdminRequest(c *gin.Context) bool {
+	perms, ok := GetAdminPermissionsFromContext(c)
+	return ok && !perms.IsSuper()
+}
+
+// RequireAdminPermission 按路由分组校验员工权限：GET/HEAD/OPTIONS 需要 <resource>:read，
+// 其余方法需要 <resource>:write。必须挂在管理员认证中间件之后；上下文中没有权限信息时一律拒绝。
+func RequireAdminPermission(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		write := true
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			write = false
+		}
+		perms, ok := GetAdminPermissionsFromContext(c)
+		if !perms.IsSuper() {
+			SetAuditExtra(c, map[string]any{"permission": service.AdminScope(resource, write)})
+		}
+		if !ok || !perms.Allows(resource, write) {
+			AbortWithError(c, http.StatusForbidden, "ADMIN_PERMISSION_DENIED", "Missing admin permission "+service.AdminScope(resource, write))
+			return
+		}
+		c.Set(contextKeyAdminRequiredScope, adminRequiredScope{resource: resource, write: write})
+		c.Next()
+	}
+}
+
+// adminTokenStepUpAllowed Admin API Token 是否被显式允许在当前路由绕过 step-up：
+// 路由必须挂有 RequireAdminPermission，且所需 scope 在 Token 的 step_up_scopes 内。
+func adminTokenStepUpAllowed(c *gin.Context) bool {
+	value, ok := c.Get(contextKeyAdminTokenStepUp)
+	if !ok {
+		return false
+	}
+	stepUp, _ := value.(*service.AdminPermissions)
+	required, ok := c.Get(contextKeyAdminRequiredScope)
+	if !ok {
+		return false
+	}
+	scope, _ := required.(adminRequiredScope)
+	return scope.resource != "" && stepUp.Allows(scope.resource, scope.write)
+}
+
+// RequireSuperAdmin 仅允许完整管理员访问（如角色管理本身）。
+func RequireSuperAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsSuperAdminRequest(c) {
+			AbortWithError(c, http.StatusForbidden, "SUPER_ADMIN_REQUIRED", "This operation requires a full administrator")
+			return
+		}
+		c.Next()
+	}
+}
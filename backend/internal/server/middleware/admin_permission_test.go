@@ -0,0 +1,276 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAdminRBACRepo struct {
+	service.AdminRBACRepository
+	roles map[int64]*service.AdminRole
+}
+
+func (r *stubAdminRBACRepo) GetUserRole(_ context.Context, userID int64) (*service.AdminRole, error) {
+	return r.roles[userID], nil
+}
+
+func TestRequireAdminPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(perms *service.AdminPermissions) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if perms != nil {
+				setAdminPermissions(c, perms)
+			}
+			c.Next()
+		})
+		users := router.Group("/users", RequireAdminPermission(service.AdminResourceUsers))
+		users.GET("", func(c *gin.Context) { c.Status(http.StatusOK) })
+		users.POST("", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return router
+	}
+	serve := func(router *gin.Engine, method string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/users", nil))
+		return w
+	}
+
+	support := newRouter(service.NewAdminPermissions("support", []string{"users:read"}))
+	require.Equal(t, http.StatusOK, serve(support, http.MethodGet).Code)
+	w := serve(support, http.MethodPost)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "users:write")
+
+	super := newRouter(service.SuperAdminPermissions())
+	require.Equal(t, http.StatusOK, serve(super, http.MethodPost).Code)
+
+	// 上下文中没有权限信息（未经过管理员认证）一律拒绝
+	anonymous := newRouter(nil)
+	require.Equal(t, http.StatusForbidden, serve(anonymous, http.MethodGet).Code)
+}
+
+func TestAdminAuthJWTGrantsStaffRolePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", ExpireHour: 1}}
+	authService := service.NewAuthService(nil, nil, nil, nil, cfg, nil, nil, nil, nil, nil, nil, nil, nil)
+	users := map[int64]*service.User{
+		10: {ID: 10, Email: "support@example.com", Role: service.RoleUser, Status: service.StatusActive, Concurrency: 1},
+		11: {ID: 11, Email: "plain@example.com", Role: service.RoleUser, Status: service.StatusActive, Concurrency: 1},
+	}
+	userService := service.NewUserService(&stubUserRepo{
+		getByID: func(_ context.Context, id int64) (*service.User, error) {
+			u, ok := users[id]
+			if !ok {
+				return nil, service.ErrUserNotFound
+			}
+			clone := *u
+			return &clone, nil
+		},
+	}, nil, nil, nil)
+	rbacService := service.NewAdminRBACService(&stubAdminRBACRepo{roles: map[int64]*service.AdminRole{
+		10: {ID: 1, Name: "support", Permissions: []string{"users:read"}},
+	}}, nil, nil)
+
+	auditRepo := &auditCaptureRepository{}
+	auditService := service.NewAuditLogService(auditRepo, nil)
+	auditService.Start()
+
+	router := gin.New()
+	admin := router.Group("/api/v1/admin")
+	admin.Use(gin.HandlerFunc(NewAdminAuthMiddleware(authService, userService, nil, nil, rbacService, nil, nil)))
+	admin.Use(gin.HandlerFunc(NewAuditLogMiddleware(auditService)))
+	scoped := admin.Group("", RequireAdminPermission(service.AdminResourceUsers))
+	scoped.GET("/users", func(c *gin.Context) {
+		perms, _ := GetAdminPermissionsFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"role": c.GetString(ContextKeyAdminRole), "scopes": perms.Scopes()})
+	})
+	scoped.PUT("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(userID int64, method, path string) *httptest.ResponseRecorder {
+		u := users[userID]
+		token, err := authService.GenerateToken(context.Background(), u)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(10, http.MethodGet, "/api/v1/admin/users")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Role   string   `json:"role"`
+		Scopes []string `json:"scopes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "support", body.Role)
+	require.Equal(t, []string{"users:read"}, body.Scopes)
+
+	require.Equal(t, http.StatusForbidden, serve(10, http.MethodPut, "/api/v1/admin/users/3").Code)
+
+	w = serve(11, http.MethodGet, "/api/v1/admin/users")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "Admin access required")
+
+	auditService.Stop()
+	auditRepo.mu.Lock()
+	defer auditRepo.mu.Unlock()
+	require.Len(t, auditRepo.logs, 1)
+	entry := auditRepo.logs[0]
+	require.Equal(t, "support", entry.AdminRole)
+	require.Equal(t, service.RoleUser, entry.ActorRole)
+	require.Equal(t, "users:write", entry.Extra["permission"])
+	require.Equal(t, http.StatusForbidden, entry.StatusCode)
+	require.WithinDuration(t, time.Now(), entry.CreatedAt, time.Minute)
+}
+
+type stubAdminAPITokenRepo struct {
+	service.AdminAPITokenRepository
+	tokens map[string]*service.AdminAPIToken
+}
+
+func (r *stubAdminAPITokenRepo) Create(_ context.Context, token *service.AdminAPIToken, tokenHash string) error {
+	token.ID = int64(len(r.tokens) + 1)
+	clone := *token
+	r.tokens[tokenHash] = &clone
+	return nil
+}
+
+func (r *stubAdminAPITokenRepo) GetByHash(_ context.Context, tokenHash string) (*service.AdminAPIToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, service.ErrAdminAPITokenNotFound
+	}
+	clone := *token
+	return &clone, nil
+}
+
+func (r *stubAdminAPITokenRepo) TouchLastUsed(context.Context, int64, string, time.Time) error {
+	return nil
+}
+
+func TestAdminAuthAPITokenScopesAndStepUp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owner := &service.User{ID: 1, Email: "admin@example.com", Role: service.RoleAdmin, Status: service.StatusActive, Concurrency: 1}
+	userRepo := &stubUserRepo{getByID: func(context.Context, int64) (*service.User, error) {
+		clone := *owner
+		return &clone, nil
+	}}
+	tokenService := service.NewAdminAPITokenService(&stubAdminAPITokenRepo{tokens: map[string]*service.AdminAPIToken{}}, userRepo)
+	_, plain, err := tokenService.Create(context.Background(), service.CreateAdminAPITokenInput{
+		Name: "provisioning", Scopes: []string{"accounts:write", "users:write"},
+	}, owner.ID)
+	require.NoError(t, err)
+	_, bypass, err := tokenService.Create(context.Background(), service.CreateAdminAPITokenInput{
+		Name: "rotation", Scopes: []string{"accounts:write"}, StepUpScopes: []string{"accounts:write"},
+	}, owner.ID)
+	require.NoError(t, err)
+
+	auditRepo := &auditCaptureRepository{}
+	auditService := service.NewAuditLogService(auditRepo, nil)
+	auditService.Start()
+
+	// 即使 step-up 授权与 TOTP 检查全部失败，允许绕过的 Token 也应放行。
+	stepUp := gin.HandlerFunc(stepUpAuth(stubStepUpGrantChecker{granted: false}, stubStepUpUserReader{user: &service.User{ID: 1}}, stepUpEnabled))
+	router := gin.New()
+	admin := router.Group("/api/v1/admin")
+	admin.Use(gin.HandlerFunc(NewAdminAuthMiddleware(nil, nil, nil, nil, nil, tokenService, nil)))
+	admin.Use(gin.HandlerFunc(NewAuditLogMiddleware(auditService)))
+	accounts := admin.Group("", RequireAdminPermission(service.AdminResourceAccounts))
+	accounts.GET("/accounts", func(c *gin.Context) { c.Status(http.StatusOK) })
+	accounts.POST("/accounts/:id/credentials", stepUp, func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.Group("", RequireAdminPermission(service.AdminResourceSettings)).GET("/settings", func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.Group("/api-tokens", RequireSuperAdmin()).GET("", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header = header
+		router.ServeHTTP(w, req)
+		return w
+	}
+	viaHeader := http.Header{AdminAPITokenHeader: []string{plain}}
+	viaBearer := http.Header{"Authorization": []string{"Bearer " + plain}}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/admin/accounts", viaHeader).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/admin/accounts", viaBearer).Code)
+	require.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/v1/admin/settings", viaHeader).Code)
+	// Token 不是完整管理员，不能管理 Token 本身
+	require.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/v1/admin/api-tokens", viaHeader).Code)
+
+	w := serve(http.MethodPost, "/api/v1/admin/accounts/3/credentials", viaHeader)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "STEP_UP_ADMIN_TOKEN_FORBIDDEN")
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/admin/accounts/3/credentials", http.Header{AdminAPITokenHeader: []string{bypass}}).Code)
+
+	w = serve(http.MethodGet, "/api/v1/admin/accounts", http.Header{AdminAPITokenHeader: []string{service.AdminAPITokenPrefix + "unknown"}})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "INVALID_ADMIN_TOKEN")
+
+	auditService.Stop()
+	auditRepo.mu.Lock()
+	defer auditRepo.mu.Unlock()
+	require.Len(t, auditRepo.logs, 2)
+	for _, entry := range auditRepo.logs {
+		require.Equal(t, service.AuditAuthMethodAdminAPIToken, entry.AuthMethod)
+		require.Equal(t, owner.ID, *entry.ActorUserID)
+		require.NotContains(t, entry.CredentialMasked, plain)
+	}
+	require.Equal(t, int64(1), auditRepo.logs[0].Extra["admin_token_id"])
+	require.Equal(t, "rotation", auditRepo.logs[1].Extra["admin_token_name"])
+}
+
+// Token IP 白名单与 API Key ACL 同口径：未信任转发头时，伪造的 X-Forwarded-For 不能冒充白名单 IP。
+func TestAdminAuthAPITokenAllowlistIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owner := &service.User{ID: 1, Email: "admin@example.com", Role: service.RoleAdmin, Status: service.StatusActive, Concurrency: 1}
+	userRepo := &stubUserRepo{getByID: func(context.Context, int64) (*service.User, error) {
+		clone := *owner
+		return &clone, nil
+	}}
+	tokenService := service.NewAdminAPITokenService(&stubAdminAPITokenRepo{tokens: map[string]*service.AdminAPIToken{}}, userRepo)
+	_, plain, err := tokenService.Create(context.Background(), service.CreateAdminAPITokenInput{
+		Name: "ci", Scopes: []string{"accounts:read"}, AllowedIPs: []string{"1.2.3.4"},
+	}, owner.ID)
+	require.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.SetTrustForwardedIPForAPIKeyACL(false)
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	admin := router.Group("/api/v1/admin")
+	admin.Use(gin.HandlerFunc(NewAdminAuthMiddleware(nil, nil, nil, nil, nil, tokenService, cfg)))
+	admin.Group("", RequireAdminPermission(service.AdminResourceAccounts)).GET("/accounts", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/accounts", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(AdminAPITokenHeader, plain)
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		req.Header.Set("X-Real-IP", "1.2.3.4")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("5.6.7.8:12345")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "ADMIN_TOKEN_IP_FORBIDDEN")
+	require.Equal(t, http.StatusOK, serve("1.2.3.4:12345").Code)
+}
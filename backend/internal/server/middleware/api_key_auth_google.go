@@ -130,6 +130,12 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 403, "API Key 所属专属分组不再允许当前用户使用")
 			return
 		}
+		// Key 级模型策略：别名改写 + 允许/禁止列表
+		if !enforceAPIKeyModelPolicy(c, apiKey, func(status int, _, message string) {
+			abortWithGoogleError(c, status, message)
+		}) {
+			return
+		}
 
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
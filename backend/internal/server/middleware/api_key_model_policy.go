@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// apiKeyModelPolicyAbort 以各入口自己的错误格式中断请求。
+type apiKeyModelPolicyAbort func(status int, code, message string)
+
+// enforceAPIKeyModelPolicy 解析 Key 的模型别名并校验允许/禁止列表。
+//
+// 模型来源：Gemini 原生路由取路径参数（:model / *modelAction），其余取 JSON 请求体的
+// model（或 session.model）字段，multipart 请求取 model 表单字段。别名在此处改写为目标模型，
+// 后续的组合路由、渠道映射与计费都只看到目标模型。返回 false 表示请求已被中断。
+func enforceAPIKeyModelPolicy(c *gin.Context, apiKey *service.APIKey, abort apiKeyModelPolicyAbort) bool {
+	if !apiKey.HasModelPolicy() {
+		return true
+	}
+	if handled, ok := enforceAPIKeyModelPolicyOnPath(c, apiKey, abort); handled {
+		return ok
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		// WebSocket 升级请求同样没有请求体：模型位于查询参数或每一帧 response.create 中，
+		// 由对应的 WS handler 校验（见 handler.enforceOpenAIWSModelPolicy）。
+		return true
+	}
+
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			abort(http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body is too large")
+			return false
+		}
+		abort(http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+		return false
+	}
+	defer func() { setRequestBody(c, body) }()
+
+	if boundary, ok := multipartBoundary(c.Request.Header.Get("Content-Type")); ok {
+		model := multipartModelField(body, boundary)
+		if model == "" {
+			return true
+		}
+		if apiKey.ResolveModelAlias(model) != model {
+			abort(http.StatusBadRequest, "MODEL_ALIAS_UNSUPPORTED", "Model aliases are not supported for multipart requests; use the target model name")
+			return false
+		}
+		return checkAPIKeyModelAllowed(c, apiKey, model, abort)
+	}
+
+	model, path := jsonRequestModel(body)
+	if model == "" {
+		return true
+	}
+	if target := apiKey.ResolveModelAlias(model); target != model {
+		if rewritten, err := sjson.SetBytes(body, path, target); err == nil {
+			body = rewritten
+			model = target
+		}
+	}
+	return checkAPIKeyModelAllowed(c, apiKey, model, abort)
+}
+
+// enforceAPIKeyModelPolicyOnPath 处理模型位于路径参数中的 Gemini 原生路由。
+// handled 为 false 表示当前路由没有模型路径参数。
+func enforceAPIKeyModelPolicyOnPath(c *gin.Context, apiKey *service.APIKey, abort apiKeyModelPolicyAbort) (handled bool, ok bool) {
+	for i := range c.Params {
+		param := &c.Params[i]
+		switch param.Key {
+		case "model":
+			model := strings.TrimSpace(param.Value)
+			if model == "" {
+				return true, true
+			}
+			target := apiKey.ResolveModelAlias(model)
+			param.Value = target
+			return true, checkAPIKeyModelAllowed(c, apiKey, target, abort)
+		case "modelAction":
+			// 形如 /{model}:{action} 或 /{model}/{action}
+			rest := strings.TrimPrefix(param.Value, "/")
+			end := strings.IndexAny(rest, ":/")
+			if end <= 0 {
+				return true, true
+			}
+			model := rest[:end]
+			target := apiKey.ResolveModelAlias(model)
+			param.Value = "/" + target + rest[end:]
+			return true, checkAPIKeyModelAllowed(c, apiKey, target, abort)
+		}
+	}
+	return false, true
+}
+
+func checkAPIKeyModelAllowed(c *gin.Context, apiKey *service.APIKey, model string, abort apiKeyModelPolicyAbort) bool {
+	if apiKey.IsModelAllowed(model) {
+		return true
+	}
+	service.MarkOpsClientBusinessLimited(c, service.OpsClientBusinessLimitedReasonLocalPolicyDenied)
+	MarkIngressRejected(c, IngressRejectModelNotAllowed)
+	abort(http.StatusForbidden, "MODEL_NOT_ALLOWED", "Model "+strconv.Quote(model)+" is not allowed for this API key")
+	return false
+}
+
+// jsonRequestModel 返回请求体中的模型名及其 JSON 路径（与组合路由的取值规则一致）。
+func jsonRequestModel(body []byte) (string, string) {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return "", ""
+	}
+	for _, path := range []string{"model", "session.model"} {
+		model := gjson.GetBytes(body, path)
+		if model.Type != gjson.String {
+			continue
+		}
+		if value := strings.TrimSpace(model.String()); value != "" {
+			return value, path
+		}
+	}
+	return "", ""
+}
+
+func multipartBoundary(contentType string) (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return "", false
+	}
+	return params["boundary"], true
+}
+
+// multipartModelField 读取 multipart 请求体中的 model 字段，跳过文件部分。
+func multipartModelField(body []byte, boundary string) string {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return ""
+		}
+		if part.FormName() != "model" || part.FileName() != "" {
+			_ = part.Close()
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, 1024))
+		_ = part.Close()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(value))
+	}
+}
+
+func setRequestBody(c *gin.Context, body []byte) {
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
@@ -0,0 +1,141 @@
+//go:build unit
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newModelPolicyTestService(t *testing.T) (*service.APIKeyService, *config.Config) {
+	t.Helper()
+	user := &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 3}
+	apiKey := &service.APIKey{
+		ID:             100,
+		UserID:         user.ID,
+		Key:            "policy-key",
+		Status:         service.StatusActive,
+		User:           user,
+		ModelAllowlist: []string{"claude-haiku-*", "gemini-2.5-flash"},
+		ModelDenylist:  []string{"claude-haiku-3*"},
+		ModelAliases:   map[string]string{"fast": "claude-haiku-4-5", "flash": "gemini-2.5-flash"},
+	}
+	repo := &stubApiKeyRepo{getByKey: func(_ context.Context, key string) (*service.APIKey, error) {
+		if key != apiKey.Key {
+			return nil, service.ErrAPIKeyNotFound
+		}
+		clone := *apiKey
+		return &clone, nil
+	}}
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	return service.NewAPIKeyService(repo, nil, nil, nil, nil, nil, cfg), cfg
+}
+
+func TestAPIKeyAuthEnforcesModelPolicyOnJSONBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, cfg := newModelPolicyTestService(t)
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(svc, nil, cfg)))
+	var seenBody []byte
+	router.POST("/v1/messages", func(c *gin.Context) {
+		seenBody, _ = io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantModel string
+	}{
+		{name: "alias rewritten", body: `{"model":"fast","max_tokens":1}`, wantCode: http.StatusOK, wantModel: "claude-haiku-4-5"},
+		{name: "allowed by wildcard", body: `{"model":"claude-haiku-4-5-20251001"}`, wantCode: http.StatusOK, wantModel: "claude-haiku-4-5-20251001"},
+		{name: "denylist wins", body: `{"model":"claude-haiku-3-5"}`, wantCode: http.StatusForbidden},
+		{name: "outside allowlist", body: `{"model":"claude-opus-4-1"}`, wantCode: http.StatusForbidden},
+		{name: "no model passes through", body: `{"messages":[]}`, wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenBody = nil
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(tt.body))
+			req.Header.Set("x-api-key", "policy-key")
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode == http.StatusForbidden {
+				require.Contains(t, w.Body.String(), "MODEL_NOT_ALLOWED")
+				return
+			}
+			require.Equal(t, tt.wantModel, gjson.GetBytes(seenBody, "model").String())
+		})
+	}
+}
+
+func TestAPIKeyAuthModelPolicyMultipartRejectsAlias(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, cfg := newModelPolicyTestService(t)
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(svc, nil, cfg)))
+	router.POST("/v1/images/edits", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	send := func(model string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, err := mw.CreateFormFile("image", "a.png")
+		require.NoError(t, err)
+		_, _ = fw.Write([]byte("png"))
+		require.NoError(t, mw.WriteField("model", model))
+		require.NoError(t, mw.Close())
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/images/edits", &buf)
+		req.Header.Set("x-api-key", "policy-key")
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send("claude-haiku-4-5").Code)
+	require.Equal(t, http.StatusBadRequest, send("fast").Code)
+	require.Equal(t, http.StatusForbidden, send("gpt-image-1").Code)
+}
+
+func TestAPIKeyAuthGoogleModelPolicyRewritesPathModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, cfg := newModelPolicyTestService(t)
+	router := gin.New()
+	router.Use(APIKeyAuthWithSubscriptionGoogle(svc, nil, cfg))
+	router.POST("/v1beta/models/*modelAction", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"model_action": c.Param("modelAction")})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/flash:generateContent", strings.NewReader(`{}`))
+	req.Header.Set("x-goog-api-key", "policy-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "/gemini-2.5-flash:generateContent", gjson.Get(w.Body.String(), "model_action").String())
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", strings.NewReader(`{}`))
+	req.Header.Set("x-goog-api-key", "policy-key")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, "PERMISSION_DENIED", gjson.Get(w.Body.String(), "error.status").String())
+}
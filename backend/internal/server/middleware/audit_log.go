@@ -58,6 +58,7 @@ var auditExtraAllowedKeys = map[string]struct{}{
 	"http_status": {}, "latency_ms": {}, "token_applied": {}, "retryable": {},
 	"event_id": {}, "requested_count": {}, "deleted_events": {}, "deleted_jobs": {},
 	"matched_count": {}, "snapshot_max_id": {}, "filter_hash": {}, "confirm": {},
+	"permission": {}, "admin_token_id": {}, "admin_token_name": {},
 }
 
 // SetAuditExtra adds allowlisted, scalar details to the current audit entry.
@@ -252,6 +253,7 @@ func NewAuditLogMiddleware(auditService *service.AuditLogService) AuditLogMiddle
 			entry.ActorRole = role
 		}
 		entry.ActorEmail = c.GetString(ContextKeyAuthEmail)
+		entry.AdminRole = c.GetString(ContextKeyAdminRole)
 		entry.AuthMethod = c.GetString("auth_method")
 		if entry.AuthMethod == "" && entry.ActorUserID != nil {
 			entry.AuthMethod = service.AuditAuthMethodJWT
@@ -310,6 +312,9 @@ func MaskedRequestCredential(c *gin.Context) string {
 	if apiKey := strings.TrimSpace(c.GetHeader("x-api-key")); apiKey != "" {
 		return "x-api-key " + service.MaskAuditCredential(apiKey)
 	}
+	if token := strings.TrimSpace(c.GetHeader(AdminAPITokenHeader)); token != "" {
+		return AdminAPITokenHeader + " " + service.MaskAuditCredential(token)
+	}
 	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
 	if authHeader == "" {
 		return ""
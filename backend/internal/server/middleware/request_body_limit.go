@@ -8,8 +8,14 @@ import (
 
 // RequestBodyLimit 使用 MaxBytesReader 限制请求体大小。
 func RequestBodyLimit(maxBytes int64) gin.HandlerFunc {
+	return RequestBodyLimitFunc(func() int64 { return maxBytes })
+}
+
+// RequestBodyLimitFunc 与 RequestBodyLimit 相同，但每个请求重新读取上限，
+// 用于支持配置热重载。
+func RequestBodyLimitFunc(maxBytes func() int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes())
 		c.Next()
 	}
 }
@@ -40,7 +40,8 @@ func StepUpSessionKey(c *gin.Context, userID int64) string {
 //
 // 功能开关 step_up_enabled（默认关闭）关闭时中间件直接放行，行为与门控引入前一致。
 // 开启时的通过条件（全部满足）：
-//  1. 必须是 JWT 认证的真人会话——admin API key（机器凭证）一律拒绝
+//  1. 必须是 JWT 认证的真人会话——admin API key（机器凭证）一律拒绝；
+//     Admin API Token 仅在当前路由所需 scope 被显式列入其 step_up_scopes 时放行（跳过 2、3）
 //  2. 当前用户已启用 TOTP（未启用则拒绝并提示先启用 2FA）
 //  3. 当前会话在有效期内完成过 TOTP step-up 验证（POST /api/v1/user/totp/step-up）
 //
@@ -106,6 +107,14 @@ func enforceStepUp(c *gin.Context, grantChecker stepUpGrantChecker, userReader s
 			"Admin API key cannot access this endpoint; a two-factor verified admin session is required")
 		return false
 	}
+	if c.GetString("auth_method") == service.AuditAuthMethodAdminAPIToken {
+		if adminTokenStepUpAllowed(c) {
+			return true
+		}
+		AbortWithError(c, 403, "STEP_UP_ADMIN_TOKEN_FORBIDDEN",
+			"Admin API token is not allowed to bypass two-factor verification for this endpoint")
+		return false
+	}
 
 	subject, ok := GetAuthSubjectFromContext(c)
 	if !ok || subject.UserID <= 0 {
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Tracing starts the server span for a gateway request. A valid W3C
+// traceparent sent by the client makes the request a child of the caller's
+// trace; otherwise a new trace is started subject to tracing.sample_ratio.
+// The traceparent is never forwarded upstream.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !tracing.Enabled() || c.Request == nil {
+			c.Next()
+			return
+		}
+
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		route := c.FullPath()
+		if route == "" && c.Request.URL != nil {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		if !span.IsRecording() {
+			c.Next()
+			return
+		}
+		traceID := span.SpanContext().TraceID().String()
+		ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(zap.String("trace_id", traceID)))
+		c.Request = c.Request.WithContext(ctx)
+
+		defer func() {
+			status := c.Writer.Status()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, strconv.Itoa(status))
+			}
+			if errs := c.Errors.ByType(gin.ErrorTypeAny); len(errs) > 0 {
+				span.AddEvent("exception", trace.WithAttributes(attribute.String("exception.message", strings.TrimSpace(errs.String()))))
+			}
+			span.End()
+		}()
+		c.Next()
+	}
+}
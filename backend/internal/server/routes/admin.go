@@ -28,102 +28,164 @@ func RegisterAdminRoutes(
 	admin.Use(gin.HandlerFunc(auditLog))
 	admin.Use(middleware.AdminComplianceGuard(settingService))
 	{
-		// 部署与运营合规确认
+		// 部署与运营合规确认（每个进入管理面的账号都需自行确认，不受角色权限限制）
 		registerAdminComplianceRoutes(admin, h)
 
+		// 细粒度权限：角色管理仅限完整管理员，员工可查询自身权限
+		registerRBACRoutes(admin, h, stepUpAuth)
+
+		// 管理面 API Token（仅完整管理员）
+		registerAdminAPITokenRoutes(admin, h, stepUpAuth)
+
+		// 以下各路由分组按资源校验员工权限（读需 <resource>:read，写需 <resource>:write）
+
 		// 仪表盘
-		registerDashboardRoutes(admin, h)
+		registerDashboardRoutes(adminScope(admin, service.AdminResourceDashboard), h)
 
 		// 用户管理
-		registerUserManagementRoutes(admin, h)
+		registerUserManagementRoutes(adminScope(admin, service.AdminResourceUsers), h)
 
 		// 分组管理
-		registerGroupRoutes(admin, h)
+		registerGroupRoutes(adminScope(admin, service.AdminResourceGroups), h)
 
 		// 账号管理
-		registerAccountRoutes(admin, h, stepUpAuth)
+		registerAccountRoutes(adminScope(admin, service.AdminResourceAccounts), h, stepUpAuth)
 
 		// 公告管理
-		registerAnnouncementRoutes(admin, h)
+		registerAnnouncementRoutes(adminScope(admin, service.AdminResourceAnnouncements), h)
 
 		// OpenAI OAuth
-		registerOpenAIOAuthRoutes(admin, h)
+		registerOpenAIOAuthRoutes(adminScope(admin, service.AdminResourceAccounts), h)
 
 		// Gemini OAuth
-		registerGeminiOAuthRoutes(admin, h)
+		registerGeminiOAuthRoutes(adminScope(admin, service.AdminResourceAccounts), h)
 
 		// Antigravity OAuth
-		registerAntigravityOAuthRoutes(admin, h)
+		registerAntigravityOAuthRoutes(adminScope(admin, service.AdminResourceAccounts), h)
 
 		// Grok OAuth
-		registerGrokOAuthRoutes(admin, h)
+		registerGrokOAuthRoutes(adminScope(admin, service.AdminResourceAccounts), h)
 
 		// 国产供应商（kimi/zhipu/deepseek）额度与余额
-		registerCNProviderRoutes(admin, h)
+		registerCNProviderRoutes(adminScope(admin, service.AdminResourceAccounts), h)
 
 		// 代理管理
-		registerProxyRoutes(admin, h, stepUpAuth)
+		registerProxyRoutes(adminScope(admin, service.AdminResourceProxies), h, stepUpAuth)
 
 		// 卡密管理
-		registerRedeemCodeRoutes(admin, h)
+		registerRedeemCodeRoutes(adminScope(admin, service.AdminResourceRedeemCodes), h)
 
 		// 优惠码管理
-		registerPromoCodeRoutes(admin, h)
+		registerPromoCodeRoutes(adminScope(admin, service.AdminResourcePromoCodes), h)
 
 		// 系统设置
-		registerSettingsRoutes(admin, h)
+		registerSettingsRoutes(adminScope(admin, service.AdminResourceSettings), h)
 
 		// 数据管理
-		registerDataManagementRoutes(admin, h, stepUpAuth)
+		registerDataManagementRoutes(adminScope(admin, service.AdminResourceDataManagement), h, stepUpAuth)
 
 		// 数据库备份恢复
-		registerBackupRoutes(admin, h, stepUpAuth)
+		registerBackupRoutes(adminScope(admin, service.AdminResourceBackups), h, stepUpAuth)
 
 		// 运维监控（Ops）
-		registerOpsRoutes(admin, h)
+		registerOpsRoutes(adminScope(admin, service.AdminResourceOps), h)
 
 		// 系统管理
-		registerSystemRoutes(admin, h)
+		registerSystemRoutes(adminScope(admin, service.AdminResourceSystem), h)
 
 		// 订阅管理
-		registerSubscriptionRoutes(admin, h)
+		registerSubscriptionRoutes(adminScope(admin, service.AdminResourceSubscriptions), h)
 
 		// 使用记录管理
-		registerUsageRoutes(admin, h)
+		registerUsageRoutes(adminScope(admin, service.AdminResourceUsage), h)
 
 		// 用户属性管理
-		registerUserAttributeRoutes(admin, h)
+		registerUserAttributeRoutes(adminScope(admin, service.AdminResourceUsers), h)
 
 		// 错误透传规则管理
-		registerErrorPassthroughRoutes(admin, h)
+		registerErrorPassthroughRoutes(adminScope(admin, service.AdminResourceErrorPassthrough), h)
 
 		// TLS 指纹模板管理
-		registerTLSFingerprintProfileRoutes(admin, h)
+		registerTLSFingerprintProfileRoutes(adminScope(admin, service.AdminResourceTLSFingerprints), h)
 
 		// API Key 管理
-		registerAdminAPIKeyRoutes(admin, h)
+		registerAdminAPIKeyRoutes(adminScope(admin, service.AdminResourceAPIKeys), h)
 
 		// 定时测试计划
-		registerScheduledTestRoutes(admin, h)
+		registerScheduledTestRoutes(adminScope(admin, service.AdminResourceScheduledTests), h)
 
 		// 渠道管理
-		registerChannelRoutes(admin, h)
+		registerChannelRoutes(adminScope(admin, service.AdminResourceChannels), h)
 
 		// 渠道监控
-		registerChannelMonitorRoutes(admin, h, settingService)
-		registerChannelMonitorV2Routes(admin, h, settingService)
+		registerChannelMonitorRoutes(adminScope(admin, service.AdminResourceChannelMonitors), h, settingService)
+		registerChannelMonitorV2Routes(adminScope(admin, service.AdminResourceChannelMonitors), h, settingService)
 
 		// 风控中心
-		registerContentModerationRoutes(admin, h)
+		registerContentModerationRoutes(adminScope(admin, service.AdminResourceRiskControl), h)
 
 		// 独立提示词输入审计
-		registerPromptAuditRoutes(admin, h)
+		registerPromptAuditRoutes(adminScope(admin, service.AdminResourcePromptAudit), h)
 
 		// 邀请返利（专属用户管理）
-		registerAffiliateRoutes(admin, h)
+		registerAffiliateRoutes(adminScope(admin, service.AdminResourceAffiliates), h)
+
+		// 组织（团队）管理
+		registerOrganizationRoutes(adminScope(admin, service.AdminResourceOrganizations), h)
+
+		// 预算（用户 / Key / 分组）
+		registerBudgetRoutes(adminScope(admin, service.AdminResourceBudgets), h)
+
+		// 余额流水对账
+		registerBalanceLedgerRoutes(adminScope(admin, service.AdminResourceBalanceLedger), h)
+
+		// 票据（收据 / 用量对账单）与开票资料
+		registerInvoiceRoutes(adminScope(admin, service.AdminResourceInvoices), h)
+
+		// 用量明细定时导出（S3）
+		registerUsageExportRoutes(adminScope(admin, service.AdminResourceUsageExport), h)
+
+		// SAML 单点登录（SP 状态与 IdP 元数据导入）
+		registerSAMLRoutes(adminScope(admin, service.AdminResourceSettings), h, stepUpAuth)
 
 		// 操作审计日志
-		registerAuditLogRoutes(admin, h, stepUpAuth)
+		registerAuditLogRoutes(adminScope(admin, service.AdminResourceAuditLogs), h, stepUpAuth)
+	}
+}
+
+// adminScope 返回同路径、挂载了资源权限校验的子分组。
+// 完整管理员不受限制；员工按请求方法校验 <resource>:read / <resource>:write。
+func adminScope(admin *gin.RouterGroup, resource string) *gin.RouterGroup {
+	return admin.Group("", middleware.RequireAdminPermission(resource))
+}
+
+// registerRBACRoutes 注册员工角色与权限管理路由
+func registerRBACRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAuth middleware.StepUpAuthMiddleware) {
+	rbac := admin.Group("/rbac")
+	{
+		rbac.GET("/me", h.Admin.RBAC.Me)
+
+		manage := rbac.Group("", middleware.RequireSuperAdmin())
+		manage.GET("/permissions", h.Admin.RBAC.ListPermissions)
+		manage.GET("/roles", h.Admin.RBAC.ListRoles)
+		manage.GET("/assignments", h.Admin.RBAC.ListAssignments)
+		// 角色定义与绑定会改变他人的管理面权限，需 step-up 2FA
+		manage.POST("/roles", gin.HandlerFunc(stepUpAuth), h.Admin.RBAC.CreateRole)
+		manage.PUT("/roles/:id", gin.HandlerFunc(stepUpAuth), h.Admin.RBAC.UpdateRole)
+		manage.DELETE("/roles/:id", gin.HandlerFunc(stepUpAuth), h.Admin.RBAC.DeleteRole)
+		manage.PUT("/assignments/:user_id", gin.HandlerFunc(stepUpAuth), h.Admin.RBAC.AssignRole)
+		manage.DELETE("/assignments/:user_id", gin.HandlerFunc(stepUpAuth), h.Admin.RBAC.RemoveAssignment)
+	}
+}
+
+// registerAdminAPITokenRoutes 注册管理面 API Token 管理路由
+func registerAdminAPITokenRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAuth middleware.StepUpAuthMiddleware) {
+	tokens := admin.Group("/api-tokens", middleware.RequireSuperAdmin())
+	{
+		tokens.GET("", h.Admin.AdminAPIToken.List)
+		// 签发长期凭证需 step-up 2FA；吊销不设门槛，便于应急处置
+		tokens.POST("", gin.HandlerFunc(stepUpAuth), h.Admin.AdminAPIToken.Create)
+		tokens.DELETE("/:id", h.Admin.AdminAPIToken.Revoke)
 	}
 }
 
@@ -304,6 +366,7 @@ func registerUserManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		users.GET("/:id/api-keys", h.Admin.User.GetUserAPIKeys)
 		users.GET("/:id/usage", h.Admin.User.GetUserUsage)
 		users.GET("/:id/balance-history", h.Admin.User.GetBalanceHistory)
+		users.GET("/:id/balance-ledger", h.Admin.BalanceLedger.ListUserLedger)
 		users.POST("/:id/replace-group", h.Admin.User.ReplaceGroup)
 		users.GET("/:id/rpm-status", h.Admin.User.GetUserRPMStatus)
 		users.POST("/batch-concurrency", h.Admin.User.BatchUpdateConcurrency)
@@ -326,6 +389,7 @@ func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		groups.GET("/usage-summary", h.Admin.Group.GetUsageSummary)
 		groups.GET("/capacity-summary", h.Admin.Group.GetCapacitySummary)
 		groups.GET("/live-capability", h.Admin.Group.GetLiveCapability)
+		groups.GET("/scheduling-strategies", h.Admin.Group.ListSchedulingStrategies)
 		groups.PUT("/sort-order", h.Admin.Group.UpdateSortOrder)
 		groups.GET("/:id/models-list-candidates", h.Admin.Group.GetModelsListCandidates)
 		groups.GET("/:id/composite-routes", h.Admin.Group.ListCompositeRoutes)
@@ -652,6 +716,11 @@ func registerSystemRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		system.POST("/update", h.Admin.System.PerformUpdate)
 		system.POST("/rollback", h.Admin.System.Rollback)
 		system.POST("/restart", h.Admin.System.RestartService)
+		system.GET("/config/reload", h.Admin.System.GetConfigReloadStatus)
+		system.POST("/config/reload", h.Admin.System.ReloadConfig)
+		system.GET("/credential-encryption", h.Admin.CredentialEncryption.GetStatus)
+		system.POST("/credential-encryption/rotate", h.Admin.CredentialEncryption.RotateDataKey)
+		system.POST("/credential-encryption/reencrypt", h.Admin.CredentialEncryption.Reencrypt)
 	}
 }
 
@@ -797,6 +866,78 @@ func registerAffiliateRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	}
 }
 
+// registerOrganizationRoutes 注册组织管理路由（钱包调整、启停）
+func registerOrganizationRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	organizations := admin.Group("/organizations")
+	{
+		organizations.GET("", h.Admin.Organization.List)
+		organizations.GET("/:id", h.Admin.Organization.Get)
+		organizations.POST("/:id/balance", h.Admin.Organization.AdjustBalance)
+		organizations.PUT("/:id/status", h.Admin.Organization.UpdateStatus)
+	}
+}
+
+// registerBudgetRoutes 注册预算管理路由（用户、API Key、分组的自然周期预算）
+func registerBudgetRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	budgets := admin.Group("/budgets")
+	{
+		budgets.GET("", h.Admin.Budget.List)
+		budgets.POST("", h.Admin.Budget.Create)
+		budgets.GET("/:id", h.Admin.Budget.Get)
+		budgets.PUT("/:id", h.Admin.Budget.Update)
+		budgets.DELETE("/:id", h.Admin.Budget.Delete)
+	}
+}
+
+// registerBalanceLedgerRoutes 注册余额流水对账路由（查看历史对账结果、手动触发对账）
+func registerBalanceLedgerRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	reconciliations := admin.Group("/balance-ledger/reconciliations")
+	{
+		reconciliations.GET("", h.Admin.BalanceLedger.ListReconciliations)
+		reconciliations.POST("/run", h.Admin.BalanceLedger.RunReconciliation)
+		reconciliations.GET("/:id", h.Admin.BalanceLedger.GetReconciliation)
+	}
+}
+
+// registerInvoiceRoutes 注册票据管理路由（查询、下载、补开、作废、重开）及开票资料维护
+func registerInvoiceRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	invoices := admin.Group("/invoices")
+	{
+		invoices.GET("", h.Admin.Invoice.List)
+		invoices.POST("/receipts", h.Admin.Invoice.IssueReceipt)
+		invoices.POST("/statements", h.Admin.Invoice.IssueStatement)
+		invoices.GET("/:id", h.Admin.Invoice.Get)
+		invoices.GET("/:id/download", h.Admin.Invoice.Download)
+		invoices.POST("/:id/void", h.Admin.Invoice.Void)
+		invoices.POST("/:id/reissue", h.Admin.Invoice.Reissue)
+	}
+
+	billingProfiles := admin.Group("/billing-profiles")
+	{
+		billingProfiles.GET("/:owner_type/:owner_id", h.Admin.Invoice.GetBillingProfile)
+		billingProfiles.PUT("/:owner_type/:owner_id", h.Admin.Invoice.UpdateBillingProfile)
+	}
+}
+
+// registerUsageExportRoutes 注册用量明细导出路由（查看水位与最近文件、手动触发导出）
+func registerUsageExportRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	usageExport := admin.Group("/usage-export")
+	{
+		usageExport.GET("", h.Admin.UsageExport.GetStatus)
+		usageExport.POST("/run", h.Admin.UsageExport.Run)
+	}
+}
+
+// registerSAMLRoutes 注册 SAML 管理路由；更换 IdP 元数据等同于更换登录信任根，需二次验证
+func registerSAMLRoutes(admin *gin.RouterGroup, h *handler.Handlers, stepUpAuth middleware.StepUpAuthMiddleware) {
+	samlGroup := admin.Group("/saml")
+	{
+		samlGroup.GET("", h.Admin.SAML.GetStatus)
+		samlGroup.POST("/metadata", gin.HandlerFunc(stepUpAuth), h.Admin.SAML.ImportMetadata)
+		samlGroup.DELETE("/metadata", gin.HandlerFunc(stepUpAuth), h.Admin.SAML.DeleteMetadata)
+	}
+}
+
 func registerChannelMonitorV2Routes(admin *gin.RouterGroup, h *handler.Handlers, settingService *service.SettingService) {
 	// Config GET/PUT: feature enabled only (operators can prepare V2 before flipping mode).
 	// Read/matrix endpoints: require mode=v2 so V1 deployments do not serve passive data.
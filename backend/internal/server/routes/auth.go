@@ -236,6 +236,41 @@ func RegisterAuthRoutes(
 			}),
 			h.Auth.CreateDingTalkOAuthAccount,
 		)
+		auth.GET("/oauth/saml/start", h.Auth.SAMLOAuthStart)
+		auth.POST("/oauth/saml/start", rateLimiter.LimitWithOptions("oauth-saml-start", 20, time.Minute, middleware.RateLimitOptions{
+			FailureMode: middleware.RateLimitFailClose,
+		}), h.Auth.SAMLOAuthStart)
+		auth.GET("/oauth/saml/bind/start", func(c *gin.Context) {
+			query := c.Request.URL.Query()
+			query.Set("intent", "bind_current_user")
+			c.Request.URL.RawQuery = query.Encode()
+			h.Auth.SAMLOAuthStart(c)
+		})
+		auth.POST("/oauth/saml/acs",
+			rateLimiter.LimitWithOptions("oauth-saml-acs", 30, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.SAMLOAuthACS,
+		)
+		auth.GET("/oauth/saml/metadata", h.Auth.SAMLMetadata)
+		auth.POST("/oauth/saml/complete-registration",
+			rateLimiter.LimitWithOptions("oauth-saml-complete", 10, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.CompleteSAMLOAuthRegistration,
+		)
+		auth.POST("/oauth/saml/bind-login",
+			rateLimiter.LimitWithOptions("oauth-saml-bind-login", 20, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.BindSAMLOAuthLogin,
+		)
+		auth.POST("/oauth/saml/create-account",
+			rateLimiter.LimitWithOptions("oauth-saml-create-account", 10, time.Minute, middleware.RateLimitOptions{
+				FailureMode: middleware.RateLimitFailClose,
+			}),
+			h.Auth.CreateSAMLOAuthAccount,
+		)
 	}
 
 	// 公开设置（无需认证）：每次请求都会查询 DB，按客户端 IP 兜底限流，
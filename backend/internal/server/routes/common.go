@@ -30,3 +30,12 @@ func RegisterCommonRoutes(r *gin.Engine) {
 		})
 	})
 }
+
+// RegisterMetricsRoutes 注册 Prometheus 抓取端点；metrics.enabled=false 时 handler 为 nil，不注册路由。
+func RegisterMetricsRoutes(r *gin.Engine, handler http.Handler) {
+	if handler == nil {
+		return
+	}
+	r.GET("/metrics", gin.WrapH(handler))
+	r.HEAD("/metrics", gin.WrapH(handler))
+}
@@ -375,6 +375,34 @@ func TestGatewayRoutesCompositeOpenAIOnlyEndpointsRequireOpenAITarget(t *testing
 	require.NotEqual(t, http.StatusNotFound, w.Code)
 }
 
+func TestGatewayRoutesRerankAndModerationsAreOpenAIOnly(t *testing.T) {
+	for _, tc := range []struct {
+		path    string
+		body    string
+		message string
+	}{
+		{"/v1/rerank", `{"model":"rerank-v3.5","query":"q","documents":["a"]}`, "Rerank API is not supported for this platform"},
+		{"/rerank", `{"model":"rerank-v3.5","query":"q","documents":["a"]}`, "Rerank API is not supported for this platform"},
+		{"/v1/moderations", `{"input":"hello"}`, "Moderations API is not supported for this platform"},
+		{"/moderations", `{"input":"hello"}`, "Moderations API is not supported for this platform"},
+	} {
+		router := newGatewayRoutesTestRouter(service.PlatformAnthropic)
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotFound, w.Code, "path=%s", tc.path)
+		require.Contains(t, w.Body.String(), tc.message)
+
+		router = newGatewayRoutesTestRouter(service.PlatformOpenAI)
+		req = httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.NotContains(t, w.Body.String(), "not supported for this platform", "path=%s", tc.path)
+	}
+}
+
 func TestGatewayRoutesGrokAllowsCLICompatibilityEntrypoints(t *testing.T) {
 	router := newGatewayRoutesTestRouter(service.PlatformGrok)
 
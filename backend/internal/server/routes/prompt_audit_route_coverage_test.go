@@ -33,6 +33,7 @@ func TestEveryGatewayPOSTRouteIsClassifiedForPromptAuditCoverage(t *testing.T) {
 		"/responses/*subpath":       {"gateway_handler_responses.go", "openai_gateway_handler.go"},
 		"/chat/completions":         {"gateway_handler_chat_completions.go", "openai_chat_completions.go"},
 		"/embeddings":               {"openai_embeddings.go"},
+		"/rerank":                   {"openai_embeddings.go"},
 		"/alpha/search":             {"openai_alpha_search.go"},
 		"/live":                     {"openai_live.go"},
 		"/realtime/calls":           {"openai_live.go"},
@@ -54,7 +55,11 @@ func TestEveryGatewayPOSTRouteIsClassifiedForPromptAuditCoverage(t *testing.T) {
 		"/messages/count_tokens":     "tokenization only; it does not execute a model request",
 		"/images/batches/:id/cancel": "control-plane cancellation with no user prompt",
 		"/stt":                       "speech transcription is not a text-generation prompt",
+		"/moderations":               "classification endpoint; compliance tooling submits content to be labelled, not executed",
 		"/custom-voices":             "voice profile management has no model prompt",
+		"/files":                     "stores batch input files; each line is audited when replayed through its endpoint",
+		"/batches":                   "each batch line is replayed in-process through the audited endpoint handler",
+		"/batches/:id/cancel":        "control-plane cancellation with no user prompt",
 	}
 
 	unclassified := make([]string, 0)
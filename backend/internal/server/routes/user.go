@@ -39,6 +39,17 @@ func RegisterUserRoutes(
 			user.POST("/auth-identities/bind/start", h.User.StartIdentityBinding)
 			user.GET("/api-keys/:id/usage/daily", panelRateLimiter.Heavy(), h.Usage.GetMyAPIKeyDailyUsage)
 			user.GET("/platform-quotas", h.User.GetMyPlatformQuotas)
+			user.GET("/balance-ledger", h.BalanceLedger.List)
+			user.GET("/balance-ledger/statement", panelRateLimiter.Heavy(), h.BalanceLedger.Statement)
+
+			// 开票资料与票据（收据 / 月度用量对账单）
+			user.GET("/billing-profile", h.Invoice.GetBillingProfile)
+			user.PUT("/billing-profile", h.Invoice.UpdateBillingProfile)
+			user.GET("/invoices", h.Invoice.List)
+			user.POST("/invoices/receipts", h.Invoice.IssueReceipt)
+			user.POST("/invoices/statements", panelRateLimiter.Heavy(), h.Invoice.IssueStatement)
+			user.GET("/invoices/:invoice_id", h.Invoice.Get)
+			user.GET("/invoices/:invoice_id/download", panelRateLimiter.Heavy(), h.Invoice.Download)
 
 			// 通知邮箱管理
 			notifyEmail := user.Group("/notify-email")
@@ -119,6 +130,54 @@ func RegisterUserRoutes(
 			announcements.POST("/:id/read", h.Announcement.MarkRead)
 		}
 
+		// 用户 Webhook（endpoint 管理与投递日志）
+		webhooks := authenticated.Group("/webhooks")
+		{
+			webhooks.GET("/events", h.UserWebhook.ListEventTypes)
+			webhooks.GET("", h.UserWebhook.List)
+			webhooks.POST("", h.UserWebhook.Create)
+			webhooks.PUT("/:id", h.UserWebhook.Update)
+			webhooks.DELETE("/:id", h.UserWebhook.Delete)
+			webhooks.POST("/:id/rotate-secret", h.UserWebhook.RotateSecret)
+			webhooks.POST("/:id/test", h.UserWebhook.SendTest)
+			webhooks.GET("/deliveries", h.UserWebhook.ListDeliveries)
+			webhooks.GET("/deliveries/:id", h.UserWebhook.GetDelivery)
+			webhooks.POST("/deliveries/:id/redeliver", h.UserWebhook.Redeliver)
+		}
+
+		// 组织（团队）：成员、邀请、共享钱包、用量与组织 Key
+		organizations := authenticated.Group("/organizations")
+		{
+			organizations.GET("", h.Organization.List)
+			organizations.POST("", h.Organization.Create)
+			organizations.POST("/invitations/accept", h.Organization.AcceptInvitation)
+			organizations.GET("/:id", h.Organization.Get)
+			organizations.PUT("/:id", h.Organization.Update)
+			organizations.GET("/:id/members", h.Organization.ListMembers)
+			organizations.PUT("/:id/members/:user_id", h.Organization.UpdateMember)
+			organizations.DELETE("/:id/members/:user_id", h.Organization.RemoveMember)
+			organizations.GET("/:id/invitations", h.Organization.ListInvitations)
+			organizations.POST("/:id/invitations", h.Organization.Invite)
+			organizations.DELETE("/:id/invitations/:invitation_id", h.Organization.RevokeInvitation)
+			organizations.POST("/:id/wallet/fund", h.Organization.Fund)
+			organizations.GET("/:id/wallet/transactions", h.Organization.ListWalletTransactions)
+			organizations.GET("/:id/usage", h.Organization.Usage)
+			organizations.GET("/:id/api-keys", h.Organization.ListAPIKeys)
+			organizations.GET("/:id/billing-profile", h.Invoice.GetBillingProfile)
+			organizations.PUT("/:id/billing-profile", h.Invoice.UpdateBillingProfile)
+			organizations.GET("/:id/invoices", h.Invoice.List)
+			organizations.POST("/:id/invoices/statements", panelRateLimiter.Heavy(), h.Invoice.IssueStatement)
+		}
+
+		// 预算：本人及本人 API Key 上的自然周期预算
+		budgets := authenticated.Group("/budgets")
+		{
+			budgets.GET("", h.Budget.List)
+			budgets.POST("", h.Budget.Create)
+			budgets.PUT("/:id", h.Budget.Update)
+			budgets.DELETE("/:id", h.Budget.Delete)
+		}
+
 		// 卡密兑换
 		redeem := authenticated.Group("/redeem")
 		{
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// AccountRuntimeStats 账号运行时统计（错误率与首 token 延迟的 EWMA），进程内共享：
+// Anthropic、Gemini、OpenAI 网关都向同一实例上报请求结果，各调度策略读取同一份数据。
+type AccountRuntimeStats struct {
+	accounts     sync.Map
+	accountCount atomic.Int64
+}
+
+type accountRuntimeStat struct {
+	errorRateEWMABits atomic.Uint64
+	ttftEWMABits      atomic.Uint64
+}
+
+// NewAccountRuntimeStats 创建空的账号运行时统计。
+func NewAccountRuntimeStats() *AccountRuntimeStats {
+	return &AccountRuntimeStats{}
+}
+
+func (s *AccountRuntimeStats) loadOrCreate(accountID int64) *accountRuntimeStat {
+	if value, ok := s.accounts.Load(accountID); ok {
+		stat, _ := value.(*accountRuntimeStat)
+		if stat != nil {
+			return stat
+		}
+	}
+
+	stat := &accountRuntimeStat{}
+	stat.ttftEWMABits.Store(math.Float64bits(math.NaN()))
+	actual, loaded := s.accounts.LoadOrStore(accountID, stat)
+	if !loaded {
+		s.accountCount.Add(1)
+		return stat
+	}
+	existing, _ := actual.(*accountRuntimeStat)
+	if existing != nil {
+		return existing
+	}
+	return stat
+}
+
+func updateEWMAAtomic(target *atomic.Uint64, sample float64, alpha float64) {
+	for {
+		oldBits := target.Load()
+		oldValue := math.Float64frombits(oldBits)
+		newValue := alpha*sample + (1-alpha)*oldValue
+		if target.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
+			return
+		}
+	}
+}
+
+func (s *AccountRuntimeStats) report(accountID int64, success bool, firstTokenMs *int) {
+	if s == nil || accountID <= 0 {
+		return
+	}
+	const alpha = 0.2
+	stat := s.loadOrCreate(accountID)
+
+	errorSample := 1.0
+	if success {
+		errorSample = 0.0
+	}
+	updateEWMAAtomic(&stat.errorRateEWMABits, errorSample, alpha)
+
+	if firstTokenMs != nil && *firstTokenMs > 0 {
+		ttft := float64(*firstTokenMs)
+		ttftBits := math.Float64bits(ttft)
+		for {
+			oldBits := stat.ttftEWMABits.Load()
+			oldValue := math.Float64frombits(oldBits)
+			if math.IsNaN(oldValue) {
+				if stat.ttftEWMABits.CompareAndSwap(oldBits, ttftBits) {
+					break
+				}
+				continue
+			}
+			newValue := alpha*ttft + (1-alpha)*oldValue
+			if stat.ttftEWMABits.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
+				break
+			}
+		}
+	}
+}
+
+func (s *AccountRuntimeStats) snapshot(accountID int64) (errorRate float64, ttft float64, hasTTFT bool) {
+	if s == nil || accountID <= 0 {
+		return 0, 0, false
+	}
+	value, ok := s.accounts.Load(accountID)
+	if !ok {
+		return 0, 0, false
+	}
+	stat, _ := value.(*accountRuntimeStat)
+	if stat == nil {
+		return 0, 0, false
+	}
+	errorRate = clamp01(math.Float64frombits(stat.errorRateEWMABits.Load()))
+	ttftValue := math.Float64frombits(stat.ttftEWMABits.Load())
+	if math.IsNaN(ttftValue) {
+		return errorRate, 0, false
+	}
+	return errorRate, ttftValue, true
+}
+
+func (s *AccountRuntimeStats) size() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.accountCount.Load())
+}
+
+// AccountRuntimeSnapshot 某账号运行时统计的只读快照。HasTTFT 为 false 表示尚无延迟样本。
+type AccountRuntimeSnapshot struct {
+	ErrorRate float64
+	TTFTMs    float64
+	HasTTFT   bool
+}
+
+// Report 记录一次请求结果；firstTokenMs 为 nil 或非正数时只更新错误率。
+func (s *AccountRuntimeStats) Report(accountID int64, success bool, firstTokenMs *int) {
+	s.report(accountID, success, firstTokenMs)
+}
+
+// Snapshot 返回账号当前的运行时统计。
+func (s *AccountRuntimeStats) Snapshot(accountID int64) AccountRuntimeSnapshot {
+	errorRate, ttft, hasTTFT := s.snapshot(accountID)
+	return AccountRuntimeSnapshot{ErrorRate: errorRate, TTFTMs: ttft, HasTTFT: hasTTFT}
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"fmt"
+	mathrand "math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 账号调度策略名称，对应分组的 scheduling_strategy 字段；空值等同 default。
+const (
+	// AccountSchedulingStrategyDefault 沿用各平台原有调度：
+	// Anthropic / Gemini 为 优先级 →（可选）最早重置 → 负载率 → LRU；
+	// OpenAI 为高级调度器（启用时）或其原有负载均衡。
+	AccountSchedulingStrategyDefault = "default"
+	// AccountSchedulingStrategyLeastLatency 优先首 token 延迟（EWMA）最低的账号，按错误率与负载率加权。
+	AccountSchedulingStrategyLeastLatency = "least_latency"
+	// AccountSchedulingStrategyCostAware 优先单位成本（账号计费倍率）最低的账号。
+	AccountSchedulingStrategyCostAware = "cost_aware"
+	// AccountSchedulingStrategyWeightedRoundRobin 按负载因子（未配置时为并发数）做平滑加权轮询。
+	AccountSchedulingStrategyWeightedRoundRobin = "weighted_round_robin"
+)
+
+const (
+	// accountSchedulerUnhealthyErrorRate 错误率 EWMA 达到该值的账号在同一优先级内排到健康账号之后。
+	accountSchedulerUnhealthyErrorRate = 0.5
+	// accountSchedulerLatencyErrorPenalty 最低延迟策略中错误率对延迟得分的放大系数。
+	accountSchedulerLatencyErrorPenalty = 4.0
+)
+
+// AccountScheduleCandidate 已通过可用性过滤（可调度、模型、配额、RPM 等）且负载未满的候选账号。
+type AccountScheduleCandidate struct {
+	Account  *Account
+	LoadInfo *AccountLoadInfo
+}
+
+func (c AccountScheduleCandidate) loadRate() int {
+	if c.LoadInfo == nil {
+		return 0
+	}
+	return c.LoadInfo.LoadRate
+}
+
+// AccountScheduleRequest 一次排序所需的上下文。
+type AccountScheduleRequest struct {
+	GroupID  *int64
+	Platform string
+	Model    string
+	// PreferOAuth LRU 平局时优先 OAuth 账号（default 策略）
+	PreferOAuth bool
+	// PreferSoonestReset 优先会话窗口最早重置的账号（default 策略）
+	PreferSoonestReset bool
+	// Now 当前时间，零值表示 time.Now()；离线模拟时传入虚拟时钟。
+	Now time.Time
+	// CostOf 返回账号的单位成本，nil 时使用账号计费倍率（cost_aware 策略）。
+	CostOf func(account *Account) float64
+}
+
+func (r AccountScheduleRequest) now() time.Time {
+	if r.Now.IsZero() {
+		return time.Now()
+	}
+	return r.Now
+}
+
+func (r AccountScheduleRequest) costOf(account *Account) float64 {
+	if r.CostOf != nil {
+		return r.CostOf(account)
+	}
+	return account.BillingRateMultiplier()
+}
+
+// AccountScheduler 账号调度策略：对候选账号给出尝试顺序，网关依次尝试获取并发槽位，
+// 失败则尝试下一个。所有策略都先按优先级分层，策略只决定同一优先级内的顺序；
+// 粘性会话、模型路由与兜底排队仍由各网关处理。
+type AccountScheduler interface {
+	Name() string
+	// Order 返回新的切片，不修改入参。
+	Order(req AccountScheduleRequest, candidates []AccountScheduleCandidate) []AccountScheduleCandidate
+}
+
+// AccountSchedulerFactory 基于共享运行时统计创建调度策略实例。
+type AccountSchedulerFactory func(stats *AccountRuntimeStats) AccountScheduler
+
+var (
+	accountSchedulerFactoriesMu sync.RWMutex
+	accountSchedulerFactories   = map[string]AccountSchedulerFactory{
+		AccountSchedulingStrategyDefault: func(*AccountRuntimeStats) AccountScheduler {
+			return defaultAccountScheduler{}
+		},
+		AccountSchedulingStrategyLeastLatency: func(stats *AccountRuntimeStats) AccountScheduler {
+			return &leastLatencyAccountScheduler{stats: stats}
+		},
+		AccountSchedulingStrategyCostAware: func(stats *AccountRuntimeStats) AccountScheduler {
+			return &costAwareAccountScheduler{stats: stats}
+		},
+		AccountSchedulingStrategyWeightedRoundRobin: func(stats *AccountRuntimeStats) AccountScheduler {
+			return newWeightedRoundRobinAccountScheduler(stats)
+		},
+	}
+)
+
+// RegisterAccountScheduler 注册（或替换）一个命名调度策略，需在服务启动前调用。
+func RegisterAccountScheduler(name string, factory AccountSchedulerFactory) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || factory == nil {
+		return
+	}
+	accountSchedulerFactoriesMu.Lock()
+	defer accountSchedulerFactoriesMu.Unlock()
+	accountSchedulerFactories[name] = factory
+}
+
+// AccountSchedulingStrategies 返回已注册的策略名称（按字母序）。
+func AccountSchedulingStrategies() []string {
+	accountSchedulerFactoriesMu.RLock()
+	defer accountSchedulerFactoriesMu.RUnlock()
+	names := make([]string, 0, len(accountSchedulerFactories))
+	for name := range accountSchedulerFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeAccountSchedulingStrategy 规范化分组配置的策略名称；空值返回空串（即 default），
+// 未注册的名称返回错误。
+func NormalizeAccountSchedulingStrategy(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", nil
+	}
+	accountSchedulerFactoriesMu.RLock()
+	_, ok := accountSchedulerFactories[name]
+	accountSchedulerFactoriesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("invalid scheduling_strategy %q, must be one of %s", name, strings.Join(AccountSchedulingStrategies(), ", "))
+	}
+	return name, nil
+}
+
+// AccountSchedulers 进程内的调度策略实例集合，所有网关共享同一份运行时统计。
+type AccountSchedulers struct {
+	stats *AccountRuntimeStats
+
+	mu        sync.Mutex
+	instances map[string]AccountScheduler
+}
+
+// NewAccountSchedulers 创建策略集合；stats 为 nil 时新建一份。
+func NewAccountSchedulers(stats *AccountRuntimeStats) *AccountSchedulers {
+	if stats == nil {
+		stats = NewAccountRuntimeStats()
+	}
+	return &AccountSchedulers{stats: stats, instances: make(map[string]AccountScheduler)}
+}
+
+// Stats 返回共享的账号运行时统计。
+func (s *AccountSchedulers) Stats() *AccountRuntimeStats {
+	if s == nil {
+		return nil
+	}
+	return s.stats
+}
+
+// ReportResult 记录一次请求结果，供各策略读取。
+func (s *AccountSchedulers) ReportResult(accountID int64, success bool, firstTokenMs *int) {
+	if s == nil {
+		return
+	}
+	s.stats.Report(accountID, success, firstTokenMs)
+}
+
+// ForStrategy 返回指定策略的实例；空值或未注册的名称返回 default。
+func (s *AccountSchedulers) ForStrategy(name string) AccountScheduler {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = AccountSchedulingStrategyDefault
+	}
+	if s == nil {
+		return defaultAccountScheduler{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scheduler, ok := s.instances[name]; ok {
+		return scheduler
+	}
+	accountSchedulerFactoriesMu.RLock()
+	factory, ok := accountSchedulerFactories[name]
+	accountSchedulerFactoriesMu.RUnlock()
+	if !ok {
+		return s.defaultLocked()
+	}
+	scheduler := factory(s.stats)
+	s.instances[name] = scheduler
+	return scheduler
+}
+
+func (s *AccountSchedulers) defaultLocked() AccountScheduler {
+	if scheduler, ok := s.instances[AccountSchedulingStrategyDefault]; ok {
+		return scheduler
+	}
+	scheduler := AccountScheduler(defaultAccountScheduler{})
+	s.instances[AccountSchedulingStrategyDefault] = scheduler
+	return scheduler
+}
+
+// ForGroup 返回分组配置的策略实例；分组为空时返回 default。
+func (s *AccountSchedulers) ForGroup(group *Group) AccountScheduler {
+	if group == nil {
+		return s.ForStrategy("")
+	}
+	return s.ForStrategy(group.SchedulingStrategy)
+}
+
+// IsDefaultAccountScheduler 报告 scheduler 是否为 default 策略。
+func IsDefaultAccountScheduler(scheduler AccountScheduler) bool {
+	return scheduler == nil || scheduler.Name() == AccountSchedulingStrategyDefault
+}
+
+// defaultAccountScheduler 原有行为：逐轮取 最小优先级 →（可选）最早重置 → 最低负载率 → LRU（平局随机）。
+type defaultAccountScheduler struct{}
+
+func (defaultAccountScheduler) Name() string { return AccountSchedulingStrategyDefault }
+
+func (defaultAccountScheduler) Order(req AccountScheduleRequest, candidates []AccountScheduleCandidate) []AccountScheduleCandidate {
+	now := req.now()
+	available := accountsWithLoadFromCandidates(candidates)
+
+	ordered := make([]AccountScheduleCandidate, 0, len(candidates))
+	for len(available) > 0 {
+		tier := filterByMinPriority(available)
+		if req.PreferSoonestReset {
+			tier = filterBySoonestResetAt(tier, now)
+		}
+		tier = filterByMinLoadRate(tier)
+		selected := selectByLRU(tier, req.PreferOAuth)
+		if selected == nil {
+			break
+		}
+		ordered = append(ordered, AccountScheduleCandidate{Account: selected.account, LoadInfo: selected.loadInfo})
+
+		selectedID := selected.account.ID
+		remaining := available[:0]
+		for _, item := range available {
+			if item.account.ID != selectedID {
+				remaining = append(remaining, item)
+			}
+		}
+		available = remaining
+	}
+	return ordered
+}
+
+// leastLatencyAccountScheduler 同一优先级内按 TTFT × (1 + 4×错误率) × (1 + 负载率) 升序；
+// 尚无延迟样本的账号取同批候选的平均延迟，既参与探测又不会被无条件优先。
+type leastLatencyAccountScheduler struct {
+	stats *AccountRuntimeStats
+}
+
+func (s *leastLatencyAccountScheduler) Name() string { return AccountSchedulingStrategyLeastLatency }
+
+func (s *leastLatencyAccountScheduler) Order(_ AccountScheduleRequest, candidates []AccountScheduleCandidate) []AccountScheduleCandidate {
+	snapshots := snapshotAccountCandidates(s.stats, candidates)
+	var sum float64
+	var known int
+	for _, snap := range snapshots {
+		if snap.HasTTFT {
+			sum += snap.TTFTMs
+			known++
+		}
+	}
+	fallback := 0.0
+	if known > 0 {
+		fallback = sum / float64(known)
+	}
+	scores := make(map[int64]float64, len(candidates))
+	for _, c := range candidates {
+		snap := snapshots[c.Account.ID]
+		ttft := fallback
+		if snap.HasTTFT {
+			ttft = snap.TTFTMs
+		}
+		scores[c.Account.ID] = ttft * (1 + accountSchedulerLatencyErrorPenalty*snap.ErrorRate) * (1 + float64(c.loadRate())/100)
+	}
+	return orderAccountCandidates(candidates, snapshots, func(a, b AccountScheduleCandidate) int {
+		return compareFloat64(scores[a.Account.ID], scores[b.Account.ID])
+	})
+}
+
+// costAwareAccountScheduler 同一优先级内按单位成本升序，成本相同再比负载率。
+type costAwareAccountScheduler struct {
+	stats *AccountRuntimeStats
+}
+
+func (s *costAwareAccountScheduler) Name() string { return AccountSchedulingStrategyCostAware }
+
+func (s *costAwareAccountScheduler) Order(req AccountScheduleRequest, candidates []AccountScheduleCandidate) []AccountScheduleCandidate {
+	snapshots := snapshotAccountCandidates(s.stats, candidates)
+	costs := make(map[int64]float64, len(candidates))
+	for _, c := range candidates {
+		costs[c.Account.ID] = req.costOf(c.Account)
+	}
+	return orderAccountCandidates(candidates, snapshots, func(a, b AccountScheduleCandidate) int {
+		if cmp := compareFloat64(costs[a.Account.ID], costs[b.Account.ID]); cmp != 0 {
+			return cmp
+		}
+		return a.loadRate() - b.loadRate()
+	})
+}
+
+type weightedRoundRobinKey struct {
+	groupID   int64
+	accountID int64
+}
+
+// weightedRoundRobinAccountScheduler 平滑加权轮询（同 nginx）：每次排序时最高优先级层内
+// 各账号的当前权重加上自身权重，取最大者并减去总权重；其余账号按当前权重降序作为后备。
+// 权重为负载因子乘以剩余负载比例，状态按 (分组, 账号) 维护。
+type weightedRoundRobinAccountScheduler struct {
+	stats *AccountRuntimeStats
+
+	mu      sync.Mutex
+	current map[weightedRoundRobinKey]int64
+}
+
+func newWeightedRoundRobinAccountScheduler(stats *AccountRuntimeStats) *weightedRoundRobinAccountScheduler {
+	return &weightedRoundRobinAccountScheduler{stats: stats, current: make(map[weightedRoundRobinKey]int64)}
+}
+
+func (s *weightedRoundRobinAccountScheduler) Name() string {
+	return AccountSchedulingStrategyWeightedRoundRobin
+}
+
+func weightedRoundRobinWeight(c AccountScheduleCandidate) int64 {
+	weight := int64(c.Account.EffectiveLoadFactor()) * int64(100-c.loadRate())
+	if weight < 1 {
+		return 1
+	}
+	return weight
+}
+
+func (s *weightedRoundRobinAccountScheduler) Order(req AccountScheduleRequest, candidates []AccountScheduleCandidate) []AccountScheduleCandidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+	snapshots := snapshotAccountCandidates(s.stats, candidates)
+	tiers := splitAccountCandidateTiers(candidates, snapshots)
+	groupID := derefGroupID(req.GroupID)
+
+	s.mu.Lock()
+	first := tiers[0]
+	var total int64
+	best := -1
+	for i, c := range first {
+		key := weightedRoundRobinKey{groupID: groupID, accountID: c.Account.ID}
+		weight := weightedRoundRobinWeight(c)
+		s.current[key] += weight
+		total += weight
+		if best < 0 || s.current[key] > s.current[weightedRoundRobinKey{groupID: groupID, accountID: first[best].Account.ID}] {
+			best = i
+		}
+	}
+	s.current[weightedRoundRobinKey{groupID: groupID, accountID: first[best].Account.ID}] -= total
+	currentWeights := make(map[int64]int64, len(candidates))
+	for _, c := range candidates {
+		currentWeights[c.Account.ID] = s.current[weightedRoundRobinKey{groupID: groupID, accountID: c.Account.ID}]
+	}
+	s.mu.Unlock()
+
+	ordered := make([]AccountScheduleCandidate, 0, len(candidates))
+	ordered = append(ordered, first[best])
+	rest := make([]AccountScheduleCandidate, 0, len(first)-1)
+	rest = append(rest, first[:best]...)
+	rest = append(rest, first[best+1:]...)
+	byCurrentWeight := func(items []AccountScheduleCandidate) {
+		sort.SliceStable(items, func(i, j int) bool {
+			return currentWeights[items[i].Account.ID] > currentWeights[items[j].Account.ID]
+		})
+	}
+	byCurrentWeight(rest)
+	ordered = append(ordered, rest...)
+	for _, tier := range tiers[1:] {
+		byCurrentWeight(tier)
+		ordered = append(ordered, tier...)
+	}
+	return ordered
+}
+
+func snapshotAccountCandidates(stats *AccountRuntimeStats, candidates []AccountScheduleCandidate) map[int64]AccountRuntimeSnapshot {
+	snapshots := make(map[int64]AccountRuntimeSnapshot, len(candidates))
+	for _, c := range candidates {
+		snapshots[c.Account.ID] = stats.Snapshot(c.Account.ID)
+	}
+	return snapshots
+}
+
+// accountCandidateTierKey 分层键：优先级，其次健康（错误率低于阈值）在前。
+func accountCandidateTierKey(c AccountScheduleCandidate, snapshots map[int64]AccountRuntimeSnapshot) (int, int) {
+	unhealthy := 0
+	if snapshots[c.Account.ID].ErrorRate >= accountSchedulerUnhealthyErrorRate {
+		unhealthy = 1
+	}
+	return c.Account.Priority, unhealthy
+}
+
+func splitAccountCandidateTiers(candidates []AccountScheduleCandidate, snapshots map[int64]AccountRuntimeSnapshot) [][]AccountScheduleCandidate {
+	sorted := append([]AccountScheduleCandidate(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, ui := accountCandidateTierKey(sorted[i], snapshots)
+		pj, uj := accountCandidateTierKey(sorted[j], snapshots)
+		if pi != pj {
+			return pi < pj
+		}
+		return ui < uj
+	})
+	var tiers [][]AccountScheduleCandidate
+	for i := 0; i < len(sorted); {
+		pi, ui := accountCandidateTierKey(sorted[i], snapshots)
+		j := i + 1
+		for j < len(sorted) {
+			pj, uj := accountCandidateTierKey(sorted[j], snapshots)
+			if pj != pi || uj != ui {
+				break
+			}
+			j++
+		}
+		tiers = append(tiers, sorted[i:j])
+		i = j
+	}
+	return tiers
+}
+
+// orderAccountCandidates 按 分层（优先级、健康）→ compare → LRU 排序，完全相同的候选随机打乱，
+// 避免并发请求读取同一快照时全部命中同一账号。
+func orderAccountCandidates(candidates []AccountScheduleCandidate, snapshots map[int64]AccountRuntimeSnapshot, compare func(a, b AccountScheduleCandidate) int) []AccountScheduleCandidate {
+	ordered := append([]AccountScheduleCandidate(nil), candidates...)
+	mathrand.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		pa, ua := accountCandidateTierKey(a, snapshots)
+		pb, ub := accountCandidateTierKey(b, snapshots)
+		if pa != pb {
+			return pa < pb
+		}
+		if ua != ub {
+			return ua < ub
+		}
+		if cmp := compare(a, b); cmp != 0 {
+			return cmp < 0
+		}
+		switch {
+		case a.Account.LastUsedAt == nil || b.Account.LastUsedAt == nil:
+			return a.Account.LastUsedAt == nil && b.Account.LastUsedAt != nil
+		default:
+			return a.Account.LastUsedAt.Before(*b.Account.LastUsedAt)
+		}
+	})
+	return ordered
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func accountScheduleCandidatesFromLoads(accounts []accountWithLoad) []AccountScheduleCandidate {
+	candidates := make([]AccountScheduleCandidate, 0, len(accounts))
+	for _, acc := range accounts {
+		candidates = append(candidates, AccountScheduleCandidate{Account: acc.account, LoadInfo: acc.loadInfo})
+	}
+	return candidates
+}
+
+func accountsWithLoadFromCandidates(candidates []AccountScheduleCandidate) []accountWithLoad {
+	accounts := make([]accountWithLoad, 0, len(candidates))
+	for _, c := range candidates {
+		loadInfo := c.LoadInfo
+		if loadInfo == nil {
+			loadInfo = &AccountLoadInfo{AccountID: c.Account.ID}
+		}
+		accounts = append(accounts, accountWithLoad{account: c.Account, loadInfo: loadInfo})
+	}
+	return accounts
+}
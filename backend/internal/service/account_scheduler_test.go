@@ -0,0 +1,168 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scheduleCandidate(id int64, priority int, loadRate int) AccountScheduleCandidate {
+	return AccountScheduleCandidate{
+		Account:  &Account{ID: id, Priority: priority, Concurrency: 1},
+		LoadInfo: &AccountLoadInfo{AccountID: id, LoadRate: loadRate},
+	}
+}
+
+func scheduleOrderIDs(candidates []AccountScheduleCandidate) []int64 {
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.Account.ID)
+	}
+	return ids
+}
+
+func TestNormalizeAccountSchedulingStrategy(t *testing.T) {
+	got, err := NormalizeAccountSchedulingStrategy("  Least_Latency ")
+	require.NoError(t, err)
+	require.Equal(t, AccountSchedulingStrategyLeastLatency, got)
+
+	got, err = NormalizeAccountSchedulingStrategy("")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = NormalizeAccountSchedulingStrategy("random")
+	require.ErrorContains(t, err, "invalid scheduling_strategy")
+
+	require.Equal(t, []string{
+		AccountSchedulingStrategyCostAware,
+		AccountSchedulingStrategyDefault,
+		AccountSchedulingStrategyLeastLatency,
+		AccountSchedulingStrategyWeightedRoundRobin,
+	}, AccountSchedulingStrategies())
+}
+
+func TestAccountSchedulers_ForGroupFallsBackToDefault(t *testing.T) {
+	schedulers := NewAccountSchedulers(nil)
+	require.True(t, IsDefaultAccountScheduler(schedulers.ForGroup(nil)))
+	require.True(t, IsDefaultAccountScheduler(schedulers.ForGroup(&Group{SchedulingStrategy: "unknown"})))
+	require.Equal(t, AccountSchedulingStrategyCostAware, schedulers.ForGroup(&Group{SchedulingStrategy: AccountSchedulingStrategyCostAware}).Name())
+	require.Same(t, schedulers.ForStrategy(AccountSchedulingStrategyWeightedRoundRobin), schedulers.ForStrategy(AccountSchedulingStrategyWeightedRoundRobin))
+
+	var nilSchedulers *AccountSchedulers
+	require.True(t, IsDefaultAccountScheduler(nilSchedulers.ForGroup(&Group{SchedulingStrategy: AccountSchedulingStrategyCostAware})))
+	nilSchedulers.ReportResult(1, true, nil)
+}
+
+func TestDefaultAccountScheduler_PriorityThenLoadThenLRU(t *testing.T) {
+	now := time.Now()
+	older := now.Add(-time.Hour)
+	a := scheduleCandidate(1, 1, 50)
+	b := scheduleCandidate(2, 1, 10)
+	b.Account.LastUsedAt = &now
+	c := scheduleCandidate(3, 1, 10)
+	c.Account.LastUsedAt = &older
+	d := scheduleCandidate(4, 0, 90)
+
+	ordered := defaultAccountScheduler{}.Order(AccountScheduleRequest{}, []AccountScheduleCandidate{a, b, c, d})
+	require.Equal(t, []int64{4, 3, 2, 1}, scheduleOrderIDs(ordered))
+}
+
+func TestDefaultAccountScheduler_PreferSoonestResetUsesRequestClock(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(10 * time.Minute)
+	later := now.Add(time.Hour)
+	a := scheduleCandidate(1, 1, 0)
+	a.Account.SessionWindowEnd = &later
+	b := scheduleCandidate(2, 1, 50)
+	b.Account.SessionWindowEnd = &soon
+
+	ordered := defaultAccountScheduler{}.Order(AccountScheduleRequest{PreferSoonestReset: true, Now: now}, []AccountScheduleCandidate{a, b})
+	require.Equal(t, []int64{2, 1}, scheduleOrderIDs(ordered))
+
+	// 虚拟时钟越过两个窗口后，回到按负载率排序
+	ordered = defaultAccountScheduler{}.Order(AccountScheduleRequest{PreferSoonestReset: true, Now: now.Add(2 * time.Hour)}, []AccountScheduleCandidate{a, b})
+	require.Equal(t, []int64{1, 2}, scheduleOrderIDs(ordered))
+}
+
+func TestLeastLatencyAccountScheduler_OrdersByTTFTAndDemotesErrors(t *testing.T) {
+	stats := NewAccountRuntimeStats()
+	stats.Report(1, true, intPtrForTest(900))
+	stats.Report(2, true, intPtrForTest(200))
+	stats.Report(3, true, intPtrForTest(100))
+	for i := 0; i < 20; i++ {
+		stats.Report(3, false, nil)
+	}
+	scheduler := NewAccountSchedulers(stats).ForStrategy(AccountSchedulingStrategyLeastLatency)
+
+	candidates := []AccountScheduleCandidate{
+		scheduleCandidate(1, 1, 0),
+		scheduleCandidate(2, 1, 0),
+		scheduleCandidate(3, 1, 0),
+		scheduleCandidate(4, 1, 0), // 无样本，取平均延迟
+	}
+	ordered := scheduler.Order(AccountScheduleRequest{}, candidates)
+	require.Equal(t, []int64{2, 4, 1, 3}, scheduleOrderIDs(ordered))
+}
+
+func TestLeastLatencyAccountScheduler_PriorityTierFirst(t *testing.T) {
+	stats := NewAccountRuntimeStats()
+	stats.Report(1, true, intPtrForTest(100))
+	stats.Report(2, true, intPtrForTest(900))
+	scheduler := NewAccountSchedulers(stats).ForStrategy(AccountSchedulingStrategyLeastLatency)
+
+	ordered := scheduler.Order(AccountScheduleRequest{}, []AccountScheduleCandidate{
+		scheduleCandidate(1, 5, 0),
+		scheduleCandidate(2, 1, 0),
+	})
+	require.Equal(t, []int64{2, 1}, scheduleOrderIDs(ordered))
+}
+
+func TestCostAwareAccountScheduler_OrdersByCost(t *testing.T) {
+	scheduler := NewAccountSchedulers(nil).ForStrategy(AccountSchedulingStrategyCostAware)
+	expensive := scheduleCandidate(1, 1, 0)
+	expensive.Account.RateMultiplier = floatPtr(2)
+	cheap := scheduleCandidate(2, 1, 80)
+	cheap.Account.RateMultiplier = floatPtr(0.5)
+	standard := scheduleCandidate(3, 1, 0)
+
+	ordered := scheduler.Order(AccountScheduleRequest{}, []AccountScheduleCandidate{expensive, cheap, standard})
+	require.Equal(t, []int64{2, 3, 1}, scheduleOrderIDs(ordered))
+
+	ordered = scheduler.Order(AccountScheduleRequest{CostOf: func(a *Account) float64 { return float64(-a.ID) }}, []AccountScheduleCandidate{expensive, cheap, standard})
+	require.Equal(t, []int64{3, 2, 1}, scheduleOrderIDs(ordered))
+}
+
+func TestWeightedRoundRobinAccountScheduler_DistributesByWeight(t *testing.T) {
+	scheduler := NewAccountSchedulers(nil).ForStrategy(AccountSchedulingStrategyWeightedRoundRobin)
+	heavy := scheduleCandidate(1, 1, 0)
+	heavy.Account.Concurrency = 3
+	light := scheduleCandidate(2, 1, 0)
+	backup := scheduleCandidate(3, 2, 0)
+	backup.Account.Concurrency = 10
+	groupID := int64(7)
+
+	picks := map[int64]int{}
+	for i := 0; i < 8; i++ {
+		ordered := scheduler.Order(AccountScheduleRequest{GroupID: &groupID}, []AccountScheduleCandidate{heavy, light, backup})
+		require.Len(t, ordered, 3)
+		require.Equal(t, int64(3), ordered[2].Account.ID, "低优先级账号只作为后备")
+		picks[ordered[0].Account.ID]++
+	}
+	require.Equal(t, 6, picks[1])
+	require.Equal(t, 2, picks[2])
+}
+
+func TestWeightedRoundRobinAccountScheduler_StatePerGroup(t *testing.T) {
+	scheduler := NewAccountSchedulers(nil).ForStrategy(AccountSchedulingStrategyWeightedRoundRobin)
+	a := scheduleCandidate(1, 1, 0)
+	b := scheduleCandidate(2, 1, 0)
+	g1, g2 := int64(1), int64(2)
+
+	first := scheduler.Order(AccountScheduleRequest{GroupID: &g1}, []AccountScheduleCandidate{a, b})[0].Account.ID
+	second := scheduler.Order(AccountScheduleRequest{GroupID: &g1}, []AccountScheduleCandidate{a, b})[0].Account.ID
+	require.NotEqual(t, first, second)
+	require.Equal(t, first, scheduler.Order(AccountScheduleRequest{GroupID: &g2}, []AccountScheduleCandidate{a, b})[0].Account.ID)
+}
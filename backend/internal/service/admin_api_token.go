@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// AdminAPITokenPrefix 管理面 API Token 明文前缀，用于与 JWT（eyJ 开头）区分。
+const AdminAPITokenPrefix = "admtok_"
+
+var (
+	ErrAdminAPITokenNotFound      = infraerrors.NotFound("ADMIN_API_TOKEN_NOT_FOUND", "admin API token not found")
+	ErrAdminAPITokenInvalid       = infraerrors.Unauthorized("INVALID_ADMIN_TOKEN", "invalid admin API token")
+	ErrAdminAPITokenExpired       = infraerrors.Unauthorized("ADMIN_TOKEN_EXPIRED", "admin API token has expired")
+	ErrAdminAPITokenOwnerInactive = infraerrors.Unauthorized("ADMIN_TOKEN_OWNER_INACTIVE", "the administrator who created this token is no longer active")
+	ErrAdminAPITokenIPForbidden   = infraerrors.Forbidden("ADMIN_TOKEN_IP_FORBIDDEN", "client IP is not in the admin API token allowlist")
+	ErrAdminAPITokenInvalidName   = infraerrors.BadRequest("ADMIN_API_TOKEN_INVALID_NAME", "admin API token name must be 1-64 characters")
+	ErrAdminAPITokenNoScopes      = infraerrors.BadRequest("ADMIN_API_TOKEN_NO_SCOPES", "admin API token requires at least one scope")
+	ErrAdminAPITokenStepUpScope   = infraerrors.BadRequest("ADMIN_API_TOKEN_STEP_UP_SCOPE", "step-up bypass scopes must name a concrete resource covered by the token scopes")
+	ErrAdminAPITokenInvalidExpiry = infraerrors.BadRequest("ADMIN_API_TOKEN_INVALID_EXPIRY", "admin API token expiry must be in the future")
+)
+
+// AdminAPIToken 长期有效的管理面 API Token，供自动化脚本调用 /api/v1/admin/*。
+// 明文只在创建时返回一次，库中只保存 SHA-256 摘要。
+//
+// 只有完整管理员可以创建 Token。Token 以创建者身份执行（审计与业务日志中的操作者为创建者，
+// 并附带 Token ID），权限仅限 Scopes；创建者被降级、禁用或删除后 Token 立即失效。
+// StepUpScopes 为空时 Token 不能访问任何需要 step-up 2FA 的路由。
+type AdminAPIToken struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	TokenPrefix  string     `json:"token_prefix"`
+	Scopes       []string   `json:"scopes"`
+	StepUpScopes []string   `json:"step_up_scopes"` // 显式允许绕过 step-up 2FA 的 scope，须被 Scopes 覆盖
+	AllowedIPs   []string   `json:"allowed_ips"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP   string     `json:"last_used_ip"`
+	CreatedBy    int64      `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    *int64     `json:"revoked_by,omitempty"`
+}
+
+// IsActive 未吊销且未过期。
+func (t *AdminAPIToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// CreateAdminAPITokenInput 创建 Token 的参数。
+type CreateAdminAPITokenInput struct {
+	Name         string
+	Scopes       []string
+	StepUpScopes []string
+	AllowedIPs   []string
+	ExpiresAt    *time.Time
+}
+
+type AdminAPITokenRepository interface {
+	// Create 写入 Token 并回填 ID 与 CreatedAt。
+	Create(ctx context.Context, token *AdminAPIToken, tokenHash string) error
+	List(ctx context.Context) ([]AdminAPIToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*AdminAPIToken, error)
+	// Revoke 吊销 Token；已吊销的 Token 保持首次吊销信息不变。
+	Revoke(ctx context.Context, id, revokedBy int64, at time.Time) error
+	TouchLastUsed(ctx context.Context, id int64, clientIP string, at time.Time) error
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// adminAPITokenTouchInterval last_used_at 的最小刷新间隔，避免每个请求都写库。
+const adminAPITokenTouchInterval = time.Minute
+
+// AdminAPITokenAuth 一次 Token 认证的结果。
+type AdminAPITokenAuth struct {
+	Token       *AdminAPIToken
+	Owner       *User
+	Permissions *AdminPermissions
+	// StepUp 允许绕过 step-up 2FA 的权限（由 StepUpScopes 构造）。
+	StepUp *AdminPermissions
+}
+
+// AdminAPITokenService 管理面 API Token 的签发、吊销与认证。
+type AdminAPITokenService struct {
+	repo     AdminAPITokenRepository
+	userRepo UserRepository
+	now      func() time.Time
+}
+
+// NewAdminAPITokenService creates the admin API token service.
+func NewAdminAPITokenService(repo AdminAPITokenRepository, userRepo UserRepository) *AdminAPITokenService {
+	return &AdminAPITokenService{repo: repo, userRepo: userRepo, now: time.Now}
+}
+
+// Create 签发 Token，返回记录与只展示一次的明文。
+func (s *AdminAPITokenService) Create(ctx context.Context, input CreateAdminAPITokenInput, creatorID int64) (*AdminAPIToken, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 64 {
+		return nil, "", ErrAdminAPITokenInvalidName
+	}
+	scopes, err := NormalizeAdminScopes(input.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrAdminAPITokenNoScopes
+	}
+	stepUpScopes, err := normalizeAdminTokenStepUpScopes(input.StepUpScopes, NewAdminPermissions("", scopes))
+	if err != nil {
+		return nil, "", err
+	}
+	allowedIPs := make([]string, 0, len(input.AllowedIPs))
+	for _, pattern := range input.AllowedIPs {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			allowedIPs = append(allowedIPs, pattern)
+		}
+	}
+	if invalid := ip.ValidateIPPatterns(allowedIPs); len(invalid) > 0 {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidIPPattern, invalid)
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return nil, "", ErrAdminAPITokenInvalidExpiry
+	}
+
+	secret, err := randomHexString(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext := AdminAPITokenPrefix + secret
+	token := &AdminAPIToken{
+		Name:         name,
+		TokenPrefix:  plaintext[:len(AdminAPITokenPrefix)+6],
+		Scopes:       scopes,
+		StepUpScopes: stepUpScopes,
+		AllowedIPs:   allowedIPs,
+		ExpiresAt:    input.ExpiresAt,
+		CreatedBy:    creatorID,
+	}
+	if err := s.repo.Create(ctx, token, hashAdminAPIToken(plaintext)); err != nil {
+		return nil, "", err
+	}
+	return token, plaintext, nil
+}
+
+func (s *AdminAPITokenService) List(ctx context.Context) ([]AdminAPIToken, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *AdminAPITokenService) Revoke(ctx context.Context, id, actorID int64) error {
+	return s.repo.Revoke(ctx, id, actorID, s.now())
+}
+
+// Authenticate 校验明文 Token：存在、未吊销、未过期、来源 IP 在白名单内，且创建者仍是活跃的完整管理员。
+func (s *AdminAPITokenService) Authenticate(ctx context.Context, plaintext, clientIP string) (*AdminAPITokenAuth, error) {
+	if !strings.HasPrefix(plaintext, AdminAPITokenPrefix) {
+		return nil, ErrAdminAPITokenInvalid
+	}
+	token, err := s.repo.GetByHash(ctx, hashAdminAPIToken(plaintext))
+	if err != nil {
+		if errors.Is(err, ErrAdminAPITokenNotFound) {
+			return nil, ErrAdminAPITokenInvalid
+		}
+		return nil, err
+	}
+	now := s.now()
+	if token.RevokedAt != nil {
+		return nil, ErrAdminAPITokenInvalid
+	}
+	if !token.IsActive(now) {
+		return nil, ErrAdminAPITokenExpired
+	}
+	if len(token.AllowedIPs) > 0 {
+		if allowed, _ := ip.CheckIPRestriction(clientIP, token.AllowedIPs, nil); !allowed {
+			return nil, ErrAdminAPITokenIPForbidden
+		}
+	}
+	owner, err := s.userRepo.GetByID(ctx, token.CreatedBy)
+	if err != nil || !owner.IsAdmin() || !owner.IsActive() {
+		return nil, ErrAdminAPITokenOwnerInactive
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= adminAPITokenTouchInterval || token.LastUsedIP != clientIP {
+		if err := s.repo.TouchLastUsed(ctx, token.ID, clientIP, now); err != nil {
+			logger.LegacyPrintf("service.admin_api_token", "touch last_used failed: token_id=%d err=%v", token.ID, err)
+		}
+	}
+	return &AdminAPITokenAuth{
+		Token:       token,
+		Owner:       owner,
+		Permissions: NewAdminPermissions("", token.Scopes),
+		StepUp:      NewAdminPermissions("", token.StepUpScopes),
+	}, nil
+}
+
+// normalizeAdminTokenStepUpScopes 校验 step-up 绕过 scope：必须指向具体资源（不允许通配），
+// 且不能超出 Token 本身的权限。
+func normalizeAdminTokenStepUpScopes(scopes []string, granted *AdminPermissions) ([]string, error) {
+	normalized, err := NormalizeAdminScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range normalized {
+		resource, action, _ := strings.Cut(scope, ":")
+		if resource == adminScopeAll || !granted.Allows(resource, action != AdminActionRead) {
+			return nil, ErrAdminAPITokenStepUpScope
+		}
+	}
+	return normalized, nil
+}
+
+func hashAdminAPIToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
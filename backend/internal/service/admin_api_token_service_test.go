@@ -0,0 +1,148 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type adminAPITokenRepoStub struct {
+	tokens  map[string]*AdminAPIToken
+	touched int
+}
+
+func (r *adminAPITokenRepoStub) Create(_ context.Context, token *AdminAPIToken, tokenHash string) error {
+	token.ID = int64(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	clone := *token
+	r.tokens[tokenHash] = &clone
+	return nil
+}
+
+func (r *adminAPITokenRepoStub) List(context.Context) ([]AdminAPIToken, error) {
+	out := make([]AdminAPIToken, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		out = append(out, *token)
+	}
+	return out, nil
+}
+
+func (r *adminAPITokenRepoStub) GetByHash(_ context.Context, tokenHash string) (*AdminAPIToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, ErrAdminAPITokenNotFound
+	}
+	clone := *token
+	return &clone, nil
+}
+
+func (r *adminAPITokenRepoStub) Revoke(_ context.Context, id, revokedBy int64, at time.Time) error {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.RevokedAt == nil {
+				token.RevokedAt, token.RevokedBy = &at, &revokedBy
+			}
+			return nil
+		}
+	}
+	return ErrAdminAPITokenNotFound
+}
+
+func (r *adminAPITokenRepoStub) TouchLastUsed(_ context.Context, id int64, clientIP string, at time.Time) error {
+	r.touched++
+	for _, token := range r.tokens {
+		if token.ID == id {
+			token.LastUsedAt, token.LastUsedIP = &at, clientIP
+		}
+	}
+	return nil
+}
+
+func TestAdminAPITokenServiceCreateValidation(t *testing.T) {
+	svc := NewAdminAPITokenService(&adminAPITokenRepoStub{tokens: map[string]*AdminAPIToken{}}, &mockUserRepo{})
+	ctx := context.Background()
+
+	_, _, err := svc.Create(ctx, CreateAdminAPITokenInput{Name: " ", Scopes: []string{"users:read"}}, 1)
+	require.ErrorIs(t, err, ErrAdminAPITokenInvalidName)
+	_, _, err = svc.Create(ctx, CreateAdminAPITokenInput{Name: "ci"}, 1)
+	require.ErrorIs(t, err, ErrAdminAPITokenNoScopes)
+	// step-up 绕过 scope 必须是具体资源且被 Token scope 覆盖
+	_, _, err = svc.Create(ctx, CreateAdminAPITokenInput{Name: "ci", Scopes: []string{"accounts:read"}, StepUpScopes: []string{"accounts:write"}}, 1)
+	require.ErrorIs(t, err, ErrAdminAPITokenStepUpScope)
+	_, _, err = svc.Create(ctx, CreateAdminAPITokenInput{Name: "ci", Scopes: []string{"*"}, StepUpScopes: []string{"*:write"}}, 1)
+	require.ErrorIs(t, err, ErrAdminAPITokenStepUpScope)
+	_, _, err = svc.Create(ctx, CreateAdminAPITokenInput{Name: "ci", Scopes: []string{"users:read"}, AllowedIPs: []string{"not-an-ip"}}, 1)
+	require.ErrorIs(t, err, ErrInvalidIPPattern)
+	past := time.Now().Add(-time.Hour)
+	_, _, err = svc.Create(ctx, CreateAdminAPITokenInput{Name: "ci", Scopes: []string{"users:read"}, ExpiresAt: &past}, 1)
+	require.ErrorIs(t, err, ErrAdminAPITokenInvalidExpiry)
+
+	token, plaintext, err := svc.Create(ctx, CreateAdminAPITokenInput{
+		Name:         "provisioning",
+		Scopes:       []string{"read-only", "accounts:write"},
+		StepUpScopes: []string{"accounts:read"},
+	}, 1)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(plaintext, AdminAPITokenPrefix))
+	require.Equal(t, plaintext[:len(token.TokenPrefix)], token.TokenPrefix)
+	require.Equal(t, []string{"*:read", "accounts:write"}, token.Scopes)
+	require.Equal(t, []string{"accounts:read"}, token.StepUpScopes)
+}
+
+func TestAdminAPITokenServiceAuthenticate(t *testing.T) {
+	repo := &adminAPITokenRepoStub{tokens: map[string]*AdminAPIToken{}}
+	userRepo := &mockUserRepo{getByIDUser: &User{ID: 1, Role: RoleAdmin, Status: StatusActive}}
+	svc := NewAdminAPITokenService(repo, userRepo)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	expires := now.Add(time.Hour)
+	token, plaintext, err := svc.Create(ctx, CreateAdminAPITokenInput{
+		Name:       "provisioning",
+		Scopes:     []string{"users:write"},
+		AllowedIPs: []string{"10.0.0.0/8"},
+		ExpiresAt:  &expires,
+	}, 1)
+	require.NoError(t, err)
+
+	auth, err := svc.Authenticate(ctx, plaintext, "10.1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), auth.Owner.ID)
+	require.True(t, auth.Permissions.Allows(AdminResourceUsers, true))
+	require.False(t, auth.Permissions.Allows(AdminResourceAccounts, false))
+	require.False(t, auth.StepUp.Allows(AdminResourceUsers, true))
+	require.Equal(t, 1, repo.touched)
+
+	// 一分钟内同 IP 重复使用不再写 last_used
+	_, err = svc.Authenticate(ctx, plaintext, "10.1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, 1, repo.touched)
+
+	_, err = svc.Authenticate(ctx, plaintext, "192.168.1.1")
+	require.ErrorIs(t, err, ErrAdminAPITokenIPForbidden)
+	_, err = svc.Authenticate(ctx, plaintext+"x", "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminAPITokenInvalid)
+	_, err = svc.Authenticate(ctx, "eyJhbGciOi", "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminAPITokenInvalid)
+
+	// 创建者被降级后 Token 立即失效
+	userRepo.getByIDUser = &User{ID: 1, Role: RoleUser, Status: StatusActive}
+	_, err = svc.Authenticate(ctx, plaintext, "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminAPITokenOwnerInactive)
+	userRepo.getByIDUser = &User{ID: 1, Role: RoleAdmin, Status: StatusActive}
+
+	svc.now = func() time.Time { return expires }
+	_, err = svc.Authenticate(ctx, plaintext, "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminAPITokenExpired)
+
+	svc.now = func() time.Time { return now }
+	require.NoError(t, svc.Revoke(ctx, token.ID, 1))
+	_, err = svc.Authenticate(ctx, plaintext, "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminAPITokenInvalid)
+}
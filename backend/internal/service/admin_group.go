@@ -332,6 +332,7 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 	videoPrice720P := normalizePrice(input.VideoPrice720P)
 	videoPrice1080P := normalizePrice(input.VideoPrice1080P)
 	webSearchPricePerCall := normalizePrice(input.WebSearchPricePerCall)
+	webFetchPricePerCall := normalizePrice(input.WebFetchPricePerCall)
 	searchPricePer1k := normalizePrice(input.SearchPricePer1k)
 	audioRealtimePricePerMin := normalizePrice(input.AudioRealtimePricePerMin)
 	audioTTSPricePerMillionChars := normalizePrice(input.AudioTTSPricePerMillionChars)
@@ -362,6 +363,42 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 	if batchImageHoldMultiplier < batchImageDiscountMultiplier {
 		return nil, errors.New("batch_image_hold_multiplier must be >= batch_image_discount_multiplier")
 	}
+	batchAPIDiscountMultiplier := defaultBatchAPIDiscountMultiplier
+	if input.BatchAPIDiscountMultiplier != nil {
+		if *input.BatchAPIDiscountMultiplier < 0 {
+			return nil, errors.New("batch_api_discount_multiplier must be >= 0")
+		}
+		batchAPIDiscountMultiplier = *input.BatchAPIDiscountMultiplier
+	}
+	batchAPIHoldMultiplier := defaultBatchAPIHoldMultiplier
+	if input.BatchAPIHoldMultiplier != nil {
+		if *input.BatchAPIHoldMultiplier < 0 {
+			return nil, errors.New("batch_api_hold_multiplier must be >= 0")
+		}
+		batchAPIHoldMultiplier = *input.BatchAPIHoldMultiplier
+	}
+	// 与批量生图相同的不变式：冻结比例低于折扣比例时结算会超出冻结额。
+	if batchAPIHoldMultiplier < batchAPIDiscountMultiplier {
+		return nil, errors.New("batch_api_hold_multiplier must be >= batch_api_discount_multiplier")
+	}
+	responseCacheTTLSeconds := 0
+	if input.ResponseCacheTTLSeconds != nil {
+		if *input.ResponseCacheTTLSeconds < 0 {
+			return nil, errors.New("response_cache_ttl_seconds must be >= 0")
+		}
+		responseCacheTTLSeconds = *input.ResponseCacheTTLSeconds
+	}
+	responseCachePriceMultiplier := 0.0
+	if input.ResponseCachePriceMultiplier != nil {
+		if *input.ResponseCachePriceMultiplier < 0 {
+			return nil, errors.New("response_cache_price_multiplier must be >= 0")
+		}
+		responseCachePriceMultiplier = *input.ResponseCachePriceMultiplier
+	}
+	schedulingStrategy, err := NormalizeAccountSchedulingStrategy(input.SchedulingStrategy)
+	if err != nil {
+		return nil, err
+	}
 	videoRateMultiplier := 1.0
 	if input.VideoRateMultiplier != nil {
 		if *input.VideoRateMultiplier < 0 {
@@ -471,6 +508,13 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		ImageRateMultiplier:             imageRateMultiplier,
 		BatchImageDiscountMultiplier:    batchImageDiscountMultiplier,
 		BatchImageHoldMultiplier:        batchImageHoldMultiplier,
+		AllowBatchAPI:                   input.AllowBatchAPI && subscriptionType != SubscriptionTypeSubscription,
+		BatchAPIDiscountMultiplier:      batchAPIDiscountMultiplier,
+		BatchAPIHoldMultiplier:          batchAPIHoldMultiplier,
+		ResponseCacheEnabled:            input.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         responseCacheTTLSeconds,
+		ResponseCachePriceMultiplier:    responseCachePriceMultiplier,
+		SchedulingStrategy:              schedulingStrategy,
 		VideoRateIndependent:            input.VideoRateIndependent,
 		VideoRateMultiplier:             videoRateMultiplier,
 		PeakRateEnabled:                 peakRateEnabled,
@@ -488,6 +532,7 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		VideoPrice1080P:                 videoPrice1080P,
 		VideoModelPrices:                NormalizeVideoModelPrices(input.VideoModelPrices),
 		WebSearchPricePerCall:           webSearchPricePerCall,
+		WebFetchPricePerCall:            webFetchPricePerCall,
 		SearchPricePer1k:                searchPricePer1k,
 		AudioRealtimePricePerMin:        audioRealtimePricePerMin,
 		AudioTTSPricePerMillionChars:    audioTTSPricePerMillionChars,
@@ -722,6 +767,51 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 		group.BatchImageHoldMultiplier < group.BatchImageDiscountMultiplier {
 		return nil, errors.New("batch_image_hold_multiplier must be >= batch_image_discount_multiplier")
 	}
+	if input.AllowBatchAPI != nil {
+		group.AllowBatchAPI = *input.AllowBatchAPI
+	}
+	// 订阅分组按额度而非余额计费，无法冻结余额，不支持 Batch API。
+	if group.SubscriptionType == SubscriptionTypeSubscription {
+		group.AllowBatchAPI = false
+	}
+	if input.BatchAPIDiscountMultiplier != nil {
+		if *input.BatchAPIDiscountMultiplier < 0 {
+			return nil, errors.New("batch_api_discount_multiplier must be >= 0")
+		}
+		group.BatchAPIDiscountMultiplier = *input.BatchAPIDiscountMultiplier
+	}
+	if input.BatchAPIHoldMultiplier != nil {
+		if *input.BatchAPIHoldMultiplier < 0 {
+			return nil, errors.New("batch_api_hold_multiplier must be >= 0")
+		}
+		group.BatchAPIHoldMultiplier = *input.BatchAPIHoldMultiplier
+	}
+	if (input.BatchAPIDiscountMultiplier != nil || input.BatchAPIHoldMultiplier != nil) &&
+		group.BatchAPIHoldMultiplier < group.BatchAPIDiscountMultiplier {
+		return nil, errors.New("batch_api_hold_multiplier must be >= batch_api_discount_multiplier")
+	}
+	if input.ResponseCacheEnabled != nil {
+		group.ResponseCacheEnabled = *input.ResponseCacheEnabled
+	}
+	if input.ResponseCacheTTLSeconds != nil {
+		if *input.ResponseCacheTTLSeconds < 0 {
+			return nil, errors.New("response_cache_ttl_seconds must be >= 0")
+		}
+		group.ResponseCacheTTLSeconds = *input.ResponseCacheTTLSeconds
+	}
+	if input.ResponseCachePriceMultiplier != nil {
+		if *input.ResponseCachePriceMultiplier < 0 {
+			return nil, errors.New("response_cache_price_multiplier must be >= 0")
+		}
+		group.ResponseCachePriceMultiplier = *input.ResponseCachePriceMultiplier
+	}
+	if input.SchedulingStrategy != nil {
+		schedulingStrategy, err := NormalizeAccountSchedulingStrategy(*input.SchedulingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		group.SchedulingStrategy = schedulingStrategy
+	}
 	if input.VideoRateIndependent != nil {
 		group.VideoRateIndependent = *input.VideoRateIndependent
 	}
@@ -790,6 +880,9 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.WebSearchPricePerCall != nil {
 		group.WebSearchPricePerCall = normalizePrice(input.WebSearchPricePerCall)
 	}
+	if input.WebFetchPricePerCall != nil {
+		group.WebFetchPricePerCall = normalizePrice(input.WebFetchPricePerCall)
+	}
 	if input.SearchPricePer1k != nil {
 		group.SearchPricePer1k = normalizePrice(input.SearchPricePer1k)
 	}
@@ -1247,6 +1340,25 @@ func (s *adminServiceImpl) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return apiKey, nil
 }
 
+// AdminUpdateAPIKeyModelPolicy 管理员修改 API Key 的模型允许/禁止列表与别名
+func (s *adminServiceImpl) AdminUpdateAPIKeyModelPolicy(ctx context.Context, keyID int64, update APIKeyModelPolicyUpdate) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := update.apply(apiKey)
+	if err != nil || !changed {
+		return apiKey, err
+	}
+	if err := s.apiKeyRepo.Update(ctx, apiKey, APIKeyUpdateFields{ModelPolicy: true}); err != nil {
+		return nil, fmt.Errorf("update api key model policy: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
 // ReplaceUserGroup 替换用户的专属分组
 func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error) {
 	if oldGroupID == newGroupID {
@@ -123,6 +123,13 @@ func cloneGroupForDuplicate(source *Group, operationID string) *Group {
 		ImagePrice4K:                    cloneGroupValuePointer(source.ImagePrice4K),
 		BatchImageDiscountMultiplier:    source.BatchImageDiscountMultiplier,
 		BatchImageHoldMultiplier:        source.BatchImageHoldMultiplier,
+		AllowBatchAPI:                   source.AllowBatchAPI,
+		BatchAPIDiscountMultiplier:      source.BatchAPIDiscountMultiplier,
+		BatchAPIHoldMultiplier:          source.BatchAPIHoldMultiplier,
+		ResponseCacheEnabled:            source.ResponseCacheEnabled,
+		ResponseCacheTTLSeconds:         source.ResponseCacheTTLSeconds,
+		ResponseCachePriceMultiplier:    source.ResponseCachePriceMultiplier,
+		SchedulingStrategy:              source.SchedulingStrategy,
 		VideoRateIndependent:            source.VideoRateIndependent,
 		VideoRateMultiplier:             source.VideoRateMultiplier,
 		VideoPrice480P:                  cloneGroupValuePointer(source.VideoPrice480P),
@@ -130,6 +137,7 @@ func cloneGroupForDuplicate(source *Group, operationID string) *Group {
 		VideoPrice1080P:                 cloneGroupValuePointer(source.VideoPrice1080P),
 		VideoModelPrices:                cloneGroupVideoModelPrices(source.VideoModelPrices),
 		WebSearchPricePerCall:           cloneGroupValuePointer(source.WebSearchPricePerCall),
+		WebFetchPricePerCall:            cloneGroupValuePointer(source.WebFetchPricePerCall),
 		SearchPricePer1k:                cloneGroupValuePointer(source.SearchPricePer1k),
 		AudioRealtimePricePerMin:        cloneGroupValuePointer(source.AudioRealtimePricePerMin),
 		AudioTTSPricePerMillionChars:    cloneGroupValuePointer(source.AudioTTSPricePerMillionChars),
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var (
+	ErrAdminRoleNotFound       = infraerrors.NotFound("ADMIN_ROLE_NOT_FOUND", "admin role not found")
+	ErrAdminRoleNameExists     = infraerrors.Conflict("ADMIN_ROLE_NAME_EXISTS", "admin role name already exists")
+	ErrAdminRoleInUse          = infraerrors.Conflict("ADMIN_ROLE_IN_USE", "admin role is still assigned to users")
+	ErrAdminRoleBuiltin        = infraerrors.BadRequest("ADMIN_ROLE_BUILTIN", "built-in admin roles cannot be renamed or deleted")
+	ErrAdminRoleInvalidName    = infraerrors.BadRequest("ADMIN_ROLE_INVALID_NAME", "admin role name must be 1-64 characters of a-z, 0-9, '_' or '-'")
+	ErrAdminRoleInvalidScope   = infraerrors.BadRequest("ADMIN_ROLE_INVALID_SCOPE", "invalid admin permission scope")
+	ErrAdminRoleTargetIsAdmin  = infraerrors.BadRequest("ADMIN_ROLE_TARGET_IS_ADMIN", "administrators already have full access and cannot be assigned a staff role")
+	ErrAdminRoleTargetInactive = infraerrors.BadRequest("ADMIN_ROLE_TARGET_INACTIVE", "staff roles can only be assigned to active users")
+	ErrAdminPermissionDenied   = infraerrors.Forbidden("ADMIN_PERMISSION_DENIED", "admin permission denied")
+	ErrSuperAdminRequired      = infraerrors.Forbidden("SUPER_ADMIN_REQUIRED", "this operation requires a full administrator")
+)
+
+// 权限动作。scope 形如 "<resource>:<action>"，write 隐含 read；
+// resource 或 action 为 "*" 表示通配（如 "*:read" 为全局只读）。
+const (
+	AdminActionRead  = "read"
+	AdminActionWrite = "write"
+	adminScopeAll    = "*"
+
+	// AdminScopeReadOnly 全局只读的别名，等价于 "*:read"。
+	AdminScopeReadOnly = "read-only"
+)
+
+// 管理面资源，与 routes/admin.go（及 admin/payment）中的路由分组一一对应。
+const (
+	AdminResourceDashboard        = "dashboard"
+	AdminResourceUsers            = "users"
+	AdminResourceGroups           = "groups"
+	AdminResourceAccounts         = "accounts"
+	AdminResourceAnnouncements    = "announcements"
+	AdminResourceProxies          = "proxies"
+	AdminResourceRedeemCodes      = "redeem_codes"
+	AdminResourcePromoCodes       = "promo_codes"
+	AdminResourceSettings         = "settings"
+	AdminResourceDataManagement   = "data_management"
+	AdminResourceBackups          = "backups"
+	AdminResourceOps              = "ops"
+	AdminResourceSystem           = "system"
+	AdminResourceSubscriptions    = "subscriptions"
+	AdminResourceUsage            = "usage"
+	AdminResourceErrorPassthrough = "error_passthrough"
+	AdminResourceTLSFingerprints  = "tls_fingerprints"
+	AdminResourceAPIKeys          = "api_keys"
+	AdminResourceScheduledTests   = "scheduled_tests"
+	AdminResourceChannels         = "channels"
+	AdminResourceChannelMonitors  = "channel_monitors"
+	AdminResourceRiskControl      = "risk_control"
+	AdminResourcePromptAudit      = "prompt_audit"
+	AdminResourceAffiliates       = "affiliates"
+	AdminResourceOrganizations    = "organizations"
+	AdminResourceBudgets          = "budgets"
+	AdminResourceBalanceLedger    = "balance_ledger"
+	AdminResourceInvoices         = "invoices"
+	AdminResourceUsageExport      = "usage_export"
+	AdminResourceAuditLogs        = "audit_logs"
+	AdminResourcePayments         = "payments"
+)
+
+// AdminResource 权限目录中的一项，供管理端展示可授予的 scope。
+type AdminResource struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// AdminResources 全部可授予的管理面资源（顺序即展示顺序）。
+// 角色管理本身（/admin/rbac）不在目录中：只有完整管理员可以管理角色，避免员工给自己提权。
+var AdminResources = []AdminResource{
+	{AdminResourceDashboard, "Admin dashboard statistics"},
+	{AdminResourceUsers, "Users, user attributes and per-user usage"},
+	{AdminResourceGroups, "Groups"},
+	{AdminResourceAccounts, "Upstream accounts, OAuth flows and provider quotas (includes credentials)"},
+	{AdminResourceAnnouncements, "Announcements"},
+	{AdminResourceProxies, "Proxies"},
+	{AdminResourceRedeemCodes, "Redeem codes"},
+	{AdminResourcePromoCodes, "Promo codes"},
+	{AdminResourceSettings, "System settings and SSO (includes the admin API key; effectively full access)"},
+	{AdminResourceDataManagement, "Data management"},
+	{AdminResourceBackups, "Database backups and restore"},
+	{AdminResourceOps, "Ops monitoring, alerts and logs"},
+	{AdminResourceSystem, "System version and updates"},
+	{AdminResourceSubscriptions, "Subscriptions"},
+	{AdminResourceUsage, "Usage records"},
+	{AdminResourceErrorPassthrough, "Error passthrough rules"},
+	{AdminResourceTLSFingerprints, "TLS fingerprint profiles"},
+	{AdminResourceAPIKeys, "User API keys"},
+	{AdminResourceScheduledTests, "Scheduled test plans"},
+	{AdminResourceChannels, "Channels"},
+	{AdminResourceChannelMonitors, "Channel monitors"},
+	{AdminResourceRiskControl, "Risk control"},
+	{AdminResourcePromptAudit, "Prompt audit"},
+	{AdminResourceAffiliates, "Affiliates"},
+	{AdminResourceOrganizations, "Organizations"},
+	{AdminResourceBudgets, "Budgets"},
+	{AdminResourceBalanceLedger, "Balance ledger reconciliation"},
+	{AdminResourceInvoices, "Invoices and billing profiles"},
+	{AdminResourceUsageExport, "Usage export"},
+	{AdminResourceAuditLogs, "Audit logs"},
+	{AdminResourcePayments, "Payment orders, plans and providers"},
+}
+
+var adminResourceSet = func() map[string]struct{} {
+	set := make(map[string]struct{}, len(AdminResources))
+	for _, r := range AdminResources {
+		set[r.Key] = struct{}{}
+	}
+	return set
+}()
+
+// AdminRole 管理面员工角色：一组权限 scope。内置角色可调整权限，但不能改名或删除。
+type AdminRole struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	Builtin     bool      `json:"builtin"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AdminRoleAssignment 用户与员工角色的绑定（每个用户至多一个角色）。
+type AdminRoleAssignment struct {
+	UserID     int64     `json:"user_id"`
+	UserEmail  string    `json:"user_email"`
+	RoleID     int64     `json:"role_id"`
+	RoleName   string    `json:"role_name"`
+	AssignedBy *int64    `json:"assigned_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AdminRBACRepository interface {
+	ListRoles(ctx context.Context) ([]AdminRole, error)
+	GetRole(ctx context.Context, id int64) (*AdminRole, error)
+	// CreateRole 写入角色并回填 ID 与时间戳；重名返回 ErrAdminRoleNameExists。
+	CreateRole(ctx context.Context, role *AdminRole) error
+	UpdateRole(ctx context.Context, role *AdminRole) error
+	// DeleteRole 删除未被引用的角色；仍有用户绑定时返回 ErrAdminRoleInUse。
+	DeleteRole(ctx context.Context, id int64) error
+
+	ListAssignments(ctx context.Context) ([]AdminRoleAssignment, error)
+	// GetUserRole 返回用户绑定的角色，未绑定时返回 nil, nil。
+	GetUserRole(ctx context.Context, userID int64) (*AdminRole, error)
+	AssignRole(ctx context.Context, userID, roleID int64, assignedBy *int64) error
+	RemoveAssignment(ctx context.Context, userID int64) error
+}
+
+// AdminPermissions 一次管理面请求的有效权限。完整管理员（users.role = admin 或 admin API key）
+// 拥有全部权限，员工仅拥有所绑定角色的 scope。
+type AdminPermissions struct {
+	// Role 员工角色名；完整管理员为空。
+	Role   string
+	super  bool
+	scopes []string
+	grants map[string]string // resource → 最高动作（read/write）
+}
+
+// SuperAdminPermissions 返回完整管理员权限。
+func SuperAdminPermissions() *AdminPermissions {
+	return &AdminPermissions{super: true}
+}
+
+// NewAdminPermissions 由角色名与 scope 列表构造权限；无法识别的 scope 被忽略（按拒绝处理）。
+func NewAdminPermissions(role string, scopes []string) *AdminPermissions {
+	p := &AdminPermissions{Role: role, grants: make(map[string]string, len(scopes))}
+	for _, scope := range scopes {
+		resource, action, ok := parseAdminScope(scope)
+		if !ok {
+			continue
+		}
+		p.scopes = append(p.scopes, resource+":"+action)
+		if action == adminScopeAll {
+			action = AdminActionWrite
+		}
+		if p.grants[resource] != AdminActionWrite {
+			p.grants[resource] = action
+		}
+	}
+	return p
+}
+
+// IsSuper 是否为完整管理员。
+func (p *AdminPermissions) IsSuper() bool {
+	return p != nil && p.super
+}
+
+// Scopes 返回生效的 scope 列表；完整管理员返回 ["*"]。
+func (p *AdminPermissions) Scopes() []string {
+	if p == nil {
+		return []string{}
+	}
+	if p.super {
+		return []string{adminScopeAll}
+	}
+	return append([]string{}, p.scopes...)
+}
+
+// Allows 判断是否允许对 resource 执行读（write=false）或写（write=true）操作。
+func (p *AdminPermissions) Allows(resource string, write bool) bool {
+	if p == nil {
+		return false
+	}
+	if p.super {
+		return true
+	}
+	for _, key := range []string{resource, adminScopeAll} {
+		switch p.grants[key] {
+		case AdminActionWrite:
+			return true
+		case AdminActionRead:
+			if !write {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AdminScope 拼接 scope 字符串，如 AdminScope("users", true) == "users:write"。
+func AdminScope(resource string, write bool) string {
+	if write {
+		return resource + ":" + AdminActionWrite
+	}
+	return resource + ":" + AdminActionRead
+}
+
+// parseAdminScope 解析 "<resource>:<action>"；单独的 "*" 等价于 "*:*"，"read-only" 等价于 "*:read"。
+func parseAdminScope(scope string) (string, string, bool) {
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	switch scope {
+	case adminScopeAll:
+		return adminScopeAll, adminScopeAll, true
+	case AdminScopeReadOnly:
+		return adminScopeAll, AdminActionRead, true
+	}
+	resource, action, ok := strings.Cut(scope, ":")
+	if !ok {
+		return "", "", false
+	}
+	if _, known := adminResourceSet[resource]; !known && resource != adminScopeAll {
+		return "", "", false
+	}
+	switch action {
+	case AdminActionRead, AdminActionWrite, adminScopeAll:
+	default:
+		return "", "", false
+	}
+	return resource, action, true
+}
+
+// NormalizeAdminScopes 校验、去重并排序 scope 列表。
+func NormalizeAdminScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		resource, action, ok := parseAdminScope(scope)
+		if !ok {
+			return nil, infraerrors.Clone(ErrAdminRoleInvalidScope).WithMetadata(map[string]string{"scope": scope})
+		}
+		normalized := resource + ":" + action
+		if _, dup := seen[normalized]; dup {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		out = append(out, normalized)
+	}
+	sort.Strings(out)
+	return out, nil
+}
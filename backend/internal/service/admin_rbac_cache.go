@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/dgraph-io/ristretto"
+)
+
+// 员工权限本地缓存：管理面每个请求都要解析权限，命中缓存时不再查询数据库。
+// 角色或绑定变更时递增代数使全部条目失效，并通过 Pub/Sub 通知其他实例；
+// TTL 兜底广播丢失的情况。
+const (
+	adminPermCacheSize = 10000
+	adminPermCacheTTL  = 5 * time.Minute
+)
+
+// AdminRBACNotifier 跨实例广播员工角色/绑定变更
+type AdminRBACNotifier interface {
+	// NotifyUpdate 通知其他实例清空本地权限缓存
+	NotifyUpdate(ctx context.Context) error
+	// SubscribeUpdates 订阅变更通知
+	SubscribeUpdates(ctx context.Context, handler func())
+}
+
+// adminPermCacheEntry 缓存的解析结果；perms 为 nil 表示未绑定角色。
+type adminPermCacheEntry struct {
+	perms      *AdminPermissions
+	generation uint64
+}
+
+func (s *AdminRBACService) initPermCache() {
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: adminPermCacheSize * 10,
+		MaxCost:     adminPermCacheSize,
+		BufferItems: 64,
+	})
+	if err == nil {
+		s.permCache = cache
+	}
+	if s.notifier != nil {
+		s.notifier.SubscribeUpdates(context.Background(), func() {
+			s.permGeneration.Add(1)
+		})
+	}
+}
+
+// getCachedPermissions 仅返回与当前代数一致的条目；旧代数的条目视为未命中。
+func (s *AdminRBACService) getCachedPermissions(userID int64, generation uint64) (*AdminPermissions, bool) {
+	if s.permCache == nil {
+		return nil, false
+	}
+	val, ok := s.permCache.Get(userID)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := val.(*adminPermCacheEntry)
+	if !ok || entry.generation != generation {
+		return nil, false
+	}
+	return entry.perms, true
+}
+
+// setCachedPermissions 写入查询开始时读取的代数：查询期间发生变更时该条目会直接失效，
+// 不会把变更前的权限写回缓存。
+func (s *AdminRBACService) setCachedPermissions(userID int64, generation uint64, perms *AdminPermissions) {
+	if s.permCache == nil {
+		return
+	}
+	_ = s.permCache.SetWithTTL(userID, &adminPermCacheEntry{perms: perms, generation: generation}, 1, adminPermCacheTTL)
+}
+
+// invalidatePermCache 使本实例全部权限缓存失效并通知其他实例。
+func (s *AdminRBACService) invalidatePermCache(ctx context.Context) {
+	s.permGeneration.Add(1)
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.NotifyUpdate(ctx); err != nil {
+		logger.LegacyPrintf("service.admin_rbac", "[AdminRBACService] Failed to notify permission cache invalidation: %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type adminRBACRepoStub struct {
+	roles       map[int64]*AdminRole
+	assignments map[int64]int64
+	nextID      int64
+
+	getUserRoleCalls int
+}
+
+func newAdminRBACRepoStub(roles ...AdminRole) *adminRBACRepoStub {
+	r := &adminRBACRepoStub{roles: map[int64]*AdminRole{}, assignments: map[int64]int64{}, nextID: 100}
+	for i := range roles {
+		role := roles[i]
+		r.roles[role.ID] = &role
+	}
+	return r
+}
+
+func (r *adminRBACRepoStub) ListRoles(context.Context) ([]AdminRole, error) {
+	out := make([]AdminRole, 0, len(r.roles))
+	for _, role := range r.roles {
+		out = append(out, *role)
+	}
+	return out, nil
+}
+
+func (r *adminRBACRepoStub) GetRole(_ context.Context, id int64) (*AdminRole, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, ErrAdminRoleNotFound
+	}
+	clone := *role
+	return &clone, nil
+}
+
+func (r *adminRBACRepoStub) CreateRole(_ context.Context, role *AdminRole) error {
+	for _, existing := range r.roles {
+		if existing.Name == role.Name {
+			return ErrAdminRoleNameExists
+		}
+	}
+	r.nextID++
+	role.ID = r.nextID
+	clone := *role
+	r.roles[role.ID] = &clone
+	return nil
+}
+
+func (r *adminRBACRepoStub) UpdateRole(_ context.Context, role *AdminRole) error {
+	clone := *role
+	r.roles[role.ID] = &clone
+	return nil
+}
+
+func (r *adminRBACRepoStub) DeleteRole(_ context.Context, id int64) error {
+	for _, roleID := range r.assignments {
+		if roleID == id {
+			return ErrAdminRoleInUse
+		}
+	}
+	delete(r.roles, id)
+	return nil
+}
+
+func (r *adminRBACRepoStub) ListAssignments(context.Context) ([]AdminRoleAssignment, error) {
+	return nil, nil
+}
+
+func (r *adminRBACRepoStub) GetUserRole(_ context.Context, userID int64) (*AdminRole, error) {
+	r.getUserRoleCalls++
+	roleID, ok := r.assignments[userID]
+	if !ok {
+		return nil, nil
+	}
+	return r.GetRole(context.Background(), roleID)
+}
+
+func (r *adminRBACRepoStub) AssignRole(_ context.Context, userID, roleID int64, _ *int64) error {
+	r.assignments[userID] = roleID
+	return nil
+}
+
+func (r *adminRBACRepoStub) RemoveAssignment(_ context.Context, userID int64) error {
+	delete(r.assignments, userID)
+	return nil
+}
+
+type adminRBACNotifierStub struct {
+	notified int
+	handler  func()
+}
+
+func (n *adminRBACNotifierStub) NotifyUpdate(context.Context) error {
+	n.notified++
+	return nil
+}
+
+func (n *adminRBACNotifierStub) SubscribeUpdates(_ context.Context, handler func()) {
+	n.handler = handler
+}
+
+func TestAdminPermissionsAllows(t *testing.T) {
+	perms := NewAdminPermissions("support", []string{"users:read", "api_keys:write", "bogus:write", "accounts:admin"})
+
+	require.False(t, perms.IsSuper())
+	require.Equal(t, "support", perms.Role)
+	require.True(t, perms.Allows(AdminResourceUsers, false))
+	require.False(t, perms.Allows(AdminResourceUsers, true))
+	// write 隐含 read
+	require.True(t, perms.Allows(AdminResourceAPIKeys, false))
+	require.True(t, perms.Allows(AdminResourceAPIKeys, true))
+	// 未授予与无法识别的 scope 一律拒绝
+	require.False(t, perms.Allows(AdminResourceAccounts, false))
+	require.Equal(t, []string{"users:read", "api_keys:write"}, perms.Scopes())
+
+	readOnly := NewAdminPermissions("auditor", []string{"*:read"})
+	require.True(t, readOnly.Allows(AdminResourceSettings, false))
+	require.False(t, readOnly.Allows(AdminResourceSettings, true))
+
+	require.True(t, SuperAdminPermissions().Allows(AdminResourceSettings, true))
+	require.Equal(t, []string{"*"}, SuperAdminPermissions().Scopes())
+
+	var none *AdminPermissions
+	require.False(t, none.Allows(AdminResourceUsers, false))
+}
+
+func TestNormalizeAdminScopes(t *testing.T) {
+	scopes, err := NormalizeAdminScopes([]string{" Users:Read ", "payments:write", "users:read", "*"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"*:*", "payments:write", "users:read"}, scopes)
+
+	_, err = NormalizeAdminScopes([]string{"users"})
+	require.ErrorIs(t, err, ErrAdminRoleInvalidScope)
+	_, err = NormalizeAdminScopes([]string{"rbac:write"})
+	require.ErrorIs(t, err, ErrAdminRoleInvalidScope)
+	_, err = NormalizeAdminScopes([]string{"users:delete"})
+	require.ErrorIs(t, err, ErrAdminRoleInvalidScope)
+}
+
+func TestAdminRBACServiceResolvePermissions(t *testing.T) {
+	repo := newAdminRBACRepoStub(AdminRole{ID: 1, Name: "finance", Permissions: []string{"payments:write", "affiliates:write"}, Builtin: true})
+	repo.assignments[7] = 1
+	svc := NewAdminRBACService(repo, &mockUserRepo{}, nil)
+	ctx := context.Background()
+
+	perms, err := svc.ResolvePermissions(ctx, &User{ID: 1, Role: RoleAdmin})
+	require.NoError(t, err)
+	require.True(t, perms.IsSuper())
+
+	perms, err = svc.ResolvePermissions(ctx, &User{ID: 7, Role: RoleUser})
+	require.NoError(t, err)
+	require.Equal(t, "finance", perms.Role)
+	require.True(t, perms.Allows(AdminResourcePayments, true))
+	require.False(t, perms.Allows(AdminResourceSettings, false))
+
+	perms, err = svc.ResolvePermissions(ctx, &User{ID: 8, Role: RoleUser})
+	require.NoError(t, err)
+	require.Nil(t, perms)
+}
+
+func TestAdminRBACServiceResolvePermissionsCache(t *testing.T) {
+	repo := newAdminRBACRepoStub(AdminRole{ID: 1, Name: "support", Permissions: []string{"users:read"}, Builtin: true})
+	repo.assignments[7] = 1
+	notifier := &adminRBACNotifierStub{}
+	userRepo := &mockUserRepo{}
+	svc := NewAdminRBACService(repo, userRepo, notifier)
+	ctx := context.Background()
+	staff := &User{ID: 7, Role: RoleUser}
+
+	resolve := func() *AdminPermissions {
+		t.Helper()
+		perms, err := svc.ResolvePermissions(ctx, staff)
+		require.NoError(t, err)
+		svc.permCache.Wait()
+		return perms
+	}
+
+	require.False(t, resolve().Allows(AdminResourceUsers, true))
+	require.False(t, resolve().Allows(AdminResourceUsers, true))
+	require.Equal(t, 1, repo.getUserRoleCalls, "second resolve must be served from cache")
+
+	// 角色权限变更后立即生效
+	_, err := svc.UpdateRole(ctx, 1, AdminRoleInput{Permissions: []string{"users:write"}})
+	require.NoError(t, err)
+	require.True(t, resolve().Allows(AdminResourceUsers, true))
+	require.Equal(t, 2, repo.getUserRoleCalls)
+
+	// 解除绑定后不再拥有管理面权限
+	require.NoError(t, svc.RemoveAssignment(ctx, 7))
+	require.Nil(t, resolve())
+	require.Nil(t, resolve())
+	require.Equal(t, 3, repo.getUserRoleCalls)
+
+	userRepo.getByIDUser = &User{ID: 7, Role: RoleUser, Status: StatusActive}
+	require.NoError(t, svc.AssignRole(ctx, 7, 1, 1))
+	require.NotNil(t, resolve())
+	require.Equal(t, 3, notifier.notified)
+
+	// 其他实例的变更通知同样使本地缓存失效
+	repo.roles[1].Permissions = []string{"accounts:read"}
+	require.True(t, resolve().Allows(AdminResourceUsers, true))
+	notifier.handler()
+	perms := resolve()
+	require.False(t, perms.Allows(AdminResourceUsers, false))
+	require.True(t, perms.Allows(AdminResourceAccounts, false))
+}
+
+func TestAdminRBACServiceRoleLifecycle(t *testing.T) {
+	repo := newAdminRBACRepoStub(AdminRole{ID: 1, Name: "support", Permissions: []string{"users:read"}, Builtin: true})
+	svc := NewAdminRBACService(repo, &mockUserRepo{}, nil)
+	ctx := context.Background()
+
+	name := "Bad Name"
+	_, err := svc.CreateRole(ctx, AdminRoleInput{Name: &name})
+	require.ErrorIs(t, err, ErrAdminRoleInvalidName)
+
+	name = "billing-viewer"
+	role, err := svc.CreateRole(ctx, AdminRoleInput{Name: &name, Permissions: []string{"invoices:read", "balance_ledger:read"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"balance_ledger:read", "invoices:read"}, role.Permissions)
+
+	// 内置角色可调整权限但不能改名或删除
+	renamed := "helpdesk"
+	_, err = svc.UpdateRole(ctx, 1, AdminRoleInput{Name: &renamed})
+	require.ErrorIs(t, err, ErrAdminRoleBuiltin)
+	updated, err := svc.UpdateRole(ctx, 1, AdminRoleInput{Permissions: []string{"users:read", "api_keys:write"}})
+	require.NoError(t, err)
+	require.Equal(t, "support", updated.Name)
+	require.Equal(t, []string{"api_keys:write", "users:read"}, updated.Permissions)
+	require.ErrorIs(t, svc.DeleteRole(ctx, 1), ErrAdminRoleBuiltin)
+
+	repo.assignments[9] = role.ID
+	require.ErrorIs(t, svc.DeleteRole(ctx, role.ID), ErrAdminRoleInUse)
+	require.NoError(t, svc.RemoveAssignment(ctx, 9))
+	require.NoError(t, svc.DeleteRole(ctx, role.ID))
+}
+
+func TestAdminRBACServiceAssignRole(t *testing.T) {
+	repo := newAdminRBACRepoStub(AdminRole{ID: 1, Name: "ops", Permissions: []string{"accounts:write"}, Builtin: true})
+	userRepo := &mockUserRepo{}
+	svc := NewAdminRBACService(repo, userRepo, nil)
+	ctx := context.Background()
+
+	userRepo.getByIDUser = &User{ID: 2, Role: RoleAdmin, Status: StatusActive}
+	require.ErrorIs(t, svc.AssignRole(ctx, 2, 1, 1), ErrAdminRoleTargetIsAdmin)
+
+	userRepo.getByIDUser = &User{ID: 3, Role: RoleUser, Status: StatusDisabled}
+	require.ErrorIs(t, svc.AssignRole(ctx, 3, 1, 1), ErrAdminRoleTargetInactive)
+
+	userRepo.getByIDUser = &User{ID: 4, Role: RoleUser, Status: StatusActive}
+	require.ErrorIs(t, svc.AssignRole(ctx, 4, 99, 1), ErrAdminRoleNotFound)
+	require.NoError(t, svc.AssignRole(ctx, 4, 1, 1))
+	require.Equal(t, int64(1), repo.assignments[4])
+}
@@ -64,6 +64,7 @@ type AdminService interface {
 	// API Key management (admin)
 	AdminUpdateAPIKeyGroupID(ctx context.Context, keyID int64, groupID *int64) (*AdminUpdateAPIKeyGroupIDResult, error)
 	AdminResetAPIKeyRateLimitUsage(ctx context.Context, keyID int64) (*APIKey, error)
+	AdminUpdateAPIKeyModelPolicy(ctx context.Context, keyID int64, update APIKeyModelPolicyUpdate) (*APIKey, error)
 
 	// ReplaceUserGroup 替换用户的专属分组：授予新分组权限、迁移 Key、移除旧分组权限
 	ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error)
@@ -227,6 +228,13 @@ type CreateGroupInput struct {
 	ImageRateMultiplier          *float64
 	BatchImageDiscountMultiplier *float64
 	BatchImageHoldMultiplier     *float64
+	AllowBatchAPI                bool
+	BatchAPIDiscountMultiplier   *float64
+	BatchAPIHoldMultiplier       *float64
+	ResponseCacheEnabled         bool
+	ResponseCacheTTLSeconds      *int
+	ResponseCachePriceMultiplier *float64
+	SchedulingStrategy           string
 	VideoRateIndependent         bool
 	VideoRateMultiplier          *float64
 	// 高峰时段倍率配置（PeakRateMultiplier 为 nil 时按 1.0 处理）
@@ -244,6 +252,8 @@ type CreateGroupInput struct {
 	VideoModelPrices map[string]map[string]float64
 	// Codex alpha/search 网页搜索单次价格（USD/次，仅 openai 平台使用）；nil/负数按默认价 0.01 处理
 	WebSearchPricePerCall *float64
+	// 模拟 web_fetch 单次抓取价格（USD/次）；nil/负数按默认价 0.01 处理
+	WebFetchPricePerCall *float64
 	// 搜索工具单价 per 1k
 	SearchPricePer1k *float64
 	// Grok Voice 显式定价（分组级）
@@ -302,6 +312,13 @@ type UpdateGroupInput struct {
 	ImageRateMultiplier          *float64
 	BatchImageDiscountMultiplier *float64
 	BatchImageHoldMultiplier     *float64
+	AllowBatchAPI                *bool
+	BatchAPIDiscountMultiplier   *float64
+	BatchAPIHoldMultiplier       *float64
+	ResponseCacheEnabled         *bool
+	ResponseCacheTTLSeconds      *int
+	ResponseCachePriceMultiplier *float64
+	SchedulingStrategy           *string
 	VideoRateIndependent         *bool
 	VideoRateMultiplier          *float64
 	// 高峰时段倍率配置（nil 表示不修改）
@@ -319,6 +336,8 @@ type UpdateGroupInput struct {
 	VideoModelPrices map[string]map[string]float64
 	// Codex alpha/search 网页搜索单次价格（USD/次）；nil 表示不修改，负数表示清除回默认价 0.01
 	WebSearchPricePerCall *float64
+	// 模拟 web_fetch 单次抓取价格（USD/次）；nil 表示不修改，负数表示清除回默认价 0.01
+	WebFetchPricePerCall *float64
 	// 搜索工具单价；nil 不修改，负数清除
 	SearchPricePer1k *float64
 	// Grok Voice 显式定价；nil 表示不修改，负数表示清除
@@ -511,6 +511,11 @@ func (s *adminServiceImpl) UpdateUserBalance(ctx context.Context, userID int64,
 		change BalanceChange
 		err    error
 	)
+	ctx = WithDefaultBalanceLedgerSource(ctx, BalanceLedgerSource{
+		Reason:     BalanceLedgerReasonAdminAdjust,
+		SourceType: BalanceLedgerSourceAdmin,
+		Note:       notes,
+	})
 	switch operation {
 	case "set":
 		change, err = s.userRepo.SetBalance(ctx, userID, balance)
@@ -1172,6 +1177,8 @@ func normalizeAdminAuthIdentityProviderType(input string) string {
 		return "wechat"
 	case "dingtalk":
 		return "dingtalk"
+	case "saml":
+		return "saml"
 	default:
 		return ""
 	}
@@ -512,10 +512,10 @@ func (s *AntigravityGatewayService) antigravityRetryLoop(p antigravityRetryLoopP
 
 	var resp *http.Response
 	var usedBaseURL string
-	logBody := p.settingService != nil && p.settingService.cfg != nil && p.settingService.cfg.Gateway.LogUpstreamErrorBody
+	logBody := p.settingService != nil && p.settingService.cfg != nil && p.settingService.cfg.Live().Gateway.LogUpstreamErrorBody
 	maxBytes := 2048
-	if p.settingService != nil && p.settingService.cfg != nil && p.settingService.cfg.Gateway.LogUpstreamErrorBodyMaxBytes > 0 {
-		maxBytes = p.settingService.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+	if p.settingService != nil && p.settingService.cfg != nil && p.settingService.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes > 0 {
+		maxBytes = p.settingService.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 	}
 	getUpstreamDetail := func(body []byte) string {
 		if !logBody {
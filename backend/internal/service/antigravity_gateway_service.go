@@ -133,8 +133,8 @@ type AntigravityGatewayService struct {
 
 func (s *AntigravityGatewayService) upstreamErrorBodyReadLimit() int64 {
 	limit := gatewayUpstreamErrorBodyReadLimit
-	if s != nil && s.settingService != nil && s.settingService.cfg != nil && s.settingService.cfg.Gateway.LogUpstreamErrorBody && s.settingService.cfg.Gateway.LogUpstreamErrorBodyMaxBytes > int(limit) {
-		limit = int64(s.settingService.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+	if s != nil && s.settingService != nil && s.settingService.cfg != nil && s.settingService.cfg.Live().Gateway.LogUpstreamErrorBody && s.settingService.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes > int(limit) {
+		limit = int64(s.settingService.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 	}
 	return limit
 }
@@ -181,7 +181,7 @@ func (s *AntigravityGatewayService) getLogConfig() (logBody bool, maxBytes int)
 	if s.settingService == nil || s.settingService.cfg == nil {
 		return false, maxBytes
 	}
-	cfg := s.settingService.cfg.Gateway
+	cfg := s.settingService.cfg.Live().Gateway
 	if cfg.LogUpstreamErrorBodyMaxBytes > 0 {
 		maxBytes = cfg.LogUpstreamErrorBodyMaxBytes
 	}
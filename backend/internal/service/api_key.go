@@ -62,6 +62,24 @@ type APIKey struct {
 	Window5hStart *time.Time // Start of current 5h window
 	Window1dStart *time.Time // Start of current 1d window
 	Window7dStart *time.Time // Start of current 7d window
+
+	// ResponseCacheEnabled opts this key into the response cache even if its group has not.
+	ResponseCacheEnabled bool
+
+	// Model policy (see api_key_model_policy.go): aliases are resolved first, then the
+	// target model is checked against the deny and allow patterns.
+	ModelAllowlist []string
+	ModelDenylist  []string
+	ModelAliases   map[string]string
+
+	// OrganizationID marks a pooled organization key: balance billing charges the
+	// organization wallet instead of the creator's personal balance. Immutable.
+	OrganizationID *int64
+}
+
+// ChargesOrganization reports whether balance billing for this key goes to an organization wallet.
+func (k *APIKey) ChargesOrganization() bool {
+	return k != nil && k.OrganizationID != nil && *k.OrganizationID > 0
 }
 
 func (k *APIKey) IsActive() bool {
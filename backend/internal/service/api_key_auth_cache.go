@@ -26,6 +26,17 @@ type APIKeyAuthSnapshot struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// ResponseCacheEnabled is read by the response cache middleware before the handler runs.
+	ResponseCacheEnabled bool `json:"response_cache_enabled"`
+
+	// OrganizationID routes balance billing to the organization wallet.
+	OrganizationID *int64 `json:"organization_id,omitempty"`
+
+	// Model policy is enforced by the auth middleware before scheduling.
+	ModelAllowlist []string          `json:"model_allowlist,omitempty"`
+	ModelDenylist  []string          `json:"model_denylist,omitempty"`
+	ModelAliases   map[string]string `json:"model_aliases,omitempty"`
 }
 
 // APIKeyAuthUserSnapshot 用户快照
@@ -80,6 +91,7 @@ type APIKeyAuthGroupSnapshot struct {
 	VideoPrice1080P                 *float64                      `json:"video_price_1080p,omitempty"`
 	VideoModelPrices                map[string]map[string]float64 `json:"video_model_prices,omitempty"`
 	WebSearchPricePerCall           *float64                      `json:"web_search_price_per_call,omitempty"`
+	WebFetchPricePerCall            *float64                      `json:"web_fetch_price_per_call,omitempty"`
 	SearchPricePer1k                *float64                      `json:"search_price_per_1k,omitempty"`
 	AudioRealtimePricePerMin        *float64                      `json:"audio_realtime_price_per_min,omitempty"`
 	AudioTTSPricePerMillionChars    *float64                      `json:"audio_tts_price_per_million_chars,omitempty"`
@@ -132,6 +144,14 @@ type APIKeyAuthGroupSnapshot struct {
 	ProfitControlEnabled bool    `json:"profit_control_enabled"`
 	ProfitMinMargin      float64 `json:"profit_min_margin"`
 	ProfitSafetyBuffer   float64 `json:"profit_safety_buffer"`
+
+	// 响应缓存：网关中间件在进入 handler 前直接读快照里的分组配置决定是否查缓存。
+	ResponseCacheEnabled         bool    `json:"response_cache_enabled"`
+	ResponseCacheTTLSeconds      int     `json:"response_cache_ttl_seconds"`
+	ResponseCachePriceMultiplier float64 `json:"response_cache_price_multiplier"`
+
+	// 账号调度策略：网关选号时按分组策略排序候选账号，避免每次请求回查分组。
+	SchedulingStrategy string `json:"scheduling_strategy"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
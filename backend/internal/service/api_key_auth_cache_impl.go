@@ -14,7 +14,7 @@ import (
 	"github.com/dgraph-io/ristretto"
 )
 
-const apiKeyAuthSnapshotVersion = 20 // v20: group long-context and model pricing fields (force refresh of pre-fix snapshots)
+const apiKeyAuthSnapshotVersion = 25 // v25: api key model allowlist / denylist / aliases
 
 type apiKeyAuthCacheConfig struct {
 	l1Size        int
@@ -350,6 +350,12 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		RateLimit5h: apiKey.RateLimit5h,
 		RateLimit1d: apiKey.RateLimit1d,
 		RateLimit7d: apiKey.RateLimit7d,
+
+		ResponseCacheEnabled: apiKey.ResponseCacheEnabled,
+		OrganizationID:       apiKey.OrganizationID,
+		ModelAllowlist:       apiKey.ModelAllowlist,
+		ModelDenylist:        apiKey.ModelDenylist,
+		ModelAliases:         apiKey.ModelAliases,
 		User: APIKeyAuthUserSnapshot{
 			ID:                         apiKey.User.ID,
 			Status:                     apiKey.User.Status,
@@ -402,6 +408,7 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			VideoPrice1080P:                 apiKey.Group.VideoPrice1080P,
 			VideoModelPrices:                NormalizeVideoModelPrices(apiKey.Group.VideoModelPrices),
 			WebSearchPricePerCall:           apiKey.Group.WebSearchPricePerCall,
+			WebFetchPricePerCall:            apiKey.Group.WebFetchPricePerCall,
 			SearchPricePer1k:                apiKey.Group.SearchPricePer1k,
 			AudioRealtimePricePerMin:        apiKey.Group.AudioRealtimePricePerMin,
 			AudioTTSPricePerMillionChars:    apiKey.Group.AudioTTSPricePerMillionChars,
@@ -430,6 +437,10 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			ProfitControlEnabled:            apiKey.Group.ProfitControlEnabled,
 			ProfitMinMargin:                 apiKey.Group.ProfitMinMargin,
 			ProfitSafetyBuffer:              apiKey.Group.ProfitSafetyBuffer,
+			ResponseCacheEnabled:            apiKey.Group.ResponseCacheEnabled,
+			ResponseCacheTTLSeconds:         apiKey.Group.ResponseCacheTTLSeconds,
+			ResponseCachePriceMultiplier:    apiKey.Group.ResponseCachePriceMultiplier,
+			SchedulingStrategy:              apiKey.Group.SchedulingStrategy,
 		}
 	}
 	return snapshot
@@ -454,6 +465,12 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		RateLimit5h: snapshot.RateLimit5h,
 		RateLimit1d: snapshot.RateLimit1d,
 		RateLimit7d: snapshot.RateLimit7d,
+
+		ResponseCacheEnabled: snapshot.ResponseCacheEnabled,
+		OrganizationID:       snapshot.OrganizationID,
+		ModelAllowlist:       snapshot.ModelAllowlist,
+		ModelDenylist:        snapshot.ModelDenylist,
+		ModelAliases:         snapshot.ModelAliases,
 		User: &User{
 			ID:                         snapshot.User.ID,
 			Status:                     snapshot.User.Status,
@@ -499,6 +516,7 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			VideoPrice1080P:                 snapshot.Group.VideoPrice1080P,
 			VideoModelPrices:                NormalizeVideoModelPrices(snapshot.Group.VideoModelPrices),
 			WebSearchPricePerCall:           snapshot.Group.WebSearchPricePerCall,
+			WebFetchPricePerCall:            snapshot.Group.WebFetchPricePerCall,
 			SearchPricePer1k:                snapshot.Group.SearchPricePer1k,
 			AudioRealtimePricePerMin:        snapshot.Group.AudioRealtimePricePerMin,
 			AudioTTSPricePerMillionChars:    snapshot.Group.AudioTTSPricePerMillionChars,
@@ -527,6 +545,10 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			ProfitControlEnabled:            snapshot.Group.ProfitControlEnabled,
 			ProfitMinMargin:                 snapshot.Group.ProfitMinMargin,
 			ProfitSafetyBuffer:              snapshot.Group.ProfitSafetyBuffer,
+			ResponseCacheEnabled:            snapshot.Group.ResponseCacheEnabled,
+			ResponseCacheTTLSeconds:         snapshot.Group.ResponseCacheTTLSeconds,
+			ResponseCachePriceMultiplier:    snapshot.Group.ResponseCachePriceMultiplier,
+			SchedulingStrategy:              snapshot.Group.SchedulingStrategy,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	maxAPIKeyModelPatterns   = 100
+	maxAPIKeyModelAliases    = 50
+	maxAPIKeyModelNameLength = 200
+)
+
+var ErrInvalidModelPolicy = infraerrors.BadRequest("INVALID_MODEL_POLICY", "invalid model allowlist, denylist or alias")
+
+// APIKeyModelPolicyUpdate 描述一次对 Key 模型策略的修改；nil 表示不修改，空值表示清空。
+type APIKeyModelPolicyUpdate struct {
+	ModelAllowlist *[]string          `json:"model_allowlist"`
+	ModelDenylist  *[]string          `json:"model_denylist"`
+	ModelAliases   *map[string]string `json:"model_aliases"`
+}
+
+// IsEmpty 报告是否没有任何修改。
+func (u APIKeyModelPolicyUpdate) IsEmpty() bool {
+	return u.ModelAllowlist == nil && u.ModelDenylist == nil && u.ModelAliases == nil
+}
+
+// apply 校验并写入 apiKey，返回是否有列需要落库。
+func (u APIKeyModelPolicyUpdate) apply(apiKey *APIKey) (bool, error) {
+	if u.IsEmpty() {
+		return false, nil
+	}
+	allow, deny, aliases := apiKey.ModelAllowlist, apiKey.ModelDenylist, apiKey.ModelAliases
+	if u.ModelAllowlist != nil {
+		allow = *u.ModelAllowlist
+	}
+	if u.ModelDenylist != nil {
+		deny = *u.ModelDenylist
+	}
+	if u.ModelAliases != nil {
+		aliases = *u.ModelAliases
+	}
+	allow, deny, aliases, err := normalizeAPIKeyModelPolicy(allow, deny, aliases)
+	if err != nil {
+		return false, err
+	}
+	apiKey.ModelAllowlist, apiKey.ModelDenylist, apiKey.ModelAliases = allow, deny, aliases
+	return true, nil
+}
+
+// normalizeAPIKeyModelPolicy 去除空白与重复项，并校验模式语法与数量上限。
+// 模式语法与分组模型路由一致：精确匹配，或仅在末尾使用一个 * 做前缀匹配。
+func normalizeAPIKeyModelPolicy(allow, deny []string, aliases map[string]string) ([]string, []string, map[string]string, error) {
+	var err error
+	if allow, err = normalizeModelPatterns("model_allowlist", allow); err != nil {
+		return nil, nil, nil, err
+	}
+	if deny, err = normalizeModelPatterns("model_denylist", deny); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(aliases) > maxAPIKeyModelAliases {
+		return nil, nil, nil, fmt.Errorf("%w: model_aliases exceeds %d entries", ErrInvalidModelPolicy, maxAPIKeyModelAliases)
+	}
+	var out map[string]string
+	for alias, target := range aliases {
+		alias, target = strings.TrimSpace(alias), strings.TrimSpace(target)
+		if err := validateModelName(alias, false); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: alias %q: %s", ErrInvalidModelPolicy, alias, err.Error())
+		}
+		if err := validateModelName(target, false); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: alias %q target: %s", ErrInvalidModelPolicy, alias, err.Error())
+		}
+		if alias == target {
+			return nil, nil, nil, fmt.Errorf("%w: alias %q points to itself", ErrInvalidModelPolicy, alias)
+		}
+		if out == nil {
+			out = make(map[string]string, len(aliases))
+		}
+		out[alias] = target
+	}
+	// 别名只解析一层：目标本身不能再是别名，避免链式与环。
+	for alias, target := range out {
+		if _, chained := out[target]; chained {
+			return nil, nil, nil, fmt.Errorf("%w: alias %q points to another alias", ErrInvalidModelPolicy, alias)
+		}
+	}
+	return allow, deny, out, nil
+}
+
+func normalizeModelPatterns(field string, patterns []string) ([]string, error) {
+	if len(patterns) > maxAPIKeyModelPatterns {
+		return nil, fmt.Errorf("%w: %s exceeds %d entries", ErrInvalidModelPolicy, field, maxAPIKeyModelPatterns)
+	}
+	var out []string
+	seen := make(map[string]struct{}, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if err := validateModelName(pattern, true); err != nil {
+			return nil, fmt.Errorf("%w: %s %q: %s", ErrInvalidModelPolicy, field, pattern, err.Error())
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		out = append(out, pattern)
+	}
+	return out, nil
+}
+
+func validateModelName(name string, allowWildcard bool) error {
+	if name == "" {
+		return fmt.Errorf("empty")
+	}
+	if len(name) > maxAPIKeyModelNameLength {
+		return fmt.Errorf("longer than %d characters", maxAPIKeyModelNameLength)
+	}
+	if strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("contains whitespace")
+	}
+	if idx := strings.Index(name, "*"); idx >= 0 && (!allowWildcard || idx != len(name)-1) {
+		return fmt.Errorf("* is only allowed as the last character of a pattern")
+	}
+	return nil
+}
+
+// HasModelPolicy 报告该 Key 是否配置了任何模型限制或别名。
+func (k *APIKey) HasModelPolicy() bool {
+	return k != nil && (len(k.ModelAllowlist) > 0 || len(k.ModelDenylist) > 0 || len(k.ModelAliases) > 0)
+}
+
+// ResolveModelAlias 返回别名对应的目标模型；非别名原样返回。
+func (k *APIKey) ResolveModelAlias(model string) string {
+	if k == nil || len(k.ModelAliases) == 0 {
+		return model
+	}
+	if target, ok := k.ModelAliases[model]; ok {
+		return target
+	}
+	return model
+}
+
+// IsModelAllowed 判断（别名解析后的）模型是否允许使用：禁止列表优先，允许列表为空表示不限制。
+func (k *APIKey) IsModelAllowed(model string) bool {
+	if k == nil {
+		return true
+	}
+	for _, pattern := range k.ModelDenylist {
+		if matchModelPattern(pattern, model) {
+			return false
+		}
+	}
+	if len(k.ModelAllowlist) == 0 {
+		return true
+	}
+	for _, pattern := range k.ModelAllowlist {
+		if matchModelPattern(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterModelIDs 按 Key 的模型策略过滤模型列表（用于 /v1/models），
+// 并在末尾追加目标模型可用的别名。未配置策略时原样返回。
+func (k *APIKey) FilterModelIDs(models []string) []string {
+	if !k.HasModelPolicy() {
+		return models
+	}
+	out := make([]string, 0, len(models)+len(k.ModelAliases))
+	seen := make(map[string]struct{}, len(models)+len(k.ModelAliases))
+	for _, model := range models {
+		if _, ok := seen[model]; ok || !k.IsModelAllowed(k.ResolveModelAlias(model)) {
+			continue
+		}
+		seen[model] = struct{}{}
+		out = append(out, model)
+	}
+	aliases := make([]string, 0, len(k.ModelAliases))
+	for alias, target := range k.ModelAliases {
+		if _, ok := seen[alias]; !ok && k.IsModelAllowed(target) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return append(out, aliases...)
+}
@@ -0,0 +1,103 @@
+//go:build unit
+
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyModelPolicy(t *testing.T) {
+	allow, deny, aliases, err := normalizeAPIKeyModelPolicy(
+		[]string{" claude-haiku-* ", "", "claude-haiku-*", "gpt-4o-mini"},
+		[]string{"claude-haiku-3*"},
+		map[string]string{" fast ": " claude-haiku-4-5 "},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"claude-haiku-*", "gpt-4o-mini"}, allow)
+	require.Equal(t, []string{"claude-haiku-3*"}, deny)
+	require.Equal(t, map[string]string{"fast": "claude-haiku-4-5"}, aliases)
+
+	allow, deny, aliases, err = normalizeAPIKeyModelPolicy([]string{" "}, nil, map[string]string{})
+	require.NoError(t, err)
+	require.Nil(t, allow)
+	require.Nil(t, deny)
+	require.Nil(t, aliases)
+}
+
+func TestNormalizeAPIKeyModelPolicyRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		aliases map[string]string
+	}{
+		{name: "wildcard in the middle", allow: []string{"claude-*-haiku"}},
+		{name: "whitespace in pattern", allow: []string{"claude haiku"}},
+		{name: "too long", allow: []string{strings.Repeat("m", maxAPIKeyModelNameLength+1)}},
+		{name: "wildcard alias", aliases: map[string]string{"fast*": "claude-haiku-4-5"}},
+		{name: "wildcard target", aliases: map[string]string{"fast": "claude-haiku-*"}},
+		{name: "self alias", aliases: map[string]string{"fast": "fast"}},
+		{name: "chained alias", aliases: map[string]string{"fast": "quick", "quick": "claude-haiku-4-5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := normalizeAPIKeyModelPolicy(tt.allow, nil, tt.aliases)
+			require.True(t, errors.Is(err, ErrInvalidModelPolicy), "got %v", err)
+		})
+	}
+}
+
+func TestAPIKeyModelPolicyMatching(t *testing.T) {
+	key := &APIKey{
+		ModelAllowlist: []string{"claude-haiku-*", "gpt-4o-mini"},
+		ModelDenylist:  []string{"claude-haiku-3*"},
+		ModelAliases:   map[string]string{"fast": "claude-haiku-4-5", "old": "claude-haiku-3-5", "big": "claude-opus-4-1"},
+	}
+	require.True(t, key.HasModelPolicy())
+	require.Equal(t, "claude-haiku-4-5", key.ResolveModelAlias("fast"))
+	require.Equal(t, "gpt-4o", key.ResolveModelAlias("gpt-4o"))
+
+	require.True(t, key.IsModelAllowed("claude-haiku-4-5-20251001"))
+	require.True(t, key.IsModelAllowed("gpt-4o-mini"))
+	require.False(t, key.IsModelAllowed("gpt-4o"))
+	require.False(t, key.IsModelAllowed("claude-haiku-3-5"), "denylist wins over allowlist")
+
+	require.Equal(t,
+		[]string{"claude-haiku-4-5", "gpt-4o-mini", "fast"},
+		key.FilterModelIDs([]string{"claude-haiku-4-5", "claude-haiku-3-5", "claude-opus-4-1", "gpt-4o-mini"}),
+	)
+
+	var nilKey *APIKey
+	require.False(t, nilKey.HasModelPolicy())
+	require.True(t, nilKey.IsModelAllowed("anything"))
+	require.Equal(t, []string{"a"}, nilKey.FilterModelIDs([]string{"a"}))
+}
+
+func TestAPIKeyModelPolicyUpdateApply(t *testing.T) {
+	key := &APIKey{ModelAllowlist: []string{"claude-*"}, ModelAliases: map[string]string{"fast": "claude-haiku-4-5"}}
+
+	changed, err := APIKeyModelPolicyUpdate{}.apply(key)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	deny := []string{"claude-opus-*"}
+	changed, err = APIKeyModelPolicyUpdate{ModelDenylist: &deny}.apply(key)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, []string{"claude-*"}, key.ModelAllowlist, "untouched fields are preserved")
+	require.Equal(t, []string{"claude-opus-*"}, key.ModelDenylist)
+
+	empty := map[string]string{}
+	changed, err = APIKeyModelPolicyUpdate{ModelAliases: &empty}.apply(key)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Nil(t, key.ModelAliases)
+
+	bad := []string{"a*b"}
+	_, err = APIKeyModelPolicyUpdate{ModelAllowlist: &bad}.apply(key)
+	require.ErrorIs(t, err, ErrInvalidModelPolicy)
+	require.Equal(t, []string{"claude-*"}, key.ModelAllowlist, "failed update leaves the key unchanged")
+}
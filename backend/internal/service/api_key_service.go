@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"html"
 	"math"
@@ -75,6 +76,10 @@ type APIKeyUpdateFields struct {
 	RateLimitUsage bool
 	// IPRules 覆盖 ip_whitelist 与 ip_blacklist。
 	IPRules bool
+	// ResponseCache 覆盖 response_cache_enabled。
+	ResponseCache bool
+	// ModelPolicy 覆盖 model_allowlist、model_denylist 与 model_aliases。
+	ModelPolicy bool
 }
 
 // IsEmpty 报告该次 Update 是否不写任何列。
@@ -223,6 +228,17 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// ResponseCacheEnabled opts the key into the exact-match response cache.
+	ResponseCacheEnabled bool `json:"response_cache_enabled"`
+
+	// Model policy: allow / deny patterns and aliases (see api_key_model_policy.go).
+	ModelAllowlist []string          `json:"model_allowlist"`
+	ModelDenylist  []string          `json:"model_denylist"`
+	ModelAliases   map[string]string `json:"model_aliases"`
+
+	// OrganizationID creates a pooled key billed to the organization wallet (creator must be a member).
+	OrganizationID *int64 `json:"organization_id"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
@@ -244,6 +260,11 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	ResponseCacheEnabled *bool `json:"response_cache_enabled"` // nil = no change
+
+	// 模型策略（各字段 nil 不修改，空值清空）
+	APIKeyModelPolicyUpdate
 }
 
 func validateAPIKeyLimit(v float64) error {
@@ -291,6 +312,7 @@ type APIKeyService struct {
 	cache                     APIKeyCache
 	rateLimitCacheInvalid     RateLimitCacheInvalidator // optional: invalidate Redis rate limit cache
 	concurrencyService        *ConcurrencyService
+	organizationRepo          OrganizationRepository
 	cfg                       *config.Config
 	authCacheL1               *ristretto.Cache
 	authNegativeCacheL1       *ristretto.Cache
@@ -369,6 +391,32 @@ func (s *APIKeyService) SetConcurrencyService(concurrencyService *ConcurrencySer
 	s.concurrencyService = concurrencyService
 }
 
+// SetOrganizationRepository 注入组织仓储，用于创建组织共享 Key 时校验成员资格。
+func (s *APIKeyService) SetOrganizationRepository(repo OrganizationRepository) {
+	s.organizationRepo = repo
+}
+
+// validateOrganizationKey 组织 Key 只能由启用中组织的成员创建。
+func (s *APIKeyService) validateOrganizationKey(ctx context.Context, userID, orgID int64) error {
+	if s.organizationRepo == nil || s.cfg == nil || !s.cfg.Organization.Enabled {
+		return ErrOrganizationDisabled
+	}
+	org, err := s.organizationRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !org.IsActive() {
+		return ErrOrganizationDisabled
+	}
+	if _, err := s.organizationRepo.GetMember(ctx, orgID, userID); err != nil {
+		if errors.Is(err, ErrOrganizationMemberNotFound) {
+			return ErrOrganizationMembershipRequired
+		}
+		return err
+	}
+	return nil
+}
+
 func (s *APIKeyService) compileAPIKeyIPRules(apiKey *APIKey) {
 	if apiKey == nil {
 		return
@@ -482,6 +530,17 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	modelAllowlist, modelDenylist, modelAliases, err := normalizeAPIKeyModelPolicy(req.ModelAllowlist, req.ModelDenylist, req.ModelAliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.OrganizationID != nil {
+		if err := s.validateOrganizationKey(ctx, userID, *req.OrganizationID); err != nil {
+			return nil, err
+		}
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -544,6 +603,13 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		RateLimit5h: req.RateLimit5h,
 		RateLimit1d: req.RateLimit1d,
 		RateLimit7d: req.RateLimit7d,
+
+		ResponseCacheEnabled: req.ResponseCacheEnabled,
+		OrganizationID:       req.OrganizationID,
+
+		ModelAllowlist: modelAllowlist,
+		ModelDenylist:  modelDenylist,
+		ModelAliases:   modelAliases,
 	}
 
 	// Set expiration time if specified
@@ -882,6 +948,17 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		apiKey.RateLimit7d = *req.RateLimit7d
 		fields.RateLimits = true
 	}
+	if req.ResponseCacheEnabled != nil {
+		apiKey.ResponseCacheEnabled = *req.ResponseCacheEnabled
+		fields.ResponseCache = true
+	}
+	policyChanged, err := req.APIKeyModelPolicyUpdate.apply(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if policyChanged {
+		fields.ModelPolicy = true
+	}
 	resetRateLimit := req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage
 	if resetRateLimit {
 		apiKey.Usage5h = 0
@@ -20,6 +20,8 @@ const (
 	AuditAuthMethodJWT         = "jwt"
 	AuditAuthMethodAdminAPIKey = "admin_api_key"
 	AuditAuthMethodPasskey     = "passkey"
+	// AuditAuthMethodAdminAPIToken 管理面 API Token（以创建者身份执行）。
+	AuditAuthMethodAdminAPIToken = "admin_api_token"
 
 	// auditRequestBodyMaxBytes 请求体脱敏后入库的最大长度（字节），超出截断。
 	auditRequestBodyMaxBytes = 16 * 1024
@@ -46,6 +48,7 @@ type AuditLog struct {
 	ActorUserID      *int64         `json:"actor_user_id,omitempty"`
 	ActorEmail       string         `json:"actor_email"`
 	ActorRole        string         `json:"actor_role"`
+	AdminRole        string         `json:"admin_role"` // 员工角色名；完整管理员为空
 	AuthMethod       string         `json:"auth_method"`
 	CredentialMasked string         `json:"credential_masked"`
 	Action           string         `json:"action"`
@@ -70,6 +73,7 @@ type AuditLogFilter struct {
 	ActorUserID *int64
 	ActorEmail  string
 	AuthMethod  string
+	AdminRole   string
 	Action      string
 	Method      string
 	ClientIP    string
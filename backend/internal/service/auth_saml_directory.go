@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/ent/userallowedgroup"
+)
+
+// ApplySAMLDirectoryMapping applies the role and group mapping resolved from a
+// SAML assertion to the user the identity is being bound to. It runs on every
+// SAML login/bind so IdP group changes propagate; group grants are additive.
+func (s *AuthService) ApplySAMLDirectoryMapping(ctx context.Context, userID int64, claims map[string]any) error {
+	if s == nil || s.entClient == nil || userID <= 0 {
+		return nil
+	}
+	role, groupIDs := SAMLDirectoryMappingFromClaims(claims)
+	if role == "" && len(groupIDs) == 0 {
+		return nil
+	}
+
+	client := s.entClient
+	if tx := dbent.TxFromContext(ctx); tx != nil {
+		client = tx.Client()
+	}
+
+	if role != "" {
+		if err := client.User.UpdateOneID(userID).SetRole(role).Exec(ctx); err != nil {
+			return fmt.Errorf("apply saml role mapping: %w", err)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	// 映射里配置了已删除的分组时跳过，避免外键错误导致整个登录失败
+	existing, err := client.Group.Query().Where(group.IDIn(groupIDs...)).IDs(ctx)
+	if err != nil {
+		return fmt.Errorf("load saml mapped groups: %w", err)
+	}
+	for _, groupID := range existing {
+		if err := client.UserAllowedGroup.Create().
+			SetUserID(userID).
+			SetGroupID(groupID).
+			OnConflictColumns(userallowedgroup.FieldUserID, userallowedgroup.FieldGroupID).
+			DoNothing().
+			Exec(ctx); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("apply saml group mapping: %w", err)
+		}
+	}
+	return nil
+}
+
+// SAMLDirectoryMappingFromClaims reads the role / group IDs written by
+// SAMLIdentity.DirectoryClaims. Values may have round-tripped through JSON.
+func SAMLDirectoryMappingFromClaims(claims map[string]any) (string, []int64) {
+	if len(claims) == 0 {
+		return "", nil
+	}
+	role := ""
+	if raw, ok := claims[SAMLClaimRole].(string); ok {
+		switch strings.TrimSpace(raw) {
+		case RoleAdmin:
+			role = RoleAdmin
+		case RoleUser:
+			role = RoleUser
+		}
+	}
+
+	var groupIDs []int64
+	appendID := func(id int64) {
+		if id > 0 {
+			groupIDs = append(groupIDs, id)
+		}
+	}
+	switch values := claims[SAMLClaimGroupIDs].(type) {
+	case []int64:
+		for _, id := range values {
+			appendID(id)
+		}
+	case []any:
+		for _, value := range values {
+			switch v := value.(type) {
+			case int64:
+				appendID(v)
+			case int:
+				appendID(int64(v))
+			case float64:
+				appendID(int64(v))
+			case string:
+				if id, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+					appendID(id)
+				}
+			}
+		}
+	}
+	return role, groupIDs
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 余额流水原因。流水由 users 表上的触发器在余额变动的同一事务内写入，
+// 调用方通过 WithBalanceLedgerSource 说明这次变动的原因与来源；未说明的记为 unattributed。
+const (
+	BalanceLedgerReasonOpening           = "opening"
+	BalanceLedgerReasonUnattributed      = "unattributed"
+	BalanceLedgerReasonUsage             = "usage"
+	BalanceLedgerReasonBatchHold         = "batch_hold"
+	BalanceLedgerReasonBatchCapture      = "batch_capture"
+	BalanceLedgerReasonBatchRelease      = "batch_release"
+	BalanceLedgerReasonRedeem            = "redeem"
+	BalanceLedgerReasonPromo             = "promo"
+	BalanceLedgerReasonPaymentRecharge   = "payment_recharge"
+	BalanceLedgerReasonPaymentRefund     = "payment_refund"
+	BalanceLedgerReasonAdminAdjust       = "admin_adjust"
+	BalanceLedgerReasonAffiliateTransfer = "affiliate_transfer"
+)
+
+// 余额流水来源类型，SourceID 为对应对象的标识。
+const (
+	BalanceLedgerSourceUsageRequest = "usage_request" // SourceID = usage_logs.request_id
+	BalanceLedgerSourceUsageLog     = "usage_log"     // SourceID = usage_logs.id
+	BalanceLedgerSourceBatch        = "batch"         // SourceID = 批量任务 ID
+	BalanceLedgerSourceRedeemCode   = "redeem_code"   // SourceID = 兑换码
+	BalanceLedgerSourcePromoCode    = "promo_code"    // SourceID = 优惠码
+	BalanceLedgerSourcePaymentOrder = "payment_order" // SourceID = payment_orders.id
+	BalanceLedgerSourceAdmin        = "admin"         // SourceID = 操作管理员 ID
+	BalanceLedgerSourceAffiliate    = "affiliate"     // SourceID = 用户 ID（返利转余额）
+)
+
+// 对账差异类型。
+const (
+	// BalanceDriftKindBalance users.balance 与该用户最后一条流水的 balance_after 不一致
+	BalanceDriftKindBalance = "balance"
+	// BalanceDriftKindLedgerGap 相邻两条流水首尾不衔接（流水被绕过或缺失）
+	BalanceDriftKindLedgerGap = "ledger_gap"
+	// BalanceDriftKindUsage 余额计费的用量日志 actual_cost 与对应扣费流水不一致或缺失
+	BalanceDriftKindUsage = "usage"
+	// BalanceDriftKindPayment 已完成的余额充值订单与对应入账流水不一致或缺失
+	BalanceDriftKindPayment = "payment"
+)
+
+const (
+	balanceReconcileTimeout         = 5 * time.Minute
+	balanceReconcileLeaderLockKey   = "balance:ledger:reconcile:leader"
+	balanceReconcileLeaderLockTTL   = 10 * time.Minute
+	balanceLedgerStatementMaxPeriod = 366 * 24 * time.Hour
+)
+
+var (
+	ErrBalanceStatementRangeInvalid = infraerrors.BadRequest("BALANCE_STATEMENT_RANGE_INVALID", "statement end must be after start and span at most 366 days")
+	ErrBalanceStatementTooLarge     = infraerrors.BadRequest("BALANCE_STATEMENT_TOO_LARGE", "statement has too many entries, narrow the date range")
+	ErrBalanceReconcileRunNotFound  = infraerrors.NotFound("BALANCE_RECONCILIATION_RUN_NOT_FOUND", "reconciliation run not found")
+)
+
+// BalanceLedgerSource 说明一次余额变动的原因与来源。
+type BalanceLedgerSource struct {
+	Reason     string
+	SourceType string
+	SourceID   string
+	ActorID    *int64
+	Note       string
+}
+
+type ctxKeyBalanceLedgerSource struct{}
+
+// WithBalanceLedgerSource 返回携带余额流水来源的 context；仓储在该 context 下修改余额时
+// 把来源写入当前事务，触发器据此生成流水。
+func WithBalanceLedgerSource(ctx context.Context, source BalanceLedgerSource) context.Context {
+	return context.WithValue(ctx, ctxKeyBalanceLedgerSource{}, source)
+}
+
+// WithDefaultBalanceLedgerSource 仅在 context 尚未携带来源时设置，
+// 用于兑换码等既可直接调用、也会被支付履约等上层流程复用的入口。
+func WithDefaultBalanceLedgerSource(ctx context.Context, source BalanceLedgerSource) context.Context {
+	if _, ok := BalanceLedgerSourceFromContext(ctx); ok {
+		return ctx
+	}
+	return WithBalanceLedgerSource(ctx, source)
+}
+
+// BalanceLedgerSourceFromContext 读取 context 上的余额流水来源。
+func BalanceLedgerSourceFromContext(ctx context.Context) (BalanceLedgerSource, bool) {
+	if ctx == nil {
+		return BalanceLedgerSource{}, false
+	}
+	source, ok := ctx.Value(ctxKeyBalanceLedgerSource{}).(BalanceLedgerSource)
+	if !ok || strings.TrimSpace(source.Reason) == "" {
+		return BalanceLedgerSource{}, false
+	}
+	return source, true
+}
+
+// BalanceLedgerEntry 一条余额流水。Amount 为余额变动（正数入账、负数扣减），
+// FrozenDelta 为冻结余额变动（批量任务预扣）。
+type BalanceLedgerEntry struct {
+	ID            int64
+	UserID        int64
+	Reason        string
+	SourceType    string
+	SourceID      string
+	ActorID       *int64
+	Amount        float64
+	BalanceBefore float64
+	BalanceAfter  float64
+	FrozenDelta   float64
+	FrozenAfter   float64
+	Note          string
+	CreatedAt     time.Time
+}
+
+// BalanceLedgerFilter 流水列表筛选条件，零值表示不筛选。
+type BalanceLedgerFilter struct {
+	Reason    string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// BalanceStatement 用户在 [Start, End) 内的余额账单。
+type BalanceStatement struct {
+	UserID         int64
+	Start          time.Time
+	End            time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	TotalCredits   float64
+	TotalDebits    float64
+	Entries        []BalanceLedgerEntry
+}
+
+// BalanceReconciliationFinding 一条对账差异。Expected 为参照来源（用量日志、订单、users.balance）的金额，
+// Actual 为流水侧的金额。
+type BalanceReconciliationFinding struct {
+	Kind       string  `json:"kind"`
+	UserID     int64   `json:"user_id"`
+	SourceType string  `json:"source_type,omitempty"`
+	SourceID   string  `json:"source_id,omitempty"`
+	LedgerID   int64   `json:"ledger_id,omitempty"`
+	Expected   float64 `json:"expected"`
+	Actual     float64 `json:"actual"`
+}
+
+// BalanceReconciliationRun 一次对账的结果。各 Count 为差异总数，Findings 每类最多保存 max_findings_per_kind 条。
+type BalanceReconciliationRun struct {
+	ID                int64
+	WindowStart       time.Time
+	WindowEnd         time.Time
+	BalanceDriftCount int
+	LedgerGapCount    int
+	UsageDriftCount   int
+	PaymentDriftCount int
+	Findings          []BalanceReconciliationFinding
+	StartedAt         time.Time
+	FinishedAt        time.Time
+}
+
+// HasDrift 报告本次对账是否发现差异。
+func (r *BalanceReconciliationRun) HasDrift() bool {
+	return r != nil && r.BalanceDriftCount+r.LedgerGapCount+r.UsageDriftCount+r.PaymentDriftCount > 0
+}
+
+// BalanceLedgerRepository 余额流水的读取与对账查询。流水只由数据库触发器写入。
+type BalanceLedgerRepository interface {
+	ListByUser(ctx context.Context, userID int64, filter BalanceLedgerFilter, params pagination.PaginationParams) ([]BalanceLedgerEntry, *pagination.PaginationResult, error)
+	// ListRange 按时间正序返回 [start, end) 内的流水，最多 limit 条。
+	ListRange(ctx context.Context, userID int64, start, end time.Time, limit int) ([]BalanceLedgerEntry, error)
+	// BalanceBefore 返回 at 之前最后一条流水的 balance_after；没有流水时为 0。
+	BalanceBefore(ctx context.Context, userID int64, at time.Time) (float64, error)
+
+	// FindBalanceDrift 比对所有用户的 users.balance 与最后一条流水。
+	FindBalanceDrift(ctx context.Context, limit int) ([]BalanceReconciliationFinding, int, error)
+	// FindLedgerGaps 查找 [start, end) 内 balance_before 与上一条 balance_after 不衔接的流水。
+	FindLedgerGaps(ctx context.Context, start, end time.Time, limit int) ([]BalanceReconciliationFinding, int, error)
+	// FindUsageDrift 比对 [start, end) 内余额计费的用量日志与 usage 流水。
+	FindUsageDrift(ctx context.Context, start, end time.Time, limit int) ([]BalanceReconciliationFinding, int, error)
+	// FindPaymentDrift 比对 [start, end) 内完成的余额充值订单与 payment_recharge 流水。
+	FindPaymentDrift(ctx context.Context, start, end time.Time, limit int) ([]BalanceReconciliationFinding, int, error)
+
+	CreateReconciliationRun(ctx context.Context, run *BalanceReconciliationRun) error
+	ListReconciliationRuns(ctx context.Context, params pagination.PaginationParams) ([]BalanceReconciliationRun, *pagination.PaginationResult, error)
+	GetReconciliationRun(ctx context.Context, id int64) (*BalanceReconciliationRun, error)
+}
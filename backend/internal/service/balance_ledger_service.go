@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+// BalanceLedgerService 提供余额流水查询、账单导出，并定期对账：
+// 流水 vs users.balance、流水首尾衔接、流水 vs usage_logs.actual_cost、流水 vs 余额充值订单。
+type BalanceLedgerService struct {
+	repo BalanceLedgerRepository
+	cfg  config.BalanceLedgerConfig
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewBalanceLedgerService(repo BalanceLedgerRepository, cfg *config.Config) *BalanceLedgerService {
+	s := &BalanceLedgerService{
+		repo:       repo,
+		stopCh:     make(chan struct{}),
+		instanceID: uuid.NewString(),
+		now:        time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.BalanceLedger
+	}
+	return s
+}
+
+// SetLeaderLock 注入选主用的锁，多实例部署时只有一个实例执行定时对账。
+func (s *BalanceLedgerService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// ListForUser 分页返回用户的余额流水（时间倒序）。
+func (s *BalanceLedgerService) ListForUser(ctx context.Context, userID int64, filter BalanceLedgerFilter, params pagination.PaginationParams) ([]BalanceLedgerEntry, *pagination.PaginationResult, error) {
+	return s.repo.ListByUser(ctx, userID, filter, params)
+}
+
+// Statement 生成用户 [start, end) 内的余额账单：期初余额、期间流水（正序）与期末余额。
+func (s *BalanceLedgerService) Statement(ctx context.Context, userID int64, start, end time.Time) (*BalanceStatement, error) {
+	if !end.After(start) || end.Sub(start) > balanceLedgerStatementMaxPeriod {
+		return nil, ErrBalanceStatementRangeInvalid
+	}
+	maxRows := s.cfg.StatementMaxRows
+	if maxRows <= 0 {
+		maxRows = 10000
+	}
+
+	opening, err := s.repo.BalanceBefore(ctx, userID, start)
+	if err != nil {
+		return nil, fmt.Errorf("load opening balance: %w", err)
+	}
+	// 多取一条用于判断是否超出上限，超限时拒绝而不是返回残缺账单。
+	entries, err := s.repo.ListRange(ctx, userID, start, end, maxRows+1)
+	if err != nil {
+		return nil, fmt.Errorf("list statement entries: %w", err)
+	}
+	if len(entries) > maxRows {
+		return nil, ErrBalanceStatementTooLarge
+	}
+
+	statement := &BalanceStatement{
+		UserID:         userID,
+		Start:          start,
+		End:            end,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Entries:        entries,
+	}
+	for i := range entries {
+		if entries[i].Amount > 0 {
+			statement.TotalCredits += entries[i].Amount
+		} else {
+			statement.TotalDebits -= entries[i].Amount
+		}
+		statement.ClosingBalance = entries[i].BalanceAfter
+	}
+	return statement, nil
+}
+
+// ListReconciliationRuns 分页返回历史对账结果（最新在前）。
+func (s *BalanceLedgerService) ListReconciliationRuns(ctx context.Context, params pagination.PaginationParams) ([]BalanceReconciliationRun, *pagination.PaginationResult, error) {
+	return s.repo.ListReconciliationRuns(ctx, params)
+}
+
+func (s *BalanceLedgerService) GetReconciliationRun(ctx context.Context, id int64) (*BalanceReconciliationRun, error) {
+	return s.repo.GetReconciliationRun(ctx, id)
+}
+
+// Reconcile 对最近 reconcile_window_hours（扣除宽限时间）执行一次对账并保存结果。
+func (s *BalanceLedgerService) Reconcile(ctx context.Context) (*BalanceReconciliationRun, error) {
+	windowHours := s.cfg.ReconcileWindowHours
+	if windowHours <= 0 {
+		windowHours = 24
+	}
+	limit := s.cfg.MaxFindingsPerKind
+	if limit <= 0 {
+		limit = 100
+	}
+	startedAt := s.now()
+	run := &BalanceReconciliationRun{
+		WindowEnd: startedAt.Add(-time.Duration(s.cfg.ReconcileGraceMinutes) * time.Minute),
+		StartedAt: startedAt,
+		Findings:  make([]BalanceReconciliationFinding, 0),
+	}
+	run.WindowStart = run.WindowEnd.Add(-time.Duration(windowHours) * time.Hour)
+
+	findings, count, err := s.repo.FindBalanceDrift(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find balance drift: %w", err)
+	}
+	run.BalanceDriftCount = count
+	run.Findings = append(run.Findings, findings...)
+
+	findings, count, err = s.repo.FindLedgerGaps(ctx, run.WindowStart, run.WindowEnd, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find ledger gaps: %w", err)
+	}
+	run.LedgerGapCount = count
+	run.Findings = append(run.Findings, findings...)
+
+	findings, count, err = s.repo.FindUsageDrift(ctx, run.WindowStart, run.WindowEnd, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find usage drift: %w", err)
+	}
+	run.UsageDriftCount = count
+	run.Findings = append(run.Findings, findings...)
+
+	findings, count, err = s.repo.FindPaymentDrift(ctx, run.WindowStart, run.WindowEnd, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find payment drift: %w", err)
+	}
+	run.PaymentDriftCount = count
+	run.Findings = append(run.Findings, findings...)
+
+	run.FinishedAt = s.now()
+	if err := s.repo.CreateReconciliationRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("save reconciliation run: %w", err)
+	}
+	if run.HasDrift() {
+		slog.Warn("[BalanceLedger] reconciliation found drift",
+			"run_id", run.ID,
+			"balance", run.BalanceDriftCount,
+			"ledger_gap", run.LedgerGapCount,
+			"usage", run.UsageDriftCount,
+			"payment", run.PaymentDriftCount)
+	}
+	return run, nil
+}
+
+func (s *BalanceLedgerService) Start() {
+	if s == nil || s.repo == nil || s.cfg.ReconcileIntervalMinutes <= 0 {
+		return
+	}
+	interval := time.Duration(s.cfg.ReconcileIntervalMinutes) * time.Minute
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *BalanceLedgerService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *BalanceLedgerService) runOnce() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, balanceReconcileLeaderLockKey, s.instanceID, balanceReconcileLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), balanceReconcileTimeout)
+	defer cancel()
+	if _, err := s.Reconcile(ctx); err != nil {
+		slog.Error("[BalanceLedger] reconciliation failed", "error", err)
+	}
+}
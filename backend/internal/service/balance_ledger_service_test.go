@@ -0,0 +1,159 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type balanceLedgerRepoStub struct {
+	BalanceLedgerRepository
+
+	opening      float64
+	entries      []BalanceLedgerEntry
+	rangeLimit   int
+	balanceDrift []BalanceReconciliationFinding
+	usageDrift   []BalanceReconciliationFinding
+	usageCount   int
+	windowStart  time.Time
+	windowEnd    time.Time
+	saved        *BalanceReconciliationRun
+}
+
+func (r *balanceLedgerRepoStub) BalanceBefore(context.Context, int64, time.Time) (float64, error) {
+	return r.opening, nil
+}
+
+func (r *balanceLedgerRepoStub) ListRange(_ context.Context, _ int64, _, _ time.Time, limit int) ([]BalanceLedgerEntry, error) {
+	r.rangeLimit = limit
+	if len(r.entries) > limit {
+		return r.entries[:limit], nil
+	}
+	return r.entries, nil
+}
+
+func (r *balanceLedgerRepoStub) FindBalanceDrift(context.Context, int) ([]BalanceReconciliationFinding, int, error) {
+	return r.balanceDrift, len(r.balanceDrift), nil
+}
+
+func (r *balanceLedgerRepoStub) FindLedgerGaps(_ context.Context, start, end time.Time, _ int) ([]BalanceReconciliationFinding, int, error) {
+	r.windowStart, r.windowEnd = start, end
+	return nil, 0, nil
+}
+
+func (r *balanceLedgerRepoStub) FindUsageDrift(context.Context, time.Time, time.Time, int) ([]BalanceReconciliationFinding, int, error) {
+	return r.usageDrift, r.usageCount, nil
+}
+
+func (r *balanceLedgerRepoStub) FindPaymentDrift(context.Context, time.Time, time.Time, int) ([]BalanceReconciliationFinding, int, error) {
+	return nil, 0, nil
+}
+
+func (r *balanceLedgerRepoStub) CreateReconciliationRun(_ context.Context, run *BalanceReconciliationRun) error {
+	run.ID = 7
+	r.saved = run
+	return nil
+}
+
+func newBalanceLedgerServiceForTest(repo BalanceLedgerRepository, ledgerCfg config.BalanceLedgerConfig) *BalanceLedgerService {
+	return NewBalanceLedgerService(repo, &config.Config{BalanceLedger: ledgerCfg})
+}
+
+func TestBalanceLedgerStatement_TotalsAndClosingBalance(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	repo := &balanceLedgerRepoStub{
+		opening: 10,
+		entries: []BalanceLedgerEntry{
+			{ID: 1, Reason: BalanceLedgerReasonPaymentRecharge, Amount: 20, BalanceBefore: 10, BalanceAfter: 30},
+			{ID: 2, Reason: BalanceLedgerReasonUsage, Amount: -1.5, BalanceBefore: 30, BalanceAfter: 28.5},
+			{ID: 3, Reason: BalanceLedgerReasonBatchHold, FrozenDelta: 2, BalanceBefore: 28.5, BalanceAfter: 28.5},
+		},
+	}
+	svc := newBalanceLedgerServiceForTest(repo, config.BalanceLedgerConfig{StatementMaxRows: 100})
+
+	statement, err := svc.Statement(context.Background(), 5, start, start.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Equal(t, 10.0, statement.OpeningBalance)
+	require.Equal(t, 28.5, statement.ClosingBalance)
+	require.Equal(t, 20.0, statement.TotalCredits)
+	require.Equal(t, 1.5, statement.TotalDebits)
+	require.Len(t, statement.Entries, 3)
+	require.Equal(t, 101, repo.rangeLimit)
+}
+
+func TestBalanceLedgerStatement_NoEntriesKeepsOpeningBalance(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	svc := newBalanceLedgerServiceForTest(&balanceLedgerRepoStub{opening: 3}, config.BalanceLedgerConfig{StatementMaxRows: 10})
+
+	statement, err := svc.Statement(context.Background(), 5, start, start.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Equal(t, 3.0, statement.ClosingBalance)
+}
+
+func TestBalanceLedgerStatement_RejectsInvalidRange(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	svc := newBalanceLedgerServiceForTest(&balanceLedgerRepoStub{}, config.BalanceLedgerConfig{StatementMaxRows: 10})
+
+	_, err := svc.Statement(context.Background(), 5, start, start)
+	require.ErrorIs(t, err, ErrBalanceStatementRangeInvalid)
+	_, err = svc.Statement(context.Background(), 5, start, start.AddDate(2, 0, 0))
+	require.ErrorIs(t, err, ErrBalanceStatementRangeInvalid)
+}
+
+func TestBalanceLedgerStatement_TooManyEntries(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	repo := &balanceLedgerRepoStub{entries: make([]BalanceLedgerEntry, 3)}
+	svc := newBalanceLedgerServiceForTest(repo, config.BalanceLedgerConfig{StatementMaxRows: 2})
+
+	_, err := svc.Statement(context.Background(), 5, start, start.AddDate(0, 0, 1))
+	require.ErrorIs(t, err, ErrBalanceStatementTooLarge)
+}
+
+func TestBalanceLedgerReconcile_WindowAndFindings(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	repo := &balanceLedgerRepoStub{
+		balanceDrift: []BalanceReconciliationFinding{{Kind: BalanceDriftKindBalance, UserID: 1, Expected: 5, Actual: 4}},
+		usageDrift:   []BalanceReconciliationFinding{{Kind: BalanceDriftKindUsage, UserID: 2, SourceID: "req-1", Expected: 0.2}},
+		usageCount:   12,
+	}
+	svc := newBalanceLedgerServiceForTest(repo, config.BalanceLedgerConfig{ReconcileWindowHours: 24, ReconcileGraceMinutes: 10, MaxFindingsPerKind: 1})
+	svc.now = func() time.Time { return now }
+
+	run, err := svc.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.Same(t, run, repo.saved)
+	require.Equal(t, int64(7), run.ID)
+	require.Equal(t, now.Add(-10*time.Minute), repo.windowEnd)
+	require.Equal(t, now.Add(-10*time.Minute-24*time.Hour), repo.windowStart)
+	require.Equal(t, 1, run.BalanceDriftCount)
+	require.Equal(t, 12, run.UsageDriftCount)
+	require.Len(t, run.Findings, 2)
+	require.True(t, run.HasDrift())
+}
+
+func TestBalanceLedgerReconcile_CleanRun(t *testing.T) {
+	repo := &balanceLedgerRepoStub{}
+	svc := newBalanceLedgerServiceForTest(repo, config.BalanceLedgerConfig{ReconcileWindowHours: 1, MaxFindingsPerKind: 10})
+
+	run, err := svc.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, run.HasDrift())
+	require.NotNil(t, run.Findings)
+}
+
+func TestWithDefaultBalanceLedgerSource_KeepsExistingSource(t *testing.T) {
+	ctx := WithBalanceLedgerSource(context.Background(), BalanceLedgerSource{Reason: BalanceLedgerReasonPaymentRecharge, SourceID: "9"})
+	ctx = WithDefaultBalanceLedgerSource(ctx, BalanceLedgerSource{Reason: BalanceLedgerReasonRedeem})
+
+	source, ok := BalanceLedgerSourceFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, BalanceLedgerReasonPaymentRecharge, source.Reason)
+
+	_, ok = BalanceLedgerSourceFromContext(WithBalanceLedgerSource(context.Background(), BalanceLedgerSource{}))
+	require.False(t, ok)
+}
@@ -43,6 +43,7 @@ type BalanceNotifyService struct {
 	settingRepo              SettingRepository
 	accountRepo              AccountQuotaReader
 	notificationEmailService *NotificationEmailService
+	userWebhookService       *UserWebhookService
 }
 
 // NewBalanceNotifyService creates a new BalanceNotifyService.
@@ -58,6 +59,11 @@ func (s *BalanceNotifyService) SetNotificationEmailService(notificationEmailServ
 	s.notificationEmailService = notificationEmailService
 }
 
+// SetUserWebhookService 注入用户 Webhook 发布（余额不足 / API Key 额度耗尽）。
+func (s *BalanceNotifyService) SetUserWebhookService(userWebhookService *UserWebhookService) {
+	s.userWebhookService = userWebhookService
+}
+
 // resolveBalanceThreshold returns the effective balance threshold.
 // For percentage type, it computes threshold = totalRecharged * percentage / 100.
 func resolveBalanceThreshold(threshold float64, thresholdType string, totalRecharged float64) float64 {
@@ -70,7 +76,10 @@ func resolveBalanceThreshold(threshold float64, thresholdType string, totalRecha
 // CheckBalanceAfterDeduction checks if balance crossed below threshold after deduction.
 // Notification is sent only on first crossing: oldBalance >= threshold && newBalance < threshold.
 func (s *BalanceNotifyService) CheckBalanceAfterDeduction(ctx context.Context, user *User, oldBalance, cost float64) {
-	if !s.canNotifyBalance(user) {
+	notifyEmail := s.canNotifyBalance(user)
+	// Webhook 不受用户邮件开关影响：配置了 endpoint 并订阅 balance.low 即视为需要通知。
+	notifyWebhook := user != nil && s.settingRepo != nil && s.userWebhookService.Enabled()
+	if !notifyEmail && !notifyWebhook {
 		return
 	}
 	effectiveThreshold, rechargeURL, ok := s.resolveUserEffectiveThreshold(ctx, user)
@@ -81,7 +90,58 @@ func (s *BalanceNotifyService) CheckBalanceAfterDeduction(ctx context.Context, u
 	if !crossedDownward(oldBalance, newBalance, effectiveThreshold) {
 		return
 	}
-	s.dispatchBalanceLowEmail(ctx, user, newBalance, effectiveThreshold, rechargeURL)
+	if notifyWebhook {
+		s.userWebhookService.Publish(UserWebhookEvent{
+			Type:   UserWebhookEventBalanceLow,
+			UserID: user.ID,
+			Data: map[string]any{
+				"balance":      newBalance,
+				"threshold":    effectiveThreshold,
+				"recharge_url": rechargeURL,
+			},
+		})
+	}
+	if notifyEmail {
+		s.dispatchBalanceLowEmail(ctx, user, newBalance, effectiveThreshold, rechargeURL)
+	}
+}
+
+// apiKeyQuotaExhaustedWebhookEvents 预先构造 API Key 额度耗尽 / 被停用事件，由计费仓储在
+// 把 Key 置为 quota_exhausted 的同一事务内写入 outbox（未触发停用则丢弃）。
+// requestID 是本次计费请求，用作事件去重键（计费重试不会重复通知）。
+func (s *BalanceNotifyService) apiKeyQuotaExhaustedWebhookEvents(apiKey *APIKey, requestID string) []UserWebhookOutboxEvent {
+	if s == nil || apiKey == nil || !s.userWebhookService.Enabled() {
+		return nil
+	}
+	data := map[string]any{
+		"api_key_id": apiKey.ID,
+		"name":       apiKey.Name,
+		"quota":      apiKey.Quota,
+	}
+	disabled := map[string]any{"reason": StatusAPIKeyQuotaExhausted}
+	for k, v := range data {
+		disabled[k] = v
+	}
+	var events []UserWebhookOutboxEvent
+	for _, event := range []UserWebhookEvent{
+		{
+			ID:     fmt.Sprintf("%s:%d:%s", UserWebhookEventAPIKeyQuotaExhausted, apiKey.ID, requestID),
+			Type:   UserWebhookEventAPIKeyQuotaExhausted,
+			UserID: apiKey.UserID,
+			Data:   data,
+		},
+		{
+			ID:     fmt.Sprintf("%s:%d:%s", UserWebhookEventAPIKeyDisabled, apiKey.ID, requestID),
+			Type:   UserWebhookEventAPIKeyDisabled,
+			UserID: apiKey.UserID,
+			Data:   disabled,
+		},
+	} {
+		if prepared, ok := s.userWebhookService.PrepareEvent(event); ok {
+			events = append(events, prepared)
+		}
+	}
+	return events
 }
 
 // canNotifyBalance checks nil guards and user-level toggle.
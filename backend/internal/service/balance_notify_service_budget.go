@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// NotifyBudgetThresholds 发送预算 50/80/100% 通知。用户 / Key 预算发给所属用户（Webhook budget.threshold
+// 与通知邮件），分组预算发给管理员通知邮箱（与账号额度告警共用）。去重已由 ClaimAlert 完成。
+func (s *BalanceNotifyService) NotifyBudgetThresholds(ctx context.Context, user *User, alerts []BudgetAlert) {
+	if s == nil {
+		return
+	}
+	for i := range alerts {
+		alert := alerts[i]
+		if alert.UserID > 0 {
+			if s.userWebhookService.Enabled() {
+				s.userWebhookService.Publish(UserWebhookEvent{
+					ID:     fmt.Sprintf("%s:%d:%d:%d", UserWebhookEventBudgetThreshold, alert.Budget.ID, alert.PeriodStart.Unix(), alert.Percent),
+					Type:   UserWebhookEventBudgetThreshold,
+					UserID: alert.UserID,
+					Data:   budgetAlertWebhookData(&alert),
+				})
+			}
+			if user != nil && user.ID == alert.UserID && user.Email != "" {
+				s.dispatchBudgetAlertEmails([]string{user.Email}, user.ID, user.Username, &alert)
+			}
+			continue
+		}
+		if s.settingRepo != nil {
+			s.dispatchBudgetAlertEmails(s.getAccountQuotaNotifyEmails(ctx), 0, "", &alert)
+		}
+	}
+}
+
+func budgetAlertWebhookData(alert *BudgetAlert) map[string]any {
+	data := map[string]any{
+		"budget_id":    alert.Budget.ID,
+		"scope_type":   alert.Budget.ScopeType,
+		"scope_id":     alert.Budget.ScopeID,
+		"model":        alert.Budget.Model,
+		"period":       alert.Budget.Period,
+		"enforcement":  alert.Budget.Enforcement,
+		"percent":      alert.Percent,
+		"spent_usd":    alert.SpentUSD,
+		"limit_usd":    alert.Budget.LimitUSD,
+		"period_start": alert.PeriodStart,
+		"period_end":   alert.PeriodEnd,
+	}
+	if alert.APIKeyName != "" {
+		data["api_key_name"] = alert.APIKeyName
+	}
+	return data
+}
+
+// budgetScopeLabel 邮件中展示的预算对象。
+func budgetScopeLabel(alert *BudgetAlert) string {
+	switch alert.Budget.ScopeType {
+	case BudgetScopeAPIKey:
+		if alert.APIKeyName != "" {
+			return fmt.Sprintf("API key %s (#%d)", alert.APIKeyName, alert.Budget.ScopeID)
+		}
+		return fmt.Sprintf("API key #%d", alert.Budget.ScopeID)
+	case BudgetScopeGroup:
+		return fmt.Sprintf("Group #%d", alert.Budget.ScopeID)
+	default:
+		return fmt.Sprintf("User #%d", alert.Budget.ScopeID)
+	}
+}
+
+func (s *BalanceNotifyService) dispatchBudgetAlertEmails(recipients []string, userID int64, userName string, alert *BudgetAlert) {
+	if s.notificationEmailService == nil || len(recipients) == 0 {
+		return
+	}
+	model := alert.Budget.Model
+	if model == "" {
+		model = "*"
+	}
+	variables := map[string]string{
+		"budget_scope":       budgetScopeLabel(alert),
+		"budget_model":       model,
+		"budget_period":      alert.Budget.Period,
+		"budget_enforcement": alert.Budget.Enforcement,
+		"budget_percent":     strconv.Itoa(alert.Percent),
+		"budget_used":        fmt.Sprintf("%.2f", alert.SpentUSD),
+		"budget_limit":       fmt.Sprintf("%.2f", alert.Budget.LimitUSD),
+		"period_resets_at":   alert.PeriodEnd.In(timezone.Location()).Format("2006-01-02 15:04"),
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in budget alert notification", "recover", r)
+			}
+		}()
+		for _, to := range recipients {
+			name := userName
+			if name == "" {
+				name = emailRecipientName(to)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), emailSendTimeout)
+			err := s.notificationEmailService.Send(ctx, NotificationEmailSendInput{
+				Event:          NotificationEmailEventBudgetThreshold,
+				RecipientEmail: to,
+				RecipientName:  name,
+				UserID:         userID,
+				SourceType:     "budget",
+				SourceID:       strconv.FormatInt(alert.Budget.ID, 10),
+				ReminderKey:    fmt.Sprintf("%d-%d", alert.PeriodStart.Unix(), alert.Percent),
+				Variables:      variables,
+			})
+			cancel()
+			if err != nil {
+				slog.Warn("budget alert email failed", "to", to, "budget_id", alert.Budget.ID, "percent", alert.Percent, "err", err.Error())
+			}
+		}
+	}()
+}
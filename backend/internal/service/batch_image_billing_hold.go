@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"go.uber.org/zap"
 )
 
@@ -49,8 +50,8 @@ func buildBatchImageHoldCommand(job *BatchImageJob, requestID string, actualAmou
 		APIKeyID:           *job.APIKeyID,
 		UserID:             job.UserID,
 		BatchID:            job.BatchID,
-		HoldAmount:         holdAmount,
-		ActualAmount:       actualAmount,
+		HoldAmount:         money.Decimal(holdAmount),
+		ActualAmount:       money.Decimal(actualAmount),
 		RequestPayloadHash: strings.TrimSpace(payloadHash),
 	}, nil
 }
@@ -63,7 +64,7 @@ func reserveBatchImageBalanceHold(ctx context.Context, repo UsageBillingReposito
 	if err != nil {
 		return err
 	}
-	if cmd.HoldAmount <= 0 {
+	if !cmd.HoldAmount.IsPositive() {
 		return nil
 	}
 	if _, err := repo.ReserveBatchImageBalance(ctx, cmd); err != nil {
@@ -97,7 +98,7 @@ func releaseBatchImageBalanceHold(ctx context.Context, repo UsageBillingReposito
 	if err != nil {
 		return err
 	}
-	if cmd.HoldAmount <= 0 {
+	if !cmd.HoldAmount.IsPositive() {
 		return nil
 	}
 	if _, err := repo.ReleaseBatchImageBalance(ctx, cmd); err != nil {
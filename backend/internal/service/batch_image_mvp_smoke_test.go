@@ -92,7 +92,7 @@ func TestBatchImageMVPFlow(t *testing.T) {
 	require.Len(t, provider.submits, 1)
 	require.Len(t, billing.reserves, 1)
 	require.Equal(t, BatchImageHoldRequestID(submitted.ID), billing.reserves[0].RequestID)
-	require.InDelta(t, 0.3, billing.reserves[0].HoldAmount, 1e-12)
+	require.InDelta(t, 0.3, billing.reserves[0].HoldAmount.InexactFloat64(), 1e-12)
 	requireBatchImagePublicJSONHasNoInternals(t, mustMarshalBatchImageSmokeJSON(t, submitted))
 
 	firstProcess, err := processor.Process(ctx, submitted.ID)
@@ -117,8 +117,8 @@ func TestBatchImageMVPFlow(t *testing.T) {
 	require.Equal(t, 1, job.FailCount)
 	require.Len(t, billing.captures, 1)
 	require.Equal(t, BatchImageCaptureRequestID(submitted.ID), billing.captures[0].RequestID)
-	require.InDelta(t, 0.3, billing.captures[0].HoldAmount, 1e-12)
-	require.InDelta(t, 0.125, billing.captures[0].ActualAmount, 1e-12)
+	require.InDelta(t, 0.3, billing.captures[0].HoldAmount.InexactFloat64(), 1e-12)
+	require.InDelta(t, 0.125, billing.captures[0].ActualAmount.InexactFloat64(), 1e-12)
 
 	secondSettlement, err := processor.SettlementService.Settle(ctx, submitted.ID)
 	require.NoError(t, err)
@@ -76,9 +76,10 @@ type BatchImageReferenceInput struct {
 }
 
 type BatchImageOwner struct {
-	UserID   int64
-	APIKeyID int64
-	GroupID  *int64
+	UserID         int64
+	APIKeyID       int64
+	GroupID        *int64
+	OrganizationID *int64
 }
 
 type BatchImagePublicService struct {
@@ -201,6 +202,10 @@ func (s *BatchImagePublicService) Submit(ctx context.Context, owner BatchImageOw
 	if !s.enabled() {
 		return nil, ErrBatchImageDisabled
 	}
+	// 批量任务冻结的是个人余额，组织 Key 不支持。
+	if owner.OrganizationID != nil {
+		return nil, ErrOrganizationKeyBatchUnsupported
+	}
 	normalized, err := s.validateSubmitRequest(req)
 	if err != nil {
 		return nil, err
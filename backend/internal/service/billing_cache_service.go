@@ -113,6 +113,12 @@ type BillingCacheService struct {
 	cfg                   *config.Config
 	circuitBreaker        *billingCircuitBreaker
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	userWebhookService    *UserWebhookService
+	organizationRepo      OrganizationRepository
+	organizationStates    sync.Map // "orgID:userID" -> *organizationBillingStateEntry
+	budgetRepo            BudgetRepository
+	budgetStates          sync.Map // "scopeType:scopeID" -> *budgetScopeStateEntry
+	budgetMu              sync.Mutex
 
 	cacheWriteChan     chan cacheWriteTask
 	cacheWriteWg       sync.WaitGroup
@@ -179,6 +185,19 @@ func (s *BillingCacheService) Stop() {
 	})
 }
 
+// CacheWriteQueueStats 返回异步缓存写入队列的当前长度与容量（供 /metrics 导出）。
+func (s *BillingCacheService) CacheWriteQueueStats() (depth, capacity int) {
+	if s == nil {
+		return 0, 0
+	}
+	s.cacheWriteMu.RLock()
+	defer s.cacheWriteMu.RUnlock()
+	if s.cacheWriteChan == nil {
+		return 0, 0
+	}
+	return len(s.cacheWriteChan), cap(s.cacheWriteChan)
+}
+
 func (s *BillingCacheService) startCacheWriteWorkers() {
 	ch := make(chan cacheWriteTask, cacheWriteBufferSize)
 	s.cacheWriteChan = ch
@@ -676,17 +695,51 @@ func (s *BillingCacheService) evaluateRateLimits(ctx context.Context, apiKey *AP
 
 	// Check limits
 	if apiKey.RateLimit5h > 0 && usage5h >= apiKey.RateLimit5h {
+		s.publishRateLimitedWebhook(apiKey, "5h", apiKey.RateLimit5h, usage5h, w5h)
 		return ErrAPIKeyRateLimit5hExceeded
 	}
 	if apiKey.RateLimit1d > 0 && usage1d >= apiKey.RateLimit1d {
+		s.publishRateLimitedWebhook(apiKey, "1d", apiKey.RateLimit1d, usage1d, w1d)
 		return ErrAPIKeyRateLimit1dExceeded
 	}
 	if apiKey.RateLimit7d > 0 && usage7d >= apiKey.RateLimit7d {
+		s.publishRateLimitedWebhook(apiKey, "7d", apiKey.RateLimit7d, usage7d, w7d)
 		return ErrAPIKeyRateLimit7dExceeded
 	}
 	return nil
 }
 
+// SetUserWebhookService 注入用户 Webhook 发布（API Key 限流触发）。
+func (s *BillingCacheService) SetUserWebhookService(userWebhookService *UserWebhookService) {
+	s.userWebhookService = userWebhookService
+}
+
+// publishRateLimitedWebhook 每个窗口只通知一次：事件 ID 含窗口起点，
+// 同一窗口内后续被拒绝的请求命中 outbox 去重。
+func (s *BillingCacheService) publishRateLimitedWebhook(apiKey *APIKey, window string, limit, usage float64, windowStart *time.Time) {
+	if !s.userWebhookService.Enabled() {
+		return
+	}
+	var startUnix int64
+	data := map[string]any{
+		"api_key_id": apiKey.ID,
+		"name":       apiKey.Name,
+		"window":     window,
+		"limit":      limit,
+		"usage":      usage,
+	}
+	if windowStart != nil {
+		startUnix = windowStart.Unix()
+		data["window_start"] = windowStart.UTC().Format(time.RFC3339)
+	}
+	s.userWebhookService.Publish(UserWebhookEvent{
+		ID:     fmt.Sprintf("%s:%d:%s:%d", UserWebhookEventAPIKeyRateLimited, apiKey.ID, window, startUnix),
+		Type:   UserWebhookEventAPIKeyRateLimited,
+		UserID: apiKey.UserID,
+		Data:   data,
+	})
+}
+
 // QueueUpdateAPIKeyRateLimitUsage asynchronously updates rate limit usage in the cache.
 func (s *BillingCacheService) QueueUpdateAPIKeyRateLimitUsage(apiKeyID int64, cost float64) {
 	if s.cache == nil {
@@ -748,8 +801,14 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 		if err := s.checkSubscriptionEligibility(ctx, user.ID, group, subscription); err != nil {
 			return err
 		}
-	} else {
-		if err := s.checkBalanceEligibility(ctx, user.ID); err != nil {
+	} else if OpenAIBatchExecutionFromContext(ctx) == nil {
+		// Batch API 请求的费用从创建批次时冻结的余额中结算，不受可用余额限制。
+		// 组织 Key 检查组织钱包与成员月度限额，而不是个人余额。
+		if apiKey.ChargesOrganization() {
+			if err := s.checkOrganizationEligibility(ctx, user.ID, *apiKey.OrganizationID); err != nil {
+				return err
+			}
+		} else if err := s.checkBalanceEligibility(ctx, user.ID); err != nil {
 			return err
 		}
 	}
@@ -759,6 +818,12 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 		if err := s.checkUserPlatformQuotaEligibility(ctx, user.ID, platform); err != nil {
 			return err
 		}
+		// 用户 / Key / 分组预算同样只约束余额计费；Batch 执行的费用已在创建时冻结。
+		if OpenAIBatchExecutionFromContext(ctx) == nil {
+			if err := s.checkBudgetEligibility(ctx, user, apiKey, group); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Check API Key rate limits (applies to both billing modes)
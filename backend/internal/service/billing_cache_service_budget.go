@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// budgetState 一条预算在当前周期的消费快照。
+type budgetState struct {
+	budget      Budget
+	periodStart time.Time
+	periodEnd   time.Time
+	spent       float64
+}
+
+// budgetScopeStateEntry 一个挂载对象（用户 / Key / 分组）上全部启用预算的进程内缓存条目。
+// 条目不可变：累加消费或推进通知阈值时整体替换。
+type budgetScopeStateEntry struct {
+	states    []budgetState
+	expiresAt time.Time
+}
+
+// validAt 缓存过期或任一预算跨入新周期时需要重新加载。
+func (e *budgetScopeStateEntry) validAt(now time.Time) bool {
+	if !now.Before(e.expiresAt) {
+		return false
+	}
+	for i := range e.states {
+		if !now.Before(e.states[i].periodEnd) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetBudgetRepository 注入预算仓储（用户 / Key / 分组预算检查）。
+func (s *BillingCacheService) SetBudgetRepository(repo BudgetRepository) {
+	s.budgetRepo = repo
+}
+
+func (s *BillingCacheService) budgetsEnabled() bool {
+	return s != nil && s.budgetRepo != nil && s.cfg != nil && s.cfg.Budget.Enabled
+}
+
+func budgetScopeKey(scope BudgetScopeRef) string {
+	return scope.Type + ":" + strconv.FormatInt(scope.ID, 10)
+}
+
+// budgetScopesFor 一次请求同时受用户、Key 与分组三层预算约束，任一 hard 预算用尽即拒绝。
+func budgetScopesFor(userID, apiKeyID int64, groupID *int64) []BudgetScopeRef {
+	scopes := make([]BudgetScopeRef, 0, 3)
+	if userID > 0 {
+		scopes = append(scopes, BudgetScopeRef{Type: BudgetScopeUser, ID: userID})
+	}
+	if apiKeyID > 0 {
+		scopes = append(scopes, BudgetScopeRef{Type: BudgetScopeAPIKey, ID: apiKeyID})
+	}
+	if groupID != nil && *groupID > 0 {
+		scopes = append(scopes, BudgetScopeRef{Type: BudgetScopeGroup, ID: *groupID})
+	}
+	return scopes
+}
+
+// budgetReachedPercent 返回消费已达到的最高通知阈值，未达到 50% 时为 0。
+func budgetReachedPercent(spent, limit float64) int {
+	if limit <= 0 {
+		return 0
+	}
+	reached := 0
+	for _, p := range budgetAlertPercents {
+		if spent >= limit*float64(p)/100 {
+			reached = p
+		}
+	}
+	return reached
+}
+
+// getBudgetScopeState 读取 scope 上启用的预算及其本周期消费，按 state_cache_seconds 缓存在进程内。
+// fresh 表示本次刚从数据库加载。
+func (s *BillingCacheService) getBudgetScopeState(ctx context.Context, scope BudgetScopeRef, now time.Time) (entry *budgetScopeStateEntry, fresh bool, err error) {
+	key := budgetScopeKey(scope)
+	if v, ok := s.budgetStates.Load(key); ok {
+		if cached := v.(*budgetScopeStateEntry); cached.validAt(now) {
+			return cached, false, nil
+		}
+	}
+
+	budgets, err := s.budgetRepo.ListByScope(ctx, scope, true)
+	if err != nil {
+		return nil, false, err
+	}
+	ttl := time.Duration(s.cfg.Budget.StateCacheSeconds) * time.Second
+	entry = &budgetScopeStateEntry{states: make([]budgetState, 0, len(budgets)), expiresAt: now.Add(ttl)}
+	for i := range budgets {
+		start, end := BudgetPeriodWindow(budgets[i].Period, now)
+		spent, err := s.budgetRepo.GetSpend(ctx, &budgets[i], start, end)
+		if err != nil {
+			return nil, false, err
+		}
+		entry.states = append(entry.states, budgetState{budget: budgets[i], periodStart: start, periodEnd: end, spent: spent})
+	}
+	if ttl > 0 {
+		s.budgetStates.Store(key, entry)
+	}
+	return entry, true, nil
+}
+
+// checkBudgetEligibility 检查用户、Key、分组上的 hard 预算。按模型的预算使用请求上下文中的模型名
+// （ctxkey.Model，由 handler 在解析请求后写入）；模型未知时只检查全模型预算。
+// 预算统计查询失败时 fail-open，与 user × platform 配额一致，不因统计故障阻断业务。
+func (s *BillingCacheService) checkBudgetEligibility(ctx context.Context, user *User, apiKey *APIKey, group *Group) error {
+	if !s.budgetsEnabled() || user == nil {
+		return nil
+	}
+	var apiKeyID int64
+	if apiKey != nil {
+		apiKeyID = apiKey.ID
+	}
+	var groupID *int64
+	if group != nil {
+		groupID = &group.ID
+	}
+	model, _ := ctx.Value(ctxkey.Model).(string)
+	now := timezone.Now()
+
+	for _, scope := range budgetScopesFor(user.ID, apiKeyID, groupID) {
+		entry, _, err := s.getBudgetScopeState(ctx, scope, now)
+		if err != nil {
+			logger.LegacyPrintf("service.billing_cache", "Warning: budget check failed for %s: %v", budgetScopeKey(scope), err)
+			continue
+		}
+		for i := range entry.states {
+			st := &entry.states[i]
+			if !st.budget.IsHard() || !st.budget.AppliesToModel(model) || st.spent < st.budget.LimitUSD {
+				continue
+			}
+			return ErrBudgetExceeded.WithMetadata(map[string]string{
+				"window_resets_at": st.periodEnd.Format(time.RFC3339),
+				"budget_scope":     scope.Type,
+				"budget_period":    st.budget.Period,
+				"budget_model":     st.budget.Model,
+			})
+		}
+	}
+	return nil
+}
+
+// RecordBudgetCharge 扣费成功后累加缓存中的本周期消费，并返回本次新达到的通知阈值。
+// 阈值通过 ClaimAlert 在数据库中去重，多实例下每个周期每档只通知一次。
+func (s *BillingCacheService) RecordBudgetCharge(ctx context.Context, userID int64, apiKey *APIKey, model string, cost float64) []BudgetAlert {
+	if !s.budgetsEnabled() || apiKey == nil || cost <= 0 || math.IsNaN(cost) || math.IsInf(cost, 0) {
+		return nil
+	}
+	now := timezone.Now()
+	var alerts []BudgetAlert
+	for _, scope := range budgetScopesFor(userID, apiKey.ID, apiKey.GroupID) {
+		entry, fresh, err := s.getBudgetScopeState(ctx, scope, now)
+		if err != nil {
+			logger.LegacyPrintf("service.billing_cache", "Warning: budget usage record failed for %s: %v", budgetScopeKey(scope), err)
+			continue
+		}
+		// 刚从库中加载的消费已包含（或即将包含）本次用量日志，不再重复累加。
+		if !fresh {
+			entry = s.addBudgetSpend(scope, entry, model, cost)
+		}
+		for i := range entry.states {
+			st := entry.states[i]
+			if !st.budget.AppliesToModel(model) {
+				continue
+			}
+			percent := budgetReachedPercent(st.spent, st.budget.LimitUSD)
+			if percent == 0 || percent <= st.budget.alertedPercent(st.periodStart) {
+				continue
+			}
+			claimed, err := s.budgetRepo.ClaimAlert(ctx, st.budget.ID, st.periodStart, percent)
+			if err != nil {
+				logger.LegacyPrintf("service.billing_cache", "Warning: budget alert claim failed for budget %d: %v", st.budget.ID, err)
+				continue
+			}
+			s.markBudgetAlerted(scope, st.budget.ID, st.periodStart, percent)
+			if !claimed {
+				continue
+			}
+			alert := BudgetAlert{
+				Budget:      st.budget,
+				Percent:     percent,
+				SpentUSD:    st.spent,
+				PeriodStart: st.periodStart,
+				PeriodEnd:   st.periodEnd,
+			}
+			switch scope.Type {
+			case BudgetScopeUser:
+				alert.UserID = scope.ID
+			case BudgetScopeAPIKey:
+				alert.UserID = apiKey.UserID
+				alert.APIKeyName = apiKey.Name
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// addBudgetSpend 在最新的缓存条目上累加适用预算的消费；并发扣费由 budgetMu 串行化，避免丢失更新。
+func (s *BillingCacheService) addBudgetSpend(scope BudgetScopeRef, entry *budgetScopeStateEntry, model string, cost float64) *budgetScopeStateEntry {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+
+	key := budgetScopeKey(scope)
+	if v, ok := s.budgetStates.Load(key); ok {
+		entry = v.(*budgetScopeStateEntry)
+	}
+	next := &budgetScopeStateEntry{states: append([]budgetState(nil), entry.states...), expiresAt: entry.expiresAt}
+	for i := range next.states {
+		if next.states[i].budget.AppliesToModel(model) {
+			next.states[i].spent += cost
+		}
+	}
+	s.budgetStates.Store(key, next)
+	return next
+}
+
+// markBudgetAlerted 记录缓存中预算本周期已通知的阈值，避免后续请求重复 ClaimAlert。
+func (s *BillingCacheService) markBudgetAlerted(scope BudgetScopeRef, budgetID int64, periodStart time.Time, percent int) {
+	s.budgetMu.Lock()
+	defer s.budgetMu.Unlock()
+
+	key := budgetScopeKey(scope)
+	v, ok := s.budgetStates.Load(key)
+	if !ok {
+		return
+	}
+	entry := v.(*budgetScopeStateEntry)
+	next := &budgetScopeStateEntry{states: append([]budgetState(nil), entry.states...), expiresAt: entry.expiresAt}
+	for i := range next.states {
+		if next.states[i].budget.ID == budgetID {
+			start := periodStart
+			next.states[i].budget.AlertPeriodStart = &start
+			next.states[i].budget.AlertPercent = percent
+		}
+	}
+	s.budgetStates.Store(key, next)
+}
+
+// InvalidateBudgetScope 清除挂载对象的预算缓存（预算增删改后调用）。
+func (s *BillingCacheService) InvalidateBudgetScope(scope BudgetScopeRef) {
+	if s == nil {
+		return
+	}
+	s.budgetStates.Delete(budgetScopeKey(scope))
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// organizationBillingStateEntry 组织计费状态的进程内缓存条目。
+// 条目不可变：扣费后整体替换，避免并发读写同一结构体。
+type organizationBillingStateEntry struct {
+	state     OrganizationBillingState
+	monthKey  string
+	expiresAt time.Time
+}
+
+// SetOrganizationRepository 注入组织仓储（组织 Key 的资格检查）。
+func (s *BillingCacheService) SetOrganizationRepository(repo OrganizationRepository) {
+	s.organizationRepo = repo
+}
+
+func organizationBillingStateKey(orgID, userID int64) string {
+	return fmt.Sprintf("%d:%d", orgID, userID)
+}
+
+func (s *BillingCacheService) organizationBillingStateTTL() time.Duration {
+	if s.cfg == nil {
+		return 0
+	}
+	return time.Duration(s.cfg.Organization.BillingStateCacheSeconds) * time.Second
+}
+
+// getOrganizationBillingState 读取组织钱包余额、成员资格与成员本月消费。
+// 结果按 billing_state_cache_seconds 缓存在进程内；跨自然月时强制刷新。
+func (s *BillingCacheService) getOrganizationBillingState(ctx context.Context, orgID, userID int64) (*OrganizationBillingState, error) {
+	now := timezone.Now()
+	monthStart := timezone.StartOfMonth(now)
+	monthKey := monthStart.Format("2006-01")
+	key := organizationBillingStateKey(orgID, userID)
+	if v, ok := s.organizationStates.Load(key); ok {
+		entry := v.(*organizationBillingStateEntry)
+		if entry.monthKey == monthKey && now.Before(entry.expiresAt) {
+			state := entry.state
+			return &state, nil
+		}
+	}
+
+	state, err := s.organizationRepo.GetBillingState(ctx, orgID, userID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+	if ttl := s.organizationBillingStateTTL(); ttl > 0 {
+		s.organizationStates.Store(key, &organizationBillingStateEntry{state: *state, monthKey: monthKey, expiresAt: now.Add(ttl)})
+	}
+	return state, nil
+}
+
+// checkOrganizationEligibility 组织 Key 的余额模式资格：组织启用、调用者仍是成员、
+// 组织钱包余额高于门槛、成员本月消费未超过其限额（0 为不限）。
+func (s *BillingCacheService) checkOrganizationEligibility(ctx context.Context, userID, orgID int64) error {
+	if s.organizationRepo == nil || s.cfg == nil || !s.cfg.Organization.Enabled {
+		return ErrOrganizationDisabled
+	}
+	state, err := s.getOrganizationBillingState(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrOrganizationNotFound) {
+			return ErrOrganizationDisabled
+		}
+		if s.circuitBreaker != nil {
+			s.circuitBreaker.OnFailure(err)
+		}
+		logger.LegacyPrintf("service.billing_cache", "ALERT: billing organization check failed for org %d user %d: %v", orgID, userID, err)
+		return ErrBillingServiceUnavailable.WithCause(err)
+	}
+	if s.circuitBreaker != nil {
+		s.circuitBreaker.OnSuccess()
+	}
+
+	if state.Status != OrganizationStatusActive {
+		return ErrOrganizationDisabled
+	}
+	if !state.IsMember {
+		return ErrOrganizationMembershipRequired
+	}
+	if s.balanceBelowEligibilityThreshold(state.Balance) {
+		return ErrOrganizationInsufficientBalance
+	}
+	if state.MonthlySpendLimitUSD > 0 && state.MonthSpendUSD >= state.MonthlySpendLimitUSD {
+		return ErrOrganizationMemberSpendLimitHit
+	}
+	return nil
+}
+
+// RecordOrganizationCharge 扣费成功后就地更新缓存的组织状态，使成员限额与钱包余额
+// 在缓存 TTL 内也能及时生效。同一组织其他成员的缓存条目只刷新余额。
+func (s *BillingCacheService) RecordOrganizationCharge(orgID, userID int64, cost float64, result *UsageBillingApplyResult) {
+	if s == nil {
+		return
+	}
+	var newBalance *float64
+	if result != nil {
+		newBalance = result.OrganizationBalance
+	}
+	prefix := fmt.Sprintf("%d:", orgID)
+	self := organizationBillingStateKey(orgID, userID)
+	s.organizationStates.Range(func(k, v any) bool {
+		key := k.(string)
+		if !strings.HasPrefix(key, prefix) {
+			return true
+		}
+		entry := *v.(*organizationBillingStateEntry)
+		if newBalance != nil {
+			entry.state.Balance = *newBalance
+		} else {
+			entry.state.Balance = money.Sum(entry.state.Balance, -cost)
+		}
+		if key == self {
+			entry.state.MonthSpendUSD = money.Sum(entry.state.MonthSpendUSD, cost)
+		}
+		s.organizationStates.Store(key, &entry)
+		return true
+	})
+}
+
+// InvalidateOrganizationBillingState 清除组织的全部缓存状态（成员、限额、余额或状态变更后调用）。
+func (s *BillingCacheService) InvalidateOrganizationBillingState(orgID int64) {
+	if s == nil {
+		return
+	}
+	prefix := fmt.Sprintf("%d:", orgID)
+	s.organizationStates.Range(func(k, _ any) bool {
+		if strings.HasPrefix(k.(string), prefix) {
+			s.organizationStates.Delete(k)
+		}
+		return true
+	})
+}
@@ -0,0 +1,89 @@
+//go:build unit
+
+package service
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func lineItemSum(c *CostBreakdown) decimal.Decimal {
+	return money.Decimal(c.InputCost).
+		Add(money.Decimal(c.ImageInputCost)).
+		Add(money.Decimal(c.OutputCost)).
+		Add(money.Decimal(c.ImageOutputCost)).
+		Add(money.Decimal(c.CacheCreationCost)).
+		Add(money.Decimal(c.CacheReadCost))
+}
+
+// 模拟一个月的请求：逐笔 usage_log.actual_cost 之和必须与余额扣减之和逐位相等，
+// 分项之和必须等于 total_cost。
+func TestCostBreakdown_PerRequestSumsReconcileWithLedger(t *testing.T) {
+	svc := newTestBillingService()
+	rng := rand.New(rand.NewSource(14))
+	multipliers := []float64{1, 1.25, 0.7, 0.333, 2.5}
+
+	var usageSum, ledgerSum decimal.Decimal
+	for i := 0; i < 5000; i++ {
+		tokens := UsageTokens{
+			InputTokens:         rng.Intn(200000),
+			OutputTokens:        rng.Intn(8000),
+			CacheCreationTokens: rng.Intn(5000),
+			CacheReadTokens:     rng.Intn(100000),
+		}
+		cost, err := svc.CalculateCostUnified(CostInput{
+			Model:          "claude-sonnet-4",
+			Tokens:         tokens,
+			RateMultiplier: multipliers[i%len(multipliers)],
+		})
+		require.NoError(t, err)
+
+		require.True(t, lineItemSum(cost).Equal(money.Decimal(cost.TotalCost)), "request %d: line items must sum to total", i)
+		require.LessOrEqual(t, decimalPlaces(cost.TotalCost), money.LineItemScale)
+		require.LessOrEqual(t, decimalPlaces(cost.ActualCost), money.LedgerScale)
+
+		cmd := &UsageBillingCommand{RequestID: "req", UserID: 1, APIKeyID: 1, AccountID: 1, BalanceCost: money.Decimal(cost.ActualCost)}
+		cmd.Normalize()
+		require.True(t, money.Decimal(cost.ActualCost).Equal(cmd.BalanceCost), "request %d: balance debit must equal usage_log.actual_cost", i)
+
+		usageSum = usageSum.Add(money.Decimal(cost.ActualCost))
+		ledgerSum = ledgerSum.Add(cmd.BalanceCost)
+	}
+
+	require.True(t, usageSum.Equal(ledgerSum))
+	require.True(t, usageSum.Equal(usageSum.Round(money.LedgerScale)), "monthly total stays on the ledger grid")
+}
+
+func TestCostBreakdown_RoundsHalfAwayFromZeroOnce(t *testing.T) {
+	// 10 input × 0.00000125 + 5 output × 0.00001 = 0.0000625，× 1.25 = 0.000078125
+	pricing := &ModelPricing{InputPricePerToken: 0.00000125, OutputPricePerToken: 0.00001}
+	cost := newTestBillingService().computeTokenBreakdown(pricing, UsageTokens{InputTokens: 10, OutputTokens: 5}, 1.25, "", false)
+
+	require.Equal(t, 0.0000625, cost.TotalCost)
+	require.Equal(t, 0.00007813, cost.ActualCost)
+}
+
+func TestCostBreakdown_AdjustmentsStayOnGrid(t *testing.T) {
+	svc := newTestBillingService()
+	cost, err := svc.CalculateCost("claude-sonnet-4", UsageTokens{InputTokens: 12345, OutputTokens: 678}, 1.1)
+	require.NoError(t, err)
+
+	search := svc.CalculateSearchCost(3, nil, 1.1)
+	expectedTotal := money.Decimal(cost.TotalCost).Add(money.Decimal(search.TotalCost))
+	expectedActual := money.Decimal(cost.ActualCost).Add(money.Decimal(search.ActualCost))
+	cost.add(search)
+	require.True(t, money.Decimal(cost.TotalCost).Equal(expectedTotal))
+	require.True(t, money.Decimal(cost.ActualCost).Equal(expectedActual))
+
+	applyCostBreakdownMultiplier(cost, 0.85)
+	require.LessOrEqual(t, decimalPlaces(cost.ActualCost), money.LedgerScale)
+	require.LessOrEqual(t, decimalPlaces(cost.InputCost), money.LineItemScale)
+
+	image := svc.CalculateImageCost("gemini-3-pro-image", "2K", 3, &ImagePriceConfig{Price2K: floatPtr(0.134)}, 0.9)
+	require.Equal(t, 0.402, image.TotalCost)
+	require.Equal(t, 0.3618, image.ActualCost)
+}
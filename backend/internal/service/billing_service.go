@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/xai"
+	"github.com/shopspring/decimal"
 )
 
 // APIKeyRateLimitCacheData holds rate limit usage data cached in Redis.
@@ -190,7 +192,12 @@ type UsageTokens struct {
 	ImageOutputTokens     int
 }
 
-// CostBreakdown 费用明细
+// CostBreakdown 费用明细。
+//
+// 字段以 float64 承载已按 money 精度定格的金额（明细 10 位、ActualCost 8 位小数），
+// 只作为载体：累加、缩放与扣费一律经 money / decimal 完成（见 add、applyCostBreakdownMultiplier
+// 与 UsageBillingCommand），不直接做浮点运算。定格值在 money.MaxExactLedgerAmount 以内
+// 经 float64 往返无损。
 type CostBreakdown struct {
 	InputCost                 float64 // 文本输入费用（不含图片输入，图片输入单独记入 ImageInputCost）
 	ImageInputCost            float64 // 图片输入 token 费用（如 gpt-image-2 图片编辑）
@@ -204,18 +211,63 @@ type CostBreakdown struct {
 	LongContextBillingApplied bool
 }
 
+// applyCostBreakdownMultiplier 把分项、TotalCost 与 ActualCost 同比缩放后重新定格。
 func applyCostBreakdownMultiplier(cost *CostBreakdown, multiplier float64) {
 	if cost == nil || multiplier == 1 {
 		return
 	}
-	cost.InputCost *= multiplier
-	cost.ImageInputCost *= multiplier
-	cost.OutputCost *= multiplier
-	cost.ImageOutputCost *= multiplier
-	cost.CacheCreationCost *= multiplier
-	cost.CacheReadCost *= multiplier
-	cost.TotalCost *= multiplier
-	cost.ActualCost *= multiplier
+	m := money.Decimal(multiplier)
+	scale := func(v *float64, places int32) {
+		*v = money.Float(money.Decimal(*v).Mul(m).Round(places))
+	}
+	scale(&cost.InputCost, money.LineItemScale)
+	scale(&cost.ImageInputCost, money.LineItemScale)
+	scale(&cost.OutputCost, money.LineItemScale)
+	scale(&cost.ImageOutputCost, money.LineItemScale)
+	scale(&cost.CacheCreationCost, money.LineItemScale)
+	scale(&cost.CacheReadCost, money.LineItemScale)
+	scale(&cost.TotalCost, money.LineItemScale)
+	scale(&cost.ActualCost, money.LedgerScale)
+}
+
+// settle 按 money 包的舍入策略定格 token 计费明细：TotalCost 为已舍入分项的
+// 精确和，ActualCost = round(TotalCost × rateMultiplier, LedgerScale)。
+// 因而分项之和恒等于 TotalCost，ActualCost 与余额 / 配额扣减的金额逐位相同。
+func (c *CostBreakdown) settle(rateMultiplier float64) {
+	total := money.Decimal(c.InputCost).
+		Add(money.Decimal(c.ImageInputCost)).
+		Add(money.Decimal(c.OutputCost)).
+		Add(money.Decimal(c.ImageOutputCost)).
+		Add(money.Decimal(c.CacheCreationCost)).
+		Add(money.Decimal(c.CacheReadCost))
+	c.TotalCost = money.Float(total)
+	c.ActualCost = money.Float(total.Mul(money.Decimal(rateMultiplier)).Round(money.LedgerScale))
+}
+
+// add 把另一份已定格的明细（如搜索附加费）逐项累加到 c；decimal 相加不引入新的舍入。
+func (c *CostBreakdown) add(other *CostBreakdown) {
+	if c == nil || other == nil {
+		return
+	}
+	c.InputCost = money.Sum(c.InputCost, other.InputCost)
+	c.ImageInputCost = money.Sum(c.ImageInputCost, other.ImageInputCost)
+	c.OutputCost = money.Sum(c.OutputCost, other.OutputCost)
+	c.ImageOutputCost = money.Sum(c.ImageOutputCost, other.ImageOutputCost)
+	c.CacheCreationCost = money.Sum(c.CacheCreationCost, other.CacheCreationCost)
+	c.CacheReadCost = money.Sum(c.CacheReadCost, other.CacheReadCost)
+	c.TotalCost = money.Sum(c.TotalCost, other.TotalCost)
+	c.ActualCost = money.Sum(c.ActualCost, other.ActualCost)
+}
+
+// flatCost 计算不拆分项的按量费用：TotalCost = round(unitPrice × quantity, LineItemScale)，
+// ActualCost = round(TotalCost × rateMultiplier, LedgerScale)。
+func flatCost(unitPrice, quantity, rateMultiplier float64, mode BillingMode) *CostBreakdown {
+	total := money.Decimal(unitPrice).Mul(money.Decimal(quantity)).Round(money.LineItemScale)
+	return &CostBreakdown{
+		TotalCost:   money.Float(total),
+		ActualCost:  money.Float(total.Mul(money.Decimal(rateMultiplier)).Round(money.LedgerScale)),
+		BillingMode: string(mode),
+	}
 }
 
 func resolvedChannelTimeMultiplier(resolved *ResolvedPricing, at time.Time) float64 {
@@ -1306,6 +1358,7 @@ func (s *BillingService) computeTokenBreakdown(
 		cacheCreationMultiplier = pricing.LongContextInputMultiplier
 	}
 
+	tier := money.Decimal(tierMultiplier)
 	bd := &CostBreakdown{}
 	// 分离图片输入 token 与文本输入 token（多模态 embedding、图片编辑等图文不同价场景）。
 	// InputCost 仅计文本输入，图片输入费用单独记入 ImageInputCost，便于对账；总额不变。
@@ -1322,10 +1375,10 @@ func (s *BillingService) computeTokenBreakdown(
 			// 未配置图片输入档时回退到文本 input 价（已含 priority / 长上下文调整）
 			imageInputPrice = inputPrice
 		}
-		bd.InputCost = float64(textInputTokens) * inputPrice
-		bd.ImageInputCost = float64(imageInputTokens) * imageInputPrice
+		bd.InputCost = tokenLineItem(textInputTokens, inputPrice, tier)
+		bd.ImageInputCost = tokenLineItem(imageInputTokens, imageInputPrice, tier)
 	} else {
-		bd.InputCost = float64(tokens.InputTokens) * inputPrice
+		bd.InputCost = tokenLineItem(tokens.InputTokens, inputPrice, tier)
 	}
 
 	// 分离图片输出 token 与文本输出 token
@@ -1333,7 +1386,7 @@ func (s *BillingService) computeTokenBreakdown(
 	if textOutputTokens < 0 {
 		textOutputTokens = 0
 	}
-	bd.OutputCost = float64(textOutputTokens) * outputPrice
+	bd.OutputCost = tokenLineItem(textOutputTokens, outputPrice, tier)
 
 	// 图片输出 token 费用（独立费率）
 	if tokens.ImageOutputTokens > 0 {
@@ -1341,43 +1394,46 @@ func (s *BillingService) computeTokenBreakdown(
 		if imgPrice == 0 && !pricing.ImageOutputPriceExplicit {
 			imgPrice = outputPrice
 		}
-		bd.ImageOutputCost = float64(tokens.ImageOutputTokens) * imgPrice
+		bd.ImageOutputCost = tokenLineItem(tokens.ImageOutputTokens, imgPrice, tier)
 	}
 
 	// 缓存创建费用
-	bd.CacheCreationCost = s.computeCacheCreationCost(pricing, tokens, cacheCreationPrice, cacheCreationMultiplier)
+	bd.CacheCreationCost = s.computeCacheCreationCost(pricing, tokens, cacheCreationPrice, cacheCreationMultiplier*tierMultiplier)
 
-	bd.CacheReadCost = float64(tokens.CacheReadTokens) * cacheReadPrice
+	bd.CacheReadCost = tokenLineItem(tokens.CacheReadTokens, cacheReadPrice, tier)
 
-	if tierMultiplier != 1.0 {
-		bd.InputCost *= tierMultiplier
-		bd.ImageInputCost *= tierMultiplier
-		bd.OutputCost *= tierMultiplier
-		bd.ImageOutputCost *= tierMultiplier
-		bd.CacheCreationCost *= tierMultiplier
-		bd.CacheReadCost *= tierMultiplier
-	}
-
-	bd.TotalCost = bd.InputCost + bd.ImageInputCost + bd.OutputCost + bd.ImageOutputCost +
-		bd.CacheCreationCost + bd.CacheReadCost
-	bd.ActualCost = bd.TotalCost * rateMultiplier
+	bd.settle(rateMultiplier)
 	bd.LongContextBillingApplied = baselineCost != nil && bd.ActualCost > baselineCost.ActualCost
 
 	return bd
 }
 
+// tokenLineItem 在 decimal 上计算 tokens × price × multiplier，并舍入到明细精度。
+func tokenLineItem(tokens int, price float64, multiplier decimal.Decimal) float64 {
+	if tokens == 0 || price == 0 {
+		return 0
+	}
+	return money.Float(decimal.NewFromInt(int64(tokens)).
+		Mul(money.Decimal(price)).
+		Mul(multiplier).
+		Round(money.LineItemScale))
+}
+
 // computeCacheCreationCost 计算缓存创建费用（支持 5m/1h 分类或标准计费）。
-// multiplier 用于长上下文等场景下的整体价格缩放（普通调用传 1.0 即可）。
+// multiplier 用于长上下文、service tier 等场景下的整体价格缩放（普通调用传 1.0 即可）。
 func (s *BillingService) computeCacheCreationCost(pricing *ModelPricing, tokens UsageTokens, price, multiplier float64) float64 {
+	m := money.Decimal(multiplier)
 	if pricing.SupportsCacheBreakdown && (pricing.CacheCreation5mPrice > 0 || pricing.CacheCreation1hPrice > 0) {
 		if tokens.CacheCreation5mTokens == 0 && tokens.CacheCreation1hTokens == 0 && tokens.CacheCreationTokens > 0 {
 			// API 未返回 ephemeral 明细，回退到全部按 5m 单价计费
-			return float64(tokens.CacheCreationTokens) * pricing.CacheCreation5mPrice * multiplier
+			return tokenLineItem(tokens.CacheCreationTokens, pricing.CacheCreation5mPrice, m)
 		}
-		return float64(tokens.CacheCreation5mTokens)*pricing.CacheCreation5mPrice*multiplier +
-			float64(tokens.CacheCreation1hTokens)*pricing.CacheCreation1hPrice*multiplier
+		return money.Sum(
+			tokenLineItem(tokens.CacheCreation5mTokens, pricing.CacheCreation5mPrice, m),
+			tokenLineItem(tokens.CacheCreation1hTokens, pricing.CacheCreation1hPrice, m),
+		)
 	}
-	return float64(tokens.CacheCreationTokens) * price * multiplier
+	return tokenLineItem(tokens.CacheCreationTokens, price, m)
 }
 
 // calculatePerRequestCost 按次/图片计费
@@ -1407,13 +1463,7 @@ func (s *BillingService) calculatePerRequestCost(resolved *ResolvedPricing, inpu
 		unitPrice = resolved.DefaultPerRequestPrice
 	}
 
-	totalCost := unitPrice * units
-	actualCost := totalCost * input.RateMultiplier
-
-	return &CostBreakdown{
-		TotalCost:  totalCost,
-		ActualCost: actualCost,
-	}, nil
+	return flatCost(unitPrice, units, input.RateMultiplier, ""), nil
 }
 
 // CalculateCost 计算使用费用
@@ -1590,18 +1640,10 @@ func (s *BillingService) CalculateCostWithLongContext(model string, tokens Usage
 		return inRangeCost, fmt.Errorf("out-range cost: %w", err)
 	}
 
-	// 合并成本
-	return &CostBreakdown{
-		InputCost:                 inRangeCost.InputCost + outRangeCost.InputCost,
-		ImageInputCost:            inRangeCost.ImageInputCost + outRangeCost.ImageInputCost,
-		OutputCost:                inRangeCost.OutputCost,
-		ImageOutputCost:           inRangeCost.ImageOutputCost,
-		CacheCreationCost:         inRangeCost.CacheCreationCost,
-		CacheReadCost:             inRangeCost.CacheReadCost + outRangeCost.CacheReadCost,
-		TotalCost:                 inRangeCost.TotalCost + outRangeCost.TotalCost,
-		ActualCost:                inRangeCost.ActualCost + outRangeCost.ActualCost,
-		LongContextBillingApplied: outRangeCost.ActualCost > 0,
-	}, nil
+	// 合并成本（两段均已定格，decimal 上相加不再引入舍入）
+	inRangeCost.add(outRangeCost)
+	inRangeCost.LongContextBillingApplied = outRangeCost.ActualCost > 0
+	return inRangeCost, nil
 }
 
 // ListSupportedModels 列出所有支持的模型（现在总是返回true，因为有模糊匹配）
@@ -1696,6 +1738,9 @@ const (
 	// Codex alpha/search 网页搜索单次默认价：OpenAI 官方 web search 定价 $10/1000 次。
 	defaultWebSearchPricePerCall = 0.01
 
+	// 网关模拟 web_fetch 单次抓取默认价：与 web search 默认价保持一致。
+	defaultWebFetchPricePerCall = 0.01
+
 	// xAI server-side web/X search and code execution are $5/1000 calls.
 	defaultSearchPricePer1k = 5.0
 
@@ -1718,17 +1763,27 @@ func (s *BillingService) CalculateWebSearchCost(callCount int, groupPrice *float
 	if groupPrice != nil && *groupPrice >= 0 {
 		unitPrice = *groupPrice
 	}
-	totalCost := unitPrice * float64(callCount)
-
 	// 应用倍率（保存时强制 > 0；负数按 0 处理避免按 1x 误扣）
 	if rateMultiplier < 0 {
 		rateMultiplier = 0
 	}
-	return &CostBreakdown{
-		TotalCost:   totalCost,
-		ActualCost:  totalCost * rateMultiplier,
-		BillingMode: string(BillingModePerRequest),
+	return flatCost(unitPrice, float64(callCount), rateMultiplier, BillingModePerRequest)
+}
+
+// CalculateWebFetchCost 计算网关模拟 web_fetch 的按次抓取费用（只计成功抓取的页面）。
+// groupPrice: 分组配置的单次价格（nil 表示使用默认价 0.01；0 表示免费）
+func (s *BillingService) CalculateWebFetchCost(fetchCount int, groupPrice *float64, rateMultiplier float64) *CostBreakdown {
+	if fetchCount <= 0 {
+		return &CostBreakdown{}
+	}
+	unitPrice := defaultWebFetchPricePerCall
+	if groupPrice != nil && *groupPrice >= 0 {
+		unitPrice = *groupPrice
+	}
+	if rateMultiplier < 0 {
+		rateMultiplier = 0
 	}
+	return flatCost(unitPrice, float64(fetchCount), rateMultiplier, BillingModePerRequest)
 }
 
 // CalculateSearchCost bills search/tool invocations (e.g. web_search) per 1k calls.
@@ -1750,13 +1805,7 @@ func (s *BillingService) CalculateSearchCost(numCalls int, groupPricePer1k *floa
 	if rateMultiplier < 0 {
 		rateMultiplier = 0
 	}
-	unit := pricePer1k / 1000.0
-	total := unit * float64(numCalls)
-	return &CostBreakdown{
-		TotalCost:   total,
-		ActualCost:  total * rateMultiplier,
-		BillingMode: string(BillingModePerRequest),
-	}
+	return flatCost(pricePer1k, float64(numCalls)/1000.0, rateMultiplier, BillingModePerRequest)
 }
 
 type audioPriceConfig struct {
@@ -1797,12 +1846,7 @@ func (s *BillingService) CalculateAudioCost(mode string, durationOrUnits float64
 	if rateMultiplier < 0 {
 		rateMultiplier = 0
 	}
-	total := unitPrice * durationOrUnits
-	return &CostBreakdown{
-		TotalCost:   total,
-		ActualCost:  total * rateMultiplier,
-		BillingMode: string(BillingModePerRequest),
-	}
+	return flatCost(unitPrice, durationOrUnits, rateMultiplier, BillingModePerRequest)
 }
 
 // CalculateImageCost 计算图片生成费用
@@ -1820,20 +1864,11 @@ func (s *BillingService) CalculateImageCost(model string, imageSize string, imag
 	// 获取单价
 	unitPrice := s.getImageUnitPrice(model, imageSize, groupConfig)
 
-	// 计算总费用
-	totalCost := unitPrice * float64(imageCount)
-
 	// 应用倍率（保存时强制 > 0；负数按 0 处理避免按 1x 误扣）
 	if rateMultiplier < 0 {
 		rateMultiplier = 0
 	}
-	actualCost := totalCost * rateMultiplier
-
-	return &CostBreakdown{
-		TotalCost:   totalCost,
-		ActualCost:  actualCost,
-		BillingMode: string(BillingModeImage),
-	}
+	return flatCost(unitPrice, float64(imageCount), rateMultiplier, BillingModeImage)
 }
 
 // CalculateVideoCost 计算视频生成费用（按秒计费，与 xAI 口径一致）。
@@ -1851,18 +1886,11 @@ func (s *BillingService) CalculateVideoCost(model string, resolution string, vid
 	durationSeconds = NormalizeVideoBillingDurationSecondsOrDefault(durationSeconds)
 
 	perSecondPrice := s.getVideoUnitPrice(model, resolution, groupConfig)
-	totalCost := perSecondPrice * float64(durationSeconds) * float64(videoCount)
 
 	if rateMultiplier < 0 {
 		rateMultiplier = 0
 	}
-	actualCost := totalCost * rateMultiplier
-
-	return &CostBreakdown{
-		TotalCost:   totalCost,
-		ActualCost:  actualCost,
-		BillingMode: string(BillingModeVideo),
-	}
+	return flatCost(perSecondPrice, float64(durationSeconds)*float64(videoCount), rateMultiplier, BillingModeVideo)
 }
 
 // getImageUnitPrice 获取图片单价
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// 预算挂载对象。用户 / Key 预算统计该用户 / Key 的消费；分组预算统计所有用户经该分组的消费总和。
+const (
+	BudgetScopeUser   = "user"
+	BudgetScopeAPIKey = "api_key"
+	BudgetScopeGroup  = "group"
+)
+
+// 预算周期为配置时区下的自然日 / 周（周一起）/ 月，而不是滚动窗口。
+const (
+	BudgetPeriodDaily   = "daily"
+	BudgetPeriodWeekly  = "weekly"
+	BudgetPeriodMonthly = "monthly"
+)
+
+// hard 预算用尽后拒绝请求；soft 预算只通知。
+const (
+	BudgetEnforcementHard = "hard"
+	BudgetEnforcementSoft = "soft"
+)
+
+// 预算来源：admin 预算只有管理员可修改；用户可管理自己创建的 user 预算。
+const (
+	BudgetSourceAdmin = "admin"
+	BudgetSourceUser  = "user"
+)
+
+// budgetAlertPercents 消费达到预算的这些百分比时通知（每个周期每档最多一次）。
+var budgetAlertPercents = []int{50, 80, 100}
+
+// budgetRecordTimeout 扣费后异步累加预算消费与领取通知的超时。
+const budgetRecordTimeout = 10 * time.Second
+
+var (
+	ErrBudgetDisabled           = infraerrors.Forbidden("BUDGET_DISABLED", "budgets are disabled")
+	ErrBudgetNotFound           = infraerrors.NotFound("BUDGET_NOT_FOUND", "budget not found")
+	ErrBudgetExists             = infraerrors.Conflict("BUDGET_EXISTS", "a budget for this scope, model and period already exists")
+	ErrBudgetForbidden          = infraerrors.Forbidden("BUDGET_FORBIDDEN", "this budget is managed by an administrator")
+	ErrBudgetLimitReached       = infraerrors.BadRequest("BUDGET_LIMIT_REACHED", "budget limit per scope reached")
+	ErrBudgetInvalidScope       = infraerrors.BadRequest("BUDGET_INVALID_SCOPE", "invalid budget scope")
+	ErrBudgetInvalidPeriod      = infraerrors.BadRequest("BUDGET_INVALID_PERIOD", "period must be daily, weekly or monthly")
+	ErrBudgetInvalidLimit       = infraerrors.BadRequest("BUDGET_INVALID_LIMIT", "limit_usd must be greater than 0")
+	ErrBudgetInvalidEnforcement = infraerrors.BadRequest("BUDGET_INVALID_ENFORCEMENT", "enforcement must be hard or soft")
+	ErrBudgetInvalidModel       = infraerrors.BadRequest("BUDGET_INVALID_MODEL", "model must be at most 100 characters")
+	// ErrBudgetExceeded 与 user × platform 配额一致映射为 429，并带 window_resets_at metadata。
+	ErrBudgetExceeded = infraerrors.TooManyRequests("BUDGET_EXCEEDED", "Spending budget exhausted for the current period.")
+)
+
+// Budget 一条预算线。Model 为空表示全部模型。
+// AlertPeriodStart / AlertPercent 记录本周期已通知的最高阈值。
+type Budget struct {
+	ID               int64
+	ScopeType        string
+	ScopeID          int64
+	Model            string
+	Period           string
+	LimitUSD         float64
+	Enforcement      string
+	Enabled          bool
+	Source           string
+	CreatedBy        *int64
+	AlertPeriodStart *time.Time
+	AlertPercent     int
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (b *Budget) IsHard() bool {
+	return b != nil && b.Enforcement == BudgetEnforcementHard
+}
+
+// AppliesToModel 全模型预算总是适用；按模型的预算只在请求模型已知且相同时适用。
+func (b *Budget) AppliesToModel(model string) bool {
+	return b.Model == "" || (model != "" && b.Model == model)
+}
+
+// alertedPercent 返回本周期已通知的最高阈值，周期切换后视为 0。
+func (b *Budget) alertedPercent(periodStart time.Time) int {
+	if b.AlertPeriodStart == nil || !b.AlertPeriodStart.Equal(periodStart) {
+		return 0
+	}
+	return b.AlertPercent
+}
+
+// BudgetScopeRef 预算挂载对象的引用。
+type BudgetScopeRef struct {
+	Type string
+	ID   int64
+}
+
+// BudgetPeriodWindow 返回 now 所在自然周期的 [start, end)。
+func BudgetPeriodWindow(period string, now time.Time) (time.Time, time.Time) {
+	switch period {
+	case BudgetPeriodDaily:
+		start := timezone.StartOfDay(now)
+		return start, start.AddDate(0, 0, 1)
+	case BudgetPeriodWeekly:
+		start := timezone.StartOfWeek(now)
+		return start, start.AddDate(0, 0, 7)
+	default:
+		start := timezone.StartOfMonth(now)
+		return start, start.AddDate(0, 1, 0)
+	}
+}
+
+// BudgetStatus 预算及其本周期消费。
+type BudgetStatus struct {
+	Budget      Budget
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	SpentUSD    float64
+}
+
+// BudgetAlert 一次阈值通知。UserID 为用户 / Key 预算的所属用户，分组预算为 0（通知管理员）。
+type BudgetAlert struct {
+	Budget      Budget
+	Percent     int
+	SpentUSD    float64
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	UserID      int64
+	APIKeyName  string
+}
+
+// BudgetListFilter 管理端列表筛选，零值表示不筛选。
+type BudgetListFilter struct {
+	ScopeType string
+	ScopeID   int64
+}
+
+type BudgetRepository interface {
+	// Create 写入预算；(scope, model, period) 冲突时返回 ErrBudgetExists。
+	Create(ctx context.Context, budget *Budget) error
+	GetByID(ctx context.Context, id int64) (*Budget, error)
+	Update(ctx context.Context, budget *Budget) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, params pagination.PaginationParams, filter BudgetListFilter) ([]Budget, *pagination.PaginationResult, error)
+	// ListByScope 返回挂在 scope 上的预算；enabledOnly 时只返回启用的。
+	ListByScope(ctx context.Context, scope BudgetScopeRef, enabledOnly bool) ([]Budget, error)
+	// ListForUser 返回挂在用户本人及其 API Key 上的全部预算。
+	ListForUser(ctx context.Context, userID int64) ([]Budget, error)
+	CountByScope(ctx context.Context, scope BudgetScopeRef) (int, error)
+
+	// GetSpend 统计 [start, end) 内该预算口径的余额计费消费（usage_logs.actual_cost）。
+	GetSpend(ctx context.Context, budget *Budget, start, end time.Time) (float64, error)
+	// ClaimAlert 原子地把本周期已通知阈值推进到 percent；已有实例通知过同档或更高档时返回 false。
+	ClaimAlert(ctx context.Context, id int64, periodStart time.Time, percent int) (bool, error)
+}
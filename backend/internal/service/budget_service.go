@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+const budgetMaxModelLength = 100
+
+// CreateBudgetInput 新建预算的参数。Enforcement 为空时默认 hard。
+type CreateBudgetInput struct {
+	ScopeType   string
+	ScopeID     int64
+	Model       string
+	Period      string
+	LimitUSD    float64
+	Enforcement string
+	Enabled     *bool
+}
+
+// UpdateBudgetInput 更新预算的参数，nil 字段保持不变。挂载对象不可修改。
+type UpdateBudgetInput struct {
+	Model       *string
+	Period      *string
+	LimitUSD    *float64
+	Enforcement *string
+	Enabled     *bool
+}
+
+// BudgetService 管理用户、API Key 与分组上的自然周期预算。
+// 检查与消费累加由 BillingCacheService 完成，这里负责增删改查与当前周期用量查询。
+type BudgetService struct {
+	repo                BudgetRepository
+	apiKeyRepo          APIKeyRepository
+	userRepo            UserRepository
+	groupRepo           GroupRepository
+	billingCacheService *BillingCacheService
+	cfg                 *config.Config
+}
+
+// NewBudgetService creates a BudgetService.
+func NewBudgetService(
+	repo BudgetRepository,
+	apiKeyRepo APIKeyRepository,
+	userRepo UserRepository,
+	groupRepo GroupRepository,
+	billingCacheService *BillingCacheService,
+	cfg *config.Config,
+) *BudgetService {
+	return &BudgetService{
+		repo:                repo,
+		apiKeyRepo:          apiKeyRepo,
+		userRepo:            userRepo,
+		groupRepo:           groupRepo,
+		billingCacheService: billingCacheService,
+		cfg:                 cfg,
+	}
+}
+
+func (s *BudgetService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg != nil && s.cfg.Budget.Enabled
+}
+
+func normalizeBudgetModel(model string) (string, error) {
+	model = strings.TrimSpace(model)
+	if model == "*" {
+		model = ""
+	}
+	if utf8.RuneCountInString(model) > budgetMaxModelLength {
+		return "", ErrBudgetInvalidModel
+	}
+	return model, nil
+}
+
+func validateBudgetPeriod(period string) error {
+	switch period {
+	case BudgetPeriodDaily, BudgetPeriodWeekly, BudgetPeriodMonthly:
+		return nil
+	}
+	return ErrBudgetInvalidPeriod
+}
+
+func validateBudgetLimit(limit float64) error {
+	if math.IsNaN(limit) || math.IsInf(limit, 0) || limit <= 0 {
+		return ErrBudgetInvalidLimit
+	}
+	return nil
+}
+
+func normalizeBudgetEnforcement(enforcement string) (string, error) {
+	switch enforcement {
+	case "":
+		return BudgetEnforcementHard, nil
+	case BudgetEnforcementHard, BudgetEnforcementSoft:
+		return enforcement, nil
+	}
+	return "", ErrBudgetInvalidEnforcement
+}
+
+// buildBudget 校验输入并构造待写入的预算（不含挂载对象存在性校验）。
+func buildBudget(in CreateBudgetInput, source string, createdBy int64) (*Budget, error) {
+	model, err := normalizeBudgetModel(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBudgetPeriod(in.Period); err != nil {
+		return nil, err
+	}
+	if err := validateBudgetLimit(in.LimitUSD); err != nil {
+		return nil, err
+	}
+	enforcement, err := normalizeBudgetEnforcement(in.Enforcement)
+	if err != nil {
+		return nil, err
+	}
+	enabled := true
+	if in.Enabled != nil {
+		enabled = *in.Enabled
+	}
+	b := &Budget{
+		ScopeType:   in.ScopeType,
+		ScopeID:     in.ScopeID,
+		Model:       model,
+		Period:      in.Period,
+		LimitUSD:    in.LimitUSD,
+		Enforcement: enforcement,
+		Enabled:     enabled,
+		Source:      source,
+	}
+	if createdBy > 0 {
+		b.CreatedBy = &createdBy
+	}
+	return b, nil
+}
+
+// applyBudgetUpdate 把更新参数合并到预算上。
+func applyBudgetUpdate(b *Budget, in UpdateBudgetInput) error {
+	if in.Model != nil {
+		model, err := normalizeBudgetModel(*in.Model)
+		if err != nil {
+			return err
+		}
+		b.Model = model
+	}
+	if in.Period != nil {
+		if err := validateBudgetPeriod(*in.Period); err != nil {
+			return err
+		}
+		b.Period = *in.Period
+	}
+	if in.LimitUSD != nil {
+		if err := validateBudgetLimit(*in.LimitUSD); err != nil {
+			return err
+		}
+		b.LimitUSD = *in.LimitUSD
+	}
+	if in.Enforcement != nil {
+		enforcement, err := normalizeBudgetEnforcement(*in.Enforcement)
+		if err != nil {
+			return err
+		}
+		b.Enforcement = enforcement
+	}
+	if in.Enabled != nil {
+		b.Enabled = *in.Enabled
+	}
+	return nil
+}
+
+// ensureScopeExists 校验挂载对象存在。
+func (s *BudgetService) ensureScopeExists(ctx context.Context, scope BudgetScopeRef) error {
+	if scope.ID <= 0 {
+		return ErrBudgetInvalidScope
+	}
+	var err error
+	switch scope.Type {
+	case BudgetScopeUser:
+		_, err = s.userRepo.GetByID(ctx, scope.ID)
+	case BudgetScopeAPIKey:
+		_, _, err = s.apiKeyRepo.GetKeyAndOwnerID(ctx, scope.ID)
+	case BudgetScopeGroup:
+		_, err = s.groupRepo.GetByIDLite(ctx, scope.ID)
+	default:
+		return ErrBudgetInvalidScope
+	}
+	return err
+}
+
+func (s *BudgetService) create(ctx context.Context, b *Budget) (*Budget, error) {
+	scope := BudgetScopeRef{Type: b.ScopeType, ID: b.ScopeID}
+	count, err := s.repo.CountByScope(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if count >= s.cfg.Budget.MaxPerScope {
+		return nil, ErrBudgetLimitReached
+	}
+	if err := s.repo.Create(ctx, b); err != nil {
+		return nil, err
+	}
+	s.billingCacheService.InvalidateBudgetScope(scope)
+	return b, nil
+}
+
+func (s *BudgetService) update(ctx context.Context, b *Budget, in UpdateBudgetInput) (*Budget, error) {
+	if err := applyBudgetUpdate(b, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, b); err != nil {
+		return nil, err
+	}
+	s.billingCacheService.InvalidateBudgetScope(BudgetScopeRef{Type: b.ScopeType, ID: b.ScopeID})
+	return b, nil
+}
+
+func (s *BudgetService) delete(ctx context.Context, b *Budget) error {
+	if err := s.repo.Delete(ctx, b.ID); err != nil {
+		return err
+	}
+	s.billingCacheService.InvalidateBudgetScope(BudgetScopeRef{Type: b.ScopeType, ID: b.ScopeID})
+	return nil
+}
+
+// statusOf 查询预算在当前周期的消费。
+func (s *BudgetService) statusOf(ctx context.Context, b Budget) (BudgetStatus, error) {
+	start, end := BudgetPeriodWindow(b.Period, timezone.Now())
+	spent, err := s.repo.GetSpend(ctx, &b, start, end)
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	return BudgetStatus{Budget: b, PeriodStart: start, PeriodEnd: end, SpentUSD: spent}, nil
+}
+
+// AdminList 分页列出预算。
+func (s *BudgetService) AdminList(ctx context.Context, params pagination.PaginationParams, filter BudgetListFilter) ([]Budget, *pagination.PaginationResult, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrBudgetDisabled
+	}
+	return s.repo.List(ctx, params, filter)
+}
+
+// AdminCreate 管理员在任意用户、Key 或分组上创建预算，用户无法修改或删除。
+func (s *BudgetService) AdminCreate(ctx context.Context, adminID int64, in CreateBudgetInput) (*Budget, error) {
+	if !s.Enabled() {
+		return nil, ErrBudgetDisabled
+	}
+	b, err := buildBudget(in, BudgetSourceAdmin, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureScopeExists(ctx, BudgetScopeRef{Type: b.ScopeType, ID: b.ScopeID}); err != nil {
+		return nil, err
+	}
+	return s.create(ctx, b)
+}
+
+func (s *BudgetService) AdminUpdate(ctx context.Context, id int64, in UpdateBudgetInput) (*Budget, error) {
+	if !s.Enabled() {
+		return nil, ErrBudgetDisabled
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(ctx, b, in)
+}
+
+func (s *BudgetService) AdminDelete(ctx context.Context, id int64) error {
+	if !s.Enabled() {
+		return ErrBudgetDisabled
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.delete(ctx, b)
+}
+
+// GetStatus 返回预算及其当前周期消费。
+func (s *BudgetService) GetStatus(ctx context.Context, id int64) (*BudgetStatus, error) {
+	if !s.Enabled() {
+		return nil, ErrBudgetDisabled
+	}
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.statusOf(ctx, *b)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// ListForUser 返回挂在用户本人及其 API Key 上的预算（含管理员创建的）与当前周期消费。
+func (s *BudgetService) ListForUser(ctx context.Context, userID int64) ([]BudgetStatus, error) {
+	if !s.Enabled() {
+		return nil, ErrBudgetDisabled
+	}
+	budgets, err := s.repo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]BudgetStatus, 0, len(budgets))
+	for _, b := range budgets {
+		st, err := s.statusOf(ctx, b)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// authorizeUserScope 用户只能把预算挂在自己或自己的 API Key 上。
+func (s *BudgetService) authorizeUserScope(ctx context.Context, userID int64, scope BudgetScopeRef) error {
+	switch scope.Type {
+	case BudgetScopeUser:
+		if scope.ID != userID {
+			return ErrBudgetInvalidScope
+		}
+		return nil
+	case BudgetScopeAPIKey:
+		_, ownerID, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, scope.ID)
+		if err != nil {
+			return err
+		}
+		if ownerID != userID {
+			return ErrAPIKeyNotFound
+		}
+		return nil
+	}
+	return ErrBudgetInvalidScope
+}
+
+// getOwnedUserBudget 取用户可修改的预算：挂在自己或自己 Key 上、且由用户创建。
+func (s *BudgetService) getOwnedUserBudget(ctx context.Context, userID, id int64) (*Budget, error) {
+	b, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeUserScope(ctx, userID, BudgetScopeRef{Type: b.ScopeType, ID: b.ScopeID}); err != nil {
+		return nil, ErrBudgetNotFound
+	}
+	if b.Source != BudgetSourceUser {
+		return nil, ErrBudgetForbidden
+	}
+	return b, nil
+}
+
+// CreateForUser 用户给自己或自己的 API Key 设置预算；ScopeID 为 0 的用户预算视为本人。
+func (s *BudgetService) CreateForUser(ctx context.Context, userID int64, in CreateBudgetInput) (*Budget, error) {
+	if !s.Enabled() {
+		return nil, ErrBudgetDisabled
+	}
+	if in.ScopeType == BudgetScopeUser && in.ScopeID == 0 {
+		in.ScopeID = userID
+	}
+	if err := s.authorizeUserScope(ctx, userID, BudgetScopeRef{Type: in.ScopeType, ID: in.ScopeID}); err != nil {
+		return nil, err
+	}
+	b, err := buildBudget(in, BudgetSourceUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.create(ctx, b)
+}
+
+func (s *BudgetService) UpdateForUser(ctx context.Context, userID, id int64, in UpdateBudgetInput) (*Budget, error) {
+	if !s.Enabled() {
+		return nil, ErrBudgetDisabled
+	}
+	b, err := s.getOwnedUserBudget(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(ctx, b, in)
+}
+
+func (s *BudgetService) DeleteForUser(ctx context.Context, userID, id int64) error {
+	if !s.Enabled() {
+		return ErrBudgetDisabled
+	}
+	b, err := s.getOwnedUserBudget(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.delete(ctx, b)
+}
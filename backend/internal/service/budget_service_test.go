@@ -0,0 +1,304 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/stretchr/testify/require"
+)
+
+type budgetRepoStub struct {
+	BudgetRepository
+
+	budgets    map[int64]*Budget
+	spend      map[int64]float64
+	spendErr   error
+	spendCalls int
+	claimed    map[int64]int
+	created    []*Budget
+	count      int
+}
+
+func newBudgetRepoStub(budgets ...Budget) *budgetRepoStub {
+	r := &budgetRepoStub{budgets: map[int64]*Budget{}, spend: map[int64]float64{}, claimed: map[int64]int{}}
+	for i := range budgets {
+		b := budgets[i]
+		r.budgets[b.ID] = &b
+	}
+	return r
+}
+
+func (r *budgetRepoStub) GetByID(_ context.Context, id int64) (*Budget, error) {
+	b, ok := r.budgets[id]
+	if !ok {
+		return nil, ErrBudgetNotFound
+	}
+	out := *b
+	return &out, nil
+}
+
+func (r *budgetRepoStub) ListByScope(_ context.Context, scope BudgetScopeRef, enabledOnly bool) ([]Budget, error) {
+	var out []Budget
+	for _, b := range r.budgets {
+		if b.ScopeType == scope.Type && b.ScopeID == scope.ID && (!enabledOnly || b.Enabled) {
+			out = append(out, *b)
+		}
+	}
+	return out, nil
+}
+
+func (r *budgetRepoStub) CountByScope(context.Context, BudgetScopeRef) (int, error) {
+	return r.count, nil
+}
+
+func (r *budgetRepoStub) Create(_ context.Context, b *Budget) error {
+	b.ID = int64(len(r.budgets) + 100)
+	r.created = append(r.created, b)
+	return nil
+}
+
+func (r *budgetRepoStub) Update(_ context.Context, b *Budget) error {
+	out := *b
+	r.budgets[b.ID] = &out
+	return nil
+}
+
+func (r *budgetRepoStub) Delete(_ context.Context, id int64) error {
+	delete(r.budgets, id)
+	return nil
+}
+
+func (r *budgetRepoStub) GetSpend(_ context.Context, b *Budget, _, _ time.Time) (float64, error) {
+	r.spendCalls++
+	if r.spendErr != nil {
+		return 0, r.spendErr
+	}
+	return r.spend[b.ID], nil
+}
+
+func (r *budgetRepoStub) ClaimAlert(_ context.Context, id int64, _ time.Time, percent int) (bool, error) {
+	if r.claimed[id] >= percent {
+		return false, nil
+	}
+	r.claimed[id] = percent
+	return true, nil
+}
+
+type budgetAPIKeyRepoStub struct {
+	APIKeyRepository
+	owners map[int64]int64
+}
+
+func (r *budgetAPIKeyRepoStub) GetKeyAndOwnerID(_ context.Context, id int64) (string, int64, error) {
+	owner, ok := r.owners[id]
+	if !ok {
+		return "", 0, ErrAPIKeyNotFound
+	}
+	return "sk-test", owner, nil
+}
+
+func newBudgetTestConfig() *config.Config {
+	return &config.Config{Budget: config.BudgetConfig{Enabled: true, MaxPerScope: 10, StateCacheSeconds: 60}}
+}
+
+func newBudgetTestBillingCache(repo BudgetRepository) *BillingCacheService {
+	s := &BillingCacheService{cfg: newBudgetTestConfig()}
+	s.SetBudgetRepository(repo)
+	return s
+}
+
+func TestBudgetPeriodWindow_CalendarAligned(t *testing.T) {
+	require.NoError(t, timezone.Init("Asia/Shanghai"))
+	t.Cleanup(func() { _ = timezone.Init("UTC") })
+	loc := timezone.Location()
+	now := time.Date(2026, 2, 18, 15, 30, 0, 0, loc) // Wednesday
+
+	start, end := BudgetPeriodWindow(BudgetPeriodDaily, now)
+	require.Equal(t, time.Date(2026, 2, 18, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2026, 2, 19, 0, 0, 0, 0, loc), end)
+
+	start, end = BudgetPeriodWindow(BudgetPeriodWeekly, now)
+	require.Equal(t, time.Date(2026, 2, 16, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2026, 2, 23, 0, 0, 0, 0, loc), end)
+
+	start, end = BudgetPeriodWindow(BudgetPeriodMonthly, now)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, loc), end)
+}
+
+func TestBudgetReachedPercent(t *testing.T) {
+	require.Equal(t, 0, budgetReachedPercent(4.99, 10))
+	require.Equal(t, 50, budgetReachedPercent(5, 10))
+	require.Equal(t, 80, budgetReachedPercent(9.99, 10))
+	require.Equal(t, 100, budgetReachedPercent(12, 10))
+	require.Equal(t, 0, budgetReachedPercent(12, 0))
+}
+
+func TestCheckBudgetEligibility_HardSoftAndModel(t *testing.T) {
+	repo := newBudgetRepoStub(
+		Budget{ID: 1, ScopeType: BudgetScopeAPIKey, ScopeID: 7, Period: BudgetPeriodMonthly, LimitUSD: 10, Enforcement: BudgetEnforcementSoft, Enabled: true},
+		Budget{ID: 2, ScopeType: BudgetScopeUser, ScopeID: 3, Model: "gpt-5", Period: BudgetPeriodDaily, LimitUSD: 5, Enforcement: BudgetEnforcementHard, Enabled: true},
+	)
+	repo.spend[1] = 50
+	repo.spend[2] = 5
+	s := newBudgetTestBillingCache(repo)
+	user := &User{ID: 3}
+	key := &APIKey{ID: 7, UserID: 3}
+
+	// soft 预算用尽不拒绝；按模型的预算只约束对应模型。
+	require.NoError(t, s.checkBudgetEligibility(context.WithValue(context.Background(), ctxkey.Model, "claude-sonnet-4"), user, key, nil))
+	require.NoError(t, s.checkBudgetEligibility(context.Background(), user, key, nil))
+
+	err := s.checkBudgetEligibility(context.WithValue(context.Background(), ctxkey.Model, "gpt-5"), user, key, nil)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	var appErr *infraerrors.ApplicationError
+	require.True(t, errors.As(err, &appErr))
+	require.NotEmpty(t, appErr.Metadata["window_resets_at"])
+	require.Equal(t, BudgetScopeUser, appErr.Metadata["budget_scope"])
+}
+
+func TestCheckBudgetEligibility_GroupBudgetAndFailOpen(t *testing.T) {
+	repo := newBudgetRepoStub(
+		Budget{ID: 1, ScopeType: BudgetScopeGroup, ScopeID: 9, Period: BudgetPeriodWeekly, LimitUSD: 100, Enforcement: BudgetEnforcementHard, Enabled: true},
+	)
+	repo.spend[1] = 100
+	s := newBudgetTestBillingCache(repo)
+	err := s.checkBudgetEligibility(context.Background(), &User{ID: 3}, &APIKey{ID: 7}, &Group{ID: 9})
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+
+	failing := newBudgetRepoStub(
+		Budget{ID: 1, ScopeType: BudgetScopeGroup, ScopeID: 9, Period: BudgetPeriodWeekly, LimitUSD: 100, Enforcement: BudgetEnforcementHard, Enabled: true},
+	)
+	failing.spendErr = errors.New("db down")
+	s = newBudgetTestBillingCache(failing)
+	require.NoError(t, s.checkBudgetEligibility(context.Background(), &User{ID: 3}, &APIKey{ID: 7}, &Group{ID: 9}))
+}
+
+func TestCheckBudgetEligibility_DisabledSkipsRepo(t *testing.T) {
+	repo := newBudgetRepoStub()
+	s := newBudgetTestBillingCache(repo)
+	s.cfg.Budget.Enabled = false
+	require.NoError(t, s.checkBudgetEligibility(context.Background(), &User{ID: 3}, &APIKey{ID: 7}, nil))
+	require.Zero(t, repo.spendCalls)
+}
+
+func TestRecordBudgetCharge_AccumulatesAndClaimsThresholdsOnce(t *testing.T) {
+	repo := newBudgetRepoStub(
+		Budget{ID: 1, ScopeType: BudgetScopeAPIKey, ScopeID: 7, Period: BudgetPeriodMonthly, LimitUSD: 10, Enforcement: BudgetEnforcementHard, Enabled: true},
+	)
+	repo.spend[1] = 4
+	s := newBudgetTestBillingCache(repo)
+	key := &APIKey{ID: 7, UserID: 3, Name: "dev"}
+
+	// 首次加载：数据库消费已包含本次用量，不重复累加，未达 50%。
+	require.Empty(t, s.RecordBudgetCharge(context.Background(), 3, key, "gpt-5", 1))
+
+	alerts := s.RecordBudgetCharge(context.Background(), 3, key, "gpt-5", 1)
+	require.Len(t, alerts, 1)
+	require.Equal(t, 50, alerts[0].Percent)
+	require.Equal(t, int64(3), alerts[0].UserID)
+	require.Equal(t, "dev", alerts[0].APIKeyName)
+	require.InDelta(t, 5.0, alerts[0].SpentUSD, 1e-9)
+
+	require.Empty(t, s.RecordBudgetCharge(context.Background(), 3, key, "gpt-5", 1))
+
+	alerts = s.RecordBudgetCharge(context.Background(), 3, key, "gpt-5", 5)
+	require.Len(t, alerts, 1)
+	require.Equal(t, 100, alerts[0].Percent)
+
+	// 缓存中的消费已用尽，下一次检查直接拒绝。
+	require.ErrorIs(t, s.checkBudgetEligibility(context.Background(), &User{ID: 3}, key, nil), ErrBudgetExceeded)
+}
+
+func TestRecordBudgetCharge_AnotherInstanceAlreadyClaimed(t *testing.T) {
+	repo := newBudgetRepoStub(
+		Budget{ID: 1, ScopeType: BudgetScopeUser, ScopeID: 3, Period: BudgetPeriodDaily, LimitUSD: 10, Enforcement: BudgetEnforcementSoft, Enabled: true},
+	)
+	repo.spend[1] = 8
+	repo.claimed[1] = 80
+	s := newBudgetTestBillingCache(repo)
+	require.Empty(t, s.RecordBudgetCharge(context.Background(), 3, &APIKey{ID: 7, UserID: 3}, "", 0.5))
+}
+
+func newBudgetTestService(repo *budgetRepoStub) *BudgetService {
+	cfg := newBudgetTestConfig()
+	keys := &budgetAPIKeyRepoStub{owners: map[int64]int64{7: 3, 8: 4}}
+	return NewBudgetService(repo, keys, nil, nil, newBudgetTestBillingCache(repo), cfg)
+}
+
+func TestBudgetService_CreateForUser_Ownership(t *testing.T) {
+	repo := newBudgetRepoStub()
+	svc := newBudgetTestService(repo)
+	ctx := context.Background()
+
+	b, err := svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeUser, Period: BudgetPeriodMonthly, LimitUSD: 20})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), b.ScopeID)
+	require.Equal(t, BudgetSourceUser, b.Source)
+	require.Equal(t, BudgetEnforcementHard, b.Enforcement)
+
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeAPIKey, ScopeID: 7, Model: "*", Period: BudgetPeriodDaily, LimitUSD: 1, Enforcement: BudgetEnforcementSoft})
+	require.NoError(t, err)
+	require.Equal(t, "", repo.created[1].Model)
+
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeAPIKey, ScopeID: 8, Period: BudgetPeriodDaily, LimitUSD: 1})
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeUser, ScopeID: 4, Period: BudgetPeriodDaily, LimitUSD: 1})
+	require.ErrorIs(t, err, ErrBudgetInvalidScope)
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeGroup, ScopeID: 1, Period: BudgetPeriodDaily, LimitUSD: 1})
+	require.ErrorIs(t, err, ErrBudgetInvalidScope)
+}
+
+func TestBudgetService_CreateForUser_Validation(t *testing.T) {
+	repo := newBudgetRepoStub()
+	svc := newBudgetTestService(repo)
+	ctx := context.Background()
+
+	_, err := svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeUser, Period: "yearly", LimitUSD: 1})
+	require.ErrorIs(t, err, ErrBudgetInvalidPeriod)
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeUser, Period: BudgetPeriodDaily, LimitUSD: 0})
+	require.ErrorIs(t, err, ErrBudgetInvalidLimit)
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeUser, Period: BudgetPeriodDaily, LimitUSD: 1, Enforcement: "strict"})
+	require.ErrorIs(t, err, ErrBudgetInvalidEnforcement)
+
+	repo.count = 10
+	_, err = svc.CreateForUser(ctx, 3, CreateBudgetInput{ScopeType: BudgetScopeUser, Period: BudgetPeriodDaily, LimitUSD: 1})
+	require.ErrorIs(t, err, ErrBudgetLimitReached)
+}
+
+func TestBudgetService_UserCannotChangeAdminBudget(t *testing.T) {
+	repo := newBudgetRepoStub(
+		Budget{ID: 1, ScopeType: BudgetScopeUser, ScopeID: 3, Period: BudgetPeriodMonthly, LimitUSD: 10, Enforcement: BudgetEnforcementHard, Enabled: true, Source: BudgetSourceAdmin},
+		Budget{ID: 2, ScopeType: BudgetScopeAPIKey, ScopeID: 7, Period: BudgetPeriodMonthly, LimitUSD: 10, Enforcement: BudgetEnforcementHard, Enabled: true, Source: BudgetSourceUser},
+		Budget{ID: 3, ScopeType: BudgetScopeAPIKey, ScopeID: 8, Period: BudgetPeriodMonthly, LimitUSD: 10, Enforcement: BudgetEnforcementHard, Enabled: true, Source: BudgetSourceUser},
+	)
+	svc := newBudgetTestService(repo)
+	ctx := context.Background()
+	limit := 30.0
+
+	_, err := svc.UpdateForUser(ctx, 3, 1, UpdateBudgetInput{LimitUSD: &limit})
+	require.ErrorIs(t, err, ErrBudgetForbidden)
+	require.ErrorIs(t, svc.DeleteForUser(ctx, 3, 1), ErrBudgetForbidden)
+
+	// 其他用户 Key 上的预算对当前用户不可见。
+	_, err = svc.UpdateForUser(ctx, 3, 3, UpdateBudgetInput{LimitUSD: &limit})
+	require.ErrorIs(t, err, ErrBudgetNotFound)
+
+	b, err := svc.UpdateForUser(ctx, 3, 2, UpdateBudgetInput{LimitUSD: &limit})
+	require.NoError(t, err)
+	require.Equal(t, 30.0, b.LimitUSD)
+	require.NoError(t, svc.DeleteForUser(ctx, 3, 2))
+
+	// 管理员可以修改任何预算。
+	b, err = svc.AdminUpdate(ctx, 1, UpdateBudgetInput{LimitUSD: &limit})
+	require.NoError(t, err)
+	require.Equal(t, 30.0, b.LimitUSD)
+}
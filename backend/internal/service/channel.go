@@ -15,6 +15,10 @@ const (
 	BillingModePerRequest BillingMode = "per_request" // 按次计费（支持上下文窗口分层）
 	BillingModeImage      BillingMode = "image"       // 图片计费（当前按次，预留 token 计费）
 	BillingModeVideo      BillingMode = "video"       // 视频生成计费（按视频生成次数）
+
+	// BillingModeResponseCache 仅出现在使用记录中：请求命中响应缓存，未转发上游。
+	// 它不是渠道定价模式，因此不参与 IsValid。
+	BillingModeResponseCache BillingMode = "response_cache"
 )
 
 // IsValid 检查 BillingMode 是否为合法值
@@ -29,7 +33,7 @@ func (m BillingMode) IsValid() bool {
 // IsValidUsageFilter 检查 BillingMode 是否可用于使用记录筛选。
 func (m BillingMode) IsValidUsageFilter() bool {
 	switch m {
-	case BillingModeToken, BillingModePerRequest, BillingModeImage, BillingModeVideo, "":
+	case BillingModeToken, BillingModePerRequest, BillingModeImage, BillingModeVideo, BillingModeResponseCache, "":
 		return true
 	}
 	return false
@@ -37,6 +37,9 @@ var monitorBlockedCIDRs = mustParseCIDRs([]string{
 	"::/128",         // IPv6 unspecified
 })
 
+// ssrfBlockedReason 是 safeDialContext 拒绝连接时 net.AddrError 的 Err 文本。
+const ssrfBlockedReason = "blocked by SSRF policy"
+
 // monitorDialer 共享 Dialer，与 net/http 默认值对齐。
 var monitorDialer = &net.Dialer{
 	Timeout:   monitorDialTimeout,
@@ -119,12 +122,12 @@ func safeDialContext(ctx context.Context, network, address string) (net.Conn, er
 	// 字面量 IP 走快速路径。
 	if ip := net.ParseIP(host); ip != nil {
 		if isPrivateIP(ip) {
-			return nil, &net.AddrError{Err: "blocked by SSRF policy", Addr: address}
+			return nil, &net.AddrError{Err: ssrfBlockedReason, Addr: address}
 		}
 		return monitorDialer.DialContext(ctx, network, address)
 	}
 	if isBlockedHostname(host) {
-		return nil, &net.AddrError{Err: "blocked by SSRF policy", Addr: address}
+		return nil, &net.AddrError{Err: ssrfBlockedReason, Addr: address}
 	}
 	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
 	if err != nil {
@@ -136,7 +139,7 @@ func safeDialContext(ctx context.Context, network, address string) (net.Conn, er
 	var lastErr error
 	for _, a := range addrs {
 		if isPrivateIP(a.IP) {
-			lastErr = &net.AddrError{Err: "blocked by SSRF policy", Addr: a.IP.String()}
+			lastErr = &net.AddrError{Err: ssrfBlockedReason, Addr: a.IP.String()}
 			continue
 		}
 		conn, err := monitorDialer.DialContext(ctx, network, net.JoinHostPort(a.IP.String(), port))
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ConfigReloadNotifier 跨实例广播配置重载信号
+type ConfigReloadNotifier interface {
+	// NotifyReload 通知其他实例重新加载配置文件，origin 为发起实例 ID
+	NotifyReload(ctx context.Context, origin string) error
+	// SubscribeReloads 订阅重载通知，handler 收到发起实例 ID
+	SubscribeReloads(ctx context.Context, handler func(origin string))
+}
+
+// 配置重载的触发来源
+const (
+	ConfigReloadSourceFileWatch = "file_watch"
+	ConfigReloadSourceSignal    = "sighup"
+	ConfigReloadSourceAdmin     = "admin"
+	ConfigReloadSourcePeer      = "peer"
+)
+
+// configReloadDebounce 合并编辑器保存、ConfigMap 原子替换产生的连续文件事件
+const configReloadDebounce = 500 * time.Millisecond
+
+// ConfigReloadStatus 最近一次配置重载的结果
+type ConfigReloadStatus struct {
+	Source     string               `json:"source"`
+	ReloadedAt time.Time            `json:"reloaded_at"`
+	Report     *config.ReloadReport `json:"report,omitempty"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// ConfigReloadService 在不重启进程的情况下重新加载 config.yaml。
+//
+// 触发方式：配置文件变更、SIGHUP、管理接口，以及其他实例通过 Redis 广播的重载信号。
+// 本地触发的重载成功后会广播给其他实例；来自其他实例的重载只在本地执行，不再转播。
+type ConfigReloadService struct {
+	cfg        *config.Config
+	notifier   ConfigReloadNotifier
+	instanceID string
+
+	reloadFn func(*config.Config) (*config.ReloadReport, error)
+	watchFn  func(onChange func()) bool
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	debounceMu sync.Mutex
+	debounce   *time.Timer
+
+	lastMu sync.RWMutex
+	last   *ConfigReloadStatus
+}
+
+// NewConfigReloadService 创建配置热重载服务
+func NewConfigReloadService(cfg *config.Config, notifier ConfigReloadNotifier) *ConfigReloadService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ConfigReloadService{
+		cfg:        cfg,
+		notifier:   notifier,
+		instanceID: uuid.NewString(),
+		reloadFn:   config.Reload,
+		watchFn:    config.WatchConfigFile,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start 开始监听配置文件变更与其他实例的重载广播
+func (s *ConfigReloadService) Start() {
+	if s == nil || s.cfg == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		if s.notifier != nil {
+			s.notifier.SubscribeReloads(s.ctx, s.handlePeerReload)
+		}
+		if s.watchFn != nil && s.watchFn(s.scheduleFileReload) {
+			logger.LegacyPrintf("service.config_reload", "[ConfigReload] Watching config file for changes")
+		}
+	})
+}
+
+// Stop 停止监听并取消尚未执行的文件重载
+func (s *ConfigReloadService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		s.cancel()
+		s.debounceMu.Lock()
+		if s.debounce != nil {
+			s.debounce.Stop()
+		}
+		s.debounceMu.Unlock()
+	})
+}
+
+// Reload 在本实例重新加载配置，成功后通知其他实例
+func (s *ConfigReloadService) Reload(ctx context.Context, source string) (*config.ReloadReport, error) {
+	report, err := s.reloadLocal(source)
+	if err != nil {
+		return nil, err
+	}
+	if s.notifier != nil {
+		if err := s.notifier.NotifyReload(ctx, s.instanceID); err != nil {
+			logger.LegacyPrintf("service.config_reload", "[ConfigReload] Failed to notify other instances: %v", err)
+		}
+	}
+	return report, nil
+}
+
+// LastStatus 返回最近一次重载的结果，尚未重载过时返回 nil
+func (s *ConfigReloadService) LastStatus() *ConfigReloadStatus {
+	if s == nil {
+		return nil
+	}
+	s.lastMu.RLock()
+	defer s.lastMu.RUnlock()
+	if s.last == nil {
+		return nil
+	}
+	status := *s.last
+	return &status
+}
+
+func (s *ConfigReloadService) scheduleFileReload() {
+	s.debounceMu.Lock()
+	defer s.debounceMu.Unlock()
+	if s.ctx.Err() != nil {
+		return
+	}
+	if s.debounce != nil {
+		s.debounce.Stop()
+	}
+	s.debounce = time.AfterFunc(configReloadDebounce, func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		_, _ = s.Reload(s.ctx, ConfigReloadSourceFileWatch)
+	})
+}
+
+func (s *ConfigReloadService) handlePeerReload(origin string) {
+	if origin == s.instanceID {
+		return
+	}
+	_, _ = s.reloadLocal(ConfigReloadSourcePeer)
+}
+
+func (s *ConfigReloadService) reloadLocal(source string) (*config.ReloadReport, error) {
+	report, err := s.reloadFn(s.cfg)
+	status := &ConfigReloadStatus{Source: source, ReloadedAt: time.Now(), Report: report}
+	if err != nil {
+		status.Error = err.Error()
+		logger.LegacyPrintf("service.config_reload", "[ConfigReload] Reload from %s failed, keeping current config: %v", source, err)
+	} else if report.Changed() {
+		logger.LegacyPrintf("service.config_reload", "[ConfigReload] Reloaded from %s: applied=%v", source, report.Applied)
+		if len(report.RestartRequired) > 0 {
+			logger.LegacyPrintf("service.config_reload", "[ConfigReload] Warning: changes require a restart to take effect: %v", report.RestartRequired)
+		}
+	} else {
+		logger.LegacyPrintf("service.config_reload", "[ConfigReload] Reloaded from %s: no changes", source)
+	}
+
+	s.lastMu.Lock()
+	s.last = status
+	s.lastMu.Unlock()
+	return report, err
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type configReloadNotifierStub struct {
+	mu       sync.Mutex
+	notified []string
+	handler  func(origin string)
+}
+
+func (n *configReloadNotifierStub) NotifyReload(_ context.Context, origin string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notified = append(n.notified, origin)
+	return nil
+}
+
+func (n *configReloadNotifierStub) SubscribeReloads(_ context.Context, handler func(origin string)) {
+	n.handler = handler
+}
+
+func (n *configReloadNotifierStub) notifications() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.notified...)
+}
+
+func newConfigReloadServiceForTest(notifier ConfigReloadNotifier, reloadErr error) (*ConfigReloadService, *atomic.Int32) {
+	var calls atomic.Int32
+	svc := NewConfigReloadService(&config.Config{}, notifier)
+	svc.reloadFn = func(cfg *config.Config) (*config.ReloadReport, error) {
+		calls.Add(1)
+		if reloadErr != nil {
+			return nil, reloadErr
+		}
+		return &config.ReloadReport{Applied: []string{"gateway.max_body_size"}, RestartRequired: []string{}}, nil
+	}
+	svc.watchFn = nil
+	return svc, &calls
+}
+
+func TestConfigReloadService_LocalReloadNotifiesPeers(t *testing.T) {
+	notifier := &configReloadNotifierStub{}
+	svc, calls := newConfigReloadServiceForTest(notifier, nil)
+
+	report, err := svc.Reload(context.Background(), ConfigReloadSourceSignal)
+	require.NoError(t, err)
+	require.Equal(t, []string{"gateway.max_body_size"}, report.Applied)
+	require.Equal(t, int32(1), calls.Load())
+	require.Equal(t, []string{svc.instanceID}, notifier.notifications())
+
+	status := svc.LastStatus()
+	require.NotNil(t, status)
+	require.Equal(t, ConfigReloadSourceSignal, status.Source)
+	require.Empty(t, status.Error)
+}
+
+func TestConfigReloadService_FailedReloadIsNotBroadcast(t *testing.T) {
+	notifier := &configReloadNotifierStub{}
+	svc, _ := newConfigReloadServiceForTest(notifier, errors.New("validate config error"))
+
+	_, err := svc.Reload(context.Background(), ConfigReloadSourceAdmin)
+	require.Error(t, err)
+	require.Empty(t, notifier.notifications())
+	require.Equal(t, "validate config error", svc.LastStatus().Error)
+}
+
+func TestConfigReloadService_PeerReloadIgnoresSelfAndDoesNotRebroadcast(t *testing.T) {
+	notifier := &configReloadNotifierStub{}
+	svc, calls := newConfigReloadServiceForTest(notifier, nil)
+	svc.Start()
+	defer svc.Stop()
+	require.NotNil(t, notifier.handler)
+
+	notifier.handler(svc.instanceID)
+	require.Equal(t, int32(0), calls.Load())
+
+	notifier.handler("other-instance")
+	require.Equal(t, int32(1), calls.Load())
+	require.Empty(t, notifier.notifications())
+	require.Equal(t, ConfigReloadSourcePeer, svc.LastStatus().Source)
+}
+
+func TestConfigReloadService_FileEventsAreDebounced(t *testing.T) {
+	svc, calls := newConfigReloadServiceForTest(nil, nil)
+	defer svc.Stop()
+
+	for i := 0; i < 5; i++ {
+		svc.scheduleFileReload()
+	}
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 20*time.Millisecond)
+	time.Sleep(configReloadDebounce + 100*time.Millisecond)
+	require.Equal(t, int32(1), calls.Load())
+	require.Equal(t, ConfigReloadSourceFileWatch, svc.LastStatus().Source)
+}
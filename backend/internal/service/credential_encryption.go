@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var (
+	ErrCredentialEncryptionDisabled = infraerrors.BadRequest("CREDENTIAL_ENCRYPTION_DISABLED", "credential encryption is not enabled")
+	ErrCredentialReencryptRunning   = infraerrors.Conflict("CREDENTIAL_REENCRYPT_IN_PROGRESS", "a credential re-encryption pass is already in progress")
+)
+
+// CredentialEncryptionRepository 账号凭证静态加密的数据密钥管理与重加密。
+//
+// 账号仓储在读写 accounts.credentials 时透明加解密敏感子键（SensitiveCredentialKeys），
+// service 层拿到的始终是明文；此接口只负责密钥轮换与把存量数据改写到活动密钥。
+type CredentialEncryptionRepository interface {
+	// Enabled 报告新写入的凭证是否加密
+	Enabled() bool
+	// RefreshKeys 从数据库重新加载数据密钥，感知其他实例完成的轮换
+	RefreshKeys(ctx context.Context) error
+	// RotateDataKey 停用当前数据密钥并生成新的活动数据密钥，返回新密钥 ID
+	RotateDataKey(ctx context.Context) (string, error)
+	// ListKeys 列出全部数据密钥（不含密钥本身）
+	ListKeys(ctx context.Context) ([]CredentialEncryptionKey, error)
+	// CountPending 统计凭证尚未处于目标形态的账号数：启用时为"全部由活动密钥加密"，关闭时为明文
+	CountPending(ctx context.Context) (int64, error)
+	// ReencryptBatch 把 id > afterID 的至多 limit 个账号（含已软删除）改写为目标形态
+	ReencryptBatch(ctx context.Context, afterID int64, limit int) (*CredentialReencryptBatch, error)
+}
+
+// CredentialEncryptionKey 数据密钥元信息
+type CredentialEncryptionKey struct {
+	ID          string `json:"id"`
+	MasterKeyID string `json:"master_key_id"`
+	Active      bool   `json:"active"`
+	// Available 为 false 表示包裹它的主密钥未配置，由它加密的值当前无法解密
+	Available bool       `json:"available"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+}
+
+// CredentialReencryptBatch 一批重加密的结果
+type CredentialReencryptBatch struct {
+	// LastID 本批扫描到的最大账号 ID，为 0 表示已扫描完毕
+	LastID    int64
+	Scanned   int
+	Rewritten int
+	// Failed 无法解密（数据密钥或主密钥缺失）而跳过的账号数
+	Failed int
+}
+
+// CredentialReencryptRun 一次完整重加密扫描的结果
+type CredentialReencryptRun struct {
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Scanned    int        `json:"scanned"`
+	Rewritten  int        `json:"rewritten"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// CredentialEncryptionStatus 后台展示的凭证加密状态
+type CredentialEncryptionStatus struct {
+	Enabled     bool                      `json:"enabled"`
+	ActiveKeyID string                    `json:"active_key_id"`
+	Keys        []CredentialEncryptionKey `json:"keys"`
+	// PendingAccounts 尚未改写到目标形态的账号数
+	PendingAccounts int64                   `json:"pending_accounts"`
+	Running         bool                    `json:"running"`
+	LastRun         *CredentialReencryptRun `json:"last_run,omitempty"`
+}
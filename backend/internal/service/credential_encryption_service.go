@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/google/uuid"
+)
+
+const (
+	credentialReencryptLeaderLockKey = "credential_reencrypt:leader"
+	credentialReencryptLeaderLockTTL = 30 * time.Minute
+	// credentialKeyRefreshInterval 其他实例轮换数据密钥后，本实例最迟在此间隔内改用新密钥写入
+	credentialKeyRefreshInterval = time.Minute
+	// credentialReencryptInterval 周期性检查是否有未改写到目标形态的账号
+	credentialReencryptInterval    = 10 * time.Minute
+	credentialReencryptPassTimeout = 20 * time.Minute
+)
+
+// CredentialEncryptionService 在线重加密任务：把账号凭证改写为当前目标形态。
+//
+// 目标形态：启用加密时所有敏感子键由活动数据密钥加密；关闭时为明文。
+// 启动时执行一次（开启加密后的首次启动即完成存量明文数据的加密），之后周期检查，
+// 数据密钥轮换后立即触发。多实例部署时通过选主锁保证只有一个实例在改写。
+type CredentialEncryptionService struct {
+	repo      CredentialEncryptionRepository
+	batchSize int
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	running atomic.Bool
+	lastMu  sync.RWMutex
+	lastRun *CredentialReencryptRun
+
+	trigger   chan struct{}
+	bgCtx     context.Context
+	bgCancel  context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewCredentialEncryptionService 创建凭证重加密服务
+func NewCredentialEncryptionService(repo CredentialEncryptionRepository, cfg *config.Config) *CredentialEncryptionService {
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	s := &CredentialEncryptionService{
+		repo:       repo,
+		batchSize:  200,
+		instanceID: uuid.NewString(),
+		trigger:    make(chan struct{}, 1),
+		bgCtx:      bgCtx,
+		bgCancel:   bgCancel,
+	}
+	if cfg != nil && cfg.Security.CredentialEncryption.ReencryptBatchSize > 0 {
+		s.batchSize = cfg.Security.CredentialEncryption.ReencryptBatchSize
+	}
+	return s
+}
+
+// SetLeaderLock 注入选主用的锁，多实例部署时只有一个实例执行重加密。
+func (s *CredentialEncryptionService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Start 启动后台循环：定期刷新数据密钥，并在启动、轮换和周期检查时执行重加密。
+func (s *CredentialEncryptionService) Start() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.loop()
+		s.requestRun()
+	})
+}
+
+// Stop 停止后台循环并等待进行中的批次结束；未完成的账号在下次启动时继续处理。
+func (s *CredentialEncryptionService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		s.bgCancel()
+		s.wg.Wait()
+	})
+}
+
+// Status 返回数据密钥列表、待处理账号数与最近一次重加密结果。
+func (s *CredentialEncryptionService) Status(ctx context.Context) (*CredentialEncryptionStatus, error) {
+	keys, err := s.repo.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.CountPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &CredentialEncryptionStatus{
+		Enabled:         s.repo.Enabled(),
+		Keys:            keys,
+		PendingAccounts: pending,
+		Running:         s.running.Load(),
+		LastRun:         s.LastRun(),
+	}
+	for _, key := range keys {
+		if key.Active {
+			status.ActiveKeyID = key.ID
+		}
+	}
+	return status, nil
+}
+
+// RotateDataKey 生成新的活动数据密钥，并在后台把存量凭证改写到新密钥。
+func (s *CredentialEncryptionService) RotateDataKey(ctx context.Context) (string, error) {
+	if !s.repo.Enabled() {
+		return "", ErrCredentialEncryptionDisabled
+	}
+	keyID, err := s.repo.RotateDataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	slog.Info("[CredentialEncryption] data key rotated", "key_id", keyID)
+	s.requestRun()
+	return keyID, nil
+}
+
+// TriggerRun 后台手动触发一次重加密扫描（异步执行）。
+func (s *CredentialEncryptionService) TriggerRun() error {
+	if s.running.Load() {
+		return ErrCredentialReencryptRunning
+	}
+	s.requestRun()
+	return nil
+}
+
+// LastRun 返回最近一次重加密扫描的结果，尚未执行过时返回 nil。
+func (s *CredentialEncryptionService) LastRun() *CredentialReencryptRun {
+	if s == nil {
+		return nil
+	}
+	s.lastMu.RLock()
+	defer s.lastMu.RUnlock()
+	if s.lastRun == nil {
+		return nil
+	}
+	run := *s.lastRun
+	return &run
+}
+
+func (s *CredentialEncryptionService) requestRun() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *CredentialEncryptionService) loop() {
+	defer s.wg.Done()
+	refreshTicker := time.NewTicker(credentialKeyRefreshInterval)
+	defer refreshTicker.Stop()
+	runTicker := time.NewTicker(credentialReencryptInterval)
+	defer runTicker.Stop()
+
+	for {
+		select {
+		case <-s.bgCtx.Done():
+			return
+		case <-refreshTicker.C:
+			if err := s.repo.RefreshKeys(s.bgCtx); err != nil {
+				slog.Warn("[CredentialEncryption] refresh data keys failed", "error", err)
+			}
+		case <-runTicker.C:
+			s.runWithLeaderLock()
+		case <-s.trigger:
+			s.runWithLeaderLock()
+		}
+	}
+}
+
+func (s *CredentialEncryptionService) runWithLeaderLock() {
+	ctx, cancel := context.WithTimeout(s.bgCtx, credentialReencryptPassTimeout)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, credentialReencryptLeaderLockKey, s.instanceID, credentialReencryptLeaderLockTTL)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// 轮换可能发生在其他实例上，改写前先同步数据密钥
+	if err := s.repo.RefreshKeys(ctx); err != nil {
+		slog.Warn("[CredentialEncryption] refresh data keys failed", "error", err)
+		return
+	}
+	pending, err := s.repo.CountPending(ctx)
+	if err != nil {
+		slog.Warn("[CredentialEncryption] count pending accounts failed", "error", err)
+		return
+	}
+	if pending == 0 {
+		return
+	}
+	run, err := s.Run(ctx)
+	if err != nil {
+		slog.Error("[CredentialEncryption] re-encryption failed", "error", err)
+		return
+	}
+	slog.Info("[CredentialEncryption] re-encryption finished",
+		"scanned", run.Scanned, "rewritten", run.Rewritten, "failed", run.Failed)
+}
+
+// Run 按账号 ID 顺序分批扫描全部账号，把凭证改写为目标形态。
+func (s *CredentialEncryptionService) Run(ctx context.Context) (*CredentialReencryptRun, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, ErrCredentialReencryptRunning
+	}
+	defer s.running.Store(false)
+
+	run := &CredentialReencryptRun{StartedAt: time.Now()}
+	var afterID int64
+	var runErr error
+	for {
+		if err := ctx.Err(); err != nil {
+			runErr = err
+			break
+		}
+		batch, err := s.repo.ReencryptBatch(ctx, afterID, s.batchSize)
+		if err != nil {
+			runErr = err
+			break
+		}
+		run.Scanned += batch.Scanned
+		run.Rewritten += batch.Rewritten
+		run.Failed += batch.Failed
+		if batch.LastID == 0 || batch.Scanned < s.batchSize {
+			break
+		}
+		afterID = batch.LastID
+	}
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	if run.Failed > 0 {
+		slog.Warn("[CredentialEncryption] some accounts could not be decrypted; configure the master key that wraps their data key",
+			"failed", run.Failed)
+	}
+
+	s.lastMu.Lock()
+	s.lastRun = run
+	s.lastMu.Unlock()
+	return run, runErr
+}
@@ -0,0 +1,105 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type credentialEncryptionRepoStub struct {
+	enabled  bool
+	keys     []CredentialEncryptionKey
+	pending  int64
+	batches  []*CredentialReencryptBatch
+	afterIDs []int64
+	rotated  int
+}
+
+func (r *credentialEncryptionRepoStub) Enabled() bool { return r.enabled }
+
+func (r *credentialEncryptionRepoStub) RefreshKeys(context.Context) error { return nil }
+
+func (r *credentialEncryptionRepoStub) RotateDataKey(context.Context) (string, error) {
+	r.rotated++
+	return "k2", nil
+}
+
+func (r *credentialEncryptionRepoStub) ListKeys(context.Context) ([]CredentialEncryptionKey, error) {
+	return r.keys, nil
+}
+
+func (r *credentialEncryptionRepoStub) CountPending(context.Context) (int64, error) {
+	return r.pending, nil
+}
+
+func (r *credentialEncryptionRepoStub) ReencryptBatch(_ context.Context, afterID int64, _ int) (*CredentialReencryptBatch, error) {
+	r.afterIDs = append(r.afterIDs, afterID)
+	if len(r.batches) == 0 {
+		return &CredentialReencryptBatch{}, nil
+	}
+	batch := r.batches[0]
+	r.batches = r.batches[1:]
+	return batch, nil
+}
+
+func newCredentialEncryptionServiceForTest(repo CredentialEncryptionRepository, batchSize int) *CredentialEncryptionService {
+	cfg := &config.Config{}
+	cfg.Security.CredentialEncryption.ReencryptBatchSize = batchSize
+	return NewCredentialEncryptionService(repo, cfg)
+}
+
+func TestCredentialEncryptionService_RunWalksAllBatches(t *testing.T) {
+	repo := &credentialEncryptionRepoStub{enabled: true, batches: []*CredentialReencryptBatch{
+		{LastID: 10, Scanned: 2, Rewritten: 2},
+		{LastID: 25, Scanned: 2, Rewritten: 1, Failed: 1},
+		{LastID: 30, Scanned: 1},
+	}}
+	svc := newCredentialEncryptionServiceForTest(repo, 2)
+
+	run, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int64{0, 10, 25}, repo.afterIDs)
+	require.Equal(t, 5, run.Scanned)
+	require.Equal(t, 3, run.Rewritten)
+	require.Equal(t, 1, run.Failed)
+	require.NotNil(t, run.FinishedAt)
+	require.Equal(t, run, svc.LastRun())
+}
+
+func TestCredentialEncryptionService_RotateRequiresEnabled(t *testing.T) {
+	repo := &credentialEncryptionRepoStub{}
+	svc := newCredentialEncryptionServiceForTest(repo, 10)
+
+	_, err := svc.RotateDataKey(context.Background())
+	require.ErrorIs(t, err, ErrCredentialEncryptionDisabled)
+	require.Zero(t, repo.rotated)
+
+	repo.enabled = true
+	keyID, err := svc.RotateDataKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "k2", keyID)
+	require.Len(t, svc.trigger, 1, "rotation schedules a re-encryption pass")
+}
+
+func TestCredentialEncryptionService_StatusReportsActiveKey(t *testing.T) {
+	repo := &credentialEncryptionRepoStub{
+		enabled: true,
+		pending: 4,
+		keys: []CredentialEncryptionKey{
+			{ID: "k2", Active: true, Available: true},
+			{ID: "k1", Available: true},
+		},
+	}
+	svc := newCredentialEncryptionServiceForTest(repo, 10)
+
+	status, err := svc.Status(context.Background())
+	require.NoError(t, err)
+	require.True(t, status.Enabled)
+	require.Equal(t, "k2", status.ActiveKeyID)
+	require.Equal(t, int64(4), status.PendingAccounts)
+	require.Nil(t, status.LastRun)
+}
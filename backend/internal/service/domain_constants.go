@@ -189,6 +189,9 @@ const WeChatConnectSyntheticEmailDomain = "@wechat-connect.invalid"
 // DingTalkConnectSyntheticEmailDomain 是 DingTalk Connect 用户的合成邮箱后缀（RFC 保留域名）。
 const DingTalkConnectSyntheticEmailDomain = "@dingtalk-connect.invalid"
 
+// SAMLSyntheticEmailDomain 是 SAML SSO 用户的合成邮箱后缀（RFC 保留域名）。
+const SAMLSyntheticEmailDomain = "@saml-sso.invalid"
+
 // Setting keys
 const (
 	// 注册设置
@@ -292,6 +295,10 @@ const (
 	SettingKeyDingTalkConnectSyncDisplayNameAttrName = "dingtalk_connect_sync_display_name_attr_name"
 	SettingKeyDingTalkConnectSyncDeptAttrName        = "dingtalk_connect_sync_dept_attr_name"
 
+	// SAML SSO：管理后台导入的 IdP 元数据（优先于配置文件）
+	SettingKeySAMLIdPMetadataXML        = "saml_idp_metadata_xml"
+	SettingKeySAMLIdPMetadataImportedAt = "saml_idp_metadata_imported_at"
+
 	// WeChat Connect OAuth 登录设置
 	SettingKeyWeChatConnectEnabled             = "wechat_connect_enabled"
 	SettingKeyWeChatConnectAppID               = "wechat_connect_app_id"
@@ -249,8 +249,8 @@ func (s *GatewayService) executeBedrockUpstream(
 					Kind:               "retry",
 					Message:            extractUpstreamErrorMessage(respBody),
 					Detail: func() string {
-						if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-							return truncateString(string(respBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+						if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+							return truncateString(string(respBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 						}
 						return ""
 					}(),
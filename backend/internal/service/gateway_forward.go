@@ -109,6 +109,10 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	if account != nil && s.shouldEmulateWebSearch(ctx, account, parsed.GroupID, parsed.Body.Bytes()) {
 		return s.handleWebSearchEmulation(ctx, c, account, parsed)
 	}
+	// Web Fetch 模拟：纯 web_fetch 请求时，由网关自行抓取页面构造响应
+	if account != nil && s.shouldEmulateWebFetch(ctx, account, parsed.GroupID, parsed.Body.Bytes()) {
+		return s.handleWebFetchEmulation(ctx, c, account, parsed)
+	}
 
 	if account != nil && account.IsAnthropicAPIKeyPassthroughEnabled() {
 		passthroughBody := parsed.Body.Bytes()
@@ -435,8 +439,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 						Kind:               "signature_error",
 						Message:            extractUpstreamErrorMessage(respBody),
 						Detail: func() string {
-							if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-								return truncateString(string(respBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+							if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+								return truncateString(string(respBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 							}
 							return ""
 						}(),
@@ -496,8 +500,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 									Kind:               "signature_retry_thinking",
 									Message:            extractUpstreamErrorMessage(retryRespBody),
 									Detail: func() string {
-										if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-											return truncateString(string(retryRespBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+										if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+											return truncateString(string(retryRespBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 										}
 										return ""
 									}(),
@@ -575,8 +579,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 						Kind:               "budget_constraint_error",
 						Message:            errMsg,
 						Detail: func() string {
-							if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-								return truncateString(string(respBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+							if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+								return truncateString(string(respBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 							}
 							return ""
 						}(),
@@ -645,8 +649,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 					Kind:               "retry",
 					Message:            extractUpstreamErrorMessage(respBody),
 					Detail: func() string {
-						if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-							return truncateString(string(respBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+						if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+							return truncateString(string(respBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 						}
 						return ""
 					}(),
@@ -698,8 +702,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 				Kind:               "retry_exhausted_failover",
 				Message:            extractUpstreamErrorMessage(respBody),
 				Detail: func() string {
-					if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-						return truncateString(string(respBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+					if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+						return truncateString(string(respBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 					}
 					return ""
 				}(),
@@ -732,8 +736,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 			Kind:               "failover",
 			Message:            extractUpstreamErrorMessage(respBody),
 			Detail: func() string {
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					return truncateString(string(respBody), s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					return truncateString(string(respBody), s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 				}
 				return ""
 			}(),
@@ -746,7 +750,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	}
 	if resp.StatusCode >= 400 {
 		// 可选：对部分 400 触发 failover（默认关闭以保持语义）
-		if resp.StatusCode == 400 && s.cfg != nil && s.cfg.Gateway.FailoverOn400 {
+		if resp.StatusCode == 400 && s.cfg != nil && s.cfg.Live().Gateway.FailoverOn400 {
 			respBody, readErr := s.readUpstreamErrorBody(resp)
 			if readErr != nil {
 				// ReadAll failed, fall back to normal error handling without consuming the stream
@@ -759,8 +763,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -777,11 +781,11 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 					Detail:             upstreamDetail,
 				})
 
-				if s.cfg.Gateway.LogUpstreamErrorBody {
+				if s.cfg.Live().Gateway.LogUpstreamErrorBody {
 					logger.LegacyPrintf("service.gateway",
 						"Account %d: 400 error, attempting failover: %s",
 						account.ID,
-						truncateForLog(respBody, s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes),
+						truncateForLog(respBody, s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes),
 					)
 				} else {
 					logger.LegacyPrintf("service.gateway", "Account %d: 400 error, attempting failover", account.ID)
@@ -834,8 +838,8 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 				)
 
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -236,8 +236,8 @@ func (s *GatewayService) handleCCBufferedFromAnthropic(
 
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
-	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
-		maxLineSize = s.cfg.Gateway.MaxLineSize
+	if s.cfg != nil && s.cfg.Live().Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Live().Gateway.MaxLineSize
 	}
 	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 
@@ -390,8 +390,8 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
-	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
-		maxLineSize = s.cfg.Gateway.MaxLineSize
+	if s.cfg != nil && s.cfg.Live().Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Live().Gateway.MaxLineSize
 	}
 	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 
@@ -103,6 +103,18 @@ func (s *GatewayService) ForwardAsResponses(
 		return nil, fmt.Errorf("marshal anthropic request: %w", err)
 	}
 
+	// Web Fetch 模拟：转换后仅剩 web_fetch 工具时由网关自行抓取，不请求上游
+	var groupID *int64
+	if parsed != nil {
+		groupID = parsed.GroupID
+	}
+	if s.shouldEmulateWebFetch(ctx, account, groupID, anthropicBody) {
+		if parsed != nil && parsed.OnUpstreamAccepted != nil {
+			parsed.OnUpstreamAccepted()
+		}
+		return s.handleResponsesWebFetchEmulation(ctx, c, account, anthropicBody, originalModel, clientStream)
+	}
+
 	// 6. Apply Claude Code mimicry for OAuth accounts (non-Claude-Code endpoints).
 	// OpenAI Responses 协议进来的请求永远不是 Claude Code 客户端，所以对 OAuth 账号
 	// 必须完整执行 /v1/messages 主路径上的伪装链路（system 重写 + normalize + metadata 注入），
@@ -326,8 +338,8 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
-	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
-		maxLineSize = s.cfg.Gateway.MaxLineSize
+	if s.cfg != nil && s.cfg.Live().Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Live().Gateway.MaxLineSize
 	}
 	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 
@@ -487,8 +499,8 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
-	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
-		maxLineSize = s.cfg.Gateway.MaxLineSize
+	if s.cfg != nil && s.cfg.Live().Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Live().Gateway.MaxLineSize
 	}
 	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 
@@ -465,6 +465,14 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 					}
 				})
 				shuffleWithinSortGroups(routingAvailable)
+				// 分组配置了非默认调度策略时，由策略决定路由账号的尝试顺序
+				if scheduler := s.accountSchedulers.ForGroup(group); !IsDefaultAccountScheduler(scheduler) {
+					routingAvailable = accountsWithLoadFromCandidates(scheduler.Order(AccountScheduleRequest{
+						GroupID:  groupID,
+						Platform: platform,
+						Model:    requestedModel,
+					}, accountScheduleCandidatesFromLoads(routingAvailable)))
+				}
 
 				// 4. 尝试获取槽位
 				for _, item := range routingAvailable {
@@ -708,44 +716,27 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 		}
 
-		// 分层过滤选择：优先级 →（可选）最早重置 → 负载率 → LRU
-		for len(available) > 0 {
-			// 1. 取优先级最小的集合
-			candidates := filterByMinPriority(available)
-			// 2. （可选）use-it-or-lose-it：优先选用会话窗口最早重置的账号
-			if cfg.PreferSoonestReset {
-				candidates = filterBySoonestReset(candidates)
-			}
-			// 3. 取负载率最低的集合
-			candidates = filterByMinLoadRate(candidates)
-			// 4. LRU 选择最久未用的账号
-			selected := selectByLRU(candidates, preferOAuth)
-			if selected == nil {
-				break
-			}
-
-			result, err := s.tryAcquireAccountSlot(ctx, selected.account.ID, selected.account.Concurrency)
+		// 按分组调度策略排序后依次尝试；默认策略为 优先级 →（可选）最早重置 → 负载率 → LRU
+		ordered := s.accountSchedulers.ForGroup(group).Order(AccountScheduleRequest{
+			GroupID:            groupID,
+			Platform:           platform,
+			Model:              requestedModel,
+			PreferOAuth:        preferOAuth,
+			PreferSoonestReset: cfg.PreferSoonestReset,
+		}, accountScheduleCandidatesFromLoads(available))
+		for _, item := range ordered {
+			result, err := s.tryAcquireAccountSlot(ctx, item.Account.ID, item.Account.Concurrency)
 			if err == nil && result.Acquired {
 				// 会话数量限制检查
-				if !s.checkAndRegisterSession(ctx, selected.account, sessionHash) {
+				if !s.checkAndRegisterSession(ctx, item.Account, sessionHash) {
 					result.ReleaseFunc() // 释放槽位，继续尝试下一个账号
-				} else {
-					if sessionHash != "" && s.cache != nil {
-						_ = s.bindGatewayStickySessionDuringSelection(ctx, groupID, sessionHash, selected.account.ID)
-					}
-					return s.newSelectionResult(ctx, selected.account, true, result.ReleaseFunc, nil)
+					continue
 				}
-			}
-
-			// 移除已尝试的账号，重新进行分层过滤
-			selectedID := selected.account.ID
-			newAvailable := make([]accountWithLoad, 0, len(available)-1)
-			for _, acc := range available {
-				if acc.account.ID != selectedID {
-					newAvailable = append(newAvailable, acc)
+				if sessionHash != "" && s.cache != nil {
+					_ = s.bindGatewayStickySessionDuringSelection(ctx, groupID, sessionHash, item.Account.ID)
 				}
+				return s.newSelectionResult(ctx, item.Account, true, result.ReleaseFunc, nil)
 			}
-			available = newAvailable
 		}
 	}
 
@@ -794,7 +785,7 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 
 func (s *GatewayService) schedulingConfig() config.GatewaySchedulingConfig {
 	if s.cfg != nil {
-		return s.cfg.Gateway.Scheduling
+		return s.cfg.Live().Gateway.Scheduling
 	}
 	return config.GatewaySchedulingConfig{
 		StickySessionMaxWaiting:  3,
@@ -1554,10 +1545,14 @@ func filterByMinLoadRate(accounts []accountWithLoad) []accountWithLoad {
 // 窗口为空或已过期的账号视为无活跃窗口、优先级最低。
 // 当所有账号都没有活跃窗口时，返回原集合（不改变后续 LRU 选择）。
 func filterBySoonestReset(accounts []accountWithLoad) []accountWithLoad {
+	return filterBySoonestResetAt(accounts, time.Now())
+}
+
+// filterBySoonestResetAt 同 filterBySoonestReset，以 now 作为当前时间。
+func filterBySoonestResetAt(accounts []accountWithLoad, now time.Time) []accountWithLoad {
 	if len(accounts) <= 1 {
 		return accounts
 	}
-	now := time.Now()
 	var minEnd *time.Time
 	for _, acc := range accounts {
 		end := acc.account.SessionWindowEnd
@@ -2581,3 +2576,16 @@ func (s *GatewayService) isModelSupportedByAccount(account *Account, requestedMo
 	// 其他平台使用账户的模型支持检查
 	return account.IsModelSupported(requestedModel)
 }
+
+// SetAccountSchedulers 注入进程内共享的账号调度策略集合，须在处理请求前调用。
+func (s *GatewayService) SetAccountSchedulers(schedulers *AccountSchedulers) {
+	s.accountSchedulers = schedulers
+}
+
+// ReportAccountScheduleResult 上报一次转发结果（成功与否、首 token 延迟），供分组调度策略使用。
+func (s *GatewayService) ReportAccountScheduleResult(account *Account, success bool, firstTokenMs *int) {
+	if s == nil || account == nil {
+		return
+	}
+	s.accountSchedulers.ReportResult(account.ID, success, firstTokenMs)
+}
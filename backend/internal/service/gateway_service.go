@@ -632,7 +632,9 @@ type ForwardResult struct {
 	ImageSizeSource    string
 	ImageSizeBreakdown map[string]int
 	SearchCount        int
-	AudioUsage         *AudioUsage
+	// WebFetchCount 网关模拟 web_fetch 成功抓取的页面数，按次叠加计费
+	WebFetchCount int
+	AudioUsage    *AudioUsage
 }
 
 // GatewayFailureStage identifies which request stage failed. The zero value is
@@ -783,6 +785,7 @@ type GatewayService struct {
 	tlsFPProfileService   *TLSFingerprintProfileService
 	balanceNotifyService  *BalanceNotifyService
 	userPlatformQuotaRepo UserPlatformQuotaRepository
+	accountSchedulers     *AccountSchedulers
 }
 
 // NewGatewayService creates a new GatewayService
@@ -74,11 +74,11 @@ func TestBuildUsageBillingCommand_SubscriptionAppliesRateMultiplier(t *testing.T
 			if cmd == nil {
 				t.Fatal("buildUsageBillingCommand returned nil")
 			}
-			if cmd.SubscriptionCost != tt.wantSub {
-				t.Errorf("SubscriptionCost = %v, want %v", cmd.SubscriptionCost, tt.wantSub)
+			if got := cmd.SubscriptionCost.InexactFloat64(); got != tt.wantSub {
+				t.Errorf("SubscriptionCost = %v, want %v", got, tt.wantSub)
 			}
-			if cmd.BalanceCost != tt.wantBalance {
-				t.Errorf("BalanceCost = %v, want %v", cmd.BalanceCost, tt.wantBalance)
+			if got := cmd.BalanceCost.InexactFloat64(); got != tt.wantBalance {
+				t.Errorf("BalanceCost = %v, want %v", got, tt.wantBalance)
 			}
 		})
 	}
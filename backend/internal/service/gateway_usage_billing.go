@@ -9,6 +9,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
 )
 
@@ -82,6 +83,8 @@ type postUsageBillingParams struct {
 	AccountRateMultiplier float64
 	APIKeyService         APIKeyQuotaUpdater
 	Platform              string // 来自 APIKey 关联 Group 的平台标识
+	BalanceHeld           bool   // Batch API 请求：余额已在创建批次时冻结，不直接扣余额，由批次结算 capture
+	Model                 string // 客户端请求的模型名，按模型的预算据此归集
 }
 
 // PlatformFromAPIKey 从 APIKey 关联的 Group 推导 platform 名称。
@@ -117,6 +120,11 @@ func QuotaPlatform(ctx context.Context, apiKey *APIKey) string {
 	return platform
 }
 
+// chargesOrganization 组织 Key 的余额计费扣组织钱包，不动用户个人余额。
+func (p *postUsageBillingParams) chargesOrganization() bool {
+	return !p.IsSubscriptionBill && p.APIKey.ChargesOrganization()
+}
+
 func (p *postUsageBillingParams) shouldDeductAPIKeyQuota() bool {
 	return p.Cost.ActualCost > 0 && p.APIKey.Quota > 0 && p.APIKeyService != nil
 }
@@ -146,7 +154,12 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 				slog.Error("increment subscription usage failed", "subscription_id", p.Subscription.ID, "error", err)
 			}
 		}
-	} else {
+	} else if !p.BalanceHeld && p.chargesOrganization() {
+		// 组织钱包扣费只在统一计费仓储的事务内实现；降级路径不能改扣用户个人余额。
+		if cost.ActualCost > 0 {
+			slog.Error("organization wallet deduction skipped in legacy billing path", "organization_id", *p.APIKey.OrganizationID, "api_key_id", p.APIKey.ID, "cost", cost.ActualCost)
+		}
+	} else if !p.BalanceHeld {
 		if cost.ActualCost > 0 {
 			if err := deps.userRepo.DeductBalance(billingCtx, p.User.ID, cost.ActualCost); err != nil {
 				slog.Error("deduct balance failed", "user_id", p.User.ID, "error", err)
@@ -171,7 +184,7 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 	}
 
 	if p.shouldUpdateAccountQuota() {
-		accountCost := cost.TotalCost * p.AccountRateMultiplier
+		accountCost := money.Mul(cost.TotalCost, p.AccountRateMultiplier, money.LedgerScale)
 		if err := deps.accountRepo.IncrementQuotaUsed(billingCtx, p.Account.ID, accountCost); err != nil {
 			slog.Error("increment account quota used failed", "account_id", p.Account.ID, "cost", accountCost, "error", err)
 		}
@@ -310,21 +323,28 @@ func buildUsageBillingCommand(requestID string, usageLog *UsageLog, p *postUsage
 	// user-specific) rate multiplier consumes subscription quota at the expected
 	// speed. TotalCost remains the raw (pre-multiplier) value; downstream guards
 	// on "> 0" still correctly skip free subscriptions (RateMultiplier == 0).
+	// From here on the amounts are carried as decimals so the repository binds
+	// exact NUMERIC parameters instead of float64.
+	actualCost := money.Decimal(p.Cost.ActualCost)
 	if p.IsSubscriptionBill && p.Subscription != nil && p.Cost.TotalCost > 0 {
 		cmd.SubscriptionID = &p.Subscription.ID
-		cmd.SubscriptionCost = p.Cost.ActualCost
-	} else if p.Cost.ActualCost > 0 {
-		cmd.BalanceCost = p.Cost.ActualCost
+		cmd.SubscriptionCost = actualCost
+	} else if actualCost.IsPositive() && !p.BalanceHeld {
+		cmd.BalanceCost = actualCost
+		if p.chargesOrganization() {
+			orgID := *p.APIKey.OrganizationID
+			cmd.OrganizationID = &orgID
+		}
 	}
 
 	if p.shouldDeductAPIKeyQuota() {
-		cmd.APIKeyQuotaCost = p.Cost.ActualCost
+		cmd.APIKeyQuotaCost = actualCost
 	}
 	if p.shouldUpdateRateLimits() {
-		cmd.APIKeyRateLimitCost = p.Cost.ActualCost
+		cmd.APIKeyRateLimitCost = actualCost
 	}
 	if p.shouldUpdateAccountQuota() {
-		cmd.AccountQuotaCost = p.Cost.TotalCost * p.AccountRateMultiplier
+		cmd.AccountQuotaCost = money.Decimal(p.Cost.TotalCost).Mul(money.Decimal(p.AccountRateMultiplier)).Round(money.LedgerScale)
 	}
 
 	cmd.Normalize()
@@ -336,12 +356,31 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 		return false, nil
 	}
 
+	batchExec := OpenAIBatchExecutionFromContext(ctx)
+	batchExec.applyPricing(usageLog, p)
+
+	if usageLog != nil && p.Model == "" {
+		p.Model = usageLog.RequestedModel
+		if p.Model == "" {
+			p.Model = usageLog.Model
+		}
+	}
+
 	cmd := buildUsageBillingCommand(requestID, usageLog, p)
 	if cmd == nil || cmd.RequestID == "" || repo == nil {
-		postUsageBilling(ctx, p, deps)
+		postUsageBilling(WithBalanceLedgerSource(ctx, BalanceLedgerSource{
+			Reason:     BalanceLedgerReasonUsage,
+			SourceType: BalanceLedgerSourceUsageRequest,
+			SourceID:   requestID,
+		}), p, deps)
+		batchExec.recordCost(ctx, requestID, p.Cost)
 		return true, nil
 	}
 
+	if cmd.APIKeyQuotaCost.IsPositive() && deps.balanceNotifyService != nil {
+		cmd.QuotaExhaustedWebhookEvents = deps.balanceNotifyService.apiKeyQuotaExhaustedWebhookEvents(p.APIKey, cmd.RequestID)
+	}
+
 	billingCtx, cancel := detachedBillingContext(ctx)
 	defer cancel()
 
@@ -362,6 +401,7 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 	}
 
 	finalizePostUsageBilling(billingCtx, p, deps, result)
+	batchExec.recordCost(billingCtx, cmd.RequestID, p.Cost)
 	return true, nil
 }
 
@@ -374,8 +414,12 @@ func finalizePostUsageBilling(ctx context.Context, p *postUsageBillingParams, de
 		if p.Cost.ActualCost > 0 && p.User != nil && p.APIKey != nil && p.APIKey.GroupID != nil {
 			deps.billingCacheService.QueueUpdateSubscriptionUsage(p.User.ID, *p.APIKey.GroupID, p.Cost.ActualCost)
 		}
-	} else if p.Cost.ActualCost > 0 && p.User != nil {
-		syncBalanceCacheAfterDeduction(ctx, p, deps, result)
+	} else if p.Cost.ActualCost > 0 && p.User != nil && !p.BalanceHeld {
+		if p.chargesOrganization() {
+			deps.billingCacheService.RecordOrganizationCharge(*p.APIKey.OrganizationID, p.User.ID, p.Cost.ActualCost, result)
+		} else {
+			syncBalanceCacheAfterDeduction(ctx, p, deps, result)
+		}
 	}
 
 	if p.Cost.ActualCost > 0 && p.APIKey != nil && p.APIKey.HasRateLimits() {
@@ -422,6 +466,24 @@ func finalizePostUsageBilling(ctx context.Context, p *postUsageBillingParams, de
 	// no dependency on the request context or upstream connection.
 	go notifyBalanceLow(p, deps, result)
 	go notifyAccountQuota(p, deps, result)
+	if !p.IsSubscriptionBill && p.Cost.ActualCost > 0 && p.User != nil && p.APIKey != nil && deps.billingCacheService.budgetsEnabled() {
+		go recordBudgetUsage(p, deps)
+	}
+}
+
+// recordBudgetUsage 累加用户 / Key / 分组预算的本周期消费，并发送新达到的 50/80/100% 通知。
+func recordBudgetUsage(p *postUsageBillingParams, deps *billingDeps) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("panic in recordBudgetUsage", "recover", r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), budgetRecordTimeout)
+	defer cancel()
+	alerts := deps.billingCacheService.RecordBudgetCharge(ctx, p.User.ID, p.APIKey, p.Model, p.Cost.ActualCost)
+	if len(alerts) > 0 && deps.balanceNotifyService != nil {
+		deps.balanceNotifyService.NotifyBudgetThresholds(ctx, p.User, alerts)
+	}
 }
 
 func syncBalanceCacheAfterDeduction(ctx context.Context, p *postUsageBillingParams, deps *billingDeps, result *UsageBillingApplyResult) {
@@ -451,7 +513,7 @@ func notifyBalanceLow(p *postUsageBillingParams, deps *billingDeps, result *Usag
 			slog.Error("panic in notifyBalanceLow", "recover", r)
 		}
 	}()
-	if p.IsSubscriptionBill || p.Cost.ActualCost <= 0 || p.User == nil || deps.balanceNotifyService == nil {
+	if p.IsSubscriptionBill || p.BalanceHeld || p.chargesOrganization() || p.Cost.ActualCost <= 0 || p.User == nil || deps.balanceNotifyService == nil {
 		slog.Debug("notifyBalanceLow: skipped",
 			"is_subscription", p.IsSubscriptionBill,
 			"actual_cost", p.Cost.ActualCost,
@@ -477,7 +539,7 @@ func notifyBalanceLow(p *postUsageBillingParams, deps *billingDeps, result *Usag
 // Prefers the DB transaction result (newBalance + cost) over snapshot.
 func resolveOldBalance(p *postUsageBillingParams, result *UsageBillingApplyResult) float64 {
 	if result != nil && result.NewBalance != nil {
-		return *result.NewBalance + p.Cost.ActualCost
+		return money.Sum(*result.NewBalance, p.Cost.ActualCost)
 	}
 	// Legacy fallback: snapshot balance from request context
 	return p.User.Balance
@@ -501,7 +563,7 @@ func notifyAccountQuota(p *postUsageBillingParams, deps *billingDeps, result *Us
 		)
 		return
 	}
-	accountCost := p.Cost.TotalCost * p.AccountRateMultiplier
+	accountCost := money.Mul(p.Cost.TotalCost, p.AccountRateMultiplier, money.LedgerScale)
 	var quotaState *AccountQuotaState
 	if result != nil {
 		quotaState = result.QuotaState
@@ -847,7 +909,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 		input.BillingModelSource,
 		result.UpstreamResponseModel,
 		result.UpstreamResponseModelConflict,
-		result.ImageCount > 0 || result.AudioUsage != nil || result.SearchCount > 0,
+		result.ImageCount > 0 || result.AudioUsage != nil || result.SearchCount > 0 || result.WebFetchCount > 0,
 	); responseModel != "" && !strings.EqualFold(responseModel, strings.TrimSpace(billingModel)) {
 		if identified, responseChannelPriced := s.hasIdentifiedResponseModelPricing(ctx, responseModel, apiKey); identified {
 			responseCost := s.calculateRecordUsageCost(ctx, result, apiKey, responseModel, multiplier, imageMultiplier, pricingAt, opts)
@@ -980,8 +1042,17 @@ func (s *GatewayService) calculateRecordUsageCost(
 			if tokenCost == nil {
 				return searchCost
 			}
-			tokenCost.TotalCost += searchCost.TotalCost
-			tokenCost.ActualCost += searchCost.ActualCost
+			tokenCost.add(searchCost)
+		}
+	}
+	// 模拟 web_fetch 同为叠加 surcharge；倍率沿用 token 倍率，与 search 口径一致。
+	if result.WebFetchCount > 0 {
+		fetchCost := s.billingService.CalculateWebFetchCost(result.WebFetchCount, webFetchPricePerCallFromAPIKey(apiKey), multiplier)
+		if fetchCost != nil && (fetchCost.TotalCost > 0 || fetchCost.ActualCost > 0) {
+			if tokenCost == nil {
+				return fetchCost
+			}
+			tokenCost.add(fetchCost)
 		}
 	}
 	return tokenCost
@@ -1140,15 +1211,31 @@ func (s *GatewayService) calculateTokenCost(
 		CacheCreation1hTokens: result.Usage.CacheCreation1hTokens,
 		ImageOutputTokens:     result.Usage.ImageOutputTokens,
 	}
+	cost, err := s.resolveTokenCost(ctx, apiKey, billingModel, tokens, multiplier, pricingAt, optionalStringValue(result.ServiceTier), opts)
+	if err != nil {
+		logger.LegacyPrintf("service.gateway", "Calculate cost failed: %v", err)
+		return &CostBreakdown{ActualCost: 0}
+	}
+	return cost
+}
 
-	var cost *CostBreakdown
-	var err error
-
+// resolveTokenCost 按 token 用量计价：渠道/分组定价优先，其次长上下文规则，再次统一解析器，
+// 最后回退全局价格表。响应缓存命中与 recordUsageCore 共用这一解析顺序。
+func (s *GatewayService) resolveTokenCost(
+	ctx context.Context,
+	apiKey *APIKey,
+	billingModel string,
+	tokens UsageTokens,
+	multiplier float64,
+	pricingAt time.Time,
+	serviceTier string,
+	opts *recordUsageOpts,
+) (*CostBreakdown, error) {
 	// Explicit group/channel pricing wins. Built-in pricing also uses the unified
 	// resolver so the group long-context toggle can veto model-native tiers.
 	if resolved := s.resolveChannelPricing(ctx, billingModel, apiKey); resolved != nil {
 		gid := apiKey.Group.ID
-		cost, err = s.billingService.CalculateCostUnified(CostInput{
+		return s.billingService.CalculateCostUnified(CostInput{
 			Ctx:            ctx,
 			Model:          billingModel,
 			GroupID:        &gid,
@@ -1157,28 +1244,24 @@ func (s *GatewayService) calculateTokenCost(
 			RequestCount:   1,
 			RateMultiplier: multiplier,
 			PricingAt:      pricingAt,
-			ServiceTier:    optionalStringValue(result.ServiceTier),
+			ServiceTier:    serviceTier,
 			Resolver:       s.resolver,
 			Resolved:       resolved,
 		})
-	} else if opts.LongContextThreshold > 0 && (apiKey.Group == nil || apiKey.Group.LongContextPricingEnabled) {
+	}
+	if opts != nil && opts.LongContextThreshold > 0 && (apiKey.Group == nil || apiKey.Group.LongContextPricingEnabled) {
 		// 长上下文双倍计费（如 Gemini 200K 阈值）
-		cost, err = s.billingService.CalculateCostWithLongContext(billingModel, tokens, multiplier, opts.LongContextThreshold, opts.LongContextMultiplier)
-	} else if s.resolver != nil && apiKey.Group != nil {
+		return s.billingService.CalculateCostWithLongContext(billingModel, tokens, multiplier, opts.LongContextThreshold, opts.LongContextMultiplier)
+	}
+	if s.resolver != nil && apiKey.Group != nil {
 		gid := apiKey.Group.ID
-		cost, err = s.billingService.CalculateCostUnified(CostInput{
+		return s.billingService.CalculateCostUnified(CostInput{
 			Ctx: ctx, Model: billingModel, GroupID: &gid, Group: apiKey.Group,
 			Tokens: tokens, RequestCount: 1, RateMultiplier: multiplier, PricingAt: pricingAt,
-			ServiceTier: optionalStringValue(result.ServiceTier), Resolver: s.resolver,
+			ServiceTier: serviceTier, Resolver: s.resolver,
 		})
-	} else {
-		cost, err = s.billingService.CalculateCost(billingModel, tokens, multiplier)
 	}
-	if err != nil {
-		logger.LegacyPrintf("service.gateway", "Calculate cost failed: %v", err)
-		return &CostBreakdown{ActualCost: 0}
-	}
-	return cost
+	return s.billingService.CalculateCost(billingModel, tokens, multiplier)
 }
 
 // buildRecordUsageLog 构建使用日志并设置计费模式。
@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/websearch"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// Web fetch emulation constants
+const (
+	toolTypeWebFetchPrefix  = "web_fetch"
+	toolNameWebFetch        = "web_fetch"
+	webFetchMsgIDPrefix     = "msg_wf_"
+	webFetchToolUseIDPrefix = "srvtoolu_wf_"
+
+	webFetchDefaultMaxUses = 3
+	webFetchMaxURLLength   = 250
+	webFetchPageTimeout    = 20 * time.Second
+
+	// web_fetch_tool_error codes, as defined by Anthropic.
+	webFetchErrInvalidInput           = "invalid_input"
+	webFetchErrURLTooLong             = "url_too_long"
+	webFetchErrURLNotAllowed          = "url_not_allowed"
+	webFetchErrURLNotAccessible       = "url_not_accessible"
+	webFetchErrUnsupportedContentType = "unsupported_content_type"
+	webFetchErrMaxUsesExceeded        = "max_uses_exceeded"
+	webFetchErrUnavailable            = "unavailable"
+)
+
+// webFetchURLPattern matches http(s) URLs in free text.
+var webFetchURLPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// webFetchPage downloads and extracts one page through the websearch page-fetch
+// client, which owns the SSRF-hardened dialer and the per-proxy client cache.
+// Replaced in tests to avoid the network.
+var webFetchPage = func(ctx context.Context, req websearch.FetchRequest) (*websearch.PageContent, error) {
+	return webFetchManager().FetchPage(ctx, req)
+}
+
+// webFetchStandaloneManager serves page fetches when no search manager is wired:
+// web fetch emulation needs no search provider.
+var webFetchStandaloneManager = websearch.NewManager(nil, nil)
+
+func webFetchManager() *websearch.Manager {
+	if m := getWebSearchManager(); m != nil {
+		return m
+	}
+	return webFetchStandaloneManager
+}
+
+// webFetchToolOptions are the web_fetch tool parameters the emulation honours.
+type webFetchToolOptions struct {
+	MaxUses          int
+	AllowedDomains   []string
+	BlockedDomains   []string
+	MaxContentTokens int
+	Citations        bool
+}
+
+// webFetchOutcome is the result of one fetch; ErrorCode is set when it failed.
+type webFetchOutcome struct {
+	URL         string
+	Page        *websearch.PageContent
+	ErrorCode   string
+	RetrievedAt time.Time
+	err         error
+}
+
+// shouldEmulateWebFetch checks whether a request should be answered by the gateway's own fetcher.
+//
+// Judgment chain: only web_fetch tool → global web_fetch switch → account/channel enabled
+// (same account/channel switch as web search emulation). No search provider is needed.
+func (s *GatewayService) shouldEmulateWebFetch(ctx context.Context, account *Account, groupID *int64, body []byte) bool {
+	if !isOnlyWebFetchToolInBody(body) {
+		return false
+	}
+	if !s.settingService.IsWebFetchEmulationEnabled(ctx) {
+		return false
+	}
+	return s.webEmulationEnabledForAccount(ctx, account, groupID)
+}
+
+// isOnlyWebFetchToolInBody checks if the body contains exactly one web_fetch server tool.
+func isOnlyWebFetchToolInBody(body []byte) bool {
+	tools := gjson.GetBytes(body, "tools")
+	if !tools.IsArray() {
+		return false
+	}
+	arr := tools.Array()
+	return len(arr) == 1 && isWebFetchToolJSON(arr[0])
+}
+
+// isWebFetchToolJSON matches the server tool by type only: unlike web_search,
+// "web_fetch" is a common name for client-defined function tools, which must
+// keep reaching the model.
+func isWebFetchToolJSON(tool gjson.Result) bool {
+	return strings.HasPrefix(tool.Get("type").String(), toolTypeWebFetchPrefix)
+}
+
+func parseWebFetchToolOptions(tool gjson.Result) webFetchToolOptions {
+	opts := webFetchToolOptions{
+		MaxUses:          int(tool.Get("max_uses").Int()),
+		MaxContentTokens: int(tool.Get("max_content_tokens").Int()),
+		Citations:        tool.Get("citations.enabled").Bool(),
+	}
+	if opts.MaxUses <= 0 {
+		opts.MaxUses = webFetchDefaultMaxUses
+	}
+	for _, d := range tool.Get("allowed_domains").Array() {
+		if v := strings.TrimSpace(d.String()); v != "" {
+			opts.AllowedDomains = append(opts.AllowedDomains, v)
+		}
+	}
+	for _, d := range tool.Get("blocked_domains").Array() {
+		if v := strings.TrimSpace(d.String()); v != "" {
+			opts.BlockedDomains = append(opts.BlockedDomains, v)
+		}
+	}
+	return opts
+}
+
+// extractFetchURLsFromBody collects the distinct URLs in the last user message, in order.
+func extractFetchURLsFromBody(body []byte) []string {
+	messages := gjson.GetBytes(body, "messages").Array()
+	if len(messages) == 0 {
+		return nil
+	}
+	lastMsg := messages[len(messages)-1]
+	if lastMsg.Get("role").String() != "user" {
+		return nil
+	}
+	var texts []string
+	content := lastMsg.Get("content")
+	if content.Type == gjson.String {
+		texts = append(texts, content.String())
+	} else {
+		for _, block := range content.Array() {
+			if block.Get("type").String() == "text" {
+				texts = append(texts, block.Get("text").String())
+			}
+		}
+	}
+
+	var urls []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, match := range webFetchURLPattern.FindAllString(text, -1) {
+			u := strings.TrimRight(match, ".,;:!?)]}>")
+			if u != "" && !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
+
+// webFetchDomainAllowed applies allowed_domains / blocked_domains. An entry matches the
+// host and its subdomains; an entry with a path ("example.com/docs") also requires that path prefix.
+func webFetchDomainAllowed(u *url.URL, opts webFetchToolOptions) bool {
+	for _, d := range opts.BlockedDomains {
+		if webFetchDomainMatches(u, d) {
+			return false
+		}
+	}
+	if len(opts.AllowedDomains) == 0 {
+		return true
+	}
+	for _, d := range opts.AllowedDomains {
+		if webFetchDomainMatches(u, d) {
+			return true
+		}
+	}
+	return false
+}
+
+func webFetchDomainMatches(u *url.URL, entry string) bool {
+	entry = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(entry, "https://"), "http://"))
+	domain, path, _ := strings.Cut(entry, "/")
+	host := strings.ToLower(u.Hostname())
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return false
+	}
+	return path == "" || strings.HasPrefix(strings.TrimPrefix(u.Path, "/"), path)
+}
+
+// handleWebFetchEmulation fetches the URLs of a web-fetch-only request itself
+// and constructs an Anthropic-format response.
+func (s *GatewayService) handleWebFetchEmulation(
+	ctx context.Context, c *gin.Context, account *Account, parsed *ParsedRequest,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+
+	// Release the serial queue lock immediately — we don't need upstream.
+	if parsed.OnUpstreamAccepted != nil {
+		parsed.OnUpstreamAccepted()
+	}
+
+	model := parsed.Model
+	if model == "" {
+		model = defaultWebSearchModel
+	}
+	msg, fetched, err := s.runWebFetchEmulation(ctx, account, parsed.Body.Bytes(), model)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Stream {
+		setSSEHeaders(c)
+		for _, evt := range emulatedAnthropicStreamEvents(msg) {
+			if err := flushSSEJSON(c.Writer, evt.Type, evt); err != nil {
+				slog.Warn("web fetch emulation: SSE write failed, stopping", "error", err)
+				break
+			}
+		}
+		c.Writer.Flush()
+	} else {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("web fetch emulation: marshal response: %w", err)
+		}
+		c.Data(http.StatusOK, "application/json", body)
+	}
+
+	return &ForwardResult{
+		Model:         model,
+		Stream:        parsed.Stream,
+		Duration:      time.Since(startTime),
+		WebFetchCount: fetched,
+	}, nil
+}
+
+// runWebFetchEmulation fetches the URLs named in the last user message and returns the
+// assistant message plus the number of pages fetched successfully (the billable count).
+func (s *GatewayService) runWebFetchEmulation(
+	ctx context.Context, account *Account, body []byte, model string,
+) (*apicompat.AnthropicResponse, int, error) {
+	urls := extractFetchURLsFromBody(body)
+	if len(urls) == 0 {
+		return nil, 0, fmt.Errorf("web fetch emulation: no URL found in messages")
+	}
+	opts := parseWebFetchToolOptions(gjson.GetBytes(body, "tools.0"))
+
+	cfg, _ := s.settingService.GetWebSearchEmulationConfig(ctx)
+	maxChars := cfg.WebFetchMaxChars()
+	if opts.MaxContentTokens > 0 && opts.MaxContentTokens*tokenEstimateDivisor < maxChars {
+		maxChars = opts.MaxContentTokens * tokenEstimateDivisor
+	}
+
+	proxyURL := resolveAccountProxyURL(account)
+
+	slog.Info("web fetch emulation: fetching pages",
+		"account_id", account.ID, "account_name", account.Name, "url_count", len(urls))
+
+	outcomes := make([]webFetchOutcome, len(urls))
+	var wg sync.WaitGroup
+	for i, rawURL := range urls {
+		outcomes[i] = webFetchOutcome{URL: rawURL}
+		if i >= opts.MaxUses {
+			outcomes[i].ErrorCode = webFetchErrMaxUsesExceeded
+			continue
+		}
+		if code := precheckWebFetchURL(rawURL, opts); code != "" {
+			outcomes[i].ErrorCode = code
+			continue
+		}
+		wg.Add(1)
+		go func(out *webFetchOutcome) {
+			defer wg.Done()
+			fetchCtx, cancel := context.WithTimeout(ctx, webFetchPageTimeout)
+			defer cancel()
+			page, err := webFetchPage(fetchCtx, websearch.FetchRequest{
+				URL: out.URL, ProxyURL: proxyURL, MaxChars: maxChars,
+			})
+			out.RetrievedAt = time.Now().UTC()
+			if err != nil {
+				slog.Info("web fetch emulation: fetch failed", "url", out.URL, "error", err)
+				out.err = err
+				out.ErrorCode = webFetchErrorCode(err)
+				return
+			}
+			out.Page = page
+		}(&outcomes[i])
+	}
+	wg.Wait()
+
+	fetched := 0
+	for _, out := range outcomes {
+		if errors.Is(out.err, websearch.ErrFetchProxyUnavailable) {
+			// Unusable account proxy → trigger account switch, same as web search emulation.
+			return nil, 0, &UpstreamFailoverError{
+				StatusCode:   http.StatusBadGateway,
+				ResponseBody: []byte(out.err.Error()),
+			}
+		}
+		if out.Page != nil {
+			fetched++
+		}
+	}
+	slog.Info("web fetch emulation: fetch completed", "fetched", fetched, "requested", len(urls))
+	return buildWebFetchMessage(model, outcomes, opts.Citations), fetched, nil
+}
+
+// precheckWebFetchURL rejects URLs before any network access; returns an error code or "".
+func precheckWebFetchURL(rawURL string, opts webFetchToolOptions) string {
+	if len(rawURL) > webFetchMaxURLLength {
+		return webFetchErrURLTooLong
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return webFetchErrInvalidInput
+	}
+	if isBlockedHostname(u.Hostname()) {
+		return webFetchErrURLNotAllowed
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && isPrivateIP(ip) {
+		return webFetchErrURLNotAllowed
+	}
+	if !webFetchDomainAllowed(u, opts) {
+		return webFetchErrURLNotAllowed
+	}
+	return ""
+}
+
+// webFetchErrorCode maps a fetch failure to a web_fetch_tool_error code.
+func webFetchErrorCode(err error) string {
+	switch {
+	case errors.Is(err, websearch.ErrFetchBlocked):
+		return webFetchErrURLNotAllowed
+	case errors.Is(err, websearch.ErrUnsupportedContentType):
+		return webFetchErrUnsupportedContentType
+	case errors.Is(err, context.DeadlineExceeded):
+		return webFetchErrUnavailable
+	default:
+		return webFetchErrURLNotAccessible
+	}
+}
+
+// --- Response construction ---
+
+// buildWebFetchMessage builds the emulated assistant turn: a server_tool_use /
+// web_fetch_tool_result pair per URL, then a text block repeating the fetched
+// text so the context survives FilterWebSearchHistoryBlocks on later turns.
+func buildWebFetchMessage(model string, outcomes []webFetchOutcome, citations bool) *apicompat.AnthropicResponse {
+	content := make([]apicompat.AnthropicContentBlock, 0, 2*len(outcomes)+1)
+	for _, out := range outcomes {
+		toolUseID := webFetchToolUseIDPrefix + uuid.New().String()[:16]
+		input, _ := json.Marshal(map[string]string{"url": out.URL})
+		result, _ := json.Marshal(buildWebFetchResultContent(out, citations))
+		content = append(content,
+			apicompat.AnthropicContentBlock{Type: blockTypeServerToolUse, ID: toolUseID, Name: toolNameWebFetch, Input: input},
+			apicompat.AnthropicContentBlock{Type: blockTypeWebFetchToolResult, ToolUseID: toolUseID, Content: result},
+		)
+	}
+	summary := buildWebFetchTextSummary(outcomes)
+	content = append(content, apicompat.AnthropicContentBlock{Type: "text", Text: summary})
+
+	return &apicompat.AnthropicResponse{
+		ID:         webFetchMsgIDPrefix + uuid.New().String(),
+		Type:       "message",
+		Role:       "assistant",
+		Model:      model,
+		Content:    content,
+		StopReason: apicompat.AnthropicStopReasonPtr("end_turn"),
+		Usage:      apicompat.AnthropicUsage{OutputTokens: len(summary) / tokenEstimateDivisor},
+	}
+}
+
+func buildWebFetchResultContent(out webFetchOutcome, citations bool) map[string]any {
+	if out.Page == nil {
+		return map[string]any{"type": "web_fetch_tool_error", "error_code": out.ErrorCode}
+	}
+	document := map[string]any{
+		"type":   "document",
+		"source": map[string]string{"type": "text", "media_type": "text/plain", "data": out.Page.Text},
+	}
+	if out.Page.Title != "" {
+		document["title"] = out.Page.Title
+	}
+	if citations {
+		document["citations"] = map[string]bool{"enabled": true}
+	}
+	return map[string]any{
+		"type":         "web_fetch_result",
+		"url":          out.Page.URL,
+		"content":      document,
+		"retrieved_at": out.RetrievedAt.Format(time.RFC3339),
+	}
+}
+
+func buildWebFetchTextSummary(outcomes []webFetchOutcome) string {
+	var sb strings.Builder
+	for _, out := range outcomes {
+		if out.Page == nil {
+			fmt.Fprintf(&sb, "Could not fetch %s (%s).\n\n", out.URL, out.ErrorCode)
+			continue
+		}
+		fmt.Fprintf(&sb, "Content fetched from %s", out.Page.URL)
+		if out.Page.Title != "" {
+			fmt.Fprintf(&sb, " (%s)", out.Page.Title)
+		}
+		sb.WriteString(":\n\n")
+		sb.WriteString(out.Page.Text)
+		if out.Page.Truncated {
+			sb.WriteString("\n\n[content truncated]")
+		}
+		sb.WriteString("\n\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// emulatedAnthropicStreamEvents replays a complete message as Anthropic SSE events.
+// Server tool blocks are sent whole in content_block_start; text goes through one delta.
+func emulatedAnthropicStreamEvents(msg *apicompat.AnthropicResponse) []apicompat.AnthropicStreamEvent {
+	start := *msg
+	start.Content = []apicompat.AnthropicContentBlock{}
+	start.StopReason = nil
+	start.Usage = apicompat.AnthropicUsage{}
+
+	events := []apicompat.AnthropicStreamEvent{{Type: "message_start", Message: &start}}
+	for i := range msg.Content {
+		index := i
+		block := msg.Content[i]
+		if block.Type == "text" {
+			events = append(events,
+				apicompat.AnthropicStreamEvent{Type: "content_block_start", Index: &index, ContentBlock: &apicompat.AnthropicContentBlock{Type: "text"}},
+				apicompat.AnthropicStreamEvent{Type: "content_block_delta", Index: &index, Delta: &apicompat.AnthropicDelta{Type: "text_delta", Text: block.Text}},
+			)
+		} else {
+			events = append(events, apicompat.AnthropicStreamEvent{Type: "content_block_start", Index: &index, ContentBlock: &block})
+		}
+		events = append(events, apicompat.AnthropicStreamEvent{Type: "content_block_stop", Index: &index})
+	}
+	usage := msg.Usage
+	events = append(events,
+		apicompat.AnthropicStreamEvent{
+			Type:  "message_delta",
+			Delta: &apicompat.AnthropicDelta{StopReason: apicompat.AnthropicStopReasonString(msg.StopReason)},
+			Usage: &usage,
+		},
+		apicompat.AnthropicStreamEvent{Type: "message_stop"},
+	)
+	return events
+}
+
+// --- Responses API ---
+
+// handleResponsesWebFetchEmulation answers a converted Responses request with the
+// emulated fetch, converting the Anthropic message back to Responses format.
+func (s *GatewayService) handleResponsesWebFetchEmulation(
+	ctx context.Context, c *gin.Context, account *Account, anthropicBody []byte, originalModel string, clientStream bool,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+	msg, fetched, err := s.runWebFetchEmulation(ctx, account, anthropicBody, originalModel)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientStream {
+		setSSEHeaders(c)
+		state := apicompat.NewAnthropicEventToResponsesState()
+		state.Model = originalModel
+		events := emulatedAnthropicStreamEvents(msg)
+	writeLoop:
+		for i := range events {
+			for _, evt := range apicompat.AnthropicEventToResponsesEvents(&events[i], state) {
+				sse, err := apicompat.ResponsesEventToSSE(evt)
+				if err == nil {
+					_, err = c.Writer.WriteString(sse)
+				}
+				if err != nil {
+					slog.Warn("web fetch emulation: SSE write failed, stopping", "error", err)
+					break writeLoop
+				}
+			}
+		}
+		c.Writer.Flush()
+	} else {
+		resp := apicompat.AnthropicToResponsesResponse(msg)
+		resp.Model = originalModel
+		c.JSON(http.StatusOK, resp)
+	}
+
+	return &ForwardResult{
+		Model:         originalModel,
+		Stream:        clientStream,
+		Duration:      time.Since(startTime),
+		WebFetchCount: fetched,
+	}, nil
+}
@@ -0,0 +1,235 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/websearch"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+var webFetchToolBody = []byte(`{"model":"claude-sonnet-4-6","tools":[{"type":"web_fetch_20250910","name":"web_fetch","max_uses":1}],` +
+	`"messages":[{"role":"user","content":[{"type":"text","text":"Read https://example.com/a and https://example.org/b."}]}]}`)
+
+// stubWebFetchPage replaces the page fetcher for the duration of the test.
+func stubWebFetchPage(t *testing.T, fn func(req websearch.FetchRequest) (*websearch.PageContent, error)) {
+	t.Helper()
+	orig := webFetchPage
+	webFetchPage = func(_ context.Context, req websearch.FetchRequest) (*websearch.PageContent, error) {
+		return fn(req)
+	}
+	t.Cleanup(func() { webFetchPage = orig })
+}
+
+func newWebFetchTestService(t *testing.T) *GatewayService {
+	t.Helper()
+	setGlobalWebSearchConfig(&WebSearchEmulationConfig{WebFetchEnabled: true, FetchMaxChars: 1000})
+	t.Cleanup(clearGlobalWebSearchConfig)
+	return &GatewayService{settingService: newSettingServiceForWebSearchTest(false)}
+}
+
+func TestIsOnlyWebFetchToolInBody(t *testing.T) {
+	require.True(t, isOnlyWebFetchToolInBody([]byte(`{"tools":[{"type":"web_fetch_20250910","name":"web_fetch"}]}`)))
+	require.True(t, isOnlyWebFetchToolInBody([]byte(`{"tools":[{"type":"web_fetch"}]}`)))
+	// A client-defined function tool that happens to be called web_fetch is not the server tool.
+	require.False(t, isOnlyWebFetchToolInBody([]byte(`{"tools":[{"name":"web_fetch","input_schema":{"type":"object"}}]}`)))
+	require.False(t, isOnlyWebFetchToolInBody([]byte(`{"tools":[{"type":"web_fetch_20250910"},{"type":"web_search_20250305"}]}`)))
+}
+
+func TestExtractFetchURLsFromBody(t *testing.T) {
+	require.Equal(t, []string{"https://example.com/a", "https://example.org/b"}, extractFetchURLsFromBody(webFetchToolBody))
+	require.Nil(t, extractFetchURLsFromBody([]byte(`{"messages":[{"role":"assistant","content":"https://example.com"}]}`)))
+	require.Equal(t, []string{"https://example.com/x?q=1"},
+		extractFetchURLsFromBody([]byte(`{"messages":[{"role":"user","content":"see (https://example.com/x?q=1), https://example.com/x?q=1"}]}`)))
+}
+
+func TestPrecheckWebFetchURL(t *testing.T) {
+	opts := webFetchToolOptions{AllowedDomains: []string{"example.com/docs"}, BlockedDomains: []string{"bad.example.com"}}
+	require.Equal(t, "", precheckWebFetchURL("https://www.example.com/docs/intro", opts))
+	require.Equal(t, webFetchErrURLNotAllowed, precheckWebFetchURL("https://example.com/blog", opts))
+	require.Equal(t, webFetchErrURLNotAllowed, precheckWebFetchURL("https://bad.example.com/docs", opts))
+	require.Equal(t, webFetchErrURLNotAllowed, precheckWebFetchURL("http://127.0.0.1/admin", webFetchToolOptions{}))
+	require.Equal(t, webFetchErrURLNotAllowed, precheckWebFetchURL("http://metadata.google.internal/", webFetchToolOptions{}))
+	require.Equal(t, webFetchErrInvalidInput, precheckWebFetchURL("ftp://example.com/file", webFetchToolOptions{}))
+	require.Equal(t, webFetchErrURLTooLong, precheckWebFetchURL("https://example.com/"+strings.Repeat("a", webFetchMaxURLLength), webFetchToolOptions{}))
+}
+
+func TestWebFetchErrorCode(t *testing.T) {
+	require.Equal(t, webFetchErrURLNotAllowed, webFetchErrorCode(fmt.Errorf("%w: private", websearch.ErrFetchBlocked)))
+	require.Equal(t, webFetchErrURLNotAllowed, webFetchErrorCode(&url.Error{Op: "Get", URL: "http://x", Err: fmt.Errorf("%w: resolved ip 10.0.0.1 is not allowed", websearch.ErrFetchBlocked)}))
+	require.Equal(t, webFetchErrUnsupportedContentType, webFetchErrorCode(fmt.Errorf("%w %q", websearch.ErrUnsupportedContentType, "image/png")))
+	require.Equal(t, webFetchErrURLNotAccessible, webFetchErrorCode(errors.New("fetch: status 404")))
+}
+
+func TestWebFetchPage_BlocksPrivateTargets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	_, err := webFetchPage(context.Background(), websearch.FetchRequest{URL: srv.URL})
+	require.Error(t, err)
+	require.Equal(t, webFetchErrURLNotAllowed, webFetchErrorCode(err))
+}
+
+func TestRunWebFetchEmulation_BadProxyFailsOver(t *testing.T) {
+	svc := newWebFetchTestService(t)
+	stubWebFetchPage(t, func(req websearch.FetchRequest) (*websearch.PageContent, error) {
+		return nil, fmt.Errorf("%w: invalid proxy URL %q", websearch.ErrFetchProxyUnavailable, req.ProxyURL)
+	})
+
+	_, _, err := svc.runWebFetchEmulation(context.Background(), newAnthropicAPIKeyAccount(WebSearchModeEnabled), webFetchToolBody, "claude-sonnet-4-6")
+	var failover *UpstreamFailoverError
+	require.ErrorAs(t, err, &failover)
+	require.Equal(t, http.StatusBadGateway, failover.StatusCode)
+}
+
+func TestShouldEmulateWebFetch(t *testing.T) {
+	svc := newWebFetchTestService(t)
+	ctx := context.Background()
+
+	require.True(t, svc.shouldEmulateWebFetch(ctx, newAnthropicAPIKeyAccount(WebSearchModeEnabled), nil, webFetchToolBody))
+	require.False(t, svc.shouldEmulateWebFetch(ctx, newAnthropicAPIKeyAccount(WebSearchModeDisabled), nil, webFetchToolBody))
+	require.False(t, svc.shouldEmulateWebFetch(ctx, newAnthropicAPIKeyAccount(WebSearchModeEnabled), nil, webSearchToolBody))
+
+	setGlobalWebSearchConfig(&WebSearchEmulationConfig{WebFetchEnabled: false})
+	require.False(t, svc.shouldEmulateWebFetch(ctx, newAnthropicAPIKeyAccount(WebSearchModeEnabled), nil, webFetchToolBody))
+}
+
+func TestRunWebFetchEmulation_BuildsToolBlocks(t *testing.T) {
+	svc := newWebFetchTestService(t)
+	var gotMaxChars int
+	stubWebFetchPage(t, func(req websearch.FetchRequest) (*websearch.PageContent, error) {
+		gotMaxChars = req.MaxChars
+		return &websearch.PageContent{URL: req.URL, Title: "Example", Text: "Example body", ContentType: "text/html"}, nil
+	})
+
+	msg, fetched, err := svc.runWebFetchEmulation(context.Background(), newAnthropicAPIKeyAccount(WebSearchModeEnabled), webFetchToolBody, "claude-sonnet-4-6")
+	require.NoError(t, err)
+	require.Equal(t, 1, fetched)
+	require.Equal(t, 1000, gotMaxChars)
+
+	// max_uses=1: the second URL is reported as max_uses_exceeded without being fetched.
+	require.Len(t, msg.Content, 5)
+	require.Equal(t, "server_tool_use", msg.Content[0].Type)
+	require.Equal(t, "web_fetch", msg.Content[0].Name)
+	require.True(t, strings.HasPrefix(msg.Content[0].ID, webFetchToolUseIDPrefix))
+	require.JSONEq(t, `{"url":"https://example.com/a"}`, string(msg.Content[0].Input))
+
+	result := gjson.ParseBytes(msg.Content[1].Content)
+	require.Equal(t, "web_fetch_tool_result", msg.Content[1].Type)
+	require.Equal(t, msg.Content[0].ID, msg.Content[1].ToolUseID)
+	require.Equal(t, "web_fetch_result", result.Get("type").String())
+	require.Equal(t, "https://example.com/a", result.Get("url").String())
+	require.Equal(t, "Example body", result.Get("content.source.data").String())
+	require.Equal(t, "Example", result.Get("content.title").String())
+	require.NotEmpty(t, result.Get("retrieved_at").String())
+
+	errResult := gjson.ParseBytes(msg.Content[3].Content)
+	require.Equal(t, "web_fetch_tool_error", errResult.Get("type").String())
+	require.Equal(t, webFetchErrMaxUsesExceeded, errResult.Get("error_code").String())
+
+	require.Equal(t, "text", msg.Content[4].Type)
+	require.Contains(t, msg.Content[4].Text, "Example body")
+}
+
+func TestRunWebFetchEmulation_MaxContentTokensLowersLimit(t *testing.T) {
+	svc := newWebFetchTestService(t)
+	var gotMaxChars int
+	stubWebFetchPage(t, func(req websearch.FetchRequest) (*websearch.PageContent, error) {
+		gotMaxChars = req.MaxChars
+		return nil, errors.New("fetch: status 404")
+	})
+	body := []byte(`{"tools":[{"type":"web_fetch_20250910","max_content_tokens":100}],"messages":[{"role":"user","content":"https://example.com"}]}`)
+
+	msg, fetched, err := svc.runWebFetchEmulation(context.Background(), newAnthropicAPIKeyAccount(WebSearchModeEnabled), body, "m")
+	require.NoError(t, err)
+	require.Equal(t, 0, fetched)
+	require.Equal(t, 100*tokenEstimateDivisor, gotMaxChars)
+	require.Equal(t, webFetchErrURLNotAccessible, gjson.GetBytes(msg.Content[1].Content, "error_code").String())
+}
+
+func TestRunWebFetchEmulation_NoURL(t *testing.T) {
+	svc := newWebFetchTestService(t)
+	body := []byte(`{"tools":[{"type":"web_fetch_20250910"}],"messages":[{"role":"user","content":"no link here"}]}`)
+	_, _, err := svc.runWebFetchEmulation(context.Background(), newAnthropicAPIKeyAccount(WebSearchModeEnabled), body, "m")
+	require.Error(t, err)
+}
+
+func TestEmulatedAnthropicStreamEvents(t *testing.T) {
+	msg := buildWebFetchMessage("claude-sonnet-4-6", []webFetchOutcome{{
+		URL:  "https://example.com",
+		Page: &websearch.PageContent{URL: "https://example.com", Text: "hello"},
+	}}, false)
+
+	events := emulatedAnthropicStreamEvents(msg)
+	var types []string
+	for _, evt := range events {
+		types = append(types, evt.Type)
+	}
+	require.Equal(t, []string{
+		"message_start",
+		"content_block_start", "content_block_stop",
+		"content_block_start", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}, types)
+	require.Empty(t, events[0].Message.Content)
+	require.Equal(t, "web_fetch_tool_result", events[3].ContentBlock.Type)
+	require.Contains(t, events[6].Delta.Text, "hello")
+	require.Equal(t, "end_turn", events[8].Delta.StopReason)
+}
+
+func TestHandleResponsesWebFetchEmulation_NonStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newWebFetchTestService(t)
+	stubWebFetchPage(t, func(req websearch.FetchRequest) (*websearch.PageContent, error) {
+		return &websearch.PageContent{URL: req.URL, Text: "page text"}, nil
+	})
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	result, err := svc.handleResponsesWebFetchEmulation(context.Background(), c, newAnthropicAPIKeyAccount(WebSearchModeEnabled), webFetchToolBody, "claude-sonnet-4-6", false)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.WebFetchCount)
+
+	var resp apicompat.ResponsesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "claude-sonnet-4-6", resp.Model)
+	require.Len(t, resp.Output, 3)
+	require.Equal(t, "web_search_call", resp.Output[0].Type)
+	require.Equal(t, "open_page", resp.Output[0].Action.Type)
+	require.Equal(t, "https://example.com/a", resp.Output[0].Action.URL)
+	require.Equal(t, "message", resp.Output[2].Type)
+	require.Contains(t, resp.Output[2].Content[0].Text, "page text")
+}
+
+func TestCalculateWebFetchCost(t *testing.T) {
+	s := &BillingService{}
+
+	cost := s.CalculateWebFetchCost(2, nil, 1.0)
+	require.InDelta(t, 0.02, cost.TotalCost, 1e-12)
+	require.Equal(t, string(BillingModePerRequest), cost.BillingMode)
+
+	cost = s.CalculateWebFetchCost(3, float64Ptr(0.002), 2.0)
+	require.InDelta(t, 0.006, cost.TotalCost, 1e-12)
+	require.InDelta(t, 0.012, cost.ActualCost, 1e-12)
+
+	cost = s.CalculateWebFetchCost(1, float64Ptr(0), 1.0)
+	require.Zero(t, cost.ActualCost)
+
+	cost = s.CalculateWebFetchCost(0, nil, 1.0)
+	require.Zero(t, cost.TotalCost)
+}
@@ -13,6 +13,7 @@ import (
 const (
 	blockTypeServerToolUse       = "server_tool_use"
 	blockTypeWebSearchToolResult = "web_search_tool_result"
+	blockTypeWebFetchToolResult  = "web_fetch_tool_result"
 )
 
 // Fast-path byte patterns: both block types only ever appear as quoted JSON
@@ -21,30 +22,33 @@ const (
 var (
 	patternServerToolUse       = []byte(`"server_tool_use"`)
 	patternWebSearchToolResult = []byte(`"web_search_tool_result"`)
+	patternWebFetchToolResult  = []byte(`"web_fetch_tool_result"`)
 )
 
-// FilterWebSearchHistoryBlocks removes web-search content blocks from
-// historical messages when the upstream cannot accept them:
+// FilterWebSearchHistoryBlocks removes web-search / web-fetch content blocks
+// from historical messages when the upstream cannot accept them:
 //
-//  1. Emulation-synthesized blocks — server_tool_use / web_search_tool_result
-//     whose tool-use ID carries webSearchToolUseIDPrefix — are fabricated
-//     locally by the web-search emulation (gateway_websearch_emulation.go).
+//  1. Emulation-synthesized blocks — server_tool_use / web_search_tool_result /
+//     web_fetch_tool_result whose tool-use ID carries webSearchToolUseIDPrefix
+//     or webFetchToolUseIDPrefix — are fabricated locally by the emulation
+//     (gateway_websearch_emulation.go, gateway_webfetch_emulation.go).
 //     No upstream ever issued them, so clients replaying the conversation
 //     (e.g. Claude Code) poison every follow-up request. They are stripped
 //     for all upstreams.
 //  2. For passback-required upstreams (DeepSeek/Kimi/GLM …, see
-//     ResolveThinkingProtocol) all server_tool_use / web_search_tool_result
-//     blocks are stripped: these upstreams only accept
+//     ResolveThinkingProtocol) all server_tool_use / web_search_tool_result /
+//     web_fetch_tool_result blocks are stripped: these upstreams only accept
 //     text/thinking/image/tool_use/tool_result and reject anything else with
 //     400 "invalid value: `server_tool_use`". anthropic-strict and unknown
 //     upstreams keep genuine blocks untouched.
 //
 // The emulated assistant turn always carries a trailing text summary, so the
-// search context survives the strip. A message whose content would become
+// search / fetch context survives the strip. A message whose content would become
 // empty gets a placeholder text block (mirroring FilterThinkingBlocksForRetry).
 // Returns the original body unchanged when nothing needs stripping.
 func FilterWebSearchHistoryBlocks(body []byte, mappedModel string) []byte {
-	if !bytes.Contains(body, patternServerToolUse) && !bytes.Contains(body, patternWebSearchToolResult) {
+	if !bytes.Contains(body, patternServerToolUse) && !bytes.Contains(body, patternWebSearchToolResult) &&
+		!bytes.Contains(body, patternWebFetchToolResult) {
 		return body
 	}
 
@@ -125,14 +129,18 @@ func shouldStripWebSearchBlock(block map[string]any, stripAll bool) bool {
 			return true
 		}
 		id, _ := block["id"].(string)
-		return strings.HasPrefix(id, webSearchToolUseIDPrefix)
-	case blockTypeWebSearchToolResult:
+		return isEmulatedWebToolUseID(id)
+	case blockTypeWebSearchToolResult, blockTypeWebFetchToolResult:
 		if stripAll {
 			return true
 		}
 		id, _ := block["tool_use_id"].(string)
-		return strings.HasPrefix(id, webSearchToolUseIDPrefix)
+		return isEmulatedWebToolUseID(id)
 	default:
 		return false
 	}
 }
+
+func isEmulatedWebToolUseID(id string) bool {
+	return strings.HasPrefix(id, webSearchToolUseIDPrefix) || strings.HasPrefix(id, webFetchToolUseIDPrefix)
+}
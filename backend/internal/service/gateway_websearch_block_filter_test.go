@@ -138,3 +138,18 @@ func TestFilterWebSearchHistoryBlocks_PreservesOtherToolBlocks(t *testing.T) {
 
 	require.Equal(t, []string{"tool_use", "text", "tool_result"}, collectContentTypes(t, out))
 }
+
+func TestFilterWebSearchHistoryBlocks_StripsEmulatedWebFetchBlocks(t *testing.T) {
+	body := []byte(`{"model":"claude-sonnet-4-6","messages":[` +
+		`{"role":"user","content":[{"type":"text","text":"read https://example.com"}]},` +
+		`{"role":"assistant","content":[` +
+		`{"type":"server_tool_use","id":"srvtoolu_wf_0123456789abcdef","name":"web_fetch","input":{"url":"https://example.com"}},` +
+		`{"type":"web_fetch_tool_result","tool_use_id":"srvtoolu_wf_0123456789abcdef","content":{"type":"web_fetch_result","url":"https://example.com"}},` +
+		`{"type":"text","text":"Content fetched from https://example.com"}]},` +
+		`{"role":"user","content":[{"type":"text","text":"summarize"}]}]}`)
+	out := FilterWebSearchHistoryBlocks(body, "claude-sonnet-4-6")
+
+	require.Equal(t, []string{"text", "text", "text"}, collectContentTypes(t, out))
+	require.Contains(t, string(out), "Content fetched from")
+	require.NotContains(t, string(out), "srvtoolu_wf_")
+}
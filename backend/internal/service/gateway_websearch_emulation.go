@@ -61,7 +61,12 @@ func (s *GatewayService) shouldEmulateWebSearch(ctx context.Context, account *Ac
 	if !s.settingService.IsWebSearchEmulationEnabled(ctx) {
 		return false
 	}
+	return s.webEmulationEnabledForAccount(ctx, account, groupID)
+}
 
+// webEmulationEnabledForAccount applies the account/channel part of the judgment
+// chain, shared by web_search and web_fetch emulation.
+func (s *GatewayService) webEmulationEnabledForAccount(ctx context.Context, account *Account, groupID *int64) bool {
 	mode := account.GetWebSearchEmulationMode()
 	switch mode {
 	case WebSearchModeEnabled:
@@ -370,7 +375,10 @@ func buildSearchResultBlocks(results []websearch.SearchResult) []map[string]stri
 			"url":   r.URL,
 			"title": r.Title,
 		}
-		if r.Snippet != "" {
+		// Fetched page text, when enabled, is richer than the provider snippet.
+		if r.Content != "" {
+			block["page_content"] = r.Content
+		} else if r.Snippet != "" {
 			block["page_content"] = r.Snippet
 		}
 		if r.PageAge != "" {
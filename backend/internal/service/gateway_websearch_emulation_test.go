@@ -119,6 +119,13 @@ func TestBuildSearchResultBlocks_WithResults(t *testing.T) {
 	require.False(t, hasPageAge)
 }
 
+func TestBuildSearchResultBlocks_PrefersFetchedContent(t *testing.T) {
+	blocks := buildSearchResultBlocks([]websearch.SearchResult{
+		{URL: "https://a.com", Title: "A", Snippet: "snippet a", Content: "full page text"},
+	})
+	require.Equal(t, "full page text", blocks[0]["page_content"])
+}
+
 func TestBuildSearchResultBlocks_Empty(t *testing.T) {
 	blocks := buildSearchResultBlocks(nil)
 	require.Empty(t, blocks)
@@ -263,7 +263,7 @@ func (s *GeminiMessagesCompatService) forwardClaudeBodyAsChatCompletions(
 			return nil, s.writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Failed to read upstream stream")
 		}
 		collectedBytes, _ := json.Marshal(collected)
-		chatResp, usageObj2, err := geminiResponseToChatCompletions(collected, originalModel, collectedBytes, usageObj)
+		chatResp, usageObj2, err := geminiResponseToChatCompletions(collectedBytes, originalModel, usageObj)
 		if err != nil {
 			return nil, s.writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
 		}
@@ -463,12 +463,7 @@ func (s *GeminiMessagesCompatService) handleChatCompletionsNonStreamingResponseF
 		}
 	}
 
-	var geminiResp map[string]any
-	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
-		return nil, s.writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
-	}
-
-	chatResp, usage, err := geminiResponseToChatCompletions(geminiResp, originalModel, respBody, nil)
+	chatResp, usage, err := geminiResponseToChatCompletions(respBody, originalModel, nil)
 	if err != nil {
 		return nil, s.writeChatCompletionsError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
 	}
@@ -479,30 +474,22 @@ func (s *GeminiMessagesCompatService) handleChatCompletionsNonStreamingResponseF
 }
 
 func geminiResponseToChatCompletions(
-	geminiResp map[string]any,
-	originalModel string,
 	rawData []byte,
+	originalModel string,
 	usageOverride *ClaudeUsage,
 ) (*apicompat.ChatCompletionsResponse, *ClaudeUsage, error) {
-	claudeRespMap, usage := convertGeminiToClaudeMessage(geminiResp, originalModel, rawData, true)
-	if usageOverride != nil && (usageOverride.InputTokens > 0 || usageOverride.OutputTokens > 0 || usageOverride.CacheReadInputTokens > 0) {
-		usage = usageOverride
-		if usageMap, ok := claudeRespMap["usage"].(map[string]any); ok {
-			usageMap["input_tokens"] = usage.InputTokens
-			usageMap["output_tokens"] = usage.OutputTokens
-			usageMap["cache_read_input_tokens"] = usage.CacheReadInputTokens
-		}
-	}
-
-	claudeBytes, err := json.Marshal(claudeRespMap)
+	anthropicResp, usage, err := convertGeminiToClaudeMessage(rawData, originalModel, true)
 	if err != nil {
 		return nil, nil, err
 	}
-	var anthropicResp apicompat.AnthropicResponse
-	if err := json.Unmarshal(claudeBytes, &anthropicResp); err != nil {
-		return nil, nil, err
+	if usageOverride != nil && (usageOverride.InputTokens > 0 || usageOverride.OutputTokens > 0 || usageOverride.CacheReadInputTokens > 0) {
+		usage = usageOverride
+		anthropicResp.Usage.InputTokens = usage.InputTokens
+		anthropicResp.Usage.OutputTokens = usage.OutputTokens
+		anthropicResp.Usage.CacheReadInputTokens = usage.CacheReadInputTokens
 	}
-	responsesResp := apicompat.AnthropicToResponsesResponse(&anthropicResp)
+
+	responsesResp := apicompat.AnthropicToResponsesResponse(anthropicResp)
 	return apicompat.ResponsesToChatCompletions(responsesResp, originalModel), usage, nil
 }
 
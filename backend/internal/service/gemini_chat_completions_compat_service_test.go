@@ -41,7 +41,7 @@ func TestGeminiResponseToChatCompletionsPreservesInlineData(t *testing.T) {
 			rawData, err := json.Marshal(geminiResp)
 			require.NoError(t, err)
 
-			got, _, err := geminiResponseToChatCompletions(geminiResp, "gemini-test", rawData, nil)
+			got, _, err := geminiResponseToChatCompletions(rawData, "gemini-test", nil)
 			require.NoError(t, err)
 			require.Len(t, got.Choices, 1)
 
@@ -83,7 +83,7 @@ func TestGeminiResponseToChatCompletionsOmitsInvalidInlineData(t *testing.T) {
 			rawData, err := json.Marshal(geminiResp)
 			require.NoError(t, err)
 
-			got, _, err := geminiResponseToChatCompletions(geminiResp, "gemini-test", rawData, nil)
+			got, _, err := geminiResponseToChatCompletions(rawData, "gemini-test", nil)
 			require.NoError(t, err)
 
 			var content string
@@ -108,28 +108,24 @@ func TestConvertGeminiToClaudeMessageOmitsInlineDataForAnthropicMessages(t *test
 	rawData, err := json.Marshal(geminiResp)
 	require.NoError(t, err)
 
-	withInlineData, _ := convertGeminiToClaudeMessage(geminiResp, "gemini-test", rawData, true)
-	contentWithInlineData, ok := withInlineData["content"].([]any)
-	require.True(t, ok)
-	require.Len(t, contentWithInlineData, 4)
-	require.Equal(t, map[string]any{"type": "text", "text": "before"}, contentWithInlineData[0])
-	require.Equal(t, map[string]any{"type": "text", "text": "![image](data:image/png;base64,aW1hZ2U=)"}, contentWithInlineData[1])
-	toolUse, ok := contentWithInlineData[2].(map[string]any)
-	require.True(t, ok)
-	require.Equal(t, "tool_use", toolUse["type"])
-	require.Equal(t, "get_weather", toolUse["name"])
-	require.Equal(t, map[string]any{"type": "text", "text": "after"}, contentWithInlineData[3])
-
-	withoutInlineData, _ := convertGeminiToClaudeMessage(geminiResp, "gemini-test", rawData, false)
-	contentWithoutInlineData, ok := withoutInlineData["content"].([]any)
-	require.True(t, ok)
-	require.Len(t, contentWithoutInlineData, 3)
-	require.Equal(t, map[string]any{"type": "text", "text": "before"}, contentWithoutInlineData[0])
-	toolUseWithoutInlineData, ok := contentWithoutInlineData[1].(map[string]any)
-	require.True(t, ok)
-	require.Equal(t, "tool_use", toolUseWithoutInlineData["type"])
-	require.Equal(t, "get_weather", toolUseWithoutInlineData["name"])
-	require.Equal(t, map[string]any{"type": "text", "text": "after"}, contentWithoutInlineData[2])
+	withInlineData, _, err := convertGeminiToClaudeMessage(rawData, "gemini-test", true)
+	require.NoError(t, err)
+	require.Len(t, withInlineData.Content, 3)
+	require.Equal(t, "text", withInlineData.Content[0].Type)
+	require.Equal(t, "before![image](data:image/png;base64,aW1hZ2U=)", withInlineData.Content[0].Text)
+	require.Equal(t, "tool_use", withInlineData.Content[1].Type)
+	require.Equal(t, "get_weather", withInlineData.Content[1].Name)
+	require.Equal(t, "after", withInlineData.Content[2].Text)
+
+	withoutInlineData, _, err := convertGeminiToClaudeMessage(rawData, "gemini-test", false)
+	require.NoError(t, err)
+	require.Len(t, withoutInlineData.Content, 3)
+	require.Equal(t, "before", withoutInlineData.Content[0].Text)
+	require.Equal(t, "tool_use", withoutInlineData.Content[1].Type)
+	require.Equal(t, "get_weather", withoutInlineData.Content[1].Name)
+	require.JSONEq(t, `{"city":"Paris"}`, string(withoutInlineData.Content[1].Input))
+	require.Equal(t, "after", withoutInlineData.Content[2].Text)
+	require.Equal(t, "tool_use", *withoutInlineData.StopReason)
 }
 
 func TestGeminiResponseToChatCompletionsRetainsTextAndToolBehavior(t *testing.T) {
@@ -148,7 +144,7 @@ func TestGeminiResponseToChatCompletionsRetainsTextAndToolBehavior(t *testing.T)
 	rawData, err := json.Marshal(geminiResp)
 	require.NoError(t, err)
 
-	got, _, err := geminiResponseToChatCompletions(geminiResp, "gemini-test", rawData, nil)
+	got, _, err := geminiResponseToChatCompletions(rawData, "gemini-test", nil)
 	require.NoError(t, err)
 	require.Len(t, got.Choices, 1)
 
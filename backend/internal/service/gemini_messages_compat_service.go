@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
@@ -62,8 +63,8 @@ func (s *GeminiMessagesCompatService) readUpstreamErrorBody(resp *http.Response)
 		return nil
 	}
 	limit := gatewayUpstreamErrorBodyReadLimit
-	if s != nil && s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody && s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes > int(limit) {
-		limit = int64(s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes)
+	if s != nil && s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody && s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes > int(limit) {
+		limit = int64(s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes)
 	}
 	body, _ := io.ReadAll(io.LimitReader(resp.Body, limit))
 	return body
@@ -815,8 +816,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -901,8 +902,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -968,8 +969,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -1001,8 +1002,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 				}
 				upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -1030,8 +1031,8 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 			upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 			upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 			upstreamDetail := ""
-			if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-				maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+			if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+				maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 				if maxBytes <= 0 {
 					maxBytes = 2048
 				}
@@ -1082,7 +1083,10 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 			collectedBytes, _ := json.Marshal(collected)
 			upstreamResponseModelObserverFromContext(c).ObserveGemini(collectedBytes)
 			observeGeminiImageOutputs(c, collectedBytes)
-			claudeResp, usageObj2 := convertGeminiToClaudeMessage(collected, originalModel, collectedBytes, false)
+			claudeResp, usageObj2, err := convertGeminiToClaudeMessage(collectedBytes, originalModel, false)
+			if err != nil {
+				return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
+			}
 			c.JSON(http.StatusOK, claudeResp)
 			usage = usageObj2
 			if usageObj != nil && (usageObj.InputTokens > 0 || usageObj.OutputTokens > 0) {
@@ -1384,8 +1388,8 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -1484,8 +1488,8 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(evBody))
 				upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -1514,8 +1518,8 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				evBody := unwrapIfNeeded(isOAuth, respBody)
 				upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(evBody)))
 				upstreamDetail := ""
-				if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-					maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+				if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+					maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 					if maxBytes <= 0 {
 						maxBytes = 2048
 					}
@@ -1540,8 +1544,8 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 			upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(evBody))
 			upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 			upstreamDetail := ""
-			if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-				maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+			if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+				maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 				if maxBytes <= 0 {
 					maxBytes = 2048
 				}
@@ -1701,10 +1705,10 @@ const geminiCustomCodeSkippedClientMessage = "Upstream gateway error"
 // upstreamErrorDetail 按配置截断上游错误响应体，用于 ops 错误日志的 Detail 字段；
 // 未开启 LogUpstreamErrorBody 时返回空。
 func (s *GeminiMessagesCompatService) upstreamErrorDetail(body []byte) string {
-	if s.cfg == nil || !s.cfg.Gateway.LogUpstreamErrorBody {
+	if s.cfg == nil || !s.cfg.Live().Gateway.LogUpstreamErrorBody {
 		return ""
 	}
-	maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+	maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 	if maxBytes <= 0 {
 		maxBytes = 2048
 	}
@@ -1742,8 +1746,8 @@ func (s *GeminiMessagesCompatService) writeGeminiNativeUpstreamError(c *gin.Cont
 	upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
 	upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 	upstreamDetail := s.upstreamErrorDetail(respBody)
-	if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-		logger.LegacyPrintf("service.gemini_messages_compat", "[Gemini] native upstream error %d: %s", resp.StatusCode, truncateForLog(respBody, s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes))
+	if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+		logger.LegacyPrintf("service.gemini_messages_compat", "[Gemini] native upstream error %d: %s", resp.StatusCode, truncateForLog(respBody, s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes))
 	}
 	setOpsUpstreamError(c, resp.StatusCode, upstreamMsg, upstreamDetail)
 	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
@@ -1802,8 +1806,8 @@ func (s *GeminiMessagesCompatService) writeGeminiMappedError(c *gin.Context, acc
 	upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(body))
 	upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 	upstreamDetail := ""
-	if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-		maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+	if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+		maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 		if maxBytes <= 0 {
 			maxBytes = 2048
 		}
@@ -1821,8 +1825,8 @@ func (s *GeminiMessagesCompatService) writeGeminiMappedError(c *gin.Context, acc
 		Detail:             upstreamDetail,
 	})
 
-	if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-		logger.LegacyPrintf("service.gemini_messages_compat", "[Gemini] upstream error %d: %s", upstreamStatus, truncateForLog(body, s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes))
+	if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+		logger.LegacyPrintf("service.gemini_messages_compat", "[Gemini] upstream error %d: %s", upstreamStatus, truncateForLog(body, s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes))
 	}
 
 	if status, errType, errMsg, matched := applyErrorPassthroughRule(
@@ -2057,12 +2061,10 @@ func (s *GeminiMessagesCompatService) handleNonStreamingResponse(c *gin.Context,
 	observer.ObserveGemini(unwrappedBody)
 	observeGeminiImageOutputs(c, unwrappedBody)
 
-	var geminiResp map[string]any
-	if err := json.Unmarshal(unwrappedBody, &geminiResp); err != nil {
+	claudeResp, usage, err := convertGeminiToClaudeMessage(unwrappedBody, originalModel, false)
+	if err != nil {
 		return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Failed to parse upstream response")
 	}
-
-	claudeResp, usage := convertGeminiToClaudeMessage(geminiResp, originalModel, unwrappedBody, false)
 	c.JSON(http.StatusOK, claudeResp)
 
 	return usage, nil
@@ -2080,275 +2082,107 @@ func (s *GeminiMessagesCompatService) handleStreamingResponse(c *gin.Context, re
 		return nil, errors.New("streaming not supported")
 	}
 
-	messageID := generateAnthropicMsgID()
-	messageStart := map[string]any{
-		"type": "message_start",
-		"message": map[string]any{
-			"id":            messageID,
-			"type":          "message",
-			"role":          "assistant",
-			"model":         originalModel,
-			"content":       []any{},
-			"stop_reason":   nil,
-			"stop_sequence": nil,
-			"usage": map[string]any{
-				"input_tokens":  0,
-				"output_tokens": 0,
-			},
-		},
-	}
-	writeSSE(c.Writer, "message_start", messageStart)
-	flusher.Flush()
-
 	var firstTokenMs *int
 	var usage ClaudeUsage
-	finishReason := ""
-	sawToolUse := false
-
-	nextBlockIndex := 0
-	openBlockIndex := -1
-	openBlockType := ""
-	seenText := ""
-	openToolIndex := -1
-	openToolID := ""
-	openToolName := ""
-	seenToolJSON := ""
+	state := apicompat.NewGeminiToAnthropicStreamState(originalModel)
+	dedup := &geminiStreamPartDeduper{}
 
-	reader := bufio.NewReader(resp.Body)
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil && !errors.Is(err, io.EOF) {
-			return nil, fmt.Errorf("stream read error: %w", err)
-		}
-
-		if !strings.HasPrefix(line, "data:") {
-			if errors.Is(err, io.EOF) {
-				break
+	writeEvents := func(events []apicompat.AnthropicStreamEvent) {
+		for _, evt := range events {
+			sse, err := apicompat.ResponsesAnthropicEventToSSE(evt)
+			if err != nil {
+				continue
 			}
-			continue
-		}
-		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if payload == "" || payload == "[DONE]" {
-			if errors.Is(err, io.EOF) {
-				break
+			if firstTokenMs == nil && evt.Type == "content_block_delta" {
+				ms := int(time.Since(startTime).Milliseconds())
+				firstTokenMs = &ms
 			}
-			continue
+			_, _ = io.WriteString(c.Writer, sse)
 		}
-
-		unwrappedBytes, err := unwrapGeminiResponse([]byte(payload))
-		if err != nil {
-			continue
-		}
-		observer := upstreamResponseModelObserverFromContext(c)
-		if observer == nil {
-			observer = beginUpstreamResponseModelObservation(c)
-		}
-		observer.ObserveGemini(unwrappedBytes)
-		observeGeminiImageOutputs(c, unwrappedBytes)
-
-		var geminiResp map[string]any
-		if err := json.Unmarshal(unwrappedBytes, &geminiResp); err != nil {
-			continue
+		if len(events) > 0 {
+			flusher.Flush()
 		}
+	}
 
-		if fr := extractGeminiFinishReason(geminiResp); fr != "" {
-			finishReason = fr
-		}
-
-		parts := extractGeminiParts(geminiResp)
-		for _, part := range parts {
-			if text, ok := part["text"].(string); ok && text != "" {
-				// Close an open tool_use block before starting text, mirroring
-				// the functionCall branch (which closes open text blocks) and
-				// the chat-completions sibling's closeOpenTool(). Otherwise a
-				// tool→text sequence keeps the tool_use block open while the
-				// text block starts, emitting overlapping Anthropic content
-				// blocks that violate the SSE contract.
-				if openToolIndex >= 0 {
-					writeSSE(c.Writer, "content_block_stop", map[string]any{
-						"type":  "content_block_stop",
-						"index": openToolIndex,
-					})
-					openToolIndex = -1
-					openToolName = ""
-					seenToolJSON = ""
-				}
-
-				delta, newSeen := computeGeminiTextDelta(seenText, text)
-				seenText = newSeen
-				if delta == "" {
-					continue
-				}
-
-				if openBlockType != "text" {
-					if openBlockIndex >= 0 {
-						writeSSE(c.Writer, "content_block_stop", map[string]any{
-							"type":  "content_block_stop",
-							"index": openBlockIndex,
-						})
-					}
-					openBlockType = "text"
-					openBlockIndex = nextBlockIndex
-					nextBlockIndex++
-					writeSSE(c.Writer, "content_block_start", map[string]any{
-						"type":  "content_block_start",
-						"index": openBlockIndex,
-						"content_block": map[string]any{
-							"type": "text",
-							"text": "",
-						},
-					})
-				}
-
-				if firstTokenMs == nil {
-					ms := int(time.Since(startTime).Milliseconds())
-					firstTokenMs = &ms
-				}
-				writeSSE(c.Writer, "content_block_delta", map[string]any{
-					"type":  "content_block_delta",
-					"index": openBlockIndex,
-					"delta": map[string]any{
-						"type": "text_delta",
-						"text": delta,
-					},
-				})
-				flusher.Flush()
-				continue
-			}
-
-			if fc, ok := part["functionCall"].(map[string]any); ok && fc != nil {
-				name, _ := fc["name"].(string)
-				args := fc["args"]
-				if strings.TrimSpace(name) == "" {
-					name = "tool"
-				}
-
-				// Close any open text block before tool_use.
-				if openBlockIndex >= 0 {
-					writeSSE(c.Writer, "content_block_stop", map[string]any{
-						"type":  "content_block_stop",
-						"index": openBlockIndex,
-					})
-					openBlockIndex = -1
-					openBlockType = ""
-				}
-
-				// If we receive streamed tool args in pieces, keep a single tool block open and emit deltas.
-				if openToolIndex >= 0 && openToolName != name {
-					writeSSE(c.Writer, "content_block_stop", map[string]any{
-						"type":  "content_block_stop",
-						"index": openToolIndex,
-					})
-					openToolIndex = -1
-					openToolName = ""
-					seenToolJSON = ""
-				}
+	// 先发送 message_start，客户端无需等待上游首包。
+	writeEvents(apicompat.GeminiChunkToAnthropicEvents(&apicompat.GeminiResponse{}, state))
 
-				if openToolIndex < 0 {
-					openToolID = "toolu_" + randomHex(8)
-					openToolIndex = nextBlockIndex
-					openToolName = name
-					nextBlockIndex++
-					sawToolUse = true
-
-					writeSSE(c.Writer, "content_block_start", map[string]any{
-						"type":  "content_block_start",
-						"index": openToolIndex,
-						"content_block": map[string]any{
-							"type":  "tool_use",
-							"id":    openToolID,
-							"name":  name,
-							"input": map[string]any{},
-						},
-					})
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, fmt.Errorf("stream read error: %w", readErr)
+		}
+
+		payload, isData := strings.CutPrefix(line, "data:")
+		payload = strings.TrimSpace(payload)
+		if isData && payload != "" && payload != "[DONE]" {
+			if unwrappedBytes, err := unwrapGeminiResponse([]byte(payload)); err == nil {
+				observer := upstreamResponseModelObserverFromContext(c)
+				if observer == nil {
+					observer = beginUpstreamResponseModelObservation(c)
 				}
+				observer.ObserveGemini(unwrappedBytes)
+				observeGeminiImageOutputs(c, unwrappedBytes)
 
-				argsJSONText := "{}"
-				switch v := args.(type) {
-				case nil:
-					// keep default "{}"
-				case string:
-					if strings.TrimSpace(v) != "" {
-						argsJSONText = v
-					}
-				default:
-					if b, err := json.Marshal(args); err == nil && len(b) > 0 {
-						argsJSONText = string(b)
-					}
+				if chunk, err := apicompat.ParseGeminiResponse(unwrappedBytes); err == nil {
+					filterGeminiInlineData(chunk, false)
+					dedup.apply(chunk)
+					writeEvents(apicompat.GeminiChunkToAnthropicEvents(chunk, state))
 				}
-
-				delta, newSeen := computeGeminiTextDelta(seenToolJSON, argsJSONText)
-				seenToolJSON = newSeen
-				if delta != "" {
-					writeSSE(c.Writer, "content_block_delta", map[string]any{
-						"type":  "content_block_delta",
-						"index": openToolIndex,
-						"delta": map[string]any{
-							"type":         "input_json_delta",
-							"partial_json": delta,
-						},
-					})
+				if u := extractGeminiUsage(unwrappedBytes); u != nil {
+					usage = *u
 				}
-				flusher.Flush()
 			}
 		}
 
-		if u := extractGeminiUsage(unwrappedBytes); u != nil {
-			usage = *u
-		}
-
 		// Process the final unterminated line at EOF as well.
-		if errors.Is(err, io.EOF) {
+		if errors.Is(readErr, io.EOF) {
 			break
 		}
 	}
 
-	if openBlockIndex >= 0 {
-		writeSSE(c.Writer, "content_block_stop", map[string]any{
-			"type":  "content_block_stop",
-			"index": openBlockIndex,
-		})
-	}
-	if openToolIndex >= 0 {
-		writeSSE(c.Writer, "content_block_stop", map[string]any{
-			"type":  "content_block_stop",
-			"index": openToolIndex,
-		})
-	}
-
-	stopReason := mapGeminiFinishReasonToClaudeStopReason(finishReason)
-	if sawToolUse {
-		stopReason = "tool_use"
-	}
-
-	usageObj := map[string]any{
-		"output_tokens": usage.OutputTokens,
-	}
-	if usage.InputTokens > 0 {
-		usageObj["input_tokens"] = usage.InputTokens
-	}
-	writeSSE(c.Writer, "message_delta", map[string]any{
-		"type": "message_delta",
-		"delta": map[string]any{
-			"stop_reason":   stopReason,
-			"stop_sequence": nil,
-		},
-		"usage": usageObj,
-	})
-	writeSSE(c.Writer, "message_stop", map[string]any{
-		"type": "message_stop",
-	})
-	flusher.Flush()
-
+	writeEvents(apicompat.FinalizeGeminiAnthropicStream(state))
 	return &geminiStreamResult{usage: &usage, firstTokenMs: firstTokenMs}, nil
 }
 
-func writeSSE(w io.Writer, event string, data any) {
-	if event != "" {
-		_, _ = fmt.Fprintf(w, "event: %s\n", event)
+// geminiStreamPartDeduper 兼容以累计方式回传的上游（每个分片携带截至当前的全文）：
+// 文本与思考内容只保留相对已发送部分的增量，重复回传的同一 functionCall 被丢弃，
+// 之后再交给 apicompat 生成 Anthropic 事件。
+type geminiStreamPartDeduper struct {
+	seenText     string
+	seenThought  string
+	lastCallJSON string
+}
+
+func (d *geminiStreamPartDeduper) apply(chunk *apicompat.GeminiResponse) {
+	if len(chunk.Candidates) == 0 || chunk.Candidates[0].Content == nil {
+		return
+	}
+	content := chunk.Candidates[0].Content
+	parts := content.Parts[:0]
+	for _, part := range content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			callJSON, _ := json.Marshal(part.FunctionCall)
+			if string(callJSON) == d.lastCallJSON {
+				continue
+			}
+			d.lastCallJSON = string(callJSON)
+		case part.Text != "":
+			seen := &d.seenText
+			if part.Thought {
+				seen = &d.seenThought
+			}
+			part.Text, *seen = computeGeminiTextDelta(*seen, part.Text)
+			if part.Text == "" && part.ThoughtSignature == "" {
+				continue
+			}
+			d.lastCallJSON = ""
+		}
+		parts = append(parts, part)
 	}
-	b, _ := json.Marshal(data)
-	_, _ = fmt.Fprintf(w, "data: %s\n\n", string(b))
+	content.Parts = parts
 }
 
 func randomHex(nBytes int) string {
@@ -2850,79 +2684,39 @@ func unwrapGeminiResponse(raw []byte) ([]byte, error) {
 	return raw, nil
 }
 
-func convertGeminiToClaudeMessage(geminiResp map[string]any, originalModel string, rawData []byte, includeInlineData bool) (map[string]any, *ClaudeUsage) {
+// convertGeminiToClaudeMessage 将非流式 Gemini 响应转换为 Anthropic Messages 响应。
+// includeInlineData=false 时丢弃生成的内联图片（Messages 兼容链路），为 true 时
+// 以 markdown data URI 形式回传（Chat Completions 链路）。返回的 usage 额外带有
+// 图片输出 token，用于计费。
+func convertGeminiToClaudeMessage(rawData []byte, originalModel string, includeInlineData bool) (*apicompat.AnthropicResponse, *ClaudeUsage, error) {
+	geminiResp, err := apicompat.ParseGeminiResponse(rawData)
+	if err != nil {
+		return nil, nil, err
+	}
+	filterGeminiInlineData(geminiResp, includeInlineData)
+
 	usage := extractGeminiUsage(rawData)
 	if usage == nil {
 		usage = &ClaudeUsage{}
 	}
+	return apicompat.GeminiResponseToAnthropic(geminiResp, originalModel), usage, nil
+}
 
-	contentBlocks := make([]any, 0)
-	sawToolUse := false
-	if candidates, ok := geminiResp["candidates"].([]any); ok && len(candidates) > 0 {
-		if cand, ok := candidates[0].(map[string]any); ok {
-			if content, ok := cand["content"].(map[string]any); ok {
-				if parts, ok := content["parts"].([]any); ok {
-					for _, part := range parts {
-						pm, ok := part.(map[string]any)
-						if !ok {
-							continue
-						}
-						if text, ok := pm["text"].(string); ok && text != "" {
-							contentBlocks = append(contentBlocks, map[string]any{
-								"type": "text",
-								"text": text,
-							})
-						}
-						if inlineData, ok := pm["inlineData"].(map[string]any); includeInlineData && ok {
-							mimeType, _ := inlineData["mimeType"].(string)
-							data, _ := inlineData["data"].(string)
-							if isGeminiInlineImageMIMEType(mimeType) && isValidBase64(data) {
-								contentBlocks = append(contentBlocks, map[string]any{
-									"type": "text",
-									"text": fmt.Sprintf("![image](data:%s;base64,%s)", mimeType, data),
-								})
-							}
-						}
-						if fc, ok := pm["functionCall"].(map[string]any); ok {
-							name, _ := fc["name"].(string)
-							if strings.TrimSpace(name) == "" {
-								name = "tool"
-							}
-							args := fc["args"]
-							sawToolUse = true
-							contentBlocks = append(contentBlocks, map[string]any{
-								"type":  "tool_use",
-								"id":    "toolu_" + randomHex(8),
-								"name":  name,
-								"input": args,
-							})
-						}
-					}
-				}
-			}
-		}
-	}
-
-	stopReason := mapGeminiFinishReasonToClaudeStopReason(extractGeminiFinishReason(geminiResp))
-	if sawToolUse {
-		stopReason = "tool_use"
+// filterGeminiInlineData 在交给 apicompat 转换前过滤首个候选中的 inlineData：
+// keep=false 时全部丢弃，否则只保留可渲染的图片类型与合法 base64。
+func filterGeminiInlineData(resp *apicompat.GeminiResponse, keep bool) {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return
 	}
-
-	resp := map[string]any{
-		"id":            generateAnthropicMsgID(),
-		"type":          "message",
-		"role":          "assistant",
-		"model":         originalModel,
-		"content":       contentBlocks,
-		"stop_reason":   stopReason,
-		"stop_sequence": nil,
-		"usage": map[string]any{
-			"input_tokens":  usage.InputTokens,
-			"output_tokens": usage.OutputTokens,
-		},
+	content := resp.Candidates[0].Content
+	parts := content.Parts[:0]
+	for _, part := range content.Parts {
+		if part.InlineData != nil && (!keep || !isGeminiInlineImageMIMEType(part.InlineData.MimeType) || !isValidBase64(part.InlineData.Data)) {
+			continue
+		}
+		parts = append(parts, part)
 	}
-
-	return resp, usage
+	content.Parts = parts
 }
 
 func isGeminiInlineImageMIMEType(mimeType string) bool {
@@ -2975,25 +2769,6 @@ func extractGeminiUsage(data []byte) *ClaudeUsage {
 	}
 }
 
-func asInt(v any) (int, bool) {
-	switch t := v.(type) {
-	case float64:
-		return int(t), true
-	case int:
-		return t, true
-	case int64:
-		return int(t), true
-	case json.Number:
-		i, err := t.Int64()
-		if err != nil {
-			return 0, false
-		}
-		return int(i), true
-	default:
-		return 0, false
-	}
-}
-
 func (s *GeminiMessagesCompatService) handleGeminiUpstreamError(ctx context.Context, account *Account, statusCode int, headers http.Header, body []byte) {
 	// 遵守自定义错误码策略：未命中则跳过所有限流处理
 	if !account.ShouldHandleErrorCode(statusCode) {
@@ -3224,303 +2999,18 @@ func mapGeminiFinishReasonToClaudeStopReason(finishReason string) string {
 	}
 }
 
+// convertClaudeMessagesToGeminiGenerateContent 将 Anthropic Messages 请求体转换为
+// Gemini generateContent 请求体，转换规则统一由 apicompat 提供。
 func convertClaudeMessagesToGeminiGenerateContent(body []byte) ([]byte, error) {
-	var req map[string]any
+	var req apicompat.AnthropicRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		return nil, err
 	}
-
-	toolUseIDToName := make(map[string]string)
-
-	systemText := extractClaudeSystemText(req["system"])
-	contents, err := convertClaudeMessagesToGeminiContents(req["messages"], toolUseIDToName)
+	geminiReq, err := apicompat.AnthropicToGemini(&req)
 	if err != nil {
 		return nil, err
 	}
-
-	out := make(map[string]any)
-	if systemText != "" {
-		out["systemInstruction"] = map[string]any{
-			"parts": []any{map[string]any{"text": systemText}},
-		}
-	}
-	out["contents"] = contents
-
-	if tools := convertClaudeToolsToGeminiTools(req["tools"]); tools != nil {
-		out["tools"] = tools
-	}
-
-	generationConfig := convertClaudeGenerationConfig(req)
-	if generationConfig != nil {
-		out["generationConfig"] = generationConfig
-	}
-
-	stripGeminiFunctionIDs(out)
-	return json.Marshal(out)
-}
-
-func stripGeminiFunctionIDs(req map[string]any) {
-	// Defensive cleanup: some upstreams reject unexpected `id` fields in functionCall/functionResponse.
-	contents, ok := req["contents"].([]any)
-	if !ok {
-		return
-	}
-	for _, c := range contents {
-		cm, ok := c.(map[string]any)
-		if !ok {
-			continue
-		}
-		contentParts, ok := cm["parts"].([]any)
-		if !ok {
-			continue
-		}
-		for _, p := range contentParts {
-			pm, ok := p.(map[string]any)
-			if !ok {
-				continue
-			}
-			if fc, ok := pm["functionCall"].(map[string]any); ok && fc != nil {
-				delete(fc, "id")
-			}
-			if fr, ok := pm["functionResponse"].(map[string]any); ok && fr != nil {
-				delete(fr, "id")
-			}
-		}
-	}
-}
-
-func extractClaudeSystemText(system any) string {
-	switch v := system.(type) {
-	case string:
-		return strings.TrimSpace(v)
-	case []any:
-		var parts []string
-		for _, p := range v {
-			pm, ok := p.(map[string]any)
-			if !ok {
-				continue
-			}
-			if t, _ := pm["type"].(string); t != "text" {
-				continue
-			}
-			if text, ok := pm["text"].(string); ok && strings.TrimSpace(text) != "" {
-				parts = append(parts, text)
-			}
-		}
-		return strings.TrimSpace(strings.Join(parts, "\n"))
-	default:
-		return ""
-	}
-}
-
-func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[string]string) ([]any, error) {
-	arr, ok := messages.([]any)
-	if !ok {
-		return nil, errors.New("messages must be an array")
-	}
-
-	out := make([]any, 0, len(arr))
-	for _, m := range arr {
-		mm, ok := m.(map[string]any)
-		if !ok {
-			continue
-		}
-		role, _ := mm["role"].(string)
-		role = strings.ToLower(strings.TrimSpace(role))
-		gRole := "user"
-		if role == "assistant" {
-			gRole = "model"
-		}
-
-		parts := make([]any, 0)
-		switch content := mm["content"].(type) {
-		case string:
-			// 字符串形式的 content，保留所有内容（包括空白）
-			parts = append(parts, map[string]any{"text": content})
-		case []any:
-			// 如果只有一个 block，不过滤空白（让上游 API 报错）
-			singleBlock := len(content) == 1
-
-			for _, block := range content {
-				bm, ok := block.(map[string]any)
-				if !ok {
-					continue
-				}
-				bt, _ := bm["type"].(string)
-				switch bt {
-				case "text":
-					if text, ok := bm["text"].(string); ok {
-						// 单个 block 时保留所有内容（包括空白）
-						// 多个 blocks 时过滤掉空白
-						if singleBlock || strings.TrimSpace(text) != "" {
-							parts = append(parts, map[string]any{"text": text})
-						}
-					}
-				case "tool_use":
-					id, _ := bm["id"].(string)
-					name, _ := bm["name"].(string)
-					if strings.TrimSpace(id) != "" && strings.TrimSpace(name) != "" {
-						toolUseIDToName[id] = name
-					}
-					signature, _ := bm["signature"].(string)
-					signature = strings.TrimSpace(signature)
-					if signature == "" {
-						signature = geminiDummyThoughtSignature
-					}
-					parts = append(parts, map[string]any{
-						"thoughtSignature": signature,
-						"functionCall": map[string]any{
-							"name": name,
-							"args": bm["input"],
-						},
-					})
-				case "tool_result":
-					toolUseID, _ := bm["tool_use_id"].(string)
-					name := toolUseIDToName[toolUseID]
-					if name == "" {
-						name = "tool"
-					}
-					parts = append(parts, map[string]any{
-						"functionResponse": map[string]any{
-							"name": name,
-							"response": map[string]any{
-								"content": extractClaudeContentText(bm["content"]),
-							},
-						},
-					})
-				case "image":
-					if src, ok := bm["source"].(map[string]any); ok {
-						if srcType, _ := src["type"].(string); srcType == "base64" {
-							mediaType, _ := src["media_type"].(string)
-							data, _ := src["data"].(string)
-							if mediaType != "" && data != "" {
-								parts = append(parts, map[string]any{
-									"inlineData": map[string]any{
-										"mimeType": mediaType,
-										"data":     data,
-									},
-								})
-							}
-						}
-					}
-				default:
-					// best-effort: preserve unknown blocks as text
-					if b, err := json.Marshal(bm); err == nil {
-						parts = append(parts, map[string]any{"text": string(b)})
-					}
-				}
-			}
-		default:
-			// ignore
-		}
-
-		out = append(out, map[string]any{
-			"role":  gRole,
-			"parts": parts,
-		})
-	}
-	return out, nil
-}
-
-func extractClaudeContentText(v any) string {
-	switch t := v.(type) {
-	case string:
-		return t
-	case []any:
-		var sb strings.Builder
-		for _, part := range t {
-			pm, ok := part.(map[string]any)
-			if !ok {
-				continue
-			}
-			if pm["type"] == "text" {
-				if text, ok := pm["text"].(string); ok {
-					_, _ = sb.WriteString(text)
-				}
-			}
-		}
-		return sb.String()
-	default:
-		b, _ := json.Marshal(t)
-		return string(b)
-	}
-}
-
-func convertClaudeToolsToGeminiTools(tools any) []any {
-	arr, ok := tools.([]any)
-	if !ok || len(arr) == 0 {
-		return nil
-	}
-
-	hasWebSearch := false
-	funcDecls := make([]any, 0, len(arr))
-	for _, t := range arr {
-		tm, ok := t.(map[string]any)
-		if !ok {
-			continue
-		}
-		if isClaudeWebSearchToolMap(tm) {
-			hasWebSearch = true
-			continue
-		}
-
-		var name, desc string
-		var params any
-
-		// 检查是否为 custom 类型工具 (MCP)
-		toolType, _ := tm["type"].(string)
-		if toolType == "custom" {
-			// Custom 格式: 从 custom 字段获取 description 和 input_schema
-			custom, ok := tm["custom"].(map[string]any)
-			if !ok {
-				continue
-			}
-			name, _ = tm["name"].(string)
-			desc, _ = custom["description"].(string)
-			params = custom["input_schema"]
-		} else {
-			// 标准格式: 从顶层字段获取
-			name, _ = tm["name"].(string)
-			desc, _ = tm["description"].(string)
-			params = tm["input_schema"]
-		}
-
-		if name == "" {
-			continue
-		}
-
-		// 为 nil params 提供默认值
-		if params == nil {
-			params = map[string]any{
-				"type":       "object",
-				"properties": map[string]any{},
-			}
-		}
-		// 清理 JSON Schema
-		cleanedParams := cleanToolSchema(params)
-
-		funcDecls = append(funcDecls, map[string]any{
-			"name":        name,
-			"description": desc,
-			"parameters":  cleanedParams,
-		})
-	}
-
-	out := make([]any, 0, 2)
-	if len(funcDecls) > 0 {
-		out = append(out, map[string]any{
-			"functionDeclarations": funcDecls,
-		})
-	}
-	if hasWebSearch {
-		out = append(out, map[string]any{
-			"googleSearch": map[string]any{},
-		})
-	}
-	if len(out) == 0 {
-		return nil
-	}
-	return out
+	return json.Marshal(geminiReq)
 }
 
 func normalizeGeminiRequestForAIStudio(body []byte) []byte {
@@ -3563,140 +3053,6 @@ func normalizeGeminiRequestForAIStudio(body []byte) []byte {
 	return normalized
 }
 
-func isClaudeWebSearchToolMap(tool map[string]any) bool {
-	toolType, _ := tool["type"].(string)
-	// A function named web_search is still a client-side function. This is
-	// especially important for Chat Completions clients such as Hermes, whose
-	// built-in runtime tools are represented as ordinary function tools.
-	// Promote only explicitly typed server-side search tools to Gemini's
-	// built-in googleSearch tool.
-	return strings.HasPrefix(toolType, "web_search") || toolType == "google_search"
-}
-
-// cleanToolSchema 清理工具的 JSON Schema，移除 Gemini 不支持的字段
-func cleanToolSchema(schema any) any {
-	if schema == nil {
-		return nil
-	}
-
-	switch v := schema.(type) {
-	case map[string]any:
-		cleaned := make(map[string]any)
-		for key, value := range v {
-			// 跳过不支持的字段
-			if key == "$schema" || key == "$id" || key == "$ref" ||
-				key == "$defs" || key == "definitions" ||
-				key == "additionalProperties" || key == "patternProperties" || key == "minLength" ||
-				key == "maxLength" || key == "minItems" || key == "maxItems" || key == "exclusiveMinimum" {
-				continue
-			}
-			// 递归清理嵌套对象
-			cleaned[key] = cleanToolSchema(value)
-		}
-		// 规范化 type 字段为大写
-		if typeVal, ok := cleaned["type"].(string); ok {
-			cleaned["type"] = strings.ToUpper(typeVal)
-		} else if typeValues, ok := cleaned["type"].([]any); ok {
-			for _, typeValue := range typeValues {
-				typeName, ok := typeValue.(string)
-				if ok && !strings.EqualFold(typeName, "null") {
-					cleaned["type"] = strings.ToUpper(typeName)
-					break
-				}
-			}
-			if _, ok := cleaned["type"].([]any); ok {
-				delete(cleaned, "type")
-			}
-		}
-		if cleaned["type"] == "INTEGER" {
-			if minimum, ok := incrementIntegralSchemaBound(v["exclusiveMinimum"]); ok {
-				if existing, exists := cleaned["minimum"]; !exists || schemaNumberLess(existing, minimum) {
-					cleaned["minimum"] = minimum
-				}
-			}
-		}
-		return cleaned
-	case []any:
-		cleaned := make([]any, len(v))
-		for i, item := range v {
-			cleaned[i] = cleanToolSchema(item)
-		}
-		return cleaned
-	default:
-		return v
-	}
-}
-
-func incrementIntegralSchemaBound(value any) (any, bool) {
-	switch v := value.(type) {
-	case float64:
-		if math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) || v+1 <= v {
-			return nil, false
-		}
-		return v + 1, true
-	case int:
-		if v == math.MaxInt {
-			return nil, false
-		}
-		return v + 1, true
-	case int64:
-		if v == math.MaxInt64 {
-			return nil, false
-		}
-		return v + 1, true
-	case json.Number:
-		i, err := v.Int64()
-		if err != nil || i == math.MaxInt64 {
-			return nil, false
-		}
-		return json.Number(fmt.Sprintf("%d", i+1)), true
-	default:
-		return nil, false
-	}
-}
-
-func schemaNumberLess(left, right any) bool {
-	leftNumber, leftOK := schemaNumberFloat64(left)
-	rightNumber, rightOK := schemaNumberFloat64(right)
-	return leftOK && rightOK && leftNumber < rightNumber
-}
-
-func schemaNumberFloat64(value any) (float64, bool) {
-	switch v := value.(type) {
-	case float64:
-		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
-	case int:
-		return float64(v), true
-	case int64:
-		return float64(v), true
-	case json.Number:
-		n, err := v.Float64()
-		return n, err == nil && !math.IsInf(n, 0)
-	default:
-		return 0, false
-	}
-}
-
-func convertClaudeGenerationConfig(req map[string]any) map[string]any {
-	out := make(map[string]any)
-	if mt, ok := asInt(req["max_tokens"]); ok && mt > 0 {
-		out["maxOutputTokens"] = mt
-	}
-	if temp, ok := req["temperature"].(float64); ok {
-		out["temperature"] = temp
-	}
-	if topP, ok := req["top_p"].(float64); ok {
-		out["topP"] = topP
-	}
-	if stopSeq, ok := req["stop_sequences"].([]any); ok && len(stopSeq) > 0 {
-		out["stopSequences"] = stopSeq
-	}
-	if len(out) == 0 {
-		return nil
-	}
-	return out
-}
-
 func (s *GeminiMessagesCompatService) extractImageInputSize(body []byte) string {
 	var req struct {
 		GenerationConfig *struct {
@@ -238,13 +238,45 @@ func TestGeminiForwardAsChatCompletions_FunctionNamedWebSearchStaysClientSide(t
 	require.NotContains(t, functionTool, "google_search")
 }
 
+// convertClaudeToolsForTest 经由 Messages→Gemini 请求转换返回生成的 tools 数组。
+func convertClaudeToolsForTest(t *testing.T, tools []any) []any {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{
+		"model":      "claude-sonnet-4",
+		"max_tokens": 16,
+		"messages":   []any{map[string]any{"role": "user", "content": "hi"}},
+		"tools":      tools,
+	})
+	require.NoError(t, err)
+	out, err := convertClaudeMessagesToGeminiGenerateContent(body)
+	require.NoError(t, err)
+	var posted map[string]any
+	require.NoError(t, json.Unmarshal(out, &posted))
+	result, _ := posted["tools"].([]any)
+	return result
+}
+
+// convertClaudeToolSchemaForTest 返回单个工具转换后的 parameters。
+func convertClaudeToolSchemaForTest(t *testing.T, schema map[string]any) map[string]any {
+	t.Helper()
+	tools := convertClaudeToolsForTest(t, []any{
+		map[string]any{"name": "tool", "input_schema": schema},
+	})
+	require.Len(t, tools, 1)
+	decls := tools[0].(map[string]any)["functionDeclarations"].([]any)
+	require.Len(t, decls, 1)
+	params, ok := decls[0].(map[string]any)["parameters"].(map[string]any)
+	require.True(t, ok)
+	return params
+}
+
 // TestConvertClaudeToolsToGeminiTools_CustomType 测试custom类型工具转换
 func TestConvertClaudeToolsToGeminiTools_CustomType(t *testing.T) {
 	tests := []struct {
-		name        string
-		tools       any
-		expectedLen int
-		description string
+		name          string
+		tools         []any
+		expectedNames []string
+		description   string
 	}{
 		{
 			name: "Standard tools",
@@ -255,8 +287,8 @@ func TestConvertClaudeToolsToGeminiTools_CustomType(t *testing.T) {
 					"input_schema": map[string]any{"type": "object"},
 				},
 			},
-			expectedLen: 1,
-			description: "标准工具格式应该正常转换",
+			expectedNames: []string{"get_weather"},
+			description:   "标准工具格式应该正常转换",
 		},
 		{
 			name: "Custom type tool (MCP format)",
@@ -270,8 +302,8 @@ func TestConvertClaudeToolsToGeminiTools_CustomType(t *testing.T) {
 					},
 				},
 			},
-			expectedLen: 1,
-			description: "Custom类型工具应该从custom字段读取",
+			expectedNames: []string{"mcp_tool"},
+			description:   "Custom类型工具应该从custom字段读取",
 		},
 		{
 			name: "Mixed standard and custom tools",
@@ -290,8 +322,8 @@ func TestConvertClaudeToolsToGeminiTools_CustomType(t *testing.T) {
 					},
 				},
 			},
-			expectedLen: 1,
-			description: "混合工具应该都能正确转换",
+			expectedNames: []string{"standard_tool", "custom_tool"},
+			description:   "混合工具应该都能正确转换",
 		},
 		{
 			name: "Custom tool without custom field",
@@ -302,63 +334,34 @@ func TestConvertClaudeToolsToGeminiTools_CustomType(t *testing.T) {
 					// 缺少 custom 字段
 				},
 			},
-			expectedLen: 0, // 应该被跳过
 			description: "缺少custom字段的custom工具应该被跳过",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := convertClaudeToolsToGeminiTools(tt.tools)
+			result := convertClaudeToolsForTest(t, tt.tools)
 
-			if tt.expectedLen == 0 {
-				if result != nil {
-					t.Errorf("%s: expected nil result, got %v", tt.description, result)
-				}
-				return
-			}
-
-			if result == nil {
-				t.Fatalf("%s: expected non-nil result", tt.description)
-			}
-
-			if len(result) != 1 {
-				t.Errorf("%s: expected 1 tool declaration, got %d", tt.description, len(result))
+			if len(tt.expectedNames) == 0 {
+				require.Empty(t, result, tt.description)
 				return
 			}
 
-			toolDecl, ok := result[0].(map[string]any)
-			if !ok {
-				t.Fatalf("%s: result[0] is not map[string]any", tt.description)
-			}
-
-			funcDecls, ok := toolDecl["functionDeclarations"].([]any)
-			if !ok {
-				t.Fatalf("%s: functionDeclarations is not []any", tt.description)
-			}
-
-			toolsArr, _ := tt.tools.([]any)
-			expectedFuncCount := 0
-			for _, tool := range toolsArr {
-				toolMap, _ := tool.(map[string]any)
-				if toolMap["name"] != "" {
-					// 检查是否为有效的custom工具
-					if toolMap["type"] == "custom" {
-						if toolMap["custom"] != nil {
-							expectedFuncCount++
-						}
-					} else {
-						expectedFuncCount++
-					}
-				}
-			}
-
-			if len(funcDecls) != expectedFuncCount {
-				t.Errorf("%s: expected %d function declarations, got %d",
-					tt.description, expectedFuncCount, len(funcDecls))
+			require.Len(t, result, 1, tt.description)
+			funcDecls, ok := result[0].(map[string]any)["functionDeclarations"].([]any)
+			require.True(t, ok, tt.description)
+			var names []string
+			for _, decl := range funcDecls {
+				names = append(names, decl.(map[string]any)["name"].(string))
 			}
+			require.Equal(t, tt.expectedNames, names, tt.description)
 		})
 	}
+
+	custom := convertClaudeToolsForTest(t, tests[1].tools)
+	decl := custom[0].(map[string]any)["functionDeclarations"].([]any)[0].(map[string]any)
+	require.Equal(t, "MCP tool description", decl["description"])
+	require.Equal(t, map[string]any{"type": "OBJECT"}, decl["parameters"])
 }
 
 func TestCleanToolSchema_NormalizesGeminiUnsupportedSchemaFields(t *testing.T) {
@@ -383,8 +386,7 @@ func TestCleanToolSchema_NormalizesGeminiUnsupportedSchemaFields(t *testing.T) {
 		},
 	}
 
-	cleaned, ok := cleanToolSchema(schema).(map[string]any)
-	require.True(t, ok)
+	cleaned := convertClaudeToolSchemaForTest(t, schema)
 	require.Equal(t, "OBJECT", cleaned["type"])
 	require.NotContains(t, cleaned, "$defs")
 	require.NotContains(t, cleaned, "definitions")
@@ -429,8 +431,7 @@ func TestCleanToolSchema_ConvertsNestedIntegerExclusiveMinimum(t *testing.T) {
 		},
 	}
 
-	cleaned, ok := cleanToolSchema(schema).(map[string]any)
-	require.True(t, ok)
+	cleaned := convertClaudeToolSchemaForTest(t, schema)
 	properties, ok := cleaned["properties"].(map[string]any)
 	require.True(t, ok)
 	counts, ok := properties["counts"].(map[string]any)
@@ -443,12 +444,12 @@ func TestCleanToolSchema_ConvertsNestedIntegerExclusiveMinimum(t *testing.T) {
 	strict, ok := properties["strict"].(map[string]any)
 	require.True(t, ok)
 	require.NotContains(t, strict, "exclusiveMinimum")
-	require.Equal(t, 5, strict["minimum"])
+	require.Equal(t, float64(5), strict["minimum"])
 
 	weak, ok := properties["weak"].(map[string]any)
 	require.True(t, ok)
 	require.NotContains(t, weak, "exclusiveMinimum")
-	require.Equal(t, 3, weak["minimum"])
+	require.Equal(t, float64(3), weak["minimum"])
 }
 
 func TestCleanToolSchema_DropsAmbiguousExclusiveMinimumWithoutConversion(t *testing.T) {
@@ -463,8 +464,7 @@ func TestCleanToolSchema_DropsAmbiguousExclusiveMinimumWithoutConversion(t *test
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			cleaned, ok := cleanToolSchema(schema).(map[string]any)
-			require.True(t, ok)
+			cleaned := convertClaudeToolSchemaForTest(t, schema)
 			require.NotContains(t, cleaned, "exclusiveMinimum")
 			require.NotContains(t, cleaned, "minimum")
 		})
@@ -484,7 +484,7 @@ func TestConvertClaudeToolsToGeminiTools_PreservesWebSearchAlongsideFunctions(t
 		},
 	}
 
-	result := convertClaudeToolsToGeminiTools(tools)
+	result := convertClaudeToolsForTest(t, tools)
 	require.Len(t, result, 2)
 
 	functionDecl, ok := result[0].(map[string]any)
@@ -1076,6 +1076,38 @@ func TestGeminiMessagesHandleStreamingResponse_ClosesToolBlockBeforeText(t *test
 	require.Equal(t, -1, open, "stream ended with a content block still open")
 }
 
+// TestGeminiMessagesHandleStreamingResponse_DedupesCumulativeText 覆盖以累计方式回传
+// 全文的上游：经 apicompat 转换后客户端只应收到增量文本，重复的 functionCall 只出现一次。
+func TestGeminiMessagesHandleStreamingResponse_DedupesCumulativeText(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	upstreamBody := `data: {"candidates":[{"content":{"parts":[{"text":"Hello"}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"text":"Hello, world"}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"get_weather","args":{"city":"SF"}}}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"get_weather","args":{"city":"SF"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":3}}` + "\n\n"
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(upstreamBody)),
+	}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	result, err := (&GeminiMessagesCompatService{}).handleStreamingResponse(c, resp, time.Now(), "claude-3-5-sonnet")
+	require.NoError(t, err)
+	require.Equal(t, 5, result.usage.InputTokens)
+	require.NotNil(t, result.firstTokenMs)
+
+	body := rec.Body.String()
+	require.True(t, strings.HasPrefix(body, "event: message_start\n"))
+	require.Equal(t, 1, strings.Count(body, `"text":"Hello"`))
+	require.Equal(t, 1, strings.Count(body, `"text":", world"`))
+	require.Equal(t, 1, strings.Count(body, `"name":"get_weather"`))
+	require.Contains(t, body, `"stop_reason":"tool_use"`)
+	require.True(t, strings.HasSuffix(body, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+}
+
 type anthropicContentBlockEvent struct {
 	event     string
 	index     int
@@ -210,7 +210,7 @@ func TestOpenAIAccountSchedulerLoadBalanceAppliesGrokFreeQuotaGate(t *testing.T)
 			1: {Tokens: 480_000}, // over 95% of 500k
 		}},
 	}
-	scheduler := &defaultOpenAIAccountScheduler{service: svc, stats: newOpenAIAccountRuntimeStats()}
+	scheduler := &defaultOpenAIAccountScheduler{service: svc, stats: NewAccountRuntimeStats()}
 
 	// Warm cache via background refresh so load-balance sees the soft-gate.
 	_ = scheduler.filterGrokFreeQuotaAccounts(context.Background(), accounts)
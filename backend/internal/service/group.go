@@ -50,11 +50,26 @@ type Group struct {
 	ImagePrice4K                 *float64
 	BatchImageDiscountMultiplier float64
 	BatchImageHoldMultiplier     float64
-	VideoRateIndependent         bool
-	VideoRateMultiplier          float64
-	VideoPrice480P               *float64
-	VideoPrice720P               *float64
-	VideoPrice1080P              *float64
+
+	// OpenAI 兼容 Batch API（/v1/batches）配置
+	AllowBatchAPI              bool
+	BatchAPIDiscountMultiplier float64
+	BatchAPIHoldMultiplier     float64
+
+	// 精确匹配响应缓存配置：TTL 为 0 时使用全局默认值；
+	// ResponseCachePriceMultiplier 在分组有效倍率之上作用于命中请求，0 表示命中免费。
+	ResponseCacheEnabled         bool
+	ResponseCacheTTLSeconds      int
+	ResponseCachePriceMultiplier float64
+
+	// SchedulingStrategy 账号调度策略，空值沿用平台默认调度，见 AccountSchedulingStrategy* 常量。
+	SchedulingStrategy string
+
+	VideoRateIndependent bool
+	VideoRateMultiplier  float64
+	VideoPrice480P       *float64
+	VideoPrice720P       *float64
+	VideoPrice1080P      *float64
 	// VideoModelPrices is optional per-model-family per-second pricing
 	// (groups.video_model_prices JSONB). Shape: family → resolution → USD/s.
 	// When set for a model, overrides VideoPrice* for that model only.
@@ -62,6 +77,9 @@ type Group struct {
 	// Codex alpha/search 网页搜索单次价格（USD/次，仅 openai 平台使用）；
 	// nil 表示使用默认价 defaultWebSearchPricePerCall（官方 $10/1000 次）。
 	WebSearchPricePerCall *float64
+	// 网关模拟 web_fetch 工具的单次抓取价格（USD/次）；
+	// nil 表示使用默认价 defaultWebFetchPricePerCall。
+	WebFetchPricePerCall *float64
 
 	// 搜索工具显式定价（per 1k calls）。
 	SearchPricePer1k *float64
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 票据类型：已支付订单的收据 / 按自然月生成的用量对账单。
+const (
+	InvoiceKindReceipt        = "receipt"
+	InvoiceKindUsageStatement = "usage_statement"
+)
+
+// 票据状态。作废的票据保留编号，不会被删除；重开会分配新编号并通过 ReplacesInvoiceID 关联原票据。
+const (
+	InvoiceStatusIssued = "issued"
+	InvoiceStatusVoid   = "void"
+)
+
+// 票据抬头 / 用量对账单的归属方。
+const (
+	InvoiceOwnerUser         = "user"
+	InvoiceOwnerOrganization = "organization"
+)
+
+// 票据下载格式。
+const (
+	InvoiceFormatHTML = "html"
+	InvoiceFormatPDF  = "pdf"
+)
+
+const (
+	invoiceAutoIssueTimeout       = 10 * time.Minute
+	invoiceAutoIssueLeaderLockKey = "invoice:auto_issue:leader"
+	invoiceAutoIssueLeaderLockTTL = 15 * time.Minute
+	invoiceMaxVoidReasonLength    = 500
+	invoiceMaxProfileFieldLength  = 200
+	invoiceMaxProfileNotesLength  = 1000
+)
+
+var (
+	ErrInvoiceNotFound            = infraerrors.NotFound("INVOICE_NOT_FOUND", "invoice not found")
+	ErrInvoiceAlreadyVoid         = infraerrors.Conflict("INVOICE_ALREADY_VOID", "invoice has already been voided")
+	ErrInvoiceAlreadyIssued       = infraerrors.Conflict("INVOICE_ALREADY_ISSUED", "a live invoice already exists for this order or period")
+	ErrInvoiceOrderNotPaid        = infraerrors.BadRequest("INVOICE_ORDER_NOT_PAID", "receipts are only available for paid orders")
+	ErrInvoicePeriodInvalid       = infraerrors.BadRequest("INVOICE_PERIOD_INVALID", "statement month must be a closed month in YYYY-MM format")
+	ErrInvoiceInvalidOwner        = infraerrors.BadRequest("INVOICE_INVALID_OWNER", "owner_type must be user or organization")
+	ErrInvoiceInvalidFormat       = infraerrors.BadRequest("INVOICE_INVALID_FORMAT", "format must be html or pdf")
+	ErrInvoiceVoidReasonRequired  = infraerrors.BadRequest("INVOICE_VOID_REASON_REQUIRED", "void reason is required (max 500 characters)")
+	ErrBillingProfileFieldTooLong = infraerrors.BadRequest("BILLING_PROFILE_FIELD_TOO_LONG", "billing profile fields are limited to 200 characters (notes 1000)")
+)
+
+// BillingProfile 用户或组织的开票抬头。签发票据时整体快照进 Invoice.BillTo。
+type BillingProfile struct {
+	OwnerType    string    `json:"owner_type"`
+	OwnerID      int64     `json:"owner_id"`
+	CompanyName  string    `json:"company_name"`
+	TaxID        string    `json:"tax_id"`
+	AddressLine1 string    `json:"address_line1"`
+	AddressLine2 string    `json:"address_line2"`
+	City         string    `json:"city"`
+	PostalCode   string    `json:"postal_code"`
+	Country      string    `json:"country"`
+	Email        string    `json:"email"`
+	Notes        string    `json:"notes"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// InvoiceLineItem 票据明细行。用量对账单按模型聚合，Quantity 为请求数；
+// 收据的 Quantity 恒为 1，退款以负金额单独列一行。
+type InvoiceLineItem struct {
+	Description  string  `json:"description"`
+	Quantity     int64   `json:"quantity"`
+	InputTokens  int64   `json:"input_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens,omitempty"`
+	CacheTokens  int64   `json:"cache_tokens,omitempty"`
+	Amount       float64 `json:"amount"`
+}
+
+// Invoice 已签发的票据。收据的 Currency 为订单支付币种；用量对账单以 USD 计价，
+// 金额为 usage_logs.actual_cost 之和（记账精度）。
+type Invoice struct {
+	ID                int64
+	Number            string
+	Kind              string
+	Status            string
+	OwnerType         string
+	OwnerID           int64
+	PaymentOrderID    *int64
+	PeriodStart       *time.Time
+	PeriodEnd         *time.Time
+	Currency          string
+	Subtotal          float64
+	Total             float64
+	BillTo            BillingProfile
+	LineItems         []InvoiceLineItem
+	ReplacesInvoiceID *int64
+	ReplacesNumber    string
+	IssuedBy          *int64
+	VoidReason        string
+	VoidedBy          *int64
+	VoidedAt          *time.Time
+	IssuedAt          time.Time
+	CreatedAt         time.Time
+}
+
+func (inv *Invoice) IsVoid() bool {
+	return inv != nil && inv.Status == InvoiceStatusVoid
+}
+
+// InvoiceFilter 票据列表筛选条件；零值字段不参与过滤。
+type InvoiceFilter struct {
+	OwnerType string
+	OwnerID   int64
+	Kind      string
+	Status    string
+	Number    string
+}
+
+// InvoiceUsageLine 用量对账单按模型聚合的一行。
+type InvoiceUsageLine struct {
+	Model        string
+	Requests     int64
+	InputTokens  int64
+	OutputTokens int64
+	CacheTokens  int64
+	ActualCost   float64
+}
+
+// InvoiceOwnerRef 一个票据归属方（用户或组织）。
+type InvoiceOwnerRef struct {
+	OwnerType string
+	OwnerID   int64
+}
+
+type InvoiceRepository interface {
+	GetBillingProfile(ctx context.Context, ownerType string, ownerID int64) (*BillingProfile, error)
+	UpsertBillingProfile(ctx context.Context, profile *BillingProfile) error
+
+	// Create 在同一事务内分配当年的下一个编号并写入票据，回填 ID / Number / IssuedAt。
+	// 与未作废票据的唯一约束冲突时返回 ErrInvoiceAlreadyIssued。
+	Create(ctx context.Context, inv *Invoice, numberPrefix string) error
+	// Replace 在同一事务内作废 oldID（已作废则保持不变）并签发替代票据，用于重开。
+	Replace(ctx context.Context, oldID, actorID int64, reason string, at time.Time, inv *Invoice, numberPrefix string) error
+	GetByID(ctx context.Context, id int64) (*Invoice, error)
+	GetLiveReceipt(ctx context.Context, orderID int64) (*Invoice, error)
+	GetLiveStatement(ctx context.Context, ownerType string, ownerID int64, periodStart time.Time) (*Invoice, error)
+	List(ctx context.Context, filter InvoiceFilter, params pagination.PaginationParams) ([]Invoice, *pagination.PaginationResult, error)
+	// Void 仅作废 issued 状态的票据，已作废时返回 ErrInvoiceAlreadyVoid。
+	Void(ctx context.Context, id, actorID int64, reason string, at time.Time) (*Invoice, error)
+
+	// AggregateUsage 按模型聚合归属方在 [start, end) 内实际扣费的用量：
+	// 用户为个人 Key 上按余额计费的请求，组织为组织 Key 上按余额（组织钱包）计费的请求。
+	AggregateUsage(ctx context.Context, owner InvoiceOwnerRef, start, end time.Time) ([]InvoiceUsageLine, error)
+	// ListOwnersWithUsage 返回 [start, end) 内有扣费用量、且该周期尚无未作废对账单的归属方。
+	ListOwnersWithUsage(ctx context.Context, start, end time.Time, limit int) ([]InvoiceOwnerRef, error)
+	// ListOrdersWithoutReceipt 返回 [start, end) 内已支付、尚无未作废收据的订单 ID。
+	ListOrdersWithoutReceipt(ctx context.Context, start, end time.Time, limit int) ([]int64, error)
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/textpdf"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// InvoiceDocument 渲染后的票据文件。
+type InvoiceDocument struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// invoiceView 是 HTML / PDF 两种格式共用的展示数据，金额已按票据类型格式化。
+type invoiceView struct {
+	Title       string
+	Number      string
+	Void        bool
+	VoidReason  string
+	IssuedAt    string
+	Period      string
+	Currency    string
+	Issuer      []string
+	BillTo      []string
+	Statement   bool
+	Lines       []invoiceViewLine
+	Total       string
+	Replaces    string
+	GeneratedAt string
+}
+
+type invoiceViewLine struct {
+	Description  string
+	Quantity     string
+	InputTokens  string
+	OutputTokens string
+	CacheTokens  string
+	Amount       string
+}
+
+// Render 把票据渲染为 HTML 或 PDF。
+func (s *InvoiceService) Render(inv *Invoice, format string) (*InvoiceDocument, error) {
+	view := s.buildInvoiceView(inv)
+	switch format {
+	case "", InvoiceFormatHTML:
+		data, err := renderInvoiceHTML(view)
+		if err != nil {
+			return nil, err
+		}
+		return &InvoiceDocument{Filename: inv.Number + ".html", ContentType: "text/html; charset=utf-8", Data: data}, nil
+	case InvoiceFormatPDF:
+		return &InvoiceDocument{Filename: inv.Number + ".pdf", ContentType: "application/pdf", Data: renderInvoicePDF(view)}, nil
+	default:
+		return nil, ErrInvoiceInvalidFormat
+	}
+}
+
+func (s *InvoiceService) buildInvoiceView(inv *Invoice) *invoiceView {
+	loc := timezone.Location()
+	view := &invoiceView{
+		Number:      inv.Number,
+		Void:        inv.IsVoid(),
+		VoidReason:  inv.VoidReason,
+		IssuedAt:    inv.IssuedAt.In(loc).Format("2006-01-02"),
+		Currency:    inv.Currency,
+		Statement:   inv.Kind == InvoiceKindUsageStatement,
+		Total:       formatInvoiceAmount(inv, inv.Total),
+		GeneratedAt: s.now().In(loc).Format(time.RFC3339),
+	}
+	if view.Statement {
+		view.Title = "用量对账单 Usage Statement"
+	} else {
+		view.Title = "收据 Receipt"
+	}
+	if inv.PeriodStart != nil && inv.PeriodEnd != nil {
+		view.Period = inv.PeriodStart.In(loc).Format("2006-01-02") + " - " +
+			inv.PeriodEnd.In(loc).AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	view.Replaces = inv.ReplacesNumber
+
+	view.Issuer = nonEmptyLines(s.cfg.IssuerName, s.cfg.IssuerAddress, taxIDLine(s.cfg.IssuerTaxID), s.cfg.IssuerEmail)
+	b := inv.BillTo
+	view.BillTo = nonEmptyLines(b.CompanyName, b.AddressLine1, b.AddressLine2,
+		strings.TrimSpace(strings.Join(nonEmptyLines(b.PostalCode, b.City), " ")), b.Country, taxIDLine(b.TaxID), b.Email, b.Notes)
+
+	for _, line := range inv.LineItems {
+		v := invoiceViewLine{
+			Description: line.Description,
+			Quantity:    strconv.FormatInt(line.Quantity, 10),
+			Amount:      formatInvoiceAmount(inv, line.Amount),
+		}
+		if view.Statement {
+			v.InputTokens = strconv.FormatInt(line.InputTokens, 10)
+			v.OutputTokens = strconv.FormatInt(line.OutputTokens, 10)
+			v.CacheTokens = strconv.FormatInt(line.CacheTokens, 10)
+		}
+		view.Lines = append(view.Lines, v)
+	}
+	return view
+}
+
+// formatInvoiceAmount 收据按支付币种的小数位显示；对账单按记账精度显示（至少 2 位、去掉多余的 0），
+// 保证明细之和与合计逐位一致。
+func formatInvoiceAmount(inv *Invoice, amount float64) string {
+	if inv.Kind != InvoiceKindUsageStatement {
+		return payment.FormatAmountForCurrency(amount, inv.Currency)
+	}
+	s := money.Decimal(amount).StringFixed(money.LedgerScale)
+	dot := strings.IndexByte(s, '.')
+	end := len(s)
+	for end > dot+3 && s[end-1] == '0' {
+		end--
+	}
+	return s[:end]
+}
+
+func taxIDLine(taxID string) string {
+	if taxID == "" {
+		return ""
+	}
+	return "Tax ID: " + taxID
+}
+
+func nonEmptyLines(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+var invoiceHTMLTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2937; margin: 40px; }
+h1 { font-size: 22px; margin: 0 0 4px; }
+.meta { color: #6b7280; font-size: 13px; }
+.parties { display: flex; gap: 48px; margin: 28px 0; font-size: 14px; }
+.parties h2 { font-size: 12px; text-transform: uppercase; color: #6b7280; margin: 0 0 6px; }
+.parties div div { line-height: 1.5; }
+table { width: 100%; border-collapse: collapse; font-size: 13px; }
+th, td { padding: 8px 6px; border-bottom: 1px solid #e5e7eb; text-align: left; }
+th.num, td.num { text-align: right; font-variant-numeric: tabular-nums; }
+tfoot td { font-weight: 600; border-bottom: none; }
+.void { color: #b91c1c; border: 2px solid #b91c1c; display: inline-block; padding: 4px 12px; margin: 12px 0; font-weight: 700; }
+footer { margin-top: 32px; color: #9ca3af; font-size: 12px; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">No. {{.Number}} &middot; {{.IssuedAt}}{{if .Period}} &middot; {{.Period}}{{end}}{{if .Replaces}} &middot; replaces {{.Replaces}}{{end}}</div>
+{{if .Void}}<div class="void">VOID 已作废{{if .VoidReason}}: {{.VoidReason}}{{end}}</div>{{end}}
+<div class="parties">
+<div><h2>From</h2>{{range .Issuer}}<div>{{.}}</div>{{end}}</div>
+<div><h2>Bill to</h2>{{range .BillTo}}<div>{{.}}</div>{{end}}</div>
+</div>
+<table>
+<thead><tr>
+{{if .Statement}}<th>Model</th><th class="num">Requests</th><th class="num">Input tokens</th><th class="num">Output tokens</th><th class="num">Cache tokens</th>{{else}}<th>Description</th><th class="num">Qty</th>{{end}}
+<th class="num">Amount ({{.Currency}})</th>
+</tr></thead>
+<tbody>
+{{range .Lines}}<tr>
+<td>{{.Description}}</td><td class="num">{{.Quantity}}</td>{{if $.Statement}}<td class="num">{{.InputTokens}}</td><td class="num">{{.OutputTokens}}</td><td class="num">{{.CacheTokens}}</td>{{end}}
+<td class="num">{{.Amount}}</td>
+</tr>
+{{end}}</tbody>
+<tfoot><tr><td colspan="{{if .Statement}}5{{else}}2{{end}}">Total</td><td class="num">{{.Total}} {{.Currency}}</td></tr></tfoot>
+</table>
+<footer>Generated {{.GeneratedAt}}</footer>
+</body>
+</html>
+`))
+
+func renderInvoiceHTML(view *invoiceView) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := invoiceHTMLTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("render invoice html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF 版式（pt）：左右边距 50，正文 9 号字，明细行超出页底时换页并重复表头。
+const (
+	invoicePDFMargin     = 50.0
+	invoicePDFBottom     = 70.0
+	invoicePDFLineHeight = 16.0
+)
+
+func renderInvoicePDF(view *invoiceView) []byte {
+	doc := textpdf.New()
+	right := textpdf.PageWidth - invoicePDFMargin
+	y := textpdf.PageHeight - invoicePDFMargin - 10
+
+	doc.Text(invoicePDFMargin, y, 18, true, view.Title)
+	doc.TextRight(right, y, 10, true, "No. "+view.Number)
+	y -= 16
+	meta := view.IssuedAt
+	if view.Period != "" {
+		meta += "  |  " + view.Period
+	}
+	if view.Replaces != "" {
+		meta += "  |  replaces " + view.Replaces
+	}
+	doc.Text(invoicePDFMargin, y, 9, false, meta)
+	if view.Void {
+		y -= 20
+		note := "VOID"
+		if view.VoidReason != "" {
+			note += ": " + view.VoidReason
+		}
+		doc.Text(invoicePDFMargin, y, 12, true, note)
+	}
+
+	y -= 32
+	partyTop := y
+	doc.Text(invoicePDFMargin, y, 8, true, "FROM")
+	for _, line := range view.Issuer {
+		y -= 13
+		doc.Text(invoicePDFMargin, y, 9, false, line)
+	}
+	bottom := y
+	y = partyTop
+	billX := textpdf.PageWidth / 2
+	doc.Text(billX, y, 8, true, "BILL TO")
+	for _, line := range view.BillTo {
+		y -= 13
+		doc.Text(billX, y, 9, false, line)
+	}
+	if y < bottom {
+		bottom = y
+	}
+	y = bottom - 30
+
+	// 列右边界：描述列左对齐，其余右对齐。
+	var headers []string
+	var columns []float64
+	if view.Statement {
+		headers = []string{"Model", "Requests", "Input", "Output", "Cache", "Amount (" + view.Currency + ")"}
+		columns = []float64{invoicePDFMargin, 270, 335, 400, 460, right}
+	} else {
+		headers = []string{"Description", "Qty", "Amount (" + view.Currency + ")"}
+		columns = []float64{invoicePDFMargin, 420, right}
+	}
+	drawHeader := func() {
+		doc.Text(columns[0], y, 9, true, headers[0])
+		for i := 1; i < len(headers); i++ {
+			doc.TextRight(columns[i], y, 9, true, headers[i])
+		}
+		y -= 6
+		doc.Line(invoicePDFMargin, y, right, y, 0.5)
+		y -= invoicePDFLineHeight - 4
+	}
+	drawHeader()
+
+	for _, line := range view.Lines {
+		if y < invoicePDFBottom {
+			doc.AddPage()
+			y = textpdf.PageHeight - invoicePDFMargin
+			drawHeader()
+		}
+		cells := []string{line.Quantity, line.Amount}
+		if view.Statement {
+			cells = []string{line.Quantity, line.InputTokens, line.OutputTokens, line.CacheTokens, line.Amount}
+		}
+		doc.Text(columns[0], y, 9, false, truncateRunes(line.Description, 60))
+		for i, cell := range cells {
+			doc.TextRight(columns[i+1], y, 9, false, cell)
+		}
+		y -= invoicePDFLineHeight
+	}
+
+	if y < invoicePDFBottom+20 {
+		doc.AddPage()
+		y = textpdf.PageHeight - invoicePDFMargin
+	}
+	doc.Line(invoicePDFMargin, y+invoicePDFLineHeight-6, right, y+invoicePDFLineHeight-6, 0.5)
+	y -= 4
+	doc.Text(invoicePDFMargin, y, 10, true, "Total")
+	doc.TextRight(right, y, 10, true, view.Total+" "+view.Currency)
+	doc.Text(invoicePDFMargin, 40, 7, false, "Generated "+view.GeneratedAt)
+	return doc.Bytes()
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
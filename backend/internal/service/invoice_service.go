@@ -0,0 +1,590 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+)
+
+// invoiceStatementGrace 自然月结束后等待异步用量日志落库的时间，之后才自动开上月对账单。
+const invoiceStatementGrace = time.Hour
+
+var errInvoicePaymentOrderNotFound = infraerrors.NotFound("PAYMENT_ORDER_NOT_FOUND", "payment order not found")
+
+// InvoiceService 签发收据（已支付订单）与月度用量对账单（用户 / 组织），
+// 管理开票抬头，并提供 HTML / PDF 下载与后台作废、重开。
+// 票据签发后内容不可变：抬头与明细都是签发时的快照。
+type InvoiceService struct {
+	repo      InvoiceRepository
+	orgRepo   OrganizationRepository
+	userRepo  UserRepository
+	entClient *dbent.Client
+	cfg       config.InvoiceConfig
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+	now        func() time.Time
+}
+
+func NewInvoiceService(repo InvoiceRepository, orgRepo OrganizationRepository, userRepo UserRepository, entClient *dbent.Client, cfg *config.Config) *InvoiceService {
+	s := &InvoiceService{
+		repo:       repo,
+		orgRepo:    orgRepo,
+		userRepo:   userRepo,
+		entClient:  entClient,
+		stopCh:     make(chan struct{}),
+		instanceID: uuid.NewString(),
+		now:        time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.Invoice
+	}
+	if s.cfg.NumberPrefix == "" {
+		s.cfg.NumberPrefix = "INV"
+	}
+	return s
+}
+
+// SetLeaderLock 注入选主用的锁，多实例部署时只有一个实例执行自动开票。
+func (s *InvoiceService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// FormatInvoiceNumber 生成票据编号：<prefix>-<年份>-<6 位序号>。
+func FormatInvoiceNumber(prefix string, year int, seq int64) string {
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, seq)
+}
+
+// ParseInvoiceMonth 把 YYYY-MM 解析为系统时区下的自然月 [start, end)。
+func ParseInvoiceMonth(month string) (time.Time, time.Time, error) {
+	start, err := timezone.ParseInLocation("2006-01", strings.TrimSpace(month))
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvoicePeriodInvalid
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+func validateInvoiceOwner(owner InvoiceOwnerRef) error {
+	if owner.OwnerID <= 0 || (owner.OwnerType != InvoiceOwnerUser && owner.OwnerType != InvoiceOwnerOrganization) {
+		return ErrInvoiceInvalidOwner
+	}
+	return nil
+}
+
+// AuthorizeOwner 校验用户能否查看 / 管理归属方的票据与抬头：用户只能访问自己的，
+// 组织需为 owner / admin。无权访问时统一返回 NotFound，避免探测。
+func (s *InvoiceService) AuthorizeOwner(ctx context.Context, userID int64, owner InvoiceOwnerRef) error {
+	if err := validateInvoiceOwner(owner); err != nil {
+		return err
+	}
+	if owner.OwnerType == InvoiceOwnerUser {
+		if owner.OwnerID != userID {
+			return ErrInvoiceNotFound
+		}
+		return nil
+	}
+	if s.orgRepo == nil {
+		return ErrOrganizationDisabled
+	}
+	member, err := s.orgRepo.GetMember(ctx, owner.OwnerID, userID)
+	if errors.Is(err, ErrOrganizationMemberNotFound) {
+		return ErrOrganizationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !member.CanManage() {
+		return ErrOrganizationForbidden
+	}
+	return nil
+}
+
+// GetBillingProfile 返回归属方的开票抬头，未设置时返回空抬头。
+func (s *InvoiceService) GetBillingProfile(ctx context.Context, owner InvoiceOwnerRef) (*BillingProfile, error) {
+	if err := validateInvoiceOwner(owner); err != nil {
+		return nil, err
+	}
+	profile, err := s.repo.GetBillingProfile(ctx, owner.OwnerType, owner.OwnerID)
+	if err != nil {
+		return nil, fmt.Errorf("get billing profile: %w", err)
+	}
+	if profile == nil {
+		profile = &BillingProfile{OwnerType: owner.OwnerType, OwnerID: owner.OwnerID}
+	}
+	return profile, nil
+}
+
+// UpdateBillingProfile 整体覆盖开票抬头。已签发的票据不受影响，需重开才会使用新抬头。
+func (s *InvoiceService) UpdateBillingProfile(ctx context.Context, profile *BillingProfile) (*BillingProfile, error) {
+	if profile == nil {
+		return nil, ErrInvoiceInvalidOwner
+	}
+	if err := validateInvoiceOwner(InvoiceOwnerRef{OwnerType: profile.OwnerType, OwnerID: profile.OwnerID}); err != nil {
+		return nil, err
+	}
+	for _, field := range []*string{&profile.CompanyName, &profile.TaxID, &profile.AddressLine1, &profile.AddressLine2,
+		&profile.City, &profile.PostalCode, &profile.Country, &profile.Email} {
+		*field = strings.TrimSpace(*field)
+		if utf8.RuneCountInString(*field) > invoiceMaxProfileFieldLength {
+			return nil, ErrBillingProfileFieldTooLong
+		}
+	}
+	profile.Notes = strings.TrimSpace(profile.Notes)
+	if utf8.RuneCountInString(profile.Notes) > invoiceMaxProfileNotesLength {
+		return nil, ErrBillingProfileFieldTooLong
+	}
+	if err := s.repo.UpsertBillingProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("save billing profile: %w", err)
+	}
+	return profile, nil
+}
+
+// ListForOwner 分页返回归属方的票据（最新在前），调用方需先通过 AuthorizeOwner。
+func (s *InvoiceService) ListForOwner(ctx context.Context, owner InvoiceOwnerRef, filter InvoiceFilter, params pagination.PaginationParams) ([]Invoice, *pagination.PaginationResult, error) {
+	filter.OwnerType = owner.OwnerType
+	filter.OwnerID = owner.OwnerID
+	return s.repo.List(ctx, filter, params)
+}
+
+// GetForUser 返回用户有权访问的票据（本人的，或其管理的组织的）。
+func (s *InvoiceService) GetForUser(ctx context.Context, userID, id int64) (*Invoice, error) {
+	inv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizeOwner(ctx, userID, InvoiceOwnerRef{OwnerType: inv.OwnerType, OwnerID: inv.OwnerID}); err != nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return inv, nil
+}
+
+// IssueReceiptForUser 为用户自己的已支付订单开具收据；已有未作废收据时直接返回。
+func (s *InvoiceService) IssueReceiptForUser(ctx context.Context, userID, orderID int64) (*Invoice, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, errInvoicePaymentOrderNotFound
+	}
+	return s.issueReceipt(ctx, order, nil)
+}
+
+// IssueStatementForUser 为用户本人或其管理的组织开具某个已结束自然月的用量对账单；
+// 该月已有未作废对账单时直接返回。
+func (s *InvoiceService) IssueStatementForUser(ctx context.Context, userID int64, owner InvoiceOwnerRef, month string) (*Invoice, error) {
+	if err := s.AuthorizeOwner(ctx, userID, owner); err != nil {
+		return nil, err
+	}
+	start, end, err := ParseInvoiceMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueStatement(ctx, owner, start, end, nil)
+}
+
+// AdminList 分页返回全部票据（最新在前）。
+func (s *InvoiceService) AdminList(ctx context.Context, filter InvoiceFilter, params pagination.PaginationParams) ([]Invoice, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, filter, params)
+}
+
+func (s *InvoiceService) AdminGet(ctx context.Context, id int64) (*Invoice, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// AdminIssueReceipt 管理员为任意已支付订单开具收据。
+func (s *InvoiceService) AdminIssueReceipt(ctx context.Context, adminID, orderID int64) (*Invoice, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueReceipt(ctx, order, &adminID)
+}
+
+// AdminIssueStatement 管理员为任意用户 / 组织开具月度用量对账单。
+func (s *InvoiceService) AdminIssueStatement(ctx context.Context, adminID int64, owner InvoiceOwnerRef, month string) (*Invoice, error) {
+	if err := validateInvoiceOwner(owner); err != nil {
+		return nil, err
+	}
+	start, end, err := ParseInvoiceMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueStatement(ctx, owner, start, end, &adminID)
+}
+
+// Void 作废票据，编号保留不回收。
+func (s *InvoiceService) Void(ctx context.Context, adminID, id int64, reason string) (*Invoice, error) {
+	reason, err := normalizeInvoiceVoidReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Void(ctx, id, adminID, reason, s.now())
+}
+
+// Reissue 作废原票据（若尚未作废）并按当前数据与当前抬头重新签发，新票据分配新编号并关联原票据。
+// 收据按订单当前状态重建（含之后发生的退款），对账单按原周期重新聚合用量。
+func (s *InvoiceService) Reissue(ctx context.Context, adminID, id int64, reason string) (*Invoice, error) {
+	reason, err := normalizeInvoiceVoidReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv *Invoice
+	switch old.Kind {
+	case InvoiceKindReceipt:
+		if old.PaymentOrderID == nil {
+			return nil, errInvoicePaymentOrderNotFound
+		}
+		order, err := s.loadOrder(ctx, *old.PaymentOrderID)
+		if err != nil {
+			return nil, err
+		}
+		inv, err = s.buildReceipt(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		if old.PeriodStart == nil || old.PeriodEnd == nil {
+			return nil, ErrInvoicePeriodInvalid
+		}
+		inv, err = s.buildStatement(ctx, InvoiceOwnerRef{OwnerType: old.OwnerType, OwnerID: old.OwnerID}, *old.PeriodStart, *old.PeriodEnd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	inv.ReplacesInvoiceID = &old.ID
+	inv.IssuedBy = &adminID
+	inv.IssuedAt = s.now().In(timezone.Location())
+	if err := s.repo.Replace(ctx, old.ID, adminID, reason, inv.IssuedAt, inv, s.cfg.NumberPrefix); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func normalizeInvoiceVoidReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > invoiceMaxVoidReasonLength {
+		return "", ErrInvoiceVoidReasonRequired
+	}
+	return reason, nil
+}
+
+func (s *InvoiceService) loadOrder(ctx context.Context, orderID int64) (*dbent.PaymentOrder, error) {
+	order, err := s.entClient.PaymentOrder.Get(ctx, orderID)
+	if dbent.IsNotFound(err) {
+		return nil, errInvoicePaymentOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load payment order: %w", err)
+	}
+	return order, nil
+}
+
+func (s *InvoiceService) issueReceipt(ctx context.Context, order *dbent.PaymentOrder, issuedBy *int64) (*Invoice, error) {
+	existing, err := s.repo.GetLiveReceipt(ctx, order.ID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+	inv, err := s.buildReceipt(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	inv.IssuedBy = issuedBy
+	inv.IssuedAt = s.now().In(timezone.Location())
+	err = s.repo.Create(ctx, inv, s.cfg.NumberPrefix)
+	if errors.Is(err, ErrInvoiceAlreadyIssued) {
+		// 并发签发时以先提交的为准。
+		return s.repo.GetLiveReceipt(ctx, order.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *InvoiceService) issueStatement(ctx context.Context, owner InvoiceOwnerRef, start, end time.Time, issuedBy *int64) (*Invoice, error) {
+	if end.After(s.now()) {
+		return nil, ErrInvoicePeriodInvalid
+	}
+	existing, err := s.repo.GetLiveStatement(ctx, owner.OwnerType, owner.OwnerID, start)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+	inv, err := s.buildStatement(ctx, owner, start, end)
+	if err != nil {
+		return nil, err
+	}
+	inv.IssuedBy = issuedBy
+	inv.IssuedAt = s.now().In(timezone.Location())
+	err = s.repo.Create(ctx, inv, s.cfg.NumberPrefix)
+	if errors.Is(err, ErrInvoiceAlreadyIssued) {
+		return s.repo.GetLiveStatement(ctx, owner.OwnerType, owner.OwnerID, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// buildReceipt 由订单生成收据：一行实付金额（含手续费），已退款部分按支付币种另列负数行。
+func (s *InvoiceService) buildReceipt(ctx context.Context, order *dbent.PaymentOrder) (*Invoice, error) {
+	if order.PaidAt == nil {
+		return nil, ErrInvoiceOrderNotPaid
+	}
+	currency := PaymentOrderCurrency(order)
+	reference := order.OutTradeNo
+	if reference == "" {
+		reference = fmt.Sprintf("#%d", order.ID)
+	}
+
+	var description string
+	if order.OrderType == payment.OrderTypeSubscription {
+		planName := "subscription"
+		if order.PlanID != nil {
+			if plan, err := s.entClient.SubscriptionPlan.Get(ctx, *order.PlanID); err == nil {
+				planName = plan.Name
+			}
+		}
+		days := 0
+		if order.SubscriptionDays != nil {
+			days = *order.SubscriptionDays
+		}
+		description = fmt.Sprintf("Subscription: %s (%d days) - order %s via %s", planName, days, reference, order.PaymentType)
+	} else {
+		description = fmt.Sprintf("Balance top-up: %s USD credited - order %s via %s",
+			money.Decimal(order.Amount).StringFixed(2), reference, order.PaymentType)
+	}
+
+	lines := []InvoiceLineItem{{Description: description, Quantity: 1, Amount: order.PayAmount}}
+	total := order.PayAmount
+	if order.RefundAmount > 0 && (order.Status == OrderStatusRefunded || order.Status == OrderStatusPartiallyRefunded) {
+		refund := calculateGatewayRefundAmount(order.Amount, order.PayAmount, order.RefundAmount, currency)
+		if refund > 0 {
+			lines = append(lines, InvoiceLineItem{Description: "Refund", Quantity: 1, Amount: -refund})
+			total = money.Sum(total, -refund)
+		}
+	}
+
+	owner := InvoiceOwnerRef{OwnerType: InvoiceOwnerUser, OwnerID: order.UserID}
+	fallbackName := order.UserEmail
+	if order.UserName != "" {
+		fallbackName = order.UserName
+	}
+	billTo, err := s.billToSnapshot(ctx, owner, fallbackName, order.UserEmail)
+	if err != nil {
+		return nil, err
+	}
+	orderID := order.ID
+	return &Invoice{
+		Kind:           InvoiceKindReceipt,
+		Status:         InvoiceStatusIssued,
+		OwnerType:      owner.OwnerType,
+		OwnerID:        owner.OwnerID,
+		PaymentOrderID: &orderID,
+		Currency:       currency,
+		Subtotal:       order.PayAmount,
+		Total:          total,
+		BillTo:         *billTo,
+		LineItems:      lines,
+	}, nil
+}
+
+// buildStatement 按模型聚合 [start, end) 内的扣费用量，金额为 actual_cost 之和（USD）。
+func (s *InvoiceService) buildStatement(ctx context.Context, owner InvoiceOwnerRef, start, end time.Time) (*Invoice, error) {
+	fallbackName, fallbackEmail, err := s.ownerContact(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.repo.AggregateUsage(ctx, owner, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate statement usage: %w", err)
+	}
+	lines := make([]InvoiceLineItem, 0, len(usage))
+	amounts := make([]float64, 0, len(usage))
+	for _, u := range usage {
+		lines = append(lines, InvoiceLineItem{
+			Description:  u.Model,
+			Quantity:     u.Requests,
+			InputTokens:  u.InputTokens,
+			OutputTokens: u.OutputTokens,
+			CacheTokens:  u.CacheTokens,
+			Amount:       money.RoundLedger(u.ActualCost),
+		})
+		amounts = append(amounts, money.RoundLedger(u.ActualCost))
+	}
+	billTo, err := s.billToSnapshot(ctx, owner, fallbackName, fallbackEmail)
+	if err != nil {
+		return nil, err
+	}
+	total := money.Sum(amounts...)
+	return &Invoice{
+		Kind:        InvoiceKindUsageStatement,
+		Status:      InvoiceStatusIssued,
+		OwnerType:   owner.OwnerType,
+		OwnerID:     owner.OwnerID,
+		PeriodStart: &start,
+		PeriodEnd:   &end,
+		Currency:    payment.DefaultPaymentCurrency,
+		Subtotal:    total,
+		Total:       total,
+		BillTo:      *billTo,
+		LineItems:   lines,
+	}, nil
+}
+
+// ownerContact 返回未设置抬头时使用的名称与邮箱：用户取用户名（为空时取邮箱），组织取组织名。
+func (s *InvoiceService) ownerContact(ctx context.Context, owner InvoiceOwnerRef) (string, string, error) {
+	if owner.OwnerType == InvoiceOwnerOrganization {
+		if s.orgRepo == nil {
+			return "", "", ErrOrganizationDisabled
+		}
+		org, err := s.orgRepo.GetByID(ctx, owner.OwnerID)
+		if err != nil {
+			return "", "", err
+		}
+		return org.Name, "", nil
+	}
+	user, err := s.userRepo.GetByID(ctx, owner.OwnerID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.Username != "" {
+		return user.Username, user.Email, nil
+	}
+	return user.Email, user.Email, nil
+}
+
+func (s *InvoiceService) billToSnapshot(ctx context.Context, owner InvoiceOwnerRef, fallbackName, fallbackEmail string) (*BillingProfile, error) {
+	profile, err := s.GetBillingProfile(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if profile.CompanyName == "" {
+		profile.CompanyName = fallbackName
+	}
+	if profile.Email == "" {
+		profile.Email = fallbackEmail
+	}
+	return profile, nil
+}
+
+func (s *InvoiceService) Start() {
+	if s == nil || s.repo == nil || s.cfg.AutoIssueIntervalMinutes <= 0 {
+		return
+	}
+	interval := time.Duration(s.cfg.AutoIssueIntervalMinutes) * time.Minute
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *InvoiceService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *InvoiceService) runOnce() {
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	release, ok := tryAcquireSingletonLeaderLock(lockCtx, s.lockCache, s.db, invoiceAutoIssueLeaderLockKey, s.instanceID, invoiceAutoIssueLeaderLockTTL)
+	lockCancel()
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), invoiceAutoIssueTimeout)
+	defer cancel()
+	receipts, statements := s.AutoIssue(ctx)
+	if receipts > 0 || statements > 0 {
+		slog.Info("[Invoice] auto issued", "receipts", receipts, "statements", statements)
+	}
+}
+
+// AutoIssue 为上月初至今已支付的订单补开收据，并在上月结束（加宽限时间）后为上月有扣费用量的
+// 用户 / 组织开具对账单。单张失败只记录日志，下一轮重试。
+func (s *InvoiceService) AutoIssue(ctx context.Context) (receipts int, statements int) {
+	batch := s.cfg.AutoIssueBatchSize
+	if batch <= 0 {
+		batch = 500
+	}
+	now := s.now()
+	monthStart := timezone.StartOfMonth(now)
+	prevMonthStart := monthStart.AddDate(0, -1, 0)
+
+	orderIDs, err := s.repo.ListOrdersWithoutReceipt(ctx, prevMonthStart, now, batch)
+	if err != nil {
+		slog.Error("[Invoice] list orders without receipt failed", "error", err)
+	}
+	for _, id := range orderIDs {
+		order, err := s.loadOrder(ctx, id)
+		if err == nil {
+			_, err = s.issueReceipt(ctx, order, nil)
+		}
+		if err != nil {
+			slog.Warn("[Invoice] auto issue receipt failed", "order_id", id, "error", err)
+			continue
+		}
+		receipts++
+	}
+
+	if now.Sub(monthStart) < invoiceStatementGrace {
+		return receipts, statements
+	}
+	owners, err := s.repo.ListOwnersWithUsage(ctx, prevMonthStart, monthStart, batch)
+	if err != nil {
+		slog.Error("[Invoice] list statement owners failed", "error", err)
+	}
+	for _, owner := range owners {
+		if _, err := s.issueStatement(ctx, owner, prevMonthStart, monthStart, nil); err != nil {
+			slog.Warn("[Invoice] auto issue statement failed", "owner_type", owner.OwnerType, "owner_id", owner.OwnerID, "error", err)
+			continue
+		}
+		statements++
+	}
+	return receipts, statements
+}
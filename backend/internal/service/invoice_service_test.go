@@ -0,0 +1,386 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/enttest"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/payment"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	_ "modernc.org/sqlite"
+)
+
+type invoiceRepoStub struct {
+	profiles map[InvoiceOwnerRef]*BillingProfile
+	invoices []*Invoice
+	usage    []InvoiceUsageLine
+	seq      int64
+}
+
+func newInvoiceRepoStub() *invoiceRepoStub {
+	return &invoiceRepoStub{profiles: map[InvoiceOwnerRef]*BillingProfile{}}
+}
+
+func (r *invoiceRepoStub) GetBillingProfile(_ context.Context, ownerType string, ownerID int64) (*BillingProfile, error) {
+	p, ok := r.profiles[InvoiceOwnerRef{OwnerType: ownerType, OwnerID: ownerID}]
+	if !ok {
+		return nil, nil
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (r *invoiceRepoStub) UpsertBillingProfile(_ context.Context, profile *BillingProfile) error {
+	cp := *profile
+	r.profiles[InvoiceOwnerRef{OwnerType: profile.OwnerType, OwnerID: profile.OwnerID}] = &cp
+	return nil
+}
+
+func (r *invoiceRepoStub) Create(_ context.Context, inv *Invoice, numberPrefix string) error {
+	r.seq++
+	inv.ID = r.seq
+	inv.Number = FormatInvoiceNumber(numberPrefix, inv.IssuedAt.Year(), r.seq)
+	cp := *inv
+	r.invoices = append(r.invoices, &cp)
+	return nil
+}
+
+func (r *invoiceRepoStub) Replace(ctx context.Context, oldID, actorID int64, reason string, at time.Time, inv *Invoice, numberPrefix string) error {
+	old := r.find(oldID)
+	if old == nil {
+		return ErrInvoiceNotFound
+	}
+	if old.Status == InvoiceStatusIssued {
+		old.Status = InvoiceStatusVoid
+		old.VoidReason = reason
+		old.VoidedBy = &actorID
+		old.VoidedAt = &at
+	}
+	inv.ReplacesNumber = old.Number
+	return r.Create(ctx, inv, numberPrefix)
+}
+
+func (r *invoiceRepoStub) find(id int64) *Invoice {
+	for _, inv := range r.invoices {
+		if inv.ID == id {
+			return inv
+		}
+	}
+	return nil
+}
+
+func (r *invoiceRepoStub) GetByID(_ context.Context, id int64) (*Invoice, error) {
+	inv := r.find(id)
+	if inv == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	cp := *inv
+	return &cp, nil
+}
+
+func (r *invoiceRepoStub) GetLiveReceipt(_ context.Context, orderID int64) (*Invoice, error) {
+	for _, inv := range r.invoices {
+		if inv.Kind == InvoiceKindReceipt && inv.Status == InvoiceStatusIssued && inv.PaymentOrderID != nil && *inv.PaymentOrderID == orderID {
+			cp := *inv
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *invoiceRepoStub) GetLiveStatement(_ context.Context, ownerType string, ownerID int64, periodStart time.Time) (*Invoice, error) {
+	for _, inv := range r.invoices {
+		if inv.Kind == InvoiceKindUsageStatement && inv.Status == InvoiceStatusIssued &&
+			inv.OwnerType == ownerType && inv.OwnerID == ownerID && inv.PeriodStart.Equal(periodStart) {
+			cp := *inv
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *invoiceRepoStub) List(context.Context, InvoiceFilter, pagination.PaginationParams) ([]Invoice, *pagination.PaginationResult, error) {
+	panic("unexpected call")
+}
+
+func (r *invoiceRepoStub) Void(_ context.Context, id, actorID int64, reason string, at time.Time) (*Invoice, error) {
+	inv := r.find(id)
+	if inv == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	if inv.Status == InvoiceStatusVoid {
+		return nil, ErrInvoiceAlreadyVoid
+	}
+	inv.Status = InvoiceStatusVoid
+	inv.VoidReason = reason
+	inv.VoidedBy = &actorID
+	inv.VoidedAt = &at
+	cp := *inv
+	return &cp, nil
+}
+
+func (r *invoiceRepoStub) AggregateUsage(context.Context, InvoiceOwnerRef, time.Time, time.Time) ([]InvoiceUsageLine, error) {
+	return r.usage, nil
+}
+
+func (r *invoiceRepoStub) ListOwnersWithUsage(context.Context, time.Time, time.Time, int) ([]InvoiceOwnerRef, error) {
+	return nil, nil
+}
+
+func (r *invoiceRepoStub) ListOrdersWithoutReceipt(context.Context, time.Time, time.Time, int) ([]int64, error) {
+	return nil, nil
+}
+
+func newInvoiceTestClient(t *testing.T) *dbent.Client {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file:invoice_service?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec("PRAGMA foreign_keys = ON")
+	require.NoError(t, err)
+
+	drv := entsql.OpenDB(dialect.SQLite, db)
+	client := enttest.NewClient(t, enttest.WithOptions(dbent.Driver(drv)))
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func createInvoiceTestOrder(t *testing.T, client *dbent.Client, userID int64, status string, refund float64, paid bool) *dbent.PaymentOrder {
+	t.Helper()
+	create := client.PaymentOrder.Create().
+		SetUserID(userID).
+		SetUserEmail("buyer@example.com").
+		SetUserName("buyer").
+		SetAmount(100).
+		SetPayAmount(103).
+		SetFeeRate(3).
+		SetRechargeCode("INVOICE-TEST").
+		SetOutTradeNo("sub2_invoice_" + status).
+		SetPaymentType(payment.TypeAlipay).
+		SetPaymentTradeNo("").
+		SetOrderType(payment.OrderTypeBalance).
+		SetStatus(status).
+		SetRefundAmount(refund).
+		SetExpiresAt(time.Now().Add(time.Hour)).
+		SetClientIP("127.0.0.1").
+		SetSrcHost("api.example.com")
+	if paid {
+		create.SetPaidAt(time.Now().Add(-time.Hour))
+	}
+	order, err := create.Save(context.Background())
+	require.NoError(t, err)
+	return order
+}
+
+func newInvoiceTestService(t *testing.T) (*InvoiceService, *invoiceRepoStub, *dbent.Client, int64) {
+	t.Helper()
+	client := newInvoiceTestClient(t)
+	user, err := client.User.Create().
+		SetEmail("invoice@example.com").
+		SetPasswordHash("hash").
+		SetUsername("invoice-user").
+		Save(context.Background())
+	require.NoError(t, err)
+
+	repo := newInvoiceRepoStub()
+	orgRepo := &organizationRepoStub{
+		org: &Organization{ID: 7, Name: "Acme"},
+		members: map[int64]*OrganizationMember{
+			1: {OrganizationID: 7, UserID: 1, Role: OrganizationRoleOwner},
+			2: {OrganizationID: 7, UserID: 2, Role: OrganizationRoleMember},
+		},
+	}
+	userRepo := &mockUserRepo{getByIDUser: &User{ID: user.ID, Email: "invoice@example.com", Username: "invoice-user"}}
+	svc := NewInvoiceService(repo, orgRepo, userRepo, client, &config.Config{Invoice: config.InvoiceConfig{NumberPrefix: "ACME"}})
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+	return svc, repo, client, user.ID
+}
+
+func TestFormatInvoiceNumber(t *testing.T) {
+	require.Equal(t, "INV-2026-000042", FormatInvoiceNumber("INV", 2026, 42))
+	require.Equal(t, "A-2025-1234567", FormatInvoiceNumber("A", 2025, 1234567))
+}
+
+func TestParseInvoiceMonth(t *testing.T) {
+	start, end, err := ParseInvoiceMonth("2026-02")
+	require.NoError(t, err)
+	require.Equal(t, 2026, start.Year())
+	require.Equal(t, time.February, start.Month())
+	require.Equal(t, start.AddDate(0, 1, 0), end)
+
+	_, _, err = ParseInvoiceMonth("2026/02")
+	require.ErrorIs(t, err, ErrInvoicePeriodInvalid)
+}
+
+func TestInvoiceAuthorizeOwner(t *testing.T) {
+	svc, _, _, _ := newInvoiceTestService(t)
+	ctx := context.Background()
+	org := InvoiceOwnerRef{OwnerType: InvoiceOwnerOrganization, OwnerID: 7}
+
+	require.NoError(t, svc.AuthorizeOwner(ctx, 5, InvoiceOwnerRef{OwnerType: InvoiceOwnerUser, OwnerID: 5}))
+	require.ErrorIs(t, svc.AuthorizeOwner(ctx, 5, InvoiceOwnerRef{OwnerType: InvoiceOwnerUser, OwnerID: 6}), ErrInvoiceNotFound)
+	require.NoError(t, svc.AuthorizeOwner(ctx, 1, org))
+	require.ErrorIs(t, svc.AuthorizeOwner(ctx, 2, org), ErrOrganizationForbidden)
+	require.ErrorIs(t, svc.AuthorizeOwner(ctx, 3, org), ErrOrganizationNotFound)
+	require.ErrorIs(t, svc.AuthorizeOwner(ctx, 1, InvoiceOwnerRef{OwnerType: "team", OwnerID: 7}), ErrInvoiceInvalidOwner)
+}
+
+func TestInvoiceUpdateBillingProfileValidatesLength(t *testing.T) {
+	svc, _, _, _ := newInvoiceTestService(t)
+	ctx := context.Background()
+
+	profile, err := svc.UpdateBillingProfile(ctx, &BillingProfile{OwnerType: InvoiceOwnerUser, OwnerID: 5, CompanyName: "  Acme Ltd  "})
+	require.NoError(t, err)
+	require.Equal(t, "Acme Ltd", profile.CompanyName)
+
+	long := make([]rune, invoiceMaxProfileFieldLength+1)
+	for i := range long {
+		long[i] = '票'
+	}
+	_, err = svc.UpdateBillingProfile(ctx, &BillingProfile{OwnerType: InvoiceOwnerUser, OwnerID: 5, TaxID: string(long)})
+	require.ErrorIs(t, err, ErrBillingProfileFieldTooLong)
+}
+
+func TestInvoiceIssueReceiptIsIdempotentAndSnapshotsProfile(t *testing.T) {
+	svc, repo, client, userID := newInvoiceTestService(t)
+	ctx := context.Background()
+	order := createInvoiceTestOrder(t, client, userID, OrderStatusCompleted, 0, true)
+
+	_, err := svc.UpdateBillingProfile(ctx, &BillingProfile{OwnerType: InvoiceOwnerUser, OwnerID: userID, CompanyName: "Acme Ltd", TaxID: "TAX-1"})
+	require.NoError(t, err)
+
+	inv, err := svc.IssueReceiptForUser(ctx, userID, order.ID)
+	require.NoError(t, err)
+	require.Equal(t, "ACME-2026-000001", inv.Number)
+	require.Equal(t, InvoiceKindReceipt, inv.Kind)
+	require.Equal(t, 103.0, inv.Total)
+	require.Len(t, inv.LineItems, 1)
+	require.Contains(t, inv.LineItems[0].Description, "Balance top-up: 100.00 USD")
+	require.Equal(t, "Acme Ltd", inv.BillTo.CompanyName)
+	require.Equal(t, "buyer@example.com", inv.BillTo.Email)
+
+	again, err := svc.IssueReceiptForUser(ctx, userID, order.ID)
+	require.NoError(t, err)
+	require.Equal(t, inv.ID, again.ID)
+	require.Len(t, repo.invoices, 1)
+
+	_, err = svc.IssueReceiptForUser(ctx, userID+1, order.ID)
+	require.ErrorIs(t, err, errInvoicePaymentOrderNotFound)
+}
+
+func TestInvoiceReceiptRequiresPaidOrderAndListsRefund(t *testing.T) {
+	svc, _, client, userID := newInvoiceTestService(t)
+	ctx := context.Background()
+
+	unpaid := createInvoiceTestOrder(t, client, userID, OrderStatusPending, 0, false)
+	_, err := svc.IssueReceiptForUser(ctx, userID, unpaid.ID)
+	require.ErrorIs(t, err, ErrInvoiceOrderNotPaid)
+
+	refunded := createInvoiceTestOrder(t, client, userID, OrderStatusPartiallyRefunded, 50, true)
+	inv, err := svc.AdminIssueReceipt(ctx, 99, refunded.ID)
+	require.NoError(t, err)
+	require.Len(t, inv.LineItems, 2)
+	require.Equal(t, -51.5, inv.LineItems[1].Amount)
+	require.Equal(t, 103.0, inv.Subtotal)
+	require.Equal(t, 51.5, inv.Total)
+	require.Equal(t, int64(99), *inv.IssuedBy)
+}
+
+func TestInvoiceIssueStatementTotalsAndPeriodCheck(t *testing.T) {
+	svc, repo, _, _ := newInvoiceTestService(t)
+	ctx := context.Background()
+	repo.usage = []InvoiceUsageLine{
+		{Model: "claude-sonnet-4", Requests: 3, InputTokens: 1000, OutputTokens: 200, ActualCost: 0.1},
+		{Model: "gpt-5", Requests: 2, InputTokens: 10, OutputTokens: 20, ActualCost: 0.2},
+	}
+	org := InvoiceOwnerRef{OwnerType: InvoiceOwnerOrganization, OwnerID: 7}
+
+	inv, err := svc.IssueStatementForUser(ctx, 1, org, "2026-02")
+	require.NoError(t, err)
+	require.Equal(t, InvoiceKindUsageStatement, inv.Kind)
+	require.Equal(t, 0.3, inv.Total)
+	require.Equal(t, "Acme", inv.BillTo.CompanyName)
+	require.Len(t, inv.LineItems, 2)
+
+	again, err := svc.IssueStatementForUser(ctx, 1, org, "2026-02")
+	require.NoError(t, err)
+	require.Equal(t, inv.ID, again.ID)
+
+	// 当月尚未结束，不能开具对账单
+	_, err = svc.IssueStatementForUser(ctx, 1, org, "2026-03")
+	require.ErrorIs(t, err, ErrInvoicePeriodInvalid)
+
+	_, err = svc.IssueStatementForUser(ctx, 2, org, "2026-02")
+	require.ErrorIs(t, err, ErrOrganizationForbidden)
+}
+
+func TestInvoiceVoidAndReissue(t *testing.T) {
+	svc, repo, client, userID := newInvoiceTestService(t)
+	ctx := context.Background()
+	order := createInvoiceTestOrder(t, client, userID, OrderStatusCompleted, 0, true)
+
+	original, err := svc.IssueReceiptForUser(ctx, userID, order.ID)
+	require.NoError(t, err)
+
+	_, err = svc.Void(ctx, 99, original.ID, "  ")
+	require.ErrorIs(t, err, ErrInvoiceVoidReasonRequired)
+
+	_, err = svc.UpdateBillingProfile(ctx, &BillingProfile{OwnerType: InvoiceOwnerUser, OwnerID: userID, CompanyName: "New Name"})
+	require.NoError(t, err)
+
+	replacement, err := svc.Reissue(ctx, 99, original.ID, "wrong company name")
+	require.NoError(t, err)
+	require.NotEqual(t, original.Number, replacement.Number)
+	require.Equal(t, original.ID, *replacement.ReplacesInvoiceID)
+	require.Equal(t, original.Number, replacement.ReplacesNumber)
+	require.Equal(t, "New Name", replacement.BillTo.CompanyName)
+
+	old, err := repo.GetByID(ctx, original.ID)
+	require.NoError(t, err)
+	require.True(t, old.IsVoid())
+	require.Equal(t, "wrong company name", old.VoidReason)
+
+	_, err = svc.Void(ctx, 99, original.ID, "again")
+	require.ErrorIs(t, err, ErrInvoiceAlreadyVoid)
+
+	live, err := repo.GetLiveReceipt(ctx, order.ID)
+	require.NoError(t, err)
+	require.Equal(t, replacement.ID, live.ID)
+}
+
+func TestInvoiceRender(t *testing.T) {
+	svc, _, client, userID := newInvoiceTestService(t)
+	ctx := context.Background()
+	order := createInvoiceTestOrder(t, client, userID, OrderStatusCompleted, 0, true)
+	_, err := svc.UpdateBillingProfile(ctx, &BillingProfile{OwnerType: InvoiceOwnerUser, OwnerID: userID, CompanyName: "<Acme 公司>"})
+	require.NoError(t, err)
+	inv, err := svc.IssueReceiptForUser(ctx, userID, order.ID)
+	require.NoError(t, err)
+
+	html, err := svc.Render(inv, InvoiceFormatHTML)
+	require.NoError(t, err)
+	require.Equal(t, inv.Number+".html", html.Filename)
+	require.Contains(t, string(html.Data), inv.Number)
+	require.Contains(t, string(html.Data), "&lt;Acme 公司&gt;")
+
+	pdf, err := svc.Render(inv, InvoiceFormatPDF)
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", pdf.ContentType)
+	require.True(t, bytes.HasPrefix(pdf.Data, []byte("%PDF-")))
+
+	_, err = svc.Render(inv, "docx")
+	require.ErrorIs(t, err, ErrInvoiceInvalidFormat)
+}
@@ -33,6 +33,8 @@ const (
 	NotificationEmailEventCyberPolicyNotice           = "content_moderation.cyber_policy_notice"
 	NotificationEmailEventOpsAlert                    = "ops.alert"
 	NotificationEmailEventOpsScheduledReport          = "ops.scheduled_report"
+	NotificationEmailEventOrganizationInvitation      = "organization.invitation"
+	NotificationEmailEventBudgetThreshold             = "budget.threshold"
 
 	notificationEmailTemplateKeyPrefix    = "notification_email_template:"
 	notificationEmailPreferenceKeyPrefix  = "notification_email_preference:"
@@ -941,6 +943,19 @@ func notificationEmailSampleVariables(locale string) map[string]string {
 			"report_start_time":   "2026-07-18T01:00:26Z",
 			"report_end_time":     "2026-07-19T01:00:26Z",
 			"report_html":         "<h2>日报</h2><p>请求量：2,374</p>",
+			"organization_name":   "研发团队",
+			"inviter_name":        "李四",
+			"invitation_role":     "member",
+			"invite_url":          "https://example.com/organizations/invitations/accept?token=preview",
+			"expires_in_hours":    "168",
+			"budget_scope":        "API key dev (#12)",
+			"budget_model":        "*",
+			"budget_period":       "monthly",
+			"budget_enforcement":  "hard",
+			"budget_percent":      "80",
+			"budget_used":         "80.00",
+			"budget_limit":        "100.00",
+			"period_resets_at":    "2026-07-01 00:00",
 		}
 		addNotificationEmailOpsSummarySampleVariables(variables)
 		return variables
@@ -989,6 +1004,19 @@ func notificationEmailSampleVariables(locale string) map[string]string {
 		"report_start_time":   "2026-07-18T01:00:26Z",
 		"report_end_time":     "2026-07-19T01:00:26Z",
 		"report_html":         "<h2>Daily summary</h2><p>Requests: 2,374</p>",
+		"organization_name":   "Platform team",
+		"inviter_name":        "Sam",
+		"invitation_role":     "member",
+		"invite_url":          "https://example.com/organizations/invitations/accept?token=preview",
+		"expires_in_hours":    "168",
+		"budget_scope":        "API key dev (#12)",
+		"budget_model":        "*",
+		"budget_period":       "monthly",
+		"budget_enforcement":  "hard",
+		"budget_percent":      "80",
+		"budget_used":         "80.00",
+		"budget_limit":        "100.00",
+		"period_resets_at":    "2026-07-01 00:00",
 	}
 	addNotificationEmailOpsSummarySampleVariables(variables)
 	return variables
@@ -1034,6 +1062,8 @@ var notificationEmailEventOrder = []string{
 	NotificationEmailEventCyberPolicyNotice,
 	NotificationEmailEventOpsAlert,
 	NotificationEmailEventOpsScheduledReport,
+	NotificationEmailEventOrganizationInvitation,
+	NotificationEmailEventBudgetThreshold,
 }
 
 var notificationEmailEventDefinitions = map[string]NotificationEmailEventInfo{
@@ -1152,6 +1182,24 @@ var notificationEmailEventDefinitions = map[string]NotificationEmailEventInfo{
 			append(append([]string{}, notificationEmailOpsSummaryPlaceholders...), "report_detail_display", "report_html")...,
 		),
 	},
+	NotificationEmailEventOrganizationInvitation: {
+		Event:       NotificationEmailEventOrganizationInvitation,
+		Label:       "Organization invitation",
+		Description: "Sent when an organization owner or admin invites someone to join the organization.",
+		Category:    "organization",
+		Optional:    false,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...),
+			"organization_name", "inviter_name", "invitation_role", "invite_url", "expires_in_hours"),
+	},
+	NotificationEmailEventBudgetThreshold: {
+		Event:       NotificationEmailEventBudgetThreshold,
+		Label:       "Budget threshold",
+		Description: "Sent when spend reaches 50%, 80% or 100% of a user, API key or group budget in the current period.",
+		Category:    "billing",
+		Optional:    true,
+		Placeholders: append(append([]string{}, notificationEmailCommonPlaceholders...),
+			"budget_scope", "budget_model", "budget_period", "budget_enforcement", "budget_percent", "budget_used", "budget_limit", "period_resets_at"),
+	},
 }
 
 var notificationEmailOfficialTemplates = map[string]map[string]notificationEmailOfficialTemplate{
@@ -1436,6 +1484,56 @@ var notificationEmailOfficialTemplates = map[string]map[string]notificationEmail
 			HTML:    notificationEmailOpsScheduledReportTemplate(notificationEmailLocaleChinese),
 		},
 	},
+	NotificationEmailEventOrganizationInvitation: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] You're invited to join {{organization_name}}",
+			HTML: notificationEmailCard("#0891b2", "Organization invitation", `
+<p>Hello {{recipient_name}},</p>
+<p><strong>{{inviter_name}}</strong> invited you to join the organization <strong>{{organization_name}}</strong> as <strong>{{invitation_role}}</strong>.</p>
+<p>Members use the organization's shared balance through organization API keys.</p>
+<p><a class="button" href="{{invite_url}}">Accept invitation</a></p>
+<p>This invitation expires in <strong>{{expires_in_hours}}</strong> hours. Sign in with this email address to accept it.</p>
+<p class="muted">If the button does not work, copy this link into your browser:<br>{{invite_url}}</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] 邀请您加入 {{organization_name}}",
+			HTML: notificationEmailCard("#0891b2", "组织邀请", `
+<p>{{recipient_name}}，您好：</p>
+<p><strong>{{inviter_name}}</strong> 邀请您以 <strong>{{invitation_role}}</strong> 身份加入组织 <strong>{{organization_name}}</strong>。</p>
+<p>成员可通过组织 API Key 使用组织的共享余额。</p>
+<p><a class="button" href="{{invite_url}}">接受邀请</a></p>
+<p>邀请将在 <strong>{{expires_in_hours}}</strong> 小时后失效，请使用本邮箱对应的账号登录后接受。</p>
+<p class="muted">如果按钮无法点击，请复制以下链接到浏览器中打开：<br>{{invite_url}}</p>`),
+		},
+	},
+	NotificationEmailEventBudgetThreshold: {
+		notificationEmailDefaultLocale: {
+			Subject: "[{{site_name}}] Budget {{budget_percent}}% used: {{budget_scope}}",
+			HTML: notificationEmailCard("#d97706", "Budget threshold reached", `
+<p>Hello {{recipient_name}},</p>
+<p>Spend for <strong>{{budget_scope}}</strong> has reached <strong>{{budget_percent}}%</strong> of its {{budget_period}} budget.</p>
+<table style="width:100%;border-collapse:collapse;">
+  <tr><td>Used / Limit</td><td>${{budget_used}} / ${{budget_limit}}</td></tr>
+  <tr><td>Model</td><td>{{budget_model}}</td></tr>
+  <tr><td>Enforcement</td><td>{{budget_enforcement}}</td></tr>
+  <tr><td>Period resets at</td><td>{{period_resets_at}}</td></tr>
+</table>
+<p>When a hard budget is used up, requests are rejected until the period resets. Soft budgets only send notifications.</p>`),
+		},
+		notificationEmailLocaleChinese: {
+			Subject: "[{{site_name}}] 预算已使用 {{budget_percent}}%：{{budget_scope}}",
+			HTML: notificationEmailCard("#d97706", "预算用量提醒", `
+<p>{{recipient_name}}，您好：</p>
+<p><strong>{{budget_scope}}</strong> 的 {{budget_period}} 预算已使用 <strong>{{budget_percent}}%</strong>。</p>
+<table style="width:100%;border-collapse:collapse;">
+  <tr><td>已用 / 上限</td><td>${{budget_used}} / ${{budget_limit}}</td></tr>
+  <tr><td>模型</td><td>{{budget_model}}</td></tr>
+  <tr><td>执行方式</td><td>{{budget_enforcement}}</td></tr>
+  <tr><td>周期重置时间</td><td>{{period_resets_at}}</td></tr>
+</table>
+<p>hard 预算用尽后请求将被拒绝，直到周期重置；soft 预算仅发送提醒。</p>`),
+		},
+	},
 }
 
 func notificationEmailOpsScheduledReportTemplate(locale string) string {
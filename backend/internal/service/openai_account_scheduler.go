@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -181,117 +182,10 @@ func (m *openAIAccountSchedulerMetrics) recordSwitch() {
 	m.accountSwitchTotal.Add(1)
 }
 
-type openAIAccountRuntimeStats struct {
-	accounts     sync.Map
-	accountCount atomic.Int64
-}
-
-type openAIAccountRuntimeStat struct {
-	errorRateEWMABits atomic.Uint64
-	ttftEWMABits      atomic.Uint64
-}
-
-func newOpenAIAccountRuntimeStats() *openAIAccountRuntimeStats {
-	return &openAIAccountRuntimeStats{}
-}
-
-func (s *openAIAccountRuntimeStats) loadOrCreate(accountID int64) *openAIAccountRuntimeStat {
-	if value, ok := s.accounts.Load(accountID); ok {
-		stat, _ := value.(*openAIAccountRuntimeStat)
-		if stat != nil {
-			return stat
-		}
-	}
-
-	stat := &openAIAccountRuntimeStat{}
-	stat.ttftEWMABits.Store(math.Float64bits(math.NaN()))
-	actual, loaded := s.accounts.LoadOrStore(accountID, stat)
-	if !loaded {
-		s.accountCount.Add(1)
-		return stat
-	}
-	existing, _ := actual.(*openAIAccountRuntimeStat)
-	if existing != nil {
-		return existing
-	}
-	return stat
-}
-
-func updateEWMAAtomic(target *atomic.Uint64, sample float64, alpha float64) {
-	for {
-		oldBits := target.Load()
-		oldValue := math.Float64frombits(oldBits)
-		newValue := alpha*sample + (1-alpha)*oldValue
-		if target.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
-			return
-		}
-	}
-}
-
-func (s *openAIAccountRuntimeStats) report(accountID int64, success bool, firstTokenMs *int) {
-	if s == nil || accountID <= 0 {
-		return
-	}
-	const alpha = 0.2
-	stat := s.loadOrCreate(accountID)
-
-	errorSample := 1.0
-	if success {
-		errorSample = 0.0
-	}
-	updateEWMAAtomic(&stat.errorRateEWMABits, errorSample, alpha)
-
-	if firstTokenMs != nil && *firstTokenMs > 0 {
-		ttft := float64(*firstTokenMs)
-		ttftBits := math.Float64bits(ttft)
-		for {
-			oldBits := stat.ttftEWMABits.Load()
-			oldValue := math.Float64frombits(oldBits)
-			if math.IsNaN(oldValue) {
-				if stat.ttftEWMABits.CompareAndSwap(oldBits, ttftBits) {
-					break
-				}
-				continue
-			}
-			newValue := alpha*ttft + (1-alpha)*oldValue
-			if stat.ttftEWMABits.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
-				break
-			}
-		}
-	}
-}
-
-func (s *openAIAccountRuntimeStats) snapshot(accountID int64) (errorRate float64, ttft float64, hasTTFT bool) {
-	if s == nil || accountID <= 0 {
-		return 0, 0, false
-	}
-	value, ok := s.accounts.Load(accountID)
-	if !ok {
-		return 0, 0, false
-	}
-	stat, _ := value.(*openAIAccountRuntimeStat)
-	if stat == nil {
-		return 0, 0, false
-	}
-	errorRate = clamp01(math.Float64frombits(stat.errorRateEWMABits.Load()))
-	ttftValue := math.Float64frombits(stat.ttftEWMABits.Load())
-	if math.IsNaN(ttftValue) {
-		return errorRate, 0, false
-	}
-	return errorRate, ttftValue, true
-}
-
-func (s *openAIAccountRuntimeStats) size() int {
-	if s == nil {
-		return 0
-	}
-	return int(s.accountCount.Load())
-}
-
 type defaultOpenAIAccountScheduler struct {
 	service                *OpenAIGatewayService
 	metrics                openAIAccountSchedulerMetrics
-	stats                  *openAIAccountRuntimeStats
+	stats                  *AccountRuntimeStats
 	grokFreeQuotaGateCache sync.Map // key: int64(accountID), value: grokFreeQuotaGateCacheEntry
 }
 
@@ -362,9 +256,9 @@ type openAIStickyEscapeConfig struct {
 	errorRate float64
 }
 
-func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *openAIAccountRuntimeStats) OpenAIAccountScheduler {
+func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *AccountRuntimeStats) OpenAIAccountScheduler {
 	if stats == nil {
-		stats = newOpenAIAccountRuntimeStats()
+		stats = NewAccountRuntimeStats()
 	}
 	return &defaultOpenAIAccountScheduler{
 		service: service,
@@ -2064,7 +1958,7 @@ func (s *OpenAIGatewayService) getOpenAIAccountScheduler(ctx context.Context) Op
 	}
 	s.openaiSchedulerOnce.Do(func() {
 		if s.openaiAccountStats == nil {
-			s.openaiAccountStats = newOpenAIAccountRuntimeStats()
+			s.openaiAccountStats = NewAccountRuntimeStats()
 		}
 		if s.openaiScheduler == nil {
 			s.openaiScheduler = newDefaultOpenAIAccountScheduler(s, s.openaiAccountStats)
@@ -2073,6 +1967,34 @@ func (s *OpenAIGatewayService) getOpenAIAccountScheduler(ctx context.Context) Op
 	return s.openaiScheduler
 }
 
+// SetAccountSchedulers 注入进程内共享的账号调度策略集合；高级调度器与分组策略共用同一份运行时统计。
+// 须在处理请求前调用。
+func (s *OpenAIGatewayService) SetAccountSchedulers(schedulers *AccountSchedulers) {
+	s.accountSchedulers = schedulers
+	if stats := schedulers.Stats(); stats != nil {
+		s.openaiAccountStats = stats
+	}
+}
+
+// resolveOpenAIGroupAccountScheduler 返回被调度分组配置的账号调度策略。
+// 优先复用认证中间件放入 ctx 的分组，ID 不一致时回源快照读取；读取失败按 default 处理。
+func (s *OpenAIGatewayService) resolveOpenAIGroupAccountScheduler(ctx context.Context, groupID *int64) AccountScheduler {
+	if s == nil || s.accountSchedulers == nil || groupID == nil || *groupID <= 0 {
+		return defaultAccountScheduler{}
+	}
+	if ctxGroup, ok := ctx.Value(ctxkey.Group).(*Group); ok && IsGroupContextValid(ctxGroup) && ctxGroup.ID == *groupID {
+		return s.accountSchedulers.ForGroup(ctxGroup)
+	}
+	if s.schedulerSnapshot == nil {
+		return s.accountSchedulers.ForGroup(nil)
+	}
+	group, err := s.schedulerSnapshot.GetGroupByIDLite(ctx, *groupID)
+	if err != nil {
+		return s.accountSchedulers.ForGroup(nil)
+	}
+	return s.accountSchedulers.ForGroup(group)
+}
+
 func resetOpenAIAdvancedSchedulerSettingCacheForTest() {
 	openAIAdvancedSchedulerSettingCache = atomic.Value{}
 	openAIAdvancedSchedulerSettingSF = singleflight.Group{}
@@ -2241,13 +2163,17 @@ func (s *OpenAIGatewayService) selectAccountWithSchedulerOnce(
 		guardianParentAccountID = s.resolveOpenAIGuardianParentAccountID(ctx, groupID)
 	}
 	scheduler := s.getOpenAIAccountScheduler(ctx)
+	// 分组配置了非默认账号调度策略时绕过高级调度器，由负载均衡路径按分组策略排序
+	if scheduler != nil && !IsDefaultAccountScheduler(s.resolveOpenAIGroupAccountScheduler(ctx, groupID)) {
+		scheduler = nil
+	}
 	if scheduler == nil {
 		decision.Layer = openAIAccountScheduleLayerLoadBalance
 		if guardianParentAccountID > 0 {
 			if s.checkChannelPricingRestriction(ctx, groupID, requestedModel) {
 				return nil, decision, fmt.Errorf("%w supporting model: %s (channel pricing restriction)", ErrNoAvailableAccounts, requestedModel)
 			}
-			fallbackScheduler := &defaultOpenAIAccountScheduler{service: s, stats: newOpenAIAccountRuntimeStats()}
+			fallbackScheduler := &defaultOpenAIAccountScheduler{service: s, stats: NewAccountRuntimeStats()}
 			selection, _, err := fallbackScheduler.selectBySessionHash(ctx, OpenAIAccountScheduleRequest{
 				GroupID:                 groupID,
 				Platform:                platform,
@@ -2432,6 +2358,8 @@ func (s *OpenAIGatewayService) ReportOpenAIAccountScheduleResult(account *Accoun
 	}
 	scheduler := s.getOpenAIAccountScheduler(context.Background())
 	if scheduler == nil {
+		// 高级调度器未启用时仍向共享统计上报，供分组调度策略使用
+		s.accountSchedulers.ReportResult(accountID, success, firstTokenMs)
 		return healthTripped
 	}
 	scheduler.ReportResult(accountID, success, firstTokenMs)
@@ -2493,7 +2421,7 @@ func (s *OpenAIGatewayService) openAIWSLBTopKForRequest(ctx context.Context) int
 
 func (s *OpenAIGatewayService) openAIStickyEscapeConfig() openAIStickyEscapeConfig {
 	if s != nil && s.cfg != nil {
-		cfg := s.cfg.Gateway.OpenAIScheduler
+		cfg := s.cfg.Live().Gateway.OpenAIScheduler
 		enabled := cfg.StickyEscapeEnabled
 		if !enabled && cfg.StickyEscapeTTFTMs == 0 && cfg.StickyEscapeErrorRate == 0 {
 			enabled = true
@@ -2339,7 +2339,7 @@ func TestOpenAIGatewayService_SelectAccountWithScheduler_SessionStickyEscapeByTT
 		cfg:                cfg,
 		rateLimitService:   newOpenAIAdvancedSchedulerRateLimitService("true"),
 		concurrencyService: NewConcurrencyService(concurrencyCache),
-		openaiAccountStats: newOpenAIAccountRuntimeStats(),
+		openaiAccountStats: NewAccountRuntimeStats(),
 	}
 	fastTTFT := 14999
 	svc.openaiAccountStats.report(21101, true, &fastTTFT)
@@ -2393,7 +2393,7 @@ func TestOpenAIGatewayService_SelectAccountWithScheduler_SessionStickyEscapeByEr
 		cfg:                cfg,
 		rateLimitService:   newOpenAIAdvancedSchedulerRateLimitService("true"),
 		concurrencyService: NewConcurrencyService(schedulerTestConcurrencyCache{acquireResults: map[int64]bool{21202: true}}),
-		openaiAccountStats: newOpenAIAccountRuntimeStats(),
+		openaiAccountStats: NewAccountRuntimeStats(),
 	}
 	for i := 0; i < 3; i++ {
 		svc.openaiAccountStats.report(21201, false, nil)
@@ -2492,7 +2492,7 @@ func TestOpenAIGatewayService_SelectAccountWithScheduler_SessionStickyEscapeDisa
 		cfg:                cfg,
 		rateLimitService:   newOpenAIAdvancedSchedulerRateLimitService("true"),
 		concurrencyService: NewConcurrencyService(concurrencyCache),
-		openaiAccountStats: newOpenAIAccountRuntimeStats(),
+		openaiAccountStats: NewAccountRuntimeStats(),
 	}
 	slowTTFT := 20000
 	svc.openaiAccountStats.report(21401, true, &slowTTFT)
@@ -2746,7 +2746,7 @@ func TestReportOpenAIAccountScheduleResult_SuccessClearsModelTransientState(t *t
 }
 
 func TestDefaultOpenAIAccountScheduler_ShouldEscapeStickyAccount_ThresholdBoundary(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := NewAccountRuntimeStats()
 	accountID := int64(21501)
 	ttft := 15000
 	stats.report(accountID, true, &ttft)
@@ -3217,7 +3217,7 @@ func intPtrForTest(v int) *int {
 }
 
 func TestOpenAIAccountRuntimeStats_ReportAndSnapshot(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := NewAccountRuntimeStats()
 	stats.report(1001, true, nil)
 	firstTTFT := 100
 	stats.report(1001, false, &firstTTFT)
@@ -3232,7 +3232,7 @@ func TestOpenAIAccountRuntimeStats_ReportAndSnapshot(t *testing.T) {
 }
 
 func TestOpenAIAccountRuntimeStats_ReportConcurrent(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := NewAccountRuntimeStats()
 
 	const (
 		accountCount = 4
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+)
+
+// OpenAI 兼容 Batch API 的文件用途。
+const (
+	OpenAIFilePurposeBatch       = "batch"
+	OpenAIFilePurposeBatchOutput = "batch_output"
+)
+
+// 批次状态，取值与 OpenAI Batch API 保持一致。
+// 创建时同步完成校验，因此批次直接从 in_progress 开始；failed 仅用于结算无法完成的批次。
+const (
+	OpenAIBatchStatusValidating = "validating"
+	OpenAIBatchStatusInProgress = "in_progress"
+	OpenAIBatchStatusFinalizing = "finalizing"
+	OpenAIBatchStatusCompleted  = "completed"
+	OpenAIBatchStatusFailed     = "failed"
+	OpenAIBatchStatusExpired    = "expired"
+	OpenAIBatchStatusCancelling = "cancelling"
+	OpenAIBatchStatusCancelled  = "cancelled"
+)
+
+// 批次内单行请求的状态。
+const (
+	OpenAIBatchItemStatusPending   = "pending"
+	OpenAIBatchItemStatusRunning   = "running"
+	OpenAIBatchItemStatusSucceeded = "succeeded"
+	OpenAIBatchItemStatusFailed    = "failed"
+)
+
+// 未执行即终止的行写入 error 文件时使用的错误码。
+const (
+	OpenAIBatchItemErrorCancelled     = "batch_cancelled"
+	OpenAIBatchItemErrorExpired       = "batch_expired"
+	OpenAIBatchItemErrorHoldExhausted = "batch_hold_exhausted"
+	OpenAIBatchItemErrorExecution     = "batch_execution_failed"
+)
+
+const (
+	openAIBatchCompletionWindow = "24h"
+	openAIBatchMaxMetadataPairs = 16
+	openAIBatchMaxCustomIDLen   = 512
+	openAIBatchHoldPayloadScope = "openai_batch:"
+	// openAIBatchOverageRequestPrefix 结算时超出冻结额部分的扣费请求 ID 前缀，保证重试幂等。
+	openAIBatchOverageRequestPrefix = "openai_batch_overage:"
+)
+
+// OpenAIBatchEndpoints 批次可调用的网关端点；请求按 API Key 分组平台路由，与直接调用一致。
+var OpenAIBatchEndpoints = []string{
+	"/v1/chat/completions",
+	"/v1/responses",
+	"/v1/messages",
+}
+
+var (
+	ErrOpenAIBatchDisabled          = infraerrors.NotFound("OPENAI_BATCH_DISABLED", "batch API is disabled")
+	ErrOpenAIBatchNotAllowed        = infraerrors.Forbidden("OPENAI_BATCH_NOT_ALLOWED", "batch API is not enabled for this API key's group")
+	ErrOpenAIBatchNotFound          = infraerrors.NotFound("OPENAI_BATCH_NOT_FOUND", "batch not found")
+	ErrOpenAIBatchNotCancellable    = infraerrors.New(http.StatusConflict, "OPENAI_BATCH_NOT_CANCELLABLE", "batch can no longer be cancelled")
+	ErrOpenAIBatchInvalidEndpoint   = infraerrors.BadRequest("OPENAI_BATCH_INVALID_ENDPOINT", "endpoint must be one of /v1/chat/completions, /v1/responses, /v1/messages")
+	ErrOpenAIBatchInvalidWindow     = infraerrors.BadRequest("OPENAI_BATCH_INVALID_COMPLETION_WINDOW", "completion_window must be 24h")
+	ErrOpenAIBatchInvalidMetadata   = infraerrors.BadRequest("OPENAI_BATCH_INVALID_METADATA", "metadata supports at most 16 string pairs")
+	ErrOpenAIBatchInsufficientFunds = infraerrors.New(http.StatusPaymentRequired, "OPENAI_BATCH_INSUFFICIENT_BALANCE", "insufficient balance for batch hold")
+	ErrOpenAIFileNotFound           = infraerrors.NotFound("OPENAI_FILE_NOT_FOUND", "file not found")
+	ErrOpenAIFileInvalidPurpose     = infraerrors.BadRequest("OPENAI_FILE_INVALID_PURPOSE", "purpose must be batch")
+	ErrOpenAIFileTooLarge           = infraerrors.New(http.StatusRequestEntityTooLarge, "OPENAI_FILE_TOO_LARGE", "file exceeds the maximum allowed size")
+	ErrOpenAIFileEmpty              = infraerrors.BadRequest("OPENAI_FILE_EMPTY", "file is empty")
+	ErrOpenAIFileInUse              = infraerrors.New(http.StatusConflict, "OPENAI_FILE_IN_USE", "file is referenced by an active batch")
+)
+
+// OpenAIBatchOwner 批次/文件的归属：创建时使用的 API Key 及其用户、分组。
+type OpenAIBatchOwner struct {
+	UserID         int64
+	APIKeyID       int64
+	GroupID        *int64
+	OrganizationID *int64
+	// ClientIP 创建请求经安全解析后的客户端 IP，批次执行时沿用。
+	ClientIP string
+}
+
+// OpenAIFile 上传的批次输入或生成的结果文件。Content 仅在创建和下载时加载。
+type OpenAIFile struct {
+	ID        string
+	UserID    int64
+	APIKeyID  int64
+	Purpose   string
+	Filename  string
+	Bytes     int64
+	Content   []byte
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+}
+
+// OpenAIBatch 批次记录。HoldAmount 在创建时按原价估算 × HoldMultiplier 冻结，
+// SettleAmount 在首次结算时固定，之后的重试按同一金额幂等 capture。
+type OpenAIBatch struct {
+	ID                 string
+	UserID             int64
+	APIKeyID           int64
+	GroupID            int64
+	Endpoint           string
+	InputFileID        string
+	OutputFileID       *string
+	ErrorFileID        *string
+	Status             string
+	CompletionWindow   string
+	Metadata           map[string]string
+	ClientIP           string
+	RequestTotal       int
+	RequestCompleted   int
+	RequestFailed      int
+	HoldAmount         float64
+	ActualCost         float64
+	SettleAmount       *float64
+	DiscountMultiplier float64
+	HoldMultiplier     float64
+	LastError          string
+	CreatedAt          time.Time
+	InProgressAt       *time.Time
+	ExpiresAt          time.Time
+	FinalizingAt       *time.Time
+	CompletedAt        *time.Time
+	FailedAt           *time.Time
+	ExpiredAt          *time.Time
+	CancellingAt       *time.Time
+	CancelledAt        *time.Time
+}
+
+// IsTerminal 批次是否已结束（不再被 worker 领取）。
+func (b *OpenAIBatch) IsTerminal() bool {
+	if b == nil {
+		return true
+	}
+	switch b.Status {
+	case OpenAIBatchStatusCompleted, OpenAIBatchStatusFailed, OpenAIBatchStatusExpired, OpenAIBatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// OpenAIBatchItem 批次输入文件中的一行。
+type OpenAIBatchItem struct {
+	ID             int64
+	BatchID        string
+	LineIndex      int
+	CustomID       string
+	Method         string
+	URL            string
+	Body           []byte
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	ResponseBody   []byte
+	RequestID      string
+	ErrorCode      string
+	ErrorMessage   string
+	Cost           float64
+	BilledAt       *time.Time
+}
+
+// OpenAIBatchProgress 批次执行进度快照，由 worker 决定继续派发还是进入结算。
+type OpenAIBatchProgress struct {
+	Pending   int
+	Running   int
+	Succeeded int
+	Failed    int
+	Expired   int
+	// Unbilled 已成功但计费钩子尚未回写费用的行数（用量记录是异步的）。
+	Unbilled int
+	Cost     float64
+}
+
+// OpenAIBatchFinish 批次结算后的终态写入。
+type OpenAIBatchFinish struct {
+	Status     string
+	ActualCost float64
+	OutputFile *OpenAIFile
+	ErrorFile  *OpenAIFile
+	LastError  string
+}
+
+// OpenAIBatchRepository 批次与文件的持久化。
+type OpenAIBatchRepository interface {
+	CreateFile(ctx context.Context, file *OpenAIFile) error
+	GetFile(ctx context.Context, userID int64, fileID string) (*OpenAIFile, error)
+	GetFileContent(ctx context.Context, userID int64, fileID string) ([]byte, error)
+	ListFiles(ctx context.Context, userID int64, purpose, after string, limit int) ([]OpenAIFile, error)
+	// DeleteFile 软删除文件；被未结束批次引用的输入文件返回 ErrOpenAIFileInUse。
+	DeleteFile(ctx context.Context, userID int64, fileID string) error
+	DeleteExpiredFiles(ctx context.Context, before time.Time, limit int) (int64, error)
+
+	// CreateBatch 在同一事务中写入批次及全部行。
+	CreateBatch(ctx context.Context, batch *OpenAIBatch, items []OpenAIBatchItem) error
+	GetBatch(ctx context.Context, userID int64, batchID string) (*OpenAIBatch, error)
+	ListBatches(ctx context.Context, userID int64, after string, limit int) ([]OpenAIBatch, error)
+	// MarkBatchCancelling 将进行中的批次置为 cancelling；已结束的批次返回 ErrOpenAIBatchNotCancellable。
+	MarkBatchCancelling(ctx context.Context, userID int64, batchID string) (*OpenAIBatch, error)
+
+	// ClaimBatches 领取 lease 已过期的活跃批次（FOR UPDATE SKIP LOCKED）。
+	ClaimBatches(ctx context.Context, workerID string, limit int, lease time.Duration) ([]OpenAIBatch, error)
+	// ReleaseBatchClaim 释放 lease 并按行状态刷新请求计数。
+	ReleaseBatchClaim(ctx context.Context, batchID, workerID string) error
+	// ClaimPendingItems 将至多 limit 行置为 running；started_at 早于 staleBefore 的 running 行视为崩溃遗留一并重新领取。
+	ClaimPendingItems(ctx context.Context, batchID string, limit int, staleBefore time.Time) ([]OpenAIBatchItem, error)
+	CompleteItem(ctx context.Context, item *OpenAIBatchItem) error
+	// RequeueItem 将可重试的行放回 pending。
+	RequeueItem(ctx context.Context, itemID int64, errorMessage string) error
+	// FailUnfinishedItems 将 pending/running 行标记为失败，返回受影响行数。
+	FailUnfinishedItems(ctx context.Context, batchID, code, message string) (int, error)
+	// RecordItemCost 由计费钩子在扣费成功后回写单行费用。
+	RecordItemCost(ctx context.Context, itemID int64, requestID string, cost float64) error
+	GetBatchProgress(ctx context.Context, batchID string) (*OpenAIBatchProgress, error)
+	ListBatchItems(ctx context.Context, batchID string) ([]OpenAIBatchItem, error)
+	MarkBatchFinalizing(ctx context.Context, batchID string) (*OpenAIBatch, error)
+	// LockSettleAmount 首次调用写入结算金额，之后返回已固定的金额。
+	LockSettleAmount(ctx context.Context, batchID string, amount float64) (float64, error)
+	SetBatchError(ctx context.Context, batchID, message string) error
+	// FinishBatch 在同一事务中写入结果文件并把批次置为终态。
+	FinishBatch(ctx context.Context, batchID string, finish *OpenAIBatchFinish) error
+}
+
+type openAIBatchExecutionKey struct{}
+
+// OpenAIBatchCostRecorder 接收计费钩子回写的单行费用。
+type OpenAIBatchCostRecorder interface {
+	RecordItemCost(ctx context.Context, itemID int64, requestID string, cost float64) error
+}
+
+// OpenAIBatchExecution 标记一次由批次 worker 发起的进程内网关请求。
+// 该标记只能在进程内注入（外部请求无法伪造），用于：
+//   - 鉴权按 ClientIP（创建批次时的客户端 IP）校验 API Key IP 限制；
+//   - 鉴权/计费前置检查跳过余额校验（资金已在创建批次时冻结）；
+//   - usage 计费按 DiscountMultiplier 折扣，且不直接扣余额，由批次结算从冻结额中 capture。
+type OpenAIBatchExecution struct {
+	BatchID            string
+	ItemID             int64
+	ClientIP           string
+	DiscountMultiplier float64
+	Recorder           OpenAIBatchCostRecorder
+}
+
+// WithOpenAIBatchExecution 返回携带批次执行标记的 context。
+func WithOpenAIBatchExecution(ctx context.Context, exec *OpenAIBatchExecution) context.Context {
+	if ctx == nil || exec == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, openAIBatchExecutionKey{}, exec)
+}
+
+// OpenAIBatchExecutionFromContext 读取批次执行标记；非批次请求返回 nil。
+func OpenAIBatchExecutionFromContext(ctx context.Context) *OpenAIBatchExecution {
+	if ctx == nil {
+		return nil
+	}
+	exec, _ := ctx.Value(openAIBatchExecutionKey{}).(*OpenAIBatchExecution)
+	return exec
+}
+
+// applyPricing 对批次请求应用折扣：与分组倍率一样只作用于 ActualCost（TotalCost 仍是原价，
+// 账号额度按原价累计），复制一份明细避免修改调用方共享的对象；并标记余额已冻结，
+// 由 buildUsageBillingCommand 跳过直接扣余额。
+func (e *OpenAIBatchExecution) applyPricing(usageLog *UsageLog, p *postUsageBillingParams) {
+	if e == nil || p == nil || p.Cost == nil || p.IsSubscriptionBill {
+		return
+	}
+	discount := e.DiscountMultiplier
+	if discount < 0 {
+		discount = 0
+	}
+	cost := *p.Cost
+	cost.ActualCost = money.Mul(cost.ActualCost, discount, money.LedgerScale)
+	p.Cost = &cost
+	p.BalanceHeld = true
+	if usageLog != nil {
+		usageLog.ActualCost = cost.ActualCost
+		usageLog.RateMultiplier *= discount
+	}
+}
+
+func (e *OpenAIBatchExecution) recordCost(ctx context.Context, requestID string, breakdown *CostBreakdown) {
+	if e == nil || e.Recorder == nil || e.ItemID <= 0 {
+		return
+	}
+	cost := 0.0
+	if breakdown != nil {
+		cost = breakdown.ActualCost
+	}
+	if err := e.Recorder.RecordItemCost(ctx, e.ItemID, requestID, cost); err != nil {
+		// 行费用缺失时结算会少收这一行；记录 ALERT 供对账。
+		slog.Error("openai_batch.record_item_cost_failed",
+			"batch_id", e.BatchID, "item_id", e.ItemID, "request_id", requestID, "cost", cost, "error", err)
+	}
+}
+
+func openAIBatchHoldPayloadHash(batchID string) string {
+	return openAIBatchHoldPayloadScope + strings.TrimSpace(batchID)
+}
@@ -0,0 +1,732 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultBatchAPIDiscountMultiplier = 0.5
+	defaultBatchAPIHoldMultiplier     = 0.6
+
+	openAIBatchListDefaultLimit = 20
+	openAIBatchListMaxLimit     = 100
+	// openAIBatchEstimateBytesPerToken 估算冻结额时按 3 字节/token 折算输入（偏保守，CJK 约 3 字节/字）。
+	openAIBatchEstimateBytesPerToken = 3
+)
+
+// OpenAIFileObject OpenAI Files API 的文件对象。
+type OpenAIFileObject struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+}
+
+// OpenAIBatchRequestCounts 批次请求计数。
+type OpenAIBatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// OpenAIBatchErrorData 批次级错误（如结算失败）。
+type OpenAIBatchErrorData struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param"`
+	Line    *int    `json:"line"`
+}
+
+// OpenAIBatchErrors 批次级错误列表。
+type OpenAIBatchErrors struct {
+	Object string                 `json:"object"`
+	Data   []OpenAIBatchErrorData `json:"data"`
+}
+
+// OpenAIBatchObject OpenAI Batch API 的批次对象。
+type OpenAIBatchObject struct {
+	ID               string                   `json:"id"`
+	Object           string                   `json:"object"`
+	Endpoint         string                   `json:"endpoint"`
+	Errors           *OpenAIBatchErrors       `json:"errors"`
+	InputFileID      string                   `json:"input_file_id"`
+	CompletionWindow string                   `json:"completion_window"`
+	Status           string                   `json:"status"`
+	OutputFileID     *string                  `json:"output_file_id"`
+	ErrorFileID      *string                  `json:"error_file_id"`
+	CreatedAt        int64                    `json:"created_at"`
+	InProgressAt     *int64                   `json:"in_progress_at"`
+	ExpiresAt        *int64                   `json:"expires_at"`
+	FinalizingAt     *int64                   `json:"finalizing_at"`
+	CompletedAt      *int64                   `json:"completed_at"`
+	FailedAt         *int64                   `json:"failed_at"`
+	ExpiredAt        *int64                   `json:"expired_at"`
+	CancellingAt     *int64                   `json:"cancelling_at"`
+	CancelledAt      *int64                   `json:"cancelled_at"`
+	RequestCounts    OpenAIBatchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string        `json:"metadata"`
+	// 以下为扩展字段：冻结额与实际结算费用（美元）。
+	HoldAmount float64 `json:"hold_amount"`
+	ActualCost float64 `json:"actual_cost"`
+}
+
+// OpenAIListObject OpenAI 风格的分页列表。
+type OpenAIListObject[T any] struct {
+	Object  string  `json:"object"`
+	Data    []T     `json:"data"`
+	FirstID *string `json:"first_id"`
+	LastID  *string `json:"last_id"`
+	HasMore bool    `json:"has_more"`
+}
+
+// CreateOpenAIBatchRequest POST /v1/batches 请求体。
+type CreateOpenAIBatchRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// OpenAIBatchService 处理 /v1/files 与 /v1/batches 的用户请求；批次执行见 OpenAIBatchWorker。
+type OpenAIBatchService struct {
+	repo             OpenAIBatchRepository
+	groupRepo        GroupRepository
+	billingService   *BillingService
+	usageBillingRepo UsageBillingRepository
+	cfg              *config.Config
+}
+
+func NewOpenAIBatchService(
+	repo OpenAIBatchRepository,
+	groupRepo GroupRepository,
+	billingService *BillingService,
+	usageBillingRepo UsageBillingRepository,
+	cfg *config.Config,
+) *OpenAIBatchService {
+	return &OpenAIBatchService{
+		repo:             repo,
+		groupRepo:        groupRepo,
+		billingService:   billingService,
+		usageBillingRepo: usageBillingRepo,
+		cfg:              cfg,
+	}
+}
+
+func (s *OpenAIBatchService) enabled() bool {
+	return s != nil && s.repo != nil && (s.cfg == nil || s.cfg.BatchAPI.Enabled)
+}
+
+// MaxFileBytes 返回单个上传文件允许的最大字节数。
+func (s *OpenAIBatchService) MaxFileBytes() int64 {
+	if s.cfg == nil || s.cfg.BatchAPI.MaxFileBytes <= 0 {
+		return 100 * 1024 * 1024
+	}
+	return s.cfg.BatchAPI.MaxFileBytes
+}
+
+func (s *OpenAIBatchService) maxRequests() int {
+	if s.cfg == nil || s.cfg.BatchAPI.MaxRequestsPerBatch <= 0 {
+		return 10000
+	}
+	return s.cfg.BatchAPI.MaxRequestsPerBatch
+}
+
+func (s *OpenAIBatchService) defaultMaxOutputTokens() int {
+	if s.cfg == nil || s.cfg.BatchAPI.DefaultMaxOutputTokens <= 0 {
+		return 4096
+	}
+	return s.cfg.BatchAPI.DefaultMaxOutputTokens
+}
+
+func (s *OpenAIBatchService) fileExpiry(now time.Time) *time.Time {
+	days := 30
+	if s.cfg != nil && s.cfg.BatchAPI.FileRetentionDays > 0 {
+		days = s.cfg.BatchAPI.FileRetentionDays
+	}
+	expiresAt := now.AddDate(0, 0, days)
+	return &expiresAt
+}
+
+// UploadFile 保存 purpose=batch 的 JSONL 输入文件。内容在创建批次时才逐行校验，与 OpenAI 行为一致。
+func (s *OpenAIBatchService) UploadFile(ctx context.Context, owner OpenAIBatchOwner, purpose, filename string, content []byte) (*OpenAIFileObject, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	if strings.TrimSpace(purpose) != OpenAIFilePurposeBatch {
+		return nil, ErrOpenAIFileInvalidPurpose
+	}
+	if int64(len(content)) > s.MaxFileBytes() {
+		return nil, ErrOpenAIFileTooLarge
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, ErrOpenAIFileEmpty
+	}
+	now := time.Now().UTC()
+	file := &OpenAIFile{
+		ID:        newOpenAIBatchObjectID("file-"),
+		UserID:    owner.UserID,
+		APIKeyID:  owner.APIKeyID,
+		Purpose:   OpenAIFilePurposeBatch,
+		Filename:  sanitizeOpenAIFilename(filename),
+		Bytes:     int64(len(content)),
+		Content:   content,
+		CreatedAt: now,
+		ExpiresAt: s.fileExpiry(now),
+	}
+	if err := s.repo.CreateFile(ctx, file); err != nil {
+		return nil, err
+	}
+	return openAIFileToObject(file), nil
+}
+
+func (s *OpenAIBatchService) GetFile(ctx context.Context, owner OpenAIBatchOwner, fileID string) (*OpenAIFileObject, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	file, err := s.repo.GetFile(ctx, owner.UserID, strings.TrimSpace(fileID))
+	if err != nil {
+		return nil, err
+	}
+	return openAIFileToObject(file), nil
+}
+
+// GetFileContent 返回文件原始内容及文件名。
+func (s *OpenAIBatchService) GetFileContent(ctx context.Context, owner OpenAIBatchOwner, fileID string) ([]byte, string, error) {
+	if !s.enabled() {
+		return nil, "", ErrOpenAIBatchDisabled
+	}
+	fileID = strings.TrimSpace(fileID)
+	file, err := s.repo.GetFile(ctx, owner.UserID, fileID)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := s.repo.GetFileContent(ctx, owner.UserID, fileID)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, file.Filename, nil
+}
+
+func (s *OpenAIBatchService) ListFiles(ctx context.Context, owner OpenAIBatchOwner, purpose, after string, limit int) (*OpenAIListObject[OpenAIFileObject], error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	limit = normalizeOpenAIBatchListLimit(limit)
+	files, err := s.repo.ListFiles(ctx, owner.UserID, strings.TrimSpace(purpose), strings.TrimSpace(after), limit+1)
+	if err != nil {
+		return nil, err
+	}
+	out := &OpenAIListObject[OpenAIFileObject]{Object: "list", Data: make([]OpenAIFileObject, 0, len(files))}
+	if len(files) > limit {
+		out.HasMore = true
+		files = files[:limit]
+	}
+	for i := range files {
+		out.Data = append(out.Data, *openAIFileToObject(&files[i]))
+	}
+	if n := len(out.Data); n > 0 {
+		out.FirstID, out.LastID = &out.Data[0].ID, &out.Data[n-1].ID
+	}
+	return out, nil
+}
+
+// DeleteFile 删除文件，返回 OpenAI 风格的删除结果。
+func (s *OpenAIBatchService) DeleteFile(ctx context.Context, owner OpenAIBatchOwner, fileID string) (map[string]any, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	fileID = strings.TrimSpace(fileID)
+	if err := s.repo.DeleteFile(ctx, owner.UserID, fileID); err != nil {
+		return nil, err
+	}
+	return map[string]any{"id": fileID, "object": "file", "deleted": true}, nil
+}
+
+// CreateBatch 校验输入文件、按原价估算并冻结余额后创建批次。
+func (s *OpenAIBatchService) CreateBatch(ctx context.Context, owner OpenAIBatchOwner, req CreateOpenAIBatchRequest) (*OpenAIBatchObject, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	// 批次创建时冻结的是个人余额，组织 Key 不支持。
+	if owner.OrganizationID != nil {
+		return nil, ErrOrganizationKeyBatchUnsupported
+	}
+	group, err := s.batchGroup(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimSpace(req.Endpoint)
+	if !slices.Contains(OpenAIBatchEndpoints, endpoint) {
+		return nil, ErrOpenAIBatchInvalidEndpoint
+	}
+	window := strings.TrimSpace(req.CompletionWindow)
+	if window == "" {
+		window = openAIBatchCompletionWindow
+	}
+	if window != openAIBatchCompletionWindow {
+		return nil, ErrOpenAIBatchInvalidWindow
+	}
+	if len(req.Metadata) > openAIBatchMaxMetadataPairs {
+		return nil, ErrOpenAIBatchInvalidMetadata
+	}
+
+	inputFileID := strings.TrimSpace(req.InputFileID)
+	file, err := s.repo.GetFile(ctx, owner.UserID, inputFileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.Purpose != OpenAIFilePurposeBatch {
+		return nil, infraerrors.BadRequest("OPENAI_BATCH_INVALID_INPUT_FILE", "input file must have purpose batch")
+	}
+	content, err := s.repo.GetFileContent(ctx, owner.UserID, inputFileID)
+	if err != nil {
+		return nil, err
+	}
+	items, estimate, err := s.parseBatchInput(content, endpoint, group.RateMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, hold := openAIBatchMultipliers(group)
+	now := time.Now().UTC()
+	batch := &OpenAIBatch{
+		ID:                 newOpenAIBatchObjectID("batch_"),
+		UserID:             owner.UserID,
+		APIKeyID:           owner.APIKeyID,
+		GroupID:            group.ID,
+		Endpoint:           endpoint,
+		InputFileID:        inputFileID,
+		Status:             OpenAIBatchStatusInProgress,
+		CompletionWindow:   window,
+		Metadata:           req.Metadata,
+		ClientIP:           owner.ClientIP,
+		RequestTotal:       len(items),
+		HoldAmount:         roundOpenAIBatchAmount(estimate * hold),
+		DiscountMultiplier: discount,
+		HoldMultiplier:     hold,
+		CreatedAt:          now,
+		InProgressAt:       &now,
+		ExpiresAt:          now.Add(24 * time.Hour),
+	}
+	if err := s.reserveHold(ctx, batch); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBatch(ctx, batch, items); err != nil {
+		if releaseErr := releaseOpenAIBatchHold(ctx, s.usageBillingRepo, batch); releaseErr != nil {
+			slog.Error("openai_batch.release_after_create_failed", "batch_id", batch.ID, "error", releaseErr)
+		}
+		return nil, err
+	}
+	return openAIBatchToObject(batch), nil
+}
+
+func (s *OpenAIBatchService) GetBatch(ctx context.Context, owner OpenAIBatchOwner, batchID string) (*OpenAIBatchObject, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	batch, err := s.repo.GetBatch(ctx, owner.UserID, strings.TrimSpace(batchID))
+	if err != nil {
+		return nil, err
+	}
+	return openAIBatchToObject(batch), nil
+}
+
+func (s *OpenAIBatchService) ListBatches(ctx context.Context, owner OpenAIBatchOwner, after string, limit int) (*OpenAIListObject[OpenAIBatchObject], error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	limit = normalizeOpenAIBatchListLimit(limit)
+	batches, err := s.repo.ListBatches(ctx, owner.UserID, strings.TrimSpace(after), limit+1)
+	if err != nil {
+		return nil, err
+	}
+	out := &OpenAIListObject[OpenAIBatchObject]{Object: "list", Data: make([]OpenAIBatchObject, 0, len(batches))}
+	if len(batches) > limit {
+		out.HasMore = true
+		batches = batches[:limit]
+	}
+	for i := range batches {
+		out.Data = append(out.Data, *openAIBatchToObject(&batches[i]))
+	}
+	if n := len(out.Data); n > 0 {
+		out.FirstID, out.LastID = &out.Data[0].ID, &out.Data[n-1].ID
+	}
+	return out, nil
+}
+
+// CancelBatch 请求取消：未执行的行由 worker 标记为 batch_cancelled，已执行的行照常计费。
+func (s *OpenAIBatchService) CancelBatch(ctx context.Context, owner OpenAIBatchOwner, batchID string) (*OpenAIBatchObject, error) {
+	if !s.enabled() {
+		return nil, ErrOpenAIBatchDisabled
+	}
+	batch, err := s.repo.MarkBatchCancelling(ctx, owner.UserID, strings.TrimSpace(batchID))
+	if err != nil {
+		return nil, err
+	}
+	return openAIBatchToObject(batch), nil
+}
+
+func (s *OpenAIBatchService) batchGroup(ctx context.Context, owner OpenAIBatchOwner) (*Group, error) {
+	if owner.GroupID == nil || s.groupRepo == nil {
+		return nil, ErrOpenAIBatchNotAllowed
+	}
+	group, err := s.groupRepo.GetByID(ctx, *owner.GroupID)
+	if err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			return nil, ErrOpenAIBatchNotAllowed
+		}
+		return nil, err
+	}
+	if !group.AllowBatchAPI || group.IsSubscriptionType() || !group.IsActive() {
+		return nil, ErrOpenAIBatchNotAllowed
+	}
+	return group, nil
+}
+
+func (s *OpenAIBatchService) reserveHold(ctx context.Context, batch *OpenAIBatch) error {
+	if batch.HoldAmount <= 0 {
+		return nil
+	}
+	if s.usageBillingRepo == nil {
+		return ErrBatchImageBillingHoldFailed.WithCause(errors.New("batch billing repository is not configured"))
+	}
+	if _, err := s.usageBillingRepo.ReserveBatchImageBalance(ctx, openAIBatchHoldCommand(batch, BatchImageHoldRequestID(batch.ID), 0)); err != nil {
+		if errors.Is(err, ErrBatchImageInsufficientBalance) {
+			return ErrOpenAIBatchInsufficientFunds
+		}
+		return ErrBatchImageBillingHoldFailed.WithCause(err)
+	}
+	return nil
+}
+
+// parseBatchInput 逐行校验输入文件并返回批次行与按原价估算的总费用（已含分组倍率）。
+func (s *OpenAIBatchService) parseBatchInput(content []byte, endpoint string, rateMultiplier float64) ([]OpenAIBatchItem, float64, error) {
+	lines := bytes.Split(content, []byte("\n"))
+	items := make([]OpenAIBatchItem, 0, len(lines))
+	seen := make(map[string]struct{}, len(lines))
+	estimate := 0.0
+	for i, raw := range lines {
+		line := bytes.TrimSpace(raw)
+		if len(line) == 0 {
+			continue
+		}
+		lineNo := i + 1
+		if len(items) >= s.maxRequests() {
+			return nil, 0, openAIBatchLineError(lineNo, fmt.Sprintf("batch exceeds the maximum of %d requests", s.maxRequests()))
+		}
+		if !gjson.ValidBytes(line) {
+			return nil, 0, openAIBatchLineError(lineNo, "line is not valid JSON")
+		}
+		customID := gjson.GetBytes(line, "custom_id").String()
+		if strings.TrimSpace(customID) == "" || len(customID) > openAIBatchMaxCustomIDLen {
+			return nil, 0, openAIBatchLineError(lineNo, "custom_id is required and must be at most 512 characters")
+		}
+		if _, dup := seen[customID]; dup {
+			return nil, 0, openAIBatchLineError(lineNo, "duplicate custom_id "+customID)
+		}
+		seen[customID] = struct{}{}
+		if method := strings.ToUpper(gjson.GetBytes(line, "method").String()); method != "POST" {
+			return nil, 0, openAIBatchLineError(lineNo, "method must be POST")
+		}
+		if url := gjson.GetBytes(line, "url").String(); url != endpoint {
+			return nil, 0, openAIBatchLineError(lineNo, "url must match the batch endpoint "+endpoint)
+		}
+		body := gjson.GetBytes(line, "body")
+		if !body.IsObject() {
+			return nil, 0, openAIBatchLineError(lineNo, "body must be a JSON object")
+		}
+		model := body.Get("model").String()
+		if strings.TrimSpace(model) == "" {
+			return nil, 0, openAIBatchLineError(lineNo, "body.model is required")
+		}
+		if body.Get("stream").Bool() {
+			return nil, 0, openAIBatchLineError(lineNo, "streaming is not supported in batch requests")
+		}
+		cost, err := s.estimateLineCost(model, body, rateMultiplier)
+		if err != nil {
+			return nil, 0, openAIBatchLineError(lineNo, err.Error())
+		}
+		estimate += cost
+		items = append(items, OpenAIBatchItem{
+			LineIndex: len(items),
+			CustomID:  customID,
+			Method:    "POST",
+			URL:       endpoint,
+			Body:      []byte(body.Raw),
+			Status:    OpenAIBatchItemStatusPending,
+		})
+	}
+	if len(items) == 0 {
+		return nil, 0, ErrOpenAIFileEmpty
+	}
+	return items, estimate, nil
+}
+
+func (s *OpenAIBatchService) estimateLineCost(model string, body gjson.Result, rateMultiplier float64) (float64, error) {
+	if s.billingService == nil {
+		return 0, nil
+	}
+	outputTokens := int(body.Get("max_completion_tokens").Int())
+	for _, field := range []string{"max_tokens", "max_output_tokens"} {
+		if outputTokens <= 0 {
+			outputTokens = int(body.Get(field).Int())
+		}
+	}
+	if outputTokens <= 0 {
+		outputTokens = s.defaultMaxOutputTokens()
+	}
+	inputTokens := len(body.Raw)/openAIBatchEstimateBytesPerToken + 1
+	breakdown, err := s.billingService.CalculateCost(model, UsageTokens{InputTokens: inputTokens, OutputTokens: outputTokens}, rateMultiplier)
+	if err != nil {
+		return 0, fmt.Errorf("pricing is not available for model %s", model)
+	}
+	return breakdown.ActualCost, nil
+}
+
+func openAIBatchLineError(line int, message string) error {
+	return infraerrors.BadRequest("OPENAI_BATCH_INVALID_INPUT", fmt.Sprintf("line %d: %s", line, message)).
+		WithMetadata(map[string]string{"line": fmt.Sprint(line)})
+}
+
+func openAIBatchMultipliers(group *Group) (discount, hold float64) {
+	discount, hold = defaultBatchAPIDiscountMultiplier, defaultBatchAPIHoldMultiplier
+	if group != nil {
+		if group.BatchAPIDiscountMultiplier >= 0 {
+			discount = group.BatchAPIDiscountMultiplier
+		}
+		if group.BatchAPIHoldMultiplier >= 0 {
+			hold = group.BatchAPIHoldMultiplier
+		}
+	}
+	// 与批量生图一致：冻结比例不得低于折扣比例，存量脏数据在此钳制。
+	if hold < discount {
+		hold = discount
+	}
+	return discount, hold
+}
+
+func openAIBatchHoldCommand(batch *OpenAIBatch, requestID string, actual float64) *BatchImageBalanceHoldCommand {
+	return &BatchImageBalanceHoldCommand{
+		RequestID:          requestID,
+		APIKeyID:           batch.APIKeyID,
+		UserID:             batch.UserID,
+		BatchID:            batch.ID,
+		HoldAmount:         money.Decimal(batch.HoldAmount),
+		ActualAmount:       money.Decimal(actual),
+		RequestPayloadHash: openAIBatchHoldPayloadHash(batch.ID),
+	}
+}
+
+// captureOpenAIBatchHold 从冻结额中结算 actual 并释放差额（actual 为 0 时等价于全额释放）。
+func captureOpenAIBatchHold(ctx context.Context, repo UsageBillingRepository, batch *OpenAIBatch, actual float64) error {
+	if batch == nil || batch.HoldAmount <= 0 {
+		return nil
+	}
+	if repo == nil {
+		return ErrBatchImageSettlementBillingFailed.WithCause(errors.New("batch billing repository is not configured"))
+	}
+	if _, err := repo.CaptureBatchImageBalance(ctx, openAIBatchHoldCommand(batch, BatchImageCaptureRequestID(batch.ID), actual)); err != nil {
+		return ErrBatchImageSettlementBillingFailed.WithCause(err)
+	}
+	return nil
+}
+
+// debitOpenAIBatchOverage 把超出冻结额的实际费用直接从余额扣除（允许扣成负余额，与实时请求的透支语义一致）。
+// 以批次 ID 派生的 request_id 走 usage 计费幂等表，结算重试不会重复扣费。
+func debitOpenAIBatchOverage(ctx context.Context, repo UsageBillingRepository, batch *OpenAIBatch, overage float64) error {
+	if batch == nil || overage <= 0 {
+		return nil
+	}
+	if repo == nil {
+		return ErrBatchImageSettlementBillingFailed.WithCause(errors.New("batch billing repository is not configured"))
+	}
+	_, err := repo.Apply(ctx, &UsageBillingCommand{
+		RequestID:          openAIBatchOverageRequestPrefix + batch.ID,
+		APIKeyID:           batch.APIKeyID,
+		UserID:             batch.UserID,
+		BalanceCost:        money.Decimal(overage),
+		RequestPayloadHash: openAIBatchHoldPayloadHash(batch.ID),
+	})
+	if err != nil {
+		return ErrBatchImageSettlementBillingFailed.WithCause(err)
+	}
+	return nil
+}
+
+func releaseOpenAIBatchHold(ctx context.Context, repo UsageBillingRepository, batch *OpenAIBatch) error {
+	if repo == nil || batch == nil || batch.HoldAmount <= 0 {
+		return nil
+	}
+	_, err := repo.ReleaseBatchImageBalance(ctx, openAIBatchHoldCommand(batch, BatchImageReleaseRequestID(batch.ID), 0))
+	return err
+}
+
+func normalizeOpenAIBatchListLimit(limit int) int {
+	if limit <= 0 {
+		return openAIBatchListDefaultLimit
+	}
+	if limit > openAIBatchListMaxLimit {
+		return openAIBatchListMaxLimit
+	}
+	return limit
+}
+
+func roundOpenAIBatchAmount(v float64) float64 {
+	return math.Ceil(v*1e8) / 1e8
+}
+
+func newOpenAIBatchObjectID(prefix string) string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return prefix + hex.EncodeToString(buf)
+}
+
+func sanitizeOpenAIFilename(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "batch.jsonl"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return strings.ToValidUTF8(name, "")
+}
+
+func openAIFileToObject(file *OpenAIFile) *OpenAIFileObject {
+	out := &OpenAIFileObject{
+		ID:        file.ID,
+		Object:    "file",
+		Bytes:     file.Bytes,
+		CreatedAt: file.CreatedAt.Unix(),
+		Filename:  file.Filename,
+		Purpose:   file.Purpose,
+		Status:    "processed",
+	}
+	if file.ExpiresAt != nil {
+		v := file.ExpiresAt.Unix()
+		out.ExpiresAt = &v
+	}
+	return out
+}
+
+func openAIBatchToObject(batch *OpenAIBatch) *OpenAIBatchObject {
+	unix := func(t *time.Time) *int64 {
+		if t == nil {
+			return nil
+		}
+		v := t.Unix()
+		return &v
+	}
+	expiresAt := batch.ExpiresAt
+	out := &OpenAIBatchObject{
+		ID:               batch.ID,
+		Object:           "batch",
+		Endpoint:         batch.Endpoint,
+		InputFileID:      batch.InputFileID,
+		CompletionWindow: batch.CompletionWindow,
+		Status:           batch.Status,
+		OutputFileID:     batch.OutputFileID,
+		ErrorFileID:      batch.ErrorFileID,
+		CreatedAt:        batch.CreatedAt.Unix(),
+		InProgressAt:     unix(batch.InProgressAt),
+		ExpiresAt:        unix(&expiresAt),
+		FinalizingAt:     unix(batch.FinalizingAt),
+		CompletedAt:      unix(batch.CompletedAt),
+		FailedAt:         unix(batch.FailedAt),
+		ExpiredAt:        unix(batch.ExpiredAt),
+		CancellingAt:     unix(batch.CancellingAt),
+		CancelledAt:      unix(batch.CancelledAt),
+		RequestCounts: OpenAIBatchRequestCounts{
+			Total:     batch.RequestTotal,
+			Completed: batch.RequestCompleted,
+			Failed:    batch.RequestFailed,
+		},
+		Metadata:   batch.Metadata,
+		HoldAmount: batch.HoldAmount,
+		ActualCost: batch.ActualCost,
+	}
+	if batch.LastError != "" {
+		out.Errors = &OpenAIBatchErrors{
+			Object: "list",
+			Data:   []OpenAIBatchErrorData{{Code: "batch_error", Message: batch.LastError}},
+		}
+	}
+	return out
+}
+
+// openAIBatchOutputLine 结果文件中的一行，格式与 OpenAI Batch API 一致。
+type openAIBatchOutputLine struct {
+	ID       string                     `json:"id"`
+	CustomID string                     `json:"custom_id"`
+	Response *openAIBatchOutputResponse `json:"response"`
+	Error    *openAIBatchOutputError    `json:"error"`
+}
+
+type openAIBatchOutputResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+type openAIBatchOutputError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// buildOpenAIBatchResultFiles 将行结果拆分为 output（2xx）与 error（非 2xx 或未执行）两个 JSONL。
+func buildOpenAIBatchResultFiles(items []OpenAIBatchItem) (output, errorsOut []byte) {
+	var outBuf, errBuf bytes.Buffer
+	for i := range items {
+		item := &items[i]
+		line := openAIBatchOutputLine{
+			ID:       fmt.Sprintf("batch_req_%d", item.ID),
+			CustomID: item.CustomID,
+		}
+		if item.ResponseStatus > 0 {
+			body := json.RawMessage(item.ResponseBody)
+			if !json.Valid(body) {
+				quoted, _ := json.Marshal(string(item.ResponseBody))
+				body = quoted
+			}
+			line.Response = &openAIBatchOutputResponse{StatusCode: item.ResponseStatus, RequestID: item.RequestID, Body: body}
+		} else {
+			code := item.ErrorCode
+			if code == "" {
+				code = OpenAIBatchItemErrorExecution
+			}
+			line.Error = &openAIBatchOutputError{Code: code, Message: item.ErrorMessage}
+		}
+		encoded, err := json.Marshal(line)
+		if err != nil {
+			continue
+		}
+		target := &errBuf
+		if item.Status == OpenAIBatchItemStatusSucceeded {
+			target = &outBuf
+		}
+		target.Write(encoded)
+		target.WriteByte('\n')
+	}
+	return outBuf.Bytes(), errBuf.Bytes()
+}
@@ -0,0 +1,198 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestOpenAIBatchService(repo OpenAIBatchRepository, groups GroupRepository, billing UsageBillingRepository) *OpenAIBatchService {
+	cfg := &config.Config{}
+	cfg.BatchAPI.Enabled = true
+	cfg.BatchAPI.MaxRequestsPerBatch = 3
+	return NewOpenAIBatchService(repo, groups, nil, billing, cfg)
+}
+
+func TestOpenAIBatchService_ParseBatchInputValidatesLines(t *testing.T) {
+	svc := newTestOpenAIBatchService(nil, nil, nil)
+	const ok = `{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o-mini","messages":[]}}`
+	cases := []struct {
+		name    string
+		content string
+		line    string
+		message string
+	}{
+		{"invalid json", ok + "\n{not json}", "2", "not valid JSON"},
+		{"missing custom id", `{"method":"POST","url":"/v1/chat/completions","body":{"model":"m"}}`, "1", "custom_id"},
+		{"duplicate custom id", ok + "\n" + ok, "2", "duplicate custom_id"},
+		{"wrong method", strings.Replace(ok, `"POST"`, `"GET"`, 1), "1", "method must be POST"},
+		{"endpoint mismatch", strings.Replace(ok, "/v1/chat/completions", "/v1/responses", 1), "1", "url must match"},
+		{"missing model", `{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{}}`, "1", "body.model"},
+		{"stream", strings.Replace(ok, `"messages":[]`, `"stream":true`, 1), "1", "streaming"},
+		{"too many", strings.Join([]string{ok, strings.Replace(ok, `"a"`, `"b"`, 1), strings.Replace(ok, `"a"`, `"c"`, 1), strings.Replace(ok, `"a"`, `"d"`, 1)}, "\n"), "4", "maximum of 3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := svc.parseBatchInput([]byte(tc.content), "/v1/chat/completions", 1)
+			require.Error(t, err)
+			appErr := infraerrors.FromError(err)
+			require.Equal(t, "OPENAI_BATCH_INVALID_INPUT", appErr.Reason)
+			require.Equal(t, tc.line, appErr.Metadata["line"])
+			require.Contains(t, appErr.Message, tc.message)
+		})
+	}
+
+	items, _, err := svc.parseBatchInput([]byte("\n"+ok+"\n\n"), "/v1/chat/completions", 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, "a", items[0].CustomID)
+	require.Equal(t, "gpt-4o-mini", gjson.GetBytes(items[0].Body, "model").String())
+
+	_, _, err = svc.parseBatchInput([]byte("\n \n"), "/v1/chat/completions", 1)
+	require.ErrorIs(t, err, ErrOpenAIFileEmpty)
+}
+
+func TestOpenAIBatchMultipliers_ClampsHoldToDiscount(t *testing.T) {
+	discount, hold := openAIBatchMultipliers(&Group{BatchAPIDiscountMultiplier: 0.5, BatchAPIHoldMultiplier: 0.3})
+	require.Equal(t, 0.5, discount)
+	require.Equal(t, 0.5, hold)
+
+	discount, hold = openAIBatchMultipliers(nil)
+	require.Equal(t, defaultBatchAPIDiscountMultiplier, discount)
+	require.Equal(t, defaultBatchAPIHoldMultiplier, hold)
+}
+
+func TestOpenAIBatchService_CreateBatchRequiresAllowedGroup(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	groupID := int64(11)
+	groups := &openAIBatchGroupRepoStub{group: &Group{ID: groupID, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard}}
+	svc := newTestOpenAIBatchService(repo, groups, &fakeBatchImageBillingRepo{})
+	owner := OpenAIBatchOwner{UserID: 3, APIKeyID: 7, GroupID: &groupID}
+
+	_, err := svc.CreateBatch(context.Background(), owner, CreateOpenAIBatchRequest{InputFileID: "file-x", Endpoint: "/v1/chat/completions"})
+	require.ErrorIs(t, err, ErrOpenAIBatchNotAllowed)
+
+	groups.group.AllowBatchAPI = true
+	groups.group.SubscriptionType = SubscriptionTypeSubscription
+	_, err = svc.CreateBatch(context.Background(), owner, CreateOpenAIBatchRequest{InputFileID: "file-x", Endpoint: "/v1/chat/completions"})
+	require.ErrorIs(t, err, ErrOpenAIBatchNotAllowed)
+
+	_, err = svc.CreateBatch(context.Background(), OpenAIBatchOwner{UserID: 3, APIKeyID: 7}, CreateOpenAIBatchRequest{})
+	require.ErrorIs(t, err, ErrOpenAIBatchNotAllowed)
+}
+
+func TestOpenAIBatchService_CreateBatchStoresItemsAndCancels(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	groupID := int64(11)
+	groups := &openAIBatchGroupRepoStub{group: &Group{
+		ID: groupID, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard, RateMultiplier: 1,
+		AllowBatchAPI: true, BatchAPIDiscountMultiplier: 0.5, BatchAPIHoldMultiplier: 0.6,
+	}}
+	billing := &fakeBatchImageBillingRepo{}
+	svc := newTestOpenAIBatchService(repo, groups, billing)
+	owner := OpenAIBatchOwner{UserID: 3, APIKeyID: 7, GroupID: &groupID}
+
+	content := `{"custom_id":"a","method":"POST","url":"/v1/responses","body":{"model":"gpt-4o-mini","input":"hi"}}` + "\n" +
+		`{"custom_id":"b","method":"POST","url":"/v1/responses","body":{"model":"gpt-4o-mini","input":"yo"}}`
+	file, err := svc.UploadFile(context.Background(), owner, OpenAIFilePurposeBatch, "in.jsonl", []byte(content))
+	require.NoError(t, err)
+	require.Equal(t, "file", file.Object)
+
+	_, err = svc.CreateBatch(context.Background(), owner, CreateOpenAIBatchRequest{InputFileID: file.ID, Endpoint: "/v1/embeddings"})
+	require.ErrorIs(t, err, ErrOpenAIBatchInvalidEndpoint)
+	_, err = svc.CreateBatch(context.Background(), owner, CreateOpenAIBatchRequest{InputFileID: file.ID, Endpoint: "/v1/responses", CompletionWindow: "1h"})
+	require.ErrorIs(t, err, ErrOpenAIBatchInvalidWindow)
+
+	batch, err := svc.CreateBatch(context.Background(), owner, CreateOpenAIBatchRequest{InputFileID: file.ID, Endpoint: "/v1/responses"})
+	require.NoError(t, err)
+	require.Equal(t, "batch", batch.Object)
+	require.Equal(t, OpenAIBatchStatusInProgress, batch.Status)
+	require.Equal(t, 2, batch.RequestCounts.Total)
+	require.Len(t, repo.items, 2)
+	require.Equal(t, 0.5, repo.batches[batch.ID].DiscountMultiplier)
+
+	cancelled, err := svc.CancelBatch(context.Background(), owner, batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, OpenAIBatchStatusCancelling, cancelled.Status)
+}
+
+func TestOpenAIBatchService_UploadFileValidatesPurposeAndSize(t *testing.T) {
+	svc := newTestOpenAIBatchService(newFakeOpenAIBatchRepo(), nil, nil)
+	svc.cfg.BatchAPI.MaxFileBytes = 4
+	owner := OpenAIBatchOwner{UserID: 3, APIKeyID: 7}
+
+	_, err := svc.UploadFile(context.Background(), owner, "fine-tune", "a.jsonl", []byte("{}"))
+	require.ErrorIs(t, err, ErrOpenAIFileInvalidPurpose)
+	_, err = svc.UploadFile(context.Background(), owner, OpenAIFilePurposeBatch, "a.jsonl", []byte("{}{}{}"))
+	require.ErrorIs(t, err, ErrOpenAIFileTooLarge)
+	_, err = svc.UploadFile(context.Background(), owner, OpenAIFilePurposeBatch, "a.jsonl", nil)
+	require.ErrorIs(t, err, ErrOpenAIFileEmpty)
+}
+
+func TestOpenAIBatchExecution_ApplyPricingDiscountsAndSkipsBalanceDeduct(t *testing.T) {
+	exec := &OpenAIBatchExecution{BatchID: "batch_x", ItemID: 1, DiscountMultiplier: 0.5}
+	original := &CostBreakdown{TotalCost: 2, ActualCost: 2}
+	usageLog := &UsageLog{ActualCost: 2, RateMultiplier: 1}
+	p := &postUsageBillingParams{
+		Cost: original, User: &User{ID: 3}, APIKey: &APIKey{ID: 7}, Account: &Account{ID: 9},
+	}
+
+	exec.applyPricing(usageLog, p)
+	require.Equal(t, 2.0, original.ActualCost, "shared breakdown must not be mutated")
+	require.Equal(t, 1.0, p.Cost.ActualCost)
+	require.Equal(t, 2.0, p.Cost.TotalCost)
+	require.True(t, p.BalanceHeld)
+	require.Equal(t, 1.0, usageLog.ActualCost)
+	require.Equal(t, 0.5, usageLog.RateMultiplier)
+
+	cmd := buildUsageBillingCommand("req-1", usageLog, p)
+	require.NotNil(t, cmd)
+	require.True(t, cmd.BalanceCost.IsZero())
+
+	var nilExec *OpenAIBatchExecution
+	p2 := &postUsageBillingParams{Cost: &CostBreakdown{ActualCost: 2}}
+	nilExec.applyPricing(nil, p2)
+	require.False(t, p2.BalanceHeld)
+	require.Nil(t, OpenAIBatchExecutionFromContext(WithOpenAIBatchExecution(context.Background(), nil)))
+}
+
+func TestBuildOpenAIBatchResultFiles_SplitsSucceededAndFailedRows(t *testing.T) {
+	items := []OpenAIBatchItem{
+		{ID: 1, CustomID: "ok", Status: OpenAIBatchItemStatusSucceeded, ResponseStatus: 200, RequestID: "req-1", ResponseBody: []byte(`{"id":"r1"}`)},
+		{ID: 2, CustomID: "upstream", Status: OpenAIBatchItemStatusFailed, ResponseStatus: 400, ResponseBody: []byte(`{"error":{"message":"bad"}}`)},
+		{ID: 3, CustomID: "cancelled", Status: OpenAIBatchItemStatusFailed, ErrorCode: OpenAIBatchItemErrorCancelled, ErrorMessage: "cancelled"},
+	}
+	output, errorsOut := buildOpenAIBatchResultFiles(items)
+
+	outLines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	require.Len(t, outLines, 1)
+	require.Equal(t, "batch_req_1", gjson.Get(outLines[0], "id").String())
+	require.Equal(t, "r1", gjson.Get(outLines[0], "response.body.id").String())
+	require.Equal(t, gjson.Null, gjson.Get(outLines[0], "error").Type)
+
+	errLines := strings.Split(strings.TrimSpace(string(errorsOut)), "\n")
+	require.Len(t, errLines, 2)
+	require.Equal(t, int64(400), gjson.Get(errLines[0], "response.status_code").Int())
+	require.Equal(t, OpenAIBatchItemErrorCancelled, gjson.Get(errLines[1], "error.code").String())
+	require.Equal(t, gjson.Null, gjson.Get(errLines[1], "response").Type)
+}
+
+type openAIBatchGroupRepoStub struct {
+	GroupRepository
+	group *Group
+}
+
+func (s *openAIBatchGroupRepoStub) GetByID(_ context.Context, id int64) (*Group, error) {
+	if s.group == nil || s.group.ID != id {
+		return nil, ErrGroupNotFound
+	}
+	cp := *s.group
+	return &cp, nil
+}
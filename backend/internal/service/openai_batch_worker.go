@@ -0,0 +1,525 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/google/uuid"
+)
+
+const (
+	openAIBatchClaimLimit       = 16
+	openAIBatchPollInterval     = 2 * time.Second
+	openAIBatchLeaseMargin      = time.Minute
+	openAIBatchAckTimeout       = 5 * time.Second
+	openAIBatchBillingGrace     = 2 * time.Minute
+	openAIBatchMaxItemAttempts  = 3
+	openAIBatchCleanupInterval  = time.Hour
+	openAIBatchCleanupBatchSize = 500
+	openAIBatchUserAgent        = "Sub2API-Batch/1.0"
+)
+
+// OpenAIBatchWorker 执行 /v1/batches 批次。
+//
+// 每一行作为进程内网关请求交给 gin engine 处理（与直接调用同一条鉴权、调度、转发、计费链路），
+// 请求 context 携带 OpenAIBatchExecution 标记，使计费钩子按分组折扣记账并把费用回写到行上。
+// 仅当分组可调度账号的平均负载低于 batch_api.idle_load_threshold 时才派发，保证实时流量优先。
+// 多实例部署时批次通过 FOR UPDATE SKIP LOCKED + lease 领取，同一批次同时只有一个实例执行。
+type OpenAIBatchWorker struct {
+	repo               OpenAIBatchRepository
+	apiKeyRepo         APIKeyRepository
+	accountRepo        AccountRepository
+	concurrencyService *ConcurrencyService
+	usageBillingRepo   UsageBillingRepository
+	cfg                *config.Config
+	workerID           string
+
+	handler atomic.Pointer[http.Handler]
+	slots   chan struct{}
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+func NewOpenAIBatchWorker(
+	repo OpenAIBatchRepository,
+	apiKeyRepo APIKeyRepository,
+	accountRepo AccountRepository,
+	concurrencyService *ConcurrencyService,
+	usageBillingRepo UsageBillingRepository,
+	cfg *config.Config,
+) *OpenAIBatchWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &OpenAIBatchWorker{
+		repo:               repo,
+		apiKeyRepo:         apiKeyRepo,
+		accountRepo:        accountRepo,
+		concurrencyService: concurrencyService,
+		usageBillingRepo:   usageBillingRepo,
+		cfg:                cfg,
+		workerID:           uuid.NewString(),
+		ctx:                ctx,
+		cancel:             cancel,
+		now:                time.Now,
+	}
+	w.slots = make(chan struct{}, w.workerConcurrency())
+	return w
+}
+
+// SetHandler 注入执行批次请求的 HTTP handler（即网关 gin engine）。
+// engine 依赖 handler 层构建，无法在构造时注入，由 server.ProvideRouter 在路由注册完成后设置。
+func (w *OpenAIBatchWorker) SetHandler(h http.Handler) {
+	if w == nil || h == nil {
+		return
+	}
+	w.handler.Store(&h)
+}
+
+func (w *OpenAIBatchWorker) workerConcurrency() int {
+	if w.cfg == nil || w.cfg.BatchAPI.WorkerConcurrency <= 0 {
+		return 4
+	}
+	return w.cfg.BatchAPI.WorkerConcurrency
+}
+
+func (w *OpenAIBatchWorker) perBatchConcurrency() int {
+	if w.cfg == nil || w.cfg.BatchAPI.PerBatchConcurrency <= 0 {
+		return 2
+	}
+	return w.cfg.BatchAPI.PerBatchConcurrency
+}
+
+func (w *OpenAIBatchWorker) idleLoadThreshold() int {
+	if w.cfg == nil || w.cfg.BatchAPI.IdleLoadThreshold <= 0 {
+		return 60
+	}
+	return w.cfg.BatchAPI.IdleLoadThreshold
+}
+
+func (w *OpenAIBatchWorker) requestTimeout() time.Duration {
+	if w.cfg == nil || w.cfg.BatchAPI.RequestTimeoutSeconds <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(w.cfg.BatchAPI.RequestTimeoutSeconds) * time.Second
+}
+
+func (w *OpenAIBatchWorker) lease() time.Duration {
+	return w.requestTimeout() + openAIBatchLeaseMargin
+}
+
+// Start launches the background loop. It is a no-op when the feature is disabled.
+func (w *OpenAIBatchWorker) Start() {
+	if w == nil || w.repo == nil || (w.cfg != nil && !w.cfg.BatchAPI.Enabled) {
+		return
+	}
+	w.startOnce.Do(func() {
+		w.wg.Add(1)
+		go w.run()
+	})
+}
+
+// Stop cancels in-flight requests and waits for the loop to exit. Interrupted
+// rows stay in running and are re-claimed after the batch lease expires.
+func (w *OpenAIBatchWorker) Stop() {
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() {
+		w.cancel()
+		w.wg.Wait()
+	})
+}
+
+func (w *OpenAIBatchWorker) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(openAIBatchPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := w.processOnce(w.ctx); err != nil && w.ctx.Err() == nil {
+			slog.Warn("openai batch worker round failed", "error", err)
+		}
+		w.cleanupIfDue(w.ctx)
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processOnce 领取活跃批次并各推进一轮：派发一组行、或在无剩余行时结算。
+func (w *OpenAIBatchWorker) processOnce(ctx context.Context) error {
+	if w.handler.Load() == nil {
+		return nil
+	}
+	batches, err := w.repo.ClaimBatches(ctx, w.workerID, openAIBatchClaimLimit, w.lease())
+	if err != nil {
+		return fmt.Errorf("claim openai batches: %w", err)
+	}
+	var wg sync.WaitGroup
+	for i := range batches {
+		wg.Add(1)
+		go func(batch OpenAIBatch) {
+			defer wg.Done()
+			w.advance(ctx, &batch)
+			ackCtx, cancel := context.WithTimeout(context.Background(), openAIBatchAckTimeout)
+			defer cancel()
+			if err := w.repo.ReleaseBatchClaim(ackCtx, batch.ID, w.workerID); err != nil {
+				slog.Warn("openai batch release claim failed", "batch_id", batch.ID, "error", err)
+			}
+		}(batches[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+func (w *OpenAIBatchWorker) advance(ctx context.Context, batch *OpenAIBatch) {
+	now := w.now()
+	switch {
+	case batch.Status == OpenAIBatchStatusCancelling:
+		w.failUnfinished(ctx, batch, OpenAIBatchItemErrorCancelled, "batch was cancelled before this request ran")
+	case batch.Status == OpenAIBatchStatusInProgress && now.After(batch.ExpiresAt):
+		w.failUnfinished(ctx, batch, OpenAIBatchItemErrorExpired, "batch expired before this request ran")
+	}
+
+	progress, err := w.repo.GetBatchProgress(ctx, batch.ID)
+	if err != nil {
+		slog.Warn("openai batch progress failed", "batch_id", batch.ID, "error", err)
+		return
+	}
+	if batch.Status == OpenAIBatchStatusInProgress && progress.Pending+progress.Running > 0 {
+		if batch.HoldAmount > 0 && progress.Cost >= batch.HoldAmount {
+			// 实际费用已达到冻结额，继续执行将无法从冻结额中结算，剩余行直接失败。
+			w.failUnfinished(ctx, batch, OpenAIBatchItemErrorHoldExhausted, "batch spending reached the held balance")
+		} else {
+			w.dispatch(ctx, batch)
+			return
+		}
+	}
+	w.finalize(ctx, batch)
+}
+
+func (w *OpenAIBatchWorker) failUnfinished(ctx context.Context, batch *OpenAIBatch, code, message string) {
+	if _, err := w.repo.FailUnfinishedItems(ctx, batch.ID, code, message); err != nil {
+		slog.Warn("openai batch fail unfinished items failed", "batch_id", batch.ID, "code", code, "error", err)
+	}
+}
+
+// dispatch 在分组空闲时领取并执行一组行。
+func (w *OpenAIBatchWorker) dispatch(ctx context.Context, batch *OpenAIBatch) {
+	if !w.groupIdle(ctx, batch.GroupID) {
+		return
+	}
+	apiKey, err := w.apiKeyRepo.GetByID(ctx, batch.APIKeyID)
+	if err != nil || apiKey == nil || apiKey.Key == "" {
+		// 创建批次的 Key 已被删除：剩余行无法以该 Key 身份执行。
+		if errors.Is(err, ErrAPIKeyNotFound) || (err == nil && (apiKey == nil || apiKey.Key == "")) {
+			w.failUnfinished(ctx, batch, OpenAIBatchItemErrorExecution, "the API key that created this batch no longer exists")
+		} else {
+			slog.Warn("openai batch load api key failed", "batch_id", batch.ID, "error", err)
+		}
+		return
+	}
+	staleBefore := w.now().Add(-w.lease())
+	items, err := w.repo.ClaimPendingItems(ctx, batch.ID, w.perBatchConcurrency(), staleBefore)
+	if err != nil {
+		slog.Warn("openai batch claim items failed", "batch_id", batch.ID, "error", err)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := range items {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case w.slots <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(item OpenAIBatchItem) {
+			defer wg.Done()
+			defer func() { <-w.slots }()
+			w.executeItem(ctx, batch, apiKey.Key, &item)
+		}(items[i])
+	}
+	wg.Wait()
+}
+
+// groupIdle 判断分组可调度账号的平均负载率是否低于阈值；无法取得负载时保守地视为繁忙。
+func (w *OpenAIBatchWorker) groupIdle(ctx context.Context, groupID int64) bool {
+	if w.accountRepo == nil || w.concurrencyService == nil {
+		return true
+	}
+	accounts, err := w.accountRepo.ListSchedulableByGroupID(ctx, groupID)
+	if err != nil {
+		slog.Warn("openai batch list schedulable accounts failed", "group_id", groupID, "error", err)
+		return false
+	}
+	if len(accounts) == 0 {
+		// 没有可调度账号时照常派发，由网关返回无可用账号错误并按可重试失败处理。
+		return true
+	}
+	withConcurrency := make([]AccountWithConcurrency, 0, len(accounts))
+	for i := range accounts {
+		withConcurrency = append(withConcurrency, AccountWithConcurrency{ID: accounts[i].ID, MaxConcurrency: accounts[i].Concurrency})
+	}
+	loads, err := w.concurrencyService.GetAccountsLoadBatch(ctx, withConcurrency)
+	if err != nil {
+		slog.Warn("openai batch load accounts failed", "group_id", groupID, "error", err)
+		return false
+	}
+	return openAIBatchAverageLoad(loads, withConcurrency) < w.idleLoadThreshold()
+}
+
+func openAIBatchAverageLoad(loads map[int64]*AccountLoadInfo, accounts []AccountWithConcurrency) int {
+	if len(accounts) == 0 {
+		return 0
+	}
+	total := 0
+	for _, account := range accounts {
+		if info := loads[account.ID]; info != nil {
+			total += info.LoadRate
+		}
+	}
+	return total / len(accounts)
+}
+
+// executeItem 以创建批次的 API Key 身份在进程内执行一行请求并记录结果。
+func (w *OpenAIBatchWorker) executeItem(ctx context.Context, batch *OpenAIBatch, apiKey string, item *OpenAIBatchItem) {
+	handlerPtr := w.handler.Load()
+	if handlerPtr == nil {
+		return
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, w.requestTimeout())
+	defer cancel()
+	reqCtx = WithOpenAIBatchExecution(reqCtx, &OpenAIBatchExecution{
+		BatchID:            batch.ID,
+		ItemID:             item.ID,
+		ClientIP:           batch.ClientIP,
+		DiscountMultiplier: batch.DiscountMultiplier,
+		Recorder:           w.repo,
+	})
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, item.URL, bytes.NewReader(item.Body))
+	if err != nil {
+		item.Status = OpenAIBatchItemStatusFailed
+		item.ErrorCode = OpenAIBatchItemErrorExecution
+		item.ErrorMessage = err.Error()
+		w.completeItem(batch, item)
+		return
+	}
+	// 以提交者的客户端 IP 发起，使 API Key IP 限制、按 IP 限流与用量记录看到与原始请求一致的来源；
+	// 不能使用回环地址，否则会绕过这些基于 IP 的控制。
+	if batch.ClientIP != "" {
+		req.RemoteAddr = net.JoinHostPort(batch.ClientIP, "0")
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", openAIBatchUserAgent)
+	if item.URL == "/v1/messages" {
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+
+	recorder := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				slog.Error("openai batch item panicked", "batch_id", batch.ID, "item_id", item.ID, "panic", recovered)
+				recorder = httptest.NewRecorder()
+			}
+		}()
+		(*handlerPtr).ServeHTTP(recorder, req)
+	}()
+
+	if ctx.Err() != nil {
+		// 关停中断：保持 running，lease 过期后重新领取。
+		return
+	}
+	status := recorder.Code
+	body := bytes.TrimSpace(recorder.Body.Bytes())
+	if len(body) == 0 && reqCtx.Err() != nil {
+		item.Status = OpenAIBatchItemStatusFailed
+		item.ErrorCode = OpenAIBatchItemErrorExecution
+		item.ErrorMessage = "request timed out"
+		w.completeItem(batch, item)
+		return
+	}
+	if openAIBatchRetryableStatus(status) && item.Attempts < openAIBatchMaxItemAttempts {
+		ackCtx, ackCancel := context.WithTimeout(context.Background(), openAIBatchAckTimeout)
+		defer ackCancel()
+		if err := w.repo.RequeueItem(ackCtx, item.ID, fmt.Sprintf("upstream status %d", status)); err != nil {
+			slog.Warn("openai batch requeue item failed", "batch_id", batch.ID, "item_id", item.ID, "error", err)
+		}
+		return
+	}
+	item.ResponseStatus = status
+	item.ResponseBody = body
+	item.RequestID = firstNonEmptyHeader(recorder.Header(), "x-request-id", "request-id")
+	if status >= 200 && status < 300 {
+		item.Status = OpenAIBatchItemStatusSucceeded
+	} else {
+		item.Status = OpenAIBatchItemStatusFailed
+	}
+	w.completeItem(batch, item)
+}
+
+func (w *OpenAIBatchWorker) completeItem(batch *OpenAIBatch, item *OpenAIBatchItem) {
+	ackCtx, cancel := context.WithTimeout(context.Background(), openAIBatchAckTimeout)
+	defer cancel()
+	if err := w.repo.CompleteItem(ackCtx, item); err != nil {
+		slog.Warn("openai batch complete item failed", "batch_id", batch.ID, "item_id", item.ID, "error", err)
+	}
+}
+
+func openAIBatchRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == 529 || status >= http.StatusInternalServerError
+}
+
+func firstNonEmptyHeader(h http.Header, keys ...string) string {
+	for _, key := range keys {
+		if v := strings.TrimSpace(h.Get(key)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// finalize 等待异步计费回写（至多 openAIBatchBillingGrace），生成结果文件并从冻结额中结算。
+func (w *OpenAIBatchWorker) finalize(ctx context.Context, batch *OpenAIBatch) {
+	current, err := w.repo.MarkBatchFinalizing(ctx, batch.ID)
+	if err != nil {
+		slog.Warn("openai batch mark finalizing failed", "batch_id", batch.ID, "error", err)
+		return
+	}
+	progress, err := w.repo.GetBatchProgress(ctx, batch.ID)
+	if err != nil {
+		slog.Warn("openai batch progress failed", "batch_id", batch.ID, "error", err)
+		return
+	}
+	if progress.Pending+progress.Running > 0 {
+		return
+	}
+	if progress.Unbilled > 0 && current.FinalizingAt != nil && w.now().Sub(*current.FinalizingAt) < openAIBatchBillingGrace {
+		return
+	}
+	if progress.Unbilled > 0 {
+		slog.Warn("openai batch finalized with unbilled requests",
+			"batch_id", batch.ID, "unbilled", progress.Unbilled)
+	}
+
+	// 结算金额首次确定后即锁定，重试时沿用同一金额，保证 capture / 超额扣费的幂等指纹不变。
+	actual, err := w.repo.LockSettleAmount(ctx, batch.ID, progress.Cost)
+	if err != nil {
+		slog.Warn("openai batch lock settle amount failed", "batch_id", batch.ID, "error", err)
+		return
+	}
+	captured, overage := actual, 0.0
+	if current.HoldAmount > 0 && actual > current.HoldAmount {
+		// 派发前已在达到冻结额时停止执行，但已在途的行仍可能让实际费用越过冻结额：
+		// 冻结额全部 capture，超出部分直接从余额扣除。
+		captured, overage = current.HoldAmount, roundOpenAIBatchAmount(actual-current.HoldAmount)
+		slog.Warn("openai batch cost exceeds hold, debiting overage from balance",
+			"batch_id", batch.ID, "user_id", current.UserID, "cost", actual, "hold", current.HoldAmount, "overage", overage)
+	}
+	if err := captureOpenAIBatchHold(ctx, w.usageBillingRepo, current, captured); err != nil {
+		w.settlementFailed(ctx, batch.ID, captured, err)
+		return
+	}
+	if err := debitOpenAIBatchOverage(ctx, w.usageBillingRepo, current, overage); err != nil {
+		w.settlementFailed(ctx, batch.ID, overage, err)
+		return
+	}
+
+	items, err := w.repo.ListBatchItems(ctx, batch.ID)
+	if err != nil {
+		slog.Warn("openai batch list items failed", "batch_id", batch.ID, "error", err)
+		return
+	}
+	finish := &OpenAIBatchFinish{Status: OpenAIBatchStatusCompleted, ActualCost: actual}
+	switch {
+	case current.CancellingAt != nil:
+		finish.Status = OpenAIBatchStatusCancelled
+	case progress.Expired > 0:
+		finish.Status = OpenAIBatchStatusExpired
+	}
+	output, errorsOut := buildOpenAIBatchResultFiles(items)
+	now := w.now().UTC()
+	expiresAt := now.AddDate(0, 0, w.fileRetentionDays())
+	if len(output) > 0 {
+		finish.OutputFile = &OpenAIFile{
+			ID: newOpenAIBatchObjectID("file-"), UserID: current.UserID, APIKeyID: current.APIKeyID,
+			Purpose: OpenAIFilePurposeBatchOutput, Filename: batch.ID + "_output.jsonl",
+			Bytes: int64(len(output)), Content: output, CreatedAt: now, ExpiresAt: &expiresAt,
+		}
+	}
+	if len(errorsOut) > 0 {
+		finish.ErrorFile = &OpenAIFile{
+			ID: newOpenAIBatchObjectID("file-"), UserID: current.UserID, APIKeyID: current.APIKeyID,
+			Purpose: OpenAIFilePurposeBatchOutput, Filename: batch.ID + "_error.jsonl",
+			Bytes: int64(len(errorsOut)), Content: errorsOut, CreatedAt: now, ExpiresAt: &expiresAt,
+		}
+	}
+	if err := w.repo.FinishBatch(ctx, batch.ID, finish); err != nil {
+		slog.Warn("openai batch finish failed", "batch_id", batch.ID, "error", err)
+	}
+}
+
+func (w *OpenAIBatchWorker) settlementFailed(ctx context.Context, batchID string, amount float64, err error) {
+	slog.Error("openai batch settlement failed", "batch_id", batchID, "amount", amount, "error", err)
+	if setErr := w.repo.SetBatchError(ctx, batchID, "settlement failed, will retry"); setErr != nil {
+		slog.Warn("openai batch set error failed", "batch_id", batchID, "error", setErr)
+	}
+}
+
+func (w *OpenAIBatchWorker) fileRetentionDays() int {
+	if w.cfg == nil || w.cfg.BatchAPI.FileRetentionDays <= 0 {
+		return 30
+	}
+	return w.cfg.BatchAPI.FileRetentionDays
+}
+
+func (w *OpenAIBatchWorker) cleanupIfDue(ctx context.Context) {
+	now := w.now()
+	if !w.lastCleanup.IsZero() && now.Sub(w.lastCleanup) < openAIBatchCleanupInterval {
+		return
+	}
+	w.lastCleanup = now
+	deleted, err := w.repo.DeleteExpiredFiles(ctx, now.UTC(), openAIBatchCleanupBatchSize)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		slog.Warn("openai batch file cleanup failed", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("openai batch files cleaned up", "deleted", deleted)
+	}
+}
+
+// ProvideOpenAIBatchWorker creates and starts the worker. Requests are only
+// dispatched once the router injects the gateway handler via SetHandler.
+func ProvideOpenAIBatchWorker(
+	repo OpenAIBatchRepository,
+	apiKeyRepo APIKeyRepository,
+	accountRepo AccountRepository,
+	concurrencyService *ConcurrencyService,
+	usageBillingRepo UsageBillingRepository,
+	cfg *config.Config,
+) *OpenAIBatchWorker {
+	w := NewOpenAIBatchWorker(repo, apiKeyRepo, accountRepo, concurrencyService, usageBillingRepo, cfg)
+	w.Start()
+	return w
+}
@@ -0,0 +1,503 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type fakeOpenAIBatchRepo struct {
+	mu       sync.Mutex
+	batches  map[string]*OpenAIBatch
+	items    map[int64]*OpenAIBatchItem
+	files    map[string]*OpenAIFile
+	finished map[string]*OpenAIBatchFinish
+	nextID   int64
+}
+
+func newFakeOpenAIBatchRepo() *fakeOpenAIBatchRepo {
+	return &fakeOpenAIBatchRepo{
+		batches:  make(map[string]*OpenAIBatch),
+		items:    make(map[int64]*OpenAIBatchItem),
+		files:    make(map[string]*OpenAIFile),
+		finished: make(map[string]*OpenAIBatchFinish),
+	}
+}
+
+func (r *fakeOpenAIBatchRepo) CreateFile(_ context.Context, file *OpenAIFile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *file
+	r.files[file.ID] = &cp
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetFile(_ context.Context, userID int64, fileID string) (*OpenAIFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[fileID]
+	if !ok || f.UserID != userID {
+		return nil, ErrOpenAIFileNotFound
+	}
+	cp := *f
+	return &cp, nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetFileContent(ctx context.Context, userID int64, fileID string) ([]byte, error) {
+	f, err := r.GetFile(ctx, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	return f.Content, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ListFiles(context.Context, int64, string, string, int) ([]OpenAIFile, error) {
+	return nil, nil
+}
+
+func (r *fakeOpenAIBatchRepo) DeleteFile(context.Context, int64, string) error { return nil }
+
+func (r *fakeOpenAIBatchRepo) DeleteExpiredFiles(context.Context, time.Time, int) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeOpenAIBatchRepo) CreateBatch(_ context.Context, batch *OpenAIBatch, items []OpenAIBatchItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *batch
+	r.batches[batch.ID] = &cp
+	for i := range items {
+		r.nextID++
+		item := items[i]
+		item.ID = r.nextID
+		item.BatchID = batch.ID
+		r.items[item.ID] = &item
+	}
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetBatch(_ context.Context, userID int64, batchID string) (*OpenAIBatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.batches[batchID]
+	if !ok || b.UserID != userID {
+		return nil, ErrOpenAIBatchNotFound
+	}
+	cp := *b
+	return &cp, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ListBatches(context.Context, int64, string, int) ([]OpenAIBatch, error) {
+	return nil, nil
+}
+
+func (r *fakeOpenAIBatchRepo) MarkBatchCancelling(_ context.Context, userID int64, batchID string) (*OpenAIBatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.batches[batchID]
+	if !ok || b.UserID != userID {
+		return nil, ErrOpenAIBatchNotFound
+	}
+	if b.IsTerminal() {
+		return nil, ErrOpenAIBatchNotCancellable
+	}
+	now := time.Now()
+	b.Status = OpenAIBatchStatusCancelling
+	b.CancellingAt = &now
+	cp := *b
+	return &cp, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ClaimBatches(context.Context, string, int, time.Duration) ([]OpenAIBatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]OpenAIBatch, 0, len(r.batches))
+	for _, b := range r.batches {
+		if !b.IsTerminal() {
+			out = append(out, *b)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ReleaseBatchClaim(context.Context, string, string) error { return nil }
+
+func (r *fakeOpenAIBatchRepo) sortedItems(batchID string) []*OpenAIBatchItem {
+	out := make([]*OpenAIBatchItem, 0, len(r.items))
+	for _, item := range r.items {
+		if item.BatchID == batchID {
+			out = append(out, item)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LineIndex < out[j].LineIndex })
+	return out
+}
+
+func (r *fakeOpenAIBatchRepo) ClaimPendingItems(_ context.Context, batchID string, limit int, _ time.Time) ([]OpenAIBatchItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []OpenAIBatchItem
+	for _, item := range r.sortedItems(batchID) {
+		if len(out) >= limit {
+			break
+		}
+		if item.Status == OpenAIBatchItemStatusPending {
+			item.Status = OpenAIBatchItemStatusRunning
+			item.Attempts++
+			out = append(out, *item)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeOpenAIBatchRepo) CompleteItem(_ context.Context, item *OpenAIBatchItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.items[item.ID]
+	stored.Status = item.Status
+	stored.ResponseStatus = item.ResponseStatus
+	stored.ResponseBody = item.ResponseBody
+	stored.RequestID = item.RequestID
+	stored.ErrorCode = item.ErrorCode
+	stored.ErrorMessage = item.ErrorMessage
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) RequeueItem(_ context.Context, itemID int64, errorMessage string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[itemID].Status = OpenAIBatchItemStatusPending
+	r.items[itemID].ErrorMessage = errorMessage
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) FailUnfinishedItems(_ context.Context, batchID, code, message string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, item := range r.sortedItems(batchID) {
+		if item.Status == OpenAIBatchItemStatusPending || item.Status == OpenAIBatchItemStatusRunning {
+			item.Status = OpenAIBatchItemStatusFailed
+			item.ErrorCode = code
+			item.ErrorMessage = message
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeOpenAIBatchRepo) RecordItemCost(_ context.Context, itemID int64, requestID string, cost float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.items[itemID].Cost = cost
+	r.items[itemID].BilledAt = &now
+	if r.items[itemID].RequestID == "" {
+		r.items[itemID].RequestID = requestID
+	}
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) GetBatchProgress(_ context.Context, batchID string) (*OpenAIBatchProgress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &OpenAIBatchProgress{}
+	for _, item := range r.sortedItems(batchID) {
+		switch item.Status {
+		case OpenAIBatchItemStatusPending:
+			p.Pending++
+		case OpenAIBatchItemStatusRunning:
+			p.Running++
+		case OpenAIBatchItemStatusSucceeded:
+			p.Succeeded++
+			if item.BilledAt == nil {
+				p.Unbilled++
+			}
+		case OpenAIBatchItemStatusFailed:
+			p.Failed++
+			if item.ErrorCode == OpenAIBatchItemErrorExpired {
+				p.Expired++
+			}
+		}
+		p.Cost += item.Cost
+	}
+	return p, nil
+}
+
+func (r *fakeOpenAIBatchRepo) ListBatchItems(_ context.Context, batchID string) ([]OpenAIBatchItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []OpenAIBatchItem
+	for _, item := range r.sortedItems(batchID) {
+		out = append(out, *item)
+	}
+	return out, nil
+}
+
+func (r *fakeOpenAIBatchRepo) MarkBatchFinalizing(_ context.Context, batchID string) (*OpenAIBatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.batches[batchID]
+	if b.Status == OpenAIBatchStatusInProgress {
+		b.Status = OpenAIBatchStatusFinalizing
+	}
+	if b.FinalizingAt == nil {
+		now := time.Now()
+		b.FinalizingAt = &now
+	}
+	cp := *b
+	return &cp, nil
+}
+
+func (r *fakeOpenAIBatchRepo) LockSettleAmount(_ context.Context, batchID string, amount float64) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.batches[batchID]
+	if b.SettleAmount == nil {
+		b.SettleAmount = &amount
+	}
+	return *b.SettleAmount, nil
+}
+
+func (r *fakeOpenAIBatchRepo) SetBatchError(_ context.Context, batchID, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches[batchID].LastError = message
+	return nil
+}
+
+func (r *fakeOpenAIBatchRepo) FinishBatch(_ context.Context, batchID string, finish *OpenAIBatchFinish) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.batches[batchID]
+	b.Status = finish.Status
+	b.ActualCost = finish.ActualCost
+	r.finished[batchID] = finish
+	return nil
+}
+
+type openAIBatchAPIKeyRepoStub struct {
+	APIKeyRepository
+	key *APIKey
+}
+
+func (s *openAIBatchAPIKeyRepoStub) GetByID(_ context.Context, id int64) (*APIKey, error) {
+	if s.key == nil || s.key.ID != id {
+		return nil, ErrAPIKeyNotFound
+	}
+	return s.key, nil
+}
+
+func newTestOpenAIBatchWorker(repo *fakeOpenAIBatchRepo, billing *fakeBatchImageBillingRepo, handler http.Handler) *OpenAIBatchWorker {
+	cfg := &config.Config{}
+	cfg.BatchAPI.Enabled = true
+	cfg.BatchAPI.PerBatchConcurrency = 4
+	w := NewOpenAIBatchWorker(repo, &openAIBatchAPIKeyRepoStub{key: &APIKey{ID: 7, UserID: 3, Key: "sk-batch"}}, nil, nil, billing, cfg)
+	w.SetHandler(handler)
+	return w
+}
+
+func seedOpenAIBatch(t *testing.T, repo *fakeOpenAIBatchRepo, customIDs ...string) *OpenAIBatch {
+	t.Helper()
+	now := time.Now()
+	batch := &OpenAIBatch{
+		ID: "batch_test", UserID: 3, APIKeyID: 7, GroupID: 11, Endpoint: "/v1/chat/completions",
+		Status: OpenAIBatchStatusInProgress, HoldAmount: 1, DiscountMultiplier: 0.5, HoldMultiplier: 0.6,
+		RequestTotal: len(customIDs), CreatedAt: now, ExpiresAt: now.Add(24 * time.Hour),
+	}
+	items := make([]OpenAIBatchItem, 0, len(customIDs))
+	for i, id := range customIDs {
+		items = append(items, OpenAIBatchItem{
+			LineIndex: i, CustomID: id, Method: "POST", URL: batch.Endpoint,
+			Body: []byte(`{"model":"gpt-4o-mini","messages":[]}`), Status: OpenAIBatchItemStatusPending,
+		})
+	}
+	require.NoError(t, repo.CreateBatch(context.Background(), batch, items))
+	return batch
+}
+
+func TestOpenAIBatchWorker_ExecutesItemsWithMarkerAndSettlesFromHold(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	billing := &fakeBatchImageBillingRepo{}
+	batch := seedOpenAIBatch(t, repo, "ok", "bad")
+
+	var mu sync.Mutex
+	seenAuth := map[string]string{}
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		exec := OpenAIBatchExecutionFromContext(r.Context())
+		if exec == nil || exec.BatchID != batch.ID || exec.DiscountMultiplier != 0.5 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		seenAuth[r.Header.Get("Authorization")] = r.URL.Path
+		mu.Unlock()
+		if exec.ItemID == 1 {
+			// 模拟计费钩子：折扣后的费用写回行上。
+			exec.recordCost(r.Context(), "req-ok", &CostBreakdown{ActualCost: 0.3})
+			rw.Header().Set("x-request-id", "req-ok")
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion"}`))
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		_, _ = rw.Write([]byte(`{"error":{"message":"bad request"}}`))
+	})
+	w := newTestOpenAIBatchWorker(repo, billing, handler)
+
+	require.NoError(t, w.processOnce(context.Background()))
+	require.Equal(t, map[string]string{"Bearer sk-batch": "/v1/chat/completions"}, seenAuth)
+
+	require.NoError(t, w.processOnce(context.Background()))
+	finish := repo.finished[batch.ID]
+	require.NotNil(t, finish)
+	require.Equal(t, OpenAIBatchStatusCompleted, finish.Status)
+	require.InDelta(t, 0.3, finish.ActualCost, 1e-9)
+	require.Len(t, billing.captures, 1)
+	require.InDelta(t, 0.3, billing.captures[0].ActualAmount.InexactFloat64(), 1e-9)
+	require.Equal(t, 1.0, billing.captures[0].HoldAmount.InexactFloat64())
+	require.Equal(t, BatchImageCaptureRequestID(batch.ID), billing.captures[0].RequestID)
+
+	require.NotNil(t, finish.OutputFile)
+	outLines := strings.Split(strings.TrimSpace(string(finish.OutputFile.Content)), "\n")
+	require.Len(t, outLines, 1)
+	require.Equal(t, "ok", gjson.Get(outLines[0], "custom_id").String())
+	require.Equal(t, int64(200), gjson.Get(outLines[0], "response.status_code").Int())
+	require.Equal(t, "req-ok", gjson.Get(outLines[0], "response.request_id").String())
+	require.Equal(t, "chatcmpl-1", gjson.Get(outLines[0], "response.body.id").String())
+
+	require.NotNil(t, finish.ErrorFile)
+	errLines := strings.Split(strings.TrimSpace(string(finish.ErrorFile.Content)), "\n")
+	require.Len(t, errLines, 1)
+	require.Equal(t, "bad", gjson.Get(errLines[0], "custom_id").String())
+	require.Equal(t, int64(400), gjson.Get(errLines[0], "response.status_code").Int())
+}
+
+func TestOpenAIBatchWorker_RetryableStatusRequeuesUntilAttemptsExhausted(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	batch := seedOpenAIBatch(t, repo, "flaky")
+	calls := 0
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusTooManyRequests)
+		_, _ = rw.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	})
+	w := newTestOpenAIBatchWorker(repo, &fakeBatchImageBillingRepo{}, handler)
+
+	for i := 0; i < openAIBatchMaxItemAttempts; i++ {
+		b := *repo.batches[batch.ID]
+		w.advance(context.Background(), &b)
+	}
+	require.Equal(t, openAIBatchMaxItemAttempts, calls)
+	item := repo.items[1]
+	require.Equal(t, OpenAIBatchItemStatusFailed, item.Status)
+	require.Equal(t, http.StatusTooManyRequests, item.ResponseStatus)
+}
+
+func TestOpenAIBatchWorker_CancellingFailsPendingRowsAndReleasesUnusedHold(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	billing := &fakeBatchImageBillingRepo{}
+	batch := seedOpenAIBatch(t, repo, "a", "b")
+	_, err := repo.MarkBatchCancelling(context.Background(), batch.UserID, batch.ID)
+	require.NoError(t, err)
+	dispatched := false
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { dispatched = true })
+	w := newTestOpenAIBatchWorker(repo, billing, handler)
+
+	require.NoError(t, w.processOnce(context.Background()))
+	require.False(t, dispatched, "cancelled batch must not dispatch requests")
+	finish := repo.finished[batch.ID]
+	require.NotNil(t, finish)
+	require.Equal(t, OpenAIBatchStatusCancelled, finish.Status)
+	require.Nil(t, finish.OutputFile)
+	require.NotNil(t, finish.ErrorFile)
+	require.Contains(t, string(finish.ErrorFile.Content), OpenAIBatchItemErrorCancelled)
+	require.Len(t, billing.captures, 1)
+	require.Equal(t, 0.0, billing.captures[0].ActualAmount.InexactFloat64())
+}
+
+func TestOpenAIBatchWorker_ExpiredBatchFinishesAsExpired(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	batch := seedOpenAIBatch(t, repo, "late")
+	repo.batches[batch.ID].ExpiresAt = time.Now().Add(-time.Minute)
+	w := newTestOpenAIBatchWorker(repo, &fakeBatchImageBillingRepo{}, http.NotFoundHandler())
+
+	require.NoError(t, w.processOnce(context.Background()))
+	require.Equal(t, OpenAIBatchStatusExpired, repo.finished[batch.ID].Status)
+	require.Equal(t, OpenAIBatchItemErrorExpired, repo.items[1].ErrorCode)
+}
+
+func TestOpenAIBatchWorker_WaitsForLateBillingBeforeSettling(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	billing := &fakeBatchImageBillingRepo{}
+	batch := seedOpenAIBatch(t, repo, "slow-billing")
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	})
+	w := newTestOpenAIBatchWorker(repo, billing, handler)
+
+	require.NoError(t, w.processOnce(context.Background()))
+	require.NoError(t, w.processOnce(context.Background()))
+	require.Nil(t, repo.finished[batch.ID], "unbilled rows should hold settlement within the grace period")
+	require.Empty(t, billing.captures)
+
+	require.NoError(t, repo.RecordItemCost(context.Background(), 1, "req-late", 0.2))
+	require.NoError(t, w.processOnce(context.Background()))
+	require.NotNil(t, repo.finished[batch.ID])
+	require.InDelta(t, 0.2, billing.captures[0].ActualAmount.InexactFloat64(), 1e-9)
+}
+
+func TestOpenAIBatchWorker_CarriesSubmitterClientIP(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	batch := seedOpenAIBatch(t, repo, "ip")
+	repo.batches[batch.ID].ClientIP = "203.0.113.9"
+
+	var remoteAddr, execIP string
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+		execIP = OpenAIBatchExecutionFromContext(r.Context()).ClientIP
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	})
+	w := newTestOpenAIBatchWorker(repo, &fakeBatchImageBillingRepo{}, handler)
+
+	require.NoError(t, w.processOnce(context.Background()))
+	require.Equal(t, "203.0.113.9:0", remoteAddr)
+	require.Equal(t, "203.0.113.9", execIP)
+}
+
+func TestOpenAIBatchWorker_DebitsCostAboveHoldFromBalance(t *testing.T) {
+	repo := newFakeOpenAIBatchRepo()
+	billing := &fakeBatchImageBillingRepo{}
+	batch := seedOpenAIBatch(t, repo, "a")
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// 在途请求的费用越过冻结额（HoldAmount=1）。
+		OpenAIBatchExecutionFromContext(r.Context()).recordCost(r.Context(), "req-a", &CostBreakdown{ActualCost: 1.25})
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte(`{}`))
+	})
+	w := newTestOpenAIBatchWorker(repo, billing, handler)
+
+	require.NoError(t, w.processOnce(context.Background()))
+	require.NoError(t, w.processOnce(context.Background()))
+	finish := repo.finished[batch.ID]
+	require.NotNil(t, finish)
+	require.InDelta(t, 1.25, finish.ActualCost, 1e-9)
+	require.Len(t, billing.captures, 1)
+	require.InDelta(t, 1.0, billing.captures[0].ActualAmount.InexactFloat64(), 1e-9)
+	require.Len(t, billing.commands, 1)
+	require.Equal(t, openAIBatchOverageRequestPrefix+batch.ID, billing.commands[0].RequestID)
+	require.Equal(t, batch.UserID, billing.commands[0].UserID)
+	require.InDelta(t, 0.25, billing.commands[0].BalanceCost.InexactFloat64(), 1e-9)
+}
@@ -207,8 +207,8 @@ func (s *OpenAIGatewayService) appendOpenAICompactFallbackRetryOps(
 		requestID = resp.Header.Get("x-request-id")
 	}
 	detail := ""
-	if s != nil && s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-		maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+	if s != nil && s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+		maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 		if maxBytes <= 0 {
 			maxBytes = 2048
 		}
@@ -17,12 +17,37 @@ import (
 	"go.uber.org/zap"
 )
 
+// openAINativeJSONEndpoint 描述一个原样透传 JSON 的 OpenAI 兼容端点（embeddings / rerank / moderations）：
+// 只替换 model、不做协议转换，按 Usage 从响应体提取用量。
+type openAINativeJSONEndpoint struct {
+	Name  string
+	Path  string
+	Usage func(body []byte) OpenAIUsage
+}
+
+var openAIEmbeddingsEndpoint = openAINativeJSONEndpoint{
+	Name:  "embeddings",
+	Path:  "/v1/embeddings",
+	Usage: extractOpenAIEmbeddingsUsage,
+}
+
 func (s *OpenAIGatewayService) ForwardEmbeddings(
 	ctx context.Context,
 	c *gin.Context,
 	account *Account,
 	body []byte,
 	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	return s.forwardOpenAINativeJSON(ctx, c, account, body, defaultMappedModel, openAIEmbeddingsEndpoint)
+}
+
+func (s *OpenAIGatewayService) forwardOpenAINativeJSON(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	defaultMappedModel string,
+	endpoint openAINativeJSONEndpoint,
 ) (*OpenAIForwardResult, error) {
 	startTime := time.Now()
 
@@ -40,7 +65,8 @@ func (s *OpenAIGatewayService) ForwardEmbeddings(
 		upstreamBody = ReplaceModelInBody(body, upstreamModel)
 	}
 
-	logger.L().Debug("openai embeddings: forwarding",
+	logger.L().Debug("openai native endpoint: forwarding",
+		zap.String("endpoint", endpoint.Name),
 		zap.Int64("account_id", account.ID),
 		zap.String("original_model", originalModel),
 		zap.String("billing_model", billingModel),
@@ -52,7 +78,7 @@ func (s *OpenAIGatewayService) ForwardEmbeddings(
 		return nil, fmt.Errorf("account %d missing api_key", account.ID)
 	}
 	// 协议感知：Anthropic 协议账号的凭证 base_url 指向 /anthropic 端点，
-	// embeddings 需使用 OpenAI 格式 base。
+	// embeddings / rerank / moderations 需使用 OpenAI 格式 base。
 	baseURL := account.GetOpenAIFormatBaseURL()
 	if baseURL == "" {
 		baseURL = "https://api.openai.com"
@@ -61,7 +87,7 @@ func (s *OpenAIGatewayService) ForwardEmbeddings(
 	if err != nil {
 		return nil, fmt.Errorf("invalid base_url: %w", err)
 	}
-	targetURL := buildOpenAIEmbeddingsURL(validatedURL)
+	targetURL := buildOpenAIEndpointURL(validatedURL, endpoint.Path)
 
 	upstreamCtx, releaseUpstreamCtx := detachUpstreamContext(ctx)
 	upstreamReq, err := http.NewRequestWithContext(upstreamCtx, http.MethodPost, targetURL, bytes.NewReader(upstreamBody))
@@ -118,8 +144,8 @@ func (s *OpenAIGatewayService) ForwardEmbeddings(
 		upstreamMsg = sanitizeUpstreamErrorMessage(upstreamMsg)
 		if s.shouldFailoverOpenAIUpstreamResponse(resp.StatusCode, upstreamMsg, respBody) {
 			upstreamDetail := ""
-			if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
-				maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+			if s.cfg != nil && s.cfg.Live().Gateway.LogUpstreamErrorBody {
+				maxBytes := s.cfg.Live().Gateway.LogUpstreamErrorBodyMaxBytes
 				if maxBytes <= 0 {
 					maxBytes = 2048
 				}
@@ -161,7 +187,7 @@ func (s *OpenAIGatewayService) ForwardEmbeddings(
 
 	return &OpenAIForwardResult{
 		RequestID:     firstNonEmptyString(resp.Header.Get("x-request-id"), resp.Header.Get("request-id")),
-		Usage:         extractOpenAIEmbeddingsUsage(respBody),
+		Usage:         endpoint.Usage(respBody),
 		Model:         originalModel,
 		BillingModel:  billingModel,
 		UpstreamModel: upstreamModel,
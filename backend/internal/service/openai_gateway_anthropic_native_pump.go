@@ -119,8 +119,8 @@ func (p *anthropicNativeLinePump) stop() {
 // anthropicNativeStreamInterval 返回本组转换路径适用的读间隔上限；
 // gateway.stream_data_interval_timeout <= 0 时视为禁用。
 func (s *OpenAIGatewayService) anthropicNativeStreamInterval() time.Duration {
-	if s.cfg != nil && s.cfg.Gateway.StreamDataIntervalTimeout > 0 {
-		return time.Duration(s.cfg.Gateway.StreamDataIntervalTimeout) * time.Second
+	if s.cfg != nil && s.cfg.Live().Gateway.StreamDataIntervalTimeout > 0 {
+		return time.Duration(s.cfg.Live().Gateway.StreamDataIntervalTimeout) * time.Second
 	}
 	return 0
 }
@@ -1208,6 +1208,7 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 	if preferLowUpstreamRate {
 		rateOrder = newOpenAILegacyUpstreamRateOrder(candidates, time.Now(), s.openAIOAuthSchedulingRateMultiplier(ctx))
 	}
+	accountScheduler := s.resolveOpenAIGroupAccountScheduler(ctx, groupID)
 
 	accountLoads := make([]AccountWithConcurrency, 0, len(candidates))
 	for _, acc := range candidates {
@@ -1256,6 +1257,14 @@ func (s *OpenAIGatewayService) selectAccountWithLoadAwareness(ctx context.Contex
 			}
 		})
 		shuffleWithinSortGroups(available)
+		// 分组配置了非默认调度策略时，由策略决定尝试顺序
+		if !IsDefaultAccountScheduler(accountScheduler) {
+			available = accountsWithLoadFromCandidates(accountScheduler.Order(AccountScheduleRequest{
+				GroupID:  groupID,
+				Platform: platform,
+				Model:    requestedModel,
+			}, accountScheduleCandidatesFromLoads(available)))
+		}
 		if rateOrder.enabled {
 			sort.SliceStable(available, func(i, j int) bool {
 				return rateOrder.compare(available[i].account, available[j].account) < 0
@@ -1656,7 +1665,7 @@ func (s *OpenAIGatewayService) newAcquiredSelectionResult(ctx context.Context, a
 
 func (s *OpenAIGatewayService) schedulingConfig() config.GatewaySchedulingConfig {
 	if s.cfg != nil {
-		return s.cfg.Gateway.Scheduling
+		return s.cfg.Live().Gateway.Scheduling
 	}
 	return config.GatewaySchedulingConfig{
 		StickySessionMaxWaiting:  3,
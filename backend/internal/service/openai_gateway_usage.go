@@ -610,8 +610,7 @@ func (s *OpenAIGatewayService) calculateOpenAIRecordUsageCost(
 		return tokenCost, nil
 	}
 	// Additive: tokens + search surcharge.
-	tokenCost.TotalCost += searchCost.TotalCost
-	tokenCost.ActualCost += searchCost.ActualCost
+	tokenCost.add(searchCost)
 	return tokenCost, nil
 }
 
@@ -843,7 +842,8 @@ func groupMediaPricingLooksIncomplete(group *Group) bool {
 		group.AudioRealtimePricePerMin != nil ||
 		group.AudioTTSPricePerMillionChars != nil ||
 		group.AudioSTTPricePerHour != nil ||
-		group.WebSearchPricePerCall != nil {
+		group.WebSearchPricePerCall != nil ||
+		group.WebFetchPricePerCall != nil {
 		return false
 	}
 	return group.ImagePrice1K == nil && group.ImagePrice2K == nil && group.ImagePrice4K == nil &&
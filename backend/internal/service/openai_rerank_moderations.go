@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// OpenAIModerationsDefaultModel 客户端未指定 model 时 /v1/moderations 使用的模型（与 OpenAI 官方默认一致）。
+// 路由、计费与用量记录都需要模型名，因此由网关补齐后再转发。
+const OpenAIModerationsDefaultModel = "omni-moderation-latest"
+
+var openAIRerankEndpoint = openAINativeJSONEndpoint{
+	Name:  "rerank",
+	Path:  "/v1/rerank",
+	Usage: extractOpenAIRerankUsage,
+}
+
+// moderations 上游通常不返回 usage；未返回时用量为 0，仅渠道按次定价产生费用。
+var openAIModerationsEndpoint = openAINativeJSONEndpoint{
+	Name:  "moderations",
+	Path:  "/v1/moderations",
+	Usage: extractOpenAIEmbeddingsUsage,
+}
+
+// ForwardRerank 透传 Cohere / Jina 风格的 /v1/rerank 请求。
+func (s *OpenAIGatewayService) ForwardRerank(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	return s.forwardOpenAINativeJSON(ctx, c, account, body, defaultMappedModel, openAIRerankEndpoint)
+}
+
+// ForwardModerations 透传 OpenAI 兼容的 /v1/moderations 请求。
+func (s *OpenAIGatewayService) ForwardModerations(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	return s.forwardOpenAINativeJSON(ctx, c, account, body, defaultMappedModel, openAIModerationsEndpoint)
+}
+
+// extractOpenAIRerankUsage 兼容 Jina / Voyage（usage.total_tokens）与 Cohere（meta.billed_units.input_tokens）。
+// Cohere 仅返回 search_units 时用量为 0，需要在渠道定价中按次计费。
+func extractOpenAIRerankUsage(body []byte) OpenAIUsage {
+	if usage := extractOpenAIEmbeddingsUsage(body); usage.InputTokens > 0 {
+		return usage
+	}
+	return OpenAIUsage{
+		InputTokens: firstPositiveGJSONInt(gjson.GetBytes(body, "meta.billed_units.input_tokens")),
+	}
+}
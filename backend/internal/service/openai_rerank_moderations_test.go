@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newOpenAINativeJSONTestAccount(id int64, baseURL string) *Account {
+	return &Account{
+		ID:       id,
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{
+			"api_key":  "sk-test",
+			"base_url": baseURL,
+		},
+	}
+}
+
+func TestForwardRerank_JinaPassthroughRecordsTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reqBody := []byte(`{"model":"jina-reranker-v2-base-multilingual","query":"q","documents":["a","b"],"top_n":1}`)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/rerank", bytes.NewReader(reqBody))
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "X-Request-Id": []string{"rr-rid"}},
+		Body: io.NopCloser(strings.NewReader(`{
+			"model":"jina-reranker-v2-base-multilingual",
+			"usage":{"total_tokens":42},
+			"results":[{"index":1,"relevance_score":0.9}]
+		}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardRerank(context.Background(), c, newOpenAINativeJSONTestAccount(51, "https://api.jina.ai"), reqBody, "")
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "rr-rid", result.RequestID)
+	require.Equal(t, 42, result.Usage.InputTokens)
+	require.Equal(t, "https://api.jina.ai/v1/rerank", upstream.lastReq.URL.String())
+	require.Equal(t, int64(2), gjson.GetBytes(upstream.lastBody, "documents.#").Int())
+	require.Equal(t, int64(1), gjson.GetBytes(rec.Body.Bytes(), "results.0.index").Int())
+}
+
+func TestExtractOpenAIRerankUsage_CohereBilledUnits(t *testing.T) {
+	usage := extractOpenAIRerankUsage([]byte(`{"results":[],"meta":{"billed_units":{"search_units":1,"input_tokens":17}}}`))
+	require.Equal(t, 17, usage.InputTokens)
+
+	usage = extractOpenAIRerankUsage([]byte(`{"results":[],"meta":{"billed_units":{"search_units":1}}}`))
+	require.Zero(t, usage.InputTokens)
+}
+
+func TestForwardModerations_PassthroughWithoutUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reqBody := []byte(`{"model":"omni-moderation-latest","input":"hello"}`)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/moderations", bytes.NewReader(reqBody))
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id":"modr-1","model":"omni-moderation-2024-09-26","results":[{"flagged":false}]}`)),
+	}}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	result, err := svc.ForwardModerations(context.Background(), c, newOpenAINativeJSONTestAccount(52, "https://api.openai.com/v1"), reqBody, "")
+
+	require.NoError(t, err)
+	require.Equal(t, "https://api.openai.com/v1/moderations", upstream.lastReq.URL.String())
+	require.Equal(t, "omni-moderation-latest", result.Model)
+	require.Equal(t, OpenAIUsage{}, result.Usage)
+	require.False(t, gjson.GetBytes(rec.Body.Bytes(), "results.0.flagged").Bool())
+}
+
+func TestSupportsOpenAIEndpointCapability_RerankModerationsRequireAPIKey(t *testing.T) {
+	apiKeyAccount := newOpenAINativeJSONTestAccount(53, "https://api.openai.com")
+	require.True(t, apiKeyAccount.SupportsOpenAIEndpointCapability(OpenAIEndpointCapabilityRerank))
+	require.True(t, apiKeyAccount.SupportsOpenAIEndpointCapability(OpenAIEndpointCapabilityModerations))
+
+	apiKeyAccount.Credentials["openai_capabilities"] = []any{"embeddings", "rerank"}
+	require.True(t, apiKeyAccount.SupportsOpenAIEndpointCapability(OpenAIEndpointCapabilityRerank))
+	require.False(t, apiKeyAccount.SupportsOpenAIEndpointCapability(OpenAIEndpointCapabilityModerations))
+
+	oauthAccount := &Account{ID: 54, Platform: PlatformOpenAI, Type: AccountTypeOAuth}
+	require.False(t, oauthAccount.SupportsOpenAIEndpointCapability(OpenAIEndpointCapabilityRerank))
+	require.False(t, oauthAccount.SupportsOpenAIEndpointCapability(OpenAIEndpointCapabilityModerations))
+}
@@ -154,7 +154,7 @@ func (s *OpsAggregationService) aggregateHourly() {
 		if !s.cfg.Ops.Enabled {
 			return
 		}
-		if !s.cfg.Ops.Aggregation.Enabled {
+		if !s.cfg.Live().Ops.Aggregation.Enabled {
 			return
 		}
 	}
@@ -254,7 +254,7 @@ func (s *OpsAggregationService) aggregateDaily() {
 		if !s.cfg.Ops.Enabled {
 			return
 		}
-		if !s.cfg.Ops.Aggregation.Enabled {
+		if !s.cfg.Live().Ops.Aggregation.Enabled {
 			return
 		}
 	}
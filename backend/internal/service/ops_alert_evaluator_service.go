@@ -51,6 +51,10 @@ type OpsAlertEvaluatorService struct {
 
 	emailLimiter *slidingWindowLimiter
 
+	notifyMu       sync.RWMutex
+	notifiers      map[string]OpsAlertNotifier
+	notifyLimiters map[int64]map[string]*slidingWindowLimiter
+
 	skipLogMu sync.Mutex
 	skipLogAt time.Time
 
@@ -80,9 +84,25 @@ func NewOpsAlertEvaluatorService(
 		instanceID:   uuid.NewString(),
 		ruleStates:   map[int64]*opsAlertRuleState{},
 		emailLimiter: newSlidingWindowLimiter(0, time.Hour),
+
+		notifiers:      defaultOpsAlertNotifiers(newOpsAlertNotifyHTTPClient(cfg)),
+		notifyLimiters: map[int64]map[string]*slidingWindowLimiter{},
 	}
 }
 
+// RegisterAlertNotifier 注册（或替换）一种告警通知渠道实现。
+func (s *OpsAlertEvaluatorService) RegisterAlertNotifier(notifier OpsAlertNotifier) {
+	if s == nil || notifier == nil {
+		return
+	}
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	if s.notifiers == nil {
+		s.notifiers = map[string]OpsAlertNotifier{}
+	}
+	s.notifiers[notifier.Type()] = notifier
+}
+
 func (s *OpsAlertEvaluatorService) Start() {
 	if s == nil {
 		return
@@ -199,6 +219,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 	eventsCreated := 0
 	eventsResolved := 0
 	emailsSent := 0
+	notificationsSent := 0
 
 	now := time.Now().UTC()
 	safeEnd := now.Truncate(time.Minute)
@@ -295,6 +316,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				if s.maybeSendAlertEmail(ctx, runtimeCfg, rule, created) {
 					emailsSent++
 				}
+				notificationsSent += s.sendAlertNotifications(ctx, runtimeCfg, rule, created)
 			}
 			continue
 		}
@@ -306,11 +328,15 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] resolve event failed (event=%d): %v", activeEvent.ID, err)
 			} else {
 				eventsResolved++
+				resolved := *activeEvent
+				resolved.Status = OpsAlertStatusResolved
+				resolved.ResolvedAt = &resolvedAt
+				notificationsSent += s.sendAlertNotifications(ctx, runtimeCfg, rule, &resolved)
 			}
 		}
 	}
 
-	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent), 2048)
+	result := truncateString(fmt.Sprintf("rules=%d enabled=%d evaluated=%d created=%d resolved=%d emails_sent=%d notifications_sent=%d", rulesTotal, rulesEnabled, rulesEvaluated, eventsCreated, eventsResolved, emailsSent, notificationsSent), 2048)
 	s.recordHeartbeatSuccess(runAt, time.Since(startedAt), result)
 }
 
@@ -329,6 +355,14 @@ func (s *OpsAlertEvaluatorService) pruneRuleStates(rules []*OpsAlertRule) {
 			delete(s.ruleStates, id)
 		}
 	}
+
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	for id := range s.notifyLimiters {
+		if _, ok := live[id]; !ok {
+			delete(s.notifyLimiters, id)
+		}
+	}
 }
 
 func (s *OpsAlertEvaluatorService) resetRuleState(ruleID int64, now time.Time) {
@@ -747,6 +781,67 @@ func (s *OpsAlertEvaluatorService) maybeSendAlertEmail(ctx context.Context, runt
 	return anySent
 }
 
+// sendAlertNotifications 向规则配置的各渠道发送 firing / resolved 通知，返回成功发送的渠道数。
+// 静默、最低级别与限流按渠道独立判断；单个渠道失败不影响其它渠道。
+func (s *OpsAlertEvaluatorService) sendAlertNotifications(ctx context.Context, runtimeCfg *OpsAlertRuntimeSettings, rule *OpsAlertRule, event *OpsAlertEvent) int {
+	if s == nil || rule == nil || event == nil || len(rule.NotifyChannels) == 0 {
+		return 0
+	}
+
+	now := time.Now().UTC()
+	if runtimeCfg != nil && runtimeCfg.Silencing.Enabled && isOpsAlertSilenced(now, rule, event, runtimeCfg.Silencing) {
+		return 0
+	}
+
+	sent := 0
+	for i := range rule.NotifyChannels {
+		channel := &rule.NotifyChannels[i]
+		s.notifyMu.RLock()
+		notifier := s.notifiers[channel.Type]
+		s.notifyMu.RUnlock()
+		if notifier == nil {
+			continue
+		}
+		if !shouldSendOpsAlertEmailByMinSeverity(channel.MinSeverity, rule.Severity) {
+			continue
+		}
+		if !s.notifyLimiter(rule.ID, i, channel).Allow(now) {
+			continue
+		}
+
+		notification := renderOpsAlertNotification(channel, rule, event)
+		if err := notifier.Send(ctx, channel, notification); err != nil {
+			logger.LegacyPrintf("service.ops_alert_evaluator", "[OpsAlertEvaluator] notify %s failed (rule=%d event=%d status=%s): %v", channel.Type, rule.ID, event.ID, notification.Status, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// notifyLimiter 返回规则下某个渠道的限流器；渠道配置变化（类型/目标）时会换成新的计数窗口。
+func (s *OpsAlertEvaluatorService) notifyLimiter(ruleID int64, index int, channel *OpsAlertNotifyChannel) *slidingWindowLimiter {
+	key := fmt.Sprintf("%d|%s|%s|%s", index, channel.Type, channel.URL, channel.ChatID)
+
+	s.notifyMu.Lock()
+	defer s.notifyMu.Unlock()
+	if s.notifyLimiters == nil {
+		s.notifyLimiters = map[int64]map[string]*slidingWindowLimiter{}
+	}
+	byRule := s.notifyLimiters[ruleID]
+	if byRule == nil {
+		byRule = map[string]*slidingWindowLimiter{}
+		s.notifyLimiters[ruleID] = byRule
+	}
+	limiter := byRule[key]
+	if limiter == nil {
+		limiter = newSlidingWindowLimiter(0, time.Hour)
+		byRule[key] = limiter
+	}
+	limiter.SetLimit(channel.RateLimitPerHour)
+	return limiter
+}
+
 func opsAlertEmailVariables(rule *OpsAlertRule, event *OpsAlertEvent) map[string]string {
 	variables := map[string]string{
 		"rule_name":         "-",
@@ -31,6 +31,9 @@ type OpsAlertRule struct {
 
 	NotifyEmail bool `json:"notify_email"`
 
+	// NotifyChannels 额外的 IM / webhook 通知渠道，见 ops_alert_notifier.go。
+	NotifyChannels []OpsAlertNotifyChannel `json:"notify_channels"`
+
 	Filters map[string]any `json:"filters,omitempty"`
 
 	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
@@ -0,0 +1,611 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+)
+
+// Ops alert chat/webhook notifiers.
+//
+// 每条 OpsAlertRule 可以挂多个 notify_channels；邮件仍走原有的 NotifyEmail 开关，
+// 这里的渠道与之并列。静默（runtime silencing）与滑动窗口限流对每个渠道独立生效，
+// 规则恢复时会按 resolved 模板再发一次。
+
+const (
+	OpsAlertChannelSlack    = "slack"
+	OpsAlertChannelFeishu   = "feishu"
+	OpsAlertChannelDingTalk = "dingtalk"
+	OpsAlertChannelTelegram = "telegram"
+	OpsAlertChannelWebhook  = "webhook"
+
+	opsAlertMaxNotifyChannels   = 10
+	opsAlertMaxTemplateLength   = 4000
+	opsAlertNotifyTimeout       = 10 * time.Second
+	opsAlertNotifyMaxRespBytes  = 4 << 10
+	opsAlertTelegramAPIBaseURL  = "https://api.telegram.org"
+	opsAlertWebhookEventFiring  = "ops_alert.firing"
+	opsAlertWebhookEventResolve = "ops_alert.resolved"
+)
+
+const defaultOpsAlertFiringTemplate = `[Ops Alert][{{.severity}}] {{.rule_name}}
+Status: {{.alert_status}}
+Metric: {{.metric_type}} {{.operator}} {{.threshold_value}} (current {{.metric_value}})
+Fired at: {{.triggered_at}}
+{{.alert_description}}`
+
+const defaultOpsAlertResolvedTemplate = `[Ops Alert Resolved][{{.severity}}] {{.rule_name}}
+Status: {{.alert_status}}
+Metric: {{.metric_type}} {{.operator}} {{.threshold_value}}
+Fired at: {{.triggered_at}}
+Resolved at: {{.resolved_at}}`
+
+// OpsAlertNotifyChannel 规则级的通知渠道配置。
+//
+// URL：slack / feishu / dingtalk 的机器人 webhook 地址，或通用 webhook 的接收地址；
+// Secret：飞书 / 钉钉的加签密钥，或通用 webhook 的 HMAC 签名密钥；
+// BotToken + ChatID：Telegram。
+// Template / ResolvedTemplate 为 text/template，可用变量与告警邮件模板一致，另含 resolved_at。
+type OpsAlertNotifyChannel struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+
+	URL      string `json:"url,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+	BotToken string `json:"bot_token,omitempty"`
+	ChatID   string `json:"chat_id,omitempty"`
+
+	Template         string `json:"template,omitempty"`
+	ResolvedTemplate string `json:"resolved_template,omitempty"`
+
+	// MinSeverity 取值同邮件配置（critical / warning / info），为空表示不过滤。
+	MinSeverity string `json:"min_severity,omitempty"`
+	// RateLimitPerHour <= 0 表示不限流。
+	RateLimitPerHour int `json:"rate_limit_per_hour,omitempty"`
+}
+
+// OpsAlertNotification 渲染后的单条通知。
+type OpsAlertNotification struct {
+	Status string // firing / resolved
+	Title  string
+	Text   string
+	Rule   *OpsAlertRule
+	Event  *OpsAlertEvent
+}
+
+// OpsAlertNotifier 是一种通知渠道的实现；新增渠道只需实现该接口并通过
+// OpsAlertEvaluatorService.RegisterAlertNotifier 注册。
+type OpsAlertNotifier interface {
+	Type() string
+	Validate(channel *OpsAlertNotifyChannel) error
+	Send(ctx context.Context, channel *OpsAlertNotifyChannel, notification *OpsAlertNotification) error
+}
+
+func newOpsAlertNotifyHTTPClient(cfg *config.Config) *http.Client {
+	allowPrivate := cfg != nil && cfg.Security.URLAllowlist.AllowPrivateHosts
+	transport := &http.Transport{
+		MaxIdleConns:          16,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: opsAlertNotifyTimeout,
+	}
+	if allowPrivate {
+		transport.DialContext = (&net.Dialer{Timeout: monitorDialTimeout, KeepAlive: monitorDialKeepAlive}).DialContext
+	} else {
+		transport.DialContext = safeDialContext
+	}
+	return &http.Client{
+		Timeout:   opsAlertNotifyTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func defaultOpsAlertNotifiers(client *http.Client) map[string]OpsAlertNotifier {
+	notifiers := []OpsAlertNotifier{
+		&opsAlertSlackNotifier{client: client},
+		&opsAlertFeishuNotifier{client: client},
+		&opsAlertDingTalkNotifier{client: client},
+		&opsAlertTelegramNotifier{client: client, apiBaseURL: opsAlertTelegramAPIBaseURL},
+		&opsAlertWebhookNotifier{client: client},
+	}
+	out := make(map[string]OpsAlertNotifier, len(notifiers))
+	for _, n := range notifiers {
+		out[n.Type()] = n
+	}
+	return out
+}
+
+// NormalizeOpsAlertNotifyChannels 校验并规整规则上的通知渠道配置（保存规则时调用）。
+func NormalizeOpsAlertNotifyChannels(channels []OpsAlertNotifyChannel, cfg *config.Config) ([]OpsAlertNotifyChannel, error) {
+	if len(channels) > opsAlertMaxNotifyChannels {
+		return nil, fmt.Errorf("notify_channels supports at most %d entries", opsAlertMaxNotifyChannels)
+	}
+	notifiers := defaultOpsAlertNotifiers(nil)
+	allowInsecure, allowPrivate := false, false
+	if cfg != nil {
+		allowInsecure = cfg.Security.URLAllowlist.AllowInsecureHTTP
+		allowPrivate = cfg.Security.URLAllowlist.AllowPrivateHosts
+	}
+
+	out := make([]OpsAlertNotifyChannel, 0, len(channels))
+	for i := range channels {
+		ch := channels[i]
+		ch.Type = strings.ToLower(strings.TrimSpace(ch.Type))
+		ch.Name = strings.TrimSpace(ch.Name)
+		ch.URL = strings.TrimSpace(ch.URL)
+		ch.Secret = strings.TrimSpace(ch.Secret)
+		ch.BotToken = strings.TrimSpace(ch.BotToken)
+		ch.ChatID = strings.TrimSpace(ch.ChatID)
+		ch.MinSeverity = strings.ToLower(strings.TrimSpace(ch.MinSeverity))
+
+		notifier, ok := notifiers[ch.Type]
+		if !ok {
+			return nil, fmt.Errorf("notify_channels[%d].type must be one of: slack, feishu, dingtalk, telegram, webhook", i)
+		}
+		if ch.URL != "" {
+			normalized, err := urlvalidator.ValidateHTTPURL(ch.URL, allowInsecure, urlvalidator.ValidationOptions{AllowPrivate: allowPrivate})
+			if err != nil {
+				return nil, fmt.Errorf("notify_channels[%d].url is invalid: %v", i, err)
+			}
+			ch.URL = normalized
+		}
+		if err := notifier.Validate(&ch); err != nil {
+			return nil, fmt.Errorf("notify_channels[%d]: %v", i, err)
+		}
+		switch ch.MinSeverity {
+		case "", "critical", "warning", "info":
+		default:
+			return nil, fmt.Errorf("notify_channels[%d].min_severity must be one of: critical, warning, info", i)
+		}
+		if ch.RateLimitPerHour < 0 {
+			return nil, fmt.Errorf("notify_channels[%d].rate_limit_per_hour must be >= 0", i)
+		}
+		for _, tpl := range []string{ch.Template, ch.ResolvedTemplate} {
+			if len(tpl) > opsAlertMaxTemplateLength {
+				return nil, fmt.Errorf("notify_channels[%d] template exceeds %d characters", i, opsAlertMaxTemplateLength)
+			}
+			if _, err := parseOpsAlertTemplate(tpl); err != nil {
+				return nil, fmt.Errorf("notify_channels[%d] template is invalid: %v", i, err)
+			}
+		}
+		out = append(out, ch)
+	}
+	return out, nil
+}
+
+// opsAlertSecretMask 出现在渠道字段里表示该值是读接口返回的脱敏占位，而非用户输入。
+const opsAlertSecretMask = "********"
+
+// MaskOpsAlertNotifyChannels 返回脱敏后的渠道副本，供管理端读取：
+// Secret / BotToken 只保留末 4 位，URL 保留 scheme 与 host（机器人 webhook 的令牌在路径或查询串里）。
+func MaskOpsAlertNotifyChannels(channels []OpsAlertNotifyChannel) []OpsAlertNotifyChannel {
+	out := make([]OpsAlertNotifyChannel, len(channels))
+	for i, ch := range channels {
+		ch.URL = maskOpsAlertNotifyURL(ch.URL)
+		ch.Secret = maskOpsAlertSecret(ch.Secret)
+		ch.BotToken = maskOpsAlertSecret(ch.BotToken)
+		out[i] = ch
+	}
+	return out
+}
+
+func maskOpsAlertSecret(secret string) string {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 8 {
+		return opsAlertSecretMask
+	}
+	return opsAlertSecretMask + secret[len(secret)-4:]
+}
+
+func maskOpsAlertNotifyURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return maskOpsAlertSecret(raw)
+	}
+	if u.User == nil && u.RawQuery == "" && strings.Trim(u.Path, "/") == "" {
+		return raw
+	}
+	tail := ""
+	if len(raw) > 4 {
+		tail = raw[len(raw)-4:]
+	}
+	return u.Scheme + "://" + u.Host + "/" + opsAlertSecretMask + tail
+}
+
+// restoreMaskedOpsAlertNotifyChannels 把管理端原样提交回来的脱敏占位换回已保存的值。
+// 优先匹配同位置、同类型的渠道，其次匹配任一同类型渠道；找不到对应值时要求重新填写，
+// 避免把占位字符串当作密钥保存。
+func restoreMaskedOpsAlertNotifyChannels(channels, stored []OpsAlertNotifyChannel) error {
+	for i := range channels {
+		ch := &channels[i]
+		fields := []struct {
+			name  string
+			value *string
+			get   func(*OpsAlertNotifyChannel) string
+			mask  func(string) string
+		}{
+			{"url", &ch.URL, func(c *OpsAlertNotifyChannel) string { return c.URL }, maskOpsAlertNotifyURL},
+			{"secret", &ch.Secret, func(c *OpsAlertNotifyChannel) string { return c.Secret }, maskOpsAlertSecret},
+			{"bot_token", &ch.BotToken, func(c *OpsAlertNotifyChannel) string { return c.BotToken }, maskOpsAlertSecret},
+		}
+		for _, field := range fields {
+			value := strings.TrimSpace(*field.value)
+			if !strings.Contains(value, opsAlertSecretMask) {
+				continue
+			}
+			restored, ok := matchMaskedOpsAlertValue(ch.Type, i, value, stored, field.get, field.mask)
+			if !ok {
+				return fmt.Errorf("notify_channels[%d].%s is masked; please re-enter it", i, field.name)
+			}
+			*field.value = restored
+		}
+	}
+	return nil
+}
+
+func matchMaskedOpsAlertValue(channelType string, index int, masked string, stored []OpsAlertNotifyChannel, get func(*OpsAlertNotifyChannel) string, mask func(string) string) (string, bool) {
+	channelType = strings.ToLower(strings.TrimSpace(channelType))
+	matches := func(j int) bool {
+		candidate := &stored[j]
+		return candidate.Type == channelType && get(candidate) != "" && mask(get(candidate)) == masked
+	}
+	if index < len(stored) && matches(index) {
+		return get(&stored[index]), true
+	}
+	for j := range stored {
+		if matches(j) {
+			return get(&stored[j]), true
+		}
+	}
+	return "", false
+}
+
+func parseOpsAlertTemplate(text string) (*template.Template, error) {
+	return template.New("ops_alert").Option("missingkey=zero").Parse(text)
+}
+
+// renderOpsAlertNotification 按渠道模板渲染通知；自定义模板渲染失败时回退到默认模板，保证告警不丢。
+func renderOpsAlertNotification(channel *OpsAlertNotifyChannel, rule *OpsAlertRule, event *OpsAlertEvent) *OpsAlertNotification {
+	status := OpsAlertStatusFiring
+	if event != nil && event.Status != OpsAlertStatusFiring {
+		status = OpsAlertStatusResolved
+	}
+
+	variables := opsAlertEmailVariables(rule, event)
+	variables["resolved_at"] = "-"
+	if event != nil && event.ResolvedAt != nil {
+		variables["resolved_at"] = event.ResolvedAt.UTC().Format(time.RFC3339)
+	}
+
+	custom, fallback := channel.Template, defaultOpsAlertFiringTemplate
+	titlePrefix := "[Ops Alert]"
+	if status == OpsAlertStatusResolved {
+		custom, fallback = channel.ResolvedTemplate, defaultOpsAlertResolvedTemplate
+		titlePrefix = "[Ops Alert Resolved]"
+	}
+
+	text := ""
+	if strings.TrimSpace(custom) != "" {
+		text, _ = executeOpsAlertTemplate(custom, variables)
+	}
+	if strings.TrimSpace(text) == "" {
+		text, _ = executeOpsAlertTemplate(fallback, variables)
+	}
+
+	return &OpsAlertNotification{
+		Status: status,
+		Title:  fmt.Sprintf("%s[%s] %s", titlePrefix, variables["severity"], variables["rule_name"]),
+		Text:   strings.TrimSpace(text),
+		Rule:   rule,
+		Event:  event,
+	}
+}
+
+func executeOpsAlertTemplate(text string, variables map[string]string) (string, error) {
+	tpl, err := parseOpsAlertTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, variables); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// postOpsAlertJSON 发送 JSON 并返回（截断后的）响应体；非 2xx 视为失败。
+func postOpsAlertJSON(ctx context.Context, client *http.Client, endpoint string, payload any, headers map[string]string) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userWebhookUserAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, opsAlertNotifyMaxRespBytes))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return respBody, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, truncateString(string(respBody), 256))
+	}
+	return respBody, nil
+}
+
+// ---- Slack ----
+
+type opsAlertSlackNotifier struct {
+	client *http.Client
+}
+
+func (n *opsAlertSlackNotifier) Type() string { return OpsAlertChannelSlack }
+
+func (n *opsAlertSlackNotifier) Validate(channel *OpsAlertNotifyChannel) error {
+	if channel.URL == "" {
+		return fmt.Errorf("url is required for slack")
+	}
+	return nil
+}
+
+func (n *opsAlertSlackNotifier) Send(ctx context.Context, channel *OpsAlertNotifyChannel, notification *OpsAlertNotification) error {
+	_, err := postOpsAlertJSON(ctx, n.client, channel.URL, map[string]any{"text": notification.Text}, nil)
+	return err
+}
+
+// ---- Feishu / Lark ----
+
+type opsAlertFeishuNotifier struct {
+	client *http.Client
+}
+
+func (n *opsAlertFeishuNotifier) Type() string { return OpsAlertChannelFeishu }
+
+func (n *opsAlertFeishuNotifier) Validate(channel *OpsAlertNotifyChannel) error {
+	if channel.URL == "" {
+		return fmt.Errorf("url is required for feishu")
+	}
+	return nil
+}
+
+func (n *opsAlertFeishuNotifier) Send(ctx context.Context, channel *OpsAlertNotifyChannel, notification *OpsAlertNotification) error {
+	payload := map[string]any{
+		"msg_type": "text",
+		"content":  map[string]string{"text": notification.Text},
+	}
+	if channel.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		payload["timestamp"] = ts
+		payload["sign"] = signOpsAlertFeishu(channel.Secret, ts)
+	}
+	respBody, err := postOpsAlertJSON(ctx, n.client, channel.URL, payload, nil)
+	if err != nil {
+		return err
+	}
+	// 飞书在签名错误等情况下仍返回 200，需要看业务 code。
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal(respBody, &result) == nil && result.Code != 0 {
+		return fmt.Errorf("feishu error %d: %s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// signOpsAlertFeishu 飞书自定义机器人加签：以 "timestamp\nsecret" 为 key 对空串做 HMAC-SHA256。
+func signOpsAlertFeishu(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ---- DingTalk ----
+
+type opsAlertDingTalkNotifier struct {
+	client *http.Client
+}
+
+func (n *opsAlertDingTalkNotifier) Type() string { return OpsAlertChannelDingTalk }
+
+func (n *opsAlertDingTalkNotifier) Validate(channel *OpsAlertNotifyChannel) error {
+	if channel.URL == "" {
+		return fmt.Errorf("url is required for dingtalk")
+	}
+	return nil
+}
+
+func (n *opsAlertDingTalkNotifier) Send(ctx context.Context, channel *OpsAlertNotifyChannel, notification *OpsAlertNotification) error {
+	endpoint := channel.URL
+	if channel.Secret != "" {
+		signed, err := signOpsAlertDingTalkURL(endpoint, channel.Secret, time.Now())
+		if err != nil {
+			return err
+		}
+		endpoint = signed
+	}
+	payload := map[string]any{
+		"msgtype": "text",
+		"text":    map[string]string{"content": notification.Text},
+	}
+	respBody, err := postOpsAlertJSON(ctx, n.client, endpoint, payload, nil)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if json.Unmarshal(respBody, &result) == nil && result.ErrCode != 0 {
+		return fmt.Errorf("dingtalk error %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// signOpsAlertDingTalkURL 钉钉机器人加签：HMAC-SHA256(secret, "timestamp\nsecret")，毫秒时间戳。
+func signOpsAlertDingTalkURL(endpoint, secret string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse dingtalk url: %w", err)
+	}
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(ts + "\n" + secret))
+	query := parsed.Query()
+	query.Set("timestamp", ts)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// ---- Telegram ----
+
+type opsAlertTelegramNotifier struct {
+	client     *http.Client
+	apiBaseURL string
+}
+
+func (n *opsAlertTelegramNotifier) Type() string { return OpsAlertChannelTelegram }
+
+func (n *opsAlertTelegramNotifier) Validate(channel *OpsAlertNotifyChannel) error {
+	if channel.BotToken == "" || channel.ChatID == "" {
+		return fmt.Errorf("bot_token and chat_id are required for telegram")
+	}
+	if strings.ContainsAny(channel.BotToken, "/?#") {
+		return fmt.Errorf("bot_token is invalid")
+	}
+	return nil
+}
+
+func (n *opsAlertTelegramNotifier) Send(ctx context.Context, channel *OpsAlertNotifyChannel, notification *OpsAlertNotification) error {
+	base := strings.TrimRight(n.apiBaseURL, "/")
+	if base == "" {
+		base = opsAlertTelegramAPIBaseURL
+	}
+	endpoint := base + "/bot" + channel.BotToken + "/sendMessage"
+	payload := map[string]any{
+		"chat_id":                  channel.ChatID,
+		"text":                     notification.Text,
+		"disable_web_page_preview": true,
+	}
+	respBody, err := postOpsAlertJSON(ctx, n.client, endpoint, payload, nil)
+	if err != nil {
+		// 错误信息里会带 URL，避免把 bot token 写进日志。
+		return fmt.Errorf("telegram send failed: %s", strings.ReplaceAll(err.Error(), channel.BotToken, "***"))
+	}
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(respBody, &result) == nil && !result.OK {
+		return fmt.Errorf("telegram error: %s", result.Description)
+	}
+	return nil
+}
+
+// ---- Generic webhook ----
+
+type opsAlertWebhookNotifier struct {
+	client *http.Client
+}
+
+func (n *opsAlertWebhookNotifier) Type() string { return OpsAlertChannelWebhook }
+
+func (n *opsAlertWebhookNotifier) Validate(channel *OpsAlertNotifyChannel) error {
+	if channel.URL == "" {
+		return fmt.Errorf("url is required for webhook")
+	}
+	return nil
+}
+
+type opsAlertWebhookRule struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Severity   string  `json:"severity"`
+	MetricType string  `json:"metric_type"`
+	Operator   string  `json:"operator"`
+	Threshold  float64 `json:"threshold"`
+}
+
+type opsAlertWebhookPayload struct {
+	Type   string               `json:"type"`
+	Status string               `json:"status"`
+	Title  string               `json:"title"`
+	Text   string               `json:"text"`
+	Rule   *opsAlertWebhookRule `json:"rule,omitempty"`
+	Event  *OpsAlertEvent       `json:"event,omitempty"`
+	SentAt time.Time            `json:"sent_at"`
+}
+
+// Send 投递通用 JSON；配置了 secret 时附带与用户 Webhook 相同格式的签名头。
+func (n *opsAlertWebhookNotifier) Send(ctx context.Context, channel *OpsAlertNotifyChannel, notification *OpsAlertNotification) error {
+	eventType := opsAlertWebhookEventFiring
+	if notification.Status == OpsAlertStatusResolved {
+		eventType = opsAlertWebhookEventResolve
+	}
+	payload := opsAlertWebhookPayload{
+		Type:   eventType,
+		Status: notification.Status,
+		Title:  notification.Title,
+		Text:   notification.Text,
+		Event:  notification.Event,
+		SentAt: time.Now().UTC(),
+	}
+	if r := notification.Rule; r != nil {
+		payload.Rule = &opsAlertWebhookRule{
+			ID:         r.ID,
+			Name:       r.Name,
+			Severity:   r.Severity,
+			MetricType: r.MetricType,
+			Operator:   r.Operator,
+			Threshold:  r.Threshold,
+		}
+	}
+
+	headers := map[string]string{userWebhookEventHeader: eventType}
+	if channel.Secret != "" {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		headers[userWebhookTimestampHeader] = ts
+		headers[userWebhookSignatureHeader] = SignUserWebhookPayload(channel.Secret, ts, body)
+		_, err = postOpsAlertJSON(ctx, n.client, channel.URL, json.RawMessage(body), headers)
+		return err
+	}
+	_, err := postOpsAlertJSON(ctx, n.client, channel.URL, payload, headers)
+	return err
+}
@@ -0,0 +1,249 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type opsAlertWebhookStandIn struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	paths    []string
+	response string
+}
+
+func (h *opsAlertWebhookStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	h.bodies = append(h.bodies, body)
+	h.headers = append(h.headers, r.Header.Clone())
+	h.paths = append(h.paths, r.URL.RequestURI())
+	resp := h.response
+	h.mu.Unlock()
+	if resp == "" {
+		resp = `{}`
+	}
+	_, _ = w.Write([]byte(resp))
+}
+
+func (h *opsAlertWebhookStandIn) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.bodies)
+}
+
+func newOpsAlertNotifierTestEvaluator() *OpsAlertEvaluatorService {
+	cfg := &config.Config{}
+	cfg.Security.URLAllowlist.AllowPrivateHosts = true
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = true
+	return NewOpsAlertEvaluatorService(nil, nil, nil, nil, cfg, nil)
+}
+
+func newOpsAlertNotifierTestRule(channels ...OpsAlertNotifyChannel) (*OpsAlertRule, *OpsAlertEvent) {
+	rule := &OpsAlertRule{
+		ID:             42,
+		Name:           "error rate",
+		Severity:       "P1",
+		MetricType:     "error_rate",
+		Operator:       ">",
+		Threshold:      5,
+		NotifyChannels: channels,
+	}
+	event := &OpsAlertEvent{
+		ID:             7,
+		RuleID:         rule.ID,
+		Severity:       rule.Severity,
+		Status:         OpsAlertStatusFiring,
+		Description:    "error_rate > 5.00 (current 12.50)",
+		MetricValue:    float64Ptr(12.5),
+		ThresholdValue: float64Ptr(5),
+		FiredAt:        time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+	}
+	return rule, event
+}
+
+func TestOpsAlertNotifications_WebhookFiringAndResolved(t *testing.T) {
+	standIn := &opsAlertWebhookStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	svc := newOpsAlertNotifierTestEvaluator()
+	rule, event := newOpsAlertNotifierTestRule(OpsAlertNotifyChannel{
+		Type:     OpsAlertChannelWebhook,
+		URL:      server.URL + "/hook",
+		Secret:   "s3cret",
+		Template: "FIRING {{.rule_name}} value={{.metric_value}}",
+	})
+
+	require.Equal(t, 1, svc.sendAlertNotifications(context.Background(), defaultOpsAlertRuntimeSettings(), rule, event))
+
+	resolvedAt := event.FiredAt.Add(10 * time.Minute)
+	resolved := *event
+	resolved.Status = OpsAlertStatusResolved
+	resolved.ResolvedAt = &resolvedAt
+	require.Equal(t, 1, svc.sendAlertNotifications(context.Background(), defaultOpsAlertRuntimeSettings(), rule, &resolved))
+
+	require.Equal(t, 2, standIn.count())
+
+	var firing opsAlertWebhookPayload
+	require.NoError(t, json.Unmarshal(standIn.bodies[0], &firing))
+	require.Equal(t, opsAlertWebhookEventFiring, firing.Type)
+	require.Equal(t, "FIRING error rate value=12.50", firing.Text)
+	require.Equal(t, int64(42), firing.Rule.ID)
+	require.Equal(t, int64(7), firing.Event.ID)
+	ts := standIn.headers[0].Get(userWebhookTimestampHeader)
+	require.Equal(t, SignUserWebhookPayload("s3cret", ts, standIn.bodies[0]), standIn.headers[0].Get(userWebhookSignatureHeader))
+
+	var recovered opsAlertWebhookPayload
+	require.NoError(t, json.Unmarshal(standIn.bodies[1], &recovered))
+	require.Equal(t, opsAlertWebhookEventResolve, recovered.Type)
+	require.Equal(t, OpsAlertStatusResolved, recovered.Status)
+	require.Contains(t, recovered.Title, "[Ops Alert Resolved][P1] error rate")
+	require.Contains(t, recovered.Text, "Resolved at: 2026-10-01T08:10:00Z")
+}
+
+func TestOpsAlertNotifications_RateLimitIsPerChannel(t *testing.T) {
+	limited := &opsAlertWebhookStandIn{}
+	limitedServer := httptest.NewServer(limited)
+	defer limitedServer.Close()
+	unlimited := &opsAlertWebhookStandIn{}
+	unlimitedServer := httptest.NewServer(unlimited)
+	defer unlimitedServer.Close()
+
+	svc := newOpsAlertNotifierTestEvaluator()
+	rule, event := newOpsAlertNotifierTestRule(
+		OpsAlertNotifyChannel{Type: OpsAlertChannelWebhook, URL: limitedServer.URL, RateLimitPerHour: 1},
+		OpsAlertNotifyChannel{Type: OpsAlertChannelSlack, URL: unlimitedServer.URL},
+	)
+
+	require.Equal(t, 2, svc.sendAlertNotifications(context.Background(), nil, rule, event))
+	require.Equal(t, 1, svc.sendAlertNotifications(context.Background(), nil, rule, event))
+	require.Equal(t, 1, limited.count())
+	require.Equal(t, 2, unlimited.count())
+}
+
+func TestOpsAlertNotifications_SilencedAndMinSeverity(t *testing.T) {
+	standIn := &opsAlertWebhookStandIn{}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	svc := newOpsAlertNotifierTestEvaluator()
+	rule, event := newOpsAlertNotifierTestRule(
+		OpsAlertNotifyChannel{Type: OpsAlertChannelWebhook, URL: server.URL},
+		OpsAlertNotifyChannel{Type: OpsAlertChannelWebhook, URL: server.URL, MinSeverity: "critical"},
+	)
+
+	runtimeCfg := defaultOpsAlertRuntimeSettings()
+	runtimeCfg.Silencing.Enabled = true
+	runtimeCfg.Silencing.GlobalUntilRFC3339 = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	require.Equal(t, 0, svc.sendAlertNotifications(context.Background(), runtimeCfg, rule, event))
+	require.Equal(t, 0, standIn.count())
+
+	// P1 maps to "warning", so only the first channel passes the min-severity filter.
+	runtimeCfg.Silencing.GlobalUntilRFC3339 = ""
+	require.Equal(t, 1, svc.sendAlertNotifications(context.Background(), runtimeCfg, rule, event))
+	require.Equal(t, 1, standIn.count())
+}
+
+func TestOpsAlertNotifiers_ChatPayloads(t *testing.T) {
+	standIn := &opsAlertWebhookStandIn{response: `{"code":0,"errcode":0,"ok":true}`}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	client := server.Client()
+	rule, event := newOpsAlertNotifierTestRule()
+	notification := renderOpsAlertNotification(&OpsAlertNotifyChannel{}, rule, event)
+
+	require.NoError(t, (&opsAlertFeishuNotifier{client: client}).Send(context.Background(), &OpsAlertNotifyChannel{URL: server.URL, Secret: "fs"}, notification))
+	require.NoError(t, (&opsAlertDingTalkNotifier{client: client}).Send(context.Background(), &OpsAlertNotifyChannel{URL: server.URL + "/robot/send?access_token=abc", Secret: "dt"}, notification))
+	require.NoError(t, (&opsAlertTelegramNotifier{client: client, apiBaseURL: server.URL}).Send(context.Background(), &OpsAlertNotifyChannel{BotToken: "123:ABC", ChatID: "-100"}, notification))
+
+	var feishu map[string]any
+	require.NoError(t, json.Unmarshal(standIn.bodies[0], &feishu))
+	require.Equal(t, "text", feishu["msg_type"])
+	require.Equal(t, signOpsAlertFeishu("fs", feishu["timestamp"].(string)), feishu["sign"])
+	require.Contains(t, feishu["content"].(map[string]any)["text"], "[Ops Alert][P1] error rate")
+
+	require.True(t, strings.HasPrefix(standIn.paths[1], "/robot/send?"))
+	require.Contains(t, standIn.paths[1], "access_token=abc")
+	require.Contains(t, standIn.paths[1], "sign=")
+	require.Contains(t, standIn.paths[1], "timestamp=")
+
+	require.Equal(t, "/bot123:ABC/sendMessage", standIn.paths[2])
+	var telegram map[string]any
+	require.NoError(t, json.Unmarshal(standIn.bodies[2], &telegram))
+	require.Equal(t, "-100", telegram["chat_id"])
+
+	standIn.response = `{"code":19021,"msg":"sign match fail"}`
+	err := (&opsAlertFeishuNotifier{client: client}).Send(context.Background(), &OpsAlertNotifyChannel{URL: server.URL}, notification)
+	require.ErrorContains(t, err, "sign match fail")
+}
+
+func TestNormalizeOpsAlertNotifyChannels(t *testing.T) {
+	cfg := &config.Config{}
+
+	out, err := NormalizeOpsAlertNotifyChannels([]OpsAlertNotifyChannel{
+		{Type: " Slack ", URL: "https://hooks.slack.com/services/T/B/X"},
+		{Type: "telegram", BotToken: "123:ABC", ChatID: "-100", MinSeverity: "Critical"},
+	}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, OpsAlertChannelSlack, out[0].Type)
+	require.Equal(t, "critical", out[1].MinSeverity)
+
+	cases := []OpsAlertNotifyChannel{
+		{Type: "pagerduty", URL: "https://example.com"},
+		{Type: OpsAlertChannelWebhook},
+		{Type: OpsAlertChannelWebhook, URL: "http://example.com/hook"},
+		{Type: OpsAlertChannelTelegram, BotToken: "123"},
+		{Type: OpsAlertChannelSlack, URL: "https://hooks.slack.com/x", Template: "{{.rule_name"},
+		{Type: OpsAlertChannelSlack, URL: "https://hooks.slack.com/x", RateLimitPerHour: -1},
+	}
+	for _, tc := range cases {
+		_, err := NormalizeOpsAlertNotifyChannels([]OpsAlertNotifyChannel{tc}, cfg)
+		require.Error(t, err, "type=%s", tc.Type)
+	}
+}
+
+func TestMaskOpsAlertNotifyChannelsAndRestore(t *testing.T) {
+	stored := []OpsAlertNotifyChannel{
+		{Type: OpsAlertChannelSlack, URL: "https://hooks.slack.com/services/T/B/XSECRET1"},
+		{Type: OpsAlertChannelTelegram, BotToken: "123456:ABCDEFGH", ChatID: "-100"},
+		{Type: OpsAlertChannelWebhook, URL: "https://example.com", Secret: "signing-secret-value"},
+	}
+
+	masked := MaskOpsAlertNotifyChannels(stored)
+	require.Equal(t, "https://hooks.slack.com/********RET1", masked[0].URL)
+	require.Equal(t, "********EFGH", masked[1].BotToken)
+	require.Equal(t, "-100", masked[1].ChatID)
+	require.Equal(t, "https://example.com", masked[2].URL, "urls without path or query carry no token")
+	require.Equal(t, "********alue", masked[2].Secret)
+	require.Equal(t, "https://hooks.slack.com/services/T/B/XSECRET1", stored[0].URL, "stored channels are not modified")
+
+	// 原样提交回来（顺序调整、新值混合）时，占位换回已保存的值，新填写的值保持不变
+	submitted := []OpsAlertNotifyChannel{masked[2], masked[0], masked[1]}
+	submitted[2].BotToken = "654321:NEWTOKEN"
+	require.NoError(t, restoreMaskedOpsAlertNotifyChannels(submitted, stored))
+	require.Equal(t, "signing-secret-value", submitted[0].Secret)
+	require.Equal(t, "https://hooks.slack.com/services/T/B/XSECRET1", submitted[1].URL)
+	require.Equal(t, "654321:NEWTOKEN", submitted[2].BotToken)
+
+	// 没有对应的已保存值（新建规则或类型不符）时要求重新填写
+	err := restoreMaskedOpsAlertNotifyChannels([]OpsAlertNotifyChannel{masked[1]}, nil)
+	require.ErrorContains(t, err, "notify_channels[0].bot_token is masked")
+	wrongType := masked[0]
+	wrongType.Type = OpsAlertChannelFeishu
+	require.Error(t, restoreMaskedOpsAlertNotifyChannels([]OpsAlertNotifyChannel{wrongType}, stored))
+}
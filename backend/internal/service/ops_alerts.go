@@ -17,7 +17,14 @@ func (s *OpsService) ListAlertRules(ctx context.Context) ([]*OpsAlertRule, error
 	if s.opsRepo == nil {
 		return []*OpsAlertRule{}, nil
 	}
-	return s.opsRepo.ListAlertRules(ctx)
+	rules, err := s.opsRepo.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		maskOpsAlertRuleChannels(rule)
+	}
+	return rules, nil
 }
 
 func (s *OpsService) CreateAlertRule(ctx context.Context, rule *OpsAlertRule) (*OpsAlertRule, error) {
@@ -30,11 +37,20 @@ func (s *OpsService) CreateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	if rule == nil {
 		return nil, infraerrors.BadRequest("INVALID_RULE", "invalid rule")
 	}
+	if err := restoreMaskedOpsAlertNotifyChannels(rule.NotifyChannels, nil); err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNELS", err.Error())
+	}
+	channels, err := NormalizeOpsAlertNotifyChannels(rule.NotifyChannels, s.cfg)
+	if err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNELS", err.Error())
+	}
+	rule.NotifyChannels = channels
 
 	created, err := s.opsRepo.CreateAlertRule(ctx, rule)
 	if err != nil {
 		return nil, err
 	}
+	maskOpsAlertRuleChannels(created)
 	return created, nil
 }
 
@@ -48,6 +64,19 @@ func (s *OpsService) UpdateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 	if rule == nil || rule.ID <= 0 {
 		return nil, infraerrors.BadRequest("INVALID_RULE", "invalid rule")
 	}
+	// 读接口返回的是脱敏渠道，未修改的字段会以占位形式提交回来，这里换回已保存的值
+	stored, err := s.storedAlertRuleChannels(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreMaskedOpsAlertNotifyChannels(rule.NotifyChannels, stored); err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNELS", err.Error())
+	}
+	channels, err := NormalizeOpsAlertNotifyChannels(rule.NotifyChannels, s.cfg)
+	if err != nil {
+		return nil, infraerrors.BadRequest("INVALID_NOTIFY_CHANNELS", err.Error())
+	}
+	rule.NotifyChannels = channels
 
 	updated, err := s.opsRepo.UpdateAlertRule(ctx, rule)
 	if err != nil {
@@ -56,9 +85,29 @@ func (s *OpsService) UpdateAlertRule(ctx context.Context, rule *OpsAlertRule) (*
 		}
 		return nil, err
 	}
+	maskOpsAlertRuleChannels(updated)
 	return updated, nil
 }
 
+func (s *OpsService) storedAlertRuleChannels(ctx context.Context, ruleID int64) ([]OpsAlertNotifyChannel, error) {
+	rules, err := s.opsRepo.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule != nil && rule.ID == ruleID {
+			return rule.NotifyChannels, nil
+		}
+	}
+	return nil, nil
+}
+
+func maskOpsAlertRuleChannels(rule *OpsAlertRule) {
+	if rule != nil {
+		rule.NotifyChannels = MaskOpsAlertNotifyChannels(rule.NotifyChannels)
+	}
+}
+
 func (s *OpsService) DeleteAlertRule(ctx context.Context, id int64) error {
 	if err := s.RequireMonitoringEnabled(ctx); err != nil {
 		return err
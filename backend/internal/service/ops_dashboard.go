@@ -74,7 +74,7 @@ func (s *OpsService) resolveOpsQueryMode(ctx context.Context, requested OpsQuery
 	if requested.IsValid() {
 		// Allow "auto" to be disabled via config until preagg is proven stable in production.
 		// Forced `preagg` via query param still works.
-		if requested == OpsQueryModeAuto && s != nil && s.cfg != nil && !s.cfg.Ops.UsePreaggregatedTables {
+		if requested == OpsQueryModeAuto && s != nil && s.cfg != nil && !s.cfg.Live().Ops.UsePreaggregatedTables {
 			return OpsQueryModeRaw
 		}
 		return requested
@@ -87,7 +87,7 @@ func (s *OpsService) resolveOpsQueryMode(ctx context.Context, requested OpsQuery
 		}
 	}
 
-	if mode == OpsQueryModeAuto && s != nil && s.cfg != nil && !s.cfg.Ops.UsePreaggregatedTables {
+	if mode == OpsQueryModeAuto && s != nil && s.cfg != nil && !s.cfg.Live().Ops.UsePreaggregatedTables {
 		return OpsQueryModeRaw
 	}
 	return mode
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 组织成员角色：owner 唯一且不可移除；owner/admin 可管理成员、邀请与钱包；
+// member 只能使用自己创建的组织 Key 并查看自己的用量。
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+const (
+	OrganizationStatusActive   = "active"
+	OrganizationStatusDisabled = "disabled"
+)
+
+const (
+	OrganizationInvitationStatusPending  = "pending"
+	OrganizationInvitationStatusAccepted = "accepted"
+	OrganizationInvitationStatusRevoked  = "revoked"
+)
+
+// 组织钱包流水类型：成员从个人余额转入 / 管理员调整。用量扣费不写流水，以 usage_logs 为准。
+const (
+	OrganizationWalletTxMemberTransfer = "member_transfer"
+	OrganizationWalletTxAdminAdjust    = "admin_adjust"
+)
+
+var (
+	ErrOrganizationNotFound            = infraerrors.NotFound("ORGANIZATION_NOT_FOUND", "organization not found")
+	ErrOrganizationForbidden           = infraerrors.Forbidden("ORGANIZATION_FORBIDDEN", "insufficient organization role")
+	ErrOrganizationDisabled            = infraerrors.Forbidden("ORGANIZATION_DISABLED", "organization is disabled")
+	ErrOrganizationMembershipRequired  = infraerrors.Forbidden("ORGANIZATION_MEMBERSHIP_REQUIRED", "you are not a member of this organization")
+	ErrOrganizationMemberNotFound      = infraerrors.NotFound("ORGANIZATION_MEMBER_NOT_FOUND", "organization member not found")
+	ErrOrganizationMemberExists        = infraerrors.Conflict("ORGANIZATION_MEMBER_EXISTS", "user is already a member of this organization")
+	ErrOrganizationOwnerImmutable      = infraerrors.BadRequest("ORGANIZATION_OWNER_IMMUTABLE", "the organization owner cannot be removed or change role")
+	ErrOrganizationInvalidRole         = infraerrors.BadRequest("ORGANIZATION_INVALID_ROLE", "invalid organization role")
+	ErrOrganizationInvalidName         = infraerrors.BadRequest("ORGANIZATION_INVALID_NAME", "organization name must be 1-100 characters")
+	ErrOrganizationInvalidAmount       = infraerrors.BadRequest("ORGANIZATION_INVALID_AMOUNT", "amount must be greater than 0")
+	ErrOrganizationInvalidLimit        = infraerrors.BadRequest("ORGANIZATION_INVALID_LIMIT", "spending limit must be >= 0")
+	ErrOrganizationInvalidStatus       = infraerrors.BadRequest("ORGANIZATION_INVALID_STATUS", "status must be active or disabled")
+	ErrOrganizationInvalidEmail        = infraerrors.BadRequest("ORGANIZATION_INVALID_EMAIL", "invalid email address")
+	ErrOrganizationInvalidTimeRange    = infraerrors.BadRequest("ORGANIZATION_INVALID_TIME_RANGE", "invalid time range (max 366 days)")
+	ErrOrganizationLimitReached        = infraerrors.BadRequest("ORGANIZATION_LIMIT_REACHED", "organization limit reached")
+	ErrOrganizationInvitationNotFound  = infraerrors.NotFound("ORGANIZATION_INVITATION_NOT_FOUND", "organization invitation not found")
+	ErrOrganizationInvitationInvalid   = infraerrors.BadRequest("ORGANIZATION_INVITATION_INVALID", "invitation is invalid or has expired")
+	ErrOrganizationInvitationMismatch  = infraerrors.Forbidden("ORGANIZATION_INVITATION_EMAIL_MISMATCH", "this invitation was sent to a different email address")
+	ErrOrganizationInsufficientBalance = infraerrors.BadRequest("ORGANIZATION_INSUFFICIENT_BALANCE", "organization wallet balance is insufficient")
+	ErrOrganizationMemberSpendLimitHit = infraerrors.Forbidden("ORGANIZATION_MEMBER_SPEND_LIMIT_EXCEEDED", "your monthly spending limit in this organization has been reached")
+	ErrOrganizationEmailUnavailable    = infraerrors.ServiceUnavailable("ORGANIZATION_EMAIL_UNAVAILABLE", "email delivery is not configured")
+	ErrOrganizationKeyBatchUnsupported = infraerrors.BadRequest("ORGANIZATION_KEY_BATCH_UNSUPPORTED", "organization API keys cannot create batch jobs; use a personal API key")
+)
+
+// Organization 组织（团队）。Balance 为共享钱包余额。
+type Organization struct {
+	ID          int64
+	Name        string
+	OwnerUserID int64
+	Balance     float64
+	Status      string
+	MemberCount int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (o *Organization) IsActive() bool {
+	return o != nil && o.Status == OrganizationStatusActive
+}
+
+// OrganizationMembership 用户视角的组织列表项（带本人角色）。
+type OrganizationMembership struct {
+	Organization Organization
+	Role         string
+}
+
+// OrganizationMember 组织成员。Email / Username 为展示用的关联字段。
+type OrganizationMember struct {
+	ID                   int64
+	OrganizationID       int64
+	UserID               int64
+	Role                 string
+	MonthlySpendLimitUSD float64
+	Email                string
+	Username             string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// CanManage owner/admin 可管理成员、邀请、钱包和查看全员用量。
+func (m *OrganizationMember) CanManage() bool {
+	return m != nil && (m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin)
+}
+
+// OrganizationInvitation 邮件邀请；令牌只以 SHA-256 形式落库。
+type OrganizationInvitation struct {
+	ID             int64
+	OrganizationID int64
+	Email          string
+	Role           string
+	InvitedBy      int64
+	Status         string
+	ExpiresAt      time.Time
+	AcceptedBy     *int64
+	AcceptedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// OrganizationWalletTransaction 钱包充值流水。
+type OrganizationWalletTransaction struct {
+	ID             int64
+	OrganizationID int64
+	ActorUserID    *int64
+	Type           string
+	Amount         float64
+	BalanceAfter   float64
+	Note           string
+	CreatedAt      time.Time
+}
+
+// OrganizationBillingState 网关计费资格检查所需的组织侧状态。
+// MonthSpendUSD 为该成员本自然月在组织 Key 上的 actual_cost 合计（来自 usage_logs）。
+type OrganizationBillingState struct {
+	Status               string
+	Balance              float64
+	IsMember             bool
+	MonthlySpendLimitUSD float64
+	MonthSpendUSD        float64
+}
+
+// OrganizationMemberUsage 组织用量视图中单个成员的聚合。
+type OrganizationMemberUsage struct {
+	UserID               int64
+	Email                string
+	Username             string
+	Role                 string
+	MonthlySpendLimitUSD float64
+	Requests             int64
+	InputTokens          int64
+	OutputTokens         int64
+	CacheTokens          int64
+	TotalCost            float64
+	ActualCost           float64
+}
+
+// OrganizationUsageSummary 组织在时间范围内的用量汇总。
+type OrganizationUsageSummary struct {
+	OrganizationID int64
+	StartTime      time.Time
+	EndTime        time.Time
+	Requests       int64
+	InputTokens    int64
+	OutputTokens   int64
+	CacheTokens    int64
+	TotalCost      float64
+	ActualCost     float64
+	Members        []OrganizationMemberUsage
+}
+
+// OrganizationAPIKey 组织共享 Key 列表项（不含密钥明文）。
+type OrganizationAPIKey struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	Status     string
+	GroupID    *int64
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type OrganizationRepository interface {
+	// Create 创建组织并在同一事务内写入 owner 成员行。
+	Create(ctx context.Context, org *Organization) error
+	GetByID(ctx context.Context, id int64) (*Organization, error)
+	Update(ctx context.Context, org *Organization) error
+	List(ctx context.Context, params pagination.PaginationParams, search string) ([]Organization, *pagination.PaginationResult, error)
+	ListForUser(ctx context.Context, userID int64) ([]OrganizationMembership, error)
+	CountOwnedBy(ctx context.Context, userID int64) (int, error)
+
+	GetMember(ctx context.Context, orgID, userID int64) (*OrganizationMember, error)
+	ListMembers(ctx context.Context, orgID int64) ([]OrganizationMember, error)
+	UpdateMember(ctx context.Context, member *OrganizationMember) error
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+
+	CreateInvitation(ctx context.Context, inv *OrganizationInvitation, tokenHash string) error
+	ListInvitations(ctx context.Context, orgID int64) ([]OrganizationInvitation, error)
+	RevokeInvitation(ctx context.Context, orgID, id int64) error
+	// AcceptInvitation 在事务内校验待接受、未过期、邮箱匹配后写入成员并标记已接受。
+	AcceptInvitation(ctx context.Context, tokenHash string, userID int64, email string, now time.Time) (*OrganizationInvitation, error)
+
+	// TransferFromUser 原子地从成员个人余额扣除 amount 并计入组织钱包；个人余额不足返回 ErrInsufficientBalance。
+	TransferFromUser(ctx context.Context, orgID, userID int64, amount float64, note string) (orgBalance float64, userBalance float64, err error)
+	AdjustBalance(ctx context.Context, orgID int64, actorUserID int64, amount float64, note string) (float64, error)
+	ListWalletTransactions(ctx context.Context, orgID int64, params pagination.PaginationParams) ([]OrganizationWalletTransaction, *pagination.PaginationResult, error)
+
+	GetBillingState(ctx context.Context, orgID, userID int64, monthStart time.Time) (*OrganizationBillingState, error)
+	GetMemberUsage(ctx context.Context, orgID int64, startTime, endTime time.Time) ([]OrganizationMemberUsage, error)
+	ListAPIKeys(ctx context.Context, orgID int64) ([]OrganizationAPIKey, error)
+}
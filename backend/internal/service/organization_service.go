@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+const (
+	organizationMaxNameLength  = 100
+	organizationMaxNoteLength  = 500
+	organizationInvitePath     = "/organizations/invitations/accept"
+	organizationUsageMaxWindow = 366 * 24 * time.Hour
+)
+
+// UpdateOrganizationMemberInput 更新成员的参数，nil 字段保持不变。
+type UpdateOrganizationMemberInput struct {
+	Role                 *string
+	MonthlySpendLimitUSD *float64
+}
+
+// OrganizationService 管理组织、成员角色、邀请与共享钱包。
+// 组织 Key 的扣费在统一计费仓储内完成，资格检查由 BillingCacheService 负责。
+type OrganizationService struct {
+	repo                     OrganizationRepository
+	userRepo                 UserRepository
+	notificationEmailService *NotificationEmailService
+	settingService           *SettingService
+	billingCacheService      *BillingCacheService
+	cfg                      *config.Config
+}
+
+// NewOrganizationService creates an OrganizationService.
+func NewOrganizationService(
+	repo OrganizationRepository,
+	userRepo UserRepository,
+	notificationEmailService *NotificationEmailService,
+	settingService *SettingService,
+	billingCacheService *BillingCacheService,
+	cfg *config.Config,
+) *OrganizationService {
+	return &OrganizationService{
+		repo:                     repo,
+		userRepo:                 userRepo,
+		notificationEmailService: notificationEmailService,
+		settingService:           settingService,
+		billingCacheService:      billingCacheService,
+		cfg:                      cfg,
+	}
+}
+
+func (s *OrganizationService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg != nil && s.cfg.Organization.Enabled
+}
+
+func normalizeOrganizationName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > organizationMaxNameLength {
+		return "", ErrOrganizationInvalidName
+	}
+	return name, nil
+}
+
+func validateOrganizationAmount(amount float64) error {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+		return ErrOrganizationInvalidAmount
+	}
+	return nil
+}
+
+func truncateOrganizationNote(note string) string {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > organizationMaxNoteLength {
+		note = string([]rune(note)[:organizationMaxNoteLength])
+	}
+	return note
+}
+
+// requireMember 返回调用者在组织中的成员行；非成员统一返回 NotFound，避免探测组织是否存在。
+func (s *OrganizationService) requireMember(ctx context.Context, userID, orgID int64) (*OrganizationMember, error) {
+	if !s.Enabled() {
+		return nil, ErrOrganizationDisabled
+	}
+	member, err := s.repo.GetMember(ctx, orgID, userID)
+	if errors.Is(err, ErrOrganizationMemberNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	return member, err
+}
+
+func (s *OrganizationService) requireManager(ctx context.Context, userID, orgID int64) (*OrganizationMember, error) {
+	member, err := s.requireMember(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.CanManage() {
+		return nil, ErrOrganizationForbidden
+	}
+	return member, nil
+}
+
+// requireActiveOrganization 被管理员停用的组织只读：不能邀请、充值或新建 Key。
+func (s *OrganizationService) requireActiveOrganization(ctx context.Context, orgID int64) (*Organization, error) {
+	org, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsActive() {
+		return nil, ErrOrganizationDisabled
+	}
+	return org, nil
+}
+
+// Create 创建组织，创建者成为 owner。
+func (s *OrganizationService) Create(ctx context.Context, userID int64, name string) (*Organization, error) {
+	if !s.Enabled() {
+		return nil, ErrOrganizationDisabled
+	}
+	name, err := normalizeOrganizationName(name)
+	if err != nil {
+		return nil, err
+	}
+	owned, err := s.repo.CountOwnedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if owned >= s.cfg.Organization.MaxOwnedPerUser {
+		return nil, ErrOrganizationLimitReached
+	}
+	org := &Organization{Name: name, OwnerUserID: userID, Status: OrganizationStatusActive}
+	if err := s.repo.Create(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) ListMine(ctx context.Context, userID int64) ([]OrganizationMembership, error) {
+	if !s.Enabled() {
+		return []OrganizationMembership{}, nil
+	}
+	return s.repo.ListForUser(ctx, userID)
+}
+
+// Get 返回组织与调用者的成员信息。
+func (s *OrganizationService) Get(ctx context.Context, userID, orgID int64) (*Organization, *OrganizationMember, error) {
+	member, err := s.requireMember(ctx, userID, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, member, nil
+}
+
+func (s *OrganizationService) Rename(ctx context.Context, userID, orgID int64, name string) (*Organization, error) {
+	if _, err := s.requireManager(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+	name, err := normalizeOrganizationName(name)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org.Name = name
+	if err := s.repo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, userID, orgID int64) ([]OrganizationMember, error) {
+	if _, err := s.requireMember(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, orgID)
+}
+
+// checkCanManageMember owner 可管理所有人；admin 只能管理普通成员，且不能授予 admin。
+func checkCanManageMember(actor, target *OrganizationMember, newRole string) error {
+	if !actor.CanManage() {
+		return ErrOrganizationForbidden
+	}
+	if actor.Role == OrganizationRoleOwner {
+		return nil
+	}
+	if target.Role != OrganizationRoleMember || newRole == OrganizationRoleAdmin {
+		return ErrOrganizationForbidden
+	}
+	return nil
+}
+
+func (s *OrganizationService) UpdateMember(ctx context.Context, actorID, orgID, targetUserID int64, input UpdateOrganizationMemberInput) (*OrganizationMember, error) {
+	actor, err := s.requireManager(ctx, actorID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetMember(ctx, orgID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	newRole := ""
+	if input.Role != nil {
+		newRole = strings.TrimSpace(*input.Role)
+		if newRole != OrganizationRoleAdmin && newRole != OrganizationRoleMember {
+			return nil, ErrOrganizationInvalidRole
+		}
+		if target.Role == OrganizationRoleOwner {
+			return nil, ErrOrganizationOwnerImmutable
+		}
+	}
+	if err := checkCanManageMember(actor, target, newRole); err != nil {
+		return nil, err
+	}
+	if newRole != "" {
+		target.Role = newRole
+	}
+	if input.MonthlySpendLimitUSD != nil {
+		limit := *input.MonthlySpendLimitUSD
+		if math.IsNaN(limit) || math.IsInf(limit, 0) || limit < 0 {
+			return nil, ErrOrganizationInvalidLimit
+		}
+		target.MonthlySpendLimitUSD = limit
+	}
+	if err := s.repo.UpdateMember(ctx, target); err != nil {
+		return nil, err
+	}
+	s.billingCacheService.InvalidateOrganizationBillingState(orgID)
+	return target, nil
+}
+
+// RemoveMember 移除成员；非 owner 成员可以移除自己（退出组织）。
+// 成员创建的组织 Key 保留，但资格检查会拒绝非成员继续使用。
+func (s *OrganizationService) RemoveMember(ctx context.Context, actorID, orgID, targetUserID int64) error {
+	actor, err := s.requireMember(ctx, actorID, orgID)
+	if err != nil {
+		return err
+	}
+	target := actor
+	if targetUserID != actorID {
+		if target, err = s.repo.GetMember(ctx, orgID, targetUserID); err != nil {
+			return err
+		}
+	}
+	if target.Role == OrganizationRoleOwner {
+		return ErrOrganizationOwnerImmutable
+	}
+	if targetUserID != actorID {
+		if err := checkCanManageMember(actor, target, ""); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.RemoveMember(ctx, orgID, targetUserID); err != nil {
+		return err
+	}
+	s.billingCacheService.InvalidateOrganizationBillingState(orgID)
+	return nil
+}
+
+// Invite 创建邀请并通过通知邮件发送接受链接。令牌明文只出现在邮件中。
+func (s *OrganizationService) Invite(ctx context.Context, actorID, orgID int64, email, role string) (*OrganizationInvitation, error) {
+	actor, err := s.requireManager(ctx, actorID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.requireActiveOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	role = strings.TrimSpace(role)
+	if role == "" {
+		role = OrganizationRoleMember
+	}
+	if role != OrganizationRoleAdmin && role != OrganizationRoleMember {
+		return nil, ErrOrganizationInvalidRole
+	}
+	if role == OrganizationRoleAdmin && actor.Role != OrganizationRoleOwner {
+		return nil, ErrOrganizationForbidden
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, ErrOrganizationInvalidEmail
+	}
+	if s.notificationEmailService == nil {
+		return nil, ErrOrganizationEmailUnavailable
+	}
+
+	token, err := generateOrganizationInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	ttl := time.Duration(s.cfg.Organization.InvitationTTLHours) * time.Hour
+	inv := &OrganizationInvitation{
+		OrganizationID: orgID,
+		Email:          strings.ToLower(addr.Address),
+		Role:           role,
+		InvitedBy:      actorID,
+		Status:         OrganizationInvitationStatusPending,
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	if err := s.repo.CreateInvitation(ctx, inv, hashToken(token)); err != nil {
+		return nil, err
+	}
+
+	if err := s.sendInvitationEmail(ctx, org, actor, inv, token); err != nil {
+		// 邮件未送达的邀请无法被接受，直接撤销，让调用方重试。
+		if revokeErr := s.repo.RevokeInvitation(ctx, orgID, inv.ID); revokeErr != nil {
+			slog.Warn("revoke undelivered organization invitation failed", "organization_id", orgID, "invitation_id", inv.ID, "error", revokeErr)
+		}
+		return nil, ErrOrganizationEmailUnavailable.WithCause(err)
+	}
+	return inv, nil
+}
+
+func generateOrganizationInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate invitation token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *OrganizationService) sendInvitationEmail(ctx context.Context, org *Organization, inviter *OrganizationMember, inv *OrganizationInvitation, token string) error {
+	inviteURL := organizationInvitePath + "?token=" + url.QueryEscape(token)
+	if s.settingService != nil {
+		if base := strings.TrimRight(strings.TrimSpace(s.settingService.GetFrontendURL(ctx)), "/"); base != "" {
+			inviteURL = base + inviteURL
+		}
+	}
+	return s.notificationEmailService.Send(ctx, NotificationEmailSendInput{
+		Event:          NotificationEmailEventOrganizationInvitation,
+		RecipientEmail: inv.Email,
+		RecipientName:  inv.Email,
+		SourceType:     "organization_invitation",
+		SourceID:       strconv.FormatInt(inv.ID, 10),
+		Variables: map[string]string{
+			"organization_name": org.Name,
+			"inviter_name":      firstNonEmpty(inviter.Username, inviter.Email),
+			"invitation_role":   inv.Role,
+			"invite_url":        inviteURL,
+			"expires_in_hours":  strconv.Itoa(s.cfg.Organization.InvitationTTLHours),
+		},
+	})
+}
+
+func (s *OrganizationService) ListInvitations(ctx context.Context, userID, orgID int64) ([]OrganizationInvitation, error) {
+	if _, err := s.requireManager(ctx, userID, orgID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvitations(ctx, orgID)
+}
+
+func (s *OrganizationService) RevokeInvitation(ctx context.Context, userID, orgID, invitationID int64) error {
+	if _, err := s.requireManager(ctx, userID, orgID); err != nil {
+		return err
+	}
+	return s.repo.RevokeInvitation(ctx, orgID, invitationID)
+}
+
+// AcceptInvitation 接受邀请：登录用户的邮箱必须与受邀邮箱一致。
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, userID int64, token string) (*OrganizationInvitation, error) {
+	if !s.Enabled() {
+		return nil, ErrOrganizationDisabled
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrOrganizationInvitationNotFound
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return s.repo.AcceptInvitation(ctx, hashToken(token), userID, user.Email, time.Now())
+}
+
+// Fund 成员把个人余额转入组织钱包。
+func (s *OrganizationService) Fund(ctx context.Context, userID, orgID int64, amount float64, note string) (orgBalance float64, userBalance float64, err error) {
+	if _, err := s.requireMember(ctx, userID, orgID); err != nil {
+		return 0, 0, err
+	}
+	if err := validateOrganizationAmount(amount); err != nil {
+		return 0, 0, err
+	}
+	if _, err := s.requireActiveOrganization(ctx, orgID); err != nil {
+		return 0, 0, err
+	}
+	orgBalance, userBalance, err = s.repo.TransferFromUser(ctx, orgID, userID, amount, truncateOrganizationNote(note))
+	if err != nil {
+		return 0, 0, err
+	}
+	if s.billingCacheService != nil {
+		if err := s.billingCacheService.InvalidateUserBalance(ctx, userID); err != nil {
+			slog.Warn("invalidate balance cache after organization transfer failed", "user_id", userID, "error", err)
+		}
+	}
+	s.billingCacheService.InvalidateOrganizationBillingState(orgID)
+	return orgBalance, userBalance, nil
+}
+
+func (s *OrganizationService) ListWalletTransactions(ctx context.Context, userID, orgID int64, params pagination.PaginationParams) ([]OrganizationWalletTransaction, *pagination.PaginationResult, error) {
+	if _, err := s.requireManager(ctx, userID, orgID); err != nil {
+		return nil, nil, err
+	}
+	return s.repo.ListWalletTransactions(ctx, orgID, params)
+}
+
+// GetUsage 组织用量视图：owner/admin 看到全部成员，普通成员只看到自己。
+// 未指定时间范围时默认本自然月。
+func (s *OrganizationService) GetUsage(ctx context.Context, userID, orgID int64, startTime, endTime *time.Time) (*OrganizationUsageSummary, error) {
+	member, err := s.requireMember(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	end := timezone.Now()
+	if endTime != nil {
+		end = *endTime
+	}
+	start := timezone.StartOfMonth(end)
+	if startTime != nil {
+		start = *startTime
+	}
+	if !start.Before(end) || end.Sub(start) > organizationUsageMaxWindow {
+		return nil, ErrOrganizationInvalidTimeRange
+	}
+
+	members, err := s.repo.GetMemberUsage(ctx, orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	summary := &OrganizationUsageSummary{OrganizationID: orgID, StartTime: start, EndTime: end, Members: make([]OrganizationMemberUsage, 0, len(members))}
+	for _, m := range members {
+		if !member.CanManage() && m.UserID != userID {
+			continue
+		}
+		summary.Requests += m.Requests
+		summary.InputTokens += m.InputTokens
+		summary.OutputTokens += m.OutputTokens
+		summary.CacheTokens += m.CacheTokens
+		summary.TotalCost = money.Sum(summary.TotalCost, m.TotalCost)
+		summary.ActualCost = money.Sum(summary.ActualCost, m.ActualCost)
+		summary.Members = append(summary.Members, m)
+	}
+	return summary, nil
+}
+
+// ListAPIKeys 列出组织 Key；普通成员只能看到自己创建的。
+func (s *OrganizationService) ListAPIKeys(ctx context.Context, userID, orgID int64) ([]OrganizationAPIKey, error) {
+	member, err := s.requireMember(ctx, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.ListAPIKeys(ctx, orgID)
+	if err != nil || member.CanManage() {
+		return keys, err
+	}
+	own := make([]OrganizationAPIKey, 0, len(keys))
+	for _, k := range keys {
+		if k.UserID == userID {
+			own = append(own, k)
+		}
+	}
+	return own, nil
+}
+
+// AdminList 管理后台组织列表。
+func (s *OrganizationService) AdminList(ctx context.Context, params pagination.PaginationParams, search string) ([]Organization, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, params, search)
+}
+
+func (s *OrganizationService) AdminGet(ctx context.Context, orgID int64) (*Organization, []OrganizationMember, error) {
+	org, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, members, nil
+}
+
+// AdminAdjustBalance 管理员调整组织钱包，amount 为正充值、为负扣减。
+func (s *OrganizationService) AdminAdjustBalance(ctx context.Context, adminID, orgID int64, amount float64, note string) (float64, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount == 0 {
+		return 0, ErrOrganizationInvalidAmount
+	}
+	balance, err := s.repo.AdjustBalance(ctx, orgID, adminID, amount, truncateOrganizationNote(note))
+	if err != nil {
+		return 0, err
+	}
+	s.billingCacheService.InvalidateOrganizationBillingState(orgID)
+	return balance, nil
+}
+
+// AdminSetStatus 停用组织后其 Key 立即不能再发起余额计费请求（受本地缓存 TTL 影响）。
+func (s *OrganizationService) AdminSetStatus(ctx context.Context, orgID int64, status string) (*Organization, error) {
+	if status != OrganizationStatusActive && status != OrganizationStatusDisabled {
+		return nil, ErrOrganizationInvalidStatus
+	}
+	org, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org.Status = status
+	if err := s.repo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	s.billingCacheService.InvalidateOrganizationBillingState(orgID)
+	return org, nil
+}
@@ -0,0 +1,236 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type organizationRepoStub struct {
+	OrganizationRepository
+
+	org          *Organization
+	members      map[int64]*OrganizationMember
+	state        *OrganizationBillingState
+	stateErr     error
+	stateCalls   int
+	removedUsers []int64
+}
+
+func (r *organizationRepoStub) GetByID(context.Context, int64) (*Organization, error) {
+	if r.org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	org := *r.org
+	return &org, nil
+}
+
+func (r *organizationRepoStub) GetMember(_ context.Context, _ int64, userID int64) (*OrganizationMember, error) {
+	m, ok := r.members[userID]
+	if !ok {
+		return nil, ErrOrganizationMemberNotFound
+	}
+	member := *m
+	return &member, nil
+}
+
+func (r *organizationRepoStub) UpdateMember(_ context.Context, member *OrganizationMember) error {
+	m := *member
+	r.members[member.UserID] = &m
+	return nil
+}
+
+func (r *organizationRepoStub) RemoveMember(_ context.Context, _ int64, userID int64) error {
+	r.removedUsers = append(r.removedUsers, userID)
+	delete(r.members, userID)
+	return nil
+}
+
+func (r *organizationRepoStub) GetBillingState(context.Context, int64, int64, time.Time) (*OrganizationBillingState, error) {
+	r.stateCalls++
+	if r.stateErr != nil {
+		return nil, r.stateErr
+	}
+	state := *r.state
+	return &state, nil
+}
+
+func newOrganizationTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Organization.Enabled = true
+	cfg.Organization.BillingStateCacheSeconds = 60
+	return cfg
+}
+
+func newOrganizationServiceForTest(repo *organizationRepoStub) *OrganizationService {
+	cfg := newOrganizationTestConfig()
+	return NewOrganizationService(repo, nil, nil, nil, &BillingCacheService{cfg: cfg}, cfg)
+}
+
+func newOrganizationRepoWithRoles() *organizationRepoStub {
+	return &organizationRepoStub{
+		org: &Organization{ID: 7, OwnerUserID: 1, Status: OrganizationStatusActive},
+		members: map[int64]*OrganizationMember{
+			1: {OrganizationID: 7, UserID: 1, Role: OrganizationRoleOwner},
+			2: {OrganizationID: 7, UserID: 2, Role: OrganizationRoleAdmin},
+			3: {OrganizationID: 7, UserID: 3, Role: OrganizationRoleMember},
+			4: {OrganizationID: 7, UserID: 4, Role: OrganizationRoleMember},
+		},
+	}
+}
+
+func TestOrganizationUpdateMember_RoleMatrix(t *testing.T) {
+	admin := OrganizationRoleAdmin
+	member := OrganizationRoleMember
+	tests := []struct {
+		name    string
+		actor   int64
+		target  int64
+		role    *string
+		wantErr error
+	}{
+		{name: "owner promotes member", actor: 1, target: 3, role: &admin},
+		{name: "owner demotes admin", actor: 1, target: 2, role: &member},
+		{name: "owner role is immutable", actor: 1, target: 1, role: &member, wantErr: ErrOrganizationOwnerImmutable},
+		{name: "admin cannot grant admin", actor: 2, target: 3, role: &admin, wantErr: ErrOrganizationForbidden},
+		{name: "admin cannot manage admin", actor: 2, target: 2, role: &member, wantErr: ErrOrganizationForbidden},
+		{name: "member cannot manage", actor: 3, target: 4, role: &member, wantErr: ErrOrganizationForbidden},
+		{name: "non-member sees not found", actor: 99, target: 3, role: &member, wantErr: ErrOrganizationNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newOrganizationRepoWithRoles()
+			svc := newOrganizationServiceForTest(repo)
+			got, err := svc.UpdateMember(context.Background(), tt.actor, 7, tt.target, UpdateOrganizationMemberInput{Role: tt.role})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, *tt.role, got.Role)
+		})
+	}
+}
+
+func TestOrganizationUpdateMember_SpendLimit(t *testing.T) {
+	repo := newOrganizationRepoWithRoles()
+	svc := newOrganizationServiceForTest(repo)
+
+	limit := 12.5
+	got, err := svc.UpdateMember(context.Background(), 2, 7, 3, UpdateOrganizationMemberInput{MonthlySpendLimitUSD: &limit})
+	require.NoError(t, err)
+	require.Equal(t, 12.5, got.MonthlySpendLimitUSD)
+	require.Equal(t, OrganizationRoleMember, repo.members[3].Role)
+
+	negative := -1.0
+	_, err = svc.UpdateMember(context.Background(), 1, 7, 3, UpdateOrganizationMemberInput{MonthlySpendLimitUSD: &negative})
+	require.ErrorIs(t, err, ErrOrganizationInvalidLimit)
+}
+
+func TestOrganizationRemoveMember(t *testing.T) {
+	repo := newOrganizationRepoWithRoles()
+	svc := newOrganizationServiceForTest(repo)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RemoveMember(ctx, 4, 7, 4), "members may leave")
+	require.ErrorIs(t, svc.RemoveMember(ctx, 3, 7, 2), ErrOrganizationForbidden)
+	require.ErrorIs(t, svc.RemoveMember(ctx, 2, 7, 1), ErrOrganizationOwnerImmutable)
+	require.ErrorIs(t, svc.RemoveMember(ctx, 1, 7, 1), ErrOrganizationOwnerImmutable)
+	require.NoError(t, svc.RemoveMember(ctx, 2, 7, 3))
+	require.Equal(t, []int64{4, 3}, repo.removedUsers)
+}
+
+func TestOrganizationService_DisabledFeature(t *testing.T) {
+	repo := newOrganizationRepoWithRoles()
+	cfg := &config.Config{}
+	svc := NewOrganizationService(repo, nil, nil, nil, nil, cfg)
+
+	_, _, err := svc.Get(context.Background(), 1, 7)
+	require.ErrorIs(t, err, ErrOrganizationDisabled)
+}
+
+func TestCheckOrganizationEligibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   OrganizationBillingState
+		wantErr error
+	}{
+		{name: "eligible", state: OrganizationBillingState{Status: OrganizationStatusActive, Balance: 10, IsMember: true}},
+		{name: "disabled org", state: OrganizationBillingState{Status: OrganizationStatusDisabled, Balance: 10, IsMember: true}, wantErr: ErrOrganizationDisabled},
+		{name: "not a member", state: OrganizationBillingState{Status: OrganizationStatusActive, Balance: 10}, wantErr: ErrOrganizationMembershipRequired},
+		{name: "empty wallet", state: OrganizationBillingState{Status: OrganizationStatusActive, IsMember: true}, wantErr: ErrOrganizationInsufficientBalance},
+		{name: "member limit reached", state: OrganizationBillingState{Status: OrganizationStatusActive, Balance: 10, IsMember: true, MonthlySpendLimitUSD: 5, MonthSpendUSD: 5}, wantErr: ErrOrganizationMemberSpendLimitHit},
+		{name: "member under limit", state: OrganizationBillingState{Status: OrganizationStatusActive, Balance: 10, IsMember: true, MonthlySpendLimitUSD: 5, MonthSpendUSD: 4.99}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			repo := &organizationRepoStub{state: &state}
+			s := &BillingCacheService{cfg: newOrganizationTestConfig(), organizationRepo: repo}
+			err := s.checkOrganizationEligibility(context.Background(), 3, 7)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckOrganizationEligibility_CachedStateTracksCharges(t *testing.T) {
+	repo := &organizationRepoStub{state: &OrganizationBillingState{
+		Status: OrganizationStatusActive, Balance: 10, IsMember: true, MonthlySpendLimitUSD: 5, MonthSpendUSD: 4,
+	}}
+	s := &BillingCacheService{cfg: newOrganizationTestConfig(), organizationRepo: repo}
+	ctx := context.Background()
+
+	require.NoError(t, s.checkOrganizationEligibility(ctx, 3, 7))
+	require.NoError(t, s.checkOrganizationEligibility(ctx, 4, 7))
+	require.Equal(t, 2, repo.stateCalls)
+
+	balance := 8.5
+	s.RecordOrganizationCharge(7, 3, 1.5, &UsageBillingApplyResult{OrganizationBalance: &balance})
+	require.ErrorIs(t, s.checkOrganizationEligibility(ctx, 3, 7), ErrOrganizationMemberSpendLimitHit)
+	require.NoError(t, s.checkOrganizationEligibility(ctx, 4, 7), "other members only see the new balance")
+	require.Equal(t, 2, repo.stateCalls, "state served from cache")
+
+	s.InvalidateOrganizationBillingState(7)
+	require.NoError(t, s.checkOrganizationEligibility(ctx, 3, 7))
+	require.Equal(t, 3, repo.stateCalls)
+}
+
+func TestCheckOrganizationEligibility_RepoErrors(t *testing.T) {
+	s := &BillingCacheService{cfg: newOrganizationTestConfig(), organizationRepo: &organizationRepoStub{stateErr: ErrOrganizationNotFound}}
+	require.ErrorIs(t, s.checkOrganizationEligibility(context.Background(), 3, 7), ErrOrganizationDisabled)
+
+	s = &BillingCacheService{cfg: newOrganizationTestConfig(), organizationRepo: &organizationRepoStub{stateErr: errors.New("db down")}}
+	require.ErrorIs(t, s.checkOrganizationEligibility(context.Background(), 3, 7), ErrBillingServiceUnavailable)
+
+	s = &BillingCacheService{cfg: &config.Config{}, organizationRepo: &organizationRepoStub{}}
+	require.ErrorIs(t, s.checkOrganizationEligibility(context.Background(), 3, 7), ErrOrganizationDisabled)
+}
+
+func TestBuildUsageBillingCommand_OrganizationKey(t *testing.T) {
+	orgID := int64(7)
+	p := &postUsageBillingParams{
+		Cost:    &CostBreakdown{TotalCost: 1, ActualCost: 2},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 2, OrganizationID: &orgID},
+		Account: &Account{ID: 3},
+	}
+	cmd := buildUsageBillingCommand("req-org", nil, p)
+	require.NotNil(t, cmd)
+	require.Equal(t, 2.0, cmd.BalanceCost.InexactFloat64())
+	require.NotNil(t, cmd.OrganizationID)
+	require.Equal(t, orgID, *cmd.OrganizationID)
+
+	p.APIKey = &APIKey{ID: 2}
+	cmd = buildUsageBillingCommand("req-personal", nil, p)
+	require.Nil(t, cmd.OrganizationID)
+}
@@ -23,9 +23,14 @@ import (
 // constructor so the frontend i18n layer can localize it.
 //
 // Only validates enabled instances — a disabled instance may be a half-filled
-// draft the admin will complete later.
-func (s *PaymentConfigService) validateProviderConfig(providerKey string, config map[string]string) error {
-	_, err := provider.CreateProvider(providerKey, "_validate_", config)
+// draft the admin will complete later. Secret references are resolved first so
+// a reference that cannot be read is rejected at save time too.
+func (s *PaymentConfigService) validateProviderConfig(ctx context.Context, providerKey string, config map[string]string) error {
+	resolved, err := s.secrets.ResolveMap(ctx, config)
+	if err != nil {
+		return infraerrors.BadRequest("PAYMENT_CONFIG_SECRET_UNRESOLVED", err.Error())
+	}
+	_, err = provider.CreateProvider(providerKey, "_validate_", resolved)
 	return err
 }
 
@@ -195,7 +200,7 @@ func (s *PaymentConfigService) CreateProviderInstance(ctx context.Context, req C
 		return nil, err
 	}
 	if req.Enabled {
-		if err := s.validateProviderConfig(req.ProviderKey, req.Config); err != nil {
+		if err := s.validateProviderConfig(ctx, req.ProviderKey, req.Config); err != nil {
 			return nil, err
 		}
 	}
@@ -366,7 +371,7 @@ func (s *PaymentConfigService) UpdateProviderInstance(ctx context.Context, id in
 		finalEnabled = *req.Enabled
 	}
 	if finalEnabled {
-		if err := s.validateProviderConfig(current.ProviderKey, configToValidate); err != nil {
+		if err := s.validateProviderConfig(ctx, current.ProviderKey, configToValidate); err != nil {
 			return nil, err
 		}
 	}
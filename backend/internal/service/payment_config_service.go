@@ -12,6 +12,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/paymentproviderinstance"
 	"github.com/Wei-Shaw/sub2api/internal/payment"
 	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/secrets"
 )
 
 const (
@@ -198,6 +199,8 @@ type PaymentConfigService struct {
 	entClient     *dbent.Client
 	settingRepo   SettingRepository
 	encryptionKey []byte
+	// secrets resolves secret references in provider config values before validation
+	secrets *secrets.Resolver
 }
 
 // NewPaymentConfigService creates a new PaymentConfigService.
@@ -205,6 +208,13 @@ func NewPaymentConfigService(entClient *dbent.Client, settingRepo SettingReposit
 	return &PaymentConfigService{entClient: entClient, settingRepo: settingRepo, encryptionKey: encryptionKey}
 }
 
+// SetSecretResolver enables secret references (e.g. "vault://payment/wxpay#private_key")
+// in provider config values. Stored configs keep the reference; only the runtime
+// load balancer and save-time validation see resolved values.
+func (s *PaymentConfigService) SetSecretResolver(resolver *secrets.Resolver) {
+	s.secrets = resolver
+}
+
 // IsPaymentEnabled returns whether the payment system is enabled.
 func (s *PaymentConfigService) IsPaymentEnabled(ctx context.Context) bool {
 	val, err := s.settingRepo.GetValue(ctx, SettingPaymentEnabled)
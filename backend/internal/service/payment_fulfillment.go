@@ -347,7 +347,12 @@ func (s *PaymentService) doBalance(ctx context.Context, o *dbent.PaymentOrder, l
 	case redeemActionRedeem:
 		// Code exists but unused — skip creation, proceed to redeem
 	}
-	if _, err := s.redeemService.Redeem(ContextSkipRedeemAffiliate(ctx), o.UserID, o.RechargeCode); err != nil {
+	redeemCtx := WithBalanceLedgerSource(ContextSkipRedeemAffiliate(ctx), BalanceLedgerSource{
+		Reason:     BalanceLedgerReasonPaymentRecharge,
+		SourceType: BalanceLedgerSourcePaymentOrder,
+		SourceID:   strconv.FormatInt(o.ID, 10),
+	})
+	if _, err := s.redeemService.Redeem(redeemCtx, o.UserID, o.RechargeCode); err != nil {
 		return fmt.Errorf("redeem balance: %w", err)
 	}
 	if err := s.applyAffiliateRebateForOrder(ctx, o); err != nil {
@@ -356,26 +361,42 @@ func (s *PaymentService) doBalance(ctx context.Context, o *dbent.PaymentOrder, l
 	return s.markCompleted(ctx, o, lease, "RECHARGE_SUCCESS")
 }
 
+// markCompleted 把订单置为 completed，并在同一事务内写入 payment.completed 用户 Webhook，
+// 保证订单完成与通知事件同时提交或同时回滚。
 func (s *PaymentService) markCompleted(ctx context.Context, o *dbent.PaymentOrder, lease *paymentFulfillmentLease, auditAction string) error {
 	if lease == nil {
 		return errors.New("missing payment fulfillment lease")
 	}
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("begin mark completed tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	txCtx := dbent.NewTxContext(ctx, tx)
+
 	now := time.Now()
-	updated, err := s.entClient.PaymentOrder.Update().Where(
+	updated, err := tx.Client().PaymentOrder.Update().Where(
 		paymentorder.IDEQ(o.ID),
 		paymentorder.StatusEQ(OrderStatusRecharging),
 		paymentorder.UpdatedAtEQ(lease.version),
-	).SetStatus(OrderStatusCompleted).SetCompletedAt(now).Save(ctx)
+	).SetStatus(OrderStatusCompleted).SetCompletedAt(now).Save(txCtx)
 	if err != nil {
 		return fmt.Errorf("mark completed: %w", err)
 	}
 	if updated == 0 {
+		_ = tx.Rollback()
 		current, getErr := s.entClient.PaymentOrder.Get(ctx, o.ID)
 		if getErr == nil && current.Status == OrderStatusCompleted {
 			return nil
 		}
 		return infraerrors.Conflict("CONFLICT", "fulfillment lease was lost before completion")
 	}
+	if err := s.publishPaymentCompletedWebhook(txCtx, o, auditAction, now); err != nil {
+		return fmt.Errorf("enqueue payment completed webhook: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mark completed tx: %w", err)
+	}
 	if !s.hasAuditLog(ctx, o.ID, auditAction) {
 		s.writeAuditLog(ctx, o.ID, auditAction, "system", map[string]any{
 			"rechargeCode":   o.RechargeCode,
@@ -409,6 +430,28 @@ func (s *PaymentService) dispatchPaymentFulfillmentNotification(o *dbent.Payment
 	}()
 }
 
+// publishPaymentCompletedWebhook 必须在标记订单完成的事务内调用（ctx 携带该事务）。
+func (s *PaymentService) publishPaymentCompletedWebhook(ctx context.Context, o *dbent.PaymentOrder, auditAction string, completedAt time.Time) error {
+	if auditAction != "RECHARGE_SUCCESS" && auditAction != "SUBSCRIPTION_SUCCESS" {
+		return nil
+	}
+	return s.userWebhookService.PublishTx(ctx, UserWebhookEvent{
+		ID:         fmt.Sprintf("%s:%d", UserWebhookEventPaymentCompleted, o.ID),
+		Type:       UserWebhookEventPaymentCompleted,
+		UserID:     o.UserID,
+		OccurredAt: completedAt,
+		Data: map[string]any{
+			"order_id":     o.ID,
+			"out_trade_no": o.OutTradeNo,
+			"order_type":   o.OrderType,
+			"amount":       o.Amount,
+			"pay_amount":   o.PayAmount,
+			"payment_type": o.PaymentType,
+			"completed_at": completedAt.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
 func (s *PaymentService) sendBalanceRechargeSuccessNotification(ctx context.Context, o *dbent.PaymentOrder) error {
 	currentBalance := ""
 	if s.userRepo != nil {
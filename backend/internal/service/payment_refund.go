@@ -287,6 +287,16 @@ type availableBalanceDeductor interface {
 	DeductAvailableBalance(ctx context.Context, id int64, amount float64) (float64, error)
 }
 
+// withRefundLedgerSource 把退款扣回（及其回滚）的余额流水关联到订单。
+func withRefundLedgerSource(ctx context.Context, orderID int64, note string) context.Context {
+	return WithBalanceLedgerSource(ctx, BalanceLedgerSource{
+		Reason:     BalanceLedgerReasonPaymentRefund,
+		SourceType: BalanceLedgerSourcePaymentOrder,
+		SourceID:   strconv.FormatInt(orderID, 10),
+		Note:       note,
+	})
+}
+
 func (s *PaymentService) deductAvailableBalance(ctx context.Context, userID int64, amount float64) (float64, error) {
 	repo, ok := s.userRepo.(availableBalanceDeductor)
 	if !ok {
@@ -307,7 +317,7 @@ func (s *PaymentService) ExecuteRefund(ctx context.Context, p *RefundPlan) (*Ref
 		// Skip balance deduction on retry if previous attempt already deducted
 		// but failed to roll back (REFUND_ROLLBACK_FAILED in audit log).
 		if !s.hasAuditLog(ctx, p.OrderID, "REFUND_ROLLBACK_FAILED") {
-			deducted, err := s.deductAvailableBalance(ctx, p.Order.UserID, p.BalanceToDeduct)
+			deducted, err := s.deductAvailableBalance(withRefundLedgerSource(ctx, p.OrderID, ""), p.Order.UserID, p.BalanceToDeduct)
 			if err != nil {
 				s.restoreStatus(ctx, p)
 				return nil, fmt.Errorf("deduction: %w", err)
@@ -534,7 +544,7 @@ func (s *PaymentService) refundFinalizePlan(o *dbent.PaymentOrder) *RefundPlan {
 
 func (s *PaymentService) applyRefundFinalDeduction(ctx context.Context, p *RefundPlan) error {
 	if p.DeductionType == payment.DeductionTypeBalance && p.BalanceToDeduct > 0 {
-		deducted, err := s.deductAvailableBalance(ctx, p.Order.UserID, p.BalanceToDeduct)
+		deducted, err := s.deductAvailableBalance(withRefundLedgerSource(ctx, p.OrderID, ""), p.Order.UserID, p.BalanceToDeduct)
 		if err != nil {
 			return fmt.Errorf("deduction: %w", err)
 		}
@@ -695,7 +705,7 @@ func refundResponseID(resp *payment.RefundResponse) string {
 
 func (s *PaymentService) RollbackRefund(ctx context.Context, p *RefundPlan, gErr error) bool {
 	if p.DeductionType == payment.DeductionTypeBalance && p.BalanceToDeduct > 0 {
-		if err := s.userRepo.UpdateBalance(ctx, p.Order.UserID, p.BalanceToDeduct); err != nil {
+		if err := s.userRepo.UpdateBalance(withRefundLedgerSource(ctx, p.OrderID, "refund rollback"), p.Order.UserID, p.BalanceToDeduct); err != nil {
 			slog.Error("[CRITICAL] rollback failed", "orderID", p.OrderID, "amount", p.BalanceToDeduct, "error", err)
 			s.writeAuditLog(ctx, p.OrderID, "REFUND_ROLLBACK_FAILED", "admin", map[string]any{"gatewayError": psErrMsg(gErr), "rollbackError": psErrMsg(err), "balanceDeducted": p.BalanceToDeduct})
 			return false
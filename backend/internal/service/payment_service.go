@@ -198,6 +198,7 @@ type PaymentService struct {
 	resumeService            *PaymentResumeService
 	affiliateService         *AffiliateService
 	notificationEmailService *NotificationEmailService
+	userWebhookService       *UserWebhookService
 }
 
 func NewPaymentService(entClient *dbent.Client, registry *payment.Registry, loadBalancer payment.LoadBalancer, redeemService *RedeemService, subscriptionSvc *SubscriptionService, configService *PaymentConfigService, userRepo UserRepository, groupRepo GroupRepository, affiliateService *AffiliateService) *PaymentService {
@@ -210,6 +211,11 @@ func (s *PaymentService) SetNotificationEmailService(notificationEmailService *N
 	s.notificationEmailService = notificationEmailService
 }
 
+// SetUserWebhookService 注入用户 Webhook 发布（payment.completed）。
+func (s *PaymentService) SetUserWebhookService(userWebhookService *UserWebhookService) {
+	s.userWebhookService = userWebhookService
+}
+
 // --- Provider Registry ---
 
 // EnsureProviders lazily initializes the provider registry on first call.
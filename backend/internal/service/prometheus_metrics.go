@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+)
+
+const (
+	prometheusConcurrencyDefaultRefresh = 15 * time.Second
+	prometheusConcurrencyTimeout        = 5 * time.Second
+)
+
+// PrometheusMetricsService owns the process-wide gateway metrics and adds
+// scrape-time gauges for state that lives outside the request path: account
+// concurrency slots, the usage record worker pool and the billing cache write
+// queue. Request counters and histograms are recorded by the handler layer via
+// metrics.Default().
+type PrometheusMetricsService struct {
+	gateway      *metrics.Gateway
+	bearerToken  string
+	opsService   *OpsService
+	usagePool    *UsageRecordWorkerPool
+	billingCache *BillingCacheService
+	refresh      time.Duration
+
+	mu          sync.Mutex
+	loadedAt    time.Time
+	concurrency []metrics.Sample
+}
+
+// NewPrometheusMetricsService returns nil when metrics are disabled so callers
+// can skip route registration with a single nil check.
+func NewPrometheusMetricsService(
+	cfg *config.Config,
+	opsService *OpsService,
+	usagePool *UsageRecordWorkerPool,
+	billingCache *BillingCacheService,
+) *PrometheusMetricsService {
+	if cfg == nil || !cfg.Metrics.Enabled {
+		metrics.SetDefault(nil)
+		return nil
+	}
+	refresh := time.Duration(cfg.Metrics.ConcurrencyRefreshSeconds) * time.Second
+	if refresh <= 0 {
+		refresh = prometheusConcurrencyDefaultRefresh
+	}
+	gateway := metrics.NewGateway(metrics.Options{
+		MaxAccountSeries: cfg.Metrics.MaxAccountSeries,
+		MaxGroupSeries:   cfg.Metrics.MaxGroupSeries,
+		MaxModelSeries:   cfg.Metrics.MaxModelSeries,
+	})
+	s := &PrometheusMetricsService{
+		gateway:      gateway,
+		bearerToken:  cfg.Metrics.BearerToken,
+		opsService:   opsService,
+		usagePool:    usagePool,
+		billingCache: billingCache,
+		refresh:      refresh,
+	}
+	gateway.Registry.RegisterCollector(s.collectQueues)
+	gateway.Registry.RegisterCollector(s.collectConcurrency)
+	metrics.SetDefault(gateway)
+	return s
+}
+
+// Handler serves the scrape endpoint.
+func (s *PrometheusMetricsService) Handler() http.Handler {
+	if s == nil {
+		return nil
+	}
+	return metrics.Handler(s.gateway.Registry, s.bearerToken)
+}
+
+func (s *PrometheusMetricsService) collectQueues(emit func(metrics.Sample)) {
+	if s.usagePool != nil {
+		stats := s.usagePool.Stats()
+		emit(metrics.Sample{
+			Name:  "sub2api_usage_record_queue_depth",
+			Help:  "Usage record and billing tasks waiting for a worker.",
+			Value: float64(stats.WaitingTasks),
+		})
+		emit(metrics.Sample{
+			Name:  "sub2api_usage_record_running_workers",
+			Help:  "Usage record workers currently executing a task.",
+			Value: float64(stats.RunningWorkers),
+		})
+		emit(metrics.Sample{
+			Name:  "sub2api_usage_record_max_workers",
+			Help:  "Current usage record worker pool size.",
+			Value: float64(stats.MaxConcurrency),
+		})
+		emit(metrics.Sample{
+			Name:   "sub2api_usage_record_dropped_total",
+			Help:   "Usage record tasks dropped by reason.",
+			Type:   "counter",
+			Labels: []metrics.Label{{Name: "reason", Value: "queue_full"}},
+			Value:  float64(stats.DroppedQueueFull),
+		})
+		emit(metrics.Sample{
+			Name:   "sub2api_usage_record_dropped_total",
+			Type:   "counter",
+			Labels: []metrics.Label{{Name: "reason", Value: "pool_stopped"}},
+			Value:  float64(stats.DroppedPoolStopped),
+		})
+		emit(metrics.Sample{
+			Name:  "sub2api_usage_record_sync_fallback_total",
+			Help:  "Usage record tasks executed synchronously because the queue was full.",
+			Type:  "counter",
+			Value: float64(stats.SyncFallbackTasks),
+		})
+	}
+	if s.billingCache != nil {
+		depth, capacity := s.billingCache.CacheWriteQueueStats()
+		emit(metrics.Sample{
+			Name:  "sub2api_billing_cache_write_queue_depth",
+			Help:  "Pending asynchronous billing cache writes.",
+			Value: float64(depth),
+		})
+		emit(metrics.Sample{
+			Name:  "sub2api_billing_cache_write_queue_capacity",
+			Help:  "Capacity of the billing cache write queue.",
+			Value: float64(capacity),
+		})
+	}
+}
+
+func (s *PrometheusMetricsService) collectConcurrency(emit func(metrics.Sample)) {
+	for _, sample := range s.concurrencySamples() {
+		emit(sample)
+	}
+}
+
+func (s *PrometheusMetricsService) concurrencySamples() []metrics.Sample {
+	if s.opsService == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < s.refresh {
+		return s.concurrency
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), prometheusConcurrencyTimeout)
+	defer cancel()
+	accounts, err := s.opsService.listAllAccountsForOps(ctx, "", nil)
+	if err != nil {
+		// Keep serving the previous snapshot; a scrape must never fail on a slow DB.
+		logger.LegacyPrintf("service.prometheus_metrics", "[Metrics] list accounts failed: %v", err)
+		return s.concurrency
+	}
+	loadMap := s.opsService.getAccountsLoadMapBestEffort(ctx, accounts)
+	s.concurrency = buildConcurrencySamples(accounts, loadMap, s.gateway.Limiter())
+	s.loadedAt = time.Now()
+	return s.concurrency
+}
+
+type concurrencyTotals struct {
+	inUse    int64
+	capacity int64
+	waiting  int64
+}
+
+func (t *concurrencyTotals) add(inUse, capacity, waiting int64) {
+	t.inUse += inUse
+	t.capacity += capacity
+	t.waiting += waiting
+}
+
+type concurrencySeriesKey struct {
+	platform string
+	id       string
+}
+
+// buildConcurrencySamples aggregates slot usage by platform, group and
+// account. Group and account IDs pass through the shared label limiter first;
+// accounts that collapse into the overflow label are summed rather than
+// emitted as duplicate series.
+func buildConcurrencySamples(accounts []Account, loadMap map[int64]*AccountLoadInfo, limiter *metrics.LabelLimiter) []metrics.Sample {
+	platforms := make(map[string]*concurrencyTotals)
+	groups := make(map[concurrencySeriesKey]*concurrencyTotals)
+	perAccount := make(map[concurrencySeriesKey]*concurrencyTotals)
+	seenAccounts := make(map[int64]struct{}, len(accounts))
+
+	totals := func(m map[concurrencySeriesKey]*concurrencyTotals, key concurrencySeriesKey) *concurrencyTotals {
+		t := m[key]
+		if t == nil {
+			t = &concurrencyTotals{}
+			m[key] = t
+		}
+		return t
+	}
+
+	for i := range accounts {
+		acc := &accounts[i]
+		if acc.ID <= 0 {
+			continue
+		}
+		if _, dup := seenAccounts[acc.ID]; dup {
+			continue
+		}
+		seenAccounts[acc.ID] = struct{}{}
+
+		var inUse, waiting int64
+		if load := loadMap[acc.ID]; load != nil {
+			inUse = int64(load.CurrentConcurrency)
+			waiting = int64(load.WaitingCount)
+		}
+		capacity := int64(acc.Concurrency)
+
+		p := platforms[acc.Platform]
+		if p == nil {
+			p = &concurrencyTotals{}
+			platforms[acc.Platform] = p
+		}
+		p.add(inUse, capacity, waiting)
+
+		if !limiter.Dropped(metrics.LabelAccount) {
+			accountLabel := limiter.Admit(metrics.LabelAccount, strconv.FormatInt(acc.ID, 10))
+			totals(perAccount, concurrencySeriesKey{platform: acc.Platform, id: accountLabel}).add(inUse, capacity, waiting)
+		}
+		if !limiter.Dropped(metrics.LabelGroup) {
+			for _, grp := range acc.Groups {
+				if grp == nil || grp.ID <= 0 {
+					continue
+				}
+				groupLabel := limiter.Admit(metrics.LabelGroup, strconv.FormatInt(grp.ID, 10))
+				totals(groups, concurrencySeriesKey{platform: acc.Platform, id: groupLabel}).add(inUse, capacity, waiting)
+			}
+		}
+	}
+
+	samples := make([]metrics.Sample, 0, 3*(len(platforms)+len(groups))+2*len(perAccount))
+	for platform, t := range platforms {
+		labels := []metrics.Label{{Name: metrics.LabelPlatform, Value: platform}}
+		samples = append(samples,
+			metrics.Sample{Name: "sub2api_concurrency_slots_in_use", Help: "Account concurrency slots currently held, by platform.", Labels: labels, Value: float64(t.inUse)},
+			metrics.Sample{Name: "sub2api_concurrency_slots_capacity", Help: "Configured account concurrency, by platform.", Labels: labels, Value: float64(t.capacity)},
+			metrics.Sample{Name: "sub2api_concurrency_waiting", Help: "Requests waiting for an account slot, by platform.", Labels: labels, Value: float64(t.waiting)},
+		)
+	}
+	for key, t := range groups {
+		labels := []metrics.Label{{Name: metrics.LabelPlatform, Value: key.platform}, {Name: metrics.LabelGroup, Value: key.id}}
+		samples = append(samples,
+			metrics.Sample{Name: "sub2api_group_concurrency_slots_in_use", Help: "Account concurrency slots currently held, by group. Accounts shared by several groups count in each.", Labels: labels, Value: float64(t.inUse)},
+			metrics.Sample{Name: "sub2api_group_concurrency_slots_capacity", Help: "Configured account concurrency, by group.", Labels: labels, Value: float64(t.capacity)},
+			metrics.Sample{Name: "sub2api_group_concurrency_waiting", Help: "Requests waiting for an account slot, by group.", Labels: labels, Value: float64(t.waiting)},
+		)
+	}
+	for key, t := range perAccount {
+		labels := []metrics.Label{{Name: metrics.LabelPlatform, Value: key.platform}, {Name: metrics.LabelAccount, Value: key.id}}
+		samples = append(samples,
+			metrics.Sample{Name: "sub2api_account_concurrency_slots_in_use", Help: "Concurrency slots currently held, by account.", Labels: labels, Value: float64(t.inUse)},
+			metrics.Sample{Name: "sub2api_account_concurrency_slots_capacity", Help: "Configured concurrency, by account.", Labels: labels, Value: float64(t.capacity)},
+		)
+	}
+	return samples
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func concurrencySampleValue(samples []metrics.Sample, name string, labels ...string) (float64, bool) {
+	for _, s := range samples {
+		if s.Name != name || len(s.Labels)*2 != len(labels) {
+			continue
+		}
+		match := true
+		for i, l := range s.Labels {
+			if l.Name != labels[2*i] || l.Value != labels[2*i+1] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return s.Value, true
+		}
+	}
+	return 0, false
+}
+
+func TestBuildConcurrencySamples_AggregatesByPlatformGroupAndAccount(t *testing.T) {
+	g1 := &Group{ID: 1}
+	g2 := &Group{ID: 2}
+	accounts := []Account{
+		{ID: 10, Platform: PlatformAnthropic, Concurrency: 5, Groups: []*Group{g1, g2}},
+		{ID: 11, Platform: PlatformAnthropic, Concurrency: 3, Groups: []*Group{g1}},
+		{ID: 10, Platform: PlatformAnthropic, Concurrency: 5, Groups: []*Group{g1}}, // duplicate row from a join
+		{ID: 20, Platform: PlatformOpenAI, Concurrency: 4},
+	}
+	loadMap := map[int64]*AccountLoadInfo{
+		10: {AccountID: 10, CurrentConcurrency: 2, WaitingCount: 1},
+		11: {AccountID: 11, CurrentConcurrency: 3},
+		20: {AccountID: 20, CurrentConcurrency: 1, WaitingCount: 4},
+	}
+	limiter := metrics.NewLabelLimiter(map[string]int{metrics.LabelAccount: 10, metrics.LabelGroup: 10})
+
+	samples := buildConcurrencySamples(accounts, loadMap, limiter)
+
+	v, ok := concurrencySampleValue(samples, "sub2api_concurrency_slots_in_use", metrics.LabelPlatform, PlatformAnthropic)
+	require.True(t, ok)
+	require.Equal(t, float64(5), v)
+	v, _ = concurrencySampleValue(samples, "sub2api_concurrency_slots_capacity", metrics.LabelPlatform, PlatformAnthropic)
+	require.Equal(t, float64(8), v)
+	v, _ = concurrencySampleValue(samples, "sub2api_concurrency_waiting", metrics.LabelPlatform, PlatformOpenAI)
+	require.Equal(t, float64(4), v)
+
+	v, _ = concurrencySampleValue(samples, "sub2api_group_concurrency_slots_in_use", metrics.LabelPlatform, PlatformAnthropic, metrics.LabelGroup, "1")
+	require.Equal(t, float64(5), v)
+	v, _ = concurrencySampleValue(samples, "sub2api_group_concurrency_slots_capacity", metrics.LabelPlatform, PlatformAnthropic, metrics.LabelGroup, "2")
+	require.Equal(t, float64(5), v)
+
+	v, _ = concurrencySampleValue(samples, "sub2api_account_concurrency_slots_in_use", metrics.LabelPlatform, PlatformOpenAI, metrics.LabelAccount, "20")
+	require.Equal(t, float64(1), v)
+}
+
+func TestBuildConcurrencySamples_OverflowAccountsAreSummed(t *testing.T) {
+	accounts := []Account{
+		{ID: 1, Platform: PlatformOpenAI, Concurrency: 2},
+		{ID: 2, Platform: PlatformOpenAI, Concurrency: 3},
+		{ID: 3, Platform: PlatformOpenAI, Concurrency: 4},
+	}
+	limiter := metrics.NewLabelLimiter(map[string]int{metrics.LabelAccount: 1, metrics.LabelGroup: 0})
+
+	samples := buildConcurrencySamples(accounts, nil, limiter)
+
+	v, ok := concurrencySampleValue(samples, "sub2api_account_concurrency_slots_capacity", metrics.LabelPlatform, PlatformOpenAI, metrics.LabelAccount, metrics.OverflowLabelValue)
+	require.True(t, ok)
+	require.Equal(t, float64(7), v)
+	for _, s := range samples {
+		require.NotContains(t, s.Name, "sub2api_group_", "group series must be dropped when the cap is 0")
+	}
+}
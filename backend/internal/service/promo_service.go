@@ -124,7 +124,12 @@ func (s *PromoService) ApplyPromoCode(ctx context.Context, userID int64, code st
 	}
 
 	// 增加用户余额
-	if err := s.userRepo.UpdateBalance(txCtx, userID, promoCode.BonusAmount); err != nil {
+	ledgerCtx := WithBalanceLedgerSource(txCtx, BalanceLedgerSource{
+		Reason:     BalanceLedgerReasonPromo,
+		SourceType: BalanceLedgerSourcePromoCode,
+		SourceID:   promoCode.Code,
+	})
+	if err := s.userRepo.UpdateBalance(ledgerCtx, userID, promoCode.BonusAmount); err != nil {
 		return fmt.Errorf("update user balance: %w", err)
 	}
 
@@ -457,14 +457,20 @@ func (s *RedeemService) Redeem(ctx context.Context, userID int64, code string) (
 	switch redeemCode.Type {
 	case RedeemTypeBalance:
 		amount := redeemCode.Value
+		// 支付履约经由兑换码入账时保留上层设置的订单来源
+		ledgerCtx := WithDefaultBalanceLedgerSource(txCtx, BalanceLedgerSource{
+			Reason:     BalanceLedgerReasonRedeem,
+			SourceType: BalanceLedgerSourceRedeemCode,
+			SourceID:   redeemCode.Code,
+		})
 		if amount < 0 {
 			if s.redeemUserRepo == nil {
 				return nil, errors.New("user repository does not support atomic redeem balance adjustments")
 			}
-			if err := s.redeemUserRepo.ApplyRedeemBalanceAdjustment(txCtx, userID, amount); err != nil {
+			if err := s.redeemUserRepo.ApplyRedeemBalanceAdjustment(ledgerCtx, userID, amount); err != nil {
 				return nil, fmt.Errorf("update user balance: %w", err)
 			}
-		} else if err := s.userRepo.UpdateBalance(txCtx, userID, amount); err != nil {
+		} else if err := s.userRepo.UpdateBalance(ledgerCtx, userID, amount); err != nil {
 			return nil, fmt.Errorf("update user balance: %w", err)
 		}
 
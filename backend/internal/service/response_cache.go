@@ -0,0 +1,475 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/tidwall/gjson"
+)
+
+// ErrResponseCacheMiss 缓存未命中。
+var ErrResponseCacheMiss = errors.New("response cache miss")
+
+// responseCacheVolatileFields 不参与缓存键的请求字段：它们标识调用方或会话，
+// 不影响模型输出，保留会让同一提示词在不同会话间永远无法命中。
+var responseCacheVolatileFields = []string{"metadata", "user", "safety_identifier", "prompt_cache_key"}
+
+// ResponseCacheEntry 一条缓存的上游成功响应。流式响应保存完整 SSE 字节流，
+// 命中时按事件边界逐条回放。
+type ResponseCacheEntry struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Stream      bool   `json:"stream"`
+	Body        []byte `json:"body"`
+	// Model 原请求模型，命中计费按它查价。
+	Model string `json:"model"`
+	// AccountID 产生该响应的上游账号；usage_logs.account_id 非空，命中记录沿用它，
+	// 但账号倍率记为 0，不计入账号额度与成本统计。
+	AccountID           int64     `json:"account_id"`
+	InputTokens         int       `json:"input_tokens"`
+	OutputTokens        int       `json:"output_tokens"`
+	CacheCreationTokens int       `json:"cache_creation_tokens"`
+	CacheReadTokens     int       `json:"cache_read_tokens"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// ResponseCacheStore 响应缓存存储（Redis）。
+type ResponseCacheStore interface {
+	GetResponseCacheEntry(ctx context.Context, key string) (*ResponseCacheEntry, error)
+	SetResponseCacheEntry(ctx context.Context, key string, entry *ResponseCacheEntry, ttl time.Duration) error
+}
+
+// ResponseCachePolicy 某个 API Key 生效的缓存策略。
+type ResponseCachePolicy struct {
+	TTL time.Duration
+	// PriceMultiplier 命中计费倍率，叠加在用户分组有效倍率之上；0 表示命中免费。
+	PriceMultiplier float64
+}
+
+// ResponseCacheRequest 一次可缓存请求的规范化结果。
+type ResponseCacheRequest struct {
+	Key    string
+	Model  string
+	Stream bool
+}
+
+// ResponseCacheService 精确匹配响应缓存：按入口端点 + 分组 + 规范化请求体命中，
+// 命中请求不转发上游，以 billing_mode=response_cache 单独计费。
+type ResponseCacheService struct {
+	store          ResponseCacheStore
+	gatewayService *GatewayService
+	cfg            *config.Config
+}
+
+func NewResponseCacheService(store ResponseCacheStore, gatewayService *GatewayService, cfg *config.Config) *ResponseCacheService {
+	return &ResponseCacheService{store: store, gatewayService: gatewayService, cfg: cfg}
+}
+
+// PolicyFor 返回 API Key 的缓存策略；分组或 Key 均未启用时返回 false。
+// Key 单独启用时 TTL 与命中计价仍沿用其分组配置。
+func (s *ResponseCacheService) PolicyFor(apiKey *APIKey) (ResponseCachePolicy, bool) {
+	if s == nil || s.store == nil || s.cfg == nil || !s.cfg.ResponseCache.Enabled || apiKey == nil {
+		return ResponseCachePolicy{}, false
+	}
+	groupEnabled := apiKey.Group != nil && apiKey.Group.ResponseCacheEnabled
+	if !groupEnabled && !apiKey.ResponseCacheEnabled {
+		return ResponseCachePolicy{}, false
+	}
+	policy := ResponseCachePolicy{TTL: time.Duration(s.cfg.ResponseCache.DefaultTTLSeconds) * time.Second}
+	if apiKey.Group != nil {
+		if apiKey.Group.ResponseCacheTTLSeconds > 0 {
+			policy.TTL = time.Duration(apiKey.Group.ResponseCacheTTLSeconds) * time.Second
+		}
+		policy.PriceMultiplier = apiKey.Group.ResponseCachePriceMultiplier
+	}
+	if policy.TTL <= 0 {
+		return ResponseCachePolicy{}, false
+	}
+	return policy, true
+}
+
+// MaxEntryBytes 单条缓存响应体的字节上限。
+func (s *ResponseCacheService) MaxEntryBytes() int {
+	if s == nil || s.cfg == nil || s.cfg.ResponseCache.MaxEntryBytes <= 0 {
+		return 1024 * 1024
+	}
+	return s.cfg.ResponseCache.MaxEntryBytes
+}
+
+// BuildRequest 规范化请求体并计算缓存键；请求体不是带 model 的 JSON 对象时返回 false。
+func (s *ResponseCacheService) BuildRequest(inboundEndpoint string, groupID int64, body []byte) (ResponseCacheRequest, bool) {
+	canonical, model, stream, ok := canonicalizeResponseCacheBody(body)
+	if !ok {
+		return ResponseCacheRequest{}, false
+	}
+	h := sha256.New()
+	_, _ = h.Write([]byte(strings.TrimSpace(inboundEndpoint)))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(strconv.FormatInt(groupID, 10)))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(canonical)
+	return ResponseCacheRequest{Key: hex.EncodeToString(h.Sum(nil)), Model: model, Stream: stream}, true
+}
+
+func (s *ResponseCacheService) Lookup(ctx context.Context, key string) (*ResponseCacheEntry, error) {
+	if s == nil || s.store == nil {
+		return nil, ErrResponseCacheMiss
+	}
+	return s.store.GetResponseCacheEntry(ctx, key)
+}
+
+// Store 校验并写入一条上游响应：只缓存 200、带用量且（流式时）完整结束的响应。
+func (s *ResponseCacheService) Store(ctx context.Context, req ResponseCacheRequest, policy ResponseCachePolicy, statusCode int, contentType string, body []byte, accountID int64) bool {
+	if s == nil || s.store == nil || statusCode != 200 || accountID <= 0 || len(body) == 0 || len(body) > s.MaxEntryBytes() {
+		return false
+	}
+	if req.Stream && !responseCacheStreamCompleted(body) {
+		return false
+	}
+	usage, ok := extractResponseCacheUsage(body, req.Stream)
+	if !ok {
+		return false
+	}
+	entry := &ResponseCacheEntry{
+		StatusCode:          statusCode,
+		ContentType:         contentType,
+		Stream:              req.Stream,
+		Body:                body,
+		Model:               req.Model,
+		AccountID:           accountID,
+		InputTokens:         usage.InputTokens,
+		OutputTokens:        usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationTokens,
+		CacheReadTokens:     usage.CacheReadTokens,
+		CreatedAt:           time.Now().UTC(),
+	}
+	if err := s.store.SetResponseCacheEntry(ctx, req.Key, entry, policy.TTL); err != nil {
+		logger.LegacyPrintf("service.response_cache", "store response cache entry failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// ResponseCacheHitPrice 回放前算好的命中费用。
+type ResponseCacheHitPrice struct {
+	Cost *CostBreakdown
+	// RateMultiplier 有效倍率 = 用户分组倍率（含高峰因子）× 分组命中倍率。
+	RateMultiplier float64
+}
+
+// ResponseCacheHitInput 一次缓存命中的计费输入。
+type ResponseCacheHitInput struct {
+	APIKey          *APIKey
+	User            *User
+	Subscription    *UserSubscription
+	Entry           *ResponseCacheEntry
+	Policy          ResponseCachePolicy
+	Price           *ResponseCacheHitPrice
+	InboundEndpoint string
+	UserAgent       string
+	IPAddress       string
+	APIKeyService   APIKeyQuotaUpdater
+	QuotaPlatform   string
+	Duration        time.Duration
+}
+
+// PriceHit 在回放前为命中计价。返回错误时调用方应按未命中处理（转发上游），
+// 不能在定价缺失时把缓存响应当作免费命中回放。
+func (s *ResponseCacheService) PriceHit(ctx context.Context, apiKey *APIKey, user *User, entry *ResponseCacheEntry, policy ResponseCachePolicy) (*ResponseCacheHitPrice, error) {
+	if s == nil || s.gatewayService == nil {
+		return &ResponseCacheHitPrice{Cost: &CostBreakdown{}}, nil
+	}
+	return s.gatewayService.PriceResponseCacheHit(ctx, apiKey, user, entry, policy)
+}
+
+// RecordHit 为命中请求写使用记录并按命中倍率扣费。
+func (s *ResponseCacheService) RecordHit(ctx context.Context, input *ResponseCacheHitInput) error {
+	if s == nil || s.gatewayService == nil {
+		return nil
+	}
+	return s.gatewayService.RecordResponseCacheHit(ctx, input)
+}
+
+// PriceResponseCacheHit 按缓存的用量与原请求模型计价，定价解析与 recordUsageCore 相同
+// （渠道/分组定价优先，再回退全局价格表）。命中倍率为 0 的分组本就免费，查不到价格时按 $0 记录；
+// 其余情况查不到价格返回错误，由调用方改走上游。
+func (s *GatewayService) PriceResponseCacheHit(ctx context.Context, apiKey *APIKey, user *User, entry *ResponseCacheEntry, policy ResponseCachePolicy) (*ResponseCacheHitPrice, error) {
+	if apiKey == nil || user == nil || entry == nil {
+		return nil, errors.New("response cache hit: missing api key, user or entry")
+	}
+	multiplier := 1.0
+	if s.cfg != nil {
+		multiplier = s.cfg.Default.RateMultiplier
+	}
+	if apiKey.GroupID != nil && apiKey.Group != nil {
+		multiplier = s.ResolveUserGroupRateMultiplier(ctx, user.ID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+	}
+	pricingAt := timezone.Now()
+	multiplier, _ = computePeakAwareMultipliers(apiKey, multiplier, pricingAt)
+	multiplier *= policy.PriceMultiplier
+
+	tokens := UsageTokens{
+		InputTokens:         entry.InputTokens,
+		OutputTokens:        entry.OutputTokens,
+		CacheCreationTokens: entry.CacheCreationTokens,
+		CacheReadTokens:     entry.CacheReadTokens,
+	}
+	var cost *CostBreakdown
+	err := errors.New("billing service unavailable")
+	if s.billingService != nil {
+		cost, err = s.resolveTokenCost(ctx, apiKey, entry.Model, tokens, multiplier, pricingAt, "", nil)
+	}
+	if err != nil || cost == nil {
+		if policy.PriceMultiplier != 0 {
+			return nil, fmt.Errorf("price response cache hit (model=%s): %w", entry.Model, err)
+		}
+		cost = &CostBreakdown{}
+	}
+	return &ResponseCacheHitPrice{Cost: cost, RateMultiplier: multiplier}, nil
+}
+
+// RecordResponseCacheHit 命中请求的计费：使用回放前 PriceResponseCacheHit 算好的费用
+// （未传入时现算）。命中没有调用上游，因此账号倍率记为 0，不计入账号额度。
+func (s *GatewayService) RecordResponseCacheHit(ctx context.Context, input *ResponseCacheHitInput) error {
+	if input == nil || input.APIKey == nil || input.User == nil || input.Entry == nil {
+		return nil
+	}
+	apiKey, user, entry := input.APIKey, input.User, input.Entry
+
+	price := input.Price
+	if price == nil {
+		var err error
+		if price, err = s.PriceResponseCacheHit(ctx, apiKey, user, entry, input.Policy); err != nil {
+			return err
+		}
+	}
+	multiplier := price.RateMultiplier
+	copied := *price.Cost
+	cost := &copied
+	billingMode := string(BillingModeResponseCache)
+	cost.BillingMode = billingMode
+
+	isSubscriptionBilling := input.Subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
+	billingType := BillingTypeBalance
+	if isSubscriptionBilling {
+		billingType = BillingTypeSubscription
+	}
+
+	requestType := RequestTypeSync
+	if entry.Stream {
+		requestType = RequestTypeStream
+	}
+	requestID := responseCacheHitRequestID(ctx)
+	accountRateMultiplier := 0.0
+	inboundEndpoint := strings.TrimSpace(input.InboundEndpoint)
+	durationMs := int(input.Duration.Milliseconds())
+	usageLog := &UsageLog{
+		UserID:                user.ID,
+		APIKeyID:              apiKey.ID,
+		AccountID:             entry.AccountID,
+		RequestID:             requestID,
+		Model:                 entry.Model,
+		RequestedModel:        entry.Model,
+		BillingMode:           &billingMode,
+		GroupID:               apiKey.GroupID,
+		InputTokens:           entry.InputTokens,
+		OutputTokens:          entry.OutputTokens,
+		CacheCreationTokens:   entry.CacheCreationTokens,
+		CacheReadTokens:       entry.CacheReadTokens,
+		InputCost:             cost.InputCost,
+		OutputCost:            cost.OutputCost,
+		CacheCreationCost:     cost.CacheCreationCost,
+		CacheReadCost:         cost.CacheReadCost,
+		TotalCost:             cost.TotalCost,
+		ActualCost:            cost.ActualCost,
+		RateMultiplier:        multiplier,
+		AccountRateMultiplier: &accountRateMultiplier,
+		BillingType:           billingType,
+		RequestType:           requestType,
+		Stream:                entry.Stream,
+		DurationMs:            &durationMs,
+		CreatedAt:             time.Now(),
+	}
+	if inboundEndpoint != "" {
+		usageLog.InboundEndpoint = &inboundEndpoint
+	}
+	if ua := strings.TrimSpace(input.UserAgent); ua != "" {
+		usageLog.UserAgent = &ua
+	}
+	if ip := strings.TrimSpace(input.IPAddress); ip != "" {
+		usageLog.IPAddress = &ip
+	}
+	if isSubscriptionBilling {
+		usageLog.SubscriptionID = &input.Subscription.ID
+	}
+
+	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
+		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+		return nil
+	}
+
+	_, billingErr := applyUsageBilling(ctx, requestID, usageLog, &postUsageBillingParams{
+		Cost:                  cost,
+		User:                  user,
+		APIKey:                apiKey,
+		Account:               &Account{ID: entry.AccountID},
+		Subscription:          input.Subscription,
+		RequestPayloadHash:    resolveUsageBillingPayloadFingerprint(ctx, ""),
+		IsSubscriptionBill:    isSubscriptionBilling,
+		AccountRateMultiplier: accountRateMultiplier,
+		APIKeyService:         input.APIKeyService,
+		Platform:              input.QuotaPlatform,
+	}, s.billingDeps(), s.usageBillingRepo)
+	if billingErr != nil {
+		usageLog.ActualCost = 0
+		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+		return billingErr
+	}
+	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.response_cache")
+	return nil
+}
+
+func responseCacheHitRequestID(ctx context.Context) string {
+	if ctx != nil {
+		if requestID, _ := ctx.Value(ctxkey.RequestID).(string); strings.TrimSpace(requestID) != "" {
+			return "response_cache:" + strings.TrimSpace(requestID)
+		}
+	}
+	return "response_cache:" + generateRequestID()
+}
+
+// canonicalizeResponseCacheBody 解析请求体并以稳定的键序重新编码（encoding/json 对 map
+// 按键排序），数字保留原始字面量；同时去掉 responseCacheVolatileFields。
+func canonicalizeResponseCacheBody(body []byte) ([]byte, string, bool, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil || payload == nil {
+		return nil, "", false, false
+	}
+	model, _ := payload["model"].(string)
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil, "", false, false
+	}
+	stream, _ := payload["stream"].(bool)
+	for _, field := range responseCacheVolatileFields {
+		delete(payload, field)
+	}
+	canonical, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", false, false
+	}
+	return canonical, model, stream, true
+}
+
+type responseCacheUsage struct {
+	InputTokens         int
+	OutputTokens        int
+	CacheCreationTokens int
+	CacheReadTokens     int
+}
+
+// extractResponseCacheUsage 从响应中提取用量，兼容 Anthropic Messages、
+// Chat Completions 与 Responses 三种格式；流式响应逐个 data 事件取各项最大值
+// （Anthropic 的 message_start / message_delta 各携带一部分用量）。
+func extractResponseCacheUsage(body []byte, stream bool) (responseCacheUsage, bool) {
+	if !stream {
+		return responseCacheUsageFromJSON(body)
+	}
+	var merged responseCacheUsage
+	found := false
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+		data := bytes.TrimSpace(line[len("data:"):])
+		if len(data) == 0 || data[0] != '{' {
+			continue
+		}
+		usage, ok := responseCacheUsageFromJSON(data)
+		if !ok {
+			continue
+		}
+		found = true
+		merged.InputTokens = max(merged.InputTokens, usage.InputTokens)
+		merged.OutputTokens = max(merged.OutputTokens, usage.OutputTokens)
+		merged.CacheCreationTokens = max(merged.CacheCreationTokens, usage.CacheCreationTokens)
+		merged.CacheReadTokens = max(merged.CacheReadTokens, usage.CacheReadTokens)
+	}
+	return merged, found
+}
+
+func responseCacheUsageFromJSON(data []byte) (responseCacheUsage, bool) {
+	var usage gjson.Result
+	for _, path := range []string{"usage", "message.usage", "response.usage"} {
+		if u := gjson.GetBytes(data, path); u.IsObject() {
+			usage = u
+			break
+		}
+	}
+	if !usage.Exists() {
+		return responseCacheUsage{}, false
+	}
+	// Chat Completions：prompt_tokens 含缓存命中部分。
+	if prompt := usage.Get("prompt_tokens"); prompt.Exists() {
+		cached := int(usage.Get("prompt_tokens_details.cached_tokens").Int())
+		return responseCacheUsage{
+			InputTokens:     max(int(prompt.Int())-cached, 0),
+			OutputTokens:    int(usage.Get("completion_tokens").Int()),
+			CacheReadTokens: cached,
+		}, true
+	}
+	// Responses 的 input_tokens 含 input_tokens_details.cached_tokens；
+	// Anthropic 的 input_tokens 不含缓存，缓存单独记在 cache_*_input_tokens。
+	cached := int(usage.Get("input_tokens_details.cached_tokens").Int())
+	return responseCacheUsage{
+		InputTokens:         max(int(usage.Get("input_tokens").Int())-cached, 0),
+		OutputTokens:        int(usage.Get("output_tokens").Int()),
+		CacheCreationTokens: int(usage.Get("cache_creation_input_tokens").Int()),
+		CacheReadTokens:     int(usage.Get("cache_read_input_tokens").Int()) + cached,
+	}, true
+}
+
+// responseCacheStreamCompleted 只有正常结束的流才可缓存：被截断或中途报错的流
+// 回放出去会让客户端永远等不到结束事件。
+func responseCacheStreamCompleted(body []byte) bool {
+	if bytes.Contains(body, []byte("event: error")) || bytes.Contains(body, []byte(`"type":"error"`)) {
+		return false
+	}
+	return bytes.Contains(body, []byte("data: [DONE]")) ||
+		bytes.Contains(body, []byte(`"type":"message_stop"`)) ||
+		bytes.Contains(body, []byte(`"type":"response.completed"`))
+}
+
+// SplitResponseCacheSSEEvents 把缓存的 SSE 字节流按空行切分成完整事件，
+// 每个事件保留结尾的空行分隔符，回放时逐条写出并 flush。
+func SplitResponseCacheSSEEvents(body []byte) [][]byte {
+	normalized := bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+	parts := bytes.Split(normalized, []byte("\n\n"))
+	events := make([][]byte, 0, len(parts))
+	for _, part := range parts {
+		part = bytes.Trim(part, "\n")
+		if len(part) == 0 {
+			continue
+		}
+		event := make([]byte, 0, len(part)+2)
+		event = append(event, part...)
+		event = append(event, '\n', '\n')
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,247 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+type responseCacheStoreStub struct {
+	entries map[string]*ResponseCacheEntry
+	lastTTL time.Duration
+}
+
+func (s *responseCacheStoreStub) GetResponseCacheEntry(_ context.Context, key string) (*ResponseCacheEntry, error) {
+	if entry, ok := s.entries[key]; ok {
+		return entry, nil
+	}
+	return nil, ErrResponseCacheMiss
+}
+
+func (s *responseCacheStoreStub) SetResponseCacheEntry(_ context.Context, key string, entry *ResponseCacheEntry, ttl time.Duration) error {
+	if s.entries == nil {
+		s.entries = map[string]*ResponseCacheEntry{}
+	}
+	s.entries[key] = entry
+	s.lastTTL = ttl
+	return nil
+}
+
+func newResponseCacheServiceForTest(store ResponseCacheStore, gateway *GatewayService) *ResponseCacheService {
+	cfg := &config.Config{}
+	cfg.ResponseCache.Enabled = true
+	cfg.ResponseCache.DefaultTTLSeconds = 600
+	cfg.ResponseCache.MaxEntryBytes = 1024
+	return NewResponseCacheService(store, gateway, cfg)
+}
+
+func TestResponseCacheService_PolicyFor(t *testing.T) {
+	svc := newResponseCacheServiceForTest(&responseCacheStoreStub{}, nil)
+
+	_, ok := svc.PolicyFor(&APIKey{ID: 1, Group: &Group{ID: 2}})
+	require.False(t, ok, "分组与 Key 均未启用时不缓存")
+
+	policy, ok := svc.PolicyFor(&APIKey{ID: 1, Group: &Group{ID: 2, ResponseCacheEnabled: true, ResponseCacheTTLSeconds: 30, ResponseCachePriceMultiplier: 0.25}})
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, policy.TTL)
+	require.InDelta(t, 0.25, policy.PriceMultiplier, 1e-12)
+
+	policy, ok = svc.PolicyFor(&APIKey{ID: 1, ResponseCacheEnabled: true, Group: &Group{ID: 2}})
+	require.True(t, ok, "Key 单独启用")
+	require.Equal(t, 600*time.Second, policy.TTL, "分组未配置 TTL 时使用全局默认值")
+	require.Zero(t, policy.PriceMultiplier)
+
+	svc.cfg.ResponseCache.Enabled = false
+	_, ok = svc.PolicyFor(&APIKey{ID: 1, ResponseCacheEnabled: true})
+	require.False(t, ok, "全局关闭时不缓存")
+}
+
+func TestResponseCacheService_BuildRequestNormalizesBody(t *testing.T) {
+	svc := newResponseCacheServiceForTest(&responseCacheStoreStub{}, nil)
+
+	a, ok := svc.BuildRequest("/v1/messages", 7, []byte(`{"model":"claude-sonnet-4","max_tokens":64,"messages":[{"role":"user","content":"hi"}],"metadata":{"user_id":"u-1"}}`))
+	require.True(t, ok)
+	require.Equal(t, "claude-sonnet-4", a.Model)
+	require.False(t, a.Stream)
+
+	b, ok := svc.BuildRequest("/v1/messages", 7, []byte(`{
+		"messages": [{"content": "hi", "role": "user"}],
+		"max_tokens": 64,
+		"model": "claude-sonnet-4",
+		"metadata": {"user_id": "u-2"}
+	}`))
+	require.True(t, ok)
+	require.Equal(t, a.Key, b.Key, "字段顺序、空白与 metadata 不影响缓存键")
+
+	other, ok := svc.BuildRequest("/v1/messages", 8, []byte(`{"model":"claude-sonnet-4","max_tokens":64,"messages":[{"role":"user","content":"hi"}]}`))
+	require.True(t, ok)
+	require.NotEqual(t, a.Key, other.Key, "分组参与缓存键")
+
+	other, ok = svc.BuildRequest("/v1/chat/completions", 7, []byte(`{"model":"claude-sonnet-4","max_tokens":64,"messages":[{"role":"user","content":"hi"}]}`))
+	require.True(t, ok)
+	require.NotEqual(t, a.Key, other.Key, "入站端点参与缓存键")
+
+	streamed, ok := svc.BuildRequest("/v1/messages", 7, []byte(`{"model":"claude-sonnet-4","max_tokens":64,"stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+	require.True(t, ok)
+	require.True(t, streamed.Stream)
+	require.NotEqual(t, a.Key, streamed.Key, "流式与非流式分开缓存")
+
+	_, ok = svc.BuildRequest("/v1/messages", 7, []byte(`{"messages":[]}`))
+	require.False(t, ok, "缺少 model 不缓存")
+	_, ok = svc.BuildRequest("/v1/messages", 7, []byte(`[1,2]`))
+	require.False(t, ok)
+}
+
+func TestResponseCacheService_StoreExtractsUsage(t *testing.T) {
+	store := &responseCacheStoreStub{}
+	svc := newResponseCacheServiceForTest(store, nil)
+	policy := ResponseCachePolicy{TTL: time.Minute}
+
+	chat := ResponseCacheRequest{Key: "chat", Model: "gpt-5"}
+	require.True(t, svc.Store(context.Background(), chat, policy, 200, "application/json",
+		[]byte(`{"id":"c1","usage":{"prompt_tokens":100,"completion_tokens":20,"prompt_tokens_details":{"cached_tokens":40}}}`), 11))
+	require.Equal(t, 60, store.entries["chat"].InputTokens)
+	require.Equal(t, 20, store.entries["chat"].OutputTokens)
+	require.Equal(t, 40, store.entries["chat"].CacheReadTokens)
+	require.Equal(t, int64(11), store.entries["chat"].AccountID)
+	require.Equal(t, time.Minute, store.lastTTL)
+
+	messages := ResponseCacheRequest{Key: "msg", Model: "claude-sonnet-4", Stream: true}
+	stream := "event: message_start\r\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":12,\"cache_read_input_tokens\":3,\"output_tokens\":1}}}\r\n\r\n" +
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":9}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	require.True(t, svc.Store(context.Background(), messages, policy, 200, "text/event-stream", []byte(stream), 11))
+	require.Equal(t, 12, store.entries["msg"].InputTokens)
+	require.Equal(t, 9, store.entries["msg"].OutputTokens)
+	require.Equal(t, 3, store.entries["msg"].CacheReadTokens)
+	require.True(t, store.entries["msg"].Stream)
+
+	truncated := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":12}}}\n\n"
+	require.False(t, svc.Store(context.Background(), ResponseCacheRequest{Key: "cut", Stream: true}, policy, 200, "text/event-stream", []byte(truncated), 11), "未完整结束的流不缓存")
+	require.False(t, svc.Store(context.Background(), ResponseCacheRequest{Key: "nousage"}, policy, 200, "application/json", []byte(`{"id":"x"}`), 11), "没有用量的响应不缓存")
+	require.False(t, svc.Store(context.Background(), chat, policy, 500, "application/json", []byte(`{"usage":{"prompt_tokens":1}}`), 11))
+	require.False(t, svc.Store(context.Background(), ResponseCacheRequest{Key: "noacct"}, policy, 200, "application/json", []byte(`{"usage":{"prompt_tokens":1}}`), 0))
+	require.False(t, svc.Store(context.Background(), ResponseCacheRequest{Key: "big"}, policy, 200, "application/json", make([]byte, 2048), 11), "超过单条上限不缓存")
+}
+
+func TestSplitResponseCacheSSEEvents(t *testing.T) {
+	events := SplitResponseCacheSSEEvents([]byte("event: a\r\ndata: 1\r\n\r\ndata: 2\n\n\n\ndata: [DONE]"))
+	require.Equal(t, [][]byte{
+		[]byte("event: a\ndata: 1\n\n"),
+		[]byte("data: 2\n\n"),
+		[]byte("data: [DONE]\n\n"),
+	}, events)
+	require.Empty(t, SplitResponseCacheSSEEvents(nil))
+}
+
+func TestGatewayServiceRecordResponseCacheHit_BillsWithHitMultiplier(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	billingRepo := &openAIRecordUsageBillingRepoStub{}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(usageRepo, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	svc := newResponseCacheServiceForTest(&responseCacheStoreStub{}, gateway)
+
+	groupID := int64(5)
+	entry := &ResponseCacheEntry{StatusCode: 200, Model: "claude-sonnet-4", AccountID: 701, InputTokens: 1000, OutputTokens: 200, Stream: true}
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "req-1")
+	err := svc.RecordHit(ctx, &ResponseCacheHitInput{
+		APIKey:          &APIKey{ID: 501, GroupID: &groupID, Group: &Group{ID: groupID, RateMultiplier: 2}},
+		User:            &User{ID: 601},
+		Entry:           entry,
+		Policy:          ResponseCachePolicy{TTL: time.Minute, PriceMultiplier: 0.5},
+		InboundEndpoint: "/v1/messages",
+	})
+	require.NoError(t, err)
+
+	expected, err := gateway.billingService.CalculateCost("claude-sonnet-4", UsageTokens{InputTokens: 1000, OutputTokens: 200}, 1.0)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, usageRepo.calls)
+	log := usageRepo.lastLog
+	require.NotNil(t, log.BillingMode)
+	require.Equal(t, string(BillingModeResponseCache), *log.BillingMode)
+	require.Equal(t, "response_cache:req-1", log.RequestID)
+	require.Equal(t, int64(701), log.AccountID)
+	require.InDelta(t, 1.0, log.RateMultiplier, 1e-12, "分组倍率 × 命中倍率")
+	require.InDelta(t, expected.ActualCost, log.ActualCost, 1e-12)
+	require.Equal(t, RequestTypeStream, log.RequestType)
+	require.NotNil(t, log.AccountRateMultiplier)
+	require.Zero(t, *log.AccountRateMultiplier)
+
+	require.NotNil(t, billingRepo.lastCmd)
+	require.InDelta(t, expected.ActualCost, billingRepo.lastCmd.BalanceCost.InexactFloat64(), 1e-12)
+	require.True(t, billingRepo.lastCmd.AccountQuotaCost.IsZero(), "命中不消耗账号额度")
+}
+
+func TestGatewayServiceRecordResponseCacheHit_FreeHit(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	billingRepo := &openAIRecordUsageBillingRepoStub{}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(usageRepo, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	svc := newResponseCacheServiceForTest(&responseCacheStoreStub{}, gateway)
+
+	err := svc.RecordHit(context.Background(), &ResponseCacheHitInput{
+		APIKey: &APIKey{ID: 501},
+		User:   &User{ID: 601},
+		Entry:  &ResponseCacheEntry{Model: "claude-sonnet-4", AccountID: 701, InputTokens: 10, OutputTokens: 5},
+		Policy: ResponseCachePolicy{TTL: time.Minute},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, usageRepo.calls)
+	require.Zero(t, usageRepo.lastLog.ActualCost)
+	require.Equal(t, 10, usageRepo.lastLog.InputTokens, "免费命中仍记录用量")
+	require.Equal(t, RequestTypeSync, usageRepo.lastLog.RequestType)
+}
+
+func TestGatewayServiceRecordResponseCacheHit_UsesChannelPricing(t *testing.T) {
+	groupID := int64(905)
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	billingRepo := &openAIRecordUsageBillingRepoStub{}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(usageRepo, billingRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	gateway.resolver = newOpenAITokenImageChannelPricingResolverForTest(t, groupID, "relay-custom-model")
+	svc := newResponseCacheServiceForTest(&responseCacheStoreStub{}, gateway)
+
+	apiKey := &APIKey{ID: 501, GroupID: &groupID, Group: &Group{ID: groupID, RateMultiplier: 2}}
+	user := &User{ID: 601}
+	entry := &ResponseCacheEntry{StatusCode: 200, Model: "relay-custom-model", AccountID: 701, InputTokens: 1000, OutputTokens: 200}
+	policy := ResponseCachePolicy{TTL: time.Minute, PriceMultiplier: 0.5}
+
+	price, err := svc.PriceHit(context.Background(), apiKey, user, entry, policy)
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordHit(context.Background(), &ResponseCacheHitInput{
+		APIKey: apiKey, User: user, Entry: entry, Policy: policy, Price: price,
+	}))
+
+	expected := 1000*3e-6 + 200*15e-6
+	require.Equal(t, 1, usageRepo.calls)
+	require.InDelta(t, expected, usageRepo.lastLog.TotalCost, 1e-12, "按渠道定价而非全局价格表")
+	require.InDelta(t, expected, usageRepo.lastLog.ActualCost, 1e-12, "分组倍率 2 × 命中倍率 0.5")
+	require.Equal(t, string(BillingModeResponseCache), *usageRepo.lastLog.BillingMode)
+}
+
+func TestResponseCacheService_PriceHitFailsForUnpricedModel(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	gateway := newGatewayRecordUsageServiceWithBillingRepoForTest(usageRepo, &openAIRecordUsageBillingRepoStub{}, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{})
+	svc := newResponseCacheServiceForTest(&responseCacheStoreStub{}, gateway)
+
+	apiKey := &APIKey{ID: 501}
+	user := &User{ID: 601}
+	entry := &ResponseCacheEntry{Model: "unpriced-private-model", AccountID: 701, InputTokens: 10, OutputTokens: 5}
+
+	_, err := svc.PriceHit(context.Background(), apiKey, user, entry, ResponseCachePolicy{TTL: time.Minute, PriceMultiplier: 1})
+	require.Error(t, err, "查不到价格必须按未命中处理，不能免费回放")
+
+	err = svc.RecordHit(context.Background(), &ResponseCacheHitInput{
+		APIKey: apiKey, User: user, Entry: entry, Policy: ResponseCachePolicy{TTL: time.Minute, PriceMultiplier: 1},
+	})
+	require.Error(t, err)
+	require.Zero(t, usageRepo.calls)
+
+	price, err := svc.PriceHit(context.Background(), apiKey, user, entry, ResponseCachePolicy{TTL: time.Minute})
+	require.NoError(t, err, "命中倍率为 0 的分组本就免费")
+	require.Zero(t, price.Cost.ActualCost)
+}
@@ -0,0 +1,734 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// SAML 元数据来源
+const (
+	SAMLMetadataSourceImported  = "imported"
+	SAMLMetadataSourceConfigXML = "config_xml"
+	SAMLMetadataSourceConfigURL = "config_url"
+)
+
+// SAML 身份映射写入 pending session upstream claims 的键，绑定完成时由
+// AuthService.ApplySAMLDirectoryMapping 读取。
+const (
+	SAMLClaimRole     = "saml_role"
+	SAMLClaimGroupIDs = "saml_group_ids"
+	SAMLClaimGroups   = "saml_groups"
+)
+
+const samlMetadataMaxBytes = 1 << 20
+
+var (
+	ErrSAMLDisabled         = infraerrors.NotFound("SAML_DISABLED", "saml login is disabled")
+	ErrSAMLNotConfigured    = infraerrors.ServiceUnavailable("SAML_NOT_CONFIGURED", "saml identity provider metadata is not configured")
+	ErrSAMLMetadataInvalid  = infraerrors.BadRequest("SAML_METADATA_INVALID", "invalid saml identity provider metadata")
+	ErrSAMLMetadataRequired = infraerrors.BadRequest("SAML_METADATA_REQUIRED", "metadata_xml or metadata_url is required")
+	ErrSAMLAssertionInvalid = infraerrors.Unauthorized("SAML_ASSERTION_INVALID", "invalid saml assertion")
+	// ErrSAMLAssertionReplayed 同一条断言（按 assertion ID）在有效期内被重复提交到 ACS
+	ErrSAMLAssertionReplayed = infraerrors.Unauthorized("SAML_ASSERTION_REPLAYED", "saml assertion has already been used")
+	ErrSAMLReplayCheckFailed = infraerrors.ServiceUnavailable("SAML_REPLAY_CHECK_FAILED", "failed to check saml assertion replay")
+)
+
+// SAMLAssertionReplayCache 记录已消费的断言 ID，用于拒绝重放。
+type SAMLAssertionReplayCache interface {
+	// MarkSAMLAssertionUsed 原子地登记断言 ID，ttl 内重复登记返回 false
+	MarkSAMLAssertionUsed(ctx context.Context, assertionID string, ttl time.Duration) (bool, error)
+}
+
+var (
+	samlDefaultEmailAttributes = []string{
+		"email", "mail", "emailaddress",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+	}
+	samlDefaultUsernameAttributes = []string{
+		"username", "uid", "preferred_username",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name",
+		"urn:oid:0.9.2342.19200300.100.1.1",
+	}
+	samlDefaultDisplayNameAttributes = []string{
+		"displayname", "cn", "name",
+		"http://schemas.microsoft.com/identity/claims/displayname",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+	}
+	samlDefaultGroupsAttributes = []string{
+		"groups", "memberof", "roles", "role",
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups",
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/role",
+	}
+)
+
+// SAMLIdPMetadataSummary 是 IdP 元数据中与登录相关字段的摘要。
+type SAMLIdPMetadataSummary struct {
+	EntityID                string     `json:"entity_id"`
+	SSOURL                  string     `json:"sso_url"`
+	CertificateFingerprints []string   `json:"certificate_fingerprints"`
+	ValidUntil              *time.Time `json:"valid_until,omitempty"`
+}
+
+// SAMLStatus 是管理后台查看的 SAML SP/IdP 配置状态。
+type SAMLStatus struct {
+	Enabled           bool                    `json:"enabled"`
+	ProviderName      string                  `json:"provider_name"`
+	EntityID          string                  `json:"entity_id"`
+	ACSURL            string                  `json:"acs_url"`
+	MetadataURL       string                  `json:"metadata_url"`
+	AllowIdPInitiated bool                    `json:"allow_idp_initiated"`
+	SignAuthnRequests bool                    `json:"sign_authn_requests"`
+	MetadataSource    string                  `json:"metadata_source"`
+	ImportedAt        *time.Time              `json:"imported_at,omitempty"`
+	IdP               *SAMLIdPMetadataSummary `json:"idp,omitempty"`
+	MetadataError     string                  `json:"metadata_error,omitempty"`
+}
+
+// SAMLIdentity 是从一条已校验断言中解析出的上游身份与目录映射结果。
+type SAMLIdentity struct {
+	Issuer      string
+	Subject     string
+	Email       string
+	Username    string
+	DisplayName string
+	Groups      []string
+	// Role 为空表示不改动本地角色
+	Role     string
+	GroupIDs []int64
+}
+
+// SAMLService 负责组装 SAML SP、导入 IdP 元数据，并把断言属性映射到本地身份/角色/分组。
+type SAMLService struct {
+	cfg         *config.Config
+	settingRepo SettingRepository
+	httpClient  *http.Client
+	replayCache SAMLAssertionReplayCache
+
+	mu              sync.Mutex
+	remoteMetadata  *saml.EntityDescriptor
+	remoteFetchedAt time.Time
+}
+
+// NewSAMLService creates a new SAMLService
+// replayCache 为空时退化为进程内缓存，仅适用于单实例部署。
+func NewSAMLService(cfg *config.Config, settingRepo SettingRepository, replayCache SAMLAssertionReplayCache) *SAMLService {
+	if cfg != nil && cfg.SAML.Enabled && cfg.SAML.ClockSkewSeconds > 0 {
+		saml.MaxClockSkew = time.Duration(cfg.SAML.ClockSkewSeconds) * time.Second
+	}
+	if replayCache == nil {
+		replayCache = newLocalSAMLAssertionReplayCache()
+	}
+	return &SAMLService{
+		cfg:         cfg,
+		settingRepo: settingRepo,
+		httpClient:  &http.Client{Timeout: 15 * time.Second},
+		replayCache: replayCache,
+	}
+}
+
+// Config 返回 SAML 配置；未启用时返回 ErrSAMLDisabled。
+func (s *SAMLService) Config() (config.SAMLConfig, error) {
+	if s == nil || s.cfg == nil {
+		return config.SAMLConfig{}, infraerrors.ServiceUnavailable("CONFIG_NOT_READY", "config not loaded")
+	}
+	if !s.cfg.SAML.Enabled {
+		return config.SAMLConfig{}, ErrSAMLDisabled
+	}
+	return s.cfg.SAML, nil
+}
+
+// ServiceProvider 组装带 IdP 元数据的 SP，用于发起 AuthnRequest 和校验 ACS 响应。
+func (s *SAMLService) ServiceProvider(ctx context.Context) (*saml.ServiceProvider, error) {
+	sp, err := s.baseServiceProvider()
+	if err != nil {
+		return nil, err
+	}
+	idp, _, err := s.idpMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sp.IDPMetadata = idp
+	return sp, nil
+}
+
+// SPMetadata 返回本 SP 的元数据 XML，供 IdP 侧导入；不依赖 IdP 元数据。
+func (s *SAMLService) SPMetadata() ([]byte, error) {
+	sp, err := s.baseServiceProvider()
+	if err != nil {
+		return nil, err
+	}
+	out, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal sp metadata: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// Status 返回当前 SP 配置与 IdP 元数据摘要；元数据加载失败时写入 MetadataError 而非报错。
+func (s *SAMLService) Status(ctx context.Context) (*SAMLStatus, error) {
+	if s == nil || s.cfg == nil {
+		return nil, infraerrors.ServiceUnavailable("CONFIG_NOT_READY", "config not loaded")
+	}
+	cfg := s.cfg.SAML
+	status := &SAMLStatus{
+		Enabled:           cfg.Enabled,
+		ProviderName:      cfg.ProviderName,
+		ACSURL:            cfg.ACSURL,
+		AllowIdPInitiated: cfg.AllowIdPInitiated,
+		SignAuthnRequests: cfg.SignAuthnRequests,
+	}
+	if metadataURL, err := samlSPMetadataURL(cfg.ACSURL); err == nil {
+		status.MetadataURL = metadataURL.String()
+		status.EntityID = firstNonEmpty(cfg.EntityID, status.MetadataURL)
+	}
+	if importedAt := s.importedAt(ctx); importedAt != nil {
+		status.ImportedAt = importedAt
+	}
+
+	idp, source, err := s.idpMetadata(ctx)
+	status.MetadataSource = source
+	if err != nil {
+		status.MetadataError = infraerrors.Message(err)
+		return status, nil
+	}
+	status.IdP = summarizeSAMLIdPMetadata(idp)
+	return status, nil
+}
+
+// ImportIdPMetadata 解析并保存 IdP 元数据（XML 优先，其次从 URL 拉取），覆盖配置文件中的元数据。
+func (s *SAMLService) ImportIdPMetadata(ctx context.Context, metadataXML, metadataURL string) (*SAMLIdPMetadataSummary, error) {
+	if s == nil || s.settingRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("CONFIG_NOT_READY", "setting repository not ready")
+	}
+	raw := []byte(strings.TrimSpace(metadataXML))
+	if len(raw) == 0 {
+		metadataURL = strings.TrimSpace(metadataURL)
+		if metadataURL == "" {
+			return nil, ErrSAMLMetadataRequired
+		}
+		fetched, err := s.fetchMetadataBytes(ctx, metadataURL)
+		if err != nil {
+			return nil, err
+		}
+		raw = fetched
+	}
+
+	idp, err := parseSAMLIdPMetadata(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.settingRepo.SetMultiple(ctx, map[string]string{
+		SettingKeySAMLIdPMetadataXML:        string(raw),
+		SettingKeySAMLIdPMetadataImportedAt: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		return nil, fmt.Errorf("save saml idp metadata: %w", err)
+	}
+	return summarizeSAMLIdPMetadata(idp), nil
+}
+
+// DeleteImportedIdPMetadata 删除导入的 IdP 元数据，回退到配置文件中的元数据。
+func (s *SAMLService) DeleteImportedIdPMetadata(ctx context.Context) error {
+	if s == nil || s.settingRepo == nil {
+		return infraerrors.ServiceUnavailable("CONFIG_NOT_READY", "setting repository not ready")
+	}
+	if err := s.settingRepo.Delete(ctx, SettingKeySAMLIdPMetadataXML); err != nil && !errors.Is(err, ErrSettingNotFound) {
+		return err
+	}
+	if err := s.settingRepo.Delete(ctx, SettingKeySAMLIdPMetadataImportedAt); err != nil && !errors.Is(err, ErrSettingNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ResolveIdentity 从已校验的断言中提取身份属性，并按配置计算角色与分组映射。
+func (s *SAMLService) ResolveIdentity(sp *saml.ServiceProvider, assertion *saml.Assertion) (*SAMLIdentity, error) {
+	if s == nil || s.cfg == nil || assertion == nil {
+		return nil, ErrSAMLAssertionInvalid
+	}
+	cfg := s.cfg.SAML
+
+	issuer := strings.TrimSpace(assertion.Issuer.Value)
+	if issuer == "" && sp != nil && sp.IDPMetadata != nil {
+		issuer = strings.TrimSpace(sp.IDPMetadata.EntityID)
+	}
+	subject := ""
+	nameIDFormat := ""
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		subject = strings.TrimSpace(assertion.Subject.NameID.Value)
+		nameIDFormat = strings.TrimSpace(assertion.Subject.NameID.Format)
+	}
+	if issuer == "" || subject == "" {
+		return nil, ErrSAMLAssertionInvalid.WithMetadata(map[string]string{"detail": "missing issuer or subject"})
+	}
+
+	attrs := samlAssertionAttributes(assertion)
+	identity := &SAMLIdentity{
+		Issuer:      issuer,
+		Subject:     subject,
+		Email:       strings.ToLower(samlFirstAttribute(attrs, cfg.EmailAttribute, samlDefaultEmailAttributes)),
+		Username:    samlFirstAttribute(attrs, cfg.UsernameAttribute, samlDefaultUsernameAttributes),
+		DisplayName: samlFirstAttribute(attrs, cfg.DisplayNameAttribute, samlDefaultDisplayNameAttributes),
+		Groups:      samlAllAttributes(attrs, cfg.GroupsAttribute, samlDefaultGroupsAttributes),
+	}
+	if identity.Email == "" && nameIDFormat == string(saml.EmailAddressNameIDFormat) && strings.Contains(subject, "@") {
+		identity.Email = strings.ToLower(subject)
+	}
+	identity.Role, identity.GroupIDs = mapSAMLGroups(cfg, identity.Groups)
+	return identity, nil
+}
+
+// ConsumeAssertion 登记一条已校验的断言，断言 ID 在其剩余有效期内再次出现时返回
+// ErrSAMLAssertionReplayed。缓存不可用时拒绝登录，不放行无法判定的断言。
+func (s *SAMLService) ConsumeAssertion(ctx context.Context, assertion *saml.Assertion) error {
+	if s == nil || assertion == nil {
+		return ErrSAMLAssertionInvalid
+	}
+	assertionID := strings.TrimSpace(assertion.ID)
+	if assertionID == "" {
+		return ErrSAMLAssertionInvalid.WithMetadata(map[string]string{"detail": "missing assertion id"})
+	}
+	ok, err := s.replayCache.MarkSAMLAssertionUsed(ctx, assertionID, samlAssertionReplayTTL(assertion, time.Now()))
+	if err != nil {
+		return ErrSAMLReplayCheckFailed.WithCause(err)
+	}
+	if !ok {
+		return ErrSAMLAssertionReplayed
+	}
+	return nil
+}
+
+// samlAssertionReplayTTL 返回断言剩余的可接受时长：取 Conditions 与 SubjectConfirmationData
+// 中最晚的 NotOnOrAfter，并加上时钟偏差容忍；都缺失时按 IssueInstant + MaxIssueDelay 计算。
+func samlAssertionReplayTTL(assertion *saml.Assertion, now time.Time) time.Duration {
+	var expiresAt time.Time
+	if assertion.Conditions != nil {
+		expiresAt = assertion.Conditions.NotOnOrAfter
+	}
+	if assertion.Subject != nil {
+		for _, confirmation := range assertion.Subject.SubjectConfirmations {
+			data := confirmation.SubjectConfirmationData
+			if data != nil && data.NotOnOrAfter.After(expiresAt) {
+				expiresAt = data.NotOnOrAfter
+			}
+		}
+	}
+	if expiresAt.IsZero() {
+		expiresAt = assertion.IssueInstant.Add(saml.MaxIssueDelay)
+	}
+	ttl := expiresAt.Add(saml.MaxClockSkew).Sub(now)
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// localSAMLAssertionReplayCache 进程内的断言 ID 登记表
+type localSAMLAssertionReplayCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newLocalSAMLAssertionReplayCache() *localSAMLAssertionReplayCache {
+	return &localSAMLAssertionReplayCache{seen: make(map[string]time.Time)}
+}
+
+func (c *localSAMLAssertionReplayCache) MarkSAMLAssertionUsed(_ context.Context, assertionID string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, expiresAt := range c.seen {
+		if !now.Before(expiresAt) {
+			delete(c.seen, id)
+		}
+	}
+	if _, exists := c.seen[assertionID]; exists {
+		return false, nil
+	}
+	c.seen[assertionID] = now.Add(ttl)
+	return true, nil
+}
+
+// DirectoryClaims 返回写入 pending session 的目录映射 claims。
+func (i *SAMLIdentity) DirectoryClaims() map[string]any {
+	if i == nil {
+		return map[string]any{}
+	}
+	groupIDs := make([]any, 0, len(i.GroupIDs))
+	for _, id := range i.GroupIDs {
+		groupIDs = append(groupIDs, id)
+	}
+	groups := make([]any, 0, len(i.Groups))
+	for _, group := range i.Groups {
+		groups = append(groups, group)
+	}
+	return map[string]any{
+		SAMLClaimRole:     i.Role,
+		SAMLClaimGroupIDs: groupIDs,
+		SAMLClaimGroups:   groups,
+	}
+}
+
+func mapSAMLGroups(cfg config.SAMLConfig, groups []string) (string, []int64) {
+	member := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		member[strings.ToLower(strings.TrimSpace(group))] = struct{}{}
+	}
+
+	role := ""
+	if len(cfg.AdminGroups) > 0 {
+		role = RoleUser
+		for _, adminGroup := range cfg.AdminGroups {
+			if _, ok := member[strings.ToLower(strings.TrimSpace(adminGroup))]; ok {
+				role = RoleAdmin
+				break
+			}
+		}
+	}
+
+	seen := make(map[int64]struct{})
+	groupIDs := make([]int64, 0)
+	for _, mapping := range cfg.GroupMappings {
+		if _, ok := member[strings.ToLower(strings.TrimSpace(mapping.IdPGroup))]; !ok {
+			continue
+		}
+		for _, id := range mapping.GroupIDs {
+			if id <= 0 {
+				continue
+			}
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+			groupIDs = append(groupIDs, id)
+		}
+	}
+	sort.Slice(groupIDs, func(a, b int) bool { return groupIDs[a] < groupIDs[b] })
+	return role, groupIDs
+}
+
+// samlAssertionAttributes 以小写的 Name 和 FriendlyName 为键汇总断言中的全部属性值。
+func samlAssertionAttributes(assertion *saml.Assertion) map[string][]string {
+	attrs := make(map[string][]string)
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			values := make([]string, 0, len(attr.Values))
+			for _, v := range attr.Values {
+				value := strings.TrimSpace(v.Value)
+				if value == "" && v.NameID != nil {
+					value = strings.TrimSpace(v.NameID.Value)
+				}
+				if value != "" {
+					values = append(values, value)
+				}
+			}
+			for _, key := range []string{attr.Name, attr.FriendlyName} {
+				key = strings.ToLower(strings.TrimSpace(key))
+				if key == "" {
+					continue
+				}
+				attrs[key] = append(attrs[key], values...)
+			}
+		}
+	}
+	return attrs
+}
+
+func samlAttributeCandidates(configured string, defaults []string) []string {
+	if configured = strings.TrimSpace(configured); configured != "" {
+		return []string{configured}
+	}
+	return defaults
+}
+
+func samlFirstAttribute(attrs map[string][]string, configured string, defaults []string) string {
+	for _, name := range samlAttributeCandidates(configured, defaults) {
+		if values := attrs[strings.ToLower(name)]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func samlAllAttributes(attrs map[string][]string, configured string, defaults []string) []string {
+	for _, name := range samlAttributeCandidates(configured, defaults) {
+		if values := attrs[strings.ToLower(name)]; len(values) > 0 {
+			return append([]string(nil), values...)
+		}
+	}
+	return nil
+}
+
+func (s *SAMLService) baseServiceProvider() (*saml.ServiceProvider, error) {
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+	acsURL, err := url.Parse(cfg.ACSURL)
+	if err != nil || acsURL.Scheme == "" || acsURL.Host == "" {
+		return nil, infraerrors.InternalServer("SAML_CONFIG_INVALID", "saml acs url is invalid")
+	}
+	metadataURL, err := samlSPMetadataURL(cfg.ACSURL)
+	if err != nil {
+		return nil, infraerrors.InternalServer("SAML_CONFIG_INVALID", "saml acs url is invalid")
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID:          cfg.EntityID,
+		AcsURL:            *acsURL,
+		MetadataURL:       *metadataURL,
+		AllowIDPInitiated: cfg.AllowIdPInitiated,
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+		HTTPClient:        s.httpClient,
+	}
+	if strings.TrimSpace(cfg.SPCertificate) != "" {
+		key, cert, err := loadSAMLKeyPair(cfg.SPCertificate, cfg.SPPrivateKey)
+		if err != nil {
+			return nil, infraerrors.InternalServer("SAML_CONFIG_INVALID", "saml sp key pair is invalid").WithCause(err)
+		}
+		sp.Key = key
+		sp.Certificate = cert
+		if cfg.SignAuthnRequests {
+			switch key.(type) {
+			case *ecdsa.PrivateKey:
+				sp.SignatureMethod = dsig.ECDSASHA256SignatureMethod
+			default:
+				sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+			}
+		}
+	}
+	return sp, nil
+}
+
+// idpMetadata 按 导入 > 配置 XML > 配置 URL 的优先级加载 IdP 元数据；URL 结果按配置缓存。
+func (s *SAMLService) idpMetadata(ctx context.Context) (*saml.EntityDescriptor, string, error) {
+	cfg, err := s.Config()
+	if err != nil {
+		return nil, "", err
+	}
+	if s.settingRepo != nil {
+		imported, err := s.settingRepo.GetValue(ctx, SettingKeySAMLIdPMetadataXML)
+		if err != nil && !errors.Is(err, ErrSettingNotFound) {
+			return nil, SAMLMetadataSourceImported, fmt.Errorf("load imported saml metadata: %w", err)
+		}
+		if strings.TrimSpace(imported) != "" {
+			idp, err := parseSAMLIdPMetadata([]byte(imported))
+			return idp, SAMLMetadataSourceImported, err
+		}
+	}
+	if strings.TrimSpace(cfg.IdPMetadataXML) != "" {
+		raw, err := readSAMLPEMOrFile(cfg.IdPMetadataXML)
+		if err != nil {
+			return nil, SAMLMetadataSourceConfigXML, ErrSAMLMetadataInvalid.WithCause(err)
+		}
+		idp, err := parseSAMLIdPMetadata(raw)
+		return idp, SAMLMetadataSourceConfigXML, err
+	}
+	if strings.TrimSpace(cfg.IdPMetadataURL) == "" {
+		return nil, "", ErrSAMLNotConfigured
+	}
+
+	ttl := time.Duration(cfg.MetadataCacheSeconds) * time.Second
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remoteMetadata != nil && ttl > 0 && time.Since(s.remoteFetchedAt) < ttl {
+		return s.remoteMetadata, SAMLMetadataSourceConfigURL, nil
+	}
+	raw, err := s.fetchMetadataBytes(ctx, cfg.IdPMetadataURL)
+	if err != nil {
+		if s.remoteMetadata != nil {
+			// 拉取失败时沿用上一次成功的元数据，避免 IdP 元数据端点抖动导致无法登录
+			return s.remoteMetadata, SAMLMetadataSourceConfigURL, nil
+		}
+		return nil, SAMLMetadataSourceConfigURL, err
+	}
+	idp, err := parseSAMLIdPMetadata(raw)
+	if err != nil {
+		return nil, SAMLMetadataSourceConfigURL, err
+	}
+	s.remoteMetadata = idp
+	s.remoteFetchedAt = time.Now()
+	return idp, SAMLMetadataSourceConfigURL, nil
+}
+
+func (s *SAMLService) importedAt(ctx context.Context) *time.Time {
+	if s.settingRepo == nil {
+		return nil
+	}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeySAMLIdPMetadataImportedAt)
+	if err != nil || strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func (s *SAMLService) fetchMetadataBytes(ctx context.Context, metadataURL string) ([]byte, error) {
+	if err := config.ValidateAbsoluteHTTPURL(metadataURL); err != nil {
+		return nil, ErrSAMLMetadataInvalid.WithMetadata(map[string]string{"detail": "metadata_url is invalid"})
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, ErrSAMLMetadataInvalid.WithCause(err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, infraerrors.ServiceUnavailable("SAML_METADATA_FETCH_FAILED", "failed to fetch saml metadata").WithCause(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, infraerrors.ServiceUnavailable("SAML_METADATA_FETCH_FAILED", fmt.Sprintf("failed to fetch saml metadata: status %d", resp.StatusCode))
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, samlMetadataMaxBytes+1))
+	if err != nil {
+		return nil, infraerrors.ServiceUnavailable("SAML_METADATA_FETCH_FAILED", "failed to read saml metadata").WithCause(err)
+	}
+	if len(raw) > samlMetadataMaxBytes {
+		return nil, ErrSAMLMetadataInvalid.WithMetadata(map[string]string{"detail": "metadata is too large"})
+	}
+	return raw, nil
+}
+
+// parseSAMLIdPMetadata 解析 IdP 元数据，并要求至少包含一个 HTTP-Redirect SSO 端点和签名证书。
+func parseSAMLIdPMetadata(raw []byte) (*saml.EntityDescriptor, error) {
+	if len(raw) > samlMetadataMaxBytes {
+		return nil, ErrSAMLMetadataInvalid.WithMetadata(map[string]string{"detail": "metadata is too large"})
+	}
+	idp, err := samlsp.ParseMetadata(raw)
+	if err != nil {
+		return nil, ErrSAMLMetadataInvalid.WithCause(err)
+	}
+	if len(idp.IDPSSODescriptors) == 0 {
+		return nil, ErrSAMLMetadataInvalid.WithMetadata(map[string]string{"detail": "metadata has no IDPSSODescriptor"})
+	}
+	summary := summarizeSAMLIdPMetadata(idp)
+	if summary.SSOURL == "" {
+		return nil, ErrSAMLMetadataInvalid.WithMetadata(map[string]string{"detail": "metadata has no HTTP-Redirect SingleSignOnService"})
+	}
+	if len(summary.CertificateFingerprints) == 0 {
+		return nil, ErrSAMLMetadataInvalid.WithMetadata(map[string]string{"detail": "metadata has no signing certificate"})
+	}
+	return idp, nil
+}
+
+func summarizeSAMLIdPMetadata(idp *saml.EntityDescriptor) *SAMLIdPMetadataSummary {
+	summary := &SAMLIdPMetadataSummary{CertificateFingerprints: []string{}}
+	if idp == nil {
+		return summary
+	}
+	summary.EntityID = idp.EntityID
+	if !idp.ValidUntil.IsZero() {
+		validUntil := idp.ValidUntil
+		summary.ValidUntil = &validUntil
+	}
+	seen := make(map[string]struct{})
+	for _, descriptor := range idp.IDPSSODescriptors {
+		for _, sso := range descriptor.SingleSignOnServices {
+			if summary.SSOURL == "" && sso.Binding == saml.HTTPRedirectBinding {
+				summary.SSOURL = sso.Location
+			}
+		}
+		for _, key := range descriptor.KeyDescriptors {
+			if key.Use != "" && key.Use != "signing" {
+				continue
+			}
+			for _, cert := range key.KeyInfo.X509Data.X509Certificates {
+				der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(cert.Data), ""))
+				if err != nil || len(der) == 0 {
+					continue
+				}
+				sum := sha256.Sum256(der)
+				fingerprint := strings.ToUpper(hex.EncodeToString(sum[:]))
+				if _, ok := seen[fingerprint]; ok {
+					continue
+				}
+				seen[fingerprint] = struct{}{}
+				summary.CertificateFingerprints = append(summary.CertificateFingerprints, fingerprint)
+			}
+		}
+	}
+	return summary
+}
+
+// samlSPMetadataURL 由 ACS 地址推导 SP 元数据地址（同目录下的 /metadata）。
+func samlSPMetadataURL(acsURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(acsURL))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("acs url must be absolute")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		path = path[:idx]
+	}
+	u.Path = path + "/metadata"
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u, nil
+}
+
+// readSAMLPEMOrFile 允许配置直接写内容，也允许写文件路径。
+func readSAMLPEMOrFile(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "-----BEGIN") || strings.HasPrefix(value, "<") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+func loadSAMLKeyPair(certValue, keyValue string) (crypto.Signer, *x509.Certificate, error) {
+	certPEM, err := readSAMLPEMOrFile(certValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sp certificate: %w", err)
+	}
+	keyPEM, err := readSAMLPEMOrFile(keyValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read sp private key: %w", err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse sp key pair: %w", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse sp certificate: %w", err)
+	}
+	switch key := pair.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		return key, cert, nil
+	case *ecdsa.PrivateKey:
+		return key, cert, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported sp private key type %T", pair.PrivateKey)
+	}
+}
@@ -0,0 +1,225 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/xml"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSAMLIdPMetadata 用本地生成的自签名证书构造 IdP 元数据，返回元数据 XML 与证书。
+func newTestSAMLIdPMetadata(t *testing.T, host string) (string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	metadataURL, _ := url.Parse("https://" + host + "/metadata")
+	ssoURL, _ := url.Parse("https://" + host + "/sso")
+	idp := &saml.IdentityProvider{Key: key, Certificate: cert, MetadataURL: *metadataURL, SSOURL: *ssoURL}
+	raw, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	return string(raw), cert
+}
+
+func newTestSAMLService(settingRepo SettingRepository, samlCfg config.SAMLConfig) *SAMLService {
+	samlCfg.Enabled = true
+	if samlCfg.ACSURL == "" {
+		samlCfg.ACSURL = "https://sub2api.example.test/api/v1/auth/oauth/saml/acs"
+	}
+	return NewSAMLService(&config.Config{SAML: samlCfg}, settingRepo, nil)
+}
+
+func TestSAMLServiceImportIdPMetadataOverridesConfig(t *testing.T) {
+	configured, _ := newTestSAMLIdPMetadata(t, "idp-config.example.test")
+	imported, importedCert := newTestSAMLIdPMetadata(t, "idp-imported.example.test")
+	repo := newMockSettingRepo()
+	svc := newTestSAMLService(repo, config.SAMLConfig{IdPMetadataXML: configured})
+	ctx := context.Background()
+
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, SAMLMetadataSourceConfigXML, status.MetadataSource)
+	require.Equal(t, "https://idp-config.example.test/metadata", status.IdP.EntityID)
+	require.Equal(t, "https://sub2api.example.test/api/v1/auth/oauth/saml/metadata", status.MetadataURL)
+	require.Equal(t, status.MetadataURL, status.EntityID)
+
+	summary, err := svc.ImportIdPMetadata(ctx, imported, "")
+	require.NoError(t, err)
+	fingerprint := sha256.Sum256(importedCert.Raw)
+	require.Equal(t, "https://idp-imported.example.test/metadata", summary.EntityID)
+	require.Equal(t, "https://idp-imported.example.test/sso", summary.SSOURL)
+	require.Equal(t, []string{strings.ToUpper(hex.EncodeToString(fingerprint[:]))}, summary.CertificateFingerprints)
+
+	status, err = svc.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, SAMLMetadataSourceImported, status.MetadataSource)
+	require.NotNil(t, status.ImportedAt)
+	sp, err := svc.ServiceProvider(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "https://idp-imported.example.test/metadata", sp.IDPMetadata.EntityID)
+
+	require.NoError(t, svc.DeleteImportedIdPMetadata(ctx))
+	status, err = svc.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, SAMLMetadataSourceConfigXML, status.MetadataSource)
+	require.Nil(t, status.ImportedAt)
+}
+
+func TestSAMLServiceImportIdPMetadataRejectsInvalidMetadata(t *testing.T) {
+	repo := newMockSettingRepo()
+	svc := newTestSAMLService(repo, config.SAMLConfig{})
+	ctx := context.Background()
+
+	_, err := svc.ImportIdPMetadata(ctx, "", "")
+	require.Equal(t, "SAML_METADATA_REQUIRED", infraerrors.Reason(err))
+
+	_, err = svc.ImportIdPMetadata(ctx, "<not-metadata/>", "")
+	require.Equal(t, "SAML_METADATA_INVALID", infraerrors.Reason(err))
+
+	unsigned := `<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.test">` +
+		`<IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">` +
+		`<SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.test/sso"/>` +
+		`</IDPSSODescriptor></EntityDescriptor>`
+	_, err = svc.ImportIdPMetadata(ctx, unsigned, "")
+	require.Equal(t, "SAML_METADATA_INVALID", infraerrors.Reason(err))
+	require.Empty(t, repo.data[SettingKeySAMLIdPMetadataXML])
+
+	status, err := svc.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, infraerrors.Message(ErrSAMLNotConfigured), status.MetadataError)
+}
+
+func TestSAMLServiceDisabled(t *testing.T) {
+	svc := NewSAMLService(&config.Config{}, newMockSettingRepo(), nil)
+
+	_, err := svc.ServiceProvider(context.Background())
+	require.ErrorIs(t, err, ErrSAMLDisabled)
+	_, err = svc.SPMetadata()
+	require.ErrorIs(t, err, ErrSAMLDisabled)
+}
+
+func TestSAMLServiceResolveIdentityMapsAttributes(t *testing.T) {
+	svc := newTestSAMLService(nil, config.SAMLConfig{
+		AdminGroups: []string{"Platform-Admins"},
+		GroupMappings: []config.SAMLGroupMapping{
+			{IdPGroup: "engineering", GroupIDs: []int64{5, 2}},
+			{IdPGroup: "platform-admins", GroupIDs: []int64{2, 9}},
+			{IdPGroup: "sales", GroupIDs: []int64{11}},
+		},
+	})
+	assertion := &saml.Assertion{
+		Issuer: saml.Issuer{Value: "https://idp.example.test/metadata"},
+		Subject: &saml.Subject{NameID: &saml.NameID{
+			Format: string(saml.EmailAddressNameIDFormat),
+			Value:  "Alice@Corp.Example.com",
+		}},
+		AttributeStatements: []saml.AttributeStatement{{Attributes: []saml.Attribute{
+			{Name: "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name", Values: []saml.AttributeValue{{Value: "alice"}}},
+			{Name: "urn:oid:2.16.840.1.113730.3.1.241", FriendlyName: "displayName", Values: []saml.AttributeValue{{Value: "Alice Liddell"}}},
+			{Name: "memberOf", Values: []saml.AttributeValue{{Value: "engineering"}, {Value: "platform-admins"}}},
+		}}},
+	}
+
+	identity, err := svc.ResolveIdentity(nil, assertion)
+	require.NoError(t, err)
+	require.Equal(t, "https://idp.example.test/metadata", identity.Issuer)
+	require.Equal(t, "Alice@Corp.Example.com", identity.Subject)
+	require.Equal(t, "alice@corp.example.com", identity.Email)
+	require.Equal(t, "alice", identity.Username)
+	require.Equal(t, "Alice Liddell", identity.DisplayName)
+	require.Equal(t, RoleAdmin, identity.Role)
+	require.Equal(t, []int64{2, 5, 9}, identity.GroupIDs)
+
+	role, groupIDs := SAMLDirectoryMappingFromClaims(identity.DirectoryClaims())
+	require.Equal(t, RoleAdmin, role)
+	require.Equal(t, []int64{2, 5, 9}, groupIDs)
+}
+
+func TestSAMLServiceResolveIdentityDemotesWhenAdminGroupsConfigured(t *testing.T) {
+	svc := newTestSAMLService(nil, config.SAMLConfig{AdminGroups: []string{"platform-admins"}})
+	assertion := &saml.Assertion{
+		Issuer:  saml.Issuer{Value: "https://idp.example.test/metadata"},
+		Subject: &saml.Subject{NameID: &saml.NameID{Value: "bob"}},
+		AttributeStatements: []saml.AttributeStatement{{Attributes: []saml.Attribute{
+			{Name: "groups", Values: []saml.AttributeValue{{Value: "engineering"}}},
+		}}},
+	}
+
+	identity, err := svc.ResolveIdentity(nil, assertion)
+	require.NoError(t, err)
+	require.Equal(t, RoleUser, identity.Role)
+	require.Empty(t, identity.Email)
+
+	// 未配置 admin_groups 时不改动本地角色
+	svc = newTestSAMLService(nil, config.SAMLConfig{})
+	identity, err = svc.ResolveIdentity(nil, assertion)
+	require.NoError(t, err)
+	require.Empty(t, identity.Role)
+}
+
+func TestSAMLDirectoryMappingFromClaimsAcceptsJSONRoundTrip(t *testing.T) {
+	role, groupIDs := SAMLDirectoryMappingFromClaims(map[string]any{
+		SAMLClaimRole:     "superuser",
+		SAMLClaimGroupIDs: []any{float64(4), "6", -1, "bad"},
+	})
+	require.Empty(t, role)
+	require.Equal(t, []int64{4, 6}, groupIDs)
+}
+
+func TestSAMLAssertionReplayTTLUsesLatestNotOnOrAfter(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	assertion := &saml.Assertion{
+		IssueInstant: now,
+		Conditions:   &saml.Conditions{NotOnOrAfter: now.Add(5 * time.Minute)},
+		Subject: &saml.Subject{SubjectConfirmations: []saml.SubjectConfirmation{
+			{SubjectConfirmationData: &saml.SubjectConfirmationData{NotOnOrAfter: now.Add(10 * time.Minute)}},
+		}},
+	}
+	require.Equal(t, 10*time.Minute+saml.MaxClockSkew, samlAssertionReplayTTL(assertion, now))
+
+	// 没有 NotOnOrAfter 时按签发时间 + MaxIssueDelay 计算
+	bare := &saml.Assertion{IssueInstant: now}
+	require.Equal(t, saml.MaxIssueDelay+saml.MaxClockSkew, samlAssertionReplayTTL(bare, now))
+
+	// 已过期的断言仍登记一个最短 TTL
+	require.Equal(t, time.Second, samlAssertionReplayTTL(assertion, now.Add(time.Hour)))
+}
+
+func TestSAMLServiceConsumeAssertionRejectsReplay(t *testing.T) {
+	svc := newTestSAMLService(nil, config.SAMLConfig{})
+	assertion := &saml.Assertion{ID: "_assertion-1", IssueInstant: time.Now()}
+
+	require.NoError(t, svc.ConsumeAssertion(context.Background(), assertion))
+	err := svc.ConsumeAssertion(context.Background(), assertion)
+	require.ErrorIs(t, err, ErrSAMLAssertionReplayed)
+
+	require.NoError(t, svc.ConsumeAssertion(context.Background(), &saml.Assertion{ID: "_assertion-2", IssueInstant: time.Now()}))
+	require.Error(t, svc.ConsumeAssertion(context.Background(), &saml.Assertion{}))
+}
@@ -0,0 +1,688 @@
+package service
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
+)
+
+// 调度离线模拟：按 usage_log 的到达时间在虚拟时钟上重放流量，走与网关相同的
+// 调度策略（AccountScheduler）、负载率计算、粘性会话与兜底排队语义，并模拟上游延迟与 429，
+// 对比不同账号配置（优先级、并发、负载因子、倍率、调度策略）下的负载、排队、粘性命中与成本。
+//
+// 与线上的差异：不访问 Redis/数据库，不模拟 RPM/窗口费用/会话数等账号级软限制，
+// 粘性会话键取 usage_log.session_id（客户端显式会话），无会话 ID 的请求不参与粘性。
+
+const (
+	defaultSchedulerSimulationRateLimitCooldown = time.Minute
+	defaultSchedulerSimulationMaxFailover       = 3
+	// defaultSchedulerSimulationDuration usage_log 缺少 duration_ms 时的请求耗时。
+	defaultSchedulerSimulationDuration = time.Second
+)
+
+var ErrSchedulerSimulationNoRequests = errors.New("scheduler simulation input contains no requests")
+
+// SchedulerSimulationUpstream 账号的模拟上游行为。
+type SchedulerSimulationUpstream struct {
+	// LatencyScale 上游耗时相对 usage_log 记录（duration_ms / first_token_ms）的倍数，0 视为 1。
+	LatencyScale float64 `json:"latency_scale,omitempty"`
+	// RPMLimit 上游每分钟请求上限（滑动 60 秒窗口），超出即返回 429；0 表示不限。
+	RPMLimit int `json:"rpm_limit,omitempty"`
+	// RateLimitProbability 每次请求随机返回 429 的概率（0-1）。
+	RateLimitProbability float64 `json:"rate_limit_probability,omitempty"`
+	// RateLimitCooldownSeconds 429 后账号的限流时长，0 时为 60 秒。
+	RateLimitCooldownSeconds int `json:"rate_limit_cooldown_seconds,omitempty"`
+}
+
+// SchedulerSimulationAccount 参与模拟的账号及其所属分组。
+type SchedulerSimulationAccount struct {
+	Account  *Account
+	GroupIDs []int64
+	Upstream SchedulerSimulationUpstream
+}
+
+// SchedulerSimulationRequest 一条待重放的请求（来自 usage_log）。
+type SchedulerSimulationRequest struct {
+	At           time.Time
+	GroupID      int64
+	Model        string
+	SessionID    string
+	Duration     time.Duration
+	FirstTokenMs *int
+	// TotalCost 标准计费成本（未乘倍率），账号成本 = TotalCost × 账号计费倍率。
+	TotalCost float64
+}
+
+// SchedulerSimulationAccountOverride 候选配置对单个账号的覆盖，nil 字段保持原值。
+type SchedulerSimulationAccountOverride struct {
+	Priority       *int     `json:"priority,omitempty"`
+	Concurrency    *int     `json:"concurrency,omitempty"`
+	LoadFactor     *int     `json:"load_factor,omitempty"`
+	RateMultiplier *float64 `json:"rate_multiplier,omitempty"`
+	Schedulable    *bool    `json:"schedulable,omitempty"`
+}
+
+// SchedulerSimulationConfig 一组候选配置。
+type SchedulerSimulationConfig struct {
+	Name string `json:"name"`
+	// Strategy 覆盖所有分组的调度策略；空值沿用分组自身的 scheduling_strategy。
+	Strategy string                                       `json:"strategy,omitempty"`
+	Accounts map[int64]SchedulerSimulationAccountOverride `json:"accounts,omitempty"`
+}
+
+// SchedulerSimulationInput 模拟输入；Scheduling 零值字段按线上默认配置补齐。
+type SchedulerSimulationInput struct {
+	Groups     []*Group
+	Accounts   []SchedulerSimulationAccount
+	Requests   []SchedulerSimulationRequest
+	Configs    []SchedulerSimulationConfig
+	Scheduling config.GatewaySchedulingConfig
+	// MaxFailover 单个请求遇到 429 后最多换号次数，0 时为 3。
+	MaxFailover int
+	// Seed 随机 429 的种子；各候选配置使用同一种子，保证对比公平。
+	Seed int64
+}
+
+// SchedulerSimulationAccountReport 单个账号在某候选配置下的模拟结果。
+type SchedulerSimulationAccountReport struct {
+	AccountID        int64   `json:"account_id"`
+	Name             string  `json:"name"`
+	Priority         int     `json:"priority"`
+	Concurrency      int     `json:"concurrency"`
+	Requests         int     `json:"requests"`
+	PeakConcurrency  int     `json:"peak_concurrency"`
+	AvgConcurrency   float64 `json:"avg_concurrency"`
+	Utilization      float64 `json:"utilization"`
+	QueuedRequests   int     `json:"queued_requests"`
+	TotalQueueWaitMs int64   `json:"total_queue_wait_ms"`
+	MaxQueueWaitMs   int64   `json:"max_queue_wait_ms"`
+	RateLimitHits    int     `json:"rate_limit_hits"`
+	Cost             float64 `json:"cost"`
+}
+
+// SchedulerSimulationReport 某候选配置的模拟结果。
+type SchedulerSimulationReport struct {
+	Config   string `json:"config"`
+	Strategy string `json:"strategy"`
+
+	Requests      int `json:"requests"`
+	Served        int `json:"served"`
+	Rejected      int `json:"rejected"`
+	QueueTimeouts int `json:"queue_timeouts"`
+	Failed        int `json:"failed"`
+	Failovers     int `json:"failovers"`
+	RateLimitHits int `json:"rate_limit_hits"`
+
+	SessionRequests int     `json:"session_requests"`
+	StickyEligible  int     `json:"sticky_eligible"`
+	StickyHits      int     `json:"sticky_hits"`
+	StickyHitRate   float64 `json:"sticky_hit_rate"`
+
+	QueuedRequests int   `json:"queued_requests"`
+	QueueWaitP50Ms int64 `json:"queue_wait_p50_ms"`
+	QueueWaitP95Ms int64 `json:"queue_wait_p95_ms"`
+	QueueWaitMaxMs int64 `json:"queue_wait_max_ms"`
+
+	TotalCost float64                            `json:"total_cost"`
+	Accounts  []SchedulerSimulationAccountReport `json:"accounts"`
+}
+
+// SimulateScheduling 对每个候选配置独立重放全部请求，返回与 Configs 同序的报告；
+// Configs 为空时只模拟当前配置（名称 current）。
+func SimulateScheduling(input SchedulerSimulationInput) ([]SchedulerSimulationReport, error) {
+	if len(input.Requests) == 0 {
+		return nil, ErrSchedulerSimulationNoRequests
+	}
+	configs := input.Configs
+	if len(configs) == 0 {
+		configs = []SchedulerSimulationConfig{{Name: "current"}}
+	}
+	for _, cfg := range configs {
+		if cfg.Strategy == "" {
+			continue
+		}
+		if _, err := NormalizeAccountSchedulingStrategy(cfg.Strategy); err != nil {
+			return nil, fmt.Errorf("config %q: %w", cfg.Name, err)
+		}
+	}
+	requests := append([]SchedulerSimulationRequest(nil), input.Requests...)
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].At.Before(requests[j].At) })
+	input.Requests = requests
+	input.Scheduling = normalizeSchedulerSimulationScheduling(input.Scheduling)
+	if input.MaxFailover <= 0 {
+		input.MaxFailover = defaultSchedulerSimulationMaxFailover
+	}
+
+	reports := make([]SchedulerSimulationReport, 0, len(configs))
+	for _, cfg := range configs {
+		reports = append(reports, newSchedulerSimulation(input, cfg).run())
+	}
+	return reports, nil
+}
+
+func normalizeSchedulerSimulationScheduling(cfg config.GatewaySchedulingConfig) config.GatewaySchedulingConfig {
+	if cfg.StickySessionMaxWaiting <= 0 {
+		cfg.StickySessionMaxWaiting = 3
+	}
+	if cfg.StickySessionWaitTimeout <= 0 {
+		cfg.StickySessionWaitTimeout = 120 * time.Second
+	}
+	if cfg.FallbackMaxWaiting <= 0 {
+		cfg.FallbackMaxWaiting = 100
+	}
+	if cfg.FallbackWaitTimeout <= 0 {
+		cfg.FallbackWaitTimeout = 30 * time.Second
+	}
+	return cfg
+}
+
+type schedulerSimAccount struct {
+	account  *Account
+	groups   map[int64]struct{}
+	upstream SchedulerSimulationUpstream
+
+	inFlight   int
+	waiting    []*schedulerSimWaiter
+	recentReqs []time.Time
+
+	lastChange      time.Time
+	concurrencyArea float64 // ∑ inFlight × 持续毫秒数
+	report          SchedulerSimulationAccountReport
+}
+
+type schedulerSimWaiter struct {
+	req        int
+	attempt    int
+	enqueuedAt time.Time
+	deadline   time.Time
+}
+
+type schedulerSimStickyBinding struct {
+	accountID int64
+	expiresAt time.Time
+}
+
+type schedulerSimEvent struct {
+	at        time.Time
+	seq       int
+	req       int // >= 0 到达事件
+	accountID int64
+}
+
+type schedulerSimEventQueue []schedulerSimEvent
+
+func (q schedulerSimEventQueue) Len() int { return len(q) }
+func (q schedulerSimEventQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+func (q schedulerSimEventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *schedulerSimEventQueue) Push(x any)   { *q = append(*q, x.(schedulerSimEvent)) }
+func (q *schedulerSimEventQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+type schedulerSimulation struct {
+	input      SchedulerSimulationInput
+	config     SchedulerSimulationConfig
+	groups     map[int64]*Group
+	accounts   []*schedulerSimAccount
+	byID       map[int64]*schedulerSimAccount
+	schedulers *AccountSchedulers
+	sticky     map[string]schedulerSimStickyBinding
+	rng        *mathrand.Rand
+
+	events      schedulerSimEventQueue
+	seq         int
+	windowStart time.Time
+	now         time.Time
+
+	queueWaits []int64
+	report     SchedulerSimulationReport
+}
+
+func newSchedulerSimulation(input SchedulerSimulationInput, cfg SchedulerSimulationConfig) *schedulerSimulation {
+	sim := &schedulerSimulation{
+		input:       input,
+		config:      cfg,
+		groups:      make(map[int64]*Group, len(input.Groups)),
+		byID:        make(map[int64]*schedulerSimAccount, len(input.Accounts)),
+		schedulers:  NewAccountSchedulers(nil),
+		sticky:      make(map[string]schedulerSimStickyBinding),
+		rng:         mathrand.New(mathrand.NewSource(input.Seed)),
+		windowStart: input.Requests[0].At,
+	}
+	for _, g := range input.Groups {
+		if g != nil {
+			sim.groups[g.ID] = g
+		}
+	}
+	for _, item := range input.Accounts {
+		if item.Account == nil {
+			continue
+		}
+		account := *item.Account
+		account.LastUsedAt = nil
+		account.RateLimitResetAt = nil
+		account.OverloadUntil = nil
+		account.TempUnschedulableUntil = nil
+		if override, ok := cfg.Accounts[account.ID]; ok {
+			applySchedulerSimulationOverride(&account, override)
+		}
+		simAccount := &schedulerSimAccount{
+			account:    &account,
+			groups:     make(map[int64]struct{}, len(item.GroupIDs)),
+			upstream:   item.Upstream,
+			lastChange: sim.windowStart,
+			report: SchedulerSimulationAccountReport{
+				AccountID:   account.ID,
+				Name:        account.Name,
+				Priority:    account.Priority,
+				Concurrency: account.Concurrency,
+			},
+		}
+		for _, groupID := range item.GroupIDs {
+			simAccount.groups[groupID] = struct{}{}
+		}
+		sim.accounts = append(sim.accounts, simAccount)
+		sim.byID[account.ID] = simAccount
+	}
+	strategy := cfg.Strategy
+	if strategy == "" {
+		strategy = "group"
+	}
+	sim.report = SchedulerSimulationReport{Config: cfg.Name, Strategy: strategy}
+	return sim
+}
+
+func applySchedulerSimulationOverride(account *Account, override SchedulerSimulationAccountOverride) {
+	if override.Priority != nil {
+		account.Priority = *override.Priority
+	}
+	if override.Concurrency != nil {
+		account.Concurrency = *override.Concurrency
+	}
+	if override.LoadFactor != nil {
+		loadFactor := *override.LoadFactor
+		account.LoadFactor = &loadFactor
+	}
+	if override.RateMultiplier != nil {
+		rate := *override.RateMultiplier
+		account.RateMultiplier = &rate
+	}
+	if override.Schedulable != nil {
+		account.Schedulable = *override.Schedulable
+	}
+}
+
+func (sim *schedulerSimulation) push(ev schedulerSimEvent) {
+	sim.seq++
+	ev.seq = sim.seq
+	heap.Push(&sim.events, ev)
+}
+
+func (sim *schedulerSimulation) run() SchedulerSimulationReport {
+	for i, req := range sim.input.Requests {
+		sim.push(schedulerSimEvent{at: req.At, req: i})
+	}
+	for sim.events.Len() > 0 {
+		ev := heap.Pop(&sim.events).(schedulerSimEvent)
+		sim.now = ev.at
+		if ev.req >= 0 {
+			sim.arrive(ev.req)
+			continue
+		}
+		sim.complete(ev.accountID)
+	}
+	return sim.finish()
+}
+
+func (sim *schedulerSimulation) arrive(reqIdx int) {
+	req := sim.input.Requests[reqIdx]
+	sim.report.Requests++
+	if req.SessionID != "" {
+		sim.report.SessionRequests++
+	}
+	sim.dispatch(reqIdx, 0, nil, true)
+}
+
+// dispatch 对应网关一次选号：粘性会话 → 按策略负载感知选择 → 兜底排队。
+func (sim *schedulerSimulation) dispatch(reqIdx, attempt int, excluded map[int64]struct{}, firstAttempt bool) {
+	req := sim.input.Requests[reqIdx]
+	group := sim.groups[req.GroupID]
+	candidates := sim.candidates(req, group, excluded)
+	if len(candidates) == 0 {
+		if attempt > 0 {
+			sim.report.Failed++
+		} else {
+			sim.report.Rejected++
+		}
+		return
+	}
+	cfg := sim.input.Scheduling
+
+	// 粘性会话
+	stickyKey := ""
+	if req.SessionID != "" {
+		stickyKey = fmt.Sprintf("%d:%s", req.GroupID, req.SessionID)
+		if binding, ok := sim.sticky[stickyKey]; ok && sim.now.Before(binding.expiresAt) {
+			if firstAttempt {
+				sim.report.StickyEligible++
+			}
+			if bound := findSchedulerSimAccount(candidates, binding.accountID); bound != nil {
+				if bound.inFlight < bound.account.Concurrency {
+					if firstAttempt {
+						sim.report.StickyHits++
+					}
+					sim.start(reqIdx, attempt, bound, sim.now)
+					return
+				}
+				if len(bound.waiting) < cfg.StickySessionMaxWaiting {
+					if firstAttempt {
+						sim.report.StickyHits++
+					}
+					sim.enqueue(reqIdx, attempt, bound, cfg.StickySessionWaitTimeout)
+					return
+				}
+			}
+		}
+	}
+
+	// 负载感知选择，排序交给分组的调度策略
+	available := make([]AccountScheduleCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		loadInfo := c.loadInfo()
+		if loadInfo.LoadRate < 100 {
+			available = append(available, AccountScheduleCandidate{Account: c.account, LoadInfo: loadInfo})
+		}
+	}
+	ordered := sim.scheduler(group).Order(AccountScheduleRequest{
+		GroupID:            &req.GroupID,
+		Platform:           platformOfSchedulerSimGroup(group),
+		Model:              req.Model,
+		PreferSoonestReset: cfg.PreferSoonestReset,
+		Now:                sim.now,
+	}, available)
+	for _, item := range ordered {
+		simAccount := sim.byID[item.Account.ID]
+		if simAccount.inFlight < simAccount.account.Concurrency {
+			sim.start(reqIdx, attempt, simAccount, sim.now)
+			return
+		}
+	}
+
+	// 兜底排队
+	fallback := make([]*Account, 0, len(candidates))
+	for _, c := range candidates {
+		fallback = append(fallback, c.account)
+	}
+	sortAccountsByPriorityAndLastUsed(fallback, false)
+	for _, account := range fallback {
+		simAccount := sim.byID[account.ID]
+		if len(simAccount.waiting) < cfg.FallbackMaxWaiting {
+			sim.enqueue(reqIdx, attempt, simAccount, cfg.FallbackWaitTimeout)
+			return
+		}
+	}
+	if attempt > 0 {
+		sim.report.Failed++
+	} else {
+		sim.report.Rejected++
+	}
+}
+
+func (sim *schedulerSimulation) scheduler(group *Group) AccountScheduler {
+	if sim.config.Strategy != "" {
+		return sim.schedulers.ForStrategy(sim.config.Strategy)
+	}
+	return sim.schedulers.ForGroup(group)
+}
+
+func platformOfSchedulerSimGroup(group *Group) string {
+	if group == nil {
+		return ""
+	}
+	return group.Platform
+}
+
+func (sim *schedulerSimulation) candidates(req SchedulerSimulationRequest, group *Group, excluded map[int64]struct{}) []*schedulerSimAccount {
+	platform := platformOfSchedulerSimGroup(group)
+	out := make([]*schedulerSimAccount, 0, len(sim.accounts))
+	for _, c := range sim.accounts {
+		if _, ok := c.groups[req.GroupID]; !ok {
+			continue
+		}
+		if _, skip := excluded[c.account.ID]; skip {
+			continue
+		}
+		if platform != "" && platform != PlatformComposite && !strings.EqualFold(c.account.Platform, platform) {
+			continue
+		}
+		if !schedulerSimSchedulableAt(c.account, sim.now) {
+			continue
+		}
+		if req.Model != "" && !c.account.IsModelSupported(req.Model) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// schedulerSimSchedulableAt 同 Account.IsSchedulable，以虚拟时钟判断限流与过载窗口。
+func schedulerSimSchedulableAt(account *Account, now time.Time) bool {
+	if !account.IsActive() || !account.Schedulable || account.Concurrency <= 0 {
+		return false
+	}
+	for _, until := range []*time.Time{account.RateLimitResetAt, account.OverloadUntil, account.TempUnschedulableUntil} {
+		if until != nil && now.Before(*until) {
+			return false
+		}
+	}
+	return true
+}
+
+func findSchedulerSimAccount(candidates []*schedulerSimAccount, accountID int64) *schedulerSimAccount {
+	for _, c := range candidates {
+		if c.account.ID == accountID {
+			return c
+		}
+	}
+	return nil
+}
+
+// loadInfo 与并发缓存的负载率口径一致：(并发 + 排队) × 100 / 负载因子。
+func (c *schedulerSimAccount) loadInfo() *AccountLoadInfo {
+	loadRate := 0
+	if factor := c.account.EffectiveLoadFactor(); factor > 0 {
+		loadRate = (c.inFlight + len(c.waiting)) * 100 / factor
+	}
+	return &AccountLoadInfo{
+		AccountID:          c.account.ID,
+		CurrentConcurrency: c.inFlight,
+		WaitingCount:       len(c.waiting),
+		LoadRate:           loadRate,
+	}
+}
+
+func (c *schedulerSimAccount) setInFlight(now time.Time, inFlight int) {
+	c.concurrencyArea += float64(c.inFlight) * float64(now.Sub(c.lastChange).Milliseconds())
+	c.lastChange = now
+	c.inFlight = inFlight
+	if inFlight > c.report.PeakConcurrency {
+		c.report.PeakConcurrency = inFlight
+	}
+}
+
+func (sim *schedulerSimulation) enqueue(reqIdx, attempt int, c *schedulerSimAccount, timeout time.Duration) {
+	c.waiting = append(c.waiting, &schedulerSimWaiter{
+		req:        reqIdx,
+		attempt:    attempt,
+		enqueuedAt: sim.now,
+		deadline:   sim.now.Add(timeout),
+	})
+}
+
+// start 在账号上发起上游请求：模拟 429 时释放并换号重试，否则占用槽位直到请求完成。
+func (sim *schedulerSimulation) start(reqIdx, attempt int, c *schedulerSimAccount, enqueuedAt time.Time) {
+	req := sim.input.Requests[reqIdx]
+	now := sim.now
+	if enqueuedAt.Before(now) {
+		wait := now.Sub(enqueuedAt).Milliseconds()
+		sim.queueWaits = append(sim.queueWaits, wait)
+		c.report.QueuedRequests++
+		c.report.TotalQueueWaitMs += wait
+		if wait > c.report.MaxQueueWaitMs {
+			c.report.MaxQueueWaitMs = wait
+		}
+	}
+	lastUsed := now
+	c.account.LastUsedAt = &lastUsed
+
+	if sim.upstreamRateLimited(c, now) {
+		cooldown := defaultSchedulerSimulationRateLimitCooldown
+		if c.upstream.RateLimitCooldownSeconds > 0 {
+			cooldown = time.Duration(c.upstream.RateLimitCooldownSeconds) * time.Second
+		}
+		resetAt := now.Add(cooldown)
+		c.account.RateLimitResetAt = &resetAt
+		c.report.RateLimitHits++
+		sim.report.RateLimitHits++
+		sim.schedulers.ReportResult(c.account.ID, false, nil)
+		// 限流后账号不可调度，排队中的请求需重新选号
+		sim.redispatchWaiters(c)
+		if attempt >= sim.input.MaxFailover {
+			sim.report.Failed++
+			return
+		}
+		sim.report.Failovers++
+		sim.dispatch(reqIdx, attempt+1, map[int64]struct{}{c.account.ID: {}}, false)
+		return
+	}
+
+	c.recentReqs = append(c.recentReqs, now)
+	c.setInFlight(now, c.inFlight+1)
+	c.report.Requests++
+	cost := money.Mul(req.TotalCost, c.account.BillingRateMultiplier(), money.LedgerScale)
+	c.report.Cost = money.Sum(c.report.Cost, cost)
+	sim.report.TotalCost = money.Sum(sim.report.TotalCost, cost)
+	sim.report.Served++
+
+	scale := c.upstream.LatencyScale
+	if scale <= 0 {
+		scale = 1
+	}
+	duration := req.Duration
+	if duration <= 0 {
+		duration = defaultSchedulerSimulationDuration
+	}
+	duration = time.Duration(float64(duration) * scale)
+	var firstTokenMs *int
+	if req.FirstTokenMs != nil {
+		v := int(float64(*req.FirstTokenMs) * scale)
+		firstTokenMs = &v
+	}
+	sim.schedulers.ReportResult(c.account.ID, true, firstTokenMs)
+	if req.SessionID != "" {
+		sim.sticky[fmt.Sprintf("%d:%s", req.GroupID, req.SessionID)] = schedulerSimStickyBinding{
+			accountID: c.account.ID,
+			expiresAt: now.Add(stickySessionTTL),
+		}
+	}
+	sim.push(schedulerSimEvent{at: now.Add(duration), req: -1, accountID: c.account.ID})
+}
+
+func (sim *schedulerSimulation) upstreamRateLimited(c *schedulerSimAccount, now time.Time) bool {
+	if limit := c.upstream.RPMLimit; limit > 0 {
+		windowStart := now.Add(-time.Minute)
+		kept := c.recentReqs[:0]
+		for _, at := range c.recentReqs {
+			if at.After(windowStart) {
+				kept = append(kept, at)
+			}
+		}
+		c.recentReqs = kept
+		if len(kept) >= limit {
+			return true
+		}
+	}
+	return c.upstream.RateLimitProbability > 0 && sim.rng.Float64() < c.upstream.RateLimitProbability
+}
+
+func (sim *schedulerSimulation) complete(accountID int64) {
+	c := sim.byID[accountID]
+	c.setInFlight(sim.now, c.inFlight-1)
+	for len(c.waiting) > 0 && c.inFlight < c.account.Concurrency {
+		if !schedulerSimSchedulableAt(c.account, sim.now) {
+			sim.redispatchWaiters(c)
+			return
+		}
+		waiter := c.waiting[0]
+		c.waiting = c.waiting[1:]
+		if sim.now.After(waiter.deadline) {
+			sim.report.QueueTimeouts++
+			continue
+		}
+		sim.start(waiter.req, waiter.attempt, c, waiter.enqueuedAt)
+	}
+}
+
+// redispatchWaiters 账号被限流时，排队请求按线上行为（等待超时前账号不可用）换号重新选择。
+func (sim *schedulerSimulation) redispatchWaiters(c *schedulerSimAccount) {
+	waiting := c.waiting
+	c.waiting = nil
+	for _, waiter := range waiting {
+		if sim.now.After(waiter.deadline) {
+			sim.report.QueueTimeouts++
+			continue
+		}
+		sim.dispatch(waiter.req, waiter.attempt, map[int64]struct{}{c.account.ID: {}}, false)
+	}
+}
+
+func (sim *schedulerSimulation) finish() SchedulerSimulationReport {
+	end := sim.now
+	window := end.Sub(sim.windowStart).Milliseconds()
+	for _, c := range sim.accounts {
+		c.setInFlight(end, c.inFlight)
+		sim.report.QueueTimeouts += len(c.waiting)
+		if window > 0 {
+			c.report.AvgConcurrency = c.concurrencyArea / float64(window)
+			if c.account.Concurrency > 0 {
+				c.report.Utilization = c.report.AvgConcurrency / float64(c.account.Concurrency)
+			}
+		}
+		sim.report.Accounts = append(sim.report.Accounts, c.report)
+	}
+	sort.Slice(sim.report.Accounts, func(i, j int) bool {
+		return sim.report.Accounts[i].AccountID < sim.report.Accounts[j].AccountID
+	})
+	if sim.report.StickyEligible > 0 {
+		sim.report.StickyHitRate = float64(sim.report.StickyHits) / float64(sim.report.StickyEligible)
+	}
+	sim.report.QueuedRequests = len(sim.queueWaits)
+	if len(sim.queueWaits) > 0 {
+		sort.Slice(sim.queueWaits, func(i, j int) bool { return sim.queueWaits[i] < sim.queueWaits[j] })
+		sim.report.QueueWaitP50Ms = schedulerSimPercentile(sim.queueWaits, 0.50)
+		sim.report.QueueWaitP95Ms = schedulerSimPercentile(sim.queueWaits, 0.95)
+		sim.report.QueueWaitMaxMs = sim.queueWaits[len(sim.queueWaits)-1]
+	}
+	return sim.report
+}
+
+func schedulerSimPercentile(sorted []int64, p float64) int64 {
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
@@ -0,0 +1,149 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func simAccount(id int64, priority, concurrency int) SchedulerSimulationAccount {
+	return SchedulerSimulationAccount{
+		Account: &Account{
+			ID:          id,
+			Name:        "acc",
+			Platform:    PlatformAnthropic,
+			Type:        AccountTypeAPIKey,
+			Status:      StatusActive,
+			Schedulable: true,
+			Priority:    priority,
+			Concurrency: concurrency,
+		},
+		GroupIDs: []int64{1},
+	}
+}
+
+func simRequests(base time.Time, offsets ...time.Duration) []SchedulerSimulationRequest {
+	out := make([]SchedulerSimulationRequest, 0, len(offsets))
+	for _, offset := range offsets {
+		out = append(out, SchedulerSimulationRequest{At: base.Add(offset), GroupID: 1, Duration: time.Second, TotalCost: 1})
+	}
+	return out
+}
+
+func simGroups() []*Group {
+	return []*Group{{ID: 1, Platform: PlatformAnthropic, Status: StatusActive}}
+}
+
+func TestSimulateScheduling_QueuesWhenConcurrencyExhausted(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	reports, err := SimulateScheduling(SchedulerSimulationInput{
+		Groups:   simGroups(),
+		Accounts: []SchedulerSimulationAccount{simAccount(10, 1, 1)},
+		Requests: simRequests(base, 0, 0),
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	report := reports[0]
+	require.Equal(t, "current", report.Config)
+	require.Equal(t, 2, report.Served)
+	require.Equal(t, 1, report.QueuedRequests)
+	require.Equal(t, int64(1000), report.QueueWaitMaxMs)
+	require.Equal(t, 1, report.Accounts[0].PeakConcurrency)
+	require.InDelta(t, 1.0, report.Accounts[0].AvgConcurrency, 0.001)
+}
+
+func TestSimulateScheduling_StickySessionHits(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	requests := simRequests(base, 0, 10*time.Second, 20*time.Second)
+	for i := range requests {
+		requests[i].SessionID = "conv-1"
+	}
+	reports, err := SimulateScheduling(SchedulerSimulationInput{
+		Groups:   simGroups(),
+		Accounts: []SchedulerSimulationAccount{simAccount(10, 1, 5), simAccount(11, 1, 5)},
+		Requests: requests,
+	})
+	require.NoError(t, err)
+	report := reports[0]
+	require.Equal(t, 3, report.SessionRequests)
+	require.Equal(t, 2, report.StickyEligible)
+	require.Equal(t, 2, report.StickyHits)
+	require.Equal(t, 1.0, report.StickyHitRate)
+	require.ElementsMatch(t, []int{0, 3}, []int{report.Accounts[0].Requests, report.Accounts[1].Requests})
+}
+
+func TestSimulateScheduling_UpstreamRPMLimitFailsOver(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	primary := simAccount(10, 0, 5)
+	primary.Upstream = SchedulerSimulationUpstream{RPMLimit: 1, RateLimitCooldownSeconds: 30}
+	backup := simAccount(11, 1, 5)
+
+	reports, err := SimulateScheduling(SchedulerSimulationInput{
+		Groups:   simGroups(),
+		Accounts: []SchedulerSimulationAccount{primary, backup},
+		Requests: simRequests(base, 0, 2*time.Second, 10*time.Second),
+	})
+	require.NoError(t, err)
+	report := reports[0]
+	require.Equal(t, 3, report.Served)
+	require.Equal(t, 1, report.RateLimitHits)
+	require.Equal(t, 1, report.Failovers)
+	require.Equal(t, 1, report.Accounts[0].Requests)
+	require.Equal(t, 1, report.Accounts[0].RateLimitHits)
+	// 冷却期内主账号不可调度，第三个请求直接落到备用账号
+	require.Equal(t, 2, report.Accounts[1].Requests)
+}
+
+func TestSimulateScheduling_ComparesCandidateConfigs(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	cheap := simAccount(10, 1, 5)
+	cheap.Account.RateMultiplier = floatPtr(0.5)
+	expensive := simAccount(11, 0, 5)
+	expensive.Account.RateMultiplier = floatPtr(2)
+	demote := 5
+
+	reports, err := SimulateScheduling(SchedulerSimulationInput{
+		Groups:   simGroups(),
+		Accounts: []SchedulerSimulationAccount{cheap, expensive},
+		Requests: simRequests(base, 0, 5*time.Second),
+		Configs: []SchedulerSimulationConfig{
+			{Name: "current"},
+			{Name: "demote-expensive", Accounts: map[int64]SchedulerSimulationAccountOverride{11: {Priority: &demote}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	require.Equal(t, 4.0, reports[0].TotalCost)
+	require.Equal(t, 2, reports[0].Accounts[1].Requests)
+	require.Equal(t, 1.0, reports[1].TotalCost)
+	require.Equal(t, 2, reports[1].Accounts[0].Requests)
+	require.Equal(t, 5, reports[1].Accounts[1].Priority)
+}
+
+func TestSimulateScheduling_RejectsWhenNoCandidates(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	off := simAccount(10, 1, 5)
+	off.Account.Schedulable = false
+	reports, err := SimulateScheduling(SchedulerSimulationInput{
+		Groups:   simGroups(),
+		Accounts: []SchedulerSimulationAccount{off},
+		Requests: simRequests(base, 0),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, reports[0].Rejected)
+	require.Zero(t, reports[0].Served)
+}
+
+func TestSimulateScheduling_ValidatesInput(t *testing.T) {
+	_, err := SimulateScheduling(SchedulerSimulationInput{})
+	require.ErrorIs(t, err, ErrSchedulerSimulationNoRequests)
+
+	_, err = SimulateScheduling(SchedulerSimulationInput{
+		Requests: simRequests(time.Now(), 0),
+		Configs:  []SchedulerSimulationConfig{{Name: "bad", Strategy: "random"}},
+	})
+	require.ErrorContains(t, err, `config "bad"`)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/metrics"
 )
 
 var (
@@ -983,6 +984,7 @@ func (s *SchedulerSnapshotService) rebuildBucketWithTokenPolicyAndQueryCache(
 		return err
 	}
 	slog.Debug("[Scheduler] rebuild ok", "bucket", bucket.String(), "reason", reason, "size", len(accounts))
+	metrics.SetSchedulerBucketSize(bucket.Platform, bucket.GroupID, bucket.Mode, len(accounts))
 	return nil
 }
 
@@ -1286,14 +1288,14 @@ func (s *SchedulerSnapshotService) checkOutboxLag(ctx context.Context, watermark
 	}
 	lagSeconds := int(lag.Seconds())
 	lagWarning := ok && !oldestCreatedAt.IsZero() &&
-		s.cfg.Gateway.Scheduling.OutboxLagWarnSeconds > 0 &&
-		lagSeconds >= s.cfg.Gateway.Scheduling.OutboxLagWarnSeconds
+		s.cfg.Live().Gateway.Scheduling.OutboxLagWarnSeconds > 0 &&
+		lagSeconds >= s.cfg.Live().Gateway.Scheduling.OutboxLagWarnSeconds
 
 	lagDegraded := ok && !oldestCreatedAt.IsZero() &&
-		s.cfg.Gateway.Scheduling.OutboxLagRebuildSeconds > 0 &&
-		lagSeconds >= s.cfg.Gateway.Scheduling.OutboxLagRebuildSeconds
+		s.cfg.Live().Gateway.Scheduling.OutboxLagRebuildSeconds > 0 &&
+		lagSeconds >= s.cfg.Live().Gateway.Scheduling.OutboxLagRebuildSeconds
 
-	backlogThreshold := s.cfg.Gateway.Scheduling.OutboxBacklogRebuildRows
+	backlogThreshold := s.cfg.Live().Gateway.Scheduling.OutboxBacklogRebuildRows
 	backlogKnown := true
 	var backlog int64
 	if backlogThreshold > 0 {
@@ -1342,7 +1344,7 @@ func (s *SchedulerSnapshotService) checkOutboxLag(ctx context.Context, watermark
 		s.lagFailures = 0
 	}
 	failures := s.lagFailures
-	lagReady := lagDegraded && failures >= s.cfg.Gateway.Scheduling.OutboxLagRebuildFailures
+	lagReady := lagDegraded && failures >= s.cfg.Live().Gateway.Scheduling.OutboxLagRebuildFailures
 	retryDue := s.outboxRebuildRetryReason != "" &&
 		!s.outboxRebuildRetryAt.IsZero() && !now.Before(s.outboxRebuildRetryAt)
 
@@ -1574,7 +1576,7 @@ func (s *SchedulerSnapshotService) resolveMode(platform string, hasForcePlatform
 }
 
 func (s *SchedulerSnapshotService) guardFallback(ctx context.Context) error {
-	if s.cfg == nil || s.cfg.Gateway.Scheduling.DbFallbackEnabled {
+	if s.cfg == nil || s.cfg.Live().Gateway.Scheduling.DbFallbackEnabled {
 		if s.fallbackLimit == nil || s.fallbackLimit.Allow() {
 			return nil
 		}
@@ -1584,10 +1586,10 @@ func (s *SchedulerSnapshotService) guardFallback(ctx context.Context) error {
 }
 
 func (s *SchedulerSnapshotService) withFallbackTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
-	if s.cfg == nil || s.cfg.Gateway.Scheduling.DbFallbackTimeoutSeconds <= 0 {
+	if s.cfg == nil || s.cfg.Live().Gateway.Scheduling.DbFallbackTimeoutSeconds <= 0 {
 		return context.WithCancel(ctx)
 	}
-	timeout := time.Duration(s.cfg.Gateway.Scheduling.DbFallbackTimeoutSeconds) * time.Second
+	timeout := time.Duration(s.cfg.Live().Gateway.Scheduling.DbFallbackTimeoutSeconds) * time.Second
 	if deadline, ok := ctx.Deadline(); ok {
 		remaining := time.Until(deadline)
 		if remaining <= 0 {
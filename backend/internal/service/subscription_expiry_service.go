@@ -30,6 +30,7 @@ type SubscriptionExpiryService struct {
 	userSubRepo              UserSubscriptionRepository
 	settingRepo              SettingRepository
 	notificationEmailService *NotificationEmailService
+	userWebhookService       *UserWebhookService
 	interval                 time.Duration
 	stopCh                   chan struct{}
 	stopOnce                 sync.Once
@@ -71,6 +72,12 @@ func (s *SubscriptionExpiryService) SetNotificationEmailService(notificationEmai
 	s.notificationEmailService = notificationEmailService
 }
 
+// SetUserWebhookService 注入用户 Webhook 发布（subscription.expiring），
+// 与邮件提醒相互独立：未配置 SMTP 时仍会投递 Webhook。
+func (s *SubscriptionExpiryService) SetUserWebhookService(userWebhookService *UserWebhookService) {
+	s.userWebhookService = userWebhookService
+}
+
 func (s *SubscriptionExpiryService) Start() {
 	if s == nil || s.userSubRepo == nil || s.interval <= 0 {
 		return
@@ -119,13 +126,12 @@ func (s *SubscriptionExpiryService) runOnce() {
 }
 
 func (s *SubscriptionExpiryService) sendExpiryReminders(ctx context.Context) {
-	if s == nil || s.userSubRepo == nil || s.notificationEmailService == nil {
+	if s == nil || s.userSubRepo == nil {
 		return
 	}
-	if !s.expiryReminderEnabled(ctx) {
-		return
-	}
-	if !s.smtpConfigured(ctx) {
+	sendEmail := s.notificationEmailService != nil && s.expiryReminderEnabled(ctx) && s.smtpConfigured(ctx)
+	sendWebhook := s.userWebhookService.Enabled()
+	if !sendEmail && !sendWebhook {
 		return
 	}
 
@@ -143,7 +149,12 @@ func (s *SubscriptionExpiryService) sendExpiryReminders(ctx context.Context) {
 			return
 		}
 		for i := range subs {
-			s.sendExpiryReminderIfDue(ctx, &subs[i])
+			if sendWebhook {
+				s.publishExpiryWebhookIfDue(&subs[i])
+			}
+			if sendEmail {
+				s.sendExpiryReminderIfDue(ctx, &subs[i])
+			}
 		}
 		if pag == nil || page >= pag.Pages || len(subs) == 0 {
 			return
@@ -188,12 +199,42 @@ func (s *SubscriptionExpiryService) smtpConfigured(ctx context.Context) bool {
 	return false
 }
 
+// expiryReminderDue 提醒节点：到期前 7 / 3 / 1 天。
+func expiryReminderDue(daysRemaining int) bool {
+	return daysRemaining == 7 || daysRemaining == 3 || daysRemaining == 1
+}
+
+func (s *SubscriptionExpiryService) publishExpiryWebhookIfDue(sub *UserSubscription) {
+	if sub == nil {
+		return
+	}
+	daysRemaining := sub.DaysRemaining()
+	if !expiryReminderDue(daysRemaining) {
+		return
+	}
+	data := map[string]any{
+		"subscription_id": sub.ID,
+		"group_id":        sub.GroupID,
+		"expires_at":      sub.ExpiresAt.UTC().Format(time.RFC3339),
+		"days_remaining":  daysRemaining,
+	}
+	if sub.Group != nil {
+		data["group_name"] = sub.Group.Name
+	}
+	s.userWebhookService.Publish(UserWebhookEvent{
+		ID:     fmt.Sprintf("%s:%d:%dd", UserWebhookEventSubscriptionExpiring, sub.ID, daysRemaining),
+		Type:   UserWebhookEventSubscriptionExpiring,
+		UserID: sub.UserID,
+		Data:   data,
+	})
+}
+
 func (s *SubscriptionExpiryService) sendExpiryReminderIfDue(ctx context.Context, sub *UserSubscription) {
 	if sub == nil || sub.User == nil || sub.Group == nil || sub.User.Email == "" {
 		return
 	}
 	daysRemaining := sub.DaysRemaining()
-	if daysRemaining != 7 && daysRemaining != 3 && daysRemaining != 1 {
+	if !expiryReminderDue(daysRemaining) {
 		return
 	}
 	if err := s.notificationEmailService.Send(ctx, NotificationEmailSendInput{
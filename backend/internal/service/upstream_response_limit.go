@@ -17,8 +17,8 @@ var ErrUpstreamResponseBodyTooLarge = errors.New("upstream response body too lar
 const defaultUpstreamResponseReadMaxBytes = config.DefaultUpstreamResponseReadMaxBytes
 
 func resolveUpstreamResponseReadLimit(cfg *config.Config) int64 {
-	if cfg != nil && cfg.Gateway.UpstreamResponseReadMaxBytes > 0 {
-		return cfg.Gateway.UpstreamResponseReadMaxBytes
+	if cfg != nil && cfg.Live().Gateway.UpstreamResponseReadMaxBytes > 0 {
+		return cfg.Live().Gateway.UpstreamResponseReadMaxBytes
 	}
 	return defaultUpstreamResponseReadMaxBytes
 }
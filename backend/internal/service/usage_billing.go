@@ -6,9 +6,9 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"math"
 	"strings"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/money"
 	"github.com/shopspring/decimal"
 )
 
@@ -37,11 +37,20 @@ type UsageBillingCommand struct {
 	ImageCount          int
 	MediaType           string
 
-	BalanceCost         float64
-	SubscriptionCost    float64
-	APIKeyQuotaCost     float64
-	APIKeyRateLimitCost float64
-	AccountQuotaCost    float64
+	// 金额在服务层全程以 decimal 承载，Normalize 后定格到 UsageBillingMonetaryScale，
+	// 仓储层原样作为 NUMERIC 参数绑定，不再经过 float64。
+	BalanceCost         decimal.Decimal
+	SubscriptionCost    decimal.Decimal
+	APIKeyQuotaCost     decimal.Decimal
+	APIKeyRateLimitCost decimal.Decimal
+	AccountQuotaCost    decimal.Decimal
+
+	// OrganizationID 非空时 BalanceCost 从组织共享钱包扣除，而不是用户个人余额。
+	OrganizationID *int64
+
+	// QuotaExhaustedWebhookEvents 仅当本次扣费把 API Key 置为 quota_exhausted 时，
+	// 在同一事务内写入用户 Webhook outbox。不参与请求指纹。
+	QuotaExhaustedWebhookEvents []UserWebhookOutboxEvent
 }
 
 func (c *UsageBillingCommand) Normalize() {
@@ -58,8 +67,8 @@ func (c *UsageBillingCommand) Normalize() {
 }
 
 // UsageBillingMonetaryScale 是所有计费金额的规范小数位数，
-// 对齐 users.balance / api_keys.quota_used 的 NUMERIC(20,8)。
-const UsageBillingMonetaryScale = 8
+// 对齐 users.balance / api_keys.quota_used 的 NUMERIC(20,8)，即 money.LedgerScale。
+const UsageBillingMonetaryScale = money.LedgerScale
 
 // quantizeMonetaryFields 把命令中的金额统一量化到 NUMERIC(20,8)。
 //
@@ -81,27 +90,24 @@ const UsageBillingMonetaryScale = 8
 // 在参数进入 SQL 之前量化一次，两条语句就都拿到已经落在 8 位刻度上的同一个金额，
 // 存储阶段不再发生任何舍入，delta 精确相等。
 func (c *UsageBillingCommand) quantizeMonetaryFields() {
-	c.BalanceCost = QuantizeUsageBillingAmount(c.BalanceCost)
-	c.SubscriptionCost = QuantizeUsageBillingAmount(c.SubscriptionCost)
-	c.APIKeyQuotaCost = QuantizeUsageBillingAmount(c.APIKeyQuotaCost)
-	c.APIKeyRateLimitCost = QuantizeUsageBillingAmount(c.APIKeyRateLimitCost)
-	c.AccountQuotaCost = QuantizeUsageBillingAmount(c.AccountQuotaCost)
+	c.BalanceCost = c.BalanceCost.Round(UsageBillingMonetaryScale)
+	c.SubscriptionCost = c.SubscriptionCost.Round(UsageBillingMonetaryScale)
+	c.APIKeyQuotaCost = c.APIKeyQuotaCost.Round(UsageBillingMonetaryScale)
+	c.APIKeyRateLimitCost = c.APIKeyRateLimitCost.Round(UsageBillingMonetaryScale)
+	c.AccountQuotaCost = c.AccountQuotaCost.Round(UsageBillingMonetaryScale)
 }
 
 // QuantizeUsageBillingAmount 把金额舍入到 UsageBillingMonetaryScale 位小数，
-// 采用与 PostgreSQL NUMERIC 一致的 half-away-from-zero 规则。
+// 采用与 PostgreSQL NUMERIC 一致的 half-away-from-zero 规则（见 money 包的舍入策略）。
 //
 // 走 decimal 而不是 math.Round(v*1e8)/1e8：后者在乘除过程中会引入额外的二进制
-// 误差，边界值可能被推到错误的一侧。decimal.NewFromFloat 取 float64 的最短十进制
-// 表示，正是 PostgreSQL 把 float8 参数转成 numeric 时所用的表示。
+// 误差，边界值可能被推到错误的一侧。
 func QuantizeUsageBillingAmount(v float64) float64 {
-	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
-		return v
-	}
-	quantized, _ := decimal.NewFromFloat(v).Round(UsageBillingMonetaryScale).Float64()
-	return quantized
+	return money.RoundLedger(v)
 }
 
+// buildUsageBillingFingerprint 的金额段沿用 float64 的 %0.10f 格式：
+// 字段改为 decimal 前后，同一 request_id 的重试必须算出相同指纹。
 func buildUsageBillingFingerprint(c *UsageBillingCommand) string {
 	if c == nil {
 		return ""
@@ -123,11 +129,11 @@ func buildUsageBillingFingerprint(c *UsageBillingCommand) string {
 		c.ImageCount,
 		strings.TrimSpace(c.MediaType),
 		valueOrZero(c.SubscriptionID),
-		c.BalanceCost,
-		c.SubscriptionCost,
-		c.APIKeyQuotaCost,
-		c.APIKeyRateLimitCost,
-		c.AccountQuotaCost,
+		money.Float(c.BalanceCost),
+		money.Float(c.SubscriptionCost),
+		money.Float(c.APIKeyQuotaCost),
+		money.Float(c.APIKeyRateLimitCost),
+		money.Float(c.AccountQuotaCost),
 	)
 	if payloadHash := strings.TrimSpace(c.RequestPayloadHash); payloadHash != "" {
 		raw += "|" + payloadHash
@@ -166,6 +172,7 @@ type UsageBillingApplyResult struct {
 	Applied              bool
 	APIKeyQuotaExhausted bool
 	NewBalance           *float64           // post-deduction balance (nil = no balance deduction)
+	OrganizationBalance  *float64           // post-deduction organization wallet balance (nil = not charged to an organization)
 	BalanceOverdrafted   bool               // true when the sufficient-balance guard missed and debt was still recorded
 	QuotaState           *AccountQuotaState // post-increment quota state (nil = no quota increment)
 }
@@ -178,8 +185,8 @@ type BatchImageBalanceHoldCommand struct {
 	RequestPayloadHash string
 	UserID             int64
 	BatchID            string
-	HoldAmount         float64
-	ActualAmount       float64
+	HoldAmount         decimal.Decimal
+	ActualAmount       decimal.Decimal
 }
 
 func (c *BatchImageBalanceHoldCommand) Normalize() {
@@ -191,6 +198,9 @@ func (c *BatchImageBalanceHoldCommand) Normalize() {
 	if strings.TrimSpace(c.RequestFingerprint) == "" {
 		c.RequestFingerprint = buildBatchImageBalanceHoldFingerprint(c)
 	}
+	// 与 UsageBillingCommand 相同：指纹由原始金额派生，之后再定格到记账精度。
+	c.HoldAmount = c.HoldAmount.Round(UsageBillingMonetaryScale)
+	c.ActualAmount = c.ActualAmount.Round(UsageBillingMonetaryScale)
 }
 
 func buildBatchImageBalanceHoldFingerprint(c *BatchImageBalanceHoldCommand) string {
@@ -202,8 +212,8 @@ func buildBatchImageBalanceHoldFingerprint(c *BatchImageBalanceHoldCommand) stri
 		c.UserID,
 		c.APIKeyID,
 		strings.TrimSpace(c.BatchID),
-		c.HoldAmount,
-		c.ActualAmount,
+		money.Float(c.HoldAmount),
+		money.Float(c.ActualAmount),
 	)
 	if payloadHash := strings.TrimSpace(c.RequestPayloadHash); payloadHash != "" {
 		raw += "|" + payloadHash
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"math"
 	"testing"
 
@@ -14,6 +17,12 @@ func decimalPlaces(v float64) int32 {
 	return -decimal.NewFromFloat(v).Exponent()
 }
 
+// decimalAmountPlaces 返回命令金额（decimal）去掉尾随零后的小数位数，
+// 即绑定为 NUMERIC 参数时的刻度。
+func decimalAmountPlaces(d decimal.Decimal) int32 {
+	return decimalPlaces(d.InexactFloat64())
+}
+
 // 复现 #5229：同一笔 ActualCost 分别流向
 //
 //	balance    = balance - $1
@@ -34,14 +43,14 @@ func TestUsageBillingCommandQuantizesBalanceAndQuotaIdentically(t *testing.T) {
 		UserID:          1,
 		APIKeyID:        2,
 		AccountID:       3,
-		BalanceCost:     actualCost,
-		APIKeyQuotaCost: actualCost,
+		BalanceCost:     decimal.NewFromFloat(actualCost),
+		APIKeyQuotaCost: decimal.NewFromFloat(actualCost),
 	}
 	cmd.Normalize()
 
-	require.Equal(t, cmd.BalanceCost, cmd.APIKeyQuotaCost,
+	require.True(t, cmd.BalanceCost.Equal(cmd.APIKeyQuotaCost),
 		"余额扣减与 API Key 配额累加必须使用同一个规范金额")
-	require.LessOrEqual(t, decimalPlaces(cmd.BalanceCost), int32(UsageBillingMonetaryScale),
+	require.LessOrEqual(t, decimalAmountPlaces(cmd.BalanceCost), int32(UsageBillingMonetaryScale),
 		"金额超过 NUMERIC(20,8) 刻度时 PostgreSQL 仍会在存储阶段舍入")
 }
 
@@ -85,14 +94,14 @@ func TestQuantizedAmountsReconcileExactlyOverManyApplications(t *testing.T) {
 		UserID:              1,
 		APIKeyID:            2,
 		AccountID:           3,
-		BalanceCost:         actualCost,
-		SubscriptionCost:    0,
-		APIKeyQuotaCost:     actualCost,
-		APIKeyRateLimitCost: actualCost,
+		BalanceCost:         decimal.NewFromFloat(actualCost),
+		SubscriptionCost:    decimal.Zero,
+		APIKeyQuotaCost:     decimal.NewFromFloat(actualCost),
+		APIKeyRateLimitCost: decimal.NewFromFloat(actualCost),
 	}
 	cmd.Normalize()
 
-	unit := decimal.NewFromFloat(cmd.BalanceCost)
+	unit := cmd.BalanceCost
 	for _, n := range []int64{1, 10, 100, 1000} {
 		total := unit.Mul(decimal.NewFromInt(n))
 
@@ -120,22 +129,22 @@ func TestNormalizeQuantizesEveryMonetaryField(t *testing.T) {
 		UserID:              1,
 		APIKeyID:            2,
 		AccountID:           3,
-		BalanceCost:         raw,
-		SubscriptionCost:    raw,
-		APIKeyQuotaCost:     raw,
-		APIKeyRateLimitCost: raw,
-		AccountQuotaCost:    raw,
+		BalanceCost:         decimal.NewFromFloat(raw),
+		SubscriptionCost:    decimal.NewFromFloat(raw),
+		APIKeyQuotaCost:     decimal.NewFromFloat(raw),
+		APIKeyRateLimitCost: decimal.NewFromFloat(raw),
+		AccountQuotaCost:    decimal.NewFromFloat(raw),
 	}
 	cmd.Normalize()
 
-	for name, got := range map[string]float64{
+	for name, got := range map[string]decimal.Decimal{
 		"BalanceCost":         cmd.BalanceCost,
 		"SubscriptionCost":    cmd.SubscriptionCost,
 		"APIKeyQuotaCost":     cmd.APIKeyQuotaCost,
 		"APIKeyRateLimitCost": cmd.APIKeyRateLimitCost,
 		"AccountQuotaCost":    cmd.AccountQuotaCost,
 	} {
-		require.LessOrEqual(t, decimalPlaces(got), int32(UsageBillingMonetaryScale), name)
+		require.LessOrEqual(t, decimalAmountPlaces(got), int32(UsageBillingMonetaryScale), name)
 	}
 }
 
@@ -151,8 +160,8 @@ func TestNormalizeKeepsFingerprintDerivedFromRawAmounts(t *testing.T) {
 			UserID:          1,
 			APIKeyID:        2,
 			AccountID:       3,
-			BalanceCost:     raw,
-			APIKeyQuotaCost: raw,
+			BalanceCost:     decimal.NewFromFloat(raw),
+			APIKeyQuotaCost: decimal.NewFromFloat(raw),
 		}
 	}
 
@@ -163,17 +172,39 @@ func TestNormalizeKeepsFingerprintDerivedFromRawAmounts(t *testing.T) {
 	require.Equal(t, expected, cmd.RequestFingerprint)
 }
 
+// 金额字段改为 decimal 后，指纹仍须与 float64 时代逐字节一致，
+// 否则升级前写入的 request_id 在升级后重试会被判为 fingerprint conflict。
+func TestUsageBillingFingerprintMatchesLegacyFloatFormat(t *testing.T) {
+	const raw = 0.000078125
+
+	cmd := &UsageBillingCommand{
+		UserID:          1,
+		AccountID:       3,
+		APIKeyID:        2,
+		Model:           "gpt-5",
+		BalanceCost:     decimal.NewFromFloat(raw),
+		APIKeyQuotaCost: decimal.NewFromFloat(raw),
+	}
+	legacy := fmt.Sprintf(
+		"%d|%d|%d|%s|%s|%s|%s|%d|%d|%d|%d|%d|%d|%s|%d|%0.10f|%0.10f|%0.10f|%0.10f|%0.10f",
+		1, 3, 2, "", "gpt-5", "", "", 0, 0, 0, 0, 0, 0, "", 0, raw, 0.0, raw, 0.0, 0.0,
+	)
+	sum := sha256.Sum256([]byte(legacy))
+
+	require.Equal(t, hex.EncodeToString(sum[:]), buildUsageBillingFingerprint(cmd))
+}
+
 // 显式设置的指纹不被覆盖，且金额仍会被量化。
 func TestNormalizePreservesExplicitFingerprint(t *testing.T) {
 	cmd := &UsageBillingCommand{
 		RequestID:          "req-5229-explicit",
 		RequestFingerprint: "preset-fingerprint",
-		BalanceCost:        0.0000781234567,
+		BalanceCost:        decimal.NewFromFloat(0.0000781234567),
 	}
 	cmd.Normalize()
 
 	require.Equal(t, "preset-fingerprint", cmd.RequestFingerprint)
-	require.LessOrEqual(t, decimalPlaces(cmd.BalanceCost), int32(UsageBillingMonetaryScale))
+	require.LessOrEqual(t, decimalAmountPlaces(cmd.BalanceCost), int32(UsageBillingMonetaryScale))
 }
 
 func TestQuantizeUsageBillingAmountPassesThroughNonFinite(t *testing.T) {
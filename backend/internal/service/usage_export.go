@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	UsageExportFormatCSV     = "csv"
+	UsageExportFormatParquet = "parquet"
+
+	// UsageExportStreamUsageLogs 导出流名称，对应 usage_export_state.stream 与对象 key 中的目录名。
+	UsageExportStreamUsageLogs = "usage_logs"
+)
+
+var (
+	ErrUsageExportDisabled   = infraerrors.BadRequest("USAGE_EXPORT_DISABLED", "usage export is not enabled")
+	ErrUsageExportInProgress = infraerrors.Conflict("USAGE_EXPORT_IN_PROGRESS", "a usage export is already in progress")
+)
+
+// UsageExportRow 一条导出的 usage_logs 明细。费用与倍率保留数据库中的定点十进制文本，
+// CSV 原样输出，Parquet 转为 DECIMAL 逻辑类型，避免浮点误差进入数仓。
+type UsageExportRow struct {
+	ID                  int64
+	CreatedAt           time.Time
+	UserID              int64
+	APIKeyID            int64
+	APIKeyName          string
+	AccountID           int64
+	GroupID             *int64
+	GroupName           string
+	RequestID           string
+	Model               string
+	RequestedModel      string
+	UpstreamModel       string
+	InboundEndpoint     string
+	UpstreamEndpoint    string
+	BillingType         int16
+	BillingMode         string
+	Stream              bool
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	InputCost           string
+	OutputCost          string
+	CacheCreationCost   string
+	CacheReadCost       string
+	TotalCost           string
+	ActualCost          string
+	RateMultiplier      string
+	DurationMs          *int64
+	FirstTokenMs        *int64
+}
+
+// UsageExportState 导出水位与进行中的批次。PendingToID 非空表示上一次批次未完成，
+// 下次运行会按 (PendingFromID, PendingToID] 原样重做。
+type UsageExportState struct {
+	Stream        string     `json:"stream"`
+	WatermarkID   int64      `json:"watermark_id"`
+	PendingFromID *int64     `json:"pending_from_id,omitempty"`
+	PendingToID   *int64     `json:"pending_to_id,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// UsageExportFile 已上传的导出文件（清单）。
+type UsageExportFile struct {
+	ID            int64     `json:"id"`
+	Stream        string    `json:"stream"`
+	ObjectKey     string    `json:"object_key"`
+	Format        string    `json:"format"`
+	PartitionDate string    `json:"partition_date"`
+	FromID        int64     `json:"from_id"`
+	ToID          int64     `json:"to_id"`
+	RowCount      int       `json:"row_count"`
+	SizeBytes     int64     `json:"size_bytes"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UsageExportResult 单次导出运行的结果汇总。
+type UsageExportResult struct {
+	Batches     int   `json:"batches"`
+	Rows        int   `json:"rows"`
+	Files       int   `json:"files"`
+	WatermarkID int64 `json:"watermark_id"`
+}
+
+type UsageExportRepository interface {
+	GetState(ctx context.Context, stream string) (*UsageExportState, error)
+	// SetPending 记录即将导出的 (fromID, toID] 区间，须在上传任何文件之前调用。
+	SetPending(ctx context.Context, stream string, fromID, toID int64, at time.Time) error
+	// Commit 在同一事务内写入文件清单、把水位推进到 toID 并清除进行中区间。
+	Commit(ctx context.Context, stream string, toID int64, files []UsageExportFile, at time.Time) error
+	RecordError(ctx context.Context, stream string, message string, at time.Time) error
+	ListFiles(ctx context.Context, stream string, limit int) ([]UsageExportFile, error)
+
+	// UpperBoundID 返回 created_at 早于 cutoff 的最新一条 usage_logs 的 id，无记录时返回 0。
+	UpperBoundID(ctx context.Context, cutoff time.Time) (int64, error)
+	// ListRows 按 id 升序返回 (fromID, toID] 内的明细；limit <= 0 表示不限制。
+	ListRows(ctx context.Context, fromID, toID int64, limit int) ([]UsageExportRow, error)
+}
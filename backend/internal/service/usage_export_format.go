@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/shopspring/decimal"
+)
+
+// usageExportDecimalScale Parquet 中费用列的小数位数，与 usage_logs 的 DECIMAL(20,10) 一致。
+const usageExportDecimalScale = 10
+
+var usageExportColumns = []string{
+	"id", "created_at", "user_id", "api_key_id", "api_key_name", "account_id", "group_id", "group_name",
+	"request_id", "model", "requested_model", "upstream_model", "inbound_endpoint", "upstream_endpoint",
+	"billing_type", "billing_mode", "stream",
+	"input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+	"input_cost", "output_cost", "cache_creation_cost", "cache_read_cost", "total_cost", "actual_cost",
+	"rate_multiplier", "duration_ms", "first_token_ms",
+}
+
+// usageExportParquetRow Parquet 行结构，列名与 CSV 表头一致。
+// 费用与倍率为 DECIMAL(18,10)，created_at 为 UTC 微秒时间戳。
+type usageExportParquetRow struct {
+	ID                  int64  `parquet:"id"`
+	CreatedAt           int64  `parquet:"created_at,timestamp(microsecond)"`
+	UserID              int64  `parquet:"user_id"`
+	APIKeyID            int64  `parquet:"api_key_id"`
+	APIKeyName          string `parquet:"api_key_name"`
+	AccountID           int64  `parquet:"account_id"`
+	GroupID             *int64 `parquet:"group_id,optional"`
+	GroupName           string `parquet:"group_name,dict"`
+	RequestID           string `parquet:"request_id"`
+	Model               string `parquet:"model,dict"`
+	RequestedModel      string `parquet:"requested_model,dict"`
+	UpstreamModel       string `parquet:"upstream_model,dict"`
+	InboundEndpoint     string `parquet:"inbound_endpoint,dict"`
+	UpstreamEndpoint    string `parquet:"upstream_endpoint,dict"`
+	BillingType         int32  `parquet:"billing_type"`
+	BillingMode         string `parquet:"billing_mode,dict"`
+	Stream              bool   `parquet:"stream"`
+	InputTokens         int64  `parquet:"input_tokens"`
+	OutputTokens        int64  `parquet:"output_tokens"`
+	CacheCreationTokens int64  `parquet:"cache_creation_tokens"`
+	CacheReadTokens     int64  `parquet:"cache_read_tokens"`
+	InputCost           int64  `parquet:"input_cost,decimal(10:18)"`
+	OutputCost          int64  `parquet:"output_cost,decimal(10:18)"`
+	CacheCreationCost   int64  `parquet:"cache_creation_cost,decimal(10:18)"`
+	CacheReadCost       int64  `parquet:"cache_read_cost,decimal(10:18)"`
+	TotalCost           int64  `parquet:"total_cost,decimal(10:18)"`
+	ActualCost          int64  `parquet:"actual_cost,decimal(10:18)"`
+	RateMultiplier      int64  `parquet:"rate_multiplier,decimal(10:18)"`
+	DurationMs          *int64 `parquet:"duration_ms,optional"`
+	FirstTokenMs        *int64 `parquet:"first_token_ms,optional"`
+}
+
+// usageExportFileExt 返回导出格式对应的文件扩展名与 Content-Type。
+func usageExportFileExt(format string) (string, string) {
+	if format == UsageExportFormatCSV {
+		return "csv.gz", "application/gzip"
+	}
+	return "parquet", "application/vnd.apache.parquet"
+}
+
+// encodeUsageExport 把一组明细编码为 gzip 压缩的 CSV 或 zstd 压缩的 Parquet。
+func encodeUsageExport(format string, rows []UsageExportRow) ([]byte, error) {
+	switch format {
+	case UsageExportFormatCSV:
+		return encodeUsageExportCSV(rows)
+	case UsageExportFormatParquet:
+		return encodeUsageExportParquet(rows)
+	default:
+		return nil, fmt.Errorf("unsupported usage export format %q", format)
+	}
+}
+
+func encodeUsageExportCSV(rows []UsageExportRow) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := csv.NewWriter(gz)
+	if err := w.Write(usageExportColumns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(usageExportColumns))
+	for i := range rows {
+		r := &rows[i]
+		record = record[:0]
+		record = append(record,
+			strconv.FormatInt(r.ID, 10),
+			r.CreatedAt.UTC().Format(time.RFC3339Nano),
+			strconv.FormatInt(r.UserID, 10),
+			strconv.FormatInt(r.APIKeyID, 10),
+			r.APIKeyName,
+			strconv.FormatInt(r.AccountID, 10),
+			formatOptionalInt64(r.GroupID),
+			r.GroupName,
+			r.RequestID,
+			r.Model,
+			r.RequestedModel,
+			r.UpstreamModel,
+			r.InboundEndpoint,
+			r.UpstreamEndpoint,
+			strconv.Itoa(int(r.BillingType)),
+			r.BillingMode,
+			strconv.FormatBool(r.Stream),
+			strconv.FormatInt(r.InputTokens, 10),
+			strconv.FormatInt(r.OutputTokens, 10),
+			strconv.FormatInt(r.CacheCreationTokens, 10),
+			strconv.FormatInt(r.CacheReadTokens, 10),
+			r.InputCost,
+			r.OutputCost,
+			r.CacheCreationCost,
+			r.CacheReadCost,
+			r.TotalCost,
+			r.ActualCost,
+			r.RateMultiplier,
+			formatOptionalInt64(r.DurationMs),
+			formatOptionalInt64(r.FirstTokenMs),
+		)
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeUsageExportParquet(rows []UsageExportRow) ([]byte, error) {
+	out := make([]usageExportParquetRow, 0, len(rows))
+	for i := range rows {
+		r := &rows[i]
+		row := usageExportParquetRow{
+			ID:                  r.ID,
+			CreatedAt:           r.CreatedAt.UnixMicro(),
+			UserID:              r.UserID,
+			APIKeyID:            r.APIKeyID,
+			APIKeyName:          r.APIKeyName,
+			AccountID:           r.AccountID,
+			GroupID:             r.GroupID,
+			GroupName:           r.GroupName,
+			RequestID:           r.RequestID,
+			Model:               r.Model,
+			RequestedModel:      r.RequestedModel,
+			UpstreamModel:       r.UpstreamModel,
+			InboundEndpoint:     r.InboundEndpoint,
+			UpstreamEndpoint:    r.UpstreamEndpoint,
+			BillingType:         int32(r.BillingType),
+			BillingMode:         r.BillingMode,
+			Stream:              r.Stream,
+			InputTokens:         r.InputTokens,
+			OutputTokens:        r.OutputTokens,
+			CacheCreationTokens: r.CacheCreationTokens,
+			CacheReadTokens:     r.CacheReadTokens,
+			DurationMs:          r.DurationMs,
+			FirstTokenMs:        r.FirstTokenMs,
+		}
+		var err error
+		for _, f := range []struct {
+			dst *int64
+			src string
+		}{
+			{&row.InputCost, r.InputCost},
+			{&row.OutputCost, r.OutputCost},
+			{&row.CacheCreationCost, r.CacheCreationCost},
+			{&row.CacheReadCost, r.CacheReadCost},
+			{&row.TotalCost, r.TotalCost},
+			{&row.ActualCost, r.ActualCost},
+			{&row.RateMultiplier, r.RateMultiplier},
+		} {
+			if *f.dst, err = scaledUsageExportDecimal(f.src); err != nil {
+				return nil, fmt.Errorf("usage log %d: %w", r.ID, err)
+			}
+		}
+		out = append(out, row)
+	}
+
+	var buf bytes.Buffer
+	w := parquet.NewGenericWriter[usageExportParquetRow](&buf, parquet.Compression(&parquet.Zstd))
+	if _, err := w.Write(out); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scaledUsageExportDecimal 把十进制文本转换为按 usageExportDecimalScale 缩放的整数（DECIMAL 的物理值）。
+func scaledUsageExportDecimal(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse decimal %q: %w", s, err)
+	}
+	return d.Shift(usageExportDecimalScale).Round(0).IntPart(), nil
+}
+
+func formatOptionalInt64(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
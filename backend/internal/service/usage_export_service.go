@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	usageExportLeaderLockKey = "usage_export:leader"
+	// usageExportLeaderLockSlack 锁 TTL 在任务超时之上留出的余量，保证任务运行期间锁不会过期。
+	usageExportLeaderLockSlack = 5 * time.Minute
+	usageExportStatusFileLimit = 20
+	usageExportMaxErrorLength  = 2000
+)
+
+var usageExportCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// UsageExportStatus 后台展示的导出配置与进度。
+type UsageExportStatus struct {
+	Enabled     bool              `json:"enabled"`
+	Running     bool              `json:"running"`
+	Cron        string            `json:"cron"`
+	Format      string            `json:"format"`
+	Bucket      string            `json:"bucket"`
+	Prefix      string            `json:"prefix"`
+	State       *UsageExportState `json:"state"`
+	RecentFiles []UsageExportFile `json:"recent_files"`
+}
+
+// UsageExportService 按 cron 把 usage_logs 明细增量导出到 S3 兼容存储，供数仓加载。
+//
+// 以 usage_logs.id 为水位：每批先在 usage_export_state 记录 (from, to] 区间，
+// 按 UTC 日期拆分为 dt=YYYY-MM-DD 分区文件上传，全部上传成功后才推进水位。
+// 中途失败时下次运行原样重做该区间，对象 key 由区间决定，重做只会覆盖同名文件。
+type UsageExportService struct {
+	repo         UsageExportRepository
+	storeFactory BackupObjectStoreFactory
+	cfg          config.UsageExportConfig
+
+	cronSched *cron.Cron
+	running   atomic.Bool
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
+	wg       sync.WaitGroup
+	now      func() time.Time
+}
+
+func NewUsageExportService(repo UsageExportRepository, storeFactory BackupObjectStoreFactory, cfg *config.Config) *UsageExportService {
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	s := &UsageExportService{
+		repo:         repo,
+		storeFactory: storeFactory,
+		instanceID:   uuid.NewString(),
+		bgCtx:        bgCtx,
+		bgCancel:     bgCancel,
+		now:          time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.UsageExport
+	}
+	return s
+}
+
+// SetLeaderLock 注入选主用的锁，多实例部署时只有一个实例执行导出。
+func (s *UsageExportService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Start 按配置的 cron 表达式注册定时导出。
+func (s *UsageExportService) Start() {
+	if s == nil || s.repo == nil || !s.cfg.Enabled {
+		return
+	}
+	if _, err := usageExportCronParser.Parse(s.cfg.Cron); err != nil {
+		slog.Error("[UsageExport] invalid cron expression, scheduled export disabled", "cron", s.cfg.Cron, "error", err)
+		return
+	}
+	s.cronSched = cron.New(cron.WithParser(usageExportCronParser), cron.WithLocation(timezone.Location()))
+	if _, err := s.cronSched.AddFunc(s.cfg.Cron, s.runScheduled); err != nil {
+		slog.Error("[UsageExport] schedule export failed", "cron", s.cfg.Cron, "error", err)
+		return
+	}
+	s.cronSched.Start()
+	slog.Info("[UsageExport] scheduled export enabled", "cron", s.cfg.Cron, "format", s.cfg.Format, "bucket", s.cfg.S3.Bucket)
+}
+
+// Stop 停止调度并取消进行中的导出；未完成的批次会在下次运行时从记录的区间继续。
+func (s *UsageExportService) Stop() {
+	if s == nil {
+		return
+	}
+	if s.cronSched != nil {
+		<-s.cronSched.Stop().Done()
+	}
+	s.bgCancel()
+	s.wg.Wait()
+}
+
+func (s *UsageExportService) runScheduled() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+	result, err := s.runWithLeaderLock()
+	if err != nil {
+		slog.Error("[UsageExport] scheduled export failed", "error", err)
+		return
+	}
+	if result != nil && result.Rows > 0 {
+		slog.Info("[UsageExport] scheduled export finished",
+			"batches", result.Batches, "rows", result.Rows, "files", result.Files, "watermark_id", result.WatermarkID)
+	}
+}
+
+// TriggerRun 后台手动触发一次导出（异步执行）。
+func (s *UsageExportService) TriggerRun() error {
+	if !s.cfg.Enabled {
+		return ErrUsageExportDisabled
+	}
+	if s.running.Load() {
+		return ErrUsageExportInProgress
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if _, err := s.runWithLeaderLock(); err != nil {
+			slog.Error("[UsageExport] manual export failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// runWithLeaderLock 多实例保护：同一时刻只有一个实例推进导出水位。
+func (s *UsageExportService) runWithLeaderLock() (*UsageExportResult, error) {
+	timeout := time.Duration(s.cfg.TimeoutMinutes) * time.Minute
+	if timeout <= 0 {
+		timeout = time.Hour
+	}
+	ctx, cancel := context.WithTimeout(s.bgCtx, timeout)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, usageExportLeaderLockKey, s.instanceID, timeout+usageExportLeaderLockSlack)
+	if !ok {
+		return nil, nil
+	}
+	defer release()
+	return s.Run(ctx)
+}
+
+// Run 从水位开始逐批导出，直到追上 now-lag 或 ctx 结束。
+func (s *UsageExportService) Run(ctx context.Context) (*UsageExportResult, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrUsageExportDisabled
+	}
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, ErrUsageExportInProgress
+	}
+	defer s.running.Store(false)
+
+	result := &UsageExportResult{}
+	err := s.run(ctx, result)
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > usageExportMaxErrorLength {
+			msg = msg[:usageExportMaxErrorLength]
+		}
+		if recErr := s.repo.RecordError(context.Background(), UsageExportStreamUsageLogs, msg, s.now()); recErr != nil {
+			slog.Warn("[UsageExport] record error failed", "error", recErr)
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *UsageExportService) run(ctx context.Context, result *UsageExportResult) error {
+	store, err := s.storeFactory(ctx, &BackupS3Config{
+		Endpoint:        s.cfg.S3.Endpoint,
+		Region:          s.cfg.S3.Region,
+		Bucket:          s.cfg.S3.Bucket,
+		AccessKeyID:     s.cfg.S3.AccessKeyID,
+		SecretAccessKey: s.cfg.S3.SecretAccessKey,
+		Prefix:          s.cfg.S3.Prefix,
+		ForcePathStyle:  s.cfg.S3.ForcePathStyle,
+	})
+	if err != nil {
+		return fmt.Errorf("create object store: %w", err)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows, files, watermark, done, err := s.exportBatch(ctx, store)
+		if err != nil {
+			return err
+		}
+		result.WatermarkID = watermark
+		if done {
+			return nil
+		}
+		result.Batches++
+		result.Rows += rows
+		result.Files += files
+	}
+}
+
+// exportBatch 导出一个批次：优先重做未完成的区间，否则从水位向后取至多 BatchSize 行。
+// done 表示已追上 now-lag，没有更多可导出的记录。
+func (s *UsageExportService) exportBatch(ctx context.Context, store BackupObjectStore) (rows int, files int, watermark int64, done bool, err error) {
+	state, err := s.repo.GetState(ctx, UsageExportStreamUsageLogs)
+	if err != nil {
+		return 0, 0, 0, false, fmt.Errorf("load export state: %w", err)
+	}
+
+	var (
+		fromID = state.WatermarkID
+		toID   int64
+		batch  []UsageExportRow
+	)
+	if state.PendingFromID != nil && state.PendingToID != nil {
+		fromID, toID = *state.PendingFromID, *state.PendingToID
+		if batch, err = s.repo.ListRows(ctx, fromID, toID, 0); err != nil {
+			return 0, 0, state.WatermarkID, false, fmt.Errorf("list pending rows: %w", err)
+		}
+	} else {
+		cutoff := s.now().Add(-time.Duration(s.cfg.LagMinutes) * time.Minute)
+		bound, err := s.repo.UpperBoundID(ctx, cutoff)
+		if err != nil {
+			return 0, 0, state.WatermarkID, false, fmt.Errorf("load export upper bound: %w", err)
+		}
+		if bound <= fromID {
+			return 0, 0, state.WatermarkID, true, nil
+		}
+		if batch, err = s.repo.ListRows(ctx, fromID, bound, s.cfg.BatchSize); err != nil {
+			return 0, 0, state.WatermarkID, false, fmt.Errorf("list rows: %w", err)
+		}
+		if len(batch) == 0 {
+			// (from, bound] 内没有记录（已被清理），直接推进水位。
+			if err := s.repo.Commit(ctx, UsageExportStreamUsageLogs, bound, nil, s.now()); err != nil {
+				return 0, 0, state.WatermarkID, false, fmt.Errorf("commit export watermark: %w", err)
+			}
+			return 0, 0, bound, false, nil
+		}
+		toID = batch[len(batch)-1].ID
+		if err := s.repo.SetPending(ctx, UsageExportStreamUsageLogs, fromID, toID, s.now()); err != nil {
+			return 0, 0, state.WatermarkID, false, fmt.Errorf("record pending range: %w", err)
+		}
+	}
+
+	uploaded, err := s.uploadBatch(ctx, store, fromID, toID, batch)
+	if err != nil {
+		return 0, 0, state.WatermarkID, false, err
+	}
+	if err := s.repo.Commit(ctx, UsageExportStreamUsageLogs, toID, uploaded, s.now()); err != nil {
+		return 0, 0, state.WatermarkID, false, fmt.Errorf("commit export batch: %w", err)
+	}
+	return len(batch), len(uploaded), toID, false, nil
+}
+
+// uploadBatch 按 UTC 日期拆分批次并逐个上传，返回文件清单。
+func (s *UsageExportService) uploadBatch(ctx context.Context, store BackupObjectStore, fromID, toID int64, batch []UsageExportRow) ([]UsageExportFile, error) {
+	byDate := make(map[string][]UsageExportRow)
+	for _, row := range batch {
+		date := row.CreatedAt.UTC().Format("2006-01-02")
+		byDate[date] = append(byDate[date], row)
+	}
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	ext, contentType := usageExportFileExt(s.cfg.Format)
+	files := make([]UsageExportFile, 0, len(dates))
+	for _, date := range dates {
+		rows := byDate[date]
+		data, err := encodeUsageExport(s.cfg.Format, rows)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", date, err)
+		}
+		key := UsageExportObjectKey(s.cfg.S3.Prefix, UsageExportStreamUsageLogs, date, fromID, toID, ext)
+		size, err := store.Upload(ctx, key, bytes.NewReader(data), contentType)
+		if err != nil {
+			return nil, fmt.Errorf("upload %s: %w", key, err)
+		}
+		files = append(files, UsageExportFile{
+			Stream:        UsageExportStreamUsageLogs,
+			ObjectKey:     key,
+			Format:        s.cfg.Format,
+			PartitionDate: date,
+			FromID:        fromID,
+			ToID:          toID,
+			RowCount:      len(rows),
+			SizeBytes:     size,
+		})
+	}
+	return files, nil
+}
+
+// UsageExportObjectKey 生成导出对象 key：<prefix><stream>/dt=<date>/<stream>_<from+1>_<to>.<ext>，
+// id 补零到 20 位，保证同一分区内按字典序即按 id 排序。
+func UsageExportObjectKey(prefix, stream, date string, fromID, toID int64, ext string) string {
+	name := fmt.Sprintf("%s_%020d_%020d.%s", stream, fromID+1, toID, ext)
+	return strings.TrimPrefix(path.Join(prefix, stream, "dt="+date, name), "/")
+}
+
+// Status 返回导出配置、水位与最近上传的文件。
+func (s *UsageExportService) Status(ctx context.Context) (*UsageExportStatus, error) {
+	state, err := s.repo.GetState(ctx, UsageExportStreamUsageLogs)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.repo.ListFiles(ctx, UsageExportStreamUsageLogs, usageExportStatusFileLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &UsageExportStatus{
+		Enabled:     s.cfg.Enabled,
+		Running:     s.running.Load(),
+		Cron:        s.cfg.Cron,
+		Format:      s.cfg.Format,
+		Bucket:      s.cfg.S3.Bucket,
+		Prefix:      s.cfg.S3.Prefix,
+		State:       state,
+		RecentFiles: files,
+	}, nil
+}
@@ -0,0 +1,257 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+type usageExportRepoStub struct {
+	state    UsageExportState
+	rows     []UsageExportRow
+	files    map[string]UsageExportFile
+	listArgs [][3]int64
+}
+
+func (r *usageExportRepoStub) GetState(context.Context, string) (*UsageExportState, error) {
+	state := r.state
+	return &state, nil
+}
+
+func (r *usageExportRepoStub) SetPending(_ context.Context, _ string, fromID, toID int64, at time.Time) error {
+	r.state.PendingFromID = &fromID
+	r.state.PendingToID = &toID
+	r.state.LastRunAt = &at
+	return nil
+}
+
+func (r *usageExportRepoStub) Commit(_ context.Context, _ string, toID int64, files []UsageExportFile, at time.Time) error {
+	for _, f := range files {
+		r.files[f.ObjectKey] = f
+	}
+	r.state.WatermarkID = toID
+	r.state.PendingFromID = nil
+	r.state.PendingToID = nil
+	r.state.LastSuccessAt = &at
+	r.state.LastError = ""
+	return nil
+}
+
+func (r *usageExportRepoStub) RecordError(_ context.Context, _ string, message string, _ time.Time) error {
+	r.state.LastError = message
+	return nil
+}
+
+func (r *usageExportRepoStub) ListFiles(context.Context, string, int) ([]UsageExportFile, error) {
+	return nil, nil
+}
+
+func (r *usageExportRepoStub) UpperBoundID(_ context.Context, cutoff time.Time) (int64, error) {
+	var bound int64
+	for _, row := range r.rows {
+		if row.CreatedAt.Before(cutoff) && row.ID > bound {
+			bound = row.ID
+		}
+	}
+	return bound, nil
+}
+
+func (r *usageExportRepoStub) ListRows(_ context.Context, fromID, toID int64, limit int) ([]UsageExportRow, error) {
+	r.listArgs = append(r.listArgs, [3]int64{fromID, toID, int64(limit)})
+	out := make([]UsageExportRow, 0)
+	for _, row := range r.rows {
+		if row.ID > fromID && row.ID <= toID {
+			out = append(out, row)
+			if limit > 0 && len(out) == limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+type usageExportStoreStub struct {
+	BackupObjectStore
+	objects   map[string][]byte
+	failAfter int
+	uploads   int
+}
+
+func (s *usageExportStoreStub) Upload(_ context.Context, key string, body io.Reader, _ string) (int64, error) {
+	s.uploads++
+	if s.failAfter > 0 && s.uploads > s.failAfter {
+		return 0, errors.New("injected upload failure")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return 0, err
+	}
+	s.objects[key] = data
+	return int64(len(data)), nil
+}
+
+func newUsageExportTestService(format string, batchSize int) (*UsageExportService, *usageExportRepoStub, *usageExportStoreStub) {
+	repo := &usageExportRepoStub{state: UsageExportState{Stream: UsageExportStreamUsageLogs}, files: map[string]UsageExportFile{}}
+	store := &usageExportStoreStub{objects: map[string][]byte{}}
+	cfg := &config.Config{UsageExport: config.UsageExportConfig{
+		Enabled:        true,
+		Cron:           "30 1 * * *",
+		Format:         format,
+		BatchSize:      batchSize,
+		LagMinutes:     10,
+		TimeoutMinutes: 10,
+		S3:             config.UsageExportS3Config{Bucket: "warehouse", Prefix: "exports/"},
+	}}
+	factory := func(context.Context, *BackupS3Config) (BackupObjectStore, error) { return store, nil }
+	svc := NewUsageExportService(repo, factory, cfg)
+	svc.now = func() time.Time { return time.Date(2026, 5, 3, 2, 0, 0, 0, time.UTC) }
+	return svc, repo, store
+}
+
+func usageExportTestRows() []UsageExportRow {
+	groupID := int64(9)
+	duration := int64(1200)
+	day1 := time.Date(2026, 5, 1, 23, 59, 0, 0, time.UTC)
+	day2 := time.Date(2026, 5, 2, 0, 1, 0, 0, time.UTC)
+	rows := []UsageExportRow{
+		{ID: 1, CreatedAt: day1, UserID: 1, APIKeyID: 11, APIKeyName: "ci", AccountID: 5, GroupID: &groupID, GroupName: "default",
+			Model: "claude-sonnet-4", RequestedModel: "sonnet", UpstreamModel: "claude-sonnet-4-20250514",
+			InputTokens: 100, OutputTokens: 20, InputCost: "0.0003000000", OutputCost: "0.0003000000",
+			CacheCreationCost: "0", CacheReadCost: "0", TotalCost: "0.0006000000", ActualCost: "0.0006000000",
+			RateMultiplier: "1.0000", DurationMs: &duration},
+		{ID: 2, CreatedAt: day1.Add(30 * time.Second), UserID: 1, APIKeyID: 11, AccountID: 5, Model: "gpt-5",
+			InputCost: "0", OutputCost: "0", CacheCreationCost: "0", CacheReadCost: "0",
+			TotalCost: "0.1234567891", ActualCost: "0.1234567891", RateMultiplier: "1.5000"},
+		{ID: 4, CreatedAt: day2, UserID: 2, APIKeyID: 12, AccountID: 6, Model: "gpt-5",
+			InputCost: "0", OutputCost: "0", CacheCreationCost: "0", CacheReadCost: "0",
+			TotalCost: "1", ActualCost: "1", RateMultiplier: "1"},
+		// 晚于 now-lag，本次不导出
+		{ID: 5, CreatedAt: time.Date(2026, 5, 3, 1, 55, 0, 0, time.UTC), UserID: 2, APIKeyID: 12, AccountID: 6, Model: "gpt-5",
+			InputCost: "0", OutputCost: "0", CacheCreationCost: "0", CacheReadCost: "0",
+			TotalCost: "1", ActualCost: "1", RateMultiplier: "1"},
+	}
+	return rows
+}
+
+func usageExportObjectKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestUsageExportObjectKey(t *testing.T) {
+	require.Equal(t,
+		"exports/usage_logs/dt=2026-05-01/usage_logs_00000000000000000001_00000000000000000004.parquet",
+		UsageExportObjectKey("exports/", UsageExportStreamUsageLogs, "2026-05-01", 0, 4, "parquet"))
+	require.Equal(t,
+		"usage_logs/dt=2026-05-01/usage_logs_00000000000000000011_00000000000000000020.csv.gz",
+		UsageExportObjectKey("", UsageExportStreamUsageLogs, "2026-05-01", 10, 20, "csv.gz"))
+}
+
+func TestUsageExportRunPartitionsByDateAndStopsAtLag(t *testing.T) {
+	svc, repo, store := newUsageExportTestService(UsageExportFormatCSV, 100)
+	repo.rows = usageExportTestRows()
+
+	result, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Batches)
+	require.Equal(t, 3, result.Rows)
+	require.Equal(t, 2, result.Files)
+	require.Equal(t, int64(4), repo.state.WatermarkID)
+	require.Nil(t, repo.state.PendingToID)
+
+	require.Equal(t, []string{
+		"exports/usage_logs/dt=2026-05-01/usage_logs_00000000000000000001_00000000000000000004.csv.gz",
+		"exports/usage_logs/dt=2026-05-02/usage_logs_00000000000000000001_00000000000000000004.csv.gz",
+	}, usageExportObjectKeys(store.objects))
+
+	gz, err := gzip.NewReader(bytes.NewReader(store.objects["exports/usage_logs/dt=2026-05-01/usage_logs_00000000000000000001_00000000000000000004.csv.gz"]))
+	require.NoError(t, err)
+	records, err := csv.NewReader(gz).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, usageExportColumns, records[0])
+	require.Equal(t, "1", records[1][0])
+	require.Equal(t, "9", records[1][6])
+	require.Equal(t, "sonnet", records[1][10])
+	require.Equal(t, "0.0006000000", records[1][26])
+	require.Equal(t, "", records[2][6])
+
+	// 再次运行没有新数据
+	result, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Rows)
+	require.Equal(t, int64(4), result.WatermarkID)
+}
+
+func TestUsageExportResumesPendingRangeAfterFailure(t *testing.T) {
+	svc, repo, store := newUsageExportTestService(UsageExportFormatCSV, 2)
+	repo.rows = usageExportTestRows()
+
+	// 第一批 (0, 2] 只含 5 月 1 日一个文件；第二批 (2, 4] 上传失败
+	store.failAfter = 1
+	_, err := svc.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, int64(2), repo.state.WatermarkID)
+	require.NotNil(t, repo.state.PendingToID)
+	require.Equal(t, int64(4), *repo.state.PendingToID)
+	require.Contains(t, repo.state.LastError, "injected upload failure")
+
+	// 期间到达的新数据不影响重做区间
+	repo.rows = append(repo.rows, UsageExportRow{ID: 3, CreatedAt: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC), Model: "late",
+		InputCost: "0", OutputCost: "0", CacheCreationCost: "0", CacheReadCost: "0", TotalCost: "0", ActualCost: "0", RateMultiplier: "1"})
+	store.failAfter = 0
+	repo.listArgs = nil
+	result, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, [3]int64{2, 4, 0}, repo.listArgs[0])
+	require.Equal(t, int64(4), result.WatermarkID)
+	require.Empty(t, repo.state.LastError)
+	require.Contains(t, store.objects, "exports/usage_logs/dt=2026-05-02/usage_logs_00000000000000000003_00000000000000000004.csv.gz")
+}
+
+func TestUsageExportParquetRoundTrip(t *testing.T) {
+	svc, repo, store := newUsageExportTestService(UsageExportFormatParquet, 100)
+	repo.rows = usageExportTestRows()
+
+	_, err := svc.Run(context.Background())
+	require.NoError(t, err)
+
+	data := store.objects["exports/usage_logs/dt=2026-05-01/usage_logs_00000000000000000001_00000000000000000004.parquet"]
+	require.NotEmpty(t, data)
+	rows, err := parquet.Read[usageExportParquetRow](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(1), rows[0].ID)
+	require.Equal(t, time.Date(2026, 5, 1, 23, 59, 0, 0, time.UTC).UnixMicro(), rows[0].CreatedAt)
+	require.NotNil(t, rows[0].GroupID)
+	require.Equal(t, int64(9), *rows[0].GroupID)
+	require.Nil(t, rows[1].GroupID)
+	require.Equal(t, int64(6_000_000), rows[0].ActualCost)
+	require.Equal(t, int64(1_234_567_891), rows[1].ActualCost)
+	require.Equal(t, int64(15_000_000_000), rows[1].RateMultiplier)
+	require.Equal(t, int64(1200), *rows[0].DurationMs)
+}
+
+func TestUsageExportRunRequiresEnabled(t *testing.T) {
+	svc, _, _ := newUsageExportTestService(UsageExportFormatCSV, 100)
+	svc.cfg.Enabled = false
+	_, err := svc.Run(context.Background())
+	require.ErrorIs(t, err, ErrUsageExportDisabled)
+	require.ErrorIs(t, svc.TriggerRun(), ErrUsageExportDisabled)
+}
@@ -161,6 +161,7 @@ type UsageLog struct {
 	CacheCreationCost         float64
 	CacheReadCost             float64
 	TotalCost                 float64
+	// ActualCost 8 位定格的实际扣费，由 CostBreakdown 原样写入，与余额扣减逐位一致
 	ActualCost                float64
 	RateMultiplier            float64
 	LongContextBillingApplied bool
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	usageLogMoneyBackfillLeaderLockKey = "usage_log_money_backfill:leader"
+	usageLogMoneyBackfillLeaderLockTTL = 30 * time.Minute
+	usageLogMoneyBackfillPassTimeout   = 20 * time.Minute
+	// usageLogMoneyBackfillInterval 未完成（被中断或其他实例持锁）时的重试间隔
+	usageLogMoneyBackfillInterval = time.Hour
+	// usageLogMoneyBackfillBatchPause 批次间让出数据库，避免长时间占满 usage_logs 的写入带宽
+	usageLogMoneyBackfillBatchPause = 200 * time.Millisecond
+	usageLogMoneyBackfillBatchSize  = 1000
+
+	// SettingKeyUsageLogMoneyBackfillCursor 回填进度：已处理到的最大 usage_logs.id，完成后为 "done"
+	SettingKeyUsageLogMoneyBackfillCursor = "usage_log_money_backfill_cursor"
+	usageLogMoneyBackfillDone             = "done"
+)
+
+// UsageLogMoneyBackfillRepository 历史 usage_logs.actual_cost 的分批舍入改写。
+type UsageLogMoneyBackfillRepository interface {
+	// RoundActualCostBatch 把 id > afterID 的至多 limit 条记录的 actual_cost 舍入到记账精度
+	RoundActualCostBatch(ctx context.Context, afterID int64, limit int) (*UsageLogMoneyBackfillBatch, error)
+}
+
+// UsageLogMoneyBackfillBatch 一批回填的结果
+type UsageLogMoneyBackfillBatch struct {
+	// LastID 本批扫描到的最大 id，为 0 表示已扫描完毕
+	LastID  int64
+	Scanned int
+	Updated int
+}
+
+// UsageLogMoneyBackfillService 在线回填任务：把升级前写入的 usage_logs.actual_cost
+// 按记账精度（8 位小数，half-away-from-zero）舍入，使 SUM(actual_cost) 与余额流水逐位一致。
+//
+// 迁移 238 不在事务内改写大表，改由本任务按 id 分批执行；进度写入 settings，
+// 重启后从断点继续，全部完成后不再运行。多实例部署时通过选主锁保证只有一个实例在改写。
+type UsageLogMoneyBackfillService struct {
+	repo        UsageLogMoneyBackfillRepository
+	settingRepo SettingRepository
+	batchSize   int
+	batchPause  time.Duration
+
+	lockCache  LeaderLockCache
+	db         *sql.DB
+	instanceID string
+
+	bgCtx     context.Context
+	bgCancel  context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewUsageLogMoneyBackfillService 创建 usage_logs 金额回填服务
+func NewUsageLogMoneyBackfillService(repo UsageLogMoneyBackfillRepository, settingRepo SettingRepository) *UsageLogMoneyBackfillService {
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	return &UsageLogMoneyBackfillService{
+		repo:        repo,
+		settingRepo: settingRepo,
+		batchSize:   usageLogMoneyBackfillBatchSize,
+		batchPause:  usageLogMoneyBackfillBatchPause,
+		instanceID:  uuid.NewString(),
+		bgCtx:       bgCtx,
+		bgCancel:    bgCancel,
+	}
+}
+
+// SetLeaderLock 注入选主用的锁，多实例部署时只有一个实例执行回填。
+func (s *UsageLogMoneyBackfillService) SetLeaderLock(lockCache LeaderLockCache, db *sql.DB) {
+	if s == nil {
+		return
+	}
+	s.lockCache = lockCache
+	s.db = db
+}
+
+// Start 启动后台循环：立即尝试一次，未完成时按间隔重试，完成后退出。
+func (s *UsageLogMoneyBackfillService) Start() {
+	if s == nil || s.repo == nil || s.settingRepo == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.loop()
+	})
+}
+
+// Stop 停止后台循环并等待进行中的批次结束；进度已逐批持久化，下次启动时继续。
+func (s *UsageLogMoneyBackfillService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		s.bgCancel()
+		s.wg.Wait()
+	})
+}
+
+func (s *UsageLogMoneyBackfillService) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(usageLogMoneyBackfillInterval)
+	defer ticker.Stop()
+	for {
+		if s.runWithLeaderLock() {
+			return
+		}
+		select {
+		case <-s.bgCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWithLeaderLock 执行一轮回填，返回是否已全部完成。
+func (s *UsageLogMoneyBackfillService) runWithLeaderLock() bool {
+	ctx, cancel := context.WithTimeout(s.bgCtx, usageLogMoneyBackfillPassTimeout)
+	defer cancel()
+
+	release, ok := tryAcquireSingletonLeaderLock(ctx, s.lockCache, s.db, usageLogMoneyBackfillLeaderLockKey, s.instanceID, usageLogMoneyBackfillLeaderLockTTL)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	done, err := s.Run(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		slog.Warn("[UsageLogMoneyBackfill] pass interrupted; will resume from the saved cursor", "error", err)
+	}
+	return done
+}
+
+// Run 从已保存的进度开始分批回填，直到扫描完毕或 ctx 结束；返回是否已全部完成。
+func (s *UsageLogMoneyBackfillService) Run(ctx context.Context) (bool, error) {
+	afterID, done, err := s.loadCursor(ctx)
+	if err != nil || done {
+		return done, err
+	}
+	var updated int
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		batch, err := s.repo.RoundActualCostBatch(ctx, afterID, s.batchSize)
+		if err != nil {
+			return false, err
+		}
+		updated += batch.Updated
+		if batch.LastID == 0 || batch.Scanned < s.batchSize {
+			if err := s.settingRepo.Set(ctx, SettingKeyUsageLogMoneyBackfillCursor, usageLogMoneyBackfillDone); err != nil {
+				return false, err
+			}
+			slog.Info("[UsageLogMoneyBackfill] finished", "updated_in_pass", updated)
+			return true, nil
+		}
+		afterID = batch.LastID
+		if err := s.settingRepo.Set(ctx, SettingKeyUsageLogMoneyBackfillCursor, strconv.FormatInt(afterID, 10)); err != nil {
+			return false, err
+		}
+		if s.batchPause > 0 {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-time.After(s.batchPause):
+			}
+		}
+	}
+}
+
+func (s *UsageLogMoneyBackfillService) loadCursor(ctx context.Context) (int64, bool, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyUsageLogMoneyBackfillCursor)
+	if errors.Is(err, ErrSettingNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	if value == usageLogMoneyBackfillDone {
+		return 0, true, nil
+	}
+	cursor, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		// 进度损坏时从头扫描：舍入是幂等的，重复处理只多花时间
+		slog.Warn("[UsageLogMoneyBackfill] invalid cursor, restarting from the beginning", "value", value)
+		return 0, false, nil
+	}
+	return cursor, false, nil
+}
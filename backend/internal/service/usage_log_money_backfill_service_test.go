@@ -0,0 +1,80 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type usageLogMoneyBackfillRepoStub struct {
+	batches  []*UsageLogMoneyBackfillBatch
+	afterIDs []int64
+	err      error
+}
+
+func (r *usageLogMoneyBackfillRepoStub) RoundActualCostBatch(_ context.Context, afterID int64, _ int) (*UsageLogMoneyBackfillBatch, error) {
+	r.afterIDs = append(r.afterIDs, afterID)
+	if len(r.batches) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &UsageLogMoneyBackfillBatch{}, nil
+	}
+	batch := r.batches[0]
+	r.batches = r.batches[1:]
+	return batch, nil
+}
+
+func newUsageLogMoneyBackfillServiceForTest(repo UsageLogMoneyBackfillRepository, settings SettingRepository) *UsageLogMoneyBackfillService {
+	svc := NewUsageLogMoneyBackfillService(repo, settings)
+	svc.batchSize = 2
+	svc.batchPause = 0
+	return svc
+}
+
+func TestUsageLogMoneyBackfillService_RunWalksBatchesAndMarksDone(t *testing.T) {
+	repo := &usageLogMoneyBackfillRepoStub{batches: []*UsageLogMoneyBackfillBatch{
+		{LastID: 10, Scanned: 2, Updated: 2},
+		{LastID: 25, Scanned: 2, Updated: 1},
+		{LastID: 30, Scanned: 1},
+	}}
+	settings := newMockSettingRepo()
+	svc := newUsageLogMoneyBackfillServiceForTest(repo, settings)
+
+	done, err := svc.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Equal(t, []int64{0, 10, 25}, repo.afterIDs)
+	require.Equal(t, usageLogMoneyBackfillDone, settings.data[SettingKeyUsageLogMoneyBackfillCursor])
+
+	// 完成后不再扫描
+	done, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Len(t, repo.afterIDs, 3)
+}
+
+func TestUsageLogMoneyBackfillService_ResumesFromSavedCursor(t *testing.T) {
+	repo := &usageLogMoneyBackfillRepoStub{
+		batches: []*UsageLogMoneyBackfillBatch{{LastID: 10, Scanned: 2, Updated: 2}},
+		err:     errors.New("statement timeout"),
+	}
+	settings := newMockSettingRepo()
+	svc := newUsageLogMoneyBackfillServiceForTest(repo, settings)
+
+	done, err := svc.Run(context.Background())
+	require.Error(t, err)
+	require.False(t, done)
+	require.Equal(t, "10", settings.data[SettingKeyUsageLogMoneyBackfillCursor], "中断前的批次进度已持久化")
+
+	repo.err = nil
+	repo.afterIDs = nil
+	done, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Equal(t, []int64{10}, repo.afterIDs)
+}
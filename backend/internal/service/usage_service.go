@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	dbent "github.com/Wei-Shaw/sub2api/ent"
@@ -124,7 +125,12 @@ func (s *UsageService) Create(ctx context.Context, req CreateUsageLogRequest) (*
 	// 扣除用户余额
 	balanceUpdated := false
 	if inserted && req.ActualCost > 0 {
-		if err := s.userRepo.UpdateBalance(txCtx, req.UserID, -req.ActualCost); err != nil {
+		ledgerCtx := WithBalanceLedgerSource(txCtx, BalanceLedgerSource{
+			Reason:     BalanceLedgerReasonUsage,
+			SourceType: BalanceLedgerSourceUsageLog,
+			SourceID:   strconv.FormatInt(usageLog.ID, 10),
+		})
+		if err := s.userRepo.UpdateBalance(ledgerCtx, req.UserID, -req.ActualCost); err != nil {
 			return nil, fmt.Errorf("update user balance: %w", err)
 		}
 		balanceUpdated = true
@@ -18,6 +18,8 @@ type User struct {
 	AvatarSHA256   string
 	PasswordHash   string
 	Role           string
+	// Balance / FrozenBalance 为 8 位定格金额的读出值；扣减与充值在仓储层以 NUMERIC 完成，
+	// service 层需要运算时经 money 包，不直接做浮点加减。
 	Balance        float64
 	FrozenBalance  float64
 	Concurrency    int
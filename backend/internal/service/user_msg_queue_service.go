@@ -40,13 +40,13 @@ type QueueLockResult struct {
 type UserMessageQueueService struct {
 	cache    UserMsgQueueCache
 	rpmCache RPMCache
-	cfg      *config.UserMessageQueueConfig
+	cfg      *config.Config
 	stopCh   chan struct{} // graceful shutdown
 	stopOnce sync.Once     // 确保 Stop() 并发安全
 }
 
 // NewUserMessageQueueService 创建用户消息串行队列服务
-func NewUserMessageQueueService(cache UserMsgQueueCache, rpmCache RPMCache, cfg *config.UserMessageQueueConfig) *UserMessageQueueService {
+func NewUserMessageQueueService(cache UserMsgQueueCache, rpmCache RPMCache, cfg *config.Config) *UserMessageQueueService {
 	return &UserMessageQueueService{
 		cache:    cache,
 		rpmCache: rpmCache,
@@ -105,6 +105,11 @@ func IsRealUserMessage(parsed *ParsedRequest) bool {
 	return isReal
 }
 
+// queueConfig 返回当前生效的队列配置（锁 TTL 与延迟区间支持热重载）
+func (s *UserMessageQueueService) queueConfig() *config.UserMessageQueueConfig {
+	return &s.cfg.Live().Gateway.UserMessageQueue
+}
+
 // TryAcquire 尝试立即获取串行锁
 func (s *UserMessageQueueService) TryAcquire(ctx context.Context, accountID int64) (*QueueLockResult, error) {
 	if s.cache == nil {
@@ -112,7 +117,7 @@ func (s *UserMessageQueueService) TryAcquire(ctx context.Context, accountID int6
 	}
 
 	requestID := generateUMQRequestID()
-	lockTTL := s.cfg.LockTTLMs
+	lockTTL := s.queueConfig().LockTTLMs
 	if lockTTL <= 0 {
 		lockTTL = 120000
 	}
@@ -202,8 +207,9 @@ func (s *UserMessageQueueService) EnforceDelay(ctx context.Context, accountID in
 // ratio ≥ 0.8 → MaxDelay
 // 返回值包含 ±15% 随机抖动（anti-detection + 避免惊群效应）
 func (s *UserMessageQueueService) CalculateRPMAwareDelay(ctx context.Context, accountID int64, baseRPM int) time.Duration {
-	minDelay := time.Duration(s.cfg.MinDelayMs) * time.Millisecond
-	maxDelay := time.Duration(s.cfg.MaxDelayMs) * time.Millisecond
+	queueCfg := s.queueConfig()
+	minDelay := time.Duration(queueCfg.MinDelayMs) * time.Millisecond
+	maxDelay := time.Duration(queueCfg.MaxDelayMs) * time.Millisecond
 
 	if minDelay <= 0 {
 		minDelay = 200 * time.Millisecond
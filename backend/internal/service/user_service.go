@@ -229,6 +229,7 @@ type UserIdentitySummarySet struct {
 	OIDC     UserIdentitySummary `json:"oidc"`
 	WeChat   UserIdentitySummary `json:"wechat"`
 	DingTalk UserIdentitySummary `json:"dingtalk"`
+	SAML     UserIdentitySummary `json:"saml"`
 }
 
 type StartUserIdentityBindingRequest struct {
@@ -349,6 +350,7 @@ func (s *UserService) GetProfileIdentitySummaries(ctx context.Context, userID in
 		OIDC:     s.buildProviderIdentitySummary("oidc", user, records),
 		WeChat:   s.buildProviderIdentitySummary("wechat", user, records),
 		DingTalk: s.buildProviderIdentitySummary("dingtalk", user, records),
+		SAML:     s.buildProviderIdentitySummary("saml", user, records),
 	}
 
 	s.applyExplicitProviderAvailability(ctx, &summaries)
@@ -794,7 +796,7 @@ func (s *UserService) canUnbindProvider(provider string, user *User, records []U
 		return true
 	}
 
-	for _, candidate := range []string{"linuxdo", "oidc", "wechat", "dingtalk"} {
+	for _, candidate := range []string{"linuxdo", "oidc", "wechat", "dingtalk", "saml"} {
 		if candidate == provider {
 			continue
 		}
@@ -872,6 +874,8 @@ func buildUserIdentityBindAuthorizeURL(provider, redirectTo string) (string, err
 		path = "/api/v1/auth/oauth/wechat/bind/start"
 	case "dingtalk":
 		path = "/api/v1/auth/oauth/dingtalk/bind/start"
+	case "saml":
+		path = "/api/v1/auth/oauth/saml/bind/start"
 	default:
 		return "", ErrIdentityProviderInvalid
 	}
@@ -892,6 +896,8 @@ func normalizeUserIdentityProvider(provider string) string {
 		return "wechat"
 	case "dingtalk":
 		return "dingtalk"
+	case "saml":
+		return "saml"
 	case "email":
 		return "email"
 	default:
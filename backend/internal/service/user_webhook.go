@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// 用户 Webhook 事件类型。事件 payload 的 data 字段随类型变化，
+// 详见各发布点（BalanceNotifyService / BillingCacheService / SubscriptionExpiryService / PaymentService）。
+// budget.threshold 在用户 / Key 预算消费达到 50/80/100% 时发布。
+const (
+	UserWebhookEventBalanceLow           = "balance.low"
+	UserWebhookEventAPIKeyQuotaExhausted = "api_key.quota_exhausted"
+	UserWebhookEventAPIKeyRateLimited    = "api_key.rate_limited"
+	UserWebhookEventAPIKeyDisabled       = "api_key.disabled"
+	UserWebhookEventSubscriptionExpiring = "subscription.expiring"
+	UserWebhookEventPaymentCompleted     = "payment.completed"
+	UserWebhookEventBudgetThreshold      = "budget.threshold"
+	UserWebhookEventPing                 = "webhook.ping"
+)
+
+// 投递状态：pending 等待（重试）投递，succeeded / failed 为终态。
+const (
+	UserWebhookDeliveryStatusPending   = "pending"
+	UserWebhookDeliveryStatusSucceeded = "succeeded"
+	UserWebhookDeliveryStatusFailed    = "failed"
+)
+
+const (
+	userWebhookSignatureHeader            = "X-Sub2API-Signature"
+	userWebhookEventHeader                = "X-Sub2API-Event"
+	userWebhookDeliveryHeader             = "X-Sub2API-Delivery"
+	userWebhookTimestampHeader            = "X-Sub2API-Timestamp"
+	userWebhookSecretPrefix               = "whsec_"
+	userWebhookAPIVersion                 = "2026-10-01"
+	userWebhookMaxStoredResponseBodyBytes = 2048
+)
+
+// UserWebhookEventTypes 用户可订阅的事件（webhook.ping 仅由“发送测试”触发，不可订阅）。
+var UserWebhookEventTypes = []string{
+	UserWebhookEventBalanceLow,
+	UserWebhookEventAPIKeyQuotaExhausted,
+	UserWebhookEventAPIKeyRateLimited,
+	UserWebhookEventAPIKeyDisabled,
+	UserWebhookEventSubscriptionExpiring,
+	UserWebhookEventPaymentCompleted,
+	UserWebhookEventBudgetThreshold,
+}
+
+var (
+	ErrUserWebhookDisabled         = infraerrors.Forbidden("USER_WEBHOOK_DISABLED", "webhooks are disabled")
+	ErrUserWebhookNotFound         = infraerrors.NotFound("USER_WEBHOOK_NOT_FOUND", "webhook endpoint not found")
+	ErrUserWebhookDeliveryNotFound = infraerrors.NotFound("USER_WEBHOOK_DELIVERY_NOT_FOUND", "webhook delivery not found")
+	ErrUserWebhookLimitReached     = infraerrors.BadRequest("USER_WEBHOOK_LIMIT_REACHED", "webhook endpoint limit reached")
+	ErrUserWebhookInvalidURL       = infraerrors.BadRequest("USER_WEBHOOK_INVALID_URL", "invalid webhook url")
+	ErrUserWebhookInvalidEvents    = infraerrors.BadRequest("USER_WEBHOOK_INVALID_EVENTS", "invalid webhook events")
+)
+
+// UserWebhookEndpoint 用户配置的投递目标。Secret 仅在创建/轮换时以明文返回一次。
+type UserWebhookEndpoint struct {
+	ID              int64
+	UserID          int64
+	Name            string
+	URL             string
+	SecretEncrypted string
+	Events          []string
+	Enabled         bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Subscribes 判断 endpoint 是否订阅了事件类型。
+func (e *UserWebhookEndpoint) Subscribes(eventType string) bool {
+	if e == nil {
+		return false
+	}
+	for _, ev := range e.Events {
+		if ev == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// UserWebhookDelivery 一次事件对一个 endpoint 的投递记录（同时也是 outbox 行）。
+type UserWebhookDelivery struct {
+	ID                 int64
+	EndpointID         int64
+	UserID             int64
+	EventID            string
+	EventType          string
+	Payload            json.RawMessage
+	Status             string
+	Attempts           int
+	NextAttemptAt      time.Time
+	LastResponseStatus *int
+	LastResponseBody   string
+	LastError          string
+	LastDurationMs     *int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeliveredAt        *time.Time
+}
+
+// UserWebhookDeliveryTask 被 dispatcher claim 的待投递任务，附带目标 URL 与加密 secret。
+type UserWebhookDeliveryTask struct {
+	UserWebhookDelivery
+	URL             string
+	SecretEncrypted string
+}
+
+// UserWebhookAttemptResult 单次投递尝试的结果，写回 delivery 行。
+type UserWebhookAttemptResult struct {
+	ResponseStatus *int
+	ResponseBody   string
+	Error          string
+	DurationMs     int
+}
+
+// UserWebhookDeliveryFilter 投递日志查询条件。
+type UserWebhookDeliveryFilter struct {
+	EndpointID int64
+	EventType  string
+	Status     string
+}
+
+// UserWebhookEvent 发布到 outbox 的事件。ID 同时作为去重键：
+// 同一 endpoint 对同一 ID 只会生成一条投递。
+type UserWebhookEvent struct {
+	ID         string
+	Type       string
+	UserID     int64
+	OccurredAt time.Time
+	Data       map[string]any
+}
+
+// UserWebhookOutboxEvent 已序列化的事件。由发起业务变更的仓储在同一数据库事务内写入 outbox，
+// 业务回滚时事件随之丢弃，提交成功则事件必定可投递。
+type UserWebhookOutboxEvent struct {
+	Event   UserWebhookEvent
+	Payload []byte
+}
+
+// userWebhookPayload 投递给用户的 JSON 结构。
+type userWebhookPayload struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	CreatedAt  string         `json:"created_at"`
+	UserID     int64          `json:"user_id"`
+	Data       map[string]any `json:"data"`
+	APIVersion string         `json:"api_version"`
+}
+
+// UserWebhookRepository 用户 Webhook 的持久化。
+type UserWebhookRepository interface {
+	ListEndpoints(ctx context.Context, userID int64) ([]UserWebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, userID, id int64) (*UserWebhookEndpoint, error)
+	CountEndpoints(ctx context.Context, userID int64) (int, error)
+	CreateEndpoint(ctx context.Context, endpoint *UserWebhookEndpoint) error
+	UpdateEndpoint(ctx context.Context, endpoint *UserWebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, userID, id int64) error
+
+	// Enqueue 为用户所有启用且订阅了该事件的 endpoint 写入投递行；
+	// endpointID > 0 时只投递给该 endpoint（忽略订阅过滤，用于测试投递）。
+	// 返回实际新写入的行数（命中去重的不计）。
+	Enqueue(ctx context.Context, event UserWebhookEvent, payload []byte, endpointID int64) (int64, error)
+	ListDeliveries(ctx context.Context, userID int64, filter UserWebhookDeliveryFilter, params pagination.PaginationParams) ([]UserWebhookDelivery, *pagination.PaginationResult, error)
+	GetDelivery(ctx context.Context, userID, id int64) (*UserWebhookDelivery, error)
+	// RequeueDelivery 把已结束的投递重新置为 pending 并立即可投递（手动重发）。
+	RequeueDelivery(ctx context.Context, userID, id int64) error
+
+	ClaimDeliveries(ctx context.Context, workerID string, limit int, lease time.Duration) ([]UserWebhookDeliveryTask, error)
+	CompleteDelivery(ctx context.Context, id int64, workerID string, result UserWebhookAttemptResult) error
+	RetryDelivery(ctx context.Context, id int64, workerID string, nextAttemptAt time.Time, result UserWebhookAttemptResult) error
+	FailDelivery(ctx context.Context, id int64, workerID string, result UserWebhookAttemptResult) error
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/google/uuid"
+)
+
+const (
+	userWebhookBatchSize        = 50
+	userWebhookPollInterval     = time.Second
+	userWebhookLeaseMargin      = 30 * time.Second
+	userWebhookAckTimeout       = 2 * time.Second
+	userWebhookRetryBaseDelay   = 30 * time.Second
+	userWebhookRetryMaxDelay    = 6 * time.Hour
+	userWebhookCleanupInterval  = time.Hour
+	userWebhookCleanupBatchSize = 5000
+	userWebhookUserAgent        = "Sub2API-Webhook/1.0"
+)
+
+// UserWebhookDispatcher 从 user_webhook_deliveries outbox 领取到期投递并发送 HTTP 请求。
+// 多实例部署时通过 FOR UPDATE SKIP LOCKED + lease 保证同一投递不会被并发发送；
+// 进程崩溃后 lease 过期即可被其他实例重新领取（至少一次语义，接收方应按 X-Sub2API-Delivery 去重）。
+type UserWebhookDispatcher struct {
+	repo      UserWebhookRepository
+	encryptor SecretEncryptor
+	cfg       *config.Config
+	client    *http.Client
+	workerID  string
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	lastCleanup time.Time
+}
+
+// NewUserWebhookDispatcher creates a dispatcher. The HTTP client refuses
+// private/loopback targets at dial time unless security.url_allowlist.allow_private_hosts is set.
+func NewUserWebhookDispatcher(repo UserWebhookRepository, encryptor SecretEncryptor, cfg *config.Config) *UserWebhookDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UserWebhookDispatcher{
+		repo:      repo,
+		encryptor: encryptor,
+		cfg:       cfg,
+		client:    newUserWebhookHTTPClient(cfg),
+		workerID:  uuid.NewString(),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+func newUserWebhookHTTPClient(cfg *config.Config) *http.Client {
+	timeout := 10 * time.Second
+	allowPrivate := false
+	if cfg != nil {
+		if cfg.UserWebhook.RequestTimeoutSeconds > 0 {
+			timeout = time.Duration(cfg.UserWebhook.RequestTimeoutSeconds) * time.Second
+		}
+		allowPrivate = cfg.Security.URLAllowlist.AllowPrivateHosts
+	}
+	transport := &http.Transport{
+		MaxIdleConns:          64,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+	if allowPrivate {
+		transport.DialContext = (&net.Dialer{Timeout: monitorDialTimeout, KeepAlive: monitorDialKeepAlive}).DialContext
+	} else {
+		transport.DialContext = safeDialContext
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// 不跟随重定向：302 到内网地址是常见的 SSRF 绕过手法，且签名只对原始 URL 有意义。
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (d *UserWebhookDispatcher) maxAttempts() int {
+	if d.cfg == nil || d.cfg.UserWebhook.MaxAttempts <= 0 {
+		return 8
+	}
+	return d.cfg.UserWebhook.MaxAttempts
+}
+
+func (d *UserWebhookDispatcher) concurrency() int {
+	if d.cfg == nil || d.cfg.UserWebhook.WorkerConcurrency <= 0 {
+		return 8
+	}
+	return d.cfg.UserWebhook.WorkerConcurrency
+}
+
+func (d *UserWebhookDispatcher) lease() time.Duration {
+	return d.client.Timeout + userWebhookLeaseMargin
+}
+
+// Start launches the background loop. It is a no-op when the feature is disabled.
+func (d *UserWebhookDispatcher) Start() {
+	if d == nil || d.repo == nil || (d.cfg != nil && !d.cfg.UserWebhook.Enabled) {
+		return
+	}
+	d.startOnce.Do(func() {
+		d.wg.Add(1)
+		go d.run()
+	})
+}
+
+// Stop cancels in-flight deliveries and waits for the loop to exit. Deliveries
+// interrupted mid-request are retried after their lease expires.
+func (d *UserWebhookDispatcher) Stop() {
+	if d == nil {
+		return
+	}
+	d.stopOnce.Do(func() {
+		d.cancel()
+		d.wg.Wait()
+	})
+}
+
+func (d *UserWebhookDispatcher) run() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(userWebhookPollInterval)
+	defer ticker.Stop()
+	for {
+		if err := d.processBatch(d.ctx); err != nil && d.ctx.Err() == nil {
+			slog.Warn("user webhook dispatcher batch failed", "error", err)
+		}
+		d.cleanupIfDue(d.ctx)
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *UserWebhookDispatcher) processBatch(ctx context.Context) error {
+	tasks, err := d.repo.ClaimDeliveries(ctx, d.workerID, userWebhookBatchSize, d.lease())
+	if err != nil {
+		return fmt.Errorf("claim user webhook deliveries: %w", err)
+	}
+	semaphore := make(chan struct{}, d.concurrency())
+	var wg sync.WaitGroup
+	for i := range tasks {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case semaphore <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(task UserWebhookDeliveryTask) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			d.deliver(ctx, task)
+		}(tasks[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+func (d *UserWebhookDispatcher) deliver(ctx context.Context, task UserWebhookDeliveryTask) {
+	result := d.send(ctx, task)
+	if ctx.Err() != nil && result.ResponseStatus == nil {
+		// 关停中断：不计入尝试次数，lease 过期后由其他实例（或重启后的本实例）重新投递。
+		return
+	}
+
+	ackCtx, cancel := context.WithTimeout(context.Background(), userWebhookAckTimeout)
+	defer cancel()
+	var err error
+	switch {
+	case result.ResponseStatus != nil && *result.ResponseStatus >= 200 && *result.ResponseStatus < 300:
+		err = d.repo.CompleteDelivery(ackCtx, task.ID, d.workerID, result)
+	case task.Attempts+1 >= d.maxAttempts():
+		err = d.repo.FailDelivery(ackCtx, task.ID, d.workerID, result)
+	default:
+		nextAt := time.Now().UTC().Add(userWebhookRetryDelay(task.Attempts + 1))
+		err = d.repo.RetryDelivery(ackCtx, task.ID, d.workerID, nextAt, result)
+	}
+	if err != nil {
+		slog.Warn("user webhook delivery ack failed", "delivery_id", task.ID, "error", err)
+	}
+}
+
+// send performs a single HTTP attempt. It never returns an error: failures are
+// captured in the result so they show up in the user's delivery log.
+func (d *UserWebhookDispatcher) send(ctx context.Context, task UserWebhookDeliveryTask) UserWebhookAttemptResult {
+	var result UserWebhookAttemptResult
+	secret := ""
+	if d.encryptor != nil && task.SecretEncrypted != "" {
+		plain, err := d.encryptor.Decrypt(task.SecretEncrypted)
+		if err != nil {
+			result.Error = "decrypt signing secret failed"
+			return result
+		}
+		secret = plain
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, task.URL, bytes.NewReader(task.Payload))
+	if err != nil {
+		result.Error = boundedUserWebhookText(err.Error(), 512)
+		return result
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userWebhookUserAgent)
+	req.Header.Set(userWebhookEventHeader, task.EventType)
+	req.Header.Set(userWebhookDeliveryHeader, task.EventID)
+	req.Header.Set(userWebhookTimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(userWebhookSignatureHeader, SignUserWebhookPayload(secret, timestamp, task.Payload))
+	}
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	result.DurationMs = int(time.Since(started).Milliseconds())
+	if err != nil {
+		result.Error = boundedUserWebhookText(err.Error(), 512)
+		return result
+	}
+	defer func() { _ = resp.Body.Close() }()
+	status := resp.StatusCode
+	result.ResponseStatus = &status
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, userWebhookMaxStoredResponseBodyBytes))
+	result.ResponseBody = string(bytes.ToValidUTF8(body, nil))
+	if status < 200 || status >= 300 {
+		result.Error = fmt.Sprintf("unexpected status %d", status)
+	}
+	return result
+}
+
+func (d *UserWebhookDispatcher) cleanupIfDue(ctx context.Context) {
+	if d.cfg == nil || d.cfg.UserWebhook.DeliveryRetentionDays <= 0 {
+		return
+	}
+	now := time.Now()
+	if !d.lastCleanup.IsZero() && now.Sub(d.lastCleanup) < userWebhookCleanupInterval {
+		return
+	}
+	d.lastCleanup = now
+	before := now.AddDate(0, 0, -d.cfg.UserWebhook.DeliveryRetentionDays)
+	deleted, err := d.repo.DeleteDeliveriesBefore(ctx, before, userWebhookCleanupBatchSize)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		slog.Warn("user webhook delivery cleanup failed", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Info("user webhook deliveries cleaned up", "deleted", deleted, "before", before)
+	}
+}
+
+// SignUserWebhookPayload 计算签名头：t=<unix 秒>,v1=<hex(HMAC-SHA256(secret, "<t>.<body>"))>。
+// 接收方应校验时间戳偏差（建议 5 分钟内）以防重放。
+func SignUserWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// userWebhookRetryDelay 指数退避：30s、1m、2m … 上限 6h，带 ±20% 抖动。
+func userWebhookRetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := userWebhookRetryBaseDelay
+	for i := 1; i < attempt && delay < userWebhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > userWebhookRetryMaxDelay {
+		delay = userWebhookRetryMaxDelay
+	}
+	return time.Duration(float64(delay) * (0.8 + rand.Float64()*0.4))
+}
+
+func boundedUserWebhookText(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return string(bytes.ToValidUTF8([]byte(s[:limit]), nil))
+}
+
+// ProvideUserWebhookDispatcher creates and starts the dispatcher.
+func ProvideUserWebhookDispatcher(repo UserWebhookRepository, encryptor SecretEncryptor, cfg *config.Config) *UserWebhookDispatcher {
+	d := NewUserWebhookDispatcher(repo, encryptor, cfg)
+	d.Start()
+	return d
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"github.com/google/uuid"
+)
+
+const (
+	userWebhookPublishTimeout   = 5 * time.Second
+	userWebhookRecentEventTTL   = 10 * time.Minute
+	userWebhookRecentEventLimit = 10000
+	userWebhookMaxNameLength    = 100
+)
+
+// CreateUserWebhookEndpointInput 创建 endpoint 的参数。
+type CreateUserWebhookEndpointInput struct {
+	Name    string
+	URL     string
+	Events  []string
+	Enabled *bool
+}
+
+// UpdateUserWebhookEndpointInput 更新 endpoint 的参数，nil 字段保持不变。
+type UpdateUserWebhookEndpointInput struct {
+	Name    *string
+	URL     *string
+	Events  []string
+	Enabled *bool
+}
+
+// UserWebhookService 管理用户 Webhook endpoint，并把业务事件写入持久化 outbox。
+// 实际 HTTP 投递由 UserWebhookDispatcher 异步完成。
+type UserWebhookService struct {
+	repo      UserWebhookRepository
+	encryptor SecretEncryptor
+	cfg       *config.Config
+
+	// recent 进程内短期去重：限流拒绝等高频事件在同一窗口内会被反复触发，
+	// 先在内存挡掉，避免每次都打一条 INSERT ... ON CONFLICT DO NOTHING。
+	recentMu sync.Mutex
+	recent   map[string]time.Time
+}
+
+// NewUserWebhookService creates a UserWebhookService.
+func NewUserWebhookService(repo UserWebhookRepository, encryptor SecretEncryptor, cfg *config.Config) *UserWebhookService {
+	return &UserWebhookService{
+		repo:      repo,
+		encryptor: encryptor,
+		cfg:       cfg,
+		recent:    make(map[string]time.Time),
+	}
+}
+
+// Enabled 返回功能开关（配置未注入视为启用）。
+func (s *UserWebhookService) Enabled() bool {
+	if s == nil || s.repo == nil {
+		return false
+	}
+	return s.cfg == nil || s.cfg.UserWebhook.Enabled
+}
+
+func (s *UserWebhookService) maxEndpointsPerUser() int {
+	if s.cfg == nil || s.cfg.UserWebhook.MaxEndpointsPerUser <= 0 {
+		return 10
+	}
+	return s.cfg.UserWebhook.MaxEndpointsPerUser
+}
+
+// ListEndpoints 列出用户的 endpoint。
+func (s *UserWebhookService) ListEndpoints(ctx context.Context, userID int64) ([]UserWebhookEndpoint, error) {
+	if !s.Enabled() {
+		return nil, ErrUserWebhookDisabled
+	}
+	return s.repo.ListEndpoints(ctx, userID)
+}
+
+// CreateEndpoint 创建 endpoint，返回的 secret 只在此处以明文出现一次。
+func (s *UserWebhookService) CreateEndpoint(ctx context.Context, userID int64, input CreateUserWebhookEndpointInput) (*UserWebhookEndpoint, string, error) {
+	if !s.Enabled() {
+		return nil, "", ErrUserWebhookDisabled
+	}
+	targetURL, err := s.validateURL(input.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	events, err := normalizeUserWebhookEvents(input.Events)
+	if err != nil {
+		return nil, "", err
+	}
+	count, err := s.repo.CountEndpoints(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= s.maxEndpointsPerUser() {
+		return nil, "", ErrUserWebhookLimitReached
+	}
+	secret, encrypted, err := s.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	endpoint := &UserWebhookEndpoint{
+		UserID:          userID,
+		Name:            normalizeUserWebhookName(input.Name),
+		URL:             targetURL,
+		SecretEncrypted: encrypted,
+		Events:          events,
+		Enabled:         input.Enabled == nil || *input.Enabled,
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, "", err
+	}
+	return endpoint, secret, nil
+}
+
+// UpdateEndpoint 更新 endpoint 名称、URL、订阅事件或启用状态。
+func (s *UserWebhookService) UpdateEndpoint(ctx context.Context, userID, id int64, input UpdateUserWebhookEndpointInput) (*UserWebhookEndpoint, error) {
+	if !s.Enabled() {
+		return nil, ErrUserWebhookDisabled
+	}
+	endpoint, err := s.repo.GetEndpoint(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		endpoint.Name = normalizeUserWebhookName(*input.Name)
+	}
+	if input.URL != nil {
+		targetURL, err := s.validateURL(*input.URL)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.URL = targetURL
+	}
+	if input.Events != nil {
+		events, err := normalizeUserWebhookEvents(input.Events)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Events = events
+	}
+	if input.Enabled != nil {
+		endpoint.Enabled = *input.Enabled
+	}
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// RotateSecret 生成新的签名密钥；旧密钥立即失效（包括尚未投递的重试）。
+func (s *UserWebhookService) RotateSecret(ctx context.Context, userID, id int64) (*UserWebhookEndpoint, string, error) {
+	if !s.Enabled() {
+		return nil, "", ErrUserWebhookDisabled
+	}
+	endpoint, err := s.repo.GetEndpoint(ctx, userID, id)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, encrypted, err := s.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	endpoint.SecretEncrypted = encrypted
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, "", err
+	}
+	return endpoint, secret, nil
+}
+
+// DeleteEndpoint 删除 endpoint，其未完成的投递不再发送。
+func (s *UserWebhookService) DeleteEndpoint(ctx context.Context, userID, id int64) error {
+	if !s.Enabled() {
+		return ErrUserWebhookDisabled
+	}
+	return s.repo.DeleteEndpoint(ctx, userID, id)
+}
+
+// SendTest 向指定 endpoint 投递一条 webhook.ping，无论其订阅了哪些事件。
+func (s *UserWebhookService) SendTest(ctx context.Context, userID, id int64) error {
+	if !s.Enabled() {
+		return ErrUserWebhookDisabled
+	}
+	if _, err := s.repo.GetEndpoint(ctx, userID, id); err != nil {
+		return err
+	}
+	event := UserWebhookEvent{
+		ID:         "evt_ping_" + uuid.NewString(),
+		Type:       UserWebhookEventPing,
+		UserID:     userID,
+		OccurredAt: time.Now(),
+		Data:       map[string]any{"endpoint_id": id},
+	}
+	payload, err := buildUserWebhookPayload(event)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.Enqueue(ctx, event, payload, id)
+	return err
+}
+
+// ListDeliveries 分页查询用户的投递日志。
+func (s *UserWebhookService) ListDeliveries(ctx context.Context, userID int64, filter UserWebhookDeliveryFilter, params pagination.PaginationParams) ([]UserWebhookDelivery, *pagination.PaginationResult, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrUserWebhookDisabled
+	}
+	return s.repo.ListDeliveries(ctx, userID, filter, params)
+}
+
+// GetDelivery 查询单条投递详情（含 payload 与最近一次响应）。
+func (s *UserWebhookService) GetDelivery(ctx context.Context, userID, id int64) (*UserWebhookDelivery, error) {
+	if !s.Enabled() {
+		return nil, ErrUserWebhookDisabled
+	}
+	return s.repo.GetDelivery(ctx, userID, id)
+}
+
+// Redeliver 重新投递一条已结束或仍在等待的投递，重置尝试次数。
+func (s *UserWebhookService) Redeliver(ctx context.Context, userID, id int64) error {
+	if !s.Enabled() {
+		return ErrUserWebhookDisabled
+	}
+	return s.repo.RequeueDelivery(ctx, userID, id)
+}
+
+// Publish 异步把事件写入 outbox，调用方位于计费/限流等热路径，不阻塞也不返回错误。
+// 仅用于没有对应数据库变更的派生事件（限流拒绝、余额跌破阈值、到期提醒等）；
+// 由业务状态变更产生的事件应通过 PublishTx 或 PrepareEvent 与变更在同一事务内写入。
+// 事件 ID 为空时自动生成；相同 ID 的事件在每个 endpoint 上只投递一次。
+func (s *UserWebhookService) Publish(event UserWebhookEvent) {
+	if !s.Enabled() || event.UserID <= 0 || strings.TrimSpace(event.Type) == "" {
+		return
+	}
+	event = normalizeUserWebhookEvent(event)
+	if !s.markRecent(event.ID, time.Now()) {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in user webhook publish", "recover", r)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), userWebhookPublishTimeout)
+		defer cancel()
+		if err := s.publish(ctx, event); err != nil {
+			slog.Warn("user webhook publish failed",
+				"user_id", event.UserID, "event_type", event.Type, "event_id", event.ID, "error", err)
+		}
+	}()
+}
+
+// PublishTx 同步写入 outbox。ctx 携带 dbent.Tx 时与调用方的业务变更处于同一事务：
+// 事务回滚则事件不会投递，提交后事件一定会投递。功能关闭或事件无效时直接返回 nil。
+func (s *UserWebhookService) PublishTx(ctx context.Context, event UserWebhookEvent) error {
+	if !s.Enabled() || event.UserID <= 0 || strings.TrimSpace(event.Type) == "" {
+		return nil
+	}
+	return s.publish(ctx, normalizeUserWebhookEvent(event))
+}
+
+// PrepareEvent 序列化事件，供使用 *sql.Tx 直接执行业务变更的仓储在自己的事务内写入 outbox。
+// 功能关闭或事件无效时返回 false。
+func (s *UserWebhookService) PrepareEvent(event UserWebhookEvent) (UserWebhookOutboxEvent, bool) {
+	if !s.Enabled() || event.UserID <= 0 || strings.TrimSpace(event.Type) == "" {
+		return UserWebhookOutboxEvent{}, false
+	}
+	event = normalizeUserWebhookEvent(event)
+	payload, err := buildUserWebhookPayload(event)
+	if err != nil {
+		slog.Warn("user webhook event encode failed", "event_type", event.Type, "event_id", event.ID, "error", err)
+		return UserWebhookOutboxEvent{}, false
+	}
+	return UserWebhookOutboxEvent{Event: event, Payload: payload}, true
+}
+
+func (s *UserWebhookService) publish(ctx context.Context, event UserWebhookEvent) error {
+	payload, err := buildUserWebhookPayload(event)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.Enqueue(ctx, event, payload, 0)
+	return err
+}
+
+func normalizeUserWebhookEvent(event UserWebhookEvent) UserWebhookEvent {
+	if event.ID == "" {
+		event.ID = "evt_" + uuid.NewString()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	return event
+}
+
+// markRecent 返回 false 表示该事件 ID 在 TTL 内已发布过。
+func (s *UserWebhookService) markRecent(eventID string, now time.Time) bool {
+	s.recentMu.Lock()
+	defer s.recentMu.Unlock()
+	if at, ok := s.recent[eventID]; ok && now.Sub(at) < userWebhookRecentEventTTL {
+		return false
+	}
+	if len(s.recent) >= userWebhookRecentEventLimit {
+		for id, at := range s.recent {
+			if now.Sub(at) >= userWebhookRecentEventTTL {
+				delete(s.recent, id)
+			}
+		}
+		// 仍然满载说明短时间内事件量异常，直接清空，由数据库唯一索引兜底去重。
+		if len(s.recent) >= userWebhookRecentEventLimit {
+			s.recent = make(map[string]time.Time)
+		}
+	}
+	s.recent[eventID] = now
+	return true
+}
+
+func (s *UserWebhookService) validateURL(raw string) (string, error) {
+	allowInsecure, allowPrivate := false, false
+	if s.cfg != nil {
+		allowInsecure = s.cfg.Security.URLAllowlist.AllowInsecureHTTP
+		allowPrivate = s.cfg.Security.URLAllowlist.AllowPrivateHosts
+	}
+	normalized, err := urlvalidator.ValidateHTTPURL(raw, allowInsecure, urlvalidator.ValidationOptions{AllowPrivate: allowPrivate})
+	if err != nil {
+		return "", ErrUserWebhookInvalidURL.WithCause(err)
+	}
+	if !allowPrivate {
+		// 保存时先拦截私网/元数据地址给用户即时反馈；投递时 safeDialContext 仍会再校验一次（防 DNS rebinding）。
+		ctx, cancel := context.WithTimeout(context.Background(), monitorEndpointResolveTimeout)
+		defer cancel()
+		parsed, err := url.Parse(normalized)
+		if err != nil {
+			return "", ErrUserWebhookInvalidURL.WithCause(err)
+		}
+		host := parsed.Hostname()
+		blocked, rerr := isPrivateOrLoopbackHost(ctx, host)
+		if rerr != nil {
+			return "", ErrUserWebhookInvalidURL.WithCause(fmt.Errorf("resolve host: %w", rerr))
+		}
+		if blocked {
+			return "", ErrUserWebhookInvalidURL.WithCause(fmt.Errorf("host is not allowed: %s", host))
+		}
+	}
+	return normalized, nil
+}
+
+func (s *UserWebhookService) newSecret() (plain string, encrypted string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	plain = userWebhookSecretPrefix + hex.EncodeToString(buf)
+	if s.encryptor == nil {
+		return "", "", fmt.Errorf("webhook secret encryptor is not configured")
+	}
+	encrypted, err = s.encryptor.Encrypt(plain)
+	if err != nil {
+		return "", "", fmt.Errorf("encrypt webhook secret: %w", err)
+	}
+	return plain, encrypted, nil
+}
+
+func normalizeUserWebhookName(name string) string {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > userWebhookMaxNameLength {
+		name = string([]rune(name)[:userWebhookMaxNameLength])
+	}
+	return name
+}
+
+func normalizeUserWebhookEvents(events []string) ([]string, error) {
+	allowed := make(map[string]struct{}, len(UserWebhookEventTypes))
+	for _, ev := range UserWebhookEventTypes {
+		allowed[ev] = struct{}{}
+	}
+	seen := make(map[string]struct{}, len(events))
+	out := make([]string, 0, len(events))
+	for _, ev := range events {
+		ev = strings.ToLower(strings.TrimSpace(ev))
+		if ev == "" {
+			continue
+		}
+		if _, ok := allowed[ev]; !ok {
+			return nil, ErrUserWebhookInvalidEvents.WithCause(fmt.Errorf("unknown event: %s", ev))
+		}
+		if _, dup := seen[ev]; dup {
+			continue
+		}
+		seen[ev] = struct{}{}
+		out = append(out, ev)
+	}
+	if len(out) == 0 {
+		return nil, ErrUserWebhookInvalidEvents
+	}
+	return out, nil
+}
+
+func buildUserWebhookPayload(event UserWebhookEvent) ([]byte, error) {
+	data := event.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+	return json.Marshal(userWebhookPayload{
+		ID:         event.ID,
+		Type:       event.Type,
+		CreatedAt:  event.OccurredAt.UTC().Format(time.RFC3339),
+		UserID:     event.UserID,
+		Data:       data,
+		APIVersion: userWebhookAPIVersion,
+	})
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type userWebhookRepoStub struct {
+	UserWebhookRepository
+
+	mu        sync.Mutex
+	endpoints []UserWebhookEndpoint
+	enqueued  []UserWebhookEvent
+	completed []UserWebhookAttemptResult
+	retried   []time.Time
+	failed    []UserWebhookAttemptResult
+}
+
+func (r *userWebhookRepoStub) CountEndpoints(context.Context, int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.endpoints), nil
+}
+
+func (r *userWebhookRepoStub) CreateEndpoint(_ context.Context, endpoint *UserWebhookEndpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	endpoint.ID = int64(len(r.endpoints) + 1)
+	r.endpoints = append(r.endpoints, *endpoint)
+	return nil
+}
+
+func (r *userWebhookRepoStub) Enqueue(_ context.Context, event UserWebhookEvent, _ []byte, _ int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enqueued = append(r.enqueued, event)
+	return 1, nil
+}
+
+func (r *userWebhookRepoStub) CompleteDelivery(_ context.Context, _ int64, _ string, result UserWebhookAttemptResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed = append(r.completed, result)
+	return nil
+}
+
+func (r *userWebhookRepoStub) RetryDelivery(_ context.Context, _ int64, _ string, nextAttemptAt time.Time, _ UserWebhookAttemptResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retried = append(r.retried, nextAttemptAt)
+	return nil
+}
+
+func (r *userWebhookRepoStub) FailDelivery(_ context.Context, _ int64, _ string, result UserWebhookAttemptResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed = append(r.failed, result)
+	return nil
+}
+
+func (r *userWebhookRepoStub) enqueuedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.enqueued)
+}
+
+type userWebhookTestEncryptor struct{}
+
+func (userWebhookTestEncryptor) Encrypt(plaintext string) (string, error) {
+	return "enc:" + plaintext, nil
+}
+
+func (userWebhookTestEncryptor) Decrypt(ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, "enc:") {
+		return "", errors.New("not encrypted")
+	}
+	return strings.TrimPrefix(ciphertext, "enc:"), nil
+}
+
+func newUserWebhookTestConfig(allowPrivate bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.UserWebhook = config.UserWebhookConfig{
+		Enabled:               true,
+		MaxEndpointsPerUser:   2,
+		MaxAttempts:           3,
+		RequestTimeoutSeconds: 5,
+		WorkerConcurrency:     2,
+	}
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = allowPrivate
+	cfg.Security.URLAllowlist.AllowPrivateHosts = allowPrivate
+	return cfg
+}
+
+func TestUserWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	const secret = "whsec_test"
+	var (
+		gotBody      []byte
+		gotSignature string
+		gotHeaders   http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(userWebhookSignatureHeader)
+		gotHeaders = r.Header.Clone()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	repo := &userWebhookRepoStub{}
+	d := NewUserWebhookDispatcher(repo, userWebhookTestEncryptor{}, newUserWebhookTestConfig(true))
+	payload, err := buildUserWebhookPayload(UserWebhookEvent{
+		ID: "evt_1", Type: UserWebhookEventBalanceLow, UserID: 7, OccurredAt: time.Now(),
+		Data: map[string]any{"balance": 1.5},
+	})
+	require.NoError(t, err)
+
+	d.deliver(context.Background(), UserWebhookDeliveryTask{
+		UserWebhookDelivery: UserWebhookDelivery{ID: 1, EventID: "evt_1", EventType: UserWebhookEventBalanceLow, Payload: payload},
+		URL:                 server.URL,
+		SecretEncrypted:     "enc:" + secret,
+	})
+
+	require.Len(t, repo.completed, 1)
+	require.Equal(t, http.StatusOK, *repo.completed[0].ResponseStatus)
+	require.Equal(t, "ok", repo.completed[0].ResponseBody)
+	require.JSONEq(t, string(payload), string(gotBody))
+	require.Equal(t, UserWebhookEventBalanceLow, gotHeaders.Get(userWebhookEventHeader))
+	require.Equal(t, "evt_1", gotHeaders.Get(userWebhookDeliveryHeader))
+	timestamp := gotHeaders.Get(userWebhookTimestampHeader)
+	require.NotEmpty(t, timestamp)
+	require.Equal(t, SignUserWebhookPayload(secret, timestamp, gotBody), gotSignature)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(gotBody, &decoded))
+	require.Equal(t, userWebhookAPIVersion, decoded["api_version"])
+}
+
+func TestUserWebhookDispatcher_RetriesThenFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := &userWebhookRepoStub{}
+	d := NewUserWebhookDispatcher(repo, userWebhookTestEncryptor{}, newUserWebhookTestConfig(true))
+	task := UserWebhookDeliveryTask{
+		UserWebhookDelivery: UserWebhookDelivery{ID: 1, EventID: "evt_1", EventType: UserWebhookEventPing, Payload: []byte(`{}`)},
+		URL:                 server.URL,
+		SecretEncrypted:     "enc:whsec_test",
+	}
+
+	before := time.Now()
+	d.deliver(context.Background(), task)
+	require.Len(t, repo.retried, 1)
+	require.Empty(t, repo.failed)
+	require.True(t, repo.retried[0].After(before.Add(20*time.Second)), "first retry backs off ~30s")
+
+	task.Attempts = 2 // max_attempts=3: this is the last one
+	d.deliver(context.Background(), task)
+	require.Len(t, repo.failed, 1)
+	require.Equal(t, "unexpected status 500", repo.failed[0].Error)
+}
+
+func TestUserWebhookService_CreateEndpoint(t *testing.T) {
+	repo := &userWebhookRepoStub{}
+	svc := NewUserWebhookService(repo, userWebhookTestEncryptor{}, newUserWebhookTestConfig(false))
+
+	_, _, err := svc.CreateEndpoint(context.Background(), 1, CreateUserWebhookEndpointInput{
+		URL: "https://127.0.0.1/hook", Events: []string{UserWebhookEventBalanceLow},
+	})
+	require.ErrorIs(t, err, ErrUserWebhookInvalidURL)
+
+	_, _, err = svc.CreateEndpoint(context.Background(), 1, CreateUserWebhookEndpointInput{
+		URL: "https://93.184.216.34/hook", Events: []string{"balance.unknown"},
+	})
+	require.ErrorIs(t, err, ErrUserWebhookInvalidEvents)
+
+	endpoint, secret, err := svc.CreateEndpoint(context.Background(), 1, CreateUserWebhookEndpointInput{
+		Name: " billing ", URL: "https://93.184.216.34/hook",
+		Events: []string{"Balance.Low", UserWebhookEventBalanceLow, UserWebhookEventPaymentCompleted},
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, userWebhookSecretPrefix))
+	require.Equal(t, "enc:"+secret, endpoint.SecretEncrypted)
+	require.Equal(t, "billing", endpoint.Name)
+	require.Equal(t, []string{UserWebhookEventBalanceLow, UserWebhookEventPaymentCompleted}, endpoint.Events)
+	require.True(t, endpoint.Enabled)
+}
+
+func TestUserWebhookService_PublishDeduplicatesEventID(t *testing.T) {
+	repo := &userWebhookRepoStub{}
+	svc := NewUserWebhookService(repo, userWebhookTestEncryptor{}, newUserWebhookTestConfig(true))
+
+	event := UserWebhookEvent{ID: "api_key.rate_limited:1:5h:100", Type: UserWebhookEventAPIKeyRateLimited, UserID: 1}
+	svc.Publish(event)
+	svc.Publish(event)
+	require.Eventually(t, func() bool { return repo.enqueuedCount() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 1, repo.enqueuedCount())
+
+	var disabled *UserWebhookService
+	disabled.Publish(event) // nil service is a no-op
+}
+
+func TestUserWebhookService_PublishTxEnqueuesSynchronously(t *testing.T) {
+	repo := &userWebhookRepoStub{}
+	svc := NewUserWebhookService(repo, userWebhookTestEncryptor{}, newUserWebhookTestConfig(true))
+
+	event := UserWebhookEvent{ID: "payment.completed:9", Type: UserWebhookEventPaymentCompleted, UserID: 1}
+	require.NoError(t, svc.PublishTx(context.Background(), event))
+	require.Equal(t, 1, repo.enqueuedCount())
+	// 事务内发布不做进程内去重：事务回滚后重试必须能再次写入。
+	require.NoError(t, svc.PublishTx(context.Background(), event))
+	require.Equal(t, 2, repo.enqueuedCount())
+
+	var disabled *UserWebhookService
+	require.NoError(t, disabled.PublishTx(context.Background(), event))
+}
+
+func TestUserWebhookService_PrepareEvent(t *testing.T) {
+	svc := NewUserWebhookService(&userWebhookRepoStub{}, userWebhookTestEncryptor{}, newUserWebhookTestConfig(true))
+
+	prepared, ok := svc.PrepareEvent(UserWebhookEvent{Type: UserWebhookEventAPIKeyQuotaExhausted, UserID: 3, Data: map[string]any{"api_key_id": 5}})
+	require.True(t, ok)
+	require.True(t, strings.HasPrefix(prepared.Event.ID, "evt_"))
+	require.False(t, prepared.Event.OccurredAt.IsZero())
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(prepared.Payload, &payload))
+	require.Equal(t, prepared.Event.ID, payload["id"])
+	require.Equal(t, UserWebhookEventAPIKeyQuotaExhausted, payload["type"])
+
+	cfg := newUserWebhookTestConfig(true)
+	cfg.UserWebhook.Enabled = false
+	_, ok = NewUserWebhookService(&userWebhookRepoStub{}, userWebhookTestEncryptor{}, cfg).PrepareEvent(prepared.Event)
+	require.False(t, ok)
+}
@@ -11,6 +11,7 @@ import (
 
 	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/websearch"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -18,13 +19,24 @@ import (
 type WebSearchEmulationConfig struct {
 	Enabled   bool                      `json:"enabled"`
 	Providers []WebSearchProviderConfig `json:"providers"`
+	// FetchPageContent fetches the top results and returns their extracted text
+	// instead of only the provider snippet.
+	FetchPageContent bool `json:"fetch_page_content"`
+	FetchMaxPages    int  `json:"fetch_max_pages,omitempty"` // 0 = default (3)
+	FetchMaxChars    int  `json:"fetch_max_chars,omitempty"` // per page; 0 = default (20000)
+	// WebFetchEnabled lets the gateway run the web_fetch server tool itself
+	// (no search provider needed). Pages are capped by FetchMaxChars.
+	WebFetchEnabled bool `json:"web_fetch_enabled"`
 }
 
-// WebSearchProviderConfig describes a single search provider (Brave or Tavily).
+// WebSearchProviderConfig describes a single search provider.
 type WebSearchProviderConfig struct {
-	Type             string `json:"type"`                    // websearch.ProviderTypeBrave | Tavily
-	APIKey           string `json:"api_key,omitempty"`       // secret — omitted in API responses
+	Type             string `json:"type"`                    // one of the websearch.ProviderType* constants
+	APIKey           string `json:"api_key,omitempty"`       // secret — omitted in API responses; optional for SearXNG
 	APIKeyConfigured bool   `json:"api_key_configured"`      // read-only mask
+	BaseURL          string `json:"base_url,omitempty"`      // SearXNG instance URL (required) or API endpoint override
+	EngineID         string `json:"engine_id,omitempty"`     // Google CSE search engine ID (cx)
+	Priority         int    `json:"priority,omitempty"`      // failover tier: lower is tried first
 	QuotaLimit       *int64 `json:"quota_limit"`             // nil = unlimited, >0 = limited
 	SubscribedAt     *int64 `json:"subscribed_at,omitempty"` // subscription start (unix seconds); quota resets monthly
 	QuotaUsed        int64  `json:"quota_used,omitempty"`    // read-only: current usage from Redis
@@ -34,11 +46,23 @@ type WebSearchProviderConfig struct {
 
 // --- Validation ---
 
-const maxWebSearchProviders = 10
+const (
+	maxWebSearchProviders = 10
+
+	defaultWebSearchFetchMaxPages = 3
+	maxWebSearchFetchMaxPages     = 10
+	defaultWebSearchFetchMaxChars = 20000
+	maxWebSearchFetchMaxChars     = 100000
+)
 
 var validProviderTypes = map[string]bool{
-	websearch.ProviderTypeBrave:  true,
-	websearch.ProviderTypeTavily: true,
+	websearch.ProviderTypeBrave:     true,
+	websearch.ProviderTypeTavily:    true,
+	websearch.ProviderTypeSearXNG:   true,
+	websearch.ProviderTypeBing:      true,
+	websearch.ProviderTypeGoogleCSE: true,
+	websearch.ProviderTypeExa:       true,
+	websearch.ProviderTypeJina:      true,
 }
 
 func validateWebSearchConfig(cfg *WebSearchEmulationConfig) error {
@@ -56,14 +80,49 @@ func validateWebSearchConfig(cfg *WebSearchEmulationConfig) error {
 		if p.QuotaLimit != nil && *p.QuotaLimit < 0 {
 			return fmt.Errorf("provider[%d]: quota_limit must be > 0 or null", i)
 		}
+		if p.Priority < 0 {
+			return fmt.Errorf("provider[%d]: priority must be >= 0", i)
+		}
+		if p.BaseURL != "" {
+			if _, err := urlvalidator.ValidateURLFormat(p.BaseURL, true); err != nil {
+				return fmt.Errorf("provider[%d]: invalid base_url: %v", i, err)
+			}
+		}
+		if p.Type == websearch.ProviderTypeSearXNG && p.BaseURL == "" {
+			return fmt.Errorf("provider[%d]: searxng requires base_url", i)
+		}
+		if p.Type == websearch.ProviderTypeGoogleCSE && p.EngineID == "" {
+			return fmt.Errorf("provider[%d]: google_cse requires engine_id", i)
+		}
 		if seen[p.Type] {
 			return fmt.Errorf("provider[%d]: duplicate type %q", i, p.Type)
 		}
 		seen[p.Type] = true
 	}
+	if cfg.FetchMaxPages < 0 || cfg.FetchMaxPages > maxWebSearchFetchMaxPages {
+		return fmt.Errorf("fetch_max_pages must be between 0 and %d", maxWebSearchFetchMaxPages)
+	}
+	if cfg.FetchMaxChars < 0 || cfg.FetchMaxChars > maxWebSearchFetchMaxChars {
+		return fmt.Errorf("fetch_max_chars must be between 0 and %d", maxWebSearchFetchMaxChars)
+	}
 	return nil
 }
 
+// PageFetchOptions returns the page-fetch settings for websearch.Manager; disabled unless FetchPageContent is set.
+func (c *WebSearchEmulationConfig) PageFetchOptions() websearch.PageFetchOptions {
+	if c == nil || !c.FetchPageContent {
+		return websearch.PageFetchOptions{}
+	}
+	opts := websearch.PageFetchOptions{MaxPages: c.FetchMaxPages, MaxChars: c.FetchMaxChars}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = defaultWebSearchFetchMaxPages
+	}
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = defaultWebSearchFetchMaxChars
+	}
+	return opts
+}
+
 // --- In-process cache (same pattern as gateway forwarding settings) ---
 
 const sfKeyWebSearchConfig = "web_search_emulation_config"
@@ -150,10 +209,10 @@ func (s *SettingService) SaveWebSearchEmulationConfig(ctx context.Context, cfg *
 	}
 	s.mergeExistingAPIKeys(ctx, cfg)
 
-	// After merge, validate all enabled providers have API keys
+	// After merge, validate all enabled providers have API keys (SearXNG may run without one)
 	if cfg.Enabled {
 		for _, p := range cfg.Providers {
-			if p.APIKey == "" {
+			if p.APIKey == "" && p.Type != websearch.ProviderTypeSearXNG {
 				return infraerrors.BadRequest("MISSING_API_KEY",
 					fmt.Sprintf("provider %s has no API key configured", p.Type))
 			}
@@ -217,6 +276,23 @@ func (s *SettingService) IsWebSearchEmulationEnabled(ctx context.Context) bool {
 	return cfg.Enabled && len(cfg.Providers) > 0
 }
 
+// IsWebFetchEmulationEnabled reports whether web_fetch emulation is switched on globally.
+func (s *SettingService) IsWebFetchEmulationEnabled(ctx context.Context) bool {
+	cfg, err := s.GetWebSearchEmulationConfig(ctx)
+	if err != nil {
+		return false
+	}
+	return cfg.WebFetchEnabled
+}
+
+// WebFetchMaxChars returns the per-page text limit for web_fetch emulation.
+func (c *WebSearchEmulationConfig) WebFetchMaxChars() int {
+	if c == nil || c.FetchMaxChars <= 0 {
+		return defaultWebSearchFetchMaxChars
+	}
+	return c.FetchMaxChars
+}
+
 // SetWebSearchManagerBuilder injects a callback that creates and wires a websearch.Manager.
 // The infra layer (main/wire) provides this builder, keeping redis out of the service layer.
 // Triggers initial build.
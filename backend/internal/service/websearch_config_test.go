@@ -38,11 +38,55 @@ func TestValidateWebSearchConfig_TooManyProviders(t *testing.T) {
 
 func TestValidateWebSearchConfig_InvalidType(t *testing.T) {
 	cfg := &WebSearchEmulationConfig{
-		Providers: []WebSearchProviderConfig{{Type: "bing"}},
+		Providers: []WebSearchProviderConfig{{Type: "duckduckgo"}},
 	}
 	require.ErrorContains(t, validateWebSearchConfig(cfg), "invalid type")
 }
 
+func TestValidateWebSearchConfig_SelfHostedAndKeyedProviders(t *testing.T) {
+	cfg := &WebSearchEmulationConfig{
+		Providers: []WebSearchProviderConfig{
+			{Type: websearch.ProviderTypeSearXNG, BaseURL: "http://searxng:8080", Priority: 0},
+			{Type: websearch.ProviderTypeBing, Priority: 1},
+			{Type: websearch.ProviderTypeGoogleCSE, EngineID: "cx-1", Priority: 1},
+			{Type: websearch.ProviderTypeExa},
+			{Type: websearch.ProviderTypeJina},
+		},
+		FetchPageContent: true,
+		FetchMaxPages:    5,
+	}
+	require.NoError(t, validateWebSearchConfig(cfg))
+}
+
+func TestValidateWebSearchConfig_ProviderSpecificFields(t *testing.T) {
+	cases := []struct {
+		provider WebSearchProviderConfig
+		wantErr  string
+	}{
+		{WebSearchProviderConfig{Type: websearch.ProviderTypeSearXNG}, "searxng requires base_url"},
+		{WebSearchProviderConfig{Type: websearch.ProviderTypeSearXNG, BaseURL: "ftp://searx"}, "invalid base_url"},
+		{WebSearchProviderConfig{Type: websearch.ProviderTypeGoogleCSE}, "google_cse requires engine_id"},
+		{WebSearchProviderConfig{Type: websearch.ProviderTypeBrave, Priority: -1}, "priority must be >= 0"},
+	}
+	for _, tc := range cases {
+		cfg := &WebSearchEmulationConfig{Providers: []WebSearchProviderConfig{tc.provider}}
+		require.ErrorContains(t, validateWebSearchConfig(cfg), tc.wantErr)
+	}
+}
+
+func TestValidateWebSearchConfig_FetchLimits(t *testing.T) {
+	require.ErrorContains(t, validateWebSearchConfig(&WebSearchEmulationConfig{FetchMaxPages: 11}), "fetch_max_pages")
+	require.ErrorContains(t, validateWebSearchConfig(&WebSearchEmulationConfig{FetchMaxChars: -1}), "fetch_max_chars")
+}
+
+func TestWebSearchEmulationConfig_PageFetchOptions(t *testing.T) {
+	require.Equal(t, websearch.PageFetchOptions{}, (&WebSearchEmulationConfig{FetchMaxPages: 2}).PageFetchOptions())
+	require.Equal(t, websearch.PageFetchOptions{MaxPages: 3, MaxChars: 20000},
+		(&WebSearchEmulationConfig{FetchPageContent: true}).PageFetchOptions())
+	require.Equal(t, websearch.PageFetchOptions{MaxPages: 1, MaxChars: 500},
+		(&WebSearchEmulationConfig{FetchPageContent: true, FetchMaxPages: 1, FetchMaxChars: 500}).PageFetchOptions())
+}
+
 func TestValidateWebSearchConfig_NegativeQuotaLimit(t *testing.T) {
 	cfg := &WebSearchEmulationConfig{
 		Providers: []WebSearchProviderConfig{{Type: "brave", QuotaLimit: int64Ptr(-1)}},
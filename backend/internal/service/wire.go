@@ -11,6 +11,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/payment"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/secrets"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/xai"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
@@ -388,10 +389,11 @@ func ProvideProxyExpiryService(proxyRepo ProxyRepository) *ProxyExpiryService {
 }
 
 // ProvideSubscriptionExpiryService creates and starts SubscriptionExpiryService.
-func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository, settingRepo SettingRepository, notificationEmailService *NotificationEmailService, lockCache LeaderLockCache, db *sql.DB) *SubscriptionExpiryService {
+func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository, settingRepo SettingRepository, notificationEmailService *NotificationEmailService, userWebhookService *UserWebhookService, lockCache LeaderLockCache, db *sql.DB) *SubscriptionExpiryService {
 	svc := NewSubscriptionExpiryService(userSubRepo, time.Minute)
 	svc.SetSettingRepository(settingRepo)
 	svc.SetNotificationEmailService(notificationEmailService)
+	svc.SetUserWebhookService(userWebhookService)
 	svc.SetLeaderLock(lockCache, db)
 	svc.Start()
 	return svc
@@ -429,7 +431,7 @@ func ProvideConcurrencyService(cache ConcurrencyCache, accountRepo AccountReposi
 
 // ProvideUserMessageQueueService 创建用户消息串行队列服务并启动清理 worker
 func ProvideUserMessageQueueService(cache UserMsgQueueCache, rpmCache RPMCache, cfg *config.Config) *UserMessageQueueService {
-	svc := NewUserMessageQueueService(cache, rpmCache, &cfg.Gateway.UserMessageQueue)
+	svc := NewUserMessageQueueService(cache, rpmCache, cfg)
 	if cfg.Gateway.UserMessageQueue.CleanupIntervalSeconds > 0 {
 		svc.StartCleanupWorker(time.Duration(cfg.Gateway.UserMessageQueue.CleanupIntervalSeconds) * time.Second)
 	}
@@ -770,8 +772,15 @@ func ProvideBillingCacheService(
 	rateRepo UserGroupRateRepository,
 	cfg *config.Config,
 	userPlatformQuotaRepo UserPlatformQuotaRepository,
+	userWebhookService *UserWebhookService,
+	organizationRepo OrganizationRepository,
+	budgetRepo BudgetRepository,
 ) *BillingCacheService {
-	return NewBillingCacheService(cache, userRepo, subRepo, apiKeyRepo, rpmCache, rateRepo, cfg, userPlatformQuotaRepo)
+	svc := NewBillingCacheService(cache, userRepo, subRepo, apiKeyRepo, rpmCache, rateRepo, cfg, userPlatformQuotaRepo)
+	svc.SetUserWebhookService(userWebhookService)
+	svc.SetOrganizationRepository(organizationRepo)
+	svc.SetBudgetRepository(budgetRepo)
+	return svc
 }
 
 // ProvideAPIKeyService wires APIKeyService and connects rate-limit cache invalidation.
@@ -785,10 +794,86 @@ func ProvideAPIKeyService(
 	cfg *config.Config,
 	billingCacheService *BillingCacheService,
 	concurrencyService *ConcurrencyService,
+	organizationRepo OrganizationRepository,
 ) *APIKeyService {
 	svc := NewAPIKeyService(apiKeyRepo, userRepo, groupRepo, userSubRepo, userGroupRateRepo, cache, cfg)
 	svc.SetRateLimitCacheInvalidator(billingCacheService)
 	svc.SetConcurrencyService(concurrencyService)
+	svc.SetOrganizationRepository(organizationRepo)
+	return svc
+}
+
+// ProvideAccountSchedulers creates the process-wide account scheduling strategies
+// shared by the Anthropic/Gemini and OpenAI gateways.
+func ProvideAccountSchedulers() *AccountSchedulers {
+	return NewAccountSchedulers(nil)
+}
+
+// ProvideGatewayService wires GatewayService and connects the shared account schedulers.
+func ProvideGatewayService(
+	accountRepo AccountRepository,
+	groupRepo GroupRepository,
+	usageLogRepo UsageLogRepository,
+	usageBillingRepo UsageBillingRepository,
+	userRepo UserRepository,
+	userSubRepo UserSubscriptionRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	cache GatewayCache,
+	cfg *config.Config,
+	schedulerSnapshot *SchedulerSnapshotService,
+	concurrencyService *ConcurrencyService,
+	billingService *BillingService,
+	rateLimitService *RateLimitService,
+	billingCacheService *BillingCacheService,
+	identityService *IdentityService,
+	httpUpstream HTTPUpstream,
+	deferredService *DeferredService,
+	claudeTokenProvider *ClaudeTokenProvider,
+	sessionLimitCache SessionLimitCache,
+	rpmCache RPMCache,
+	digestStore *DigestSessionStore,
+	settingService *SettingService,
+	tlsFPProfileService *TLSFingerprintProfileService,
+	channelService *ChannelService,
+	resolver *ModelPricingResolver,
+	compositeResolver *CompositeRouteResolver,
+	balanceNotifyService *BalanceNotifyService,
+	userPlatformQuotaRepo UserPlatformQuotaRepository,
+	accountSchedulers *AccountSchedulers,
+) *GatewayService {
+	svc := NewGatewayService(accountRepo, groupRepo, usageLogRepo, usageBillingRepo, userRepo, userSubRepo, userGroupRateRepo, cache, cfg, schedulerSnapshot, concurrencyService, billingService, rateLimitService, billingCacheService, identityService, httpUpstream, deferredService, claudeTokenProvider, sessionLimitCache, rpmCache, digestStore, settingService, tlsFPProfileService, channelService, resolver, compositeResolver, balanceNotifyService, userPlatformQuotaRepo)
+	svc.SetAccountSchedulers(accountSchedulers)
+	return svc
+}
+
+// ProvideOpenAIGatewayService wires OpenAIGatewayService and connects the shared account schedulers.
+func ProvideOpenAIGatewayService(
+	accountRepo AccountRepository,
+	usageLogRepo UsageLogRepository,
+	usageBillingRepo UsageBillingRepository,
+	userRepo UserRepository,
+	userSubRepo UserSubscriptionRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	cache GatewayCache,
+	cfg *config.Config,
+	schedulerSnapshot *SchedulerSnapshotService,
+	concurrencyService *ConcurrencyService,
+	billingService *BillingService,
+	rateLimitService *RateLimitService,
+	billingCacheService *BillingCacheService,
+	httpUpstream HTTPUpstream,
+	deferredService *DeferredService,
+	openAITokenProvider *OpenAITokenProvider,
+	grokTokenProvider *GrokTokenProvider,
+	resolver *ModelPricingResolver,
+	channelService *ChannelService,
+	balanceNotifyService *BalanceNotifyService,
+	settingService *SettingService,
+	userPlatformQuotaRepo UserPlatformQuotaRepository,
+	accountSchedulers *AccountSchedulers,
+) *OpenAIGatewayService {
+	svc := NewOpenAIGatewayService(accountRepo, usageLogRepo, usageBillingRepo, userRepo, userSubRepo, userGroupRateRepo, cache, cfg, schedulerSnapshot, concurrencyService, billingService, rateLimitService, billingCacheService, httpUpstream, deferredService, openAITokenProvider, grokTokenProvider, resolver, channelService, balanceNotifyService, settingService, userPlatformQuotaRepo)
+	svc.SetAccountSchedulers(accountSchedulers)
 	return svc
 }
 
@@ -814,8 +899,9 @@ var ProviderSet = wire.NewSet(
 	ProvideBillingCacheService,
 	NewAnnouncementService,
 	NewAdminService,
-	NewGatewayService,
-	NewOpenAIGatewayService,
+	ProvideAccountSchedulers,
+	ProvideGatewayService,
+	ProvideOpenAIGatewayService,
 	ProvideImageStorageSettingService,
 	ProvideImageTaskService,
 	ProvideBatchImageModelPricingResolver,
@@ -865,6 +951,22 @@ var ProviderSet = wire.NewSet(
 	ProvideOpsScheduledReportService,
 	NewEmailService,
 	NewNotificationEmailService,
+	NewUserWebhookService,
+	NewOrganizationService,
+	NewBudgetService,
+	ProvideBalanceLedgerService,
+	ProvideInvoiceService,
+	ProvideUsageExportService,
+	ProvideConfigReloadService,
+	ProvideCredentialEncryptionService,
+	ProvideUsageLogMoneyBackfillService,
+	NewAdminRBACService,
+	NewAdminAPITokenService,
+	NewSAMLService,
+	ProvideUserWebhookDispatcher,
+	NewOpenAIBatchService,
+	ProvideOpenAIBatchWorker,
+	NewResponseCacheService,
 	ProvideEmailQueueService,
 	NewTurnstileService,
 	NewTencentCaptchaService,
@@ -874,6 +976,7 @@ var ProviderSet = wire.NewSet(
 	ProvideConcurrencyService,
 	ProvideUserMessageQueueService,
 	NewUsageRecordWorkerPool,
+	NewPrometheusMetricsService,
 	ProvideSchedulerSnapshotService,
 	NewIdentityService,
 	NewCRSSyncService,
@@ -929,21 +1032,72 @@ func ProvideUserPlatformQuotaUsageFlusher(cfg *config.Config, cache BillingCache
 
 // ProvidePaymentConfigService wraps NewPaymentConfigService to accept the named
 // payment.EncryptionKey type instead of raw []byte, avoiding Wire ambiguity.
-func ProvidePaymentConfigService(entClient *dbent.Client, settingRepo SettingRepository, key payment.EncryptionKey) *PaymentConfigService {
-	return NewPaymentConfigService(entClient, settingRepo, []byte(key))
+func ProvidePaymentConfigService(entClient *dbent.Client, settingRepo SettingRepository, key payment.EncryptionKey, resolver *secrets.Resolver) *PaymentConfigService {
+	svc := NewPaymentConfigService(entClient, settingRepo, []byte(key))
+	svc.SetSecretResolver(resolver)
+	return svc
 }
 
 // ProvideBalanceNotifyService creates BalanceNotifyService
-func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, notificationEmailService *NotificationEmailService) *BalanceNotifyService {
+func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, notificationEmailService *NotificationEmailService, userWebhookService *UserWebhookService) *BalanceNotifyService {
 	svc := NewBalanceNotifyService(emailService, settingRepo, accountRepo)
 	svc.SetNotificationEmailService(notificationEmailService)
+	svc.SetUserWebhookService(userWebhookService)
 	return svc
 }
 
 // ProvidePaymentService creates PaymentService and attaches notification email delivery.
-func ProvidePaymentService(entClient *dbent.Client, registry *payment.Registry, loadBalancer payment.LoadBalancer, redeemService *RedeemService, subscriptionSvc *SubscriptionService, configService *PaymentConfigService, userRepo UserRepository, groupRepo GroupRepository, affiliateService *AffiliateService, notificationEmailService *NotificationEmailService) *PaymentService {
+func ProvidePaymentService(entClient *dbent.Client, registry *payment.Registry, loadBalancer payment.LoadBalancer, redeemService *RedeemService, subscriptionSvc *SubscriptionService, configService *PaymentConfigService, userRepo UserRepository, groupRepo GroupRepository, affiliateService *AffiliateService, notificationEmailService *NotificationEmailService, userWebhookService *UserWebhookService) *PaymentService {
 	svc := NewPaymentService(entClient, registry, loadBalancer, redeemService, subscriptionSvc, configService, userRepo, groupRepo, affiliateService)
 	svc.SetNotificationEmailService(notificationEmailService)
+	svc.SetUserWebhookService(userWebhookService)
+	return svc
+}
+
+// ProvideBalanceLedgerService creates BalanceLedgerService and starts the periodic reconciliation.
+func ProvideBalanceLedgerService(repo BalanceLedgerRepository, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *BalanceLedgerService {
+	svc := NewBalanceLedgerService(repo, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideInvoiceService creates InvoiceService and starts the periodic receipt / statement auto-issue.
+func ProvideInvoiceService(repo InvoiceRepository, orgRepo OrganizationRepository, userRepo UserRepository, entClient *dbent.Client, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *InvoiceService {
+	svc := NewInvoiceService(repo, orgRepo, userRepo, entClient, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideUsageExportService creates UsageExportService and registers the scheduled usage_logs export.
+func ProvideUsageExportService(repo UsageExportRepository, storeFactory BackupObjectStoreFactory, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *UsageExportService {
+	svc := NewUsageExportService(repo, storeFactory, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideCredentialEncryptionService creates CredentialEncryptionService and starts the online credential re-encryption job.
+func ProvideCredentialEncryptionService(repo CredentialEncryptionRepository, cfg *config.Config, lockCache LeaderLockCache, db *sql.DB) *CredentialEncryptionService {
+	svc := NewCredentialEncryptionService(repo, cfg)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideUsageLogMoneyBackfillService creates UsageLogMoneyBackfillService and starts the online usage_logs.actual_cost rounding job.
+func ProvideUsageLogMoneyBackfillService(repo UsageLogMoneyBackfillRepository, settingRepo SettingRepository, lockCache LeaderLockCache, db *sql.DB) *UsageLogMoneyBackfillService {
+	svc := NewUsageLogMoneyBackfillService(repo, settingRepo)
+	svc.SetLeaderLock(lockCache, db)
+	svc.Start()
+	return svc
+}
+
+// ProvideConfigReloadService creates ConfigReloadService and starts watching config.yaml and peer reload signals.
+func ProvideConfigReloadService(cfg *config.Config, notifier ConfigReloadNotifier) *ConfigReloadService {
+	svc := NewConfigReloadService(cfg, notifier)
+	svc.Start()
 	return svc
 }
 
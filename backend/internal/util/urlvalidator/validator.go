@@ -117,14 +117,20 @@ func ValidateResolvedIP(host string) error {
 	}
 
 	for _, ip := range ips {
-		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
-			ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		if IsDisallowedIP(ip) {
 			return fmt.Errorf("resolved ip %s is not allowed", ip.String())
 		}
 	}
 	return nil
 }
 
+// IsDisallowedIP 判断 IP 是否为 loopback/私网/链路本地/未指定地址。
+// 供在 socket 层校验真实连接 IP 的 Dialer 复用，与 ValidateResolvedIP 口径一致。
+func IsDisallowedIP(ip net.IP) bool {
+	return ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
 func normalizeAllowlist(values []string) []string {
 	if len(values) == 0 {
 		return nil
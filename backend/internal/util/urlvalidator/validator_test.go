@@ -1,6 +1,9 @@
 package urlvalidator
 
-import "testing"
+import (
+	"net"
+	"testing"
+)
 
 func TestValidateURLFormat(t *testing.T) {
 	if _, err := ValidateURLFormat("", false); err == nil {
@@ -73,3 +76,19 @@ func TestValidateHTTPURL(t *testing.T) {
 		t.Fatalf("expected localhost to be blocked when allow_private_hosts is false")
 	}
 }
+
+func TestIsDisallowedIP(t *testing.T) {
+	for _, raw := range []string{"127.0.0.1", "10.1.2.3", "192.168.0.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1"} {
+		if !IsDisallowedIP(net.ParseIP(raw)) {
+			t.Fatalf("expected %s to be disallowed", raw)
+		}
+	}
+	for _, raw := range []string{"8.8.8.8", "2606:4700:4700::1111"} {
+		if IsDisallowedIP(net.ParseIP(raw)) {
+			t.Fatalf("expected %s to be allowed", raw)
+		}
+	}
+	if !IsDisallowedIP(nil) {
+		t.Fatalf("expected nil ip to be disallowed")
+	}
+}
@@ -0,0 +1,60 @@
+-- User-configured outbound webhooks.
+-- user_webhook_endpoints holds the per-user targets; the signing secret is
+-- encrypted with the application SecretEncryptor and never returned after creation.
+-- user_webhook_deliveries is both the durable outbox and the delivery log shown
+-- in the user panel: rows are enqueued when an event fires and updated in place
+-- by the dispatcher until they succeed or exhaust their attempts.
+
+CREATE TABLE IF NOT EXISTS user_webhook_endpoints (
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name             VARCHAR(100) NOT NULL DEFAULT '',
+    url              TEXT NOT NULL,
+    secret_encrypted TEXT NOT NULL,
+    events           TEXT[] NOT NULL DEFAULT '{}',
+    enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_webhook_endpoints_user
+    ON user_webhook_endpoints (user_id)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS user_webhook_deliveries (
+    id                   BIGSERIAL PRIMARY KEY,
+    endpoint_id          BIGINT NOT NULL REFERENCES user_webhook_endpoints(id) ON DELETE CASCADE,
+    user_id              BIGINT NOT NULL,
+    event_id             VARCHAR(200) NOT NULL,
+    event_type           VARCHAR(64) NOT NULL,
+    payload              JSONB NOT NULL,
+    status               VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts             INTEGER NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    next_attempt_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_response_status INTEGER,
+    last_response_body   TEXT,
+    last_error           TEXT,
+    last_duration_ms     INTEGER,
+    claimed_at           TIMESTAMPTZ,
+    claimed_by           TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at         TIMESTAMPTZ
+);
+
+-- Same event fired twice (e.g. a rate-limit rejection on every request in the
+-- window) is delivered once per endpoint.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_webhook_deliveries_endpoint_event
+    ON user_webhook_deliveries (endpoint_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_user_webhook_deliveries_due
+    ON user_webhook_deliveries (next_attempt_at, id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_user_webhook_deliveries_user_created
+    ON user_webhook_deliveries (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_user_webhook_deliveries_created_at
+    ON user_webhook_deliveries (created_at);
+
+COMMENT ON TABLE user_webhook_deliveries IS
+    'Outbox and delivery log for user webhooks; (endpoint_id, event_id) deduplicates repeated events';
@@ -0,0 +1,8 @@
+-- 230_ops_alert_notify_channels.sql
+-- Per-rule IM / webhook notification channels for ops alerts
+-- (slack / feishu / dingtalk / telegram / webhook). Email keeps using notify_email.
+
+ALTER TABLE ops_alert_rules
+    ADD COLUMN IF NOT EXISTS notify_channels JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN ops_alert_rules.notify_channels IS 'Array of notifier channel configs: type, url, secret, bot_token, chat_id, templates, min_severity, rate_limit_per_hour.';
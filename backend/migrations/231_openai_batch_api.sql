@@ -0,0 +1,109 @@
+-- OpenAI-compatible Batch API (/v1/files + /v1/batches).
+-- openai_files stores uploaded batch inputs and generated output/error files.
+-- openai_batches is the job row claimed by gateway workers (FOR UPDATE SKIP LOCKED + lease);
+-- the balance hold reuses the batch image hold primitives keyed by batch id.
+-- openai_batch_items holds one row per JSONL line so progress survives restarts.
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS allow_batch_api BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS batch_api_discount_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.5,
+    ADD COLUMN IF NOT EXISTS batch_api_hold_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.6;
+
+COMMENT ON COLUMN groups.allow_batch_api IS '是否允许通过 /v1/batches 提交 OpenAI 兼容批量请求';
+COMMENT ON COLUMN groups.batch_api_discount_multiplier IS 'Batch API 折扣倍率，批次内请求的实际费用会乘以该值';
+COMMENT ON COLUMN groups.batch_api_hold_multiplier IS 'Batch API 冻结比例，按估算原价乘以该比例冻结，结算后释放差额';
+
+CREATE TABLE IF NOT EXISTS openai_files (
+    id          VARCHAR(64) PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id  BIGINT NOT NULL,
+    purpose     VARCHAR(32) NOT NULL,
+    filename    VARCHAR(255) NOT NULL DEFAULT '',
+    bytes       BIGINT NOT NULL DEFAULT 0,
+    content     BYTEA NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_openai_files_user_created
+    ON openai_files (user_id, created_at DESC, id DESC)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_openai_files_expires_at
+    ON openai_files (expires_at)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS openai_batches (
+    id                  VARCHAR(64) PRIMARY KEY,
+    user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id          BIGINT NOT NULL,
+    group_id            BIGINT NOT NULL,
+    endpoint            VARCHAR(64) NOT NULL,
+    input_file_id       VARCHAR(64) NOT NULL,
+    output_file_id      VARCHAR(64),
+    error_file_id       VARCHAR(64),
+    status              VARCHAR(16) NOT NULL DEFAULT 'in_progress'
+        CHECK (status IN ('validating', 'in_progress', 'finalizing', 'completed', 'failed', 'expired', 'cancelling', 'cancelled')),
+    completion_window   VARCHAR(16) NOT NULL DEFAULT '24h',
+    metadata            JSONB NOT NULL DEFAULT '{}'::jsonb,
+    request_total       INTEGER NOT NULL DEFAULT 0,
+    request_completed   INTEGER NOT NULL DEFAULT 0,
+    request_failed      INTEGER NOT NULL DEFAULT 0,
+    hold_amount         DECIMAL(20,10) NOT NULL DEFAULT 0,
+    actual_cost         DECIMAL(20,10) NOT NULL DEFAULT 0,
+    settle_amount       DECIMAL(20,10),
+    discount_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0.5,
+    hold_multiplier     DECIMAL(10,4) NOT NULL DEFAULT 0.6,
+    last_error          TEXT,
+    claimed_by          TEXT,
+    claimed_until       TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    in_progress_at      TIMESTAMPTZ,
+    expires_at          TIMESTAMPTZ NOT NULL,
+    finalizing_at       TIMESTAMPTZ,
+    completed_at        TIMESTAMPTZ,
+    failed_at           TIMESTAMPTZ,
+    expired_at          TIMESTAMPTZ,
+    cancelling_at       TIMESTAMPTZ,
+    cancelled_at        TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_openai_batches_user_created
+    ON openai_batches (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_openai_batches_active
+    ON openai_batches (claimed_until NULLS FIRST, created_at)
+    WHERE status IN ('in_progress', 'finalizing', 'cancelling');
+
+CREATE TABLE IF NOT EXISTS openai_batch_items (
+    id              BIGSERIAL PRIMARY KEY,
+    batch_id        VARCHAR(64) NOT NULL REFERENCES openai_batches(id) ON DELETE CASCADE,
+    line_index      INTEGER NOT NULL,
+    custom_id       VARCHAR(512) NOT NULL,
+    method          VARCHAR(8) NOT NULL DEFAULT 'POST',
+    url             VARCHAR(64) NOT NULL,
+    body            JSONB NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    response_body   BYTEA,
+    request_id      VARCHAR(128),
+    error_code      VARCHAR(64),
+    error_message   TEXT,
+    cost            DECIMAL(20,10) NOT NULL DEFAULT 0,
+    billed_at       TIMESTAMPTZ,
+    started_at      TIMESTAMPTZ,
+    finished_at     TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (batch_id, custom_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_openai_batch_items_pending
+    ON openai_batch_items (batch_id, line_index)
+    WHERE status IN ('pending', 'running');
+
+COMMENT ON COLUMN openai_batches.settle_amount IS
+    'Amount captured from the hold, fixed on the first settlement attempt so retries stay idempotent';
+COMMENT ON TABLE openai_batch_items IS
+    'One row per Batch API input line; cost is written by the usage billing hook when the line is billed';
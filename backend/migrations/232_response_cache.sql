@@ -0,0 +1,17 @@
+-- Exact-match response cache for /v1/messages, /v1/chat/completions and /v1/responses.
+-- Opt-in per group (with TTL and hit pricing) or per API key; cached bodies live in Redis only.
+-- Cache hits are written to usage_logs with billing_mode = 'response_cache'.
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS response_cache_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS response_cache_ttl_seconds INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS response_cache_price_multiplier DECIMAL(10,4) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN groups.response_cache_enabled IS '是否对该分组启用精确匹配响应缓存';
+COMMENT ON COLUMN groups.response_cache_ttl_seconds IS '响应缓存 TTL（秒），0 表示使用全局默认值';
+COMMENT ON COLUMN groups.response_cache_price_multiplier IS '缓存命中计费倍率，在分组有效倍率之上再乘以该值；0 表示命中免费';
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS response_cache_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN api_keys.response_cache_enabled IS '单独为该 API Key 启用响应缓存（分组未启用时生效，TTL 与命中计价沿用分组配置）';
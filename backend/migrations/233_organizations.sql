@@ -0,0 +1,85 @@
+-- Organizations (teams) with a shared wallet.
+-- organizations.balance is charged instead of users.balance for API keys that
+-- carry an organization_id; members keep their personal balance for personal keys.
+-- organization_members holds the owner/admin/member role and an optional
+-- per-member monthly spending limit, enforced against usage_logs of the
+-- member's organization keys.
+-- organization_invitations stores only the SHA-256 of the emailed token.
+-- organization_wallet_transactions is the audit trail of wallet top-ups.
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id            BIGSERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    owner_user_id BIGINT NOT NULL REFERENCES users(id),
+    balance       DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    status        VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'disabled')),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_owner
+    ON organizations (owner_user_id)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN organizations.balance IS '组织共享钱包余额（可为负数），组织 API Key 的余额计费从此扣除';
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id                      BIGSERIAL PRIMARY KEY,
+    organization_id         BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id                 BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role                    VARCHAR(20) NOT NULL DEFAULT 'member'
+        CHECK (role IN ('owner', 'admin', 'member')),
+    monthly_spend_limit_usd DECIMAL(20, 8) NOT NULL DEFAULT 0 CHECK (monthly_spend_limit_usd >= 0),
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user
+    ON organization_members (user_id);
+
+COMMENT ON COLUMN organization_members.monthly_spend_limit_usd IS '成员每自然月在组织 Key 上的消费上限（USD），0 表示不限制';
+
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id              BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email           VARCHAR(255) NOT NULL,
+    role            VARCHAR(20) NOT NULL DEFAULT 'member'
+        CHECK (role IN ('admin', 'member')),
+    token_hash      VARCHAR(64) NOT NULL UNIQUE,
+    invited_by      BIGINT NOT NULL REFERENCES users(id),
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'revoked')),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    accepted_by     BIGINT REFERENCES users(id),
+    accepted_at     TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_org
+    ON organization_invitations (organization_id, status);
+
+CREATE TABLE IF NOT EXISTS organization_wallet_transactions (
+    id              BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    actor_user_id   BIGINT REFERENCES users(id),
+    type            VARCHAR(20) NOT NULL CHECK (type IN ('member_transfer', 'admin_adjust')),
+    amount          DECIMAL(20, 8) NOT NULL,
+    balance_after   DECIMAL(20, 8) NOT NULL,
+    note            TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_wallet_transactions_org
+    ON organization_wallet_transactions (organization_id, created_at DESC);
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS organization_id BIGINT REFERENCES organizations(id);
+
+CREATE INDEX IF NOT EXISTS apikey_organization_id
+    ON api_keys (organization_id)
+    WHERE organization_id IS NOT NULL;
+
+COMMENT ON COLUMN api_keys.organization_id IS '所属组织；非空时余额计费扣组织钱包，创建后不可变更';
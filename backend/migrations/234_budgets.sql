@@ -0,0 +1,41 @@
+-- Spending budgets attached to a user, an API key or a group.
+-- Periods (daily / weekly / monthly) are calendar periods in the configured
+-- timezone, unlike the rolling api_keys.rate_limit_* windows.
+-- Spend is the sum of usage_logs.actual_cost for balance-billed requests
+-- (billing_type = 0) within the current period; model = '' covers every model,
+-- otherwise only rows whose requested model matches.
+-- enforcement = 'hard' rejects requests once spend reaches limit_usd,
+-- 'soft' only notifies. Notifications fire at 50/80/100%; alert_period_start
+-- and alert_percent record the highest threshold already notified so that
+-- multiple gateway instances do not send duplicates.
+-- source = 'admin' budgets can only be changed by administrators; users manage
+-- their own 'user' budgets on themselves and their API keys.
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id                 BIGSERIAL PRIMARY KEY,
+    scope_type         VARCHAR(16) NOT NULL
+        CHECK (scope_type IN ('user', 'api_key', 'group')),
+    scope_id           BIGINT NOT NULL,
+    model              VARCHAR(100) NOT NULL DEFAULT '',
+    period             VARCHAR(16) NOT NULL
+        CHECK (period IN ('daily', 'weekly', 'monthly')),
+    limit_usd          DECIMAL(20, 8) NOT NULL CHECK (limit_usd > 0),
+    enforcement        VARCHAR(16) NOT NULL DEFAULT 'hard'
+        CHECK (enforcement IN ('hard', 'soft')),
+    enabled            BOOLEAN NOT NULL DEFAULT TRUE,
+    source             VARCHAR(16) NOT NULL DEFAULT 'admin'
+        CHECK (source IN ('admin', 'user')),
+    created_by         BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    alert_period_start TIMESTAMPTZ,
+    alert_percent      SMALLINT NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (scope_type, scope_id, model, period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_scope
+    ON budgets (scope_type, scope_id)
+    WHERE enabled;
+
+COMMENT ON COLUMN budgets.model IS '空字符串表示全部模型，否则只统计请求模型等于该值的用量';
+COMMENT ON COLUMN budgets.alert_percent IS '当前周期内已通知的最高阈值（50/80/100），周期切换后重置';
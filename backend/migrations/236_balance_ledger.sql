@@ -0,0 +1,132 @@
+-- Append-only per-user balance ledger.
+-- Every change to users.balance / users.frozen_balance is recorded by a trigger
+-- in the same transaction, so no write path (ent builders, raw SQL, manual
+-- fixes) can skip the trail. balance_after is the running balance.
+-- The application attributes a change by setting the transaction-local GUC
+-- sub2api.balance_ledger_source to a JSON object
+-- {"reason", "source_type", "source_id", "actor_id", "note"} right before the
+-- UPDATE; changes without it are recorded as reason = 'unattributed'.
+-- Existing balances are captured once as reason = 'opening' entries.
+
+CREATE TABLE IF NOT EXISTS balance_ledger (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT NOT NULL,
+    reason         VARCHAR(32) NOT NULL,
+    source_type    VARCHAR(32) NOT NULL DEFAULT '',
+    source_id      VARCHAR(255) NOT NULL DEFAULT '',
+    actor_id       BIGINT,
+    amount         DECIMAL(20, 8) NOT NULL,
+    balance_before DECIMAL(20, 8) NOT NULL,
+    balance_after  DECIMAL(20, 8) NOT NULL,
+    frozen_delta   DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    frozen_after   DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    note           TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_user_id
+    ON balance_ledger (user_id, id);
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_user_created_at
+    ON balance_ledger (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_source
+    ON balance_ledger (source_type, source_id)
+    WHERE source_id <> '';
+CREATE INDEX IF NOT EXISTS idx_balance_ledger_created_at
+    ON balance_ledger (created_at);
+
+INSERT INTO balance_ledger (user_id, reason, amount, balance_before, balance_after, frozen_after, note)
+SELECT u.id, 'opening', u.balance, 0, u.balance, COALESCE(u.frozen_balance, 0), 'balance at ledger introduction'
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM balance_ledger bl WHERE bl.user_id = u.id);
+
+CREATE OR REPLACE FUNCTION record_balance_ledger_entry()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    raw_source     TEXT;
+    source         JSONB;
+    old_balance    DECIMAL(20, 8) := 0;
+    old_frozen     DECIMAL(20, 8) := 0;
+    new_frozen     DECIMAL(20, 8) := COALESCE(NEW.frozen_balance, 0);
+    default_reason TEXT := 'unattributed';
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_balance := OLD.balance;
+        old_frozen := COALESCE(OLD.frozen_balance, 0);
+    ELSE
+        default_reason := 'opening';
+    END IF;
+    IF NEW.balance = old_balance AND new_frozen = old_frozen THEN
+        RETURN NEW;
+    END IF;
+
+    raw_source := current_setting('sub2api.balance_ledger_source', true);
+    IF raw_source IS NOT NULL AND raw_source <> '' THEN
+        BEGIN
+            source := raw_source::JSONB;
+        EXCEPTION WHEN others THEN
+            source := NULL;
+        END;
+    END IF;
+
+    INSERT INTO balance_ledger (
+        user_id, reason, source_type, source_id, actor_id,
+        amount, balance_before, balance_after, frozen_delta, frozen_after, note
+    ) VALUES (
+        NEW.id,
+        COALESCE(NULLIF(source->>'reason', ''), default_reason),
+        COALESCE(source->>'source_type', ''),
+        LEFT(COALESCE(source->>'source_id', ''), 255),
+        NULLIF(source->>'actor_id', '')::BIGINT,
+        NEW.balance - old_balance,
+        old_balance,
+        NEW.balance,
+        new_frozen - old_frozen,
+        new_frozen,
+        COALESCE(source->>'note', '')
+    );
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_users_balance_ledger ON users;
+CREATE TRIGGER trg_users_balance_ledger
+AFTER INSERT OR UPDATE OF balance, frozen_balance ON users
+FOR EACH ROW EXECUTE FUNCTION record_balance_ledger_entry();
+
+CREATE OR REPLACE FUNCTION reject_balance_ledger_mutation()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'balance_ledger is append-only';
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_balance_ledger_append_only ON balance_ledger;
+CREATE TRIGGER trg_balance_ledger_append_only
+BEFORE UPDATE OR DELETE ON balance_ledger
+FOR EACH ROW EXECUTE FUNCTION reject_balance_ledger_mutation();
+
+DROP TRIGGER IF EXISTS trg_balance_ledger_no_truncate ON balance_ledger;
+CREATE TRIGGER trg_balance_ledger_no_truncate
+BEFORE TRUNCATE ON balance_ledger
+FOR EACH STATEMENT EXECUTE FUNCTION reject_balance_ledger_mutation();
+
+-- One row per reconciliation run; findings holds a capped sample per kind.
+CREATE TABLE IF NOT EXISTS balance_reconciliation_runs (
+    id                  BIGSERIAL PRIMARY KEY,
+    window_start        TIMESTAMPTZ NOT NULL,
+    window_end          TIMESTAMPTZ NOT NULL,
+    balance_drift_count INTEGER NOT NULL DEFAULT 0,
+    ledger_gap_count    INTEGER NOT NULL DEFAULT 0,
+    usage_drift_count   INTEGER NOT NULL DEFAULT 0,
+    payment_drift_count INTEGER NOT NULL DEFAULT 0,
+    findings            JSONB NOT NULL DEFAULT '[]'::JSONB,
+    started_at          TIMESTAMPTZ NOT NULL,
+    finished_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_reconciliation_runs_started_at
+    ON balance_reconciliation_runs (started_at DESC);
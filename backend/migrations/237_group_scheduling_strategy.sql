@@ -0,0 +1,8 @@
+-- Per-group account scheduling strategy shared by the Anthropic, Gemini and
+-- OpenAI gateways. Empty (or 'default') keeps each platform's existing
+-- scheduler; other values: least_latency, cost_aware, weighted_round_robin.
+
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS scheduling_strategy VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.scheduling_strategy IS '账号调度策略：空或 default 沿用平台默认调度，另可选 least_latency、cost_aware、weighted_round_robin';
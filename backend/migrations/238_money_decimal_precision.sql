@@ -0,0 +1,81 @@
+-- 计费金额统一为定点 NUMERIC，新写入的 usage_logs.actual_cost 按记账精度落库。
+--
+-- 舍入策略（与 internal/pkg/money 一致）：
+--   * 明细金额（usage_logs 各分项费用与 total_cost）保留 10 位小数；
+--   * 记账金额（actual_cost、余额、Key 配额、订阅 / 平台用量、限速窗口用量）保留 8 位小数；
+--   * 一律 half-away-from-zero（PostgreSQL NUMERIC 的 ROUND 语义）。
+--
+-- 1) 守卫：由 ent 自动建表或手工建表的部署可能把金额列建成 double precision / real，
+--    这里统一改回 NUMERIC 并按上述精度舍入；标准安装下这些列已是 DECIMAL，本段为 no-op。
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT c.table_name, c.column_name, t.type_spec
+        FROM information_schema.columns c
+        JOIN (VALUES
+            ('users', 'balance', 'DECIMAL(20, 8)'),
+            ('users', 'frozen_balance', 'DECIMAL(20, 8)'),
+            ('users', 'total_recharged', 'DECIMAL(20, 8)'),
+            ('users', 'balance_notify_threshold', 'DECIMAL(20, 8)'),
+            ('api_keys', 'quota', 'DECIMAL(20, 8)'),
+            ('api_keys', 'quota_used', 'DECIMAL(20, 8)'),
+            ('api_keys', 'rate_limit_5h', 'DECIMAL(20, 8)'),
+            ('api_keys', 'rate_limit_1d', 'DECIMAL(20, 8)'),
+            ('api_keys', 'rate_limit_7d', 'DECIMAL(20, 8)'),
+            ('api_keys', 'usage_5h', 'DECIMAL(20, 8)'),
+            ('api_keys', 'usage_1d', 'DECIMAL(20, 8)'),
+            ('api_keys', 'usage_7d', 'DECIMAL(20, 8)'),
+            ('usage_logs', 'input_cost', 'DECIMAL(20, 10)'),
+            ('usage_logs', 'output_cost', 'DECIMAL(20, 10)'),
+            ('usage_logs', 'cache_creation_cost', 'DECIMAL(20, 10)'),
+            ('usage_logs', 'cache_read_cost', 'DECIMAL(20, 10)'),
+            ('usage_logs', 'total_cost', 'DECIMAL(20, 10)'),
+            ('usage_logs', 'actual_cost', 'DECIMAL(20, 10)'),
+            ('user_subscriptions', 'daily_usage_usd', 'DECIMAL(20, 10)'),
+            ('user_subscriptions', 'weekly_usage_usd', 'DECIMAL(20, 10)'),
+            ('user_subscriptions', 'monthly_usage_usd', 'DECIMAL(20, 10)'),
+            ('user_platform_quotas', 'daily_usage_usd', 'DECIMAL(20, 10)'),
+            ('user_platform_quotas', 'weekly_usage_usd', 'DECIMAL(20, 10)'),
+            ('user_platform_quotas', 'monthly_usage_usd', 'DECIMAL(20, 10)')
+        ) AS t(table_name, column_name, type_spec)
+          ON t.table_name = c.table_name AND t.column_name = c.column_name
+        WHERE c.table_schema = current_schema()
+          AND c.data_type IN ('double precision', 'real')
+    LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ALTER COLUMN %I TYPE %s USING ROUND(%I::numeric, %s)',
+            col.table_name, col.column_name, col.type_spec, col.column_name,
+            substring(col.type_spec FROM ',\s*(\d+)\)')
+        );
+        RAISE NOTICE 'money column %.% converted to %', col.table_name, col.column_name, col.type_spec;
+    END LOOP;
+END
+$$;
+
+-- 2) 历史 usage_logs.actual_cost 不在迁移中改写：迁移整体运行在单个事务里，
+--    一次性 UPDATE 大表会长时间持锁。改由在线任务 UsageLogMoneyBackfillService
+--    按 id 分批 ROUND(actual_cost, 8)（选主锁保证单实例执行，进度记在
+--    settings.usage_log_money_backfill_cursor，完成后置为 done）。回填完成前，
+--    升级前的记录与余额流水仍可能相差不到 1e-8 / 笔。
+--
+-- 3) 订阅与平台用量按记账精度累计（每个订阅 / 平台配额一行，体量远小于 usage_logs）。
+UPDATE user_subscriptions
+SET daily_usage_usd   = ROUND(daily_usage_usd, 8),
+    weekly_usage_usd  = ROUND(weekly_usage_usd, 8),
+    monthly_usage_usd = ROUND(monthly_usage_usd, 8)
+WHERE daily_usage_usd <> ROUND(daily_usage_usd, 8)
+   OR weekly_usage_usd <> ROUND(weekly_usage_usd, 8)
+   OR monthly_usage_usd <> ROUND(monthly_usage_usd, 8);
+
+UPDATE user_platform_quotas
+SET daily_usage_usd   = ROUND(daily_usage_usd, 8),
+    weekly_usage_usd  = ROUND(weekly_usage_usd, 8),
+    monthly_usage_usd = ROUND(monthly_usage_usd, 8)
+WHERE daily_usage_usd <> ROUND(daily_usage_usd, 8)
+   OR weekly_usage_usd <> ROUND(weekly_usage_usd, 8)
+   OR monthly_usage_usd <> ROUND(monthly_usage_usd, 8);
+
+COMMENT ON COLUMN usage_logs.actual_cost IS '实际扣除费用（USD），按记账精度 8 位小数 half-away-from-zero 舍入，与余额 / 配额扣减逐位一致';
+COMMENT ON COLUMN usage_logs.total_cost IS '原始总费用（USD），为各分项费用（10 位小数）的精确和';
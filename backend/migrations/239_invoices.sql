@@ -0,0 +1,72 @@
+-- Invoices: receipts for paid payment orders and monthly usage statements.
+-- A user or organization keeps one billing profile (company name, tax id,
+-- address); every issued document stores a snapshot of the profile and its
+-- line items, so later profile edits never change an issued document.
+-- Numbers are allocated from invoice_number_sequences inside the issuing
+-- transaction, giving a gapless sequence per calendar year. Documents are
+-- never deleted: voiding keeps the number, and a reissue gets a new number
+-- that points back at the voided document through replaces_invoice_id.
+
+CREATE TABLE IF NOT EXISTS billing_profiles (
+    owner_type    VARCHAR(16) NOT NULL
+        CHECK (owner_type IN ('user', 'organization')),
+    owner_id      BIGINT NOT NULL,
+    company_name  VARCHAR(200) NOT NULL DEFAULT '',
+    tax_id        VARCHAR(64) NOT NULL DEFAULT '',
+    address_line1 VARCHAR(200) NOT NULL DEFAULT '',
+    address_line2 VARCHAR(200) NOT NULL DEFAULT '',
+    city          VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code   VARCHAR(32) NOT NULL DEFAULT '',
+    country       VARCHAR(64) NOT NULL DEFAULT '',
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    notes         TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (owner_type, owner_id)
+);
+
+CREATE TABLE IF NOT EXISTS invoice_number_sequences (
+    year       INTEGER PRIMARY KEY,
+    last_value BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id                  BIGSERIAL PRIMARY KEY,
+    number              VARCHAR(64) NOT NULL UNIQUE,
+    kind                VARCHAR(32) NOT NULL
+        CHECK (kind IN ('receipt', 'usage_statement')),
+    status              VARCHAR(16) NOT NULL DEFAULT 'issued'
+        CHECK (status IN ('issued', 'void')),
+    owner_type          VARCHAR(16) NOT NULL
+        CHECK (owner_type IN ('user', 'organization')),
+    owner_id            BIGINT NOT NULL,
+    payment_order_id    BIGINT,
+    period_start        TIMESTAMPTZ,
+    period_end          TIMESTAMPTZ,
+    currency            VARCHAR(8) NOT NULL,
+    subtotal            DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    total               DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    bill_to             JSONB NOT NULL DEFAULT '{}'::jsonb,
+    line_items          JSONB NOT NULL DEFAULT '[]'::jsonb,
+    replaces_invoice_id BIGINT REFERENCES invoices(id),
+    issued_by           BIGINT,
+    void_reason         TEXT NOT NULL DEFAULT '',
+    voided_by           BIGINT,
+    voided_at           TIMESTAMPTZ,
+    issued_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_owner
+    ON invoices (owner_type, owner_id, issued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_invoices_issued_at
+    ON invoices (issued_at DESC);
+
+-- At most one live receipt per order and one live statement per owner and period;
+-- a voided document frees the slot for its reissue.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_invoices_live_receipt
+    ON invoices (payment_order_id)
+    WHERE kind = 'receipt' AND status = 'issued';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_invoices_live_statement
+    ON invoices (owner_type, owner_id, period_start)
+    WHERE kind = 'usage_statement' AND status = 'issued';
@@ -0,0 +1,41 @@
+-- Scheduled usage_logs exports to S3-compatible storage (CSV / Parquet).
+-- usage_export_state holds one row per export stream: the committed
+-- watermark (last exported usage_logs.id) and, while a batch is in flight,
+-- the pending id range. A batch records its range before uploading and only
+-- advances the watermark after every file of the batch is uploaded, so a run
+-- that fails halfway retries exactly the same range on the next schedule.
+-- Object keys are derived from the range, so a retry overwrites the files it
+-- already wrote instead of duplicating rows.
+-- usage_export_files is the manifest of uploaded objects for warehouse loads.
+
+CREATE TABLE IF NOT EXISTS usage_export_state (
+    stream          VARCHAR(32) PRIMARY KEY,
+    watermark_id    BIGINT NOT NULL DEFAULT 0,
+    pending_from_id BIGINT,
+    pending_to_id   BIGINT,
+    last_run_at     TIMESTAMPTZ,
+    last_success_at TIMESTAMPTZ,
+    last_error      TEXT NOT NULL DEFAULT '',
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO usage_export_state (stream) VALUES ('usage_logs')
+ON CONFLICT (stream) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS usage_export_files (
+    id             BIGSERIAL PRIMARY KEY,
+    stream         VARCHAR(32) NOT NULL,
+    object_key     VARCHAR(512) NOT NULL,
+    format         VARCHAR(16) NOT NULL,
+    partition_date DATE NOT NULL,
+    from_id        BIGINT NOT NULL,
+    to_id          BIGINT NOT NULL,
+    row_count      INTEGER NOT NULL,
+    size_bytes     BIGINT NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_usage_export_files_key
+    ON usage_export_files (object_key);
+CREATE INDEX IF NOT EXISTS idx_usage_export_files_stream_created
+    ON usage_export_files (stream, created_at DESC);
@@ -0,0 +1,38 @@
+-- Fine-grained admin RBAC.
+-- users.role keeps its admin/user semantics: role = 'admin' is a full administrator.
+-- A regular user bound to an admin_roles row becomes staff and may enter /admin,
+-- limited to the permission scopes of that role ("<resource>:read|write", write implies read).
+CREATE TABLE IF NOT EXISTS admin_roles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_roles_name ON admin_roles (name);
+
+-- One staff role per user; roles cannot be dropped while assigned.
+CREATE TABLE IF NOT EXISTS admin_role_assignments (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    role_id BIGINT NOT NULL REFERENCES admin_roles(id) ON DELETE RESTRICT,
+    assigned_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_role_assignments_role ON admin_role_assignments (role_id);
+
+-- Built-in roles. Permissions may be edited from the admin panel; names are fixed.
+INSERT INTO admin_roles (name, description, permissions, builtin) VALUES
+    ('support', 'View users and their usage, manage user API keys. No access to upstream accounts or credentials.',
+     '["api_keys:write", "dashboard:read", "subscriptions:read", "usage:read", "users:read"]'::jsonb, TRUE),
+    ('finance', 'Payments and affiliates.',
+     '["affiliates:write", "payments:write"]'::jsonb, TRUE),
+    ('ops', 'Manage upstream accounts, proxies and routing. No access to system settings.',
+     '["accounts:write", "channel_monitors:write", "channels:write", "dashboard:read", "error_passthrough:write", "groups:write", "ops:write", "proxies:write", "scheduled_tests:write", "tls_fingerprints:write", "usage:read"]'::jsonb, TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+-- Staff role of the actor at the time of the audited request ('' for full administrators).
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS admin_role VARCHAR(64) NOT NULL DEFAULT '';
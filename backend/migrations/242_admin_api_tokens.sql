@@ -0,0 +1,25 @@
+-- Long-lived admin API tokens for automation.
+-- Only the SHA-256 digest of the token is stored; the plaintext is shown once on creation.
+-- A token acts as its creator (created_by) and is limited to its scopes; it stops working
+-- as soon as the creator is no longer an active full administrator.
+CREATE TABLE IF NOT EXISTS admin_api_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    token_prefix VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    -- Scopes allowed to skip step-up 2FA; must be covered by scopes.
+    step_up_scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    -- Empty list = any source IP.
+    allowed_ips JSONB NOT NULL DEFAULT '[]'::jsonb,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    revoked_by BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_api_tokens_hash ON admin_api_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_admin_api_tokens_created_by ON admin_api_tokens (created_by);
@@ -0,0 +1,27 @@
+-- At-rest encryption of sensitive accounts.credentials sub-keys (envelope encryption).
+-- Each sensitive value is stored as "enc:v1:<data key id>:<ciphertext>", so the key
+-- that encrypted a value travels with the value. Data keys (DEKs) live here, wrapped
+-- by a master key (KEK) from security.credential_encryption.master_keys; the master
+-- key itself never touches the database.
+--
+-- Exactly one data key is active (new writes). Rotating the data key deactivates the
+-- current one and inserts a new one; the online re-encryption job then rewrites
+-- every account onto the active key. Retired keys stay here so values that have not
+-- been rewritten yet remain readable.
+--
+-- Existing plaintext rows are encrypted by the same job on first start with
+-- encryption enabled: the master key is only available to the application, so the
+-- backfill cannot run as plain SQL in this migration.
+
+CREATE TABLE IF NOT EXISTS credential_encryption_keys (
+    id            VARCHAR(32) PRIMARY KEY,
+    wrapped_key   TEXT NOT NULL,
+    master_key_id VARCHAR(64) NOT NULL,
+    active        BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at    TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_credential_encryption_keys_active
+    ON credential_encryption_keys (active)
+    WHERE active;
@@ -0,0 +1,3 @@
+-- 网关模拟 web_fetch 服务端工具按次计费：分组级单次价格覆盖。
+-- NULL 表示使用内置默认价 0.01 USD/次（与 web search 默认价一致）。
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS web_fetch_price_per_call DECIMAL(20,8);
@@ -0,0 +1,12 @@
+-- Per-API-key model policy: allow / deny patterns and model aliases.
+-- Patterns match exactly or by trailing * wildcard (same syntax as group model routing).
+-- Aliases are resolved first; the alias target is then checked against deny and allow lists.
+
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS model_allowlist JSONB DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS model_denylist JSONB DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS model_aliases JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN api_keys.model_allowlist IS '允许的模型模式（支持末尾 * 通配），为空表示不限制';
+COMMENT ON COLUMN api_keys.model_denylist IS '禁止的模型模式（支持末尾 * 通配），优先于允许列表';
+COMMENT ON COLUMN api_keys.model_aliases IS '模型别名映射，如 {"team-default": "claude-sonnet-4-5"}';
@@ -0,0 +1,9 @@
+-- Record the submitter's client IP on each batch so that in-process executions
+-- are checked against the API key IP allowlist / blacklist and per-IP limits
+-- exactly like the original request. Batches created before this migration have
+-- an empty value and are rejected by keys that carry an IP restriction.
+
+ALTER TABLE openai_batches
+    ADD COLUMN IF NOT EXISTS client_ip VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN openai_batches.client_ip IS '创建批次时的客户端 IP，批次执行时用于 API Key IP 限制与限流';
@@ -0,0 +1,93 @@
+/**
+ * Admin API token management.
+ *
+ * Admin API tokens are long-lived, scoped credentials for automation against
+ * /api/v1/admin/*. Only full administrators can manage them. The plaintext
+ * token is returned once, by create(); listings only expose its prefix.
+ */
+
+import { apiClient } from '../client'
+
+export interface AdminApiToken {
+  id: number
+  name: string
+  token_prefix: string
+  /** Normalized "<resource>:<action>" scopes, e.g. "users:read" or "*:read". */
+  scopes: string[]
+  /** Scopes allowed to bypass step-up 2FA; must be covered by scopes. */
+  step_up_scopes: string[]
+  allowed_ips: string[]
+  expires_at?: string
+  last_used_at?: string
+  last_used_ip: string
+  created_by: number
+  created_at: string
+  revoked_at?: string
+  revoked_by?: number
+}
+
+export interface CreateAdminApiTokenRequest {
+  name: string
+  scopes: string[]
+  step_up_scopes?: string[]
+  allowed_ips?: string[]
+  /** Omit (or 0) for a token that never expires. */
+  expires_in_days?: number
+}
+
+export interface CreateAdminApiTokenResponse {
+  /** Plaintext token; shown once and never retrievable again. */
+  token: string
+  api_token: AdminApiToken
+}
+
+export interface AdminResourceInfo {
+  key: string
+  description: string
+}
+
+export interface AdminPermissionCatalog {
+  resources: AdminResourceInfo[]
+  actions: string[]
+}
+
+/**
+ * List all admin API tokens, including revoked and expired ones.
+ */
+export async function list(): Promise<AdminApiToken[]> {
+  const { data } = await apiClient.get<AdminApiToken[]>('/admin/api-tokens')
+  return data
+}
+
+/**
+ * Issue a new token. Requires step-up 2FA (STEP_UP_REQUIRED triggers the TOTP prompt).
+ */
+export async function create(payload: CreateAdminApiTokenRequest): Promise<CreateAdminApiTokenResponse> {
+  const { data } = await apiClient.post<CreateAdminApiTokenResponse>('/admin/api-tokens', payload)
+  return data
+}
+
+/**
+ * Revoke a token immediately. Revoking an already revoked token is a no-op.
+ */
+export async function revoke(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/admin/api-tokens/${id}`)
+  return data
+}
+
+/**
+ * Resources and actions that scopes can be built from.
+ */
+export async function getPermissionCatalog(): Promise<AdminPermissionCatalog> {
+  const { data } = await apiClient.get<AdminPermissionCatalog>('/admin/rbac/permissions')
+  return data
+}
+
+export const apiTokensAPI = {
+  list,
+  create,
+  revoke,
+  getPermissionCatalog
+}
+
+export default apiTokensAPI
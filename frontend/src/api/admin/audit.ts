@@ -16,6 +16,8 @@ export interface AuditLog {
   actor_user_id?: number
   actor_email: string
   actor_role: string
+  /** Staff role of the actor; empty for full administrators. */
+  admin_role: string
   auth_method: string
   credential_masked: string
   action: string
@@ -38,6 +40,7 @@ export interface AuditLogQuery {
   actor_user_id?: number
   actor_email?: string
   auth_method?: string
+  admin_role?: string
   action?: string
   method?: string
   client_ip?: string
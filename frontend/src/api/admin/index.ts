@@ -35,6 +35,8 @@ import affiliatesAPI from './affiliates'
 import riskControlAPI from './riskControl'
 import adminComplianceAPI from './compliance'
 import auditAPI from './audit'
+import apiTokensAPI from './apiTokens'
+import invoicesAPI from './invoices'
 
 /**
  * Unified admin API object for convenient access
@@ -71,7 +73,9 @@ export const adminAPI = {
   affiliates: affiliatesAPI,
   riskControl: riskControlAPI,
   compliance: adminComplianceAPI,
-  audit: auditAPI
+  audit: auditAPI,
+  apiTokens: apiTokensAPI,
+  invoices: invoicesAPI
 }
 
 export {
@@ -106,13 +110,17 @@ export {
   affiliatesAPI,
   riskControlAPI,
   adminComplianceAPI,
-  auditAPI
+  auditAPI,
+  apiTokensAPI,
+  invoicesAPI
 }
 
 export default adminAPI
 
 // Re-export types used by components
 export type { AuditLog, AuditLogQuery, AuditLogListResponse } from './audit'
+export type { AdminInvoice, InvoiceQuery, InvoiceListResponse } from './invoices'
+export type { AdminApiToken, CreateAdminApiTokenRequest, CreateAdminApiTokenResponse } from './apiTokens'
 export type { BalanceHistoryItem } from './users'
 export type { ErrorPassthroughRule, CreateRuleRequest, UpdateRuleRequest } from './errorPassthrough'
 export type { BackupAgentHealth, DataManagementConfig } from './dataManagement'
@@ -0,0 +1,129 @@
+/**
+ * Admin invoice API.
+ *
+ * Receipts (one per paid order) and monthly usage statements. Issued invoices
+ * are immutable: corrections are made by voiding the invoice, or by reissuing
+ * it, which voids the original and issues a replacement with a new number.
+ */
+
+import { apiClient } from '../client'
+import type { PaginatedResponse } from '@/types'
+
+export type InvoiceKind = 'receipt' | 'usage_statement'
+export type InvoiceStatus = 'issued' | 'void'
+export type InvoiceOwnerType = 'user' | 'organization'
+
+export interface InvoiceBillTo {
+  owner_type: InvoiceOwnerType
+  owner_id: number
+  company_name: string
+  tax_id: string
+  address_line1: string
+  address_line2: string
+  city: string
+  postal_code: string
+  country: string
+  email: string
+  notes: string
+}
+
+export interface InvoiceLineItem {
+  description: string
+  quantity: number
+  input_tokens?: number
+  output_tokens?: number
+  cache_tokens?: number
+  amount: number
+}
+
+export interface AdminInvoice {
+  id: number
+  number: string
+  kind: InvoiceKind
+  status: InvoiceStatus
+  owner_type: InvoiceOwnerType
+  owner_id: number
+  payment_order_id?: number
+  period_start?: string
+  period_end?: string
+  currency: string
+  subtotal: number
+  total: number
+  bill_to: InvoiceBillTo
+  line_items: InvoiceLineItem[]
+  replaces_invoice_id?: number
+  replaces_number?: string
+  void_reason?: string
+  voided_at?: string
+  issued_at: string
+  issued_by?: number
+  voided_by?: number
+}
+
+export interface InvoiceQuery {
+  page?: number
+  page_size?: number
+  owner_type?: InvoiceOwnerType | ''
+  owner_id?: number
+  kind?: InvoiceKind | ''
+  status?: InvoiceStatus | ''
+  number?: string
+}
+
+export type InvoiceListResponse = PaginatedResponse<AdminInvoice>
+
+/**
+ * List invoices, newest first.
+ */
+export async function list(params: InvoiceQuery): Promise<InvoiceListResponse> {
+  const { data } = await apiClient.get('/admin/invoices', { params })
+  return data
+}
+
+/**
+ * Get a single invoice with its line items and bill-to snapshot.
+ */
+export async function get(id: number): Promise<AdminInvoice> {
+  const { data } = await apiClient.get(`/admin/invoices/${id}`)
+  return data
+}
+
+/**
+ * Download the rendered invoice document.
+ * @param format - 'html' or 'pdf'
+ */
+export async function download(id: number, format: 'html' | 'pdf' = 'html'): Promise<Blob> {
+  const response = await apiClient.get(`/admin/invoices/${id}/download`, {
+    params: { format },
+    responseType: 'blob'
+  })
+  return response.data
+}
+
+/**
+ * Void an issued invoice. The number is kept and the invoice stays listed.
+ */
+export async function voidInvoice(id: number, reason: string): Promise<AdminInvoice> {
+  const { data } = await apiClient.post(`/admin/invoices/${id}/void`, { reason })
+  return data
+}
+
+/**
+ * Void the invoice (if still issued) and issue a replacement with a new number,
+ * rebuilt from current order / usage data and the current billing profile.
+ * @returns the replacement invoice
+ */
+export async function reissue(id: number, reason: string): Promise<AdminInvoice> {
+  const { data } = await apiClient.post(`/admin/invoices/${id}/reissue`, { reason })
+  return data
+}
+
+export const invoicesAPI = {
+  list,
+  get,
+  download,
+  void: voidInvoice,
+  reissue
+}
+
+export default invoicesAPI
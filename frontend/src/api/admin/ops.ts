@@ -690,6 +690,21 @@ export type MetricType =
   | 'overload_account_count'
 export type Operator = '>' | '>=' | '<' | '<=' | '==' | '!='
 
+export type AlertNotifyChannelType = 'slack' | 'feishu' | 'dingtalk' | 'telegram' | 'webhook'
+
+export interface AlertNotifyChannel {
+  type: AlertNotifyChannelType
+  name?: string
+  url?: string
+  secret?: string
+  bot_token?: string
+  chat_id?: string
+  template?: string
+  resolved_template?: string
+  min_severity?: '' | 'critical' | 'warning' | 'info'
+  rate_limit_per_hour?: number
+}
+
 export interface AlertRule {
   id?: number
   name: string
@@ -703,6 +718,7 @@ export interface AlertRule {
   severity: OpsSeverity
   cooldown_minutes: number
   notify_email: boolean
+  notify_channels?: AlertNotifyChannel[]
   filters?: Record<string, any>
   created_at?: string
   updated_at?: string
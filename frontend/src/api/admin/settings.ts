@@ -1492,10 +1492,22 @@ export async function updateBetaPolicySettings(
 
 // --- Web Search Emulation Config ---
 
+export type WebSearchProviderType =
+  | "brave"
+  | "tavily"
+  | "searxng"
+  | "bing"
+  | "google_cse"
+  | "exa"
+  | "jina";
+
 export interface WebSearchProviderConfig {
-  type: "brave" | "tavily";
+  type: WebSearchProviderType;
   api_key: string;
   api_key_configured: boolean;
+  base_url?: string;
+  engine_id?: string;
+  priority?: number;
   quota_limit: number | null;
   subscribed_at: number | null;
   quota_used?: number;
@@ -1506,11 +1518,21 @@ export interface WebSearchProviderConfig {
 export interface WebSearchEmulationConfig {
   enabled: boolean;
   providers: WebSearchProviderConfig[];
+  fetch_page_content?: boolean;
+  fetch_max_pages?: number;
+  fetch_max_chars?: number;
+  web_fetch_enabled?: boolean;
 }
 
 export interface WebSearchTestResult {
   provider: string;
-  results: { url: string; title: string; snippet: string; page_age?: string }[];
+  results: {
+    url: string;
+    title: string;
+    snippet: string;
+    page_age?: string;
+    content?: string;
+  }[];
   query: string;
 }
 
@@ -31,6 +31,7 @@ export type OAuthLoginProvider =
   | 'dingtalk'
   | 'wechat'
   | 'oidc'
+  | 'saml'
 
 export interface OAuthLoginStart {
   provider: OAuthLoginProvider
@@ -604,6 +605,14 @@ export async function completeOIDCOAuthRegistration(
   return createPendingOIDCOAuthAccount(invitationCode, decision, affiliateCode)
 }
 
+export async function completeSAMLOAuthRegistration(
+  invitationCode: string,
+  decision?: OAuthAdoptionDecision,
+  affiliateCode?: string
+): Promise<OAuthTokenResponse> {
+  return createPendingSAMLOAuthAccount(invitationCode, decision, affiliateCode)
+}
+
 export async function completeWeChatOAuthRegistration(
   invitationCode: string,
   decision?: OAuthAdoptionDecision,
@@ -613,7 +622,7 @@ export async function completeWeChatOAuthRegistration(
 }
 
 async function createPendingOAuthAccount(
-  provider: 'linuxdo' | 'oidc' | 'wechat' | 'dingtalk',
+  provider: 'linuxdo' | 'oidc' | 'saml' | 'wechat' | 'dingtalk',
   invitationCode: string,
   decision?: OAuthAdoptionDecision,
   affiliateCode?: string
@@ -646,6 +655,14 @@ export async function createPendingOIDCOAuthAccount(
   return createPendingOAuthAccount('oidc', invitationCode, decision, affiliateCode)
 }
 
+export async function createPendingSAMLOAuthAccount(
+  invitationCode: string,
+  decision?: OAuthAdoptionDecision,
+  affiliateCode?: string
+): Promise<PendingOAuthCreateAccountResponse> {
+  return createPendingOAuthAccount('saml', invitationCode, decision, affiliateCode)
+}
+
 export async function createPendingWeChatOAuthAccount(
   invitationCode: string,
   decision?: OAuthAdoptionDecision,
@@ -712,6 +729,7 @@ export const authAPI = {
   exchangePendingOAuthCompletion,
   completeLinuxDoOAuthRegistration,
   completeOIDCOAuthRegistration,
+  completeSAMLOAuthRegistration,
   completeWeChatOAuthRegistration,
   createPendingDingTalkOAuthAccount
 }
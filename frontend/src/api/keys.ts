@@ -4,7 +4,13 @@
  */
 
 import { apiClient } from './client'
-import type { ApiKey, CreateApiKeyRequest, UpdateApiKeyRequest, PaginatedResponse } from '@/types'
+import type {
+  ApiKey,
+  ApiKeyModelPolicy,
+  CreateApiKeyRequest,
+  UpdateApiKeyRequest,
+  PaginatedResponse
+} from '@/types'
 
 /**
  * List all API keys for current user
@@ -55,6 +61,7 @@ export async function getById(id: number): Promise<ApiKey> {
  * @param quota - Optional quota limit in USD (0 = unlimited)
  * @param expiresInDays - Optional days until expiry (undefined = never expires)
  * @param rateLimitData - Optional rate limit fields
+ * @param modelPolicy - Optional model allowlist / denylist / aliases
  * @returns Created API key
  */
 export async function create(
@@ -65,7 +72,8 @@ export async function create(
   ipBlacklist?: string[],
   quota?: number,
   expiresInDays?: number,
-  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number }
+  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number },
+  modelPolicy?: ApiKeyModelPolicy
 ): Promise<ApiKey> {
   const payload: CreateApiKeyRequest = { name }
   if (groupId !== undefined) {
@@ -95,6 +103,15 @@ export async function create(
   if (rateLimitData?.rate_limit_7d && rateLimitData.rate_limit_7d > 0) {
     payload.rate_limit_7d = rateLimitData.rate_limit_7d
   }
+  if (modelPolicy?.model_allowlist && modelPolicy.model_allowlist.length > 0) {
+    payload.model_allowlist = modelPolicy.model_allowlist
+  }
+  if (modelPolicy?.model_denylist && modelPolicy.model_denylist.length > 0) {
+    payload.model_denylist = modelPolicy.model_denylist
+  }
+  if (modelPolicy?.model_aliases && Object.keys(modelPolicy.model_aliases).length > 0) {
+    payload.model_aliases = modelPolicy.model_aliases
+  }
 
   const { data } = await apiClient.post<ApiKey>('/keys', payload)
   return data
@@ -0,0 +1,153 @@
+/**
+ * User webhook API endpoints
+ * Handles webhook endpoint management and the delivery log
+ */
+
+import { apiClient } from './client'
+import type { PaginatedResponse } from '@/types'
+
+export type WebhookDeliveryStatus = 'pending' | 'succeeded' | 'failed'
+
+export interface WebhookEndpoint {
+  id: number
+  name: string
+  url: string
+  events: string[]
+  enabled: boolean
+  created_at: string
+  updated_at: string
+}
+
+export interface WebhookEndpointWithSecret extends WebhookEndpoint {
+  // Signing secret, only returned on create / rotate
+  secret: string
+}
+
+export interface WebhookEndpointRequest {
+  name?: string
+  url?: string
+  events?: string[]
+  enabled?: boolean
+}
+
+export interface WebhookDelivery {
+  id: number
+  endpoint_id: number
+  event_id: string
+  event_type: string
+  payload?: unknown
+  status: WebhookDeliveryStatus
+  attempts: number
+  next_attempt_at?: string
+  last_response_status?: number
+  last_response_body?: string
+  last_error?: string
+  last_duration_ms?: number
+  created_at: string
+  updated_at: string
+  delivered_at?: string
+}
+
+export interface WebhookDeliveryFilters {
+  endpoint_id?: number
+  event_type?: string
+  status?: WebhookDeliveryStatus
+}
+
+/**
+ * List the event types an endpoint can subscribe to
+ */
+export async function listEventTypes(): Promise<string[]> {
+  const { data } = await apiClient.get<{ events: string[] }>('/webhooks/events')
+  return data.events || []
+}
+
+/**
+ * List the current user's webhook endpoints
+ */
+export async function list(): Promise<WebhookEndpoint[]> {
+  const { data } = await apiClient.get<WebhookEndpoint[]>('/webhooks')
+  return data
+}
+
+/**
+ * Create a webhook endpoint; the signing secret is returned once
+ */
+export async function create(payload: WebhookEndpointRequest): Promise<WebhookEndpointWithSecret> {
+  const { data } = await apiClient.post<WebhookEndpointWithSecret>('/webhooks', payload)
+  return data
+}
+
+/**
+ * Update a webhook endpoint
+ */
+export async function update(id: number, payload: WebhookEndpointRequest): Promise<WebhookEndpoint> {
+  const { data } = await apiClient.put<WebhookEndpoint>(`/webhooks/${id}`, payload)
+  return data
+}
+
+/**
+ * Delete a webhook endpoint
+ */
+export async function remove(id: number): Promise<void> {
+  await apiClient.delete(`/webhooks/${id}`)
+}
+
+/**
+ * Rotate the signing secret; the old secret stops working immediately
+ */
+export async function rotateSecret(id: number): Promise<WebhookEndpointWithSecret> {
+  const { data } = await apiClient.post<WebhookEndpointWithSecret>(`/webhooks/${id}/rotate-secret`)
+  return data
+}
+
+/**
+ * Queue a webhook.ping delivery to the endpoint
+ */
+export async function sendTest(id: number): Promise<void> {
+  await apiClient.post(`/webhooks/${id}/test`)
+}
+
+/**
+ * List the delivery log
+ */
+export async function listDeliveries(
+  page: number = 1,
+  pageSize: number = 20,
+  filters?: WebhookDeliveryFilters
+): Promise<PaginatedResponse<WebhookDelivery>> {
+  const { data } = await apiClient.get<PaginatedResponse<WebhookDelivery>>('/webhooks/deliveries', {
+    params: { page, page_size: pageSize, ...filters }
+  })
+  return data
+}
+
+/**
+ * Get a single delivery including its payload and last response
+ */
+export async function getDelivery(id: number): Promise<WebhookDelivery> {
+  const { data } = await apiClient.get<WebhookDelivery>(`/webhooks/deliveries/${id}`)
+  return data
+}
+
+/**
+ * Queue a delivery to be sent again
+ */
+export async function redeliver(id: number): Promise<void> {
+  await apiClient.post(`/webhooks/deliveries/${id}/redeliver`)
+}
+
+export const webhooksAPI = {
+  listEventTypes,
+  list,
+  create,
+  update,
+  remove,
+  rotateSecret,
+  sendTest,
+  listDeliveries,
+  getDelivery,
+  redeliver
+}
+
+export default webhooksAPI
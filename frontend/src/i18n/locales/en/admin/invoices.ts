@@ -0,0 +1,56 @@
+export default {
+  invoices: {
+    title: 'Invoices',
+    description: 'Receipts for paid orders and monthly usage statements. Issued invoices are immutable: void an invoice to cancel it, or reissue it to void the original and issue a replacement with a new number.',
+    empty: 'No invoices yet',
+    loadFailed: 'Failed to load invoices',
+    downloadFailed: 'Failed to download invoice',
+    actionFailed: 'Operation failed',
+    replaces: 'Replaces {number}',
+    reason: 'Reason',
+    reasonPlaceholder: 'Recorded on the original invoice',
+    filters: {
+      all: 'All',
+      number: 'Invoice Number',
+      ownerType: 'Owner Type',
+      ownerId: 'Owner ID',
+      kind: 'Kind',
+      status: 'Status'
+    },
+    columns: {
+      number: 'Number',
+      kind: 'Kind',
+      owner: 'Bill To',
+      total: 'Total',
+      status: 'Status',
+      issuedAt: 'Issued At'
+    },
+    kinds: {
+      receipt: 'Receipt',
+      usage_statement: 'Usage Statement'
+    },
+    statuses: {
+      issued: 'Issued',
+      void: 'Void'
+    },
+    ownerTypes: {
+      user: 'User',
+      organization: 'Organization'
+    },
+    actions: {
+      download: 'Download',
+      void: 'Void',
+      reissue: 'Reissue'
+    },
+    voidDialog: {
+      title: 'Void Invoice',
+      message: 'Void invoice {number}? The number is kept and the invoice stays listed as void.',
+      success: 'Invoice {number} voided'
+    },
+    reissueDialog: {
+      title: 'Reissue Invoice',
+      message: 'Reissue invoice {number}? The original is voided and a replacement is issued from current data with a new number.',
+      success: 'Replacement invoice {number} issued'
+    }
+  }
+}
@@ -554,6 +554,33 @@ export default {
           enabled: 'Enabled',
           notifyEmail: 'Send email notifications'
         },
+        channels: {
+          title: 'Notification channels',
+          hint: 'Send this rule to chat bots or webhooks in addition to email. Silencing and rate limits apply per channel.',
+          add: 'Add channel',
+          empty: 'No channels configured',
+          entryTitle: 'Channel #{n}',
+          type: 'Type',
+          name: 'Name (optional)',
+          url: 'Webhook URL',
+          secret: 'Signing secret (optional)',
+          botToken: 'Bot token',
+          chatId: 'Chat ID',
+          minSeverity: 'Minimum severity',
+          allSeverities: 'All severities',
+          rateLimit: 'Max messages per hour (0 = unlimited)',
+          template: 'Firing template (optional)',
+          resolvedTemplate: 'Resolved template (optional)',
+          templatePlaceholder: 'Go text/template with variables such as .rule_name and .metric_value; empty uses the default',
+          maskedHint: 'Saved secrets are shown masked. Leave them unchanged to keep the stored values, or replace them with new ones.',
+          types: {
+            slack: 'Slack',
+            feishu: 'Feishu',
+            dingtalk: 'DingTalk',
+            telegram: 'Telegram',
+            webhook: 'Webhook'
+          }
+        },
         validation: {
           title: 'Please fix the following issues',
           invalid: 'Invalid rule',
@@ -564,7 +591,10 @@ export default {
           thresholdRequired: 'Threshold must be a number',
           windowRange: 'Window must be one of: 1, 5, 60 minutes',
           sustainedRange: 'Sustained must be between 1 and 1440 samples',
-          cooldownRange: 'Cooldown must be between 0 and 1440 minutes'
+          cooldownRange: 'Cooldown must be between 0 and 1440 minutes',
+          channelUrlRequired: 'Each channel needs a webhook URL',
+          channelTelegramRequired: 'Telegram channels need a bot token and chat ID',
+          channelRateLimit: 'Channel rate limit must be 0 or greater'
         }
       },
       runtime: {
@@ -563,6 +563,10 @@ export default {
       groupChangedSuccess: 'Group updated successfully',
       groupChangedWithGrant: 'Group updated. User auto-granted access to "{group}"',
       groupChangeFailed: 'Failed to update group',
+      modelPolicy: 'Model Restriction',
+      modelPolicyNone: 'Unrestricted',
+      modelPolicyUpdated: 'Model restriction updated',
+      modelPolicyUpdateFailed: 'Failed to update model restriction',
       noUsersYet: 'No users yet',
       createFirstUser: 'Create your first user to get started.',
       userCreated: 'User created successfully',
@@ -1029,6 +1033,12 @@ export default {
           'Leave empty to use the default $0.01 per call (official pricing: $10 per 1,000 calls); 0 means free. The group rate multiplier is applied on top.',
         finalPricePreview: 'Per-call price after current multiplier: {price}'
       },
+      webFetchPricing: {
+        title: 'Web Fetch Pricing',
+        pricePerCall: 'Price per fetched page (USD)',
+        pricePerCallHint:
+          'Applies to emulated web_fetch requests. Leave empty to use the default $0.01 per page; 0 means free. Only successfully fetched pages are billed; the group rate multiplier is applied on top.'
+      },
       peakRate: {
         enable: 'Enable peak rate multiplier',
         peakStart: 'Peak start',
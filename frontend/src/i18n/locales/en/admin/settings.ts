@@ -561,6 +561,17 @@ export default {
         apiKey: 'API Key',
         apiKeyPlaceholder: 'Enter API Key',
         apiKeyConfigured: 'Configured',
+        baseUrl: 'Base URL',
+        baseUrlOptional: 'Optional endpoint override',
+        engineId: 'Search Engine ID (cx)',
+        priority: 'Priority',
+        priorityHint: 'Lower tiers are tried first; same tier is balanced by remaining quota',
+        fetchPageContent: 'Fetch Page Content',
+        fetchPageContentHint: 'Fetch the top results and return extracted page text instead of snippets only',
+        webFetchEnabled: 'Web Fetch Emulation',
+        webFetchEnabledHint:
+          'Serve requests whose only tool is web_fetch by fetching the pages in the gateway (accounts and channels with web search emulation enabled).',
+        fetchMaxPages: 'Pages to fetch',
         showApiKey: 'Show',
         hideApiKey: 'Hide',
         copyApiKey: 'Copy',
@@ -955,6 +966,64 @@ export default {
         metricsInterval: 'Metrics Collection Interval (seconds)',
         metricsIntervalHint: 'How often to collect system/request metrics (60-3600 seconds)'
       },
+      adminApiTokens: {
+        title: 'Admin API Tokens',
+        description: 'Scoped, revocable tokens for automation. Each token acts as the administrator who created it, limited to its scopes.',
+        create: 'Create Token',
+        empty: 'No admin API tokens yet',
+        columns: {
+          name: 'Name',
+          scopes: 'Scopes',
+          allowedIps: 'IP Allowlist',
+          expiresAt: 'Expires',
+          lastUsed: 'Last Used',
+          status: 'Status'
+        },
+        status: {
+          active: 'Active',
+          expired: 'Expired',
+          revoked: 'Revoked'
+        },
+        stepUpBypass: 'Skips 2FA for: {scopes}',
+        anyIp: 'Any',
+        neverExpires: 'Never',
+        neverUsed: 'Never used',
+        revoke: 'Revoke',
+        revokeConfirm: 'Revoke token "{name}"? Automation using it stops working immediately.',
+        revoked: 'Token revoked',
+        presets: {
+          custom: 'Custom',
+          readOnly: 'Read-only (all resources)',
+          full: 'Full access'
+        },
+        presetHints: {
+          custom: 'Choose read or write access per resource. Routes that require 2FA stay blocked unless "Skip 2FA" is checked.',
+          readOnly: 'Can read every admin resource; cannot change anything.',
+          full: 'Same access as the creator. Routes that require 2FA stay blocked.'
+        },
+        form: {
+          name: 'Name',
+          namePlaceholder: 'e.g. CI deploy script',
+          nameRequired: 'Please enter a token name',
+          access: 'Access',
+          resource: 'Resource',
+          permission: 'Permission',
+          skipStepUp: 'Skip 2FA',
+          none: 'None',
+          read: 'Read',
+          write: 'Read & write',
+          scopesRequired: 'Select at least one permission',
+          allowedIps: 'IP allowlist',
+          allowedIpsHint: 'One IP or CIDR per line. Leave empty to allow any IP.',
+          expiresInDays: 'Expires in (days)',
+          expiresInDaysHint: 'Leave empty or 0 for a token that never expires.'
+        },
+        created: {
+          title: 'Token Created',
+          hint: 'Copy the token now. It will not be shown again.',
+          usage: 'Send it as "Authorization: Bearer <token>" to /api/v1/admin/* endpoints.'
+        }
+      },
       adminApiKey: {
         title: 'Admin API Key',
         description: 'Global API key for external system integration with full admin access',
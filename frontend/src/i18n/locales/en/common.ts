@@ -192,6 +192,7 @@ export default {
     buySubscription: 'Recharge / Subscription',
     docs: 'Docs',
     myOrders: 'My Orders',
+    webhooks: 'Webhooks',
     orderManagement: 'Orders',
     paymentDashboard: 'Payment Dashboard',
     paymentConfig: 'Payment Config',
@@ -205,6 +206,7 @@ export default {
     contentModeration: 'Content Moderation',
     promptAudit: 'Prompt Audit',
     auditLogs: 'Audit Logs',
+    invoices: 'Invoices',
   },
 
   // Auth
@@ -354,6 +356,9 @@ export default {
       completing: 'Completing registration…',
       completeRegistrationFailed: 'Registration failed. Please check your invitation code and try again.'
     },
+    saml: {
+      signIn: 'Sign in with {providerName}'
+    },
     oauthFlow: {
       profileDetailsTitle: 'Use {providerName} profile details',
       profileDetailsDescription: 'Choose whether to apply the nickname or avatar from {providerName} to this account.',
@@ -388,6 +393,7 @@ export default {
     dingtalkCallbackPageTitle: 'DingTalk Sign-In Callback',
     dingtalkProviderName: 'DingTalk',
     oidcCallbackPageTitle: 'OIDC Sign-In Callback',
+    samlCallbackPageTitle: 'SSO Sign-In Callback',
     oauthCallbackPageTitle: 'OAuth Callback',
     wechatProviderName: 'WeChat',
     wechatCallbackPageTitle: 'WeChat Sign-In Callback',
@@ -208,6 +208,18 @@ export default {
     ipBlacklistPlaceholder: '1.2.3.4\n5.6.0.0/16',
     ipBlacklistHint: 'One IP or CIDR per line. These IPs will be blocked from using this key.',
     ipRestrictionEnabled: 'IP restriction enabled',
+    modelRestriction: 'Model Restriction',
+    modelAllowlist: 'Allowed Models',
+    modelAllowlistPlaceholder: 'claude-haiku-*\ngpt-4o-mini',
+    modelAllowlistHint: 'One model per line; a trailing * matches a prefix. When set, only these models can be used.',
+    modelDenylist: 'Denied Models',
+    modelDenylistPlaceholder: 'claude-opus-*',
+    modelDenylistHint: 'One model per line; a trailing * matches a prefix. Takes precedence over the allowlist.',
+    modelAliases: 'Model Aliases',
+    modelAliasesPlaceholder: 'fast=claude-haiku-4-5\nsmart=claude-sonnet-4-5',
+    modelAliasesHint: 'One alias=model per line. Requests using the alias are sent as the target model and listed in /v1/models.',
+    modelAliasesInvalid: 'Invalid alias line "{line}", expected alias=model',
+    modelRestrictionEnabled: 'Model restriction enabled',
     ccSwitchNotInstalled: 'CC-Switch is not installed or the protocol handler is not registered. Please install CC-Switch first or manually copy the API key.',
     ccsClientSelect: {
       title: 'Select Client',
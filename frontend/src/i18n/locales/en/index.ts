@@ -5,6 +5,7 @@ import channelMonitorV2 from './channelMonitorV2'
 import batchImage from './batchImage'
 import admin from './admin'
 import misc from './misc'
+import webhooks from './webhooks'
 
 export default {
   ...landing,
@@ -14,4 +15,5 @@ export default {
   ...batchImage,
   admin,
   ...misc,
+  ...webhooks,
 }
@@ -0,0 +1,56 @@
+export default {
+  webhooks: {
+    title: 'Webhooks',
+    description: 'Receive signed HTTP callbacks for balance, API key, subscription and payment events',
+    endpoints: {
+      title: 'Endpoints',
+      description: 'Each delivery is signed with the endpoint secret and retried with backoff on failure',
+      create: 'Add Endpoint',
+      edit: 'Edit Endpoint',
+      delete: 'Delete Endpoint',
+      deleteConfirm: 'Delete endpoint "{name}"? Pending deliveries to it will be discarded.',
+      empty: 'No webhook endpoints yet',
+      sendTest: 'Send Test',
+      testQueued: 'Test event queued',
+      viewDeliveries: 'Deliveries',
+      rotateSecret: 'Rotate Secret',
+      rotateConfirm: 'The current secret stops working immediately. Continue?'
+    },
+    form: {
+      name: 'Name',
+      namePlaceholder: 'Optional, e.g. Billing alerts',
+      url: 'Callback URL',
+      events: 'Subscribed Events',
+      enabled: 'Enabled'
+    },
+    secret: {
+      title: 'Signing Secret',
+      hint: 'Copy this secret now. It will not be shown again.'
+    },
+    deliveries: {
+      title: 'Delivery Log',
+      detail: 'Delivery Details',
+      event: 'Event',
+      endpoint: 'Endpoint',
+      status: 'Status',
+      attempts: 'Attempts',
+      response: 'Response',
+      createdAt: 'Created',
+      nextAttempt: 'Next Attempt',
+      deliveredAt: 'Delivered At',
+      lastError: 'Last Error',
+      lastResponse: 'Last Response',
+      payload: 'Payload',
+      networkError: 'Network error',
+      allEndpoints: 'All endpoints',
+      allEvents: 'All events',
+      redeliver: 'Redeliver',
+      redeliverQueued: 'Redelivery queued'
+    },
+    status: {
+      pending: 'Pending',
+      succeeded: 'Succeeded',
+      failed: 'Failed'
+    }
+  },
+}
@@ -0,0 +1,56 @@
+export default {
+  invoices: {
+    title: '发票管理',
+    description: '已支付订单的收据与按月用量账单。已开具的票据不可修改：可作废，或重开（作废原票据并以新编号开具替代票据）。',
+    empty: '暂无票据',
+    loadFailed: '加载票据失败',
+    downloadFailed: '下载票据失败',
+    actionFailed: '操作失败',
+    replaces: '替代 {number}',
+    reason: '原因',
+    reasonPlaceholder: '将记录在原票据上',
+    filters: {
+      all: '全部',
+      number: '票据编号',
+      ownerType: '归属类型',
+      ownerId: '归属 ID',
+      kind: '类型',
+      status: '状态'
+    },
+    columns: {
+      number: '编号',
+      kind: '类型',
+      owner: '抬头',
+      total: '合计',
+      status: '状态',
+      issuedAt: '开具时间'
+    },
+    kinds: {
+      receipt: '收据',
+      usage_statement: '用量账单'
+    },
+    statuses: {
+      issued: '已开具',
+      void: '已作废'
+    },
+    ownerTypes: {
+      user: '用户',
+      organization: '组织'
+    },
+    actions: {
+      download: '下载',
+      void: '作废',
+      reissue: '重开'
+    },
+    voidDialog: {
+      title: '作废票据',
+      message: '确定作废票据 {number}？编号保留，票据将以已作废状态保留在列表中。',
+      success: '票据 {number} 已作废'
+    },
+    reissueDialog: {
+      title: '重开票据',
+      message: '确定重开票据 {number}？原票据将被作废，并按当前数据以新编号开具替代票据。',
+      success: '已开具替代票据 {number}'
+    }
+  }
+}
@@ -554,6 +554,33 @@ export default {
           enabled: '启用',
           notifyEmail: '发送邮件通知'
         },
+        channels: {
+          title: '通知渠道',
+          hint: '除邮件外，把该规则推送到聊天机器人或 Webhook。静默与限流对每个渠道独立生效。',
+          add: '新增渠道',
+          empty: '暂未配置通知渠道',
+          entryTitle: '渠道 #{n}',
+          type: '类型',
+          name: '名称（可选）',
+          url: 'Webhook 地址',
+          secret: '签名密钥（可选）',
+          botToken: 'Bot Token',
+          chatId: 'Chat ID',
+          minSeverity: '最低级别',
+          allSeverities: '全部级别',
+          rateLimit: '每小时最多发送条数（0 = 不限）',
+          template: '告警模板（可选）',
+          resolvedTemplate: '恢复模板（可选）',
+          templatePlaceholder: 'Go text/template，可用变量如 .rule_name、.metric_value；留空使用默认模板',
+          maskedHint: '已保存的密钥以脱敏形式显示。保持不变即沿用原值，如需更换请直接填写新值。',
+          types: {
+            slack: 'Slack',
+            feishu: '飞书',
+            dingtalk: '钉钉',
+            telegram: 'Telegram',
+            webhook: 'Webhook'
+          }
+        },
         validation: {
           title: '请先修正以下问题',
           invalid: '规则不合法',
@@ -564,7 +591,10 @@ export default {
           thresholdRequired: '阈值必须为数字',
           windowRange: '统计窗口必须为 1 / 5 / 60 分钟之一',
           sustainedRange: '连续样本数必须在 1 到 1440 之间',
-          cooldownRange: '冷却期必须在 0 到 1440 分钟之间'
+          cooldownRange: '冷却期必须在 0 到 1440 分钟之间',
+          channelUrlRequired: '每个渠道都需要填写 Webhook 地址',
+          channelTelegramRequired: 'Telegram 渠道需要填写 Bot Token 和 Chat ID',
+          channelRateLimit: '渠道限流必须大于等于 0'
         }
       },
       runtime: {
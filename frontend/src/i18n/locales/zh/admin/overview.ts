@@ -543,6 +543,10 @@ export default {
       groupChangedSuccess: '分组修改成功',
       groupChangedWithGrant: '分组修改成功，已自动为用户添加「{group}」分组权限',
       groupChangeFailed: '分组修改失败',
+      modelPolicy: '模型限制',
+      modelPolicyNone: '不限制',
+      modelPolicyUpdated: '模型限制已更新',
+      modelPolicyUpdateFailed: '模型限制更新失败',
       noUsersYet: '暂无用户',
       createFirstUser: '创建您的第一个用户以开始使用系统',
       userCreated: '用户创建成功',
@@ -1026,6 +1030,12 @@ export default {
           '留空使用默认价 $0.01/次（官方定价 $10/1000 次）；填 0 表示免费。实际扣费会叠加分组费率倍数。',
         finalPricePreview: '应用当前倍率后的单次价格：{price}'
       },
+      webFetchPricing: {
+        title: '网页抓取计费',
+        pricePerCall: '抓取单页价格（USD/页）',
+        pricePerCallHint:
+          '用于模拟 web_fetch 请求。留空使用默认价 $0.01/页；填 0 表示免费。仅对成功抓取的页面计费，实际扣费会叠加分组费率倍数。'
+      },
       peakRate: {
         enable: '启用高峰倍率',
         peakStart: '高峰开始',
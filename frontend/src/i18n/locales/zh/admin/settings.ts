@@ -554,6 +554,17 @@ export default {
         apiKey: 'API Key',
         apiKeyPlaceholder: '输入 API Key',
         apiKeyConfigured: '已配置',
+        baseUrl: 'Base URL',
+        baseUrlOptional: '可选，自定义接口地址',
+        engineId: '搜索引擎 ID (cx)',
+        priority: '优先级',
+        priorityHint: '数值越小越优先；同一优先级按剩余配额分配',
+        fetchPageContent: '抓取网页正文',
+        fetchPageContentHint: '抓取排名靠前的结果页面，返回提取后的正文而不仅是摘要',
+        webFetchEnabled: '网页抓取模拟',
+        webFetchEnabledHint:
+          '仅携带 web_fetch 工具的请求由网关自行抓取页面并构造响应（适用于开启了网页搜索模拟的账号与渠道）。',
+        fetchMaxPages: '抓取页数',
         showApiKey: '显示',
         hideApiKey: '隐藏',
         copyApiKey: '复制',
@@ -950,6 +961,64 @@ export default {
         metricsInterval: '采集频率（秒）',
         metricsIntervalHint: '系统/请求指标采集频率（60-3600 秒）'
       },
+      adminApiTokens: {
+        title: '管理 API Token',
+        description: '用于自动化脚本的可吊销、按权限范围授权的 Token。Token 以创建者身份执行，权限仅限所选范围。',
+        create: '创建 Token',
+        empty: '暂无管理 API Token',
+        columns: {
+          name: '名称',
+          scopes: '权限范围',
+          allowedIps: 'IP 白名单',
+          expiresAt: '过期时间',
+          lastUsed: '最近使用',
+          status: '状态'
+        },
+        status: {
+          active: '有效',
+          expired: '已过期',
+          revoked: '已吊销'
+        },
+        stepUpBypass: '免 2FA：{scopes}',
+        anyIp: '不限',
+        neverExpires: '永不过期',
+        neverUsed: '从未使用',
+        revoke: '吊销',
+        revokeConfirm: '确定吊销 Token「{name}」？使用它的自动化任务将立即失效。',
+        revoked: 'Token 已吊销',
+        presets: {
+          custom: '自定义',
+          readOnly: '只读（全部资源）',
+          full: '完全访问'
+        },
+        presetHints: {
+          custom: '按资源选择只读或读写权限。需要 2FA 的接口默认仍被拦截，勾选「免 2FA」后放行。',
+          readOnly: '可读取全部管理资源，不能做任何修改。',
+          full: '与创建者权限相同，需要 2FA 的接口仍被拦截。'
+        },
+        form: {
+          name: '名称',
+          namePlaceholder: '例如：CI 部署脚本',
+          nameRequired: '请输入 Token 名称',
+          access: '访问权限',
+          resource: '资源',
+          permission: '权限',
+          skipStepUp: '免 2FA',
+          none: '无',
+          read: '只读',
+          write: '读写',
+          scopesRequired: '请至少选择一项权限',
+          allowedIps: 'IP 白名单',
+          allowedIpsHint: '每行一个 IP 或 CIDR，留空表示不限制来源 IP。',
+          expiresInDays: '有效期（天）',
+          expiresInDaysHint: '留空或填 0 表示永不过期。'
+        },
+        created: {
+          title: 'Token 已创建',
+          hint: '请立即复制 Token，关闭后将无法再次查看。',
+          usage: '调用 /api/v1/admin/* 接口时以 "Authorization: Bearer <token>" 发送。'
+        }
+      },
       adminApiKey: {
         title: '管理员 API Key',
         description: '用于外部系统集成的全局 API Key，拥有完整的管理员权限',
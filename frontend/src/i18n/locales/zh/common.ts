@@ -192,6 +192,7 @@ export default {
     buySubscription: '充值/订阅',
     docs: '文档',
     myOrders: '我的订单',
+    webhooks: 'Webhook',
     orderManagement: '订单管理',
     paymentDashboard: '支付概览',
     paymentConfig: '支付配置',
@@ -205,6 +206,7 @@ export default {
     contentModeration: '内容审核',
     promptAudit: '提示词审计',
     auditLogs: '操作日志',
+    invoices: '发票管理',
   },
 
   // Auth
@@ -352,6 +354,9 @@ export default {
       completing: '正在完成注册...',
       completeRegistrationFailed: '注册失败，请检查邀请码后重试。'
     },
+    saml: {
+      signIn: '通过 {providerName} 单点登录'
+    },
     oauthFlow: {
       profileDetailsTitle: '使用 {providerName} 资料',
       profileDetailsDescription: '选择是否将 {providerName} 的昵称或头像应用到当前账户。',
@@ -386,6 +391,7 @@ export default {
     dingtalkCallbackPageTitle: '钉钉登录回调',
     dingtalkProviderName: '钉钉',
     oidcCallbackPageTitle: 'OIDC 登录回调',
+    samlCallbackPageTitle: 'SSO 登录回调',
     oauthCallbackPageTitle: 'OAuth 回调',
     wechatProviderName: '微信',
     wechatCallbackPageTitle: '微信登录回调',
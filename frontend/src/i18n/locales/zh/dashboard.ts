@@ -212,6 +212,18 @@ export default {
     ipBlacklistPlaceholder: '1.2.3.4\n5.6.0.0/16',
     ipBlacklistHint: '每行一个 IP 或 CIDR，这些 IP 将被禁止使用此密钥',
     ipRestrictionEnabled: '已配置 IP 限制',
+    modelRestriction: '模型限制',
+    modelAllowlist: '允许的模型',
+    modelAllowlistPlaceholder: 'claude-haiku-*\ngpt-4o-mini',
+    modelAllowlistHint: '每行一个模型，末尾 * 表示前缀匹配；设置后仅允许使用这些模型',
+    modelDenylist: '禁止的模型',
+    modelDenylistPlaceholder: 'claude-opus-*',
+    modelDenylistHint: '每行一个模型，末尾 * 表示前缀匹配；优先于允许列表',
+    modelAliases: '模型别名',
+    modelAliasesPlaceholder: 'fast=claude-haiku-4-5\nsmart=claude-sonnet-4-5',
+    modelAliasesHint: '每行一个 别名=模型，使用别名的请求将按目标模型转发，并出现在 /v1/models 中',
+    modelAliasesInvalid: '别名格式错误："{line}"，应为 别名=模型',
+    modelRestrictionEnabled: '已配置模型限制',
     ccSwitchNotInstalled:
       'CC-Switch 未安装或协议处理程序未注册。请先安装 CC-Switch 或手动复制 API 密钥。',
     ccsClientSelect: {
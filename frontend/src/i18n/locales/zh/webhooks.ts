@@ -0,0 +1,56 @@
+export default {
+  webhooks: {
+    title: 'Webhook',
+    description: '在余额、API 密钥、订阅和支付事件发生时接收签名的 HTTP 回调',
+    endpoints: {
+      title: '回调端点',
+      description: '每次投递都使用端点密钥签名，失败后按退避策略自动重试',
+      create: '添加端点',
+      edit: '编辑端点',
+      delete: '删除端点',
+      deleteConfirm: '确定删除端点「{name}」吗？未完成的投递将被丢弃。',
+      empty: '暂无 Webhook 端点',
+      sendTest: '发送测试',
+      testQueued: '测试事件已加入投递队列',
+      viewDeliveries: '投递记录',
+      rotateSecret: '轮换密钥',
+      rotateConfirm: '当前密钥将立即失效，确定继续吗？'
+    },
+    form: {
+      name: '名称',
+      namePlaceholder: '可选，例如：账单告警',
+      url: '回调地址',
+      events: '订阅事件',
+      enabled: '启用'
+    },
+    secret: {
+      title: '签名密钥',
+      hint: '请立即复制此密钥，关闭后将无法再次查看。'
+    },
+    deliveries: {
+      title: '投递记录',
+      detail: '投递详情',
+      event: '事件',
+      endpoint: '端点',
+      status: '状态',
+      attempts: '尝试次数',
+      response: '响应',
+      createdAt: '创建时间',
+      nextAttempt: '下次尝试',
+      deliveredAt: '投递时间',
+      lastError: '最近错误',
+      lastResponse: '最近响应',
+      payload: '请求体',
+      networkError: '网络错误',
+      allEndpoints: '全部端点',
+      allEvents: '全部事件',
+      redeliver: '重新投递',
+      redeliverQueued: '已加入重新投递队列'
+    },
+    status: {
+      pending: '待投递',
+      succeeded: '成功',
+      failed: '失败'
+    }
+  },
+}
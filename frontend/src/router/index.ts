@@ -138,6 +138,17 @@ const routes: RouteRecordRaw[] = [
       titleKey: 'auth.oidcCallbackPageTitle'
     }
   },
+  {
+    path: '/auth/saml/callback',
+    name: 'SAMLOAuthCallback',
+    component: () => import('@/views/auth/OidcCallbackView.vue'),
+    props: { provider: 'saml' },
+    meta: {
+      requiresAuth: false,
+      title: 'SSO Sign-In Callback',
+      titleKey: 'auth.samlCallbackPageTitle'
+    }
+  },
   {
     path: '/forgot-password',
     name: 'ForgotPassword',
@@ -252,6 +263,18 @@ const routes: RouteRecordRaw[] = [
       descriptionKey: 'redeem.description'
     }
   },
+  {
+    path: '/webhooks',
+    name: 'Webhooks',
+    component: () => import('@/views/user/WebhooksView.vue'),
+    meta: {
+      requiresAuth: true,
+      requiresAdmin: false,
+      title: 'Webhooks',
+      titleKey: 'webhooks.title',
+      descriptionKey: 'webhooks.description'
+    }
+  },
   {
     path: '/affiliate',
     name: 'Affiliate',
@@ -437,6 +460,18 @@ const routes: RouteRecordRaw[] = [
       descriptionKey: 'admin.audit.description'
     }
   },
+  {
+    path: '/admin/invoices',
+    name: 'AdminInvoices',
+    component: () => import('@/views/admin/InvoicesView.vue'),
+    meta: {
+      requiresAuth: true,
+      requiresAdmin: true,
+      title: 'Invoices',
+      titleKey: 'admin.invoices.title',
+      descriptionKey: 'admin.invoices.description'
+    }
+  },
   {
     path: '/admin/users',
     name: 'AdminUsers',
@@ -745,6 +780,7 @@ const BACKEND_MODE_CALLBACK_PATHS = [
   '/auth/dingtalk/callback',
   '/auth/dingtalk/email-completion',
   '/auth/oidc/callback',
+  '/auth/saml/callback',
   '/auth/wechat/callback',
   '/auth/wechat/payment/callback',
 ]
@@ -360,6 +360,8 @@ export const useAppStore = defineStore('app', () => {
         wechat_oauth_mobile_enabled: false,
         oidc_oauth_enabled: false,
         oidc_oauth_provider_name: 'OIDC',
+        saml_oauth_enabled: false,
+        saml_oauth_provider_name: '',
         github_oauth_enabled: false,
         google_oauth_enabled: false,
         backend_mode_enabled: false,
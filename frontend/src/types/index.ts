@@ -251,6 +251,8 @@ export interface PublicSettings {
   wechat_oauth_mobile_enabled?: boolean
   oidc_oauth_enabled: boolean
   oidc_oauth_provider_name: string
+  saml_oauth_enabled?: boolean
+  saml_oauth_provider_name?: string
   github_oauth_enabled: boolean
   google_oauth_enabled: boolean
   backend_mode_enabled: boolean
@@ -580,6 +582,7 @@ export interface Group {
   video_model_prices?: VideoModelPrices
   // Codex 网页搜索单次价格（USD/次）；null 表示使用默认价 0.01
   web_search_price_per_call: number | null
+  web_fetch_price_per_call: number | null
   // Grok Voice 显式定价（分组级）
   search_price_per_1k: number | null
   audio_realtime_price_per_min: number | null
@@ -730,9 +733,18 @@ export interface ApiKey {
   reset_5h_at: string | null
   reset_1d_at: string | null
   reset_7d_at: string | null
+  model_allowlist: string[] | null
+  model_denylist: string[] | null
+  model_aliases: Record<string, string> | null
 }
 
-export interface CreateApiKeyRequest {
+export interface ApiKeyModelPolicy {
+  model_allowlist?: string[]
+  model_denylist?: string[]
+  model_aliases?: Record<string, string> // alias -> target model
+}
+
+export interface CreateApiKeyRequest extends ApiKeyModelPolicy {
   name: string
   group_id?: number | null
   custom_key?: string // Optional custom API Key
@@ -745,7 +757,7 @@ export interface CreateApiKeyRequest {
   rate_limit_7d?: number
 }
 
-export interface UpdateApiKeyRequest {
+export interface UpdateApiKeyRequest extends ApiKeyModelPolicy {
   name?: string
   group_id?: number | null
   status?: 'active' | 'inactive'
@@ -788,6 +800,7 @@ export interface CreateGroupRequest {
   video_price_1080p?: number | null
   video_model_prices?: VideoModelPrices
   web_search_price_per_call?: number | null
+  web_fetch_price_per_call?: number | null
   search_price_per_1k?: number | null
   audio_realtime_price_per_min?: number | null
   audio_tts_price_per_million_chars?: number | null
@@ -850,6 +863,7 @@ export interface UpdateGroupRequest {
   video_price_1080p?: number | null
   video_model_prices?: VideoModelPrices
   web_search_price_per_call?: number | null
+  web_fetch_price_per_call?: number | null
   search_price_per_1k?: number | null
   audio_realtime_price_per_min?: number | null
   audio_tts_price_per_million_chars?: number | null
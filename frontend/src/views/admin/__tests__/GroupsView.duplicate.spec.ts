@@ -94,6 +94,7 @@ const sourceGroup: AdminGroup = {
   video_price_720p: null,
   video_price_1080p: null,
   web_search_price_per_call: null,
+  web_fetch_price_per_call: null,
   peak_rate_enabled: false,
   peak_start: '',
   peak_end: '',
@@ -0,0 +1,126 @@
+import { beforeEach, describe, expect, it, vi } from 'vitest'
+import { flushPromises, mount } from '@vue/test-utils'
+
+import InvoicesView from '../InvoicesView.vue'
+
+const { list, voidInvoice, reissue, showError, showSuccess } = vi.hoisted(() => ({
+  list: vi.fn(),
+  voidInvoice: vi.fn(),
+  reissue: vi.fn(),
+  showError: vi.fn(),
+  showSuccess: vi.fn(),
+}))
+
+vi.mock('@/api/admin', () => ({
+  adminAPI: {
+    invoices: {
+      list,
+      get: vi.fn(),
+      download: vi.fn(),
+      void: voidInvoice,
+      reissue,
+    },
+  },
+}))
+
+vi.mock('@/stores', () => ({
+  useAppStore: () => ({ showError, showSuccess }),
+}))
+
+vi.mock('vue-i18n', () => ({
+  useI18n: () => ({
+    t: (key: string, params?: Record<string, unknown>) =>
+      params?.number === undefined ? key : `${key}:${params.number}`,
+  }),
+}))
+
+const issued = {
+  id: 3,
+  number: 'RCPT-2026-000003',
+  kind: 'receipt',
+  status: 'issued',
+  owner_type: 'user',
+  owner_id: 9,
+  currency: 'USD',
+  subtotal: 20,
+  total: 20,
+  bill_to: { company_name: 'Acme' },
+  line_items: [],
+  issued_at: '2026-09-01T00:00:00Z',
+}
+
+function mountView() {
+  return mount(InvoicesView, {
+    global: {
+      stubs: {
+        AppLayout: { template: '<div><slot /></div>' },
+        TablePageLayout: {
+          template: '<div><slot name="filters" /><slot name="table" /><slot name="pagination" /></div>',
+        },
+        DataTable: {
+          props: ['data'],
+          template:
+            '<div><div v-for="row in data" :key="row.id" class="row"><slot name="cell-actions" :row="row" /></div></div>',
+        },
+        Pagination: true,
+        Select: true,
+        Icon: true,
+        BaseDialog: {
+          props: ['show', 'title'],
+          template: '<div v-if="show"><slot /><slot name="footer" /></div>',
+        },
+      },
+    },
+  })
+}
+
+describe('admin InvoicesView', () => {
+  beforeEach(() => {
+    list.mockReset().mockResolvedValue({ items: [issued], total: 1, page: 1, page_size: 20, pages: 1 })
+    voidInvoice.mockReset().mockResolvedValue({ ...issued, status: 'void' })
+    reissue.mockReset().mockResolvedValue({ ...issued, id: 4, number: 'RCPT-2026-000004' })
+    showError.mockReset()
+    showSuccess.mockReset()
+  })
+
+  it('requires a reason before voiding and reloads the list afterwards', async () => {
+    const wrapper = mountView()
+    await flushPromises()
+    expect(list).toHaveBeenCalledTimes(1)
+
+    await wrapper.get('[data-test="void-invoice"]').trigger('click')
+    const confirm = wrapper.get('[data-test="confirm-action"]')
+    expect(confirm.attributes('disabled')).toBeDefined()
+
+    await wrapper.get('[data-test="action-reason"]').setValue('  duplicate charge  ')
+    await confirm.trigger('click')
+    await flushPromises()
+
+    expect(voidInvoice).toHaveBeenCalledWith(3, 'duplicate charge')
+    expect(reissue).not.toHaveBeenCalled()
+    expect(list).toHaveBeenCalledTimes(2)
+    expect(wrapper.find('[data-test="confirm-action"]').exists()).toBe(false)
+  })
+
+  it('reissues and reports the replacement number', async () => {
+    const wrapper = mountView()
+    await flushPromises()
+
+    await wrapper.get('[data-test="reissue-invoice"]').trigger('click')
+    await wrapper.get('[data-test="action-reason"]').setValue('wrong tax id')
+    await wrapper.get('[data-test="confirm-action"]').trigger('click')
+    await flushPromises()
+
+    expect(reissue).toHaveBeenCalledWith(3, 'wrong tax id')
+    expect(showSuccess).toHaveBeenCalledWith('admin.invoices.reissueDialog.success:RCPT-2026-000004')
+  })
+
+  it('hides the void action for voided invoices', async () => {
+    list.mockResolvedValue({ items: [{ ...issued, status: 'void' }], total: 1, page: 1, page_size: 20, pages: 1 })
+    const wrapper = mountView()
+    await flushPromises()
+
+    expect(wrapper.find('[data-test="void-invoice"]').exists()).toBe(false)
+    expect(wrapper.find('[data-test="reissue-invoice"]').exists()).toBe(true)
+  })
+})
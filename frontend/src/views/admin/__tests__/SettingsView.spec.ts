@@ -558,6 +558,7 @@ function mountView() {
         ProxySelector: true,
         ImageUpload: ImageUploadStub,
         BackupSettings: true,
+        AdminApiTokensSettings: true,
       },
     },
   });
@@ -1241,6 +1242,7 @@ describe("admin SettingsView payment visible method controls", () => {
           ProxySelector: true,
           ImageUpload: ImageUploadStub,
           BackupSettings: true,
+          AdminApiTokensSettings: true,
         },
       },
     });
@@ -1518,6 +1520,7 @@ describe("admin SettingsView payment visible method controls", () => {
           ProxySelector: true,
           ImageUpload: ImageUploadStub,
           BackupSettings: true,
+          AdminApiTokensSettings: true,
         },
       },
     });
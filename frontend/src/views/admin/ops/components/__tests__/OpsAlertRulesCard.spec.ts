@@ -0,0 +1,145 @@
+import { beforeEach, describe, expect, it, vi } from 'vitest'
+import { defineComponent } from 'vue'
+import { flushPromises, mount } from '@vue/test-utils'
+import OpsAlertRulesCard from '../OpsAlertRulesCard.vue'
+
+const mockListAlertRules = vi.fn()
+const mockCreateAlertRule = vi.fn()
+const mockUpdateAlertRule = vi.fn()
+const mockDeleteAlertRule = vi.fn()
+
+vi.mock('@/api/admin/ops', () => ({
+  opsAPI: {
+    listAlertRules: (...args: any[]) => mockListAlertRules(...args),
+    createAlertRule: (...args: any[]) => mockCreateAlertRule(...args),
+    updateAlertRule: (...args: any[]) => mockUpdateAlertRule(...args),
+    deleteAlertRule: (...args: any[]) => mockDeleteAlertRule(...args),
+  },
+}))
+
+vi.mock('@/api', () => ({
+  adminAPI: {
+    groups: { getAll: vi.fn().mockResolvedValue([]) },
+  },
+}))
+
+const showError = vi.fn()
+const showSuccess = vi.fn()
+
+vi.mock('@/stores/app', () => ({
+  useAppStore: () => ({ showError, showSuccess }),
+}))
+
+vi.mock('@vueuse/core', async () => {
+  const { ref } = await import('vue')
+  return { useMediaQuery: () => ref(true) }
+})
+
+vi.mock('vue-i18n', async (importOriginal) => {
+  const actual = await importOriginal<typeof import('vue-i18n')>()
+  return {
+    ...actual,
+    useI18n: () => ({ t: (key: string) => key }),
+  }
+})
+
+const SelectStub = defineComponent({
+  name: 'SelectControlStub',
+  props: {
+    modelValue: {
+      type: [String, Number],
+      default: '',
+    },
+  },
+  emits: ['update:modelValue'],
+  template: '<div class="select-stub" />',
+})
+
+const ruleWithChannels = {
+  id: 7,
+  name: 'error rate',
+  enabled: true,
+  metric_type: 'error_rate',
+  operator: '>',
+  threshold: 1,
+  window_minutes: 1,
+  sustained_minutes: 2,
+  severity: 'P1',
+  cooldown_minutes: 10,
+  notify_email: true,
+  notify_channels: [
+    { type: 'telegram', bot_token: '********EFGH', chat_id: '-100', min_severity: '', rate_limit_per_hour: 0 },
+  ],
+}
+
+function mountCard() {
+  return mount(OpsAlertRulesCard, {
+    global: {
+      stubs: {
+        Select: SelectStub,
+        BaseDialog: {
+          props: ['show', 'title'],
+          template: '<div v-if="show"><slot /><slot name="footer" /></div>',
+        },
+        ConfirmDialog: true,
+      },
+    },
+  })
+}
+
+describe('OpsAlertRulesCard notify channels', () => {
+  beforeEach(() => {
+    mockListAlertRules.mockReset().mockResolvedValue([ruleWithChannels])
+    mockCreateAlertRule.mockReset().mockResolvedValue({})
+    mockUpdateAlertRule.mockReset().mockResolvedValue({})
+    mockDeleteAlertRule.mockReset()
+    showError.mockReset()
+    showSuccess.mockReset()
+  })
+
+  it('submits masked secrets unchanged so the server keeps the stored values', async () => {
+    const wrapper = mountCard()
+    await flushPromises()
+
+    const editButton = wrapper.findAll('button').find((b) => b.text() === 'common.edit')
+    await editButton!.trigger('click')
+
+    expect(wrapper.findAll('[data-test="channel-row"]')).toHaveLength(1)
+    expect((wrapper.get('[data-test="channel-bot-token"]').element as HTMLInputElement).value).toBe('********EFGH')
+    expect(wrapper.text()).toContain('admin.ops.alertRules.channels.maskedHint')
+
+    await wrapper.findAll('button').find((b) => b.text() === 'common.save')!.trigger('click')
+    await flushPromises()
+
+    expect(mockUpdateAlertRule).toHaveBeenCalledTimes(1)
+    const [id, payload] = mockUpdateAlertRule.mock.calls[0]
+    expect(id).toBe(7)
+    expect(payload.notify_channels).toEqual(ruleWithChannels.notify_channels)
+  })
+
+  it('adds a webhook channel and requires its url before saving', async () => {
+    const wrapper = mountCard()
+    await flushPromises()
+
+    await wrapper.findAll('button').find((b) => b.text() === 'admin.ops.alertRules.create')!.trigger('click')
+    await wrapper.findAll('input[type="text"]')[0].setValue('latency')
+    await wrapper.get('[data-test="add-channel"]').trigger('click')
+    expect(wrapper.findAll('[data-test="channel-row"]')).toHaveLength(1)
+
+    const save = () => wrapper.findAll('button').find((b) => b.text() === 'common.save')!.trigger('click')
+    await save()
+    await flushPromises()
+    expect(mockCreateAlertRule).not.toHaveBeenCalled()
+    expect(showError).toHaveBeenCalledWith('admin.ops.alertRules.validation.channelUrlRequired')
+
+    await wrapper.get('[data-test="channel-url"]').setValue('https://example.com/hook')
+    await wrapper.get('[data-test="channel-secret"]').setValue('s3cret')
+    await save()
+    await flushPromises()
+
+    expect(mockCreateAlertRule).toHaveBeenCalledTimes(1)
+    expect(mockCreateAlertRule.mock.calls[0][0].notify_channels).toEqual([
+      { type: 'webhook', min_severity: '', rate_limit_per_hour: 0, url: 'https://example.com/hook', secret: 's3cret' },
+    ])
+  })
+})
@@ -0,0 +1,151 @@
+import { flushPromises, mount } from '@vue/test-utils'
+import { beforeEach, describe, expect, it, vi } from 'vitest'
+
+import AdminApiTokensSettings from '../AdminApiTokensSettings.vue'
+
+vi.mock('vue-i18n', () => ({
+  useI18n: () => ({
+    t: (key: string) => key,
+  }),
+}))
+
+const mockList = vi.fn()
+const mockCreate = vi.fn()
+const mockRevoke = vi.fn()
+const mockGetPermissionCatalog = vi.fn()
+
+vi.mock('@/api/admin', () => ({
+  adminAPI: {
+    apiTokens: {
+      list: (...args: unknown[]) => mockList(...args),
+      create: (...args: unknown[]) => mockCreate(...args),
+      revoke: (...args: unknown[]) => mockRevoke(...args),
+      getPermissionCatalog: (...args: unknown[]) => mockGetPermissionCatalog(...args),
+    },
+  },
+}))
+
+const showError = vi.fn()
+const showSuccess = vi.fn()
+
+vi.mock('@/stores', () => ({
+  useAppStore: () => ({ showError, showSuccess }),
+}))
+
+vi.mock('@/composables/useClipboard', () => ({
+  useClipboard: () => ({ copyToClipboard: vi.fn() }),
+}))
+
+const activeToken = {
+  id: 1,
+  name: 'ci',
+  token_prefix: 'admtok_abc123',
+  scopes: ['users:read'],
+  step_up_scopes: [],
+  allowed_ips: [],
+  last_used_ip: '',
+  created_by: 1,
+  created_at: '2026-10-01T00:00:00Z',
+}
+
+const revokedToken = {
+  ...activeToken,
+  id: 2,
+  name: 'old',
+  revoked_at: '2026-10-02T00:00:00Z',
+}
+
+function mountPanel() {
+  return mount(AdminApiTokensSettings, {
+    global: {
+      stubs: {
+        Icon: true,
+        TotpStepUpDialog: true,
+        BaseDialog: {
+          props: ['show', 'title'],
+          template: '<div v-if="show"><slot /><slot name="footer" /></div>',
+        },
+        ConfirmDialog: {
+          props: ['show'],
+          emits: ['confirm', 'cancel'],
+          template: '<button v-if="show" data-test="confirm-revoke" @click="$emit(\'confirm\')">confirm</button>',
+        },
+      },
+    },
+  })
+}
+
+describe('AdminApiTokensSettings', () => {
+  beforeEach(() => {
+    mockList.mockReset().mockResolvedValue([activeToken, revokedToken])
+    mockCreate.mockReset()
+    mockRevoke.mockReset().mockResolvedValue({ message: 'ok' })
+    mockGetPermissionCatalog.mockReset().mockResolvedValue({
+      resources: [
+        { key: 'users', description: 'Users' },
+        { key: 'accounts', description: 'Accounts' },
+      ],
+      actions: ['read', 'write'],
+    })
+    showError.mockReset()
+    showSuccess.mockReset()
+  })
+
+  it('lists tokens and only offers revoke for tokens that are not revoked', async () => {
+    const wrapper = mountPanel()
+    await flushPromises()
+
+    expect(wrapper.findAll('[data-test="token-row"]')).toHaveLength(2)
+    expect(wrapper.text()).toContain('admtok_abc123')
+    expect(wrapper.text()).toContain('admin.settings.adminApiTokens.status.active')
+    expect(wrapper.text()).toContain('admin.settings.adminApiTokens.status.revoked')
+    expect(wrapper.findAll('[data-test="revoke-token"]')).toHaveLength(1)
+  })
+
+  it('creates a custom-scoped token and reveals the plaintext once', async () => {
+    mockCreate.mockResolvedValue({ token: 'admtok_secret', api_token: activeToken })
+    const wrapper = mountPanel()
+    await flushPromises()
+
+    await wrapper.get('[data-test="create-token"]').trigger('click')
+    await wrapper.get('[data-test="token-name"]').setValue('deploy')
+    await wrapper.get('[data-test="access-accounts"]').setValue('write')
+    await wrapper.get('[data-test="submit-token"]').trigger('click')
+    await flushPromises()
+
+    expect(mockCreate).toHaveBeenCalledWith({
+      name: 'deploy',
+      scopes: ['accounts:write'],
+      step_up_scopes: [],
+      allowed_ips: [],
+      expires_in_days: 90,
+    })
+    expect(wrapper.get('[data-test="revealed-token"]').text()).toBe('admtok_secret')
+  })
+
+  it('requires at least one scope before calling the API', async () => {
+    const wrapper = mountPanel()
+    await flushPromises()
+
+    await wrapper.get('[data-test="create-token"]').trigger('click')
+    await wrapper.get('[data-test="token-name"]').setValue('empty')
+    await wrapper.get('[data-test="submit-token"]').trigger('click')
+    await flushPromises()
+
+    expect(mockCreate).not.toHaveBeenCalled()
+    expect(showError).toHaveBeenCalledWith('admin.settings.adminApiTokens.form.scopesRequired')
+  })
+
+  it('revokes a token after confirmation and reloads the list', async () => {
+    const wrapper = mountPanel()
+    await flushPromises()
+
+    await wrapper.get('[data-test="revoke-token"]').trigger('click')
+    await wrapper.get('[data-test="confirm-revoke"]').trigger('click')
+    await flushPromises()
+
+    expect(mockRevoke).toHaveBeenCalledWith(1)
+    expect(mockList).toHaveBeenCalledTimes(2)
+    expect(showSuccess).toHaveBeenCalled()
+  })
+})
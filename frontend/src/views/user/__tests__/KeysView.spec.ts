@@ -134,6 +134,9 @@ const createApiKey = (): ApiKey => ({
   reset_5h_at: null,
   reset_1d_at: null,
   reset_7d_at: null,
+  model_allowlist: null,
+  model_denylist: null,
+  model_aliases: null,
 })
 
 const AppLayoutStub = {